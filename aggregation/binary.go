@@ -36,6 +36,8 @@ func binaryEval(binaryOp stmt.BinaryOP, left, right *collections.FloatArray) *co
 
 	capacity := left.Capacity()
 	result := collections.NewFloatArray(capacity)
+	// result of constants(like 2*3) is also constant, which is applied to all points of other operand
+	result.SetSingle(left.IsSingle() && right.IsSingle())
 
 	for i := 0; i < capacity; i++ {
 		leftHasValue := left.HasValue(i)
@@ -63,6 +63,28 @@ func TestBinary_Eval_Single(t *testing.T) {
 	assert.Equal(t, 99.0, result.GetValue(8))
 }
 
+func TestBinary_Eval_Constant(t *testing.T) {
+	newConstant := func(val float64) *collections.FloatArray {
+		values := collections.NewFloatArray(10)
+		for i := 0; i < 10; i++ {
+			values.SetValue(i, val)
+		}
+		values.SetSingle(true)
+		return values
+	}
+	constant := binaryEval(stmt.ADD, newConstant(2), newConstant(3))
+	assert.True(t, constant.IsSingle())
+	assert.Equal(t, 5.0, constant.GetValue(9))
+
+	left := collections.NewFloatArray(10)
+	left.SetValue(1, 2)
+	// no point if field has no value
+	result := binaryEval(stmt.MUL, left, constant)
+	assert.False(t, result.IsSingle())
+	assert.Equal(t, 1, result.Size())
+	assert.Equal(t, 10.0, result.GetValue(1))
+}
+
 func TestBinaryEval(t *testing.T) {
 	assert.Nil(t, binaryEval(stmt.DIV, collections.NewFloatArray(10), collections.NewFloatArray(10)))
 
@@ -20,7 +20,6 @@ package aggregation
 import (
 	"math"
 
-	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/pkg/bit"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/timeutil"
@@ -37,33 +36,27 @@ type DownSamplingAggregator interface {
 
 // DownSamplingResult represents the result of down sampling aggregator.
 type DownSamplingResult interface {
-	// Append appends time and value.
-	Append(slot bit.Bit, value float64)
+	// AppendPage appends the values of target slot range, idx 0 of page is the start of target slot range.
+	AppendPage(page *Page)
 	Reset()
 }
 
 type downSamplingMergeResult struct {
 	agg FieldAggregator
-
-	pos int
 }
 
 func NewDownSamplingMergeResult(agg FieldAggregator) DownSamplingResult {
 	return &downSamplingMergeResult{
 		agg: agg,
-		pos: 0,
 	}
 }
 
-func (d *downSamplingMergeResult) Append(slot bit.Bit, value float64) {
-	if slot == bit.One {
-		d.agg.AggregateBySlot(d.pos, value)
-	}
-	d.pos++
+func (d *downSamplingMergeResult) AppendPage(page *Page) {
+	d.agg.AggregatePage(page)
 }
 
 func (d *downSamplingMergeResult) Reset() {
-	d.pos = 0
+	// do nothing
 }
 
 // TSDDownSamplingResult implements DownSamplingResult using encoding TSDEncoder.
@@ -76,11 +69,15 @@ func NewTSDDownSamplingResult(stream encoding.TSDEncoder) DownSamplingResult {
 	return &TSDDownSamplingResult{stream: stream}
 }
 
-// Append appends time and value into tsd encode stream.
-func (rs *TSDDownSamplingResult) Append(slot bit.Bit, value float64) {
-	rs.stream.AppendTime(slot)
-	if slot == bit.One {
-		rs.stream.AppendValue(math.Float64bits(value))
+// AppendPage appends times and values into tsd encode stream.
+func (rs *TSDDownSamplingResult) AppendPage(page *Page) {
+	for i := 0; i < page.Len(); i++ {
+		if page.HasValue(i) {
+			rs.stream.AppendTime(bit.One)
+			rs.stream.AppendValue(math.Float64bits(page.Value(i)))
+		} else {
+			rs.stream.AppendTime(bit.Zero)
+		}
 	}
 }
 
@@ -109,44 +106,61 @@ func NewDownSamplingAggregator(source, target timeutil.SlotRange,
 
 // DownSampling merges field data from source time range => target time range,
 // for example: source range[5,182]=>target range[0,6], ratio:30, source interval:10s, target interval:5min.
+// Each series is decoded into a page once, then pages are merged slot by slot in batch.
 func (ds *downSamplingAggregator) DownSampling(aggFunc field.AggFunc, values []*encoding.TSDDecoder) {
-	hasValue := false
-	pos := ds.source.Start
-	end := ds.source.End
-	result := 0.0
-	rs := ds.rs
-	// first loop: target slot range
-	for j := ds.target.Start; j <= ds.target.End; j++ {
-		// second loop: source slot range and ratio(target interval/source interval)
-		intervalEnd := ds.ratio * (j + 1)
-		for pos <= end && pos < intervalEnd {
-			// 1. merge data by time slot
-			for _, value := range values {
-				if value == nil {
-					// if series id not exist, value maybe nil
-					continue
-				}
-				if value.HasValueWithSlot(pos) {
-					if !hasValue {
-						// if target value not exist, set it
-						result = math.Float64frombits(value.Value())
-						hasValue = true
-					} else {
-						// if target value exist, do aggregate
-						result = aggFunc.Aggregate(result, math.Float64frombits(value.Value()))
-					}
-				}
+	identity := aggFunc.Identity()
+	sourceStart := int(ds.source.Start)
+	sourceLen := int(ds.source.End) - sourceStart + 1
+	if sourceLen < 0 {
+		sourceLen = 0
+	}
+	merged := getPage(sourceLen)
+	page := getPage(sourceLen)
+	target := getPage(int(ds.target.End) - int(ds.target.Start) + 1)
+	defer func() {
+		putPage(merged)
+		putPage(page)
+		putPage(target)
+	}()
+
+	// 1. decode each series into page, then merge data by time slot
+	merged.Reset(identity)
+	for _, value := range values {
+		if value == nil {
+			// if series id not exist, value maybe nil
+			continue
+		}
+		page.Reset(identity)
+		decodePage(value, ds.source.Start, page)
+		merged.Merge(aggFunc, page)
+	}
+	// 2. merge data by target interval(target interval/source interval)
+	target.Reset(identity)
+	pos := sourceStart
+	end := int(ds.source.End)
+	ratio := int(ds.ratio)
+	for j := int(ds.target.Start); j <= int(ds.target.End); j++ {
+		intervalEnd := ratio * (j + 1)
+		hasValue := false
+		result := 0.0
+		for ; pos <= end && pos < intervalEnd; pos++ {
+			idx := pos - sourceStart
+			if !merged.HasValue(idx) {
+				continue
+			}
+			if !hasValue {
+				// if target value not exist, set it
+				result = merged.Value(idx)
+				hasValue = true
+			} else {
+				// if target value exist, do aggregate
+				result = aggFunc.Aggregate(result, merged.Value(idx))
 			}
-			pos++
 		}
-		// 2. add data into rs stream
 		if hasValue {
-			rs.Append(bit.One, result)
-			// reset has value for next loop
-			hasValue = false
-			result = 0.0
-		} else {
-			rs.Append(bit.Zero, constants.EmptyValue)
+			target.SetValue(j-int(ds.target.Start), result)
 		}
 	}
+	// 3. add data into rs
+	ds.rs.AppendPage(target)
 }
@@ -0,0 +1,203 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aggregation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/pkg/bit"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/series/field"
+)
+
+// mapResult collects down sampling result into map for comparing.
+type mapResult struct {
+	values map[int]float64
+}
+
+func (m *mapResult) AppendPage(page *Page) {
+	for i := 0; i < page.Len(); i++ {
+		if page.HasValue(i) {
+			m.values[i] = page.Value(i)
+		}
+	}
+}
+
+func (m *mapResult) Reset() {}
+
+// referenceDownSampling merges data slot by slot, same as the algorithm before using page.
+func referenceDownSampling(source, target timeutil.SlotRange, ratio uint16,
+	aggFunc field.AggFunc, values []*encoding.TSDDecoder) map[int]float64 {
+	result := make(map[int]float64)
+	pos := source.Start
+	for j := target.Start; j <= target.End; j++ {
+		hasValue := false
+		value := 0.0
+		intervalEnd := ratio * (j + 1)
+		for pos <= source.End && pos < intervalEnd {
+			for _, v := range values {
+				if v == nil || !v.HasValueWithSlot(pos) {
+					continue
+				}
+				if !hasValue {
+					value = math.Float64frombits(v.Value())
+					hasValue = true
+				} else {
+					value = aggFunc.Aggregate(value, math.Float64frombits(v.Value()))
+				}
+			}
+			pos++
+		}
+		if hasValue {
+			result[int(j-target.Start)] = value
+		}
+	}
+	return result
+}
+
+// genSeries generates tsd data with random values in slot range, ~1/3 slots are empty.
+func genSeries(r *rand.Rand, start, end uint16) []byte {
+	encoder := encoding.NewTSDEncoder(start)
+	for i := start; i <= end; i++ {
+		if r.Intn(3) == 0 {
+			encoder.AppendTime(bit.Zero)
+			continue
+		}
+		encoder.AppendTime(bit.One)
+		// integer values keep sum result exact regardless of aggregate order
+		encoder.AppendValue(math.Float64bits(float64(r.Intn(1000) - 500)))
+	}
+	data, _ := encoder.Bytes()
+	return data
+}
+
+func newDecoders(data [][]byte) []*encoding.TSDDecoder {
+	decoders := make([]*encoding.TSDDecoder, len(data)+1)
+	// nil decoder represents series not exist
+	for i, d := range data {
+		decoders[i+1] = encoding.NewTSDDecoder(d)
+	}
+	return decoders
+}
+
+func TestDownSamplingAggregator_DownSampling(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	cases := []struct {
+		source, target timeutil.SlotRange
+		ratio          uint16
+	}{
+		{source: timeutil.SlotRange{Start: 0, End: 359}, target: timeutil.SlotRange{Start: 0, End: 359}, ratio: 1},
+		{source: timeutil.SlotRange{Start: 5, End: 182}, target: timeutil.SlotRange{Start: 0, End: 6}, ratio: 30},
+		{source: timeutil.SlotRange{Start: 10, End: 100}, target: timeutil.SlotRange{Start: 5, End: 20}, ratio: 6},
+		{source: timeutil.SlotRange{Start: 3, End: 3}, target: timeutil.SlotRange{Start: 0, End: 0}, ratio: 10},
+	}
+	for _, c := range cases {
+		var data [][]byte
+		for i := 0; i < 5; i++ {
+			data = append(data, genSeries(r, c.source.Start, c.source.End))
+		}
+		for _, aggType := range []field.AggType{field.Sum, field.Count, field.Min, field.Max, field.LastValue} {
+			aggFunc := aggType.AggFunc()
+			expect := referenceDownSampling(c.source, c.target, c.ratio, aggFunc, newDecoders(data))
+			rs := &mapResult{values: make(map[int]float64)}
+			NewDownSamplingAggregator(c.source, c.target, c.ratio, rs).DownSampling(aggFunc, newDecoders(data))
+			assert.Equal(t, expect, rs.values, "agg type: %d, case: %v", aggType, c)
+		}
+	}
+}
+
+func TestTSDDownSamplingResult_AppendPage(t *testing.T) {
+	encoder := encoding.NewTSDEncoder(0)
+	rs := NewTSDDownSamplingResult(encoder)
+	page := NewPage(3)
+	page.Reset(0)
+	page.SetValue(1, 10)
+	rs.AppendPage(page)
+	rs.Reset()
+	data, err := encoder.Bytes()
+	assert.NoError(t, err)
+	decoder := encoding.NewTSDDecoder(data)
+	assert.False(t, decoder.HasValueWithSlot(0))
+	assert.True(t, decoder.HasValueWithSlot(1))
+	assert.Equal(t, 10.0, math.Float64frombits(decoder.Value()))
+	assert.False(t, decoder.HasValueWithSlot(2))
+}
+
+func TestDownSamplingMergeResult_AppendPage(t *testing.T) {
+	spec := NewAggregatorSpec("f", field.SumField)
+	spec.AddFunctionType(function.Sum)
+	agg := NewFieldAggregator(spec, 0, 0, 99)
+	rs := NewDownSamplingMergeResult(agg)
+	page := NewPage(100)
+	page.Reset(0)
+	page.SetValue(1, 10)
+	page.SetValue(70, 20)
+	rs.AppendPage(page)
+	rs.AppendPage(page)
+	rs.Reset()
+	agg.AggregateBySlot(70, 1)
+
+	_, it := agg.ResultSet()
+	assert.True(t, it.HasNext())
+	pIt := it.Next()
+	var result [][2]float64
+	for pIt.HasNext() {
+		slot, value := pIt.Next()
+		result = append(result, [2]float64{float64(slot), value})
+	}
+	assert.Equal(t, [][2]float64{{1, 20}, {70, 41}}, result)
+}
+
+func BenchmarkDownSampling_PerSlot(b *testing.B) {
+	benchmarkDownSampling(b, func(source, target timeutil.SlotRange, aggFunc field.AggFunc, decoders []*encoding.TSDDecoder) {
+		_ = referenceDownSampling(source, target, 1, aggFunc, decoders)
+	})
+}
+
+func BenchmarkDownSampling_Page(b *testing.B) {
+	rs := &mapResult{values: make(map[int]float64)}
+	benchmarkDownSampling(b, func(source, target timeutil.SlotRange, aggFunc field.AggFunc, decoders []*encoding.TSDDecoder) {
+		NewDownSamplingAggregator(source, target, 1, rs).DownSampling(aggFunc, decoders)
+	})
+}
+
+func benchmarkDownSampling(b *testing.B,
+	downSampling func(source, target timeutil.SlotRange, aggFunc field.AggFunc, decoders []*encoding.TSDDecoder)) {
+	r := rand.New(rand.NewSource(1))
+	slotRange := timeutil.SlotRange{Start: 0, End: 359}
+	var data [][]byte
+	for i := 0; i < 10; i++ {
+		data = append(data, genSeries(r, slotRange.Start, slotRange.End))
+	}
+	decoders := newDecoders(data)
+	aggFunc := field.Sum.AggFunc()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for idx, d := range data {
+			decoders[idx+1].Reset(d)
+		}
+		downSampling(slotRange, slotRange, aggFunc, decoders)
+	}
+}
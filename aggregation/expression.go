@@ -71,8 +71,8 @@ func (e *Expression) Eval(timeSeries series.GroupedIterator) {
 		values := e.eval(nil, selectItem)
 		if len(values) != 0 {
 			item, ok := selectItem.(*stmt.SelectItem)
-			if ok && len(item.Alias) > 0 {
-				e.resultSet[item.Alias] = values[0]
+			if ok {
+				e.resultSet[item.ResultName()] = values[0]
 			} else {
 				e.resultSet[item.Rewrite()] = values[0]
 			}
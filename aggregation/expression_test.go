@@ -18,12 +18,19 @@
 package aggregation
 
 import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
 	"testing"
+	"testing/quick"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/aggregation/fields"
 	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/pkg/collections"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
@@ -338,3 +345,120 @@ func TestExpression_NotSupport_Expr(t *testing.T) {
 	resultSet = expression.ResultSet()
 	assert.Equal(t, 0, len(resultSet))
 }
+
+// arithmeticNode represents the random arithmetic expression for property test.
+type arithmeticNode struct {
+	op          stmt.BinaryOP // leaf node if op is 0
+	left, right *arithmeticNode
+	field       string
+	constant    float64
+}
+
+// newArithmeticNode generates random arithmetic expression with fields(f1,f2,f3) and constants.
+func newArithmeticNode(r *rand.Rand, depth int) *arithmeticNode {
+	if depth == 0 || r.Intn(4) == 0 {
+		if r.Intn(3) == 0 {
+			return &arithmeticNode{constant: float64(r.Intn(9) + 1)}
+		}
+		return &arithmeticNode{field: fmt.Sprintf("f%d", r.Intn(3)+1)}
+	}
+	ops := []stmt.BinaryOP{stmt.ADD, stmt.SUB, stmt.MUL, stmt.DIV}
+	return &arithmeticNode{
+		op:    ops[r.Intn(len(ops))],
+		left:  newArithmeticNode(r, depth-1),
+		right: newArithmeticNode(r, depth-1),
+	}
+}
+
+func (n *arithmeticNode) precedence() int {
+	switch n.op {
+	case stmt.ADD, stmt.SUB:
+		return 1
+	case stmt.MUL, stmt.DIV:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// sql renders expression with parentheses only if needed by precedence/left associative,
+// redundant parentheses are added randomly.
+func (n *arithmeticNode) sql(r *rand.Rand) string {
+	if n.op == 0 {
+		if len(n.field) > 0 {
+			return n.field
+		}
+		return strconv.FormatFloat(n.constant, 'f', -1, 64)
+	}
+	left := n.left.sql(r)
+	if n.left.precedence() < n.precedence() || r.Intn(5) == 0 {
+		left = "(" + left + ")"
+	}
+	right := n.right.sql(r)
+	if n.right.precedence() <= n.precedence() || r.Intn(5) == 0 {
+		right = "(" + right + ")"
+	}
+	return left + stmt.BinaryOPString(n.op) + right
+}
+
+// value evaluates expression for the slot as reference result.
+func (n *arithmeticNode) value(fieldValues map[string][]float64, slot int) float64 {
+	if n.op == 0 {
+		if len(n.field) > 0 {
+			return fieldValues[n.field][slot]
+		}
+		return n.constant
+	}
+	return eval(n.op, n.left.value(fieldValues, slot), n.right.value(fieldValues, slot))
+}
+
+func TestExpression_Arithmetic_Property(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	property := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		node := newArithmeticNode(r, 4)
+		if node.op == 0 {
+			// select list doesn't support constant only
+			node = &arithmeticNode{op: stmt.MUL, left: &arithmeticNode{field: "f1"}, right: node}
+		}
+		expr := node.sql(r)
+		q, err := sql.Parse("select " + expr + " as f from cpu")
+		if err != nil {
+			t.Logf("parse expr: %s, err: %s", expr, err)
+			return false
+		}
+		expression := NewExpression(timeutil.TimeRange{
+			Start: now,
+			End:   now + timeutil.OneMinute*10,
+		}, timeutil.OneMinute, q.(*stmt.Query).SelectItems)
+		fieldValues := make(map[string][]float64)
+		for _, fieldName := range []string{"f1", "f2", "f3"} {
+			values := collections.NewFloatArray(expression.pointCount)
+			for i := 0; i < expression.pointCount; i++ {
+				value := float64(r.Intn(100) + 1)
+				values.SetValue(i, value)
+				fieldValues[fieldName] = append(fieldValues[fieldName], value)
+			}
+			f := fields.NewMockField(ctrl)
+			f.EXPECT().GetDefaultValues().Return([]*collections.FloatArray{values}).AnyTimes()
+			expression.fieldStore[field.Name(fieldName)] = f
+		}
+		expression.Eval(nil)
+		result := expression.ResultSet()["f"]
+		if result == nil {
+			t.Logf("no result of expr: %s", expr)
+			return false
+		}
+		for i := 0; i < expression.pointCount; i++ {
+			expect := node.value(fieldValues, i)
+			if math.Abs(result.GetValue(i)-expect) > 1e-9*math.Max(1, math.Abs(expect)) {
+				t.Logf("expr: %s, slot: %d, expect: %f, actual: %f", expr, i, expect, result.GetValue(i))
+				return false
+			}
+		}
+		return true
+	}
+	assert.NoError(t, quick.Check(property, &quick.Config{MaxCount: 500}))
+}
@@ -18,6 +18,8 @@
 package aggregation
 
 import (
+	"math/bits"
+
 	"github.com/lindb/lindb/pkg/collections"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
@@ -31,6 +33,8 @@ type FieldAggregator interface {
 	Aggregate(it series.FieldIterator)
 	// AggregateBySlot aggregates the field series into current aggregator.
 	AggregateBySlot(slot int, value float64)
+	// AggregatePage aggregates the values of page into current aggregator, idx of page is the slot.
+	AggregatePage(page *Page)
 	// ResultSet returns the result set of field aggregator.
 	ResultSet() (startTime int64, it series.FieldIterator)
 	SlotRange() (start, end int)
@@ -105,6 +109,30 @@ func (a *fieldAggregator) AggregateBySlot(slot int, value float64) {
 	}
 }
 
+// AggregatePage aggregates the values of page into current aggregator, only visits the slots which have value.
+func (a *fieldAggregator) AggregatePage(page *Page) {
+	for idx, aggType := range a.aggTypes {
+		values := a.fieldSeriesList[idx]
+		if values == nil {
+			values = collections.NewFloatArray(a.end - a.start + 1)
+			a.fieldSeriesList[idx] = values
+		}
+		aggFunc := aggType.AggFunc()
+		for w, mark := range page.marks {
+			for mark != 0 {
+				slot := w<<6 + bits.TrailingZeros64(mark)
+				mark &= mark - 1
+				value := page.values[slot]
+				if values.HasValue(slot) {
+					values.SetValue(slot, aggFunc.Aggregate(values.GetValue(slot), value))
+				} else {
+					values.SetValue(slot, value)
+				}
+			}
+		}
+	}
+}
+
 func (a *fieldAggregator) reset() {
 	for idx := range a.fieldSeriesList {
 		if a.fieldSeriesList[idx] == nil {
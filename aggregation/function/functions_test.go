@@ -41,3 +41,9 @@ func TestFuncCall_Sum(t *testing.T) {
 	result = FuncCall(Sum, array1, array2)
 	assert.Equal(t, array1, result)
 }
+
+func TestFuncCall_Value(t *testing.T) {
+	array := collections.NewFloatArray(10)
+	assert.Equal(t, array, FuncCall(LastValue, array))
+	assert.Equal(t, array, FuncCall(FirstValue, array))
+}
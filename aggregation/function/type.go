@@ -29,6 +29,7 @@ const (
 	LastValue
 	Quantile
 	Stddev
+	FirstValue
 
 	Unknown
 )
@@ -52,6 +53,8 @@ func (t FuncType) String() string {
 		return "quantile"
 	case Stddev:
 		return "stddev"
+	case FirstValue:
+		return "first_value"
 	default:
 		return "unknown"
 	}
@@ -32,5 +32,6 @@ func TestFuncTypeString(t *testing.T) {
 	assert.Equal(t, "last_value", LastValue.String())
 	assert.Equal(t, "quantile", Quantile.String())
 	assert.Equal(t, "stddev", Stddev.String())
+	assert.Equal(t, "first_value", FirstValue.String())
 	assert.Equal(t, "unknown", Unknown.String())
 }
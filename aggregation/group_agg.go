@@ -47,12 +47,27 @@ func NewGroupingAggregator(
 	timeRange timeutil.TimeRange,
 	aggSpecs AggregatorSpecs,
 ) GroupingAggregator {
+	return NewGroupingAggregatorWithCapacity(interval, intervalRatio, timeRange, aggSpecs, 0)
+}
+
+// NewGroupingAggregatorWithCapacity creates a grouping aggregator,
+// preallocates the space of groups by capacity for avoiding map growth when aggregates too many groups.
+func NewGroupingAggregatorWithCapacity(
+	interval timeutil.Interval,
+	intervalRatio int,
+	timeRange timeutil.TimeRange,
+	aggSpecs AggregatorSpecs,
+	capacity int,
+) GroupingAggregator {
+	if capacity < 0 {
+		capacity = 0
+	}
 	return &groupingAggregator{
 		aggSpecs:      aggSpecs,
 		interval:      interval,
 		intervalRatio: intervalRatio,
 		timeRange:     timeRange,
-		aggregates:    make(map[string]FieldAggregates),
+		aggregates:    make(map[string]FieldAggregates, capacity),
 	}
 }
 
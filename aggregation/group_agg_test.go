@@ -17,6 +17,60 @@
 
 package aggregation
 
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/series"
+	"github.com/lindb/lindb/series/field"
+)
+
+func TestNewGroupingAggregatorWithCapacity(t *testing.T) {
+	agg := NewGroupingAggregatorWithCapacity(timeutil.Interval(timeutil.OneSecond), 1,
+		timeutil.TimeRange{Start: 0, End: timeutil.OneHour}, newTestAggSpecs(), -1)
+	assert.Nil(t, agg.ResultSet())
+	for i := 0; i < 10; i++ {
+		agg.Aggregate(series.NewGroupedIterator(strconv.Itoa(i), nil))
+	}
+	assert.Len(t, agg.ResultSet(), 10)
+}
+
+func BenchmarkGroupingAggregator_100k_Groups(b *testing.B) {
+	benchmarkGroupingAggregator(b, 0)
+}
+
+func BenchmarkGroupingAggregator_100k_Groups_Preallocate(b *testing.B) {
+	benchmarkGroupingAggregator(b, 100000)
+}
+
+func benchmarkGroupingAggregator(b *testing.B, capacity int) {
+	tags := make([]string, 100000)
+	for i := range tags {
+		tags[i] = "host-" + strconv.Itoa(i)
+	}
+	specs := newTestAggSpecs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		agg := NewGroupingAggregatorWithCapacity(timeutil.Interval(timeutil.OneSecond), 1,
+			timeutil.TimeRange{Start: 0, End: timeutil.OneHour}, specs, capacity)
+		for _, t := range tags {
+			agg.Aggregate(series.NewGroupedIterator(t, nil))
+		}
+		_ = agg.ResultSet()
+	}
+}
+
+func newTestAggSpecs() AggregatorSpecs {
+	spec := NewAggregatorSpec(field.Name("f"), field.SumField)
+	spec.AddFunctionType(function.Sum)
+	return AggregatorSpecs{spec}
+}
+
 //TODO need impl
 //func TestGroupByAggregator_Aggregate(t *testing.T) {
 //	ctrl := gomock.NewController(t)
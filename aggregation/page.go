@@ -0,0 +1,134 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aggregation
+
+import (
+	"math"
+	"sync"
+
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/series/field"
+)
+
+var pagePool sync.Pool
+
+// getPage returns a page with given length from pool.
+func getPage(length int) *Page {
+	item := pagePool.Get()
+	if item == nil {
+		return NewPage(length)
+	}
+	page := item.(*Page)
+	page.resize(length)
+	return page
+}
+
+// putPage returns page into pool.
+func putPage(page *Page) {
+	if page != nil {
+		pagePool.Put(page)
+	}
+}
+
+// Page represents the decoded values of field in continuous slots, which is used for
+// aggregating field values in batch instead of slot by slot.
+// Empty slots are filled with identity value of aggregator, so that sum/min/max can be
+// aggregated without checking if slot has value.
+type Page struct {
+	values []float64
+	marks  []uint64 // bitmap of slots which have value, 64 slots per word
+}
+
+// NewPage creates a page with given length.
+func NewPage(length int) *Page {
+	p := &Page{}
+	p.resize(length)
+	return p
+}
+
+// resize resizes the page with new length, reuses underlying buffer if possible.
+func (p *Page) resize(length int) {
+	if cap(p.values) < length {
+		p.values = make([]float64, length)
+	}
+	p.values = p.values[:length]
+	markLen := (length + 63) >> 6
+	if cap(p.marks) < markLen {
+		p.marks = make([]uint64, markLen)
+	}
+	p.marks = p.marks[:markLen]
+}
+
+// Len returns the num. of slots in page.
+func (p *Page) Len() int {
+	return len(p.values)
+}
+
+// Reset clears all slots and fills them with identity value.
+func (p *Page) Reset(identity float64) {
+	for i := range p.values {
+		p.values[i] = identity
+	}
+	for i := range p.marks {
+		p.marks[i] = 0
+	}
+}
+
+// HasValue returns if slot has value.
+func (p *Page) HasValue(idx int) bool {
+	return p.marks[idx>>6]&(1<<(uint(idx)&63)) != 0
+}
+
+// Value returns the value of slot.
+func (p *Page) Value(idx int) float64 {
+	return p.values[idx]
+}
+
+// SetValue sets the value of slot.
+func (p *Page) SetValue(idx int, value float64) {
+	p.values[idx] = value
+	p.marks[idx>>6] |= 1 << (uint(idx) & 63)
+}
+
+// IsEmpty returns if page has no value.
+func (p *Page) IsEmpty() bool {
+	for _, mark := range p.marks {
+		if mark != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge aggregates other page into current page slot by slot, both pages must be filled with
+// identity value of aggregator.
+func (p *Page) Merge(aggFunc field.AggFunc, other *Page) {
+	aggFunc.AggregatePage(p.values, other.values, other.marks)
+	for i, mark := range other.marks {
+		p.marks[i] |= mark
+	}
+}
+
+// decodePage decodes values of tsd decoder in slot range [start, start+page.Len()) into page.
+func decodePage(decoder *encoding.TSDDecoder, start uint16, page *Page) {
+	for i := range page.values {
+		if decoder.HasValueWithSlot(start + uint16(i)) {
+			page.SetValue(i, math.Float64frombits(decoder.Value()))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aggregation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/series/field"
+)
+
+func TestPage(t *testing.T) {
+	page := NewPage(100)
+	assert.Equal(t, 100, page.Len())
+	page.Reset(math.Inf(1))
+	assert.True(t, page.IsEmpty())
+	page.SetValue(70, 10)
+	assert.False(t, page.IsEmpty())
+	assert.True(t, page.HasValue(70))
+	assert.False(t, page.HasValue(69))
+	assert.Equal(t, 10.0, page.Value(70))
+
+	other := NewPage(100)
+	other.Reset(math.Inf(1))
+	other.SetValue(70, 5)
+	other.SetValue(1, 20)
+	page.Merge(field.Min.AggFunc(), other)
+	assert.Equal(t, 5.0, page.Value(70))
+	assert.Equal(t, 20.0, page.Value(1))
+	assert.True(t, page.HasValue(1))
+	assert.Equal(t, math.Inf(1), page.Value(2))
+	assert.False(t, page.HasValue(2))
+}
+
+func TestPage_pool(t *testing.T) {
+	page := getPage(100)
+	assert.Equal(t, 100, page.Len())
+	putPage(page)
+	putPage(nil)
+	page = getPage(200)
+	assert.Equal(t, 200, page.Len())
+	page = getPage(10)
+	assert.Equal(t, 10, page.Len())
+	page.Reset(0)
+	assert.True(t, page.IsEmpty())
+}
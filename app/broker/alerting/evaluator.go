@@ -0,0 +1,321 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package alerting
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/state"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+)
+
+const (
+	alertNameLabel  = "alertname"
+	alertFieldLabel = "field"
+)
+
+var (
+	alertingScope                = linmetric.NewScope("lindb.broker.alerting")
+	evaluationsCounterVec        = alertingScope.NewDeltaCounterVec("evaluations", "rule")
+	evaluationFailuresVec        = alertingScope.NewDeltaCounterVec("evaluation_failures", "rule")
+	firingAlertsGaugeVec         = alertingScope.NewGaugeVec("firing_alerts", "rule")
+	notificationsCounterVec      = alertingScope.NewDeltaCounterVec("notifications", "notifier")
+	notificationFailuresVec      = alertingScope.NewDeltaCounterVec("notification_failures", "notifier")
+	evaluateDurationHistogramVec = alertingScope.Scope("evaluate_duration").NewDeltaHistogramVec("rule").
+					WithExponentBuckets(time.Millisecond, time.Minute, 20)
+)
+
+// alertState represents the state of alert for a series' field of alert rule.
+type alertState struct {
+	alert  *models.Alert
+	firing bool // pending if condition keeps true less than the for duration of rule
+}
+
+// Evaluator evaluates the alert rules stored in state repo every interval if current broker is master,
+// the latest value of each series' field queried by rule's sql is compared with threshold,
+// firing/resolved alerts are sent to notifiers.
+type Evaluator struct {
+	ctx          context.Context
+	cfg          config.Alerting
+	repo         state.Repository
+	master       coordinator.Master
+	queryFactory brokerQuery.Factory
+	notifiers    []Notifier
+	now          func() time.Time
+
+	// rule name => alert key(series' tags and field) => alert state, only accessed by evaluating goroutine
+	states map[string]map[string]*alertState
+
+	logger *logger.Logger
+}
+
+// NewEvaluator creates the alert rule evaluator, notifiers are created by config.
+func NewEvaluator(
+	ctx context.Context,
+	cfg config.Alerting,
+	repo state.Repository,
+	master coordinator.Master,
+	queryFactory brokerQuery.Factory,
+) *Evaluator {
+	var notifiers []Notifier
+	for _, url := range cfg.WebhookURLs {
+		notifiers = append(notifiers, NewWebhookNotifier(url))
+	}
+	if cfg.AlertmanagerURL != "" {
+		notifiers = append(notifiers, NewAlertmanagerNotifier(cfg.AlertmanagerURL))
+	}
+	return &Evaluator{
+		ctx:          ctx,
+		cfg:          cfg,
+		repo:         repo,
+		master:       master,
+		queryFactory: queryFactory,
+		notifiers:    notifiers,
+		now:          time.Now,
+		states:       make(map[string]map[string]*alertState),
+		logger:       logger.GetLogger("broker", "AlertEvaluator"),
+	}
+}
+
+// Run evaluates all alert rules every interval until context is done.
+func (e *Evaluator) Run() {
+	ticker := time.NewTicker(e.cfg.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.evaluateAll()
+		}
+	}
+}
+
+// evaluateAll evaluates all alert rules if current broker is master,
+// states of alerts are dropped when current broker isn't master, new master takes over them.
+func (e *Evaluator) evaluateAll() {
+	if !e.master.IsMaster() {
+		if len(e.states) > 0 {
+			for ruleName := range e.states {
+				firingAlertsGaugeVec.WithTagValues(ruleName).Update(0)
+			}
+			e.states = make(map[string]map[string]*alertState)
+		}
+		return
+	}
+	rules, err := e.listRules()
+	if err != nil {
+		e.logger.Error("list alert rules failure", logger.Error(err))
+		return
+	}
+	ruleNames := make(map[string]struct{})
+	var alerts []*models.Alert
+	for _, rule := range rules {
+		ruleNames[rule.Name] = struct{}{}
+		start := time.Now()
+		evaluationsCounterVec.WithTagValues(rule.Name).Incr()
+		ruleAlerts, err := e.evaluate(rule)
+		if err != nil {
+			evaluationFailuresVec.WithTagValues(rule.Name).Incr()
+			e.logger.Warn("evaluate alert rule failure",
+				logger.String("rule", rule.Name), logger.Error(err))
+			continue
+		}
+		evaluateDurationHistogramVec.WithTagValues(rule.Name).UpdateSince(start)
+		alerts = append(alerts, ruleAlerts...)
+	}
+	// drop states of removed rules
+	for ruleName := range e.states {
+		if _, ok := ruleNames[ruleName]; !ok {
+			firingAlertsGaugeVec.WithTagValues(ruleName).Update(0)
+			delete(e.states, ruleName)
+		}
+	}
+	e.notify(alerts)
+}
+
+// listRules returns all valid alert rules in state repo.
+func (e *Evaluator) listRules() ([]*models.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(e.ctx, e.cfg.Timeout.Duration())
+	defer cancel()
+
+	kvs, err := e.repo.List(ctx, constants.AlertRulePath)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*models.AlertRule
+	for _, kv := range kvs {
+		rule := &models.AlertRule{}
+		if err := encoding.JSONUnmarshal(kv.Value, rule); err != nil {
+			e.logger.Warn("unmarshal alert rule failure",
+				logger.String("key", kv.Key), logger.Error(err))
+			continue
+		}
+		if err := rule.Validate(); err != nil {
+			e.logger.Warn("invalid alert rule",
+				logger.String("key", kv.Key), logger.Error(err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// evaluate queries the series of alert rule, compares the latest value of each series' field with threshold,
+// returns the alerts which become firing or resolved.
+func (e *Evaluator) evaluate(rule *models.AlertRule) ([]*models.Alert, error) {
+	ctx, cancel := context.WithTimeout(e.ctx, e.cfg.Timeout.Duration())
+	defer cancel()
+
+	rs, err := e.queryFactory.NewMetricQuery(ctx, rule.Database, rule.SQL, "").WaitResponse()
+	if err != nil {
+		return nil, err
+	}
+	now := e.now()
+	states, ok := e.states[rule.Name]
+	if !ok {
+		states = make(map[string]*alertState)
+		e.states[rule.Name] = states
+	}
+	var alerts []*models.Alert
+	matched := make(map[string]struct{})
+	if rs != nil {
+		for _, series := range rs.Series {
+			for fieldName, points := range series.Fields {
+				value, ok := latestValue(points)
+				if !ok || !rule.Match(value) {
+					continue
+				}
+				labels := alertLabels(rule, series.Tags, fieldName)
+				key := alertKey(labels)
+				matched[key] = struct{}{}
+				s, ok := states[key]
+				if !ok {
+					s = &alertState{alert: &models.Alert{
+						Status:      models.AlertFiring,
+						Labels:      labels,
+						Annotations: rule.Annotations,
+						StartsAt:    now,
+					}}
+					states[key] = s
+				}
+				s.alert.Value = value
+				if !s.firing && now.Sub(s.alert.StartsAt) >= rule.GetFor() {
+					s.firing = true
+					alerts = append(alerts, s.alert)
+				}
+			}
+		}
+	}
+	firing := 0
+	for key, s := range states {
+		if _, ok := matched[key]; ok {
+			if s.firing {
+				firing++
+			}
+			continue
+		}
+		// condition isn't true any more, resolves firing alert, drops pending alert
+		if s.firing {
+			s.alert.Status = models.AlertResolved
+			s.alert.EndsAt = now
+			alerts = append(alerts, s.alert)
+		}
+		delete(states, key)
+	}
+	firingAlertsGaugeVec.WithTagValues(rule.Name).Update(float64(firing))
+	return alerts, nil
+}
+
+// notify sends the alerts to all notifiers.
+func (e *Evaluator) notify(alerts []*models.Alert) {
+	if len(alerts) == 0 {
+		return
+	}
+	for _, notifier := range e.notifiers {
+		ctx, cancel := context.WithTimeout(e.ctx, e.cfg.Timeout.Duration())
+		err := notifier.Notify(ctx, alerts)
+		cancel()
+		if err != nil {
+			notificationFailuresVec.WithTagValues(notifier.Name()).Incr()
+			e.logger.Error("send alerts failure",
+				logger.String("notifier", notifier.Name()), logger.Error(err))
+			continue
+		}
+		notificationsCounterVec.WithTagValues(notifier.Name()).Add(float64(len(alerts)))
+	}
+}
+
+// latestValue returns the value of latest point.
+func latestValue(points map[int64]float64) (float64, bool) {
+	var (
+		latest int64
+		value  float64
+		found  bool
+	)
+	for timestamp, v := range points {
+		if !found || timestamp > latest {
+			latest = timestamp
+			value = v
+			found = true
+		}
+	}
+	return value, found
+}
+
+// alertLabels builds the labels of alert, includes labels of rule, tags of series, alert name and field name.
+func alertLabels(rule *models.AlertRule, tags map[string]string, fieldName string) map[string]string {
+	labels := make(map[string]string, len(rule.Labels)+len(tags)+2)
+	for k, v := range rule.Labels {
+		labels[k] = v
+	}
+	for k, v := range tags {
+		labels[k] = v
+	}
+	labels[alertNameLabel] = rule.Name
+	labels[alertFieldLabel] = fieldName
+	return labels
+}
+
+// alertKey returns the unique key of alert by sorted labels.
+func alertKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(labels[k])
+		sb.WriteString(",")
+	}
+	return sb.String()
+}
@@ -0,0 +1,215 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/state"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+)
+
+func newTestRuleKV(t *testing.T, rule models.AlertRule) state.KeyValue {
+	data, err := json.Marshal(rule)
+	assert.NoError(t, err)
+	return state.KeyValue{Key: rule.Name, Value: data}
+}
+
+func newTestResultSet(values ...float64) *models.ResultSet {
+	rs := models.NewResultSet()
+	for idx, value := range values {
+		series := models.NewSeries(map[string]string{"host": fmt.Sprintf("h%d", idx)})
+		points := models.NewPoints()
+		points.AddPoint(1, 0)
+		points.AddPoint(2, value)
+		series.AddField("usage", points)
+		rs.AddSeries(series)
+	}
+	return rs
+}
+
+func TestNewEvaluator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	e := NewEvaluator(ctx, config.Alerting{
+		Interval:        ltoml.Duration(time.Millisecond),
+		WebhookURLs:     []string{"http://localhost:8080"},
+		AlertmanagerURL: "http://localhost:9093",
+	}, nil, nil, nil)
+	assert.Len(t, e.notifiers, 2)
+	assert.Equal(t, "webhook", e.notifiers[0].Name())
+	assert.Equal(t, "alertmanager", e.notifiers[1].Name())
+	cancel()
+	// run until context done
+	e.Run()
+}
+
+func TestEvaluator_evaluateAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := state.NewMockRepository(ctrl)
+	master := coordinator.NewMockMaster(ctrl)
+	factory := brokerQuery.NewMockFactory(ctrl)
+	query := brokerQuery.NewMockMetricQuery(ctrl)
+	notifier := NewMockNotifier(ctrl)
+	notifier.EXPECT().Name().Return("mock").AnyTimes()
+	factory.EXPECT().NewMetricQuery(gomock.Any(), "db", "select usage from cpu group by host", "").
+		Return(query).AnyTimes()
+
+	now := time.Now()
+	e := NewEvaluator(context.TODO(), config.Alerting{Timeout: ltoml.Duration(time.Second)}, repo, master, factory)
+	e.notifiers = []Notifier{notifier}
+	e.now = func() time.Time { return now }
+
+	rule := models.AlertRule{
+		Name:      "cpu_high",
+		Database:  "db",
+		SQL:       "select usage from cpu group by host",
+		Operator:  ">",
+		Threshold: 90,
+		Labels:    map[string]string{"severity": "critical"},
+	}
+	rules := []state.KeyValue{
+		newTestRuleKV(t, rule),
+		{Key: "bad-json", Value: []byte("abc")},
+		newTestRuleKV(t, models.AlertRule{Name: "invalid"}),
+	}
+
+	// case 1: not master
+	master.EXPECT().IsMaster().Return(false)
+	e.evaluateAll()
+	master.EXPECT().IsMaster().Return(true).AnyTimes()
+
+	// case 2: list rules failure
+	repo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	e.evaluateAll()
+
+	// case 3: fired immediately
+	repo.EXPECT().List(gomock.Any(), gomock.Any()).Return(rules, nil).AnyTimes()
+	query.EXPECT().WaitResponse().Return(newTestResultSet(95, 50), nil)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, alerts []*models.Alert) error {
+			assert.Len(t, alerts, 1)
+			assert.Equal(t, models.AlertFiring, alerts[0].Status)
+			assert.Equal(t, 95.0, alerts[0].Value)
+			assert.Equal(t, map[string]string{
+				"alertname": "cpu_high", "field": "usage", "host": "h0", "severity": "critical",
+			}, alerts[0].Labels)
+			return nil
+		})
+	e.evaluateAll()
+	assert.Len(t, e.states["cpu_high"], 1)
+
+	// case 4: still firing, no notification
+	query.EXPECT().WaitResponse().Return(newTestResultSet(96, 50), nil)
+	e.evaluateAll()
+
+	// case 5: query failure, keeps alert states
+	query.EXPECT().WaitResponse().Return(nil, fmt.Errorf("err"))
+	e.evaluateAll()
+	assert.Len(t, e.states["cpu_high"], 1)
+
+	// case 6: resolved, notify failure
+	query.EXPECT().WaitResponse().Return(newTestResultSet(50, 50), nil)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, alerts []*models.Alert) error {
+			assert.Len(t, alerts, 1)
+			assert.Equal(t, models.AlertResolved, alerts[0].Status)
+			assert.Equal(t, now, alerts[0].EndsAt)
+			return fmt.Errorf("err")
+		})
+	e.evaluateAll()
+	assert.Empty(t, e.states["cpu_high"])
+
+	// case 7: rule removed, states dropped
+	e.states["removed"] = map[string]*alertState{"key": {}}
+	query.EXPECT().WaitResponse().Return(nil, nil)
+	e.evaluateAll()
+	assert.NotContains(t, e.states, "removed")
+
+	// case 8: not master any more, states dropped
+	e.states["cpu_high"]["key"] = &alertState{}
+	e.master = coordinator.NewMockMaster(ctrl)
+	e.master.(*coordinator.MockMaster).EXPECT().IsMaster().Return(false)
+	e.evaluateAll()
+	assert.Empty(t, e.states)
+}
+
+func TestEvaluator_evaluate_for(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := brokerQuery.NewMockFactory(ctrl)
+	query := brokerQuery.NewMockMetricQuery(ctrl)
+	factory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(query).AnyTimes()
+
+	now := time.Now()
+	e := NewEvaluator(context.TODO(), config.Alerting{Timeout: ltoml.Duration(time.Second)}, nil, nil, factory)
+	e.now = func() time.Time { return now }
+	rule := &models.AlertRule{Name: "disk_low", Operator: "<", Threshold: 10, For: "5m"}
+
+	// pending
+	query.EXPECT().WaitResponse().Return(newTestResultSet(5), nil)
+	alerts, err := e.evaluate(rule)
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+	startsAt := now
+	// still pending
+	now = now.Add(time.Minute)
+	query.EXPECT().WaitResponse().Return(newTestResultSet(6), nil)
+	alerts, err = e.evaluate(rule)
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+	// firing after for duration
+	now = now.Add(4 * time.Minute)
+	query.EXPECT().WaitResponse().Return(newTestResultSet(7), nil)
+	alerts, err = e.evaluate(rule)
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, startsAt, alerts[0].StartsAt)
+	assert.Equal(t, 7.0, alerts[0].Value)
+
+	// pending alert is dropped without notification
+	delete(e.states, rule.Name)
+	query.EXPECT().WaitResponse().Return(newTestResultSet(5), nil)
+	_, _ = e.evaluate(rule)
+	query.EXPECT().WaitResponse().Return(newTestResultSet(50), nil)
+	alerts, err = e.evaluate(rule)
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+	assert.Empty(t, e.states[rule.Name])
+}
+
+func Test_latestValue(t *testing.T) {
+	_, ok := latestValue(nil)
+	assert.False(t, ok)
+	value, ok := latestValue(map[int64]float64{3: 30, 1: 10, 2: 20})
+	assert.True(t, ok)
+	assert.Equal(t, 30.0, value)
+}
@@ -0,0 +1,140 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lindb/lindb/models"
+)
+
+//go:generate mockgen -source=./notifier.go -destination=./notifier_mock.go -package=alerting
+
+// alertmanagerAlertsPath represents the path of alertmanager's api for posting alerts.
+const alertmanagerAlertsPath = "/api/v2/alerts"
+
+// Notifier represents the sender of alert notifications.
+type Notifier interface {
+	// Name returns the name of notifier.
+	Name() string
+	// Notify sends the firing/resolved alerts.
+	Notify(ctx context.Context, alerts []*models.Alert) error
+}
+
+// webhookNotifier posts the alerts to webhook as json array.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates the notifier which posts alerts to webhook url.
+func NewWebhookNotifier(url string) Notifier {
+	return &webhookNotifier{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+// Name returns the name of notifier.
+func (n *webhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the alerts to webhook.
+func (n *webhookNotifier) Notify(ctx context.Context, alerts []*models.Alert) error {
+	return post(ctx, n.client, n.url, alerts)
+}
+
+// alertmanagerAlert is the json mapping of postable alert of alertmanager api v2.
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    string            `json:"startsAt,omitempty"`
+	EndsAt      string            `json:"endsAt,omitempty"`
+}
+
+// alertmanagerNotifier posts the alerts to alertmanager by api v2,
+// alertmanager takes care of grouping, deduplication, silencing and routing.
+type alertmanagerNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewAlertmanagerNotifier creates the notifier which posts alerts to alertmanager, url like http://localhost:9093.
+func NewAlertmanagerNotifier(url string) Notifier {
+	return &alertmanagerNotifier{
+		url:    strings.TrimSuffix(url, "/") + alertmanagerAlertsPath,
+		client: &http.Client{},
+	}
+}
+
+// Name returns the name of notifier.
+func (n *alertmanagerNotifier) Name() string {
+	return "alertmanager"
+}
+
+// Notify posts the alerts to alertmanager, value of alert is added into annotations,
+// resolved alert is posted with ends time.
+func (n *alertmanagerNotifier) Notify(ctx context.Context, alerts []*models.Alert) error {
+	postableAlerts := make([]alertmanagerAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		annotations := map[string]string{"value": strconv.FormatFloat(alert.Value, 'f', -1, 64)}
+		for k, v := range alert.Annotations {
+			annotations[k] = v
+		}
+		postableAlert := alertmanagerAlert{
+			Labels:      alert.Labels,
+			Annotations: annotations,
+			StartsAt:    alert.StartsAt.Format(time.RFC3339Nano),
+		}
+		if alert.Status == models.AlertResolved {
+			postableAlert.EndsAt = alert.EndsAt.Format(time.RFC3339Nano)
+		}
+		postableAlerts = append(postableAlerts, postableAlert)
+	}
+	return post(ctx, n.client, n.url, postableAlerts)
+}
+
+// post posts the alerts to url as json, returns error if response status isn't 2xx.
+func post(ctx context.Context, client *http.Client, url string, alerts interface{}) error {
+	data, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("post alerts to %s failure, status: %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan []*models.Alert, 1)
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(r.Body)
+		var alerts []*models.Alert
+		assert.NoError(t, json.Unmarshal(body, &alerts))
+		w.WriteHeader(status)
+		received <- alerts
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	alerts := []*models.Alert{{Status: models.AlertFiring, Labels: map[string]string{"alertname": "cpu_high"}, Value: 95}}
+	assert.NoError(t, n.Notify(context.TODO(), alerts))
+	result := <-received
+	assert.Len(t, result, 1)
+	assert.Equal(t, models.AlertFiring, result[0].Status)
+	assert.Equal(t, 95.0, result[0].Value)
+	assert.Equal(t, "cpu_high", result[0].Labels["alertname"])
+
+	// bad status
+	status = http.StatusInternalServerError
+	assert.Error(t, n.Notify(context.TODO(), alerts))
+	<-received
+	// bad url
+	assert.Error(t, NewWebhookNotifier("http://127.0.0.1:1").Notify(context.TODO(), alerts))
+	assert.Error(t, NewWebhookNotifier(":bad url").Notify(context.TODO(), alerts))
+}
+
+func TestAlertmanagerNotifier(t *testing.T) {
+	received := make(chan []alertmanagerAlert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, alertmanagerAlertsPath, r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		var alerts []alertmanagerAlert
+		assert.NoError(t, json.Unmarshal(body, &alerts))
+		received <- alerts
+	}))
+	defer server.Close()
+
+	startsAt := time.Date(2021, 4, 10, 8, 0, 0, 0, time.UTC)
+	n := NewAlertmanagerNotifier(server.URL + "/")
+	assert.NoError(t, n.Notify(context.TODO(), []*models.Alert{
+		{
+			Status:      models.AlertFiring,
+			Labels:      map[string]string{"alertname": "cpu_high"},
+			Annotations: map[string]string{"summary": "cpu usage is high"},
+			Value:       95.5,
+			StartsAt:    startsAt,
+		},
+		{
+			Status:   models.AlertResolved,
+			Labels:   map[string]string{"alertname": "disk_low"},
+			Value:    1,
+			StartsAt: startsAt,
+			EndsAt:   startsAt.Add(time.Hour),
+		},
+	}))
+	alerts := <-received
+	assert.Equal(t, []alertmanagerAlert{
+		{
+			Labels:      map[string]string{"alertname": "cpu_high"},
+			Annotations: map[string]string{"summary": "cpu usage is high", "value": "95.5"},
+			StartsAt:    "2021-04-10T08:00:00Z",
+		},
+		{
+			Labels:      map[string]string{"alertname": "disk_low"},
+			Annotations: map[string]string{"value": "1"},
+			StartsAt:    "2021-04-10T08:00:00Z",
+			EndsAt:      "2021-04-10T09:00:00Z",
+		},
+	}, alerts)
+}
@@ -0,0 +1,167 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/sql"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+var (
+	AlertRulePath     = "/alert/rule"
+	ListAlertRulePath = "/alert/rule/list"
+)
+
+// AlertRuleAPI represents alert rule admin rest api
+type AlertRuleAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewAlertRuleAPI creates alert rule api instance
+func NewAlertRuleAPI(deps *deps.HTTPDeps) *AlertRuleAPI {
+	return &AlertRuleAPI{
+		deps: deps,
+	}
+}
+
+// Register adds alert rule admin url route.
+func (ar *AlertRuleAPI) Register(route gin.IRoutes) {
+	route.POST(AlertRulePath, ar.Save)
+	route.GET(AlertRulePath, ar.GetByName)
+	route.DELETE(AlertRulePath, ar.Delete)
+	route.GET(ListAlertRulePath, ar.List)
+}
+
+// GetByName gets an alert rule by the name.
+func (ar *AlertRuleAPI) GetByName(c *gin.Context) {
+	var param struct {
+		Name string `form:"name" binding:"required"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	ctx, cancel := ar.deps.WithTimeout()
+	defer cancel()
+
+	data, err := ar.deps.Repo.Get(ctx, constants.GetAlertRulePath(param.Name))
+	if err != nil {
+		if err == state.ErrNoKey {
+			http.NotFound(c)
+			return
+		}
+		http.Error(c, err)
+		return
+	}
+	rule := &models.AlertRule{}
+	if err := encoding.JSONUnmarshal(data, rule); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.OK(c, rule)
+}
+
+// Save creates the alert rule if there is no rule with the name, otherwise updates the rule,
+// rule is evaluated by master broker in next evaluation.
+func (ar *AlertRuleAPI) Save(c *gin.Context) {
+	rule := &models.AlertRule{}
+	if err := c.ShouldBind(rule); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if err := rule.Validate(); err != nil {
+		http.Error(c, err)
+		return
+	}
+	// rule's sql must be data query
+	statement, err := sql.Parse(rule.SQL)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	if _, ok := statement.(*stmt.Query); !ok {
+		http.Error(c, fmt.Errorf("sql of alert rule must be select statement"))
+		return
+	}
+	data, err := json.Marshal(rule)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	ctx, cancel := ar.deps.WithTimeout()
+	defer cancel()
+	if err := ar.deps.Repo.Put(ctx, constants.GetAlertRulePath(rule.Name), data); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
+
+// Delete removes the alert rule by the name.
+func (ar *AlertRuleAPI) Delete(c *gin.Context) {
+	var param struct {
+		Name string `form:"name" binding:"required"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	ctx, cancel := ar.deps.WithTimeout()
+	defer cancel()
+	if err := ar.deps.Repo.Delete(ctx, constants.GetAlertRulePath(param.Name)); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
+
+// List returns all alert rules.
+func (ar *AlertRuleAPI) List(c *gin.Context) {
+	ctx, cancel := ar.deps.WithTimeout()
+	defer cancel()
+
+	kvs, err := ar.deps.Repo.List(ctx, constants.AlertRulePath)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	rules := make([]*models.AlertRule, 0, len(kvs))
+	for _, kv := range kvs {
+		rule := &models.AlertRule{}
+		if err := encoding.JSONUnmarshal(kv.Value, rule); err != nil {
+			logger.GetLogger("broker", "AlertRuleAPI").
+				Warn("unmarshal alert rule error",
+					logger.String("data", string(kv.Value)))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	http.OK(c, rules)
+}
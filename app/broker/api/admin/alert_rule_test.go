@@ -0,0 +1,146 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/state"
+)
+
+const testAlertRule = `{"name":"cpu_high","database":"db","sql":"select usage from cpu","operator":">","threshold":90}`
+
+func newTestAlertRuleAPI(ctrl *gomock.Controller) (*gin.Engine, *state.MockRepository) {
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewAlertRuleAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+	return r, repo
+}
+
+func TestAlertRuleAPI_Save(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, repo := newTestAlertRuleAPI(ctrl)
+	// bind error
+	resp := mock.DoRequest(t, r, http.MethodPost, AlertRulePath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// validate error
+	resp = mock.DoRequest(t, r, http.MethodPost, AlertRulePath,
+		`{"name":"cpu_high","database":"db","sql":"select usage from cpu","operator":"=>"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// bad sql
+	resp = mock.DoRequest(t, r, http.MethodPost, AlertRulePath,
+		`{"name":"cpu_high","database":"db","sql":"select from cpu","operator":">"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// not data query
+	resp = mock.DoRequest(t, r, http.MethodPost, AlertRulePath,
+		`{"name":"cpu_high","database":"db","sql":"show databases","operator":">"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// put error
+	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPost, AlertRulePath, testAlertRule)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// put ok
+	repo.EXPECT().Put(gomock.Any(), "/alert/rule/cpu_high", gomock.Any()).Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodPost, AlertRulePath,
+		`{"name":"cpu_high","database":"db","sql":"select usage from cpu","operator":">","threshold":90,`+
+			`"for":"5m","labels":{"severity":"critical"}}`)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestAlertRuleAPI_GetByName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, repo := newTestAlertRuleAPI(ctrl)
+	// param error
+	resp := mock.DoRequest(t, r, http.MethodGet, AlertRulePath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// not found
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, state.ErrNoKey)
+	resp = mock.DoRequest(t, r, http.MethodGet, AlertRulePath+"?name=cpu_high", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	// get error
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, AlertRulePath+"?name=cpu_high", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// unmarshal error
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte("abc"), nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, AlertRulePath+"?name=cpu_high", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// ok
+	repo.EXPECT().Get(gomock.Any(), "/alert/rule/cpu_high").Return([]byte(testAlertRule), nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, AlertRulePath+"?name=cpu_high", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAlertRuleAPI_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, repo := newTestAlertRuleAPI(ctrl)
+	// param error
+	resp := mock.DoRequest(t, r, http.MethodDelete, AlertRulePath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// delete error
+	repo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodDelete, AlertRulePath+"?name=cpu_high", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// ok
+	repo.EXPECT().Delete(gomock.Any(), "/alert/rule/cpu_high").Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodDelete, AlertRulePath+"?name=cpu_high", "")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestAlertRuleAPI_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, repo := newTestAlertRuleAPI(ctrl)
+	// list error
+	repo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	resp := mock.DoRequest(t, r, http.MethodGet, ListAlertRulePath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// ok, bad rule skipped
+	repo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]state.KeyValue{
+		{Key: "cpu_high", Value: []byte(testAlertRule)},
+		{Key: "bad", Value: []byte("abc")},
+	}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, ListAlertRulePath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"name":"cpu_high"`)
+	assert.NotContains(t, resp.Body.String(), "bad")
+}
@@ -0,0 +1,85 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/pkg/http"
+)
+
+var (
+	AuditLogPath = "/audit/log"
+)
+
+const (
+	defaultAuditLogPageSize = 20
+	maxAuditLogPageSize     = 1000
+)
+
+// AuditLogAPI represents audit log admin rest api
+type AuditLogAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewAuditLogAPI creates audit log api instance
+func NewAuditLogAPI(deps *deps.HTTPDeps) *AuditLogAPI {
+	return &AuditLogAPI{
+		deps: deps,
+	}
+}
+
+// Register adds audit log admin url route.
+func (al *AuditLogAPI) Register(route gin.IRoutes) {
+	route.GET(AuditLogPath, al.List)
+}
+
+// List returns a page of audit log entries which id < before, newest entry first,
+// the next page can be fetched with the "next" id of response as before param.
+func (al *AuditLogAPI) List(c *gin.Context) {
+	var param struct {
+		Before int64 `form:"before"`
+		Limit  int   `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if al.deps.AuditLog == nil {
+		http.Error(c, fmt.Errorf("audit log is disabled"))
+		return
+	}
+	if param.Limit <= 0 {
+		param.Limit = defaultAuditLogPageSize
+	}
+	if param.Limit > maxAuditLogPageSize {
+		param.Limit = maxAuditLogPageSize
+	}
+	ctx, cancel := al.deps.WithTimeout()
+	defer cancel()
+
+	page, err := al.deps.AuditLog.List(ctx, param.Before, param.Limit)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.OK(c, page)
+}
@@ -0,0 +1,69 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/audit"
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+func TestAuditLogAPI_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditLog := audit.NewMockLog(ctrl)
+	httpDeps := &deps.HTTPDeps{
+		Ctx:       context.Background(),
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	}
+	r := gin.New()
+	NewAuditLogAPI(httpDeps).Register(r)
+
+	// audit log disabled
+	resp := mock.DoRequest(t, r, http.MethodGet, AuditLogPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	httpDeps.AuditLog = auditLog
+	// bad param
+	resp = mock.DoRequest(t, r, http.MethodGet, AuditLogPath+"?before=abc", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// list failure
+	auditLog.EXPECT().List(gomock.Any(), int64(0), defaultAuditLogPageSize).Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, AuditLogPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// list ok
+	auditLog.EXPECT().List(gomock.Any(), int64(10), maxAuditLogPageSize).
+		Return(&models.AuditLogPage{Entries: []*models.AuditEntry{{ID: 9}}, Next: 9}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, AuditLogPath+"?before=10&limit=10000", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"next":9`)
+}
@@ -0,0 +1,108 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/state"
+)
+
+var (
+	ClusterConfigPath = "/cluster/config"
+)
+
+// ClusterConfigAPI represents cluster-wide operational config admin rest api
+type ClusterConfigAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewClusterConfigAPI creates cluster config api instance
+func NewClusterConfigAPI(deps *deps.HTTPDeps) *ClusterConfigAPI {
+	return &ClusterConfigAPI{
+		deps: deps,
+	}
+}
+
+// Register adds cluster config admin url route.
+func (cc *ClusterConfigAPI) Register(route gin.IRoutes) {
+	route.POST(ClusterConfigPath, cc.Save)
+	route.GET(ClusterConfigPath, cc.Get)
+	route.DELETE(ClusterConfigPath, cc.Reset)
+}
+
+// Get returns the cluster-wide config, if not set returns empty config.
+func (cc *ClusterConfigAPI) Get(c *gin.Context) {
+	ctx, cancel := cc.deps.WithTimeout()
+	defer cancel()
+
+	data, err := cc.deps.Repo.Get(ctx, constants.ClusterConfigPath)
+	if err == state.ErrNoKey {
+		http.OK(c, models.ClusterConfig{})
+		return
+	}
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	cfg := models.ClusterConfig{}
+	if err := encoding.JSONUnmarshal(data, &cfg); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.OK(c, cfg)
+}
+
+// Save creates or updates the cluster-wide config,
+// all brokers/storages will be notified by cluster config state machine.
+func (cc *ClusterConfigAPI) Save(c *gin.Context) {
+	cfg := models.ClusterConfig{}
+	if err := c.ShouldBind(&cfg); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		http.Error(c, err)
+		return
+	}
+	data := encoding.JSONMarshal(&cfg)
+
+	ctx, cancel := cc.deps.WithTimeout()
+	defer cancel()
+	if err := cc.deps.Repo.Put(ctx, constants.ClusterConfigPath, data); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
+
+// Reset deletes the cluster-wide config, all brokers/storages will use their local config.
+func (cc *ClusterConfigAPI) Reset(c *gin.Context) {
+	ctx, cancel := cc.deps.WithTimeout()
+	defer cancel()
+	if err := cc.deps.Repo.Delete(ctx, constants.ClusterConfigPath); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
@@ -0,0 +1,121 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/state"
+)
+
+func TestClusterConfigAPI_Save(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewClusterConfigAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	// bind error
+	resp := mock.DoRequest(t, r, http.MethodPost, ClusterConfigPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// validate error
+	resp = mock.DoRequest(t, r, http.MethodPost, ClusterConfigPath, `{"queryTimeout":"abc"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	cfg := models.ClusterConfig{QueryTimeout: "30s", MaxSeriesPerMetric: 1000}
+	// put error
+	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPost, ClusterConfigPath, string(encoding.JSONMarshal(&cfg)))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// put ok
+	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodPost, ClusterConfigPath, string(encoding.JSONMarshal(&cfg)))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestClusterConfigAPI_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewClusterConfigAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	// not set
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, state.ErrNoKey)
+	resp := mock.DoRequest(t, r, http.MethodGet, ClusterConfigPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	// get error
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, ClusterConfigPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// bad data
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte("bad-data"), nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, ClusterConfigPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// get ok
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte(`{"queryTimeout":"30s"}`), nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, ClusterConfigPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestClusterConfigAPI_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewClusterConfigAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	repo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp := mock.DoRequest(t, r, http.MethodDelete, ClusterConfigPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	repo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodDelete, ClusterConfigPath, "")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
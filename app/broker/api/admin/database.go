@@ -18,6 +18,7 @@
 package admin
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
@@ -28,6 +29,7 @@ import (
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/http"
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/state"
 )
 
 var (
@@ -35,6 +37,9 @@ var (
 	ListDatabasePath = "/database/list"
 )
 
+// ErrDatabaseRevisionConflict represents database config is modified by others after the revision read by client.
+var ErrDatabaseRevisionConflict = errors.New("database config revision conflict, please reload and retry")
+
 // DatabaseAPI represents database admin rest api
 type DatabaseAPI struct {
 	deps *deps.HTTPDeps
@@ -50,6 +55,7 @@ func NewDatabaseAPI(deps *deps.HTTPDeps) *DatabaseAPI {
 // Register adds database admin url route.
 func (d *DatabaseAPI) Register(route gin.IRoutes) {
 	route.POST(DatabasePath, d.Save)
+	route.PUT(DatabasePath, d.Update)
 	route.GET(DatabasePath, d.GetByName)
 	route.GET(ListDatabasePath, d.List)
 }
@@ -76,7 +82,7 @@ func (d *DatabaseAPI) getByName(name string) (*models.Database, error) {
 	ctx, cancel := d.deps.WithTimeout()
 	defer cancel()
 
-	configBytes, err := d.deps.Repo.Get(ctx, constants.GetDatabaseConfigPath(name))
+	configBytes, rev, err := d.deps.Repo.GetWithRevision(ctx, constants.GetDatabaseConfigPath(name))
 	if err != nil {
 		return nil, err
 	}
@@ -85,11 +91,14 @@ func (d *DatabaseAPI) getByName(name string) (*models.Database, error) {
 	if err != nil {
 		return nil, err
 	}
+	database.Revision = rev
 	return database, nil
 }
 
 // Save creates the database config if there is no database
-// config with the name database.Name, otherwise update the config
+// config with the name database.Name, otherwise update the config.
+// Save is compare-and-swap on the revision of config, if config is modified by others
+// after the revision read by client, responses conflict.
 func (d *DatabaseAPI) Save(c *gin.Context) {
 	database := &models.Database{}
 	if err := c.ShouldBind(&database); err != nil {
@@ -97,6 +106,47 @@ func (d *DatabaseAPI) Save(c *gin.Context) {
 		return
 	}
 	if err := d.saveDataBase(database); err != nil {
+		if errors.Is(err, ErrDatabaseRevisionConflict) {
+			http.Conflict(c, err)
+			return
+		}
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
+
+// Update modifies the config of an existing database, such as replica factor/rollup/behind/ahead,
+// master will apply new config to storage shards by coordinator task, no need to recreate database.
+// Storage cluster and write interval cannot be changed, num. of shard and replica factor cannot be reduced.
+// Update is compare-and-swap on the revision of config, uses the current revision if not set by client.
+func (d *DatabaseAPI) Update(c *gin.Context) {
+	database := &models.Database{}
+	if err := c.ShouldBind(&database); err != nil {
+		http.Error(c, err)
+		return
+	}
+	old, err := d.getByName(database.Name)
+	if err != nil {
+		if errors.Is(err, state.ErrNotExist) {
+			http.NotFound(c)
+			return
+		}
+		http.Error(c, err)
+		return
+	}
+	if err := database.ValidateUpdate(*old); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if database.Revision == 0 {
+		database.Revision = old.Revision
+	}
+	if err := d.saveDataBase(database); err != nil {
+		if errors.Is(err, ErrDatabaseRevisionConflict) {
+			http.Conflict(c, err)
+			return
+		}
 		http.Error(c, err)
 		return
 	}
@@ -117,11 +167,33 @@ func (d *DatabaseAPI) saveDataBase(database *models.Database) error {
 	if err := database.Option.Validate(); err != nil {
 		return err
 	}
+	rev := database.Revision
+	// revision is maintained by state repo, don't store it in config
+	database.Revision = 0
 	data := encoding.JSONMarshal(database)
 
 	ctx, cancel := d.deps.WithTimeout()
 	defer cancel()
-	return d.deps.Repo.Put(ctx, constants.GetDatabaseConfigPath(database.Name), data)
+	key := constants.GetDatabaseConfigPath(database.Name)
+	// if key not exist, modify revision is 0
+	txn := d.deps.Repo.NewTransaction()
+	txn.ModRevisionCmp(key, "=", rev)
+	txn.Put(key, data)
+	if err := d.deps.Repo.Commit(ctx, txn); err != nil {
+		if errors.Is(err, state.ErrTxnFailed) {
+			return ErrDatabaseRevisionConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteDatabase removes the database config from state repo.
+func (d *DatabaseAPI) deleteDatabase(name string) error {
+	ctx, cancel := d.deps.WithTimeout()
+	defer cancel()
+
+	return d.deps.Repo.Delete(ctx, constants.GetDatabaseConfigPath(name))
 }
 
 // List returns all database configs
@@ -27,6 +27,7 @@ import (
 
 	"github.com/lindb/lindb/app/broker/deps"
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/internal/mock"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
@@ -84,10 +85,90 @@ func TestDatabaseAPI_Save(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, reps.Code)
 	// put
 	database.Option = option.DatabaseOption{Interval: "10s"}
+	database.Revision = 10
 	data = encoding.JSONMarshal(&database)
-	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	txn := state.NewMockTransaction(ctrl)
+	repo.EXPECT().NewTransaction().Return(txn).AnyTimes()
+	txn.EXPECT().ModRevisionCmp(constants.GetDatabaseConfigPath("test"), "=", int64(10)).AnyTimes()
+	txn.EXPECT().Put(constants.GetDatabaseConfigPath("test"), gomock.Any()).
+		DoAndReturn(func(_ string, value []byte) {
+			saved := &models.Database{}
+			_ = encoding.JSONUnmarshal(value, saved)
+			// revision not stored in config
+			assert.Zero(t, saved.Revision)
+		}).AnyTimes()
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(nil)
 	reps = mock.DoRequest(t, r, http.MethodPost, DatabasePath, string(data))
 	assert.Equal(t, http.StatusNoContent, reps.Code)
+	// revision conflict
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(state.ErrTxnFailed)
+	reps = mock.DoRequest(t, r, http.MethodPost, DatabasePath, string(data))
+	assert.Equal(t, http.StatusConflict, reps.Code)
+	// commit err
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(io.ErrClosedPipe)
+	reps = mock.DoRequest(t, r, http.MethodPost, DatabasePath, string(data))
+	assert.Equal(t, http.StatusInternalServerError, reps.Code)
+}
+
+func TestDatabaseAPI_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewDatabaseAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	old := models.Database{
+		Name:          "test",
+		Cluster:       "cluster-test",
+		NumOfShard:    12,
+		ReplicaFactor: 2,
+		Option:        option.DatabaseOption{Interval: "10s"},
+	}
+	database := old
+	database.ReplicaFactor = 3
+	database.Option.Ahead = "1h"
+	database.Option.Rollup = []string{"5m"}
+	data := string(encoding.JSONMarshal(&database))
+
+	// bind error
+	reps := mock.DoRequest(t, r, http.MethodPut, DatabasePath, "")
+	assert.Equal(t, http.StatusInternalServerError, reps.Code)
+	// database not exist
+	repo.EXPECT().GetWithRevision(gomock.Any(), constants.GetDatabaseConfigPath("test")).Return(nil, int64(0), state.ErrNotExist)
+	reps = mock.DoRequest(t, r, http.MethodPut, DatabasePath, data)
+	assert.Equal(t, http.StatusNotFound, reps.Code)
+	// get database err
+	repo.EXPECT().GetWithRevision(gomock.Any(), gomock.Any()).Return(nil, int64(0), io.ErrClosedPipe)
+	reps = mock.DoRequest(t, r, http.MethodPut, DatabasePath, data)
+	assert.Equal(t, http.StatusInternalServerError, reps.Code)
+	// interval cannot be changed
+	repo.EXPECT().GetWithRevision(gomock.Any(), gomock.Any()).Return(encoding.JSONMarshal(&old), int64(5), nil).AnyTimes()
+	badDatabase := database
+	badDatabase.Option.Interval = "1m"
+	reps = mock.DoRequest(t, r, http.MethodPut, DatabasePath, string(encoding.JSONMarshal(&badDatabase)))
+	assert.Equal(t, http.StatusInternalServerError, reps.Code)
+	// update with current revision
+	txn := state.NewMockTransaction(ctrl)
+	repo.EXPECT().NewTransaction().Return(txn).AnyTimes()
+	txn.EXPECT().ModRevisionCmp(constants.GetDatabaseConfigPath("test"), "=", int64(5)).AnyTimes()
+	txn.EXPECT().Put(constants.GetDatabaseConfigPath("test"), gomock.Any()).AnyTimes()
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(nil)
+	reps = mock.DoRequest(t, r, http.MethodPut, DatabasePath, data)
+	assert.Equal(t, http.StatusNoContent, reps.Code)
+	// revision conflict
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(state.ErrTxnFailed)
+	reps = mock.DoRequest(t, r, http.MethodPut, DatabasePath, data)
+	assert.Equal(t, http.StatusConflict, reps.Code)
+	// commit err
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(io.ErrClosedPipe)
+	reps = mock.DoRequest(t, r, http.MethodPut, DatabasePath, data)
+	assert.Equal(t, http.StatusInternalServerError, reps.Code)
 }
 
 func TestDatabaseAPI_GetByName(t *testing.T) {
@@ -111,19 +192,22 @@ func TestDatabaseAPI_GetByName(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, reps.Code)
 
 	// get bad content
-	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte("bad-data"), nil)
+	repo.EXPECT().GetWithRevision(gomock.Any(), gomock.Any()).Return([]byte("bad-data"), int64(0), nil)
 	reps = mock.DoRequest(t, r, http.MethodGet, DatabasePath+"?name=xxx", "")
 	assert.Equal(t, http.StatusNotFound, reps.Code)
 
 	// get error
-	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, io.ErrClosedPipe)
+	repo.EXPECT().GetWithRevision(gomock.Any(), gomock.Any()).Return(nil, int64(0), io.ErrClosedPipe)
 	reps = mock.DoRequest(t, r, http.MethodGet, DatabasePath+"?name=xxx", "")
 	assert.Equal(t, http.StatusNotFound, reps.Code)
 
 	// get ok
-	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte(`{"name":"xxx"}`), nil)
+	repo.EXPECT().GetWithRevision(gomock.Any(), gomock.Any()).Return([]byte(`{"name":"xxx"}`), int64(10), nil)
 	reps = mock.DoRequest(t, r, http.MethodGet, DatabasePath+"?name=xxx", "")
 	assert.Equal(t, http.StatusOK, reps.Code)
+	db := &models.Database{}
+	_ = encoding.JSONUnmarshal(reps.Body.Bytes(), db)
+	assert.Equal(t, int64(10), db.Revision)
 
 }
 
@@ -0,0 +1,108 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/state"
+)
+
+var (
+	QueryDefaultsPath = "/query/defaults"
+)
+
+// QueryDefaultsAPI represents cluster-wide query defaults admin rest api
+type QueryDefaultsAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewQueryDefaultsAPI creates query defaults api instance
+func NewQueryDefaultsAPI(deps *deps.HTTPDeps) *QueryDefaultsAPI {
+	return &QueryDefaultsAPI{
+		deps: deps,
+	}
+}
+
+// Register adds query defaults admin url route.
+func (q *QueryDefaultsAPI) Register(route gin.IRoutes) {
+	route.POST(QueryDefaultsPath, q.Save)
+	route.GET(QueryDefaultsPath, q.Get)
+	route.DELETE(QueryDefaultsPath, q.Reset)
+}
+
+// Get returns the cluster-wide query defaults, if not set returns the built-in defaults.
+func (q *QueryDefaultsAPI) Get(c *gin.Context) {
+	ctx, cancel := q.deps.WithTimeout()
+	defer cancel()
+
+	data, err := q.deps.Repo.Get(ctx, constants.QueryDefaultsConfigPath)
+	if err == state.ErrNoKey {
+		http.OK(c, models.NewDefaultQueryDefaults())
+		return
+	}
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	defaults := models.QueryDefaults{}
+	if err := encoding.JSONUnmarshal(data, &defaults); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.OK(c, defaults)
+}
+
+// Save creates or updates the cluster-wide query defaults,
+// all brokers will be notified by query defaults state machine.
+func (q *QueryDefaultsAPI) Save(c *gin.Context) {
+	defaults := models.QueryDefaults{}
+	if err := c.ShouldBind(&defaults); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if err := defaults.Validate(); err != nil {
+		http.Error(c, err)
+		return
+	}
+	data := encoding.JSONMarshal(&defaults)
+
+	ctx, cancel := q.deps.WithTimeout()
+	defer cancel()
+	if err := q.deps.Repo.Put(ctx, constants.QueryDefaultsConfigPath, data); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
+
+// Reset deletes the cluster-wide query defaults, all brokers will use the built-in defaults.
+func (q *QueryDefaultsAPI) Reset(c *gin.Context) {
+	ctx, cancel := q.deps.WithTimeout()
+	defer cancel()
+	if err := q.deps.Repo.Delete(ctx, constants.QueryDefaultsConfigPath); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
@@ -0,0 +1,121 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/state"
+)
+
+func TestQueryDefaultsAPI_Save(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewQueryDefaultsAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	// bind error
+	resp := mock.DoRequest(t, r, http.MethodPost, QueryDefaultsPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// validate error
+	resp = mock.DoRequest(t, r, http.MethodPost, QueryDefaultsPath, `{"timeRange":"abc"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	defaults := models.QueryDefaults{TimeRange: "1d", MaxPoints: 1000, Timezone: "UTC"}
+	// put error
+	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPost, QueryDefaultsPath, string(encoding.JSONMarshal(&defaults)))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// put ok
+	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodPost, QueryDefaultsPath, string(encoding.JSONMarshal(&defaults)))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestQueryDefaultsAPI_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewQueryDefaultsAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	// not set
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, state.ErrNoKey)
+	resp := mock.DoRequest(t, r, http.MethodGet, QueryDefaultsPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	// get error
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, QueryDefaultsPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// bad data
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte("bad-data"), nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, QueryDefaultsPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// get ok
+	repo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte(`{"timeRange":"1d"}`), nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, QueryDefaultsPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestQueryDefaultsAPI_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewQueryDefaultsAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	repo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp := mock.DoRequest(t, r, http.MethodDelete, QueryDefaultsPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	repo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodDelete, QueryDefaultsPath, "")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
@@ -0,0 +1,178 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	httppkg "github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+var (
+	// for testing
+	httpClient = http.DefaultClient
+	// ReplicaVerifyPath represents replica verify api path.
+	ReplicaVerifyPath = "/database/replica/verify"
+	// ReplicaRepairPath represents replica repair api path.
+	ReplicaRepairPath = "/database/replica/repair"
+)
+
+// ReplicaVerifyAPI represents the api which verifies the data consistency between replicas of database,
+// storage nodes compute family checksums of shards, master compares them and reports the divergences.
+type ReplicaVerifyAPI struct {
+	deps *deps.HTTPDeps
+
+	logger *logger.Logger
+}
+
+// NewReplicaVerifyAPI creates replica verify api.
+func NewReplicaVerifyAPI(deps *deps.HTTPDeps) *ReplicaVerifyAPI {
+	return &ReplicaVerifyAPI{
+		deps:   deps,
+		logger: logger.GetLogger("broker", "ReplicaVerifyAPI"),
+	}
+}
+
+// Register adds replica verify admin url route.
+func (api *ReplicaVerifyAPI) Register(route gin.IRoutes) {
+	route.PUT(ReplicaVerifyPath, api.Verify)
+	route.GET(ReplicaVerifyPath, api.GetReport)
+	route.PUT(ReplicaRepairPath, api.Repair)
+}
+
+// Verify submits the replica verify task, returns the task name,
+// verifies the data of last day if time range not set.
+func (api *ReplicaVerifyAPI) Verify(c *gin.Context) {
+	if !api.deps.Master.IsMaster() {
+		api.forwardToMaster(c)
+		return
+	}
+	var param struct {
+		Cluster   string `json:"cluster" binding:"required"`
+		Database  string `json:"database" binding:"required"`
+		StartTime int64  `json:"startTime"`
+		EndTime   int64  `json:"endTime"`
+	}
+	if err := c.ShouldBind(&param); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	timeRange := timeutil.TimeRange{Start: param.StartTime, End: param.EndTime}
+	if timeRange.End <= 0 {
+		timeRange.End = timeutil.Now()
+	}
+	if timeRange.Start <= 0 {
+		timeRange.Start = timeRange.End - timeutil.OneDay
+	}
+	if timeRange.Start > timeRange.End {
+		httppkg.Error(c, fmt.Errorf("start time is after end time"))
+		return
+	}
+	name, err := api.deps.Master.VerifyReplicas(param.Cluster, param.Database, timeRange)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	httppkg.OK(c, gin.H{"name": name})
+}
+
+// GetReport returns the divergence report of replica verify task.
+func (api *ReplicaVerifyAPI) GetReport(c *gin.Context) {
+	if !api.deps.Master.IsMaster() {
+		api.forwardToMaster(c)
+		return
+	}
+	var param struct {
+		Cluster  string `form:"cluster" binding:"required"`
+		Database string `form:"database" binding:"required"`
+		Name     string `form:"name" binding:"required"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	report, err := api.deps.Master.GetReplicaVerifyReport(param.Cluster, param.Database, param.Name)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	httppkg.OK(c, report)
+}
+
+// Repair submits the repair task which copies the families missing on some replicas from other replica,
+// returns the divergence report with the number of repair tasks.
+func (api *ReplicaVerifyAPI) Repair(c *gin.Context) {
+	if !api.deps.Master.IsMaster() {
+		api.forwardToMaster(c)
+		return
+	}
+	var param struct {
+		Cluster  string `json:"cluster" binding:"required"`
+		Database string `json:"database" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBind(&param); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	report, err := api.deps.Master.RepairReplicas(param.Cluster, param.Database, param.Name)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	httppkg.OK(c, report)
+}
+
+// forwardToMaster forwards the request to master node, then writes the response of master.
+func (api *ReplicaVerifyAPI) forwardToMaster(c *gin.Context) {
+	master := api.deps.Master.GetMaster()
+	if master == nil {
+		httppkg.Error(c, fmt.Errorf("master not found"))
+		return
+	}
+	url := fmt.Sprintf("http://%s:%d%s", master.Node.IP, master.Node.HTTPPort, c.Request.URL.RequestURI())
+	req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, url, c.Request.Body)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	req.Header = c.Request.Header.Clone()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			api.logger.Error("close http response body", logger.Error(err))
+		}
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), body)
+}
@@ -0,0 +1,157 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+func TestReplicaVerifyAPI_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	master := coordinator.NewMockMaster(ctrl)
+	master.EXPECT().IsMaster().Return(true).AnyTimes()
+	api := NewReplicaVerifyAPI(&deps.HTTPDeps{Master: master})
+	r := gin.New()
+	api.Register(r)
+
+	// case 1: bad param
+	resp := mock.DoRequest(t, r, http.MethodPut, ReplicaVerifyPath, `{"cluster":"test"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 2: bad time range
+	resp = mock.DoRequest(t, r, http.MethodPut, ReplicaVerifyPath,
+		`{"cluster":"test","database":"db","startTime":20,"endTime":10}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 3: verify err
+	master.EXPECT().VerifyReplicas("test", "db", timeutil.TimeRange{Start: 10, End: 20}).Return("", fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPut, ReplicaVerifyPath,
+		`{"cluster":"test","database":"db","startTime":10,"endTime":20}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 4: verify last day by default
+	master.EXPECT().VerifyReplicas("test", "db", gomock.Any()).
+		DoAndReturn(func(_, _ string, timeRange timeutil.TimeRange) (string, error) {
+			assert.Equal(t, timeutil.OneDay, timeRange.End-timeRange.Start)
+			return "db-1", nil
+		})
+	resp = mock.DoRequest(t, r, http.MethodPut, ReplicaVerifyPath, `{"cluster":"test","database":"db"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"name":"db-1"}`, resp.Body.String())
+}
+
+func TestReplicaVerifyAPI_GetReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	master := coordinator.NewMockMaster(ctrl)
+	master.EXPECT().IsMaster().Return(true).AnyTimes()
+	api := NewReplicaVerifyAPI(&deps.HTTPDeps{Master: master})
+	r := gin.New()
+	api.Register(r)
+
+	// case 1: bad param
+	resp := mock.DoRequest(t, r, http.MethodGet, ReplicaVerifyPath+"?cluster=test&database=db", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 2: get report err
+	master.EXPECT().GetReplicaVerifyReport("test", "db", "db-1").Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, ReplicaVerifyPath+"?cluster=test&database=db&name=db-1", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 3: get report
+	master.EXPECT().GetReplicaVerifyReport("test", "db", "db-1").
+		Return(&models.ReplicaVerifyReport{Name: "db-1", Database: "db", Completed: true}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, ReplicaVerifyPath+"?cluster=test&database=db&name=db-1", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"name":"db-1","database":"db","completed":true,"shards":0,"families":0}`, resp.Body.String())
+}
+
+func TestReplicaVerifyAPI_Repair(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	master := coordinator.NewMockMaster(ctrl)
+	master.EXPECT().IsMaster().Return(true).AnyTimes()
+	api := NewReplicaVerifyAPI(&deps.HTTPDeps{Master: master})
+	r := gin.New()
+	api.Register(r)
+
+	// case 1: bad param
+	resp := mock.DoRequest(t, r, http.MethodPut, ReplicaRepairPath, `{"cluster":"test","database":"db"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 2: repair err
+	master.EXPECT().RepairReplicas("test", "db", "db-1").Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPut, ReplicaRepairPath, `{"cluster":"test","database":"db","name":"db-1"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 3: repair
+	master.EXPECT().RepairReplicas("test", "db", "db-1").
+		Return(&models.ReplicaVerifyReport{Name: "db-1", RepairTasks: 2}, nil)
+	resp = mock.DoRequest(t, r, http.MethodPut, ReplicaRepairPath, `{"cluster":"test","database":"db","name":"db-1"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"repairTasks":2`)
+}
+
+func TestReplicaVerifyAPI_forwardToMaster(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	master := coordinator.NewMockMaster(ctrl)
+	master.EXPECT().IsMaster().Return(false).AnyTimes()
+	api := NewReplicaVerifyAPI(&deps.HTTPDeps{Master: master})
+	r := gin.New()
+	api.Register(r)
+
+	// case 1: master not found
+	master.EXPECT().GetMaster().Return(nil)
+	resp := mock.DoRequest(t, r, http.MethodPut, ReplicaVerifyPath, `{"cluster":"test","database":"db"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// case 2: forward to master
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPut, req.Method)
+		assert.Equal(t, ReplicaRepairPath, req.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"name":"db-1"}`))
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	httpPort, _ := strconv.Atoi(port)
+	master.EXPECT().GetMaster().Return(&models.Master{Node: models.Node{IP: host, HTTPPort: uint16(httpPort)}}).AnyTimes()
+	resp = mock.DoRequest(t, r, http.MethodPut, ReplicaRepairPath, `{"cluster":"test","database":"db","name":"db-1"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"name":"db-1"}`, resp.Body.String())
+
+	// case 3: master unavailable
+	server.Close()
+	resp = mock.DoRequest(t, r, http.MethodGet, ReplicaVerifyPath+"?cluster=test&database=db&name=db-1", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
@@ -0,0 +1,182 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/sql"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+var (
+	SchemaPath = "/schema"
+)
+
+// SchemaAPI represents database lifecycle admin rest api by sql,
+// supports create database/drop database/show schemas statement.
+type SchemaAPI struct {
+	database *DatabaseAPI
+}
+
+// NewSchemaAPI creates schema api instance
+func NewSchemaAPI(deps *deps.HTTPDeps) *SchemaAPI {
+	return &SchemaAPI{
+		database: NewDatabaseAPI(deps),
+	}
+}
+
+// Register adds schema admin url route.
+func (s *SchemaAPI) Register(route gin.IRoutes) {
+	route.POST(SchemaPath, s.Execute)
+}
+
+// Execute executes the schema statement, like:
+// create database test with (cluster=dev, numOfShard=8, replicaFactor=2, interval='10s').
+func (s *SchemaAPI) Execute(c *gin.Context) {
+	var param struct {
+		SQL string `form:"sql" json:"sql" binding:"required"`
+	}
+	if err := c.ShouldBind(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	statement, err := sql.Parse(param.SQL)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	schema, ok := statement.(*stmt.Schema)
+	if !ok {
+		http.Error(c, fmt.Errorf("only support create database/drop database/show schemas statement"))
+		return
+	}
+	switch schema.Type {
+	case stmt.CreateDatabaseSchemaType:
+		s.createDatabase(c, schema)
+	case stmt.DropDatabaseSchemaType:
+		s.dropDatabase(c, schema.Database)
+	case stmt.DatabaseSchemasSchemaType:
+		s.database.List(c)
+	default:
+		http.Error(c, fmt.Errorf("not support schema statement: %s", schema.Type))
+	}
+}
+
+// createDatabase creates the database config, responses conflict if database exists.
+func (s *SchemaAPI) createDatabase(c *gin.Context, schema *stmt.Schema) {
+	database, err := newDatabase(schema)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	if err := s.database.saveDataBase(database); err != nil {
+		if errors.Is(err, ErrDatabaseRevisionConflict) {
+			http.Conflict(c, fmt.Errorf("database %s already exists", database.Name))
+			return
+		}
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
+
+// dropDatabase removes the database config, responses not found if database not exists.
+func (s *SchemaAPI) dropDatabase(c *gin.Context, databaseName string) {
+	if _, err := s.database.getByName(databaseName); err != nil {
+		if errors.Is(err, state.ErrNotExist) {
+			http.NotFound(c)
+			return
+		}
+		http.Error(c, err)
+		return
+	}
+	if err := s.database.deleteDatabase(databaseName); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
+
+// newDatabase builds the database config from options of create database statement,
+// option name is case-insensitive, list value(rollup/numericTagKeys) is separated by comma.
+func newDatabase(schema *stmt.Schema) (*models.Database, error) {
+	if len(schema.Database) == 0 {
+		return nil, fmt.Errorf("database name cannot be empty")
+	}
+	database := &models.Database{Name: schema.Database}
+	for _, opt := range schema.Options {
+		var err error
+		switch strings.ToLower(opt.Key) {
+		case "cluster":
+			database.Cluster = opt.Value
+		case "numofshard":
+			database.NumOfShard, err = strconv.Atoi(opt.Value)
+		case "replicafactor":
+			database.ReplicaFactor, err = strconv.Atoi(opt.Value)
+		case "interval":
+			database.Option.Interval = opt.Value
+		case "rollup":
+			database.Option.Rollup = splitOptionValues(opt.Value)
+		case "autocreatens":
+			database.Option.AutoCreateNS, err = strconv.ParseBool(opt.Value)
+		case "behind":
+			database.Option.Behind = opt.Value
+		case "ahead":
+			database.Option.Ahead = opt.Value
+		case "familywindow":
+			database.Option.FamilyWindow = opt.Value
+		case "retention":
+			database.Option.Retention = opt.Value
+		case "datapointbuffer":
+			database.Option.DataPointBuffer = opt.Value
+		case "blockcodec":
+			database.Option.BlockCodec = opt.Value
+		case "writeweight":
+			database.Option.WriteWeight, err = strconv.Atoi(opt.Value)
+		case "numerictagkeys":
+			database.Option.NumericTagKeys = splitOptionValues(opt.Value)
+		default:
+			return nil, fmt.Errorf("unknown database option: %s", opt.Key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of database option %s: %s", opt.Key, opt.Value)
+		}
+	}
+	return database, nil
+}
+
+// splitOptionValues splits the list value of option by comma.
+func splitOptionValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
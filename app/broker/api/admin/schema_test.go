@@ -0,0 +1,170 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestSchemaAPI_Execute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := gin.New()
+	repo := state.NewMockRepository(ctrl)
+	api := NewSchemaAPI(&deps.HTTPDeps{
+		Ctx:       context.Background(),
+		Repo:      repo,
+		BrokerCfg: &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+	})
+	api.Register(r)
+
+	execute := func(sql string) int {
+		return mock.DoRequest(t, r, http.MethodPost, SchemaPath, fmt.Sprintf(`{"sql":%q}`, sql)).Code
+	}
+	// bind error
+	assert.Equal(t, http.StatusInternalServerError, mock.DoRequest(t, r, http.MethodPost, SchemaPath, "").Code)
+	// parse error
+	assert.Equal(t, http.StatusInternalServerError, execute("create database test with ("))
+	// not schema statement
+	assert.Equal(t, http.StatusInternalServerError, execute("show databases"))
+	// invalid option
+	assert.Equal(t, http.StatusInternalServerError, execute("create database test with (numOfShard=a)"))
+	// validate error
+	assert.Equal(t, http.StatusInternalServerError, execute("create database test with (numOfShard=1)"))
+
+	// create database
+	txn := state.NewMockTransaction(ctrl)
+	repo.EXPECT().NewTransaction().Return(txn).AnyTimes()
+	txn.EXPECT().ModRevisionCmp(constants.GetDatabaseConfigPath("test"), "=", int64(0)).AnyTimes()
+	txn.EXPECT().Put(constants.GetDatabaseConfigPath("test"), gomock.Any()).
+		DoAndReturn(func(_ string, value []byte) {
+			saved := &models.Database{}
+			_ = encoding.JSONUnmarshal(value, saved)
+			assert.Equal(t, models.Database{
+				Name:          "test",
+				Cluster:       "dev",
+				NumOfShard:    8,
+				ReplicaFactor: 2,
+				Option:        option.DatabaseOption{Interval: "10s", Rollup: []string{"1m", "1h"}},
+			}, *saved)
+		}).AnyTimes()
+	createSQL := "create database test with (cluster=dev, numOfShard=8, replicaFactor=2, interval='10s', rollup='1m,1h')"
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(nil)
+	assert.Equal(t, http.StatusNoContent, execute(createSQL))
+	// database exists
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(state.ErrTxnFailed)
+	assert.Equal(t, http.StatusConflict, execute(createSQL))
+	// commit err
+	repo.EXPECT().Commit(gomock.Any(), txn).Return(io.ErrClosedPipe)
+	assert.Equal(t, http.StatusInternalServerError, execute(createSQL))
+
+	// drop database not exist
+	repo.EXPECT().GetWithRevision(gomock.Any(), constants.GetDatabaseConfigPath("test")).
+		Return(nil, int64(0), state.ErrNotExist)
+	assert.Equal(t, http.StatusNotFound, execute("drop database test"))
+	// get database err
+	repo.EXPECT().GetWithRevision(gomock.Any(), constants.GetDatabaseConfigPath("test")).
+		Return(nil, int64(0), io.ErrClosedPipe)
+	assert.Equal(t, http.StatusInternalServerError, execute("drop database test"))
+	// delete err
+	repo.EXPECT().GetWithRevision(gomock.Any(), constants.GetDatabaseConfigPath("test")).
+		Return([]byte(`{"name":"test"}`), int64(10), nil).Times(2)
+	repo.EXPECT().Delete(gomock.Any(), constants.GetDatabaseConfigPath("test")).Return(io.ErrClosedPipe)
+	assert.Equal(t, http.StatusInternalServerError, execute("drop database test"))
+	// drop database
+	repo.EXPECT().Delete(gomock.Any(), constants.GetDatabaseConfigPath("test")).Return(nil)
+	assert.Equal(t, http.StatusNoContent, execute("drop database test"))
+
+	// show schemas
+	repo.EXPECT().List(gomock.Any(), constants.DatabaseConfigPath).Return([]state.KeyValue{
+		{Key: "db", Value: []byte(`{"name":"test"}`)},
+	}, nil)
+	assert.Equal(t, http.StatusOK, execute("show schemas"))
+	repo.EXPECT().List(gomock.Any(), constants.DatabaseConfigPath).Return(nil, fmt.Errorf("err"))
+	assert.Equal(t, http.StatusInternalServerError, execute("show schemas"))
+}
+
+func TestNewDatabase(t *testing.T) {
+	_, err := newDatabase(&stmt.Schema{})
+	assert.Error(t, err)
+	_, err = newDatabase(&stmt.Schema{Database: "test", Options: []stmt.SchemaOption{{Key: "unknown", Value: "1"}}})
+	assert.Error(t, err)
+	_, err = newDatabase(&stmt.Schema{Database: "test", Options: []stmt.SchemaOption{{Key: "autoCreateNS", Value: "a"}}})
+	assert.Error(t, err)
+
+	database, err := newDatabase(&stmt.Schema{
+		Database: "test",
+		Options: []stmt.SchemaOption{
+			{Key: "Cluster", Value: "dev"},
+			{Key: "NUMOFSHARD", Value: "4"},
+			{Key: "replicaFactor", Value: "3"},
+			{Key: "interval", Value: "10s"},
+			{Key: "rollup", Value: "1m, ,1h"},
+			{Key: "autoCreateNS", Value: "true"},
+			{Key: "behind", Value: "1h"},
+			{Key: "ahead", Value: "2h"},
+			{Key: "familyWindow", Value: "1h"},
+			{Key: "retention", Value: "30d"},
+			{Key: "dataPointBuffer", Value: "heap"},
+			{Key: "blockCodec", Value: "gorilla"},
+			{Key: "writeWeight", Value: "2"},
+			{Key: "numericTagKeys", Value: "code,status"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Database{
+		Name:          "test",
+		Cluster:       "dev",
+		NumOfShard:    4,
+		ReplicaFactor: 3,
+		Option: option.DatabaseOption{
+			Interval:        "10s",
+			Rollup:          []string{"1m", "1h"},
+			AutoCreateNS:    true,
+			Behind:          "1h",
+			Ahead:           "2h",
+			FamilyWindow:    "1h",
+			Retention:       "30d",
+			DataPointBuffer: "heap",
+			BlockCodec:      "gorilla",
+			WriteWeight:     2,
+			NumericTagKeys:  []string{"code", "status"},
+		},
+	}, database)
+}
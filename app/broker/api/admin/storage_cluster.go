@@ -18,19 +18,24 @@
 package admin
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/lindb/lindb/app/broker/deps"
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/http"
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/state"
 )
 
 var (
-	StorageClusterPath     = "/storage/cluster"
-	ListStorageClusterPath = "/storage/cluster/list"
+	StorageClusterPath      = "/storage/cluster"
+	ListStorageClusterPath  = "/storage/cluster/list"
+	StorageNodeReadOnlyPath = "/storage/cluster/read-only"
 )
 
 type storageClusterParam struct {
@@ -57,6 +62,7 @@ func (s *StorageClusterAPI) Register(route gin.IRoutes) {
 	route.GET(StorageClusterPath, s.GetByName)
 	route.DELETE(StorageClusterPath, s.DeleteByName)
 	route.GET(ListStorageClusterPath, s.List)
+	route.PUT(StorageNodeReadOnlyPath, s.SetReadOnly)
 }
 
 // Create creates config of storage cluster
@@ -77,6 +83,53 @@ func (s *StorageClusterAPI) Create(c *gin.Context) {
 	http.NoContent(c)
 }
 
+// SetReadOnly marks/unmarks a storage node read-only, read-only node receives no new shard assignments
+// and writes, but still serves queries, used for draining hardware or archive node which holds cold shards.
+func (s *StorageClusterAPI) SetReadOnly(c *gin.Context) {
+	var param struct {
+		ClusterName string `json:"cluster" binding:"required"`
+		Node        string `json:"node" binding:"required"`
+		ReadOnly    bool   `json:"readOnly"`
+	}
+	if err := c.ShouldBind(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if _, err := models.ParseNode(param.Node); err != nil {
+		http.Error(c, err)
+		return
+	}
+	ctx, cancel := s.deps.WithTimeout()
+	defer cancel()
+	key := constants.GetStorageClusterConfigPath(param.ClusterName)
+	data, err := s.deps.Repo.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, state.ErrNotExist) {
+			http.NotFound(c)
+			return
+		}
+		http.Error(c, err)
+		return
+	}
+	storageCluster := &config.StorageCluster{}
+	if err := encoding.JSONUnmarshal(data, storageCluster); err != nil {
+		http.Error(c, err)
+		return
+	}
+	// master rebuilds the storage cluster state after config changed, then brokers find the read-only node
+	if storageCluster.SetReadOnlyNode(param.Node, param.ReadOnly) {
+		if err := s.deps.Repo.Put(ctx, key, encoding.JSONMarshal(storageCluster)); err != nil {
+			http.Error(c, err)
+			return
+		}
+		s.logger.Info("set storage node read-only",
+			logger.String("cluster", param.ClusterName),
+			logger.String("node", param.Node),
+			logger.Any("readOnly", param.ReadOnly))
+	}
+	http.NoContent(c)
+}
+
 // GetByName gets storage cluster by name
 func (s *StorageClusterAPI) GetByName(c *gin.Context) {
 	param := storageClusterParam{}
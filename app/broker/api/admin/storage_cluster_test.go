@@ -112,3 +112,59 @@ func TestStorageClusterAPI(t *testing.T) {
 	resp = mock.DoRequest(t, r, http.MethodDelete, StorageClusterPath+"?name=test1", "")
 	assert.Equal(t, http.StatusNoContent, resp.Code)
 }
+
+func TestStorageClusterAPI_SetReadOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := state.NewMockRepository(ctrl)
+	api := NewStorageClusterAPI(&deps.HTTPDeps{
+		Ctx:  context.Background(),
+		Repo: mockRepo,
+		BrokerCfg: &config.BrokerBase{
+			HTTP: config.HTTP{
+				ReadTimeout: ltoml.Duration(time.Second)},
+		},
+	})
+	r := gin.New()
+	api.Register(r)
+
+	// bind error
+	resp := mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, `{"cluster":"test"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// bad node
+	resp = mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, `{"cluster":"test","node":"xxx"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	body := `{"cluster":"test","node":"1.1.1.1:2891","readOnly":true}`
+	// cluster not exist
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, state.ErrNotExist)
+	resp = mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, body)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	// get error
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, body)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// unmarshal error
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte("[]"), nil)
+	resp = mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, body)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// put error
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte(`{"name":"test"}`), nil)
+	mockRepo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, body)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// mark read-only
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]byte(`{"name":"test"}`), nil)
+	mockRepo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, data []byte) error {
+			assert.Contains(t, string(data), `"readOnlyNodes":["1.1.1.1:2891"]`)
+			return nil
+		})
+	resp = mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, body)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	// already read-only, nothing changed
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return([]byte(`{"name":"test","readOnlyNodes":["1.1.1.1:2891"]}`), nil)
+	resp = mock.DoRequest(t, r, http.MethodPut, StorageNodeReadOnlyPath, body)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
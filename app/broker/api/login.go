@@ -18,30 +18,36 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/lindb/lindb/app/broker/audit"
 	"github.com/lindb/lindb/config"
-	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/models"
+	httppkg "github.com/lindb/lindb/pkg/http"
 	"github.com/lindb/lindb/pkg/logger"
 )
 
 var (
-	createTokenFn = http.CreateToken
+	createTokenFn = httppkg.CreateToken
 	LoginPath     = "/login"
 )
 
 // LoginAPI represents login param
 type LoginAPI struct {
-	user config.User
+	user     config.User
+	auditLog audit.Log
 
 	logger *logger.Logger
 }
 
-// NewLoginAPI creates login api instance
-func NewLoginAPI(user config.User) *LoginAPI {
+// NewLoginAPI creates login api instance, login events are recorded into audit log if it isn't nil.
+func NewLoginAPI(user config.User, auditLog audit.Log) *LoginAPI {
 	return &LoginAPI{
-		user:   user,
-		logger: logger.GetLogger("broker", "LoginAPI"),
+		user:     user,
+		auditLog: auditLog,
+		logger:   logger.GetLogger("broker", "LoginAPI"),
 	}
 }
 
@@ -58,25 +64,50 @@ func (l *LoginAPI) Login(c *gin.Context) {
 	err := c.ShouldBind(&user)
 	if err != nil {
 		l.logger.Error("cannot get user info from request")
-		http.OK(c, "")
+		l.audit(c, user.UserName, "cannot get user info from request")
+		httppkg.OK(c, "")
 		return
 	}
 	// user name is error
 	if l.user.UserName != user.UserName {
 		l.logger.Error("username is invalid")
-		http.OK(c, "")
+		l.audit(c, user.UserName, "username is invalid")
+		httppkg.OK(c, "")
 		return
 	}
 	// password is error
 	if l.user.Password != user.Password {
 		l.logger.Error("password is invalid")
-		http.OK(c, "")
+		l.audit(c, user.UserName, "password is invalid")
+		httppkg.OK(c, "")
 		return
 	}
 	token, err := createTokenFn(user)
 	if err != nil {
-		http.OK(c, "")
+		l.audit(c, user.UserName, err.Error())
+		httppkg.OK(c, "")
+		return
+	}
+	l.audit(c, user.UserName, "")
+	httppkg.OK(c, token)
+}
+
+// audit records the login event into audit log, reason is empty if login successfully.
+func (l *LoginAPI) audit(c *gin.Context, userName, reason string) {
+	if l.auditLog == nil {
 		return
 	}
-	http.OK(c, token)
+	entry := &models.AuditEntry{
+		Actor:     userName,
+		ClientIP:  c.ClientIP(),
+		Operation: "login",
+		Status:    http.StatusOK,
+		Error:     reason,
+	}
+	if reason != "" {
+		entry.Status = http.StatusUnauthorized
+	}
+	if err := l.auditLog.Record(c.Request.Context(), entry); err != nil {
+		l.logger.Error("record login audit log failure", logger.String("user", userName), logger.Error(err))
+	}
 }
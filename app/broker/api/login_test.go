@@ -26,8 +26,10 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/app/broker/audit"
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
 	httppkg "github.com/lindb/lindb/pkg/http"
 )
 
@@ -39,7 +41,7 @@ func TestLogin(t *testing.T) {
 	}()
 
 	user := config.User{UserName: "admin", Password: "admin123"}
-	api := NewLoginAPI(user)
+	api := NewLoginAPI(user, nil)
 	r := gin.New()
 	api.Register(r)
 
@@ -78,3 +80,38 @@ func TestLogin(t *testing.T) {
 	resp = mock.DoRequest(t, r, http.MethodPut, LoginPath, `{"username": "admin", "password": "admin123"}`)
 	assert.Equal(t, http.StatusOK, resp.Code)
 }
+
+func TestLogin_audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditLog := audit.NewMockLog(ctrl)
+	api := NewLoginAPI(config.User{UserName: "admin", Password: "admin123"}, auditLog)
+	r := gin.New()
+	api.Register(r)
+
+	var entries []*models.AuditEntry
+	auditLog.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, entry *models.AuditEntry) error {
+			entries = append(entries, entry)
+			return nil
+		}).Times(2)
+	resp := mock.DoRequest(t, r, http.MethodPut, LoginPath, `{"username": "admin", "password": "admin1234"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = mock.DoRequest(t, r, http.MethodPut, LoginPath, `{"username": "admin", "password": "admin123"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "admin", entries[0].Actor)
+	assert.Equal(t, "login", entries[0].Operation)
+	assert.Equal(t, http.StatusUnauthorized, entries[0].Status)
+	assert.Equal(t, "password is invalid", entries[0].Error)
+	assert.Empty(t, entries[0].Payload)
+	assert.Equal(t, http.StatusOK, entries[1].Status)
+	assert.Empty(t, entries[1].Error)
+
+	// record failure, login still works
+	auditLog.EXPECT().Record(gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPut, LoginPath, `{"username": "admin", "password": "admin123"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, `""`, resp.Body.String())
+}
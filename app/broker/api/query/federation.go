@@ -0,0 +1,86 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/pkg/http"
+)
+
+var (
+	FederationQueryPath = "/query/federation"
+)
+
+// errFederationDisabled is the error returned when federation query without remote clusters configured.
+var errFederationDisabled = errors.New("federation query is disabled, please config remote clusters")
+
+// FederationAPI represents the federation query api, which fans metric query out to remote clusters.
+type FederationAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewFederationAPI creates the federation query api
+func NewFederationAPI(deps *deps.HTTPDeps) *FederationAPI {
+	return &FederationAPI{
+		deps: deps,
+	}
+}
+
+// Register adds federation query url route.
+func (f *FederationAPI) Register(route gin.IRoutes) {
+	route.GET(FederationQueryPath, f.Search)
+}
+
+// Search searches the metric data from current cluster and remote clusters based on database and sql,
+// series of results are tagged with cluster name.
+func (f *FederationAPI) Search(c *gin.Context) {
+	var param struct {
+		Database string `form:"db" binding:"required"`
+		SQL      string `form:"sql" binding:"required"`
+		// optional, returns results of available clusters if some clusters fail or time out
+		Partial bool `form:"partial"`
+	}
+	err := c.ShouldBindQuery(&param)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	if f.deps.Federation == nil {
+		http.Error(c, errFederationDisabled)
+		return
+	}
+	queryID := assignQueryID(c, "")
+
+	startTime := time.Now()
+	ctx, cancel := newQueryContext(c, f.deps.QueryTimeout())
+	defer cancel()
+
+	resultSet, err := f.deps.Federation.Query(ctx, param.Database, param.SQL, param.Partial)
+	logSlowQuery(ctx, f.deps.SlowQueryThreshold(),
+		queryID, param.Database, param.SQL, startTime, err)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.OK(c, resultSet)
+}
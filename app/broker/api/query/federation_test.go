@@ -0,0 +1,68 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/app/broker/federation"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+func TestFederationAPI_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fed := federation.NewMockFederation(ctrl)
+	httpDeps := &deps.HTTPDeps{
+		BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second)}},
+		StateMachines: &coordinator.BrokerStateMachines{},
+	}
+	api := NewFederationAPI(httpDeps)
+	r := gin.New()
+	api.Register(r)
+
+	// bad request
+	resp := mock.DoRequest(t, r, http.MethodGet, FederationQueryPath+"?db=test", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// federation disabled
+	resp = mock.DoRequest(t, r, http.MethodGet, FederationQueryPath+"?db=test&sql=select+f+from+cpu", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	httpDeps.Federation = fed
+	// query failure
+	fed.EXPECT().Query(gomock.Any(), "test", "select f from cpu", false).Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, FederationQueryPath+"?db=test&sql=select+f+from+cpu", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// partial results allowed
+	fed.EXPECT().Query(gomock.Any(), "test", "select f from cpu", true).Return(&models.ResultSet{MetricName: "cpu"}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, FederationQueryPath+"?db=test&sql=select+f+from+cpu&partial=true", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
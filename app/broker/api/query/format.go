@@ -0,0 +1,144 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/arrow"
+	httppkg "github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/msgpack"
+)
+
+// msgpackAltContentType is the alternative media type of msgpack accepted by query endpoint.
+const msgpackAltContentType = "application/msgpack"
+
+// writeResultSet responses the merged result set with the format negotiated by Accept header,
+// msgpack and arrow ipc stream are cheaper than json for programmatic consumers pulling large result sets.
+func writeResultSet(c *gin.Context, resultSet *models.ResultSet) {
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, arrow.ContentType):
+		c.Header("Content-Type", arrow.ContentType)
+		c.Status(http.StatusOK)
+		if err := arrow.WriteStream(c.Writer, resultSetColumns(resultSet)); err != nil {
+			_ = c.Error(err)
+		}
+	case strings.Contains(accept, msgpack.ContentType) || strings.Contains(accept, msgpackAltContentType):
+		data, err := msgpack.Marshal(resultSet)
+		if err != nil {
+			httppkg.Error(c, err)
+			return
+		}
+		c.Data(http.StatusOK, msgpack.ContentType, data)
+	default:
+		httppkg.OK(c, resultSet)
+	}
+}
+
+// resultSetColumns converts the result set into columns of arrow record batch, one row per point:
+// tag columns(sorted by key), field, timestamp, value, and string column if result set has string fields.
+func resultSetColumns(resultSet *models.ResultSet) []arrow.Column {
+	tagKeys := make(map[string]struct{})
+	hasStrings := false
+	for _, series := range resultSet.Series {
+		for tagKey := range series.Tags {
+			tagKeys[tagKey] = struct{}{}
+		}
+		if len(series.Strings) > 0 {
+			hasStrings = true
+		}
+	}
+	tagColumns := make([]arrow.Column, 0, len(tagKeys))
+	for tagKey := range tagKeys {
+		tagColumns = append(tagColumns, arrow.Column{Name: tagKey, Type: arrow.Utf8, Valid: []bool{}})
+	}
+	sort.Slice(tagColumns, func(i, j int) bool {
+		return tagColumns[i].Name < tagColumns[j].Name
+	})
+	fieldColumn := arrow.Column{Name: "field", Type: arrow.Utf8}
+	timestampColumn := arrow.Column{Name: "timestamp", Type: arrow.TimestampMillis}
+	valueColumn := arrow.Column{Name: "value", Type: arrow.Float64, Valid: []bool{}}
+	stringColumn := arrow.Column{Name: "string", Type: arrow.Utf8, Valid: []bool{}}
+
+	addRow := func(series *models.Series, fieldName string, timestamp int64) {
+		for idx := range tagColumns {
+			tagValue, ok := series.Tags[tagColumns[idx].Name]
+			tagColumns[idx].Strings = append(tagColumns[idx].Strings, tagValue)
+			tagColumns[idx].Valid = append(tagColumns[idx].Valid, ok)
+		}
+		fieldColumn.Strings = append(fieldColumn.Strings, fieldName)
+		timestampColumn.Int64s = append(timestampColumn.Int64s, timestamp)
+	}
+	for _, series := range resultSet.Series {
+		names := make([]string, 0, len(series.Fields))
+		for fieldName := range series.Fields {
+			names = append(names, fieldName)
+		}
+		for _, fieldName := range resultSet.SortFieldNames(names) {
+			points := series.Fields[fieldName]
+			timestamps := make([]int64, 0, len(points))
+			for timestamp := range points {
+				timestamps = append(timestamps, timestamp)
+			}
+			for _, timestamp := range sortTimestamps(timestamps) {
+				addRow(series, fieldName, timestamp)
+				valueColumn.Floats = append(valueColumn.Floats, points[timestamp])
+				valueColumn.Valid = append(valueColumn.Valid, true)
+				stringColumn.Strings = append(stringColumn.Strings, "")
+				stringColumn.Valid = append(stringColumn.Valid, false)
+			}
+		}
+		names = names[:0]
+		for fieldName := range series.Strings {
+			names = append(names, fieldName)
+		}
+		for _, fieldName := range resultSet.SortFieldNames(names) {
+			values := series.Strings[fieldName]
+			timestamps := make([]int64, 0, len(values))
+			for timestamp := range values {
+				timestamps = append(timestamps, timestamp)
+			}
+			for _, timestamp := range sortTimestamps(timestamps) {
+				addRow(series, fieldName, timestamp)
+				valueColumn.Floats = append(valueColumn.Floats, 0)
+				valueColumn.Valid = append(valueColumn.Valid, false)
+				stringColumn.Strings = append(stringColumn.Strings, values[timestamp])
+				stringColumn.Valid = append(stringColumn.Valid, true)
+			}
+		}
+	}
+	columns := append(tagColumns, fieldColumn, timestampColumn, valueColumn)
+	if hasStrings {
+		columns = append(columns, stringColumn)
+	}
+	return columns
+}
+
+// sortTimestamps sorts the timestamps in ascending order.
+func sortTimestamps(timestamps []int64) []int64 {
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+	return timestamps
+}
@@ -0,0 +1,117 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/arrow"
+	"github.com/lindb/lindb/pkg/msgpack"
+)
+
+func newTestResultSet() *models.ResultSet {
+	rs := models.NewResultSet()
+	rs.MetricName = "cpu"
+	rs.Fields = []string{"f2", "f1"}
+	s1 := models.NewSeries(map[string]string{"host": "h1"})
+	points := models.NewPoints()
+	points.AddPoint(20, 2)
+	points.AddPoint(10, 1)
+	s1.AddField("f1", points)
+	points = models.NewPoints()
+	points.AddPoint(10, 3)
+	s1.AddField("f2", points)
+	rs.AddSeries(s1)
+	s2 := models.NewSeries(map[string]string{"ip": "1.1.1.1"})
+	s2.AddStringField("version", map[int64]string{10: "v1"})
+	rs.AddSeries(s2)
+	return rs
+}
+
+func TestWriteResultSet(t *testing.T) {
+	cases := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/json; charset=utf-8"},
+		{"application/json", "application/json; charset=utf-8"},
+		{"application/x-msgpack", msgpack.ContentType},
+		{"application/msgpack", msgpack.ContentType},
+		{arrow.ContentType, arrow.ContentType},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Accept", tc.accept)
+		writeResultSet(c, newTestResultSet())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, tc.contentType, w.Header().Get("Content-Type"))
+		assert.NotEmpty(t, w.Body.Bytes())
+	}
+	// arrow stream starts with continuation marker
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Accept", arrow.ContentType)
+	writeResultSet(c, newTestResultSet())
+	assert.Equal(t, uint32(0xFFFFFFFF), binary.LittleEndian.Uint32(w.Body.Bytes()))
+}
+
+func TestWriteResultSet_Msgpack(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Accept", msgpack.ContentType)
+	writeResultSet(c, newTestResultSet())
+
+	expect, err := msgpack.Marshal(newTestResultSet())
+	assert.NoError(t, err)
+	assert.Equal(t, expect, w.Body.Bytes())
+	assert.True(t, bytes.Contains(w.Body.Bytes(), []byte("metricName")))
+}
+
+func TestResultSetColumns(t *testing.T) {
+	columns := resultSetColumns(newTestResultSet())
+	assert.Len(t, columns, 6)
+	assert.Equal(t, arrow.Column{Name: "host", Type: arrow.Utf8,
+		Strings: []string{"h1", "h1", "h1", ""}, Valid: []bool{true, true, true, false}}, columns[0])
+	assert.Equal(t, arrow.Column{Name: "ip", Type: arrow.Utf8,
+		Strings: []string{"", "", "", "1.1.1.1"}, Valid: []bool{false, false, false, true}}, columns[1])
+	// fields in order of select list
+	assert.Equal(t, []string{"f2", "f1", "f1", "version"}, columns[2].Strings)
+	assert.Equal(t, []int64{10, 10, 20, 10}, columns[3].Int64s)
+	assert.Equal(t, []float64{3, 1, 2, 0}, columns[4].Floats)
+	assert.Equal(t, []bool{true, true, true, false}, columns[4].Valid)
+	assert.Equal(t, arrow.Column{Name: "string", Type: arrow.Utf8,
+		Strings: []string{"", "", "", "v1"}, Valid: []bool{false, false, false, true}}, columns[5])
+
+	// no string fields
+	rs := newTestResultSet()
+	rs.Series = rs.Series[:1]
+	columns = resultSetColumns(rs)
+	assert.Len(t, columns, 4)
+}
@@ -0,0 +1,115 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/sql/influxql"
+)
+
+var (
+	InfluxQueryPath = "/query"
+)
+
+// InfluxQueryAPI represents the query api compatible with InfluxDB,
+// translates InfluxQL into LinQL, used for migrating the dashboards built for InfluxDB.
+type InfluxQueryAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewInfluxQueryAPI creates the influx query api
+func NewInfluxQueryAPI(deps *deps.HTTPDeps) *InfluxQueryAPI {
+	return &InfluxQueryAPI{
+		deps: deps,
+	}
+}
+
+// Register adds influx query url route.
+func (iq *InfluxQueryAPI) Register(route gin.IRoutes) {
+	route.GET(InfluxQueryPath, iq.Query)
+	route.POST(InfluxQueryPath, iq.Query)
+}
+
+// Query translates the InfluxQL into LinQL, then returns the result set as InfluxDB's format.
+func (iq *InfluxQueryAPI) Query(c *gin.Context) {
+	var param struct {
+		Database string `form:"db" binding:"required"`
+		Query    string `form:"q" binding:"required"`
+		Epoch    string `form:"epoch"`
+	}
+	// InfluxDB clients send GET with query string, or POST with url-encoded form
+	var b binding.Binding = binding.Query
+	if c.Request.Method == "POST" {
+		b = binding.Form
+	}
+	err := c.ShouldBindWith(&param, b)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	queryID := assignQueryID(c, "")
+	queries, err := influxql.Translate(param.Query, iq.getLocation())
+	if err != nil {
+		http.OK(c, &influxql.Response{Error: err.Error()})
+		return
+	}
+
+	ctx, cancel := newQueryContext(c, iq.deps.QueryTimeout())
+	defer cancel()
+
+	resp := &influxql.Response{}
+	for idx, q := range queries {
+		// each statement is an individual query, distinguished by statement id
+		statementQueryID := queryID
+		if len(queries) > 1 {
+			statementQueryID += "-" + strconv.Itoa(idx)
+		}
+		startTime := time.Now()
+		metricQuery := iq.deps.QueryFactory.NewMetricQuery(ctx, param.Database, q.SQL, statementQueryID)
+		resultSet, err := metricQuery.WaitResponse()
+		logSlowQuery(ctx, iq.deps.SlowQueryThreshold(),
+			statementQueryID, param.Database, q.SQL, startTime, err)
+		if err != nil {
+			resp.Results = append(resp.Results, &influxql.Result{StatementID: idx, Error: err.Error()})
+			continue
+		}
+		resp.Results = append(resp.Results, q.BuildResult(idx, resultSet, param.Epoch))
+	}
+	http.OK(c, resp)
+}
+
+// getLocation returns the location for parsing time literal of LinQL based on query defaults.
+func (iq *InfluxQueryAPI) getLocation() *time.Location {
+	return queryLocation(iq.deps)
+}
+
+// queryLocation returns the location for formatting time literal of LinQL based on query defaults,
+// returns nil if query defaults state machine not exist.
+func queryLocation(deps *deps.HTTPDeps) *time.Location {
+	if deps.StateMachines == nil || deps.StateMachines.QueryDefaultsSM == nil {
+		return nil
+	}
+	return deps.StateMachines.QueryDefaultsSM.GetQueryDefaults().GetLocation()
+}
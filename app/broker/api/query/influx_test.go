@@ -0,0 +1,110 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/coordinator/broker"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/ltoml"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+)
+
+func TestInfluxQueryAPI_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	queryDefaultsSM := broker.NewMockQueryDefaultsStateMachine(ctrl)
+	queryDefaultsSM.EXPECT().GetQueryDefaults().Return(models.QueryDefaults{Timezone: "UTC"}).AnyTimes()
+
+	api := NewInfluxQueryAPI(&deps.HTTPDeps{
+		BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second)}},
+		StateMachines: &coordinator.BrokerStateMachines{QueryDefaultsSM: queryDefaultsSM},
+		QueryFactory:  queryFactory,
+	})
+	r := gin.New()
+	api.Register(r)
+
+	// param error
+	resp := mock.DoRequest(t, r, http.MethodGet, InfluxQueryPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// translate error
+	resp = mock.DoRequest(t, r, http.MethodGet, InfluxQueryPath+"?db=test&q=show+databases", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"error":"influxql: only supports select statement"`)
+
+	q := url.QueryEscape(`SELECT mean("f") FROM "cpu" WHERE time >= 1620000000000ms GROUP BY time(1m), "host"; ` +
+		`SELECT max(f) FROM cpu`)
+	var queryIDs []string
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "test",
+		`select avg("f") as "mean" from "cpu" where time>='2021-05-03 00:00:00' group by time(60s),"host"`, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, queryID string) brokerQuery.MetricQuery {
+			queryIDs = append(queryIDs, queryID)
+			return metricQuery
+		})
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "test", `select max("f") as "max" from "cpu"`, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, queryID string) brokerQuery.MetricQuery {
+			queryIDs = append(queryIDs, queryID)
+			return metricQuery
+		})
+	series := models.NewSeries(map[string]string{"host": "a"})
+	series.Fields["mean"] = map[int64]float64{1620000000000: 1}
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{Series: []*models.Series{series}}, nil)
+	metricQuery.EXPECT().WaitResponse().Return(nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, InfluxQueryPath+"?db=test&epoch=ms&q="+q, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"results":[`+
+		`{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"a"},"columns":["time","mean"],"values":[[1620000000000,1]]}]},`+
+		`{"statement_id":1,"error":"err"}]}`,
+		resp.Body.String())
+	// each statement has its own query id derived from query id of request
+	queryID := resp.Header().Get(QueryIDHeader)
+	assert.NotEmpty(t, queryID)
+	assert.Equal(t, []string{queryID + "-0", queryID + "-1"}, queryIDs)
+
+	// post form
+	req, _ := http.NewRequest(http.MethodPost, InfluxQueryPath, strings.NewReader("db=test&q=show+databases"))
+	req.Header.Set("content-type", "application/x-www-form-urlencoded")
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"error":"influxql: only supports select statement"`)
+}
+
+func TestInfluxQueryAPI_getLocation(t *testing.T) {
+	api := NewInfluxQueryAPI(&deps.HTTPDeps{})
+	assert.Nil(t, api.getLocation())
+}
@@ -18,17 +18,18 @@
 package query
 
 import (
-	"context"
 	"errors"
+	"regexp"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/lindb/lindb/app/broker/api/admin"
 	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/models"
-	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/http"
-	"github.com/lindb/lindb/series/field"
+	lindQuery "github.com/lindb/lindb/query"
+	brokerQuery "github.com/lindb/lindb/query/broker"
 	"github.com/lindb/lindb/sql"
 	"github.com/lindb/lindb/sql/stmt"
 )
@@ -65,28 +66,57 @@ func (d *MetadataAPI) Register(route gin.IRoutes) {
 // Suggest handles metadata suggest query by LinQL
 func (d *MetadataAPI) Suggest(c *gin.Context) {
 	var param struct {
-		Database string `form:"db"`
-		SQL      string `form:"sql" binding:"required"`
+		Database  string `form:"db"`
+		SQL       string `form:"sql" binding:"required"`
+		Namespace string `form:"ns"` // namespace(tenant) of query
+		// optional pagination/filter params, override the values of sql if set
+		Prefix string `form:"prefix"`
+		Regex  string `form:"regex"`
+		Limit  int    `form:"limit" binding:"min=0"`
+		Offset int    `form:"offset" binding:"min=0"`
 	}
 	err := c.ShouldBind(&param)
 	if err != nil {
 		http.Error(c, err)
 		return
 	}
-	metaQuery, err := parseSQLFunc(param.SQL)
+	statement, err := parseSQLFunc(param.SQL)
 	if err != nil {
 		http.Error(c, err)
 		return
 	}
+	if stateQuery, ok := statement.(*stmt.State); ok {
+		d.showState(c, stateQuery)
+		return
+	}
+	metaQuery := statement.(*stmt.Metadata)
+	if param.Prefix != "" {
+		metaQuery.Prefix = param.Prefix
+	}
+	if param.Regex != "" {
+		if _, err = regexp.Compile(param.Regex); err != nil {
+			http.Error(c, err)
+			return
+		}
+		metaQuery.Regex = param.Regex
+	}
+	if param.Limit > 0 {
+		metaQuery.Limit = param.Limit
+	}
+	if metaQuery.Limit > constants.MaxSuggestions {
+		metaQuery.Limit = constants.MaxSuggestions
+	}
+	metaQuery.Offset = param.Offset
 	switch metaQuery.Type {
 	case stmt.Database:
 		d.showDatabases(c)
-	case stmt.Namespace, stmt.Metric, stmt.Field, stmt.TagKey, stmt.TagValue:
+	case stmt.Namespace, stmt.Metric, stmt.Field, stmt.TagKey, stmt.TagValue,
+		stmt.SeriesCardinality, stmt.TagValueCardinality, stmt.MetricDescriptor:
 		if param.Database == "" {
 			http.Error(c, errors.New("database name required"))
 			return
 		}
-		d.suggest(c, param.Database, metaQuery)
+		d.suggest(c, param.Database, param.Namespace, metaQuery)
 	default:
 		http.Error(c, errUnknownMetadataStmt)
 	}
@@ -109,79 +139,55 @@ func (d *MetadataAPI) showDatabases(c *gin.Context) {
 	})
 }
 
+// showState shows the internal state of current broker
+func (d *MetadataAPI) showState(c *gin.Context, request *stmt.State) {
+	switch request.Type {
+	case stmt.ReplicationState:
+		http.OK(c, &models.Metadata{
+			Type:   request.Type.String(),
+			Values: d.deps.CM.Topology(),
+		})
+	default:
+		http.Error(c, errUnknownMetadataStmt)
+	}
+}
+
 // suggest executes the suggest query
-func (d *MetadataAPI) suggest(c *gin.Context, database string, request *stmt.Metadata) {
-	ctx, cancel := context.WithTimeout(context.Background(), d.deps.BrokerCfg.Query.Timeout.Duration())
+func (d *MetadataAPI) suggest(c *gin.Context, database, namespace string, request *stmt.Metadata) {
+	ctx, cancel := newQueryContext(c, d.deps.QueryTimeout())
 	defer cancel()
+	if namespace != "" {
+		ctx = lindQuery.WithNamespace(ctx, namespace)
+	}
 
 	metaDataQuery := d.deps.QueryFactory.NewMetadataQuery(ctx, database, request)
 	values, err := metaDataQuery.WaitResponse()
+	if err != nil {
+		queryError(c, err)
+		return
+	}
+	result, err := brokerQuery.BuildMetadata(request, values)
 	if err != nil {
 		http.Error(c, err)
 		return
 	}
-	switch request.Type {
-	case stmt.Field:
-		// build field result model
-		result := make(map[field.Name]field.Meta)
-		fields := field.Metas{}
-		for _, value := range values {
-			err = encoding.JSONUnmarshal([]byte(value), &fields)
-			if err != nil {
-				http.Error(c, err)
-				return
-			}
-			for _, f := range fields {
-				result[f.Name] = f
-			}
-		}
-		// HistogramSum(sum), HistogramCount(sum), HistogramMin(min), HistogramMax(max) is visible
-		// __bucket_{id}(HistogramField) is not visible for api,
-		// underlying histogram data is only restricted access by user via quantile function
-		// furthermore, we suggest some quantile functions for user in field names, such as quantile(0.99)
-		var (
-			resultFields []models.Field
-			hasHistogram bool
-		)
-		for _, f := range result {
-			if f.Type != field.HistogramField {
-				resultFields = append(resultFields, models.Field{
-					Name: string(f.Name),
-					Type: f.Type.String(),
-				})
-			} else {
-				hasHistogram = true
-			}
-		}
-		//
-		if hasHistogram {
-			resultFields = append(resultFields,
-				models.Field{Name: "quantile(0.99)", Type: field.HistogramField.String()},
-				models.Field{Name: "quantile(0.95)", Type: field.HistogramField.String()},
-				models.Field{Name: "quantile(0.90)", Type: field.HistogramField.String()},
-			)
-		}
-		http.OK(c, &models.Metadata{
-			Type:   request.Type.String(),
-			Values: resultFields,
-		})
-	default:
-		http.OK(c, &models.Metadata{
-			Type:   request.Type.String(),
-			Values: values,
-		})
+	if _, ok := result.Values.([]string); ok {
+		// pagination is only applied to plain values
+		result.Total = metaDataQuery.Total()
 	}
+	http.OK(c, result)
 }
 
-// parseSQL parses metadata query sql
-func parseSQL(ql string) (*stmt.Metadata, error) {
+// parseSQL parses metadata/state query sql
+func parseSQL(ql string) (stmt.Statement, error) {
 	query, err := sql.Parse(ql)
 	if err != nil {
 		return nil, err
 	}
-	metaQuery, ok := query.(*stmt.Metadata)
-	if !ok {
+	switch query.(type) {
+	case *stmt.Metadata, *stmt.State:
+		return query, nil
+	default:
 		return nil, errWrongQueryStmt
 	}
-	return metaQuery, nil
 }
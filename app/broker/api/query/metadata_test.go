@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 
 	"github.com/lindb/lindb/app/broker/deps"
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/coordinator"
 	"github.com/lindb/lindb/internal/mock"
 	"github.com/lindb/lindb/models"
@@ -39,6 +41,7 @@ import (
 	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/pkg/state"
 	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/replication"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/sql/stmt"
 )
@@ -67,11 +70,36 @@ func TestMetadataAPI_Handle_err(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 
 	// case 4: unknown metadata type
-	parseSQLFunc = func(ql string) (*stmt.Metadata, error) {
+	parseSQLFunc = func(ql string) (stmt.Statement, error) {
 		return &stmt.Metadata{}, nil
 	}
 	resp = mock.DoRequest(t, r, http.MethodGet, MetadataQueryPath+"?db=db&sql=select f1 from cpu", "")
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// case 5: unknown state type
+	parseSQLFunc = func(ql string) (stmt.Statement, error) {
+		return &stmt.State{}, nil
+	}
+	resp = mock.DoRequest(t, r, http.MethodGet, MetadataQueryPath+"?sql=show replication", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestMetadataAPI_ShowReplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	api := NewMetadataAPI(&deps.HTTPDeps{CM: cm})
+	r := gin.New()
+	api.Register(r)
+
+	cm.EXPECT().Topology().Return([]models.DatabaseChannelTopology{{Database: "db", NumOfShard: 1}})
+	req := httptest.NewRequest(http.MethodGet, MetadataQueryPath+"?sql=show+replication", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"type":"replication"`)
+	assert.Contains(t, resp.Body.String(), `"database":"db"`)
 }
 
 func TestMetadataAPI_ShowDatabases(t *testing.T) {
@@ -130,6 +158,7 @@ func TestMetadataAPI_SuggestCommon(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 
 	metaDataQuery.EXPECT().WaitResponse().Return([]string{"a", "b"}, nil)
+	metaDataQuery.EXPECT().Total().Return(2)
 	resp = mock.DoRequest(t, r, http.MethodGet, MetadataQueryPath+"?db=db&sql=show namespaces", "")
 	assert.Equal(t, http.StatusOK, resp.Code)
 
@@ -158,10 +187,256 @@ func TestMetadataAPI_SuggestCommon(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 }
 
+func TestMetadataAPI_SuggestPagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := brokerQuery.NewMockFactory(ctrl)
+	metaDataQuery := brokerQuery.NewMockMetaDataQuery(ctrl)
+	api := NewMetadataAPI(
+		&deps.HTTPDeps{
+			StateMachines: &coordinator.BrokerStateMachines{},
+			QueryFactory:  factory,
+			BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second * 10)}},
+		})
+	r := gin.New()
+	api.Register(r)
+
+	// case 1: bad regex
+	resp := doGet(r, MetadataQueryPath+"?db=db&sql=show+metrics&regex=%28")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 2: bad offset
+	resp = doGet(r, MetadataQueryPath+"?db=db&sql=show+metrics&offset=-1")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// case 3: params override sql
+	factory.EXPECT().NewMetadataQuery(gomock.Any(), "db", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, request *stmt.Metadata) brokerQuery.MetaDataQuery {
+			assert.Equal(t, "cpu", request.Prefix)
+			assert.Equal(t, "^cpu.*", request.Regex)
+			assert.Equal(t, 5, request.Limit)
+			assert.Equal(t, 10, request.Offset)
+			return metaDataQuery
+		})
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{"cpu.idle"}, nil)
+	metaDataQuery.EXPECT().Total().Return(11)
+	resp = doGet(r, MetadataQueryPath+"?db=db&sql=show+metrics&prefix=cpu&regex=%5Ecpu.%2A&limit=5&offset=10")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"total":11`)
+
+	// case 4: limit is capped
+	factory.EXPECT().NewMetadataQuery(gomock.Any(), "db", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, request *stmt.Metadata) brokerQuery.MetaDataQuery {
+			assert.Equal(t, constants.MaxSuggestions, request.Limit)
+			assert.Zero(t, request.Offset)
+			return metaDataQuery
+		})
+	metaDataQuery.EXPECT().WaitResponse().Return(nil, nil)
+	metaDataQuery.EXPECT().Total().Return(0)
+	resp = doGet(r, MetadataQueryPath+"?db=db&sql=show+metrics&limit=100000")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestMetadataAPI_SeriesCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := brokerQuery.NewMockFactory(ctrl)
+	metaDataQuery := brokerQuery.NewMockMetaDataQuery(ctrl)
+	factory.EXPECT().NewMetadataQuery(gomock.Any(), "db", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, request *stmt.Metadata) brokerQuery.MetaDataQuery {
+			assert.Equal(t, stmt.SeriesCardinality, request.Type)
+			assert.Equal(t, "cpu", request.MetricName)
+			return metaDataQuery
+		}).AnyTimes()
+	api := NewMetadataAPI(
+		&deps.HTTPDeps{
+			StateMachines: &coordinator.BrokerStateMachines{},
+			QueryFactory:  factory,
+			BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second * 10)}},
+		})
+	r := gin.New()
+	api.Register(r)
+	path := MetadataQueryPath + "?db=db&sql=show+series+cardinality+from+cpu+limit+1"
+
+	// case 1: bad data
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{"ddd"}, nil)
+	resp := doGet(r, path)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// case 2: bad sketch
+	partial, _ := json.Marshal(&models.SeriesCardinality{
+		MetricName: "cpu",
+		TagKeys:    []models.TagKeyCardinality{{TagKey: "host", Sketch: []byte{1}}},
+	})
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{string(partial), string(partial)}, nil)
+	resp = doGet(r, path)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// case 3: merge results of storage nodes, keep top tag keys
+	partial1, _ := json.Marshal(&models.SeriesCardinality{
+		MetricName: "cpu",
+		Series:     2,
+		TagKeys: []models.TagKeyCardinality{
+			{TagKey: "host", Values: 2, TagValues: []string{"a", "b"}},
+			{TagKey: "region", Values: 1, TagValues: []string{"sh"}},
+		},
+	})
+	partial2, _ := json.Marshal(&models.SeriesCardinality{
+		MetricName: "cpu",
+		Series:     1,
+		TagKeys: []models.TagKeyCardinality{
+			{TagKey: "host", Values: 1, TagValues: []string{"c"}},
+			{TagKey: "region", Values: 1, TagValues: []string{"sh"}},
+		},
+	})
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{string(partial1), string(partial2)}, nil)
+	resp = doGet(r, path)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	result := struct {
+		Type   string                   `json:"type"`
+		Values models.SeriesCardinality `json:"values"`
+	}{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "seriesCardinality", result.Type)
+	assert.Equal(t, models.SeriesCardinality{
+		MetricName: "cpu",
+		Series:     3,
+		TagKeys:    []models.TagKeyCardinality{{TagKey: "host", Values: 3}},
+	}, result.Values)
+
+	// case 4: no data
+	metaDataQuery.EXPECT().WaitResponse().Return(nil, nil)
+	resp = doGet(r, path)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"metricName":"cpu"`)
+}
+
+func TestMetadataAPI_TagValueCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := brokerQuery.NewMockFactory(ctrl)
+	metaDataQuery := brokerQuery.NewMockMetaDataQuery(ctrl)
+	factory.EXPECT().NewMetadataQuery(gomock.Any(), "db", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, request *stmt.Metadata) brokerQuery.MetaDataQuery {
+			assert.Equal(t, stmt.TagValueCardinality, request.Type)
+			assert.Equal(t, "cpu", request.MetricName)
+			assert.Equal(t, "host", request.TagKey)
+			assert.True(t, request.BySeries)
+			return metaDataQuery
+		}).AnyTimes()
+	api := NewMetadataAPI(
+		&deps.HTTPDeps{
+			StateMachines: &coordinator.BrokerStateMachines{},
+			QueryFactory:  factory,
+			BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second * 10)}},
+		})
+	r := gin.New()
+	api.Register(r)
+	path := MetadataQueryPath + "?db=db&sql=show+tag+values+cardinality+from+cpu+with+key%3Dhost+by+series+limit+2"
+
+	// case 1: bad data
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{"ddd"}, nil)
+	resp := doGet(r, path)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// case 2: merge results of storage nodes, keep top-k tag values
+	partial1, _ := json.Marshal(&models.TagValueCardinality{
+		MetricName: "cpu", TagKey: "host", By: "series", Window: 3600000,
+		Values: []models.TagValueCount{{TagValue: "a", Count: 5}, {TagValue: "b", Count: 3}},
+	})
+	partial2, _ := json.Marshal(&models.TagValueCardinality{
+		MetricName: "cpu", TagKey: "host", By: "series", Window: 3600000,
+		Values: []models.TagValueCount{{TagValue: "c", Count: 6}, {TagValue: "b", Count: 4}},
+	})
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{string(partial1), string(partial2)}, nil)
+	resp = doGet(r, path)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	result := struct {
+		Type   string                     `json:"type"`
+		Values models.TagValueCardinality `json:"values"`
+	}{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "tagValueCardinality", result.Type)
+	assert.Equal(t, models.TagValueCardinality{
+		MetricName: "cpu", TagKey: "host", By: "series", Window: 3600000,
+		Values: []models.TagValueCount{{TagValue: "b", Count: 7}, {TagValue: "c", Count: 6}},
+	}, result.Values)
+
+	// case 3: no data
+	metaDataQuery.EXPECT().WaitResponse().Return(nil, nil)
+	resp = doGet(r, path)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"metricName":"cpu"`)
+	assert.Contains(t, resp.Body.String(), `"tagKey":"host"`)
+}
+
+func TestMetadataAPI_MetricDescriptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := brokerQuery.NewMockFactory(ctrl)
+	metaDataQuery := brokerQuery.NewMockMetaDataQuery(ctrl)
+	factory.EXPECT().NewMetadataQuery(gomock.Any(), "db", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, request *stmt.Metadata) brokerQuery.MetaDataQuery {
+			assert.Equal(t, stmt.MetricDescriptor, request.Type)
+			assert.Equal(t, "cpu", request.MetricName)
+			return metaDataQuery
+		}).AnyTimes()
+	api := NewMetadataAPI(
+		&deps.HTTPDeps{
+			StateMachines: &coordinator.BrokerStateMachines{},
+			QueryFactory:  factory,
+			BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second * 10)}},
+		})
+	r := gin.New()
+	api.Register(r)
+	path := MetadataQueryPath + "?db=db&sql=describe+metric+cpu"
+
+	// case 1: bad data
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{"ddd"}, nil)
+	resp := doGet(r, path)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// case 2: merge descriptors of storage nodes
+	partial1, _ := json.Marshal(&models.MetricDescriptor{Namespace: "default-ns", Name: "cpu", Unit: "percent"})
+	partial2, _ := json.Marshal(&models.MetricDescriptor{Namespace: "default-ns", Name: "cpu", Description: "cpu usage"})
+	metaDataQuery.EXPECT().WaitResponse().Return([]string{string(partial1), string(partial2)}, nil)
+	resp = doGet(r, path)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	result := struct {
+		Type   string                  `json:"type"`
+		Values models.MetricDescriptor `json:"values"`
+	}{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "metricDescriptor", result.Type)
+	assert.Equal(t, models.MetricDescriptor{
+		Namespace: "default-ns", Name: "cpu", Unit: "percent", Description: "cpu usage",
+	}, result.Values)
+
+	// case 3: metric not described
+	metaDataQuery.EXPECT().WaitResponse().Return(nil, nil)
+	resp = doGet(r, path)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"name":"cpu"`)
+}
+
+func doGet(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	return resp
+}
+
 func Test_parseSQL(t *testing.T) {
 	_, err := parseSQL("")
 	assert.Error(t, err)
 
 	_, err = parseSQL("select x from y ")
 	assert.Error(t, err)
+
+	query, err := parseSQL("show replication")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.State{Type: stmt.ReplicationState}, query)
 }
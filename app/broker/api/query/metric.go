@@ -18,16 +18,22 @@
 package query
 
 import (
-	"context"
+	"errors"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/internal/tenant"
 	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/timeutil"
+	lindQuery "github.com/lindb/lindb/query"
 )
 
 var (
-	MetricQueryPath = "/query/metric"
+	MetricQueryPath         = "/query/metric"
+	MetricQueryProgressPath = "/query/:id/progress"
+	PreparedQueryPath       = "/query/prepared"
 )
 
 // MetricAPI represents the metric query api
@@ -45,28 +51,124 @@ func NewMetricAPI(deps *deps.HTTPDeps) *MetricAPI {
 // Register adds metric query url route.
 func (m *MetricAPI) Register(route gin.IRoutes) {
 	route.GET(MetricQueryPath, m.Search)
+	route.POST(PreparedQueryPath, m.PreparedSearch)
+	route.GET(MetricQueryProgressPath, m.Progress)
+}
+
+// metricQueryParam represents the param of metric query.
+type metricQueryParam struct {
+	Database string `form:"db" json:"db" binding:"required"`
+	SQL      string `form:"sql" json:"sql" binding:"required"`
+	QueryID  string `form:"id" json:"id"` // optional, used for tracking query progress
+	// optional, namespace(tenant) of query, statement can only access it if namespace isolation enabled
+	Namespace string `form:"ns" json:"ns"`
+	// optional, returns partial results if some nodes fail or time out
+	Partial bool `form:"partial" json:"partial"`
+	// optional, returns execution stats of query(series scanned, blocks decoded, bytes read, cost of stages)
+	Stats bool `form:"stats" json:"stats"`
+	// optional, returns exemplars(trace id, value, timestamp) linked to histogram buckets with quantile results
+	Exemplars bool `form:"exemplars" json:"exemplars"`
+	// optional, max points hint of series(like width of chart), downsampling interval is chosen automatically
+	// from interval hierarchy of database if points exceed it
+	MaxPoints int `form:"maxPoints" json:"maxPoints"`
+	// optional, explicit downsampling interval(like 5m), overrides the interval of group by time
+	Interval string `form:"interval" json:"interval"`
+	// values bound to placeholders(like $host) of sql, only supported by prepared query
+	Params map[string]string `form:"-" json:"params"`
 }
 
 // Search searches the metric data based on database and sql.
 func (m *MetricAPI) Search(c *gin.Context) {
-	var param struct {
-		Database string `form:"db" binding:"required"`
-		SQL      string `form:"sql" binding:"required"`
-	}
+	var param metricQueryParam
 	err := c.ShouldBind(&param)
 	if err != nil {
 		http.Error(c, err)
 		return
 	}
+	m.search(c, &param)
+}
+
+// PreparedSearch searches the metric data based on database and sql with placeholders(like
+// where host=$host and time>$start), the parameter map binds values to placeholders. Parse result
+// is cached by sql text, so structurally identical queries(like dashboard) need not be parsed again.
+func (m *MetricAPI) PreparedSearch(c *gin.Context) {
+	var param metricQueryParam
+	err := c.ShouldBindJSON(&param)
+	if err != nil {
+		http.Error(c, err)
+		return
+	}
+	if param.Params == nil {
+		// placeholder must be bound for prepared query
+		param.Params = make(map[string]string)
+	}
+	m.search(c, &param)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), m.deps.BrokerCfg.Query.Timeout.Duration())
+func (m *MetricAPI) search(c *gin.Context, param *metricQueryParam) {
+	var interval timeutil.Interval
+	if param.Interval != "" {
+		if err := interval.ValueOf(param.Interval); err != nil {
+			http.Error(c, err)
+			return
+		}
+	}
+	param.QueryID = assignQueryID(c, param.QueryID)
+
+	startTime := time.Now()
+	ctx, cancel := newQueryContext(c, m.deps.QueryTimeout())
 	defer cancel()
+	if param.Partial {
+		ctx = lindQuery.WithPartialResults(ctx)
+	}
+	if param.Stats {
+		ctx = lindQuery.WithStats(ctx)
+	}
+	if param.Exemplars {
+		ctx = lindQuery.WithExemplars(ctx)
+	}
+	if param.Params != nil {
+		ctx = lindQuery.WithParams(ctx, param.Params)
+	}
+	if param.MaxPoints > 0 {
+		ctx = lindQuery.WithMaxPoints(ctx, param.MaxPoints)
+	}
+	if interval > 0 {
+		ctx = lindQuery.WithInterval(ctx, interval)
+	}
+	if param.Namespace != "" {
+		ctx = lindQuery.WithNamespace(ctx, param.Namespace)
+	}
 
-	metricQuery := m.deps.QueryFactory.NewMetricQuery(ctx, param.Database, param.SQL)
+	metricQuery := m.deps.QueryFactory.NewMetricQuery(ctx, param.Database, param.SQL, param.QueryID)
 	resultSet, err := metricQuery.WaitResponse()
+	logSlowQuery(ctx, m.deps.SlowQueryThreshold(),
+		param.QueryID, param.Database, param.SQL, startTime, err)
 	if err != nil {
+		queryError(c, err)
+		return
+	}
+	writeResultSet(c, resultSet)
+}
+
+// queryError responses the error of query, rejection by quota/isolation of namespace is not internal error.
+func queryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, tenant.ErrQueryRateExceeded):
+		http.TooManyRequests(c, err)
+	case errors.Is(err, tenant.ErrNamespaceIsolated):
+		http.Forbidden(c, err)
+	default:
 		http.Error(c, err)
+	}
+}
+
+// Progress returns the execution progress of metric query by query id.
+func (m *MetricAPI) Progress(c *gin.Context) {
+	progress, ok := m.deps.QueryFactory.QueryProgress(c.Param("id"))
+	if !ok {
+		http.NotFound(c)
 		return
 	}
-	http.OK(c, resultSet)
+	http.OK(c, progress)
 }
@@ -18,8 +18,10 @@
 package query
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -31,8 +33,11 @@ import (
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/coordinator"
 	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/internal/tenant"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/timeutil"
+	lindQuery "github.com/lindb/lindb/query"
 	brokerQuery "github.com/lindb/lindb/query/broker"
 )
 
@@ -43,7 +48,7 @@ func TestMetricAPI_Search(t *testing.T) {
 	queryFactory := brokerQuery.NewMockFactory(ctrl)
 	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
 
-	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(metricQuery)
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(metricQuery)
 
 	api := NewMetricAPI(&deps.HTTPDeps{
 		BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second)}},
@@ -56,6 +61,38 @@ func TestMetricAPI_Search(t *testing.T) {
 	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{}, nil)
 	resp := mock.DoRequest(t, r, http.MethodGet, MetricQueryPath+"?db=test&sql=select f from cpu", "")
 	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// partial results allowed
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _, _ string) brokerQuery.MetricQuery {
+			assert.True(t, lindQuery.PartialResultsFromContext(ctx))
+			return metricQuery
+		})
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, MetricQueryPath+"?db=test&sql=select+f+from+cpu&partial=true", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// stats required
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _, _ string) brokerQuery.MetricQuery {
+			assert.True(t, lindQuery.StatsFromContext(ctx))
+			return metricQuery
+		})
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, MetricQueryPath+"?db=test&sql=select+f+from+cpu&stats=true", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// max points hint and explicit interval
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _, _ string) brokerQuery.MetricQuery {
+			assert.Equal(t, 1000, lindQuery.MaxPointsFromContext(ctx))
+			assert.Equal(t, timeutil.Interval(5*timeutil.OneMinute), lindQuery.IntervalFromContext(ctx))
+			return metricQuery
+		})
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet,
+		MetricQueryPath+"?db=test&sql=select+f+from+cpu&maxPoints=1000&interval=5m", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
 }
 
 func TestNewMetricAPI_Search_Err(t *testing.T) {
@@ -75,9 +112,91 @@ func TestNewMetricAPI_Search_Err(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 
 	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
-	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(metricQuery)
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(metricQuery)
 	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{}, fmt.Errorf("err"))
 
 	resp = mock.DoRequest(t, r, http.MethodGet, MetricQueryPath+"?db=test&sql=select f from cpu", "")
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	// bad interval
+	resp = mock.DoRequest(t, r, http.MethodGet, MetricQueryPath+"?db=test&sql=select f from cpu&interval=5x", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestMetricAPI_PreparedSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	api := NewMetricAPI(&deps.HTTPDeps{
+		BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second)}},
+		QueryFactory:  queryFactory,
+		StateMachines: &coordinator.BrokerStateMachines{}})
+	r := gin.New()
+	api.Register(r)
+
+	// param error
+	resp := mock.DoRequest(t, r, http.MethodPost, PreparedQueryPath, `{"db":"test"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "test", "select f from cpu where host=$host", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _, _ string) brokerQuery.MetricQuery {
+			assert.Equal(t, map[string]string{"host": "h1"}, lindQuery.ParamsFromContext(ctx))
+			return metricQuery
+		})
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{}, nil)
+	resp = mock.DoRequest(t, r, http.MethodPost, PreparedQueryPath,
+		`{"db":"test","sql":"select f from cpu where host=$host","params":{"host":"h1"}}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// params not set
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _, _ string) brokerQuery.MetricQuery {
+			assert.Equal(t, map[string]string{}, lindQuery.ParamsFromContext(ctx))
+			return metricQuery
+		})
+	metricQuery.EXPECT().WaitResponse().Return(nil, fmt.Errorf("parameter $host is not bound"))
+	resp = mock.DoRequest(t, r, http.MethodPost, PreparedQueryPath,
+		`{"db":"test","sql":"select f from cpu where host=$host"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestMetricAPI_Progress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	api := NewMetricAPI(&deps.HTTPDeps{
+		BrokerCfg:    &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second)}},
+		QueryFactory: queryFactory,
+	})
+	r := gin.New()
+	api.Register(r)
+
+	// query not found
+	queryFactory.EXPECT().QueryProgress("q1").Return(nil, false)
+	resp := mock.DoRequest(t, r, http.MethodGet, "/query/q1/progress", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	queryFactory.EXPECT().QueryProgress("q1").Return(&models.QueryProgress{QueryID: "q1", TotalTasks: 2}, true)
+	resp = mock.DoRequest(t, r, http.MethodGet, "/query/q1/progress", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func Test_queryError(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{err: fmt.Errorf("err"), code: http.StatusInternalServerError},
+		{err: fmt.Errorf("%w(ns)", tenant.ErrQueryRateExceeded), code: http.StatusTooManyRequests},
+		{err: fmt.Errorf("%w, tenant: ns", tenant.ErrNamespaceIsolated), code: http.StatusForbidden},
+	}
+	for _, tt := range cases {
+		resp := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(resp)
+		queryError(c, tt.err)
+		assert.Equal(t, tt.code, resp.Code)
+	}
 }
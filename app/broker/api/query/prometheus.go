@@ -0,0 +1,378 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/sql"
+	"github.com/lindb/lindb/sql/promql"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+var (
+	// Prometheus http api is served under prefix, so that Grafana can use it as Prometheus datasource
+	// with url http://broker:port/api/v1/prometheus, database is given by custom query parameter db.
+	PrometheusQueryPath       = "/prometheus/api/v1/query"
+	PrometheusQueryRangePath  = "/prometheus/api/v1/query_range"
+	PrometheusLabelsPath      = "/prometheus/api/v1/labels"
+	PrometheusLabelValuesPath = "/prometheus/api/v1/label/:name/values"
+)
+
+var errDatabaseRequired = errors.New("database name required, set by parameter db")
+
+const (
+	// lookback of instant query, the latest point in lookback is the value of series
+	promLookback = 5 * time.Minute
+	// max num. of label values
+	promLabelValuesLimit = 1000
+)
+
+// PrometheusQueryAPI represents the query api compatible with Prometheus http api,
+// translates the subset of PromQL into LinQL, used by Grafana Prometheus datasource without custom plugin.
+type PrometheusQueryAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewPrometheusQueryAPI creates the prometheus query api
+func NewPrometheusQueryAPI(deps *deps.HTTPDeps) *PrometheusQueryAPI {
+	return &PrometheusQueryAPI{
+		deps: deps,
+	}
+}
+
+// Register adds prometheus query url route.
+func (pq *PrometheusQueryAPI) Register(route gin.IRoutes) {
+	route.GET(PrometheusQueryPath, pq.Query)
+	route.POST(PrometheusQueryPath, pq.Query)
+	route.GET(PrometheusQueryRangePath, pq.QueryRange)
+	route.POST(PrometheusQueryRangePath, pq.QueryRange)
+	route.GET(PrometheusLabelsPath, pq.Labels)
+	route.POST(PrometheusLabelsPath, pq.Labels)
+	route.GET(PrometheusLabelValuesPath, pq.LabelValues)
+}
+
+// Query evaluates the instant query at given time, the latest point in lookback window is the value of series.
+func (pq *PrometheusQueryAPI) Query(c *gin.Context) {
+	var param struct {
+		Database string `form:"db"`
+		Query    string `form:"query"`
+		Time     string `form:"time"`
+	}
+	if err := bindPromParam(c, &param); err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	evalTime := time.Now()
+	if param.Time != "" {
+		tm, err := parsePromTime(param.Time)
+		if err != nil {
+			http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+			return
+		}
+		evalTime = tm
+	}
+	q, err := promql.Translate(param.Query)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	if q.Scalar {
+		http.OK(c, promql.Success(&promql.QueryData{ResultType: promql.ResultTypeScalar, Result: q.BuildScalar(evalTime)}))
+		return
+	}
+	rs, err := pq.execute(c, param.Database, q, evalTime.Add(-promLookback), evalTime, promLookback)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeExecution, err))
+		return
+	}
+	http.OK(c, promql.Success(&promql.QueryData{ResultType: promql.ResultTypeVector, Result: q.BuildVector(rs, evalTime)}))
+}
+
+// QueryRange evaluates the range query in time range [start, end] with step.
+func (pq *PrometheusQueryAPI) QueryRange(c *gin.Context) {
+	var param struct {
+		Database string `form:"db"`
+		Query    string `form:"query"`
+		Start    string `form:"start" binding:"required"`
+		End      string `form:"end" binding:"required"`
+		Step     string `form:"step" binding:"required"`
+	}
+	if err := bindPromParam(c, &param); err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	start, err := parsePromTime(param.Start)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	end, err := parsePromTime(param.End)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	step, err := promql.ParseDuration(param.Step)
+	if err != nil || step <= 0 {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, fmt.Errorf("invalid step %s", param.Step)))
+		return
+	}
+	if end.Before(start) {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, fmt.Errorf("end timestamp must not be before start time")))
+		return
+	}
+	q, err := promql.Translate(param.Query)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	if q.Scalar {
+		http.OK(c, promql.Success(&promql.QueryData{
+			ResultType: promql.ResultTypeMatrix,
+			Result:     q.BuildScalarMatrix(start, end, step),
+		}))
+		return
+	}
+	rs, err := pq.execute(c, param.Database, q, start, end, step)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeExecution, err))
+		return
+	}
+	http.OK(c, promql.Success(&promql.QueryData{ResultType: promql.ResultTypeMatrix, Result: q.BuildMatrix(rs)}))
+}
+
+// Labels returns the label names of metrics selected by match[], includes __name__ and __field__.
+func (pq *PrometheusQueryAPI) Labels(c *gin.Context) {
+	queries, database, err := pq.parseMatches(c)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	ctx, cancel := newQueryContext(c, pq.deps.QueryTimeout())
+	defer cancel()
+
+	labels := map[string]struct{}{promql.MetricNameLabel: {}, promql.FieldNameLabel: {}}
+	for _, q := range queries {
+		tagKeys, err := pq.metadata(ctx, database, q.ShowTagKeysSQL())
+		if err != nil {
+			http.OK(c, promql.Fail(promql.ErrorTypeExecution, err))
+			return
+		}
+		for _, tagKey := range tagKeys {
+			labels[tagKey] = struct{}{}
+		}
+	}
+	http.OK(c, promql.Success(sortedKeys(labels)))
+}
+
+// LabelValues returns the values of label, metric names for __name__, field names or tag values of metrics
+// selected by match[] for others.
+func (pq *PrometheusQueryAPI) LabelValues(c *gin.Context) {
+	queries, database, err := pq.parseMatches(c)
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeBadData, err))
+		return
+	}
+	ctx, cancel := newQueryContext(c, pq.deps.QueryTimeout())
+	defer cancel()
+
+	name := c.Param("name")
+	values := make(map[string]struct{})
+	collect := func(ql string) error {
+		result, err := pq.metadata(ctx, database, ql)
+		if err != nil {
+			return err
+		}
+		for _, value := range result {
+			values[value] = struct{}{}
+		}
+		return nil
+	}
+	switch name {
+	case promql.MetricNameLabel:
+		err = collect("show metrics limit " + strconv.Itoa(promLabelValuesLimit))
+	case promql.FieldNameLabel:
+		for _, q := range queries {
+			var fields []string
+			if fields, err = pq.fields(ctx, database, q); err != nil {
+				break
+			}
+			for _, f := range fields {
+				values[f] = struct{}{}
+			}
+		}
+	default:
+		for _, q := range queries {
+			if err = collect(q.ShowTagValuesSQL(name, promLabelValuesLimit)); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		http.OK(c, promql.Fail(promql.ErrorTypeExecution, err))
+		return
+	}
+	http.OK(c, promql.Success(sortedKeys(values)))
+}
+
+// execute executes the metric query translated from PromQL.
+func (pq *PrometheusQueryAPI) execute(c *gin.Context, database string, q *promql.Query,
+	start, end time.Time, step time.Duration,
+) (*models.ResultSet, error) {
+	if database == "" {
+		return nil, errDatabaseRequired
+	}
+	ctx, cancel := newQueryContext(c, pq.deps.QueryTimeout())
+	defer cancel()
+
+	if q.Field == "" {
+		fields, err := pq.fields(ctx, database, q)
+		if err != nil {
+			return nil, err
+		}
+		switch len(fields) {
+		case 0:
+			return nil, fmt.Errorf("metric %s not found", q.Metric)
+		case 1:
+			q.Field = fields[0]
+		default:
+			return nil, fmt.Errorf("metric %s has multiple fields %v, selects one by label %s",
+				q.Metric, fields, promql.FieldNameLabel)
+		}
+	}
+	if q.GroupByAll {
+		// keeps all series like Prometheus, group by all tag keys of metric
+		tagKeys, err := pq.metadata(ctx, database, q.ShowTagKeysSQL())
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(tagKeys)
+		q.GroupBy = tagKeys
+	}
+	queryID := assignQueryID(c, "")
+	linQL := q.SQL(start, end, step, queryLocation(pq.deps))
+	startTime := time.Now()
+	metricQuery := pq.deps.QueryFactory.NewMetricQuery(ctx, database, linQL, queryID)
+	rs, err := metricQuery.WaitResponse()
+	logSlowQuery(ctx, pq.deps.SlowQueryThreshold(), queryID, database, linQL, startTime, err)
+	return rs, err
+}
+
+// fields returns the visible field names of metric, histogram field is invisible.
+func (pq *PrometheusQueryAPI) fields(ctx context.Context, database string, q *promql.Query) ([]string, error) {
+	values, err := pq.metadata(ctx, database, q.ShowFieldsSQL())
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]struct{})
+	for _, value := range values {
+		fields := field.Metas{}
+		if err := encoding.JSONUnmarshal([]byte(value), &fields); err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			if f.Type != field.HistogramField {
+				names[string(f.Name)] = struct{}{}
+			}
+		}
+	}
+	return sortedKeys(names), nil
+}
+
+// metadata executes the metadata query of LinQL.
+func (pq *PrometheusQueryAPI) metadata(ctx context.Context, database, ql string) ([]string, error) {
+	if database == "" {
+		return nil, errDatabaseRequired
+	}
+	statement, err := sql.Parse(ql)
+	if err != nil {
+		return nil, err
+	}
+	metadataStmt, ok := statement.(*stmt.Metadata)
+	if !ok {
+		return nil, errWrongQueryStmt
+	}
+	return pq.deps.QueryFactory.NewMetadataQuery(ctx, database, metadataStmt).WaitResponse()
+}
+
+// parseMatches parses the database and series selectors of match[] parameter.
+func (pq *PrometheusQueryAPI) parseMatches(c *gin.Context) (queries []*promql.Query, database string, err error) {
+	var param struct {
+		Database string   `form:"db"`
+		Matches  []string `form:"match[]"`
+	}
+	if err := bindPromParam(c, &param); err != nil {
+		return nil, "", err
+	}
+	for _, match := range param.Matches {
+		q, err := promql.Translate(match)
+		if err != nil {
+			return nil, "", err
+		}
+		if !q.GroupByAll {
+			return nil, "", fmt.Errorf("invalid series selector %s", match)
+		}
+		queries = append(queries, q)
+	}
+	return queries, param.Database, nil
+}
+
+// bindPromParam binds the parameters of Prometheus http api, GET with query string, or POST with url-encoded form.
+func bindPromParam(c *gin.Context, param interface{}) error {
+	var b binding.Binding = binding.Query
+	if c.Request.Method == "POST" {
+		b = binding.Form
+	}
+	return c.ShouldBindWith(param, b)
+}
+
+// parsePromTime parses the time parameter of Prometheus http api, unix timestamp in seconds or RFC3339.
+func parsePromTime(str string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(str, 64); err == nil {
+		s, ns := math.Modf(seconds)
+		return time.Unix(int64(s), int64(math.Round(ns*1000))*int64(time.Millisecond)), nil
+	}
+	if tm, err := time.Parse(time.RFC3339Nano, str); err == nil {
+		return tm, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", str)
+}
+
+// sortedKeys returns the sorted keys of set.
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
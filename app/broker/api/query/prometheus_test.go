@@ -0,0 +1,271 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/coordinator/broker"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/ltoml"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func newTestPrometheusAPI(ctrl *gomock.Controller, queryFactory brokerQuery.Factory) *gin.Engine {
+	queryDefaultsSM := broker.NewMockQueryDefaultsStateMachine(ctrl)
+	queryDefaultsSM.EXPECT().GetQueryDefaults().Return(models.QueryDefaults{Timezone: "UTC"}).AnyTimes()
+	api := NewPrometheusQueryAPI(&deps.HTTPDeps{
+		BrokerCfg:     &config.BrokerBase{Query: config.Query{Timeout: ltoml.Duration(time.Second)}},
+		StateMachines: &coordinator.BrokerStateMachines{QueryDefaultsSM: queryDefaultsSM},
+		QueryFactory:  queryFactory,
+	})
+	r := gin.New()
+	api.Register(r)
+	return r
+}
+
+func expectMetadata(ctrl *gomock.Controller, queryFactory *brokerQuery.MockFactory,
+	metricName string, typ stmt.MetadataType, values []string, err error,
+) {
+	metadataQuery := brokerQuery.NewMockMetaDataQuery(ctrl)
+	queryFactory.EXPECT().NewMetadataQuery(gomock.Any(), "test", gomock.Any()).
+		DoAndReturn(func(_, _ interface{}, request *stmt.Metadata) brokerQuery.MetaDataQuery {
+			if request.MetricName != metricName || request.Type != typ {
+				panic(fmt.Sprintf("unexpected metadata query %v", request))
+			}
+			return metadataQuery
+		})
+	metadataQuery.EXPECT().WaitResponse().Return(values, err)
+}
+
+func TestPrometheusQueryAPI_QueryRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	r := newTestPrometheusAPI(ctrl, queryFactory)
+	rangeParam := "&start=1620000000&end=2021-05-03T01:00:00Z&step=60"
+	fields := string(encoding.JSONMarshal(&field.Metas{
+		{Name: "counter", Type: field.SumField},
+		{Name: "__bucket_0", Type: field.HistogramField},
+	}))
+
+	// bad data
+	for _, param := range []string{
+		"",
+		"?query=cpu&start=a&end=1&step=1",
+		"?query=cpu&start=1&end=a&step=1",
+		"?query=cpu&start=1&end=2&step=a",
+		"?query=cpu&start=1&end=2&step=0",
+		"?query=cpu&start=2&end=1&step=1",
+		"?query=cpu+1" + rangeParam,
+	} {
+		resp := mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+param, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"errorType":"bad_data"`, param)
+	}
+	// scalar
+	resp := mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+"?query=1&start=0&end=60&step=60", "")
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix",`+
+		`"result":[{"metric":{},"values":[[0,"1"],[60,"1"]]}]}}`, resp.Body.String())
+	// database required
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+"?query=cpu"+rangeParam, "")
+	assert.Contains(t, resp.Body.String(), `"error":"database name required, set by parameter db"`)
+	// resolve field failure
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+"?db=test&query=cpu"+rangeParam, "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+	// metric not found
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, nil, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+"?db=test&query=cpu"+rangeParam, "")
+	assert.Contains(t, resp.Body.String(), `"error":"metric cpu not found"`)
+	// multiple fields
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, []string{
+		string(encoding.JSONMarshal(&field.Metas{{Name: "a", Type: field.SumField}, {Name: "b", Type: field.GaugeField}})),
+	}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+"?db=test&query=cpu"+rangeParam, "")
+	assert.Contains(t, resp.Body.String(), `has multiple fields [a b]`)
+	// bad field metas
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, []string{"abc"}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+"?db=test&query=cpu"+rangeParam, "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+	// tag keys failure
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, []string{fields}, nil)
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.TagKey, nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusQueryRangePath+"?db=test&query=cpu"+rangeParam, "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+
+	// query all series, group by all tag keys
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, []string{fields}, nil)
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.TagKey, []string{"region", "host"}, nil)
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "test",
+		`select "counter" as "value" from "cpu" where "host"='a' and `+
+			`time>='2021-05-03 00:00:00' and time<='2021-05-03 01:00:00' group by time(60s),"host","region"`,
+		gomock.Any()).Return(metricQuery)
+	series := models.NewSeries(map[string]string{"host": "a", "region": "sh"})
+	series.Fields["value"] = map[int64]float64{1620000000000: 1}
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{Series: []*models.Series{series}}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet,
+		PrometheusQueryRangePath+"?db=test&query="+url.QueryEscape(`cpu{host="a"}`)+rangeParam, "")
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[`+
+		`{"metric":{"__name__":"cpu","host":"a","region":"sh"},"values":[[1620000000,"1"]]}]}}`, resp.Body.String())
+	assert.NotEmpty(t, resp.Header().Get(QueryIDHeader))
+
+	// aggregation with field, post form
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "test",
+		`select sum("f") as "value" from "cpu" where `+
+			`time>='2021-05-03 00:00:00' and time<='2021-05-03 01:00:00' group by time(60s)`,
+		gomock.Any()).Return(metricQuery)
+	metricQuery.EXPECT().WaitResponse().Return(nil, fmt.Errorf("err"))
+	form := "db=test&query=" + url.QueryEscape(`sum(cpu{__field__="f"})`) + rangeParam
+	req, _ := http.NewRequest(http.MethodPost, PrometheusQueryRangePath, strings.NewReader(form))
+	req.Header.Set("content-type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.JSONEq(t, `{"status":"error","errorType":"execution","error":"err"}`, rec.Body.String())
+}
+
+func TestPrometheusQueryAPI_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	r := newTestPrometheusAPI(ctrl, queryFactory)
+
+	// bad data
+	for _, param := range []string{"?query=1&time=a", "?query=cpu+1"} {
+		resp := mock.DoRequest(t, r, http.MethodGet, PrometheusQueryPath+param, "")
+		assert.Contains(t, resp.Body.String(), `"errorType":"bad_data"`, param)
+	}
+	// scalar, used by Grafana datasource health check
+	resp := mock.DoRequest(t, r, http.MethodGet, PrometheusQueryPath+"?query=1&time=100.5", "")
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"scalar","result":[100.5,"1"]}}`, resp.Body.String())
+
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "test",
+		`select sum("f") as "value" from "cpu" where `+
+			`time>='2021-05-03 00:55:00' and time<='2021-05-03 01:00:00' group by time(300s),"host"`,
+		gomock.Any()).Return(metricQuery)
+	series := models.NewSeries(map[string]string{"host": "a"})
+	series.Fields["value"] = map[int64]float64{1620000000000: 1, 1620003300000: 2}
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{Series: []*models.Series{series}}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet,
+		PrometheusQueryPath+"?db=test&time=1620003600&query="+url.QueryEscape(`sum by (host) (cpu{__field__="f"})`), "")
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[`+
+		`{"metric":{"host":"a"},"value":[1620003600,"2"]}]}}`, resp.Body.String())
+
+	// execute failure
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusQueryPath+"?query=cpu", "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+}
+
+func TestPrometheusQueryAPI_Labels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	r := newTestPrometheusAPI(ctrl, queryFactory)
+
+	// bad match
+	for _, param := range []string{"?db=test&match[]=cpu+1", "?db=test&match[]=sum(cpu)"} {
+		resp := mock.DoRequest(t, r, http.MethodGet, PrometheusLabelsPath+param, "")
+		assert.Contains(t, resp.Body.String(), `"errorType":"bad_data"`, param)
+		resp = mock.DoRequest(t, r, http.MethodGet, strings.Replace(PrometheusLabelValuesPath, ":name", "host", 1)+param, "")
+		assert.Contains(t, resp.Body.String(), `"errorType":"bad_data"`, param)
+	}
+	// no match
+	resp := mock.DoRequest(t, r, http.MethodGet, PrometheusLabelsPath, "")
+	assert.JSONEq(t, `{"status":"success","data":["__field__","__name__"]}`, resp.Body.String())
+	// tag keys
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.TagKey, []string{"host"}, nil)
+	expectMetadata(ctrl, queryFactory, "mem", stmt.TagKey, []string{"host", "region"}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusLabelsPath+"?db=test&match[]=cpu&match[]=mem", "")
+	assert.JSONEq(t, `{"status":"success","data":["__field__","__name__","host","region"]}`, resp.Body.String())
+	// failure
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.TagKey, nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, PrometheusLabelsPath+"?db=test&match[]=cpu", "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+}
+
+func TestPrometheusQueryAPI_LabelValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	r := newTestPrometheusAPI(ctrl, queryFactory)
+	path := func(name string) string {
+		return strings.Replace(PrometheusLabelValuesPath, ":name", name, 1)
+	}
+
+	// metric names
+	expectMetadata(ctrl, queryFactory, "", stmt.Metric, []string{"mem", "cpu"}, nil)
+	resp := mock.DoRequest(t, r, http.MethodGet, path("__name__")+"?db=test", "")
+	assert.JSONEq(t, `{"status":"success","data":["cpu","mem"]}`, resp.Body.String())
+	// metric names without database
+	resp = mock.DoRequest(t, r, http.MethodGet, path("__name__"), "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+	// field names
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, []string{
+		string(encoding.JSONMarshal(&field.Metas{{Name: "f", Type: field.SumField}})),
+	}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, path("__field__")+"?db=test&match[]=cpu", "")
+	assert.JSONEq(t, `{"status":"success","data":["f"]}`, resp.Body.String())
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.Field, nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, path("__field__")+"?db=test&match[]=cpu", "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+	// tag values
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.TagValue, []string{"b", "a"}, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet,
+		path("host")+"?db=test&match[]="+url.QueryEscape(`cpu{region="sh"}`), "")
+	assert.JSONEq(t, `{"status":"success","data":["a","b"]}`, resp.Body.String())
+	expectMetadata(ctrl, queryFactory, "cpu", stmt.TagValue, nil, fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodGet, path("host")+"?db=test&match[]=cpu", "")
+	assert.Contains(t, resp.Body.String(), `"errorType":"execution"`)
+	// no match
+	resp = mock.DoRequest(t, r, http.MethodGet, path("host")+"?db=test", "")
+	assert.JSONEq(t, `{"status":"success","data":[]}`, resp.Body.String())
+}
+
+func TestParsePromTime(t *testing.T) {
+	tm, err := parsePromTime("1620000000.123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1620000000123), tm.UnixNano()/int64(time.Millisecond))
+	tm, err = parsePromTime("2021-05-03T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1620000000), tm.Unix())
+	_, err = parsePromTime("abc")
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/internal/tracing"
+	"github.com/lindb/lindb/pkg/logger"
+	lindQuery "github.com/lindb/lindb/query"
+)
+
+// QueryIDHeader is the header of query id, which ties together logs of all nodes for one query.
+const QueryIDHeader = "X-LinDB-Query-ID"
+
+var slowQueryLogger = logger.GetLogger("broker", "SlowQuery")
+
+// assignQueryID assigns a cluster-wide query id if client doesn't specify, returns it in response header.
+func assignQueryID(c *gin.Context, queryID string) string {
+	if queryID == "" {
+		queryID = lindQuery.NewQueryID()
+	}
+	c.Header(QueryIDHeader, queryID)
+	return queryID
+}
+
+// newQueryContext creates the query context with timeout, which carries the request id of http request,
+// the request id is forwarded to storage nodes with task request.
+func newQueryContext(c *gin.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if c.Request != nil {
+		if requestID := tracing.RequestIDFromContext(c.Request.Context()); requestID != "" {
+			ctx = tracing.WithRequestID(ctx, requestID)
+		}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// logSlowQuery logs the query which takes longer than threshold, 0 threshold means disabled.
+func logSlowQuery(ctx context.Context, threshold time.Duration, queryID, database, sql string,
+	startTime time.Time, err error,
+) {
+	cost := time.Since(startTime)
+	if threshold <= 0 || cost < threshold {
+		return
+	}
+	slowQueryLogger.Warn("slow query",
+		logger.String("queryID", queryID),
+		logger.String("requestID", tracing.RequestIDFromContext(ctx)),
+		logger.String("db", database),
+		logger.String("sql", sql),
+		logger.String("cost", cost.String()),
+		logger.Error(err))
+}
@@ -0,0 +1,71 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/internal/tracing"
+)
+
+func TestAssignQueryID(t *testing.T) {
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	queryID := assignQueryID(c, "")
+	assert.NotEmpty(t, queryID)
+	assert.Equal(t, queryID, resp.Header().Get(QueryIDHeader))
+
+	resp = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(resp)
+	assert.Equal(t, "q1", assignQueryID(c, "q1"))
+	assert.Equal(t, "q1", resp.Header().Get(QueryIDHeader))
+}
+
+func TestNewQueryContext(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx, cancel := newQueryContext(c, time.Second)
+	assert.Empty(t, tracing.RequestIDFromContext(ctx))
+	cancel()
+
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	c.Request = c.Request.WithContext(tracing.WithRequestID(c.Request.Context(), "r1"))
+	ctx, cancel = newQueryContext(c, time.Second)
+	defer cancel()
+	assert.Equal(t, "r1", tracing.RequestIDFromContext(ctx))
+	_, ok := ctx.Deadline()
+	assert.True(t, ok)
+}
+
+func TestLogSlowQuery(t *testing.T) {
+	startTime := time.Now().Add(-time.Second)
+	ctx := tracing.WithRequestID(context.TODO(), "r1")
+	// disabled
+	logSlowQuery(ctx, 0, "q1", "db", "select f from cpu", startTime, nil)
+	// fast query
+	logSlowQuery(ctx, time.Minute, "q1", "db", "select f from cpu", startTime, nil)
+	// slow query
+	logSlowQuery(ctx, time.Millisecond, "q1", "db", "select f from cpu", startTime, nil)
+	logSlowQuery(ctx, time.Millisecond, "q1", "db", "select f from cpu", startTime, fmt.Errorf("err"))
+}
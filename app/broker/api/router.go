@@ -25,7 +25,12 @@ import (
 	"github.com/lindb/lindb/app/broker/api/query"
 	"github.com/lindb/lindb/app/broker/api/state"
 	"github.com/lindb/lindb/app/broker/api/write"
+	"github.com/lindb/lindb/app/broker/audit"
 	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/app/broker/middleware"
+	"github.com/lindb/lindb/config"
+	httppkg "github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/server"
 )
 
 // API represents broker http api.
@@ -33,14 +38,32 @@ type API struct {
 	master          *cluster.MasterAPI
 	database        *admin.DatabaseAPI
 	flusher         *admin.DatabaseFlusherAPI
+	replicaVerify   *admin.ReplicaVerifyAPI
 	storage         *admin.StorageClusterAPI
+	queryDefaults   *admin.QueryDefaultsAPI
+	clusterConfig   *admin.ClusterConfigAPI
+	schema          *admin.SchemaAPI
+	alertRule       *admin.AlertRuleAPI
+	auditLog        *admin.AuditLogAPI
+	login           *LoginAPI
+	logger          *httppkg.LoggerAPI
+	drain           *httppkg.DrainAPI
 	brokerState     *state.BrokerAPI
 	storageState    *state.StorageAPI
+	health          *state.HealthAPI
+	databaseStats   *state.DatabaseStatsAPI
 	prometheus      *write.PrometheusWriter
 	influxIngestion *write.InfluxWriter
 	nativeIngestion *write.NativeWriter
+	descriptor      *write.MetricDescriptorWriter
 	metric          *query.MetricAPI
 	metadata        *query.MetadataAPI
+	influxQuery     *query.InfluxQueryAPI
+	promQuery       *query.PrometheusQueryAPI
+	federation      *query.FederationAPI
+
+	drainer server.Drainer
+	auditor audit.Log
 }
 
 // NewAPI creates broker http api.
@@ -49,30 +72,78 @@ func NewAPI(deps *deps.HTTPDeps) *API {
 		master:          cluster.NewMasterAPI(deps),
 		database:        admin.NewDatabaseAPI(deps),
 		flusher:         admin.NewDatabaseFlusherAPI(deps),
+		replicaVerify:   admin.NewReplicaVerifyAPI(deps),
 		storage:         admin.NewStorageClusterAPI(deps),
+		queryDefaults:   admin.NewQueryDefaultsAPI(deps),
+		clusterConfig:   admin.NewClusterConfigAPI(deps),
+		schema:          admin.NewSchemaAPI(deps),
+		alertRule:       admin.NewAlertRuleAPI(deps),
+		auditLog:        admin.NewAuditLogAPI(deps),
+		login:           NewLoginAPI(loginUser(deps), deps.AuditLog),
+		logger:          httppkg.NewLoggerAPI(),
+		drain:           httppkg.NewDrainAPI(deps.Drainer),
 		brokerState:     state.NewBrokerAPI(deps),
 		storageState:    state.NewStorageAPI(deps),
+		health:          state.NewHealthAPI(deps),
+		databaseStats:   state.NewDatabaseStatsAPI(deps),
 		prometheus:      write.NewPrometheusWriter(deps),
 		influxIngestion: write.NewInfluxWriter(deps),
 		nativeIngestion: write.NewNativeWriter(deps),
+		descriptor:      write.NewMetricDescriptorWriter(deps),
 		metric:          query.NewMetricAPI(deps),
 		metadata:        query.NewMetadataAPI(deps),
+		influxQuery:     query.NewInfluxQueryAPI(deps),
+		promQuery:       query.NewPrometheusQueryAPI(deps),
+		federation:      query.NewFederationAPI(deps),
+		drainer:         deps.Drainer,
+		auditor:         deps.AuditLog,
 	}
 }
 
-// RegisterRouter registers v1 http api router.
+// loginUser returns the admin user of broker config.
+func loginUser(deps *deps.HTTPDeps) config.User {
+	if deps.BrokerCfg == nil {
+		return config.User{}
+	}
+	return deps.BrokerCfg.User
+}
+
+// RegisterRouter registers v1 http api router,
+// each api is registered with its operation type for authorization,
+// admin mutations are recorded into audit log, new writes/queries are rejected when broker is draining.
 func (api *API) RegisterRouter(router *gin.RouterGroup) {
-	api.master.Register(router)
-	api.database.Register(router)
-	api.flusher.Register(router)
-	api.storage.Register(router)
+	rejectWhenDraining := httppkg.RejectWhenDraining(api.drainer)
+	adminRouter := router.Group("", middleware.Audit(api.auditor), middleware.Authorize(middleware.OperationAdmin))
+	readRouter := router.Group("", middleware.Authorize(middleware.OperationRead))
+	queryRouter := readRouter.Group("", rejectWhenDraining)
+	writeRouter := router.Group("", middleware.Authorize(middleware.OperationWrite), rejectWhenDraining)
+
+	api.login.Register(router)
+	api.master.Register(readRouter)
+	api.database.Register(adminRouter)
+	api.flusher.Register(adminRouter)
+	api.replicaVerify.Register(adminRouter)
+	api.storage.Register(adminRouter)
+	api.queryDefaults.Register(adminRouter)
+	api.clusterConfig.Register(adminRouter)
+	api.schema.Register(adminRouter)
+	api.alertRule.Register(adminRouter)
+	api.auditLog.Register(adminRouter)
+	api.logger.Register(adminRouter)
+	api.drain.Register(adminRouter)
 
-	api.brokerState.Register(router)
-	api.storageState.Register(router)
+	api.brokerState.Register(readRouter)
+	api.storageState.Register(readRouter)
+	api.health.Register(readRouter)
+	api.databaseStats.Register(readRouter)
 
-	api.metadata.Register(router)
-	api.metric.Register(router)
-	api.influxIngestion.Register(router)
-	api.nativeIngestion.Register(router)
-	api.prometheus.Register(router)
+	api.metadata.Register(queryRouter)
+	api.metric.Register(queryRouter)
+	api.influxQuery.Register(queryRouter)
+	api.promQuery.Register(queryRouter)
+	api.federation.Register(queryRouter)
+	api.influxIngestion.Register(writeRouter)
+	api.nativeIngestion.Register(writeRouter)
+	api.prometheus.Register(writeRouter)
+	api.descriptor.Register(writeRouter)
 }
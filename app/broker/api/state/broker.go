@@ -27,10 +27,14 @@ import (
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/replication"
 )
 
 var (
-	BrokerStatePath = "/broker/cluster/state"
+	BrokerStatePath       = "/broker/cluster/state"
+	BrokerReplicationPath = "/broker/replication/topology"
+	BrokerDeadLetterPath  = "/broker/dead-letter"
+	BrokerHashRingPath    = "/broker/replication/hash-ring"
 )
 
 // BrokerAPI represents query broker state api from broker state machine.
@@ -48,6 +52,49 @@ func NewBrokerAPI(deps *deps.HTTPDeps) *BrokerAPI {
 // Register adds broker state url route.
 func (s *BrokerAPI) Register(route gin.IRoutes) {
 	route.GET(BrokerStatePath, s.ListBrokersState)
+	route.GET(BrokerReplicationPath, s.ReplicationTopology)
+	route.GET(BrokerDeadLetterPath, s.RecentRejectedMetrics)
+	route.GET(BrokerHashRingPath, s.HashRing)
+}
+
+// ReplicationTopology returns the replication channel topology of current broker,
+// database -> shard -> target storage node with buffer backlog and wal sequence.
+func (s *BrokerAPI) ReplicationTopology(c *gin.Context) {
+	http.OK(c, s.deps.CM.Topology())
+}
+
+// HashRing returns the routing(modulo or consistent hash ring) which maps series to shard of given database,
+// with the ratio of hash space owned by each shard.
+func (s *BrokerAPI) HashRing(c *gin.Context) {
+	var param struct {
+		Database string `form:"db" binding:"required"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	ring, ok := s.deps.CM.HashRing(param.Database)
+	if !ok {
+		http.NotFound(c)
+		return
+	}
+	http.OK(c, ring)
+}
+
+// RecentRejectedMetrics returns recent metrics rejected by validation of current broker, the newest first.
+func (s *BrokerAPI) RecentRejectedMetrics(c *gin.Context) {
+	var param struct {
+		Limit int `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if s.deps.DeadLetter == nil {
+		http.OK(c, []replication.RejectedMetric{})
+		return
+	}
+	http.OK(c, s.deps.DeadLetter.Recent(param.Limit))
 }
 
 // ListBrokersState returns brokers state.
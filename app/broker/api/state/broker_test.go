@@ -38,6 +38,7 @@ import (
 	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/pkg/state"
 	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/replication"
 )
 
 func TestBrokerAPI_ListBrokersStat(t *testing.T) {
@@ -104,3 +105,93 @@ func TestBrokerAPI_ListBrokersStat(t *testing.T) {
 	resp = mock.DoRequest(t, r, http.MethodGet, BrokerStatePath, "")
 	assert.Equal(t, http.StatusOK, resp.Code)
 }
+
+func TestBrokerAPI_ReplicationTopology(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	api := NewBrokerAPI(&deps.HTTPDeps{CM: cm})
+	r := gin.New()
+	api.Register(r)
+
+	cm.EXPECT().Topology().Return([]models.DatabaseChannelTopology{
+		{
+			Database:   "db",
+			NumOfShard: 1,
+			Shards: []models.ShardChannelTopology{{
+				ShardID:   0,
+				AppendSeq: 10,
+				Replicas:  []models.ReplicaState{{Database: "db", Target: models.Node{IP: "1.1.1.1", Port: 2080}}},
+			}},
+		},
+	})
+	resp := mock.DoRequest(t, r, http.MethodGet, BrokerReplicationPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var topology []models.DatabaseChannelTopology
+	assert.NoError(t, encoding.JSONUnmarshal(resp.Body.Bytes(), &topology))
+	assert.Len(t, topology, 1)
+	assert.Equal(t, int64(10), topology[0].Shards[0].AppendSeq)
+}
+
+func TestBrokerAPI_RecentRejectedMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// dead letter not set
+	api := NewBrokerAPI(&deps.HTTPDeps{})
+	r := gin.New()
+	api.Register(r)
+	resp := mock.DoRequest(t, r, http.MethodGet, BrokerDeadLetterPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "[]", resp.Body.String())
+
+	deadLetter := replication.NewMockDeadLetter(ctrl)
+	api = NewBrokerAPI(&deps.HTTPDeps{DeadLetter: deadLetter})
+	r = gin.New()
+	api.Register(r)
+	// bad limit
+	resp = mock.DoRequest(t, r, http.MethodGet, BrokerDeadLetterPath+"?limit=a", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	deadLetter.EXPECT().Recent(10).Return([]replication.RejectedMetric{
+		{Database: "db", Reason: "metric name is empty", Metric: []byte(`{"name":""}`)},
+	})
+	resp = mock.DoRequest(t, r, http.MethodGet, BrokerDeadLetterPath+"?limit=10", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var rejected []replication.RejectedMetric
+	assert.NoError(t, encoding.JSONUnmarshal(resp.Body.Bytes(), &rejected))
+	assert.Len(t, rejected, 1)
+	assert.Equal(t, "metric name is empty", rejected[0].Reason)
+}
+
+func TestBrokerAPI_HashRing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	api := NewBrokerAPI(&deps.HTTPDeps{CM: cm})
+	r := gin.New()
+	api.Register(r)
+
+	// database required
+	resp := mock.DoRequest(t, r, http.MethodGet, BrokerHashRingPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// database not found
+	cm.EXPECT().HashRing("db").Return(nil, false)
+	resp = mock.DoRequest(t, r, http.MethodGet, BrokerHashRingPath+"?db=db", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	cm.EXPECT().HashRing("db").Return(&models.HashRing{
+		Database:     "db",
+		NumOfShard:   2,
+		VirtualNodes: 128,
+		Shards:       []models.HashRingShard{{ShardID: 0, Ownership: 0.4}, {ShardID: 1, Ownership: 0.6}},
+	}, true)
+	resp = mock.DoRequest(t, r, http.MethodGet, BrokerHashRingPath+"?db=db", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var ring models.HashRing
+	assert.NoError(t, encoding.JSONUnmarshal(resp.Body.Bytes(), &ring))
+	assert.Equal(t, int32(2), ring.NumOfShard)
+	assert.Len(t, ring.Shards, 2)
+}
@@ -0,0 +1,62 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package state
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/internal/dbstats"
+	"github.com/lindb/lindb/pkg/http"
+)
+
+var (
+	DatabaseStatsPath = "/databases/:db/stats"
+)
+
+// DatabaseStatsAPI represents query write/query statistics of database on current broker.
+type DatabaseStatsAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewDatabaseStatsAPI creates the database statistics api.
+func NewDatabaseStatsAPI(deps *deps.HTTPDeps) *DatabaseStatsAPI {
+	return &DatabaseStatsAPI{
+		deps: deps,
+	}
+}
+
+// Register adds database statistics url route.
+func (s *DatabaseStatsAPI) Register(route gin.IRoutes) {
+	route.GET(DatabaseStatsPath, s.Stats)
+}
+
+// Stats returns the write/query statistics of database since current broker started,
+// returns empty statistics if database exists but nothing recorded.
+func (s *DatabaseStatsAPI) Stats(c *gin.Context) {
+	database := c.Param("db")
+	if stats, ok := dbstats.Get(database); ok {
+		http.OK(c, stats)
+		return
+	}
+	if _, ok := s.deps.StateMachines.DatabaseSM.GetDatabaseCfg(database); !ok {
+		http.NotFound(c)
+		return
+	}
+	http.OK(c, &dbstats.Stats{Database: database})
+}
@@ -0,0 +1,64 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package state
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/coordinator/broker"
+	"github.com/lindb/lindb/internal/dbstats"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+)
+
+func TestDatabaseStatsAPI_Stats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	databaseSM := broker.NewMockDatabaseStateMachine(ctrl)
+	api := NewDatabaseStatsAPI(&deps.HTTPDeps{
+		StateMachines: &coordinator.BrokerStateMachines{DatabaseSM: databaseSM},
+	})
+	r := gin.New()
+	api.Register(r)
+
+	// case 1: database not exist
+	databaseSM.EXPECT().GetDatabaseCfg("not_exist_db").Return(models.Database{}, false)
+	resp := mock.DoRequest(t, r, http.MethodGet, "/databases/not_exist_db/stats", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	// case 2: nothing recorded
+	databaseSM.EXPECT().GetDatabaseCfg("empty_db").Return(models.Database{Name: "empty_db"}, true)
+	resp = mock.DoRequest(t, r, http.MethodGet, "/databases/empty_db/stats", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"database":"empty_db"`)
+	// case 3: statistics recorded
+	dbstats.RecordWrite("stats_api_db", 10, 100, 0)
+	dbstats.RecordQuery("stats_api_db", time.Millisecond, nil)
+	resp = mock.DoRequest(t, r, http.MethodGet, "/databases/stats_api_db/stats", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"writtenPoints":10`)
+	assert.Contains(t, resp.Body.String(), `"queries":1`)
+}
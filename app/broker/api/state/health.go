@@ -0,0 +1,56 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package state
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/http"
+)
+
+var (
+	HealthPath = "/health"
+)
+
+// HealthAPI represents current broker node health api.
+type HealthAPI struct {
+	deps *deps.HTTPDeps
+}
+
+// NewHealthAPI creates the broker health api.
+func NewHealthAPI(deps *deps.HTTPDeps) *HealthAPI {
+	return &HealthAPI{
+		deps: deps,
+	}
+}
+
+// Register adds health url route.
+func (s *HealthAPI) Register(route gin.IRoutes) {
+	route.GET(HealthPath, s.Health)
+}
+
+// Health returns the health status of current broker node, includes degraded components.
+func (s *HealthAPI) Health(c *gin.Context) {
+	if s.deps.Components == nil {
+		http.OK(c, &models.NodeHealth{})
+		return
+	}
+	http.OK(c, s.deps.Components.Health())
+}
@@ -0,0 +1,56 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package state
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/server"
+)
+
+func TestHealthAPI_Health(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d := &deps.HTTPDeps{}
+	api := NewHealthAPI(d)
+	r := gin.New()
+	api.Register(r)
+
+	// no components
+	resp := mock.DoRequest(t, r, http.MethodGet, HealthPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	components := server.NewMockComponentManager(ctrl)
+	d.Components = components
+	components.EXPECT().Health().Return(&models.NodeHealth{
+		Degraded:   true,
+		Components: []models.ComponentHealth{{Name: "pusher", Error: "err"}},
+	})
+	resp = mock.DoRequest(t, r, http.MethodGet, HealthPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"degraded":true`)
+}
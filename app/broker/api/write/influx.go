@@ -53,6 +53,7 @@ func (iw *InfluxWriter) Write(c *gin.Context) {
 	var param struct {
 		Database  string `form:"db" binding:"required"`
 		Namespace string `form:"ns"`
+		producerParam
 	}
 	err := c.ShouldBindQuery(&param)
 	if err != nil {
@@ -67,14 +68,12 @@ func (iw *InfluxWriter) Write(c *gin.Context) {
 		http.Error(c, err)
 		return
 	}
-	metricList, err := influx.Parse(c.Request, enrichedTags, param.Namespace)
+	metricList, err := influx.Parse(c.Request, enrichedTags, param.Namespace,
+		iw.deps.DatabasePrecision(param.Database))
 	if err != nil {
 		http.Error(c, err)
 		return
 	}
-	if err := iw.deps.CM.Write(param.Database, metricList); err != nil {
-		http.Error(c, err)
-		return
-	}
-	http.NoContent(c)
+	param.attach(metricList)
+	writeResponse(c, iw.deps.CM.WriteBatch(param.Database, metricList))
 }
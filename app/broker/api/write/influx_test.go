@@ -28,6 +28,7 @@ import (
 
 	"github.com/lindb/lindb/app/broker/deps"
 	"github.com/lindb/lindb/internal/mock"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/replication"
 )
 
@@ -56,7 +57,7 @@ a,v=c,d=f a=2 b=3 c=4
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 
 	// write error
-	cm.EXPECT().Write(gomock.Any(), gomock.Any()).Return(io.ErrClosedPipe)
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).Return(io.ErrClosedPipe)
 	resp = mock.DoRequest(t, r, http.MethodPut, InfluxWritePath+"?db=test3&enrich_tag=a=b", `
 # good line
 measurement,foo=bar value=12 1439587925
@@ -65,11 +66,24 @@ measurement value=12 1439587925
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 
 	// no content
-	cm.EXPECT().Write(gomock.Any(), gomock.Any()).Return(nil)
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).Return(nil)
 	resp = mock.DoRequest(t, r, http.MethodPut, InfluxWritePath+"?db=test&ns=ns4&enrich_tag=a=b", `
 # good line
 measurement,foo=bar value=12 1439587925
 measurement value=12 1439587925
+`)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// write with producer
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ string, metricList *protoMetricsV1.MetricList) error {
+			assert.Equal(t, "p1", metricList.ProducerID)
+			assert.Equal(t, int64(10), metricList.Sequence)
+			assert.Equal(t, int64(2), metricList.ProducerEpoch)
+			return nil
+		})
+	resp = mock.DoRequest(t, r, http.MethodPut, InfluxWritePath+"?db=test&producer_id=p1&producer_epoch=2&seq=10", `
+measurement,foo=bar value=12 1439587925
 `)
 	assert.Equal(t, http.StatusNoContent, resp.Code)
 }
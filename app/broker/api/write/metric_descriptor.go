@@ -0,0 +1,89 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/http"
+)
+
+var (
+	MetricDescriptorPath = "/metadata/metric-descriptor"
+)
+
+// MetricDescriptorWriter writes metric descriptors(unit/description/expected field types),
+// which are returned by describe metric statement.
+type MetricDescriptorWriter struct {
+	deps *deps.HTTPDeps
+}
+
+// NewMetricDescriptorWriter creates metric descriptor writer.
+func NewMetricDescriptorWriter(deps *deps.HTTPDeps) *MetricDescriptorWriter {
+	return &MetricDescriptorWriter{
+		deps: deps,
+	}
+}
+
+// Register adds metric descriptor write url route.
+func (w *MetricDescriptorWriter) Register(route gin.IRoutes) {
+	route.PUT(MetricDescriptorPath, w.Write)
+	route.POST(MetricDescriptorPath, w.Write)
+}
+
+// Write saves the metric descriptors of request body into storage nodes of database,
+// the stored descriptors are overwritten.
+func (w *MetricDescriptorWriter) Write(c *gin.Context) {
+	var param struct {
+		Database  string `form:"db" binding:"required"`
+		Namespace string `form:"ns"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if param.Namespace == "" {
+		param.Namespace = constants.DefaultNamespace
+	}
+	var descriptors []*models.MetricDescriptor
+	if err := c.ShouldBindJSON(&descriptors); err != nil {
+		http.Error(c, err)
+		return
+	}
+	if len(descriptors) == 0 {
+		http.Error(c, errors.New("metric descriptors cannot be empty"))
+		return
+	}
+	for _, descriptor := range descriptors {
+		if descriptor.Namespace == "" {
+			descriptor.Namespace = param.Namespace
+		}
+	}
+	ctx, cancel := w.deps.WithTimeout()
+	defer cancel()
+	if err := w.deps.MetricDescriptors.Save(ctx, param.Database, descriptors); err != nil {
+		http.Error(c, err)
+		return
+	}
+	http.NoContent(c)
+}
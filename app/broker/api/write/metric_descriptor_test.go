@@ -0,0 +1,73 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/app/broker/descriptor"
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+func TestMetricDescriptorWriter_Write(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	registry := descriptor.NewMockRegistry(ctrl)
+	api := NewMetricDescriptorWriter(&deps.HTTPDeps{
+		Ctx:               context.Background(),
+		BrokerCfg:         &config.BrokerBase{HTTP: config.HTTP{ReadTimeout: ltoml.Duration(time.Second * 10)}},
+		MetricDescriptors: registry,
+	})
+	r := gin.New()
+	api.Register(r)
+
+	// case 1: param error
+	resp := mock.DoRequest(t, r, http.MethodPut, MetricDescriptorPath, `[{"name":"cpu"}]`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 2: bad body
+	resp = mock.DoRequest(t, r, http.MethodPut, MetricDescriptorPath+"?db=db", `{`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 3: empty descriptors
+	resp = mock.DoRequest(t, r, http.MethodPut, MetricDescriptorPath+"?db=db", `[]`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 4: save err
+	registry.EXPECT().Save(gomock.Any(), "db", gomock.Any()).Return(errors.New("err"))
+	resp = mock.DoRequest(t, r, http.MethodPut, MetricDescriptorPath+"?db=db", `[{"name":"cpu"}]`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 5: save successfully, namespace of descriptor takes precedence
+	registry.EXPECT().Save(gomock.Any(), "db", []*models.MetricDescriptor{
+		{Namespace: "ns", Name: "cpu", Unit: "percent"},
+		{Namespace: "other", Name: "memory"},
+	}).Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodPost, MetricDescriptorPath+"?db=db&ns=ns",
+		`[{"name":"cpu","unit":"percent"},{"namespace":"other","name":"memory"}]`)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
@@ -53,6 +53,7 @@ func (nw *NativeWriter) Write(c *gin.Context) {
 	var param struct {
 		Database  string `form:"db" binding:"required"`
 		Namespace string `form:"ns"`
+		producerParam
 	}
 	err := c.ShouldBindQuery(&param)
 	if err != nil {
@@ -72,9 +73,6 @@ func (nw *NativeWriter) Write(c *gin.Context) {
 		http.Error(c, err)
 		return
 	}
-	if err := nw.deps.CM.Write(param.Database, metrics); err != nil {
-		http.Error(c, err)
-		return
-	}
-	http.NoContent(c)
+	param.attach(metrics)
+	writeResponse(c, nw.deps.CM.WriteBatch(param.Database, metrics))
 }
@@ -58,7 +58,7 @@ func Test_NativeWriter(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 
 	// no content
-	cm.EXPECT().Write(gomock.Any(), gomock.Any()).Return(nil)
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).Return(nil)
 	var metricList = protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
 		{Name: "1", Namespace: "ns", SimpleFields: []*protoMetricsV1.SimpleField{
 			{Name: "counter", Type: protoMetricsV1.SimpleFieldType_CUMULATIVE_SUM, Value: 23},
@@ -68,7 +68,7 @@ func Test_NativeWriter(t *testing.T) {
 	resp = mock.DoRequest(t, r, http.MethodPost, NativeWritePath+"?db=test&ns=ns4&enrich_tag=a=b", string(data))
 	assert.Equal(t, http.StatusNoContent, resp.Code)
 
-	cm.EXPECT().Write(gomock.Any(), gomock.Any()).Return(io.ErrClosedPipe)
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).Return(io.ErrClosedPipe)
 	resp = mock.DoRequest(t, r, http.MethodPost, NativeWritePath+"?db=test&ns=ns4&enrich_tag=a=b", string(data))
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 
@@ -54,6 +54,7 @@ func (m *PrometheusWriter) Write(c *gin.Context) {
 	var param struct {
 		Database  string `form:"db" binding:"required"`
 		Namespace string `form:"ns"`
+		producerParam
 	}
 	err := c.ShouldBindQuery(&param)
 	if err != nil {
@@ -69,15 +70,17 @@ func (m *PrometheusWriter) Write(c *gin.Context) {
 		http.Error(c, err)
 		return
 	}
-	metricList, err := prometheus.Parse(c.Request, enrichedTags, param.Namespace)
+	metricList, descriptors, err := prometheus.Parse(c.Request, enrichedTags, param.Namespace,
+		m.deps.DatabasePrecision(param.Database))
 	if err != nil {
 		http.Error(c, err)
 		return
 	}
-
-	if err := m.deps.CM.Write(param.Database, metricList); err != nil {
-		http.Error(c, err)
-		return
+	if m.deps.MetricDescriptors != nil && len(descriptors) > 0 {
+		// saves descriptors inferred from HELP/TYPE asynchronously, explicit descriptors aren't overwritten
+		m.deps.MetricDescriptors.Infer(param.Database, descriptors)
 	}
-	http.NoContent(c)
+
+	param.attach(metricList)
+	writeResponse(c, m.deps.CM.WriteBatch(param.Database, metricList))
 }
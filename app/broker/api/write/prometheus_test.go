@@ -27,7 +27,9 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/app/broker/descriptor"
 	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/replication"
 )
 
@@ -52,11 +54,11 @@ go_gc_duration_seconds { quantile = "0.9999" } NaN
 go_gc_duration_seconds_count 9
 go_gc_duration_seconds_sum 90
 //`
-	cm.EXPECT().Write(gomock.Any(), gomock.Any()).Return(errors.New("err"))
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).Return(errors.New("err"))
 	resp = mock.DoRequest(t, r, http.MethodPut, PrometheusWritePath+"?db=dal", input)
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	// case 4: write wal success
-	cm.EXPECT().Write(gomock.Any(), gomock.Any()).Return(nil)
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).Return(nil)
 	resp = mock.DoRequest(t, r, http.MethodPut, PrometheusWritePath+"?db=dal", input)
 	assert.Equal(t, http.StatusNoContent, resp.Code)
 	// case 5: parse prometheus data err
@@ -68,3 +70,28 @@ go_gc_duration_seconds_sum 90
 	resp = mock.DoRequest(t, r, http.MethodPut, PrometheusWritePath+"?db=test&ns=ns2&enrich_tag=a", "")
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 }
+
+func TestPrometheusWrite_InferDescriptors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	registry := descriptor.NewMockRegistry(ctrl)
+	api := NewPrometheusWriter(&deps.HTTPDeps{CM: cm, MetricDescriptors: registry})
+	r := gin.New()
+	api.Register(r)
+	input := `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+`
+	registry.EXPECT().Infer("dal", []*models.MetricDescriptor{{
+		Namespace:   "ns",
+		Name:        "http_requests_total",
+		Type:        "counter",
+		Description: "The total number of HTTP requests.",
+		Fields:      []models.Field{{Name: "counter", Type: "sum"}},
+	}})
+	cm.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).Return(nil)
+	resp := mock.DoRequest(t, r, http.MethodPut, PrometheusWritePath+"?db=dal&ns=ns", input)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
@@ -0,0 +1,67 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/pkg/http"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+// producerParam represents the optional producer id, epoch and sequence of the write batch,
+// storage side drops the batch with duplicate (producer id, epoch, sequence), so writer can safely retry against
+// any broker. Producer increases epoch(like start time) after restarted, so that sequence can restart from 0.
+type producerParam struct {
+	ProducerID string `form:"producer_id"`
+	Epoch      int64  `form:"producer_epoch"`
+	Sequence   int64  `form:"seq"`
+}
+
+// attach attaches producer id, epoch and sequence to the metric list if producer id given.
+func (p *producerParam) attach(metricList *protoMetricsV1.MetricList) {
+	if p.ProducerID == "" {
+		return
+	}
+	metricList.ProducerID = p.ProducerID
+	metricList.ProducerEpoch = p.Epoch
+	metricList.Sequence = p.Sequence
+}
+
+// writeResponse responses the result of writing metrics into replication channel.
+// If some metrics are rejected, responses the summary of partial write with 200 when others are written,
+// or with 400 when all metrics are rejected.
+func writeResponse(c *gin.Context, err error) {
+	var partial *replication.PartialWriteError
+	switch {
+	case err == nil:
+		http.NoContent(c)
+	case errors.As(err, &partial):
+		_ = c.Error(err)
+		if partial.Succeeded > 0 {
+			http.OK(c, partial)
+		} else {
+			http.BadRequest(c, partial)
+		}
+	default:
+		http.Error(c, err)
+	}
+}
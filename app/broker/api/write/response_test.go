@@ -0,0 +1,60 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/replication"
+)
+
+func Test_writeResponse(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+		body string
+	}{
+		{err: nil, code: http.StatusNoContent},
+		{err: fmt.Errorf("err"), code: http.StatusInternalServerError, body: `"err"`},
+		{
+			err: &replication.PartialWriteError{Succeeded: 1, Rejected: 1,
+				Errors: []replication.MetricError{{Index: 1, Metric: "cpu", Reason: "metric has no fields"}}},
+			code: http.StatusOK,
+			body: `{"succeeded":1,"rejected":1,"errors":[{"index":1,"metric":"cpu","reason":"metric has no fields"}]}`,
+		},
+		{
+			err: &replication.PartialWriteError{Rejected: 1,
+				Errors: []replication.MetricError{{Index: 0, Reason: "metric name is empty"}}},
+			code: http.StatusBadRequest,
+			body: `{"succeeded":0,"rejected":1,"errors":[{"index":0,"metric":"","reason":"metric name is empty"}]}`,
+		},
+	}
+	for _, tt := range cases {
+		resp := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(resp)
+		writeResponse(c, tt.err)
+		assert.Equal(t, tt.code, resp.Code)
+		assert.Equal(t, tt.body, resp.Body.String())
+	}
+}
@@ -0,0 +1,126 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package audit
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+//go:generate mockgen -source=./audit.go -destination=./audit_mock.go -package=audit
+
+// for testing
+var (
+	nowFunc = timeutil.Now
+)
+
+// maxEntries is the max num. of entries kept in audit log, the oldest entry is pruned when recording new one.
+const maxEntries = 10000
+
+var (
+	auditScope            = linmetric.NewScope("lindb.broker.audit")
+	recordsCounter        = auditScope.NewDeltaCounter("records")
+	recordFailuresCounter = auditScope.NewDeltaCounter("record_failures")
+)
+
+// Log represents the durable audit log of admin operations, entries are stored in state repo,
+// so that all brokers share the same audit log.
+type Log interface {
+	// Record appends the entry into audit log, id and timestamp of entry are assigned by audit log.
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	// List returns the page of entries which id < before(all entries if before <= 0), newest entry first.
+	List(ctx context.Context, before int64, limit int) (*models.AuditLogPage, error)
+}
+
+// auditLog implements Log based on state repo.
+type auditLog struct {
+	repo       state.Repository
+	maxEntries int64
+	logger     *logger.Logger
+}
+
+// NewLog creates the audit log stored in state repo.
+func NewLog(repo state.Repository) Log {
+	return &auditLog{
+		repo:       repo,
+		maxEntries: maxEntries,
+		logger:     logger.GetLogger("broker", "AuditLog"),
+	}
+}
+
+// Record appends the entry with id generated by state repo, then prunes the entry out of retention.
+func (l *auditLog) Record(ctx context.Context, entry *models.AuditEntry) error {
+	id, err := l.repo.NextSequence(ctx, constants.AuditLogSeqPath)
+	if err != nil {
+		recordFailuresCounter.Incr()
+		return err
+	}
+	entry.ID = id
+	entry.Timestamp = nowFunc()
+	if err := l.repo.Put(ctx, constants.GetAuditLogPath(id), encoding.JSONMarshal(entry)); err != nil {
+		recordFailuresCounter.Incr()
+		return err
+	}
+	recordsCounter.Incr()
+	// ids are continuous, older entries have been pruned by previous records
+	if expired := id - l.maxEntries; expired > 0 {
+		if err := l.repo.Delete(ctx, constants.GetAuditLogPath(expired)); err != nil {
+			l.logger.Warn("prune expired audit log entry failure",
+				logger.Int64("id", expired), logger.Error(err))
+		}
+	}
+	return nil
+}
+
+// List returns the page of entries which id < before, newest entry first.
+func (l *auditLog) List(ctx context.Context, before int64, limit int) (*models.AuditLogPage, error) {
+	kvs, err := l.repo.List(ctx, constants.AuditLogPath)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*models.AuditEntry, 0, len(kvs))
+	for _, kv := range kvs {
+		entry := &models.AuditEntry{}
+		if err := encoding.JSONUnmarshal(kv.Value, entry); err != nil {
+			l.logger.Warn("unmarshal audit log entry failure",
+				logger.String("key", kv.Key), logger.Error(err))
+			continue
+		}
+		if before > 0 && entry.ID >= before {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+	page := &models.AuditLogPage{Entries: entries}
+	if limit > 0 && len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.Next = entries[limit-1].ID
+	}
+	return page, nil
+}
@@ -0,0 +1,117 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+func TestAuditLog_Record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		nowFunc = timeutil.Now
+		ctrl.Finish()
+	}()
+	nowFunc = func() int64 { return 100 }
+
+	repo := state.NewMockRepository(ctrl)
+	log := NewLog(repo)
+	ctx := context.TODO()
+	// next seq failure
+	repo.EXPECT().NextSequence(gomock.Any(), constants.AuditLogSeqPath).Return(int64(0), fmt.Errorf("err"))
+	assert.Error(t, log.Record(ctx, &models.AuditEntry{}))
+	// put failure
+	repo.EXPECT().NextSequence(gomock.Any(), constants.AuditLogSeqPath).Return(int64(1), nil)
+	repo.EXPECT().Put(gomock.Any(), constants.GetAuditLogPath(1), gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, log.Record(ctx, &models.AuditEntry{}))
+	// record ok
+	entry := &models.AuditEntry{Actor: "admin", Operation: "login"}
+	repo.EXPECT().NextSequence(gomock.Any(), constants.AuditLogSeqPath).Return(int64(2), nil)
+	repo.EXPECT().Put(gomock.Any(), constants.GetAuditLogPath(2),
+		encoding.JSONMarshal(&models.AuditEntry{ID: 2, Timestamp: 100, Actor: "admin", Operation: "login"})).
+		Return(nil)
+	assert.NoError(t, log.Record(ctx, entry))
+	assert.Equal(t, int64(2), entry.ID)
+	assert.Equal(t, int64(100), entry.Timestamp)
+	// prune expired entry
+	repo.EXPECT().NextSequence(gomock.Any(), constants.AuditLogSeqPath).Return(int64(maxEntries+1), nil)
+	repo.EXPECT().Put(gomock.Any(), constants.GetAuditLogPath(maxEntries+1), gomock.Any()).Return(nil)
+	repo.EXPECT().Delete(gomock.Any(), constants.GetAuditLogPath(1)).Return(nil)
+	assert.NoError(t, log.Record(ctx, &models.AuditEntry{}))
+	// prune failure, ignore it
+	repo.EXPECT().NextSequence(gomock.Any(), constants.AuditLogSeqPath).Return(int64(maxEntries+2), nil)
+	repo.EXPECT().Put(gomock.Any(), constants.GetAuditLogPath(maxEntries+2), gomock.Any()).Return(nil)
+	repo.EXPECT().Delete(gomock.Any(), constants.GetAuditLogPath(2)).Return(fmt.Errorf("err"))
+	assert.NoError(t, log.Record(ctx, &models.AuditEntry{}))
+}
+
+func TestAuditLog_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := state.NewMockRepository(ctrl)
+	log := NewLog(repo)
+	ctx := context.TODO()
+	// list failure
+	repo.EXPECT().List(gomock.Any(), constants.AuditLogPath).Return(nil, fmt.Errorf("err"))
+	page, err := log.List(ctx, 0, 10)
+	assert.Error(t, err)
+	assert.Nil(t, page)
+
+	var kvs []state.KeyValue
+	for id := int64(1); id <= 5; id++ {
+		kvs = append(kvs, state.KeyValue{
+			Key:   constants.GetAuditLogPath(id),
+			Value: encoding.JSONMarshal(&models.AuditEntry{ID: id}),
+		})
+	}
+	kvs = append(kvs, state.KeyValue{Key: constants.GetAuditLogPath(6), Value: []byte("bad")})
+	repo.EXPECT().List(gomock.Any(), constants.AuditLogPath).Return(kvs, nil).AnyTimes()
+	ids := func(page *models.AuditLogPage) (result []int64) {
+		for _, entry := range page.Entries {
+			result = append(result, entry.ID)
+		}
+		return
+	}
+	// first page
+	page, err = log.List(ctx, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 4}, ids(page))
+	assert.Equal(t, int64(4), page.Next)
+	// next page
+	page, err = log.List(ctx, page.Next, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, ids(page))
+	assert.Equal(t, int64(2), page.Next)
+	// last page
+	page, err = log.List(ctx, page.Next, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, ids(page))
+	assert.Equal(t, int64(0), page.Next)
+}
@@ -19,10 +19,17 @@ package deps
 
 import (
 	"context"
+	"time"
 
+	"github.com/lindb/lindb/app/broker/audit"
+	"github.com/lindb/lindb/app/broker/descriptor"
+	"github.com/lindb/lindb/app/broker/federation"
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/server"
 	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
 	brokerQuery "github.com/lindb/lindb/query/broker"
 	"github.com/lindb/lindb/replication"
 )
@@ -36,9 +43,19 @@ type HTTPDeps struct {
 	Repo          state.Repository
 	StateMachines *coordinator.BrokerStateMachines
 
-	CM replication.ChannelManager
+	CM         replication.ChannelManager
+	DeadLetter replication.DeadLetter
 
 	QueryFactory brokerQuery.Factory
+	// Federation fans query out to remote clusters, nil if federation is disabled
+	Federation federation.Federation
+	// AuditLog records admin operations and login events
+	AuditLog audit.Log
+	// MetricDescriptors saves metric descriptors(unit/description/expected field types) into storage nodes
+	MetricDescriptors descriptor.Registry
+
+	Components server.ComponentManager
+	Drainer    server.Drainer
 }
 
 func (deps *HTTPDeps) WithTimeout() (context.Context, context.CancelFunc) {
@@ -49,3 +66,33 @@ func (deps *HTTPDeps) WithTimeout() (context.Context, context.CancelFunc) {
 	}
 	return context.WithTimeout(deps.Ctx, timeout)
 }
+
+// QueryTimeout returns the timeout of query, cluster config takes precedence over local config.
+func (deps *HTTPDeps) QueryTimeout() time.Duration {
+	return deps.clusterConfig().GetQueryTimeout(deps.BrokerCfg.Query.Timeout.Duration())
+}
+
+// SlowQueryThreshold returns the threshold of slow query, cluster config takes precedence over local config.
+func (deps *HTTPDeps) SlowQueryThreshold() time.Duration {
+	return deps.clusterConfig().GetSlowQueryThreshold(deps.BrokerCfg.Query.SlowQueryThreshold.Duration())
+}
+
+// clusterConfig returns the cluster-wide config, returns empty config if not watched.
+func (deps *HTTPDeps) clusterConfig() models.ClusterConfig {
+	if deps.StateMachines == nil || deps.StateMachines.ClusterConfigSM == nil {
+		return models.ClusterConfig{}
+	}
+	return deps.StateMachines.ClusterConfigSM.GetClusterConfig()
+}
+
+// DatabasePrecision returns the precision of written timestamp of database, returns millisecond if not found.
+func (deps *HTTPDeps) DatabasePrecision(database string) timeutil.Precision {
+	if deps.StateMachines == nil || deps.StateMachines.DatabaseSM == nil {
+		return timeutil.Millisecond
+	}
+	databaseCfg, ok := deps.StateMachines.DatabaseSM.GetDatabaseCfg(database)
+	if !ok {
+		return timeutil.Millisecond
+	}
+	return databaseCfg.Option.GetPrecision()
+}
@@ -0,0 +1,184 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package descriptor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	storageapi "github.com/lindb/lindb/app/storage/api"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+//go:generate mockgen -source=./registry.go -destination=./registry_mock.go -package=descriptor
+
+// maxInferredDescriptors is the max number of inferred descriptors cached for skipping unchanged descriptors.
+const maxInferredDescriptors = 100000
+
+var (
+	// for testing
+	doRequest = http.DefaultClient.Do
+)
+
+var (
+	descriptorScope        = linmetric.NewScope("lindb.broker.metric_descriptor")
+	inferredCounter        = descriptorScope.NewDeltaCounter("inferred")
+	forwardFailuresCounter = descriptorScope.NewDeltaCounter("forward_failures")
+)
+
+// Registry represents the registry of metric descriptors(unit/description/expected field types),
+// descriptors are forwarded to storage nodes of database, stored alongside metric name mappings in tsdb metadata.
+type Registry interface {
+	// Save saves the descriptors written by api, which overwrite the stored descriptors.
+	Save(ctx context.Context, database string, descriptors []*models.MetricDescriptor) error
+	// Infer saves the descriptors inferred from ingestion asynchronously, which only fill the absent attributes
+	// of stored descriptors, unchanged descriptors are skipped.
+	Infer(database string, descriptors []*models.MetricDescriptor)
+}
+
+// registry implements Registry.
+type registry struct {
+	ctx           context.Context
+	stateMachines *coordinator.BrokerStateMachines
+	// database/namespace/metric name => json of the last inferred descriptor
+	inferred map[string]string
+	mutex    sync.Mutex
+	logger   *logger.Logger
+}
+
+// NewRegistry creates the metric descriptor registry.
+func NewRegistry(ctx context.Context, stateMachines *coordinator.BrokerStateMachines) Registry {
+	return &registry{
+		ctx:           ctx,
+		stateMachines: stateMachines,
+		inferred:      make(map[string]string),
+		logger:        logger.GetLogger("broker", "MetricDescriptorRegistry"),
+	}
+}
+
+// Save saves the descriptors written by api, which overwrite the stored descriptors.
+func (r *registry) Save(ctx context.Context, database string, descriptors []*models.MetricDescriptor) error {
+	for _, descriptor := range descriptors {
+		if err := descriptor.Validate(); err != nil {
+			return err
+		}
+	}
+	return r.forward(ctx, database, descriptors, false)
+}
+
+// Infer saves the descriptors inferred from ingestion asynchronously, unchanged descriptors are skipped.
+func (r *registry) Infer(database string, descriptors []*models.MetricDescriptor) {
+	var changed []*models.MetricDescriptor
+	var keys []string
+	r.mutex.Lock()
+	for _, descriptor := range descriptors {
+		key := database + "/" + descriptor.Namespace + "/" + descriptor.Name
+		value := string(encoding.JSONMarshal(descriptor))
+		if r.inferred[key] == value {
+			continue
+		}
+		if len(r.inferred) >= maxInferredDescriptors {
+			r.inferred = make(map[string]string)
+		}
+		r.inferred[key] = value
+		changed = append(changed, descriptor)
+		keys = append(keys, key)
+	}
+	r.mutex.Unlock()
+	if len(changed) == 0 {
+		return
+	}
+	inferredCounter.Add(float64(len(changed)))
+	go func() {
+		if err := r.forward(r.ctx, database, changed, true); err != nil {
+			r.logger.Warn("forward inferred metric descriptors failure",
+				logger.String("db", database), logger.Error(err))
+			// forget the failed descriptors, retry when inferred next time
+			r.mutex.Lock()
+			for _, key := range keys {
+				delete(r.inferred, key)
+			}
+			r.mutex.Unlock()
+		}
+	}()
+}
+
+// forward forwards the descriptors to all active storage nodes of the storage cluster which database belongs to,
+// nodes without database are skipped.
+func (r *registry) forward(ctx context.Context, database string,
+	descriptors []*models.MetricDescriptor, inferred bool,
+) error {
+	cfg, ok := r.stateMachines.DatabaseSM.GetDatabaseCfg(database)
+	if !ok {
+		return fmt.Errorf("%w: %s", constants.ErrDatabaseNotFound, database)
+	}
+	var storage *models.StorageState
+	for _, state := range r.stateMachines.StorageSM.List() {
+		if state.Name == cfg.Cluster {
+			storage = state
+			break
+		}
+	}
+	if storage == nil || len(storage.ActiveNodes) == 0 {
+		return fmt.Errorf("storage cluster(%s) of database(%s) isn't available", cfg.Cluster, database)
+	}
+	body := encoding.JSONMarshal(descriptors)
+	for _, node := range storage.ActiveNodes {
+		if err := r.forwardToNode(ctx, &node.Node, database, body, inferred); err != nil {
+			forwardFailuresCounter.Incr()
+			return err
+		}
+	}
+	return nil
+}
+
+// forwardToNode forwards the descriptors to storage node, database not found in node is ignored.
+func (r *registry) forwardToNode(ctx context.Context, node *models.Node, database string,
+	body []byte, inferred bool,
+) error {
+	address := fmt.Sprintf("http://%s:%d/api/v1%s?db=%s&inferred=%t",
+		node.IP, node.HTTPPort, storageapi.MetricDescriptorPath, url.QueryEscape(database), inferred)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := doRequest(req)
+	if err != nil {
+		return err
+	}
+	if err := resp.Body.Close(); err != nil {
+		r.logger.Warn("close response body failure", logger.String("node", node.Indicator()), logger.Error(err))
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("save metric descriptors on storage node(%s) failure, status code: %d",
+			node.Indicator(), resp.StatusCode)
+	}
+}
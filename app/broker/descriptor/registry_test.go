@@ -0,0 +1,141 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package descriptor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/coordinator"
+	"github.com/lindb/lindb/coordinator/broker"
+	"github.com/lindb/lindb/models"
+)
+
+func TestRegistry_Save(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		doRequest = http.DefaultClient.Do
+		ctrl.Finish()
+	}()
+
+	databaseSM := broker.NewMockDatabaseStateMachine(ctrl)
+	storageSM := broker.NewMockStorageStateMachine(ctrl)
+	r := NewRegistry(context.TODO(), &coordinator.BrokerStateMachines{DatabaseSM: databaseSM, StorageSM: storageSM})
+	descriptors := []*models.MetricDescriptor{{Namespace: "ns", Name: "cpu", Unit: "percent"}}
+	storage := models.NewStorageState()
+	storage.Name = "cluster"
+	storage.AddActiveNode(&models.ActiveNode{Node: models.Node{IP: "1.1.1.1", Port: 2891, HTTPPort: 2892}})
+
+	// case 1: invalid descriptor
+	assert.Error(t, r.Save(context.TODO(), "db", []*models.MetricDescriptor{{}}))
+	// case 2: database not found
+	databaseSM.EXPECT().GetDatabaseCfg("db").Return(models.Database{}, false)
+	assert.Error(t, r.Save(context.TODO(), "db", descriptors))
+	databaseSM.EXPECT().GetDatabaseCfg("db").Return(models.Database{Name: "db", Cluster: "cluster"}, true).AnyTimes()
+	// case 3: storage cluster not available
+	storageSM.EXPECT().List().Return([]*models.StorageState{{Name: "other"}})
+	assert.Error(t, r.Save(context.TODO(), "db", descriptors))
+	storageSM.EXPECT().List().Return([]*models.StorageState{storage}).AnyTimes()
+	// case 4: request err
+	doRequest = func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("err")
+	}
+	assert.Error(t, r.Save(context.TODO(), "db", descriptors))
+	// case 5: storage node failure
+	doRequest = func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	assert.Error(t, r.Save(context.TODO(), "db", descriptors))
+	// case 6: database not in storage node is ignored
+	doRequest = func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	assert.NoError(t, r.Save(context.TODO(), "db", descriptors))
+	// case 7: save successfully
+	doRequest = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPut, req.Method)
+		assert.Equal(t, "http://1.1.1.1:2892/api/v1/metadata/metric-descriptor?db=db&inferred=false", req.URL.String())
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, `[{"namespace":"ns","name":"cpu","unit":"percent"}]`, string(body))
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	assert.NoError(t, r.Save(context.TODO(), "db", descriptors))
+}
+
+func TestRegistry_Infer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		doRequest = http.DefaultClient.Do
+		ctrl.Finish()
+	}()
+
+	databaseSM := broker.NewMockDatabaseStateMachine(ctrl)
+	storageSM := broker.NewMockStorageStateMachine(ctrl)
+	databaseSM.EXPECT().GetDatabaseCfg("db").Return(models.Database{Name: "db", Cluster: "cluster"}, true).AnyTimes()
+	storage := models.NewStorageState()
+	storage.Name = "cluster"
+	storage.AddActiveNode(&models.ActiveNode{Node: models.Node{IP: "1.1.1.1", Port: 2891, HTTPPort: 2892}})
+	storageSM.EXPECT().List().Return([]*models.StorageState{storage}).AnyTimes()
+	r := NewRegistry(context.TODO(), &coordinator.BrokerStateMachines{DatabaseSM: databaseSM, StorageSM: storageSM})
+
+	var mutex sync.Mutex
+	var requests []string
+	statusCode := http.StatusInternalServerError
+	doRequest = func(req *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests = append(requests, req.URL.RawQuery)
+		return &http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	numOfRequests := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(requests)
+	}
+	descriptors := []*models.MetricDescriptor{{Namespace: "ns", Name: "cpu", Type: "gauge"}}
+
+	// case 1: forward failure, retry when inferred next time
+	r.Infer("db", descriptors)
+	assert.Eventually(t, func() bool { return numOfRequests() == 1 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		reg := r.(*registry)
+		reg.mutex.Lock()
+		defer reg.mutex.Unlock()
+		return len(reg.inferred) == 0
+	}, time.Second, time.Millisecond)
+	// case 2: forward successfully
+	mutex.Lock()
+	statusCode = http.StatusOK
+	mutex.Unlock()
+	r.Infer("db", descriptors)
+	assert.Eventually(t, func() bool { return numOfRequests() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, "db=db&inferred=true", requests[1])
+	// case 3: unchanged descriptors skipped
+	r.Infer("db", []*models.MetricDescriptor{{Namespace: "ns", Name: "cpu", Type: "gauge"}})
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 2, numOfRequests())
+}
@@ -0,0 +1,222 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/logger"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	lindQuery "github.com/lindb/lindb/query"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/rpc"
+)
+
+//go:generate mockgen -source=./federation.go -destination=./federation_mock.go -package=federation
+
+// ClusterTagKey is the tag key added into series of federation query result, value is the cluster name.
+const ClusterTagKey = "cluster"
+
+var (
+	federationScope          = linmetric.NewScope("lindb.broker.federation")
+	federationQueriesCounter = federationScope.NewDeltaCounter("queries")
+	remoteQueryFailuresVec   = federationScope.NewDeltaCounterVec("remote_query_failures", "cluster")
+	queryDurationHistogram   = federationScope.Scope("query_duration").NewDeltaHistogram().
+					WithExponentBuckets(time.Millisecond, time.Minute, 20)
+)
+
+// Federation fans the metric query out to current cluster and remote LinDB clusters,
+// merges results with cluster tag, so that one query can span regional clusters.
+type Federation interface {
+	// Query executes the metric query on all clusters, returns the merged result set,
+	// if partial is true, results of available clusters are returned with failures of other clusters.
+	Query(ctx context.Context, database, sql string, partial bool) (*models.ResultSet, error)
+}
+
+// remoteCluster represents the remote LinDB cluster which query is sent to.
+type remoteCluster struct {
+	name string
+	node models.Node // broker of remote cluster
+}
+
+// clusterResult represents the query result of a cluster.
+type clusterResult struct {
+	cluster   string
+	resultSet *models.ResultSet
+	err       error
+}
+
+// federation implements Federation.
+type federation struct {
+	cluster        string
+	remoteClusters []remoteCluster
+	queryFactory   brokerQuery.Factory
+	clientFactory  rpc.ClientStreamFactory
+	logger         *logger.Logger
+}
+
+// NewFederation creates the federation query with remote clusters of config,
+// returns error if remote cluster isn't in format "name=ip:grpc-port" or cluster name duplicated.
+func NewFederation(cfg config.Federation,
+	queryFactory brokerQuery.Factory,
+	clientFactory rpc.ClientStreamFactory,
+) (Federation, error) {
+	if cfg.Cluster == "" {
+		return nil, fmt.Errorf("cluster name of federation cannot be empty")
+	}
+	names := map[string]struct{}{cfg.Cluster: {}}
+	var remoteClusters []remoteCluster
+	for _, cluster := range cfg.RemoteClusters {
+		parts := strings.SplitN(cluster, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("remote cluster(%s) is not in the format name=ip:grpc-port", cluster)
+		}
+		if _, ok := names[parts[0]]; ok {
+			return nil, fmt.Errorf("duplicate cluster name: %s", parts[0])
+		}
+		names[parts[0]] = struct{}{}
+		node, err := models.ParseNode(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		remoteClusters = append(remoteClusters, remoteCluster{name: parts[0], node: *node})
+	}
+	return &federation{
+		cluster:        cfg.Cluster,
+		remoteClusters: remoteClusters,
+		queryFactory:   queryFactory,
+		clientFactory:  clientFactory,
+		logger:         logger.GetLogger("broker", "Federation"),
+	}, nil
+}
+
+// Query executes the metric query on all clusters concurrently, returns the merged result set.
+func (f *federation) Query(ctx context.Context, database, sql string, partial bool) (*models.ResultSet, error) {
+	federationQueriesCounter.Incr()
+	defer queryDurationHistogram.UpdateSince(time.Now())
+
+	results := make([]clusterResult, len(f.remoteClusters)+1)
+	var wait sync.WaitGroup
+	wait.Add(len(results))
+	go func() {
+		defer wait.Done()
+		queryCtx := ctx
+		if partial {
+			queryCtx = lindQuery.WithPartialResults(ctx)
+		}
+		resultSet, err := f.queryFactory.NewMetricQuery(queryCtx, database, sql, "").WaitResponse()
+		results[0] = clusterResult{cluster: f.cluster, resultSet: resultSet, err: err}
+	}()
+	for idx := range f.remoteClusters {
+		go func(idx int) {
+			defer wait.Done()
+			cluster := f.remoteClusters[idx]
+			resultSet, err := f.queryRemote(ctx, cluster, &protoBrokerV1.QueryRequest{
+				Database: database,
+				Sql:      sql,
+				Partial:  partial,
+			})
+			if err != nil {
+				remoteQueryFailuresVec.WithTagValues(cluster.name).Incr()
+				f.logger.Warn("query remote cluster error",
+					logger.String("cluster", cluster.name), logger.String("sql", sql), logger.Error(err))
+			}
+			results[idx+1] = clusterResult{cluster: cluster.name, resultSet: resultSet, err: err}
+		}(idx)
+	}
+	wait.Wait()
+	return merge(results, partial)
+}
+
+// queryRemote queries the broker of remote cluster, decodes the result set from json payload.
+func (f *federation) queryRemote(ctx context.Context,
+	cluster remoteCluster, req *protoBrokerV1.QueryRequest,
+) (*models.ResultSet, error) {
+	client, err := f.clientFactory.CreateQueryServiceClient(cluster.node)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Query(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resultSet := &models.ResultSet{}
+	if err := json.Unmarshal(resp.Payload, resultSet); err != nil {
+		return nil, err
+	}
+	return resultSet, nil
+}
+
+// merge merges the results of all clusters, adds cluster tag into series of each cluster.
+// Query fails if any cluster fails and partial is false, or all clusters fail.
+func merge(results []clusterResult, partial bool) (*models.ResultSet, error) {
+	var merged *models.ResultSet
+	var failures []models.NodeFailure
+	for _, result := range results {
+		if result.err != nil {
+			if !partial {
+				return nil, fmt.Errorf("query cluster[%s] failure: %w", result.cluster, result.err)
+			}
+			failures = append(failures, models.NodeFailure{Node: result.cluster, Error: result.err.Error()})
+			continue
+		}
+		resultSet := result.resultSet
+		for _, series := range resultSet.Series {
+			if series.Tags == nil {
+				series.Tags = make(map[string]string)
+			}
+			series.Tags[ClusterTagKey] = result.cluster
+		}
+		if merged == nil {
+			merged = resultSet
+			continue
+		}
+		merged.Series = append(merged.Series, resultSet.Series...)
+		merged.Warnings = append(merged.Warnings, resultSet.Warnings...)
+		merged.Failures = append(merged.Failures, resultSet.Failures...)
+		merged.Flags |= resultSet.Flags
+		if merged.MetricName == "" {
+			merged.MetricName = resultSet.MetricName
+		}
+		if len(merged.Fields) == 0 {
+			merged.Fields = resultSet.Fields
+		}
+		if resultSet.Interval != merged.Interval {
+			merged.Warnings = append(merged.Warnings,
+				fmt.Sprintf("interval of cluster[%s] is %dms, others is %dms",
+					result.cluster, resultSet.Interval, merged.Interval))
+		}
+	}
+	if merged == nil {
+		// all clusters fail
+		return nil, fmt.Errorf("query cluster[%s] failure: %s", failures[0].Node, failures[0].Error)
+	}
+	if len(failures) > 0 {
+		merged.Failures = append(merged.Failures, failures...)
+		merged.Flags |= models.PointPartial
+	}
+	return merged, nil
+}
@@ -0,0 +1,123 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package federation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/models"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/rpc"
+)
+
+func TestNewFederation(t *testing.T) {
+	badConfigs := []config.Federation{
+		{}, // empty cluster name
+		{Cluster: "a", RemoteClusters: []string{"b"}},              // bad format
+		{Cluster: "a", RemoteClusters: []string{"a=1.1.1.1:9001"}}, // duplicate name
+		{Cluster: "a", RemoteClusters: []string{"b=host:9001"}},    // bad address
+	}
+	for _, cfg := range badConfigs {
+		_, err := NewFederation(cfg, nil, nil)
+		assert.Error(t, err)
+	}
+
+	f, err := NewFederation(config.Federation{Cluster: "a", RemoteClusters: []string{"b=1.1.1.1:9001"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []remoteCluster{{name: "b", node: models.Node{IP: "1.1.1.1", Port: 9001}}},
+		f.(*federation).remoteClusters)
+}
+
+func TestFederation_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	clientFactory := rpc.NewMockClientStreamFactory(ctrl)
+	bjClient := protoBrokerV1.NewMockQueryServiceClient(ctrl)
+	gzClient := protoBrokerV1.NewMockQueryServiceClient(ctrl)
+	f, err := NewFederation(config.Federation{
+		Cluster:        "sh",
+		RemoteClusters: []string{"bj=1.1.1.1:9001", "gz=2.2.2.2:9001"},
+	}, queryFactory, clientFactory)
+	assert.NoError(t, err)
+
+	bj := models.Node{IP: "1.1.1.1", Port: 9001}
+	gz := models.Node{IP: "2.2.2.2", Port: 9001}
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "db", "select f from cpu", "").Return(metricQuery).AnyTimes()
+
+	// case 1: remote cluster failure
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{MetricName: "cpu"}, nil)
+	clientFactory.EXPECT().CreateQueryServiceClient(bj).Return(nil, fmt.Errorf("err"))
+	clientFactory.EXPECT().CreateQueryServiceClient(gz).Return(gzClient, nil)
+	gzClient.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	_, err = f.Query(context.TODO(), "db", "select f from cpu", false)
+	assert.Error(t, err)
+	// case 2: partial results
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{
+		MetricName: "cpu", Interval: 10000, Series: []*models.Series{{Tags: map[string]string{"host": "a"}}},
+	}, nil)
+	clientFactory.EXPECT().CreateQueryServiceClient(bj).Return(bjClient, nil)
+	clientFactory.EXPECT().CreateQueryServiceClient(gz).Return(gzClient, nil)
+	bjClient.EXPECT().Query(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *protoBrokerV1.QueryRequest, _ ...interface{}) (*protoBrokerV1.QueryResponse, error) {
+			assert.Equal(t, "db", req.Database)
+			assert.True(t, req.Partial)
+			return &protoBrokerV1.QueryResponse{
+				Payload: []byte(`{"metricName":"cpu","interval":10000,"series":[{"fields":{"f":{"10000":1}}}]}`),
+			}, nil
+		})
+	gzClient.EXPECT().Query(gomock.Any(), gomock.Any()).Return(&protoBrokerV1.QueryResponse{Payload: []byte("bad")}, nil)
+	rs, err := f.Query(context.TODO(), "db", "select f from cpu", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", rs.MetricName)
+	assert.Len(t, rs.Series, 2)
+	assert.Equal(t, map[string]string{"host": "a", ClusterTagKey: "sh"}, rs.Series[0].Tags)
+	assert.Equal(t, map[string]string{ClusterTagKey: "bj"}, rs.Series[1].Tags)
+	assert.Equal(t, map[int64]float64{10000: 1}, rs.Series[1].Fields["f"])
+	assert.Len(t, rs.Failures, 1)
+	assert.Equal(t, "gz", rs.Failures[0].Node)
+	assert.True(t, rs.Flags.Has(models.PointPartial))
+}
+
+func Test_merge(t *testing.T) {
+	// all clusters fail
+	_, err := merge([]clusterResult{{cluster: "a", err: fmt.Errorf("err")}}, true)
+	assert.EqualError(t, err, "query cluster[a] failure: err")
+	// merge header of result set
+	rs, err := merge([]clusterResult{
+		{cluster: "a", resultSet: &models.ResultSet{Interval: 10000}},
+		{cluster: "b", resultSet: &models.ResultSet{
+			MetricName: "cpu", Interval: 60000, Fields: []string{"f"},
+			Warnings: []string{"w"}, Flags: models.PointDownSampled,
+		}},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", rs.MetricName)
+	assert.Equal(t, []string{"f"}, rs.Fields)
+	assert.Equal(t, []string{"w", "interval of cluster[b] is 60000ms, others is 10000ms"}, rs.Warnings)
+	assert.Equal(t, models.PointDownSampled, rs.Flags)
+}
@@ -0,0 +1,182 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/ingestion/graphite"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/logger"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+var (
+	graphiteScope              = linmetric.NewScope("lindb.ingestion").Scope("graphite")
+	graphiteConnectionsGauge   = graphiteScope.NewGauge("active_connections")
+	graphiteReceivedCounter    = graphiteScope.NewDeltaCounter("received_metrics")
+	graphiteBadLinesCounter    = graphiteScope.NewDeltaCounter("bad_lines")
+	graphiteWriteFailedCounter = graphiteScope.NewDeltaCounter("write_failures")
+)
+
+// graphiteListener listens on tcp port for graphite plaintext protocol, parses lines into metrics by templates,
+// then writes them into replication channel in batch.
+type graphiteListener struct {
+	ctx      context.Context
+	cfg      config.Graphite
+	parser   *graphite.Parser
+	cm       replication.ChannelManager
+	listener net.Listener
+	metricCh chan *protoMetricsV1.Metric
+
+	logger *logger.Logger
+}
+
+// newGraphiteListener creates the graphite listener, returns error if templates are invalid.
+func newGraphiteListener(
+	ctx context.Context,
+	cfg config.Graphite,
+	cm replication.ChannelManager,
+) (*graphiteListener, error) {
+	parser, err := graphite.NewParser(cfg.Namespace, cfg.Separator, cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = config.NewDefaultBrokerBase().Graphite.FlushInterval
+	}
+	return &graphiteListener{
+		ctx:      ctx,
+		cfg:      cfg,
+		parser:   parser,
+		cm:       cm,
+		metricCh: make(chan *protoMetricsV1.Metric, cfg.BatchSize),
+		logger:   logger.GetLogger("broker", "GraphiteListener"),
+	}, nil
+}
+
+// listen listens on the tcp port.
+func (l *graphiteListener) listen() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", l.cfg.Port))
+	if err != nil {
+		return err
+	}
+	l.listener = listener
+	return nil
+}
+
+// run accepts connections until ctx done, then closes listener and flushes buffered metrics.
+func (l *graphiteListener) run() {
+	go l.batch()
+	go func() {
+		<-l.ctx.Done()
+		_ = l.listener.Close()
+	}()
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if l.ctx.Err() != nil {
+				return
+			}
+			l.logger.Warn("accept graphite connection failure", logger.Error(err))
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		go l.handle(conn)
+	}
+}
+
+// handle reads lines from connection until connection closed or ctx done.
+func (l *graphiteListener) handle(conn net.Conn) {
+	graphiteConnectionsGauge.Incr()
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		_ = conn.Close()
+		graphiteConnectionsGauge.Decr()
+	}()
+	go func() {
+		select {
+		case <-l.ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		metric, err := l.parser.Parse(scanner.Text())
+		if err != nil {
+			graphiteBadLinesCounter.Incr()
+			l.logger.Debug("parse graphite line failure",
+				logger.String("remote", conn.RemoteAddr().String()), logger.Error(err))
+			continue
+		}
+		if metric == nil {
+			continue
+		}
+		graphiteReceivedCounter.Incr()
+		select {
+		case l.metricCh <- metric:
+		case <-l.ctx.Done():
+			return
+		}
+	}
+}
+
+// batch buffers metrics, writes them into replication channel if exceeds batch size or every flush interval.
+func (l *graphiteListener) batch() {
+	ticker := time.NewTicker(l.cfg.FlushInterval.Duration())
+	defer ticker.Stop()
+
+	metricList := &protoMetricsV1.MetricList{}
+	flush := func() {
+		if len(metricList.Metrics) == 0 {
+			return
+		}
+		if err := l.cm.WriteBatch(l.cfg.Database, metricList); err != nil {
+			graphiteWriteFailedCounter.Incr()
+			l.logger.Error("write graphite metrics failure",
+				logger.String("db", l.cfg.Database), logger.Error(err))
+		}
+		metricList = &protoMetricsV1.MetricList{}
+	}
+	for {
+		select {
+		case <-l.ctx.Done():
+			flush()
+			return
+		case <-ticker.C:
+			flush()
+		case metric := <-l.metricCh:
+			metricList.Metrics = append(metricList.Metrics, metric)
+			if len(metricList.Metrics) >= l.cfg.BatchSize {
+				flush()
+			}
+		}
+	}
+}
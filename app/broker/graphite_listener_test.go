@@ -0,0 +1,110 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/pkg/ltoml"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+func TestGraphiteListener_New(t *testing.T) {
+	_, err := newGraphiteListener(context.TODO(), config.Graphite{Templates: []string{"a b c d"}}, nil)
+	assert.Error(t, err)
+	l, err := newGraphiteListener(context.TODO(), config.Graphite{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, l.cfg.BatchSize)
+	assert.True(t, l.cfg.FlushInterval > 0)
+}
+
+func TestGraphiteListener_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	var (
+		mutex   sync.Mutex
+		metrics []*protoMetricsV1.Metric
+	)
+	cm.EXPECT().WriteBatch("graphite", gomock.Any()).
+		DoAndReturn(func(_ string, list *protoMetricsV1.MetricList) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			metrics = append(metrics, list.Metrics...)
+			return nil
+		}).MinTimes(1)
+	cm.EXPECT().WriteBatch("graphite", gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	l, err := newGraphiteListener(ctx, config.Graphite{
+		Port:          0,
+		Database:      "graphite",
+		Separator:     ".",
+		Templates:     []string{"servers.* .host.measurement.field"},
+		BatchSize:     2,
+		FlushInterval: ltoml.Duration(10 * time.Millisecond),
+	}, cm)
+	assert.NoError(t, err)
+	assert.NoError(t, l.listen())
+	go l.run()
+
+	conn, err := net.Dial("tcp", l.listener.Addr().String())
+	assert.NoError(t, err)
+	_, err = fmt.Fprintf(conn, "servers.host1.cpu.idle 90 %d\nbad line\nservers.host2.cpu.idle 80\n",
+		time.Now().Unix())
+	assert.NoError(t, err)
+	_ = conn.Close()
+
+	waitUntil(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(metrics) == 2
+	})
+	mutex.Lock()
+	assert.Equal(t, "cpu", metrics[0].Name)
+	mutex.Unlock()
+
+	cancel()
+	waitUntil(t, func() bool {
+		_, err := net.DialTimeout("tcp", l.listener.Addr().String(), 10*time.Millisecond)
+		return err != nil
+	})
+}
+
+// waitUntil waits until condition is true, fails the test if timeout.
+func waitUntil(t *testing.T, condition func() bool) {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not satisfied in time")
+}
@@ -0,0 +1,274 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/internal/tenant"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoQueryV1 "github.com/lindb/lindb/proto/gen/v1/query"
+	lindQuery "github.com/lindb/lindb/query"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/sql"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+const (
+	// defaultSeriesChunkSize is the max num. of series in each chunk of metric query result.
+	defaultSeriesChunkSize = 100
+	// defaultValuesChunkSize is the max num. of values in each chunk of metadata query result.
+	defaultValuesChunkSize = 1000
+)
+
+// ClientQueryHandler implements the public query service, executes the metric/metadata query for clients
+// (like backend services), result is streamed in chunks so that http and json can be skipped entirely.
+type ClientQueryHandler struct {
+	queryFactory brokerQuery.Factory
+	timeout      time.Duration
+	logger       *logger.Logger
+}
+
+// NewClientQueryHandler returns a new ClientQueryHandler, timeout is used if caller doesn't set deadline.
+func NewClientQueryHandler(queryFactory brokerQuery.Factory, timeout time.Duration) *ClientQueryHandler {
+	return &ClientQueryHandler{
+		queryFactory: queryFactory,
+		timeout:      timeout,
+		logger:       logger.GetLogger("broker", "ClientQueryHandler"),
+	}
+}
+
+// MetricQuery executes the metric query, sends the result set in chunks of series,
+// the first chunk contains the header of result set, the last chunk is marked as completed.
+func (h *ClientQueryHandler) MetricQuery(req *protoQueryV1.MetricQueryRequest,
+	stream protoQueryV1.QueryService_MetricQueryServer,
+) error {
+	if req.Database == "" || req.Sql == "" {
+		return status.Error(codes.InvalidArgument, "database and sql cannot be empty")
+	}
+	var interval timeutil.Interval
+	if req.Interval != "" {
+		if err := interval.ValueOf(req.Interval); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	ctx, cancel := h.withTimeout(stream.Context())
+	defer cancel()
+	if req.Partial {
+		ctx = lindQuery.WithPartialResults(ctx)
+	}
+	if req.Stats {
+		ctx = lindQuery.WithStats(ctx)
+	}
+	if req.MaxPoints > 0 {
+		ctx = lindQuery.WithMaxPoints(ctx, int(req.MaxPoints))
+	}
+	if interval > 0 {
+		ctx = lindQuery.WithInterval(ctx, interval)
+	}
+	if req.Namespace != "" {
+		ctx = lindQuery.WithNamespace(ctx, req.Namespace)
+	}
+	resultSet, err := h.queryFactory.NewMetricQuery(ctx, req.Database, req.Sql, "").WaitResponse()
+	if err != nil {
+		h.logger.Warn("execute metric query for client error",
+			logger.String("database", req.Database), logger.String("sql", req.Sql), logger.Error(err))
+		return queryError(err)
+	}
+	chunkSize := int(req.ChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = defaultSeriesChunkSize
+	}
+	resp := &protoQueryV1.MetricQueryResponse{
+		MetricName: resultSet.MetricName,
+		StartTime:  resultSet.StartTime,
+		EndTime:    resultSet.EndTime,
+		Interval:   resultSet.Interval,
+		Fields:     resultSet.Fields,
+		Flags:      uint32(resultSet.Flags),
+	}
+	for idx, series := range resultSet.Series {
+		resp.Series = append(resp.Series, toProtoSeries(resultSet, series))
+		if len(resp.Series) == chunkSize && idx < len(resultSet.Series)-1 {
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			resp = &protoQueryV1.MetricQueryResponse{}
+		}
+	}
+	if resultSet.Stats != nil {
+		if resp.Stats, err = json.Marshal(resultSet.Stats); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	resp.Warnings = resultSet.Warnings
+	for _, failure := range resultSet.Failures {
+		resp.Failures = append(resp.Failures, &protoQueryV1.NodeFailure{
+			Node:     failure.Node,
+			ShardIDs: failure.ShardIDs,
+			Error:    failure.Error,
+		})
+	}
+	resp.Completed = true
+	return stream.Send(resp)
+}
+
+// MetadataQuery executes the metadata query(show namespaces/metrics/fields/tag keys/tag values etc.),
+// sends the values in chunks, structured result(cardinality, metric descriptor) is sent as json payload.
+func (h *ClientQueryHandler) MetadataQuery(req *protoQueryV1.MetadataQueryRequest,
+	stream protoQueryV1.QueryService_MetadataQueryServer,
+) error {
+	if req.Database == "" || req.Sql == "" {
+		return status.Error(codes.InvalidArgument, "database and sql cannot be empty")
+	}
+	statement, err := sql.Parse(req.Sql)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	request, ok := statement.(*stmt.Metadata)
+	if !ok || request.Type == stmt.Database {
+		return status.Error(codes.InvalidArgument, "not supported metadata statement")
+	}
+	if request.Limit > constants.MaxSuggestions {
+		request.Limit = constants.MaxSuggestions
+	}
+	ctx, cancel := h.withTimeout(stream.Context())
+	defer cancel()
+	if req.Namespace != "" {
+		ctx = lindQuery.WithNamespace(ctx, req.Namespace)
+	}
+	metadataQuery := h.queryFactory.NewMetadataQuery(ctx, req.Database, request)
+	values, err := metadataQuery.WaitResponse()
+	if err != nil {
+		h.logger.Warn("execute metadata query for client error",
+			logger.String("database", req.Database), logger.String("sql", req.Sql), logger.Error(err))
+		return queryError(err)
+	}
+	result, err := brokerQuery.BuildMetadata(request, values)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	resp := &protoQueryV1.MetadataQueryResponse{Type: result.Type}
+	switch rs := result.Values.(type) {
+	case []string:
+		chunkSize := int(req.ChunkSize)
+		if chunkSize <= 0 {
+			chunkSize = defaultValuesChunkSize
+		}
+		for len(rs) > chunkSize {
+			resp.Values = rs[:chunkSize]
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			rs = rs[chunkSize:]
+			resp = &protoQueryV1.MetadataQueryResponse{}
+		}
+		resp.Values = rs
+		resp.Total = int32(metadataQuery.Total())
+	case []models.Field:
+		for _, f := range rs {
+			resp.Fields = append(resp.Fields, &protoQueryV1.FieldMeta{Name: f.Name, Type: f.Type})
+		}
+	default:
+		if resp.Payload, err = json.Marshal(rs); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	resp.Completed = true
+	return stream.Send(resp)
+}
+
+// withTimeout returns the context with default timeout if caller doesn't set deadline.
+func (h *ClientQueryHandler) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, h.timeout)
+}
+
+// toProtoSeries converts the series of result set, fields are in order of select list, points in order of time.
+func toProtoSeries(resultSet *models.ResultSet, series *models.Series) *protoQueryV1.Series {
+	result := &protoQueryV1.Series{Tags: series.Tags}
+	names := make([]string, 0, len(series.Fields)+len(series.Strings))
+	for fieldName := range series.Fields {
+		names = append(names, fieldName)
+	}
+	for fieldName := range series.Strings {
+		names = append(names, fieldName)
+	}
+	for _, fieldName := range resultSet.SortFieldNames(names) {
+		f := &protoQueryV1.Field{Name: fieldName}
+		if points, ok := series.Fields[fieldName]; ok {
+			for timestamp := range points {
+				f.Timestamps = append(f.Timestamps, timestamp)
+			}
+			sortTimestamps(f.Timestamps)
+			for _, timestamp := range f.Timestamps {
+				f.Values = append(f.Values, points[timestamp])
+			}
+		} else {
+			values := series.Strings[fieldName]
+			for timestamp := range values {
+				f.Timestamps = append(f.Timestamps, timestamp)
+			}
+			sortTimestamps(f.Timestamps)
+			for _, timestamp := range f.Timestamps {
+				f.Strings = append(f.Strings, values[timestamp])
+			}
+		}
+		if flags := series.Flags[fieldName]; len(flags) > 0 {
+			for _, timestamp := range f.Timestamps {
+				f.Flags = append(f.Flags, uint32(flags[timestamp]))
+			}
+		}
+		result.Fields = append(result.Fields, f)
+	}
+	return result
+}
+
+// sortTimestamps sorts the timestamps in ascending order.
+func sortTimestamps(timestamps []int64) {
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+}
+
+// queryError converts the error of query to grpc status, rejection by quota/isolation of namespace
+// is not internal error.
+func queryError(err error) error {
+	switch {
+	case errors.Is(err, tenant.ErrQueryRateExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, tenant.ErrNamespaceIsolated):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
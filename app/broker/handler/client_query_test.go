@@ -0,0 +1,244 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/internal/tenant"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	protoQueryV1 "github.com/lindb/lindb/proto/gen/v1/query"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/series/field"
+)
+
+func TestClientQueryHandler_MetricQuery_Err(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	stream := protoQueryV1.NewMockQueryService_MetricQueryServer(ctrl)
+	stream.EXPECT().Context().Return(context.TODO()).AnyTimes()
+	h := NewClientQueryHandler(queryFactory, time.Second)
+
+	// case 1: bad request
+	err := h.MetricQuery(&protoQueryV1.MetricQueryRequest{Database: "db"}, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = h.MetricQuery(&protoQueryV1.MetricQueryRequest{Database: "db", Sql: "select f from cpu", Interval: "x"}, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	// case 2: query failure
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "db", "select f from cpu", "").Return(metricQuery).AnyTimes()
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{fmt.Errorf("err"), codes.Internal},
+		{tenant.ErrQueryRateExceeded, codes.ResourceExhausted},
+		{tenant.ErrNamespaceIsolated, codes.PermissionDenied},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+	}
+	for _, c := range cases {
+		metricQuery.EXPECT().WaitResponse().Return(nil, c.err)
+		err = h.MetricQuery(&protoQueryV1.MetricQueryRequest{Database: "db", Sql: "select f from cpu"}, stream)
+		assert.Equal(t, c.code, status.Code(err))
+	}
+	// case 3: send failure
+	rs := models.NewResultSet()
+	rs.AddSeries(models.NewSeries(nil))
+	rs.AddSeries(models.NewSeries(nil))
+	metricQuery.EXPECT().WaitResponse().Return(rs, nil).Times(2)
+	stream.EXPECT().Send(gomock.Any()).Return(fmt.Errorf("err"))
+	err = h.MetricQuery(&protoQueryV1.MetricQueryRequest{Database: "db", Sql: "select f from cpu", ChunkSize: 1}, stream)
+	assert.Error(t, err)
+	stream.EXPECT().Send(gomock.Any()).Return(fmt.Errorf("err"))
+	err = h.MetricQuery(&protoQueryV1.MetricQueryRequest{Database: "db", Sql: "select f from cpu"}, stream)
+	assert.Error(t, err)
+}
+
+func TestClientQueryHandler_MetricQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	stream := protoQueryV1.NewMockQueryService_MetricQueryServer(ctrl)
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+	defer cancel()
+	stream.EXPECT().Context().Return(ctx).AnyTimes()
+	h := NewClientQueryHandler(queryFactory, time.Second)
+
+	rs := models.NewResultSet()
+	rs.MetricName = "cpu"
+	rs.Interval = 10
+	rs.Fields = []string{"f2", "f1"}
+	rs.Stats = &models.QueryStats{TotalCost: 10}
+	rs.Warnings = []string{"warn"}
+	rs.Failures = []models.NodeFailure{{Node: "1.1.1.1:9000", ShardIDs: []int32{1}, Error: "timeout"}}
+	s1 := models.NewSeries(map[string]string{"host": "h1"})
+	points := models.NewPoints()
+	points.AddPoint(20, 2)
+	points.AddPointWithFlags(10, 1, models.PointFill)
+	s1.AddField("f1", points)
+	points = models.NewPoints()
+	points.AddPoint(10, 3)
+	s1.AddField("f2", points)
+	rs.AddSeries(s1)
+	s2 := models.NewSeries(map[string]string{"host": "h2"})
+	s2.AddStringField("version", map[int64]string{20: "v2", 10: "v1"})
+	rs.AddSeries(s2)
+
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "db", "select f from cpu", "").
+		DoAndReturn(func(ctx context.Context, _, _, _ string) brokerQuery.MetricQuery {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			// deadline of caller is used
+			assert.True(t, time.Until(deadline) > time.Second)
+			return metricQuery
+		})
+	metricQuery.EXPECT().WaitResponse().Return(rs, nil)
+	var chunks []*protoQueryV1.MetricQueryResponse
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoQueryV1.MetricQueryResponse) error {
+		chunks = append(chunks, resp)
+		return nil
+	}).Times(2)
+	err := h.MetricQuery(&protoQueryV1.MetricQueryRequest{
+		Database:  "db",
+		Sql:       "select f from cpu",
+		Namespace: "ns",
+		Partial:   true,
+		Stats:     true,
+		MaxPoints: 100,
+		Interval:  "10s",
+		ChunkSize: 1,
+	}, stream)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	// first chunk
+	assert.Equal(t, "cpu", chunks[0].MetricName)
+	assert.Equal(t, int64(10), chunks[0].Interval)
+	assert.Equal(t, []string{"f2", "f1"}, chunks[0].Fields)
+	assert.False(t, chunks[0].Completed)
+	assert.Equal(t, []*protoQueryV1.Series{{
+		Tags: map[string]string{"host": "h1"},
+		Fields: []*protoQueryV1.Field{
+			{Name: "f2", Timestamps: []int64{10}, Values: []float64{3}},
+			{Name: "f1", Timestamps: []int64{10, 20}, Values: []float64{1, 2},
+				Flags: []uint32{uint32(models.PointFill), 0}},
+		},
+	}}, chunks[0].Series)
+	// last chunk
+	assert.Empty(t, chunks[1].MetricName)
+	assert.True(t, chunks[1].Completed)
+	assert.Equal(t, []*protoQueryV1.Series{{
+		Tags: map[string]string{"host": "h2"},
+		Fields: []*protoQueryV1.Field{
+			{Name: "version", Timestamps: []int64{10, 20}, Strings: []string{"v1", "v2"}},
+		},
+	}}, chunks[1].Series)
+	stats := &models.QueryStats{}
+	assert.NoError(t, encoding.JSONUnmarshal(chunks[1].Stats, stats))
+	assert.Equal(t, rs.Stats.TotalCost, stats.TotalCost)
+	assert.Equal(t, []string{"warn"}, chunks[1].Warnings)
+	assert.Equal(t, []*protoQueryV1.NodeFailure{{Node: "1.1.1.1:9000", ShardIDs: []int32{1}, Error: "timeout"}},
+		chunks[1].Failures)
+	// chunk is encoded/decoded by grpc
+	data, err := chunks[0].Marshal()
+	assert.NoError(t, err)
+	decoded := &protoQueryV1.MetricQueryResponse{}
+	assert.NoError(t, decoded.Unmarshal(data))
+	assert.Equal(t, chunks[0].Series, decoded.Series)
+}
+
+func TestClientQueryHandler_MetadataQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metadataQuery := brokerQuery.NewMockMetaDataQuery(ctrl)
+	stream := protoQueryV1.NewMockQueryService_MetadataQueryServer(ctrl)
+	stream.EXPECT().Context().Return(context.TODO()).AnyTimes()
+	queryFactory.EXPECT().NewMetadataQuery(gomock.Any(), "db", gomock.Any()).Return(metadataQuery).AnyTimes()
+	h := NewClientQueryHandler(queryFactory, time.Second)
+
+	var chunks []*protoQueryV1.MetadataQueryResponse
+	send := func(resp *protoQueryV1.MetadataQueryResponse) error {
+		chunks = append(chunks, resp)
+		return nil
+	}
+	// case 1: bad request
+	for _, sql := range []string{"", "show x", "select f from cpu", "show databases"} {
+		err := h.MetadataQuery(&protoQueryV1.MetadataQueryRequest{Database: "db", Sql: sql}, stream)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+	// case 2: query failure
+	metadataQuery.EXPECT().WaitResponse().Return(nil, tenant.ErrNamespaceIsolated)
+	err := h.MetadataQuery(&protoQueryV1.MetadataQueryRequest{Database: "db", Sql: "show metrics", Namespace: "ns"}, stream)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	// case 3: build result failure
+	metadataQuery.EXPECT().WaitResponse().Return([]string{"xx"}, nil)
+	err = h.MetadataQuery(&protoQueryV1.MetadataQueryRequest{Database: "db", Sql: "show fields from cpu"}, stream)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	// case 4: values in chunks
+	metadataQuery.EXPECT().WaitResponse().Return([]string{"a", "b", "c"}, nil)
+	metadataQuery.EXPECT().Total().Return(10)
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(send).Times(2)
+	err = h.MetadataQuery(&protoQueryV1.MetadataQueryRequest{Database: "db", Sql: "show metrics limit 10000", ChunkSize: 2}, stream)
+	assert.NoError(t, err)
+	assert.Equal(t, []*protoQueryV1.MetadataQueryResponse{
+		{Type: "metric", Values: []string{"a", "b"}},
+		{Values: []string{"c"}, Total: 10, Completed: true},
+	}, chunks)
+	// case 5: fields
+	chunks = nil
+	metadataQuery.EXPECT().WaitResponse().Return([]string{
+		string(encoding.JSONMarshal(field.Metas{{Name: "f1", Type: field.SumField}})),
+	}, nil)
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(send)
+	err = h.MetadataQuery(&protoQueryV1.MetadataQueryRequest{Database: "db", Sql: "show fields from cpu"}, stream)
+	assert.NoError(t, err)
+	assert.Equal(t, []*protoQueryV1.MetadataQueryResponse{{
+		Type:      "field",
+		Fields:    []*protoQueryV1.FieldMeta{{Name: "f1", Type: field.SumField.String()}},
+		Completed: true,
+	}}, chunks)
+	// case 6: structured result
+	chunks = nil
+	metadataQuery.EXPECT().WaitResponse().Return(nil, nil)
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(send)
+	err = h.MetadataQuery(&protoQueryV1.MetadataQueryRequest{Database: "db", Sql: "describe metric cpu"}, stream)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	descriptor := &models.MetricDescriptor{}
+	assert.NoError(t, encoding.JSONUnmarshal(chunks[0].Payload, descriptor))
+	assert.Equal(t, "cpu", descriptor.Name)
+	// case 7: send failure
+	metadataQuery.EXPECT().WaitResponse().Return([]string{"a", "b", "c"}, nil)
+	stream.EXPECT().Send(gomock.Any()).Return(fmt.Errorf("err"))
+	err = h.MetadataQuery(&protoQueryV1.MetadataQueryRequest{Database: "db", Sql: "show metrics", ChunkSize: 2}, stream)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/pkg/logger"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	lindQuery "github.com/lindb/lindb/query"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+)
+
+// QueryHandler implements the query service, executes the metric query for federation query of other cluster.
+type QueryHandler struct {
+	queryFactory brokerQuery.Factory
+	timeout      time.Duration
+	logger       *logger.Logger
+}
+
+// NewQueryHandler returns a new QueryHandler, timeout is used if caller doesn't set deadline.
+func NewQueryHandler(queryFactory brokerQuery.Factory, timeout time.Duration) *QueryHandler {
+	return &QueryHandler{
+		queryFactory: queryFactory,
+		timeout:      timeout,
+		logger:       logger.GetLogger("broker", "QueryHandler"),
+	}
+}
+
+// Query executes the metric query, returns the result set as json.
+func (h *QueryHandler) Query(ctx context.Context, req *protoBrokerV1.QueryRequest) (*protoBrokerV1.QueryResponse, error) {
+	if req.Database == "" || req.Sql == "" {
+		return nil, status.Error(codes.InvalidArgument, "database and sql cannot be empty")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	if req.Partial {
+		ctx = lindQuery.WithPartialResults(ctx)
+	}
+	resultSet, err := h.queryFactory.NewMetricQuery(ctx, req.Database, req.Sql, "").WaitResponse()
+	if err != nil {
+		h.logger.Warn("execute query for federation error",
+			logger.String("database", req.Database), logger.String("sql", req.Sql), logger.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	payload, err := json.Marshal(resultSet)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &protoBrokerV1.QueryResponse{Payload: payload}, nil
+}
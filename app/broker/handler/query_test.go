@@ -0,0 +1,65 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/models"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+)
+
+func TestQueryHandler_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFactory := brokerQuery.NewMockFactory(ctrl)
+	metricQuery := brokerQuery.NewMockMetricQuery(ctrl)
+	h := NewQueryHandler(queryFactory, time.Second)
+
+	// case 1: bad request
+	_, err := h.Query(context.TODO(), &protoBrokerV1.QueryRequest{Database: "db"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	// case 2: query failure
+	queryFactory.EXPECT().NewMetricQuery(gomock.Any(), "db", "select f from cpu", "").
+		DoAndReturn(func(ctx context.Context, _, _, _ string) brokerQuery.MetricQuery {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			return metricQuery
+		}).Times(2)
+	metricQuery.EXPECT().WaitResponse().Return(nil, fmt.Errorf("err"))
+	_, err = h.Query(context.TODO(), &protoBrokerV1.QueryRequest{Database: "db", Sql: "select f from cpu"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	// case 3: query successfully
+	metricQuery.EXPECT().WaitResponse().Return(&models.ResultSet{MetricName: "cpu"}, nil)
+	resp, err := h.Query(context.TODO(), &protoBrokerV1.QueryRequest{Database: "db", Sql: "select f from cpu", Partial: true})
+	assert.NoError(t, err)
+	rs := &models.ResultSet{}
+	assert.NoError(t, json.Unmarshal(resp.Payload, rs))
+	assert.Equal(t, "cpu", rs.MetricName)
+}
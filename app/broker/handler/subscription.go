@@ -0,0 +1,80 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/pkg/logger"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	"github.com/lindb/lindb/replication"
+)
+
+// SubscriptionHandler implements the subscription service, pushes written metrics of database
+// which match the filter to external consumers over grpc stream in near real time.
+type SubscriptionHandler struct {
+	subscriptions replication.Subscriptions
+	logger        *logger.Logger
+}
+
+// NewSubscriptionHandler returns a new SubscriptionHandler.
+func NewSubscriptionHandler(subscriptions replication.Subscriptions) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptions: subscriptions,
+		logger:        logger.GetLogger("broker", "SubscriptionHandler"),
+	}
+}
+
+// Subscribe registers a subscriber with the filter of request, then pushes matched metrics until
+// stream is closed by consumer or subscriber is removed when broker stopping.
+func (h *SubscriptionHandler) Subscribe(req *protoBrokerV1.SubscribeRequest,
+	stream protoBrokerV1.SubscriptionService_SubscribeServer) error {
+	if req.Database == "" {
+		return status.Error(codes.InvalidArgument, "database cannot be empty")
+	}
+	subscriber, err := h.subscriptions.Subscribe(req.Database, req.MetricPrefix)
+	if err != nil {
+		if errors.Is(err, replication.ErrSubscriptionDisabled) {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer h.subscriptions.Unsubscribe(subscriber)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case data, ok := <-subscriber.C():
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&protoBrokerV1.SubscribeResponse{
+				Data:    data,
+				Dropped: subscriber.Dropped(),
+			}); err != nil {
+				h.logger.Warn("send metrics to subscriber error",
+					logger.String("database", req.Database), logger.Error(err))
+				return err
+			}
+		}
+	}
+}
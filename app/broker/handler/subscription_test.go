@@ -0,0 +1,92 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/config"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+func TestSubscriptionHandler_Subscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stream := protoBrokerV1.NewMockSubscriptionService_SubscribeServer(ctrl)
+	subscriptions := replication.NewSubscriptions(config.Subscription{MaxSubscribers: 1})
+	h := NewSubscriptionHandler(subscriptions)
+
+	// case 1: database is empty
+	err := h.Subscribe(&protoBrokerV1.SubscribeRequest{}, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	// case 2: subscription disabled
+	err = NewSubscriptionHandler(replication.NewSubscriptions(config.Subscription{})).
+		Subscribe(&protoBrokerV1.SubscribeRequest{Database: "db"}, stream)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	// case 3: too many subscribers
+	s, err := subscriptions.Subscribe("db", "")
+	assert.NoError(t, err)
+	err = h.Subscribe(&protoBrokerV1.SubscribeRequest{Database: "db"}, stream)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	subscriptions.Unsubscribe(s)
+	// case 4: stream closed by consumer
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	stream.EXPECT().Context().Return(ctx)
+	err = h.Subscribe(&protoBrokerV1.SubscribeRequest{Database: "db"}, stream)
+	assert.NoError(t, err)
+	// case 5: send metrics failure
+	stream.EXPECT().Context().Return(context.TODO()).AnyTimes()
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoBrokerV1.SubscribeResponse) error {
+		metricList := &protoMetricsV1.MetricList{}
+		assert.NoError(t, metricList.Unmarshal(resp.Data))
+		assert.Equal(t, "cpu.load", metricList.Metrics[0].Name)
+		return fmt.Errorf("err")
+	})
+	publish(subscriptions)
+	err = h.Subscribe(&protoBrokerV1.SubscribeRequest{Database: "db", MetricPrefix: "cpu"}, stream)
+	assert.Error(t, err)
+	// case 6: subscriber removed when broker stopping
+	stream.EXPECT().Send(gomock.Any()).Return(nil)
+	publish(subscriptions)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		subscriptions.Close()
+	}()
+	err = h.Subscribe(&protoBrokerV1.SubscribeRequest{Database: "db"}, stream)
+	assert.NoError(t, err)
+}
+
+// publish publishes metrics when subscriber registered.
+func publish(subscriptions replication.Subscriptions) {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		subscriptions.Publish("db", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "cpu.load"}}})
+	}()
+}
@@ -0,0 +1,86 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/lindb/lindb/pkg/logger"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+const (
+	// writeOK represents data is written or cannot be written by retrying(rejected metrics/bad data),
+	// so sender should not re-send the data.
+	writeOK int32 = iota
+	// writeFailure represents data isn't written, sender can re-send the data later.
+	writeFailure
+)
+
+// WriterHandler implements the broker write service, writes metrics sent by broker of other clusters(mirroring).
+type WriterHandler struct {
+	cm     replication.ChannelManager
+	logger *logger.Logger
+}
+
+// NewWriterHandler returns a new WriterHandler.
+func NewWriterHandler(cm replication.ChannelManager) *WriterHandler {
+	return &WriterHandler{
+		cm:     cm,
+		logger: logger.GetLogger("broker", "WriterHandler"),
+	}
+}
+
+// Write receives the write requests from stream, writes metrics into channel manager
+// and responses the result of each request in order.
+func (h *WriterHandler) Write(stream protoBrokerV1.BrokerService_WriteServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(h.write(req)); err != nil {
+			h.logger.Warn("send write response error", logger.String("database", req.Database), logger.Error(err))
+			return err
+		}
+	}
+}
+
+// write writes the metrics of request, returns the response.
+func (h *WriterHandler) write(req *protoBrokerV1.WriteRequest) *protoBrokerV1.WriteResponse {
+	metricList := &protoMetricsV1.MetricList{}
+	if err := metricList.Unmarshal(req.Data); err != nil {
+		h.logger.Error("unmarshal metric list error", logger.String("database", req.Database), logger.Error(err))
+		return &protoBrokerV1.WriteResponse{Code: writeOK, Message: err.Error()}
+	}
+	err := h.cm.WriteBatch(req.Database, metricList)
+	if err == nil {
+		return &protoBrokerV1.WriteResponse{Code: writeOK}
+	}
+	var partialErr *replication.PartialWriteError
+	if errors.As(err, &partialErr) {
+		return &protoBrokerV1.WriteResponse{Code: writeOK, Message: err.Error()}
+	}
+	return &protoBrokerV1.WriteResponse{Code: writeFailure, Message: err.Error()}
+}
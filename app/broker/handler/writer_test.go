@@ -0,0 +1,77 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+func TestWriterHandler_Write(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	stream := protoBrokerV1.NewMockBrokerService_WriteServer(ctrl)
+	h := NewWriterHandler(cm)
+
+	data, _ := (&protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "cpu"}}}).Marshal()
+	// case 1: bad data
+	stream.EXPECT().Recv().Return(&protoBrokerV1.WriteRequest{Database: "db", Data: []byte{1, 2, 3}}, nil)
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoBrokerV1.WriteResponse) error {
+		assert.Equal(t, writeOK, resp.Code)
+		assert.NotEmpty(t, resp.Message)
+		return nil
+	})
+	// case 2: write successfully
+	stream.EXPECT().Recv().Return(&protoBrokerV1.WriteRequest{Database: "db", Data: data}, nil)
+	cm.EXPECT().WriteBatch("db", gomock.Any()).Return(nil)
+	stream.EXPECT().Send(&protoBrokerV1.WriteResponse{Code: writeOK}).Return(nil)
+	// case 3: metrics rejected
+	stream.EXPECT().Recv().Return(&protoBrokerV1.WriteRequest{Database: "db", Data: data}, nil)
+	cm.EXPECT().WriteBatch("db", gomock.Any()).Return(&replication.PartialWriteError{Rejected: 1})
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoBrokerV1.WriteResponse) error {
+		assert.Equal(t, writeOK, resp.Code)
+		assert.NotEmpty(t, resp.Message)
+		return nil
+	})
+	// case 4: write failure
+	stream.EXPECT().Recv().Return(&protoBrokerV1.WriteRequest{Database: "db", Data: data}, nil)
+	cm.EXPECT().WriteBatch("db", gomock.Any()).Return(fmt.Errorf("database not found"))
+	stream.EXPECT().Send(&protoBrokerV1.WriteResponse{Code: writeFailure, Message: "database not found"}).Return(nil)
+	// case 5: stream closed
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+	assert.NoError(t, h.Write(stream))
+
+	// case 6: recv failure
+	stream.EXPECT().Recv().Return(nil, fmt.Errorf("err"))
+	assert.Error(t, h.Write(stream))
+	// case 7: send failure
+	stream.EXPECT().Recv().Return(&protoBrokerV1.WriteRequest{Database: "db", Data: data}, nil)
+	cm.EXPECT().WriteBatch("db", gomock.Any()).Return(nil)
+	stream.EXPECT().Send(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, h.Write(stream))
+}
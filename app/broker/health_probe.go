@@ -0,0 +1,187 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cespare/xxhash"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/replication"
+	"github.com/lindb/lindb/series/tag"
+)
+
+// for testing
+var (
+	probeRetryInterval = time.Second
+)
+
+const (
+	healthProbeMetricName = "lindb.broker.health_probe"
+	healthProbeFieldName  = "probe"
+	healthProbeTagKey     = "node"
+)
+
+var (
+	errProbeDataNotFound = errors.New("health probe data not found")
+)
+
+var (
+	healthProbeScope         = linmetric.NewScope("lindb.broker.health_probe")
+	probeSuccessCounterVec   = healthProbeScope.NewDeltaCounterVec("success", "db")
+	probeFailureCounterVec   = healthProbeScope.NewDeltaCounterVec("failure", "db")
+	probeWriteFailCounterVec = healthProbeScope.NewDeltaCounterVec("write_failures", "db")
+	probeLatencyTimerVec     = healthProbeScope.Scope("latency").NewDeltaHistogramVec("db").
+					WithExponentBuckets(time.Millisecond, time.Minute, 20)
+)
+
+// healthProbe writes a synthetic series into each configured database every interval,
+// then reads it back through storage, as an end-to-end canary of write/query path.
+type healthProbe struct {
+	ctx          context.Context
+	node         models.Node
+	cfg          config.HealthProbe
+	cm           replication.ChannelManager
+	queryFactory brokerQuery.Factory
+
+	logger *logger.Logger
+}
+
+// newHealthProbe creates the health probe.
+func newHealthProbe(
+	ctx context.Context,
+	node models.Node,
+	cfg config.HealthProbe,
+	cm replication.ChannelManager,
+	queryFactory brokerQuery.Factory,
+) *healthProbe {
+	return &healthProbe{
+		ctx:          ctx,
+		node:         node,
+		cfg:          cfg,
+		cm:           cm,
+		queryFactory: queryFactory,
+		logger:       logger.GetLogger("broker", "HealthProbe"),
+	}
+}
+
+// Run probes all configured databases every interval until context is done.
+func (p *healthProbe) Run() {
+	ticker := time.NewTicker(p.cfg.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.probeAll()
+		}
+	}
+}
+
+// probeAll probes all configured databases.
+func (p *healthProbe) probeAll() {
+	for _, db := range p.cfg.Databases {
+		start := time.Now()
+		if err := p.probe(db); err != nil {
+			probeFailureCounterVec.WithTagValues(db).Incr()
+			p.logger.Warn("health probe failure",
+				logger.String("db", db), logger.Error(err))
+			continue
+		}
+		probeSuccessCounterVec.WithTagValues(db).Incr()
+		probeLatencyTimerVec.WithTagValues(db).UpdateSince(start)
+	}
+}
+
+// probe writes the synthetic series into database, then waits it can be read back.
+func (p *healthProbe) probe(database string) error {
+	now := timeutil.Now()
+	// timestamp in millisecond can be represented by float64 exactly
+	value := float64(now)
+	if err := p.cm.Write(database, p.buildMetricList(now, value)); err != nil {
+		probeWriteFailCounterVec.WithTagValues(database).Incr()
+		return err
+	}
+	ctx, cancel := context.WithTimeout(p.ctx, p.cfg.Timeout.Duration())
+	defer cancel()
+	sql := fmt.Sprintf("select %s from %s where %s='%s' and time>now()-%ds",
+		healthProbeFieldName, healthProbeMetricName, healthProbeTagKey, p.node.Indicator(),
+		int64(p.cfg.Timeout.Duration().Seconds())+1)
+	for {
+		err := p.read(ctx, database, sql, value)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("read health probe data timeout, last error: %w", err)
+		case <-time.After(probeRetryInterval):
+		}
+	}
+}
+
+// read queries the synthetic series, returns nil if the expect value found.
+func (p *healthProbe) read(ctx context.Context, database, sql string, expect float64) error {
+	rs, err := p.queryFactory.NewMetricQuery(ctx, database, sql, "").WaitResponse()
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		return errProbeDataNotFound
+	}
+	for _, s := range rs.Series {
+		for _, v := range s.Fields[healthProbeFieldName] {
+			if v == expect {
+				return nil
+			}
+		}
+	}
+	return errProbeDataNotFound
+}
+
+// buildMetricList builds the synthetic series.
+func (p *healthProbe) buildMetricList(timestamp int64, value float64) *protoMetricsV1.MetricList {
+	tags := tag.KeyValues{{Key: healthProbeTagKey, Value: p.node.Indicator()}}
+	return &protoMetricsV1.MetricList{
+		Metrics: []*protoMetricsV1.Metric{{
+			Namespace: constants.DefaultNamespace,
+			Name:      healthProbeMetricName,
+			Timestamp: timestamp,
+			Tags:      tags,
+			TagsHash:  xxhash.Sum64String(tag.ConcatKeyValues(tags)),
+			SimpleFields: []*protoMetricsV1.SimpleField{{
+				Name:  healthProbeFieldName,
+				Type:  protoMetricsV1.SimpleFieldType_GAUGE,
+				Value: value,
+			}},
+		}},
+	}
+}
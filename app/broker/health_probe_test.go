@@ -0,0 +1,116 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/ltoml"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	brokerQuery "github.com/lindb/lindb/query/broker"
+	"github.com/lindb/lindb/replication"
+)
+
+func TestHealthProbe_probe(t *testing.T) {
+	defer func() {
+		probeRetryInterval = time.Second
+	}()
+	probeRetryInterval = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// case 1: write failure
+	cm, q, p := newTestHealthProbe(ctrl)
+	cm.EXPECT().Write("db", gomock.Any()).Return(fmt.Errorf("err"))
+	err := p.probe("db")
+	assert.Error(t, err)
+	// case 2: query failure
+	cm, q, p = newTestHealthProbe(ctrl)
+	cm.EXPECT().Write("db", gomock.Any()).Return(nil)
+	q.EXPECT().WaitResponse().Return(nil, fmt.Errorf("err")).MinTimes(1)
+	err = p.probe("db")
+	assert.Error(t, err)
+	// case 3: result set nil
+	cm, q, p = newTestHealthProbe(ctrl)
+	cm.EXPECT().Write("db", gomock.Any()).Return(nil)
+	q.EXPECT().WaitResponse().Return(nil, nil).MinTimes(1)
+	err = p.probe("db")
+	assert.Error(t, err)
+	// case 4: read back after retry
+	cm, q, p = newTestHealthProbe(ctrl)
+	var written float64
+	cm.EXPECT().Write("db", gomock.Any()).DoAndReturn(func(_ string, metricList *protoMetricsV1.MetricList) error {
+		metric := metricList.Metrics[0]
+		assert.Equal(t, healthProbeMetricName, metric.Name)
+		assert.Equal(t, "1.1.1.1:9000", metric.Tags[0].Value)
+		assert.NotZero(t, metric.TagsHash)
+		written = metric.SimpleFields[0].Value
+		return nil
+	})
+	gomock.InOrder(
+		q.EXPECT().WaitResponse().Return(&models.ResultSet{}, nil),
+		q.EXPECT().WaitResponse().DoAndReturn(func() (*models.ResultSet, error) {
+			return &models.ResultSet{Series: []*models.Series{{
+				Fields: map[string]map[int64]float64{healthProbeFieldName: {10: written}},
+			}}}, nil
+		}),
+	)
+	err = p.probe("db")
+	assert.NoError(t, err)
+}
+
+func newTestHealthProbe(ctrl *gomock.Controller) (*replication.MockChannelManager, *brokerQuery.MockMetricQuery, *healthProbe) {
+	cm := replication.NewMockChannelManager(ctrl)
+	factory := brokerQuery.NewMockFactory(ctrl)
+	q := brokerQuery.NewMockMetricQuery(ctrl)
+	factory.EXPECT().NewMetricQuery(gomock.Any(), "db", gomock.Any(), "").Return(q).AnyTimes()
+	p := newHealthProbe(context.TODO(), models.Node{IP: "1.1.1.1", Port: 9000}, config.HealthProbe{
+		Interval:  ltoml.Duration(time.Millisecond),
+		Timeout:   ltoml.Duration(50 * time.Millisecond),
+		Databases: []string{"db"},
+	}, cm, factory)
+	return cm, q, p
+}
+
+func TestHealthProbe_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	ctx, cancel := context.WithCancel(context.TODO())
+	p := newHealthProbe(ctx, models.Node{IP: "1.1.1.1", Port: 9000}, config.HealthProbe{
+		Interval:  ltoml.Duration(10 * time.Millisecond),
+		Timeout:   ltoml.Duration(time.Millisecond),
+		Databases: []string{"db"},
+	}, cm, nil)
+	cm.EXPECT().Write("db", gomock.Any()).Return(fmt.Errorf("err")).MinTimes(1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	p.Run()
+}
@@ -30,18 +30,28 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/lindb/lindb"
+	"github.com/lindb/lindb/app/broker/api/query"
 	"github.com/lindb/lindb/app/broker/middleware"
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/series/tag"
 )
 
-const _apiRootPath = "/api/v1"
+const (
+	_apiRootPath     = "/api/v1"
+	_metricsRootPath = "/metrics"
+)
 
 // HTTPServer represents http server with gin framework.
 type HTTPServer struct {
 	addr   string
+	cfg    config.HTTP
 	server http.Server
 	gin    *gin.Engine
+	// root is the router group of base path, all routes are registered under it
+	root *gin.RouterGroup
 
 	logger *logger.Logger
 }
@@ -50,6 +60,7 @@ type HTTPServer struct {
 func NewHTTPServer(cfg config.HTTP) *HTTPServer {
 	s := &HTTPServer{
 		addr: fmt.Sprintf(":%d", cfg.Port),
+		cfg:  cfg,
 		gin:  gin.New(),
 		server: http.Server{
 			// use extra timeout for ingestion and query timeout
@@ -66,15 +77,21 @@ func NewHTTPServer(cfg config.HTTP) *HTTPServer {
 // init initializes http server default router/handle/middleware.
 func (s *HTTPServer) init() {
 	// Using middlewares on group.
+	// assign request id before access log, so that access log includes it
+	s.gin.Use(middleware.RequestIDMiddleware())
+	// resolve client ip behind reverse proxy for access log and rate limit
+	s.gin.Use(middleware.ClientIPMiddleware(s.cfg.TrustedProxies))
 	// use AccessLogMiddleware to log panic error with zap
 	s.gin.Use(middleware.AccessLogMiddleware())
-	s.gin.Use(cors.Default())
+	s.gin.Use(s.newCORS())
 
+	basePath := s.cfg.GetBasePath()
+	s.root = s.gin.Group(basePath)
 	if logger.IsDebug() {
-		s.logger.Info("/debug/pprof is enabled")
-		pprof.Register(s.gin)
-		s.logger.Info("/debug/fgprof is enabled")
-		s.gin.GET("/debug/fgprof", gin.WrapH(fgprof.Handler()))
+		s.logger.Info(basePath + "/debug/pprof is enabled")
+		pprof.RouteRegister(s.root)
+		s.logger.Info(basePath + "/debug/fgprof is enabled")
+		s.root.GET("/debug/fgprof", gin.WrapH(fgprof.Handler()))
 	}
 	// server static file
 	staticFS, err := fs.Sub(lindb.StaticContent, "web/static")
@@ -82,18 +99,54 @@ func (s *HTTPServer) init() {
 	if err != nil {
 		s.logger.Error("cannot find static resource", logger.Error(err))
 	} else {
-		s.gin.StaticFS(staticHome, http.FS(staticFS))
+		s.root.StaticFS(staticHome, http.FS(staticFS))
 		// redirects to admin console
-		s.gin.GET("/", func(c *gin.Context) {
-			c.Request.URL.Path = staticHome
+		s.root.GET("/", func(c *gin.Context) {
+			c.Request.URL.Path = basePath + staticHome
 			s.gin.HandleContext(c)
 		})
 	}
 }
 
+// newCORS creates the cors middleware based on cors policy, all origins are allowed if not configured,
+// cross-origin requests are rejected if policy is invalid.
+func (s *HTTPServer) newCORS() gin.HandlerFunc {
+	policy := s.cfg.CORS
+	corsCfg := cors.DefaultConfig()
+	corsCfg.AllowMethods = append(corsCfg.AllowMethods, http.MethodOptions)
+	corsCfg.AllowHeaders = append(corsCfg.AllowHeaders,
+		"Authorization", tracing.RequestIDHeader, middleware.TopologyVersionHeader)
+	corsCfg.AllowHeaders = append(corsCfg.AllowHeaders, policy.AllowHeaders...)
+	corsCfg.ExposeHeaders = []string{tracing.RequestIDHeader, query.QueryIDHeader, "Retry-After",
+		middleware.TopologyVersionHeader, middleware.TopologyStaleHeader}
+	corsCfg.AllowCredentials = policy.AllowCredentials
+	corsCfg.AllowWildcard = true
+	if policy.MaxAge > 0 {
+		corsCfg.MaxAge = policy.MaxAge.Duration()
+	}
+	if len(policy.AllowOrigins) == 0 {
+		corsCfg.AllowAllOrigins = true
+	} else {
+		corsCfg.AllowOrigins = policy.AllowOrigins
+	}
+	if err := corsCfg.Validate(); err != nil {
+		s.logger.Error("invalid cors policy, cross-origin requests are rejected", logger.Error(err))
+		corsCfg.AllowAllOrigins = false
+		corsCfg.AllowOrigins = nil
+		corsCfg.AllowOriginFunc = func(string) bool { return false }
+	}
+	return cors.New(corsCfg)
+}
+
 // GetAPIRouter returns api router.
 func (s *HTTPServer) GetAPIRouter() *gin.RouterGroup {
-	return s.gin.Group(_apiRootPath)
+	return s.root.Group(_apiRootPath)
+}
+
+// RegisterMetricsHandler exposes self-monitoring metrics with prometheus text format,
+// global key values are added as labels of each metric.
+func (s *HTTPServer) RegisterMetricsHandler(globalKeyValues tag.KeyValues) {
+	s.root.GET(_metricsRootPath, gin.WrapH(linmetric.NewPrometheusHandler(globalKeyValues)))
 }
 
 // Run runs the HTTP server.
@@ -19,9 +19,14 @@ package broker
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
+
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/series/tag"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +41,77 @@ func TestNewHTTPServer(t *testing.T) {
 		_ = s.Close(context.TODO())
 	}()
 }
+
+func TestHTTPServer_Console(t *testing.T) {
+	s := NewHTTPServer(config.HTTP{Port: 9999})
+	// static resource of web console
+	req := httptest.NewRequest(http.MethodGet, "/console/README.md", nil)
+	resp := httptest.NewRecorder()
+	s.gin.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	// root path redirects to console
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	resp = httptest.NewRecorder()
+	s.gin.ServeHTTP(resp, req)
+	assert.NotEqual(t, http.StatusNotFound, resp.Code)
+}
+
+func TestHTTPServer_Metrics(t *testing.T) {
+	s := NewHTTPServer(config.HTTP{Port: 9999})
+	s.RegisterMetricsHandler(tag.KeyValues{{Key: "role", Value: "broker"}})
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	resp := httptest.NewRecorder()
+	s.gin.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `role="broker"`)
+}
+
+func TestHTTPServer_BasePath(t *testing.T) {
+	s := NewHTTPServer(config.HTTP{Port: 9999, BasePath: "/lindb/"})
+	s.GetAPIRouter().GET("/test", func(c *gin.Context) {})
+	s.RegisterMetricsHandler(nil)
+	for path, code := range map[string]int{
+		"/lindb/api/v1/test":        http.StatusOK,
+		"/api/v1/test":              http.StatusNotFound,
+		"/lindb/metrics":            http.StatusOK,
+		"/lindb/console/README.md":  http.StatusOK,
+		"/console/README.md":        http.StatusNotFound,
+		"/lindb/api/v1/not-a-route": http.StatusNotFound,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		resp := httptest.NewRecorder()
+		s.gin.ServeHTTP(resp, req)
+		assert.Equal(t, code, resp.Code, path)
+	}
+	// base path redirects to console
+	req := httptest.NewRequest(http.MethodGet, "/lindb/", nil)
+	resp := httptest.NewRecorder()
+	s.gin.ServeHTTP(resp, req)
+	assert.NotEqual(t, http.StatusNotFound, resp.Code)
+}
+
+func TestHTTPServer_CORS(t *testing.T) {
+	examples := []struct {
+		cors   config.CORS
+		origin string
+		allow  string
+	}{
+		// all origins allowed by default
+		{config.CORS{}, "http://a.com", "*"},
+		// origin allowed
+		{config.CORS{AllowOrigins: []string{"https://*.example.com"}}, "https://grafana.example.com", "https://grafana.example.com"},
+		// origin not allowed
+		{config.CORS{AllowOrigins: []string{"https://*.example.com"}}, "http://a.com", ""},
+		// invalid policy
+		{config.CORS{AllowOrigins: []string{"a.com"}}, "http://a.com", ""},
+	}
+	for _, example := range examples {
+		s := NewHTTPServer(config.HTTP{Port: 9999, CORS: example.cors})
+		s.GetAPIRouter().GET("/test", func(c *gin.Context) {})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/test", nil)
+		req.Header.Set("Origin", example.origin)
+		resp := httptest.NewRecorder()
+		s.gin.ServeHTTP(resp, req)
+		assert.Equal(t, example.allow, resp.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/pkg/logger"
@@ -55,27 +56,34 @@ func AccessLogMiddleware() gin.HandlerFunc {
 			if err != nil {
 				unescapedPath = path
 			}
-			// http://httpd.apache.org/docs/1.3/logs.html?PHPSESSID=026558d61a93eafd6da3438bb9605d4d#common
-			requestInfo := realIP(r) + " " + strconv.Itoa(int(time.Since(start).Milliseconds())) + "ms" +
-				" \"" + r.Method + " " + unescapedPath + " " + r.Proto + "\" " +
-				strconv.Itoa(c.Writer.Status()) + " " + strconv.Itoa(c.Writer.Size())
+			// structured access log with request id, latency and status
+			fields := []zap.Field{
+				logger.String("requestID", c.GetString(RequestIDKey)),
+				logger.String("remote", clientIP(c)),
+				logger.String("method", r.Method),
+				logger.String("uri", unescapedPath),
+				logger.String("proto", r.Proto),
+				logger.Int64("status", int64(c.Writer.Status())),
+				logger.Int64("size", int64(c.Writer.Size())),
+				logger.Int64("latencyMs", time.Since(start).Milliseconds()),
+			}
 
 			r := recover()
 			switch {
 			case r != nil:
-				logger.AccessLog.Error(requestInfo, logger.Stack())
+				logger.AccessLog.Error("access", append(fields, logger.Stack())...)
 			case len(c.Errors) > 0:
-				logger.AccessLog.Error(requestInfo, logger.Error(c.Errors[0].Err))
+				logger.AccessLog.Error("access", append(fields, logger.Error(c.Errors[0].Err))...)
 			default:
-				logger.AccessLog.Info(requestInfo)
+				logger.AccessLog.Info("access", fields...)
 			}
 
 			paths := strings.Split(unescapedPath, "?")
 			if len(paths) > 0 {
 				path = paths[0]
 			}
-			// ignore admin web static js, css files
-			if strings.HasPrefix(path, "/api/") {
+			// ignore admin web static js, css files, api path maybe has base path prefix
+			if strings.Contains(path, "/api/") {
 				httHandlerTimerVec.
 					WithTagValues(path, strconv.Itoa(c.Writer.Status())).
 					UpdateSince(start)
@@ -0,0 +1,153 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/app/broker/audit"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+const (
+	// maxAuditPayloadSize is the max size of request body recorded as payload of audit entry.
+	maxAuditPayloadSize = 4 * 1024
+	// redactedValue replaces the value of sensitive field in payload.
+	redactedValue = "******"
+)
+
+var auditLogger = logger.GetLogger("broker", "Audit")
+
+// Audit returns the middleware which records the mutating request(except GET/HEAD/OPTIONS) into audit log
+// after the request is handled, the request denied by authorizer is also recorded, so it must be applied
+// before authorize middleware. If audit log is nil, audit is disabled.
+func Audit(auditLog audit.Log) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := c.Request
+		if auditLog == nil ||
+			r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		var body []byte
+		if r.Body != nil {
+			// keep request body for handler
+			body, _ = io.ReadAll(r.Body)
+			_ = r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		c.Next()
+
+		operation := r.Method + " " + r.URL.Path
+		if r.URL.RawQuery != "" {
+			operation += "?" + redactForm(r.URL.RawQuery)
+		}
+		entry := &models.AuditEntry{
+			Actor:     PrincipalFromContext(r.Context()),
+			ClientIP:  realIP(r),
+			Operation: operation,
+			Payload:   redactPayload(r.Header.Get("Content-Type"), body),
+			Status:    c.Writer.Status(),
+		}
+		if entry.Actor == "" {
+			entry.Actor = entry.ClientIP
+		}
+		if err := c.Errors.Last(); err != nil {
+			entry.Error = err.Error()
+		}
+		if err := auditLog.Record(r.Context(), entry); err != nil {
+			auditLogger.Error("record audit log failure",
+				logger.String("operation", entry.Operation), logger.Error(err))
+		}
+	}
+}
+
+// redactPayload returns the payload of request body with sensitive fields redacted, truncated if too large.
+func redactPayload(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var payload string
+	switch {
+	case json.Valid(body):
+		var value interface{}
+		_ = json.Unmarshal(body, &value)
+		data, _ := json.Marshal(redactJSON(value))
+		payload = string(data)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		payload = redactForm(string(body))
+	default:
+		payload = string(body)
+	}
+	if len(payload) > maxAuditPayloadSize {
+		payload = payload[:maxAuditPayloadSize] + "..."
+	}
+	return payload
+}
+
+// redactJSON redacts the value of sensitive fields in json object recursively.
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveField(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactJSON(item)
+			}
+		}
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+// redactForm redacts the value of sensitive fields in url encoded form.
+func redactForm(form string) string {
+	values, err := url.ParseQuery(form)
+	if err != nil {
+		return form
+	}
+	redacted := false
+	for key := range values {
+		if isSensitiveField(key) {
+			values[key] = []string{redactedValue}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return form
+	}
+	return values.Encode()
+}
+
+// isSensitiveField returns if the value of field cannot be recorded, like password/token/secret.
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "password") || strings.Contains(name, "token") || strings.Contains(name, "secret")
+}
@@ -0,0 +1,131 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/app/broker/audit"
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/models"
+)
+
+func TestAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditLog := audit.NewMockLog(ctrl)
+	var entries []*models.AuditEntry
+	auditLog.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, entry *models.AuditEntry) error {
+			entries = append(entries, entry)
+			return nil
+		}).AnyTimes()
+
+	r := gin.New()
+	route := r.Group("", Audit(auditLog))
+	route.GET("/database", func(c *gin.Context) {
+		c.JSON(http.StatusOK, "ok")
+	})
+	route.POST("/database", func(c *gin.Context) {
+		var param map[string]interface{}
+		if err := c.ShouldBindJSON(&param); err != nil {
+			_ = c.Error(err)
+			c.JSON(http.StatusInternalServerError, err.Error())
+			return
+		}
+		// handler can read the request body
+		assert.Equal(t, "admin123", param["config"].(map[string]interface{})["password"])
+		c.JSON(http.StatusNoContent, nil)
+	})
+
+	// get request isn't recorded
+	resp := mock.DoRequest(t, r, http.MethodGet, "/database", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, entries)
+
+	resp = mock.DoRequest(t, r, http.MethodPost, "/database?token=abc",
+		`{"name":"test","config":{"username":"admin","password":"admin123"}}`)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "POST /database?token=%2A%2A%2A%2A%2A%2A", entry.Operation)
+	assert.Equal(t, `{"config":{"password":"******","username":"admin"},"name":"test"}`, entry.Payload)
+	assert.Equal(t, http.StatusNoContent, entry.Status)
+	assert.Equal(t, entry.ClientIP, entry.Actor)
+	assert.Empty(t, entry.Error)
+
+	// handle failure
+	resp = mock.DoRequest(t, r, http.MethodPost, "/database", "bad")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "bad", entries[1].Payload)
+	assert.NotEmpty(t, entries[1].Error)
+
+	// actor is the principal
+	r = gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), "admin"))
+	})
+	r.Group("", Audit(auditLog), Authorize(OperationAdmin)).DELETE("/database", func(c *gin.Context) {
+		c.JSON(http.StatusNoContent, nil)
+	})
+	resp = mock.DoRequest(t, r, http.MethodDelete, "/database?name=test", "")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "admin", entries[2].Actor)
+	assert.Equal(t, "DELETE /database?name=test", entries[2].Operation)
+
+	// record failure
+	auditLog = audit.NewMockLog(ctrl)
+	auditLog.EXPECT().Record(gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	r = gin.New()
+	r.Group("", Audit(auditLog)).PUT("/drain", func(c *gin.Context) {
+		c.JSON(http.StatusOK, "ok")
+	})
+	resp = mock.DoRequest(t, r, http.MethodPut, "/drain", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// audit disabled
+	r = gin.New()
+	r.Group("", Audit(nil)).PUT("/drain", func(c *gin.Context) {
+		c.JSON(http.StatusOK, "ok")
+	})
+	resp = mock.DoRequest(t, r, http.MethodPut, "/drain", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAudit_redactPayload(t *testing.T) {
+	assert.Empty(t, redactPayload("", nil))
+	assert.Equal(t, `[{"secretKey":"******"}]`, redactPayload("application/json", []byte(`[{"secretKey":"k"}]`)))
+	assert.Equal(t, "password=%2A%2A%2A%2A%2A%2A&username=admin",
+		redactPayload("application/x-www-form-urlencoded", []byte("username=admin&password=admin123")))
+	assert.Equal(t, "username=admin",
+		redactPayload("application/x-www-form-urlencoded", []byte("username=admin")))
+	assert.Equal(t, "sql=%zz", redactPayload("application/x-www-form-urlencoded", []byte("sql=%zz")))
+	assert.Equal(t, "create database", redactPayload("text/plain", []byte("create database")))
+	payload := redactPayload("text/plain", []byte(strings.Repeat("a", maxAuditPayloadSize+10)))
+	assert.Len(t, payload, maxAuditPayloadSize+3)
+}
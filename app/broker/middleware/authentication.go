@@ -56,7 +56,7 @@ func (u *userAuthentication) Validate(next http.Handler) http.Handler {
 		if len(token) > 0 {
 			claims := parseToken(token, u.user)
 			if claims.UserName == u.user.UserName && claims.Password == u.user.Password {
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), claims.UserName)))
 				return
 			}
 		}
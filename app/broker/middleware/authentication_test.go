@@ -50,6 +50,7 @@ func TestUserAuthentication_Validate(t *testing.T) {
 	auth := NewAuthentication(user)
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "admin", PrincipalFromContext(r.Context()))
 		w.WriteHeader(http.StatusOK)
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = io.WriteString(w, "ok")
@@ -0,0 +1,186 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	httppkg "github.com/lindb/lindb/pkg/http"
+)
+
+// Operation represents the operation type of request for authorization.
+type Operation int
+
+const (
+	// OperationRead represents query/state request.
+	OperationRead Operation = iota + 1
+	// OperationWrite represents data ingestion request.
+	OperationWrite
+	// OperationAdmin represents admin request, such as database/storage cluster management.
+	OperationAdmin
+)
+
+// String returns the string value of operation.
+func (op Operation) String() string {
+	switch op {
+	case OperationRead:
+		return "read"
+	case OperationWrite:
+		return "write"
+	case OperationAdmin:
+		return "admin"
+	default:
+		return "unknown"
+	}
+}
+
+// AuthorizationRequest represents the request which needs authorization decision.
+type AuthorizationRequest struct {
+	// Principal is the authenticated user, empty if request is anonymous.
+	Principal string
+	// Database is the target database, empty if request is cluster level.
+	Database string
+	// Operation is the operation type of request.
+	Operation Operation
+	// Path is the registered route path of request.
+	Path string
+}
+
+// AuthorizationDecision represents the decision of authorizer.
+type AuthorizationDecision struct {
+	Allow  bool
+	Reason string
+}
+
+// Allow returns the decision which allows the request.
+func Allow() AuthorizationDecision {
+	return AuthorizationDecision{Allow: true}
+}
+
+// Deny returns the decision which denies the request with reason.
+func Deny(reason string) AuthorizationDecision {
+	return AuthorizationDecision{Reason: reason}
+}
+
+// Authorizer represents the custom authorization plugin,
+// which can integrate with central policy engine.
+type Authorizer interface {
+	// Authorize returns allow/deny decision for the request.
+	Authorize(ctx context.Context, req *AuthorizationRequest) AuthorizationDecision
+}
+
+// AuthorizerFunc is an adapter to allow the use of ordinary function as Authorizer.
+type AuthorizerFunc func(ctx context.Context, req *AuthorizationRequest) AuthorizationDecision
+
+// Authorize calls f(ctx, req).
+func (f AuthorizerFunc) Authorize(ctx context.Context, req *AuthorizationRequest) AuthorizationDecision {
+	return f(ctx, req)
+}
+
+type namedAuthorizer struct {
+	name       string
+	authorizer Authorizer
+}
+
+var (
+	authorizersLock sync.RWMutex
+	authorizers     []namedAuthorizer
+)
+
+// RegisterAuthorizer registers the authorizer plugin, plugin package registers itself in init(),
+// and is compiled in by importing it in main package.
+// NOTICE: must register before broker http server starts
+func RegisterAuthorizer(name string, authorizer Authorizer) {
+	authorizersLock.Lock()
+	defer authorizersLock.Unlock()
+
+	for _, registered := range authorizers {
+		if registered.name == name {
+			panic(fmt.Sprintf("authorizer [%s] already register", name))
+		}
+	}
+	authorizers = append(authorizers, namedAuthorizer{name: name, authorizer: authorizer})
+}
+
+// unregisterAuthorizer removes the authorizer, only for testing.
+func unregisterAuthorizer(name string) {
+	authorizersLock.Lock()
+	defer authorizersLock.Unlock()
+
+	for idx, registered := range authorizers {
+		if registered.name == name {
+			authorizers = append(authorizers[:idx], authorizers[idx+1:]...)
+			return
+		}
+	}
+}
+
+// authorize asks all registered authorizers in registration order, the first denial wins.
+func authorize(ctx context.Context, req *AuthorizationRequest) (name string, decision AuthorizationDecision) {
+	authorizersLock.RLock()
+	defer authorizersLock.RUnlock()
+
+	for _, registered := range authorizers {
+		if decision = registered.authorizer.Authorize(ctx, req); !decision.Allow {
+			return registered.name, decision
+		}
+	}
+	return "", Allow()
+}
+
+// principalKey is the context key of authenticated principal.
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal, empty if request is anonymous.
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// Authorize returns the middleware which asks registered authorizers for every request
+// with the given operation, responses 403 with reason if the request is denied.
+// Target database is resolved from "db" parameter of request.
+func Authorize(operation Operation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		database := c.Query("db")
+		if database == "" {
+			database = c.PostForm("db")
+		}
+		req := &AuthorizationRequest{
+			Principal: PrincipalFromContext(c.Request.Context()),
+			Database:  database,
+			Operation: operation,
+			Path:      c.FullPath(),
+		}
+		if name, decision := authorize(c.Request.Context(), req); !decision.Allow {
+			httppkg.Forbidden(c, fmt.Errorf("%s denied by authorizer [%s]: %s", operation, name, decision.Reason))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/internal/mock"
+)
+
+func TestAuthorize(t *testing.T) {
+	var requests []AuthorizationRequest
+	RegisterAuthorizer("audit", AuthorizerFunc(func(ctx context.Context, req *AuthorizationRequest) AuthorizationDecision {
+		requests = append(requests, *req)
+		return Allow()
+	}))
+	RegisterAuthorizer("policy", AuthorizerFunc(func(ctx context.Context, req *AuthorizationRequest) AuthorizationDecision {
+		if req.Operation == OperationWrite && req.Database == "readonly" {
+			return Deny("database is read only")
+		}
+		return Allow()
+	}))
+	defer func() {
+		unregisterAuthorizer("audit")
+		unregisterAuthorizer("policy")
+		unregisterAuthorizer("not_exist")
+	}()
+	assert.Panics(t, func() {
+		RegisterAuthorizer("audit", AuthorizerFunc(func(ctx context.Context, req *AuthorizationRequest) AuthorizationDecision {
+			return Allow()
+		}))
+	})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), "admin"))
+	})
+	r.Group("", Authorize(OperationRead)).GET("/query", func(c *gin.Context) {
+		c.JSON(http.StatusOK, "ok")
+	})
+	r.Group("", Authorize(OperationWrite)).POST("/write", func(c *gin.Context) {
+		c.JSON(http.StatusOK, "ok")
+	})
+
+	resp := mock.DoRequest(t, r, http.MethodGet, "/query?db=readonly", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = mock.DoRequest(t, r, http.MethodPost, "/write?db=test", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = mock.DoRequest(t, r, http.MethodPost, "/write?db=readonly", "")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, `"write denied by authorizer [policy]: database is read only"`, resp.Body.String())
+
+	assert.Equal(t, []AuthorizationRequest{
+		{Principal: "admin", Database: "readonly", Operation: OperationRead, Path: "/query"},
+		{Principal: "admin", Database: "test", Operation: OperationWrite, Path: "/write"},
+		{Principal: "admin", Database: "readonly", Operation: OperationWrite, Path: "/write"},
+	}, requests)
+}
+
+func TestOperation_String(t *testing.T) {
+	assert.Equal(t, "read", OperationRead.String())
+	assert.Equal(t, "write", OperationWrite.String())
+	assert.Equal(t, "admin", OperationAdmin.String())
+	assert.Equal(t, "unknown", Operation(0).String())
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	assert.Empty(t, PrincipalFromContext(context.Background()))
+	assert.Equal(t, "admin", PrincipalFromContext(WithPrincipal(context.Background(), "admin")))
+}
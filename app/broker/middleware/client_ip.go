@@ -0,0 +1,125 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+// ClientIPKey is the key of resolved client ip in gin context.
+const ClientIPKey = "clientIP"
+
+// ClientIPMiddleware resolves the client ip of request for access log and rate limit, then attaches it
+// to gin context. If trusted proxies are given, X-Forwarded-For header is only trusted when request comes
+// from trusted proxy, client ip is the right-most address of X-Forwarded-For which is not trusted proxy,
+// otherwise X-Real-Ip/X-Forwarded-For header is always trusted.
+func ClientIPMiddleware(trustedProxies []string) gin.HandlerFunc {
+	trusted := parseTrustedProxies(trustedProxies)
+	return func(c *gin.Context) {
+		if len(trusted) == 0 {
+			c.Set(ClientIPKey, realIP(c.Request))
+		} else {
+			c.Set(ClientIPKey, resolveClientIP(c.Request, trusted))
+		}
+		c.Next()
+	}
+}
+
+// clientIP returns the resolved client ip of request, resolves by headers if not resolved by middleware.
+func clientIP(c *gin.Context) string {
+	if ip := c.GetString(ClientIPKey); ip != "" {
+		return ip
+	}
+	return realIP(c.Request)
+}
+
+// parseTrustedProxies parses the ips/cidrs of trusted proxies, invalid one is ignored.
+func parseTrustedProxies(trustedProxies []string) []*net.IPNet {
+	var trusted []*net.IPNet
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip = ip.To4()
+					bits = 8 * net.IPv4len
+				}
+				trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		} else if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+			trusted = append(trusted, ipNet)
+			continue
+		}
+		logger.AccessLog.Warn("ignore invalid trusted proxy", logger.String("proxy", proxy))
+	}
+	return trusted
+}
+
+// resolveClientIP returns the client ip, walks X-Forwarded-For from right to left if request comes from
+// trusted proxy, skipping trusted proxies, because addresses on the left can be forged by client.
+func resolveClientIP(r *http.Request, trusted []*net.IPNet) string {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+	if !isTrustedProxy(remoteIP, trusted) {
+		return remoteIP
+	}
+	addresses := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	leftMost := ""
+	for i := len(addresses) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(addresses[i])
+		if address == "" {
+			continue
+		}
+		leftMost = address
+		if !isTrustedProxy(address, trusted) {
+			return address
+		}
+	}
+	if leftMost != "" {
+		// all addresses are trusted proxies, left-most one is the closest to client
+		return leftMost
+	}
+	if xRealIP := r.Header.Get("X-Real-Ip"); xRealIP != "" {
+		return xRealIP
+	}
+	return remoteIP
+}
+
+// isTrustedProxy checks if the ip belongs to trusted proxies.
+func isTrustedProxy(address string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
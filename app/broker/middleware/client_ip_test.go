@@ -0,0 +1,72 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIPMiddleware(t *testing.T) {
+	examples := []struct {
+		trustedProxies []string
+		remoteAddr     string
+		headers        map[string]string
+		clientIP       string
+	}{
+		// trust headers if no trusted proxies
+		{nil, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "1.1.1.1, 10.0.0.2"}, "1.1.1.1"},
+		// untrusted remote
+		{[]string{"10.0.0.0/8"}, "2.2.2.2:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"}, "2.2.2.2"},
+		// skip trusted proxies from right
+		{[]string{"10.0.0.0/8", "192.168.1.1", "invalid"}, "10.0.0.1:1234",
+			map[string]string{"X-Forwarded-For": "3.3.3.3, 1.1.1.1, 192.168.1.1"}, "1.1.1.1"},
+		// all trusted proxies
+		{[]string{"10.0.0.0/8"}, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		// real ip header from trusted proxy
+		{[]string{"10.0.0.1"}, "10.0.0.1:1234", map[string]string{"X-Real-Ip": "1.1.1.1"}, "1.1.1.1"},
+		// no forwarded header
+		{[]string{"::1"}, "[::1]:1234", nil, "::1"},
+	}
+	for _, example := range examples {
+		r := gin.New()
+		r.Use(ClientIPMiddleware(example.trustedProxies))
+		var ip string
+		r.GET("/test", func(c *gin.Context) {
+			ip = clientIP(c)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = example.remoteAddr
+		for k, v := range example.headers {
+			req.Header.Set(k, v)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, example.clientIP, ip)
+	}
+}
+
+func TestClientIP_withoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/test", nil)
+	c.Request.RemoteAddr = "1.1.1.1:1234"
+	assert.Equal(t, "1.1.1.1", clientIP(c))
+}
@@ -0,0 +1,173 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/http"
+)
+
+var (
+	// ErrTooManyInflightRequests is the error returned when in-flight requests exceed the global cap.
+	ErrTooManyInflightRequests = errors.New("too many in-flight requests, please retry later")
+	// ErrRouteRateExceeded is the error returned when requests of route exceed the rate limit.
+	ErrRouteRateExceeded = errors.New("exceeds request rate limit of api, please retry later")
+	// ErrClientRateExceeded is the error returned when requests of client ip exceed the rate limit.
+	ErrClientRateExceeded = errors.New("exceeds request rate limit of client, please retry later")
+)
+
+// retryAfterHeader is the header which tells client how many seconds to wait before retrying.
+const retryAfterHeader = "Retry-After"
+
+// clientLimiterIdleTimeout is the duration after which the limiter of inactive client ip is removed.
+const clientLimiterIdleTimeout = 5 * time.Minute
+
+var (
+	httpRejectedVec = linmetric.
+		NewScope("lindb.broker.http").
+		NewDeltaCounterVec("rejected", "reason")
+)
+
+// for testing
+var (
+	nowFunc = time.Now
+)
+
+// clientLimiter represents the rate limiter of client ip with last seen time.
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter limits the api requests by global in-flight cap, route rate and client ip rate.
+type rateLimiter struct {
+	maxInflight   int64
+	inflight      atomic.Int64
+	routeLimiters map[string]*rate.Limiter // route path => limiter
+	clientRate    int
+
+	mutex         sync.Mutex
+	clients       map[string]*clientLimiter // client ip => limiter
+	lastEvictTime time.Time
+}
+
+// RateLimitMiddleware returns the middleware which protects the broker from request stampedes(like dashboards),
+// requests exceeding the limits are rejected with 429(Too Many Requests) and Retry-After header.
+func RateLimitMiddleware(cfg config.HTTP) gin.HandlerFunc {
+	l := &rateLimiter{
+		maxInflight:   int64(cfg.MaxInflightRequests),
+		routeLimiters: make(map[string]*rate.Limiter),
+		clientRate:    cfg.ClientIPRate,
+		clients:       make(map[string]*clientLimiter),
+		lastEvictTime: nowFunc(),
+	}
+	for _, routeRate := range cfg.RouteRates {
+		if routeRate.Rate > 0 {
+			l.routeLimiters[routeRate.Path] = rate.NewLimiter(rate.Limit(routeRate.Rate), routeRate.Rate)
+		}
+	}
+	return l.handle
+}
+
+// handle checks the limits before handling request.
+func (l *rateLimiter) handle(c *gin.Context) {
+	if l.maxInflight > 0 {
+		if l.inflight.Inc() > l.maxInflight {
+			l.inflight.Dec()
+			l.reject(c, "inflight", time.Second, ErrTooManyInflightRequests)
+			return
+		}
+		defer l.inflight.Dec()
+	}
+	if limiter, ok := l.routeLimiters[c.FullPath()]; ok {
+		if delay, ok := allow(limiter); !ok {
+			l.reject(c, "route", delay, ErrRouteRateExceeded)
+			return
+		}
+	}
+	if l.clientRate > 0 {
+		if delay, ok := allow(l.getClientLimiter(clientIP(c))); !ok {
+			l.reject(c, "client", delay, ErrClientRateExceeded)
+			return
+		}
+	}
+	c.Next()
+}
+
+// getClientLimiter returns the rate limiter of client ip, creates it if not exist,
+// evicts the limiters of inactive clients periodically.
+func (l *rateLimiter) getClientLimiter(ip string) *rate.Limiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := nowFunc()
+	if now.Sub(l.lastEvictTime) >= clientLimiterIdleTimeout {
+		for clientIP, client := range l.clients {
+			if now.Sub(client.lastSeen) >= clientLimiterIdleTimeout {
+				delete(l.clients, clientIP)
+			}
+		}
+		l.lastEvictTime = now
+	}
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(l.clientRate), l.clientRate)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+	return client.limiter
+}
+
+// reject responses 429 with Retry-After header in seconds.
+func (l *rateLimiter) reject(c *gin.Context, reason string, retryAfter time.Duration, err error) {
+	httpRejectedVec.WithTagValues(reason).Incr()
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header(retryAfterHeader, strconv.Itoa(seconds))
+	http.TooManyRequests(c, err)
+	c.Abort()
+}
+
+// allow checks if a request can happen now, returns the duration to wait if not allowed.
+func allow(limiter *rate.Limiter) (time.Duration, bool) {
+	now := nowFunc()
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return time.Second, false
+	}
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return 0, true
+	}
+	// don't wait, give back the token
+	reservation.CancelAt(now)
+	return delay, false
+}
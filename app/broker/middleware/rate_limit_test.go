@@ -0,0 +1,118 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+)
+
+func doRateLimitRequest(r *gin.Engine, path, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Real-Ip", ip)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestRateLimitMiddleware_inflight(t *testing.T) {
+	r := gin.New()
+	r.Use(RateLimitMiddleware(config.HTTP{MaxInflightRequests: 1}))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r.GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+	})
+	r.GET("/fast", func(c *gin.Context) {})
+
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/slow", "1.1.1.1").Code)
+	}()
+	<-started
+	resp := doRateLimitRequest(r, "/fast", "1.1.1.1")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get(retryAfterHeader))
+	close(release)
+	wait.Wait()
+	// in-flight request completed
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/fast", "1.1.1.1").Code)
+}
+
+func TestRateLimitMiddleware_route(t *testing.T) {
+	r := gin.New()
+	r.Use(RateLimitMiddleware(config.HTTP{RouteRates: []config.RouteRate{
+		{Path: "/limited/:id", Rate: 1},
+		{Path: "/unlimited", Rate: 0},
+	}}))
+	r.GET("/limited/:id", func(c *gin.Context) {})
+	r.GET("/unlimited", func(c *gin.Context) {})
+
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/limited/1", "1.1.1.1").Code)
+	// route is limited by path pattern
+	resp := doRateLimitRequest(r, "/limited/2", "1.1.1.2")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get(retryAfterHeader))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/unlimited", "1.1.1.1").Code)
+	}
+}
+
+func TestRateLimitMiddleware_client(t *testing.T) {
+	now := time.Now()
+	nowFunc = func() time.Time { return now }
+	defer func() {
+		nowFunc = time.Now
+	}()
+	r := gin.New()
+	limiter := &rateLimiter{
+		clientRate:    2,
+		clients:       make(map[string]*clientLimiter),
+		lastEvictTime: now,
+	}
+	r.Use(limiter.handle)
+	r.GET("/test", func(c *gin.Context) {})
+
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/test", "1.1.1.1").Code)
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/test", "1.1.1.1").Code)
+	resp := doRateLimitRequest(r, "/test", "1.1.1.1")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get(retryAfterHeader))
+	// other client is not limited
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/test", "1.1.1.2").Code)
+	// tokens refilled
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/test", "1.1.1.1").Code)
+	assert.Len(t, limiter.clients, 2)
+
+	// limiters of inactive clients are evicted
+	now = now.Add(clientLimiterIdleTimeout)
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(r, "/test", "1.1.1.3").Code)
+	assert.Len(t, limiter.clients, 1)
+}
@@ -0,0 +1,43 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/internal/tracing"
+)
+
+// RequestIDKey is the key of request id in gin context.
+const RequestIDKey = "requestID"
+
+// RequestIDMiddleware accepts the request id from client if valid, otherwise generates a new one,
+// then attaches it to gin context and request context(forwarded to storage nodes with task request),
+// and returns it with response header.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(tracing.RequestIDHeader)
+		if !tracing.IsValidRequestID(requestID) {
+			requestID = tracing.NewRequestID()
+		}
+		c.Set(RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(tracing.WithRequestID(c.Request.Context(), requestID))
+		c.Header(tracing.RequestIDHeader, requestID)
+		c.Next()
+	}
+}
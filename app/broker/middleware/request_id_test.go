@@ -0,0 +1,63 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/internal/tracing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	var requestID, ctxRequestID string
+	r.GET("/test", func(c *gin.Context) {
+		requestID = c.GetString(RequestIDKey)
+		ctxRequestID = tracing.RequestIDFromContext(c.Request.Context())
+	})
+
+	// generate request id
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/test", nil))
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, ctxRequestID)
+	assert.Equal(t, requestID, resp.Header().Get(tracing.RequestIDHeader))
+
+	// accept request id from client
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(tracing.RequestIDHeader, "req-1")
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	assert.Equal(t, "req-1", requestID)
+	assert.Equal(t, "req-1", ctxRequestID)
+	assert.Equal(t, "req-1", resp.Header().Get(tracing.RequestIDHeader))
+
+	// invalid request id from client
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(tracing.RequestIDHeader, "req 1")
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	assert.NotEqual(t, "req 1", requestID)
+	assert.Len(t, requestID, 32)
+}
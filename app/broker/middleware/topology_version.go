@@ -0,0 +1,96 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TopologyVersionHeader is the header of topology version which the response computed on,
+	// client can send the last-seen topology version with the same header.
+	TopologyVersionHeader = "X-LinDB-Topology-Version"
+	// TopologyStaleHeader is the header flags the response is computed on a stale topology.
+	TopologyStaleHeader = "X-LinDB-Topology-Stale"
+)
+
+// TopologyVersionMiddleware returns the topology version header on every response,
+// and flags the response as stale if topology changed during handling request
+// or client has seen a newer topology than current broker.
+func TopologyVersionMiddleware(topologyVersion func() int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &topologyVersionWriter{
+			ResponseWriter:  c.Writer,
+			topologyVersion: topologyVersion,
+			startVersion:    topologyVersion(),
+		}
+		if lastSeen := c.GetHeader(TopologyVersionHeader); lastSeen != "" {
+			if version, err := strconv.ParseInt(lastSeen, 10, 64); err == nil {
+				writer.lastSeenVersion = version
+			}
+		}
+		c.Writer = writer
+		c.Next()
+		// response without body, header not written yet
+		writer.writeTopologyHeader()
+	}
+}
+
+// topologyVersionWriter writes topology header before response body written.
+type topologyVersionWriter struct {
+	gin.ResponseWriter
+
+	topologyVersion func() int64
+	startVersion    int64
+	lastSeenVersion int64
+	headerWritten   bool
+}
+
+// writeTopologyHeader writes topology version/stale header if not written.
+func (w *topologyVersionWriter) writeTopologyHeader() {
+	if w.headerWritten || w.ResponseWriter.Written() {
+		return
+	}
+	w.headerWritten = true
+	version := w.topologyVersion()
+	header := w.ResponseWriter.Header()
+	header.Set(TopologyVersionHeader, strconv.FormatInt(version, 10))
+	if version != w.startVersion || w.lastSeenVersion > version {
+		header.Set(TopologyStaleHeader, "true")
+	}
+}
+
+// WriteHeaderNow writes topology header, then writes the response header.
+func (w *topologyVersionWriter) WriteHeaderNow() {
+	w.writeTopologyHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Write writes topology header, then writes the response body.
+func (w *topologyVersionWriter) Write(data []byte) (int, error) {
+	w.writeTopologyHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes topology header, then writes the response body.
+func (w *topologyVersionWriter) WriteString(s string) (int, error) {
+	w.writeTopologyHeader()
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,79 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/internal/mock"
+)
+
+func TestTopologyVersionMiddleware(t *testing.T) {
+	version := atomic.NewInt64(10)
+	r := gin.New()
+	r.Use(TopologyVersionMiddleware(version.Load))
+	r.GET("/json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, "ok")
+	})
+	r.GET("/string", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/status", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/failover", func(c *gin.Context) {
+		// topology changed during handling request
+		version.Inc()
+		c.JSON(http.StatusOK, "ok")
+	})
+
+	for _, path := range []string{"/json", "/string", "/status"} {
+		resp := mock.DoRequest(t, r, http.MethodGet, path, "")
+		assert.Equal(t, "10", resp.Header().Get(TopologyVersionHeader))
+		assert.Empty(t, resp.Header().Get(TopologyStaleHeader))
+	}
+
+	resp := mock.DoRequest(t, r, http.MethodGet, "/failover", "")
+	assert.Equal(t, "11", resp.Header().Get(TopologyVersionHeader))
+	assert.Equal(t, "true", resp.Header().Get(TopologyStaleHeader))
+
+	doRequest := func(lastSeen string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/json", nil)
+		req.Header.Set(TopologyVersionHeader, lastSeen)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+	// client has seen newer topology
+	resp = doRequest("12")
+	assert.Equal(t, "11", resp.Header().Get(TopologyVersionHeader))
+	assert.Equal(t, "true", resp.Header().Get(TopologyStaleHeader))
+	// client has seen same topology
+	resp = doRequest("11")
+	assert.Empty(t, resp.Header().Get(TopologyStaleHeader))
+	// bad version
+	resp = doRequest("abc")
+	assert.Equal(t, "11", resp.Header().Get(TopologyVersionHeader))
+	assert.Empty(t, resp.Header().Get(TopologyStaleHeader))
+}
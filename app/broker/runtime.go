@@ -21,10 +21,21 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"sync"
+	"time"
 
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/app/broker/alerting"
 	"github.com/lindb/lindb/app/broker/api"
+	"github.com/lindb/lindb/app/broker/audit"
 	"github.com/lindb/lindb/app/broker/deps"
+	"github.com/lindb/lindb/app/broker/descriptor"
+	"github.com/lindb/lindb/app/broker/federation"
+	"github.com/lindb/lindb/app/broker/handler"
+	"github.com/lindb/lindb/app/broker/middleware"
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/coordinator"
@@ -33,6 +44,8 @@ import (
 	"github.com/lindb/lindb/coordinator/task"
 	"github.com/lindb/lindb/internal/concurrent"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/internal/tenant"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/monitoring"
 	"github.com/lindb/lindb/pkg/hostutil"
@@ -40,7 +53,9 @@ import (
 	"github.com/lindb/lindb/pkg/server"
 	"github.com/lindb/lindb/pkg/state"
 	"github.com/lindb/lindb/pkg/timeutil"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	protoQueryV1 "github.com/lindb/lindb/proto/gen/v1/query"
 	"github.com/lindb/lindb/query"
 	brokerQuery "github.com/lindb/lindb/query/broker"
 	"github.com/lindb/lindb/replication"
@@ -52,11 +67,23 @@ import (
 var getHostIP = hostutil.GetHostIP
 var hostName = os.Hostname
 
+var (
+	// backoff of retrying start non-critical components
+	componentMinBackoff = time.Second
+	componentMaxBackoff = time.Minute
+	// max waiting time of replicating buffered data to storage when draining
+	drainTimeout = time.Minute
+)
+
 // srv represents all services for broker
 type srv struct {
 	replicatorStateReport replication.ReplicatorStateReport
 	channelManager        replication.ChannelManager
 	taskManager           brokerQuery.TaskManager
+	deadLetter            replication.DeadLetter
+	subscriptions         replication.Subscriptions
+	mirrors               replication.Mirrors
+	tenants               tenant.Tenants
 }
 
 // factory represents all factories for broker
@@ -92,7 +119,15 @@ type runtime struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	pusher monitoring.NativePusher
+	pusher        monitoring.NativePusher
+	traceExporter *tracing.OTLPExporter
+	// non-critical components, failure of them doesn't prevent serving writes/queries
+	components server.ComponentManager
+	// rejects new writes/queries if draining
+	draining  atomic.Bool
+	drainLock sync.Mutex
+	// serializes config reloading
+	reloadLock sync.Mutex
 
 	log *logger.Logger
 }
@@ -107,10 +142,10 @@ func NewBrokerRuntime(version string, config *config.Broker) server.Service {
 		repoFactory: state.NewRepositoryFactory("broker"),
 		ctx:         ctx,
 		cancel:      cancel,
-		queryPool: concurrent.NewPool(
+		components:  server.NewComponentManager(ctx, componentMinBackoff, componentMaxBackoff),
+		queryPool: query.NewTaskPool(
 			"task-pool",
-			config.BrokerBase.Query.QueryConcurrency,
-			config.BrokerBase.Query.IdleTimeout.Duration(),
+			config.BrokerBase.Query,
 			linmetric.NewScope("lindb.concurrent", "pool_name", "broker-query"),
 		),
 		log: logger.GetLogger("broker", "Runtime"),
@@ -124,6 +159,10 @@ func (r *runtime) Name() string {
 
 // Run runs broker server based on config file
 func (r *runtime) Run() error {
+	if err := rpc.ValidateCompression(r.config.BrokerBase.GRPC.Compression); err != nil {
+		r.state = server.Failed
+		return err
+	}
 	ip, err := getHostIP()
 	if err != nil {
 		r.state = server.Failed
@@ -140,6 +179,8 @@ func (r *runtime) Run() error {
 		Port:     r.config.BrokerBase.GRPC.Port,
 		HostName: hostName,
 		HTTPPort: r.config.BrokerBase.HTTP.Port,
+		Zone:     r.config.BrokerBase.Location.Zone,
+		Rack:     r.config.BrokerBase.Location.Rack,
 	}
 
 	// start state repository
@@ -150,7 +191,7 @@ func (r *runtime) Run() error {
 	}
 
 	r.factory = factory{
-		taskClient: rpc.NewTaskClientFactory(r.node),
+		taskClient: rpc.NewTaskClientFactory(r.node, r.config.BrokerBase.GRPC.Compression),
 		taskServer: rpc.NewTaskServerFactory(),
 	}
 
@@ -176,7 +217,7 @@ func (r *runtime) Run() error {
 		Ctx:               r.ctx,
 		Repo:              r.repo,
 		Node:              r.node,
-		TTL:               1, //TODO need config
+		TTL:               r.config.BrokerBase.Coordinator.LeaseTTLSeconds(),
 		DiscoveryFactory:  discoveryFactory,
 		ControllerFactory: task.NewControllerFactory(),
 		ClusterFactory:    storage.NewClusterFactory(),
@@ -189,20 +230,31 @@ func (r *runtime) Run() error {
 	r.startGRPCServer()
 
 	// register broker node info
-	//TODO TTL default value???
-	r.registry = discovery.NewRegistry(r.repo, constants.ActiveNodesPath, 1)
+	r.registry = discovery.NewRegistry(r.repo, constants.ActiveNodesPath,
+		time.Duration(r.config.BrokerBase.Coordinator.LeaseTTLSeconds())*time.Second)
 	if err := r.registry.Register(r.node); err != nil {
 		return fmt.Errorf("register storagequery node error:%s", err)
 	}
-	r.master.Start()
+	// start master campaign, node can serve writes/queries without master
+	r.components.StartComponent("master", r.startMaster)
 
 	// start http server
 	r.startHTTPServer()
 
 	// start system collector
-	r.systemCollector()
+	r.components.StartComponent("system-collector", r.systemCollector)
 	// start stat monitoring
-	r.nativePusher()
+	r.components.StartComponent("native-pusher", r.nativePusher)
+	// start query tracing
+	r.components.StartComponent("trace-exporter", r.startTracing)
+	// start end-to-end health probe
+	r.components.StartComponent("health-probe", r.healthProbe)
+	// start graphite plaintext protocol listener
+	r.components.StartComponent("graphite-listener", r.graphiteListener)
+	// start statsd listener
+	r.components.StartComponent("statsd-listener", r.statsdListener)
+	// start alert rule evaluator
+	r.components.StartComponent("alerting", r.alerting)
 
 	r.state = server.Running
 	return nil
@@ -213,8 +265,107 @@ func (r *runtime) State() server.State {
 	return r.state
 }
 
-// Stop stops broker server,
+// Drain stops accepting new writes/queries, waits until buffered data of replication channels replicated to storage,
+// then deregisters broker node from discovery.
+func (r *runtime) Drain() error {
+	r.drainLock.Lock()
+	defer r.drainLock.Unlock()
+
+	if r.draining.Load() {
+		// already drained
+		return nil
+	}
+	r.log.Info("draining broker server...")
+	r.draining.Store(true)
+	r.state = server.Draining
+
+	var err error
+	if r.srv.channelManager != nil {
+		ctx, cancel := context.WithTimeout(r.ctx, drainTimeout)
+		err = r.srv.channelManager.Drain(ctx)
+		cancel()
+		if err != nil {
+			r.log.Error("flush replication channels error when draining", logger.Error(err))
+		} else {
+			r.log.Info("flushed replication channels successfully")
+		}
+	}
+	if r.registry != nil {
+		if err0 := r.registry.Deregister(r.node); err0 != nil {
+			r.log.Error("deregister broker node error when draining", logger.Error(err0))
+			err = err0
+		} else {
+			r.log.Info("deregistered broker node successfully")
+		}
+	}
+	r.state = server.Drained
+	r.log.Info("drained broker server")
+	return err
+}
+
+// Draining returns if broker is draining or drained.
+func (r *runtime) Draining() bool {
+	return r.draining.Load()
+}
+
+// Reload applies the dynamic fields(query concurrency, ingestion limits, log levels, monitor interval)
+// of new broker config without restart, changes of immutable fields are rejected.
+func (r *runtime) Reload(newCfg interface{}) (*config.ReloadReport, error) {
+	cfg, ok := newCfg.(*config.Broker)
+	if !ok {
+		return nil, fmt.Errorf("unexpected broker config type: %T", newCfg)
+	}
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	report := config.NewReloadReport(config.DiffFields(r.config, cfg), config.BrokerDynamicFields)
+	if err := report.Validate(cfg); err != nil {
+		// keep the old config if any value is invalid
+		return nil, err
+	}
+	if report.IsApplied("logging.level") || report.IsApplied("logging.module-levels") {
+		if err := logger.UpdateLevels(cfg.Logging); err != nil {
+			return nil, err
+		}
+		r.config.Logging.Level = cfg.Logging.Level
+		r.config.Logging.ModuleLevels = cfg.Logging.ModuleLevels
+	}
+	if report.IsApplied("broker.query.query-concurrency") {
+		r.queryPool.SetMaxWorkers(cfg.BrokerBase.Query.QueryConcurrency)
+		r.config.BrokerBase.Query.QueryConcurrency = cfg.BrokerBase.Query.QueryConcurrency
+	}
+	if report.IsApplied("broker.ingestion.max-timestamp-behind") || report.IsApplied("broker.ingestion.max-timestamp-ahead") {
+		r.config.BrokerBase.Ingestion.MaxTimestampBehind = cfg.BrokerBase.Ingestion.MaxTimestampBehind
+		r.config.BrokerBase.Ingestion.MaxTimestampAhead = cfg.BrokerBase.Ingestion.MaxTimestampAhead
+		if r.srv.channelManager != nil {
+			r.srv.channelManager.UpdateIngestion(r.config.BrokerBase.Ingestion)
+		}
+	}
+	if report.IsApplied("broker.tenant.quotas") {
+		if r.srv.tenants == nil {
+			report.Reject("broker.tenant.quotas")
+		} else {
+			r.srv.tenants.UpdateQuotas(cfg.BrokerBase.Tenant.Quotas)
+			r.config.BrokerBase.Tenant.Quotas = cfg.BrokerBase.Tenant.Quotas
+		}
+	}
+	if report.IsApplied("monitor.report-interval") {
+		if r.pusher == nil || cfg.Monitor.ReportInterval <= 0 {
+			// pusher is started/stopped only when server starts
+			report.Reject("monitor.report-interval")
+		} else {
+			r.pusher.SetInterval(cfg.Monitor.ReportInterval.Duration())
+			r.config.Monitor.ReportInterval = cfg.Monitor.ReportInterval
+		}
+	}
+	return report, nil
+}
+
+// Stop stops broker server, drains broker first if not drained.
 func (r *runtime) Stop() {
+	if err := r.Drain(); err != nil {
+		r.log.Error("drain broker server error, continue stopping", logger.Error(err))
+	}
 	r.log.Info("stopping broker server...")
 	defer r.cancel()
 
@@ -222,6 +373,11 @@ func (r *runtime) Stop() {
 		r.pusher.Stop()
 		r.log.Info("stopped native linmetric pusher successfully")
 	}
+	if r.traceExporter != nil {
+		tracing.Setup(nil, 0)
+		r.traceExporter.Stop()
+		r.log.Info("stopped trace exporter successfully")
+	}
 
 	if r.httpServer != nil {
 		r.log.Info("stopping http server...")
@@ -261,6 +417,17 @@ func (r *runtime) Stop() {
 		}
 	}
 
+	if r.srv.deadLetter != nil {
+		if err := r.srv.deadLetter.Close(); err != nil {
+			r.log.Error("close dead letter error", logger.Error(err))
+		}
+	}
+
+	if r.srv.subscriptions != nil {
+		// close subscription streams, then rpc server can be stopped
+		r.srv.subscriptions.Close()
+	}
+
 	// finally shutdown rpc server
 	if r.grpcServer != nil {
 		r.log.Info("stopping grpc server...")
@@ -268,6 +435,11 @@ func (r *runtime) Stop() {
 		r.log.Info("stopped grpc server successfully")
 	}
 
+	if r.srv.mirrors != nil {
+		// no more writes after rpc server stopped, data not forwarded is kept in queue
+		r.srv.mirrors.Close()
+	}
+
 	r.log.Info("stopped broker server successfully")
 	r.state = server.Terminated
 }
@@ -276,7 +448,6 @@ func (r *runtime) Stop() {
 func (r *runtime) startHTTPServer() {
 	r.log.Info("starting HTTP server")
 	r.httpServer = NewHTTPServer(r.config.BrokerBase.HTTP)
-	// TODO login api is not registered
 	httpAPI := api.NewAPI(&deps.HTTPDeps{
 		Ctx:           r.ctx,
 		BrokerCfg:     &r.config.BrokerBase,
@@ -284,14 +455,23 @@ func (r *runtime) startHTTPServer() {
 		Repo:          r.repo,
 		StateMachines: r.stateMachines,
 		CM:            r.srv.channelManager,
-		QueryFactory: brokerQuery.NewQueryFactory(
-			r.stateMachines.ReplicaStatusSM,
-			r.stateMachines.NodeSM,
-			r.stateMachines.DatabaseSM,
-			r.srv.taskManager,
-		),
+		DeadLetter:    r.srv.deadLetter,
+		Components:    r.components,
+		Drainer:       r,
+		QueryFactory:  r.newQueryFactory(),
+		Federation:    r.newFederation(),
+		AuditLog:      audit.NewLog(r.repo),
+
+		MetricDescriptors: descriptor.NewRegistry(r.ctx, r.stateMachines),
 	})
-	httpAPI.RegisterRouter(r.httpServer.GetAPIRouter())
+	apiRouter := r.httpServer.GetAPIRouter()
+	// reject requests exceeding in-flight cap/rate limits before handling, protects broker from request stampedes
+	apiRouter.Use(middleware.RateLimitMiddleware(r.config.BrokerBase.HTTP))
+	// return topology version on every api response, let client retry when topology changing
+	apiRouter.Use(middleware.TopologyVersionMiddleware(r.stateMachines.TopologyVersion))
+	httpAPI.RegisterRouter(apiRouter)
+	// expose metrics for prometheus scraping
+	r.httpServer.RegisterMetricsHandler(r.globalKeyValues())
 	go func() {
 		if err := r.httpServer.Run(); err != http.ErrServerClosed {
 			panic(fmt.Sprintf("start http server with error: %s", err))
@@ -316,11 +496,20 @@ func (r *runtime) buildServiceDependency() {
 	// todo watch stateMachine states change.
 
 	replicatorStateReport := replication.NewReplicatorStateReport(r.node, r.repo)
+	deadLetter := replication.NewDeadLetter(r.config.BrokerBase.DeadLetter)
+	subscriptions := replication.NewSubscriptions(r.config.BrokerBase.Subscription)
+	mirrors := r.newMirrors()
+	tenants := r.newTenants()
 
 	// hard code create channel first.
 	cm := replication.NewChannelManager(
 		r.config.BrokerBase.ReplicationChannel,
-		rpc.NewClientStreamFactory(r.node),
+		r.config.BrokerBase.Ingestion,
+		deadLetter,
+		subscriptions,
+		mirrors,
+		tenants,
+		rpc.NewClientStreamFactory(r.node, r.config.BrokerBase.GRPC.Compression),
 		replicatorStateReport)
 	taskManager := brokerQuery.NewTaskManager(
 		r.ctx,
@@ -338,6 +527,10 @@ func (r *runtime) buildServiceDependency() {
 		replicatorStateReport: replicatorStateReport,
 		channelManager:        cm,
 		taskManager:           taskManager,
+		deadLetter:            deadLetter,
+		subscriptions:         subscriptions,
+		mirrors:               mirrors,
+		tenants:               tenants,
 	}
 	r.srv = srv
 }
@@ -375,13 +568,85 @@ func (r *runtime) bindGRPCHandlers() {
 	}
 
 	protoCommonV1.RegisterTaskServiceServer(r.grpcServer.GetServer(), r.rpcHandler.handler)
+	protoBrokerV1.RegisterSubscriptionServiceServer(r.grpcServer.GetServer(),
+		handler.NewSubscriptionHandler(r.srv.subscriptions))
+	protoBrokerV1.RegisterQueryServiceServer(r.grpcServer.GetServer(),
+		handler.NewQueryHandler(r.newQueryFactory(), r.config.BrokerBase.Query.Timeout.Duration()))
+	protoQueryV1.RegisterQueryServiceServer(r.grpcServer.GetServer(),
+		handler.NewClientQueryHandler(r.newQueryFactory(), r.config.BrokerBase.Query.Timeout.Duration()))
+	protoBrokerV1.RegisterBrokerServiceServer(r.grpcServer.GetServer(),
+		handler.NewWriterHandler(r.srv.channelManager))
+}
+
+// newQueryFactory creates the metric query factory based on state machines.
+func (r *runtime) newQueryFactory() brokerQuery.Factory {
+	return brokerQuery.NewQueryFactory(
+		r.stateMachines.ReplicaStatusSM,
+		r.stateMachines.NodeSM,
+		r.stateMachines.DatabaseSM,
+		r.stateMachines.QueryDefaultsSM,
+		r.srv.taskManager,
+		r.srv.tenants,
+	)
+}
+
+// newFederation creates the federation query if remote clusters configured, returns nil if disabled or bad config.
+func (r *runtime) newFederation() federation.Federation {
+	cfg := r.config.BrokerBase.Federation
+	if !cfg.Enabled() {
+		return nil
+	}
+	fed, err := federation.NewFederation(cfg, r.newQueryFactory(),
+		rpc.NewClientStreamFactory(r.node, r.config.BrokerBase.GRPC.Compression))
+	if err != nil {
+		r.log.Error("federation query is disabled because of bad config", logger.Error(err))
+		return nil
+	}
+	r.log.Info("federation query is enabled",
+		logger.String("cluster", cfg.Cluster), logger.Any("remoteClusters", cfg.RemoteClusters))
+	return fed
 }
 
-func (r *runtime) nativePusher() {
+// newMirrors creates the mirrors if mirror databases configured, returns nil if disabled or bad config.
+func (r *runtime) newMirrors() replication.Mirrors {
+	cfg := r.config.BrokerBase.Mirror
+	if !cfg.Enabled() {
+		return nil
+	}
+	mirrors, err := replication.NewMirrors(cfg,
+		rpc.NewClientStreamFactory(r.node, r.config.BrokerBase.GRPC.Compression))
+	if err != nil {
+		r.log.Error("mirroring is disabled because of bad config", logger.Error(err))
+		return nil
+	}
+	r.log.Info("mirroring is enabled", logger.Any("databases", cfg.Databases))
+	return mirrors
+}
+
+// newTenants creates the quotas/isolation of namespaces, quotas are ignored if bad config.
+func (r *runtime) newTenants() tenant.Tenants {
+	cfg := r.config.BrokerBase.Tenant
+	if err := cfg.Validate(); err != nil {
+		r.log.Error("quotas of namespaces are ignored because of bad config", logger.Error(err))
+		cfg.Quotas = nil
+	}
+	return tenant.NewTenants(cfg)
+}
+
+// startMaster starts master campaign
+func (r *runtime) startMaster() error {
+	r.master.Start()
+	return nil
+}
+
+func (r *runtime) nativePusher() error {
 	monitorEnabled := r.config.Monitor.ReportInterval > 0
 	if !monitorEnabled {
 		r.log.Info("pusher won't start because report-interval is 0")
-		return
+		return nil
+	}
+	if _, err := url.ParseRequestURI(r.config.Monitor.URL); err != nil {
+		return fmt.Errorf("invalid monitor url: %s, error: %w", r.config.Monitor.URL, err)
 	}
 	r.log.Info("pusher is running",
 		logger.String("interval", r.config.Monitor.ReportInterval.String()))
@@ -391,15 +656,107 @@ func (r *runtime) nativePusher() {
 		r.config.Monitor.URL,
 		r.config.Monitor.ReportInterval.Duration(),
 		r.config.Monitor.PushTimeout.Duration(),
-		tag.KeyValues{
-			{Key: "node", Value: r.node.Indicator()},
-			{Key: "role", Value: "broker"},
-		},
+		r.globalKeyValues(),
 	)
 	go r.pusher.Start()
+	return nil
+}
+
+func (r *runtime) startTracing() error {
+	cfg := r.config.Monitor
+	if cfg.TraceURL == "" {
+		r.log.Info("trace exporter won't start because trace-url is empty")
+		return nil
+	}
+	if _, err := url.ParseRequestURI(cfg.TraceURL); err != nil {
+		return fmt.Errorf("invalid trace url: %s, error: %w", cfg.TraceURL, err)
+	}
+	r.log.Info("trace exporter is running",
+		logger.String("url", cfg.TraceURL), logger.Any("ratio", cfg.TraceRatio))
+
+	r.traceExporter = tracing.NewOTLPExporter(
+		r.ctx,
+		cfg.TraceURL,
+		cfg.PushTimeout.Duration(),
+		r.globalKeyValues().Map(),
+	)
+	tracing.Setup(r.traceExporter, cfg.TraceRatio)
+	go r.traceExporter.Start()
+	return nil
+}
+
+// globalKeyValues returns the tags added to all self-monitoring metrics.
+func (r *runtime) globalKeyValues() tag.KeyValues {
+	return tag.KeyValues{
+		{Key: "node", Value: r.node.Indicator()},
+		{Key: "role", Value: "broker"},
+	}
+}
+
+func (r *runtime) healthProbe() error {
+	cfg := r.config.BrokerBase.HealthProbe
+	if !cfg.Enabled() {
+		r.log.Info("health probe won't start because interval is 0 or databases is empty")
+		return nil
+	}
+	r.log.Info("health probe is running",
+		logger.String("interval", cfg.Interval.String()),
+		logger.Any("databases", cfg.Databases))
+	go newHealthProbe(
+		r.ctx,
+		r.node,
+		cfg,
+		r.srv.channelManager,
+		brokerQuery.NewQueryFactory(
+			r.stateMachines.ReplicaStatusSM,
+			r.stateMachines.NodeSM,
+			r.stateMachines.DatabaseSM,
+			r.stateMachines.QueryDefaultsSM,
+			r.srv.taskManager,
+			nil, // probe queries are not limited by quota of namespace
+		),
+	).Run()
+	return nil
 }
 
-func (r *runtime) systemCollector() {
+func (r *runtime) graphiteListener() error {
+	cfg := r.config.BrokerBase.Graphite
+	if !cfg.Enabled() {
+		r.log.Info("graphite listener won't start because port is 0 or database is empty")
+		return nil
+	}
+	listener, err := newGraphiteListener(r.ctx, cfg, r.srv.channelManager)
+	if err != nil {
+		return err
+	}
+	if err := listener.listen(); err != nil {
+		return err
+	}
+	r.log.Info("graphite listener is running",
+		logger.Uint16("port", cfg.Port),
+		logger.String("database", cfg.Database))
+	go listener.run()
+	return nil
+}
+
+func (r *runtime) statsdListener() error {
+	cfg := r.config.BrokerBase.StatsD
+	if !cfg.Enabled() {
+		r.log.Info("statsd listener won't start because port is 0 or database is empty")
+		return nil
+	}
+	listener := newStatsDListener(r.ctx, cfg, r.srv.channelManager)
+	if err := listener.listen(); err != nil {
+		return err
+	}
+	r.log.Info("statsd listener is running",
+		logger.Uint16("port", cfg.Port),
+		logger.String("database", cfg.Database))
+	go listener.run()
+	return nil
+}
+
+func (r *runtime) systemCollector() error {
 	r.log.Info("system collector is running")
 
 	go monitoring.NewSystemCollector(
@@ -412,4 +769,25 @@ func (r *runtime) systemCollector() {
 			Node:       r.node,
 			OnlineTime: timeutil.Now(),
 		}, "broker").Run()
+	return nil
+}
+
+func (r *runtime) alerting() error {
+	cfg := r.config.BrokerBase.Alerting
+	if !cfg.Enabled() {
+		r.log.Info("alerting won't start because interval is 0")
+		return nil
+	}
+	r.log.Info("alerting is running",
+		logger.String("interval", cfg.Interval.String()),
+		logger.Any("webhooks", cfg.WebhookURLs),
+		logger.String("alertmanager", cfg.AlertmanagerURL))
+	go alerting.NewEvaluator(
+		r.ctx,
+		cfg,
+		r.repo,
+		r.master,
+		r.newQueryFactory(),
+	).Run()
+	return nil
 }
@@ -30,10 +30,13 @@ import (
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/coordinator/discovery"
 	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/internal/tenant"
+	"github.com/lindb/lindb/monitoring"
 	"github.com/lindb/lindb/pkg/hostutil"
 	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/pkg/server"
 	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/replication"
 )
 
 type testBrokerRuntimeSuite struct {
@@ -86,11 +89,24 @@ func (ts *testBrokerRuntimeSuite) TestBrokerRun(c *check.C) {
 
 	c.Assert(server.Running, check.Equals, broker.State())
 	c.Assert("broker", check.Equals, broker.Name())
+	// monitor url not set, native pusher cannot start, but broker still running
+	health := broker.(*runtime).components.Health()
+	c.Assert(health.Degraded, check.Equals, true)
+	c.Assert(len(health.Components), check.Equals, 8)
 
 	broker.Stop()
 	c.Assert(server.Terminated, check.Equals, broker.State())
 }
 
+func (ts *testBrokerRuntimeSuite) TestBrokerRun_Compression_Err(c *check.C) {
+	cfg1 := cfg
+	cfg1.BrokerBase.GRPC.Compression = "lz4"
+	broker := NewBrokerRuntime("test-version", &cfg1)
+	err := broker.Run()
+	c.Assert(err, check.NotNil)
+	c.Assert(server.Failed, check.Equals, broker.State())
+}
+
 func (ts *testBrokerRuntimeSuite) TestBrokerRun_GetHost_Err(c *check.C) {
 	defer func() {
 		getHostIP = hostutil.GetHostIP
@@ -146,3 +162,99 @@ func (ts *testBrokerRuntimeSuite) TestBroker_Run_Err(c *check.C) {
 	registry.EXPECT().Close().Return(fmt.Errorf("err"))
 	broker.Stop()
 }
+
+func TestBrokerRuntime_Drain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	broker := NewBrokerRuntime("test-version", &cfg)
+	b := broker.(*runtime)
+	cm := replication.NewMockChannelManager(ctrl)
+	registry := discovery.NewMockRegistry(ctrl)
+	b.srv.channelManager = cm
+	b.registry = registry
+	// case 1: drain failure
+	cm.EXPECT().Drain(gomock.Any()).Return(fmt.Errorf("err"))
+	registry.EXPECT().Deregister(gomock.Any()).Return(nil)
+	assert.Error(t, broker.Drain())
+	assert.True(t, broker.Draining())
+	assert.Equal(t, server.Drained, broker.State())
+	// case 2: already drained
+	assert.NoError(t, broker.Drain())
+}
+
+func TestBrokerRuntime_Reload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	brokerCfg := cfg
+	broker := NewBrokerRuntime("test-version", &brokerCfg)
+	b := broker.(*runtime)
+	cm := replication.NewMockChannelManager(ctrl)
+	b.srv.channelManager = cm
+
+	// case 1: unexpected config type
+	report, err := broker.Reload(&config.Storage{})
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 2: no changes
+	newCfg := brokerCfg
+	report, err = broker.Reload(&newCfg)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Applied)
+	assert.Empty(t, report.Rejected)
+	// case 3: invalid log level
+	newCfg.Logging.Level = "unknown"
+	report, err = broker.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 4: apply dynamic fields, reject immutable fields
+	newCfg.Logging.Level = "info"
+	newCfg.BrokerBase.Query.QueryConcurrency = 10
+	newCfg.BrokerBase.Ingestion.MaxTimestampBehind = ltoml.Duration(time.Hour)
+	newCfg.BrokerBase.HTTP.Port = 9998
+	newCfg.Monitor.ReportInterval = ltoml.Duration(time.Minute)
+	cm.EXPECT().UpdateIngestion(newCfg.BrokerBase.Ingestion)
+	report, err = broker.Reload(&newCfg)
+	assert.NoError(t, err)
+	assert.Len(t, report.Applied, 3)
+	// pusher not running, monitor interval cannot be applied
+	assert.Len(t, report.Rejected, 2)
+	assert.Equal(t, 10, brokerCfg.BrokerBase.Query.QueryConcurrency)
+	assert.Equal(t, ltoml.Duration(time.Hour), brokerCfg.BrokerBase.Ingestion.MaxTimestampBehind)
+	assert.Equal(t, uint16(9999), brokerCfg.BrokerBase.HTTP.Port)
+	assert.Equal(t, ltoml.Duration(10*time.Second), brokerCfg.Monitor.ReportInterval)
+	// case 5: apply monitor interval
+	b.pusher = monitoring.NewNativeProtoPusher(b.ctx, "http://localhost:9000", time.Second, time.Second, nil)
+	report, err = broker.Reload(&newCfg)
+	assert.NoError(t, err)
+	assert.Len(t, report.Applied, 1)
+	assert.Equal(t, ltoml.Duration(time.Minute), brokerCfg.Monitor.ReportInterval)
+	// case 6: bad quotas of namespaces
+	tenants := tenant.NewMockTenants(ctrl)
+	b.srv.tenants = tenants
+	newCfg.BrokerBase.Tenant.Quotas = []config.NamespaceQuota{{Namespace: "ns", WriteRate: -1}}
+	newCfg.BrokerBase.Query.QueryConcurrency = 20
+	report, err = broker.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// keep the old config
+	assert.Empty(t, brokerCfg.BrokerBase.Tenant.Quotas)
+	assert.Equal(t, 10, brokerCfg.BrokerBase.Query.QueryConcurrency)
+	newCfg.BrokerBase.Query.QueryConcurrency = 10
+	// case 7: query concurrency is 0
+	newCfg.BrokerBase.Tenant.Quotas = nil
+	newCfg.BrokerBase.Query.QueryConcurrency = 0
+	report, err = broker.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	assert.Equal(t, 10, brokerCfg.BrokerBase.Query.QueryConcurrency)
+	newCfg.BrokerBase.Query.QueryConcurrency = 10
+	// case 8: apply quotas of namespaces
+	newCfg.BrokerBase.Tenant.Quotas = []config.NamespaceQuota{{Namespace: "ns", WriteRate: 100}}
+	tenants.EXPECT().UpdateQuotas(newCfg.BrokerBase.Tenant.Quotas)
+	report, err = broker.Reload(&newCfg)
+	assert.NoError(t, err)
+	assert.True(t, report.IsApplied("broker.tenant.quotas"))
+	assert.Equal(t, newCfg.BrokerBase.Tenant.Quotas, brokerCfg.BrokerBase.Tenant.Quotas)
+}
@@ -0,0 +1,153 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/ingestion/statsd"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+// statsdMaxPacketSize represents the max size of udp packet.
+const statsdMaxPacketSize = 64 * 1024
+
+var (
+	statsdScope              = linmetric.NewScope("lindb.ingestion").Scope("statsd")
+	statsdReceivedCounter    = statsdScope.NewDeltaCounter("received_samples")
+	statsdBadLinesCounter    = statsdScope.NewDeltaCounter("bad_lines")
+	statsdFlushedCounter     = statsdScope.NewDeltaCounter("flushed_metrics")
+	statsdWriteFailedCounter = statsdScope.NewDeltaCounter("write_failures")
+)
+
+// statsdListener listens on udp port for statsd protocol, aggregates samples in memory,
+// then writes aggregated metrics into replication channel every flush interval.
+type statsdListener struct {
+	ctx        context.Context
+	cfg        config.StatsD
+	aggregator *statsd.Aggregator
+	cm         replication.ChannelManager
+	conn       net.PacketConn
+
+	logger *logger.Logger
+}
+
+// newStatsDListener creates the statsd listener.
+func newStatsDListener(
+	ctx context.Context,
+	cfg config.StatsD,
+	cm replication.ChannelManager,
+) *statsdListener {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = config.NewDefaultBrokerBase().StatsD.FlushInterval
+	}
+	return &statsdListener{
+		ctx:        ctx,
+		cfg:        cfg,
+		aggregator: statsd.NewAggregator(cfg.Namespace, cfg.TimerBounds),
+		cm:         cm,
+		logger:     logger.GetLogger("broker", "StatsDListener"),
+	}
+}
+
+// listen listens on the udp port.
+func (l *statsdListener) listen() error {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", l.cfg.Port))
+	if err != nil {
+		return err
+	}
+	l.conn = conn
+	return nil
+}
+
+// run reads packets until ctx done, then closes connection and flushes aggregated metrics.
+func (l *statsdListener) run() {
+	go l.flushLoop()
+	go func() {
+		<-l.ctx.Done()
+		_ = l.conn.Close()
+	}()
+	buf := make([]byte, statsdMaxPacketSize)
+	for {
+		n, _, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			if l.ctx.Err() != nil {
+				return
+			}
+			l.logger.Warn("read statsd packet failure", logger.Error(err))
+			continue
+		}
+		l.handle(buf[:n])
+	}
+}
+
+// handle parses lines of packet, then adds samples into aggregator.
+func (l *statsdListener) handle(packet []byte) {
+	for _, line := range bytes.Split(packet, []byte("\n")) {
+		sample, err := statsd.Parse(string(line))
+		if err != nil {
+			statsdBadLinesCounter.Incr()
+			l.logger.Debug("parse statsd line failure", logger.Error(err))
+			continue
+		}
+		if sample == nil {
+			continue
+		}
+		statsdReceivedCounter.Incr()
+		l.aggregator.Add(sample)
+	}
+}
+
+// flushLoop flushes aggregated metrics every flush interval.
+func (l *statsdListener) flushLoop() {
+	ticker := time.NewTicker(l.cfg.FlushInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			l.flush()
+			return
+		case <-ticker.C:
+			l.flush()
+		}
+	}
+}
+
+// flush writes aggregated metrics into replication channel.
+func (l *statsdListener) flush() {
+	metrics := l.aggregator.Flush(timeutil.Now())
+	if len(metrics) == 0 {
+		return
+	}
+	statsdFlushedCounter.Add(float64(len(metrics)))
+	if err := l.cm.WriteBatch(l.cfg.Database, &protoMetricsV1.MetricList{Metrics: metrics}); err != nil {
+		statsdWriteFailedCounter.Incr()
+		l.logger.Error("write statsd metrics failure",
+			logger.String("db", l.cfg.Database), logger.Error(err))
+	}
+}
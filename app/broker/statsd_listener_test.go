@@ -0,0 +1,83 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/pkg/ltoml"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/replication"
+)
+
+func TestStatsDListener_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	var (
+		mutex   sync.Mutex
+		metrics []*protoMetricsV1.Metric
+	)
+	cm.EXPECT().WriteBatch("statsd", gomock.Any()).
+		DoAndReturn(func(_ string, list *protoMetricsV1.MetricList) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			metrics = append(metrics, list.Metrics...)
+			return nil
+		}).MinTimes(1)
+	cm.EXPECT().WriteBatch("statsd", gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	l := newStatsDListener(ctx, config.StatsD{
+		Database:      "statsd",
+		FlushInterval: ltoml.Duration(10 * time.Millisecond),
+	}, cm)
+	assert.NoError(t, l.listen())
+	go l.run()
+
+	conn, err := net.Dial("udp", l.conn.LocalAddr().String())
+	assert.NoError(t, err)
+	_, err = conn.Write([]byte("requests:1|c\nrequests:2|c\nbad\nlatency:10|ms\n"))
+	assert.NoError(t, err)
+	_ = conn.Close()
+
+	waitUntil(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(metrics) == 2
+	})
+
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestStatsDListener_DefaultFlushInterval(t *testing.T) {
+	l := newStatsDListener(context.TODO(), config.StatsD{}, nil)
+	assert.Equal(t, 10*time.Second, l.cfg.FlushInterval.Duration())
+}
@@ -45,6 +45,9 @@ var log = logger.GetLogger("standalone", "Runtime")
 
 const storageClusterName = "standalone"
 
+// for testing
+var etcdStartTimeout = 60 * time.Second
+
 // runtime represents the runtime dependency of standalone mode
 type runtime struct {
 	version     string
@@ -65,22 +68,15 @@ type runtime struct {
 
 // NewStandaloneRuntime creates the runtime
 func NewStandaloneRuntime(version string, cfg *config.Standalone) server.Service {
+	useEmbeddedETCD(cfg)
 	ctx, cancel := context.WithCancel(context.Background())
 	return &runtime{
 		version:     version,
 		state:       server.New,
 		delayInit:   5 * time.Second,
 		repoFactory: state.NewRepositoryFactory("standalone"),
-		broker: broker.NewBrokerRuntime(version,
-			&config.Broker{
-				BrokerBase: cfg.BrokerBase,
-				Monitor:    config.Monitor{}, // empty to disable broker monitor
-			}),
-		storage: storage.NewStorageRuntime(version,
-			&config.Storage{
-				StorageBase: cfg.StorageBase,
-				Monitor:     config.Monitor{}, // empty to disable storage monitor
-			}),
+		broker:      broker.NewBrokerRuntime(version, newBrokerConfig(cfg)),
+		storage:     storage.NewStorageRuntime(version, newStorageConfig(cfg)),
 		cfg:         cfg,
 		initializer: bootstrap.NewClusterInitializer(fmt.Sprintf("http://localhost:%d", cfg.BrokerBase.HTTP.Port)),
 		ctx:         ctx,
@@ -88,6 +84,36 @@ func NewStandaloneRuntime(version string, cfg *config.Standalone) server.Service
 	}
 }
 
+// useEmbeddedETCD makes broker/storage connect to the embedded etcd server if not using external etcd cluster.
+func useEmbeddedETCD(cfg *config.Standalone) {
+	if !cfg.ETCD.External {
+		endpoints := []string{cfg.ETCD.URL}
+		cfg.BrokerBase.Coordinator.Endpoints = endpoints
+		cfg.StorageBase.Coordinator.Endpoints = endpoints
+	}
+}
+
+// newBrokerConfig returns the config of broker in standalone mode.
+func newBrokerConfig(cfg *config.Standalone) *config.Broker {
+	return &config.Broker{
+		BrokerBase: cfg.BrokerBase,
+		// disable broker monitor, query tracing is shared by broker and storage in process
+		Monitor: config.Monitor{
+			PushTimeout: cfg.Monitor.PushTimeout,
+			TraceURL:    cfg.Monitor.TraceURL,
+			TraceRatio:  cfg.Monitor.TraceRatio,
+		},
+	}
+}
+
+// newStorageConfig returns the config of storage in standalone mode.
+func newStorageConfig(cfg *config.Standalone) *config.Storage {
+	return &config.Storage{
+		StorageBase: cfg.StorageBase,
+		Monitor:     config.Monitor{}, // empty to disable storage monitor
+	}
+}
+
 // Name returns the cluster mode
 func (r *runtime) Name() string {
 	return "standalone"
@@ -97,7 +123,10 @@ func (r *runtime) Name() string {
 func (r *runtime) Run() error {
 	config.StandaloneMode = true
 
-	if err := r.startETCD(); err != nil {
+	if r.cfg.ETCD.External {
+		log.Info("using external etcd cluster, embedded etcd server won't start",
+			logger.Any("endpoints", r.cfg.BrokerBase.Coordinator.Endpoints))
+	} else if err := r.startETCD(); err != nil {
 		log.Error("failed to start ETCD", logger.Error(err))
 		r.state = server.Failed
 		return err
@@ -162,6 +191,73 @@ func (r *runtime) State() server.State {
 	return r.state
 }
 
+// Drain drains broker first, flushes buffered data to storage, then drains storage.
+func (r *runtime) Drain() error {
+	r.state = server.Draining
+	var err error
+	if r.broker != nil {
+		if err = r.broker.Drain(); err != nil {
+			log.Error("drain broker server error", logger.Error(err))
+		}
+	}
+	if r.storage != nil {
+		if err0 := r.storage.Drain(); err0 != nil {
+			log.Error("drain storage server error", logger.Error(err0))
+			err = err0
+		}
+	}
+	r.state = server.Drained
+	return err
+}
+
+// Draining returns if the cluster is draining or drained.
+func (r *runtime) Draining() bool {
+	return r.broker != nil && r.broker.Draining()
+}
+
+// Reload applies the dynamic fields of new standalone config without restart,
+// log levels and monitor interval are applied by standalone, others are applied by broker/storage.
+func (r *runtime) Reload(newCfg interface{}) (*config.ReloadReport, error) {
+	cfg, ok := newCfg.(*config.Standalone)
+	if !ok {
+		return nil, fmt.Errorf("unexpected standalone config type: %T", newCfg)
+	}
+	useEmbeddedETCD(cfg)
+	report := config.NewReloadReport(config.DiffFields(r.cfg, cfg), config.StandaloneDynamicFields)
+	if err := report.Validate(cfg); err != nil {
+		// keep the old config if any value is invalid
+		return nil, err
+	}
+	if report.IsApplied("logging.level") || report.IsApplied("logging.module-levels") {
+		if err := logger.UpdateLevels(cfg.Logging); err != nil {
+			return nil, err
+		}
+		r.cfg.Logging.Level = cfg.Logging.Level
+		r.cfg.Logging.ModuleLevels = cfg.Logging.ModuleLevels
+	}
+	if report.IsApplied("monitor.report-interval") {
+		if r.pusher == nil || cfg.Monitor.ReportInterval <= 0 {
+			// pusher is started/stopped only when server starts
+			report.Reject("monitor.report-interval")
+		} else {
+			r.pusher.SetInterval(cfg.Monitor.ReportInterval.Duration())
+			r.cfg.Monitor.ReportInterval = cfg.Monitor.ReportInterval
+		}
+	}
+	// immutable changes are rejected by standalone report, broker/storage only apply dynamic fields
+	if _, err := r.broker.Reload(newBrokerConfig(cfg)); err != nil {
+		return nil, err
+	}
+	if _, err := r.storage.Reload(newStorageConfig(cfg)); err != nil {
+		return nil, err
+	}
+	r.cfg.BrokerBase.Query.QueryConcurrency = cfg.BrokerBase.Query.QueryConcurrency
+	r.cfg.BrokerBase.Ingestion.MaxTimestampBehind = cfg.BrokerBase.Ingestion.MaxTimestampBehind
+	r.cfg.BrokerBase.Ingestion.MaxTimestampAhead = cfg.BrokerBase.Ingestion.MaxTimestampAhead
+	r.cfg.StorageBase.Query.QueryConcurrency = cfg.StorageBase.Query.QueryConcurrency
+	return report, nil
+}
+
 // Stop stops the cluster
 func (r *runtime) Stop() {
 	defer r.cancel()
@@ -199,13 +295,13 @@ func (r *runtime) startETCD() error {
 	select {
 	case <-e.Server.ReadyNotify():
 		log.Info("etcd server is ready")
-	case <-time.After(60 * time.Second):
+		return nil
+	case <-time.After(etcdStartTimeout):
 		e.Server.Stop() // trigger a shutdown
-		log.Error("etcd server took too long to start")
+		return fmt.Errorf("etcd server took too long to start")
 	case err := <-e.Err():
-		log.Error("etcd server error", logger.Error(err))
+		return fmt.Errorf("etcd server error: %s", err)
 	}
-	return nil
 }
 
 // cleanupState cleans the state of previous standalone process.
@@ -157,3 +157,106 @@ func TestRuntime_cleanupState(t *testing.T) {
 	err = s.cleanupState()
 	assert.Error(t, err)
 }
+
+func TestRuntime_ExternalETCD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		ctrl.Finish()
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	cfg := defaultStandaloneConfig
+	cfg.ETCD.URL = "http://localhost:2479"
+	cfg.BrokerBase.Coordinator.Endpoints = []string{"http://etcd:2379"}
+	cfg.ETCD.External = true
+	standalone := NewStandaloneRuntime("test-version", &cfg)
+	// external etcd cluster, keep coordinator's endpoints
+	assert.Equal(t, []string{"http://etcd:2379"}, cfg.BrokerBase.Coordinator.Endpoints)
+	s := standalone.(*runtime)
+	s.delayInit = time.Hour
+	repoFactory := state.NewMockRepositoryFactory(ctrl)
+	s.repoFactory = repoFactory
+	storage := server.NewMockService(ctrl)
+	s.storage = storage
+	broker := server.NewMockService(ctrl)
+	s.broker = broker
+	repo := state.NewMockRepository(ctrl)
+	repoFactory.EXPECT().CreateRepo(gomock.Any()).Return(repo, nil)
+	repo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().Close().Return(nil)
+	storage.EXPECT().Run().Return(nil)
+	broker.EXPECT().Run().Return(nil)
+	err := standalone.Run()
+	assert.NoError(t, err)
+	assert.Nil(t, s.etcd)
+	storage.EXPECT().Stop()
+	broker.EXPECT().Stop()
+	s.Stop()
+
+	// embedded etcd server, coordinator connects to it
+	cfg.ETCD.External = false
+	_ = NewStandaloneRuntime("test-version", &cfg)
+	assert.Equal(t, []string{"http://localhost:2479"}, cfg.BrokerBase.Coordinator.Endpoints)
+	assert.Equal(t, []string{"http://localhost:2479"}, cfg.StorageBase.Coordinator.Endpoints)
+}
+
+func TestRuntime_Reload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := defaultStandaloneConfig
+	standalone := NewStandaloneRuntime("test-version", &cfg)
+	s := standalone.(*runtime)
+	storage := server.NewMockService(ctrl)
+	s.storage = storage
+	broker := server.NewMockService(ctrl)
+	s.broker = broker
+
+	// case 1: unexpected config type
+	report, err := standalone.Reload(&config.Broker{})
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 2: invalid log level
+	newCfg := defaultStandaloneConfig
+	newCfg.Logging.Level = "unknown"
+	report, err = standalone.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 3: broker reload failure
+	newCfg.Logging.Level = cfg.Logging.Level
+	broker.EXPECT().Reload(gomock.Any()).Return(nil, fmt.Errorf("err"))
+	report, err = standalone.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 4: storage reload failure
+	broker.EXPECT().Reload(gomock.Any()).Return(&config.ReloadReport{}, nil)
+	storage.EXPECT().Reload(gomock.Any()).Return(nil, fmt.Errorf("err"))
+	report, err = standalone.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 5: embedded etcd endpoints are not changed, apply dynamic fields, reject immutable fields
+	newCfg.BrokerBase.Query.QueryConcurrency = 10
+	newCfg.StorageBase.Query.QueryConcurrency = 20
+	newCfg.ETCD.Dir = "/tmp/etcd"
+	newCfg.Monitor.ReportInterval = ltoml.Duration(time.Minute)
+	broker.EXPECT().Reload(gomock.Any()).DoAndReturn(func(newCfg interface{}) (*config.ReloadReport, error) {
+		assert.Equal(t, 10, newCfg.(*config.Broker).BrokerBase.Query.QueryConcurrency)
+		return &config.ReloadReport{}, nil
+	})
+	storage.EXPECT().Reload(gomock.Any()).DoAndReturn(func(newCfg interface{}) (*config.ReloadReport, error) {
+		assert.Equal(t, 20, newCfg.(*config.Storage).StorageBase.Query.QueryConcurrency)
+		return &config.ReloadReport{}, nil
+	})
+	report, err = standalone.Reload(&newCfg)
+	assert.NoError(t, err)
+	assert.Len(t, report.Applied, 2)
+	// pusher not running, monitor interval cannot be applied
+	assert.Len(t, report.Rejected, 2)
+	assert.Equal(t, 10, cfg.BrokerBase.Query.QueryConcurrency)
+	assert.Equal(t, 20, cfg.StorageBase.Query.QueryConcurrency)
+	// case 6: invalid dynamic field, keep the old config
+	newCfg.StorageBase.Query.QueryConcurrency = 0
+	report, err = standalone.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	assert.Equal(t, 20, cfg.StorageBase.Query.QueryConcurrency)
+}
@@ -0,0 +1,87 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
+	httppkg "github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/tsdb"
+)
+
+var (
+	// MetricDescriptorPath represents the path of saving metric descriptors into tsdb metadata.
+	MetricDescriptorPath = "/metadata/metric-descriptor"
+)
+
+// MetricDescriptorAPI represents the api which saves metric descriptors(unit/description/expected field types)
+// into metadata of database, alongside metric name mappings.
+type MetricDescriptorAPI struct {
+	engine tsdb.Engine
+}
+
+// NewMetricDescriptorAPI creates metric descriptor api.
+func NewMetricDescriptorAPI(engine tsdb.Engine) *MetricDescriptorAPI {
+	return &MetricDescriptorAPI{
+		engine: engine,
+	}
+}
+
+// Register adds metric descriptor url route.
+func (api *MetricDescriptorAPI) Register(route gin.IRoutes) {
+	route.PUT(MetricDescriptorPath, api.Save)
+}
+
+// Save saves the metric descriptors into metadata of database, responses 404 if database not exist in this node.
+// Descriptor overwrites the stored one, if inferred is true, only fills the absent attributes of stored one.
+func (api *MetricDescriptorAPI) Save(c *gin.Context) {
+	var param struct {
+		Database string `form:"db" binding:"required"`
+		Inferred bool   `form:"inferred"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	var descriptors []*models.MetricDescriptor
+	if err := c.ShouldBindJSON(&descriptors); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	db, ok := api.engine.GetDatabase(param.Database)
+	if !ok {
+		httppkg.NotFound(c)
+		return
+	}
+	for _, descriptor := range descriptors {
+		if err := descriptor.Validate(); err != nil {
+			httppkg.Error(c, err)
+			return
+		}
+		if descriptor.Namespace == "" {
+			descriptor.Namespace = constants.DefaultNamespace
+		}
+		if err := db.Metadata().MetadataDatabase().SaveMetricDescriptor(descriptor, param.Inferred); err != nil {
+			httppkg.Error(c, err)
+			return
+		}
+	}
+	httppkg.OK(c, "success")
+}
@@ -0,0 +1,75 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/tsdb"
+	"github.com/lindb/lindb/tsdb/metadb"
+)
+
+func TestMetricDescriptorAPI_Save(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	db := tsdb.NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	api := NewMetricDescriptorAPI(engine)
+	r := gin.New()
+	api.Register(r)
+	put := func(query, body string) int {
+		req := httptest.NewRequest(http.MethodPut, MetricDescriptorPath+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	// case 1: database required
+	assert.Equal(t, http.StatusInternalServerError, put("", `[]`))
+	// case 2: bad body
+	assert.Equal(t, http.StatusInternalServerError, put("?db=db", `{`))
+	// case 3: database not found
+	engine.EXPECT().GetDatabase("db").Return(nil, false)
+	assert.Equal(t, http.StatusNotFound, put("?db=db", `[]`))
+	engine.EXPECT().GetDatabase("db").Return(db, true).AnyTimes()
+	// case 4: invalid descriptor
+	assert.Equal(t, http.StatusInternalServerError, put("?db=db", `[{"unit":"bytes"}]`))
+	// case 5: save err
+	metadataDB.EXPECT().SaveMetricDescriptor(gomock.Any(), false).Return(fmt.Errorf("err"))
+	assert.Equal(t, http.StatusInternalServerError, put("?db=db", `[{"name":"cpu"}]`))
+	// case 6: save inferred descriptor with default namespace
+	metadataDB.EXPECT().SaveMetricDescriptor(&models.MetricDescriptor{
+		Namespace: "default-ns", Name: "cpu", Unit: "percent",
+	}, true).Return(nil)
+	assert.Equal(t, http.StatusOK, put("?db=db&inferred=true", `[{"name":"cpu","unit":"percent"}]`))
+}
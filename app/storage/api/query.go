@@ -0,0 +1,142 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/models"
+	httppkg "github.com/lindb/lindb/pkg/http"
+	"github.com/lindb/lindb/pkg/timeutil"
+	storageQuery "github.com/lindb/lindb/query/storage"
+	"github.com/lindb/lindb/sql"
+	"github.com/lindb/lindb/sql/stmt"
+	"github.com/lindb/lindb/tsdb"
+)
+
+// for testing
+var (
+	queryShardFunc = storageQuery.QueryShard
+)
+
+var (
+	// LocalQueryPath represents the path of executing metric query against local shards.
+	LocalQueryPath = "/query/local"
+)
+
+var (
+	errNotLocalRequest = errors.New("local query only accepts the request from loopback address")
+	errNotMetricQuery  = errors.New("local query only supports metric query without sub query")
+)
+
+// LocalQueryAPI represents the debugging api which executes metric query against local shards directly,
+// bypassing broker, returns the raw result of each shard, so that operators can isolate whether the data
+// problem is storage side or merge side.
+type LocalQueryAPI struct {
+	engine  tsdb.Engine
+	timeout time.Duration
+}
+
+// NewLocalQueryAPI creates local query api.
+func NewLocalQueryAPI(engine tsdb.Engine, timeout time.Duration) *LocalQueryAPI {
+	return &LocalQueryAPI{
+		engine:  engine,
+		timeout: timeout,
+	}
+}
+
+// Register adds local query url route.
+func (api *LocalQueryAPI) Register(route gin.IRoutes) {
+	route.GET(LocalQueryPath, api.Query)
+}
+
+// Query executes the metric query against the given shards(all shards of database in this node if not set),
+// only accepts the request from loopback address, responses 404 if database not exist in this node.
+func (api *LocalQueryAPI) Query(c *gin.Context) {
+	if !isLoopback(c.Request.RemoteAddr) {
+		httppkg.Forbidden(c, errNotLocalRequest)
+		return
+	}
+	var param struct {
+		Database string  `form:"db" binding:"required"`
+		SQL      string  `form:"sql" binding:"required"`
+		ShardIDs []int32 `form:"shard"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	db, ok := api.engine.GetDatabase(param.Database)
+	if !ok {
+		httppkg.NotFound(c)
+		return
+	}
+	statement, err := sql.Parse(param.SQL)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	query, ok := statement.(*stmt.Query)
+	if !ok || query.SubQuery != nil {
+		httppkg.Error(c, errNotMetricQuery)
+		return
+	}
+	// plans the query same as broker, uses write interval of database if query without interval
+	if query.Interval <= 0 {
+		if err := query.Interval.ValueOf(db.GetOption().Interval); err != nil {
+			httppkg.Error(c, err)
+			return
+		}
+	}
+	query.TimeRange.Start = timeutil.Truncate(query.TimeRange.Start, query.Interval.Int64())
+	query.TimeRange.End = timeutil.Truncate(query.TimeRange.End, query.Interval.Int64())
+
+	shardIDs := param.ShardIDs
+	if len(shardIDs) == 0 {
+		shardIDs = db.ShardIDs()
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), api.timeout)
+	defer cancel()
+	results := make([]*models.ShardResult, 0, len(shardIDs))
+	for _, shardID := range shardIDs {
+		// each shard is queried with a copy of statement, avoids sharing state between shards
+		shardQuery := *query
+		result, err := queryShardFunc(ctx, db, shardID, &shardQuery)
+		if err != nil {
+			httppkg.Error(c, err)
+			return
+		}
+		results = append(results, result)
+	}
+	httppkg.OK(c, results)
+}
+
+// isLoopback returns if the remote address of request is loopback address.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
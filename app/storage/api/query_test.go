@@ -0,0 +1,127 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	storageQuery "github.com/lindb/lindb/query/storage"
+	"github.com/lindb/lindb/sql/stmt"
+	"github.com/lindb/lindb/tsdb"
+)
+
+func TestLocalQueryAPI_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		queryShardFunc = storageQuery.QueryShard
+		ctrl.Finish()
+	}()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	db := tsdb.NewMockDatabase(ctrl)
+	db.EXPECT().GetOption().Return(option.DatabaseOption{Interval: "10s"}).AnyTimes()
+	api := NewLocalQueryAPI(engine, time.Second)
+	r := gin.New()
+	api.Register(r)
+	get := func(remoteAddr string, params url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, LocalQueryPath+"?"+params.Encode(), nil)
+		req.RemoteAddr = remoteAddr
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+	params := url.Values{"db": []string{"db"}, "sql": []string{"select f from cpu group by host"}}
+
+	// case 1: not local request
+	assert.Equal(t, http.StatusForbidden, get("192.168.1.1:2000", params).Code)
+	assert.Equal(t, http.StatusForbidden, get("bad-addr", params).Code)
+	// case 2: bad param
+	assert.Equal(t, http.StatusInternalServerError, get("127.0.0.1:2000", url.Values{"db": []string{"db"}}).Code)
+	// case 3: database not found
+	engine.EXPECT().GetDatabase("db").Return(nil, false)
+	assert.Equal(t, http.StatusNotFound, get("127.0.0.1:2000", params).Code)
+	engine.EXPECT().GetDatabase("db").Return(db, true).AnyTimes()
+	// case 4: bad sql
+	for _, ql := range []string{"select", "show databases", "select f from (select f from cpu)"} {
+		resp := get("[::1]:2000", url.Values{"db": []string{"db"}, "sql": []string{ql}})
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	}
+	// case 5: query shard failure
+	queryShardFunc = func(ctx context.Context, db tsdb.Database, shardID int32, query *stmt.Query) (*models.ShardResult, error) {
+		return nil, fmt.Errorf("err")
+	}
+	db.EXPECT().ShardIDs().Return([]int32{1, 2})
+	assert.Equal(t, http.StatusInternalServerError, get("127.0.0.1:2000", params).Code)
+	// case 6: query all shards of database
+	queryShardFunc = func(ctx context.Context, db tsdb.Database, shardID int32, query *stmt.Query) (*models.ShardResult, error) {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, timeutil.Interval(10*timeutil.OneSecond), query.Interval)
+		assert.Zero(t, query.TimeRange.Start%query.Interval.Int64())
+		return &models.ShardResult{ShardID: shardID}, nil
+	}
+	db.EXPECT().ShardIDs().Return([]int32{1, 2})
+	resp := get("127.0.0.1:2000", params)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var results []*models.ShardResult
+	assert.NoError(t, encoding.JSONUnmarshal(resp.Body.Bytes(), &results))
+	assert.Equal(t, []*models.ShardResult{{ShardID: 1}, {ShardID: 2}}, results)
+	// case 7: query given shards with interval
+	params.Set("sql", "select f from cpu group by time(1m)")
+	params.Add("shard", "2")
+	queryShardFunc = func(ctx context.Context, db tsdb.Database, shardID int32, query *stmt.Query) (*models.ShardResult, error) {
+		assert.Equal(t, timeutil.Interval(timeutil.OneMinute), query.Interval)
+		return &models.ShardResult{ShardID: shardID}, nil
+	}
+	resp = get("127.0.0.1:2000", params)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NoError(t, encoding.JSONUnmarshal(resp.Body.Bytes(), &results))
+	assert.Equal(t, []*models.ShardResult{{ShardID: 2}}, results)
+}
+
+func TestLocalQueryAPI_Query_BadInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	db := tsdb.NewMockDatabase(ctrl)
+	engine.EXPECT().GetDatabase("db").Return(db, true)
+	db.EXPECT().GetOption().Return(option.DatabaseOption{Interval: "x"})
+	api := NewLocalQueryAPI(engine, time.Second)
+	r := gin.New()
+	api.Register(r)
+	req := httptest.NewRequest(http.MethodGet, LocalQueryPath+"?db=db&sql=select+f+from+cpu", nil)
+	req.RemoteAddr = "127.0.0.1:2000"
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
@@ -0,0 +1,105 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/pkg/logger"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+	"github.com/lindb/lindb/tsdb"
+)
+
+// shardKey represents the unique key of shard.
+type shardKey struct {
+	database string
+	shardID  int32
+}
+
+// BulkLoader implements the bulk load service, loads historical data into sealed data files of shard directly.
+type BulkLoader struct {
+	engine tsdb.Engine
+	logger *logger.Logger
+}
+
+// NewBulkLoader returns a new BulkLoader.
+func NewBulkLoader(engine tsdb.Engine) *BulkLoader {
+	return &BulkLoader{
+		engine: engine,
+		logger: logger.GetLogger("storage", "BulkLoader"),
+	}
+}
+
+// Load handles the stream bulk load request, commits all pending families when stream completed,
+// discards the pending families if any error occurs(families already sealed are kept).
+func (l *BulkLoader) Load(stream protoStorageV1.BulkLoadService_LoadServer) error {
+	loaders := make(map[shardKey]tsdb.BulkLoader)
+	defer func() {
+		for _, loader := range loaders {
+			_ = loader.Close()
+		}
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			l.logger.Error("receive bulk load request error", logger.Error(err))
+			return status.Error(codes.Internal, err.Error())
+		}
+		key := shardKey{database: req.Database, shardID: req.ShardID}
+		loader, ok := loaders[key]
+		if !ok {
+			shard, ok := l.engine.GetShard(req.Database, req.ShardID)
+			if !ok {
+				return status.Errorf(codes.NotFound, "shard %d for database %s not exists", req.ShardID, req.Database)
+			}
+			loader = shard.NewBulkLoader()
+			loaders[key] = loader
+		}
+		var metricList protoMetricsV1.MetricList
+		if err := metricList.Unmarshal(req.Data); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		for _, metric := range metricList.Metrics {
+			if err := loader.Load(metric); err != nil {
+				l.logger.Error("load metric error",
+					logger.String("database", req.Database), logger.Int32("shardID", req.ShardID), logger.Error(err))
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+	}
+
+	resp := &protoStorageV1.BulkLoadResponse{}
+	for key, loader := range loaders {
+		if err := loader.Commit(); err != nil {
+			l.logger.Error("commit bulk load error",
+				logger.String("database", key.database), logger.Int32("shardID", key.shardID), logger.Error(err))
+			return status.Error(codes.Internal, err.Error())
+		}
+		resp.LoadedMetrics += loader.LoadedMetrics()
+		resp.SealedFamilies += loader.SealedFamilies()
+	}
+	return stream.SendAndClose(resp)
+}
@@ -0,0 +1,87 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+	"github.com/lindb/lindb/tsdb"
+)
+
+func TestBulkLoader_Load(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	shard := tsdb.NewMockShard(ctrl)
+	loader := tsdb.NewMockBulkLoader(ctrl)
+	stream := protoStorageV1.NewMockBulkLoadService_LoadServer(ctrl)
+	bulkLoader := NewBulkLoader(engine)
+
+	metricList := &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "test"}}}
+	data, _ := metricList.Marshal()
+	req := &protoStorageV1.BulkLoadRequest{Database: database, ShardID: shardID, Data: data}
+
+	// case 1: receive err
+	stream.EXPECT().Recv().Return(nil, fmt.Errorf("err"))
+	assert.Error(t, bulkLoader.Load(stream))
+	// case 2: shard not exist
+	stream.EXPECT().Recv().Return(req, nil)
+	engine.EXPECT().GetShard(database, shardID).Return(nil, false)
+	assert.Error(t, bulkLoader.Load(stream))
+	// case 3: unmarshal err
+	engine.EXPECT().GetShard(database, shardID).Return(shard, true).AnyTimes()
+	shard.EXPECT().NewBulkLoader().Return(loader).AnyTimes()
+	stream.EXPECT().Recv().Return(&protoStorageV1.BulkLoadRequest{Database: database, ShardID: shardID, Data: []byte{1, 2}}, nil)
+	loader.EXPECT().Close().Return(nil)
+	assert.Error(t, bulkLoader.Load(stream))
+	// case 4: load err
+	stream.EXPECT().Recv().Return(req, nil)
+	loader.EXPECT().Load(gomock.Any()).Return(fmt.Errorf("err"))
+	loader.EXPECT().Close().Return(nil)
+	assert.Error(t, bulkLoader.Load(stream))
+	// case 5: commit err
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(req, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	loader.EXPECT().Load(gomock.Any()).Return(nil)
+	loader.EXPECT().Commit().Return(fmt.Errorf("err"))
+	loader.EXPECT().Close().Return(nil)
+	assert.Error(t, bulkLoader.Load(stream))
+	// case 6: load successfully
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(req, nil),
+		stream.EXPECT().Recv().Return(req, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	loader.EXPECT().Load(gomock.Any()).Return(nil).Times(2)
+	loader.EXPECT().Commit().Return(nil)
+	loader.EXPECT().LoadedMetrics().Return(int64(2))
+	loader.EXPECT().SealedFamilies().Return(int32(1))
+	loader.EXPECT().Close().Return(nil)
+	stream.EXPECT().SendAndClose(&protoStorageV1.BulkLoadResponse{LoadedMetrics: 2, SealedFamilies: 1}).Return(nil)
+	assert.NoError(t, bulkLoader.Load(stream))
+}
@@ -0,0 +1,122 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+	"github.com/lindb/lindb/tsdb"
+)
+
+const (
+	// exportBatchSize is the max number of metrics per export response.
+	exportBatchSize = 1000
+	// maxExportMetrics is the max number of metrics exported if metric names are not specified.
+	maxExportMetrics = 1000000
+)
+
+// Exporter implements the export service, dumps raw data points of shard.
+type Exporter struct {
+	engine tsdb.Engine
+	logger *logger.Logger
+}
+
+// NewExporter returns a new Exporter.
+func NewExporter(engine tsdb.Engine) *Exporter {
+	return &Exporter{
+		engine: engine,
+		logger: logger.GetLogger("storage", "Exporter"),
+	}
+}
+
+// Export scans the data points of metrics within time range, sends them to stream in batches.
+func (e *Exporter) Export(req *protoStorageV1.ExportRequest, stream protoStorageV1.ExportService_ExportServer) error {
+	if req.StartTime > req.EndTime {
+		return status.Errorf(codes.InvalidArgument, "start time: %d is after end time: %d", req.StartTime, req.EndTime)
+	}
+	shard, ok := e.engine.GetShard(req.Database, req.ShardID)
+	if !ok {
+		return status.Errorf(codes.NotFound, "shard %d for database %s not exists", req.ShardID, req.Database)
+	}
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = constants.DefaultNamespace
+	}
+	metricNames := req.MetricNames
+	if len(metricNames) == 0 {
+		// exports all metrics of namespace, used for copying data between replicas
+		db, ok := e.engine.GetDatabase(req.Database)
+		if !ok {
+			return status.Errorf(codes.NotFound, "database %s not exists", req.Database)
+		}
+		names, err := db.Metadata().MetadataDatabase().SuggestMetrics(namespace, "", maxExportMetrics)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		metricNames = names
+	}
+	timeRange := timeutil.TimeRange{Start: req.StartTime, End: req.EndTime}
+	batch := &protoMetricsV1.MetricList{}
+	send := func() error {
+		if len(batch.Metrics) == 0 {
+			return nil
+		}
+		data, err := batch.Marshal()
+		if err != nil {
+			return err
+		}
+		batch.Metrics = batch.Metrics[:0]
+		return stream.Send(&protoStorageV1.ExportResponse{Data: data})
+	}
+	for _, metricName := range metricNames {
+		err := shard.Export(namespace, metricName, timeRange, func(metric *protoMetricsV1.Metric) error {
+			batch.Metrics = append(batch.Metrics, metric)
+			if len(batch.Metrics) >= exportBatchSize {
+				return send()
+			}
+			return nil
+		})
+		if err != nil {
+			e.logger.Error("export metric error",
+				logger.String("database", req.Database), logger.Int32("shardID", req.ShardID),
+				logger.String("metric", metricName), logger.Error(err))
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	if err := send(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// ListShards returns the ids of shards of database hosted by this node, used by export tool as default shards.
+func (e *Exporter) ListShards(_ context.Context, req *protoStorageV1.ListShardsRequest) (*protoStorageV1.ListShardsResponse, error) {
+	db, ok := e.engine.GetDatabase(req.Database)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "database %s not exists", req.Database)
+	}
+	return &protoStorageV1.ListShardsResponse{ShardIDs: db.ShardIDs()}, nil
+}
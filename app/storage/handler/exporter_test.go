@@ -0,0 +1,153 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+	"github.com/lindb/lindb/tsdb"
+	"github.com/lindb/lindb/tsdb/metadb"
+)
+
+func TestExporter_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	shard := tsdb.NewMockShard(ctrl)
+	stream := protoStorageV1.NewMockExportService_ExportServer(ctrl)
+	exporter := NewExporter(engine)
+	req := &protoStorageV1.ExportRequest{
+		Database:    database,
+		ShardID:     shardID,
+		MetricNames: []string{"cpu", "memory"},
+		StartTime:   10,
+		EndTime:     100,
+	}
+	timeRange := timeutil.TimeRange{Start: 10, End: 100}
+
+	// case 1: bad time range
+	assert.Error(t, exporter.Export(&protoStorageV1.ExportRequest{StartTime: 10, EndTime: 1}, stream))
+	// case 2: shard not exist
+	engine.EXPECT().GetShard(database, shardID).Return(nil, false)
+	assert.Error(t, exporter.Export(req, stream))
+	engine.EXPECT().GetShard(database, shardID).Return(shard, true).AnyTimes()
+	// case 3: export err
+	shard.EXPECT().Export(constants.DefaultNamespace, "cpu", timeRange, gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, exporter.Export(req, stream))
+	// case 4: send err
+	shard.EXPECT().Export(constants.DefaultNamespace, "cpu", timeRange, gomock.Any()).
+		DoAndReturn(func(_, _ string, _ timeutil.TimeRange, fn func(metric *protoMetricsV1.Metric) error) error {
+			return fn(&protoMetricsV1.Metric{Name: "cpu"})
+		})
+	shard.EXPECT().Export(constants.DefaultNamespace, "memory", timeRange, gomock.Any()).Return(nil)
+	stream.EXPECT().Send(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, exporter.Export(req, stream))
+	// case 5: export successfully, sends full batch and remaining metrics
+	shard.EXPECT().Export(constants.DefaultNamespace, "cpu", timeRange, gomock.Any()).
+		DoAndReturn(func(_, _ string, _ timeutil.TimeRange, fn func(metric *protoMetricsV1.Metric) error) error {
+			for i := 0; i < exportBatchSize+1; i++ {
+				if err := fn(&protoMetricsV1.Metric{Name: "cpu"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	shard.EXPECT().Export(constants.DefaultNamespace, "memory", timeRange, gomock.Any()).Return(nil)
+	var sent int
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoStorageV1.ExportResponse) error {
+		var metricList protoMetricsV1.MetricList
+		assert.NoError(t, metricList.Unmarshal(resp.Data))
+		sent += len(metricList.Metrics)
+		return nil
+	}).Times(2)
+	assert.NoError(t, exporter.Export(req, stream))
+	assert.Equal(t, exportBatchSize+1, sent)
+}
+
+func TestExporter_Export_AllMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	shard := tsdb.NewMockShard(ctrl)
+	db := tsdb.NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	stream := protoStorageV1.NewMockExportService_ExportServer(ctrl)
+	exporter := NewExporter(engine)
+	req := &protoStorageV1.ExportRequest{
+		Database:  database,
+		ShardID:   shardID,
+		Namespace: "ns",
+		StartTime: 10,
+		EndTime:   100,
+	}
+	timeRange := timeutil.TimeRange{Start: 10, End: 100}
+	engine.EXPECT().GetShard(database, shardID).Return(shard, true).AnyTimes()
+
+	// case 1: database not exist
+	engine.EXPECT().GetDatabase(database).Return(nil, false)
+	assert.Error(t, exporter.Export(req, stream))
+	engine.EXPECT().GetDatabase(database).Return(db, true).AnyTimes()
+	// case 2: suggest metrics err
+	metadataDB.EXPECT().SuggestMetrics("ns", "", maxExportMetrics).Return(nil, fmt.Errorf("err"))
+	assert.Error(t, exporter.Export(req, stream))
+	// case 3: exports all metrics of namespace
+	metadataDB.EXPECT().SuggestMetrics("ns", "", maxExportMetrics).Return([]string{"cpu", "memory"}, nil)
+	shard.EXPECT().Export("ns", "cpu", timeRange, gomock.Any()).
+		DoAndReturn(func(_, _ string, _ timeutil.TimeRange, fn func(metric *protoMetricsV1.Metric) error) error {
+			return fn(&protoMetricsV1.Metric{Name: "cpu"})
+		})
+	shard.EXPECT().Export("ns", "memory", timeRange, gomock.Any()).Return(nil)
+	stream.EXPECT().Send(gomock.Any()).Return(nil)
+	assert.NoError(t, exporter.Export(req, stream))
+}
+
+func TestExporter_ListShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	db := tsdb.NewMockDatabase(ctrl)
+	exporter := NewExporter(engine)
+	req := &protoStorageV1.ListShardsRequest{Database: database}
+
+	// case 1: database not exist
+	engine.EXPECT().GetDatabase(database).Return(nil, false)
+	resp, err := exporter.ListShards(context.TODO(), req)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	// case 2: list shards of database
+	engine.EXPECT().GetDatabase(database).Return(db, true)
+	db.EXPECT().ShardIDs().Return([]int32{1, 3})
+	resp, err = exporter.ListShards(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1, 3}, resp.ShardIDs)
+}
@@ -42,7 +42,9 @@ import (
 // Writer implements the stream write service.
 type Writer struct {
 	engine tsdb.Engine
-	logger *logger.Logger
+	// rejects new writes if storage is draining, nil means never draining
+	draining func() bool
+	logger   *logger.Logger
 }
 
 // NewWriter returns a new Writer.
@@ -53,6 +55,13 @@ func NewWriter(engine tsdb.Engine) *Writer {
 	}
 }
 
+// WithDraining sets the function which checks if storage is draining,
+// new writes are rejected when draining, broker keeps the data in replication channel.
+func (w *Writer) WithDraining(draining func() bool) *Writer {
+	w.draining = draining
+	return w
+}
+
 func (w *Writer) Reset(ctx context.Context, req *protoStorageV1.ResetSeqRequest) (*protoStorageV1.ResetSeqResponse, error) {
 	logicNode, err := getLogicNodeFromCtx(ctx)
 	if err != nil {
@@ -112,6 +121,9 @@ func (w *Writer) Write(stream protoStorageV1.WriteService_WriteServer) error {
 			return status.Error(codes.Internal, err.Error())
 		}
 
+		if w.draining != nil && w.draining() {
+			return status.Error(codes.Unavailable, "storage is draining, rejects new writes")
+		}
 		if len(req.Replicas) == 0 {
 			continue
 		}
@@ -27,7 +27,9 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/golang/snappy"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/lindb/lindb/models"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
@@ -199,6 +201,24 @@ func TestWriter_Write_Fail(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestWriter_Write_draining(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	engine := tsdb.NewMockEngine(ctl)
+	writer := NewWriter(engine).WithDraining(func() bool { return true })
+
+	ctx := mockContext(database, shardID, node)
+	writeServer := protoStorageV1.NewMockWriteService_WriteServer(ctl)
+	writeServer.EXPECT().Context().Return(ctx)
+	shard := tsdb.NewMockShard(ctl)
+	engine.EXPECT().GetShard(gomock.Any(), gomock.Any()).Return(shard, true)
+	shard.EXPECT().GetOrCreateSequence(gomock.Any()).Return(replication.NewMockSequence(ctl), nil)
+	writeServer.EXPECT().Recv().Return(&protoStorageV1.WriteRequest{Replicas: []*protoStorageV1.Replica{{Seq: int64(10)}}}, nil)
+	err := writer.Write(writeServer)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
 func TestWriter_handle_replica(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -22,12 +22,15 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/felixge/fgprof"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/atomic"
 
+	"github.com/lindb/lindb/app/storage/api"
 	"github.com/lindb/lindb/app/storage/handler"
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/constants"
@@ -35,9 +38,11 @@ import (
 	task "github.com/lindb/lindb/coordinator/storage"
 	"github.com/lindb/lindb/internal/concurrent"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/monitoring"
 	"github.com/lindb/lindb/pkg/hostutil"
+	httppkg "github.com/lindb/lindb/pkg/http"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/server"
 	"github.com/lindb/lindb/pkg/state"
@@ -58,8 +63,10 @@ type factory struct {
 
 // rpcHandler represents all dependency rpc handlers
 type rpcHandler struct {
-	writer  *handler.Writer
-	handler *query.TaskHandler
+	writer     *handler.Writer
+	bulkLoader *handler.BulkLoader
+	exporter   *handler.Exporter
+	handler    *query.TaskHandler
 }
 
 // just for testing
@@ -75,19 +82,26 @@ type runtime struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	node         models.Node
-	server       rpc.GRPCServer
-	repoFactory  state.RepositoryFactory
-	repo         state.Repository
-	registry     discovery.Registry
-	taskExecutor *task.TaskExecutor
-	factory      factory
-	engine       tsdb.Engine
-	rpcHandler   *rpcHandler
-	httpServer   *http.Server
-	queryPool    concurrent.Pool
-	pusher       monitoring.NativePusher
-	log          *logger.Logger
+	node          models.Node
+	server        rpc.GRPCServer
+	repoFactory   state.RepositoryFactory
+	repo          state.Repository
+	registry      discovery.Registry
+	clusterCfgSM  discovery.ClusterConfigStateMachine
+	taskExecutor  *task.TaskExecutor
+	factory       factory
+	engine        tsdb.Engine
+	rpcHandler    *rpcHandler
+	httpServer    *http.Server
+	queryPool     concurrent.Pool
+	pusher        monitoring.NativePusher
+	traceExporter *tracing.OTLPExporter
+	// rejects new writes/queries if draining
+	draining  atomic.Bool
+	drainLock sync.Mutex
+	// serializes config reloading
+	reloadLock sync.Mutex
+	log        *logger.Logger
 }
 
 // NewStorageRuntime creates storage runtime
@@ -100,10 +114,9 @@ func NewStorageRuntime(version string, config *config.Storage) server.Service {
 		config:      config,
 		ctx:         ctx,
 		cancel:      cancel,
-		queryPool: concurrent.NewPool(
+		queryPool: query.NewTaskPool(
 			"task-pool",
-			config.StorageBase.Query.QueryConcurrency,
-			config.StorageBase.Query.IdleTimeout.Duration(),
+			config.StorageBase.Query,
 			linmetric.NewScope("lindb.concurrent.pool", "pool", "storage-query")),
 		log: logger.GetLogger("storage", "Runtime"),
 	}
@@ -122,8 +135,8 @@ func (r *runtime) Run() error {
 		return fmt.Errorf("failed to get server ip address, error: %s", err)
 	}
 
-	// start tsdb engine for storage server
-	engine, err := tsdb.NewEngine(r.config.StorageBase.TSDB)
+	// start tsdb engine for storage server, background flush/compaction share query pool with low priority
+	engine, err := tsdb.NewEngine(r.config.StorageBase.TSDB, r.queryPool)
 	if err != nil {
 		r.state = server.Failed
 		return err
@@ -140,6 +153,9 @@ func (r *runtime) Run() error {
 		Port:     r.config.StorageBase.GRPC.Port,
 		HostName: hostName,
 		HTTPPort: r.config.StorageBase.GRPC.Port + 1,
+		Zone:     r.config.StorageBase.Location.Zone,
+		Rack:     r.config.StorageBase.Location.Rack,
+		ReadOnly: r.config.StorageBase.ReadOnly,
 	}
 
 	r.factory = factory{taskServer: rpc.NewTaskServerFactory()}
@@ -157,12 +173,20 @@ func (r *runtime) Run() error {
 	}
 
 	// register storage node info
-	//TODO TTL default value???
-	r.registry = discovery.NewRegistry(r.repo, constants.ActiveNodesPath, r.config.StorageBase.GRPC.TTL.Duration())
+	r.registry = discovery.NewRegistry(r.repo, constants.ActiveNodesPath,
+		time.Duration(r.config.StorageBase.Coordinator.LeaseTTLSeconds())*time.Second)
 	if err := r.registry.Register(r.node); err != nil {
 		return fmt.Errorf("register storage node error:%s", err)
 	}
 
+	// watch cluster-wide config, apply changes without restart
+	clusterCfgSM, err := discovery.NewClusterConfigStateMachine(r.ctx, discovery.NewFactory(r.repo), r.applyClusterConfig)
+	if err != nil {
+		r.state = server.Failed
+		return err
+	}
+	r.clusterCfgSM = clusterCfgSM
+
 	r.taskExecutor = task.NewTaskExecutor(r.ctx, &r.node, r.repo, r.engine)
 	r.taskExecutor.Run()
 
@@ -170,6 +194,8 @@ func (r *runtime) Run() error {
 	r.systemCollector()
 	// start stat monitoring
 	r.nativePusher()
+	// start query tracing
+	r.startTracing()
 
 	r.state = server.Running
 	return nil
@@ -191,8 +217,89 @@ func (r *runtime) startStateRepo() error {
 	return nil
 }
 
-// Stop stops storage server
+// Drain stops accepting new writes/queries, flushes memory data of all databases to disk,
+// then deregisters storage node from discovery.
+func (r *runtime) Drain() error {
+	r.drainLock.Lock()
+	defer r.drainLock.Unlock()
+
+	if r.draining.Load() {
+		// already drained
+		return nil
+	}
+	r.log.Info("draining storage server...")
+	r.draining.Store(true)
+	r.state = server.Draining
+
+	var err error
+	if r.engine != nil {
+		if err = r.engine.FlushAll(); err != nil {
+			r.log.Error("flush tsdb engine error when draining", logger.Error(err))
+		} else {
+			r.log.Info("flushed tsdb engine successfully")
+		}
+	}
+	if r.registry != nil {
+		if err0 := r.registry.Deregister(r.node); err0 != nil {
+			r.log.Error("deregister storage node error when draining", logger.Error(err0))
+			err = err0
+		} else {
+			r.log.Info("deregistered storage node successfully")
+		}
+	}
+	r.state = server.Drained
+	r.log.Info("drained storage server")
+	return err
+}
+
+// Draining returns if storage is draining or drained.
+func (r *runtime) Draining() bool {
+	return r.draining.Load()
+}
+
+// Reload applies the dynamic fields(query concurrency, log levels, monitor interval)
+// of new storage config without restart, changes of immutable fields are rejected.
+func (r *runtime) Reload(newCfg interface{}) (*config.ReloadReport, error) {
+	cfg, ok := newCfg.(*config.Storage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected storage config type: %T", newCfg)
+	}
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	report := config.NewReloadReport(config.DiffFields(r.config, cfg), config.StorageDynamicFields)
+	if err := report.Validate(cfg); err != nil {
+		// keep the old config if any value is invalid
+		return nil, err
+	}
+	if report.IsApplied("logging.level") || report.IsApplied("logging.module-levels") {
+		if err := logger.UpdateLevels(cfg.Logging); err != nil {
+			return nil, err
+		}
+		r.config.Logging.Level = cfg.Logging.Level
+		r.config.Logging.ModuleLevels = cfg.Logging.ModuleLevels
+	}
+	if report.IsApplied("storage.query.query-concurrency") {
+		r.queryPool.SetMaxWorkers(cfg.StorageBase.Query.QueryConcurrency)
+		r.config.StorageBase.Query.QueryConcurrency = cfg.StorageBase.Query.QueryConcurrency
+	}
+	if report.IsApplied("monitor.report-interval") {
+		if r.pusher == nil || cfg.Monitor.ReportInterval <= 0 {
+			// pusher is started/stopped only when server starts
+			report.Reject("monitor.report-interval")
+		} else {
+			r.pusher.SetInterval(cfg.Monitor.ReportInterval.Duration())
+			r.config.Monitor.ReportInterval = cfg.Monitor.ReportInterval
+		}
+	}
+	return report, nil
+}
+
+// Stop stops storage server, drains storage first if not drained.
 func (r *runtime) Stop() {
+	if err := r.Drain(); err != nil {
+		r.log.Error("drain storage server error, continue stopping", logger.Error(err))
+	}
 	r.log.Info("stopping storage server...")
 	defer r.cancel()
 
@@ -200,6 +307,11 @@ func (r *runtime) Stop() {
 		r.pusher.Stop()
 		r.log.Info("stopped native linmetric pusher successfully")
 	}
+	if r.traceExporter != nil {
+		tracing.Setup(nil, 0)
+		r.traceExporter.Stop()
+		r.log.Info("stopped trace exporter successfully")
+	}
 
 	if r.taskExecutor != nil {
 		r.log.Info("stopping task executor")
@@ -210,6 +322,14 @@ func (r *runtime) Stop() {
 		}
 	}
 
+	if r.clusterCfgSM != nil {
+		if err := r.clusterCfgSM.Close(); err != nil {
+			r.log.Error("close cluster config state machine error", logger.Error(err))
+		} else {
+			r.log.Info("closed cluster config state machine successfully")
+		}
+	}
+
 	// close registry, deregister storage node from active list
 	if r.registry != nil {
 		r.log.Info("closing discovery-registry...")
@@ -257,20 +377,35 @@ func (r *runtime) Stop() {
 	r.state = server.Terminated
 }
 
+// applyClusterConfig applies the cluster-wide config to storage server.
+func (r *runtime) applyClusterConfig(cfg models.ClusterConfig) {
+	r.log.Info("apply cluster config", logger.Any("config", cfg))
+	r.engine.SetMaxSeriesPerMetric(cfg.MaxSeriesPerMetric)
+}
+
 // startHTTPServer starts http server for api rpcHandler
 func (r *runtime) startHTTPServer() {
-	if !logger.IsDebug() {
-		return
-	}
 	port := r.node.Port + 1
 	r.log.Info("starting http server", logger.Uint16("port", port))
 
-	// add prometheus metric report
 	g := gin.New()
-	pprof.Register(g)
-	r.log.Info("/debug/pprof is enabled")
-	g.GET("/debug/fgprof", gin.WrapH(fgprof.Handler()))
-	r.log.Info("/debug/fgprof is enabled")
+	// add prometheus metric report
+	g.GET("/metrics", gin.WrapH(linmetric.NewPrometheusHandler(r.globalKeyValues())))
+	apiRouter := g.Group("/api/v1")
+	// changes log levels and tails recent log entries on the fly
+	httppkg.NewLoggerAPI().Register(apiRouter)
+	// drains storage before shutdown
+	httppkg.NewDrainAPI(r).Register(apiRouter)
+	// saves metric descriptors forwarded by broker
+	api.NewMetricDescriptorAPI(r.engine).Register(apiRouter)
+	// executes metric query against local shards for debugging, only accepts request from loopback address
+	api.NewLocalQueryAPI(r.engine, r.config.StorageBase.Query.Timeout.Duration()).Register(apiRouter)
+	if logger.IsDebug() {
+		pprof.Register(g)
+		r.log.Info("/debug/pprof is enabled")
+		g.GET("/debug/fgprof", gin.WrapH(fgprof.Handler()))
+		r.log.Info("/debug/fgprof is enabled")
+	}
 
 	r.httpServer = &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
@@ -308,20 +443,25 @@ func (r *runtime) bindRPCHandlers() {
 		r.node,
 		r.engine,
 		r.factory.taskServer,
+		r.config.StorageBase.Query.GetShardParallelism(),
 	)
 
 	r.rpcHandler = &rpcHandler{
-		writer: handler.NewWriter(r.engine),
+		writer:     handler.NewWriter(r.engine).WithDraining(r.Draining),
+		bulkLoader: handler.NewBulkLoader(r.engine),
+		exporter:   handler.NewExporter(r.engine),
 		handler: query.NewTaskHandler(
 			r.config.StorageBase.Query,
 			r.factory.taskServer,
 			leafTaskProcessor,
 			r.queryPool,
-		),
+		).WithDraining(r.Draining),
 	}
 
 	//TODO add task service ??????
 	protoStorageV1.RegisterWriteServiceServer(r.server.GetServer(), r.rpcHandler.writer)
+	protoStorageV1.RegisterBulkLoadServiceServer(r.server.GetServer(), r.rpcHandler.bulkLoader)
+	protoStorageV1.RegisterExportServiceServer(r.server.GetServer(), r.rpcHandler.exporter)
 	protoCommonV1.RegisterTaskServiceServer(r.server.GetServer(), r.rpcHandler.handler)
 }
 
@@ -339,18 +479,42 @@ func (r *runtime) nativePusher() {
 		r.config.Monitor.URL,
 		r.config.Monitor.ReportInterval.Duration(),
 		r.config.Monitor.PushTimeout.Duration(),
-		tag.KeyValues{
-			{Key: "node", Value: r.node.Indicator()},
-			{Key: "role", Value: "storage"},
-		},
+		r.globalKeyValues(),
 	)
 	go r.pusher.Start()
 }
 
+func (r *runtime) startTracing() {
+	cfg := r.config.Monitor
+	if cfg.TraceURL == "" {
+		r.log.Info("trace exporter won't start because trace-url is empty")
+		return
+	}
+	r.log.Info("trace exporter is running",
+		logger.String("url", cfg.TraceURL), logger.Any("ratio", cfg.TraceRatio))
+
+	r.traceExporter = tracing.NewOTLPExporter(
+		r.ctx,
+		cfg.TraceURL,
+		cfg.PushTimeout.Duration(),
+		r.globalKeyValues().Map(),
+	)
+	tracing.Setup(r.traceExporter, cfg.TraceRatio)
+	go r.traceExporter.Start()
+}
+
+// globalKeyValues returns the tags added to all self-monitoring metrics.
+func (r *runtime) globalKeyValues() tag.KeyValues {
+	return tag.KeyValues{
+		{Key: "node", Value: r.node.Indicator()},
+		{Key: "role", Value: "storage"},
+	}
+}
+
 func (r *runtime) systemCollector() {
 	r.log.Info("system collector is running")
 
-	go monitoring.NewSystemCollector(
+	collector := monitoring.NewSystemCollector(
 		r.ctx,
 		r.config.StorageBase.TSDB.Dir,
 		r.repo,
@@ -359,5 +523,8 @@ func (r *runtime) systemCollector() {
 			Version:    r.version,
 			Node:       r.node,
 			OnlineTime: timeutil.Now(),
-		}, "storage").Run()
+		}, "storage")
+	// report write throughput for disk-aware shard placement
+	collector.WrittenMetricsGetter = tsdb.WrittenMetrics
+	go collector.Run()
 }
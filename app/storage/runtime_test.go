@@ -33,11 +33,13 @@ import (
 	"github.com/lindb/lindb/coordinator/discovery"
 	"github.com/lindb/lindb/internal/mock"
 	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/monitoring"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/hostutil"
 	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/pkg/server"
 	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/tsdb"
 )
 
 type testStorageRuntimeSuite struct {
@@ -141,6 +143,7 @@ func (ts *testStorageRuntimeSuite) TestStorageRun_Err(c *check.C) {
 
 	registry := discovery.NewMockRegistry(ctrl)
 	s.registry = registry
+	registry.EXPECT().Deregister(gomock.Any()).Return(nil)
 	registry.EXPECT().Close().Return(fmt.Errorf("err"))
 	repo := state.NewMockRepository(ctrl)
 	s.repo = repo
@@ -148,3 +151,76 @@ func (ts *testStorageRuntimeSuite) TestStorageRun_Err(c *check.C) {
 	s.Stop()
 	assert.Error(ts.t, err)
 }
+
+func TestStorageRuntime_Drain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := NewStorageRuntime("test-version", &cfg)
+	s := storage.(*runtime)
+	engine := tsdb.NewMockEngine(ctrl)
+	registry := discovery.NewMockRegistry(ctrl)
+	s.engine = engine
+	s.registry = registry
+	// case 1: drain failure
+	engine.EXPECT().FlushAll().Return(nil)
+	registry.EXPECT().Deregister(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, storage.Drain())
+	assert.True(t, storage.Draining())
+	assert.Equal(t, server.Drained, storage.State())
+	// case 2: already drained
+	assert.NoError(t, storage.Drain())
+}
+
+func TestStorageRuntime_Reload(t *testing.T) {
+	storageCfg := cfg
+	storage := NewStorageRuntime("test-version", &storageCfg)
+	s := storage.(*runtime)
+
+	// case 1: unexpected config type
+	report, err := storage.Reload(&config.Broker{})
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 2: invalid log level
+	newCfg := storageCfg
+	newCfg.Logging.ModuleLevels = "unknown"
+	report, err = storage.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	// case 3: apply dynamic fields, reject immutable fields
+	newCfg.Logging.ModuleLevels = ""
+	newCfg.StorageBase.Query.QueryConcurrency = 10
+	newCfg.StorageBase.GRPC.Port = 1234
+	newCfg.Monitor.ReportInterval = ltoml.Duration(time.Minute)
+	report, err = storage.Reload(&newCfg)
+	assert.NoError(t, err)
+	assert.Len(t, report.Applied, 1)
+	assert.Len(t, report.Rejected, 2)
+	assert.Equal(t, 10, storageCfg.StorageBase.Query.QueryConcurrency)
+	// case 4: apply monitor interval
+	s.pusher = monitoring.NewNativeProtoPusher(s.ctx, "http://localhost:9000", time.Second, time.Second, nil)
+	report, err = storage.Reload(&newCfg)
+	assert.NoError(t, err)
+	assert.Len(t, report.Applied, 1)
+	assert.Equal(t, ltoml.Duration(time.Minute), storageCfg.Monitor.ReportInterval)
+	// case 5: query concurrency is 0, keep the old config
+	newCfg.StorageBase.Query.QueryConcurrency = 0
+	newCfg.Logging.Level = "debug"
+	report, err = storage.Reload(&newCfg)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	assert.Equal(t, 10, storageCfg.StorageBase.Query.QueryConcurrency)
+	assert.Equal(t, cfg.Logging.Level, storageCfg.Logging.Level)
+}
+
+func TestStorageRuntime_applyClusterConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := NewStorageRuntime("test-version", &cfg)
+	s := storage.(*runtime)
+	engine := tsdb.NewMockEngine(ctrl)
+	s.engine = engine
+	engine.EXPECT().SetMaxSeriesPerMetric(uint32(1000))
+	s.applyClusterConfig(models.ClusterConfig{MaxSeriesPerMetric: 1000})
+}
@@ -84,8 +84,9 @@ func serveBroker(cmd *cobra.Command, args []string) error {
 	if err := logger.InitLogger(brokerCfg.Logging, brokerLogFileName); err != nil {
 		return fmt.Errorf("init logger error: %s", err)
 	}
-
 	// start broker server
 	brokerRuntime := broker.NewBrokerRuntime(getVersion(), &brokerCfg)
+	// reloads dynamic fields without restart if config file changed or SIGHUP received
+	go watchConfig(ctx, cfg, defaultBrokerCfgFile, func() interface{} { return &config.Broker{} }, brokerRuntime)
 	return run(ctx, brokerRuntime)
 }
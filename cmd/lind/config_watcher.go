@@ -0,0 +1,94 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/server"
+)
+
+// configCheckInterval is the interval of checking if config file changed.
+const configCheckInterval = 10 * time.Second
+
+// watchConfig watches the config file, reloads the config if config file changed or SIGHUP received,
+// so that dynamic fields(query concurrency, limits, log levels, monitor interval) can be changed without restart.
+// newCfg returns an empty config of server for decoding.
+func watchConfig(ctx context.Context, cfgPath, defaultCfgPath string, newCfg func() interface{}, reloader server.Reloader) {
+	if cfgPath == "" {
+		cfgPath = defaultCfgPath
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	log := logger.GetLogger("cmd", "ConfigWatcher")
+	reload := func(reason string) {
+		cfg := newCfg()
+		if err := ltoml.DecodeToml(cfgPath, cfg); err != nil {
+			log.Warn("decode config file error", logger.String("path", cfgPath), logger.Error(err))
+			return
+		}
+		report, err := reloader.Reload(cfg)
+		if err != nil {
+			log.Warn("reload config error", logger.String("path", cfgPath), logger.Error(err))
+			return
+		}
+		if len(report.Rejected) > 0 {
+			log.Warn("config reloaded, changes of immutable fields are rejected, restart is required",
+				logger.String("reason", reason), logger.String("report", report.String()))
+			return
+		}
+		log.Info("config reloaded", logger.String("reason", reason), logger.String("report", report.String()))
+	}
+	lastModTime := modTime(cfgPath)
+	ticker := time.NewTicker(configCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			lastModTime = modTime(cfgPath)
+			reload("SIGHUP")
+		case <-ticker.C:
+			modifiedAt := modTime(cfgPath)
+			if modifiedAt.Equal(lastModTime) {
+				continue
+			}
+			lastModTime = modifiedAt
+			reload("file changed")
+		}
+	}
+}
+
+// modTime returns the modification time of file, returns zero time if file not exist.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
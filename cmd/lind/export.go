@@ -0,0 +1,449 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+)
+
+var (
+	exportStorageEndpoint string
+	exportDatabase        string
+	exportShardIDs        []int
+	exportNamespace       string
+	exportMetrics         []string
+	exportStartTime       string
+	exportEndTime         string
+	exportFormat          string
+	exportPrecision       string
+	exportOutput          string
+	exportParallelism     int
+)
+
+const exportLongText = `
+Export the raw data points of metrics within time range from storage node,
+writes them as csv/parquet(one row per field) or influx line protocol.
+
+Shards specified by --shard-ids(all shards of database hosted by storage node if not set) are exported in parallel, data of one series is sorted by timestamp,
+but the output of different shards is interleaved. Only sum and gauge fields are exported,
+the field type of line protocol is guessed by field name when importing(suffix 'sum' as delta sum).
+Data is exported from one replica, so specify the storage node which hosts the shards.
+`
+
+// newExportCmd returns a new export-cmd
+func newExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export data of metrics from storage node",
+		Long:  exportLongText,
+		RunE:  runExport,
+	}
+	exportCmd.Flags().StringVar(&exportStorageEndpoint, "storage", "localhost:2891",
+		"grpc endpoint of storage node")
+	exportCmd.Flags().StringVar(&exportDatabase, "database", "", "database name")
+	exportCmd.Flags().IntSliceVar(&exportShardIDs, "shard-ids", nil, "shards hosted by storage node, default is all shards of database")
+	exportCmd.Flags().StringVar(&exportNamespace, "namespace", constants.DefaultNamespace, "namespace of metrics")
+	exportCmd.Flags().StringSliceVar(&exportMetrics, "metrics", nil, "metric names")
+	exportCmd.Flags().StringVar(&exportStartTime, "start", "",
+		"start time, format: 2006-01-02 15:04:05, default is 1 hour before end time")
+	exportCmd.Flags().StringVar(&exportEndTime, "end", "", "end time, format: 2006-01-02 15:04:05, default is now")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "csv", "output format, csv/line/parquet")
+	exportCmd.Flags().StringVar(&exportPrecision, "precision", "ms", "precision of line protocol timestamp, ns/us/ms/s")
+	exportCmd.Flags().StringVar(&exportOutput, "output", "-", "output file, '-' means stdout")
+	exportCmd.Flags().IntVar(&exportParallelism, "parallelism", 4, "max number of shards exported concurrently")
+	return exportCmd
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if err := validateExportFlags(); err != nil {
+		return err
+	}
+	timeRange, err := parseExportTimeRange(exportStartTime, exportEndTime)
+	if err != nil {
+		return err
+	}
+	convertTimestamp, err := exportTimestampConverter(exportPrecision)
+	if err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	if exportOutput != "" && exportOutput != "-" {
+		f, err := os.Create(exportOutput)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		out = f
+	}
+	writer, err := newExportWriter(exportFormat, out, convertTimestamp)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(exportStorageEndpoint, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	ctx := newCtxWithSignals()
+	exporter := &shardExporter{
+		client:    protoStorageV1.NewExportServiceClient(conn),
+		writer:    writer,
+		database:  exportDatabase,
+		namespace: exportNamespace,
+		metrics:   exportMetrics,
+		timeRange: timeRange,
+	}
+	shardIDs := exportShardIDs
+	if len(shardIDs) == 0 {
+		if shardIDs, err = exporter.listShards(ctx); err != nil {
+			return err
+		}
+	}
+	if err := exporter.exportShards(ctx, shardIDs, exportParallelism); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported metrics: %d\n", exporter.exported)
+	return nil
+}
+
+// validateExportFlags checks the required flags of export command.
+func validateExportFlags() error {
+	if exportDatabase == "" {
+		return fmt.Errorf("database is required")
+	}
+	if len(exportMetrics) == 0 {
+		return fmt.Errorf("metrics are required")
+	}
+	return nil
+}
+
+// parseExportTimeRange parses the time range, end time is now and start time is 1 hour before end if not set.
+func parseExportTimeRange(start, end string) (timeRange timeutil.TimeRange, err error) {
+	timeRange.End = timeutil.Now()
+	if end != "" {
+		if timeRange.End, err = timeutil.ParseTimestamp(end); err != nil {
+			return timeRange, err
+		}
+	}
+	timeRange.Start = timeRange.End - timeutil.OneHour
+	if start != "" {
+		if timeRange.Start, err = timeutil.ParseTimestamp(start); err != nil {
+			return timeRange, err
+		}
+	}
+	if timeRange.Start > timeRange.End {
+		return timeRange, fmt.Errorf("start time is after end time")
+	}
+	return timeRange, nil
+}
+
+// exportTimestampConverter returns the function which converts timestamp(ms) to given precision.
+func exportTimestampConverter(precision string) (func(timestamp int64) int64, error) {
+	switch precision {
+	case "ns":
+		return func(timestamp int64) int64 { return timestamp * 1000 * 1000 }, nil
+	case "us":
+		return func(timestamp int64) int64 { return timestamp * 1000 }, nil
+	case "ms":
+		return func(timestamp int64) int64 { return timestamp }, nil
+	case "s":
+		return func(timestamp int64) int64 { return timestamp / 1000 }, nil
+	default:
+		return nil, fmt.Errorf("unknown precision: %s", precision)
+	}
+}
+
+// shardExporter exports the data of shards concurrently, writes the data into writer.
+type shardExporter struct {
+	client    protoStorageV1.ExportServiceClient
+	writer    exportWriter
+	database  string
+	namespace string
+	metrics   []string
+	timeRange timeutil.TimeRange
+
+	mutex    sync.Mutex // serializes writing of shards
+	exported int64
+}
+
+// listShards returns all shards of database hosted by storage node.
+func (e *shardExporter) listShards(ctx context.Context) ([]int, error) {
+	resp, err := e.client.ListShards(ctx, &protoStorageV1.ListShardsRequest{Database: e.database})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.ShardIDs) == 0 {
+		return nil, fmt.Errorf("database: %s has no shard in storage node", e.database)
+	}
+	shardIDs := make([]int, len(resp.ShardIDs))
+	for idx, shardID := range resp.ShardIDs {
+		shardIDs[idx] = int(shardID)
+	}
+	return shardIDs, nil
+}
+
+// exportShards exports the shards with max parallelism, returns the first error.
+func (e *shardExporter) exportShards(ctx context.Context, shardIDs []int, parallelism int) error {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	tokens := make(chan struct{}, parallelism)
+	for _, shardID := range shardIDs {
+		shardID := int32(shardID)
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			if err := e.exportShard(ctx, shardID); err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("export shard: %d error: %s", shardID, err)
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// exportShard receives the data of shard from storage node, then writes it.
+func (e *shardExporter) exportShard(ctx context.Context, shardID int32) error {
+	stream, err := e.client.Export(ctx, &protoStorageV1.ExportRequest{
+		Database:    e.database,
+		ShardID:     shardID,
+		Namespace:   e.namespace,
+		MetricNames: e.metrics,
+		StartTime:   e.timeRange.Start,
+		EndTime:     e.timeRange.End,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var metricList protoMetricsV1.MetricList
+		if err := metricList.Unmarshal(resp.Data); err != nil {
+			return err
+		}
+		if err := e.write(metricList.Metrics); err != nil {
+			return err
+		}
+	}
+}
+
+// write writes the metrics of one batch.
+func (e *shardExporter) write(metrics []*protoMetricsV1.Metric) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, metric := range metrics {
+		if err := e.writer.Write(metric); err != nil {
+			return err
+		}
+		e.exported++
+	}
+	return nil
+}
+
+// exportWriter represents the writer which writes metric with spec format.
+type exportWriter interface {
+	// Write writes the metric.
+	Write(metric *protoMetricsV1.Metric) error
+	// Flush flushes the buffered data.
+	Flush() error
+}
+
+// newExportWriter creates the writer by format.
+func newExportWriter(format string, out io.Writer, convertTimestamp func(int64) int64) (exportWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVExportWriter(out), nil
+	case "line":
+		return &lineExportWriter{out: bufio.NewWriter(out), convertTimestamp: convertTimestamp}, nil
+	case "parquet":
+		return newParquetExportWriter(out), nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// csvExportWriter writes one row for each field of metric.
+type csvExportWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// newCSVExportWriter creates the csv writer.
+func newCSVExportWriter(out io.Writer) exportWriter {
+	return &csvExportWriter{w: csv.NewWriter(out)}
+}
+
+// Write writes the fields of metric as rows: timestamp,namespace,metric,tags,field,type,value.
+func (w *csvExportWriter) Write(metric *protoMetricsV1.Metric) error {
+	if !w.headerWritten {
+		w.headerWritten = true
+		if err := w.w.Write([]string{"timestamp", "namespace", "metric", "tags", "field", "type", "value"}); err != nil {
+			return err
+		}
+	}
+	timestamp := strconv.FormatInt(metric.Timestamp, 10)
+	tagsStr := exportTags(metric)
+	for _, f := range metric.SimpleFields {
+		if err := w.w.Write([]string{
+			timestamp,
+			metric.Namespace,
+			metric.Name,
+			tagsStr,
+			f.Name,
+			exportFieldType(f.Type),
+			strconv.FormatFloat(f.Value, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush flushes the buffered rows.
+func (w *csvExportWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// lineExportWriter writes metric as influx line protocol.
+type lineExportWriter struct {
+	out              *bufio.Writer
+	convertTimestamp func(timestamp int64) int64
+}
+
+// Write writes the metric as one line.
+func (w *lineExportWriter) Write(metric *protoMetricsV1.Metric) error {
+	if len(metric.SimpleFields) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString(escapeLineProtocol(metric.Name, ", "))
+	for _, kv := range metric.Tags {
+		sb.WriteByte(',')
+		sb.WriteString(escapeLineProtocol(kv.Key, ",= "))
+		sb.WriteByte('=')
+		sb.WriteString(escapeLineProtocol(kv.Value, ",= "))
+	}
+	for idx, f := range metric.SimpleFields {
+		if idx == 0 {
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(escapeLineProtocol(f.Name, ",= "))
+		sb.WriteByte('=')
+		sb.WriteString(strconv.FormatFloat(f.Value, 'f', -1, 64))
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(w.convertTimestamp(metric.Timestamp), 10))
+	sb.WriteByte('\n')
+	_, err := w.out.WriteString(sb.String())
+	return err
+}
+
+// Flush flushes the buffered lines.
+func (w *lineExportWriter) Flush() error {
+	return w.out.Flush()
+}
+
+// escapeLineProtocol escapes the special chars with backslash.
+func escapeLineProtocol(s, chars string) string {
+	if !strings.ContainsAny(s, chars) {
+		return s
+	}
+	var sb strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune(chars, c) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// exportTags joins the tags of metric as k1=v1,k2=v2.
+func exportTags(metric *protoMetricsV1.Metric) string {
+	tags := make([]string, len(metric.Tags))
+	for idx, kv := range metric.Tags {
+		tags[idx] = kv.Key + "=" + kv.Value
+	}
+	return strings.Join(tags, ",")
+}
+
+// exportFieldType returns the name of field type.
+func exportFieldType(fieldType protoMetricsV1.SimpleFieldType) string {
+	switch fieldType {
+	case protoMetricsV1.SimpleFieldType_DELTA_SUM:
+		return "sum"
+	case protoMetricsV1.SimpleFieldType_GAUGE:
+		return "gauge"
+	case protoMetricsV1.SimpleFieldType_FIRST:
+		return "first"
+	case protoMetricsV1.SimpleFieldType_LAST:
+		return "last"
+	case protoMetricsV1.SimpleFieldType_COUNT:
+		return "count"
+	default:
+		return "unknown"
+	}
+}
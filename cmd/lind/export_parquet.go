@@ -0,0 +1,395 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/klauspost/compress/zstd"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+// parquet file layout: magic, row groups, footer(FileMetaData with thrift compact protocol), footer length, magic.
+// each column chunk of row group has one data page, values are plain encoded and compressed by zstd,
+// all columns are required, so that the page has no repetition/definition levels.
+const (
+	parquetMagic = "PAR1"
+	// parquetRowGroupSize is the max number of rows buffered in memory for one row group.
+	parquetRowGroupSize = 64 * 1024
+	parquetCreatedBy    = "lind export"
+)
+
+// types and enums of parquet format, refer https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+const (
+	parquetTypeInt64     int32 = 2
+	parquetTypeDouble    int32 = 5
+	parquetTypeByteArray int32 = 6
+
+	parquetConvertedTypeNone            int32 = -1
+	parquetConvertedTypeUTF8            int32 = 0
+	parquetConvertedTypeTimestampMillis int32 = 9
+
+	parquetRepetitionRequired int32 = 0
+	parquetEncodingPlain      int32 = 0
+	parquetEncodingRLE        int32 = 3
+	parquetCodecZstd          int32 = 6
+	parquetPageTypeData       int32 = 0
+)
+
+// parquetColumn buffers the plain encoded values of one column for current row group.
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	values        []byte
+}
+
+// appendInt64 appends int64 value as 8 bytes little endian.
+func (c *parquetColumn) appendInt64(value int64) {
+	c.values = appendUint64(c.values, uint64(value))
+}
+
+// appendDouble appends double value as 8 bytes little endian.
+func (c *parquetColumn) appendDouble(value float64) {
+	c.values = appendUint64(c.values, math.Float64bits(value))
+}
+
+// appendString appends byte array value as 4 bytes little endian length + bytes.
+func (c *parquetColumn) appendString(value string) {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(len(value)))
+	c.values = append(c.values, buf[:]...)
+	c.values = append(c.values, value...)
+}
+
+// parquetColumnChunk represents the position of column chunk written in file.
+type parquetColumnChunk struct {
+	offset           int64 // offset of data page
+	numOfValues      int64
+	uncompressedSize int64 // page header + uncompressed page data
+	compressedSize   int64 // page header + compressed page data
+}
+
+// parquetRowGroup represents the row group written in file.
+type parquetRowGroup struct {
+	numOfRows int64
+	chunks    []parquetColumnChunk
+}
+
+// parquetExportWriter writes one row for each field of metric as parquet file,
+// the columns are same as csv: timestamp,namespace,metric,tags,field,type,value.
+type parquetExportWriter struct {
+	out       *bufio.Writer
+	encoder   *zstd.Encoder
+	offset    int64
+	columns   []*parquetColumn
+	numOfRows int // rows of current row group
+	rowGroups []parquetRowGroup
+}
+
+// newParquetExportWriter creates the parquet writer.
+func newParquetExportWriter(out io.Writer) exportWriter {
+	encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	return &parquetExportWriter{
+		out:     bufio.NewWriter(out),
+		encoder: encoder,
+		columns: []*parquetColumn{
+			{name: "timestamp", physicalType: parquetTypeInt64, convertedType: parquetConvertedTypeTimestampMillis},
+			{name: "namespace", physicalType: parquetTypeByteArray, convertedType: parquetConvertedTypeUTF8},
+			{name: "metric", physicalType: parquetTypeByteArray, convertedType: parquetConvertedTypeUTF8},
+			{name: "tags", physicalType: parquetTypeByteArray, convertedType: parquetConvertedTypeUTF8},
+			{name: "field", physicalType: parquetTypeByteArray, convertedType: parquetConvertedTypeUTF8},
+			{name: "type", physicalType: parquetTypeByteArray, convertedType: parquetConvertedTypeUTF8},
+			{name: "value", physicalType: parquetTypeDouble, convertedType: parquetConvertedTypeNone},
+		},
+	}
+}
+
+// Write buffers the fields of metric as rows, writes the row group if it is full.
+func (w *parquetExportWriter) Write(metric *protoMetricsV1.Metric) error {
+	tags := exportTags(metric)
+	for _, f := range metric.SimpleFields {
+		w.columns[0].appendInt64(metric.Timestamp)
+		w.columns[1].appendString(metric.Namespace)
+		w.columns[2].appendString(metric.Name)
+		w.columns[3].appendString(tags)
+		w.columns[4].appendString(f.Name)
+		w.columns[5].appendString(exportFieldType(f.Type))
+		w.columns[6].appendDouble(f.Value)
+		w.numOfRows++
+		if w.numOfRows >= parquetRowGroupSize {
+			if err := w.writeRowGroup(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered rows and the footer, the file is completed, so it can be called only once.
+func (w *parquetExportWriter) Flush() error {
+	if err := w.writeRowGroup(); err != nil {
+		return err
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	footer := w.encodeFileMetadata()
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	for _, data := range [][]byte{footer, length[:], []byte(parquetMagic)} {
+		if err := w.write(data); err != nil {
+			return err
+		}
+	}
+	return w.out.Flush()
+}
+
+// writeMagic writes the magic at the beginning of file.
+func (w *parquetExportWriter) writeMagic() error {
+	if w.offset > 0 {
+		return nil
+	}
+	return w.write([]byte(parquetMagic))
+}
+
+// writeRowGroup writes the buffered rows as a row group, one data page per column.
+func (w *parquetExportWriter) writeRowGroup() error {
+	if w.numOfRows == 0 {
+		return nil
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	rowGroup := parquetRowGroup{numOfRows: int64(w.numOfRows), chunks: make([]parquetColumnChunk, len(w.columns))}
+	for idx, column := range w.columns {
+		page := w.encoder.EncodeAll(column.values, nil)
+		header := encodeParquetPageHeader(int32(w.numOfRows), len(column.values), len(page))
+		rowGroup.chunks[idx] = parquetColumnChunk{
+			offset:           w.offset,
+			numOfValues:      int64(w.numOfRows),
+			uncompressedSize: int64(len(header) + len(column.values)),
+			compressedSize:   int64(len(header) + len(page)),
+		}
+		if err := w.write(header); err != nil {
+			return err
+		}
+		if err := w.write(page); err != nil {
+			return err
+		}
+		column.values = column.values[:0]
+	}
+	w.rowGroups = append(w.rowGroups, rowGroup)
+	w.numOfRows = 0
+	return nil
+}
+
+// write writes the data, then moves the offset of file.
+func (w *parquetExportWriter) write(data []byte) error {
+	n, err := w.out.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+// encodeParquetPageHeader encodes the PageHeader of data page.
+func encodeParquetPageHeader(numOfValues int32, uncompressedSize, compressedSize int) []byte {
+	w := &thriftCompactWriter{}
+	w.writeI32(1, parquetPageTypeData)
+	w.writeI32(2, int32(uncompressedSize))
+	w.writeI32(3, int32(compressedSize))
+	w.beginStruct(5) // DataPageHeader
+	w.writeI32(1, numOfValues)
+	w.writeI32(2, parquetEncodingPlain)
+	w.writeI32(3, parquetEncodingRLE)
+	w.writeI32(4, parquetEncodingRLE)
+	w.endStruct()
+	w.endStruct()
+	return w.buf
+}
+
+// encodeFileMetadata encodes the FileMetaData of footer.
+func (w *parquetExportWriter) encodeFileMetadata() []byte {
+	tw := &thriftCompactWriter{}
+	tw.writeI32(1, 1) // version
+	// schema, root element + columns
+	tw.beginList(2, thriftTypeStruct, len(w.columns)+1)
+	tw.beginListStruct()
+	tw.writeBinary(4, "schema")
+	tw.writeI32(5, int32(len(w.columns)))
+	tw.endStruct()
+	for _, column := range w.columns {
+		tw.beginListStruct()
+		tw.writeI32(1, column.physicalType)
+		tw.writeI32(3, parquetRepetitionRequired)
+		tw.writeBinary(4, column.name)
+		if column.convertedType != parquetConvertedTypeNone {
+			tw.writeI32(6, column.convertedType)
+		}
+		tw.endStruct()
+	}
+	var numOfRows int64
+	for _, rowGroup := range w.rowGroups {
+		numOfRows += rowGroup.numOfRows
+	}
+	tw.writeI64(3, numOfRows)
+	tw.beginList(4, thriftTypeStruct, len(w.rowGroups))
+	for _, rowGroup := range w.rowGroups {
+		tw.beginListStruct()
+		tw.beginList(1, thriftTypeStruct, len(rowGroup.chunks))
+		var totalSize int64
+		for idx, chunk := range rowGroup.chunks {
+			column := w.columns[idx]
+			totalSize += chunk.uncompressedSize
+			tw.beginListStruct() // ColumnChunk
+			tw.writeI64(2, chunk.offset)
+			tw.beginStruct(3) // ColumnMetaData
+			tw.writeI32(1, column.physicalType)
+			tw.beginList(2, thriftTypeI32, 1)
+			tw.writeListI32(parquetEncodingPlain)
+			tw.beginList(3, thriftTypeBinary, 1)
+			tw.writeListBinary(column.name)
+			tw.writeI32(4, parquetCodecZstd)
+			tw.writeI64(5, chunk.numOfValues)
+			tw.writeI64(6, chunk.uncompressedSize)
+			tw.writeI64(7, chunk.compressedSize)
+			tw.writeI64(9, chunk.offset)
+			tw.endStruct()
+			tw.endStruct()
+		}
+		tw.writeI64(2, totalSize)
+		tw.writeI64(3, rowGroup.numOfRows)
+		tw.endStruct()
+	}
+	tw.writeBinary(6, parquetCreatedBy)
+	tw.endStruct()
+	return tw.buf
+}
+
+// types of thrift compact protocol used by parquet metadata.
+const (
+	thriftTypeI32    byte = 5
+	thriftTypeI64    byte = 6
+	thriftTypeBinary byte = 8
+	thriftTypeList   byte = 9
+	thriftTypeStruct byte = 12
+)
+
+// thriftCompactWriter encodes the structs with thrift compact protocol,
+// fields must be written in ascending order of field id, the top level struct is begun implicitly.
+type thriftCompactWriter struct {
+	buf     []byte
+	lastID  int16   // last field id of current struct
+	lastIDs []int16 // last field ids of enclosing structs
+}
+
+// writeI32 writes the i32 field.
+func (w *thriftCompactWriter) writeI32(id int16, value int32) {
+	w.writeFieldHeader(id, thriftTypeI32)
+	w.writeVarint(int64(value))
+}
+
+// writeI64 writes the i64 field.
+func (w *thriftCompactWriter) writeI64(id int16, value int64) {
+	w.writeFieldHeader(id, thriftTypeI64)
+	w.writeVarint(value)
+}
+
+// writeBinary writes the binary field.
+func (w *thriftCompactWriter) writeBinary(id int16, value string) {
+	w.writeFieldHeader(id, thriftTypeBinary)
+	w.writeListBinary(value)
+}
+
+// beginStruct begins the struct field, must be ended by endStruct.
+func (w *thriftCompactWriter) beginStruct(id int16) {
+	w.writeFieldHeader(id, thriftTypeStruct)
+	w.beginListStruct()
+}
+
+// beginList writes the header of list field, then the elements are written by writeList*/beginListStruct.
+func (w *thriftCompactWriter) beginList(id int16, elemType byte, size int) {
+	w.writeFieldHeader(id, thriftTypeList)
+	if size < 15 {
+		w.buf = append(w.buf, byte(size)<<4|elemType)
+		return
+	}
+	w.buf = append(w.buf, 0xf0|elemType)
+	w.writeUvarint(uint64(size))
+}
+
+// beginListStruct begins the struct element of list, must be ended by endStruct.
+func (w *thriftCompactWriter) beginListStruct() {
+	w.lastIDs = append(w.lastIDs, w.lastID)
+	w.lastID = 0
+}
+
+// endStruct writes the stop field of struct.
+func (w *thriftCompactWriter) endStruct() {
+	w.buf = append(w.buf, 0)
+	if n := len(w.lastIDs); n > 0 {
+		w.lastID = w.lastIDs[n-1]
+		w.lastIDs = w.lastIDs[:n-1]
+	}
+}
+
+// writeListI32 writes the i32 element of list.
+func (w *thriftCompactWriter) writeListI32(value int32) {
+	w.writeVarint(int64(value))
+}
+
+// writeListBinary writes the binary element of list.
+func (w *thriftCompactWriter) writeListBinary(value string) {
+	w.writeUvarint(uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+// writeFieldHeader writes the field header with id delta if possible.
+func (w *thriftCompactWriter) writeFieldHeader(id int16, fieldType byte) {
+	if delta := id - w.lastID; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|fieldType)
+	} else {
+		w.buf = append(w.buf, fieldType)
+		w.writeVarint(int64(id))
+	}
+	w.lastID = id
+}
+
+// writeVarint writes the zigzag varint.
+func (w *thriftCompactWriter) writeVarint(value int64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], value)
+	w.buf = append(w.buf, buf[:n]...)
+}
+
+// writeUvarint writes the unsigned varint.
+func (w *thriftCompactWriter) writeUvarint(value uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], value)
+	w.buf = append(w.buf, buf[:n]...)
+}
+
+// appendUint64 appends the value as 8 bytes little endian.
+func appendUint64(buf []byte, value uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], value)
+	return append(buf, b[:]...)
+}
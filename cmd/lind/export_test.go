@@ -0,0 +1,295 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+)
+
+func TestValidateExportFlags(t *testing.T) {
+	defer func() {
+		exportDatabase = ""
+		exportShardIDs = nil
+		exportMetrics = nil
+	}()
+	// case 1: database is empty
+	assert.EqualError(t, validateExportFlags(), "database is required")
+	// case 2: metrics are empty
+	exportDatabase = "db"
+	exportShardIDs = []int{1}
+	assert.EqualError(t, validateExportFlags(), "metrics are required")
+	// case 3: valid flags
+	exportMetrics = []string{"cpu"}
+	assert.NoError(t, validateExportFlags())
+	// case 4: all shards if shard ids are empty
+	exportShardIDs = nil
+	assert.NoError(t, validateExportFlags())
+}
+
+func TestParseExportTimeRange(t *testing.T) {
+	start, _ := timeutil.ParseTimestamp("2021-08-01 10:00:00")
+	end, _ := timeutil.ParseTimestamp("2021-08-01 12:00:00")
+	// case 1: start and end time
+	timeRange, err := parseExportTimeRange("2021-08-01 10:00:00", "2021-08-01 12:00:00")
+	assert.NoError(t, err)
+	assert.Equal(t, timeutil.TimeRange{Start: start, End: end}, timeRange)
+	// case 2: start time is 1 hour before end if not set
+	timeRange, err = parseExportTimeRange("", "2021-08-01 12:00:00")
+	assert.NoError(t, err)
+	assert.Equal(t, timeutil.TimeRange{Start: end - timeutil.OneHour, End: end}, timeRange)
+	// case 3: bad start time
+	_, err = parseExportTimeRange("2021/08/01", "2021-08-01 12:00:00")
+	assert.Error(t, err)
+	// case 4: bad end time
+	_, err = parseExportTimeRange("", "12:00")
+	assert.Error(t, err)
+	// case 5: start time is after end time
+	_, err = parseExportTimeRange("2021-08-01 12:00:01", "2021-08-01 12:00:00")
+	assert.Error(t, err)
+	// case 6: end time is now if not set
+	now := timeutil.Now()
+	timeRange, err = parseExportTimeRange("", "")
+	assert.NoError(t, err)
+	assert.True(t, timeRange.End >= now)
+	assert.Equal(t, timeRange.End-timeutil.OneHour, timeRange.Start)
+}
+
+func TestExportTimestampConverter(t *testing.T) {
+	examples := []struct {
+		precision string
+		timestamp int64
+	}{
+		{"ns", 1500 * 1000 * 1000},
+		{"us", 1500 * 1000},
+		{"ms", 1500},
+		{"s", 1},
+	}
+	for _, example := range examples {
+		convert, err := exportTimestampConverter(example.precision)
+		assert.NoError(t, err)
+		assert.Equal(t, example.timestamp, convert(1500))
+	}
+	convert, err := exportTimestampConverter("m")
+	assert.Error(t, err)
+	assert.Nil(t, convert)
+}
+
+func TestNewExportWriter(t *testing.T) {
+	for _, format := range []string{"csv", "line", "parquet"} {
+		w, err := newExportWriter(format, &bytes.Buffer{}, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, w)
+	}
+	w, err := newExportWriter("json", &bytes.Buffer{}, nil)
+	assert.Error(t, err)
+	assert.Nil(t, w)
+}
+
+func TestCSVExportWriter(t *testing.T) {
+	examples := []struct {
+		metrics []*protoMetricsV1.Metric
+		output  string
+	}{
+		// only header for metric without field
+		{
+			output: "timestamp,namespace,metric,tags,field,type,value\n",
+			metrics: []*protoMetricsV1.Metric{
+				{Name: "cpu", Namespace: "ns", Timestamp: 1000},
+			},
+		},
+		// one row per field
+		{
+			output: "timestamp,namespace,metric,tags,field,type,value\n" +
+				"1000,ns,cpu,host=h1,usage,gauge,1.5\n" +
+				"1000,ns,cpu,host=h1,count,sum,10\n",
+			metrics: []*protoMetricsV1.Metric{
+				{
+					Name:      "cpu",
+					Namespace: "ns",
+					Timestamp: 1000,
+					Tags:      []*protoMetricsV1.KeyValue{{Key: "host", Value: "h1"}},
+					SimpleFields: []*protoMetricsV1.SimpleField{
+						{Name: "usage", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1.5},
+						{Name: "count", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 10},
+					},
+				},
+			},
+		},
+		// quote tags with comma, header written once
+		{
+			output: "timestamp,namespace,metric,tags,field,type,value\n" +
+				"1000,ns,cpu,\"host=h1,ip=1.1.1.1\",last,last,2\n" +
+				"2000,ns,mem,,first,first,3\n",
+			metrics: []*protoMetricsV1.Metric{
+				{
+					Name:      "cpu",
+					Namespace: "ns",
+					Timestamp: 1000,
+					Tags: []*protoMetricsV1.KeyValue{
+						{Key: "host", Value: "h1"},
+						{Key: "ip", Value: "1.1.1.1"},
+					},
+					SimpleFields: []*protoMetricsV1.SimpleField{
+						{Name: "last", Type: protoMetricsV1.SimpleFieldType_LAST, Value: 2},
+					},
+				},
+				{
+					Name:      "mem",
+					Namespace: "ns",
+					Timestamp: 2000,
+					SimpleFields: []*protoMetricsV1.SimpleField{
+						{Name: "first", Type: protoMetricsV1.SimpleFieldType_FIRST, Value: 3},
+					},
+				},
+			},
+		},
+	}
+	for _, example := range examples {
+		var buf bytes.Buffer
+		w := newCSVExportWriter(&buf)
+		for _, metric := range example.metrics {
+			assert.NoError(t, w.Write(metric))
+		}
+		assert.NoError(t, w.Flush())
+		assert.Equal(t, example.output, buf.String())
+	}
+}
+
+func TestLineExportWriter(t *testing.T) {
+	var buf bytes.Buffer
+	convert, _ := exportTimestampConverter("s")
+	w, err := newExportWriter("line", &buf, convert)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(&protoMetricsV1.Metric{Name: "cpu", Timestamp: 1000}))
+	assert.NoError(t, w.Write(&protoMetricsV1.Metric{
+		Name:      "cpu load",
+		Timestamp: 2000,
+		Tags:      []*protoMetricsV1.KeyValue{{Key: "host", Value: "h=1"}},
+		SimpleFields: []*protoMetricsV1.SimpleField{
+			{Name: "usage", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1.5},
+			{Name: "count", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 10},
+		},
+	}))
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "cpu\\ load,host=h\\=1 usage=1.5,count=10 2\n", buf.String())
+}
+
+func TestShardExporter_listShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := protoStorageV1.NewMockExportServiceClient(ctrl)
+	exporter := &shardExporter{client: client, database: "db"}
+	req := &protoStorageV1.ListShardsRequest{Database: "db"}
+	// case 1: list shards err
+	client.EXPECT().ListShards(gomock.Any(), req).Return(nil, fmt.Errorf("err"))
+	shardIDs, err := exporter.listShards(context.TODO())
+	assert.Error(t, err)
+	assert.Nil(t, shardIDs)
+	// case 2: database without shard
+	client.EXPECT().ListShards(gomock.Any(), req).Return(&protoStorageV1.ListShardsResponse{}, nil)
+	shardIDs, err = exporter.listShards(context.TODO())
+	assert.Error(t, err)
+	assert.Nil(t, shardIDs)
+	// case 3: all shards of database
+	client.EXPECT().ListShards(gomock.Any(), req).Return(&protoStorageV1.ListShardsResponse{ShardIDs: []int32{1, 3}}, nil)
+	shardIDs, err = exporter.listShards(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, shardIDs)
+}
+
+func TestParquetExportWriter(t *testing.T) {
+	// empty file
+	var buf bytes.Buffer
+	w := newParquetExportWriter(&buf)
+	assert.NoError(t, w.Write(&protoMetricsV1.Metric{Name: "cpu", Timestamp: 1000}))
+	assert.NoError(t, w.Flush())
+	data := buf.Bytes()
+	assert.Equal(t, parquetMagic, string(data[:4]))
+	assert.Equal(t, parquetMagic, string(data[len(data)-4:]))
+	assert.Equal(t, len(data)-12, int(binary.LittleEndian.Uint32(data[len(data)-8:])))
+
+	// rows are split into row groups
+	buf.Reset()
+	w = newParquetExportWriter(&buf)
+	numOfRows := parquetRowGroupSize + 10
+	for i := 0; i < numOfRows; i++ {
+		assert.NoError(t, w.Write(&protoMetricsV1.Metric{
+			Name:      "cpu",
+			Namespace: "ns",
+			Timestamp: int64(i),
+			Tags:      []*protoMetricsV1.KeyValue{{Key: "host", Value: "h1"}},
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "usage", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1.5},
+			},
+		}))
+	}
+	assert.NoError(t, w.Flush())
+	data = buf.Bytes()
+	assert.Equal(t, parquetMagic, string(data[:4]))
+	assert.Equal(t, parquetMagic, string(data[len(data)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	rowGroups := w.(*parquetExportWriter).rowGroups
+	assert.Len(t, rowGroups, 2)
+	assert.Equal(t, int64(parquetRowGroupSize), rowGroups[0].numOfRows)
+	assert.Equal(t, int64(10), rowGroups[1].numOfRows)
+	lastChunk := rowGroups[1].chunks[6]
+	assert.Equal(t, len(data)-8-footerLen, int(lastChunk.offset+lastChunk.compressedSize))
+	// timestamp column of last row group
+	chunk := rowGroups[1].chunks[0]
+	headerLen := chunk.uncompressedSize - 8*chunk.numOfValues
+	decoder, _ := zstd.NewReader(nil)
+	values, err := decoder.DecodeAll(data[chunk.offset+headerLen:chunk.offset+chunk.compressedSize], nil)
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, uint64(parquetRowGroupSize+i), binary.LittleEndian.Uint64(values[i*8:]))
+	}
+}
+
+func TestThriftCompactWriter(t *testing.T) {
+	w := &thriftCompactWriter{}
+	w.writeI32(1, -1)
+	w.writeI64(20, 1)
+	w.beginStruct(21)
+	w.writeBinary(1, "a")
+	w.endStruct()
+	w.beginList(22, thriftTypeI32, 15)
+	for i := 0; i < 15; i++ {
+		w.writeListI32(0)
+	}
+	w.endStruct()
+	assert.Equal(t, []byte{
+		0x15, 0x01, // field 1, i32 -1
+		0x06, 0x28, 0x02, // field 20 with long form, i64 1
+		0x1c, 0x18, 0x01, 'a', 0x00, // field 21, struct{1: "a"}
+		0x19, 0xf5, 0x0f, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, // field 22, list of 15 i32
+		0x00,
+	}, w.buf)
+}
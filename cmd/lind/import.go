@@ -0,0 +1,256 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cespare/xxhash"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/ingestion/influx"
+	"github.com/lindb/lindb/pkg/hashring"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+	"github.com/lindb/lindb/series/tag"
+)
+
+var (
+	importStorageEndpoint string
+	importDatabase        string
+	importNumOfShards     int32
+	importShardRouting    string
+	importShardIDs        []int
+	importNamespace       string
+	importPrecision       string
+	importBatchSize       int
+	importDryRun          bool
+)
+
+const importLongText = `
+Bulk load historical data(influx line protocol) into sealed data files of storage node directly,
+bypasses the write path(replication channel, write ahead log and memory database).
+
+Data should be sorted by timestamp, sharding is same as the broker(hash of tags mod number of shards,
+or consistent hash ring if shard routing of database is hashRing),
+only data of the shards specified by --shard-ids is loaded, all shards are loaded if not specified.
+Replication is bypassed also, so import the data into each replica of the shard.
+Use --dry-run to check the data files(parse errors, sharding and batching) without sending them.
+`
+
+// newImportCmd returns a new import-cmd
+func newImportCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import [files]",
+		Short: "Bulk load historical data into storage node",
+		Long:  importLongText,
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runImport,
+	}
+	importCmd.Flags().StringVar(&importStorageEndpoint, "storage", "localhost:2891",
+		"grpc endpoint of storage node")
+	importCmd.Flags().StringVar(&importDatabase, "database", "", "database name")
+	importCmd.Flags().Int32Var(&importNumOfShards, "num-of-shards", 1, "number of shards of database")
+	importCmd.Flags().StringVar(&importShardRouting, "shard-routing", option.ShardRoutingModulo,
+		"shard routing of database, modulo/hashRing")
+	importCmd.Flags().IntSliceVar(&importShardIDs, "shard-ids", nil,
+		"shards hosted by storage node, all shards if not specified")
+	importCmd.Flags().StringVar(&importNamespace, "namespace", constants.DefaultNamespace, "namespace of metrics")
+	importCmd.Flags().StringVar(&importPrecision, "precision", "ms", "precision of timestamp, ns/us/ms/s/m/h")
+	importCmd.Flags().IntVar(&importBatchSize, "batch-size", 1000, "max number of metrics per request")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false,
+		"parse and batch data without sending it to storage node")
+	return importCmd
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	if err := validateImportFlags(); err != nil {
+		return err
+	}
+	if importDryRun {
+		importer := newBulkImporter(nil, importDatabase, importShardRouting, importNumOfShards, importShardIDs,
+			importBatchSize)
+		if err := importFiles(importer, args); err != nil {
+			return err
+		}
+		fmt.Printf("dry run, parsed metrics: %d, requests: %d, skipped metrics: %d\n",
+			importer.sent, importer.requests, importer.skipped)
+		return nil
+	}
+	conn, err := grpc.Dial(importStorageEndpoint, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	stream, err := protoStorageV1.NewBulkLoadServiceClient(conn).Load(newCtxWithSignals())
+	if err != nil {
+		return err
+	}
+	importer := newBulkImporter(stream, importDatabase, importShardRouting, importNumOfShards, importShardIDs,
+		importBatchSize)
+	if err := importFiles(importer, args); err != nil {
+		return err
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("loaded metrics: %d, sealed families: %d, skipped metrics: %d\n",
+		resp.LoadedMetrics, resp.SealedFamilies, importer.skipped)
+	return nil
+}
+
+// validateImportFlags checks the required flags of import command.
+func validateImportFlags() error {
+	if importDatabase == "" {
+		return fmt.Errorf("database is required")
+	}
+	if importNumOfShards <= 0 {
+		return fmt.Errorf("number of shards should be greater than 0")
+	}
+	if importShardRouting != option.ShardRoutingModulo && importShardRouting != option.ShardRoutingHashRing {
+		return fmt.Errorf("unknown shard routing: %s", importShardRouting)
+	}
+	if importBatchSize <= 0 {
+		return fmt.Errorf("batch size should be greater than 0")
+	}
+	return nil
+}
+
+// importFiles imports the files in order, then sends all pending batches.
+func importFiles(importer *bulkImporter, files []string) error {
+	for _, file := range files {
+		if err := importFile(importer, file); err != nil {
+			return fmt.Errorf("import file: %s error: %s", file, err)
+		}
+	}
+	return importer.flush()
+}
+
+// importFile reads metrics from file, then sends them to storage node.
+func importFile(importer *bulkImporter, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return influx.Read(f, importNamespace, importPrecision, timeutil.Millisecond, importer.add)
+}
+
+// bulkImporter batches the metrics of each shard, sends the batch if full.
+// If stream is nil(dry run), batches are counted but not sent.
+type bulkImporter struct {
+	stream    protoStorageV1.BulkLoadService_LoadClient
+	database  string
+	ring      hashring.Router // same as broker, maps series to shard
+	shardIDs  map[int32]struct{}
+	batchSize int
+	batches   map[int32]*protoMetricsV1.MetricList
+	skipped   int64
+	sent      int64 // num. of metrics sent
+	requests  int64 // num. of requests sent
+}
+
+// newBulkImporter creates a bulk importer.
+func newBulkImporter(
+	stream protoStorageV1.BulkLoadService_LoadClient,
+	database string,
+	shardRouting string,
+	numOfShards int32,
+	shardIDs []int,
+	batchSize int,
+) *bulkImporter {
+	importer := &bulkImporter{
+		stream:    stream,
+		database:  database,
+		ring:      hashring.NewRouter(shardRouting == option.ShardRoutingHashRing, numOfShards),
+		batchSize: batchSize,
+		batches:   make(map[int32]*protoMetricsV1.MetricList),
+	}
+	if len(shardIDs) > 0 {
+		importer.shardIDs = make(map[int32]struct{})
+		for _, shardID := range shardIDs {
+			importer.shardIDs[int32(shardID)] = struct{}{}
+		}
+	}
+	return importer
+}
+
+// add adds the metric into the batch of its shard.
+func (i *bulkImporter) add(metric *protoMetricsV1.Metric) error {
+	// same as broker, storage side will use this hash for write
+	metric.TagsHash = xxhash.Sum64String(tag.ConcatKeyValues(metric.Tags))
+	shardID := i.ring.Get(metric.TagsHash)
+	if i.shardIDs != nil {
+		if _, ok := i.shardIDs[shardID]; !ok {
+			i.skipped++
+			return nil
+		}
+	}
+	batch, ok := i.batches[shardID]
+	if !ok {
+		batch = &protoMetricsV1.MetricList{}
+		i.batches[shardID] = batch
+	}
+	batch.Metrics = append(batch.Metrics, metric)
+	if len(batch.Metrics) >= i.batchSize {
+		return i.send(shardID, batch)
+	}
+	return nil
+}
+
+// flush sends all pending batches.
+func (i *bulkImporter) flush() error {
+	for shardID, batch := range i.batches {
+		if len(batch.Metrics) == 0 {
+			continue
+		}
+		if err := i.send(shardID, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send sends the batch to storage node, then resets it.
+func (i *bulkImporter) send(shardID int32, batch *protoMetricsV1.MetricList) error {
+	data, err := batch.Marshal()
+	if err != nil {
+		return err
+	}
+	i.sent += int64(len(batch.Metrics))
+	i.requests++
+	batch.Metrics = batch.Metrics[:0]
+	if i.stream == nil {
+		return nil
+	}
+	return i.stream.Send(&protoStorageV1.BulkLoadRequest{
+		Database: i.database,
+		ShardID:  shardID,
+		Data:     data,
+	})
+}
@@ -0,0 +1,197 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/hashring"
+	"github.com/lindb/lindb/pkg/option"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+)
+
+const importTestData = `cpu,host=h1 usage=1 1628000000000
+cpu,host=h2 usage=2 1628000001000
+cpu,host=h3 usage=3 1628000002000
+`
+
+func writeImportFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "data.txt")
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func resetImportFlags() {
+	importStorageEndpoint = "localhost:2891"
+	importDatabase = ""
+	importNumOfShards = 1
+	importShardRouting = option.ShardRoutingModulo
+	importShardIDs = nil
+	importNamespace = constants.DefaultNamespace
+	importPrecision = "ms"
+	importBatchSize = 1000
+	importDryRun = false
+}
+
+func TestValidateImportFlags(t *testing.T) {
+	defer resetImportFlags()
+	resetImportFlags()
+	// case 1: database is empty
+	assert.EqualError(t, validateImportFlags(), "database is required")
+	// case 2: number of shards is 0
+	importDatabase = "db"
+	importNumOfShards = 0
+	assert.EqualError(t, validateImportFlags(), "number of shards should be greater than 0")
+	// case 3: batch size is 0
+	importNumOfShards = 3
+	importBatchSize = 0
+	assert.EqualError(t, validateImportFlags(), "batch size should be greater than 0")
+	// case 4: valid flags
+	importBatchSize = 100
+	assert.NoError(t, validateImportFlags())
+	// case 5: shard routing
+	importShardRouting = option.ShardRoutingHashRing
+	assert.NoError(t, validateImportFlags())
+	importShardRouting = "unknown"
+	assert.EqualError(t, validateImportFlags(), "unknown shard routing: unknown")
+}
+
+func TestImportFiles_parseError(t *testing.T) {
+	defer resetImportFlags()
+	resetImportFlags()
+	examples := [][]string{
+		// file not exist
+		{filepath.Join(t.TempDir(), "not_exist.txt")},
+		// line without field
+		{writeImportFile(t, "cpu,host=h1 1628000000000\n")},
+		// bad field value
+		{writeImportFile(t, "cpu,host=h1 usage=abc 1628000000000\n")},
+		// bad timestamp
+		{writeImportFile(t, "cpu,host=h1 usage=1 abc\n")},
+		// bad line in second file
+		{writeImportFile(t, importTestData), writeImportFile(t, "cpu,host=h1\n")},
+	}
+	for _, files := range examples {
+		importer := newBulkImporter(nil, "db", option.ShardRoutingModulo, 1, nil, 10)
+		assert.Error(t, importFiles(importer, files))
+	}
+}
+
+func TestBulkImporter_batching(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stream := protoStorageV1.NewMockBulkLoadService_LoadClient(ctrl)
+	importer := newBulkImporter(stream, "db", option.ShardRoutingModulo, 1, nil, 2)
+	var sizes []int
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *protoStorageV1.BulkLoadRequest) error {
+		assert.Equal(t, "db", req.Database)
+		assert.Equal(t, int32(0), req.ShardID)
+		var metricList protoMetricsV1.MetricList
+		assert.NoError(t, metricList.Unmarshal(req.Data))
+		sizes = append(sizes, len(metricList.Metrics))
+		return nil
+	}).Times(3)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, importer.add(&protoMetricsV1.Metric{
+			Name:         "cpu",
+			Timestamp:    int64(i),
+			Tags:         []*protoMetricsV1.KeyValue{{Key: "host", Value: fmt.Sprintf("h%d", i)}},
+			SimpleFields: []*protoMetricsV1.SimpleField{{Name: "usage", Value: 1}},
+		}))
+	}
+	// send full batch when adding
+	assert.Equal(t, []int{2, 2}, sizes)
+	// send pending batch when flushing
+	assert.NoError(t, importer.flush())
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.Equal(t, int64(5), importer.sent)
+	assert.Equal(t, int64(3), importer.requests)
+	// nothing to flush
+	assert.NoError(t, importer.flush())
+
+	// send failure
+	stream.EXPECT().Send(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.NoError(t, importer.add(&protoMetricsV1.Metric{Name: "cpu"}))
+	assert.Error(t, importer.add(&protoMetricsV1.Metric{Name: "cpu"}))
+}
+
+func TestBulkImporter_skipShards(t *testing.T) {
+	importer := newBulkImporter(nil, "db", option.ShardRoutingModulo, 3, []int{0}, 100)
+	for i := 0; i < 30; i++ {
+		assert.NoError(t, importer.add(&protoMetricsV1.Metric{
+			Name: "cpu",
+			Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: fmt.Sprintf("h%d", i)}},
+		}))
+	}
+	assert.NoError(t, importer.flush())
+	// metrics of other shards are skipped
+	assert.True(t, importer.skipped > 0)
+	assert.Equal(t, int64(30), importer.sent+importer.skipped)
+	for shardID := range importer.batches {
+		assert.Equal(t, int32(0), shardID)
+	}
+}
+
+func TestBulkImporter_shardRouting(t *testing.T) {
+	modulo := newBulkImporter(nil, "db", option.ShardRoutingModulo, 3, nil, 100)
+	ring := newBulkImporter(nil, "db", option.ShardRoutingHashRing, 3, nil, 100)
+	metric := &protoMetricsV1.Metric{
+		Name: "cpu",
+		Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: "h1"}},
+	}
+	assert.NoError(t, modulo.add(metric))
+	// same as broker, modulo routing by default
+	assert.Contains(t, modulo.batches, int32(metric.TagsHash%3))
+	assert.NoError(t, ring.add(metric))
+	assert.Contains(t, ring.batches, ring.ring.Get(metric.TagsHash))
+	assert.Equal(t, 0, modulo.ring.VirtualNodes())
+	assert.Equal(t, hashring.DefaultVirtualNodes, ring.ring.VirtualNodes())
+}
+
+func TestRunImport_dryRun(t *testing.T) {
+	defer resetImportFlags()
+	resetImportFlags()
+	importDatabase = "db"
+	importBatchSize = 2
+	importDryRun = true
+	// storage node is not connected when dry run
+	importStorageEndpoint = "unknown:0"
+	file := writeImportFile(t, importTestData)
+	assert.NoError(t, runImport(nil, []string{file, file}))
+
+	importer := newBulkImporter(nil, "db", option.ShardRoutingModulo, 1, nil, 2)
+	assert.NoError(t, importFiles(importer, []string{file, file}))
+	assert.Equal(t, int64(6), importer.sent)
+	assert.Equal(t, int64(3), importer.requests)
+	assert.Equal(t, int64(0), importer.skipped)
+
+	// parse error when dry run
+	assert.Error(t, runImport(nil, []string{writeImportFile(t, "cpu,host=h1\n")}))
+	// bad flags
+	importDatabase = ""
+	assert.Error(t, runImport(nil, []string{file}))
+}
@@ -52,6 +52,9 @@ func init() {
 		newStorageCmd(),
 		newBrokerCmd(),
 		newStandaloneCmd(),
+		newImportCmd(),
+		newExportCmd(),
+		newVerifyCmd(),
 		cli.NewCLICmd(),
 	)
 }
@@ -88,9 +88,10 @@ func serveStandalone(cmd *cobra.Command, args []string) error {
 	if err := logger.InitLogger(standaloneCfg.Logging, standaloneLogFileName); err != nil {
 		return fmt.Errorf("init logger error: %s", err)
 	}
-
 	// run cluster as standalone mode
 	runtime := standalone.NewStandaloneRuntime(getVersion(), &standaloneCfg)
+	// reloads dynamic fields without restart if config file changed or SIGHUP received
+	go watchConfig(ctx, cfg, defaultStandaloneCfgFile, func() interface{} { return &config.Standalone{} }, runtime)
 	if err := run(ctx, runtime); err != nil {
 		return err
 	}
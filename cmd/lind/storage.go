@@ -83,9 +83,10 @@ func serveStorage(cmd *cobra.Command, args []string) error {
 	if err := logger.InitLogger(storageCfg.Logging, storageLogFileName); err != nil {
 		return fmt.Errorf("init logger error: %s", err)
 	}
-
 	// start storage server
 	storageRuntime := storage.NewStorageRuntime(getVersion(), &storageCfg)
+	// reloads dynamic fields without restart if config file changed or SIGHUP received
+	go watchConfig(ctx, cfg, defaultStorageCfgFile, func() interface{} { return &config.Storage{} }, storageRuntime)
 	if err := run(ctx, storageRuntime); err != nil {
 		return err
 	}
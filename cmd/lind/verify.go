@@ -0,0 +1,262 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+var (
+	verifyBrokerEndpoint string
+	verifyToken          string
+	verifyCluster        string
+	verifyDatabase       string
+	verifyStartTime      string
+	verifyEndTime        string
+	verifyRepair         bool
+	verifyTimeout        time.Duration
+)
+
+const verifyLongText = `
+Verify the data consistency between replicas of database, each storage node computes the checksum/series count
+of every family of its replicas within time range, then master compares them and reports the divergent families.
+
+The checksum is computed from the raw data points, so recent data which is still replicating may be reported
+as divergence, verify the data before the end time which is some minutes ago.
+With --repair, the families which are only missing on some replicas(others have same data) are copied from
+the replica which has the data, other divergences need to be fixed manually.
+`
+
+// newVerifyCmd returns a new verify-cmd
+func newVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the data consistency between replicas of database",
+		Long:  verifyLongText,
+		RunE:  runVerify,
+	}
+	verifyCmd.Flags().StringVar(&verifyBrokerEndpoint, "endpoint", "http://localhost:9000", "endpoint of any broker")
+	verifyCmd.Flags().StringVar(&verifyToken, "token", "", "token of admin user for authorization")
+	verifyCmd.Flags().StringVar(&verifyCluster, "cluster", "", "storage cluster name")
+	verifyCmd.Flags().StringVar(&verifyDatabase, "database", "", "database name")
+	verifyCmd.Flags().StringVar(&verifyStartTime, "start", "",
+		"start time, format: 2006-01-02 15:04:05, default is 1 day before end time")
+	verifyCmd.Flags().StringVar(&verifyEndTime, "end", "", "end time, format: 2006-01-02 15:04:05, default is now")
+	verifyCmd.Flags().BoolVar(&verifyRepair, "repair", false, "copy the families which are missing on some replicas")
+	verifyCmd.Flags().DurationVar(&verifyTimeout, "timeout", 30*time.Minute, "max time waiting for verify completed")
+	return verifyCmd
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if verifyCluster == "" {
+		return fmt.Errorf("cluster is required")
+	}
+	if verifyDatabase == "" {
+		return fmt.Errorf("database is required")
+	}
+	timeRange, err := parseVerifyTimeRange(verifyStartTime, verifyEndTime)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(newCtxWithSignals(), verifyTimeout)
+	defer cancel()
+
+	client := newVerifyClient(verifyBrokerEndpoint, verifyToken)
+	name, err := client.verify(ctx, verifyCluster, verifyDatabase, timeRange)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "replica verify task: %s submitted, waiting for storage nodes\n", name)
+	report, err := client.waitReport(ctx, verifyCluster, verifyDatabase, name, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	printVerifyReport(cmd.OutOrStdout(), report)
+	if verifyRepair {
+		report, err = client.repair(ctx, verifyCluster, verifyDatabase, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "submitted repair tasks: %d\n", report.RepairTasks)
+	}
+	if len(report.Failures) > 0 || len(report.Divergences) > 0 {
+		return fmt.Errorf("replicas of database: %s are inconsistent", verifyDatabase)
+	}
+	return nil
+}
+
+// parseVerifyTimeRange parses the time range, end time is now and start time is 1 day before end if not set.
+func parseVerifyTimeRange(start, end string) (timeRange timeutil.TimeRange, err error) {
+	timeRange.End = timeutil.Now()
+	if end != "" {
+		if timeRange.End, err = timeutil.ParseTimestamp(end); err != nil {
+			return timeRange, err
+		}
+	}
+	timeRange.Start = timeRange.End - timeutil.OneDay
+	if start != "" {
+		if timeRange.Start, err = timeutil.ParseTimestamp(start); err != nil {
+			return timeRange, err
+		}
+	}
+	if timeRange.Start > timeRange.End {
+		return timeRange, fmt.Errorf("start time is after end time")
+	}
+	return timeRange, nil
+}
+
+// printVerifyReport prints the divergence report.
+func printVerifyReport(w io.Writer, report *models.ReplicaVerifyReport) {
+	fmt.Fprintf(w, "verified shards: %d, families: %d, divergent families: %d\n",
+		report.Shards, report.Families, len(report.Divergences))
+	for _, failure := range report.Failures {
+		fmt.Fprintf(w, "node: %s failure: %s\n", failure.Node, failure.Error)
+	}
+	for _, divergence := range report.Divergences {
+		fmt.Fprintf(w, "shard: %d family: %s repairable: %t\n", divergence.ShardID,
+			timeutil.FormatTimestamp(divergence.FamilyTime, "2006-01-02 15:04:05"), divergence.Repairable)
+		for _, replica := range divergence.Replicas {
+			if replica.Checksum == nil {
+				fmt.Fprintf(w, "  %s: missing\n", replica.Node)
+				continue
+			}
+			fmt.Fprintf(w, "  %s: series=%d points=%d checksum=%x\n", replica.Node,
+				replica.Checksum.Series, replica.Checksum.Points, replica.Checksum.Checksum)
+		}
+	}
+}
+
+// verifyClient calls the replica verify api of broker.
+type verifyClient struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// newVerifyClient creates the replica verify api client.
+func newVerifyClient(endpoint, token string) *verifyClient {
+	u, _ := url.Parse(endpoint)
+	u.Path = path.Join(u.Path, "/api/v1")
+	return &verifyClient{
+		endpoint: u.String(),
+		token:    token,
+		client:   &http.Client{Timeout: time.Minute},
+	}
+}
+
+// verify submits the replica verify task, returns the task name.
+func (c *verifyClient) verify(ctx context.Context, cluster, database string, timeRange timeutil.TimeRange) (string, error) {
+	var result struct {
+		Name string `json:"name"`
+	}
+	err := c.do(ctx, http.MethodPut, "/database/replica/verify", map[string]interface{}{
+		"cluster":   cluster,
+		"database":  database,
+		"startTime": timeRange.Start,
+		"endTime":   timeRange.End,
+	}, &result)
+	return result.Name, err
+}
+
+// waitReport polls the report of replica verify task until all storage nodes report.
+func (c *verifyClient) waitReport(ctx context.Context, cluster, database, name string,
+	pollInterval time.Duration,
+) (*models.ReplicaVerifyReport, error) {
+	query := url.Values{}
+	query.Set("cluster", cluster)
+	query.Set("database", database)
+	query.Set("name", name)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		report := &models.ReplicaVerifyReport{}
+		if err := c.do(ctx, http.MethodGet, "/database/replica/verify?"+query.Encode(), nil, report); err != nil {
+			return nil, err
+		}
+		if report.Completed {
+			return report, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait replica verify task: %s, pending nodes: %s, error: %s",
+				name, strings.Join(report.Pending, ","), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// repair submits the replica repair task, returns the report with the number of repair tasks.
+func (c *verifyClient) repair(ctx context.Context, cluster, database, name string) (*models.ReplicaVerifyReport, error) {
+	report := &models.ReplicaVerifyReport{}
+	err := c.do(ctx, http.MethodPut, "/database/replica/repair", map[string]interface{}{
+		"cluster":  cluster,
+		"database": database,
+		"name":     name,
+	}, report)
+	return report, err
+}
+
+// do sends the request to broker, decodes the response into result.
+func (c *verifyClient) do(ctx context.Context, method, uri string, param, result interface{}) error {
+	var body io.Reader
+	if param != nil {
+		data, err := json.Marshal(param)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+uri, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	if c.token != "" {
+		req.Header.Set("Authorization", c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s error, status: %d, body: %s", method, uri, resp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, result)
+}
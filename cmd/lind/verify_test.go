@@ -0,0 +1,192 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lind
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+// newMockVerifyBroker creates the broker which returns the given verify/repair report.
+func newMockVerifyBroker(t *testing.T, report, repairReport *models.ReplicaVerifyReport) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "admin-token", r.Header.Get("Authorization"))
+		var result interface{}
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v1/database/replica/verify":
+			var param map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&param))
+			assert.Equal(t, "cluster", param["cluster"])
+			assert.Equal(t, "db", param["database"])
+			result = map[string]string{"name": "task-1"}
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/database/replica/verify" && report != nil:
+			assert.Equal(t, "task-1", r.URL.Query().Get("name"))
+			result = report
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v1/database/replica/repair" && repairReport != nil:
+			result = repairReport
+		}
+		if result == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("internal error"))
+			return
+		}
+		data, _ := json.Marshal(result)
+		_, _ = w.Write(data)
+	}))
+}
+
+func TestRunVerify(t *testing.T) {
+	familyTime, _ := timeutil.ParseTimestamp("2021-08-01 10:00:00")
+	corruptedReport := &models.ReplicaVerifyReport{
+		Name:      "task-1",
+		Completed: true,
+		Shards:    1,
+		Families:  2,
+		Divergences: []models.FamilyDivergence{
+			{
+				ShardID:    1,
+				FamilyTime: familyTime,
+				Replicas: []models.FamilyReplica{
+					{Node: "node-1", Checksum: &models.FamilyChecksum{Series: 10, Points: 100, Checksum: 0xab}},
+					{Node: "node-2", Checksum: &models.FamilyChecksum{Series: 10, Points: 99, Checksum: 0xcd}},
+					{Node: "node-3"},
+				},
+			},
+		},
+	}
+	missingReport := &models.ReplicaVerifyReport{
+		Name:      "task-1",
+		Completed: true,
+		Shards:    1,
+		Families:  2,
+		Divergences: []models.FamilyDivergence{
+			{
+				ShardID:    1,
+				FamilyTime: familyTime,
+				Replicas: []models.FamilyReplica{
+					{Node: "node-1", Checksum: &models.FamilyChecksum{Series: 10, Points: 100, Checksum: 0xab}},
+					{Node: "node-2"},
+				},
+				Repairable: true,
+			},
+		},
+	}
+	// case 1: cluster is empty
+	output, err := runMockVerify(t, []string{"--database", "db"}, nil, nil)
+	assert.EqualError(t, err, "cluster is required")
+	assert.Empty(t, output)
+	// case 2: database is empty
+	output, err = runMockVerify(t, []string{"--cluster", "cluster"}, nil, nil)
+	assert.EqualError(t, err, "database is required")
+	assert.Empty(t, output)
+	// case 3: start time is after end time
+	output, err = runMockVerify(t, []string{"--cluster", "cluster", "--database", "db",
+		"--start", "2021-08-02 00:00:00", "--end", "2021-08-01 00:00:00"}, nil, nil)
+	assert.EqualError(t, err, "start time is after end time")
+	assert.Empty(t, output)
+	// case 4: broker error
+	output, err = runMockVerify(t, []string{"--cluster", "cluster", "--database", "db"}, nil, nil)
+	assert.EqualError(t, err,
+		"GET /database/replica/verify?cluster=cluster&database=db&name=task-1 error, status: 500, body: internal error")
+	assert.Empty(t, output)
+	// case 5: clean store
+	output, err = runMockVerify(t, []string{"--cluster", "cluster", "--database", "db"},
+		&models.ReplicaVerifyReport{Name: "task-1", Completed: true, Shards: 2, Families: 48}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "verified shards: 2, families: 48, divergent families: 0\n", output)
+	// case 6: corrupted store
+	output, err = runMockVerify(t, []string{"--cluster", "cluster", "--database", "db"}, corruptedReport, nil)
+	assert.EqualError(t, err, "replicas of database: db are inconsistent")
+	assert.Equal(t, "verified shards: 1, families: 2, divergent families: 1\n"+
+		"shard: 1 family: 2021-08-01 10:00:00 repairable: false\n"+
+		"  node-1: series=10 points=100 checksum=ab\n"+
+		"  node-2: series=10 points=99 checksum=cd\n"+
+		"  node-3: missing\n", output)
+	// case 7: node failure
+	output, err = runMockVerify(t, []string{"--cluster", "cluster", "--database", "db"},
+		&models.ReplicaVerifyReport{Name: "task-1", Completed: true, Shards: 1, Families: 2,
+			Failures: []models.ReplicaVerifyFailure{{Node: "node-1", Error: "shard not found"}}}, nil)
+	assert.EqualError(t, err, "replicas of database: db are inconsistent")
+	assert.Equal(t, "verified shards: 1, families: 2, divergent families: 0\n"+
+		"node: node-1 failure: shard not found\n", output)
+	// case 8: repair missing families
+	output, err = runMockVerify(t, []string{"--cluster", "cluster", "--database", "db", "--repair"},
+		missingReport, &models.ReplicaVerifyReport{Name: "task-1", Completed: true, RepairTasks: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "verified shards: 1, families: 2, divergent families: 1\n"+
+		"shard: 1 family: 2021-08-01 10:00:00 repairable: true\n"+
+		"  node-1: series=10 points=100 checksum=ab\n"+
+		"  node-2: missing\n"+
+		"submitted repair tasks: 1\n", output)
+	// case 9: repair failure
+	output, err = runMockVerify(t, []string{"--cluster", "cluster", "--database", "db", "--repair"}, missingReport, nil)
+	assert.EqualError(t, err, "PUT /database/replica/repair error, status: 500, body: internal error")
+	assert.Equal(t, "verified shards: 1, families: 2, divergent families: 1\n"+
+		"shard: 1 family: 2021-08-01 10:00:00 repairable: true\n"+
+		"  node-1: series=10 points=100 checksum=ab\n"+
+		"  node-2: missing\n", output)
+}
+
+// runMockVerify runs verify command against the mock broker, returns the output of command.
+func runMockVerify(t *testing.T, args []string, report, repairReport *models.ReplicaVerifyReport) (string, error) {
+	broker := newMockVerifyBroker(t, report, repairReport)
+	defer broker.Close()
+
+	cmd := newVerifyCmd()
+	var buf bytes.Buffer
+	cmd.SetOutput(&buf)
+	assert.NoError(t, cmd.Flags().Parse(append(args, "--endpoint", broker.URL, "--token", "admin-token")))
+	err := runVerify(cmd, nil)
+	return buf.String(), err
+}
+
+func TestVerifyClient_waitReport(t *testing.T) {
+	polls := atomic.NewInt32(0)
+	broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := &models.ReplicaVerifyReport{Name: "task-1", Completed: polls.Inc() >= 3, Pending: []string{"node-2"}}
+		data, _ := json.Marshal(report)
+		_, _ = w.Write(data)
+	}))
+	defer broker.Close()
+
+	client := newVerifyClient(broker.URL, "")
+	// wait until all storage nodes report
+	report, err := client.waitReport(context.TODO(), "cluster", "db", "task-1", time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, report.Completed)
+	assert.Equal(t, int32(3), polls.Load())
+
+	// timeout
+	polls.Store(-100)
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	report, err = client.waitReport(ctx, "cluster", "db", "task-1", time.Millisecond)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+}
@@ -20,6 +20,8 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lindb/lindb/pkg/ltoml"
@@ -31,9 +33,82 @@ type HTTP struct {
 	IdleTimeout  ltoml.Duration `toml:"idle-timeout"`
 	WriteTimeout ltoml.Duration `toml:"write-timeout"`
 	ReadTimeout  ltoml.Duration `toml:"read-timeout"`
+	// MaxInflightRequests is the max number of api requests being handled concurrently, 0 means no limit.
+	MaxInflightRequests int `toml:"max-inflight-requests"`
+	// ClientIPRate is the max requests per second of each client ip, 0 means no limit.
+	ClientIPRate int         `toml:"client-ip-rate"`
+	RouteRates   []RouteRate `toml:"route-rates"`
+	// BasePath is the path prefix of all routes, used when broker is behind a shared ingress(like /lindb).
+	BasePath string `toml:"base-path"`
+	// TrustedProxies are the ips/cidrs of reverse proxies whose X-Forwarded-For header is trusted,
+	// empty means X-Forwarded-For/X-Real-Ip header is always trusted.
+	TrustedProxies []string `toml:"trusted-proxies"`
+	CORS           CORS     `toml:"cors"`
+}
+
+// GetBasePath returns the normalized base path which starts with "/" and has no trailing "/",
+// returns empty if base path is not set.
+func (h *HTTP) GetBasePath() string {
+	basePath := strings.Trim(h.BasePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// CORS represents the cross-origin resource sharing policy of HTTP api.
+type CORS struct {
+	AllowOrigins     []string       `toml:"allow-origins"` // empty means all origins are allowed
+	AllowHeaders     []string       `toml:"allow-headers"` // extra request headers allowed
+	AllowCredentials bool           `toml:"allow-credentials"`
+	MaxAge           ltoml.Duration `toml:"max-age"` // how long the result of preflight request can be cached
+}
+
+func (c *CORS) TOML() string {
+	return fmt.Sprintf(`
+    ## origins which are allowed to access api, supports wildcard like "https://*.example.com",
+    ## empty means all origins are allowed
+    allow-origins = [%s]
+    ## extra request headers which are allowed besides Origin/Content-Type/Authorization etc.
+    allow-headers = [%s]
+    ## whether the request can include user credentials like cookies
+    allow-credentials = %v
+    ## how long the result of preflight request can be cached by client, 0 means 12h
+    max-age = "%s"`,
+		quoteStrings(c.AllowOrigins),
+		quoteStrings(c.AllowHeaders),
+		c.AllowCredentials,
+		c.MaxAge.String(),
+	)
+}
+
+// quoteStrings returns the quoted strings joined by comma for toml array.
+func quoteStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for idx, value := range values {
+		quoted[idx] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// RouteRate represents the rate limit of api route.
+type RouteRate struct {
+	Path string `toml:"path"` // route path, like /api/v1/query/metric
+	Rate int    `toml:"rate"` // max requests per second of route, 0 means no limit
 }
 
 func (h *HTTP) TOML() string {
+	var routeRates strings.Builder
+	for _, routeRate := range h.RouteRates {
+		routeRates.WriteString(fmt.Sprintf(`
+
+  [[broker.http.route-rates]]
+    path = "%s"
+    rate = %d`,
+			routeRate.Path,
+			routeRate.Rate,
+		))
+	}
 	return fmt.Sprintf(`
 	## Controls how HTTP Server are configured.
     ##
@@ -45,23 +120,57 @@ func (h *HTTP) TOML() string {
 	write-timeout = "%s"	
 	## maximum duration for reading the entire request, including the body.
 	read-timeout = "%s"
+	## maximum number of api requests being handled concurrently, requests exceeding it are
+	## rejected with 429(Too Many Requests), 0 means no limit.
+	max-inflight-requests = %d
+	## maximum requests per second of each client ip, 0 means no limit.
+	client-ip-rate = %d
+	## path prefix of all routes(api/console/metrics), used when broker is behind a shared ingress
+	## with path prefix like /lindb, empty means no prefix.
+	base-path = "%s"
+	## ips/cidrs of reverse proxies whose X-Forwarded-For header is trusted for resolving client ip
+	## of access log and rate limit, empty means X-Forwarded-For/X-Real-Ip header is always trusted.
+	trusted-proxies = [%s]
+	## maximum requests per second of api routes, 0 means no limit.
+	## [[broker.http.route-rates]]
+	##   path = "/api/v1/query/metric"
+	##   rate = 100
+
+  ## Controls the cross-origin resource sharing policy of api.
+  [broker.http.cors]%s%s
 `,
 		h.Port,
 		h.IdleTimeout.Duration().String(),
 		h.WriteTimeout.Duration().String(),
 		h.ReadTimeout.Duration().String(),
+		h.MaxInflightRequests,
+		h.ClientIPRate,
+		h.BasePath,
+		quoteStrings(h.TrustedProxies),
+		h.CORS.TOML(),
+		routeRates.String(),
 	)
 }
 
 type Ingestion struct {
-	IngestTimeout ltoml.Duration `toml:"ingest-timeout"`
+	IngestTimeout      ltoml.Duration `toml:"ingest-timeout"`
+	MaxTimestampBehind ltoml.Duration `toml:"max-timestamp-behind"`
+	MaxTimestampAhead  ltoml.Duration `toml:"max-timestamp-ahead"`
 }
 
 func (i *Ingestion) TOML() string {
 	return fmt.Sprintf(`
     ## maximum duration before timeout for server ingesting metrics
-    ingest-timeout = "%s"`,
-		i.IngestTimeout.Duration().String())
+    ingest-timeout = "%s"
+
+    ## metrics with timestamp before now - max-timestamp-behind are rejected, 0 means no limit
+    max-timestamp-behind = "%s"
+
+    ## metrics with timestamp after now + max-timestamp-ahead are rejected, 0 means no limit
+    max-timestamp-ahead = "%s"`,
+		i.IngestTimeout.Duration().String(),
+		i.MaxTimestampBehind.Duration().String(),
+		i.MaxTimestampAhead.Duration().String())
 }
 
 // User represents user model
@@ -98,9 +207,17 @@ type ReplicationChannel struct {
 	CheckFlushInterval ltoml.Duration `toml:"check-flush-interval"`
 	FlushInterval      ltoml.Duration `toml:"flush-interval"`
 	BufferSize         int            `toml:"buffer-size"`
+	MaxSize            ltoml.Size     `toml:"max-size"`     // max total size of on-disk queue of each shard
+	SegmentSize        ltoml.Size     `toml:"segment-size"` // size of each data segment of on-disk queue
+	Retention          ltoml.Duration `toml:"retention"`    // how long acked segments are retained for replay
 }
 
+// GetDataSizeLimit returns the max total size of on-disk queue of each shard,
+// max-size takes precedence over data-size-limit if set.
 func (rc *ReplicationChannel) GetDataSizeLimit() int64 {
+	if rc.MaxSize > 0 {
+		return int64(rc.MaxSize)
+	}
 	if rc.DataSizeLimit <= 1 {
 		return 1024 * 1024 // 1MB
 	}
@@ -136,7 +253,20 @@ func (rc *ReplicationChannel) TOML() string {
     flush-interval = "%s"
 
     ## will flush if this size of data in kegabytes get buffered
-    buffer-size = %d`,
+    buffer-size = %d
+
+    ## max-size is the maximum total size of the on-disk queue of each shard,
+    ## takes precedence over data-size-limit, 0 means using data-size-limit.
+    ## acked segments retained for replay are removed first when queue is full
+    max-size = "%s"
+
+    ## segment-size is the size of each data segment file of the on-disk queue,
+    ## a new segment is created when current segment is full, it defaults to 128MB
+    segment-size = "%s"
+
+    ## retention is how long acked segments are retained, so that a storage node
+    ## can replay from an earlier sequence after recovery, 0 means removed once acked
+    retention = "%s"`,
 		rc.Dir,
 		rc.DataSizeLimit,
 		rc.RemoveTaskInterval.String(),
@@ -144,6 +274,366 @@ func (rc *ReplicationChannel) TOML() string {
 		rc.CheckFlushInterval.String(),
 		rc.FlushInterval.String(),
 		rc.BufferSize,
+		rc.MaxSize.String(),
+		rc.SegmentSize.String(),
+		rc.Retention.String(),
+	)
+}
+
+// HealthProbe represents config for broker's end-to-end health probe, which writes a synthetic
+// series into database then reads it back through storage.
+type HealthProbe struct {
+	Interval  ltoml.Duration `toml:"interval"`
+	Timeout   ltoml.Duration `toml:"timeout"`
+	Databases []string       `toml:"databases"`
+}
+
+// Enabled returns if health probe is enabled.
+func (hp *HealthProbe) Enabled() bool {
+	return hp.Interval > 0 && len(hp.Databases) > 0
+}
+
+func (hp *HealthProbe) TOML() string {
+	var databases []string
+	for _, db := range hp.Databases {
+		databases = append(databases, fmt.Sprintf("%q", db))
+	}
+	return fmt.Sprintf(`
+    ## interval for how often synthetic series will be written and read back,
+    ## 0 means health probe is disabled
+    interval = "%s"
+
+    ## maximum duration for waiting the written series can be read back
+    timeout = "%s"
+
+    ## databases which health probe writes into
+    databases = [%s]`,
+		hp.Interval.String(),
+		hp.Timeout.String(),
+		strings.Join(databases, ", "),
+	)
+}
+
+// Alerting represents config of alerting, alert rules stored in state repo are evaluated
+// by master broker periodically, notifications are sent to webhooks and alertmanager.
+type Alerting struct {
+	Interval        ltoml.Duration `toml:"interval"`
+	Timeout         ltoml.Duration `toml:"timeout"`
+	WebhookURLs     []string       `toml:"webhook-urls"`
+	AlertmanagerURL string         `toml:"alertmanager-url"`
+}
+
+// Enabled returns if alerting is enabled.
+func (a *Alerting) Enabled() bool {
+	return a.Interval > 0
+}
+
+func (a *Alerting) TOML() string {
+	var urls []string
+	for _, url := range a.WebhookURLs {
+		urls = append(urls, fmt.Sprintf("%q", url))
+	}
+	return fmt.Sprintf(`
+    ## interval for how often alert rules will be evaluated by master broker,
+    ## 0 means alerting is disabled
+    interval = "%s"
+
+    ## maximum duration of querying for each alert rule and sending notifications
+    timeout = "%s"
+
+    ## urls of webhooks which firing/resolved alerts are posted to as json
+    webhook-urls = [%s]
+
+    ## url of alertmanager(like http://localhost:9093) which alerts are posted to,
+    ## empty means alerts are not sent to alertmanager
+    alertmanager-url = "%s"`,
+		a.Interval.String(),
+		a.Timeout.String(),
+		strings.Join(urls, ", "),
+		a.AlertmanagerURL,
+	)
+}
+
+// Graphite represents config of graphite plaintext protocol listener, which maps the dotted path into
+// metric name, field and tags by templates, template format: "[filter] template [tag1=value1,tag2=value2]",
+// like "servers.* .host.measurement* region=sh".
+type Graphite struct {
+	Port          uint16         `toml:"port"`
+	Database      string         `toml:"database"`
+	Namespace     string         `toml:"namespace"`
+	Separator     string         `toml:"separator"`
+	Templates     []string       `toml:"templates"`
+	BatchSize     int            `toml:"batch-size"`
+	FlushInterval ltoml.Duration `toml:"flush-interval"`
+}
+
+// Enabled returns if graphite listener is enabled.
+func (g *Graphite) Enabled() bool {
+	return g.Port > 0 && g.Database != ""
+}
+
+func (g *Graphite) TOML() string {
+	var templates []string
+	for _, template := range g.Templates {
+		templates = append(templates, fmt.Sprintf("%q", template))
+	}
+	return fmt.Sprintf(`
+    ## port of graphite plaintext protocol tcp listener, 0 means graphite listener is disabled
+    port = %d
+
+    ## database which graphite metrics are written into
+    database = "%s"
+
+    ## namespace of graphite metrics
+    namespace = "%s"
+
+    ## separator for joining multiple measurement parts of path
+    separator = "%s"
+
+    ## templates for mapping dotted path into metric name, field and tags,
+    ## format: "[filter] template [tag1=value1,tag2=value2]", default template is "measurement*"
+    templates = [%s]
+
+    ## max num. of metrics buffered before written into replication channel
+    batch-size = %d
+
+    ## interval for how often buffered metrics will be written if not exceeds batch-size
+    flush-interval = "%s"`,
+		g.Port,
+		g.Database,
+		g.Namespace,
+		g.Separator,
+		strings.Join(templates, ", "),
+		g.BatchSize,
+		g.FlushInterval.String(),
+	)
+}
+
+// StatsD represents config of statsd udp listener, which aggregates counters, gauges and timers in memory,
+// then writes them periodically.
+type StatsD struct {
+	Port          uint16         `toml:"port"`
+	Database      string         `toml:"database"`
+	Namespace     string         `toml:"namespace"`
+	FlushInterval ltoml.Duration `toml:"flush-interval"`
+	TimerBounds   []float64      `toml:"timer-bounds"`
+}
+
+// Enabled returns if statsd listener is enabled.
+func (s *StatsD) Enabled() bool {
+	return s.Port > 0 && s.Database != ""
+}
+
+func (s *StatsD) TOML() string {
+	var bounds []string
+	for _, bound := range s.TimerBounds {
+		bounds = append(bounds, strconv.FormatFloat(bound, 'f', -1, 64))
+	}
+	return fmt.Sprintf(`
+    ## port of statsd udp listener, 0 means statsd listener is disabled
+    port = %d
+
+    ## database which statsd metrics are written into
+    database = "%s"
+
+    ## namespace of statsd metrics
+    namespace = "%s"
+
+    ## interval for how often aggregated metrics will be written,
+    ## counters => count(delta sum), gauges => gauge, timers => delta histogram
+    flush-interval = "%s"
+
+    ## upper bounds(milliseconds) of timer histogram buckets, default bounds used if empty
+    timer-bounds = [%s]`,
+		s.Port,
+		s.Database,
+		s.Namespace,
+		s.FlushInterval.String(),
+		strings.Join(bounds, ", "),
+	)
+}
+
+// DeadLetter represents config of dead letter which records metrics rejected by validation.
+type DeadLetter struct {
+	Dir         string     `toml:"dir"`
+	MaxFileSize ltoml.Size `toml:"max-file-size"`
+	SampleSize  int        `toml:"sample-size"`
+}
+
+func (d *DeadLetter) TOML() string {
+	return fmt.Sprintf(`
+    ## directory for recording rejected metrics as json lines, empty means rejected metrics are not recorded into file
+    dir = "%s"
+
+    ## file is rotated if exceeds max file size, only one rotated file is kept
+    max-file-size = "%s"
+
+    ## num. of recent rejected metrics kept in memory, which can be sampled by http api
+    sample-size = %d`,
+		d.Dir,
+		d.MaxFileSize.String(),
+		d.SampleSize,
+	)
+}
+
+// Subscription represents config of subscriptions which push written metrics to external consumers.
+type Subscription struct {
+	MaxSubscribers int `toml:"max-subscribers"`
+	BufferSize     int `toml:"buffer-size"`
+}
+
+func (s *Subscription) TOML() string {
+	return fmt.Sprintf(`
+    ## max num. of subscribers on each broker, 0 means subscription is disabled
+    max-subscribers = %d
+
+    ## num. of metric batches buffered for each subscriber,
+    ## batches are dropped if subscriber is too slow to consume
+    buffer-size = %d`,
+		s.MaxSubscribers,
+		s.BufferSize,
+	)
+}
+
+// Federation represents config of federation query, broker fans query out to remote LinDB clusters
+// over grpc and merges results with cluster tag, remote cluster format: "name=ip:grpc-port",
+// like "region-a=10.0.0.1:9001".
+type Federation struct {
+	Cluster        string   `toml:"cluster"`
+	RemoteClusters []string `toml:"remote-clusters"`
+}
+
+// Enabled returns if federation query is enabled.
+func (f *Federation) Enabled() bool {
+	return len(f.RemoteClusters) > 0
+}
+
+func (f *Federation) TOML() string {
+	var clusters []string
+	for _, cluster := range f.RemoteClusters {
+		clusters = append(clusters, fmt.Sprintf("%q", cluster))
+	}
+	return fmt.Sprintf(`
+    ## name of current cluster, which is the value of cluster tag for results of current cluster
+    cluster = "%s"
+
+    ## remote clusters which federation query fans out to, format: "name=ip:grpc-port",
+    ## empty means federation query is disabled
+    remote-clusters = [%s]`,
+		f.Cluster,
+		strings.Join(clusters, ", "),
+	)
+}
+
+// Mirror represents config of mirroring, written metrics of databases are forwarded to remote LinDB clusters
+// asynchronously for disaster recovery, database format: "database=ip:grpc-port", like "db1=10.0.0.1:9001".
+type Mirror struct {
+	Dir           string         `toml:"dir"`
+	Databases     []string       `toml:"databases"`
+	DataSizeLimit ltoml.Size     `toml:"data-size-limit"`
+	RetryInterval ltoml.Duration `toml:"retry-interval"`
+}
+
+// Enabled returns if mirroring is enabled.
+func (m *Mirror) Enabled() bool {
+	return len(m.Databases) > 0
+}
+
+func (m *Mirror) TOML() string {
+	var databases []string
+	for _, database := range m.Databases {
+		databases = append(databases, fmt.Sprintf("%q", database))
+	}
+	return fmt.Sprintf(`
+    ## directory of mirror queues which buffer written metrics until forwarded to remote cluster
+    dir = "%s"
+
+    ## databases mirrored to broker of remote cluster, format: "database=ip:grpc-port",
+    ## database with the same name must exist in remote cluster, mirroring in both directions is not supported,
+    ## empty means mirroring is disabled
+    databases = [%s]
+
+    ## max size of queue for each database, new metrics are dropped if remote cluster is unavailable
+    ## for a long time and queue is full, local writes are never blocked by mirroring
+    data-size-limit = "%s"
+
+    ## interval of retrying to forward metrics after remote cluster failure
+    retry-interval = "%s"`,
+		m.Dir,
+		strings.Join(databases, ", "),
+		m.DataSizeLimit.String(),
+		m.RetryInterval.String(),
+	)
+}
+
+// NamespaceQuota represents the quota of a namespace(tenant), 0 means no limit.
+type NamespaceQuota struct {
+	Namespace string `toml:"namespace"`
+	WriteRate int    `toml:"write-rate"` // max num. of metrics written per second
+	QueryRate int    `toml:"query-rate"` // max num. of queries per second
+	MaxSeries int    `toml:"max-series"` // max num. of active series within series window
+}
+
+// Tenant represents the multi-tenancy config of broker, namespace is the tenant of metrics.
+type Tenant struct {
+	Isolation    bool             `toml:"isolation"`
+	SeriesWindow ltoml.Duration   `toml:"series-window"`
+	Quotas       []NamespaceQuota `toml:"quotas"`
+}
+
+// Validate checks the quotas are not negative and namespace is not duplicated.
+func (t *Tenant) Validate() error {
+	namespaces := make(map[string]struct{})
+	for _, quota := range t.Quotas {
+		if quota.Namespace == "" {
+			return fmt.Errorf("namespace of quota cannot be empty")
+		}
+		if _, ok := namespaces[quota.Namespace]; ok {
+			return fmt.Errorf("duplicate namespace quota: %s", quota.Namespace)
+		}
+		namespaces[quota.Namespace] = struct{}{}
+		if quota.WriteRate < 0 || quota.QueryRate < 0 || quota.MaxSeries < 0 {
+			return fmt.Errorf("quota of namespace(%s) cannot be negative", quota.Namespace)
+		}
+	}
+	return nil
+}
+
+func (t *Tenant) TOML() string {
+	var quotas strings.Builder
+	for _, quota := range t.Quotas {
+		quotas.WriteString(fmt.Sprintf(`
+
+  [[broker.tenant.quotas]]
+    namespace = "%s"
+    write-rate = %d
+    query-rate = %d
+    max-series = %d`,
+			quota.Namespace,
+			quota.WriteRate,
+			quota.QueryRate,
+			quota.MaxSeries,
+		))
+	}
+	return fmt.Sprintf(`
+    ## if true, query can only access the metrics of namespace which is given by query param "ns"(default namespace if not given),
+    ## statement without namespace(on clause) queries the namespace of query param
+    isolation = %v
+
+    ## window of counting active series of namespace, series limit(max-series) is reset when window slides
+    series-window = "%s"
+
+    ## quotas of namespaces, namespace "*" applies to the namespaces not configured, 0 means no limit,
+    ## metrics exceeding write-rate/max-series are rejected, queries exceeding query-rate are rejected.
+    ## quotas can be changed without restart.
+    ## [[broker.tenant.quotas]]
+    ##   namespace = "*"
+    ##   write-rate = 100000
+    ##   query-rate = 100
+    ##   max-series = 1000000%s`,
+		t.Isolation,
+		t.SeriesWindow.String(),
+		quotas.String(),
 	)
 }
 
@@ -156,6 +646,16 @@ type BrokerBase struct {
 	User               User               `toml:"user"`
 	GRPC               GRPC               `toml:"grpc"`
 	ReplicationChannel ReplicationChannel `toml:"replication_channel"`
+	HealthProbe        HealthProbe        `toml:"health_probe"`
+	Graphite           Graphite           `toml:"graphite"`
+	StatsD             StatsD             `toml:"statsd"`
+	DeadLetter         DeadLetter         `toml:"dead_letter"`
+	Alerting           Alerting           `toml:"alerting"`
+	Subscription       Subscription       `toml:"subscription"`
+	Federation         Federation         `toml:"federation"`
+	Mirror             Mirror             `toml:"mirror"`
+	Tenant             Tenant             `toml:"tenant"`
+	Location           Location           `toml:"location"`
 }
 
 func (bb *BrokerBase) TOML() string {
@@ -173,7 +673,27 @@ func (bb *BrokerBase) TOML() string {
 
   [broker.grpc]%s
 
-  [broker.replication_channel]%s`,
+  [broker.replication_channel]%s
+
+  [broker.health_probe]%s
+
+  [broker.graphite]%s
+
+  [broker.statsd]%s
+
+  [broker.dead_letter]%s
+
+  [broker.alerting]%s
+
+  [broker.subscription]%s
+
+  [broker.federation]%s
+
+  [broker.mirror]%s
+
+  [broker.tenant]%s
+
+  [broker.location]%s`,
 		bb.Coordinator.TOML(),
 		bb.Query.TOML(),
 		bb.HTTP.TOML(),
@@ -181,19 +701,36 @@ func (bb *BrokerBase) TOML() string {
 		bb.User.TOML(),
 		bb.GRPC.TOML(),
 		bb.ReplicationChannel.TOML(),
+		bb.HealthProbe.TOML(),
+		bb.Graphite.TOML(),
+		bb.StatsD.TOML(),
+		bb.DeadLetter.TOML(),
+		bb.Alerting.TOML(),
+		bb.Subscription.TOML(),
+		bb.Federation.TOML(),
+		bb.Mirror.TOML(),
+		bb.Tenant.TOML(),
+		bb.Location.TOML(),
 	)
 }
 
 func NewDefaultBrokerBase() *BrokerBase {
 	return &BrokerBase{
 		HTTP: HTTP{
-			Port:         9000,
-			IdleTimeout:  ltoml.Duration(time.Minute * 2),
-			ReadTimeout:  ltoml.Duration(time.Second * 15),
-			WriteTimeout: ltoml.Duration(time.Second * 15),
+			Port:           9000,
+			IdleTimeout:    ltoml.Duration(time.Minute * 2),
+			ReadTimeout:    ltoml.Duration(time.Second * 15),
+			WriteTimeout:   ltoml.Duration(time.Second * 15),
+			TrustedProxies: []string{},
+			CORS: CORS{
+				AllowOrigins: []string{},
+				AllowHeaders: []string{},
+			},
 		},
 		Ingestion: Ingestion{
-			IngestTimeout: ltoml.Duration(time.Second * 5),
+			IngestTimeout:      ltoml.Duration(time.Second * 5),
+			MaxTimestampBehind: ltoml.Duration(30 * 24 * time.Hour),
+			MaxTimestampAhead:  ltoml.Duration(24 * time.Hour),
 		},
 		GRPC: GRPC{
 			Port: 9001,
@@ -203,6 +740,7 @@ func NewDefaultBrokerBase() *BrokerBase {
 			Endpoints:   []string{"http://localhost:2379"},
 			Timeout:     ltoml.Duration(time.Second * 10),
 			DialTimeout: ltoml.Duration(time.Second * 5),
+			LeaseTTL:    ltoml.Duration(time.Second * defaultLeaseTTL),
 		},
 		User: User{
 			UserName: "admin",
@@ -215,8 +753,50 @@ func NewDefaultBrokerBase() *BrokerBase {
 			CheckFlushInterval: ltoml.Duration(time.Second),
 			FlushInterval:      ltoml.Duration(5 * time.Second),
 			BufferSize:         128,
+			SegmentSize:        ltoml.Size(128 * 1024 * 1024),
+			Retention:          ltoml.Duration(time.Hour),
 		},
 		Query: *NewDefaultQuery(),
+		HealthProbe: HealthProbe{
+			Timeout:   ltoml.Duration(30 * time.Second),
+			Databases: []string{},
+		},
+		Graphite: Graphite{
+			Separator:     ".",
+			Templates:     []string{},
+			BatchSize:     1000,
+			FlushInterval: ltoml.Duration(time.Second),
+		},
+		StatsD: StatsD{
+			FlushInterval: ltoml.Duration(10 * time.Second),
+			TimerBounds:   []float64{},
+		},
+		DeadLetter: DeadLetter{
+			MaxFileSize: ltoml.Size(64 * 1024 * 1024),
+			SampleSize:  100,
+		},
+		Alerting: Alerting{
+			Interval:    ltoml.Duration(time.Minute),
+			Timeout:     ltoml.Duration(30 * time.Second),
+			WebhookURLs: []string{},
+		},
+		Subscription: Subscription{
+			MaxSubscribers: 64,
+			BufferSize:     1024,
+		},
+		Federation: Federation{
+			Cluster:        "local",
+			RemoteClusters: []string{},
+		},
+		Mirror: Mirror{
+			Dir:           filepath.Join(defaultParentDir, "broker/mirror"),
+			Databases:     []string{},
+			DataSizeLimit: ltoml.Size(1024 * 1024 * 1024),
+			RetryInterval: ltoml.Duration(5 * time.Second),
+		},
+		Tenant: Tenant{
+			SeriesWindow: ltoml.Duration(time.Hour),
+		},
 	}
 }
 
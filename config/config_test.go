@@ -19,7 +19,9 @@ package config
 
 import (
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -80,3 +82,200 @@ func Test_ReplicationChannel_SegmentFileSizeInBytes(t *testing.T) {
 	rc.DataSizeLimit = 10000
 	assert.Equal(t, int64(1024*1024*1024), rc.GetDataSizeLimit())
 }
+
+func Test_Query_ShardParallelism(t *testing.T) {
+	var q Query
+	assert.Equal(t, runtime.NumCPU(), q.GetShardParallelism())
+	q.ShardParallelism = 2
+	assert.Equal(t, 2, q.GetShardParallelism())
+}
+
+func Test_RepoState_LeaseTTLSeconds(t *testing.T) {
+	var rs RepoState
+	assert.Equal(t, int64(defaultLeaseTTL), rs.LeaseTTLSeconds())
+	rs.LeaseTTL = ltoml.Duration(500 * time.Millisecond)
+	assert.Equal(t, int64(defaultLeaseTTL), rs.LeaseTTLSeconds())
+	rs.LeaseTTL = ltoml.Duration(5 * time.Second)
+	assert.Equal(t, int64(5), rs.LeaseTTLSeconds())
+	assert.Contains(t, rs.TOML(), `lease-ttl = "5s"`)
+}
+
+func Test_HealthProbe(t *testing.T) {
+	hp := NewDefaultBrokerBase().HealthProbe
+	assert.False(t, hp.Enabled())
+	hp.Interval = ltoml.Duration(time.Minute)
+	assert.False(t, hp.Enabled())
+	hp.Databases = []string{"_internal"}
+	assert.True(t, hp.Enabled())
+	assert.Contains(t, hp.TOML(), `databases = ["_internal"]`)
+}
+
+func Test_Alerting(t *testing.T) {
+	a := NewDefaultBrokerBase().Alerting
+	assert.True(t, a.Enabled())
+	a.WebhookURLs = []string{"http://localhost:8080/alerts"}
+	assert.Contains(t, a.TOML(), `webhook-urls = ["http://localhost:8080/alerts"]`)
+	a.Interval = 0
+	assert.False(t, a.Enabled())
+}
+
+func Test_Graphite(t *testing.T) {
+	g := NewDefaultBrokerBase().Graphite
+	assert.False(t, g.Enabled())
+	g.Port = 2003
+	assert.False(t, g.Enabled())
+	g.Database = "graphite"
+	assert.True(t, g.Enabled())
+	g.Templates = []string{"servers.* .host.measurement*"}
+	assert.Contains(t, g.TOML(), `templates = ["servers.* .host.measurement*"]`)
+}
+
+func Test_StatsD(t *testing.T) {
+	s := NewDefaultBrokerBase().StatsD
+	assert.False(t, s.Enabled())
+	s.Port = 8125
+	s.Database = "statsd"
+	assert.True(t, s.Enabled())
+	s.TimerBounds = []float64{0.5, 10}
+	assert.Contains(t, s.TOML(), `timer-bounds = [0.5, 10]`)
+}
+
+func Test_DeadLetter(t *testing.T) {
+	d := NewDefaultBrokerBase().DeadLetter
+	d.Dir = "/tmp/dead-letter"
+	assert.Contains(t, d.TOML(), `dir = "/tmp/dead-letter"`)
+	assert.Contains(t, d.TOML(), `max-file-size = "64 MiB"`)
+}
+
+func Test_Subscription(t *testing.T) {
+	s := NewDefaultBrokerBase().Subscription
+	assert.Contains(t, s.TOML(), `max-subscribers = 64`)
+	assert.Contains(t, s.TOML(), `buffer-size = 1024`)
+}
+
+func Test_Federation(t *testing.T) {
+	f := NewDefaultBrokerBase().Federation
+	assert.False(t, f.Enabled())
+	f.RemoteClusters = []string{"region-a=10.0.0.1:9001"}
+	assert.True(t, f.Enabled())
+	assert.Contains(t, f.TOML(), `remote-clusters = ["region-a=10.0.0.1:9001"]`)
+}
+
+func Test_Mirror(t *testing.T) {
+	m := NewDefaultBrokerBase().Mirror
+	assert.False(t, m.Enabled())
+	m.Databases = []string{"db=10.0.0.1:9001"}
+	assert.True(t, m.Enabled())
+	assert.Contains(t, m.TOML(), `databases = ["db=10.0.0.1:9001"]`)
+	assert.Contains(t, m.TOML(), `data-size-limit = "1.0 GiB"`)
+}
+
+func Test_Location(t *testing.T) {
+	_ = fileutil.MkDirIfNotExist(testPath)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+
+	cfg := NewDefaultStorageBase()
+	cfg.Location = Location{Zone: "zone-a", Rack: "rack-1"}
+	cfgPath := filepath.Join(testPath, "storage.toml")
+	assert.Nil(t, ltoml.WriteConfig(cfgPath, cfg.TOML()))
+	var storageCfg Storage
+	assert.Nil(t, ltoml.DecodeToml(cfgPath, &storageCfg))
+	assert.Equal(t, cfg.Location, storageCfg.StorageBase.Location)
+	assert.Contains(t, NewDefaultBrokerBase().TOML(), "[broker.location]")
+}
+
+func Test_ReadOnly(t *testing.T) {
+	_ = fileutil.MkDirIfNotExist(testPath)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+
+	cfg := NewDefaultStorageBase()
+	cfg.ReadOnly = true
+	cfgPath := filepath.Join(testPath, "storage.toml")
+	assert.Nil(t, ltoml.WriteConfig(cfgPath, cfg.TOML()))
+	var storageCfg Storage
+	assert.Nil(t, ltoml.DecodeToml(cfgPath, &storageCfg))
+	assert.True(t, storageCfg.StorageBase.ReadOnly)
+
+	cluster := &StorageCluster{}
+	assert.False(t, cluster.IsReadOnlyNode("1.1.1.1:2080"))
+	assert.True(t, cluster.SetReadOnlyNode("1.1.1.1:2080", true))
+	assert.False(t, cluster.SetReadOnlyNode("1.1.1.1:2080", true))
+	assert.True(t, cluster.SetReadOnlyNode("1.1.1.2:2080", true))
+	assert.True(t, cluster.IsReadOnlyNode("1.1.1.1:2080"))
+	assert.True(t, cluster.SetReadOnlyNode("1.1.1.1:2080", false))
+	assert.False(t, cluster.SetReadOnlyNode("1.1.1.1:2080", false))
+	assert.Equal(t, []string{"1.1.1.2:2080"}, cluster.ReadOnlyNodes)
+}
+
+func Test_Tenant(t *testing.T) {
+	_ = fileutil.MkDirIfNotExist(testPath)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+
+	cfg := NewDefaultBrokerBase()
+	assert.NoError(t, cfg.Tenant.Validate())
+	cfg.Tenant.Quotas = []NamespaceQuota{
+		{Namespace: "*", WriteRate: 100, QueryRate: 10, MaxSeries: 1000},
+		{Namespace: "ns", WriteRate: 200},
+	}
+	assert.NoError(t, cfg.Tenant.Validate())
+	// quotas are kept after decoding
+	cfgPath := filepath.Join(testPath, "broker.toml")
+	assert.Nil(t, ltoml.WriteConfig(cfgPath, cfg.TOML()+"\n\n"+NewDefaultLogging().TOML()))
+	var brokerCfg Broker
+	assert.Nil(t, ltoml.DecodeToml(cfgPath, &brokerCfg))
+	assert.Equal(t, cfg.Tenant, brokerCfg.BrokerBase.Tenant)
+	assert.Equal(t, *NewDefaultLogging(), brokerCfg.Logging)
+
+	cfg.Tenant.Quotas = []NamespaceQuota{{Namespace: ""}}
+	assert.Error(t, cfg.Tenant.Validate())
+	cfg.Tenant.Quotas = []NamespaceQuota{{Namespace: "ns"}, {Namespace: "ns"}}
+	assert.Error(t, cfg.Tenant.Validate())
+	cfg.Tenant.Quotas = []NamespaceQuota{{Namespace: "ns", MaxSeries: -1}}
+	assert.Error(t, cfg.Tenant.Validate())
+}
+
+func Test_HTTP_RateLimit(t *testing.T) {
+	_ = fileutil.MkDirIfNotExist(testPath)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+
+	cfg := NewDefaultBrokerBase()
+	cfg.HTTP.MaxInflightRequests = 100
+	cfg.HTTP.ClientIPRate = 10
+	cfg.HTTP.RouteRates = []RouteRate{{Path: "/api/v1/query/metric", Rate: 50}}
+	cfg.HTTP.BasePath = "/lindb"
+	cfg.HTTP.TrustedProxies = []string{"10.0.0.0/8"}
+	cfg.HTTP.CORS = CORS{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowHeaders:     []string{"X-Custom"},
+		AllowCredentials: true,
+		MaxAge:           ltoml.Duration(time.Hour),
+	}
+	assert.Contains(t, cfg.HTTP.TOML(), `path = "/api/v1/query/metric"`)
+	// rate limits are kept after decoding
+	cfgPath := filepath.Join(testPath, "broker_http.toml")
+	assert.Nil(t, ltoml.WriteConfig(cfgPath, cfg.TOML()+"\n\n"+NewDefaultLogging().TOML()))
+	var brokerCfg Broker
+	assert.Nil(t, ltoml.DecodeToml(cfgPath, &brokerCfg))
+	assert.Equal(t, cfg.HTTP, brokerCfg.BrokerBase.HTTP)
+}
+
+func Test_HTTP_GetBasePath(t *testing.T) {
+	for basePath, expect := range map[string]string{
+		"":         "",
+		"/":        "",
+		"lindb":    "/lindb",
+		"/lindb/":  "/lindb",
+		"/a/lindb": "/a/lindb",
+	} {
+		h := HTTP{BasePath: basePath}
+		assert.Equal(t, expect, h.GetBasePath(), basePath)
+	}
+}
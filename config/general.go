@@ -20,11 +20,15 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/lindb/lindb/pkg/ltoml"
 )
 
+// default ttl of etcd lease(seconds)
+const defaultLeaseTTL = 10
+
 // RepoState represents state repository config
 type RepoState struct {
 	Namespace   string         `toml:"namespace" json:"namespace"`
@@ -33,6 +37,7 @@ type RepoState struct {
 	DialTimeout ltoml.Duration `toml:"dial-timeout" json:"dialTimeout"`
 	Username    string         `toml:"username" json:"username"`
 	Password    string         `toml:"password" json:"password"`
+	LeaseTTL    ltoml.Duration `toml:"lease-ttl" json:"leaseTTL"`
 }
 
 // TOML returns RepoState's toml config string
@@ -51,28 +56,66 @@ func (rs *RepoState) TOML() string {
 	## Username is a user name for etcd authentication.
 	username = "%s"
 	## Password is a password for etcd authentication.
-	password = "%s"`,
+	password = "%s"
+	## LeaseTTL is the ttl of etcd lease for node registry and master election,
+	## node will be removed from active list if keepalive fails in ttl.
+	lease-ttl = "%s"`,
 		rs.Namespace,
 		coordinatorEndpoints,
 		rs.Timeout.String(),
 		rs.DialTimeout.String(),
 		rs.Username,
 		rs.Password,
+		rs.LeaseTTL.String(),
 	)
 }
 
+// LeaseTTLSeconds returns the lease ttl in seconds, returns default ttl if not set.
+func (rs *RepoState) LeaseTTLSeconds() int64 {
+	ttl := int64(rs.LeaseTTL.Duration().Seconds())
+	if ttl <= 0 {
+		return defaultLeaseTTL
+	}
+	return ttl
+}
+
 // GRPC represents grpc server config
 type GRPC struct {
-	Port uint16         `toml:"port"`
-	TTL  ltoml.Duration `toml:"ttl"`
+	Port        uint16         `toml:"port"`
+	TTL         ltoml.Duration `toml:"ttl"`
+	Compression string         `toml:"compression"`
 }
 
 func (g *GRPC) TOML() string {
 	return fmt.Sprintf(`
     port = %d
-    ttl = "%s"`,
+    ttl = "%s"
+
+    ## compression of replication and task streams sent by this node, none/gzip/snappy/zstd,
+    ## server side responds with the same compression.
+    compression = "%s"`,
 		g.Port,
 		g.TTL.String(),
+		g.Compression,
+	)
+}
+
+// Location represents the zone/rack labels of node, replicas of a shard are placed across distinct zones,
+// and broker prefers the replicas in its own zone when querying.
+type Location struct {
+	Zone string `toml:"zone"`
+	Rack string `toml:"rack"`
+}
+
+func (l *Location) TOML() string {
+	return fmt.Sprintf(`
+    ## zone(like availability zone) which node belongs to, empty means zone isn't labeled
+    zone = "%s"
+
+    ## rack which node belongs to, only for display
+    rack = "%s"`,
+		l.Zone,
+		l.Rack,
 	)
 }
 
@@ -80,13 +123,49 @@ func (g *GRPC) TOML() string {
 type StorageCluster struct {
 	Name   string    `json:"name" binding:"required"`
 	Config RepoState `json:"config"`
+	// ReadOnlyNodes are the nodes(ip:port) marked read-only by admin, which receive no new shard assignments
+	// and writes.
+	ReadOnlyNodes []string `json:"readOnlyNodes,omitempty"`
+}
+
+// IsReadOnlyNode returns if the node(ip:port) is marked read-only.
+func (s *StorageCluster) IsReadOnlyNode(node string) bool {
+	for _, n := range s.ReadOnlyNodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// SetReadOnlyNode marks/unmarks the node(ip:port) read-only, returns false if nothing changed.
+func (s *StorageCluster) SetReadOnlyNode(node string, readOnly bool) bool {
+	if s.IsReadOnlyNode(node) == readOnly {
+		return false
+	}
+	if readOnly {
+		s.ReadOnlyNodes = append(s.ReadOnlyNodes, node)
+		return true
+	}
+	var nodes []string
+	for _, n := range s.ReadOnlyNodes {
+		if n != node {
+			nodes = append(nodes, n)
+		}
+	}
+	s.ReadOnlyNodes = nodes
+	return true
 }
 
 // Query represents query rpc config
 type Query struct {
-	QueryConcurrency int            `toml:"query-concurrency"`
-	IdleTimeout      ltoml.Duration `toml:"idle-timeout"`
-	Timeout          ltoml.Duration `toml:"timeout"`
+	QueryConcurrency   int            `toml:"query-concurrency"`
+	IdleTimeout        ltoml.Duration `toml:"idle-timeout"`
+	Timeout            ltoml.Duration `toml:"timeout"`
+	ShardParallelism   int            `toml:"shard-parallelism"`
+	MaxPendingTasks    int            `toml:"max-pending-tasks"`
+	PendingTimeout     ltoml.Duration `toml:"pending-timeout"`
+	SlowQueryThreshold ltoml.Duration `toml:"slow-query-threshold"`
 }
 
 func (q *Query) TOML() string {
@@ -98,17 +177,48 @@ func (q *Query) TOML() string {
     idle-timeout = "%s"
 
     ## maximum timeout threshold for query.
-    timeout = "%s"`,
+    timeout = "%s"
+
+    ## maximum number of shards searched concurrently by one query in storage side,
+    ## 0 means the number of cpu.
+    shard-parallelism = %d
+
+    ## maximum number of query tasks waiting for idle worker.
+    max-pending-tasks = %d
+
+    ## maximum waiting time when pending queue is full, query task is rejected after timeout,
+    ## 0 means waiting until queue has free space.
+    pending-timeout = "%s"
+
+    ## query which takes longer than this threshold is logged into slow query log with its query id,
+    ## 0 means slow query log is disabled.
+    slow-query-threshold = "%s"`,
 		q.QueryConcurrency,
 		q.IdleTimeout,
 		q.Timeout,
+		q.ShardParallelism,
+		q.MaxPendingTasks,
+		q.PendingTimeout,
+		q.SlowQueryThreshold,
 	)
 }
 
+// GetShardParallelism returns the maximum number of shards searched concurrently by one query,
+// returns the number of cpu if not set.
+func (q *Query) GetShardParallelism() int {
+	if q.ShardParallelism <= 0 {
+		return runtime.NumCPU()
+	}
+	return q.ShardParallelism
+}
+
 func NewDefaultQuery() *Query {
 	return &Query{
-		QueryConcurrency: 30,
-		IdleTimeout:      ltoml.Duration(5 * time.Second),
-		Timeout:          ltoml.Duration(15 * time.Second),
+		QueryConcurrency:   30,
+		IdleTimeout:        ltoml.Duration(5 * time.Second),
+		Timeout:            ltoml.Duration(15 * time.Second),
+		MaxPendingTasks:    1024,
+		PendingTimeout:     ltoml.Duration(5 * time.Second),
+		SlowQueryThreshold: ltoml.Duration(5 * time.Second),
 	}
 }
@@ -29,11 +29,12 @@ var (
 
 // Logging represents a logging configuration
 type Logging struct {
-	Dir        string `toml:"dir"`
-	Level      string `toml:"level"`
-	MaxSize    uint16 `toml:"maxsize"`
-	MaxBackups uint16 `toml:"maxbackups"`
-	MaxAge     uint16 `toml:"maxage"`
+	Dir          string `toml:"dir"`
+	Level        string `toml:"level"`
+	ModuleLevels string `toml:"module-levels"`
+	MaxSize      uint16 `toml:"maxsize"`
+	MaxBackups   uint16 `toml:"maxbackups"`
+	MaxAge       uint16 `toml:"maxage"`
 }
 
 // TOML returns Logging's toml config string
@@ -47,6 +48,10 @@ func (l *Logging) TOML() string {
   ## error, warn, info, and debug are available
   level = "%s"
 
+  ## Overrides the log level of modules, format: module1:level1,module2:level2,
+  ## e.g. "replication:debug,tsdb:warn". Changes of levels take effect without restart.
+  module-levels = "%s"
+
   ## MaxSize is the maximum size in megabytes of the log file before it gets
   ## rotated. It defaults to 100 megabytes.
   maxsize = %d
@@ -64,6 +69,7 @@ func (l *Logging) TOML() string {
   maxage = %d`,
 		l.Dir,
 		l.Level,
+		l.ModuleLevels,
 		l.MaxSize,
 		l.MaxBackups,
 		l.MaxAge)
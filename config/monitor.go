@@ -34,6 +34,8 @@ type Monitor struct {
 	PushTimeout    ltoml.Duration `toml:"push-timeout"`
 	ReportInterval ltoml.Duration `toml:"report-interval"`
 	URL            string         `toml:"url"`
+	TraceURL       string         `toml:"trace-url"`
+	TraceRatio     float64        `toml:"trace-sample-ratio"`
 }
 
 // TOML returns Monitor's toml config
@@ -49,10 +51,19 @@ func (m *Monitor) TOML() string {
   report-interval = "%s"
 	
   ## URL is the target of broker native ingestion url
-  url = "%s"`,
+  url = "%s"
+
+  ## OTLP/HTTP trace receiver url of OpenTelemetry collector, such as http://127.0.0.1:4318/v1/traces
+  ## query tracing won't start when trace-url is empty
+  trace-url = "%s"
+
+  ## sample ratio(0~1) of query traces, 1 means all queries are traced
+  trace-sample-ratio = %.2f`,
 		m.PushTimeout.String(),
 		m.ReportInterval.String(),
 		m.URL,
+		m.TraceURL,
+		m.TraceRatio,
 	)
 }
 
@@ -62,5 +73,6 @@ func NewDefaultMonitor() *Monitor {
 		PushTimeout:    ltoml.Duration(3 * time.Second),
 		ReportInterval: ltoml.Duration(10 * time.Second),
 		URL:            defaultPusherURL,
+		TraceRatio:     1,
 	}
 }
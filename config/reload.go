@@ -0,0 +1,257 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+var (
+	// BrokerDynamicFields represents the fields of broker config which can be changed without restart.
+	BrokerDynamicFields = []string{
+		"broker.query.query-concurrency",
+		"broker.ingestion.max-timestamp-behind",
+		"broker.ingestion.max-timestamp-ahead",
+		"broker.tenant.quotas",
+		"monitor.report-interval",
+		"logging.level",
+		"logging.module-levels",
+	}
+	// StorageDynamicFields represents the fields of storage config which can be changed without restart.
+	StorageDynamicFields = []string{
+		"storage.query.query-concurrency",
+		"monitor.report-interval",
+		"logging.level",
+		"logging.module-levels",
+	}
+	// StandaloneDynamicFields represents the fields of standalone config which can be changed without restart.
+	StandaloneDynamicFields = []string{
+		"broker.query.query-concurrency",
+		"broker.ingestion.max-timestamp-behind",
+		"broker.ingestion.max-timestamp-ahead",
+		"broker.tenant.quotas",
+		"storage.query.query-concurrency",
+		"monitor.report-interval",
+		"logging.level",
+		"logging.module-levels",
+	}
+)
+
+// dynamicFieldValidators validates the new value of dynamic fields before applying.
+var dynamicFieldValidators = map[string]func(value interface{}) error{
+	"broker.query.query-concurrency":        validatePositiveInt,
+	"storage.query.query-concurrency":       validatePositiveInt,
+	"broker.ingestion.max-timestamp-behind": validateNonNegativeDuration,
+	"broker.ingestion.max-timestamp-ahead":  validateNonNegativeDuration,
+	"broker.tenant.quotas":                  validateQuotas,
+}
+
+// FieldChange represents the value change of config field.
+type FieldChange struct {
+	Path string `json:"path"` // dotted toml path, like broker.query.query-concurrency
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// String returns the string value of field change.
+func (fc FieldChange) String() string {
+	return fmt.Sprintf("%s: %s => %s", fc.Path, fc.Old, fc.New)
+}
+
+// ReloadReport represents the result of reloading config,
+// applied changes are dynamic fields, rejected changes are immutable fields which require restart.
+type ReloadReport struct {
+	Applied  []FieldChange `json:"applied,omitempty"`
+	Rejected []FieldChange `json:"rejected,omitempty"`
+}
+
+// NewReloadReport splits the changes into applied/rejected based on dynamic fields.
+func NewReloadReport(changes []FieldChange, dynamicFields []string) *ReloadReport {
+	dynamic := make(map[string]struct{}, len(dynamicFields))
+	for _, field := range dynamicFields {
+		dynamic[field] = struct{}{}
+	}
+	report := &ReloadReport{}
+	for _, change := range changes {
+		if _, ok := dynamic[change.Path]; ok {
+			report.Applied = append(report.Applied, change)
+		} else {
+			report.Rejected = append(report.Rejected, change)
+		}
+	}
+	return report
+}
+
+// IsApplied returns true if the change of field is applied.
+func (r *ReloadReport) IsApplied(path string) bool {
+	for _, change := range r.Applied {
+		if change.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Reject moves the applied change of field into rejected changes,
+// used when the dynamic field cannot be applied in current state.
+func (r *ReloadReport) Reject(path string) {
+	for idx, change := range r.Applied {
+		if change.Path == path {
+			r.Applied = append(r.Applied[:idx], r.Applied[idx+1:]...)
+			r.Rejected = append(r.Rejected, change)
+			return
+		}
+	}
+}
+
+// Validate validates the new values of applied dynamic fields,
+// if returns error, reload must be aborted so that the old config is kept.
+func (r *ReloadReport) Validate(newCfg interface{}) error {
+	for _, change := range r.Applied {
+		validator, ok := dynamicFieldValidators[change.Path]
+		if !ok {
+			continue
+		}
+		value, ok := fieldValue(newCfg, change.Path)
+		if !ok {
+			continue
+		}
+		if err := validator(value); err != nil {
+			return fmt.Errorf("invalid value of %s: %s", change.Path, err)
+		}
+	}
+	return nil
+}
+
+// String returns the readable report of reloading config.
+func (r *ReloadReport) String() string {
+	if len(r.Applied) == 0 && len(r.Rejected) == 0 {
+		return "no changes"
+	}
+	var sb strings.Builder
+	for _, change := range r.Applied {
+		sb.WriteString("applied: ")
+		sb.WriteString(change.String())
+		sb.WriteString("; ")
+	}
+	for _, change := range r.Rejected {
+		sb.WriteString("rejected(restart required): ")
+		sb.WriteString(change.String())
+		sb.WriteString("; ")
+	}
+	return strings.TrimSuffix(sb.String(), "; ")
+}
+
+// DiffFields returns the changed leaf fields between old and new config(same type),
+// the path of field is joined by toml tags.
+func DiffFields(oldCfg, newCfg interface{}) []FieldChange {
+	var changes []FieldChange
+	diffValue("", reflect.Indirect(reflect.ValueOf(oldCfg)), reflect.Indirect(reflect.ValueOf(newCfg)), &changes)
+	return changes
+}
+
+func diffValue(path string, oldVal, newVal reflect.Value, changes *[]FieldChange) {
+	if oldVal.Kind() == reflect.Struct && !isLeafStruct(oldVal) {
+		t := oldVal.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				// unexported field
+				continue
+			}
+			name := strings.Split(field.Tag.Get("toml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValue(name, oldVal.Field(i), newVal.Field(i), changes)
+		}
+		return
+	}
+	if reflect.DeepEqual(oldVal.Interface(), newVal.Interface()) {
+		return
+	}
+	*changes = append(*changes, FieldChange{
+		Path: path,
+		Old:  fmt.Sprint(oldVal.Interface()),
+		New:  fmt.Sprint(newVal.Interface()),
+	})
+}
+
+// fieldValue returns the value of field by the path joined by toml tags.
+func fieldValue(cfg interface{}, path string) (interface{}, bool) {
+	val := reflect.Indirect(reflect.ValueOf(cfg))
+	for _, name := range strings.Split(path, ".") {
+		if val.Kind() != reflect.Struct {
+			return nil, false
+		}
+		t := val.Type()
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			if strings.Split(t.Field(i).Tag.Get("toml"), ",")[0] == name {
+				val = val.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return val.Interface(), true
+}
+
+func validatePositiveInt(value interface{}) error {
+	if v, ok := value.(int); !ok || v <= 0 {
+		return fmt.Errorf("should be greater than 0")
+	}
+	return nil
+}
+
+func validateNonNegativeDuration(value interface{}) error {
+	if v, ok := value.(ltoml.Duration); !ok || v < 0 {
+		return fmt.Errorf("cannot be negative")
+	}
+	return nil
+}
+
+func validateQuotas(value interface{}) error {
+	quotas, ok := value.([]NamespaceQuota)
+	if !ok {
+		return fmt.Errorf("unexpected type: %T", value)
+	}
+	tenant := &Tenant{Quotas: quotas}
+	return tenant.Validate()
+}
+
+// isLeafStruct returns true if the struct has no toml tagged fields, like time.Time.
+func isLeafStruct(val reflect.Value) bool {
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("toml") != "" {
+			return false
+		}
+	}
+	return true
+}
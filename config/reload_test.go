@@ -0,0 +1,175 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+func Test_DiffFields(t *testing.T) {
+	oldCfg := &Broker{BrokerBase: *NewDefaultBrokerBase(), Logging: *NewDefaultLogging(), Monitor: *NewDefaultMonitor()}
+	newCfg := &Broker{BrokerBase: *NewDefaultBrokerBase(), Logging: *NewDefaultLogging(), Monitor: *NewDefaultMonitor()}
+	assert.Empty(t, DiffFields(oldCfg, newCfg))
+
+	newCfg.BrokerBase.Query.QueryConcurrency = 10
+	newCfg.BrokerBase.HTTP.Port = 9999
+	newCfg.BrokerBase.Coordinator.Endpoints = []string{"http://127.0.0.1:2379"}
+	newCfg.Logging.Level = "debug"
+	changes := DiffFields(oldCfg, newCfg)
+	assert.Equal(t, []FieldChange{
+		{Path: "broker.coordinator.endpoints", Old: "[http://localhost:2379]", New: "[http://127.0.0.1:2379]"},
+		{Path: "broker.query.query-concurrency", Old: "30", New: "10"},
+		{Path: "broker.http.port", Old: "9000", New: "9999"},
+		{Path: "logging.level", Old: "info", New: "debug"},
+	}, changes)
+
+	report := NewReloadReport(changes, BrokerDynamicFields)
+	assert.Len(t, report.Applied, 2)
+	assert.Len(t, report.Rejected, 2)
+	assert.True(t, report.IsApplied("logging.level"))
+	assert.False(t, report.IsApplied("broker.http.port"))
+	assert.Equal(t, "applied: broker.query.query-concurrency: 30 => 10; applied: logging.level: info => debug; "+
+		"rejected(restart required): broker.coordinator.endpoints: [http://localhost:2379] => [http://127.0.0.1:2379]; "+
+		"rejected(restart required): broker.http.port: 9000 => 9999", report.String())
+	assert.Equal(t, "no changes", NewReloadReport(nil, BrokerDynamicFields).String())
+
+	report.Reject("logging.level")
+	report.Reject("not-exist")
+	assert.False(t, report.IsApplied("logging.level"))
+	assert.Len(t, report.Applied, 1)
+	assert.Len(t, report.Rejected, 3)
+}
+
+func Test_DiffFields_nested(t *testing.T) {
+	oldCfg := &Standalone{
+		BrokerBase:  *NewDefaultBrokerBase(),
+		StorageBase: *NewDefaultStorageBase(),
+		Logging:     *NewDefaultLogging(),
+		Monitor:     *NewDefaultMonitor(),
+	}
+	newCfg := *oldCfg
+	newCfg.BrokerBase.Tenant.Quotas = []NamespaceQuota{{Namespace: "ns", WriteRate: 100}}
+	newCfg.StorageBase.Query.QueryConcurrency = 5
+	newCfg.Monitor.ReportInterval = ltoml.Duration(time.Minute)
+	changes := DiffFields(oldCfg, &newCfg)
+	assert.Equal(t, []FieldChange{
+		{Path: "broker.tenant.quotas", Old: "[]", New: "[{ns 100 0 0}]"},
+		{Path: "storage.query.query-concurrency", Old: "30", New: "5"},
+		{Path: "monitor.report-interval", Old: "10s", New: "1m0s"},
+	}, changes)
+}
+
+func Test_NewReloadReport(t *testing.T) {
+	examples := []struct {
+		dynamicFields []string
+		path          string
+		applied       bool
+	}{
+		{BrokerDynamicFields, "broker.query.query-concurrency", true},
+		{BrokerDynamicFields, "broker.ingestion.max-timestamp-behind", true},
+		{BrokerDynamicFields, "broker.ingestion.max-timestamp-ahead", true},
+		{BrokerDynamicFields, "broker.tenant.quotas", true},
+		{BrokerDynamicFields, "logging.level", true},
+		{BrokerDynamicFields, "monitor.report-interval", true},
+		{BrokerDynamicFields, "broker.http.port", false},
+		{BrokerDynamicFields, "storage.query.query-concurrency", false},
+		{StorageDynamicFields, "storage.query.query-concurrency", true},
+		{StorageDynamicFields, "logging.module-levels", true},
+		{StorageDynamicFields, "storage.tsdb.dir", false},
+		{StorageDynamicFields, "storage.grpc.port", false},
+		{StandaloneDynamicFields, "broker.query.query-concurrency", true},
+		{StandaloneDynamicFields, "storage.query.query-concurrency", true},
+		{StandaloneDynamicFields, "etcd.dir", false},
+	}
+	for _, example := range examples {
+		report := NewReloadReport([]FieldChange{{Path: example.path, Old: "1", New: "2"}}, example.dynamicFields)
+		assert.Equal(t, example.applied, report.IsApplied(example.path))
+		if example.applied {
+			assert.Len(t, report.Applied, 1)
+			assert.Empty(t, report.Rejected)
+		} else {
+			assert.Empty(t, report.Applied)
+			assert.Equal(t, []FieldChange{{Path: example.path, Old: "1", New: "2"}}, report.Rejected)
+		}
+	}
+}
+
+func Test_ReloadReport_Validate(t *testing.T) {
+	oldCfg := &Broker{BrokerBase: *NewDefaultBrokerBase(), Logging: *NewDefaultLogging(), Monitor: *NewDefaultMonitor()}
+	validate := func(update func(cfg *Broker)) error {
+		newCfg := *oldCfg
+		newCfg.BrokerBase.Tenant.Quotas = nil
+		update(&newCfg)
+		report := NewReloadReport(DiffFields(oldCfg, &newCfg), BrokerDynamicFields)
+		return report.Validate(&newCfg)
+	}
+	// case 1: no changes
+	assert.NoError(t, validate(func(cfg *Broker) {}))
+	// case 2: valid dynamic fields
+	assert.NoError(t, validate(func(cfg *Broker) {
+		cfg.BrokerBase.Query.QueryConcurrency = 10
+		cfg.BrokerBase.Ingestion.MaxTimestampBehind = 0
+		cfg.BrokerBase.Tenant.Quotas = []NamespaceQuota{{Namespace: "ns", WriteRate: 100}}
+		cfg.Logging.Level = "debug"
+	}))
+	// case 3: invalid immutable field is not validated
+	assert.NoError(t, validate(func(cfg *Broker) {
+		cfg.BrokerBase.HTTP.ReadTimeout = ltoml.Duration(-time.Second)
+	}))
+	// case 4: query concurrency is 0
+	assert.Error(t, validate(func(cfg *Broker) {
+		cfg.BrokerBase.Query.QueryConcurrency = 0
+	}))
+	// case 5: negative timestamp behind
+	assert.Error(t, validate(func(cfg *Broker) {
+		cfg.BrokerBase.Ingestion.MaxTimestampBehind = ltoml.Duration(-time.Hour)
+	}))
+	// case 6: negative timestamp ahead
+	assert.Error(t, validate(func(cfg *Broker) {
+		cfg.BrokerBase.Ingestion.MaxTimestampAhead = ltoml.Duration(-time.Hour)
+	}))
+	// case 7: negative quota
+	assert.Error(t, validate(func(cfg *Broker) {
+		cfg.BrokerBase.Tenant.Quotas = []NamespaceQuota{{Namespace: "ns", WriteRate: -1}}
+	}))
+	// case 8: duplicate namespace quota
+	assert.Error(t, validate(func(cfg *Broker) {
+		cfg.BrokerBase.Tenant.Quotas = []NamespaceQuota{{Namespace: "ns"}, {Namespace: "ns"}}
+	}))
+}
+
+func Test_fieldValue(t *testing.T) {
+	cfg := &Storage{StorageBase: *NewDefaultStorageBase()}
+	value, ok := fieldValue(cfg, "storage.query.query-concurrency")
+	assert.True(t, ok)
+	assert.Equal(t, 30, value)
+	_, ok = fieldValue(cfg, "storage.not-exist")
+	assert.False(t, ok)
+	_, ok = fieldValue(cfg, "storage.query.query-concurrency.not-exist")
+	assert.False(t, ok)
+
+	// validator with unexpected type
+	assert.Error(t, validatePositiveInt("1"))
+	assert.Error(t, validateNonNegativeDuration(1))
+	assert.Error(t, validateQuotas(1))
+}
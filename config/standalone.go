@@ -35,6 +35,9 @@ type Standalone struct {
 type ETCD struct {
 	Dir string `toml:"dir"`
 	URL string `toml:"url"`
+	// External is true if using external etcd cluster configured by coordinator of broker/storage,
+	// instead of starting embedded etcd server.
+	External bool `toml:"external"`
 }
 
 // TOML returns ETCD's toml config string
@@ -51,9 +54,15 @@ func (etcd *ETCD) TOML() string {
   ## etcd will listen on the given port and interface.
   ## example: http://10.0.0.1:2379
   url = "%s"
+
+  ## Whether to use the external etcd cluster configured by coordinator of broker and storage,
+  ## instead of starting embedded etcd server.
+  ## Default: false, broker and storage connect to the embedded etcd server.
+  external = %v
 `,
 		etcd.Dir,
-		etcd.URL)
+		etcd.URL,
+		etcd.External)
 }
 
 // NewDefaultETCD returns a default ETCD
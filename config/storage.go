@@ -27,30 +27,98 @@ import (
 
 // TSDB represents the tsdb configuration
 type TSDB struct {
-	Dir string `toml:"dir"`
+	Dir                       string         `toml:"dir"`
+	MaxCompactionConcurrency  int            `toml:"max-compaction-concurrency"`
+	CompactionThroughput      ltoml.Size     `toml:"compaction-throughput"`
+	CompactCheckInterval      ltoml.Duration `toml:"compact-check-interval"`
+	MaxMemDBTotalSize         ltoml.Size     `toml:"max-memdb-total-size"`
+	MemoryHighWaterMark       float64        `toml:"memory-high-watermark"`
+	MemoryLowWaterMark        float64        `toml:"memory-low-watermark"`
+	MaxWriteRate              int            `toml:"max-write-rate"`
+	QueryLatencyHighWaterMark ltoml.Duration `toml:"query-latency-high-watermark"`
+	QueryLatencyLowWaterMark  ltoml.Duration `toml:"query-latency-low-watermark"`
+	MemDBCriticalWaterMark    float64        `toml:"memdb-critical-watermark"`
+	MaxBackgroundIODeferral   ltoml.Duration `toml:"max-background-io-deferral"`
+	HistoricalFamilyIdleTTL   ltoml.Duration `toml:"historical-family-idle-ttl"`
 }
 
 func (t *TSDB) TOML() string {
 	return fmt.Sprintf(`
     ## where the tsdb data is stored
-    dir = "%s"`,
+    dir = "%s"
+
+    ## number of data family compaction jobs allowed to execute concurrently
+    max-compaction-concurrency = %d
+
+    ## max bytes written per second by all compaction jobs, 0 means no limit
+    compaction-throughput = "%s"
+
+    ## interval of checking if data family need to compact
+    compact-check-interval = "%s"
+
+    ## max total memory size of all memory databases on this node, 0 means no limit
+    max-memdb-total-size = "%s"
+
+    ## when memory databases' total size/process rss(percent of total memory) is above high watermark,
+    ## the biggest families will be flushed until the memory usage is below low watermark
+    memory-high-watermark = %.1f
+    memory-low-watermark = %.1f
+
+    ## max number of metrics applied per second on this node, shared by databases based on write weight,
+    ## 0 means no limit
+    max-write-rate = %d
+
+    ## when average latency of storage queries is above high watermark, background flush/compaction are
+    ## deferred until the latency is below low watermark, 0 means never defer
+    query-latency-high-watermark = "%s"
+    query-latency-low-watermark = "%s"
+
+    ## when memory databases' total size(percent of max-memdb-total-size) is above critical watermark,
+    ## query io is capped until the size is below memory-low-watermark, 0 means never cap
+    memdb-critical-watermark = %.1f
+
+    ## max time of background flush/compaction deferred by slow queries, 0 means no limit
+    max-background-io-deferral = "%s"
+
+    ## memory database of historical family(family time window has passed, written by late/backfill data)
+    ## is flushed if no data written within idle ttl, it will be reopened when late data comes again,
+    ## 0 means never flush by idle ttl
+    historical-family-idle-ttl = "%s"`,
 		t.Dir,
+		t.MaxCompactionConcurrency,
+		t.CompactionThroughput.String(),
+		t.CompactCheckInterval.String(),
+		t.MaxMemDBTotalSize.String(),
+		t.MemoryHighWaterMark,
+		t.MemoryLowWaterMark,
+		t.MaxWriteRate,
+		t.QueryLatencyHighWaterMark.String(),
+		t.QueryLatencyLowWaterMark.String(),
+		t.MemDBCriticalWaterMark,
+		t.MaxBackgroundIODeferral.String(),
+		t.HistoricalFamilyIdleTTL.String(),
 	)
 }
 
 // StorageBase represents a storage configuration
 type StorageBase struct {
+	ReadOnly    bool      `toml:"read-only"`
 	Coordinator RepoState `toml:"coordinator"`
 	GRPC        GRPC      `toml:"grpc"`
 	TSDB        TSDB      `toml:"tsdb"`
 	Query       Query     `toml:"query"`
 	Replica     Replica   `toml:"replica"`
+	Location    Location  `toml:"location"`
 }
 
 // TOML returns StorageBase's toml config string
 func (s *StorageBase) TOML() string {
 	return fmt.Sprintf(`## Config for the Storage Node
 [storage]
+  ## read-only storage node receives no new shard assignments and writes, queries still work,
+  ## used for draining hardware or archive node which holds cold shards.
+  read-only = %v
+
   [storage.coordinator]%s
   
   [storage.query]%s
@@ -58,11 +126,15 @@ func (s *StorageBase) TOML() string {
   [storage.grpc]%s
 
   [storage.tsdb]%s
+
+  [storage.location]%s
 `,
+		s.ReadOnly,
 		s.Coordinator.TOML(),
 		s.Query.TOML(),
 		s.GRPC.TOML(),
 		s.TSDB.TOML(),
+		s.Location.TOML(),
 	)
 }
 
@@ -102,12 +174,25 @@ func NewDefaultStorageBase() *StorageBase {
 			Endpoints:   []string{"http://localhost:2379"},
 			Timeout:     ltoml.Duration(time.Second * 10),
 			DialTimeout: ltoml.Duration(time.Second * 5),
+			LeaseTTL:    ltoml.Duration(time.Second * defaultLeaseTTL),
 		},
 		GRPC: GRPC{
 			Port: 2891,
 			TTL:  ltoml.Duration(time.Second)},
 		TSDB: TSDB{
-			Dir: filepath.Join(defaultParentDir, "storage/data")},
+			Dir:                       filepath.Join(defaultParentDir, "storage/data"),
+			MaxCompactionConcurrency:  2,
+			CompactionThroughput:      ltoml.Size(32 * 1024 * 1024),
+			CompactCheckInterval:      ltoml.Duration(time.Minute),
+			MaxMemDBTotalSize:         ltoml.Size(4 * 1024 * 1024 * 1024),
+			MemoryHighWaterMark:       80,
+			MemoryLowWaterMark:        60,
+			QueryLatencyHighWaterMark: ltoml.Duration(5 * time.Second),
+			QueryLatencyLowWaterMark:  ltoml.Duration(2 * time.Second),
+			MemDBCriticalWaterMark:    95,
+			MaxBackgroundIODeferral:   ltoml.Duration(time.Minute),
+			HistoricalFamilyIdleTTL:   ltoml.Duration(10 * time.Minute),
+		},
 		Query: *NewDefaultQuery(),
 	}
 }
@@ -33,6 +33,13 @@ const (
 	NodeSeqPath = "/active/nodes/seq"
 	// StateNodesPath represents the state of node that node will report runtime status
 	StateNodesPath = "/state/nodes"
+	// ClusterConfigPath represents cluster-wide operational config path,
+	// master syncs it from broker's repo into storage cluster's repo.
+	ClusterConfigPath = "/cluster/config"
+	// ReplicaVerifyPath represents the result path of replica verify task that storage node reports
+	ReplicaVerifyPath = "/verify/replica"
+	// ReplicaRepairPath represents the path which marks replica verify task has been repaired
+	ReplicaRepairPath = "/verify/repair"
 )
 
 // defines storage level constants will be used in storage
@@ -48,6 +55,14 @@ const (
 	StorageClusterConfigPath = "/storage/cluster/config"
 	// DatabaseConfigPath represents database config path
 	DatabaseConfigPath = "/database/config"
+	// QueryDefaultsConfigPath represents cluster-wide query defaults config path
+	QueryDefaultsConfigPath = "/query/config/defaults"
+	// AlertRulePath represents alert rule config path
+	AlertRulePath = "/alert/rule"
+	// AuditLogPath represents audit log entry path of admin operations
+	AuditLogPath = "/audit/log"
+	// AuditLogSeqPath represents audit log entry id generate path
+	AuditLogSeqPath = "/audit/seq"
 
 	// StorageClusterNodeStatePath represents storage cluster's node state
 	StorageClusterNodeStatePath = "/state/storage/nodes/cluster"
@@ -63,6 +78,12 @@ const (
 	CreateShard task.Kind = "create-shard"
 	// FlushDatabase represents task kind which is flush memory database for storage node
 	FlushDatabase task.Kind = "flush-database"
+	// UpdateDatabaseOption represents task kind which is update database option for storage node
+	UpdateDatabaseOption task.Kind = "update-database-option"
+	// ReplicaVerify represents task kind which is computing family checksums of shard replicas for storage node
+	ReplicaVerify task.Kind = "replica-verify"
+	// ReplicaRepair represents task kind which is copying missing families from other replica for storage node
+	ReplicaRepair task.Kind = "replica-repair"
 )
 
 // GetStorageClusterConfigPath returns path which storing config of storage cluster
@@ -85,6 +106,17 @@ func GetDatabaseConfigPath(name string) string {
 	return fmt.Sprintf("%s/%s", DatabaseConfigPath, name)
 }
 
+// GetAlertRulePath returns path which storing alert rule
+func GetAlertRulePath(name string) string {
+	return fmt.Sprintf("%s/%s", AlertRulePath, name)
+}
+
+// GetAuditLogPath returns path which storing audit log entry,
+// id is zero padded so that entries are sorted by id.
+func GetAuditLogPath(id int64) string {
+	return fmt.Sprintf("%s/%020d", AuditLogPath, id)
+}
+
 // GetDatabaseAssignPath returns path which storing shard assignment of database
 func GetDatabaseAssignPath(name string) string {
 	return fmt.Sprintf("%s/%s", DatabaseAssignPath, name)
@@ -119,3 +151,18 @@ func GetReplicaStatePath(node string) string {
 func GetNodeMonitoringStatPath(node string) string {
 	return fmt.Sprintf("%s/%s", StateNodesPath, node)
 }
+
+// GetReplicaVerifyPath returns the path which storing the results of replica verify task
+func GetReplicaVerifyPath(databaseName, name string) string {
+	return fmt.Sprintf("%s/%s/%s", ReplicaVerifyPath, databaseName, name)
+}
+
+// GetReplicaVerifyResultPath returns the path which storing the result of replica verify task of storage node
+func GetReplicaVerifyResultPath(databaseName, name, node string) string {
+	return fmt.Sprintf("%s/%s", GetReplicaVerifyPath(databaseName, name), node)
+}
+
+// GetReplicaRepairPath returns the path which marks the replica verify task has been repaired
+func GetReplicaRepairPath(databaseName, name string) string {
+	return fmt.Sprintf("%s/%s/%s", ReplicaRepairPath, databaseName, name)
+}
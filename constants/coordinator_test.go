@@ -31,6 +31,15 @@ func TestGetDatabaseConfigPath(t *testing.T) {
 	assert.Equal(t, DatabaseConfigPath+"/name", GetDatabaseConfigPath("name"))
 }
 
+func TestGetAlertRulePath(t *testing.T) {
+	assert.Equal(t, AlertRulePath+"/name", GetAlertRulePath("name"))
+}
+
+func TestGetAuditLogPath(t *testing.T) {
+	assert.Equal(t, AuditLogPath+"/00000000000000000012", GetAuditLogPath(12))
+	assert.True(t, GetAuditLogPath(9) < GetAuditLogPath(10))
+}
+
 func TestGetNodePath(t *testing.T) {
 	assert.Equal(t, ActiveNodesPath+"/name", GetActiveNodePath("name"))
 }
@@ -58,3 +67,9 @@ func TestGetNodeMonitoringStatPath(t *testing.T) {
 func TestGetNodeIDPath(t *testing.T) {
 	assert.Equal(t, NodesPath+"/ids/1.1.1.1:port", GetNodeIDPath("1.1.1.1:port"))
 }
+
+func TestGetReplicaVerifyResultPath(t *testing.T) {
+	assert.Equal(t, ReplicaVerifyPath+"/db/name", GetReplicaVerifyPath("db", "name"))
+	assert.Equal(t, ReplicaVerifyPath+"/db/name/1.1.1.1:port", GetReplicaVerifyResultPath("db", "name", "1.1.1.1:port"))
+	assert.Equal(t, ReplicaRepairPath+"/db/name", GetReplicaRepairPath("db", "name"))
+}
@@ -29,20 +29,21 @@ var (
 	ErrTagFilterResultNotFound      = fmt.Errorf("tagFilter result %w", ErrNotFound)
 	ErrTagValueFilterResultNotFound = fmt.Errorf("tagValueFitler result %w", ErrNotFound)
 
-	ErrDatabaseNotFound        = fmt.Errorf("database %w", ErrNotFound)
-	ErrShardNotFound           = fmt.Errorf("shard %w", ErrNotFound)
-	ErrNameSpaceBucketNotFound = fmt.Errorf("namespace bucket %w", ErrNotFound)
-	ErrMetricIDNotFound        = fmt.Errorf("metricID %w", ErrNotFound)
-	ErrMetricBucketNotFound    = fmt.Errorf("metric bucket %w", ErrNotFound)
-	ErrHistogramFieldNotFound  = fmt.Errorf("histogram field %w", ErrNotFound)
-	ErrTagKeyIDNotFound        = fmt.Errorf("tagKeyID %w", ErrNotFound)
-	ErrTagKeyMetaNotFound      = fmt.Errorf("tagKeyMeta %w", ErrNotFound)
-	ErrTagValueSeqNotFound     = fmt.Errorf("tagValueSeq %w", ErrNotFound)
-	ErrTagValueIDNotFound      = fmt.Errorf("tagValueID %w", ErrNotFound)
-	ErrFieldNotFound           = fmt.Errorf("field %w", ErrNotFound)
-	ErrFieldBucketNotFound     = fmt.Errorf("field bucket %w", ErrNotFound)
-	ErrSeriesIDNotFound        = fmt.Errorf("seriesID %w", ErrNotFound)
-	ErrDataFamilyNotFound      = fmt.Errorf("data family %w", ErrNotFound)
+	ErrDatabaseNotFound         = fmt.Errorf("database %w", ErrNotFound)
+	ErrShardNotFound            = fmt.Errorf("shard %w", ErrNotFound)
+	ErrNameSpaceBucketNotFound  = fmt.Errorf("namespace bucket %w", ErrNotFound)
+	ErrMetricIDNotFound         = fmt.Errorf("metricID %w", ErrNotFound)
+	ErrMetricBucketNotFound     = fmt.Errorf("metric bucket %w", ErrNotFound)
+	ErrMetricDescriptorNotFound = fmt.Errorf("metric descriptor %w", ErrNotFound)
+	ErrHistogramFieldNotFound   = fmt.Errorf("histogram field %w", ErrNotFound)
+	ErrTagKeyIDNotFound         = fmt.Errorf("tagKeyID %w", ErrNotFound)
+	ErrTagKeyMetaNotFound       = fmt.Errorf("tagKeyMeta %w", ErrNotFound)
+	ErrTagValueSeqNotFound      = fmt.Errorf("tagValueSeq %w", ErrNotFound)
+	ErrTagValueIDNotFound       = fmt.Errorf("tagValueID %w", ErrNotFound)
+	ErrFieldNotFound            = fmt.Errorf("field %w", ErrNotFound)
+	ErrFieldBucketNotFound      = fmt.Errorf("field bucket %w", ErrNotFound)
+	ErrSeriesIDNotFound         = fmt.Errorf("seriesID %w", ErrNotFound)
+	ErrDataFamilyNotFound       = fmt.Errorf("data family %w", ErrNotFound)
 
 	ErrMetricOutOfTimeRange = errors.New("metric's timestamp is out of timerange")
 	// ErrBadMetricPBFormat represents write bad pb format
@@ -59,6 +60,10 @@ var (
 	ErrMetricNanField = fmt.Errorf("%w, field is not a number", ErrBadMetricPBFormat)
 	// ErrMetricInfField represents field value is infinity, positive or negative
 	ErrMetricInfField = fmt.Errorf("%w, field is infinity", ErrBadMetricPBFormat)
+	// ErrMetricStringFieldTooLong represents string field value exceeds the max length
+	ErrMetricStringFieldTooLong = fmt.Errorf("%w, string field is too long", ErrBadMetricPBFormat)
+	// ErrStringValueConflict represents different string values hashed to same string value id
+	ErrStringValueConflict = errors.New("string value id conflicts with stored value")
 
 	// ErrDataFileCorruption represents data in tsdb's file is corrupted
 	ErrDataFileCorruption = errors.New("data corruption")
@@ -66,4 +71,7 @@ var (
 	ErrInfluxLineTooLong = errors.New("influx line is too long")
 
 	ErrBadEnrichTagQueryFormat = errors.New("enrich_tag has the wrong format")
+
+	// ErrTagKeyNotNumeric represents range filter used on tag key which is not declared as numeric
+	ErrTagKeyNotNumeric = errors.New("tag key is not declared as numeric")
 )
@@ -31,6 +31,8 @@ type BrokerStateMachines struct {
 	ReplicaStatusSM broker.ReplicaStatusStateMachine
 	ReplicatorSM    replica.ReplicatorStateMachine
 	DatabaseSM      broker.DatabaseStateMachine
+	QueryDefaultsSM broker.QueryDefaultsStateMachine
+	ClusterConfigSM discovery.ClusterConfigStateMachine
 
 	factory StateMachineFactory
 
@@ -71,10 +73,33 @@ func (s *BrokerStateMachines) Start() (err error) {
 	if err != nil {
 		return err
 	}
+	s.log.Debug("starting QueryDefaultsStateMachine")
+	s.QueryDefaultsSM, err = s.factory.CreateQueryDefaultsStateMachine()
+	if err != nil {
+		return err
+	}
+	s.log.Debug("starting ClusterConfigStateMachine")
+	s.ClusterConfigSM, err = s.factory.CreateClusterConfigStateMachine()
+	if err != nil {
+		return err
+	}
 	s.log.Info("started BrokerStateMachines")
 	return nil
 }
 
+// TopologyVersion returns the version of cluster topology which the broker sees,
+// changed when broker node or storage cluster state changed.
+func (s *BrokerStateMachines) TopologyVersion() int64 {
+	var version int64
+	if s.NodeSM != nil {
+		version += s.NodeSM.TopologyVersion()
+	}
+	if s.StorageSM != nil {
+		version += s.StorageSM.TopologyVersion()
+	}
+	return version
+}
+
 // Stop stops the broker's state machines
 func (s *BrokerStateMachines) Stop() {
 	if s.StorageSM != nil {
@@ -102,4 +127,14 @@ func (s *BrokerStateMachines) Stop() {
 			s.log.Error("close database state machine error", logger.Error(err))
 		}
 	}
+	if s.QueryDefaultsSM != nil {
+		if err := s.QueryDefaultsSM.Close(); err != nil {
+			s.log.Error("close query defaults state machine error", logger.Error(err))
+		}
+	}
+	if s.ClusterConfigSM != nil {
+		if err := s.ClusterConfigSM.Close(); err != nil {
+			s.log.Error("close cluster config state machine error", logger.Error(err))
+		}
+	}
 }
@@ -30,6 +30,7 @@ import (
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/replication"
 
 	"go.uber.org/atomic"
 )
@@ -49,6 +50,8 @@ type DatabaseStateMachine interface {
 // databaseStateMachine implements DatabaseStateMachine
 type databaseStateMachine struct {
 	discovery discovery.Discovery
+	// applies database option to metric validation of writes, nil means not applied
+	cm replication.ChannelManager
 
 	databases map[string]models.Database
 	running   *atomic.Bool
@@ -64,12 +67,14 @@ type databaseStateMachine struct {
 func NewDatabaseStateMachine(
 	ctx context.Context,
 	discoveryFactory discovery.Factory,
+	cm replication.ChannelManager,
 ) (DatabaseStateMachine, error) {
 	c, cancel := context.WithCancel(ctx)
 	// new admin state machine instance
 	stateMachine := &databaseStateMachine{
 		ctx:       c,
 		cancel:    cancel,
+		cm:        cm,
 		running:   atomic.NewBool(false),
 		databases: make(map[string]models.Database),
 		logger:    logger.GetLogger("coordinator", "DatabaseStateMachine"),
@@ -108,6 +113,9 @@ func (sm *databaseStateMachine) OnCreate(key string, resource []byte) {
 	defer sm.mutex.Unlock()
 
 	sm.databases[cfg.Name] = cfg
+	if sm.cm != nil {
+		sm.cm.UpdateDatabaseOption(cfg.Name, cfg.Option)
+	}
 }
 
 // OnDelete removes database config from list when database deletion.
@@ -121,6 +129,9 @@ func (sm *databaseStateMachine) OnDelete(key string) {
 	defer sm.mutex.Unlock()
 
 	delete(sm.databases, databaseName)
+	if sm.cm != nil {
+		sm.cm.RemoveDatabaseOption(databaseName)
+	}
 }
 
 // GetDatabaseCfg returns the database config by name.
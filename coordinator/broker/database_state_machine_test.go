@@ -28,6 +28,7 @@ import (
 	"github.com/lindb/lindb/coordinator/discovery"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/replication"
 )
 
 func TestNewDatabaseStateMachine(t *testing.T) {
@@ -40,12 +41,12 @@ func TestNewDatabaseStateMachine(t *testing.T) {
 
 	// case 1: discovery err
 	discovery1.EXPECT().Discovery(true).Return(fmt.Errorf("err"))
-	_, err := NewDatabaseStateMachine(context.TODO(), factory)
+	_, err := NewDatabaseStateMachine(context.TODO(), factory, nil)
 	assert.Error(t, err)
 
 	// case 2: normal case
 	discovery1.EXPECT().Discovery(true).Return(nil)
-	stateMachine, err := NewDatabaseStateMachine(context.TODO(), factory)
+	stateMachine, err := NewDatabaseStateMachine(context.TODO(), factory, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, stateMachine)
 }
@@ -59,9 +60,13 @@ func TestDBStateMachine_listen(t *testing.T) {
 	// normal case
 	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
 	discovery1.EXPECT().Discovery(true).Return(nil)
-	stateMachine, err := NewDatabaseStateMachine(context.TODO(), factory)
+	cm := replication.NewMockChannelManager(ctrl)
+	stateMachine, err := NewDatabaseStateMachine(context.TODO(), factory, cm)
 	assert.NoError(t, err)
 
+	cm.EXPECT().UpdateDatabaseOption("test", gomock.Any())
+	cm.EXPECT().UpdateDatabaseOption("test3", gomock.Any())
+	cm.EXPECT().RemoveDatabaseOption("test")
 	db := models.Database{Name: "test"}
 	data := encoding.JSONMarshal(&db)
 	stateMachine.OnCreate("/data/test", data)
@@ -0,0 +1,142 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/discovery"
+	"github.com/lindb/lindb/coordinator/inif"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+
+	"go.uber.org/atomic"
+)
+
+//go:generate mockgen -source=./query_defaults_state_machine.go -destination=./query_defaults_state_machine_mock.go -package=broker
+
+// QueryDefaultsStateMachine represents cluster-wide query defaults state machine,
+// listens query defaults config change event, so that all brokers use the same query defaults.
+type QueryDefaultsStateMachine interface {
+	inif.Listener
+	io.Closer
+
+	// GetQueryDefaults returns the current query defaults.
+	GetQueryDefaults() models.QueryDefaults
+}
+
+// queryDefaultsStateMachine implements QueryDefaultsStateMachine
+type queryDefaultsStateMachine struct {
+	discovery discovery.Discovery
+
+	defaults models.QueryDefaults
+	running  *atomic.Bool
+
+	mutex  sync.RWMutex
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	logger *logger.Logger
+}
+
+// NewQueryDefaultsStateMachine creates query defaults state machine instance.
+func NewQueryDefaultsStateMachine(
+	ctx context.Context,
+	discoveryFactory discovery.Factory,
+) (QueryDefaultsStateMachine, error) {
+	c, cancel := context.WithCancel(ctx)
+	stateMachine := &queryDefaultsStateMachine{
+		ctx:      c,
+		cancel:   cancel,
+		running:  atomic.NewBool(false),
+		defaults: models.NewDefaultQueryDefaults(),
+		logger:   logger.GetLogger("coordinator", "QueryDefaultsStateMachine"),
+	}
+
+	// new query defaults config discovery
+	stateMachine.discovery = discoveryFactory.CreateDiscovery(constants.QueryDefaultsConfigPath, stateMachine)
+	if err := stateMachine.discovery.Discovery(true); err != nil {
+		return nil, fmt.Errorf("discovery query defaults config error:%s", err)
+	}
+
+	stateMachine.running.Store(true)
+	stateMachine.logger.Info("query defaults state machine is started")
+
+	return stateMachine, nil
+}
+
+// OnCreate replaces the query defaults when query defaults config creation/modification.
+func (sm *queryDefaultsStateMachine) OnCreate(key string, resource []byte) {
+	sm.logger.Info("discovery query defaults change in cluster",
+		logger.String("key", key),
+		logger.String("data", string(resource)))
+
+	defaults := models.QueryDefaults{}
+	if err := encoding.JSONUnmarshal(resource, &defaults); err != nil {
+		sm.logger.Error("discovery query defaults change but unmarshal error", logger.Error(err))
+		return
+	}
+	if err := defaults.Validate(); err != nil {
+		sm.logger.Error("discovery query defaults change but validate error", logger.Error(err))
+		return
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.defaults = defaults
+}
+
+// OnDelete resets the query defaults when query defaults config deletion.
+func (sm *queryDefaultsStateMachine) OnDelete(key string) {
+	sm.logger.Info("discovery query defaults delete from cluster",
+		logger.String("key", key))
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.defaults = models.NewDefaultQueryDefaults()
+}
+
+// GetQueryDefaults returns the current query defaults.
+func (sm *queryDefaultsStateMachine) GetQueryDefaults() models.QueryDefaults {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return sm.defaults
+}
+
+// Close closes query defaults state machine, stops watch change event.
+func (sm *queryDefaultsStateMachine) Close() error {
+	if sm.running.CAS(true, false) {
+		sm.mutex.Lock()
+		defer func() {
+			sm.mutex.Unlock()
+			sm.cancel()
+		}()
+
+		sm.discovery.Close()
+		sm.logger.Info("query defaults state machine is stopped.")
+	}
+	return nil
+}
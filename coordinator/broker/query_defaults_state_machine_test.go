@@ -0,0 +1,84 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/coordinator/discovery"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+)
+
+func TestNewQueryDefaultsStateMachine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := discovery.NewMockFactory(ctrl)
+	discovery1 := discovery.NewMockDiscovery(ctrl)
+	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
+
+	// case 1: discovery err
+	discovery1.EXPECT().Discovery(true).Return(fmt.Errorf("err"))
+	_, err := NewQueryDefaultsStateMachine(context.TODO(), factory)
+	assert.Error(t, err)
+
+	// case 2: normal case
+	discovery1.EXPECT().Discovery(true).Return(nil)
+	stateMachine, err := NewQueryDefaultsStateMachine(context.TODO(), factory)
+	assert.NoError(t, err)
+	assert.NotNil(t, stateMachine)
+	assert.Equal(t, models.NewDefaultQueryDefaults(), stateMachine.GetQueryDefaults())
+}
+
+func TestQueryDefaultsStateMachine_listen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := discovery.NewMockFactory(ctrl)
+	discovery1 := discovery.NewMockDiscovery(ctrl)
+	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
+	discovery1.EXPECT().Discovery(true).Return(nil)
+	stateMachine, err := NewQueryDefaultsStateMachine(context.TODO(), factory)
+	assert.NoError(t, err)
+
+	defaults := models.QueryDefaults{TimeRange: "1d", MaxPoints: 1000, Timezone: "UTC"}
+	stateMachine.OnCreate("/query/config/defaults", encoding.JSONMarshal(&defaults))
+	assert.Equal(t, defaults, stateMachine.GetQueryDefaults())
+
+	// unmarshal err, keep old value
+	stateMachine.OnCreate("/query/config/defaults", []byte{1, 1})
+	assert.Equal(t, defaults, stateMachine.GetQueryDefaults())
+	// validate err, keep old value
+	stateMachine.OnCreate("/query/config/defaults",
+		encoding.JSONMarshal(&models.QueryDefaults{TimeRange: "bad"}))
+	assert.Equal(t, defaults, stateMachine.GetQueryDefaults())
+
+	// delete, reset default value
+	stateMachine.OnDelete("/query/config/defaults")
+	assert.Equal(t, models.NewDefaultQueryDefaults(), stateMachine.GetQueryDefaults())
+
+	discovery1.EXPECT().Close()
+	_ = stateMachine.Close()
+	_ = stateMachine.Close()
+}
@@ -44,9 +44,13 @@ type ReplicaStatusStateMachine interface {
 	io.Closer
 
 	// GetQueryableReplicas returns the queryable replicas，
-	// and chooses the fastest replica if the shard has multi-replica.
+	// and chooses the replica in local zone then the fastest if the shard has multi-replica.
 	// returns storage node => shard id list
 	GetQueryableReplicas(database string) map[string][]int32
+	// GetQueryableShardReplicas returns all queryable replicas of each shard,
+	// replicas are sorted by zone(local zone first) and pending(the fastest first).
+	// returns shard id => storage node list
+	GetQueryableShardReplicas(database string) map[int32][]string
 	// GetReplicas returns the replica state list under this broker by broker's indicator
 	GetReplicas(broker string) models.BrokerReplicaState
 }
@@ -55,6 +59,7 @@ type ReplicaStatusStateMachine interface {
 // watches replica state path for listening modify event which broker uploaded
 type replicaStatusStateMachine struct {
 	discovery discovery.Discovery
+	zone      string // zone of current broker, replicas in local zone are preferred when querying
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -67,10 +72,13 @@ type replicaStatusStateMachine struct {
 	logger *logger.Logger
 }
 
-// NewReplicaStatusStateMachine creates a replica's status state machine
-func NewReplicaStatusStateMachine(ctx context.Context, factory discovery.Factory) (ReplicaStatusStateMachine, error) {
+// NewReplicaStatusStateMachine creates a replica's status state machine,
+// zone is the zone of current broker, empty if zone isn't labeled.
+func NewReplicaStatusStateMachine(ctx context.Context, factory discovery.Factory,
+	zone string) (ReplicaStatusStateMachine, error) {
 	c, cancel := context.WithCancel(ctx)
 	sm := &replicaStatusStateMachine{
+		zone:    zone,
 		running: atomic.NewBool(false),
 		brokers: make(map[string]models.BrokerReplicaState),
 		logger:  logger.GetLogger("coordinator", "ReplicaStatusStateMachine"),
@@ -121,11 +129,8 @@ func (sm *replicaStatusStateMachine) GetQueryableReplicas(database string) map[s
 	for _, replicas := range shards {
 		replicaList := replicas
 		if len(replicaList) > 1 {
-			// has multi-replica, chooses the fastest
-			// sort replicas based pending msg
-			sort.Slice(replicaList, func(i, j int) bool {
-				return replicaList[i].Pending < replicaList[j].Pending
-			})
+			// has multi-replica, chooses the replica in local zone then the fastest
+			sm.sortReplicas(replicaList)
 		}
 		nodeID := replicaList[0].Target.Indicator()
 		result[nodeID] = append(result[nodeID], replicaList[0].ShardID)
@@ -134,6 +139,62 @@ func (sm *replicaStatusStateMachine) GetQueryableReplicas(database string) map[s
 	return result
 }
 
+// GetQueryableShardReplicas returns all queryable replicas of each shard, sorted by pending.
+// returns shard id => storage node list
+func (sm *replicaStatusStateMachine) GetQueryableShardReplicas(database string) map[int32][]string {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	if !sm.running.Load() {
+		return nil
+	}
+
+	shards := make(map[int32][]models.ReplicaState)
+	for _, brokerReplicaState := range sm.brokers {
+		for _, replica := range brokerReplicaState.Replicas {
+			if replica.Database != database {
+				continue
+			}
+			shards[replica.ShardID] = append(shards[replica.ShardID], replica)
+		}
+	}
+	if len(shards) == 0 {
+		return nil
+	}
+
+	result := make(map[int32][]string)
+	for shardID, replicas := range shards {
+		replicaList := replicas
+		sm.sortReplicas(replicaList)
+		// same target may be reported by multi-brokers
+		targets := make(map[string]struct{})
+		for _, replica := range replicaList {
+			nodeID := replica.Target.Indicator()
+			if _, ok := targets[nodeID]; ok {
+				continue
+			}
+			targets[nodeID] = struct{}{}
+			result[shardID] = append(result[shardID], nodeID)
+		}
+	}
+	return result
+}
+
+// sortReplicas sorts replicas by zone(local zone first) and pending msg(the fastest first),
+// so that query doesn't cross zones if there is replica in local zone.
+func (sm *replicaStatusStateMachine) sortReplicas(replicas []models.ReplicaState) {
+	sort.SliceStable(replicas, func(i, j int) bool {
+		if sm.zone != "" {
+			localI := replicas[i].Target.Zone == sm.zone
+			localJ := replicas[j].Target.Zone == sm.zone
+			if localI != localJ {
+				return localI
+			}
+		}
+		return replicas[i].Pending < replicas[j].Pending
+	})
+}
+
 // GetReplicas returns the replica state list under this broker by broker's indicator
 func (sm *replicaStatusStateMachine) GetReplicas(broker string) models.BrokerReplicaState {
 	sm.mutex.RLock()
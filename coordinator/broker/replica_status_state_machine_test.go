@@ -40,11 +40,11 @@ func TestStatusStateMachine(t *testing.T) {
 	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
 
 	discovery1.EXPECT().Discovery(gomock.Any()).Return(fmt.Errorf("err"))
-	_, err := NewReplicaStatusStateMachine(context.TODO(), factory)
+	_, err := NewReplicaStatusStateMachine(context.TODO(), factory, "")
 	assert.Error(t, err)
 
 	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
-	sm, err := NewReplicaStatusStateMachine(context.TODO(), factory)
+	sm, err := NewReplicaStatusStateMachine(context.TODO(), factory, "")
 	assert.NoError(t, err)
 	assert.NotNil(t, sm)
 
@@ -135,9 +135,17 @@ func TestStatusStateMachine(t *testing.T) {
 	r = sm.GetQueryableReplicas("test_db_not_exist")
 	assert.Nil(t, r)
 
+	shardReplicas := sm.GetQueryableShardReplicas("test_db")
+	assert.Equal(t, map[int32][]string{
+		1: {"1.1.1.3:2090", "1.1.1.2:2090"},
+		2: {"1.1.1.3:2090", "1.1.1.2:2090"},
+	}, shardReplicas)
+	assert.Nil(t, sm.GetQueryableShardReplicas("test_db_not_exist"))
+
 	discovery1.EXPECT().Close()
 	err = sm.Close()
 	assert.NoError(t, err)
+	assert.Nil(t, sm.GetQueryableShardReplicas("test_db"))
 
 	err = sm.Close()
 	assert.NoError(t, err)
@@ -146,3 +154,46 @@ func TestStatusStateMachine(t *testing.T) {
 	assert.Nil(t, sm.GetQueryableReplicas("test_db_2"))
 	assert.Equal(t, models.BrokerReplicaState{}, sm.GetReplicas("1.1.1.1:9000"))
 }
+
+func TestReplicaStatusStateMachine_localZone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := discovery.NewMockFactory(ctrl)
+	discovery1 := discovery.NewMockDiscovery(ctrl)
+	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1)
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
+	discovery1.EXPECT().Close()
+	sm, err := NewReplicaStatusStateMachine(context.TODO(), factory, "zone-a")
+	assert.NoError(t, err)
+	defer func() {
+		_ = sm.Close()
+	}()
+
+	replicaStatus := []models.ReplicaState{
+		{
+			Database: "test_db",
+			Target:   models.Node{IP: "1.1.1.2", Port: 2090, Zone: "zone-b"},
+			Pending:  10,
+			ShardID:  1,
+		},
+		{
+			Database: "test_db",
+			Target:   models.Node{IP: "1.1.1.3", Port: 2090, Zone: "zone-a"},
+			Pending:  50,
+			ShardID:  1,
+		},
+		{
+			Database: "test_db",
+			Target:   models.Node{IP: "1.1.1.4", Port: 2090, Zone: "zone-a"},
+			Pending:  20,
+			ShardID:  1,
+		},
+	}
+	sm.OnCreate("/broker/2.1.1.1:2080", encoding.JSONMarshal(models.BrokerReplicaState{Replicas: replicaStatus}))
+	// replica in local zone is preferred even if it is slower
+	assert.Equal(t, map[string][]int32{"1.1.1.4:2090": {1}}, sm.GetQueryableReplicas("test_db"))
+	assert.Equal(t, map[int32][]string{
+		1: {"1.1.1.4:2090", "1.1.1.3:2090", "1.1.1.2:2090"},
+	}, sm.GetQueryableShardReplicas("test_db"))
+}
@@ -20,6 +20,7 @@ package broker
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 
 	"github.com/lindb/lindb/models"
 )
@@ -86,6 +87,165 @@ func ModifyShardAssignment(storageNodeIDs []int, cfg *models.Database, shardAssi
 	return nil
 }
 
+// AddReplicas adds replicas for each shard until num. of replicas reaches the replica factor,
+// picks the storage node which has the least replicas for each new replica, so that replicas are spread evenly,
+// the node in the zone which has no replica of shard is preferred if zone of nodes is labeled.
+func AddReplicas(storageNodeIDs []int, cfg *models.Database, shardAssignment *models.ShardAssignment) error {
+	replicaFactor := cfg.ReplicaFactor
+	if replicaFactor > len(storageNodeIDs) {
+		return fmt.Errorf("add replicas error for databaes[%s], bacause replica factor > num. of storage nodes",
+			cfg.Name)
+	}
+	nodeIDs := append([]int{}, storageNodeIDs...)
+	sort.Ints(nodeIDs)
+	// num. of replicas on each storage node
+	replicasOfNode := make(map[int]int)
+	var shardIDs []int
+	for shardID, replica := range shardAssignment.Shards {
+		shardIDs = append(shardIDs, shardID)
+		for _, nodeID := range replica.Replicas {
+			replicasOfNode[nodeID]++
+		}
+	}
+	sort.Ints(shardIDs)
+	zoneOfNode := func(nodeID int) string {
+		if node, ok := shardAssignment.Nodes[nodeID]; ok && node != nil {
+			return node.Zone
+		}
+		return ""
+	}
+
+	for _, shardID := range shardIDs {
+		replica := shardAssignment.Shards[shardID]
+		for len(replica.Replicas) < replicaFactor {
+			usedZones := replicaZones(replica, zoneOfNode)
+			selected := -1
+			selectedNewZone := false
+			for _, nodeID := range nodeIDs {
+				if replica.Contains(nodeID) {
+					continue
+				}
+				_, used := usedZones[zoneOfNode(nodeID)]
+				newZone := !used
+				if selected < 0 ||
+					(newZone && !selectedNewZone) ||
+					(newZone == selectedNewZone && replicasOfNode[nodeID] < replicasOfNode[selected]) {
+					selected = nodeID
+					selectedNewZone = newZone
+				}
+			}
+			replica.Replicas = append(replica.Replicas, selected)
+			replicasOfNode[selected]++
+		}
+	}
+	return nil
+}
+
+// NodePlacement represents the free capacity and zone of storage node used by replica placement.
+type NodePlacement struct {
+	Capacity uint64 // free disk capacity, same value for all nodes if capacity isn't reported
+	Zone     string // empty if zone isn't labeled
+}
+
+// PlacementShardAssignment assigns replicas of new shards(from num. of existing shards to num. of shard in config)
+// by placement of storage nodes:
+// 1. replicas of a shard are placed across distinct zones as much as possible, so a zone loss can't eliminate
+// all copies of the shard.
+// 2. replicas are weighted by free disk capacity, so that the node which has more free capacity holds more replicas.
+// Each replica is placed on the node which has the least replicas per free capacity and doesn't hold other replicas
+// of the shard, leaders(first replica) are weighted separately, so that writes are also spread by capacity.
+// Replicas of existing shards are counted in, the node without free capacity is picked only if no other choice.
+func PlacementShardAssignment(placements map[int]NodePlacement, cfg *models.Database,
+	shardAssignment *models.ShardAssignment) error {
+	numOfShard := cfg.NumOfShard - len(shardAssignment.Shards)
+	replicaFactor := cfg.ReplicaFactor
+	if numOfShard <= 0 {
+		return fmt.Errorf("shard assign error for databaes[%s], because add num. of shard <=0", cfg.Name)
+	}
+	if replicaFactor <= 0 {
+		return fmt.Errorf("shard assign error for databaes[%s], bacause replica factor <=0", cfg.Name)
+	}
+	if replicaFactor > len(placements) {
+		return fmt.Errorf("shard assign error for databaes[%s], bacause replica factor > num. of storage nodes",
+			cfg.Name)
+	}
+	var nodeIDs []int
+	for nodeID := range placements {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Ints(nodeIDs)
+	// num. of leaders/replicas on each storage node
+	leadersOfNode := make(map[int]int)
+	replicasOfNode := make(map[int]int)
+	for _, replica := range shardAssignment.Shards {
+		for idx, nodeID := range replica.Replicas {
+			if idx == 0 {
+				leadersOfNode[nodeID]++
+			}
+			replicasOfNode[nodeID]++
+		}
+	}
+
+	for shardID := len(shardAssignment.Shards); shardID < cfg.NumOfShard; shardID++ {
+		leader := pickNode(nodeIDs, placements, leadersOfNode, nil)
+		shardAssignment.AddReplica(shardID, leader)
+		leadersOfNode[leader]++
+		replicasOfNode[leader]++
+
+		replica := shardAssignment.Shards[shardID]
+		for len(replica.Replicas) < replicaFactor {
+			selected := pickNode(nodeIDs, placements, replicasOfNode, replica)
+			shardAssignment.AddReplica(shardID, selected)
+			replicasOfNode[selected]++
+		}
+	}
+	return nil
+}
+
+// pickNode returns the node for new replica of shard, the nodes in replica are excluded,
+// prefers the node in the zone which has no replica of shard, then the node which has the least
+// load per free capacity after placing the replica, node id is the tie-breaker.
+func pickNode(nodeIDs []int, placements map[int]NodePlacement, loadOfNode map[int]int, replica *models.Replica) int {
+	usedZones := replicaZones(replica, func(nodeID int) string {
+		return placements[nodeID].Zone
+	})
+	selected := -1
+	selectedScore := 0.0
+	selectedNewZone := false
+	for _, nodeID := range nodeIDs {
+		if replica != nil && replica.Contains(nodeID) {
+			continue
+		}
+		placement := placements[nodeID]
+		_, used := usedZones[placement.Zone]
+		newZone := !used
+		// score is +Inf if node has no free capacity
+		score := float64(loadOfNode[nodeID]+1) / float64(placement.Capacity)
+		if selected < 0 ||
+			(newZone && !selectedNewZone) ||
+			(newZone == selectedNewZone && score < selectedScore) {
+			selected = nodeID
+			selectedScore = score
+			selectedNewZone = newZone
+		}
+	}
+	return selected
+}
+
+// replicaZones returns the labeled zones which hold replicas of shard.
+func replicaZones(replica *models.Replica, zoneOfNode func(nodeID int) string) map[string]struct{} {
+	zones := make(map[string]struct{})
+	if replica == nil {
+		return zones
+	}
+	for _, nodeID := range replica.Replicas {
+		if zone := zoneOfNode(nodeID); zone != "" {
+			zones[zone] = struct{}{}
+		}
+	}
+	return zones
+}
+
 // assignReplicasToStorageNodes assigns replica list for storage cluster
 // which database's each shard based on selected node list in cluster.
 func assignReplicasToStorageNodes(storageNodeIDs []int,
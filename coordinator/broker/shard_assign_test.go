@@ -61,6 +61,120 @@ func TestShardAssign(t *testing.T) {
 	checkShardAssignResult(shardAssignment, t)
 }
 
+func TestAddReplicas(t *testing.T) {
+	storageNodeIDs := []int{0, 1, 2, 3, 4}
+	cfg := &models.Database{Name: "test", NumOfShard: 10, ReplicaFactor: 1}
+	shardAssignment, err := ShardAssignment(storageNodeIDs, cfg, -1, -1)
+	assert.NoError(t, err)
+
+	// replica factor > num. of storage nodes
+	cfg.ReplicaFactor = 6
+	assert.Error(t, AddReplicas(storageNodeIDs, cfg, shardAssignment))
+	// add replicas, spread evenly
+	cfg.ReplicaFactor = 3
+	assert.NoError(t, AddReplicas(storageNodeIDs, cfg, shardAssignment))
+	for _, replica := range shardAssignment.Shards {
+		assert.Len(t, replica.Replicas, 3)
+	}
+	checkShardAssignResult(shardAssignment, t)
+}
+
+func TestPlacementShardAssignment(t *testing.T) {
+	placements := map[int]NodePlacement{0: {Capacity: 100}, 1: {Capacity: 100}, 2: {Capacity: 200}, 3: {}}
+	cfg := &models.Database{Name: "test", NumOfShard: 0, ReplicaFactor: 1}
+	assert.Error(t, PlacementShardAssignment(placements, cfg, models.NewShardAssignment("test")))
+	cfg.NumOfShard = 8
+	cfg.ReplicaFactor = 0
+	assert.Error(t, PlacementShardAssignment(placements, cfg, models.NewShardAssignment("test")))
+	cfg.ReplicaFactor = 5
+	assert.Error(t, PlacementShardAssignment(placements, cfg, models.NewShardAssignment("test")))
+
+	countOfNode := func(shardAssignment *models.ShardAssignment) (leaders, replicas map[int]int) {
+		leaders = make(map[int]int)
+		replicas = make(map[int]int)
+		for _, replica := range shardAssignment.Shards {
+			leaders[replica.Replicas[0]]++
+			for _, nodeID := range replica.Replicas {
+				replicas[nodeID]++
+			}
+		}
+		return
+	}
+	// replicas are weighted by free capacity, node without free capacity is skipped
+	cfg.ReplicaFactor = 1
+	shardAssignment := models.NewShardAssignment("test")
+	assert.NoError(t, PlacementShardAssignment(placements, cfg, shardAssignment))
+	assert.Len(t, shardAssignment.Shards, 8)
+	_, replicas := countOfNode(shardAssignment)
+	assert.Equal(t, map[int]int{0: 2, 1: 2, 2: 4}, replicas)
+
+	// add shards, existing replicas are counted in
+	cfg.NumOfShard = 12
+	assert.NoError(t, PlacementShardAssignment(placements, cfg, shardAssignment))
+	assert.Len(t, shardAssignment.Shards, 12)
+	_, replicas = countOfNode(shardAssignment)
+	assert.Equal(t, map[int]int{0: 3, 1: 3, 2: 6}, replicas)
+
+	// replicas of shard are on different nodes, node without free capacity is picked if no other choice
+	cfg.ReplicaFactor = 4
+	cfg.NumOfShard = 4
+	shardAssignment = models.NewShardAssignment("test")
+	assert.NoError(t, PlacementShardAssignment(placements, cfg, shardAssignment))
+	leaders, replicas := countOfNode(shardAssignment)
+	assert.Equal(t, map[int]int{0: 4, 1: 4, 2: 4, 3: 4}, replicas)
+	assert.Equal(t, map[int]int{0: 1, 1: 1, 2: 2}, leaders)
+	for _, replica := range shardAssignment.Shards {
+		assert.ElementsMatch(t, []int{0, 1, 2, 3}, replica.Replicas)
+	}
+}
+
+func TestPlacementShardAssignment_zone(t *testing.T) {
+	// zone a has more capacity than zone b, but replicas of shard are across zones
+	placements := map[int]NodePlacement{
+		0: {Capacity: 300, Zone: "a"},
+		1: {Capacity: 300, Zone: "a"},
+		2: {Capacity: 100, Zone: "b"},
+		3: {Capacity: 100, Zone: "b"},
+	}
+	cfg := &models.Database{Name: "test", NumOfShard: 8, ReplicaFactor: 2}
+	shardAssignment := models.NewShardAssignment("test")
+	assert.NoError(t, PlacementShardAssignment(placements, cfg, shardAssignment))
+	for _, replica := range shardAssignment.Shards {
+		assert.Len(t, replica.Replicas, 2)
+		assert.NotEqual(t, placements[replica.Replicas[0]].Zone, placements[replica.Replicas[1]].Zone)
+	}
+	// replica factor > num. of zones, zones are reused
+	cfg.ReplicaFactor = 3
+	shardAssignment = models.NewShardAssignment("test")
+	assert.NoError(t, PlacementShardAssignment(placements, cfg, shardAssignment))
+	for _, replica := range shardAssignment.Shards {
+		zones := make(map[string]struct{})
+		for _, nodeID := range replica.Replicas {
+			zones[placements[nodeID].Zone] = struct{}{}
+		}
+		assert.Len(t, zones, 2)
+	}
+}
+
+func TestAddReplicas_zone(t *testing.T) {
+	storageNodeIDs := []int{0, 1, 2, 3}
+	cfg := &models.Database{Name: "test", NumOfShard: 4, ReplicaFactor: 1}
+	shardAssignment := models.NewShardAssignment("test")
+	for shardID := 0; shardID < 4; shardID++ {
+		shardAssignment.AddReplica(shardID, shardID)
+	}
+	for idx, zone := range []string{"a", "a", "b", "b"} {
+		shardAssignment.Nodes[idx] = &models.Node{IP: "127.0.0.1", Port: uint16(2080 + idx), Zone: zone}
+	}
+	cfg.ReplicaFactor = 2
+	assert.NoError(t, AddReplicas(storageNodeIDs, cfg, shardAssignment))
+	for _, replica := range shardAssignment.Shards {
+		assert.Len(t, replica.Replicas, 2)
+		assert.NotEqual(t, shardAssignment.Nodes[replica.Replicas[0]].Zone,
+			shardAssignment.Nodes[replica.Replicas[1]].Zone)
+	}
+}
+
 func checkShardAssignResult(shardAssignment *models.ShardAssignment, t *testing.T) {
 	assert.Equal(t, 10, len(shardAssignment.Shards))
 	var nodes = make(map[int]map[int]int)
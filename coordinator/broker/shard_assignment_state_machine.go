@@ -117,11 +117,18 @@ func (sm *shardAssignmentStateMachine) OnCreate(key string, resource []byte) {
 		if err := sm.createShardAssignment(cfg.Name, cluster, &cfg, -1, -1); err != nil {
 			sm.logger.Error("create shard assignment error", logger.Error(err))
 		}
-	} else if len(shardAssign.Shards) != cfg.NumOfShard {
+		return
+	}
+	if len(shardAssign.Shards) != cfg.NumOfShard || shardAssign.MinReplicaFactor() < cfg.ReplicaFactor {
 		if err := sm.modifyShardAssignment(cfg.Name, shardAssign, cluster, &cfg); err != nil {
 			sm.logger.Error("modify shard assignment error", logger.Error(err))
+			return
 		}
 	}
+	// apply modified database option to existing shards
+	if err := cluster.UpdateDatabaseOption(cfg.Name, cfg.Option); err != nil {
+		sm.logger.Error("update database option error", logger.String("database", cfg.Name), logger.Error(err))
+	}
 }
 
 func (sm *shardAssignmentStateMachine) OnDelete(key string) {
@@ -145,11 +152,10 @@ func (sm *shardAssignmentStateMachine) Close() error {
 // 3) submit create shard coordinator task(storage node will execute it when receive task event)
 func (sm *shardAssignmentStateMachine) createShardAssignment(databaseName string,
 	cluster storage.Cluster, cfg *models.Database, fixedStartIndex, startShardID int) error {
-	activeNodes := cluster.GetActiveNodes()
+	activeNodes := writableNodes(cluster.GetActiveNodes())
 	if len(activeNodes) == 0 {
-		return fmt.Errorf("active node not found")
+		return fmt.Errorf("writable active node not found")
 	}
-	//TODO need calc resource and pick related node for store data
 	var nodes = make(map[int]*models.Node)
 	for idx, node := range activeNodes {
 		nodes[idx] = &node.Node
@@ -160,13 +166,23 @@ func (sm *shardAssignmentStateMachine) createShardAssignment(databaseName string
 		nodeIDs = append(nodeIDs, idx)
 	}
 
-	// generate shard assignment based on node ids and config
-	shardAssign, err := ShardAssignment(nodeIDs, cfg, fixedStartIndex, startShardID)
+	// generate shard assignment based on node ids and config,
+	// placed by zone and free capacity of nodes if nodes report capacity or zone is labeled
+	var shardAssign *models.ShardAssignment
+	var err error
+	if placements := sm.nodePlacements(cluster, nodes); placements != nil {
+		shardAssign = models.NewShardAssignment(cfg.Name)
+		err = PlacementShardAssignment(placements, cfg, shardAssign)
+	} else {
+		shardAssign, err = ShardAssignment(nodeIDs, cfg, fixedStartIndex, startShardID)
+	}
 	if err != nil {
 		return err
 	}
 	// set nodes and config, storage node will use it when execute create shard task
 	shardAssign.Nodes = nodes
+	// broker maps series to shard by shard routing of database
+	shardAssign.ShardRouting = cfg.Option.ShardRouting
 
 	sm.logger.Info("create shard assign",
 		logger.String("database", databaseName),
@@ -184,11 +200,10 @@ func (sm *shardAssignmentStateMachine) modifyShardAssignment(databaseName string
 		//TODO implement the reduce shards, is needed?
 		panic("not implemented")
 	} else if len(shardAssign.Shards) < cfg.NumOfShard { //add shardAssign's shards
-		activeNodes := cluster.GetActiveNodes()
+		activeNodes := writableNodes(cluster.GetActiveNodes())
 		if len(activeNodes) == 0 {
-			return fmt.Errorf("active node not found")
+			return fmt.Errorf("writable active node not found")
 		}
-		//TODO need calc resource and pick related node for store data
 		var nodes = make(map[int]*models.Node)
 		for idx, node := range activeNodes {
 			nodes[idx] = &node.Node
@@ -199,12 +214,38 @@ func (sm *shardAssignmentStateMachine) modifyShardAssignment(databaseName string
 			nodeIDs = append(nodeIDs, idx)
 		}
 
-		// generate shard assignment based on node ids and config
-		err := ModifyShardAssignment(nodeIDs, cfg, shardAssign, -1, len(shardAssign.Shards))
+		// generate shard assignment based on node ids and config,
+		// placed by zone and free capacity of nodes if nodes report capacity or zone is labeled
+		var err error
+		if placements := sm.nodePlacements(cluster, nodes); placements != nil {
+			err = PlacementShardAssignment(placements, cfg, shardAssign)
+		} else {
+			err = ModifyShardAssignment(nodeIDs, cfg, shardAssign, -1, len(shardAssign.Shards))
+		}
 		if err != nil {
 			return err
 		}
 	}
+	if shardAssign.MinReplicaFactor() < cfg.ReplicaFactor { // add replicas for existing shards
+		// only picks the storage nodes in shard assignment, because replica id is the node's index of it,
+		// skips the nodes which are read-only now
+		readOnlyNodes := make(map[string]struct{})
+		for _, node := range cluster.GetActiveNodes() {
+			if node.Node.ReadOnly {
+				readOnlyNodes[node.Node.Indicator()] = struct{}{}
+			}
+		}
+		var nodeIDs []int
+		for idx, node := range shardAssign.Nodes {
+			if _, ok := readOnlyNodes[node.Indicator()]; ok {
+				continue
+			}
+			nodeIDs = append(nodeIDs, idx)
+		}
+		if err := AddReplicas(nodeIDs, cfg, shardAssign); err != nil {
+			return err
+		}
+	}
 	sm.logger.Info("modify shard assign",
 		logger.String("database", databaseName),
 		logger.Any("shardAssign", shardAssign))
@@ -214,3 +255,63 @@ func (sm *shardAssignmentStateMachine) modifyShardAssignment(databaseName string
 	}
 	return nil
 }
+
+// writableNodes returns the active nodes which aren't read-only, read-only node receives no new shard assignments.
+func writableNodes(activeNodes []*models.ActiveNode) []*models.ActiveNode {
+	var nodes []*models.ActiveNode
+	for _, node := range activeNodes {
+		if !node.Node.ReadOnly {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// nodePlacements returns the placement of nodes, key is node id in shard assignment, capacity is free disk
+// capacity reported by storage node, all nodes have the same capacity if capacity of any node isn't reported.
+// Returns nil if neither capacity is reported nor zone is labeled, then replicas are spread evenly by round-robin.
+func (sm *shardAssignmentStateMachine) nodePlacements(cluster storage.Cluster,
+	nodes map[int]*models.Node) map[int]NodePlacement {
+	capacities := sm.nodeCapacities(cluster, nodes)
+	zoneLabeled := false
+	placements := make(map[int]NodePlacement)
+	for id, node := range nodes {
+		placement := NodePlacement{Capacity: 1, Zone: node.Zone}
+		if capacities != nil {
+			placement.Capacity = capacities[id]
+		}
+		if node.Zone != "" {
+			zoneLabeled = true
+		}
+		placements[id] = placement
+	}
+	if capacities == nil && !zoneLabeled {
+		return nil
+	}
+	return placements
+}
+
+// nodeCapacities returns free disk capacity of nodes reported by storage nodes, key is node id in shard assignment,
+// returns nil if capacity of any node isn't reported, then replicas are spread evenly.
+func (sm *shardAssignmentStateMachine) nodeCapacities(cluster storage.Cluster, nodes map[int]*models.Node) map[int]uint64 {
+	stat, err := cluster.CollectStat()
+	if err != nil {
+		sm.logger.Warn("collect stat of storage nodes failure, spread replicas evenly", logger.Error(err))
+		return nil
+	}
+	capacityOfNode := make(map[string]models.NodeCapacity)
+	for _, nodeStat := range stat.Nodes {
+		capacityOfNode[nodeStat.Node.Node.Indicator()] = nodeStat.Capacity
+	}
+	capacities := make(map[int]uint64)
+	for id, node := range nodes {
+		capacity, ok := capacityOfNode[node.Indicator()]
+		if !ok || !capacity.Reported() {
+			sm.logger.Info("capacity of storage node isn't reported, spread replicas evenly",
+				logger.String("node", node.Indicator()))
+			return nil
+		}
+		capacities[id] = capacity.DiskFree
+	}
+	return capacities
+}
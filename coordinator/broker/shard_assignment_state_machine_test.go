@@ -29,6 +29,7 @@ import (
 	"github.com/lindb/lindb/coordinator/storage"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/pkg/state"
 )
 
@@ -79,6 +80,7 @@ func TestAdminStateMachine(t *testing.T) {
 	stateMachine.OnCreate("/data/db1", data)
 
 	cluster.EXPECT().GetActiveNodes().Return(prepareStorageCluster())
+	cluster.EXPECT().CollectStat().Return(nil, fmt.Errorf("err"))
 	stateMachine.OnCreate("/data/db1", data)
 
 	data = encoding.JSONMarshal(&models.Database{
@@ -90,10 +92,74 @@ func TestAdminStateMachine(t *testing.T) {
 
 	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
 	cluster.EXPECT().GetActiveNodes().Return(prepareStorageCluster())
+	cluster.EXPECT().CollectStat().Return(&models.StorageClusterStat{}, nil)
 	stateMachine.OnCreate("/data/db1", data)
 
 	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), gomock.Any()).Return(nil)
 	cluster.EXPECT().GetActiveNodes().Return(prepareStorageCluster())
+	cluster.EXPECT().CollectStat().Return(&models.StorageClusterStat{}, nil)
+	stateMachine.OnCreate("/data/db1", data)
+
+	// replicas are weighted by free capacity of nodes
+	cluster.EXPECT().GetActiveNodes().Return(prepareStorageCluster())
+	cluster.EXPECT().CollectStat().Return(prepareStorageClusterStat(), nil)
+	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, shardAssign *models.ShardAssignment, _ option.DatabaseOption) error {
+			assert.Len(t, shardAssign.Shards, 10)
+			assert.Len(t, shardAssign.Nodes, 5)
+			for _, replica := range shardAssign.Shards {
+				// the node without free capacity is the last choice
+				full := -1
+				for id, node := range shardAssign.Nodes {
+					if node.IP == "127.0.0.5" {
+						full = id
+					}
+				}
+				assert.False(t, replica.Contains(full))
+			}
+			return nil
+		})
+	stateMachine.OnCreate("/data/db1", data)
+
+	// replicas are placed across zones
+	zoneNodes := prepareStorageCluster()
+	for idx, node := range zoneNodes {
+		node.Node.Zone = fmt.Sprintf("zone-%d", idx%2)
+	}
+	cluster.EXPECT().GetActiveNodes().Return(zoneNodes)
+	cluster.EXPECT().CollectStat().Return(&models.StorageClusterStat{}, nil)
+	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, shardAssign *models.ShardAssignment, _ option.DatabaseOption) error {
+			for _, replica := range shardAssign.Shards {
+				zones := make(map[string]struct{})
+				for _, nodeID := range replica.Replicas {
+					zones[shardAssign.Nodes[nodeID].Zone] = struct{}{}
+				}
+				assert.Len(t, zones, 2)
+			}
+			return nil
+		})
+	stateMachine.OnCreate("/data/db1", data)
+
+	// read-only nodes receive no new shard assignments
+	readOnlyNodes := prepareStorageCluster()
+	readOnlyNodes[0].Node.ReadOnly = true
+	readOnlyNodes[1].Node.ReadOnly = true
+	cluster.EXPECT().GetActiveNodes().Return(readOnlyNodes)
+	cluster.EXPECT().CollectStat().Return(&models.StorageClusterStat{}, nil)
+	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, shardAssign *models.ShardAssignment, _ option.DatabaseOption) error {
+			assert.Len(t, shardAssign.Nodes, 3)
+			for _, node := range shardAssign.Nodes {
+				assert.False(t, node.ReadOnly)
+			}
+			return nil
+		})
+	stateMachine.OnCreate("/data/db1", data)
+	for _, node := range readOnlyNodes {
+		node.Node.ReadOnly = true
+	}
+	cluster.EXPECT().GetActiveNodes().Return(readOnlyNodes)
 	stateMachine.OnCreate("/data/db1", data)
 
 	stateMachine.OnDelete("mock")
@@ -102,6 +168,93 @@ func TestAdminStateMachine(t *testing.T) {
 	_ = stateMachine.Close()
 }
 
+func TestShardAssignmentStateMachine_modify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := discovery.NewMockFactory(ctrl)
+	discovery1 := discovery.NewMockDiscovery(ctrl)
+	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1)
+	discovery1.EXPECT().Discovery(false).Return(nil)
+	storageCluster := storage.NewMockClusterStateMachine(ctrl)
+	stateMachine, err := NewShardAssignmentStateMachine(context.TODO(), factory, storageCluster)
+	assert.NoError(t, err)
+
+	cluster := storage.NewMockCluster(ctrl)
+	storageCluster.EXPECT().GetCluster("cluster").Return(cluster).AnyTimes()
+	newShardAssign := func() *models.ShardAssignment {
+		shardAssign, _ := ShardAssignment([]int{0, 1, 2}, &models.Database{Name: "db1", NumOfShard: 3, ReplicaFactor: 1}, -1, -1)
+		for idx, node := range prepareStorageCluster()[:3] {
+			shardAssign.Nodes[idx] = &node.Node
+		}
+		return shardAssign
+	}
+	cfg := models.Database{
+		Name:          "db1",
+		Cluster:       "cluster",
+		NumOfShard:    3,
+		ReplicaFactor: 1,
+		Option:        option.DatabaseOption{Interval: "10s", Ahead: "1h"},
+	}
+	// case 1: only option modified
+	cluster.EXPECT().GetShardAssign("db1").Return(newShardAssign(), nil)
+	cluster.EXPECT().UpdateDatabaseOption("db1", cfg.Option).Return(fmt.Errorf("err"))
+	stateMachine.OnCreate("/data/db1", encoding.JSONMarshal(&cfg))
+	// case 2: replica factor > num. of nodes
+	cfg.ReplicaFactor = 4
+	cluster.EXPECT().GetShardAssign("db1").Return(newShardAssign(), nil)
+	cluster.EXPECT().GetActiveNodes().Return(prepareStorageCluster())
+	stateMachine.OnCreate("/data/db1", encoding.JSONMarshal(&cfg))
+	// case 3: add shards weighted by capacity
+	cfg.NumOfShard = 5
+	cfg.ReplicaFactor = 1
+	cluster.EXPECT().GetShardAssign("db1").Return(newShardAssign(), nil)
+	cluster.EXPECT().GetActiveNodes().Return(prepareStorageCluster())
+	cluster.EXPECT().CollectStat().Return(prepareStorageClusterStat(), nil)
+	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), cfg.Option).
+		DoAndReturn(func(_ string, shardAssign *models.ShardAssignment, _ option.DatabaseOption) error {
+			assert.Len(t, shardAssign.Shards, 5)
+			return nil
+		})
+	cluster.EXPECT().UpdateDatabaseOption("db1", cfg.Option).Return(nil)
+	stateMachine.OnCreate("/data/db1", encoding.JSONMarshal(&cfg))
+	cfg.NumOfShard = 3
+	// case 4: add replicas
+	cfg.ReplicaFactor = 2
+	cluster.EXPECT().GetShardAssign("db1").Return(newShardAssign(), nil)
+	cluster.EXPECT().GetActiveNodes().Return(prepareStorageCluster())
+	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), cfg.Option).
+		DoAndReturn(func(_ string, shardAssign *models.ShardAssignment, _ option.DatabaseOption) error {
+			assert.Equal(t, 2, shardAssign.MinReplicaFactor())
+			return nil
+		})
+	cluster.EXPECT().UpdateDatabaseOption("db1", cfg.Option).Return(nil)
+	stateMachine.OnCreate("/data/db1", encoding.JSONMarshal(&cfg))
+	// case 5: add replicas skips read-only node
+	readOnlyNodes := prepareStorageCluster()
+	readOnlyNodes[2].Node.ReadOnly = true
+	cluster.EXPECT().GetShardAssign("db1").Return(newShardAssign(), nil)
+	cluster.EXPECT().GetActiveNodes().Return(readOnlyNodes)
+	cluster.EXPECT().SaveShardAssign("db1", gomock.Any(), cfg.Option).
+		DoAndReturn(func(_ string, shardAssign *models.ShardAssignment, _ option.DatabaseOption) error {
+			assert.Equal(t, 2, shardAssign.MinReplicaFactor())
+			// no new replica on read-only node
+			replicasOfReadOnly := 0
+			for _, replica := range shardAssign.Shards {
+				if replica.Contains(2) {
+					replicasOfReadOnly++
+				}
+			}
+			assert.Equal(t, 1, replicasOfReadOnly)
+			return nil
+		})
+	cluster.EXPECT().UpdateDatabaseOption("db1", cfg.Option).Return(nil)
+	stateMachine.OnCreate("/data/db1", encoding.JSONMarshal(&cfg))
+
+	discovery1.EXPECT().Close()
+	_ = stateMachine.Close()
+}
+
 func prepareStorageCluster() []*models.ActiveNode {
 	return []*models.ActiveNode{
 		{Node: models.Node{IP: "127.0.0.1", Port: 2080}},
@@ -111,3 +264,15 @@ func prepareStorageCluster() []*models.ActiveNode {
 		{Node: models.Node{IP: "127.0.0.5", Port: 2080}},
 	}
 }
+
+func prepareStorageClusterStat() *models.StorageClusterStat {
+	stat := &models.StorageClusterStat{}
+	for idx, node := range prepareStorageCluster() {
+		capacity := models.NodeCapacity{DiskTotal: 1000, DiskFree: uint64(100 * (idx + 1))}
+		if idx == 4 {
+			capacity.DiskFree = 0
+		}
+		stat.Nodes = append(stat.Nodes, &models.NodeStat{Node: *node, Capacity: capacity})
+	}
+	return stat
+}
@@ -45,6 +45,8 @@ type StorageStateMachine interface {
 
 	// List lists currently all alive storage cluster's state
 	List() []*models.StorageState
+	// TopologyVersion returns the version of storage topology, increased when storage state changed.
+	TopologyVersion() int64
 }
 
 // storageStateMachine implements StorageStateMachine interface.
@@ -55,6 +57,7 @@ type storageStateMachine struct {
 	taskClientFactory rpc.TaskClientFactory
 
 	storageClusters map[string]*StorageClusterState
+	version         *atomic.Int64
 
 	mutex   sync.RWMutex
 	running *atomic.Bool
@@ -71,6 +74,7 @@ func NewStorageStateMachine(ctx context.Context,
 		ctx:               c,
 		cancel:            cancel,
 		storageClusters:   make(map[string]*StorageClusterState),
+		version:           atomic.NewInt64(0),
 		running:           atomic.NewBool(false),
 		logger:            logger.GetLogger("coordinator", "StorageStateMachine"),
 	}
@@ -102,6 +106,11 @@ func (s *storageStateMachine) List() (rs []*models.StorageState) {
 	return
 }
 
+// TopologyVersion returns the version of storage topology, increased when storage state changed.
+func (s *storageStateMachine) TopologyVersion() int64 {
+	return s.version.Load()
+}
+
 // OnCreate modifies storage cluster's state, such as trigger by storage create event.
 func (s *storageStateMachine) OnCreate(key string, resource []byte) {
 	s.logger.Info("discovery new storage cluster create",
@@ -129,6 +138,7 @@ func (s *storageStateMachine) OnCreate(key string, resource []byte) {
 		s.storageClusters[storageState.Name] = state
 	}
 	state.SetState(storageState)
+	s.version.Inc()
 }
 
 // OnDelete deletes storage cluster's state when cluster offline
@@ -141,6 +151,7 @@ func (s *storageStateMachine) OnDelete(key string) {
 	if ok {
 		state.close()
 		delete(s.storageClusters, name)
+		s.version.Inc()
 	}
 }
 
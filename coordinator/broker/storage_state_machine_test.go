@@ -80,8 +80,14 @@ func TestStorageStateMachine(t *testing.T) {
 	stateMachine.OnCreate("/data/test5", data3)
 	assert.Equal(t, 2, len(stateMachine.List()))
 
+	assert.Equal(t, int64(2), stateMachine.TopologyVersion())
+
 	stateMachine.OnDelete("/data/test")
 	assert.Equal(t, 1, len(stateMachine.List()))
+	assert.Equal(t, int64(3), stateMachine.TopologyVersion())
+	// delete not exist cluster
+	stateMachine.OnDelete("/data/test")
+	assert.Equal(t, int64(3), stateMachine.TopologyVersion())
 	assert.Equal(t, *storageState2, *(stateMachine.List()[0]))
 
 	discovery1.EXPECT().Close()
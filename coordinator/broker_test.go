@@ -40,6 +40,8 @@ func TestBrokerStateMachines(t *testing.T) {
 	storageStateSM := broker.NewMockStorageStateMachine(ctrl)
 	replicatorSM := replica.NewMockReplicatorStateMachine(ctrl)
 	dbSM := broker.NewMockDatabaseStateMachine(ctrl)
+	queryDefaultsSM := broker.NewMockQueryDefaultsStateMachine(ctrl)
+	clusterConfigSM := discovery.NewMockClusterConfigStateMachine(ctrl)
 
 	factory.EXPECT().CreateActiveNodeStateMachine().Return(nil, fmt.Errorf("err"))
 	err := brokerSMs.Start()
@@ -66,13 +68,30 @@ func TestBrokerStateMachines(t *testing.T) {
 	assert.Error(t, err)
 
 	factory.EXPECT().CreateDatabaseStateMachine().Return(dbSM, nil).AnyTimes()
+	factory.EXPECT().CreateQueryDefaultsStateMachine().Return(nil, fmt.Errorf("err"))
+	err = brokerSMs.Start()
+	assert.Error(t, err)
+
+	factory.EXPECT().CreateQueryDefaultsStateMachine().Return(queryDefaultsSM, nil).AnyTimes()
+	factory.EXPECT().CreateClusterConfigStateMachine().Return(nil, fmt.Errorf("err"))
+	err = brokerSMs.Start()
+	assert.Error(t, err)
+
+	factory.EXPECT().CreateClusterConfigStateMachine().Return(clusterConfigSM, nil).AnyTimes()
 	err = brokerSMs.Start()
 	assert.NoError(t, err)
 
+	nodeSM.EXPECT().TopologyVersion().Return(int64(2))
+	storageStateSM.EXPECT().TopologyVersion().Return(int64(3))
+	assert.Equal(t, int64(5), brokerSMs.TopologyVersion())
+	assert.Zero(t, NewBrokerStateMachines(factory).TopologyVersion())
+
 	nodeSM.EXPECT().Close().Return(fmt.Errorf("err"))
 	replicaSM.EXPECT().Close().Return(fmt.Errorf("err"))
 	storageStateSM.EXPECT().Close().Return(fmt.Errorf("err"))
 	replicatorSM.EXPECT().Close().Return(fmt.Errorf("err"))
 	dbSM.EXPECT().Close().Return(fmt.Errorf("err"))
+	queryDefaultsSM.EXPECT().Close().Return(fmt.Errorf("err"))
+	clusterConfigSM.EXPECT().Close().Return(fmt.Errorf("err"))
 	brokerSMs.Stop()
 }
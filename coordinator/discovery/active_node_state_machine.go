@@ -46,6 +46,8 @@ type ActiveNodeStateMachine interface {
 	GetCurrentNode() models.Node
 	// GetActiveNodes returns all active nodes.
 	GetActiveNodes() []models.ActiveNode
+	// TopologyVersion returns the version of active nodes, increased when node online/offline.
+	TopologyVersion() int64
 }
 
 // activeNodeStateMachine implements node state machine interface,
@@ -61,6 +63,7 @@ type activeNodeStateMachine struct {
 	running *atomic.Bool
 
 	nodes             map[string]models.ActiveNode
+	version           *atomic.Int64
 	connectionManager *ConnectionManager
 
 	logger *logger.Logger
@@ -83,6 +86,7 @@ func NewActiveNodeStateMachine(ctx context.Context, currentNode models.Node,
 			TaskClientFactory: taskClientFactory,
 		},
 		nodes:   make(map[string]models.ActiveNode),
+		version: atomic.NewInt64(0),
 		running: atomic.NewBool(false),
 		logger:  logger.GetLogger("coordinator", "ActiveNodeStateMachine"),
 	}
@@ -118,6 +122,11 @@ func (s *activeNodeStateMachine) GetActiveNodes() (rs []models.ActiveNode) {
 	return
 }
 
+// TopologyVersion returns the version of active nodes, increased when node online/offline.
+func (s *activeNodeStateMachine) TopologyVersion() int64 {
+	return s.version.Load()
+}
+
 // OnCreate adds node into active node list when node online.
 func (s *activeNodeStateMachine) OnCreate(key string, resource []byte) {
 	s.logger.Info("discovery new node online in cluster",
@@ -139,6 +148,7 @@ func (s *activeNodeStateMachine) OnCreate(key string, resource []byte) {
 	s.connectionManager.CreateConnection(node.Node)
 
 	s.nodes[nodeID] = node
+	s.version.Inc()
 }
 
 // OnDelete removes node into active node list when node offline.
@@ -154,6 +164,7 @@ func (s *activeNodeStateMachine) OnDelete(key string) {
 	defer s.mutex.Unlock()
 	s.connectionManager.CloseConnection(nodeID)
 	delete(s.nodes, nodeID)
+	s.version.Inc()
 }
 
 // Close closes state machine, then releases resource.
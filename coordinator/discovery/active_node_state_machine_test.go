@@ -81,8 +81,10 @@ func TestNodeStateMachine_Listener(t *testing.T) {
 	assert.Equal(t, 1, len(stateMachine.GetActiveNodes()))
 
 	taskClientFactory.EXPECT().CloseTaskClient(gomock.Any()).Return(true, nil)
+	assert.Equal(t, int64(1), stateMachine.TopologyVersion())
 	stateMachine.OnDelete("/data/test")
 	assert.Equal(t, 0, len(stateMachine.GetActiveNodes()))
+	assert.Equal(t, int64(2), stateMachine.TopologyVersion())
 
 	// add
 	stateMachine.OnCreate("/data/test", data)
@@ -0,0 +1,149 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/inif"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+//go:generate mockgen -source=./cluster_config_state_machine.go -destination=./cluster_config_state_machine_mock.go -package=discovery
+
+// ClusterConfigStateMachine represents cluster-wide operational config state machine,
+// listens cluster config change event, so that all brokers/storages use the same config without restart.
+type ClusterConfigStateMachine interface {
+	inif.Listener
+	io.Closer
+
+	// GetClusterConfig returns the current cluster config.
+	GetClusterConfig() models.ClusterConfig
+}
+
+// clusterConfigStateMachine implements ClusterConfigStateMachine
+type clusterConfigStateMachine struct {
+	discovery Discovery
+	// onChange is invoked after cluster config changed, nil means no callback
+	onChange func(cfg models.ClusterConfig)
+
+	cfg     models.ClusterConfig
+	running *atomic.Bool
+
+	mutex  sync.RWMutex
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	logger *logger.Logger
+}
+
+// NewClusterConfigStateMachine creates cluster config state machine instance,
+// onChange is invoked with the new cluster config after changed.
+func NewClusterConfigStateMachine(
+	ctx context.Context,
+	discoveryFactory Factory,
+	onChange func(cfg models.ClusterConfig),
+) (ClusterConfigStateMachine, error) {
+	c, cancel := context.WithCancel(ctx)
+	stateMachine := &clusterConfigStateMachine{
+		ctx:      c,
+		cancel:   cancel,
+		onChange: onChange,
+		running:  atomic.NewBool(false),
+		logger:   logger.GetLogger("coordinator", "ClusterConfigStateMachine"),
+	}
+
+	// new cluster config discovery
+	stateMachine.discovery = discoveryFactory.CreateDiscovery(constants.ClusterConfigPath, stateMachine)
+	if err := stateMachine.discovery.Discovery(true); err != nil {
+		return nil, fmt.Errorf("discovery cluster config error:%s", err)
+	}
+
+	stateMachine.running.Store(true)
+	stateMachine.logger.Info("cluster config state machine is started")
+
+	return stateMachine, nil
+}
+
+// OnCreate replaces the cluster config when cluster config creation/modification.
+func (sm *clusterConfigStateMachine) OnCreate(key string, resource []byte) {
+	sm.logger.Info("discovery cluster config change in cluster",
+		logger.String("key", key),
+		logger.String("data", string(resource)))
+
+	cfg := models.ClusterConfig{}
+	if err := encoding.JSONUnmarshal(resource, &cfg); err != nil {
+		sm.logger.Error("discovery cluster config change but unmarshal error", logger.Error(err))
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		sm.logger.Error("discovery cluster config change but validate error", logger.Error(err))
+		return
+	}
+	sm.change(cfg)
+}
+
+// OnDelete resets the cluster config when cluster config deletion, all nodes use their local config.
+func (sm *clusterConfigStateMachine) OnDelete(key string) {
+	sm.logger.Info("discovery cluster config delete from cluster",
+		logger.String("key", key))
+
+	sm.change(models.ClusterConfig{})
+}
+
+// GetClusterConfig returns the current cluster config.
+func (sm *clusterConfigStateMachine) GetClusterConfig() models.ClusterConfig {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return sm.cfg
+}
+
+// Close closes cluster config state machine, stops watch change event.
+func (sm *clusterConfigStateMachine) Close() error {
+	if sm.running.CAS(true, false) {
+		sm.mutex.Lock()
+		defer func() {
+			sm.mutex.Unlock()
+			sm.cancel()
+		}()
+
+		sm.discovery.Close()
+		sm.logger.Info("cluster config state machine is stopped.")
+	}
+	return nil
+}
+
+// change replaces the cluster config, then invokes the callback.
+func (sm *clusterConfigStateMachine) change(cfg models.ClusterConfig) {
+	sm.mutex.Lock()
+	sm.cfg = cfg
+	sm.mutex.Unlock()
+
+	if sm.onChange != nil {
+		sm.onChange(cfg)
+	}
+}
@@ -0,0 +1,88 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+)
+
+func TestNewClusterConfigStateMachine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := NewMockFactory(ctrl)
+	discovery1 := NewMockDiscovery(ctrl)
+	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
+
+	// case 1: discovery err
+	discovery1.EXPECT().Discovery(true).Return(fmt.Errorf("err"))
+	_, err := NewClusterConfigStateMachine(context.TODO(), factory, nil)
+	assert.Error(t, err)
+
+	// case 2: normal case
+	discovery1.EXPECT().Discovery(true).Return(nil)
+	stateMachine, err := NewClusterConfigStateMachine(context.TODO(), factory, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, stateMachine)
+	assert.Equal(t, models.ClusterConfig{}, stateMachine.GetClusterConfig())
+	// without callback
+	stateMachine.OnDelete("/cluster/config")
+}
+
+func TestClusterConfigStateMachine_listen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := NewMockFactory(ctrl)
+	discovery1 := NewMockDiscovery(ctrl)
+	factory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
+	discovery1.EXPECT().Discovery(true).Return(nil)
+	var changes []models.ClusterConfig
+	stateMachine, err := NewClusterConfigStateMachine(context.TODO(), factory, func(cfg models.ClusterConfig) {
+		changes = append(changes, cfg)
+	})
+	assert.NoError(t, err)
+
+	cfg := models.ClusterConfig{QueryTimeout: "10s", SlowQueryThreshold: "1s", MaxSeriesPerMetric: 1000}
+	stateMachine.OnCreate("/cluster/config", encoding.JSONMarshal(&cfg))
+	assert.Equal(t, cfg, stateMachine.GetClusterConfig())
+
+	// unmarshal err, keep old value
+	stateMachine.OnCreate("/cluster/config", []byte{1, 1})
+	assert.Equal(t, cfg, stateMachine.GetClusterConfig())
+	// validate err, keep old value
+	stateMachine.OnCreate("/cluster/config", encoding.JSONMarshal(&models.ClusterConfig{QueryTimeout: "bad"}))
+	assert.Equal(t, cfg, stateMachine.GetClusterConfig())
+
+	// delete, reset value
+	stateMachine.OnDelete("/cluster/config")
+	assert.Equal(t, models.ClusterConfig{}, stateMachine.GetClusterConfig())
+	assert.Equal(t, []models.ClusterConfig{cfg, {}}, changes)
+
+	discovery1.EXPECT().Close()
+	_ = stateMachine.Close()
+	_ = stateMachine.Close()
+}
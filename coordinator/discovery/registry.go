@@ -20,6 +20,7 @@ package discovery
 import (
 	"context"
 	"io"
+	"math/rand"
 	"strconv"
 	"time"
 
@@ -31,6 +32,12 @@ import (
 	"github.com/lindb/lindb/pkg/timeutil"
 )
 
+const (
+	// min/max waiting time before retrying register
+	minRegisterRetryInterval = 500 * time.Millisecond
+	maxRegisterRetryInterval = 10 * time.Second
+)
+
 //go:generate mockgen -source=./registry.go -destination=./registry_mock.go -package=discovery
 
 // Registry represents server node register.
@@ -118,20 +125,30 @@ func (r *registry) Close() error {
 }
 
 // register registers node info, if fail do retry.
+// heartbeat channel closed means the lease is lost(keepalive failed in ttl), re-register node with new lease.
 func (r *registry) register(path string, node models.Node) {
+	retries := 0
 	for {
 		// if ctx happen err, exit register loop
 		if r.ctx.Err() != nil {
 			return
 		}
+		if retries > 0 {
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(retryInterval(retries)):
+			}
+		}
 		nodeBytes := encoding.JSONMarshal(&models.ActiveNode{OnlineTime: timeutil.Now(), Node: node})
 
 		closed, err := r.repo.Heartbeat(r.ctx, path, nodeBytes, int64(r.ttl.Seconds()))
 		if err != nil {
-			r.log.Error("register node error", logger.Error(err))
-			time.Sleep(500 * time.Millisecond)
+			r.log.Error("register node error", logger.Error(err), logger.Int32("retries", int32(retries)))
+			retries++
 			continue
 		}
+		retries = 0
 
 		r.log.Info("register node successfully", logger.String("path", path))
 
@@ -140,7 +157,23 @@ func (r *registry) register(path string, node models.Node) {
 			r.log.Warn("context is canceled, exit register loop")
 			return
 		case <-closed:
-			r.log.Warn("the heartbeat channel is closed, retry register")
+			r.log.Warn("the heartbeat channel is closed(lease lost), retry register")
+			retries++
 		}
 	}
 }
+
+// retryInterval returns the waiting time before retrying register, exponential backoff with jitter,
+// avoids all nodes re-registering at the same time after etcd recovered.
+func retryInterval(retries int) time.Duration {
+	interval := minRegisterRetryInterval
+	for i := 1; i < retries && interval < maxRegisterRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxRegisterRetryInterval {
+		interval = maxRegisterRetryInterval
+	}
+	// [interval/2, interval)
+	half := int64(interval / 2)
+	return time.Duration(half + rand.Int63n(half))
+}
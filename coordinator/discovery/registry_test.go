@@ -122,3 +122,14 @@ func TestRegistry_GenerateNodeID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, models.NodeID(100), id)
 }
+
+func TestRegistry_retryInterval(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		interval := retryInterval(1)
+		assert.True(t, interval >= minRegisterRetryInterval/2 && interval < minRegisterRetryInterval)
+		interval = retryInterval(3)
+		assert.True(t, interval >= minRegisterRetryInterval*2 && interval < minRegisterRetryInterval*4)
+		interval = retryInterval(100)
+		assert.True(t, interval >= maxRegisterRetryInterval/2 && interval < maxRegisterRetryInterval)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
 )
 
 //go:generate mockgen -source=./master.go -destination=./master_mock.go -package=coordinator
@@ -40,6 +41,7 @@ var log = logger.GetLogger("coordinator", "Master")
 
 var (
 	errNoCluster = errors.New("cluster not exist")
+	errNotMaster = errors.New("current node is not master")
 )
 
 // MasterCfg represents the config for master creating
@@ -74,6 +76,13 @@ type Master interface {
 	Stop()
 	// FlushDatabase submits the coordinator task for flushing memory database by cluster and database name
 	FlushDatabase(cluster string, databaseName string) error
+	// VerifyReplicas submits the coordinator task for verifying replicas of database by cluster and database name,
+	// returns the task name which is used for getting the verify report
+	VerifyReplicas(cluster string, databaseName string, timeRange timeutil.TimeRange) (string, error)
+	// GetReplicaVerifyReport returns the divergence report of replica verify task
+	GetReplicaVerifyReport(cluster string, databaseName string, name string) (*models.ReplicaVerifyReport, error)
+	// RepairReplicas submits the coordinator task for repairing the divergences of replica verify task
+	RepairReplicas(cluster string, databaseName string, name string) (*models.ReplicaVerifyReport, error)
 }
 
 // master implements master interface
@@ -184,3 +193,51 @@ func (m *master) FlushDatabase(cluster string, databaseName string) error {
 	}
 	return nil
 }
+
+// VerifyReplicas submits the coordinator task for verifying replicas of database by cluster and database name
+func (m *master) VerifyReplicas(cluster string, databaseName string, timeRange timeutil.TimeRange) (name string, err error) {
+	err = m.withCluster(cluster, func(c storage.Cluster) error {
+		name, err = c.VerifyReplicas(databaseName, timeRange)
+		return err
+	})
+	return name, err
+}
+
+// GetReplicaVerifyReport returns the divergence report of replica verify task
+func (m *master) GetReplicaVerifyReport(cluster string, databaseName string, name string) (
+	report *models.ReplicaVerifyReport, err error,
+) {
+	err = m.withCluster(cluster, func(c storage.Cluster) error {
+		report, err = c.GetReplicaVerifyReport(databaseName, name)
+		return err
+	})
+	return report, err
+}
+
+// RepairReplicas submits the coordinator task for repairing the divergences of replica verify task
+func (m *master) RepairReplicas(cluster string, databaseName string, name string) (
+	report *models.ReplicaVerifyReport, err error,
+) {
+	err = m.withCluster(cluster, func(c storage.Cluster) error {
+		report, err = c.RepairReplicas(databaseName, name)
+		return err
+	})
+	return report, err
+}
+
+// withCluster calls fn with the storage cluster controller by name, returns err if current node is not master
+func (m *master) withCluster(cluster string, fn func(c storage.Cluster) error) error {
+	if !m.IsMaster() {
+		return errNotMaster
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.masterCtx == nil {
+		return errNotMaster
+	}
+	c := m.masterCtx.StateMachine.StorageCluster.GetCluster(cluster)
+	if c == nil {
+		return errNoCluster
+	}
+	return fn(c)
+}
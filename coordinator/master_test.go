@@ -27,11 +27,14 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/constants"
+	coCtx "github.com/lindb/lindb/coordinator/context"
 	"github.com/lindb/lindb/coordinator/discovery"
+	"github.com/lindb/lindb/coordinator/elect"
 	"github.com/lindb/lindb/coordinator/storage"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
 )
 
 func TestMaster(t *testing.T) {
@@ -216,3 +219,41 @@ func sendEvent(eventCh chan *state.Event, event *state.Event) {
 	eventCh <- event
 	time.Sleep(10 * time.Millisecond)
 }
+
+func TestMaster_VerifyReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	election := elect.NewMockElection(ctrl)
+	clusterSM := storage.NewMockClusterStateMachine(ctrl)
+	cluster1 := storage.NewMockCluster(ctrl)
+	m := &master{elect: election}
+	timeRange := timeutil.TimeRange{Start: 10, End: 20}
+
+	// case 1: not master
+	election.EXPECT().IsMaster().Return(false)
+	_, err := m.VerifyReplicas("test", "db", timeRange)
+	assert.Equal(t, errNotMaster, err)
+	election.EXPECT().IsMaster().Return(true).AnyTimes()
+	// case 2: master context not ready
+	_, err = m.GetReplicaVerifyReport("test", "db", "db-1")
+	assert.Equal(t, errNotMaster, err)
+	m.masterCtx = coCtx.NewMasterContext(&coCtx.StateMachine{StorageCluster: clusterSM})
+	// case 3: cluster not exist
+	clusterSM.EXPECT().GetCluster("test").Return(nil)
+	_, err = m.RepairReplicas("test", "db", "db-1")
+	assert.Equal(t, errNoCluster, err)
+	clusterSM.EXPECT().GetCluster("test").Return(cluster1).AnyTimes()
+	// case 4: call cluster
+	cluster1.EXPECT().VerifyReplicas("db", timeRange).Return("db-1", nil)
+	name, err := m.VerifyReplicas("test", "db", timeRange)
+	assert.NoError(t, err)
+	assert.Equal(t, "db-1", name)
+	cluster1.EXPECT().GetReplicaVerifyReport("db", "db-1").Return(&models.ReplicaVerifyReport{Name: "db-1"}, nil)
+	report, err := m.GetReplicaVerifyReport("test", "db", "db-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "db-1", report.Name)
+	cluster1.EXPECT().RepairReplicas("db", "db-1").Return(nil, fmt.Errorf("err"))
+	_, err = m.RepairReplicas("test", "db", "db-1")
+	assert.Error(t, err)
+}
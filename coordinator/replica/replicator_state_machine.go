@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"reflect"
 	"sync"
 
 	"go.uber.org/atomic"
@@ -47,12 +48,16 @@ type ReplicatorStateMachine interface {
 // watches shard assignment change event, then builds replicators
 type replicatorStateMachine struct {
 	discovery discovery.Discovery
-	cm        replication.ChannelManager
+	// watches storage cluster state, no replicator for the read-only storage node
+	storageDiscovery discovery.Discovery
+	cm               replication.ChannelManager
 
 	mutex   sync.RWMutex
 	running *atomic.Bool
 	// shardAssigns: db's name => shard assignment
 	shardAssigns map[string]*models.ShardAssignment
+	// readOnlyNodes: storage cluster's name => read-only node indicators
+	readOnlyNodes map[string]map[string]struct{}
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -67,16 +72,24 @@ func NewReplicatorStateMachine(ctx context.Context,
 ) (ReplicatorStateMachine, error) {
 	c, cancel := context.WithCancel(ctx)
 	stateMachine := &replicatorStateMachine{
-		ctx:          c,
-		cancel:       cancel,
-		cm:           cm,
-		shardAssigns: make(map[string]*models.ShardAssignment),
-		running:      atomic.NewBool(false),
-		logger:       logger.GetLogger("coordinator", "ReplicatorStateMachine"),
+		ctx:           c,
+		cancel:        cancel,
+		cm:            cm,
+		shardAssigns:  make(map[string]*models.ShardAssignment),
+		readOnlyNodes: make(map[string]map[string]struct{}),
+		running:       atomic.NewBool(false),
+		logger:        logger.GetLogger("coordinator", "ReplicatorStateMachine"),
+	}
+	// new storage cluster state discovery, finds read-only nodes before building replicators
+	stateMachine.storageDiscovery = discoveryFactory.CreateDiscovery(constants.StorageClusterNodeStatePath,
+		&storageStateListener{stateMachine: stateMachine})
+	if err := stateMachine.storageDiscovery.Discovery(true); err != nil {
+		return nil, fmt.Errorf("discovery storage cluster state error:%s", err)
 	}
 	// new database's shard assign discovery
 	stateMachine.discovery = discoveryFactory.CreateDiscovery(constants.DatabaseAssignPath, stateMachine)
 	if err := stateMachine.discovery.Discovery(true); err != nil {
+		stateMachine.storageDiscovery.Close()
 		return nil, fmt.Errorf("discovery replicator error:%s", err)
 	}
 
@@ -133,6 +146,7 @@ func (sm *replicatorStateMachine) Close() error {
 			sm.cancel()
 		}()
 		sm.discovery.Close()
+		sm.storageDiscovery.Close()
 
 		sm.logger.Info("replicator state machine is stopped")
 	}
@@ -153,7 +167,7 @@ func (sm *replicatorStateMachine) buildShardAssign(shardAssign *models.ShardAssi
 // createReplicaChannel creates wal replica channel for spec database's shard
 func (sm *replicatorStateMachine) createReplicaChannel(numOfShard, shardID int, shardAssign *models.ShardAssignment) {
 	db := shardAssign.Name
-	ch, err := sm.cm.CreateChannel(db, int32(numOfShard), int32(shardID))
+	ch, err := sm.cm.CreateChannel(db, shardAssign.ShardRouting, int32(numOfShard), int32(shardID))
 	if err != nil {
 		sm.logger.Error("create replica channel", logger.Error(err))
 		return
@@ -171,6 +185,13 @@ func (sm *replicatorStateMachine) startReplicator(ch replication.Channel, shardI
 	for _, replicaID := range replica.Replicas {
 		target := shardAssign.Nodes[replicaID]
 		if target != nil {
+			if sm.isReadOnly(target) {
+				// no writes to read-only node, queries still work
+				ch.RemoveReplicator(*target)
+				sm.logger.Info("skip replicator for read-only node", logger.String("db", db),
+					logger.Any("shardID", shardID), logger.String("target", target.Indicator()))
+				continue
+			}
 			_, err := ch.GetOrCreateReplicator(*target)
 			if err != nil {
 				sm.logger.Error("start replicator", logger.Error(err))
@@ -181,3 +202,68 @@ func (sm *replicatorStateMachine) startReplicator(ch replication.Channel, shardI
 		}
 	}
 }
+
+// isReadOnly returns if the storage node is read-only.
+func (sm *replicatorStateMachine) isReadOnly(node *models.Node) bool {
+	indicator := node.Indicator()
+	for _, nodes := range sm.readOnlyNodes {
+		if _, ok := nodes[indicator]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// setReadOnlyNodes sets the read-only nodes of storage cluster,
+// rebuilds replicators of all shard assignments if read-only nodes changed.
+func (sm *replicatorStateMachine) setReadOnlyNodes(cluster string, nodes map[string]struct{}) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	old := sm.readOnlyNodes[cluster]
+	if (len(old) == 0 && len(nodes) == 0) || reflect.DeepEqual(old, nodes) {
+		return
+	}
+	if len(nodes) == 0 {
+		delete(sm.readOnlyNodes, cluster)
+	} else {
+		sm.readOnlyNodes[cluster] = nodes
+	}
+	sm.logger.Info("read-only storage nodes changed, rebuild replicators",
+		logger.String("cluster", cluster), logger.Any("readOnlyNodes", nodes))
+	for _, shardAssign := range sm.shardAssigns {
+		sm.buildShardAssign(shardAssign)
+	}
+	if sm.running.Load() {
+		sm.cm.SyncReplicatorState()
+	}
+}
+
+// storageStateListener listens the storage cluster state change event,
+// then stops/starts the replicators of read-only nodes.
+type storageStateListener struct {
+	stateMachine *replicatorStateMachine
+}
+
+// OnCreate finds the read-only nodes of storage cluster when state creation/modification.
+func (l *storageStateListener) OnCreate(key string, resource []byte) {
+	storageState := models.NewStorageState()
+	if err := encoding.JSONUnmarshal(resource, storageState); err != nil {
+		l.stateMachine.logger.Error("unmarshal storage state", logger.String("key", key), logger.Error(err))
+		return
+	}
+	nodes := make(map[string]struct{})
+	for _, node := range storageState.ActiveNodes {
+		if node.Node.ReadOnly {
+			nodes[node.Node.Indicator()] = struct{}{}
+		}
+	}
+	_, cluster := filepath.Split(key)
+	l.stateMachine.setReadOnlyNodes(cluster, nodes)
+}
+
+// OnDelete clears the read-only nodes of storage cluster.
+func (l *storageStateListener) OnDelete(key string) {
+	_, cluster := filepath.Split(key)
+	l.stateMachine.setReadOnlyNodes(cluster, nil)
+}
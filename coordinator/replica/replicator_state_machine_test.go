@@ -19,6 +19,7 @@ package replica
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -51,22 +52,29 @@ func TestReplicatorStateMachine(t *testing.T) {
 	assert.Nil(t, sm)
 
 	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(fmt.Errorf("err"))
+	discovery1.EXPECT().Close()
+	sm, err = NewReplicatorStateMachine(context.TODO(), cm, discoveryFactory)
+	assert.Error(t, err)
+	assert.Nil(t, sm)
+
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil).Times(2)
 	sm, err = NewReplicatorStateMachine(context.TODO(), cm, discoveryFactory)
 	assert.NoError(t, err)
 	assert.NotNil(t, sm)
 
 	data := encoding.JSONMarshal(shardAssign)
-	cm.EXPECT().CreateChannel(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	cm.EXPECT().CreateChannel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
 	sm.OnCreate("/test/path", data)
 
 	// test on create event
 	sm.OnCreate("/test/path", []byte{1, 2, 3})
 	ch := replication.NewMockChannel(ctrl)
-	cm.EXPECT().CreateChannel(gomock.Any(), gomock.Any(), gomock.Any()).Return(ch, nil)
+	cm.EXPECT().CreateChannel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(ch, nil)
 	ch.EXPECT().GetOrCreateReplicator(gomock.Any()).Return(nil, fmt.Errorf("err"))
 	sm.OnCreate("/test/path", data)
 
-	cm.EXPECT().CreateChannel(gomock.Any(), gomock.Any(), gomock.Any()).Return(ch, nil)
+	cm.EXPECT().CreateChannel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(ch, nil)
 	ch.EXPECT().GetOrCreateReplicator(gomock.Any()).Return(nil, nil)
 	sm.OnCreate("/test/path", data)
 
@@ -77,10 +85,67 @@ func TestReplicatorStateMachine(t *testing.T) {
 	sm.OnDelete("/shard/test")
 	assert.Equal(t, 0, len(s.shardAssigns))
 
-	discovery1.EXPECT().Close()
+	discovery1.EXPECT().Close().Times(2)
 	err = sm.Close()
 	assert.NoError(t, err)
 
 	err = sm.Close()
 	assert.NoError(t, err)
 }
+
+func TestReplicatorStateMachine_readOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := replication.NewMockChannelManager(ctrl)
+	cm.EXPECT().SyncReplicatorState().AnyTimes()
+	discoveryFactory := discovery.NewMockFactory(ctrl)
+	discovery1 := discovery.NewMockDiscovery(ctrl)
+	discoveryFactory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil).Times(2)
+	sm, err := NewReplicatorStateMachine(context.TODO(), cm, discoveryFactory)
+	assert.NoError(t, err)
+	s := sm.(*replicatorStateMachine)
+	listener := &storageStateListener{stateMachine: s}
+
+	node1 := models.Node{IP: "1.1.1.1", Port: 9000}
+	node2 := models.Node{IP: "1.1.1.2", Port: 9000}
+	shardAssign := models.NewShardAssignment("test")
+	shardAssign.Nodes[1] = &node1
+	shardAssign.Nodes[2] = &node2
+	shardAssign.AddReplica(0, 1)
+	shardAssign.AddReplica(0, 2)
+
+	ch := replication.NewMockChannel(ctrl)
+	cm.EXPECT().CreateChannel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(ch, nil).AnyTimes()
+	ch.EXPECT().GetOrCreateReplicator(gomock.Any()).Return(nil, nil).Times(2)
+	sm.OnCreate("/shard/test", mustMarshal(t, shardAssign))
+
+	// unmarshal storage state failure
+	listener.OnCreate("/storage/state/cluster", []byte{1, 2, 3})
+	// node2 becomes read-only, stops replicator of it
+	storageState := models.NewStorageState()
+	storageState.AddActiveNode(&models.ActiveNode{Node: node1})
+	readOnlyNode := node2
+	readOnlyNode.ReadOnly = true
+	storageState.AddActiveNode(&models.ActiveNode{Node: readOnlyNode})
+	ch.EXPECT().GetOrCreateReplicator(node1).Return(nil, nil)
+	ch.EXPECT().RemoveReplicator(node2)
+	listener.OnCreate("/storage/state/cluster", mustMarshal(t, storageState))
+	assert.True(t, s.isReadOnly(&node2))
+	// read-only nodes not changed
+	listener.OnCreate("/storage/state/cluster", mustMarshal(t, storageState))
+	// cluster offline, node2 is writable again
+	ch.EXPECT().GetOrCreateReplicator(gomock.Any()).Return(nil, nil).Times(2)
+	listener.OnDelete("/storage/state/cluster")
+	assert.False(t, s.isReadOnly(&node2))
+
+	discovery1.EXPECT().Close().Times(2)
+	assert.NoError(t, sm.Close())
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return data
+}
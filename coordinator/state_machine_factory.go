@@ -53,6 +53,10 @@ type StateMachineFactory interface {
 	CreateReplicatorStateMachine() (replica.ReplicatorStateMachine, error)
 	// CreateDatabaseStateMachine creates the database state machine.
 	CreateDatabaseStateMachine() (broker.DatabaseStateMachine, error)
+	// CreateQueryDefaultsStateMachine creates the cluster-wide query defaults state machine.
+	CreateQueryDefaultsStateMachine() (broker.QueryDefaultsStateMachine, error)
+	// CreateClusterConfigStateMachine creates the cluster-wide operational config state machine.
+	CreateClusterConfigStateMachine() (discovery.ClusterConfigStateMachine, error)
 }
 
 // stateMachineFactory implements the interface, using state machine config for creating.
@@ -77,7 +81,7 @@ func (s *stateMachineFactory) CreateStorageStateMachine() (broker.StorageStateMa
 
 // CreateReplicaStatusStateMachine creates the shard replica status state machine, if fail returns err.
 func (s *stateMachineFactory) CreateReplicaStatusStateMachine() (broker.ReplicaStatusStateMachine, error) {
-	return broker.NewReplicaStatusStateMachine(s.cfg.Ctx, s.cfg.DiscoveryFactory)
+	return broker.NewReplicaStatusStateMachine(s.cfg.Ctx, s.cfg.DiscoveryFactory, s.cfg.CurrentNode.Zone)
 }
 
 // CreateReplicatorStateMachine creates the shard replicator state machine.
@@ -87,5 +91,15 @@ func (s *stateMachineFactory) CreateReplicatorStateMachine() (replica.Replicator
 
 // CreateDatabaseStateMachine creates the database state machine.
 func (s *stateMachineFactory) CreateDatabaseStateMachine() (broker.DatabaseStateMachine, error) {
-	return broker.NewDatabaseStateMachine(s.cfg.Ctx, s.cfg.DiscoveryFactory)
+	return broker.NewDatabaseStateMachine(s.cfg.Ctx, s.cfg.DiscoveryFactory, s.cfg.ChannelManager)
+}
+
+// CreateQueryDefaultsStateMachine creates the cluster-wide query defaults state machine.
+func (s *stateMachineFactory) CreateQueryDefaultsStateMachine() (broker.QueryDefaultsStateMachine, error) {
+	return broker.NewQueryDefaultsStateMachine(s.cfg.Ctx, s.cfg.DiscoveryFactory)
+}
+
+// CreateClusterConfigStateMachine creates the cluster-wide operational config state machine.
+func (s *stateMachineFactory) CreateClusterConfigStateMachine() (discovery.ClusterConfigStateMachine, error) {
+	return discovery.NewClusterConfigStateMachine(s.cfg.Ctx, s.cfg.DiscoveryFactory, nil)
 }
@@ -87,7 +87,7 @@ func TestStateMachineFactory_Create(t *testing.T) {
 	replicatorSM, err := factory.CreateReplicatorStateMachine()
 	assert.Error(t, err)
 	assert.Nil(t, replicatorSM)
-	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil).Times(2)
 	replicatorSM, err = factory.CreateReplicatorStateMachine()
 	assert.NoError(t, err)
 	assert.NotNil(t, replicatorSM)
@@ -96,4 +96,14 @@ func TestStateMachineFactory_Create(t *testing.T) {
 	dbSM, err := factory.CreateDatabaseStateMachine()
 	assert.NoError(t, err)
 	assert.NotNil(t, dbSM)
+
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
+	queryDefaultsSM, err := factory.CreateQueryDefaultsStateMachine()
+	assert.NoError(t, err)
+	assert.NotNil(t, queryDefaultsSM)
+
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
+	clusterConfigSM, err := factory.CreateClusterConfigStateMachine()
+	assert.NoError(t, err)
+	assert.NotNil(t, clusterConfigSM)
 }
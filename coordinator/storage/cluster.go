@@ -33,6 +33,7 @@ import (
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
 )
 
 //go:generate mockgen -source=./cluster.go -destination=./cluster_mock.go -package=storage
@@ -91,6 +92,19 @@ type Cluster interface {
 	// FlushDatabase submits the coordinator task for flushing memory database by name
 	FlushDatabase(databaseName string) error
 
+	// UpdateDatabaseOption submits the coordinator task for updating database option by name
+	UpdateDatabaseOption(databaseName string, databaseOption option.DatabaseOption) error
+
+	// VerifyReplicas submits the coordinator task for computing family checksums of all replicas of database,
+	// returns the task name which is used for getting the verify report
+	VerifyReplicas(databaseName string, timeRange timeutil.TimeRange) (string, error)
+
+	// GetReplicaVerifyReport compares the family checksums reported by replicas, returns the divergence report
+	GetReplicaVerifyReport(databaseName, name string) (*models.ReplicaVerifyReport, error)
+
+	// RepairReplicas submits the coordinator task for copying missing families from other replica
+	RepairReplicas(databaseName, name string) (*models.ReplicaVerifyReport, error)
+
 	// SaveShardAssign saves shard assignment
 	SaveShardAssign(
 		databaseName string,
@@ -224,6 +238,24 @@ func (c *cluster) FlushDatabase(databaseName string) error {
 	return nil
 }
 
+// UpdateDatabaseOption submits the coordinator task for updating database option by name,
+// storage node ignores the task if database not exist.
+func (c *cluster) UpdateDatabaseOption(databaseName string, databaseOption option.DatabaseOption) error {
+	var params []task.ControllerTaskParam
+	taskParam := &models.UpdateDatabaseOptionTask{DatabaseName: databaseName, DatabaseOption: databaseOption}
+	for _, node := range c.clusterState.ActiveNodes {
+		params = append(params, task.ControllerTaskParam{
+			NodeID: node.Node.Indicator(),
+			Params: taskParam,
+		})
+	}
+	// create update database option coordinator tasks
+	if err := c.SubmitTask(constants.UpdateDatabaseOption, databaseName, params); err != nil {
+		return err
+	}
+	return nil
+}
+
 // GetShardAssign returns shard assignment by database name, return not exist err if it not exist
 func (c *cluster) GetShardAssign(databaseName string) (*models.ShardAssignment, error) {
 	data, err := c.cfg.brokerRepo.Get(c.cfg.ctx, constants.GetDatabaseAssignPath(databaseName))
@@ -306,11 +338,16 @@ func (c *cluster) addNode(resource []byte) bool {
 			logger.String("data", string(resource)), logger.Error(err))
 		return false
 	}
+	// node marked read-only by admin, broker routes no new shard assignments and writes to it
+	if c.cfg.cfg.IsReadOnlyNode(node.Node.Indicator()) {
+		node.Node.ReadOnly = true
+	}
 
 	c.clusterState.AddActiveNode(node)
 	c.logger.Info("peer storage is online",
 		logger.String("node", node.Node.Indicator()),
 		logger.Int64("nodeOnlineTime", node.OnlineTime),
+		logger.Any("readOnly", node.Node.ReadOnly),
 	)
 	return true
 }
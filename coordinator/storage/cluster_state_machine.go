@@ -57,8 +57,11 @@ type ClusterStateMachine interface {
 type clusterStateMachine struct {
 	repo      state.Repository
 	discovery discovery.Discovery
-	ctx       context.Context
-	cancel    context.CancelFunc
+	// watches cluster-wide config of broker's repo, syncs it into storage cluster's repo
+	configDiscovery discovery.Discovery
+	clusterConfig   []byte // nil means cluster-wide config not set
+	ctx             context.Context
+	cancel          context.CancelFunc
 
 	clusterFactory    ClusterFactory
 	discoveryFactory  discovery.Factory
@@ -105,6 +108,13 @@ func NewClusterStateMachine(
 	if err := stateMachine.discovery.Discovery(true); err != nil {
 		return nil, fmt.Errorf("discovery storage cluster config error:%s", err)
 	}
+	// new cluster-wide config discovery
+	stateMachine.configDiscovery = discoveryFactory.CreateDiscovery(constants.ClusterConfigPath,
+		&clusterConfigListener{stateMachine: stateMachine})
+	if err := stateMachine.configDiscovery.Discovery(true); err != nil {
+		stateMachine.discovery.Close()
+		return nil, fmt.Errorf("discovery cluster config error:%s", err)
+	}
 	// start collect cluster stat goroutine
 	stateMachine.timer = time.NewTimer(stateMachine.interval)
 	go stateMachine.collectStat()
@@ -167,8 +177,9 @@ func (c *clusterStateMachine) Close() error {
 			c.timer.Stop()
 			c.cancel()
 		}()
-		// 1) close listen for storage cluster config change
+		// 1) close listen for storage cluster config/cluster-wide config change
 		c.discovery.Close()
+		c.configDiscovery.Close()
 		// 2) cleanup clusters and release resource
 		c.cleanupCluster()
 	}
@@ -264,6 +275,53 @@ func (c *clusterStateMachine) addCluster(resource []byte) {
 		return
 	}
 	c.clusters[cfg.Name] = cluster
+	if c.clusterConfig != nil {
+		c.syncClusterConfig(cfg.Name, cluster, c.clusterConfig)
+	}
+}
+
+// syncClusterConfig saves the cluster-wide config into storage cluster's repo,
+// deletes it if data is nil, so that all storage nodes can watch it.
+func (c *clusterStateMachine) syncClusterConfig(name string, cluster Cluster, data []byte) {
+	var err error
+	if data == nil {
+		err = cluster.GetRepo().Delete(c.ctx, constants.ClusterConfigPath)
+	} else {
+		err = cluster.GetRepo().Put(c.ctx, constants.ClusterConfigPath, data)
+	}
+	if err != nil {
+		c.logger.Warn("sync cluster config to storage cluster error",
+			logger.String("cluster", name), logger.Error(err))
+	}
+}
+
+// clusterConfigListener listens the cluster-wide config change event of broker's repo,
+// then syncs it into all storage clusters.
+type clusterConfigListener struct {
+	stateMachine *clusterStateMachine
+}
+
+// OnCreate syncs cluster-wide config into all storage clusters when creation/modification.
+func (l *clusterConfigListener) OnCreate(key string, resource []byte) {
+	l.stateMachine.logger.Info("cluster config be changed", logger.String("key", key))
+	l.sync(resource)
+}
+
+// OnDelete deletes cluster-wide config from all storage clusters.
+func (l *clusterConfigListener) OnDelete(key string) {
+	l.stateMachine.logger.Info("cluster config be deleted", logger.String("key", key))
+	l.sync(nil)
+}
+
+func (l *clusterConfigListener) sync(data []byte) {
+	c := l.stateMachine
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.clusterConfig = data
+	for name, cluster := range c.clusters {
+		c.syncClusterConfig(name, cluster, data)
+	}
 }
 
 // deleteCluster deletes the cluster if exist
@@ -26,6 +26,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/coordinator/discovery"
 	"github.com/lindb/lindb/coordinator/task"
 	"github.com/lindb/lindb/models"
@@ -52,8 +53,16 @@ func TestClusterStateMachine_New(t *testing.T) {
 		controllerFactory, discoverFactory, clusterFactory, repoFactory)
 	assert.Error(t, err)
 
-	// normal case
+	// register cluster config discovery err
 	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(fmt.Errorf("err"))
+	discovery1.EXPECT().Close()
+	_, err = NewClusterStateMachine(context.TODO(), repo,
+		controllerFactory, discoverFactory, clusterFactory, repoFactory)
+	assert.Error(t, err)
+
+	// normal case
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil).Times(2)
 
 	stateMachine, err := NewClusterStateMachine(context.TODO(), repo,
 		controllerFactory, discoverFactory, clusterFactory, repoFactory)
@@ -71,7 +80,7 @@ func TestClusterStateMachine_New(t *testing.T) {
 	clusterFactory.EXPECT().newCluster(gomock.Any()).Return(cluster, nil)
 	stateMachine.OnCreate("/test/data/test1", encoding.JSONMarshal(&models.StorageState{Name: "test1"}))
 
-	discovery1.EXPECT().Close()
+	discovery1.EXPECT().Close().Times(2)
 	_ = stateMachine.Close()
 	_ = stateMachine.Close()
 }
@@ -89,7 +98,7 @@ func TestClusterStateMachine_collect(t *testing.T) {
 	discoverFactory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
 	clusterFactory := NewMockClusterFactory(ctrl)
 
-	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil)
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil).Times(2)
 	sm, err := NewClusterStateMachine(context.TODO(), repo,
 		controllerFactory, discoverFactory, clusterFactory, repoFactory)
 	assert.NoError(t, err)
@@ -105,3 +114,45 @@ func TestClusterStateMachine_collect(t *testing.T) {
 
 	time.Sleep(time.Second)
 }
+
+func TestClusterStateMachine_syncClusterConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	controllerFactory := task.NewMockControllerFactory(ctrl)
+	repoFactory := state.NewMockRepositoryFactory(ctrl)
+	repo := state.NewMockRepository(ctrl)
+	discoverFactory := discovery.NewMockFactory(ctrl)
+	discovery1 := discovery.NewMockDiscovery(ctrl)
+	discoverFactory.EXPECT().CreateDiscovery(gomock.Any(), gomock.Any()).Return(discovery1).AnyTimes()
+	clusterFactory := NewMockClusterFactory(ctrl)
+
+	discovery1.EXPECT().Discovery(gomock.Any()).Return(nil).Times(2)
+	sm, err := NewClusterStateMachine(context.TODO(), repo,
+		controllerFactory, discoverFactory, clusterFactory, repoFactory)
+	assert.NoError(t, err)
+	sm1 := sm.(*clusterStateMachine)
+	listener := &clusterConfigListener{stateMachine: sm1}
+	cluster := NewMockCluster(ctrl)
+	storageRepo := state.NewMockRepository(ctrl)
+	cluster.EXPECT().GetRepo().Return(storageRepo).AnyTimes()
+	sm1.clusters["test"] = cluster
+
+	data := encoding.JSONMarshal(&models.ClusterConfig{QueryTimeout: "10s"})
+	// case 1: sync cluster config into exist cluster
+	storageRepo.EXPECT().Put(gomock.Any(), constants.ClusterConfigPath, data).Return(fmt.Errorf("err"))
+	listener.OnCreate(constants.ClusterConfigPath, data)
+	// case 2: sync cluster config into new cluster
+	repoFactory.EXPECT().CreateRepo(gomock.Any()).Return(state.NewMockRepository(ctrl), nil)
+	clusterFactory.EXPECT().newCluster(gomock.Any()).Return(cluster, nil)
+	storageRepo.EXPECT().Put(gomock.Any(), constants.ClusterConfigPath, data).Return(nil)
+	sm.OnCreate("/test/data/test1", encoding.JSONMarshal(&models.StorageState{Name: "test1"}))
+	// case 3: delete cluster config from all clusters
+	storageRepo.EXPECT().Delete(gomock.Any(), constants.ClusterConfigPath).Return(nil).Times(2)
+	listener.OnDelete(constants.ClusterConfigPath)
+	assert.Nil(t, sm1.clusterConfig)
+
+	cluster.EXPECT().Close().Times(2)
+	discovery1.EXPECT().Close().Times(2)
+	_ = sm.Close()
+}
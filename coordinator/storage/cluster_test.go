@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/coordinator/discovery"
 	"github.com/lindb/lindb/coordinator/task"
 	"github.com/lindb/lindb/models"
@@ -51,6 +52,7 @@ func TestStorageCluster(t *testing.T) {
 			Namespace: "storage",
 			Timeout:   ltoml.Duration(time.Second * 5),
 		},
+		ReadOnlyNodes: []string{"1.1.1.5:4000"},
 	}
 	discoveryFactory := discovery.NewMockFactory(ctrl)
 	discovery1 := discovery.NewMockDiscovery(ctrl)
@@ -86,6 +88,15 @@ func TestStorageCluster(t *testing.T) {
 		encoding.JSONMarshal(&models.ActiveNode{Node: models.Node{IP: "1.1.1.4", Port: 4000}}))
 	cluster.OnCreate("/active/node/2", []byte{1, 2, 3})
 	assert.Equal(t, 1, len(cluster.GetActiveNodes()))
+	assert.False(t, cluster.GetActiveNodes()[0].Node.ReadOnly)
+	// node marked read-only by admin
+	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	cluster.OnCreate("/active/node/5",
+		encoding.JSONMarshal(&models.ActiveNode{Node: models.Node{IP: "1.1.1.5", Port: 4000}}))
+	for _, node := range cluster.GetActiveNodes() {
+		assert.Equal(t, node.Node.IP == "1.1.1.5", node.Node.ReadOnly)
+	}
+	cluster.OnDelete("/active/nodes/1.1.1.5:4000")
 
 	// OnDelete
 	repo.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
@@ -255,3 +266,24 @@ func TestCluster_FlushDatabase(t *testing.T) {
 	err = cluster1.FlushDatabase("test")
 	assert.Error(t, err)
 }
+
+func TestCluster_UpdateDatabaseOption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	controller := task.NewMockController(ctrl)
+	c := &cluster{
+		clusterState:   models.NewStorageState(),
+		taskController: controller,
+	}
+	c.clusterState.AddActiveNode(&models.ActiveNode{
+		Node: models.Node{IP: "1.1.1.1", Port: 9000},
+	})
+	controller.EXPECT().Submit(constants.UpdateDatabaseOption, "test", gomock.Any()).Return(nil)
+	err := c.UpdateDatabaseOption("test", option.DatabaseOption{Interval: "10s"})
+	assert.NoError(t, err)
+
+	controller.EXPECT().Submit(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	err = c.UpdateDatabaseOption("test", option.DatabaseOption{Interval: "10s"})
+	assert.Error(t, err)
+}
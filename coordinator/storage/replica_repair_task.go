@@ -0,0 +1,146 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/task"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+	"github.com/lindb/lindb/rpc"
+	"github.com/lindb/lindb/tsdb"
+)
+
+// for testing
+var newExportServiceClient = func(source string) (protoStorageV1.ExportServiceClient, error) {
+	node, err := models.ParseNode(source)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := rpc.GetClientConnFactory().GetClientConn(*node)
+	if err != nil {
+		return nil, err
+	}
+	return protoStorageV1.NewExportServiceClient(conn), nil
+}
+
+// replicaRepairProcessor copies the families which are missing on current replica from source replica,
+// the data exported by source replica is bulk loaded into data files of shard.
+type replicaRepairProcessor struct {
+	engine tsdb.Engine
+}
+
+// newReplicaRepairProcessor returns replica repair processor instance
+func newReplicaRepairProcessor(engine tsdb.Engine) task.Processor {
+	return &replicaRepairProcessor{
+		engine: engine,
+	}
+}
+
+func (p *replicaRepairProcessor) Kind() task.Kind             { return constants.ReplicaRepair }
+func (p *replicaRepairProcessor) RetryCount() int             { return 0 }
+func (p *replicaRepairProcessor) RetryBackOff() time.Duration { return 0 }
+func (p *replicaRepairProcessor) Concurrency() int            { return 1 }
+
+// Process copies the missing families of shards from source replicas.
+func (p *replicaRepairProcessor) Process(ctx context.Context, task task.Task) error {
+	param := models.ReplicaRepairTask{}
+	if err := encoding.JSONUnmarshal(task.Params, &param); err != nil {
+		return err
+	}
+	log := logger.GetLogger("coordinator", "StorageReplicaRepairProcessor")
+	log.Info("process replica repair task", logger.String("params", string(task.Params)))
+	for _, repair := range param.Shards {
+		shard, ok := p.engine.GetShard(param.DatabaseName, repair.ShardID)
+		if !ok {
+			return fmt.Errorf("shard %d of database %s not exist", repair.ShardID, param.DatabaseName)
+		}
+		client, err := newExportServiceClient(repair.Source)
+		if err != nil {
+			return err
+		}
+		for _, timeRange := range repair.TimeRanges {
+			for _, namespace := range repair.Namespaces {
+				loaded, err := p.repairFamily(ctx, client, shard, namespace, timeRange)
+				if err != nil {
+					return fmt.Errorf("repair shard %d from %s error: %s", repair.ShardID, repair.Source, err)
+				}
+				log.Info("repair family successfully",
+					logger.String("database", param.DatabaseName), logger.Int32("shardID", repair.ShardID),
+					logger.String("source", repair.Source), logger.String("namespace", namespace),
+					logger.Int64("family", timeRange.Start), logger.Int64("metrics", loaded))
+			}
+		}
+	}
+	return nil
+}
+
+// repairFamily exports the data of namespace within time range from source replica, then bulk loads it.
+func (p *replicaRepairProcessor) repairFamily(
+	ctx context.Context,
+	client protoStorageV1.ExportServiceClient,
+	shard tsdb.Shard,
+	namespace string,
+	timeRange timeutil.TimeRange,
+) (int64, error) {
+	stream, err := client.Export(ctx, &protoStorageV1.ExportRequest{
+		Database:  shard.DatabaseName(),
+		ShardID:   shard.ShardID(),
+		Namespace: namespace,
+		StartTime: timeRange.Start,
+		EndTime:   timeRange.End,
+	})
+	if err != nil {
+		return 0, err
+	}
+	loader := shard.NewBulkLoader()
+	defer func() {
+		_ = loader.Close()
+	}()
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		var metricList protoMetricsV1.MetricList
+		if err := metricList.Unmarshal(resp.Data); err != nil {
+			return 0, err
+		}
+		for _, metric := range metricList.Metrics {
+			if err := loader.Load(metric); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := loader.Commit(); err != nil {
+		return 0, err
+	}
+	return loader.LoadedMetrics(), nil
+}
@@ -0,0 +1,117 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/task"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
+	"github.com/lindb/lindb/tsdb"
+)
+
+func TestReplicaRepairProcessor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	newClientFn := newExportServiceClient
+	defer func() {
+		newExportServiceClient = newClientFn
+		ctrl.Finish()
+	}()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	shard := tsdb.NewMockShard(ctrl)
+	shard.EXPECT().DatabaseName().Return("db").AnyTimes()
+	shard.EXPECT().ShardID().Return(int32(1)).AnyTimes()
+	client := protoStorageV1.NewMockExportServiceClient(ctrl)
+	stream := protoStorageV1.NewMockExportService_ExportClient(ctrl)
+	loader := tsdb.NewMockBulkLoader(ctrl)
+	loader.EXPECT().Close().Return(nil).AnyTimes()
+	processor := newReplicaRepairProcessor(engine)
+	assert.Equal(t, 1, processor.Concurrency())
+	assert.Equal(t, time.Duration(0), processor.RetryBackOff())
+	assert.Equal(t, 0, processor.RetryCount())
+	assert.Equal(t, constants.ReplicaRepair, processor.Kind())
+
+	err := processor.Process(context.TODO(), task.Task{Params: []byte{1, 1, 1}})
+	assert.Error(t, err)
+
+	timeRange := timeutil.TimeRange{Start: 10, End: 100}
+	param := models.ReplicaRepairTask{DatabaseName: "db", Shards: []models.ShardRepair{{
+		ShardID:    1,
+		Source:     "1.1.1.2:2891",
+		Namespaces: []string{"ns"},
+		TimeRanges: []timeutil.TimeRange{timeRange},
+	}}}
+	repairTask := task.Task{Name: "db-1", Params: param.Bytes()}
+	exportReq := &protoStorageV1.ExportRequest{Database: "db", ShardID: 1, Namespace: "ns", StartTime: 10, EndTime: 100}
+	metricList := &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "cpu"}}}
+	data, _ := metricList.Marshal()
+
+	// case 1: shard not exist
+	engine.EXPECT().GetShard("db", int32(1)).Return(nil, false)
+	assert.Error(t, processor.Process(context.TODO(), repairTask))
+	engine.EXPECT().GetShard("db", int32(1)).Return(shard, true).AnyTimes()
+	// case 2: create client err
+	newExportServiceClient = func(source string) (protoStorageV1.ExportServiceClient, error) {
+		return nil, fmt.Errorf("err")
+	}
+	assert.Error(t, processor.Process(context.TODO(), repairTask))
+	newExportServiceClient = func(source string) (protoStorageV1.ExportServiceClient, error) {
+		assert.Equal(t, "1.1.1.2:2891", source)
+		return client, nil
+	}
+	// case 3: export err
+	client.EXPECT().Export(gomock.Any(), exportReq).Return(nil, fmt.Errorf("err"))
+	assert.Error(t, processor.Process(context.TODO(), repairTask))
+	client.EXPECT().Export(gomock.Any(), exportReq).Return(stream, nil).AnyTimes()
+	shard.EXPECT().NewBulkLoader().Return(loader).AnyTimes()
+	// case 4: recv err
+	stream.EXPECT().Recv().Return(nil, fmt.Errorf("err"))
+	assert.Error(t, processor.Process(context.TODO(), repairTask))
+	// case 5: unmarshal err
+	stream.EXPECT().Recv().Return(&protoStorageV1.ExportResponse{Data: []byte{1, 2, 3}}, nil)
+	assert.Error(t, processor.Process(context.TODO(), repairTask))
+	// case 6: load err
+	stream.EXPECT().Recv().Return(&protoStorageV1.ExportResponse{Data: data}, nil)
+	loader.EXPECT().Load(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, processor.Process(context.TODO(), repairTask))
+	// case 7: commit err
+	stream.EXPECT().Recv().Return(&protoStorageV1.ExportResponse{Data: data}, nil)
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+	loader.EXPECT().Load(gomock.Any()).Return(nil)
+	loader.EXPECT().Commit().Return(fmt.Errorf("err"))
+	assert.Error(t, processor.Process(context.TODO(), repairTask))
+	// case 8: repair successfully
+	stream.EXPECT().Recv().Return(&protoStorageV1.ExportResponse{Data: data}, nil)
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+	loader.EXPECT().Load(gomock.Any()).Return(nil)
+	loader.EXPECT().Commit().Return(nil)
+	loader.EXPECT().LoadedMetrics().Return(int64(1))
+	assert.NoError(t, processor.Process(context.TODO(), repairTask))
+}
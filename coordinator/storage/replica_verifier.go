@@ -0,0 +1,313 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/task"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+// for testing
+var nowFunc = timeutil.Now
+
+// errReplicaRepaired represents the divergences of replica verify task have been repaired.
+var errReplicaRepaired = errors.New("replicas have been repaired, please verify replicas again")
+
+// VerifyReplicas submits the coordinator task for computing family checksums of all replicas of database,
+// returns the task name which is used for getting the verify report.
+func (c *cluster) VerifyReplicas(databaseName string, timeRange timeutil.TimeRange) (string, error) {
+	shardAssign, err := c.GetShardAssign(databaseName)
+	if err != nil {
+		return "", err
+	}
+	var tasks = make(map[int]*models.ReplicaVerifyTask)
+	for ID, shard := range shardAssign.Shards {
+		for _, replicaID := range shard.Replicas {
+			taskParam, ok := tasks[replicaID]
+			if !ok {
+				taskParam = &models.ReplicaVerifyTask{DatabaseName: databaseName, TimeRange: timeRange}
+				tasks[replicaID] = taskParam
+			}
+			taskParam.ShardIDs = append(taskParam.ShardIDs, int32(ID))
+		}
+	}
+	var params []task.ControllerTaskParam
+	for nodeID, taskParam := range tasks {
+		sort.Slice(taskParam.ShardIDs, func(i, j int) bool { return taskParam.ShardIDs[i] < taskParam.ShardIDs[j] })
+		node := shardAssign.Nodes[nodeID]
+		params = append(params, task.ControllerTaskParam{
+			NodeID: node.Indicator(),
+			Params: taskParam,
+		})
+	}
+	name := fmt.Sprintf("%s-%d", databaseName, nowFunc())
+	// create replica verify coordinator tasks
+	if err := c.SubmitTask(constants.ReplicaVerify, name, params); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// GetReplicaVerifyReport compares the family checksums reported by replicas, returns the divergence report.
+func (c *cluster) GetReplicaVerifyReport(databaseName, name string) (*models.ReplicaVerifyReport, error) {
+	shardAssign, results, err := c.loadReplicaVerifyResults(databaseName, name)
+	if err != nil {
+		return nil, err
+	}
+	return buildReplicaVerifyReport(name, shardAssign, results), nil
+}
+
+// RepairReplicas submits the coordinator task for copying the families which are missing on some replicas
+// from the replica which has the data, only can be done once for each replica verify task,
+// because the loaded data is merged with existing data.
+func (c *cluster) RepairReplicas(databaseName, name string) (*models.ReplicaVerifyReport, error) {
+	repairPath := constants.GetReplicaRepairPath(databaseName, name)
+	if _, err := c.GetRepo().Get(c.cfg.ctx, repairPath); err == nil {
+		return nil, errReplicaRepaired
+	} else if !errors.Is(err, state.ErrNotExist) {
+		return nil, err
+	}
+	shardAssign, results, err := c.loadReplicaVerifyResults(databaseName, name)
+	if err != nil {
+		return nil, err
+	}
+	report := buildReplicaVerifyReport(name, shardAssign, results)
+	if !report.Completed {
+		return nil, fmt.Errorf("replica verify task: %s not completed", name)
+	}
+	var tasks = make(map[string]*models.ReplicaRepairTask)
+	for idx := range report.Divergences {
+		divergence := &report.Divergences[idx]
+		if !divergence.Repairable {
+			continue
+		}
+		source, targets, _ := divergence.RepairSource()
+		timeRange := timeutil.TimeRange{Start: divergence.FamilyTime, End: divergence.EndTime}
+		for _, target := range targets {
+			taskParam, ok := tasks[target]
+			if !ok {
+				taskParam = &models.ReplicaRepairTask{DatabaseName: databaseName}
+				tasks[target] = taskParam
+			}
+			shardRepair := findShardRepair(taskParam, divergence.ShardID, source)
+			if shardRepair == nil {
+				taskParam.Shards = append(taskParam.Shards, models.ShardRepair{
+					ShardID:    divergence.ShardID,
+					Source:     source,
+					Namespaces: findShardChecksum(results[source], divergence.ShardID).Namespaces,
+				})
+				shardRepair = &taskParam.Shards[len(taskParam.Shards)-1]
+			}
+			shardRepair.TimeRanges = append(shardRepair.TimeRanges, timeRange)
+		}
+	}
+	if len(tasks) == 0 {
+		return report, nil
+	}
+	var params []task.ControllerTaskParam
+	for node, taskParam := range tasks {
+		params = append(params, task.ControllerTaskParam{
+			NodeID: node,
+			Params: taskParam,
+		})
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].NodeID < params[j].NodeID })
+	// create replica repair coordinator tasks
+	if err := c.SubmitTask(constants.ReplicaRepair, name, params); err != nil {
+		return nil, err
+	}
+	if err := c.GetRepo().Put(c.cfg.ctx, repairPath, []byte(name)); err != nil {
+		return nil, err
+	}
+	report.RepairTasks = len(params)
+	return report, nil
+}
+
+// loadReplicaVerifyResults loads the shard assignment of database and the results reported by storage nodes.
+func (c *cluster) loadReplicaVerifyResults(databaseName, name string) (
+	shardAssign *models.ShardAssignment,
+	results map[string]*models.ReplicaVerifyResult,
+	err error,
+) {
+	shardAssign, err = c.GetShardAssign(databaseName)
+	if err != nil {
+		return nil, nil, err
+	}
+	kvs, err := c.GetRepo().List(c.cfg.ctx, constants.GetReplicaVerifyPath(databaseName, name)+"/")
+	if err != nil {
+		return nil, nil, err
+	}
+	results = make(map[string]*models.ReplicaVerifyResult)
+	for _, kv := range kvs {
+		_, node := filepath.Split(kv.Key)
+		result := &models.ReplicaVerifyResult{}
+		if err := encoding.JSONUnmarshal(kv.Value, result); err != nil {
+			return nil, nil, err
+		}
+		results[node] = result
+	}
+	return shardAssign, results, nil
+}
+
+// buildReplicaVerifyReport compares the family checksums of replicas for each shard,
+// replicas which don't report result or fail are excluded from comparison.
+func buildReplicaVerifyReport(
+	name string,
+	shardAssign *models.ShardAssignment,
+	results map[string]*models.ReplicaVerifyResult,
+) *models.ReplicaVerifyReport {
+	report := &models.ReplicaVerifyReport{Name: name, Database: shardAssign.Name}
+	shardIDs := make([]int, 0, len(shardAssign.Shards))
+	nodes := make(map[string]struct{})
+	for shardID, replica := range shardAssign.Shards {
+		shardIDs = append(shardIDs, shardID)
+		for _, replicaID := range replica.Replicas {
+			if node, ok := shardAssign.Nodes[replicaID]; ok {
+				nodes[node.Indicator()] = struct{}{}
+			}
+		}
+	}
+	sort.Ints(shardIDs)
+	for node := range nodes {
+		result, ok := results[node]
+		switch {
+		case !ok:
+			report.Pending = append(report.Pending, node)
+		case result.Error != "":
+			report.Failures = append(report.Failures, models.ReplicaVerifyFailure{Node: node, Error: result.Error})
+		}
+	}
+	sort.Strings(report.Pending)
+	sort.Slice(report.Failures, func(i, j int) bool { return report.Failures[i].Node < report.Failures[j].Node })
+	report.Completed = len(report.Pending) == 0
+
+	for _, shardID := range shardIDs {
+		var replicas []string
+		var checksums []*models.ShardChecksum
+		for _, replicaID := range shardAssign.Shards[shardID].Replicas {
+			node, ok := shardAssign.Nodes[replicaID]
+			if !ok {
+				continue
+			}
+			result, ok := results[node.Indicator()]
+			if !ok || result.Error != "" {
+				continue
+			}
+			checksum := findShardChecksum(result, int32(shardID))
+			if checksum == nil {
+				continue
+			}
+			replicas = append(replicas, node.Indicator())
+			checksums = append(checksums, checksum)
+		}
+		if len(replicas) < 2 {
+			// nothing to compare
+			continue
+		}
+		report.Shards++
+		report.Families += compareShardReplicas(report, int32(shardID), replicas, checksums)
+	}
+	return report
+}
+
+// compareShardReplicas compares the families of shard replicas, adds divergences into report,
+// returns the number of compared families.
+func compareShardReplicas(
+	report *models.ReplicaVerifyReport,
+	shardID int32,
+	replicas []string,
+	checksums []*models.ShardChecksum,
+) int {
+	families := make(map[int64][]models.FamilyReplica)
+	endTimes := make(map[int64]int64)
+	for idx, checksum := range checksums {
+		for familyIdx := range checksum.Families {
+			family := &checksum.Families[familyIdx]
+			familyReplicas, ok := families[family.FamilyTime]
+			if !ok {
+				familyReplicas = make([]models.FamilyReplica, len(replicas))
+				for replicaIdx, replica := range replicas {
+					familyReplicas[replicaIdx].Node = replica
+				}
+				families[family.FamilyTime] = familyReplicas
+				endTimes[family.FamilyTime] = family.EndTime
+			}
+			familyReplicas[idx].Checksum = family
+		}
+	}
+	familyTimes := make([]int64, 0, len(families))
+	for familyTime := range families {
+		familyTimes = append(familyTimes, familyTime)
+	}
+	sort.Slice(familyTimes, func(i, j int) bool { return familyTimes[i] < familyTimes[j] })
+	for _, familyTime := range familyTimes {
+		familyReplicas := families[familyTime]
+		same := true
+		for _, replica := range familyReplicas[1:] {
+			if !familyReplicas[0].Checksum.Equal(replica.Checksum) {
+				same = false
+				break
+			}
+		}
+		if same {
+			continue
+		}
+		divergence := models.FamilyDivergence{
+			ShardID:    shardID,
+			FamilyTime: familyTime,
+			EndTime:    endTimes[familyTime],
+			Replicas:   familyReplicas,
+		}
+		_, _, divergence.Repairable = divergence.RepairSource()
+		report.Divergences = append(report.Divergences, divergence)
+	}
+	return len(familyTimes)
+}
+
+// findShardChecksum returns the checksum of shard in result, returns nil if not found.
+func findShardChecksum(result *models.ReplicaVerifyResult, shardID int32) *models.ShardChecksum {
+	if result == nil {
+		return nil
+	}
+	for idx := range result.Shards {
+		if result.Shards[idx].ShardID == shardID {
+			return &result.Shards[idx]
+		}
+	}
+	return nil
+}
+
+// findShardRepair returns the repair of shard which copies data from source, returns nil if not found.
+func findShardRepair(taskParam *models.ReplicaRepairTask, shardID int32, source string) *models.ShardRepair {
+	for idx := range taskParam.Shards {
+		shardRepair := &taskParam.Shards[idx]
+		if shardRepair.ShardID == shardID && shardRepair.Source == source {
+			return shardRepair
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/task"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+func newVerifyShardAssign() *models.ShardAssignment {
+	shardAssign := models.NewShardAssignment("test")
+	shardAssign.Nodes[1] = &models.Node{IP: "1.1.1.1", Port: 2891}
+	shardAssign.Nodes[2] = &models.Node{IP: "1.1.1.2", Port: 2891}
+	shardAssign.AddReplica(1, 1)
+	shardAssign.AddReplica(1, 2)
+	shardAssign.AddReplica(2, 1)
+	shardAssign.AddReplica(2, 2)
+	return shardAssign
+}
+
+// shardAssignData is the json data of shard assignment used by replica verify tests.
+var shardAssignData, _ = json.Marshal(newVerifyShardAssign())
+
+func newVerifyCluster(ctrl *gomock.Controller) (*cluster, *state.MockRepository, *task.MockController) {
+	repo := state.NewMockRepository(ctrl)
+	controller := task.NewMockController(ctrl)
+	return &cluster{
+		cfg: clusterCfg{
+			ctx:         context.TODO(),
+			brokerRepo:  repo,
+			storageRepo: repo,
+		},
+		clusterState:   models.NewStorageState(),
+		taskController: controller,
+	}, repo, controller
+}
+
+func TestCluster_VerifyReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		nowFunc = timeutil.Now
+		ctrl.Finish()
+	}()
+	nowFunc = func() int64 { return 100 }
+
+	c, repo, controller := newVerifyCluster(ctrl)
+	timeRange := timeutil.TimeRange{Start: 10, End: 20}
+	// case 1: get shard assign err
+	repo.EXPECT().Get(gomock.Any(), constants.GetDatabaseAssignPath("test")).Return(nil, fmt.Errorf("err"))
+	_, err := c.VerifyReplicas("test", timeRange)
+	assert.Error(t, err)
+	repo.EXPECT().Get(gomock.Any(), constants.GetDatabaseAssignPath("test")).
+		Return(shardAssignData, nil).AnyTimes()
+	// case 2: submit err
+	controller.EXPECT().Submit(constants.ReplicaVerify, "test-100", gomock.Any()).Return(fmt.Errorf("err"))
+	_, err = c.VerifyReplicas("test", timeRange)
+	assert.Error(t, err)
+	// case 3: submit tasks
+	controller.EXPECT().Submit(constants.ReplicaVerify, "test-100", gomock.Any()).
+		DoAndReturn(func(_ task.Kind, _ string, params []task.ControllerTaskParam) error {
+			assert.Len(t, params, 2)
+			for _, param := range params {
+				assert.Equal(t, &models.ReplicaVerifyTask{
+					DatabaseName: "test",
+					ShardIDs:     []int32{1, 2},
+					TimeRange:    timeRange,
+				}, param.Params)
+			}
+			return nil
+		})
+	name, err := c.VerifyReplicas("test", timeRange)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-100", name)
+}
+
+func TestCluster_GetReplicaVerifyReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, repo, _ := newVerifyCluster(ctrl)
+	resultPath := constants.GetReplicaVerifyPath("test", "test-100") + "/"
+	// case 1: get shard assign err
+	repo.EXPECT().Get(gomock.Any(), constants.GetDatabaseAssignPath("test")).Return(nil, fmt.Errorf("err"))
+	_, err := c.GetReplicaVerifyReport("test", "test-100")
+	assert.Error(t, err)
+	repo.EXPECT().Get(gomock.Any(), constants.GetDatabaseAssignPath("test")).
+		Return(shardAssignData, nil).AnyTimes()
+	// case 2: list results err
+	repo.EXPECT().List(gomock.Any(), resultPath).Return(nil, fmt.Errorf("err"))
+	_, err = c.GetReplicaVerifyReport("test", "test-100")
+	assert.Error(t, err)
+	// case 3: unmarshal result err
+	repo.EXPECT().List(gomock.Any(), resultPath).Return([]state.KeyValue{{Key: resultPath + "1.1.1.1:2891", Value: []byte{1}}}, nil)
+	_, err = c.GetReplicaVerifyReport("test", "test-100")
+	assert.Error(t, err)
+	// case 4: pending node
+	result := &models.ReplicaVerifyResult{Node: "1.1.1.1:2891", Shards: []models.ShardChecksum{{ShardID: 1}, {ShardID: 2}}}
+	repo.EXPECT().List(gomock.Any(), resultPath).
+		Return([]state.KeyValue{{Key: resultPath + "1.1.1.1:2891", Value: encoding.JSONMarshal(result)}}, nil)
+	report, err := c.GetReplicaVerifyReport("test", "test-100")
+	assert.NoError(t, err)
+	assert.False(t, report.Completed)
+	assert.Equal(t, []string{"1.1.1.2:2891"}, report.Pending)
+	assert.Equal(t, 0, report.Shards)
+}
+
+func TestBuildReplicaVerifyReport(t *testing.T) {
+	shardAssign := newVerifyShardAssign()
+	shardAssign.Nodes[3] = &models.Node{IP: "1.1.1.3", Port: 2891}
+	shardAssign.AddReplica(1, 3)
+	shardAssign.AddReplica(3, 3)
+	family := models.FamilyChecksum{FamilyTime: 10, EndTime: 19, Series: 1, Points: 2, Checksum: 3}
+	family2 := models.FamilyChecksum{FamilyTime: 20, EndTime: 29, Series: 1, Points: 2, Checksum: 3}
+	results := map[string]*models.ReplicaVerifyResult{
+		"1.1.1.1:2891": {Node: "1.1.1.1:2891", Shards: []models.ShardChecksum{
+			{ShardID: 1, Families: []models.FamilyChecksum{family, family2}},
+			{ShardID: 2, Families: []models.FamilyChecksum{family}},
+		}},
+		"1.1.1.2:2891": {Node: "1.1.1.2:2891", Shards: []models.ShardChecksum{
+			{ShardID: 1, Families: []models.FamilyChecksum{family}},
+			{ShardID: 2, Families: []models.FamilyChecksum{{FamilyTime: 10, EndTime: 19, Series: 2}}},
+		}},
+		"1.1.1.3:2891": {Node: "1.1.1.3:2891", Error: "err"},
+	}
+	report := buildReplicaVerifyReport("test-100", shardAssign, results)
+	assert.True(t, report.Completed)
+	assert.Equal(t, []models.ReplicaVerifyFailure{{Node: "1.1.1.3:2891", Error: "err"}}, report.Failures)
+	assert.Equal(t, 2, report.Shards)
+	assert.Equal(t, 3, report.Families)
+	assert.Len(t, report.Divergences, 2)
+	// family missing on replica
+	divergence := report.Divergences[0]
+	assert.Equal(t, int32(1), divergence.ShardID)
+	assert.Equal(t, int64(20), divergence.FamilyTime)
+	assert.Equal(t, int64(29), divergence.EndTime)
+	assert.True(t, divergence.Repairable)
+	assert.Nil(t, divergence.Replicas[1].Checksum)
+	// family differs between replicas
+	divergence = report.Divergences[1]
+	assert.Equal(t, int32(2), divergence.ShardID)
+	assert.False(t, divergence.Repairable)
+}
+
+func TestCluster_RepairReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, repo, controller := newVerifyCluster(ctrl)
+	repairPath := constants.GetReplicaRepairPath("test", "test-100")
+	resultPath := constants.GetReplicaVerifyPath("test", "test-100") + "/"
+	family := models.FamilyChecksum{FamilyTime: 10, EndTime: 19, Series: 1, Points: 2, Checksum: 3}
+	result1 := &models.ReplicaVerifyResult{Node: "1.1.1.1:2891", Shards: []models.ShardChecksum{
+		{ShardID: 1, Namespaces: []string{"ns"}, Families: []models.FamilyChecksum{family}},
+		{ShardID: 2, Namespaces: []string{"ns"}},
+	}}
+	result2 := &models.ReplicaVerifyResult{Node: "1.1.1.2:2891", Shards: []models.ShardChecksum{
+		{ShardID: 1},
+		{ShardID: 2, Namespaces: []string{"ns"}, Families: []models.FamilyChecksum{family}},
+	}}
+	kvs := []state.KeyValue{
+		{Key: resultPath + "1.1.1.1:2891", Value: encoding.JSONMarshal(result1)},
+		{Key: resultPath + "1.1.1.2:2891", Value: encoding.JSONMarshal(result2)},
+	}
+
+	// case 1: repaired already
+	repo.EXPECT().Get(gomock.Any(), repairPath).Return([]byte("test-100"), nil)
+	_, err := c.RepairReplicas("test", "test-100")
+	assert.Equal(t, errReplicaRepaired, err)
+	// case 2: get repair mark err
+	repo.EXPECT().Get(gomock.Any(), repairPath).Return(nil, fmt.Errorf("err"))
+	_, err = c.RepairReplicas("test", "test-100")
+	assert.Error(t, err)
+	repo.EXPECT().Get(gomock.Any(), repairPath).Return(nil, state.ErrNotExist).AnyTimes()
+	// case 3: load results err
+	repo.EXPECT().Get(gomock.Any(), constants.GetDatabaseAssignPath("test")).Return(nil, fmt.Errorf("err"))
+	_, err = c.RepairReplicas("test", "test-100")
+	assert.Error(t, err)
+	repo.EXPECT().Get(gomock.Any(), constants.GetDatabaseAssignPath("test")).
+		Return(shardAssignData, nil).AnyTimes()
+	// case 4: verify task not completed
+	repo.EXPECT().List(gomock.Any(), resultPath).Return(kvs[:1], nil)
+	_, err = c.RepairReplicas("test", "test-100")
+	assert.Error(t, err)
+	// case 5: nothing to repair
+	repo.EXPECT().List(gomock.Any(), resultPath).Return([]state.KeyValue{
+		{Key: resultPath + "1.1.1.1:2891", Value: encoding.JSONMarshal(result1)},
+		{Key: resultPath + "1.1.1.2:2891", Value: encoding.JSONMarshal(result1)},
+	}, nil)
+	report, err := c.RepairReplicas("test", "test-100")
+	assert.NoError(t, err)
+	assert.Empty(t, report.Divergences)
+	assert.Equal(t, 0, report.RepairTasks)
+	repo.EXPECT().List(gomock.Any(), resultPath).Return(kvs, nil).AnyTimes()
+	// case 6: submit err
+	controller.EXPECT().Submit(constants.ReplicaRepair, "test-100", gomock.Any()).Return(fmt.Errorf("err"))
+	_, err = c.RepairReplicas("test", "test-100")
+	assert.Error(t, err)
+	// case 7: mark repaired err
+	controller.EXPECT().Submit(constants.ReplicaRepair, "test-100", gomock.Any()).Return(nil)
+	repo.EXPECT().Put(gomock.Any(), repairPath, gomock.Any()).Return(fmt.Errorf("err"))
+	_, err = c.RepairReplicas("test", "test-100")
+	assert.Error(t, err)
+	// case 8: submit repair tasks
+	controller.EXPECT().Submit(constants.ReplicaRepair, "test-100", gomock.Any()).
+		DoAndReturn(func(_ task.Kind, _ string, params []task.ControllerTaskParam) error {
+			assert.Len(t, params, 2)
+			assert.Equal(t, "1.1.1.1:2891", params[0].NodeID)
+			assert.Equal(t, &models.ReplicaRepairTask{DatabaseName: "test", Shards: []models.ShardRepair{{
+				ShardID:    2,
+				Source:     "1.1.1.2:2891",
+				Namespaces: []string{"ns"},
+				TimeRanges: []timeutil.TimeRange{{Start: 10, End: 19}},
+			}}}, params[0].Params)
+			assert.Equal(t, "1.1.1.2:2891", params[1].NodeID)
+			assert.Equal(t, &models.ReplicaRepairTask{DatabaseName: "test", Shards: []models.ShardRepair{{
+				ShardID:    1,
+				Source:     "1.1.1.1:2891",
+				Namespaces: []string{"ns"},
+				TimeRanges: []timeutil.TimeRange{{Start: 10, End: 19}},
+			}}}, params[1].Params)
+			return nil
+		})
+	repo.EXPECT().Put(gomock.Any(), repairPath, gomock.Any()).Return(nil)
+	report, err = c.RepairReplicas("test", "test-100")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.RepairTasks)
+	assert.Len(t, report.Divergences, 2)
+}
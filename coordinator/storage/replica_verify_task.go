@@ -0,0 +1,84 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/task"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/tsdb"
+)
+
+// replicaVerifyProcessor computes the family checksums of shard replicas when receive task,
+// then reports the result into state repo, master compares the results of all replicas.
+type replicaVerifyProcessor struct {
+	engine tsdb.Engine
+	repo   state.Repository
+	node   string
+}
+
+// newReplicaVerifyProcessor returns replica verify processor instance
+func newReplicaVerifyProcessor(engine tsdb.Engine, repo state.Repository, node *models.Node) task.Processor {
+	return &replicaVerifyProcessor{
+		engine: engine,
+		repo:   repo,
+		node:   node.Indicator(),
+	}
+}
+
+func (p *replicaVerifyProcessor) Kind() task.Kind             { return constants.ReplicaVerify }
+func (p *replicaVerifyProcessor) RetryCount() int             { return 0 }
+func (p *replicaVerifyProcessor) RetryBackOff() time.Duration { return 0 }
+func (p *replicaVerifyProcessor) Concurrency() int            { return 1 }
+
+// Process computes the family checksums of shards, the failure of shard is reported in result.
+func (p *replicaVerifyProcessor) Process(ctx context.Context, task task.Task) error {
+	param := models.ReplicaVerifyTask{}
+	if err := encoding.JSONUnmarshal(task.Params, &param); err != nil {
+		return err
+	}
+	log := logger.GetLogger("coordinator", "StorageReplicaVerifyProcessor")
+	log.Info("process replica verify task", logger.String("params", string(task.Params)))
+	result := &models.ReplicaVerifyResult{Node: p.node}
+	for _, shardID := range param.ShardIDs {
+		shard, ok := p.engine.GetShard(param.DatabaseName, shardID)
+		if !ok {
+			result.Error = fmt.Sprintf("shard %d of database %s not exist", shardID, param.DatabaseName)
+			break
+		}
+		checksum, err := shard.Checksum(param.TimeRange)
+		if err != nil {
+			result.Error = fmt.Sprintf("compute checksum of shard %d error: %s", shardID, err)
+			break
+		}
+		result.Shards = append(result.Shards, *checksum)
+	}
+	if result.Error != "" {
+		log.Warn("verify replica failure", logger.String("name", task.Name), logger.String("error", result.Error))
+	}
+	return p.repo.Put(ctx,
+		constants.GetReplicaVerifyResultPath(param.DatabaseName, task.Name, p.node),
+		encoding.JSONMarshal(result))
+}
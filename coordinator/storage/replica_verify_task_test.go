@@ -0,0 +1,82 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/task"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/state"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/tsdb"
+)
+
+func TestReplicaVerifyProcessor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	engine := tsdb.NewMockEngine(ctrl)
+	repo := state.NewMockRepository(ctrl)
+	shard := tsdb.NewMockShard(ctrl)
+	node := &models.Node{IP: "1.1.1.1", Port: 2891}
+	processor := newReplicaVerifyProcessor(engine, repo, node)
+	assert.Equal(t, 1, processor.Concurrency())
+	assert.Equal(t, time.Duration(0), processor.RetryBackOff())
+	assert.Equal(t, 0, processor.RetryCount())
+	assert.Equal(t, constants.ReplicaVerify, processor.Kind())
+
+	err := processor.Process(context.TODO(), task.Task{Params: []byte{1, 1, 1}})
+	assert.Error(t, err)
+
+	timeRange := timeutil.TimeRange{Start: 10, End: 100}
+	param := models.ReplicaVerifyTask{DatabaseName: "db", ShardIDs: []int32{1, 2}, TimeRange: timeRange}
+	verifyTask := task.Task{Name: "db-1", Params: param.Bytes()}
+	path := constants.GetReplicaVerifyResultPath("db", "db-1", node.Indicator())
+	var result models.ReplicaVerifyResult
+	repo.EXPECT().Put(gomock.Any(), path, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, data []byte) error {
+		result = models.ReplicaVerifyResult{}
+		return encoding.JSONUnmarshal(data, &result)
+	}).AnyTimes()
+	// case 1: shard not exist
+	engine.EXPECT().GetShard("db", int32(1)).Return(nil, false)
+	assert.NoError(t, processor.Process(context.TODO(), verifyTask))
+	assert.NotEmpty(t, result.Error)
+	engine.EXPECT().GetShard("db", gomock.Any()).Return(shard, true).AnyTimes()
+	// case 2: checksum err
+	shard.EXPECT().Checksum(timeRange).Return(nil, fmt.Errorf("err"))
+	assert.NoError(t, processor.Process(context.TODO(), verifyTask))
+	assert.NotEmpty(t, result.Error)
+	// case 3: report checksums
+	shard.EXPECT().Checksum(timeRange).Return(&models.ShardChecksum{ShardID: 1}, nil)
+	shard.EXPECT().Checksum(timeRange).Return(&models.ShardChecksum{ShardID: 2,
+		Families: []models.FamilyChecksum{{FamilyTime: 10, Series: 1, Points: 2, Checksum: 3}}}, nil)
+	assert.NoError(t, processor.Process(context.TODO(), verifyTask))
+	assert.Empty(t, result.Error)
+	assert.Equal(t, node.Indicator(), result.Node)
+	assert.Len(t, result.Shards, 2)
+	assert.Equal(t, uint64(3), result.Shards[1].Families[0].Checksum)
+}
@@ -48,6 +48,9 @@ func NewTaskExecutor(ctx context.Context,
 	// register task processor
 	executor.Register(newCreateShardProcessor(engine))
 	executor.Register(newDatabaseFlushProcessor(engine))
+	executor.Register(newUpdateDatabaseOptionProcessor(engine))
+	executor.Register(newReplicaVerifyProcessor(engine, repo, node))
+	executor.Register(newReplicaRepairProcessor(engine))
 	return &TaskExecutor{
 		ctx:      ctx,
 		repo:     repo,
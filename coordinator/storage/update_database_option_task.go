@@ -0,0 +1,58 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/coordinator/task"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/tsdb"
+)
+
+// updateDatabaseOptionProcessor represents update database option when receive task.
+type updateDatabaseOptionProcessor struct {
+	engine tsdb.Engine
+}
+
+// newUpdateDatabaseOptionProcessor returns update database option processor instance
+func newUpdateDatabaseOptionProcessor(engine tsdb.Engine) task.Processor {
+	return &updateDatabaseOptionProcessor{
+		engine: engine,
+	}
+}
+
+func (p *updateDatabaseOptionProcessor) Kind() task.Kind             { return constants.UpdateDatabaseOption }
+func (p *updateDatabaseOptionProcessor) RetryCount() int             { return 0 }
+func (p *updateDatabaseOptionProcessor) RetryBackOff() time.Duration { return 0 }
+func (p *updateDatabaseOptionProcessor) Concurrency() int            { return 1 }
+
+// Process applies the new option to database and all shards of it
+func (p *updateDatabaseOptionProcessor) Process(_ context.Context, task task.Task) error {
+	param := models.UpdateDatabaseOptionTask{}
+	if err := encoding.JSONUnmarshal(task.Params, &param); err != nil {
+		return err
+	}
+	logger.GetLogger("coordinator", "StorageUpdateDBOptionProcessor").
+		Info("process update database option task", logger.String("params", string(task.Params)))
+	return p.engine.UpdateDatabaseOption(param.DatabaseName, param.DatabaseOption)
+}
@@ -49,6 +49,8 @@ type FilterResultSet interface {
 	Load(highKey uint16, seriesID roaring.Container) DataLoader
 	// SeriesIDs returns the series ids which matches with query series ids.
 	SeriesIDs() *roaring.Bitmap
+	// Close releases the resources(e.g. file readers) held by result set after loading data.
+	Close()
 }
 
 // DataLoader represents the loader which load metric data from storage.
@@ -0,0 +1,158 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package graphite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+// defaultFieldName represents the field name if template has no field part.
+const defaultFieldName = "value"
+
+// define errors
+var (
+	ErrTooManyTags = errors.New("graphite: too many tags")
+)
+
+// Parser parses graphite plaintext protocol line into LinDB metric.
+type Parser struct {
+	namespace       string
+	separator       string
+	templates       []*template
+	defaultTemplate *template
+}
+
+// NewParser creates graphite parser with templates, separator joins multiple measurement parts.
+func NewParser(namespace, separator string, templates []string) (*Parser, error) {
+	if separator == "" {
+		separator = "."
+	}
+	p := &Parser{namespace: namespace, separator: separator}
+	for _, str := range templates {
+		t, err := newTemplate(str)
+		if err != nil {
+			return nil, err
+		}
+		if t.filter == nil {
+			if p.defaultTemplate != nil {
+				return nil, fmt.Errorf("graphite: duplicate default template %q", str)
+			}
+			p.defaultTemplate = t
+			continue
+		}
+		p.templates = append(p.templates, t)
+	}
+	if p.defaultTemplate == nil {
+		p.defaultTemplate, _ = newTemplate(defaultTemplate)
+	}
+	return p, nil
+}
+
+// Parse parses one line of graphite plaintext protocol: "path[;tag=value...] value [timestamp]",
+// timestamp in seconds, uses current time if timestamp is missing or -1.
+// Returns nil metric for empty line or comment.
+func (p *Parser) Parse(line string) (*protoMetricsV1.Metric, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, nil
+	}
+	items := strings.Fields(line)
+	if len(items) != 2 && len(items) != 3 {
+		return nil, fmt.Errorf("graphite: invalid line %q, expect 'path value [timestamp]'", line)
+	}
+	value, err := strconv.ParseFloat(items[1], 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("graphite: invalid value %q", items[1])
+	}
+	timestamp := timeutil.Now()
+	if len(items) == 3 && items[2] != "-1" {
+		seconds, err := strconv.ParseFloat(items[2], 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("graphite: invalid timestamp %q", items[2])
+		}
+		timestamp = int64(seconds * 1000)
+	}
+
+	// graphite 1.1 tagged series, like path;tag1=value1;tag2=value2
+	pathTags := strings.Split(items[0], ";")
+	path := pathTags[0]
+	if path == "" {
+		return nil, fmt.Errorf("graphite: empty path of line %q", line)
+	}
+	pathParts := strings.Split(path, ".")
+	measurement, field, tags := p.matchTemplate(pathParts).apply(pathParts, p.separator)
+	for _, kv := range pathTags[1:] {
+		idx := strings.Index(kv, "=")
+		if idx <= 0 || idx == len(kv)-1 {
+			return nil, fmt.Errorf("graphite: invalid tag %q", kv)
+		}
+		tags[kv[:idx]] = kv[idx+1:]
+	}
+	if len(tags) >= constants.DefaultMaxTagKeysCount {
+		return nil, ErrTooManyTags
+	}
+	if field == "" {
+		field = defaultFieldName
+	}
+	m := &protoMetricsV1.Metric{
+		Namespace: p.namespace,
+		Name:      measurement,
+		Timestamp: timestamp,
+		SimpleFields: []*protoMetricsV1.SimpleField{{
+			Name:  field,
+			Type:  protoMetricsV1.SimpleFieldType_GAUGE,
+			Value: value,
+		}},
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		m.Tags = append(m.Tags, &protoMetricsV1.KeyValue{Key: k, Value: tags[k]})
+	}
+	return m, nil
+}
+
+// matchTemplate returns the most specific template which filter matches path, returns default template if not match.
+func (p *Parser) matchTemplate(pathParts []string) *template {
+	var (
+		matched     *template
+		specificity = -1
+	)
+	for _, t := range p.templates {
+		if s, ok := t.match(pathParts); ok && s > specificity {
+			matched = t
+			specificity = s
+		}
+	}
+	if matched == nil {
+		return p.defaultTemplate
+	}
+	return matched
+}
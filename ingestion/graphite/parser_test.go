@@ -0,0 +1,139 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package graphite
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func TestParser_Parse(t *testing.T) {
+	p, err := NewParser("ns", "_", []string{
+		"servers.* .host.measurement* region=sh",
+		"servers.web.* ..host.measurement.field*",
+		"stats.*.* measurement.host.host.field",
+		".measurement.measurement",
+	})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		line   string
+		metric *protoMetricsV1.Metric
+	}{
+		{
+			line: "servers.a.cpu.load 1.5 1620000000",
+			metric: &protoMetricsV1.Metric{Namespace: "ns", Name: "cpu_load", Timestamp: 1620000000000,
+				Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: "a"}, {Key: "region", Value: "sh"}},
+				SimpleFields: []*protoMetricsV1.SimpleField{
+					{Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1.5}}},
+		},
+		{
+			// more specific filter
+			line: "servers.web.a.nginx.conn.active 10 1620000000.5",
+			metric: &protoMetricsV1.Metric{Namespace: "ns", Name: "nginx", Timestamp: 1620000000500,
+				Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: "a"}},
+				SimpleFields: []*protoMetricsV1.SimpleField{
+					{Name: "conn_active", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 10}}},
+		},
+		{
+			// multiple parts of tag, extra parts ignored
+			line: "stats.a.b.count.extra 2 1620000000",
+			metric: &protoMetricsV1.Metric{Namespace: "ns", Name: "stats", Timestamp: 1620000000000,
+				Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: "a_b"}},
+				SimpleFields: []*protoMetricsV1.SimpleField{
+					{Name: "count", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 2}}},
+		},
+		{
+			// default template, graphite tagged series
+			line: "collectd.memory.used;host=a;dc=sh 3 1620000000",
+			metric: &protoMetricsV1.Metric{Namespace: "ns", Name: "memory_used", Timestamp: 1620000000000,
+				Tags: []*protoMetricsV1.KeyValue{{Key: "dc", Value: "sh"}, {Key: "host", Value: "a"}},
+				SimpleFields: []*protoMetricsV1.SimpleField{
+					{Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 3}}},
+		},
+	}
+	for _, c := range cases {
+		m, err := p.Parse(c.line)
+		assert.NoError(t, err, c.line)
+		assert.Equal(t, c.metric, m, c.line)
+	}
+
+	// no measurement part, uses whole path
+	p, err = NewParser("ns", "", []string{"a.* host.field"})
+	assert.NoError(t, err)
+	m, err := p.Parse("a.b 1 1620000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "a.b", m.Name)
+	assert.Equal(t, "b", m.SimpleFields[0].Name)
+	// default template
+	m, err = p.Parse("x.y.z 1")
+	assert.NoError(t, err)
+	assert.Equal(t, "x.y.z", m.Name)
+	assert.Empty(t, m.Tags)
+	assert.True(t, m.Timestamp > 0)
+	m, err = p.Parse("x.y.z 1 -1")
+	assert.NoError(t, err)
+	assert.True(t, m.Timestamp > 0)
+	// empty line and comment
+	for _, line := range []string{"", "  ", "# comment"} {
+		m, err = p.Parse(line)
+		assert.NoError(t, err)
+		assert.Nil(t, m)
+	}
+}
+
+func TestParser_Parse_Error(t *testing.T) {
+	p, err := NewParser("ns", ".", nil)
+	assert.NoError(t, err)
+	tooManyTags := "a"
+	for i := 0; i < 40; i++ {
+		tooManyTags += ";t" + strconv.Itoa(i) + "=v"
+	}
+	for _, line := range []string{
+		"a",
+		"a 1 2 3",
+		"a b",
+		"a NaN",
+		"a 1 b",
+		"a 1 -2",
+		";t=v 1",
+		"a;t 1",
+		"a;t= 1",
+		tooManyTags + " 1",
+	} {
+		_, err := p.Parse(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestNewParser_Error(t *testing.T) {
+	for _, templates := range [][]string{
+		{"a b c d"},
+		{"measurement a="},
+		{"* measurement a=b,c"},
+		{"measurement*.field*"},
+		{"measurement", "host.measurement*"},
+	} {
+		_, err := NewParser("ns", ".", templates)
+		assert.Error(t, err, templates)
+	}
+}
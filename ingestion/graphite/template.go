@@ -0,0 +1,153 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package graphite
+
+import (
+	"fmt"
+	"strings"
+)
+
+// define the special parts of template
+const (
+	partMeasurement         = "measurement"
+	partMeasurementWildcard = "measurement*"
+	partField               = "field"
+	partFieldWildcard       = "field*"
+
+	defaultTemplate = partMeasurementWildcard
+)
+
+// template represents the template for mapping dotted path into metric name, field and tags,
+// format: "[filter] template [tag1=value1,tag2=value2]".
+type template struct {
+	filter      []string          // filter parts, * matches any part, nil means default template
+	parts       []string          // template parts
+	defaultTags map[string]string // default tags of template
+}
+
+// newTemplate parses the template string.
+func newTemplate(str string) (*template, error) {
+	items := strings.Fields(str)
+	t := &template{}
+	switch len(items) {
+	case 1:
+		t.parts = strings.Split(items[0], ".")
+	case 2:
+		if strings.Contains(items[1], "=") {
+			t.parts = strings.Split(items[0], ".")
+			if err := t.parseTags(items[1]); err != nil {
+				return nil, err
+			}
+		} else {
+			t.filter = strings.Split(items[0], ".")
+			t.parts = strings.Split(items[1], ".")
+		}
+	case 3:
+		t.filter = strings.Split(items[0], ".")
+		t.parts = strings.Split(items[1], ".")
+		if err := t.parseTags(items[2]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("graphite: invalid template %q", str)
+	}
+	measurementWildcard, fieldWildcard := 0, 0
+	for _, part := range t.parts {
+		switch part {
+		case partMeasurementWildcard:
+			measurementWildcard++
+		case partFieldWildcard:
+			fieldWildcard++
+		}
+	}
+	if measurementWildcard+fieldWildcard > 1 {
+		return nil, fmt.Errorf("graphite: only one of measurement* or field* is allowed in template %q", str)
+	}
+	return t, nil
+}
+
+// parseTags parses the default tags of template, like tag1=value1,tag2=value2.
+func (t *template) parseTags(str string) error {
+	t.defaultTags = make(map[string]string)
+	for _, kv := range strings.Split(str, ",") {
+		idx := strings.Index(kv, "=")
+		if idx <= 0 || idx == len(kv)-1 {
+			return fmt.Errorf("graphite: invalid template tag %q", kv)
+		}
+		t.defaultTags[kv[:idx]] = kv[idx+1:]
+	}
+	return nil
+}
+
+// match checks if path parts match the filter, returns the specificity(num. of exact parts) of filter.
+func (t *template) match(pathParts []string) (specificity int, ok bool) {
+	if len(t.filter) > len(pathParts) {
+		return 0, false
+	}
+	for idx, part := range t.filter {
+		switch {
+		case part == "*":
+		case part == pathParts[idx]:
+			specificity++
+		default:
+			return 0, false
+		}
+	}
+	return specificity, true
+}
+
+// apply applies the template on path parts, returns the measurement, field and tags.
+func (t *template) apply(pathParts []string, separator string) (measurement, field string, tags map[string]string) {
+	var (
+		measurements []string
+		fields       []string
+		pathTags     = make(map[string][]string)
+	)
+	tags = make(map[string]string)
+	for k, v := range t.defaultTags {
+		tags[k] = v
+	}
+	for idx, part := range pathParts {
+		if idx >= len(t.parts) {
+			break
+		}
+		tp := t.parts[idx]
+		switch tp {
+		case "":
+		case partMeasurement:
+			measurements = append(measurements, part)
+		case partMeasurementWildcard:
+			measurements = append(measurements, pathParts[idx:]...)
+		case partField:
+			fields = append(fields, part)
+		case partFieldWildcard:
+			fields = append(fields, pathParts[idx:]...)
+		default:
+			// multiple parts of same tag are joined by separator
+			pathTags[tp] = append(pathTags[tp], part)
+			tags[tp] = strings.Join(pathTags[tp], separator)
+		}
+		if tp == partMeasurementWildcard || tp == partFieldWildcard {
+			break
+		}
+	}
+	if len(measurements) == 0 {
+		measurements = pathParts
+	}
+	return strings.Join(measurements, separator), strings.Join(fields, "_"), tags
+}
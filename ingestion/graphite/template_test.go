@@ -0,0 +1,235 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package graphite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTemplate(t *testing.T) {
+	examples := []struct {
+		str         string
+		filter      []string
+		parts       []string
+		defaultTags map[string]string
+	}{
+		{str: "measurement*", parts: []string{"measurement*"}},
+		{str: "host.measurement region=sh", parts: []string{"host", "measurement"}, defaultTags: map[string]string{"region": "sh"}},
+		{str: "servers.* .host.measurement*", filter: []string{"servers", "*"}, parts: []string{"", "host", "measurement*"}},
+		{
+			str:         "servers.* .host.field* region=sh,dc=a",
+			filter:      []string{"servers", "*"},
+			parts:       []string{"", "host", "field*"},
+			defaultTags: map[string]string{"region": "sh", "dc": "a"},
+		},
+		{str: "measurement region", filter: []string{"measurement"}, parts: []string{"region"}},
+	}
+	for _, example := range examples {
+		tmpl, err := newTemplate(example.str)
+		assert.NoError(t, err)
+		assert.Equal(t, example.filter, tmpl.filter)
+		assert.Equal(t, example.parts, tmpl.parts)
+		assert.Equal(t, example.defaultTags, tmpl.defaultTags)
+	}
+
+	// bad templates
+	for _, str := range []string{
+		"a b c d",
+		"",
+		"measurement =sh",
+		"measurement region=",
+		"* measurement region=sh,dc",
+		"measurement*.field*",
+		"measurement*.host.measurement*",
+	} {
+		tmpl, err := newTemplate(str)
+		assert.Error(t, err)
+		assert.Nil(t, tmpl)
+	}
+}
+
+func TestTemplate_match(t *testing.T) {
+	examples := []struct {
+		filter      string
+		path        string
+		specificity int
+		ok          bool
+	}{
+		// exact parts
+		{filter: "servers.web", path: "servers.web.cpu", specificity: 2, ok: true},
+		// wildcard part
+		{filter: "servers.*", path: "servers.web.cpu", specificity: 1, ok: true},
+		// all wildcard parts
+		{filter: "*.*", path: "servers.web.cpu", specificity: 0, ok: true},
+		// same length as path
+		{filter: "servers.web.cpu", path: "servers.web.cpu", specificity: 3, ok: true},
+		// filter longer than path
+		{filter: "servers.web.*", path: "servers.web"},
+		// part not match
+		{filter: "servers.db", path: "servers.web.cpu"},
+		// wildcard is not prefix match
+		{filter: "serv", path: "servers.web"},
+	}
+	for _, example := range examples {
+		tmpl, err := newTemplate(example.filter + " measurement*")
+		assert.NoError(t, err)
+		specificity, ok := tmpl.match(strings.Split(example.path, "."))
+		assert.Equal(t, example.ok, ok)
+		assert.Equal(t, example.specificity, specificity)
+	}
+}
+
+func TestTemplate_apply(t *testing.T) {
+	examples := []struct {
+		template    string
+		path        string
+		measurement string
+		field       string
+		tags        map[string]string
+	}{
+		// default template
+		{
+			template:    defaultTemplate,
+			path:        "servers.web.cpu",
+			measurement: "servers_web_cpu",
+			tags:        map[string]string{},
+		},
+		// measurement* takes the greedy tail
+		{
+			template:    "region.host.measurement*",
+			path:        "sh.web.cpu.load.1m",
+			measurement: "cpu_load_1m",
+			tags:        map[string]string{"region": "sh", "host": "web"},
+		},
+		// parts after measurement* are ignored
+		{
+			template:    "host.measurement*.region",
+			path:        "web.cpu.load",
+			measurement: "cpu_load",
+			tags:        map[string]string{"host": "web"},
+		},
+		// field* takes the greedy tail
+		{
+			template:    "measurement.host.field*",
+			path:        "nginx.web.conn.active",
+			measurement: "nginx",
+			field:       "conn_active",
+			tags:        map[string]string{"host": "web"},
+		},
+		// multiple measurement parts
+		{
+			template:    "measurement..measurement.field",
+			path:        "nginx.web.conn.active.extra",
+			measurement: "nginx_conn",
+			field:       "active",
+			tags:        map[string]string{},
+		},
+		// multiple parts of same tag
+		{
+			template:    "measurement.host.host",
+			path:        "cpu.web.1",
+			measurement: "cpu",
+			tags:        map[string]string{"host": "web_1"},
+		},
+		// path shorter than template
+		{
+			template:    "measurement.host.field",
+			path:        "cpu",
+			measurement: "cpu",
+			tags:        map[string]string{},
+		},
+		// no measurement part uses whole path
+		{
+			template:    "host.field",
+			path:        "web.load",
+			measurement: "web_load",
+			field:       "load",
+			tags:        map[string]string{"host": "web"},
+		},
+		// default tags
+		{
+			template:    "host.measurement* region=sh,dc=a",
+			path:        "web.cpu",
+			measurement: "cpu",
+			tags:        map[string]string{"host": "web", "region": "sh", "dc": "a"},
+		},
+		// path tag overrides default tag
+		{
+			template:    "host.measurement* host=default,dc=a",
+			path:        "web.cpu",
+			measurement: "cpu",
+			tags:        map[string]string{"host": "web", "dc": "a"},
+		},
+	}
+	for _, example := range examples {
+		tmpl, err := newTemplate(example.template)
+		assert.NoError(t, err)
+		measurement, field, tags := tmpl.apply(strings.Split(example.path, "."), "_")
+		assert.Equal(t, example.measurement, measurement)
+		assert.Equal(t, example.field, field)
+		assert.Equal(t, example.tags, tags)
+	}
+
+	// default tags of template are not changed by apply
+	tmpl, err := newTemplate("host.measurement* host=default")
+	assert.NoError(t, err)
+	_, _, tags := tmpl.apply([]string{"web", "cpu"}, "_")
+	tags["host"] = "changed"
+	assert.Equal(t, map[string]string{"host": "default"}, tmpl.defaultTags)
+}
+
+func TestParser_matchTemplate(t *testing.T) {
+	p, err := NewParser("ns", "_", []string{
+		"servers.* .host.measurement*",
+		"servers.web.* ..host.measurement*",
+		"servers.*.* ..measurement.host",
+		"*.web.* .measurement*",
+		"host.measurement* region=sh",
+	})
+	assert.NoError(t, err)
+	examples := []struct {
+		path     string
+		template *template
+	}{
+		// only one filter matches
+		{path: "servers.db", template: p.templates[0]},
+		// most specific filter wins
+		{path: "servers.web.a.cpu", template: p.templates[1]},
+		// first filter wins if same specificity
+		{path: "servers.db.a.cpu", template: p.templates[0]},
+		// wildcard filter
+		{path: "stats.web.a.cpu", template: p.templates[3]},
+		// default template if no filter matches
+		{path: "stats.db.cpu", template: p.defaultTemplate},
+		// default template if path shorter than filter
+		{path: "stats", template: p.defaultTemplate},
+	}
+	for _, example := range examples {
+		assert.Same(t, example.template, p.matchTemplate(strings.Split(example.path, ".")))
+	}
+	assert.Equal(t, map[string]string{"region": "sh"}, p.defaultTemplate.defaultTags)
+
+	// default template is measurement* if not set
+	p, err = NewParser("ns", "_", []string{"servers.* .host.measurement*"})
+	assert.NoError(t, err)
+	assert.Nil(t, p.defaultTemplate.filter)
+	assert.Equal(t, []string{partMeasurementWildcard}, p.defaultTemplate.parts)
+}
@@ -22,6 +22,7 @@ import (
 
 	ingestCommon "github.com/lindb/lindb/ingestion/common"
 	"github.com/lindb/lindb/pkg/strutil"
+	"github.com/lindb/lindb/pkg/timeutil"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/series/tag"
 
@@ -33,7 +34,13 @@ import (
 
 // Parse parses influxdb line protocol data to LinDB pb prometheus.
 // https://docs.influxdata.com/influxdb/v2.0/write-data/developer-tools/api/#example-api-write-request
-func Parse(req *http.Request, enrichedTags tag.Tags, namespace string) (*protoMetricsV1.MetricList, error) {
+// timestamps are converted into the precision of database.
+func Parse(
+	req *http.Request,
+	enrichedTags tag.Tags,
+	namespace string,
+	dbPrecision timeutil.Precision,
+) (*protoMetricsV1.MetricList, error) {
 	qry := req.URL.Query()
 	var reader = req.Body
 	if strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
@@ -44,21 +51,8 @@ func Parse(req *http.Request, enrichedTags tag.Tags, namespace string) (*protoMe
 		defer ingestCommon.PutGzipReader(gzipReader)
 		reader = gzipReader
 	}
-	// precision
-	multiplier := getPrecisionMultiplier(qry.Get("precision"))
-
-	cr := ingestCommon.GetChunkReader(reader)
-	defer ingestCommon.PutChunkReader(cr)
-
 	metricList := &protoMetricsV1.MetricList{}
-	for cr.HasNext() {
-		metric, err := parseInfluxLine(cr.Next(), namespace, multiplier)
-		if err != nil {
-			return nil, err
-		}
-		if metric == nil {
-			continue
-		}
+	err := Read(reader, namespace, qry.Get("precision"), dbPrecision, func(metric *protoMetricsV1.Metric) error {
 		// enrich tags
 		for _, enrichedTag := range enrichedTags {
 			tagKey := strutil.ByteSlice2String(enrichedTag.Key)
@@ -74,33 +68,71 @@ func Parse(req *http.Request, enrichedTags tag.Tags, namespace string) (*protoMe
 			metric.TagsHash = xxhash.Sum64String(tag.ConcatKeyValues(metric.Tags))
 		}
 		metricList.Metrics = append(metricList.Metrics, metric)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metricList, nil
+}
+
+// Read reads influxdb line protocol data from reader, calls fn for each parsed metric,
+// stops reading if parse failure or fn returns error.
+// precision is the precision of timestamp in data, dbPrecision is the precision of database.
+func Read(
+	reader io.Reader,
+	namespace, precision string,
+	dbPrecision timeutil.Precision,
+	fn func(metric *protoMetricsV1.Metric) error,
+) error {
+	multiplier := getPrecisionMultiplier(precision, dbPrecision)
+
+	cr := ingestCommon.GetChunkReader(reader)
+	defer ingestCommon.PutChunkReader(cr)
+
+	for cr.HasNext() {
+		metric, err := parseInfluxLine(cr.Next(), namespace, multiplier, dbPrecision)
+		if err != nil {
+			return err
+		}
+		if metric == nil {
+			continue
+		}
+		if err := fn(metric); err != nil {
+			return err
+		}
 	}
 	if cr.Error() == nil || cr.Error() == io.EOF {
-		return metricList, nil
+		return nil
 	}
-	return metricList, cr.Error()
+	return cr.Error()
 }
 
 // getPrecisionMultiplier returns a multiplier for the precision specified.
 // https://docs.influxdata.com/influxdb/v2.0/api/#operation/PostWrite
-// timestamp in lindb is milliseconds
+// timestamp in lindb is the precision of database(milliseconds by default)
 // when multiplier > 0, real_timestamp = timestamp * multiplier
 // when multiplier < 0, real_timestamp = timestamp / (-1 * multiplier)
-func getPrecisionMultiplier(precision string) int64 {
+func getPrecisionMultiplier(precision string, dbPrecision timeutil.Precision) int64 {
+	// number of nanosecond of timestamp unit
+	var unit int64
 	switch strings.ToLower(precision) {
 	case "ns":
-		return -1e6
+		unit = 1
 	case "us":
-		return -1e3
-	case "ms":
-		return 1
+		unit = 1e3
 	case "s":
-		return 1000
+		unit = 1e9
 	case "m":
-		return 1000 * 60
+		unit = 1e9 * 60
 	case "h":
-		return 1000 * 3600
+		unit = 1e9 * 3600
 	default:
-		return 1
+		unit = 1e6
+	}
+	dbUnit := int64(timeutil.Nanosecond / dbPrecision)
+	if unit >= dbUnit {
+		return unit / dbUnit
 	}
+	return -dbUnit / unit
 }
@@ -21,9 +21,12 @@ import (
 	"github.com/klauspost/compress/gzip"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/series/tag"
 
 	"bytes"
+	"fmt"
 	"net/http"
 	"strings"
 	"testing"
@@ -59,7 +62,7 @@ func Test_Parse(t *testing.T) {
 		tag.NewTag([]byte("ip"), []byte("1.1.1.1")),
 		tag.NewTag([]byte("region"), []byte("sh")),
 	}
-	metrics, err := Parse(req, enrichedTags, "ns")
+	metrics, err := Parse(req, enrichedTags, "ns", timeutil.Millisecond)
 	assert.Nil(t, err)
 	assert.NotNil(t, metrics)
 	assert.Len(t, metrics.Metrics, 6)
@@ -70,7 +73,7 @@ func Test_getGzipError(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, req)
 	req.Header.Set("Content-Encoding", "gzip")
-	_, err = Parse(req, nil, "ns")
+	_, err = Parse(req, nil, "ns", timeutil.Millisecond)
 	assert.NotNil(t, err)
 }
 
@@ -87,15 +90,52 @@ measurement value=12 1439587925
 	assert.NotNil(t, req)
 	req.Header.Set("Content-Encoding", "gzip")
 
-	_, err = Parse(req, nil, "ns")
+	_, err = Parse(req, nil, "ns", timeutil.Millisecond)
 	assert.NotNil(t, err)
 }
 
 func Test_getPrecisionMultiplier(t *testing.T) {
-	assert.Equal(t, int64(-1000000), getPrecisionMultiplier("ns"))
-	assert.Equal(t, int64(-1000), getPrecisionMultiplier("us"))
-	assert.Equal(t, int64(1), getPrecisionMultiplier("ms"))
-	assert.Equal(t, int64(1000), getPrecisionMultiplier("s"))
-	assert.Equal(t, int64(60000), getPrecisionMultiplier("m"))
-	assert.Equal(t, int64(3600000), getPrecisionMultiplier("h"))
+	assert.Equal(t, int64(-1000000), getPrecisionMultiplier("ns", timeutil.Millisecond))
+	assert.Equal(t, int64(-1000), getPrecisionMultiplier("us", timeutil.Millisecond))
+	assert.Equal(t, int64(1), getPrecisionMultiplier("ms", timeutil.Millisecond))
+	assert.Equal(t, int64(1), getPrecisionMultiplier("", timeutil.Millisecond))
+	assert.Equal(t, int64(1000), getPrecisionMultiplier("s", timeutil.Millisecond))
+	assert.Equal(t, int64(60000), getPrecisionMultiplier("m", timeutil.Millisecond))
+	assert.Equal(t, int64(3600000), getPrecisionMultiplier("h", timeutil.Millisecond))
+	// precision of database
+	assert.Equal(t, int64(1), getPrecisionMultiplier("ns", timeutil.Nanosecond))
+	assert.Equal(t, int64(1000000), getPrecisionMultiplier("ms", timeutil.Nanosecond))
+	assert.Equal(t, int64(-1000), getPrecisionMultiplier("ns", timeutil.Microsecond))
+	assert.Equal(t, int64(1000000), getPrecisionMultiplier("s", timeutil.Microsecond))
+}
+
+func Test_Read_Precision(t *testing.T) {
+	var timestamps []int64
+	err := Read(strings.NewReader("cpu value=1 1465839830100400200\ncpu value=2"), "ns", "ns", timeutil.Nanosecond,
+		func(metric *protoMetricsV1.Metric) error {
+			timestamps = append(timestamps, metric.Timestamp)
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Len(t, timestamps, 2)
+	assert.Equal(t, int64(1465839830100400200), timestamps[0])
+	// no timestamp, uses now with precision of database
+	assert.True(t, timestamps[1] > timeutil.Nanosecond.FromMillis(timeutil.Now()-timeutil.OneMinute))
+}
+
+func Test_Read(t *testing.T) {
+	count := 0
+	err := Read(strings.NewReader(_testBody), "ns", "s", timeutil.Millisecond, func(metric *protoMetricsV1.Metric) error {
+		assert.Equal(t, "ns", metric.Namespace)
+		count++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	// fn err
+	err = Read(strings.NewReader(_testBody), "ns", "s", timeutil.Millisecond, func(metric *protoMetricsV1.Metric) error {
+		return fmt.Errorf("err")
+	})
+	assert.Error(t, err)
 }
@@ -41,7 +41,12 @@ var (
 // Test cases in
 // https://github.com/influxdata/influxdb/blob/master/models/points_test.go
 
-func parseInfluxLine(content []byte, namespace string, multiplier int64) (*protoMetricsV1.Metric, error) {
+func parseInfluxLine(
+	content []byte,
+	namespace string,
+	multiplier int64,
+	dbPrecision timeutil.Precision,
+) (*protoMetricsV1.Metric, error) {
 	// skip comment line
 	if bytes.HasPrefix(content, []byte{'#'}) {
 		return nil, nil
@@ -81,7 +86,7 @@ func parseInfluxLine(content []byte, namespace string, multiplier int64) (*proto
 	}
 
 	// parse timestamp
-	if m.Timestamp, err = parseTimestamp(content, fieldsEndAt+1, multiplier); err != nil {
+	if m.Timestamp, err = parseTimestamp(content, fieldsEndAt+1, multiplier, dbPrecision); err != nil {
 		return nil, err
 	}
 	return &m, nil
@@ -266,25 +271,31 @@ func parseField(key, value []byte) (*protoMetricsV1.SimpleField, error) {
 			return nil, ErrBadFields
 		}
 		return &protoMetricsV1.SimpleField{
-			Name:  string(unescapedKey),
-			Type:  guessFieldType(key),
-			Value: float64(v),
+			Name:      string(unescapedKey),
+			Type:      guessFieldType(key),
+			Value:     float64(v),
+			ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE,
+			IntValue:  v,
 		}, nil
 	case 't', 'T': // boolean true
 		if len(value) == 1 {
 			return &protoMetricsV1.SimpleField{
-				Name:  string(unescapedKey),
-				Type:  protoMetricsV1.SimpleFieldType_GAUGE,
-				Value: float64(1),
+				Name:      string(unescapedKey),
+				Type:      protoMetricsV1.SimpleFieldType_GAUGE,
+				Value:     float64(1),
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE,
+				IntValue:  1,
 			}, nil
 		}
 		return nil, ErrBadFields
 	case 'f', 'F': // boolean false
 		if len(value) == 1 {
 			return &protoMetricsV1.SimpleField{
-				Name:  string(unescapedKey),
-				Type:  protoMetricsV1.SimpleFieldType_GAUGE,
-				Value: float64(0),
+				Name:      string(unescapedKey),
+				Type:      protoMetricsV1.SimpleFieldType_GAUGE,
+				Value:     float64(0),
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE,
+				IntValue:  0,
 			}, nil
 		}
 		return nil, ErrBadFields
@@ -295,15 +306,19 @@ func parseField(key, value []byte) (*protoMetricsV1.SimpleField, error) {
 		switch lf {
 		case "false", "False", "FALSE":
 			return &protoMetricsV1.SimpleField{
-				Name:  string(unescapedKey),
-				Type:  protoMetricsV1.SimpleFieldType_GAUGE,
-				Value: float64(0),
+				Name:      string(unescapedKey),
+				Type:      protoMetricsV1.SimpleFieldType_GAUGE,
+				Value:     float64(0),
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE,
+				IntValue:  0,
 			}, nil
 		case "true", "True", "TRUE":
 			return &protoMetricsV1.SimpleField{
-				Name:  string(unescapedKey),
-				Type:  protoMetricsV1.SimpleFieldType_GAUGE,
-				Value: float64(1),
+				Name:      string(unescapedKey),
+				Type:      protoMetricsV1.SimpleFieldType_GAUGE,
+				Value:     float64(1),
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE,
+				IntValue:  1,
 			}, nil
 		default:
 			// todo, decimal, such like 1e-3, 1e20, -3e23
@@ -331,10 +346,10 @@ func guessFieldType(key []byte) protoMetricsV1.SimpleFieldType {
 	}
 }
 
-func parseTimestamp(buf []byte, startAt int, multiplier int64) (int64, error) {
+func parseTimestamp(buf []byte, startAt int, multiplier int64, dbPrecision timeutil.Precision) (int64, error) {
 	// no timestamp
 	if startAt >= len(buf) {
-		return timeutil.Now(), nil
+		return dbPrecision.FromMillis(timeutil.Now()), nil
 	}
 	f, err := strconv.ParseInt(string(buf[startAt:]), 10, 64)
 	if err != nil {
@@ -19,6 +19,7 @@ package influx
 
 import (
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/timeutil"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/series/tag"
 
@@ -37,12 +38,12 @@ func Test_tooManyTags(t *testing.T) {
 		tagPair = append(tagPair, fmt.Sprintf("%s=%s", v, v))
 	}
 	line := fmt.Sprintf("mmm,%s x=1,y=2 1465839830100400200", strings.Join(tagPair, ","))
-	_, err := parseInfluxLine([]byte(line), "ns", -1e6)
+	_, err := parseInfluxLine([]byte(line), "ns", -1e6, timeutil.Millisecond)
 	assert.Equal(t, ErrTooManyTags, err)
 }
 
 func Test_noTags_noTimestamp(t *testing.T) {
-	m, err := parseInfluxLine([]byte("cpu value=1"), "ns2", -1e6)
+	m, err := parseInfluxLine([]byte("cpu value=1"), "ns2", -1e6, timeutil.Millisecond)
 	assert.Nil(t, err)
 	assert.NotZero(t, m.Timestamp)
 	assert.Empty(t, m.Tags)
@@ -59,7 +60,7 @@ func Test_badTimestamp(t *testing.T) {
 		"cpu value=1 9223372036854775807 12",
 	}
 	for _, line := range lines {
-		m, err := parseInfluxLine([]byte(line), "ns3", 1)
+		m, err := parseInfluxLine([]byte(line), "ns3", 1, timeutil.Millisecond)
 		assert.Equal(t, ErrBadTimestamp, err)
 		assert.Nil(t, m)
 	}
@@ -81,7 +82,7 @@ func Test_tags(t *testing.T) {
 		{`cpu,tag0=1\"\",t=k value=1`, map[string]string{"tag0": `1\"\"`, "t": "k"}},
 	}
 	for _, example := range examples {
-		m, err := parseInfluxLine([]byte(example.Line), "ns", 1e6)
+		m, err := parseInfluxLine([]byte(example.Line), "ns", 1e6, timeutil.Millisecond)
 		assert.NotNil(t, m)
 		assert.Nil(t, err)
 		assert.EqualValues(t, example.Tags, tag.KeyValues(m.Tags).Map())
@@ -100,7 +101,7 @@ func Test_InvalidLine(t *testing.T) {
 		{`# `, nil},
 	}
 	for _, example := range examples {
-		_, err := parseInfluxLine([]byte(example.Line), "ns", 1e6)
+		_, err := parseInfluxLine([]byte(example.Line), "ns", 1e6, timeutil.Millisecond)
 		assert.Equal(t, example.Err, err)
 	}
 }
@@ -119,7 +120,7 @@ func Test_metricName(t *testing.T) {
 		{`cpu\\\,\ a, tag0=v0 value=1`, "cpu\\\\, a"},
 	}
 	for _, example := range examples {
-		m, err := parseInfluxLine([]byte(example.Line), "ns", 1e6)
+		m, err := parseInfluxLine([]byte(example.Line), "ns", 1e6, timeutil.Millisecond)
 		assert.NotNil(t, m)
 		assert.Nil(t, err)
 		assert.Equal(t, example.MetricName, m.Name)
@@ -142,7 +143,7 @@ func Test_missingTagValues(t *testing.T) {
 		{`cpu,host=f\==o,`, ErrMissingWhiteSpace},
 	}
 	for _, example := range examples {
-		m, err := parseInfluxLine([]byte(example.Line), "ns", -1e6)
+		m, err := parseInfluxLine([]byte(example.Line), "ns", -1e6, timeutil.Millisecond)
 		assert.Equal(t, example.Err, err)
 		assert.Nil(t, m)
 	}
@@ -159,7 +160,7 @@ func Test_missingFieldNames(t *testing.T) {
 		{`cpu,host=serverA,region=us-west value=123i,=456i`, ErrBadFields},
 	}
 	for _, example := range examples {
-		_, err := parseInfluxLine([]byte(example.Line), "ns", 1e6)
+		_, err := parseInfluxLine([]byte(example.Line), "ns", 1e6, timeutil.Millisecond)
 		assert.Equal(t, example.Err, err)
 	}
 }
@@ -177,6 +178,7 @@ func Test_parseUnescapedMetric(t *testing.T) {
 			map[string]string{},
 			[]*protoMetricsV1.SimpleField{{
 				Name: "value_total", Type: protoMetricsV1.SimpleFieldType_CUMULATIVE_SUM, Value: 1,
+				ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE, IntValue: 1,
 			}},
 		},
 		// comma in metric name with tags
@@ -201,6 +203,7 @@ func Test_parseUnescapedMetric(t *testing.T) {
 			map[string]string{"region": "east"},
 			[]*protoMetricsV1.SimpleField{{
 				Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 0,
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE, IntValue: 0,
 			}},
 		},
 		// equals in metric name, boolean true
@@ -209,6 +212,7 @@ func Test_parseUnescapedMetric(t *testing.T) {
 			map[string]string{"region": "east"},
 			[]*protoMetricsV1.SimpleField{{
 				Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1,
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE, IntValue: 1,
 			}},
 		},
 		// commas in tag names, boolean true
@@ -217,6 +221,7 @@ func Test_parseUnescapedMetric(t *testing.T) {
 			map[string]string{"region,zone": "east"},
 			[]*protoMetricsV1.SimpleField{{
 				Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1,
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE, IntValue: 1,
 			}},
 		},
 		// spaces in tag name, boolean false
@@ -225,6 +230,7 @@ func Test_parseUnescapedMetric(t *testing.T) {
 			map[string]string{"region zone": "east"},
 			[]*protoMetricsV1.SimpleField{{
 				Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 0,
+				ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE, IntValue: 0,
 			}},
 		},
 		// backslash with escaped equals in tag name, decimal value
@@ -297,6 +303,16 @@ func Test_parseUnescapedMetric(t *testing.T) {
 			map[string]string{},
 			[]*protoMetricsV1.SimpleField{{
 				Name: "\\a", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1,
+				ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE, IntValue: 1,
+			}},
+		},
+		// int value above 2^53 keeps precision
+		{`cpu value=9007199254740993i`,
+			`cpu`,
+			map[string]string{},
+			[]*protoMetricsV1.SimpleField{{
+				Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 9007199254740993,
+				ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE, IntValue: 9007199254740993,
 			}},
 		},
 		// measurement, tag and tag value with equals
@@ -304,13 +320,15 @@ func Test_parseUnescapedMetric(t *testing.T) {
 			`cpu=load`,
 			map[string]string{"equals=foo": "tag=value"},
 			[]*protoMetricsV1.SimpleField{
-				{Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1},
-				{Name: "bool", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 0},
+				{Name: "value", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1,
+					ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE, IntValue: 1},
+				{Name: "bool", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 0,
+					ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE},
 			}},
 	}
 
 	for _, example := range examples {
-		m, err := parseInfluxLine([]byte(example.Line), "ns", -1e6)
+		m, err := parseInfluxLine([]byte(example.Line), "ns", -1e6, timeutil.Millisecond)
 		assert.Nil(t, err)
 		assert.Equal(t, example.MetricName, m.Name)
 		assert.Equal(t, example.Tags, tag.KeyValues(m.Tags).Map())
@@ -333,7 +351,7 @@ func Test_parseBadFields(t *testing.T) {
 		`cpu,regions=east value=2f`,
 	}
 	for _, line := range lines {
-		_, err := parseInfluxLine([]byte(line), "ns", 1e6)
+		_, err := parseInfluxLine([]byte(line), "ns", 1e6, timeutil.Millisecond)
 		assert.Equal(t, ErrBadFields, err)
 	}
 }
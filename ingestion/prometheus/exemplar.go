@@ -0,0 +1,218 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+var (
+	exemplarSeparator = []byte(" # {")
+	bucketSuffix      = "_bucket"
+	bucketLabel       = "le"
+)
+
+// extractExemplars strips the exemplars(OpenMetrics format, like `# {trace_id="abc"} 0.5 1600000000.123`)
+// from the sample lines of histogram buckets, because the text parser cannot parse them,
+// returns the text without exemplars and the exemplars keyed by the series of histogram.
+func extractExemplars(data []byte) ([]byte, map[string][]*protoMetricsV1.Exemplar) {
+	if !bytes.Contains(data, exemplarSeparator) {
+		return data, nil
+	}
+	exemplars := make(map[string][]*protoMetricsV1.Exemplar)
+	lines := bytes.Split(data, []byte("\n"))
+	for idx, line := range lines {
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		pos := bytes.Index(line, exemplarSeparator)
+		if pos < 0 {
+			continue
+		}
+		sample, exemplarText := string(line[:pos]), string(line[pos+3:])
+		lines[idx] = line[:pos]
+		name, labels, ok := parseSeries(sample)
+		if !ok || !strings.HasSuffix(name, bucketSuffix) {
+			continue
+		}
+		exemplar, ok := parseExemplar(exemplarText)
+		if !ok {
+			continue
+		}
+		delete(labels, bucketLabel)
+		key := exemplarSeriesKey(strings.TrimSuffix(name, bucketSuffix), labels)
+		exemplars[key] = append(exemplars[key], exemplar)
+	}
+	return bytes.Join(lines, []byte("\n")), exemplars
+}
+
+// parseExemplar parses the exemplar text, like `{trace_id="abc",span_id="def"} 0.5 1600000000.123`.
+func parseExemplar(text string) (*protoMetricsV1.Exemplar, bool) {
+	labels, rest, ok := parseLabels(text)
+	if !ok {
+		return nil, false
+	}
+	traceID := firstLabel(labels, "trace_id", "traceID", "trace-id")
+	if traceID == "" {
+		return nil, false
+	}
+	parts := strings.Fields(rest)
+	if len(parts) == 0 {
+		return nil, false
+	}
+	value, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, false
+	}
+	exemplar := &protoMetricsV1.Exemplar{
+		TraceId: decodeID(traceID),
+		SpanId:  decodeID(firstLabel(labels, "span_id", "spanID", "span-id")),
+		Value:   value,
+	}
+	if len(parts) > 1 {
+		// timestamp of OpenMetrics is seconds
+		if seconds, err := strconv.ParseFloat(parts[1], 64); err == nil {
+			exemplar.Timestamp = int64(seconds * 1000)
+		}
+	}
+	return exemplar, true
+}
+
+// parseSeries parses the metric name and labels of sample, like `name{a="b"}`.
+func parseSeries(sample string) (name string, labels map[string]string, ok bool) {
+	sample = strings.TrimSpace(sample)
+	pos := strings.IndexAny(sample, "{ \t")
+	if pos < 0 {
+		return "", nil, false
+	}
+	name = sample[:pos]
+	rest := strings.TrimLeft(sample[pos:], " \t")
+	if !strings.HasPrefix(rest, "{") {
+		return name, map[string]string{}, true
+	}
+	labels, _, ok = parseLabels(rest)
+	return name, labels, ok
+}
+
+// parseLabels parses the label set which starts with '{', returns labels and the text after '}'.
+func parseLabels(text string) (labels map[string]string, rest string, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") {
+		return nil, "", false
+	}
+	labels = make(map[string]string)
+	i := 1
+	for {
+		for i < len(text) && (text[i] == ' ' || text[i] == '\t' || text[i] == ',') {
+			i++
+		}
+		if i >= len(text) {
+			return nil, "", false
+		}
+		if text[i] == '}' {
+			return labels, text[i+1:], true
+		}
+		eq := strings.IndexByte(text[i:], '=')
+		if eq < 0 {
+			return nil, "", false
+		}
+		key := strings.TrimSpace(text[i : i+eq])
+		i += eq + 1
+		for i < len(text) && (text[i] == ' ' || text[i] == '\t') {
+			i++
+		}
+		if i >= len(text) || text[i] != '"' {
+			return nil, "", false
+		}
+		i++
+		var value strings.Builder
+		for ; i < len(text) && text[i] != '"'; i++ {
+			if text[i] == '\\' && i+1 < len(text) {
+				i++
+				switch text[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(text[i])
+				}
+				continue
+			}
+			value.WriteByte(text[i])
+		}
+		if i >= len(text) {
+			return nil, "", false
+		}
+		i++ // skip closing quote
+		labels[key] = value.String()
+	}
+}
+
+// exemplarSeriesKey returns the key of histogram series, labels are sorted by name.
+func exemplarSeriesKey(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, key := range keys {
+		sb.WriteByte(',')
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(labels[key])
+	}
+	return sb.String()
+}
+
+// histogramSeriesKey returns the key of histogram series parsed by text parser.
+func histogramSeriesKey(name string, dtoMetric *dto.Metric) string {
+	labels := make(map[string]string, len(dtoMetric.Label))
+	for _, label := range dtoMetric.Label {
+		labels[label.GetName()] = label.GetValue()
+	}
+	return exemplarSeriesKey(name, labels)
+}
+
+func firstLabel(labels map[string]string, names ...string) string {
+	for _, name := range names {
+		if value, ok := labels[name]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// decodeID decodes the hex trace/span id, uses raw bytes if id isn't hex encoded.
+func decodeID(id string) []byte {
+	if id == "" {
+		return nil
+	}
+	if decoded, err := hex.DecodeString(id); err == nil {
+		return decoded
+	}
+	return []byte(id)
+}
@@ -0,0 +1,99 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/tag"
+)
+
+func TestPromParse_Exemplars(t *testing.T) {
+	input := `# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{path="/api",le="0.5"} 10 # {trace_id="0af7651916cd43dd",span_id="b7ad6b71"} 0.32 1600000000.5
+http_request_duration_seconds_bucket{le="+Inf", path="/api"} 12 # {trace_id="not-hex"} 1.2
+http_request_duration_seconds_sum{path="/api"} 7
+http_request_duration_seconds_count{path="/api"} 12
+http_request_duration_seconds_bucket{path="/home",le="0.5"} 1
+http_request_duration_seconds_bucket{path="/home",le="+Inf"} 1
+http_request_duration_seconds_sum{path="/home"} 0.1
+http_request_duration_seconds_count{path="/home"} 1
+# TYPE http_requests_total counter
+http_requests_total{path="/api"} 12 # {trace_id="0af7"} 1
+`
+	metrics, _, err := promParse(strings.NewReader(input), tag.Tags{}, "ns", timeutil.Millisecond)
+	assert.NoError(t, err)
+	exemplars := make(map[string][]*protoMetricsV1.Exemplar)
+	for _, m := range metrics.Metrics {
+		if m.CompoundField != nil {
+			exemplars[m.Tags[0].Value] = m.CompoundField.Exemplars
+		}
+	}
+	assert.Len(t, exemplars, 2)
+	assert.Equal(t, []*protoMetricsV1.Exemplar{
+		{
+			TraceId:   []byte{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd},
+			SpanId:    []byte{0xb7, 0xad, 0x6b, 0x71},
+			Value:     0.32,
+			Timestamp: 1600000000500,
+		},
+		{TraceId: []byte("not-hex"), Value: 1.2},
+	}, exemplars["/api"])
+	assert.Empty(t, exemplars["/home"])
+}
+
+func TestExtractExemplars(t *testing.T) {
+	// no exemplars
+	data, exemplars := extractExemplars([]byte("a 1"))
+	assert.Equal(t, "a 1", string(data))
+	assert.Nil(t, exemplars)
+	// bad exemplars are stripped
+	data, exemplars = extractExemplars([]byte(`a_bucket{le="1"} 1 # {span_id="1"} 1
+a_bucket{le="2"} 1 # {trace_id="1"} x
+a_bucket{le="3" 1 # {trace_id="1"} 1
+a_bucket{le="4"} 1 # {trace_id="1}
+a_bucket{le="5"} 1 # {trace_id=1} 1
+a_bucket{le="6"} 1 # {trace_id="1"}`))
+	assert.Equal(t, `a_bucket{le="1"} 1
+a_bucket{le="2"} 1
+a_bucket{le="3" 1
+a_bucket{le="4"} 1
+a_bucket{le="5"} 1
+a_bucket{le="6"} 1`, string(data))
+	assert.Empty(t, exemplars)
+}
+
+func TestParseSeries(t *testing.T) {
+	name, labels, ok := parseSeries("a 1")
+	assert.True(t, ok)
+	assert.Equal(t, "a", name)
+	assert.Empty(t, labels)
+	_, _, ok = parseSeries("a")
+	assert.False(t, ok)
+	name, labels, ok = parseSeries(`a { b = "c\"\n", d="e" } 1`)
+	assert.True(t, ok)
+	assert.Equal(t, "a", name)
+	assert.Equal(t, map[string]string{"b": "c\"\n", "d": "e"}, labels)
+	_, _, ok = parseSeries(`a{b} 1`)
+	assert.False(t, ok)
+}
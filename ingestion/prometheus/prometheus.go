@@ -18,8 +18,10 @@
 package prometheus
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -29,8 +31,10 @@ import (
 
 	ingestCommon "github.com/lindb/lindb/ingestion/common"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/timeutil"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/series/tag"
 )
 
@@ -46,36 +50,51 @@ var (
 	badHistogramCounter      = prometheusIngestionScope.NewDeltaCounter("bad_histograms")
 )
 
-// Parse parses prometheus text
-func Parse(req *http.Request, enrichedTags tag.Tags, namespace string) (*protoMetricsV1.MetricList, error) {
+// unitSuffixes are the base units of prometheus metric naming conventions, like http_request_duration_seconds.
+var unitSuffixes = []string{"seconds", "bytes", "ratio", "percent", "celsius", "meters", "volts", "amperes", "joules", "grams"}
+
+// Parse parses prometheus text, returns the metrics and the descriptors inferred from HELP/TYPE/metric name,
+// timestamps(millisecond) are converted into the precision of database.
+func Parse(req *http.Request, enrichedTags tag.Tags, namespace string, dbPrecision timeutil.Precision) (
+	*protoMetricsV1.MetricList, []*models.MetricDescriptor, error,
+) {
 	var reader = req.Body
 	if strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
 		gzipReader, err := ingestCommon.GetGzipReader(req.Body)
 		if err != nil {
 			corruptedGzipCounter.Incr()
-			return nil, fmt.Errorf("ingestion corrupted gzip data: %w", err)
+			return nil, nil, fmt.Errorf("ingestion corrupted gzip data: %w", err)
 		}
 		defer ingestCommon.PutGzipReader(gzipReader)
 		reader = gzipReader
 	}
 
-	return promParse(reader, enrichedTags, namespace)
+	return promParse(reader, enrichedTags, namespace, dbPrecision)
 }
 
 // promParse parses prometheus text prometheus to LinDB pb prometheus.
-func promParse(reader io.Reader, enrichedTags tag.Tags, namespace string) (*protoMetricsV1.MetricList, error) {
+func promParse(reader io.Reader, enrichedTags tag.Tags, namespace string, dbPrecision timeutil.Precision) (
+	*protoMetricsV1.MetricList, []*models.MetricDescriptor, error,
+) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, exemplars := extractExemplars(data)
 	parser := &expfmt.TextParser{}
-	out, err := parser.TextToMetricFamilies(reader)
+	out, err := parser.TextToMetricFamilies(bytes.NewReader(data))
 	if err != nil && len(out) == 0 {
-		return nil, err
+		return nil, nil, err
 	}
 	metricList := &protoMetricsV1.MetricList{}
+	var descriptors []*models.MetricDescriptor
 	for name, pm := range out {
 		metricType := *pm.Type
 		if metricType == dto.MetricType_UNTYPED {
 			unsupportedCounter.Incr()
 			continue
 		}
+		numOfMetrics := len(metricList.Metrics)
 		for _, m := range pm.Metric {
 			metric := &protoMetricsV1.Metric{
 				Name:      name,
@@ -85,10 +104,13 @@ func promParse(reader io.Reader, enrichedTags tag.Tags, namespace string) (*prot
 			if !set {
 				continue
 			}
+			if len(exemplars) > 0 && metric.CompoundField != nil {
+				metric.CompoundField.Exemplars = exemplars[histogramSeriesKey(name, m)]
+			}
 			if m.TimestampMs != nil {
-				metric.Timestamp = *m.TimestampMs
+				metric.Timestamp = dbPrecision.FromMillis(*m.TimestampMs)
 			} else {
-				metric.Timestamp = timeutil.Now()
+				metric.Timestamp = dbPrecision.FromMillis(timeutil.Now())
 			}
 			tagCount := len(m.Label)
 			if tagCount > 0 {
@@ -124,8 +146,40 @@ func promParse(reader io.Reader, enrichedTags tag.Tags, namespace string) (*prot
 
 			metricList.Metrics = append(metricList.Metrics, metric)
 		}
+		if len(metricList.Metrics) > numOfMetrics {
+			descriptors = append(descriptors, inferDescriptor(namespace, name, metricType, pm.GetHelp()))
+		}
+	}
+	return metricList, descriptors, nil
+}
+
+// inferDescriptor infers the metric descriptor from metric type/help, unit is inferred from metric name's suffix.
+func inferDescriptor(namespace, name string, metricType dto.MetricType, help string) *models.MetricDescriptor {
+	descriptor := &models.MetricDescriptor{
+		Namespace:   namespace,
+		Name:        name,
+		Type:        strings.ToLower(metricType.String()),
+		Description: help,
+	}
+	baseName := strings.TrimSuffix(name, "_total")
+	for _, unit := range unitSuffixes {
+		if strings.HasSuffix(baseName, "_"+unit) {
+			descriptor.Unit = unit
+			break
+		}
+	}
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		descriptor.Fields = []models.Field{{Name: "counter", Type: field.SumField.String()}}
+	case dto.MetricType_GAUGE:
+		descriptor.Fields = []models.Field{{Name: "gauge", Type: field.GaugeField.String()}}
+	case dto.MetricType_HISTOGRAM:
+		descriptor.Fields = []models.Field{
+			{Name: field.HistogramConverter.CountFieldName, Type: field.SumField.String()},
+			{Name: field.HistogramConverter.SumFieldName, Type: field.SumField.String()},
+		}
 	}
-	return metricList, nil
+	return descriptor
 }
 
 func setField(metric *protoMetricsV1.Metric, metricType dto.MetricType, dtoMetric *dto.Metric) bool {
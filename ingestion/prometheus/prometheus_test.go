@@ -20,9 +20,12 @@ package prometheus
 import (
 	"bytes"
 	"net/http"
+	"sort"
 	"strings"
 	"testing"
 
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/series/tag"
 
 	"github.com/klauspost/compress/gzip"
@@ -91,10 +94,10 @@ func Test_Parse(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodPut, "", r1)
 	req.Header.Set("Content-Encoding", "gzip")
 
-	metricList, err := Parse(req, []tag.Tag{
+	metricList, _, err := Parse(req, []tag.Tag{
 		tag.NewTag([]byte("zone"), []byte("bj")),
 		tag.NewTag([]byte("host"), []byte("abcd")),
-	}, "ns")
+	}, "ns", timeutil.Millisecond)
 	assert.Nil(t, err)
 	assert.NotNil(t, metricList)
 
@@ -102,37 +105,93 @@ func Test_Parse(t *testing.T) {
 	r2 := bytes.NewReader([]byte(goodText))
 	req, _ = http.NewRequest(http.MethodPut, "", r2)
 	req.Header.Set("Content-Encoding", "gzip")
-	metricList, err = Parse(req, []tag.Tag{}, "ns")
+	metricList, _, err = Parse(req, []tag.Tag{}, "ns", timeutil.Millisecond)
 	assert.NotNil(t, err)
 	assert.Nil(t, metricList)
 }
 
+func TestPromParse_Precision(t *testing.T) {
+	input := "# TYPE cpu_usage gauge\ncpu_usage 1 1465839830100\n"
+	metrics, _, err := promParse(strings.NewReader(input), tag.Tags{}, "ns", timeutil.Nanosecond)
+	assert.NoError(t, err)
+	assert.Len(t, metrics.Metrics, 1)
+	assert.Equal(t, int64(1465839830100*1000*1000), metrics.Metrics[0].Timestamp)
+}
+
 func TestPromParse(t *testing.T) {
 	input := goodText
 	input += "\n# HELP metric foo\x00bar"
 	input += "\nnull_byte_metric{a=\"abc\x00\"} 1\n"
 
-	metrics, err := promParse(strings.NewReader(input), tag.Tags{}, "ns")
+	metrics, descriptors, err := promParse(strings.NewReader(input), tag.Tags{}, "ns", timeutil.Millisecond)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, metrics)
+	assert.NotEmpty(t, descriptors)
 
-	metrics, err = promParse(strings.NewReader("empty"), tag.Tags{}, "ns")
+	metrics, descriptors, err = promParse(strings.NewReader("empty"), tag.Tags{}, "ns", timeutil.Millisecond)
 	assert.Error(t, err)
 	assert.Empty(t, metrics)
+	assert.Empty(t, descriptors)
 	input = `# HELP go_gc_duration_seconds A summary of the GC invocation durations.
 # 	TYPE go_gc_duration_seconds summary
 go_gc_duration_seconds{method="post",code="400",quantile="0"} 4.9351e-05
 go_gc_duration_seconds { quantile = "0.9999" } 8.38`
-	metrics, err = promParse(strings.NewReader(input), tag.Tags{}, "ns")
+	metrics, descriptors, err = promParse(strings.NewReader(input), tag.Tags{}, "ns", timeutil.Millisecond)
 	assert.NoError(t, err)
 	assert.Empty(t, metrics)
+	assert.Empty(t, descriptors)
 	input = `# HELP go_gc_duration_seconds A summary of the GC invocation durations.
 # 	TYPE go_gc_duration_seconds summary
 go_gc_duration_seconds { quantile = "0.9999" } NaN
 go_gc_duration_seconds_count 9
 go_gc_duration_seconds_sum 90
 `
-	metrics, err = promParse(strings.NewReader(input), tag.Tags{}, "ns")
+	metrics, descriptors, err = promParse(strings.NewReader(input), tag.Tags{}, "ns", timeutil.Millisecond)
 	assert.NoError(t, err)
 	assert.Empty(t, metrics)
+	assert.Empty(t, descriptors)
+}
+
+func TestPromParse_Descriptors(t *testing.T) {
+	input := `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+# HELP process_resident_memory_bytes Resident memory size in bytes.
+# TYPE process_resident_memory_bytes gauge
+process_resident_memory_bytes 1.2e+07
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.5"} 129389
+http_request_duration_seconds_bucket{le="+Inf"} 144320
+http_request_duration_seconds_sum 53423
+http_request_duration_seconds_count 144320
+`
+	_, descriptors, err := promParse(strings.NewReader(input), tag.Tags{}, "ns", timeutil.Millisecond)
+	assert.NoError(t, err)
+	sort.Slice(descriptors, func(i, j int) bool {
+		return descriptors[i].Name < descriptors[j].Name
+	})
+	assert.Equal(t, []*models.MetricDescriptor{
+		{
+			Namespace: "ns",
+			Name:      "http_request_duration_seconds",
+			Type:      "histogram",
+			Unit:      "seconds",
+			Fields:    []models.Field{{Name: "HistogramCount", Type: "sum"}, {Name: "HistogramSum", Type: "sum"}},
+		},
+		{
+			Namespace:   "ns",
+			Name:        "http_requests_total",
+			Type:        "counter",
+			Description: "The total number of HTTP requests.",
+			Fields:      []models.Field{{Name: "counter", Type: "sum"}},
+		},
+		{
+			Namespace:   "ns",
+			Name:        "process_resident_memory_bytes",
+			Type:        "gauge",
+			Unit:        "bytes",
+			Description: "Resident memory size in bytes.",
+			Fields:      []models.Field{{Name: "gauge", Type: "gauge"}},
+		},
+	}, descriptors)
 }
@@ -0,0 +1,177 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+// DefaultTimerBounds represents the default upper bounds(milliseconds) of timer histogram buckets.
+var DefaultTimerBounds = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+const (
+	counterFieldName = "count"
+	gaugeFieldName   = "gauge"
+)
+
+// series represents the aggregated value of one statsd metric in flush interval.
+type series struct {
+	name    string
+	typ     MetricType
+	tags    []*protoMetricsV1.KeyValue
+	value   float64 // counter sum or gauge value
+	updated bool    // if gauge is updated in flush interval
+
+	// timer histogram
+	min, max, sum, count float64
+	values               []float64
+}
+
+// Aggregator aggregates statsd samples in memory, counters are summed, gauges keep the last value,
+// timers are aggregated into histogram, then flushes them as LinDB metrics periodically.
+type Aggregator struct {
+	namespace string
+	bounds    []float64 // sorted upper bounds, last one is +Inf
+	series    map[string]*series
+
+	mutex sync.Mutex
+}
+
+// NewAggregator creates the statsd aggregator, uses DefaultTimerBounds if timer bounds is empty.
+func NewAggregator(namespace string, timerBounds []float64) *Aggregator {
+	if len(timerBounds) == 0 {
+		timerBounds = DefaultTimerBounds
+	}
+	bounds := make([]float64, 0, len(timerBounds)+1)
+	bounds = append(bounds, timerBounds...)
+	sort.Float64s(bounds)
+	if !math.IsInf(bounds[len(bounds)-1], 1) {
+		bounds = append(bounds, math.Inf(1))
+	}
+	return &Aggregator{
+		namespace: namespace,
+		bounds:    bounds,
+		series:    make(map[string]*series),
+	}
+}
+
+// Add aggregates the sample, same name with different type will be kept as different series.
+func (a *Aggregator) Add(s *Sample) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	key := seriesKey(s)
+	ss, ok := a.series[key]
+	if !ok {
+		ss = &series{name: s.Name, typ: s.Type, tags: s.Tags}
+		if s.Type == Timer {
+			ss.values = make([]float64, len(a.bounds))
+		}
+		a.series[key] = ss
+	}
+	switch s.Type {
+	case Counter:
+		ss.value += s.Value / s.SampleRate
+	case Gauge:
+		if s.Relative {
+			ss.value += s.Value
+		} else {
+			ss.value = s.Value
+		}
+		ss.updated = true
+	case Timer:
+		count := 1 / s.SampleRate
+		if ss.count == 0 || s.Value < ss.min {
+			ss.min = s.Value
+		}
+		if ss.count == 0 || s.Value > ss.max {
+			ss.max = s.Value
+		}
+		ss.sum += s.Value * count
+		ss.count += count
+		ss.values[sort.SearchFloat64s(a.bounds, s.Value)] += count
+	}
+}
+
+// Flush returns the aggregated metrics with timestamp, then resets counters and timers.
+// Gauges are kept for relative update, but only flushed if updated in this interval.
+func (a *Aggregator) Flush(timestamp int64) []*protoMetricsV1.Metric {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var metrics []*protoMetricsV1.Metric
+	for key, ss := range a.series {
+		m := &protoMetricsV1.Metric{
+			Namespace: a.namespace,
+			Name:      ss.name,
+			Timestamp: timestamp,
+			Tags:      ss.tags,
+		}
+		switch ss.typ {
+		case Counter:
+			m.SimpleFields = []*protoMetricsV1.SimpleField{{
+				Name:  counterFieldName,
+				Type:  protoMetricsV1.SimpleFieldType_DELTA_SUM,
+				Value: ss.value,
+			}}
+			delete(a.series, key)
+		case Gauge:
+			if !ss.updated {
+				continue
+			}
+			m.SimpleFields = []*protoMetricsV1.SimpleField{{
+				Name:  gaugeFieldName,
+				Type:  protoMetricsV1.SimpleFieldType_GAUGE,
+				Value: ss.value,
+			}}
+			ss.updated = false
+		case Timer:
+			m.CompoundField = &protoMetricsV1.CompoundField{
+				Type:           protoMetricsV1.CompoundFieldType_DELTA_HISTOGRAM,
+				Min:            ss.min,
+				Max:            ss.max,
+				Sum:            ss.sum,
+				Count:          ss.count,
+				ExplicitBounds: append([]float64(nil), a.bounds...),
+				Values:         ss.values,
+			}
+			delete(a.series, key)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// seriesKey returns the unique key of sample by type, name and tags.
+func seriesKey(s *Sample) string {
+	var sb strings.Builder
+	sb.WriteByte(byte('0' + s.Type))
+	sb.WriteString(s.Name)
+	for _, tag := range s.Tags {
+		sb.WriteByte('|')
+		sb.WriteString(tag.Key)
+		sb.WriteByte('=')
+		sb.WriteString(tag.Value)
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func TestAggregator_Flush(t *testing.T) {
+	a := NewAggregator("ns", []float64{100, 10})
+	assert.Equal(t, []float64{10, 100, math.Inf(1)}, a.bounds)
+
+	for _, line := range []string{
+		"requests:1|c",
+		"requests:1|c|@0.5",
+		"requests:1|c|#host:a",
+		"conn:10|g",
+		"conn:-3|g",
+		"latency:5|ms",
+		"latency:50|ms",
+		"latency:500|ms",
+		"latency:10|ms",
+	} {
+		s, err := Parse(line)
+		assert.NoError(t, err)
+		a.Add(s)
+	}
+	metrics := a.Flush(1000)
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Name == metrics[j].Name {
+			return len(metrics[i].Tags) < len(metrics[j].Tags)
+		}
+		return metrics[i].Name < metrics[j].Name
+	})
+	assert.Equal(t, []*protoMetricsV1.Metric{
+		{Namespace: "ns", Name: "conn", Timestamp: 1000,
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "gauge", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 7}}},
+		{Namespace: "ns", Name: "latency", Timestamp: 1000,
+			CompoundField: &protoMetricsV1.CompoundField{
+				Type: protoMetricsV1.CompoundFieldType_DELTA_HISTOGRAM,
+				Min:  5, Max: 500, Sum: 565, Count: 4,
+				ExplicitBounds: []float64{10, 100, math.Inf(1)},
+				Values:         []float64{2, 1, 1},
+			}},
+		{Namespace: "ns", Name: "requests", Timestamp: 1000,
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "count", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 3}}},
+		{Namespace: "ns", Name: "requests", Timestamp: 1000,
+			Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: "a"}},
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "count", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 1}}},
+	}, metrics)
+
+	// gauge not updated, counters and timers are reset
+	assert.Empty(t, a.Flush(2000))
+
+	// relative gauge update based on last value
+	s, _ := Parse("conn:+1|g")
+	a.Add(s)
+	metrics = a.Flush(3000)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, 8.0, metrics[0].SimpleFields[0].Value)
+}
+
+func TestNewAggregator_DefaultBounds(t *testing.T) {
+	a := NewAggregator("ns", nil)
+	assert.Len(t, a.bounds, len(DefaultTimerBounds)+1)
+	a = NewAggregator("ns", []float64{1, math.Inf(1)})
+	assert.Equal(t, []float64{1, math.Inf(1)}, a.bounds)
+}
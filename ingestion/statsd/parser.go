@@ -0,0 +1,143 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lindb/lindb/constants"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+// MetricType represents the statsd metric type.
+type MetricType uint8
+
+// Defines all supported statsd metric types.
+const (
+	Counter MetricType = iota + 1
+	Gauge
+	Timer
+)
+
+// define errors
+var (
+	ErrUnsupportedType = errors.New("statsd: unsupported metric type")
+	ErrTooManyTags     = errors.New("statsd: too many tags")
+)
+
+// Sample represents one parsed statsd line.
+type Sample struct {
+	Name       string
+	Type       MetricType
+	Value      float64
+	SampleRate float64
+	// Relative is true if gauge value has '+'/'-' sign, which modifies current gauge value.
+	Relative bool
+	// Tags are sorted by key.
+	Tags []*protoMetricsV1.KeyValue
+}
+
+// Parse parses one statsd line: "name:value|type[|@sample_rate][|#tag1:value1,tag2:value2]",
+// supported types: c(counter), g(gauge), ms/h(timer), tags are in dogstatsd format.
+// Returns nil sample for empty line.
+func Parse(line string) (*Sample, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, nil
+	}
+	idx := strings.LastIndex(line[:indexOrLen(line, '|')], ":")
+	if idx <= 0 {
+		return nil, fmt.Errorf("statsd: invalid line %q, expect 'name:value|type'", line)
+	}
+	s := &Sample{Name: line[:idx], SampleRate: 1}
+	items := strings.Split(line[idx+1:], "|")
+	if len(items) < 2 {
+		return nil, fmt.Errorf("statsd: invalid line %q, expect 'name:value|type'", line)
+	}
+	switch items[1] {
+	case "c":
+		s.Type = Counter
+	case "g":
+		s.Type = Gauge
+		s.Relative = strings.HasPrefix(items[0], "+") || strings.HasPrefix(items[0], "-")
+	case "ms", "h":
+		s.Type = Timer
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedType, items[1])
+	}
+	value, err := strconv.ParseFloat(items[0], 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("statsd: invalid value %q", items[0])
+	}
+	s.Value = value
+
+	for _, item := range items[2:] {
+		switch {
+		case strings.HasPrefix(item, "@"):
+			rate, err := strconv.ParseFloat(item[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return nil, fmt.Errorf("statsd: invalid sample rate %q", item)
+			}
+			s.SampleRate = rate
+		case strings.HasPrefix(item, "#"):
+			if err := s.parseTags(item[1:]); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("statsd: invalid section %q", item)
+		}
+	}
+	return s, nil
+}
+
+// parseTags parses dogstatsd tags: "tag1:value1,tag2:value2".
+func (s *Sample) parseTags(str string) error {
+	tags := make(map[string]string)
+	for _, kv := range strings.Split(str, ",") {
+		idx := strings.Index(kv, ":")
+		if idx <= 0 || idx == len(kv)-1 {
+			return fmt.Errorf("statsd: invalid tag %q", kv)
+		}
+		tags[kv[:idx]] = kv[idx+1:]
+	}
+	if len(tags) >= constants.DefaultMaxTagKeysCount {
+		return ErrTooManyTags
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s.Tags = append(s.Tags, &protoMetricsV1.KeyValue{Key: k, Value: tags[k]})
+	}
+	return nil
+}
+
+// indexOrLen returns the index of first c in str, returns the length of str if not found.
+func indexOrLen(str string, c byte) int {
+	if idx := strings.IndexByte(str, c); idx >= 0 {
+		return idx
+	}
+	return len(str)
+}
@@ -0,0 +1,99 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		line   string
+		sample *Sample
+	}{
+		{
+			line:   "app.requests:2|c",
+			sample: &Sample{Name: "app.requests", Type: Counter, Value: 2, SampleRate: 1},
+		},
+		{
+			line: "app.requests:1|c|@0.1|#host:a,dc:sh",
+			sample: &Sample{Name: "app.requests", Type: Counter, Value: 1, SampleRate: 0.1,
+				Tags: []*protoMetricsV1.KeyValue{{Key: "dc", Value: "sh"}, {Key: "host", Value: "a"}}},
+		},
+		{
+			line:   "app.conn:10|g",
+			sample: &Sample{Name: "app.conn", Type: Gauge, Value: 10, SampleRate: 1},
+		},
+		{
+			line:   "app.conn:-2|g",
+			sample: &Sample{Name: "app.conn", Type: Gauge, Value: -2, SampleRate: 1, Relative: true},
+		},
+		{
+			line:   "app.conn:+3|g",
+			sample: &Sample{Name: "app.conn", Type: Gauge, Value: 3, SampleRate: 1, Relative: true},
+		},
+		{
+			line: "app.latency:32.5|ms|#url:a:b",
+			sample: &Sample{Name: "app.latency", Type: Timer, Value: 32.5, SampleRate: 1,
+				Tags: []*protoMetricsV1.KeyValue{{Key: "url", Value: "a:b"}}},
+		},
+		{
+			line:   "app.size:100|h",
+			sample: &Sample{Name: "app.size", Type: Timer, Value: 100, SampleRate: 1},
+		},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.line)
+		assert.NoError(t, err, c.line)
+		assert.Equal(t, c.sample, s, c.line)
+	}
+	s, err := Parse("  ")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+}
+
+func TestParse_Error(t *testing.T) {
+	tooManyTags := "a:1|c|#"
+	for i := 0; i < 40; i++ {
+		tooManyTags += "t" + strconv.Itoa(i) + ":v,"
+	}
+	for _, line := range []string{
+		"a",
+		":1|c",
+		"a:1",
+		"a:b|c",
+		"a:NaN|g",
+		"a:1|c|@2",
+		"a:1|c|@b",
+		"a:1|c|x",
+		"a:1|c|#t",
+		"a:1|c|#t:",
+		tooManyTags[:len(tooManyTags)-1],
+	} {
+		_, err := Parse(line)
+		assert.Error(t, err, line)
+	}
+	_, err := Parse("a:1|s")
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+}
@@ -19,6 +19,7 @@ package concurrent
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -30,28 +31,126 @@ import (
 const (
 	// size of the queue that workers register their availability to the dispatcher.
 	readyWorkerQueueSize = 32
-	// size of the tasks queue
+	// default size of the pending tasks queue for each priority
 	tasksCapacity = 8
-	// sleeps in this interval when there are no available workers
-	sleepInterval = time.Millisecond * 5
 )
 
+var (
+	// ErrPoolStopped represents the task is submitted to a stopped pool.
+	ErrPoolStopped = errors.New("worker pool is stopped")
+	// ErrPoolQueueFull represents the task is rejected because pending queue is full.
+	ErrPoolQueueFull = errors.New("worker pool pending queue is full")
+)
+
+// Priority represents the priority of task, pending tasks with higher priority are dispatched first.
+type Priority int
+
+const (
+	// PriorityHigh is the priority for interactive task, such as query.
+	PriorityHigh Priority = iota
+	// PriorityNormal is the default priority.
+	PriorityNormal
+	// PriorityLow is the priority for background task, such as flush/compaction.
+	PriorityLow
+
+	priorityCount = int(PriorityLow) + 1
+)
+
+// String returns the string value of priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// RejectPolicy represents the policy when the pending queue of pool is full.
+type RejectPolicy int
+
+const (
+	// RejectBlock blocks the submitter until the pending queue has free space.
+	RejectBlock RejectPolicy = iota
+	// RejectAbort rejects the task immediately.
+	RejectAbort
+	// RejectTimeout blocks the submitter until timeout, then rejects the task.
+	RejectTimeout
+)
+
+// PoolOptions represents the options of worker pool.
+type PoolOptions struct {
+	// QueueCapacity is the capacity of pending queue for each priority.
+	QueueCapacity int
+	// RejectPolicy is the policy when pending queue is full.
+	RejectPolicy RejectPolicy
+	// RejectTimeout is the max waiting time for RejectTimeout policy.
+	RejectTimeout time.Duration
+}
+
+// priorityKey is the context key of task priority.
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx carrying the priority used by SubmitWithContext.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// priorityFromContext returns the priority carried by ctx, default is PriorityNormal.
+func priorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
 // Task represents a task function to be executed by a worker(goroutine).
 type Task func()
 
+// pendingTask represents the task waiting in the pending queue.
+type pendingTask struct {
+	ctx       context.Context // context of submitter, nil if the task cannot be abandoned
+	priority  Priority
+	task      Task
+	startTime time.Time
+}
+
+// cancelled returns true if the submitter has abandoned the task.
+func (t *pendingTask) cancelled() bool {
+	return t.ctx != nil && t.ctx.Err() != nil
+}
+
 // Pool represents the goroutine pool that executes submitted tasks.
 type Pool interface {
-	// Submit enqueues a callable task for a worker to execute.
+	// Submit enqueues a callable task with normal priority for a worker to execute.
 	//
 	// Each submitted task is immediately given to an ready worker.
 	// If there are no available workers, the dispatcher starts a new worker,
 	// until the maximum number of workers are added.
 	//
 	// After the maximum number of workers are running, and no workers are ready,
-	// execute function will be blocked.
-	Submit(task Task)
+	// the task is pending in queue, if the queue is full, handled by reject policy.
+	// Returns ErrPoolQueueFull if rejected by reject policy, ErrPoolStopped if pool is stopped,
+	// the caller must handle the error, because the task will never be executed.
+	Submit(task Task) error
+	// SubmitWithPriority enqueues a callable task with priority,
+	// returns ErrPoolQueueFull if the task is rejected by reject policy.
+	SubmitWithPriority(priority Priority, task Task) error
+	// SubmitWithContext enqueues a callable task with the priority carried by ctx(see WithPriority),
+	// waiting for queue space is abandoned when ctx is done, and the task is dropped
+	// without executing if ctx is done before a worker picks it up.
+	// Returns ctx.Err() if abandoned, ErrPoolQueueFull if rejected by reject policy.
+	SubmitWithContext(ctx context.Context, task Task) error
+	// TrySubmit enqueues a callable task with normal priority without blocking,
+	// returns false if the pool is stopped or the pending queue is full.
+	TrySubmit(task Task) bool
 	// SubmitAndWait executes the task and waits for it to be executed.
 	SubmitAndWait(task Task)
+	// SetMaxWorkers changes the maximum number of workers at runtime,
+	// excess workers are stopped after they finish current tasks.
+	SetMaxWorkers(maxWorkers int)
 	// Stopped returns true if this pool has been stopped.
 	Stopped() bool
 	// Stop stops all goroutines gracefully,
@@ -62,35 +161,51 @@ type Pool interface {
 // workerPool is a pool for goroutines.
 type workerPool struct {
 	name                string
-	maxWorkers          int
-	tasks               chan Task                    // tasks channel
-	readyWorkers        chan *worker                 // available worker
-	idleTimeout         time.Duration                // idle goroutine recycle time
-	onDispatcherStopped chan struct{}                // signal that dispatcher is stopped
-	stopped             atomic.Bool                  // mark if the pool is closed or not
-	workersAlive        *linmetric.BoundGauge        // current workers count in use
-	workersCreated      *linmetric.BoundDeltaCounter // workers created count since start
-	workersKilled       *linmetric.BoundDeltaCounter // workers killed since start
-	tasksConsumed       *linmetric.BoundDeltaCounter // tasks consumed count
-	tasksWaitingTime    *linmetric.BoundDeltaCounter // tasks waiting total time
-	tasksExecutingTime  *linmetric.BoundDeltaCounter // tasks executing total time with waiting period
+	maxWorkers          atomic.Int32
+	tasks               [priorityCount]chan *pendingTask            // pending tasks channel of each priority
+	readyWorkers        chan *worker                                // available worker
+	idleTimeout         time.Duration                               // idle goroutine recycle time
+	rejectPolicy        RejectPolicy                                // policy when pending queue is full
+	rejectTimeout       time.Duration                               // max waiting time when pending queue is full
+	onDispatcherStopped chan struct{}                               // signal that dispatcher is stopped
+	stopped             atomic.Bool                                 // mark if the pool is closed or not
+	aliveWorkers        atomic.Int32                                // current workers count of this pool
+	workersAlive        *linmetric.BoundGauge                       // current workers count in use
+	workersCreated      *linmetric.BoundDeltaCounter                // workers created count since start
+	workersKilled       *linmetric.BoundDeltaCounter                // workers killed since start
+	tasksConsumed       *linmetric.BoundDeltaCounter                // tasks consumed count
+	tasksWaitingTime    *linmetric.BoundDeltaCounter                // tasks waiting total time
+	tasksExecutingTime  *linmetric.BoundDeltaCounter                // tasks executing total time with waiting period
+	tasksPending        [priorityCount]*linmetric.BoundGauge        // pending tasks count of each priority
+	tasksRejected       [priorityCount]*linmetric.BoundDeltaCounter // rejected tasks count of each priority
+	tasksCancelled      *linmetric.BoundDeltaCounter                // tasks abandoned by submitter
 	ctx                 context.Context
 	cancel              context.CancelFunc
 }
 
-// NewPool returns a new worker pool,
+// NewPool returns a new worker pool with default options,
 // maxWorkers parameter specifies the maximum number workers that will execute tasks concurrently.
 func NewPool(name string, maxWorkers int, idleTimeout time.Duration, scope linmetric.Scope) Pool {
+	return NewPoolWithOptions(name, maxWorkers, idleTimeout, PoolOptions{}, scope)
+}
+
+// NewPoolWithOptions returns a new worker pool with bounded pending queue and reject policy.
+func NewPoolWithOptions(name string, maxWorkers int, idleTimeout time.Duration,
+	opts PoolOptions, scope linmetric.Scope,
+) Pool {
 	if maxWorkers < 1 {
 		maxWorkers = 1
 	}
+	if opts.QueueCapacity < 1 {
+		opts.QueueCapacity = tasksCapacity
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	pool := &workerPool{
 		name:                name,
-		maxWorkers:          maxWorkers,
-		tasks:               make(chan Task, tasksCapacity),
 		readyWorkers:        make(chan *worker, readyWorkerQueueSize),
 		idleTimeout:         idleTimeout,
+		rejectPolicy:        opts.RejectPolicy,
+		rejectTimeout:       opts.RejectTimeout,
 		onDispatcherStopped: make(chan struct{}),
 		stopped:             *atomic.NewBool(false),
 		workersAlive:        scope.NewGauge("workers_alive"),
@@ -99,25 +214,140 @@ func NewPool(name string, maxWorkers int, idleTimeout time.Duration, scope linme
 		tasksConsumed:       scope.NewDeltaCounter("tasks_consumed"),
 		tasksWaitingTime:    scope.NewDeltaCounter("tasks_waiting_duration_sum"),
 		tasksExecutingTime:  scope.NewDeltaCounter("tasks_executing_duration_sum"),
+		tasksCancelled:      scope.NewDeltaCounter("tasks_cancelled"),
 		ctx:                 ctx,
 		cancel:              cancel,
 	}
+	pool.maxWorkers.Store(int32(maxWorkers))
+	tasksPending := scope.NewGaugeVec("tasks_pending", "priority")
+	tasksRejected := scope.NewDeltaCounterVec("tasks_rejected", "priority")
+	for idx := range pool.tasks {
+		priority := Priority(idx).String()
+		pool.tasks[idx] = make(chan *pendingTask, opts.QueueCapacity)
+		pool.tasksPending[idx] = tasksPending.WithTagValues(priority)
+		pool.tasksRejected[idx] = tasksRejected.WithTagValues(priority)
+	}
 	go pool.dispatch()
 	return pool
 }
 
-func (p *workerPool) Submit(task Task) {
+func (p *workerPool) Submit(task Task) error {
+	return p.SubmitWithPriority(PriorityNormal, task)
+}
+
+func (p *workerPool) SubmitWithPriority(priority Priority, task Task) error {
+	return p.submit(nil, priority, task)
+}
+
+func (p *workerPool) SubmitWithContext(ctx context.Context, task Task) error {
+	return p.submit(ctx, priorityFromContext(ctx), task)
+}
+
+func (p *workerPool) TrySubmit(task Task) bool {
 	if task == nil || p.Stopped() {
-		return
+		return false
 	}
-	startTime := time.Now()
-	p.tasks <- func() {
-		p.tasksWaitingTime.Add(float64(time.Since(startTime).Nanoseconds() / 1e6))
-		task()
-		p.tasksExecutingTime.Add(float64(time.Since(startTime).Nanoseconds() / 1e6))
+	t := p.newPendingTask(nil, PriorityNormal, task)
+	select {
+	case p.tasks[PriorityNormal] <- t:
+		return true
+	default:
+	}
+	p.tasksPending[PriorityNormal].Decr()
+	p.tasksRejected[PriorityNormal].Incr()
+	return false
+}
+
+// newPendingTask creates a pending task, and marks it as pending.
+func (p *workerPool) newPendingTask(ctx context.Context, priority Priority, task Task) *pendingTask {
+	if priority < PriorityHigh || priority > PriorityLow {
+		priority = PriorityNormal
+	}
+	p.tasksPending[priority].Incr()
+	return &pendingTask{
+		ctx:       ctx,
+		priority:  priority,
+		task:      task,
+		startTime: time.Now(),
 	}
 }
 
+// submit enqueues the task, waiting for queue space is handled by reject policy,
+// and abandoned if ctx is done.
+func (p *workerPool) submit(ctx context.Context, priority Priority, task Task) error {
+	if task == nil {
+		return nil
+	}
+	if p.Stopped() {
+		return ErrPoolStopped
+	}
+	var done <-chan struct{}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			p.tasksCancelled.Incr()
+			return err
+		}
+		done = ctx.Done()
+	}
+	t := p.newPendingTask(ctx, priority, task)
+	tasks := p.tasks[t.priority]
+	switch p.rejectPolicy {
+	case RejectAbort:
+		select {
+		case tasks <- t:
+			return nil
+		default:
+		}
+	case RejectTimeout:
+		timer := time.NewTimer(p.rejectTimeout)
+		defer timer.Stop()
+		select {
+		case tasks <- t:
+			return nil
+		case <-done:
+			p.drop(t)
+			return ctx.Err()
+		case <-p.ctx.Done():
+			p.tasksPending[t.priority].Decr()
+			return ErrPoolStopped
+		case <-timer.C:
+		}
+	default:
+		select {
+		case tasks <- t:
+			return nil
+		case <-done:
+			p.drop(t)
+			return ctx.Err()
+		case <-p.ctx.Done():
+			// pool stopped when waiting queue space
+			p.tasksPending[t.priority].Decr()
+			return ErrPoolStopped
+		}
+	}
+	p.tasksPending[t.priority].Decr()
+	p.tasksRejected[t.priority].Incr()
+	return ErrPoolQueueFull
+}
+
+// drop discards the pending task abandoned by submitter.
+func (p *workerPool) drop(t *pendingTask) {
+	p.tasksPending[t.priority].Decr()
+	p.tasksCancelled.Incr()
+}
+
+// run executes the pending task, skips it if the submitter has abandoned it.
+func (p *workerPool) run(t *pendingTask) {
+	if t.cancelled() {
+		p.drop(t)
+		return
+	}
+	p.tasksPending[t.priority].Decr()
+	p.tasksWaitingTime.Add(float64(time.Since(t.startTime).Nanoseconds() / 1e6))
+	t.task()
+	p.tasksExecutingTime.Add(float64(time.Since(t.startTime).Nanoseconds() / 1e6))
+}
+
 func (p *workerPool) SubmitAndWait(task Task) {
 	if task == nil || p.Stopped() {
 		return
@@ -125,6 +355,12 @@ func (p *workerPool) SubmitAndWait(task Task) {
 	startTime := time.Now()
 	worker := p.mustGetWorker()
 	p.tasksWaitingTime.Add(float64(time.Since(startTime).Nanoseconds() / 1e6))
+	if worker == nil {
+		// pool stopped when waiting worker, executes the task directly
+		task()
+		p.tasksExecutingTime.Add(float64(time.Since(startTime).Nanoseconds() / 1e6))
+		return
+	}
 	doneChan := make(chan struct{})
 	worker.execute(func() {
 		task()
@@ -134,24 +370,87 @@ func (p *workerPool) SubmitAndWait(task Task) {
 	p.tasksExecutingTime.Add(float64(time.Since(startTime).Nanoseconds() / 1e6))
 }
 
-// mustGetWorker makes sure that a ready worker is return
+// mustGetWorker makes sure that a ready worker is return,
+// waits a worker becomes ready if all workers are busy,
+// returns nil if the pool is stopped when waiting.
 func (p *workerPool) mustGetWorker() *worker {
-	var worker *worker
 	for {
 		select {
 		// got a worker
-		case worker = <-p.readyWorkers:
-			return worker
+		case worker := <-p.readyWorkers:
+			if !p.retire(worker) {
+				return worker
+			}
+			continue
 		default:
-			if int(p.workersAlive.Get()) >= p.maxWorkers {
-				// no available workers
-				time.Sleep(sleepInterval)
-				continue
+		}
+		if worker := p.tryNewWorker(); worker != nil {
+			return worker
+		}
+		// no available workers, waits a worker completes its task
+		select {
+		case worker := <-p.readyWorkers:
+			if !p.retire(worker) {
+				return worker
 			}
-			w := newWorker(p)
-			return w
+		case <-p.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// tryNewWorker creates a new worker if alive workers less than the maximum number, returns nil if not.
+// The worker count is reserved by CAS, because SubmitAndWait and dispatcher may create workers concurrently.
+func (p *workerPool) tryNewWorker() *worker {
+	for {
+		alive := p.aliveWorkers.Load()
+		if alive >= p.maxWorkers.Load() {
+			return nil
+		}
+		if p.aliveWorkers.CAS(alive, alive+1) {
+			return newWorker(p)
+		}
+	}
+}
+
+// retire stops the ready worker if alive workers exceed the maximum number after shrinking.
+func (p *workerPool) retire(w *worker) bool {
+	if p.aliveWorkers.Load() <= p.maxWorkers.Load() {
+		return false
+	}
+	w.stop(func() {})
+	return true
+}
+
+// nextTask returns the pending task with the highest priority, returns nil if no pending task.
+func (p *workerPool) nextTask() *pendingTask {
+	for _, tasks := range p.tasks {
+		select {
+		case task := <-tasks:
+			return task
+		default:
 		}
 	}
+	return nil
+}
+
+// execute gives the task to a ready worker, drops the task abandoned by submitter
+// without occupying a worker.
+func (p *workerPool) execute(t *pendingTask) {
+	if t.cancelled() {
+		p.drop(t)
+		return
+	}
+	worker := p.mustGetWorker()
+	if worker == nil {
+		// pool stopped when waiting worker, executes the task directly
+		p.run(t)
+		p.tasksConsumed.Incr()
+		return
+	}
+	worker.execute(func() {
+		p.run(t)
+	})
 }
 
 func (p *workerPool) dispatch() {
@@ -163,20 +462,28 @@ func (p *workerPool) dispatch() {
 	defer idleTimeoutTimer.Stop()
 	var (
 		worker *worker
-		task   Task
+		task   *pendingTask
 	)
 
 	for {
+		// dispatch pending task with higher priority first
+		if task = p.nextTask(); task != nil {
+			p.execute(task)
+			continue
+		}
 		idleTimeoutTimer.Reset(p.idleTimeout)
 		select {
 		case <-p.ctx.Done():
 			return
-		case task = <-p.tasks:
-			worker := p.mustGetWorker()
-			worker.execute(task)
+		case task = <-p.tasks[PriorityHigh]:
+			p.execute(task)
+		case task = <-p.tasks[PriorityNormal]:
+			p.execute(task)
+		case task = <-p.tasks[PriorityLow]:
+			p.execute(task)
 		case <-idleTimeoutTimer.C:
 			// timed out waiting, kill a ready worker
-			if p.workersAlive.Get() > 0 {
+			if p.aliveWorkers.Load() > 0 {
 				select {
 				case worker = <-p.readyWorkers:
 					worker.stop(func() {})
@@ -188,6 +495,13 @@ func (p *workerPool) dispatch() {
 	}
 }
 
+func (p *workerPool) SetMaxWorkers(maxWorkers int) {
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+	p.maxWorkers.Store(int32(maxWorkers))
+}
+
 func (p *workerPool) Stopped() bool {
 	return p.stopped.Load()
 }
@@ -195,7 +509,7 @@ func (p *workerPool) Stopped() bool {
 // stopWorkers stops all workers
 func (p *workerPool) stopWorkers() {
 	var wg sync.WaitGroup
-	for p.workersAlive.Get() > 0 {
+	for p.aliveWorkers.Load() > 0 {
 		wg.Add(1)
 		worker := <-p.readyWorkers
 		worker.stop(func() {
@@ -207,14 +521,9 @@ func (p *workerPool) stopWorkers() {
 
 // consumedRemainingTasks consumes all buffered tasks in the channel
 func (p *workerPool) consumedRemainingTasks() {
-	for {
-		select {
-		case task := <-p.tasks:
-			task()
-			p.tasksConsumed.Incr()
-		default:
-			return
-		}
+	for task := p.nextTask(); task != nil; task = p.nextTask() {
+		p.run(task)
+		p.tasksConsumed.Incr()
 	}
 }
 
@@ -240,7 +549,8 @@ type worker struct {
 	stopCh chan struct{}
 }
 
-// newWorker creates the worker that executes tasks given by the dispatcher
+// newWorker creates the worker that executes tasks given by the dispatcher,
+// the alive workers count must be reserved by caller(see tryNewWorker).
 // When a new worker starts, it registers itself on the createdWorkers channel.
 func newWorker(pool *workerPool) *worker {
 	w := &worker{
@@ -263,6 +573,7 @@ func (w *worker) stop(callable func()) {
 	defer callable()
 	w.stopCh <- struct{}{}
 	w.pool.workersKilled.Incr()
+	w.pool.aliveWorkers.Dec()
 	w.pool.workersAlive.Decr()
 }
 
@@ -18,7 +18,9 @@
 package concurrent
 
 import (
+	"context"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -71,3 +73,260 @@ func Test_Pool_Statistics(t *testing.T) {
 	p.Stop()
 	assert.Equal(t, float64(0), wp.workersAlive.Get())
 }
+
+func Test_Pool_Priority(t *testing.T) {
+	p := NewPool("test", 1, time.Second*5, linmetric.NewScope("3"))
+	defer p.Stop()
+
+	block := make(chan struct{})
+	done := make(chan struct{})
+	var (
+		mutex sync.Mutex
+		rs    []string
+	)
+	record := func(name string) Task {
+		return func() {
+			mutex.Lock()
+			rs = append(rs, name)
+			mutex.Unlock()
+			if name == "normal4" {
+				close(done)
+			}
+		}
+	}
+	p.Submit(func() { <-block })
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, p.SubmitWithPriority(PriorityNormal, record("normal1")))
+	// wait dispatcher takes normal1, waiting for ready worker
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, p.SubmitWithPriority(PriorityLow, record("low1")))
+	assert.NoError(t, p.SubmitWithPriority(PriorityNormal, record("normal2")))
+	assert.NoError(t, p.SubmitWithPriority(PriorityNormal, record("normal3")))
+	assert.NoError(t, p.SubmitWithPriority(PriorityHigh, record("high1")))
+	assert.NoError(t, p.SubmitWithPriority(Priority(100), record("normal4")))
+	assert.NoError(t, p.SubmitWithPriority(PriorityHigh, nil))
+	close(block)
+	<-done
+	time.Sleep(50 * time.Millisecond)
+	mutex.Lock()
+	assert.Equal(t, []string{"normal1", "high1", "normal2", "normal3", "normal4", "low1"}, rs)
+	mutex.Unlock()
+	assert.Equal(t, "high", PriorityHigh.String())
+	assert.Equal(t, "normal", PriorityNormal.String())
+	assert.Equal(t, "low", PriorityLow.String())
+}
+
+func Test_Pool_Reject(t *testing.T) {
+	examples := []struct {
+		policy string
+		opts   PoolOptions
+	}{
+		{"abort", PoolOptions{QueueCapacity: 1, RejectPolicy: RejectAbort}},
+		{"timeout", PoolOptions{QueueCapacity: 1, RejectPolicy: RejectTimeout, RejectTimeout: 10 * time.Millisecond}},
+	}
+	for _, example := range examples {
+		p := NewPoolWithOptions("test", 1, time.Second*5, example.opts, linmetric.NewScope("4", "policy", example.policy))
+		wp := p.(*workerPool)
+		block := make(chan struct{})
+		var c atomic.Int32
+		p.Submit(func() { <-block })
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, p.SubmitWithPriority(PriorityHigh, func() { c.Inc() }))
+		// wait dispatcher takes the task, waiting for ready worker
+		time.Sleep(50 * time.Millisecond)
+		assert.NoError(t, p.SubmitWithPriority(PriorityHigh, func() { c.Inc() }))
+		assert.Equal(t, ErrPoolQueueFull, p.SubmitWithPriority(PriorityHigh, func() { c.Inc() }))
+		// one task is waiting for ready worker, one task is in queue
+		assert.Equal(t, float64(2), wp.tasksPending[PriorityHigh].Get())
+		// other priority has its own queue
+		assert.NoError(t, p.SubmitWithPriority(PriorityNormal, func() { c.Inc() }))
+		close(block)
+		p.Stop()
+		assert.Equal(t, int32(3), c.Load())
+		assert.Equal(t, float64(0), wp.tasksPending[PriorityHigh].Get())
+		assert.Equal(t, ErrPoolStopped, p.SubmitWithPriority(PriorityHigh, func() {}))
+		assert.Equal(t, ErrPoolStopped, p.Submit(func() {}))
+	}
+}
+
+func Test_Pool_Stop_when_blocked(t *testing.T) {
+	p := NewPoolWithOptions("test", 1, time.Second*5, PoolOptions{QueueCapacity: 1}, linmetric.NewScope("8"))
+	wp := p.(*workerPool)
+	block := make(chan struct{})
+	p.Submit(func() { <-block })
+	time.Sleep(10 * time.Millisecond)
+	// dispatcher takes the task, waiting for ready worker
+	assert.NoError(t, p.Submit(func() {}))
+	time.Sleep(50 * time.Millisecond)
+	// task in queue
+	assert.NoError(t, p.Submit(func() {}))
+	// queue is full, blocks until pool stopped
+	submitDone := make(chan error)
+	go func() {
+		submitDone <- p.Submit(func() {})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	wp.cancel()
+	assert.Equal(t, ErrPoolStopped, <-submitDone)
+	close(block)
+	p.Stop()
+}
+
+func Test_Pool_MaxWorkers_Concurrent(t *testing.T) {
+	p := NewPool("test", 2, time.Second*5, linmetric.NewScope("9"))
+	wp := p.(*workerPool)
+	var (
+		wg      sync.WaitGroup
+		running atomic.Int32
+		max     atomic.Int32
+		done    atomic.Int32
+	)
+	task := func() {
+		n := running.Inc()
+		for {
+			m := max.Load()
+			if n <= m || max.CAS(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Dec()
+		done.Inc()
+	}
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			p.SubmitAndWait(task)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = p.Submit(task)
+		}()
+	}
+	wg.Wait()
+	// wait all submitted tasks executed by workers
+	for done.Load() < 40 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, wp.aliveWorkers.Load(), int32(2))
+	assert.LessOrEqual(t, max.Load(), int32(2))
+	p.Stop()
+}
+
+func Test_Pool_SubmitAndWait_Busy(t *testing.T) {
+	p := NewPool("test", 1, time.Second*5, linmetric.NewScope("5"))
+	block := make(chan struct{})
+	p.Submit(func() { <-block })
+	time.Sleep(50 * time.Millisecond)
+
+	var c atomic.Int32
+	waitDone := make(chan struct{})
+	go func() {
+		// waits worker becomes ready
+		p.SubmitAndWait(func() { c.Inc() })
+		close(waitDone)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), c.Load())
+	close(block)
+	<-waitDone
+	assert.Equal(t, int32(1), c.Load())
+	p.Stop()
+}
+
+func Test_Pool_SubmitWithContext(t *testing.T) {
+	p := NewPoolWithOptions("test", 1, time.Second*5, PoolOptions{QueueCapacity: 1}, linmetric.NewScope("6"))
+	wp := p.(*workerPool)
+	block := make(chan struct{})
+	var c atomic.Int32
+	p.Submit(func() { <-block })
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// dispatcher takes the task, waiting for ready worker
+	assert.NoError(t, p.SubmitWithContext(ctx, func() { c.Inc() }))
+	time.Sleep(50 * time.Millisecond)
+	// task in queue
+	assert.NoError(t, p.SubmitWithContext(WithPriority(ctx, PriorityHigh), func() { c.Inc() }))
+	// queue is full, waits until ctx done
+	submitDone := make(chan error)
+	go func() {
+		submitDone <- p.SubmitWithContext(WithPriority(ctx, PriorityHigh), func() { c.Inc() })
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-submitDone)
+	// submit with done ctx
+	assert.Equal(t, context.Canceled, p.SubmitWithContext(ctx, func() { c.Inc() }))
+
+	close(block)
+	p.Stop()
+	// abandoned tasks are dropped without executing
+	assert.Equal(t, int32(0), c.Load())
+	assert.Equal(t, float64(0), wp.tasksPending[PriorityHigh].Get())
+	assert.Equal(t, float64(0), wp.tasksPending[PriorityNormal].Get())
+	assert.Equal(t, ErrPoolStopped, p.SubmitWithContext(context.Background(), func() {}))
+}
+
+func Test_Pool_TrySubmit(t *testing.T) {
+	p := NewPoolWithOptions("test", 1, time.Second*5, PoolOptions{QueueCapacity: 1}, linmetric.NewScope("7"))
+	block := make(chan struct{})
+	var c atomic.Int32
+	p.Submit(func() { <-block })
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, p.TrySubmit(func() { c.Inc() }))
+	// wait dispatcher takes the task, waiting for ready worker
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, p.TrySubmit(func() { c.Inc() }))
+	assert.False(t, p.TrySubmit(func() { c.Inc() }))
+	assert.False(t, p.TrySubmit(nil))
+	close(block)
+	p.Stop()
+	assert.Equal(t, int32(2), c.Load())
+	assert.False(t, p.TrySubmit(func() { c.Inc() }))
+}
+
+func Test_Pool_SetMaxWorkers(t *testing.T) {
+	p := NewPool("test", 4, time.Second*5, linmetric.NewScope("8"))
+	defer p.Stop()
+	wp := p.(*workerPool)
+
+	block := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		p.Submit(func() {
+			defer wg.Done()
+			<-block
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(4), wp.aliveWorkers.Load())
+
+	// shrink, excess workers are stopped when becoming ready
+	p.SetMaxWorkers(0)
+	assert.Equal(t, int32(1), wp.maxWorkers.Load())
+	p.SetMaxWorkers(2)
+	close(block)
+	wg.Wait()
+	for i := 0; i < 10; i++ {
+		p.SubmitAndWait(func() {})
+	}
+	assert.True(t, wp.aliveWorkers.Load() <= 2)
+
+	// grow
+	p.SetMaxWorkers(3)
+	block = make(chan struct{})
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		p.Submit(func() {
+			defer wg.Done()
+			<-block
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(3), wp.aliveWorkers.Load())
+	close(block)
+	wg.Wait()
+}
@@ -0,0 +1,189 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbstats
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/internal/linmetric"
+)
+
+var (
+	databaseScope         = linmetric.NewScope("lindb.broker.database")
+	writtenPointsVec      = databaseScope.NewDeltaCounterVec("written_points", "db")
+	writtenBytesVec       = databaseScope.NewDeltaCounterVec("written_bytes", "db")
+	writeFailuresVec      = databaseScope.NewDeltaCounterVec("write_failures", "db")
+	queriesVec            = databaseScope.NewDeltaCounterVec("queries", "db")
+	queryFailuresVec      = databaseScope.NewDeltaCounterVec("query_failures", "db")
+	queryDurationTimerVec = databaseScope.Scope("query_duration").NewDeltaHistogramVec("db").
+				WithExponentBuckets(time.Millisecond, time.Minute, 20)
+)
+
+// latencyBounds are the upper bounds of query latency buckets exposed by api.
+var latencyBounds = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	time.Minute,
+}
+
+// registry keeps the statistics of all databases, database name => *databaseStats.
+var registry sync.Map
+
+// LatencyBucket represents the number of queries whose latency is less than or equal to upper bound.
+type LatencyBucket struct {
+	UpperBound string `json:"upperBound"` // 'inf' for the last bucket
+	Count      int64  `json:"count"`
+}
+
+// Stats represents the write and query statistics of database since broker started.
+type Stats struct {
+	Database         string          `json:"database"`
+	WrittenPoints    int64           `json:"writtenPoints"`
+	WrittenBytes     int64           `json:"writtenBytes"`
+	WriteFailures    int64           `json:"writeFailures"`
+	Queries          int64           `json:"queries"`
+	QueryFailures    int64           `json:"queryFailures"`
+	AvgQueryLatency  float64         `json:"avgQueryLatency"` // ms
+	MaxQueryLatency  float64         `json:"maxQueryLatency"` // ms
+	QueryLatencyHist []LatencyBucket `json:"queryLatencyHistogram"`
+}
+
+// databaseStats records the statistics of one database, reports them to linmetric also.
+type databaseStats struct {
+	writtenPoints  atomic.Int64
+	writtenBytes   atomic.Int64
+	writeFailures  atomic.Int64
+	queries        atomic.Int64
+	queryFailures  atomic.Int64
+	queryLatency   atomic.Int64 // ns
+	maxLatency     atomic.Int64 // ns
+	latencyBuckets []atomic.Int64
+
+	writtenPointsCounter *linmetric.BoundDeltaCounter
+	writtenBytesCounter  *linmetric.BoundDeltaCounter
+	writeFailuresCounter *linmetric.BoundDeltaCounter
+	queriesCounter       *linmetric.BoundDeltaCounter
+	queryFailuresCounter *linmetric.BoundDeltaCounter
+	queryDurationTimer   *linmetric.BoundDeltaHistogram
+}
+
+// getOrCreate returns the statistics of database, creates it if not exist.
+func getOrCreate(database string) *databaseStats {
+	if stats, ok := registry.Load(database); ok {
+		return stats.(*databaseStats)
+	}
+	stats, _ := registry.LoadOrStore(database, &databaseStats{
+		latencyBuckets:       make([]atomic.Int64, len(latencyBounds)+1),
+		writtenPointsCounter: writtenPointsVec.WithTagValues(database),
+		writtenBytesCounter:  writtenBytesVec.WithTagValues(database),
+		writeFailuresCounter: writeFailuresVec.WithTagValues(database),
+		queriesCounter:       queriesVec.WithTagValues(database),
+		queryFailuresCounter: queryFailuresVec.WithTagValues(database),
+		queryDurationTimer:   queryDurationTimerVec.WithTagValues(database),
+	})
+	return stats.(*databaseStats)
+}
+
+// RecordWrite records the number of points/bytes written into database, and the number of failed points.
+func RecordWrite(database string, points, bytes, failures int) {
+	stats := getOrCreate(database)
+	if points > 0 {
+		stats.writtenPoints.Add(int64(points))
+		stats.writtenPointsCounter.Add(float64(points))
+	}
+	if bytes > 0 {
+		stats.writtenBytes.Add(int64(bytes))
+		stats.writtenBytesCounter.Add(float64(bytes))
+	}
+	if failures > 0 {
+		stats.writeFailures.Add(int64(failures))
+		stats.writeFailuresCounter.Add(float64(failures))
+	}
+}
+
+// RecordQuery records the query of database with its latency, failed if err is not nil.
+func RecordQuery(database string, latency time.Duration, err error) {
+	stats := getOrCreate(database)
+	stats.queries.Inc()
+	stats.queriesCounter.Incr()
+	if err != nil {
+		stats.queryFailures.Inc()
+		stats.queryFailuresCounter.Incr()
+	}
+	stats.queryDurationTimer.UpdateDuration(latency)
+	stats.queryLatency.Add(int64(latency))
+	for {
+		max := stats.maxLatency.Load()
+		if int64(latency) <= max || stats.maxLatency.CAS(max, int64(latency)) {
+			break
+		}
+	}
+	idx := len(latencyBounds)
+	for i, bound := range latencyBounds {
+		if latency <= bound {
+			idx = i
+			break
+		}
+	}
+	stats.latencyBuckets[idx].Inc()
+}
+
+// Get returns the statistics of database, returns false if nothing recorded.
+func Get(database string) (*Stats, bool) {
+	value, ok := registry.Load(database)
+	if !ok {
+		return nil, false
+	}
+	stats := value.(*databaseStats)
+	result := &Stats{
+		Database:        database,
+		WrittenPoints:   stats.writtenPoints.Load(),
+		WrittenBytes:    stats.writtenBytes.Load(),
+		WriteFailures:   stats.writeFailures.Load(),
+		Queries:         stats.queries.Load(),
+		QueryFailures:   stats.queryFailures.Load(),
+		MaxQueryLatency: toMilliseconds(stats.maxLatency.Load()),
+	}
+	if result.Queries > 0 {
+		result.AvgQueryLatency = toMilliseconds(stats.queryLatency.Load() / result.Queries)
+	}
+	for idx := range stats.latencyBuckets {
+		upperBound := "inf"
+		if idx < len(latencyBounds) {
+			upperBound = latencyBounds[idx].String()
+		}
+		result.QueryLatencyHist = append(result.QueryLatencyHist, LatencyBucket{
+			UpperBound: upperBound,
+			Count:      stats.latencyBuckets[idx].Load(),
+		})
+	}
+	return result, true
+}
+
+// toMilliseconds converts nanoseconds to milliseconds.
+func toMilliseconds(ns int64) float64 {
+	return float64(ns) / float64(time.Millisecond)
+}
@@ -0,0 +1,52 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbstats
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	_, ok := Get("db")
+	assert.False(t, ok)
+
+	RecordWrite("db", 10, 100, 0)
+	RecordWrite("db", 0, 0, 2)
+	RecordQuery("db", 20*time.Millisecond, nil)
+	RecordQuery("db", 2*time.Minute, fmt.Errorf("err"))
+	// other database
+	RecordWrite("other-db", 1, 1, 1)
+
+	stats, ok := Get("db")
+	assert.True(t, ok)
+	assert.Equal(t, "db", stats.Database)
+	assert.Equal(t, int64(10), stats.WrittenPoints)
+	assert.Equal(t, int64(100), stats.WrittenBytes)
+	assert.Equal(t, int64(2), stats.WriteFailures)
+	assert.Equal(t, int64(2), stats.Queries)
+	assert.Equal(t, int64(1), stats.QueryFailures)
+	assert.Equal(t, float64(2*time.Minute/time.Millisecond), stats.MaxQueryLatency)
+	assert.Equal(t, float64(60010), stats.AvgQueryLatency)
+	assert.Len(t, stats.QueryLatencyHist, len(latencyBounds)+1)
+	assert.Equal(t, LatencyBucket{UpperBound: "50ms", Count: 1}, stats.QueryLatencyHist[1])
+	assert.Equal(t, LatencyBucket{UpperBound: "inf", Count: 1}, stats.QueryLatencyHist[len(latencyBounds)])
+}
@@ -24,6 +24,7 @@ import "go.uber.org/atomic"
 // Get will resets the underlying delta value
 type BoundDeltaCounter struct {
 	delta     atomic.Float64
+	total     atomic.Float64 // cumulative value for prometheus exposition, never resets
 	fieldName string
 }
 
@@ -36,21 +37,25 @@ func newDeltaCounter(fieldName string) *BoundDeltaCounter {
 // Incr increments c.
 func (c *BoundDeltaCounter) Incr() {
 	c.delta.Add(1)
+	c.total.Add(1)
 }
 
 // Decr decrements g.
 func (c *BoundDeltaCounter) Decr() {
 	c.delta.Sub(1)
+	c.total.Sub(1)
 }
 
 // Add adds v to c.
 func (c *BoundDeltaCounter) Add(v float64) {
 	c.delta.Add(v)
+	c.total.Add(v)
 }
 
 // Sub subs v to c.
 func (c *BoundDeltaCounter) Sub(v float64) {
 	c.delta.Sub(v)
+	c.total.Sub(v)
 }
 
 // Get returns the current delta counter value
@@ -58,6 +63,11 @@ func (c *BoundDeltaCounter) Get() float64 {
 	return c.delta.Load()
 }
 
+// getTotal returns the cumulative counter value since created, it won't be reset by gathering.
+func (c *BoundDeltaCounter) getTotal() float64 {
+	return c.total.Load()
+}
+
 // getAndReset returns the current cumulative counter value
 // and resets the delta value by spin lock.
 func (c *BoundDeltaCounter) getAndReset() float64 {
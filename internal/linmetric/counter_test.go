@@ -49,6 +49,8 @@ func Test_Counter(t *testing.T) {
 	assert.Equal(t, float64(100), c1.getAndReset())
 	// reset
 	assert.Equal(t, float64(0), c1.Get())
+	// total is not reset
+	assert.Equal(t, float64(100), c1.getTotal())
 
 	assert.Equal(t, float64(100), c2.Get())
 	assert.Equal(t, float64(100), c2.Get())
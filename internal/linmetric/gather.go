@@ -82,7 +82,7 @@ func (g *gather) Gather() []*protoMetricsV1.Metric {
 
 type readRuntimeOption struct{}
 
-func (o *readRuntimeOption) ApplyConfig(g *gather) { g.runtimeObserver = newRuntimeObserver() }
+func (o *readRuntimeOption) ApplyConfig(g *gather) { g.runtimeObserver = getRuntimeObserver() }
 
 func WithReadRuntimeOption() GatherOption { return &readRuntimeOption{} }
 
@@ -58,3 +58,24 @@ func Test_Gather_appendKeyValuesToFront(t *testing.T) {
 		{Key: "c", Value: "2"},
 	}, m.Tags)
 }
+
+func Test_Gather_MaxMinGauge_UpDownCounter(t *testing.T) {
+	series := newTaggedSeries("gather_extremum_test", nil)
+	series.NewMaxGauge("max").Update(10)
+	series.NewMinGauge("min").Update(3)
+	series.NewUpDownCounter("in_flight").Add(2)
+
+	m := series.gatherMetric()
+	assert.Equal(t, []*protoMetricsV1.SimpleField{
+		{Name: "max", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 10},
+		{Name: "min", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 3},
+		{Name: "in_flight", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 2},
+	}, m.SimpleFields)
+	// max/min gauges are reset after gathering, up-down counter keeps value
+	m = series.gatherMetric()
+	assert.Equal(t, []*protoMetricsV1.SimpleField{
+		{Name: "max", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 0},
+		{Name: "min", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 0},
+		{Name: "in_flight", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 2},
+	}, m.SimpleFields)
+}
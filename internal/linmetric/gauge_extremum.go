@@ -0,0 +1,108 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"math"
+
+	"go.uber.org/atomic"
+)
+
+// BoundMaxGauge is a gauge which has Bound to a certain metric with field-name and tags,
+// it records the max updated value, and resets after gathering.
+type BoundMaxGauge struct {
+	fieldName string
+	value     atomic.Float64
+}
+
+func newMaxGauge(fieldName string) *BoundMaxGauge {
+	g := &BoundMaxGauge{fieldName: fieldName}
+	g.value.Store(math.Inf(-1))
+	return g
+}
+
+// Update updates max gauge if v is greater than current max value
+func (g *BoundMaxGauge) Update(v float64) {
+	for {
+		old := g.value.Load()
+		if v <= old || g.value.CAS(old, v) {
+			return
+		}
+	}
+}
+
+// Get returns the max value since last gathering, returns 0 if not updated
+func (g *BoundMaxGauge) Get() float64 {
+	return extremumValue(g.value.Load())
+}
+
+// getAndReset returns the max value since last gathering, then resets it by spin lock.
+func (g *BoundMaxGauge) getAndReset() float64 {
+	for {
+		v := g.value.Load()
+		if g.value.CAS(v, math.Inf(-1)) {
+			return extremumValue(v)
+		}
+	}
+}
+
+// BoundMinGauge is a gauge which has Bound to a certain metric with field-name and tags,
+// it records the min updated value, and resets after gathering.
+type BoundMinGauge struct {
+	fieldName string
+	value     atomic.Float64
+}
+
+func newMinGauge(fieldName string) *BoundMinGauge {
+	g := &BoundMinGauge{fieldName: fieldName}
+	g.value.Store(math.Inf(1))
+	return g
+}
+
+// Update updates min gauge if v is less than current min value
+func (g *BoundMinGauge) Update(v float64) {
+	for {
+		old := g.value.Load()
+		if v >= old || g.value.CAS(old, v) {
+			return
+		}
+	}
+}
+
+// Get returns the min value since last gathering, returns 0 if not updated
+func (g *BoundMinGauge) Get() float64 {
+	return extremumValue(g.value.Load())
+}
+
+// getAndReset returns the min value since last gathering, then resets it by spin lock.
+func (g *BoundMinGauge) getAndReset() float64 {
+	for {
+		v := g.value.Load()
+		if g.value.CAS(v, math.Inf(1)) {
+			return extremumValue(v)
+		}
+	}
+}
+
+// extremumValue returns 0 if value is the initial infinity(not updated).
+func extremumValue(v float64) float64 {
+	if math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
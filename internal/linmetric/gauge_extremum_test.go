@@ -0,0 +1,172 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func Test_MaxMinGauge(t *testing.T) {
+	maxGauge := newMaxGauge("max")
+	minGauge := newMinGauge("min")
+	// not updated
+	assert.Equal(t, float64(0), maxGauge.Get())
+	assert.Equal(t, float64(0), minGauge.Get())
+
+	var wg sync.WaitGroup
+	for i := range [10]struct{}{} {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				maxGauge.Update(float64(i*100 + j))
+				minGauge.Update(float64(i*100 + j - 10))
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, float64(999), maxGauge.Get())
+	assert.Equal(t, float64(999), maxGauge.getAndReset())
+	assert.Equal(t, float64(-10), minGauge.Get())
+	assert.Equal(t, float64(-10), minGauge.getAndReset())
+	// reset after gathering
+	assert.Equal(t, float64(0), maxGauge.getAndReset())
+	assert.Equal(t, float64(0), minGauge.getAndReset())
+	maxGauge.Update(-5)
+	minGauge.Update(5)
+	assert.Equal(t, float64(-5), maxGauge.getAndReset())
+	assert.Equal(t, float64(5), minGauge.getAndReset())
+}
+
+func Test_MaxMinGauge_Update(t *testing.T) {
+	examples := []struct {
+		values []float64
+		max    float64
+		min    float64
+	}{
+		{nil, 0, 0},
+		{[]float64{3}, 3, 3},
+		{[]float64{3, 10, 1, 7}, 10, 1},
+		{[]float64{-3, -10, -1}, -1, -10},
+		{[]float64{2, 2, 2}, 2, 2},
+		{[]float64{0}, 0, 0},
+		// infinity is reported as 0
+		{[]float64{math.Inf(-1), 1, math.Inf(1)}, 0, 0},
+	}
+	for _, example := range examples {
+		maxGauge := newMaxGauge("max")
+		minGauge := newMinGauge("min")
+		for _, v := range example.values {
+			maxGauge.Update(v)
+			minGauge.Update(v)
+		}
+		assert.Equal(t, example.max, maxGauge.Get())
+		assert.Equal(t, example.min, minGauge.Get())
+		// get doesn't reset
+		assert.Equal(t, example.max, maxGauge.Get())
+		assert.Equal(t, example.min, minGauge.Get())
+		assert.Equal(t, example.max, maxGauge.getAndReset())
+		assert.Equal(t, example.min, minGauge.getAndReset())
+		assert.Equal(t, float64(0), maxGauge.Get())
+		assert.Equal(t, float64(0), minGauge.Get())
+	}
+}
+
+func Test_MaxMinGauge_ConcurrentGather(t *testing.T) {
+	maxGauge := newMaxGauge("max")
+	minGauge := newMinGauge("min")
+	var (
+		gathered  []float64
+		gathering sync.WaitGroup
+		updating  sync.WaitGroup
+		done      = make(chan struct{})
+	)
+	// gathers while updating, the extremum value must be reported by one of gatherings
+	gathering.Add(1)
+	go func() {
+		defer gathering.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				gathered = append(gathered, maxGauge.getAndReset(), minGauge.getAndReset())
+			}
+		}
+	}()
+	for i := range [10]struct{}{} {
+		updating.Add(1)
+		go func(i int) {
+			defer updating.Done()
+			for j := 1; j <= 1000; j++ {
+				maxGauge.Update(float64(i*1000 + j))
+				minGauge.Update(-float64(i*1000 + j))
+			}
+		}(i)
+	}
+	updating.Wait()
+	close(done)
+	gathering.Wait()
+	gathered = append(gathered, maxGauge.getAndReset(), minGauge.getAndReset())
+
+	max, min := math.Inf(-1), math.Inf(1)
+	for _, v := range gathered {
+		max = math.Max(max, v)
+		min = math.Min(min, v)
+	}
+	assert.Equal(t, float64(10000), max)
+	assert.Equal(t, float64(-10000), min)
+	// all reset
+	assert.Equal(t, float64(0), maxGauge.getAndReset())
+	assert.Equal(t, float64(0), minGauge.getAndReset())
+}
+
+func Test_MaxMinGauge_GatherMetric(t *testing.T) {
+	series := newTaggedSeries("gauge_extremum_test", nil)
+	maxGauge := series.NewMaxGauge("max")
+	minGauge := series.NewMinGauge("min")
+	// same field returns same gauge
+	assert.Same(t, maxGauge, series.NewMaxGauge("max"))
+	assert.Same(t, minGauge, series.NewMinGauge("min"))
+
+	gatherValues := func() []float64 {
+		var values []float64
+		for _, f := range series.gatherMetric().SimpleFields {
+			assert.Equal(t, protoMetricsV1.SimpleFieldType_GAUGE, f.Type)
+			values = append(values, f.Value)
+		}
+		return values
+	}
+	for _, v := range []float64{5, 1, 9} {
+		maxGauge.Update(v)
+		minGauge.Update(v)
+	}
+	assert.Equal(t, []float64{9, 1}, gatherValues())
+	// reset after gathering
+	assert.Equal(t, []float64{0, 0}, gatherValues())
+	// only values updated after last gathering are reported
+	maxGauge.Update(2)
+	minGauge.Update(4)
+	assert.Equal(t, []float64{2, 4}, gatherValues())
+}
@@ -150,6 +150,24 @@ func (bkt *histogramBuckets) Update(v float64) {
 	}
 }
 
+// histogramSnapshot is the cumulative state of histogram buckets since created.
+type histogramSnapshot struct {
+	upperBounds []float64
+	values      []float64 // count in different sections
+	sum         float64
+	count       float64
+}
+
+// snapshot returns the cumulative state of buckets without resetting anything.
+func (bkt *histogramBuckets) snapshot() histogramSnapshot {
+	return histogramSnapshot{
+		upperBounds: cloneFloat64Slice(bkt.upperBounds),
+		values:      cloneFloat64Slice(bkt.values),
+		sum:         bkt.totalSum,
+		count:       bkt.totalCount,
+	}
+}
+
 func cloneFloat64Slice(src []float64) []float64 {
 	var dst = make([]float64, len(src))
 	copy(dst, src)
@@ -88,6 +88,14 @@ func (h *BoundCumulativeHistogram) Update(f func()) {
 	h.UpdateSince(start)
 }
 
+// snapshot returns the cumulative state of histogram for prometheus exposition.
+func (h *BoundCumulativeHistogram) snapshot() histogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.bkts.snapshot()
+}
+
 func (h *BoundCumulativeHistogram) marshalToCompoundField() *protoMetricsV1.CompoundField {
 	h.mu.Lock()
 	defer h.mu.Unlock()
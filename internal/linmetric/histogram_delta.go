@@ -74,6 +74,24 @@ func (h *BoundDeltaHistogram) WithLinearBuckets(lower, upper time.Duration, coun
 	return h
 }
 
+// WithExponentValueBuckets sets the exponent buckets for the values which aren't duration, such as bytes.
+func (h *BoundDeltaHistogram) WithExponentValueBuckets(lower, upper float64, count int) *BoundDeltaHistogram {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.bkts.reset(lower, upper, count, exponentBucket)
+	h.afterResetBuckets()
+	return h
+}
+
+// UpdateValue updates the value which isn't duration, such as bytes.
+func (h *BoundDeltaHistogram) UpdateValue(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.bkts.Update(v)
+}
+
 func (h *BoundDeltaHistogram) UpdateDuration(d time.Duration) {
 	h.UpdateMilliseconds(float64(d.Nanoseconds() / 1e6))
 }
@@ -99,6 +117,14 @@ func (h *BoundDeltaHistogram) Update(f func()) {
 	h.UpdateSince(start)
 }
 
+// snapshot returns the cumulative state of histogram for prometheus exposition.
+func (h *BoundDeltaHistogram) snapshot() histogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.bkts.snapshot()
+}
+
 func (h *BoundDeltaHistogram) marshalToCompoundField() *protoMetricsV1.CompoundField {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -63,6 +63,17 @@ func (hv *DeltaHistogramVec) WithLinearBuckets(lower, upper time.Duration, count
 	return hv
 }
 
+// WithExponentValueBuckets sets the exponent buckets for the values which aren't duration, such as bytes.
+func (hv *DeltaHistogramVec) WithExponentValueBuckets(lower, upper float64, count int) *DeltaHistogramVec {
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+
+	hv.setBucketsFunc = func(h *BoundDeltaHistogram) {
+		h.WithExponentValueBuckets(lower, upper, count)
+	}
+	return hv
+}
+
 func (hv *DeltaHistogramVec) WithTagValues(tagValues ...string) *BoundDeltaHistogram {
 	if len(tagValues) != len(hv.tagKeys) {
 		panic("count of tagKey and tagValue not match")
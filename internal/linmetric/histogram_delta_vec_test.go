@@ -38,6 +38,14 @@ func Test_HistogramDeltaVec(t *testing.T) {
 	vec.WithTagValues("a", "b").UpdateSeconds(1)
 	vec.WithTagValues("a", "c").UpdateSeconds(1)
 	vec.WithTagValues("a", "b").UpdateSeconds(1)
+
+	// buckets of bytes
+	bytesVec := scope.NewDeltaHistogramVec("3").WithExponentValueBuckets(1024, 1024*1024, 10)
+	h := bytesVec.WithTagValues("a")
+	h.UpdateValue(2048)
+	assert.Equal(t, float64(1024), h.bkts.upperBounds[0])
+	assert.Equal(t, float64(1), h.bkts.totalCount)
+	assert.Equal(t, float64(2048), h.bkts.totalSum)
 }
 
 func Benchmark_HistogramVec(b *testing.B) {
@@ -0,0 +1,242 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/tag"
+)
+
+// PrometheusContentType is the content type of prometheus text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	prometheusCounter   = "counter"
+	prometheusGauge     = "gauge"
+	prometheusHistogram = "histogram"
+	prometheusSummary   = "summary"
+)
+
+// prometheusFamily represents all samples of one prometheus metric name.
+type prometheusFamily struct {
+	metricType string
+	samples    []string
+}
+
+// NewPrometheusHandler returns a http handler which exposes all registered metrics with prometheus text format.
+func NewPrometheusHandler(globalKeyValues tag.KeyValues) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		var buf bytes.Buffer
+		if err := WritePrometheus(&buf, globalKeyValues); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", PrometheusContentType)
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// WritePrometheus writes all registered metrics into w with prometheus text exposition format,
+// global key values are added as labels of each sample.
+// Delta counters and histograms are exposed with cumulative values without resetting them,
+// so that scraping won't steal data from native pusher.
+func WritePrometheus(w io.Writer, globalKeyValues tag.KeyValues) error {
+	getRuntimeObserver().Observe()
+
+	defaultRegistry.mu.RLock()
+	seriesList := make([]*taggedSeries, 0, len(defaultRegistry.series))
+	for _, s := range defaultRegistry.series {
+		seriesList = append(seriesList, s)
+	}
+	defaultRegistry.mu.RUnlock()
+	// samples of histogram must be in order, so sorts series instead of samples
+	sort.Slice(seriesList, func(i, j int) bool {
+		if seriesList[i].metricName != seriesList[j].metricName {
+			return seriesList[i].metricName < seriesList[j].metricName
+		}
+		return tag.ConcatKeyValues(seriesList[i].tags) < tag.ConcatKeyValues(seriesList[j].tags)
+	})
+
+	families := make(map[string]*prometheusFamily)
+	for _, s := range seriesList {
+		s.collectPrometheus(globalKeyValues, families)
+	}
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		family := families[name]
+		buf.WriteString("# TYPE ")
+		buf.WriteString(name)
+		buf.WriteByte(' ')
+		buf.WriteString(family.metricType)
+		buf.WriteByte('\n')
+		for _, sample := range family.samples {
+			buf.WriteString(sample)
+			buf.WriteByte('\n')
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// collectPrometheus collects the current values of series as prometheus samples into families.
+func (s *taggedSeries) collectPrometheus(globalKeyValues tag.KeyValues, families map[string]*prometheusFamily) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.payload == nil {
+		return
+	}
+	tags := globalKeyValues.Merge(s.tags)
+	sort.Sort(tags)
+	labels := formatPrometheusLabels(tags)
+
+	for _, g := range s.payload.gauges {
+		addPrometheusSample(families, s.metricName, g.fieldName, prometheusGauge, "", labels, g.Get())
+	}
+	for _, mg := range s.payload.maxGauges {
+		addPrometheusSample(families, s.metricName, mg.fieldName, prometheusGauge, "", labels, mg.Get())
+	}
+	for _, mg := range s.payload.minGauges {
+		addPrometheusSample(families, s.metricName, mg.fieldName, prometheusGauge, "", labels, mg.Get())
+	}
+	for _, uc := range s.payload.countersUpDown {
+		addPrometheusSample(families, s.metricName, uc.fieldName, prometheusGauge, "", labels, uc.Get())
+	}
+	for _, cc := range s.payload.countersCumulative {
+		addPrometheusSample(families, s.metricName, cc.fieldName, prometheusCounter, "", labels, cc.Get())
+	}
+	for _, dc := range s.payload.countersDelta {
+		addPrometheusSample(families, s.metricName, dc.fieldName, prometheusCounter, "", labels, dc.getTotal())
+	}
+	if s.payload.summary != nil {
+		summary := s.payload.summary.snapshot()
+		for idx, q := range summary.quantiles {
+			quantileTags := append(tags.Clone(), &protoMetricsV1.KeyValue{Key: "quantile", Value: formatPrometheusValue(q)})
+			addPrometheusSample(families, s.metricName, "", prometheusSummary, "",
+				formatPrometheusLabels(quantileTags), summary.values[idx])
+		}
+		addPrometheusSample(families, s.metricName, "", prometheusSummary, "_sum", labels, summary.sum)
+		addPrometheusSample(families, s.metricName, "", prometheusSummary, "_count", labels, summary.count)
+	}
+	var snapshot *histogramSnapshot
+	if s.payload.histogramCumulative != nil {
+		v := s.payload.histogramCumulative.snapshot()
+		snapshot = &v
+	}
+	if s.payload.histogramDelta != nil {
+		v := s.payload.histogramDelta.snapshot()
+		snapshot = &v
+	}
+	if snapshot == nil {
+		return
+	}
+	// prometheus buckets are cumulative, bucket values of linmetric are counts in different sections
+	var cumulative float64
+	for idx, upperBound := range snapshot.upperBounds {
+		cumulative += snapshot.values[idx]
+		bucketTags := append(tags.Clone(), &protoMetricsV1.KeyValue{Key: "le", Value: formatPrometheusValue(upperBound)})
+		addPrometheusSample(families, s.metricName, "", prometheusHistogram, "_bucket",
+			formatPrometheusLabels(bucketTags), cumulative)
+	}
+	addPrometheusSample(families, s.metricName, "", prometheusHistogram, "_sum", labels, snapshot.sum)
+	addPrometheusSample(families, s.metricName, "", prometheusHistogram, "_count", labels, snapshot.count)
+}
+
+// addPrometheusSample adds a sample into the family of metric name.
+func addPrometheusSample(
+	families map[string]*prometheusFamily,
+	metricName, fieldName, metricType, suffix, labels string,
+	value float64,
+) {
+	name := metricName
+	if fieldName != "" {
+		name += "_" + fieldName
+	}
+	name = sanitizePrometheusName(name)
+	family, ok := families[name]
+	if !ok {
+		family = &prometheusFamily{metricType: metricType}
+		families[name] = family
+	}
+	family.samples = append(family.samples, name+suffix+labels+" "+formatPrometheusValue(value))
+}
+
+// formatPrometheusLabels formats tags as prometheus labels, like {k1="v1",k2="v2"}.
+func formatPrometheusLabels(tags tag.KeyValues) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for idx, kv := range tags {
+		if idx > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(sanitizePrometheusName(kv.Key))
+		sb.WriteString(`="`)
+		sb.WriteString(escapePrometheusLabelValue(kv.Value))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// sanitizePrometheusName replaces the characters which are not allowed in prometheus name with '_'.
+func sanitizePrometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_' || r == ':' || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+var prometheusLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapePrometheusLabelValue escapes backslash, double-quote and line feed of label value.
+func escapePrometheusLabelValue(value string) string {
+	return prometheusLabelValueReplacer.Replace(value)
+}
+
+// formatPrometheusValue formats float value, infinity is formatted as +Inf/-Inf.
+func formatPrometheusValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
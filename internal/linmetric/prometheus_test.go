@@ -0,0 +1,100 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/series/tag"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	scope := NewScope("lindb.prometheus-test", "k\"1", "v\n1")
+	scope.NewGauge("gauge").Update(1.5)
+	scope.NewCumulativeCounter("cumulative").Add(3)
+	deltaCounter := scope.NewDeltaCounter("delta")
+	deltaCounter.Add(10)
+	histogram := scope.Scope("latency").NewDeltaHistogram().
+		WithLinearBuckets(time.Millisecond, 3*time.Millisecond, 4)
+	histogram.UpdateMilliseconds(1)
+	histogram.UpdateMilliseconds(3)
+	histogram.UpdateMilliseconds(100)
+	summary := scope.Scope("duration").NewSummary().WithQuantiles(0.5)
+	summary.UpdateMilliseconds(10)
+	summary.UpdateMilliseconds(10)
+
+	// delta values are not reset by native gathering
+	_ = NewGather().Gather()
+	deltaCounter.Incr()
+
+	var buf bytes.Buffer
+	assert.NoError(t, WritePrometheus(&buf, tag.KeyValues{{Key: "node", Value: "1.1.1.1:9000"}}))
+	output := buf.String()
+	labels := `k_1="v\n1",node="1.1.1.1:9000"`
+	for _, line := range []string{
+		"# TYPE lindb_prometheus_test_gauge gauge",
+		fmt.Sprintf("lindb_prometheus_test_gauge{%s} 1.5", labels),
+		"# TYPE lindb_prometheus_test_cumulative counter",
+		fmt.Sprintf("lindb_prometheus_test_cumulative{%s} 3", labels),
+		"# TYPE lindb_prometheus_test_delta counter",
+		fmt.Sprintf("lindb_prometheus_test_delta{%s} 11", labels),
+		"# TYPE lindb_prometheus_test_latency histogram",
+		fmt.Sprintf(`lindb_prometheus_test_latency_bucket{%s,le="1"} 1`, labels),
+		fmt.Sprintf(`lindb_prometheus_test_latency_bucket{%s,le="3"} 2`, labels),
+		fmt.Sprintf(`lindb_prometheus_test_latency_bucket{%s,le="+Inf"} 3`, labels),
+		fmt.Sprintf("lindb_prometheus_test_latency_sum{%s} 104", labels),
+		fmt.Sprintf("lindb_prometheus_test_latency_count{%s} 3", labels),
+		"# TYPE lindb_prometheus_test_duration summary",
+		fmt.Sprintf("lindb_prometheus_test_duration_sum{%s} 20", labels),
+		fmt.Sprintf("lindb_prometheus_test_duration_count{%s} 2", labels),
+		"# TYPE lindb_runtime_go_goroutines gauge",
+	} {
+		assert.Contains(t, output, line+"\n")
+	}
+	assert.Contains(t, output, fmt.Sprintf(`lindb_prometheus_test_duration{%s,quantile="0.5"} 10.`, labels))
+	// buckets are in order of upper bound
+	assert.Less(t,
+		strings.Index(output, fmt.Sprintf(`lindb_prometheus_test_latency_bucket{%s,le="3"}`, labels)),
+		strings.Index(output, fmt.Sprintf(`lindb_prometheus_test_latency_bucket{%s,le="+Inf"}`, labels)))
+}
+
+func TestNewPrometheusHandler(t *testing.T) {
+	NewScope("lindb.prometheus-handler-test").NewGauge("gauge").Update(1)
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	resp := httptest.NewRecorder()
+	NewPrometheusHandler(nil).ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, PrometheusContentType, resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), "lindb_prometheus_handler_test_gauge 1\n")
+}
+
+func Test_formatPrometheusValue(t *testing.T) {
+	assert.Equal(t, "+Inf", formatPrometheusValue(math.Inf(1)))
+	assert.Equal(t, "-Inf", formatPrometheusValue(math.Inf(-1)))
+	assert.Equal(t, "NaN", formatPrometheusValue(math.NaN()))
+	assert.Equal(t, "0.25", formatPrometheusValue(0.25))
+}
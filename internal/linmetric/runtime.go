@@ -17,9 +17,28 @@
 
 package linmetric
 
-import "runtime"
+import (
+	"runtime"
+	"sync"
+)
+
+var (
+	observerOnce    sync.Once
+	defaultObserver *runtimeObserver
+)
+
+// getRuntimeObserver returns the shared runtime observer, so that runtime delta counters are not counted twice
+// when both native pusher and prometheus exposition observe runtime.
+func getRuntimeObserver() *runtimeObserver {
+	observerOnce.Do(func() {
+		defaultObserver = newRuntimeObserver()
+	})
+	return defaultObserver
+}
 
 type runtimeObserver struct {
+	mu sync.Mutex // lock for reading runtime stats
+
 	goRoutinesGauge        *BoundGauge
 	threadsGauge           *BoundGauge
 	allocBytesGauge        *BoundGauge
@@ -88,6 +107,9 @@ func newRuntimeObserver() *runtimeObserver {
 }
 
 func (rb *runtimeObserver) Observe() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
 	rb.goRoutinesGauge.Update(float64(runtime.NumGoroutine()))
 	n, _ := runtime.ThreadCreateProfile(nil)
 	rb.threadsGauge.Update(float64(n))
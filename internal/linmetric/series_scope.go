@@ -50,6 +50,18 @@ type Scope interface {
 	NewDeltaCounterVec(fieldName string, tagKey ...string) *DeltaCounterVec
 	// NewGaugeVec initializes a vec by tagKeys and fieldName
 	NewGaugeVec(fieldName string, tagKey ...string) *GaugeVec
+	// NewMaxGauge returns a gauge recording max value which bounded to the scope
+	NewMaxGauge(fieldName string) *BoundMaxGauge
+	// NewMinGauge returns a gauge recording min value which bounded to the scope
+	NewMinGauge(fieldName string) *BoundMinGauge
+	// NewUpDownCounter returns a counter supporting decrement which bounded to the scope
+	NewUpDownCounter(fieldName string) *BoundUpDownCounter
+	// NewUpDownCounterVec initializes a vec by tagKeys and fieldName
+	NewUpDownCounterVec(fieldName string, tagKey ...string) *UpDownCounterVec
+	// NewSummary returns a quantile summary which bounded to the scope
+	NewSummary() *BoundSummary
+	// NewSummaryVec initializes a vec by tagKeys
+	NewSummaryVec(tagKey ...string) *SummaryVec
 }
 
 type taggedSeries struct {
@@ -64,8 +76,12 @@ type fieldPayload struct {
 	gauges              []*BoundGauge             // BoundGauge list
 	countersCumulative  []*BoundCumulativeCounter // BoundCumulativeCounter list
 	countersDelta       []*BoundDeltaCounter      // BoundDeltaCounter list
+	maxGauges           []*BoundMaxGauge          // BoundMaxGauge list
+	minGauges           []*BoundMinGauge          // BoundMinGauge list
+	countersUpDown      []*BoundUpDownCounter     // BoundUpDownCounter list
 	histogramCumulative *BoundCumulativeHistogram
 	histogramDelta      *BoundDeltaHistogram
+	summary             *BoundSummary
 }
 
 func NewScope(metricName string, tagList ...string) Scope {
@@ -109,6 +125,21 @@ func (s *taggedSeries) containsFieldName(fieldName string) bool {
 			return true
 		}
 	}
+	for _, mg := range s.payload.maxGauges {
+		if mg.fieldName == fieldName {
+			return true
+		}
+	}
+	for _, mg := range s.payload.minGauges {
+		if mg.fieldName == fieldName {
+			return true
+		}
+	}
+	for _, uc := range s.payload.countersUpDown {
+		if uc.fieldName == fieldName {
+			return true
+		}
+	}
 	return false
 }
 
@@ -220,6 +251,63 @@ func (s *taggedSeries) NewDeltaCounter(fieldName string) *BoundDeltaCounter {
 	panic(fmt.Sprintf("delta-counter field: %s has registered another type before", fieldName))
 }
 
+func (s *taggedSeries) NewMaxGauge(fieldName string) *BoundMaxGauge {
+	assertFieldName(fieldName)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensurePayload()
+	if !s.containsFieldName(fieldName) {
+		mg := newMaxGauge(fieldName)
+		s.payload.maxGauges = append(s.payload.maxGauges, mg)
+		return mg
+	}
+	for _, mg := range s.payload.maxGauges {
+		if mg.fieldName == fieldName {
+			return mg
+		}
+	}
+	panic(fmt.Sprintf("max-gauge field: %s has registered another type before", fieldName))
+}
+
+func (s *taggedSeries) NewMinGauge(fieldName string) *BoundMinGauge {
+	assertFieldName(fieldName)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensurePayload()
+	if !s.containsFieldName(fieldName) {
+		mg := newMinGauge(fieldName)
+		s.payload.minGauges = append(s.payload.minGauges, mg)
+		return mg
+	}
+	for _, mg := range s.payload.minGauges {
+		if mg.fieldName == fieldName {
+			return mg
+		}
+	}
+	panic(fmt.Sprintf("min-gauge field: %s has registered another type before", fieldName))
+}
+
+func (s *taggedSeries) NewUpDownCounter(fieldName string) *BoundUpDownCounter {
+	assertFieldName(fieldName)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensurePayload()
+	if !s.containsFieldName(fieldName) {
+		uc := newUpDownCounter(fieldName)
+		s.payload.countersUpDown = append(s.payload.countersUpDown, uc)
+		return uc
+	}
+	for _, uc := range s.payload.countersUpDown {
+		if uc.fieldName == fieldName {
+			return uc
+		}
+	}
+	panic(fmt.Sprintf("up-down-counter field: %s has registered another type before", fieldName))
+}
+
 func (s *taggedSeries) NewDeltaHistogram() *BoundDeltaHistogram {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -231,6 +319,9 @@ func (s *taggedSeries) NewDeltaHistogram() *BoundDeltaHistogram {
 	if s.payload.histogramCumulative != nil {
 		panic("cumulative-histogram is already existed")
 	}
+	if s.payload.summary != nil {
+		panic("summary is already existed")
+	}
 	s.payload.histogramDelta = newDeltaHistogram()
 	return s.payload.histogramDelta
 }
@@ -252,6 +343,12 @@ func (s *taggedSeries) NewGaugeVec(fieldName string, tagKey ...string) *GaugeVec
 	return newGaugeVec(s.metricName, fieldName, s.tags, tagKey...)
 }
 
+func (s *taggedSeries) NewUpDownCounterVec(fieldName string, tagKey ...string) *UpDownCounterVec {
+	assertFieldName(fieldName)
+	assertTagKeyList(tagKey...)
+	return newUpDownCounterVec(s.metricName, fieldName, s.tags, tagKey...)
+}
+
 func (s *taggedSeries) NewCumulativeHistogram() *BoundCumulativeHistogram {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -263,10 +360,33 @@ func (s *taggedSeries) NewCumulativeHistogram() *BoundCumulativeHistogram {
 	if s.payload.histogramDelta != nil {
 		panic("delta-histogram is already existed")
 	}
+	if s.payload.summary != nil {
+		panic("summary is already existed")
+	}
 	s.payload.histogramCumulative = newCumulativeHistogram()
 	return s.payload.histogramCumulative
 }
 
+func (s *taggedSeries) NewSummary() *BoundSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensurePayload()
+	if s.payload.summary != nil {
+		return s.payload.summary
+	}
+	if s.payload.histogramDelta != nil || s.payload.histogramCumulative != nil {
+		panic("histogram is already existed")
+	}
+	s.payload.summary = newSummary()
+	return s.payload.summary
+}
+
+func (s *taggedSeries) NewSummaryVec(tagKey ...string) *SummaryVec {
+	assertTagKeyList(tagKey...)
+	return newSummaryVec(s.metricName, s.tags, tagKey...)
+}
+
 func (s *taggedSeries) gatherMetric() *protoMetricsV1.Metric {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -288,6 +408,29 @@ func (s *taggedSeries) gatherMetric() *protoMetricsV1.Metric {
 			Value: g.Get(),
 		})
 	}
+	// pick max/min gauges
+	for _, mg := range s.payload.maxGauges {
+		m.SimpleFields = append(m.SimpleFields, &protoMetricsV1.SimpleField{
+			Name:  mg.fieldName,
+			Type:  protoMetricsV1.SimpleFieldType_GAUGE,
+			Value: mg.getAndReset(),
+		})
+	}
+	for _, mg := range s.payload.minGauges {
+		m.SimpleFields = append(m.SimpleFields, &protoMetricsV1.SimpleField{
+			Name:  mg.fieldName,
+			Type:  protoMetricsV1.SimpleFieldType_GAUGE,
+			Value: mg.getAndReset(),
+		})
+	}
+	// pick up-down counters, current value is reported as gauge
+	for _, uc := range s.payload.countersUpDown {
+		m.SimpleFields = append(m.SimpleFields, &protoMetricsV1.SimpleField{
+			Name:  uc.fieldName,
+			Type:  protoMetricsV1.SimpleFieldType_GAUGE,
+			Value: uc.Get(),
+		})
+	}
 	// pick delta counter
 	for _, dc := range s.payload.countersDelta {
 		m.SimpleFields = append(m.SimpleFields, &protoMetricsV1.SimpleField{
@@ -310,5 +453,8 @@ func (s *taggedSeries) gatherMetric() *protoMetricsV1.Metric {
 	if s.payload.histogramDelta != nil {
 		m.CompoundField = s.payload.histogramDelta.marshalToCompoundField()
 	}
+	if s.payload.summary != nil {
+		m.SimpleFields = append(m.SimpleFields, s.payload.summary.marshalToSimpleFields()...)
+	}
 	return &m
 }
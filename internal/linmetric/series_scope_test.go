@@ -38,6 +38,12 @@ func Test_MetricScope(t *testing.T) {
 	scope1.NewCumulativeCounter("c1").Incr()
 	scope1.NewDeltaCounter("c2").Incr()
 	scope1.NewDeltaCounter("c2").Incr()
+	scope1.NewMaxGauge("max").Update(1)
+	scope1.NewMaxGauge("max").Update(2)
+	scope1.NewMinGauge("min").Update(1)
+	scope1.NewMinGauge("min").Update(2)
+	scope1.NewUpDownCounter("in_flight").Incr()
+	scope1.NewUpDownCounter("in_flight").Incr()
 	scope1.NewCumulativeHistogram().UpdateDuration(time.Second)
 	scope1.NewCumulativeHistogram().UpdateDuration(time.Second)
 
@@ -49,6 +55,18 @@ func Test_MetricScope(t *testing.T) {
 	assert.Panics(t, func() {
 		scope12.NewCumulativeHistogram().UpdateDuration(time.Second)
 	})
+	assert.Panics(t, func() {
+		scope12.NewSummary()
+	})
+	scope13 := scope1.Scope("3")
+	scope13.NewSummary().UpdateDuration(time.Second)
+	scope13.NewSummary().UpdateDuration(time.Second)
+	assert.Panics(t, func() {
+		scope13.NewDeltaHistogram()
+	})
+	assert.Panics(t, func() {
+		scope13.NewCumulativeHistogram()
+	})
 	time.Sleep(time.Second)
 	gather := linmetric.NewGather(linmetric.WithReadRuntimeOption())
 	_ = gather.Gather()
@@ -75,6 +93,21 @@ func Test_MetricScope_Scope(t *testing.T) {
 	assert.Panics(t, func() {
 		scope3.NewCumulativeCounter("d")
 	})
+	scope3.NewMaxGauge("max")
+	assert.Panics(t, func() {
+		scope3.NewMinGauge("max")
+	})
+	scope3.NewMinGauge("min")
+	assert.Panics(t, func() {
+		scope3.NewUpDownCounter("min")
+	})
+	scope3.NewUpDownCounter("in_flight")
+	assert.Panics(t, func() {
+		scope3.NewMaxGauge("in_flight")
+	})
+	assert.Panics(t, func() {
+		scope3.NewUpDownCounterVec("23")
+	})
 	scope3.NewCumulativeHistogram()
 	assert.Panics(t, func() {
 		scope3.NewDeltaHistogram()
@@ -88,6 +121,9 @@ func Test_MetricScope_Scope(t *testing.T) {
 	assert.Panics(t, func() {
 		scope3.NewGaugeVec("23")
 	})
+	assert.Panics(t, func() {
+		scope3.NewSummaryVec()
+	})
 	assert.Panics(t, func() {
 		scope3.NewGauge("")
 	})
@@ -0,0 +1,236 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+var (
+	defaultQuantiles         = []float64{0.5, 0.9, 0.99}
+	defaultSummaryWindow     = time.Minute
+	defaultSummaryAgeBuckets = 5
+)
+
+// BoundSummary is a summary which has been Bound to a certain metric
+// with metric-name and tags, used for non-negative values, like latency.
+//
+// quantiles are computed over a sliding window, the window is divided into age buckets,
+// each bucket is a sketch, the oldest bucket is dropped when window slides.
+// count and sum are reported as delta since last gathering.
+type BoundSummary struct {
+	mu         sync.Mutex
+	quantiles  []float64
+	window     time.Duration
+	sketches   []*ddSketch // ring of age buckets
+	head       int         // index of age bucket for updating
+	headExpire int64       // expire timestamp(ms) of head age bucket
+	totalCount float64
+	totalSum   float64
+	lastCount  float64
+	lastSum    float64
+	nowFunc    func() int64
+}
+
+func newSummary() *BoundSummary {
+	s := &BoundSummary{
+		quantiles: defaultQuantiles,
+		nowFunc:   timeutil.Now,
+	}
+	s.resetWindow(defaultSummaryWindow, defaultSummaryAgeBuckets)
+	return s
+}
+
+func (s *BoundSummary) resetWindow(window time.Duration, ageBuckets int) {
+	s.window = window
+	s.sketches = make([]*ddSketch, ageBuckets)
+	for idx := range s.sketches {
+		s.sketches[idx] = newDDSketch(defaultRelativeAccuracy)
+	}
+	s.head = 0
+	s.headExpire = s.nowFunc() + s.ageBucketDuration()
+}
+
+// ageBucketDuration returns the time range(ms) of each age bucket.
+func (s *BoundSummary) ageBucketDuration() int64 {
+	return s.window.Milliseconds() / int64(len(s.sketches))
+}
+
+// WithQuantiles sets the quantiles(0<q<1) reported by summary, like 0.5/0.9/0.99.
+func (s *BoundSummary) WithQuantiles(quantiles ...float64) *BoundSummary {
+	assertQuantiles(quantiles...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quantiles = append([]float64{}, quantiles...)
+	return s
+}
+
+// WithWindow sets the sliding window of quantiles, which is divided into age buckets.
+func (s *BoundSummary) WithWindow(window time.Duration, ageBuckets int) *BoundSummary {
+	assertSummaryWindow(window, ageBuckets)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resetWindow(window, ageBuckets)
+	return s
+}
+
+func (s *BoundSummary) UpdateDuration(d time.Duration) {
+	s.UpdateMilliseconds(float64(d.Nanoseconds() / 1e6))
+}
+
+func (s *BoundSummary) UpdateSince(start time.Time) {
+	s.UpdateMilliseconds(float64(time.Since(start).Nanoseconds() / 1e6))
+}
+
+func (s *BoundSummary) UpdateSeconds(v float64) {
+	s.UpdateMilliseconds(v * 1000)
+}
+
+func (s *BoundSummary) UpdateMilliseconds(v float64) {
+	if v < 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rotate()
+	s.sketches[s.head].add(v)
+	s.totalCount++
+	s.totalSum += v
+}
+
+func (s *BoundSummary) Update(f func()) {
+	start := time.Now()
+	f()
+	s.UpdateSince(start)
+}
+
+// rotate drops the expired age buckets if window slides.
+func (s *BoundSummary) rotate() {
+	now := s.nowFunc()
+	if now < s.headExpire {
+		return
+	}
+	step := s.ageBucketDuration()
+	if now-s.headExpire >= s.window.Milliseconds() {
+		// all age buckets expired
+		for _, sketch := range s.sketches {
+			sketch.reset()
+		}
+		s.headExpire = now + step
+		return
+	}
+	for now >= s.headExpire {
+		s.head = (s.head + 1) % len(s.sketches)
+		s.sketches[s.head].reset()
+		s.headExpire += step
+	}
+}
+
+// summarySnapshot is the state of summary, quantile values are computed over the sliding window,
+// count and sum are cumulative since created.
+type summarySnapshot struct {
+	quantiles []float64
+	values    []float64
+	count     float64
+	sum       float64
+}
+
+func (s *BoundSummary) snapshot() summarySnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rotate()
+	merged := newDDSketch(defaultRelativeAccuracy)
+	for _, sketch := range s.sketches {
+		merged.merge(sketch)
+	}
+	snapshot := summarySnapshot{
+		quantiles: cloneFloat64Slice(s.quantiles),
+		values:    make([]float64, len(s.quantiles)),
+		count:     s.totalCount,
+		sum:       s.totalSum,
+	}
+	for idx, q := range s.quantiles {
+		snapshot.values[idx] = merged.quantile(q)
+	}
+	return snapshot
+}
+
+// marshalToSimpleFields returns quantiles as gauge fields, count and sum as delta sum fields.
+func (s *BoundSummary) marshalToSimpleFields() []*protoMetricsV1.SimpleField {
+	snapshot := s.snapshot()
+
+	s.mu.Lock()
+	count := snapshot.count - s.lastCount
+	sum := snapshot.sum - s.lastSum
+	s.lastCount = snapshot.count
+	s.lastSum = snapshot.sum
+	s.mu.Unlock()
+
+	fields := make([]*protoMetricsV1.SimpleField, 0, len(snapshot.quantiles)+2)
+	for idx, q := range snapshot.quantiles {
+		fields = append(fields, &protoMetricsV1.SimpleField{
+			Name:  quantileFieldName(q),
+			Type:  protoMetricsV1.SimpleFieldType_GAUGE,
+			Value: snapshot.values[idx],
+		})
+	}
+	fields = append(fields,
+		&protoMetricsV1.SimpleField{Name: "count", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: count},
+		&protoMetricsV1.SimpleField{Name: "sum", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: sum},
+	)
+	return fields
+}
+
+// quantileFieldName returns the field name of quantile, e.g. 0.5 => p50, 0.99 => p99, 0.999 => p999.
+func quantileFieldName(q float64) string {
+	digits := strings.TrimPrefix(strconv.FormatFloat(q, 'f', -1, 64), "0.")
+	if len(digits) == 1 {
+		digits += "0"
+	}
+	return "p" + digits
+}
+
+func assertQuantiles(quantiles ...float64) {
+	if len(quantiles) == 0 {
+		panic("quantiles cannot be empty")
+	}
+	for _, q := range quantiles {
+		if q <= 0 || q >= 1 {
+			panic("valid quantile range is 0 < q < 1")
+		}
+	}
+}
+
+func assertSummaryWindow(window time.Duration, ageBuckets int) {
+	if ageBuckets <= 0 {
+		panic("age buckets must > 0")
+	}
+	if window.Milliseconds() < int64(ageBuckets) {
+		panic("window is too small for age buckets")
+	}
+}
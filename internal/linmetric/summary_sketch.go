@@ -0,0 +1,104 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"math"
+	"sort"
+)
+
+// defaultRelativeAccuracy is the relative accuracy of quantile value computed by sketch.
+const defaultRelativeAccuracy = 0.01
+
+// ddSketch is a quantile sketch with relative-error guarantees(DDSketch),
+// value is mapped into logarithmic bins, the quantile value computed has a relative error of relativeAccuracy.
+// not thread-safe
+type ddSketch struct {
+	gamma     float64
+	logGamma  float64
+	bins      map[int]float64 // bin index => count
+	zeroCount float64         // count of zero values
+	count     float64         // total count
+}
+
+func newDDSketch(relativeAccuracy float64) *ddSketch {
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &ddSketch{
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		bins:     make(map[int]float64),
+	}
+}
+
+// add adds a non-negative value into sketch.
+func (s *ddSketch) add(v float64) {
+	if math.IsNaN(v) || v < 0 || math.IsInf(v, 1) {
+		return
+	}
+	s.count++
+	if v == 0 {
+		s.zeroCount++
+		return
+	}
+	s.bins[int(math.Ceil(math.Log(v)/s.logGamma))]++
+}
+
+// merge merges other sketch which has the same relative accuracy.
+func (s *ddSketch) merge(other *ddSketch) {
+	for idx, count := range other.bins {
+		s.bins[idx] += count
+	}
+	s.zeroCount += other.zeroCount
+	s.count += other.count
+}
+
+// quantile returns the value of quantile q(0<=q<=1), returns 0 if sketch is empty.
+func (s *ddSketch) quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	rank := q * (s.count - 1)
+	if rank < s.zeroCount {
+		return 0
+	}
+	indexes := make([]int, 0, len(s.bins))
+	for idx := range s.bins {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	cumulative := s.zeroCount
+	for _, idx := range indexes {
+		cumulative += s.bins[idx]
+		if cumulative > rank {
+			return s.binValue(idx)
+		}
+	}
+	return s.binValue(indexes[len(indexes)-1])
+}
+
+// binValue returns the representative value of bin, which has the minimum relative error for values in bin.
+func (s *ddSketch) binValue(idx int) float64 {
+	return 2 * math.Pow(s.gamma, float64(idx)) / (s.gamma + 1)
+}
+
+// reset clears all values of sketch.
+func (s *ddSketch) reset() {
+	s.bins = make(map[int]float64)
+	s.zeroCount = 0
+	s.count = 0
+}
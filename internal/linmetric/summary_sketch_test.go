@@ -0,0 +1,166 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ddSketch(t *testing.T) {
+	s := newDDSketch(defaultRelativeAccuracy)
+	assert.Equal(t, float64(0), s.quantile(0.5))
+	s.add(-1)
+	s.add(math.NaN())
+	s.add(math.Inf(1))
+	assert.Equal(t, float64(0), s.count)
+	s.add(0)
+	for i := 1; i <= 1000; i++ {
+		s.add(float64(i))
+	}
+	assert.Equal(t, float64(0), s.quantile(0))
+	for _, q := range []float64{0.5, 0.9, 0.99, 1} {
+		expect := q * 1000
+		assert.InEpsilon(t, expect, s.quantile(q), defaultRelativeAccuracy+0.001)
+	}
+
+	other := newDDSketch(defaultRelativeAccuracy)
+	for i := 1001; i <= 2000; i++ {
+		other.add(float64(i))
+	}
+	s.merge(other)
+	assert.Equal(t, float64(2001), s.count)
+	assert.InEpsilon(t, 1000, s.quantile(0.5), defaultRelativeAccuracy+0.001)
+	s.reset()
+	assert.Equal(t, float64(0), s.quantile(0.5))
+}
+
+func Test_ddSketch_RelativeAccuracy(t *testing.T) {
+	for _, accuracy := range []float64{0.001, 0.01, 0.05} {
+		for _, value := range []float64{0.001, 1, 123.456, 1e12} {
+			s := newDDSketch(accuracy)
+			s.add(value)
+			for _, q := range []float64{0, 0.5, 1} {
+				// value on the bound of bin has the max relative error
+				assert.InEpsilon(t, value, s.quantile(q), accuracy+1e-9)
+			}
+		}
+	}
+}
+
+func Test_ddSketch_Reset(t *testing.T) {
+	s := newDDSketch(defaultRelativeAccuracy)
+	s.add(0)
+	s.add(100)
+	assert.InEpsilon(t, 100, s.quantile(1), defaultRelativeAccuracy)
+	s.reset()
+	assert.Equal(t, float64(0), s.count)
+	assert.Equal(t, float64(0), s.zeroCount)
+	assert.Empty(t, s.bins)
+	assert.Equal(t, float64(0), s.quantile(1))
+	// values added after reset
+	s.add(10)
+	assert.Equal(t, float64(1), s.count)
+	assert.InEpsilon(t, 10, s.quantile(0.5), defaultRelativeAccuracy)
+
+	// merging empty sketch
+	s.merge(newDDSketch(defaultRelativeAccuracy))
+	assert.Equal(t, float64(1), s.count)
+	assert.InEpsilon(t, 10, s.quantile(0.5), defaultRelativeAccuracy)
+}
+
+func Test_Summary_ResetOnGather(t *testing.T) {
+	now := int64(0)
+	s := newSummary()
+	s.nowFunc = func() int64 { return now }
+	s.WithWindow(time.Minute, 3).WithQuantiles(0.5)
+
+	for i := 1; i <= 100; i++ {
+		s.UpdateMilliseconds(float64(i))
+	}
+	fields := s.marshalToSimpleFields()
+	assert.InEpsilon(t, 50, fields[0].Value, 0.02)
+	assert.Equal(t, float64(100), fields[1].Value)
+	assert.Equal(t, float64(5050), fields[2].Value)
+	// count and sum are reset after gathering, quantiles are kept in window
+	fields = s.marshalToSimpleFields()
+	assert.InEpsilon(t, 50, fields[0].Value, 0.02)
+	assert.Equal(t, float64(0), fields[1].Value)
+	assert.Equal(t, float64(0), fields[2].Value)
+	// snapshot doesn't reset
+	s.UpdateMilliseconds(10)
+	assert.Equal(t, float64(101), s.snapshot().count)
+	assert.Equal(t, float64(101), s.snapshot().count)
+	fields = s.marshalToSimpleFields()
+	assert.Equal(t, float64(1), fields[1].Value)
+	assert.Equal(t, float64(10), fields[2].Value)
+	// quantiles are reset after window slides
+	now += time.Minute.Milliseconds()
+	fields = s.marshalToSimpleFields()
+	assert.Equal(t, float64(0), fields[0].Value)
+	assert.Equal(t, float64(0), fields[1].Value)
+}
+
+func Test_Summary_ConcurrentGather(t *testing.T) {
+	s := newSummary()
+	var (
+		count, sum float64
+		gathering  sync.WaitGroup
+		done       = make(chan struct{})
+	)
+	gather := func() {
+		for _, f := range s.marshalToSimpleFields() {
+			switch f.Name {
+			case "count":
+				count += f.Value
+			case "sum":
+				sum += f.Value
+			}
+		}
+	}
+	// gathers while updating, no value is lost or reported twice
+	gathering.Add(1)
+	go func() {
+		defer gathering.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				gather()
+			}
+		}
+	}()
+	concurrentDo(func() {
+		for i := 1; i <= 100; i++ {
+			s.UpdateMilliseconds(float64(i))
+		}
+	})
+	close(done)
+	gathering.Wait()
+	gather()
+	assert.Equal(t, float64(100*100), count)
+	assert.Equal(t, float64(100*5050), sum)
+	snapshot := s.snapshot()
+	assert.InEpsilon(t, 50, snapshot.values[0], 0.02)
+	assert.InEpsilon(t, 99, snapshot.values[2], 0.02)
+}
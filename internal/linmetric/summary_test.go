@@ -0,0 +1,96 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func Test_Summary(t *testing.T) {
+	now := int64(0)
+	s := newSummary()
+	s.nowFunc = func() int64 { return now }
+	s.WithWindow(time.Minute, 3).WithQuantiles(0.5, 0.99)
+
+	concurrentDo(func() {
+		for i := 1; i <= 100; i++ {
+			s.UpdateMilliseconds(float64(i))
+		}
+	})
+	s.UpdateMilliseconds(-1)
+	s.UpdateSince(time.Now().Add(time.Second))
+	s.Update(func() {})
+	snapshot := s.snapshot()
+	assert.Equal(t, []float64{0.5, 0.99}, snapshot.quantiles)
+	assert.InEpsilon(t, 50, snapshot.values[0], 0.02)
+	assert.InEpsilon(t, 99, snapshot.values[1], 0.02)
+
+	fields := s.marshalToSimpleFields()
+	assert.Len(t, fields, 4)
+	assert.Equal(t, "p50", fields[0].Name)
+	assert.Equal(t, protoMetricsV1.SimpleFieldType_GAUGE, fields[0].Type)
+	assert.Equal(t, "p99", fields[1].Name)
+	assert.Equal(t, &protoMetricsV1.SimpleField{
+		Name: "count", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 10001}, fields[2])
+	assert.Equal(t, &protoMetricsV1.SimpleField{
+		Name: "sum", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 505000}, fields[3])
+
+	// window slides, values are still in window
+	now += 20 * time.Second.Milliseconds()
+	s.UpdateSeconds(1)
+	fields = s.marshalToSimpleFields()
+	assert.InEpsilon(t, 50, fields[0].Value, 0.02)
+	assert.Equal(t, float64(1), fields[2].Value)
+	assert.Equal(t, float64(1000), fields[3].Value)
+	// first age bucket expired
+	now += 40 * time.Second.Milliseconds()
+	snapshot = s.snapshot()
+	assert.InEpsilon(t, 1000, snapshot.values[0], 0.02)
+	// all age buckets expired
+	now += 10 * time.Minute.Milliseconds()
+	snapshot = s.snapshot()
+	assert.Equal(t, []float64{0, 0}, snapshot.values)
+	assert.Equal(t, float64(10002), snapshot.count)
+	fields = s.marshalToSimpleFields()
+	assert.Equal(t, float64(0), fields[2].Value)
+}
+
+func Test_Summary_Options(t *testing.T) {
+	s := newSummary()
+	assert.Panics(t, func() {
+		s.WithQuantiles()
+	})
+	assert.Panics(t, func() {
+		s.WithQuantiles(1)
+	})
+	assert.Panics(t, func() {
+		s.WithWindow(time.Minute, 0)
+	})
+	assert.Panics(t, func() {
+		s.WithWindow(time.Millisecond, 2)
+	})
+	assert.Equal(t, "p50", quantileFieldName(0.5))
+	assert.Equal(t, "p90", quantileFieldName(0.9))
+	assert.Equal(t, "p99", quantileFieldName(0.99))
+	assert.Equal(t, "p999", quantileFieldName(0.999))
+}
@@ -0,0 +1,102 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/series/tag"
+)
+
+type SummaryVec struct {
+	tags             tag.KeyValues // unique tags
+	tagKeys          []string
+	metricName       string // concated metric name
+	mu               sync.RWMutex
+	summaries        map[string]*BoundSummary
+	setQuantilesFunc func(s *BoundSummary)
+	setWindowFunc    func(s *BoundSummary)
+}
+
+func newSummaryVec(metricName string, tags tag.KeyValues, tagKey ...string) *SummaryVec {
+	return &SummaryVec{
+		metricName: metricName,
+		tags:       tags,
+		tagKeys:    tagKey,
+		summaries:  make(map[string]*BoundSummary),
+	}
+}
+
+func (sv *SummaryVec) WithQuantiles(quantiles ...float64) *SummaryVec {
+	assertQuantiles(quantiles...)
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	sv.setQuantilesFunc = func(s *BoundSummary) {
+		s.WithQuantiles(quantiles...)
+	}
+	return sv
+}
+
+func (sv *SummaryVec) WithWindow(window time.Duration, ageBuckets int) *SummaryVec {
+	assertSummaryWindow(window, ageBuckets)
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	sv.setWindowFunc = func(s *BoundSummary) {
+		s.WithWindow(window, ageBuckets)
+	}
+	return sv
+}
+
+func (sv *SummaryVec) WithTagValues(tagValues ...string) *BoundSummary {
+	if len(tagValues) != len(sv.tagKeys) {
+		panic("count of tagKey and tagValue not match")
+	}
+	id := strings.Join(tagValues, ",")
+	sv.mu.RLock()
+	s, ok := sv.summaries[id]
+	sv.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	s, ok = sv.summaries[id]
+	if ok {
+		return s
+	}
+	var tagsMap = sv.tags.Map()
+	for i := range sv.tagKeys {
+		tagsMap[sv.tagKeys[i]] = tagValues[i]
+	}
+	series := newTaggedSeries(sv.metricName, tag.KeyValuesFromMap(tagsMap))
+	s = series.NewSummary()
+	if sv.setQuantilesFunc != nil {
+		sv.setQuantilesFunc(s)
+	}
+	if sv.setWindowFunc != nil {
+		sv.setWindowFunc(s)
+	}
+	sv.summaries[id] = s
+	return s
+}
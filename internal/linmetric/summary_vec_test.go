@@ -0,0 +1,53 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SummaryVec(t *testing.T) {
+	scope := NewScope("summary_vec_test")
+	vec := scope.NewSummaryVec("1", "2")
+
+	assert.Panics(t, func() {
+		vec.WithTagValues("1")
+	})
+	vec.WithTagValues("1", "2").UpdateSeconds(1)
+	vec.WithQuantiles(0.75).WithWindow(time.Minute*2, 4)
+
+	s := vec.WithTagValues("a", "b")
+	s.UpdateSeconds(1)
+	assert.Equal(t, s, vec.WithTagValues("a", "b"))
+	assert.Equal(t, []float64{0.75}, s.quantiles)
+	assert.Len(t, s.sketches, 4)
+	assert.Equal(t, time.Minute*2, s.window)
+	vec.WithTagValues("a", "c").UpdateSeconds(1)
+}
+
+func Benchmark_SummaryVec(b *testing.B) {
+	scope := NewScope("summary_vec_test")
+	vec := scope.NewSummaryVec("1", "2")
+
+	for i := 0; i < b.N; i++ {
+		vec.WithTagValues("3", "4").UpdateSeconds(1)
+	}
+}
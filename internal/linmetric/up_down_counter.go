@@ -0,0 +1,57 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import "go.uber.org/atomic"
+
+// BoundUpDownCounter is a counter which has Bound to a certain metric with field-name and tags,
+// it supports incrementing and decrementing, like the number of in-flight requests.
+// The current value is reported as gauge, and never resets.
+type BoundUpDownCounter struct {
+	fieldName string
+	value     atomic.Float64
+}
+
+func newUpDownCounter(fieldName string) *BoundUpDownCounter {
+	return &BoundUpDownCounter{fieldName: fieldName}
+}
+
+// Incr increments c.
+func (c *BoundUpDownCounter) Incr() {
+	c.value.Add(1)
+}
+
+// Decr decrements c.
+func (c *BoundUpDownCounter) Decr() {
+	c.value.Sub(1)
+}
+
+// Add adds v to c.
+func (c *BoundUpDownCounter) Add(v float64) {
+	c.value.Add(v)
+}
+
+// Sub subs v to c.
+func (c *BoundUpDownCounter) Sub(v float64) {
+	c.value.Sub(v)
+}
+
+// Get returns the current counter value
+func (c *BoundUpDownCounter) Get() float64 {
+	return c.value.Load()
+}
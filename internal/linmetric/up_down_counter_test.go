@@ -0,0 +1,110 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func Test_UpDownCounter(t *testing.T) {
+	c := newUpDownCounter("in_flight")
+	var wg sync.WaitGroup
+	for range [10]struct{}{} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				c.Add(3)
+				c.Sub(1)
+				c.Incr()
+				c.Decr()
+				c.Decr()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, float64(100), c.Get())
+	// never resets
+	assert.Equal(t, float64(100), c.Get())
+}
+
+func Test_UpDownCounter_Ops(t *testing.T) {
+	examples := []struct {
+		update func(c *BoundUpDownCounter)
+		value  float64
+	}{
+		{func(c *BoundUpDownCounter) {}, 0},
+		{func(c *BoundUpDownCounter) { c.Incr(); c.Incr() }, 2},
+		{func(c *BoundUpDownCounter) { c.Incr(); c.Decr(); c.Decr() }, -1},
+		{func(c *BoundUpDownCounter) { c.Add(1.5); c.Sub(0.5) }, 1},
+		{func(c *BoundUpDownCounter) { c.Add(-3) }, -3},
+		{func(c *BoundUpDownCounter) { c.Sub(-3) }, 3},
+	}
+	for _, example := range examples {
+		c := newUpDownCounter("in_flight")
+		example.update(c)
+		assert.Equal(t, example.value, c.Get())
+	}
+}
+
+func Test_UpDownCounter_ConcurrentGather(t *testing.T) {
+	series := newTaggedSeries("up_down_counter_test", nil)
+	c := series.NewUpDownCounter("in_flight")
+	assert.Same(t, c, series.NewUpDownCounter("in_flight"))
+
+	var (
+		gathering sync.WaitGroup
+		done      = make(chan struct{})
+	)
+	// gathering never resets the counter, and each gathered value is in range of in-flight updates
+	gathering.Add(1)
+	go func() {
+		defer gathering.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				m := series.gatherMetric()
+				assert.Len(t, m.SimpleFields, 1)
+				assert.Equal(t, protoMetricsV1.SimpleFieldType_GAUGE, m.SimpleFields[0].Type)
+				assert.True(t, m.SimpleFields[0].Value >= 0 && m.SimpleFields[0].Value <= 1000)
+			}
+		}
+	}()
+	concurrentDo(func() {
+		for i := 0; i < 10; i++ {
+			// request started
+			c.Incr()
+		}
+		for i := 0; i < 5; i++ {
+			// request completed
+			c.Decr()
+		}
+	})
+	close(done)
+	gathering.Wait()
+	assert.Equal(t, float64(500), c.Get())
+	assert.Equal(t, float64(500), series.gatherMetric().SimpleFields[0].Value)
+	assert.Equal(t, float64(500), series.gatherMetric().SimpleFields[0].Value)
+}
@@ -0,0 +1,74 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linmetric //nolint:dupl
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lindb/lindb/series/tag"
+)
+
+type UpDownCounterVec struct {
+	tags       tag.KeyValues // unique tags
+	tagKeys    []string
+	metricName string // concated metric name
+	fieldName  string
+	mu         sync.RWMutex
+	counters   map[string]*BoundUpDownCounter
+}
+
+func newUpDownCounterVec(metricName string, fieldName string, tags tag.KeyValues, tagKey ...string) *UpDownCounterVec {
+	return &UpDownCounterVec{
+		metricName: metricName,
+		fieldName:  fieldName,
+		tags:       tags,
+		tagKeys:    tagKey,
+		counters:   make(map[string]*BoundUpDownCounter),
+	}
+}
+
+func (cv *UpDownCounterVec) WithTagValues(tagValues ...string) *BoundUpDownCounter {
+	if len(tagValues) != len(cv.tagKeys) {
+		panic("count of tagKey and tagValue not match")
+	}
+	id := strings.Join(tagValues, ",")
+	cv.mu.RLock()
+	c, ok := cv.counters[id]
+	cv.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	c, ok = cv.counters[id]
+	if ok {
+		return c
+	}
+	var tagsMap = cv.tags.Map()
+	for i := range cv.tagKeys {
+		tagsMap[cv.tagKeys[i]] = tagValues[i]
+	}
+	series := newTaggedSeries(cv.metricName, tag.KeyValuesFromMap(tagsMap))
+	c = series.NewUpDownCounter(cv.fieldName)
+
+	cv.counters[id] = c
+	return c
+}
@@ -44,6 +44,15 @@ func (etcd *EtcdCluster) Terminate(t *testing.T) {
 	etcd.cluster.Terminate(t)
 }
 
+// Restart stops then restarts the etcd member, simulates etcd server restarting
+func (etcd *EtcdCluster) Restart(t *testing.T) {
+	member := etcd.cluster.Members[0]
+	member.Stop(t)
+	if err := member.Restart(t); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // RepoTestSuite represents repo test suite init integration etcd cluster
 type RepoTestSuite struct {
 	Cluster *EtcdCluster
@@ -64,6 +73,11 @@ func (ts *RepoTestSuite) SetUpSuite(c *check.C) {
 	ts.Cluster = StartEtcdCluster(test)
 }
 
+// RestartCluster restarts integration etcd cluster
+func (ts *RepoTestSuite) RestartCluster() {
+	ts.Cluster.Restart(test)
+}
+
 // TearDownSuite teardowns test suite, release resource
 func (ts *RepoTestSuite) TearDownSuite(c *check.C) {
 	ts.Cluster.Terminate(test)
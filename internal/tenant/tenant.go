@@ -0,0 +1,266 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cespare/xxhash"
+	"golang.org/x/time/rate"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/tag"
+)
+
+//go:generate mockgen -source=./tenant.go -destination=./tenant_mock.go -package=tenant
+
+// defaultQuota is the namespace of quota which applies to the namespaces not configured.
+const defaultQuota = "*"
+
+var (
+	// ErrWriteRateExceeded is the error returned when metric exceeds the write rate quota of namespace.
+	ErrWriteRateExceeded = errors.New("exceeds write rate quota of namespace")
+	// ErrSeriesLimitExceeded is the error returned when new series exceeds the series quota of namespace.
+	ErrSeriesLimitExceeded = errors.New("exceeds series quota of namespace")
+	// ErrQueryRateExceeded is the error returned when query exceeds the query rate quota of namespace.
+	ErrQueryRateExceeded = errors.New("exceeds query rate quota of namespace")
+	// ErrNamespaceIsolated is the error returned when query accesses the namespace of other tenant.
+	ErrNamespaceIsolated = errors.New("cannot access namespace of other tenant")
+)
+
+var (
+	namespaceScope     = linmetric.NewScope("lindb.broker.namespace")
+	writesVec          = namespaceScope.NewDeltaCounterVec("writes", "namespace")
+	writeThrottlesVec  = namespaceScope.NewDeltaCounterVec("write_throttles", "namespace")
+	seriesLimitsVec    = namespaceScope.NewDeltaCounterVec("series_limits", "namespace")
+	queriesVec         = namespaceScope.NewDeltaCounterVec("queries", "namespace")
+	queryThrottlesVec  = namespaceScope.NewDeltaCounterVec("query_throttles", "namespace")
+	activeSeriesVec    = namespaceScope.NewGaugeVec("active_series", "namespace")
+	isolatedQueriesVec = namespaceScope.NewDeltaCounterVec("isolated_queries", "namespace")
+)
+
+// Tenants enforces the quotas and isolation of namespaces(tenants) in broker,
+// reports the statistics of each namespace to monitoring.
+type Tenants interface {
+	// AdmitWrite checks the metric against the write rate and series quota of its namespace,
+	// returns error if metric is rejected.
+	AdmitWrite(metric *protoMetricsV1.Metric) error
+	// AdmitQuery binds the namespace of query statement to the tenant of query if isolation enabled,
+	// then checks the query rate quota, returns the namespace which query accesses.
+	AdmitQuery(tenant, namespace string) (string, error)
+	// Isolation returns if query can only access the namespace of its tenant.
+	Isolation() bool
+	// UpdateQuotas applies new quotas at runtime, statistics of series quota are reset.
+	UpdateQuotas(quotas []config.NamespaceQuota)
+}
+
+// tenants implements Tenants.
+type tenants struct {
+	isolation    bool
+	seriesWindow int64 // ms, 0 means series never expire
+
+	mutex      sync.RWMutex
+	quotas     map[string]config.NamespaceQuota
+	namespaces map[string]*namespace
+}
+
+// NewTenants creates the Tenants based on tenant config.
+func NewTenants(cfg config.Tenant) Tenants {
+	t := &tenants{
+		isolation:    cfg.Isolation,
+		seriesWindow: cfg.SeriesWindow.Duration().Milliseconds(),
+	}
+	t.UpdateQuotas(cfg.Quotas)
+	return t
+}
+
+// AdmitWrite checks the metric against the write rate and series quota of its namespace.
+func (t *tenants) AdmitWrite(metric *protoMetricsV1.Metric) error {
+	ns := t.getNamespace(metric.Namespace)
+	if err := ns.admitWrite(metric, t.seriesWindow); err != nil {
+		return fmt.Errorf("%w(%s)", err, ns.name)
+	}
+	return nil
+}
+
+// AdmitQuery binds the namespace of query statement to the tenant of query, then checks the query rate quota.
+func (t *tenants) AdmitQuery(tenant, namespace string) (string, error) {
+	if namespace == "" {
+		namespace = constants.DefaultNamespace
+	}
+	if t.isolation {
+		if tenant == "" {
+			tenant = constants.DefaultNamespace
+		}
+		// statement without namespace queries the namespace of tenant
+		if namespace == constants.DefaultNamespace {
+			namespace = tenant
+		}
+		if namespace != tenant {
+			isolatedQueriesVec.WithTagValues(tenant).Incr()
+			return "", fmt.Errorf("%w, tenant: %s, namespace: %s", ErrNamespaceIsolated, tenant, namespace)
+		}
+	}
+	ns := t.getNamespace(namespace)
+	if err := ns.admitQuery(); err != nil {
+		return "", fmt.Errorf("%w(%s)", err, ns.name)
+	}
+	return namespace, nil
+}
+
+// Isolation returns if query can only access the namespace of its tenant.
+func (t *tenants) Isolation() bool {
+	return t.isolation
+}
+
+// UpdateQuotas applies new quotas at runtime, limiters and active series are rebuilt.
+func (t *tenants) UpdateQuotas(quotas []config.NamespaceQuota) {
+	m := make(map[string]config.NamespaceQuota, len(quotas))
+	for _, quota := range quotas {
+		m[quota.Namespace] = quota
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.quotas = m
+	t.namespaces = make(map[string]*namespace)
+}
+
+// getNamespace returns the state of namespace, creates it with quota if not exist.
+func (t *tenants) getNamespace(name string) *namespace {
+	if name == "" {
+		name = constants.DefaultNamespace
+	}
+	t.mutex.RLock()
+	ns, ok := t.namespaces[name]
+	t.mutex.RUnlock()
+	if ok {
+		return ns
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if ns, ok = t.namespaces[name]; ok {
+		return ns
+	}
+	quota, ok := t.quotas[name]
+	if !ok {
+		quota = t.quotas[defaultQuota]
+	}
+	ns = newNamespace(name, quota)
+	t.namespaces[name] = ns
+	return ns
+}
+
+// namespace represents the quota state and statistics of a namespace.
+type namespace struct {
+	name         string
+	writeLimiter *rate.Limiter // nil means no limit
+	queryLimiter *rate.Limiter // nil means no limit
+	maxSeries    int           // 0 means no limit
+
+	mutex       sync.Mutex
+	series      map[uint64]struct{} // active series in current window
+	windowStart int64
+
+	writes         *linmetric.BoundDeltaCounter
+	writeThrottles *linmetric.BoundDeltaCounter
+	seriesLimits   *linmetric.BoundDeltaCounter
+	queries        *linmetric.BoundDeltaCounter
+	queryThrottles *linmetric.BoundDeltaCounter
+	activeSeries   *linmetric.BoundGauge
+}
+
+// newNamespace creates the state of namespace with its quota.
+func newNamespace(name string, quota config.NamespaceQuota) *namespace {
+	ns := &namespace{
+		name:           name,
+		maxSeries:      quota.MaxSeries,
+		writes:         writesVec.WithTagValues(name),
+		writeThrottles: writeThrottlesVec.WithTagValues(name),
+		seriesLimits:   seriesLimitsVec.WithTagValues(name),
+		queries:        queriesVec.WithTagValues(name),
+		queryThrottles: queryThrottlesVec.WithTagValues(name),
+		activeSeries:   activeSeriesVec.WithTagValues(name),
+	}
+	if quota.WriteRate > 0 {
+		ns.writeLimiter = rate.NewLimiter(rate.Limit(quota.WriteRate), quota.WriteRate)
+	}
+	if quota.QueryRate > 0 {
+		ns.queryLimiter = rate.NewLimiter(rate.Limit(quota.QueryRate), quota.QueryRate)
+	}
+	if quota.MaxSeries > 0 {
+		ns.series = make(map[uint64]struct{})
+		ns.windowStart = timeutil.Now()
+	}
+	ns.activeSeries.Update(0)
+	return ns
+}
+
+// admitWrite checks the write rate and series quota, new series is counted into active series if admitted.
+func (ns *namespace) admitWrite(metric *protoMetricsV1.Metric, seriesWindow int64) error {
+	if ns.writeLimiter != nil && !ns.writeLimiter.Allow() {
+		ns.writeThrottles.Incr()
+		return ErrWriteRateExceeded
+	}
+	if ns.maxSeries > 0 && !ns.admitSeries(metric, seriesWindow) {
+		ns.seriesLimits.Incr()
+		return ErrSeriesLimitExceeded
+	}
+	ns.writes.Incr()
+	return nil
+}
+
+// admitSeries returns if the series of metric is active or can be added into active series.
+func (ns *namespace) admitSeries(metric *protoMetricsV1.Metric, seriesWindow int64) bool {
+	hash := xxhash.Sum64String(metric.Name + "," + tag.ConcatKeyValues(metric.Tags))
+
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+
+	if now := timeutil.Now(); seriesWindow > 0 && now-ns.windowStart >= seriesWindow {
+		ns.series = make(map[uint64]struct{})
+		ns.windowStart = now
+	}
+	if _, ok := ns.series[hash]; ok {
+		return true
+	}
+	if len(ns.series) >= ns.maxSeries {
+		return false
+	}
+	ns.series[hash] = struct{}{}
+	ns.activeSeries.Update(float64(len(ns.series)))
+	return true
+}
+
+// admitQuery checks the query rate quota.
+func (ns *namespace) admitQuery() error {
+	if ns.queryLimiter != nil && !ns.queryLimiter.Allow() {
+		ns.queryThrottles.Incr()
+		return ErrQueryRateExceeded
+	}
+	ns.queries.Incr()
+	return nil
+}
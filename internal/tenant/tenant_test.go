@@ -0,0 +1,107 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tenant
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/ltoml"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func newMetric(namespace, name, host string) *protoMetricsV1.Metric {
+	return &protoMetricsV1.Metric{
+		Namespace: namespace,
+		Name:      name,
+		Tags:      []*protoMetricsV1.KeyValue{{Key: "host", Value: host}},
+	}
+}
+
+func TestTenants_AdmitWrite(t *testing.T) {
+	ts := NewTenants(config.Tenant{
+		SeriesWindow: ltoml.Duration(time.Hour),
+		Quotas: []config.NamespaceQuota{
+			{Namespace: "*", MaxSeries: 2},
+			{Namespace: "ns", WriteRate: 2},
+		},
+	})
+	// case 1: write rate quota
+	assert.NoError(t, ts.AdmitWrite(newMetric("ns", "cpu", "1")))
+	assert.NoError(t, ts.AdmitWrite(newMetric("ns", "cpu", "2")))
+	err := ts.AdmitWrite(newMetric("ns", "cpu", "3"))
+	assert.True(t, errors.Is(err, ErrWriteRateExceeded))
+	// case 2: series quota of default namespace
+	assert.NoError(t, ts.AdmitWrite(newMetric("", "cpu", "1")))
+	assert.NoError(t, ts.AdmitWrite(newMetric(constants.DefaultNamespace, "cpu", "2")))
+	assert.NoError(t, ts.AdmitWrite(newMetric("", "cpu", "1")))
+	err = ts.AdmitWrite(newMetric("", "cpu", "3"))
+	assert.True(t, errors.Is(err, ErrSeriesLimitExceeded))
+	// case 3: series quota is isolated by namespace
+	assert.NoError(t, ts.AdmitWrite(newMetric("other", "cpu", "3")))
+	// case 4: series window slides
+	ns := ts.(*tenants).getNamespace("")
+	ns.windowStart -= time.Hour.Milliseconds()
+	assert.NoError(t, ts.AdmitWrite(newMetric("", "cpu", "3")))
+	assert.Len(t, ns.series, 1)
+	// case 5: update quotas
+	ts.UpdateQuotas(nil)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, ts.AdmitWrite(newMetric("ns", "cpu", "4")))
+		assert.NoError(t, ts.AdmitWrite(newMetric("", "memory", "4")))
+	}
+}
+
+func TestTenants_AdmitQuery(t *testing.T) {
+	ts := NewTenants(config.Tenant{
+		Quotas: []config.NamespaceQuota{{Namespace: "ns", QueryRate: 1}},
+	})
+	// case 1: isolation disabled
+	ns, err := ts.AdmitQuery("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.DefaultNamespace, ns)
+	ns, err = ts.AdmitQuery("other", "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns", ns)
+	// case 2: query rate quota
+	_, err = ts.AdmitQuery("", "ns")
+	assert.True(t, errors.Is(err, ErrQueryRateExceeded))
+
+	// case 3: isolation enabled
+	assert.False(t, ts.Isolation())
+	ts = NewTenants(config.Tenant{Isolation: true})
+	assert.True(t, ts.Isolation())
+	ns, err = ts.AdmitQuery("ns", constants.DefaultNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns", ns)
+	ns, err = ts.AdmitQuery("ns", "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns", ns)
+	_, err = ts.AdmitQuery("ns", "other")
+	assert.True(t, errors.Is(err, ErrNamespaceIsolated))
+	_, err = ts.AdmitQuery("", "other")
+	assert.True(t, errors.Is(err, ErrNamespaceIsolated))
+	ns, err = ts.AdmitQuery("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.DefaultNamespace, ns)
+}
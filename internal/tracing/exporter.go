@@ -0,0 +1,251 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+var exporterLogger = logger.GetLogger("monitoring", "TraceExporter")
+
+var (
+	tracingScope        = linmetric.NewScope("lindb.monitor.tracing")
+	exportSpansCounter  = tracingScope.NewDeltaCounter("export_spans")
+	dropSpansCounter    = tracingScope.NewDeltaCounter("drop_spans")
+	exportErrorsCounter = tracingScope.NewDeltaCounter("export_errors")
+)
+
+const (
+	defaultFlushInterval = 5 * time.Second
+	defaultMaxQueueSize  = 2048
+	instrumentationName  = "github.com/lindb/lindb"
+	// otlp span kind/status code, see opentelemetry-proto trace.proto
+	spanKindInternal = 1
+	statusCodeOK     = 1
+	statusCodeError  = 2
+)
+
+// Exporter exports the ended spans.
+type Exporter interface {
+	// ExportSpan exports the ended span, must not block.
+	ExportSpan(span *Span)
+}
+
+// OTLPExporter batches the ended spans, then exports spans to OpenTelemetry collector
+// via OTLP/HTTP json protocol(POST {endpoint}/v1/traces) in period.
+type OTLPExporter struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	endpoint      string
+	flushInterval time.Duration
+	maxQueueSize  int
+	resource      []otlpKeyValue
+	client        *http.Client
+
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewOTLPExporter creates an OTLP/HTTP exporter, endpoint is the trace receiver url of collector.
+func NewOTLPExporter(
+	ctx context.Context,
+	endpoint string,
+	timeout time.Duration,
+	resource map[string]string,
+) *OTLPExporter {
+	c, cancel := context.WithCancel(ctx)
+	e := &OTLPExporter{
+		ctx:           c,
+		cancel:        cancel,
+		endpoint:      endpoint,
+		flushInterval: defaultFlushInterval,
+		maxQueueSize:  defaultMaxQueueSize,
+		client:        &http.Client{Timeout: timeout},
+	}
+	for key, value := range resource {
+		e.resource = append(e.resource, newOTLPKeyValue(key, value))
+	}
+	return e
+}
+
+// ExportSpan adds the span into queue, drops the span if queue is full.
+func (e *OTLPExporter) ExportSpan(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.spans) >= e.maxQueueSize {
+		dropSpansCounter.Incr()
+		return
+	}
+	e.spans = append(e.spans, span)
+}
+
+// Start flushes the spans in period, flushes remaining spans when stopped.
+func (e *OTLPExporter) Start() {
+	exporterLogger.Info("otlp trace exporter starting...")
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.flush()
+		case <-e.ctx.Done():
+			e.flush()
+			exporterLogger.Info("otlp trace exporter stopped")
+			return
+		}
+	}
+}
+
+// Stop stops the exporter.
+func (e *OTLPExporter) Stop() {
+	e.cancel()
+}
+
+// flush posts all spans in queue to collector.
+func (e *OTLPExporter) flush() {
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mu.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+	data := encoding.JSONMarshal(e.buildRequest(spans))
+	req, _ := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		exportErrorsCounter.Incr()
+		exporterLogger.Error("failed to export spans", logger.Error(err))
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		exportErrorsCounter.Incr()
+		exporterLogger.Error("failed to export spans", logger.String("status", resp.Status))
+		return
+	}
+	exportSpansCounter.Add(float64(len(spans)))
+}
+
+// buildRequest converts spans to OTLP export trace service request.
+func (e *OTLPExporter) buildRequest(spans []*Span) *otlpTraceRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, newOTLPSpan(span))
+	}
+	return &otlpTraceRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: e.resource},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentationName},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
+
+// otlpTraceRequest is the json mapping of OTLP ExportTraceServiceRequest.
+type otlpTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string        `json:"key"`
+	Value otlpAnyString `json:"value"`
+}
+
+type otlpAnyString struct {
+	StringValue string `json:"stringValue"`
+}
+
+func newOTLPKeyValue(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyString{StringValue: value}}
+}
+
+func newOTLPSpan(span *Span) otlpSpan {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	s := otlpSpan{
+		TraceID:           span.spanCtx.TraceID.String(),
+		SpanID:            span.spanCtx.SpanID.String(),
+		Name:              span.name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(span.startTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.endTime.UnixNano(), 10),
+		Status:            otlpStatus{Code: statusCodeOK},
+	}
+	if span.parentSpanID.IsValid() {
+		s.ParentSpanID = span.parentSpanID.String()
+	}
+	for _, attr := range span.attributes {
+		s.Attributes = append(s.Attributes, newOTLPKeyValue(attr.Key, attr.Value))
+	}
+	if span.errMsg != "" {
+		s.Status = otlpStatus{Code: statusCodeError, Message: span.errMsg}
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/encoding"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan *otlpTraceRequest, 1)
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(r.Body)
+		req := &otlpTraceRequest{}
+		assert.NoError(t, encoding.JSONUnmarshal(body, req))
+		w.WriteHeader(status)
+		received <- req
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(context.TODO(), server.URL, time.Second, map[string]string{"role": "broker"})
+	exporter.flushInterval = 10 * time.Millisecond
+	Setup(exporter, 1)
+	defer Setup(nil, 0)
+
+	// empty queue
+	exporter.flush()
+
+	ctx, root := StartSpan(context.TODO(), "root")
+	_, child := StartSpan(ctx, "child")
+	child.SetAttribute("key", "value")
+	child.SetError(fmt.Errorf("err"))
+	child.End()
+	root.End()
+
+	go exporter.Start()
+	req := <-received
+	exporter.Stop()
+
+	assert.Len(t, req.ResourceSpans, 1)
+	assert.Equal(t, []otlpKeyValue{newOTLPKeyValue("role", "broker")}, req.ResourceSpans[0].Resource.Attributes)
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, root.SpanContext().TraceID.String(), spans[0].TraceID)
+	assert.Equal(t, root.SpanContext().SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, []otlpKeyValue{newOTLPKeyValue("key", "value")}, spans[0].Attributes)
+	assert.Equal(t, otlpStatus{Code: statusCodeError, Message: "err"}, spans[0].Status)
+	assert.Equal(t, "root", spans[1].Name)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Equal(t, otlpStatus{Code: statusCodeOK}, spans[1].Status)
+}
+
+func TestOTLPExporter_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(context.TODO(), server.URL, time.Second, nil)
+	exporter.maxQueueSize = 1
+	exporter.ExportSpan(&Span{})
+	// queue is full, drop span
+	exporter.ExportSpan(&Span{})
+	assert.Len(t, exporter.spans, 1)
+	exporter.flush()
+	assert.Empty(t, exporter.spans)
+
+	// bad endpoint
+	exporter = NewOTLPExporter(context.TODO(), "http://127.0.0.1:1", time.Second, nil)
+	exporter.ExportSpan(&Span{})
+	exporter.flush()
+}
@@ -0,0 +1,108 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceParentKey is the metadata key of trace context, follows W3C trace context format:
+// {version}-{trace-id}-{parent-id}-{trace-flags}, e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+const TraceParentKey = "traceparent"
+
+const (
+	traceParentVersion = "00"
+	flagSampled        = "01"
+	flagNotSampled     = "00"
+)
+
+// Inject writes the trace context of span and the request id in context into metadata,
+// does nothing if context has neither span nor request id.
+func Inject(ctx context.Context, metadata map[string]string) {
+	if metadata == nil {
+		return
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		metadata[RequestIDKey] = requestID
+	}
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	metadata[TraceParentKey] = formatTraceParent(span.SpanContext())
+}
+
+// Extract reads the trace context and the request id from metadata, returns the context with remote span context,
+// which is used as parent of new span, returns the original context if metadata has no valid trace context.
+func Extract(ctx context.Context, metadata map[string]string) context.Context {
+	if requestID, ok := metadata[RequestIDKey]; ok {
+		ctx = WithRequestID(ctx, requestID)
+	}
+	value, ok := metadata[TraceParentKey]
+	if !ok {
+		return ctx
+	}
+	sc, err := parseTraceParent(value)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteSpanKey{}, sc)
+}
+
+// formatTraceParent formats span context to W3C traceparent value.
+func formatTraceParent(sc SpanContext) string {
+	flags := flagNotSampled
+	if sc.Sampled {
+		flags = flagSampled
+	}
+	return strings.Join([]string{traceParentVersion, sc.TraceID.String(), sc.SpanID.String(), flags}, "-")
+}
+
+// parseTraceParent parses W3C traceparent value to span context.
+func parseTraceParent(value string) (sc SpanContext, err error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 || parts[0] != traceParentVersion {
+		return sc, fmt.Errorf("invalid traceparent: %s", value)
+	}
+	if err := decodeHex(parts[1], sc.TraceID[:]); err != nil {
+		return sc, err
+	}
+	if err := decodeHex(parts[2], sc.SpanID[:]); err != nil {
+		return sc, err
+	}
+	var flags [1]byte
+	if err := decodeHex(parts[3], flags[:]); err != nil {
+		return sc, err
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("invalid traceparent: %s", value)
+	}
+	return sc, nil
+}
+
+func decodeHex(value string, dst []byte) error {
+	if len(value) != hex.EncodedLen(len(dst)) {
+		return fmt.Errorf("invalid hex length: %s", value)
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err
+}
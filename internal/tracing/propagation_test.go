@@ -0,0 +1,75 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectAndExtract(t *testing.T) {
+	exporter := &mockExporter{}
+	Setup(exporter, 1)
+	defer Setup(nil, 0)
+
+	metadata := make(map[string]string)
+	// no span
+	Inject(context.TODO(), metadata)
+	assert.Empty(t, metadata)
+
+	ctx, span := StartSpan(context.TODO(), "root")
+	Inject(ctx, nil)
+	Inject(ctx, metadata)
+	sc := span.SpanContext()
+	assert.Equal(t, "00-"+sc.TraceID.String()+"-"+sc.SpanID.String()+"-01", metadata[TraceParentKey])
+
+	// remote node continues the trace
+	remoteCtx := Extract(context.TODO(), metadata)
+	_, child := StartSpan(remoteCtx, "child")
+	assert.Equal(t, sc.TraceID, child.SpanContext().TraceID)
+	assert.Equal(t, sc.SpanID, child.parentSpanID)
+	assert.True(t, child.SpanContext().Sampled)
+
+	// no trace context
+	ctx = context.TODO()
+	assert.Equal(t, ctx, Extract(ctx, nil))
+}
+
+func TestParseTraceParent(t *testing.T) {
+	sc, err := parseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.False(t, sc.Sampled)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", formatTraceParent(sc))
+
+	for _, value := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+	} {
+		_, err = parseTraceParent(value)
+		assert.Error(t, err, value)
+		assert.Equal(t, context.TODO(), Extract(context.TODO(), map[string]string{TraceParentKey: value}))
+	}
+}
@@ -0,0 +1,51 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestIDFromContext(context.TODO()))
+	assert.Equal(t, "r1", RequestIDFromContext(WithRequestID(context.TODO(), "r1")))
+
+	id := NewRequestID()
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, NewRequestID())
+	assert.True(t, IsValidRequestID(id))
+	assert.True(t, IsValidRequestID("req-1_2.3"))
+	assert.False(t, IsValidRequestID(""))
+	assert.False(t, IsValidRequestID("req 1"))
+	assert.False(t, IsValidRequestID("req\n1"))
+	assert.False(t, IsValidRequestID(strings.Repeat("a", maxRequestIDLen+1)))
+}
+
+func TestInjectAndExtract_RequestID(t *testing.T) {
+	metadata := make(map[string]string)
+	Inject(WithRequestID(context.TODO(), "r1"), metadata)
+	assert.Equal(t, map[string]string{RequestIDKey: "r1"}, metadata)
+
+	ctx := Extract(context.TODO(), metadata)
+	assert.Equal(t, "r1", RequestIDFromContext(ctx))
+	assert.Nil(t, SpanFromContext(ctx))
+}
@@ -0,0 +1,251 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID is the identifier of a trace, which is shared by all spans of one query across nodes.
+type TraceID [16]byte
+
+// IsValid returns if trace id is not all zero.
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String returns the hex encoding of trace id.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID is the identifier of a span.
+type SpanID [8]byte
+
+// IsValid returns if span id is not all zero.
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// String returns the hex encoding of span id.
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext represents the part of span which is propagated to children spans and remote nodes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid returns if span context has valid trace id and span id.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Attribute represents a key/value pair which describes the span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Span represents an operation of query pipeline, nil span is a no-op span.
+type Span struct {
+	spanCtx      SpanContext
+	parentSpanID SpanID
+	name         string
+	startTime    time.Time
+	endTime      time.Time
+	exporter     Exporter
+
+	mu         sync.Mutex
+	attributes []Attribute
+	errMsg     string
+	ended      bool
+}
+
+type spanKey struct{}
+type remoteSpanKey struct{}
+
+// StartSpan starts a new span as child of the span(local or remote) in context,
+// starts a new trace if context has no span, returns the context with the new span.
+// Returns nil span if tracing is disabled.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	t := getTracer()
+	if t == nil {
+		return ctx, nil
+	}
+	parent, hasParent := parentSpanContext(ctx)
+	span := &Span{
+		name:      name,
+		startTime: time.Now(),
+		exporter:  t.exporter,
+	}
+	if hasParent {
+		span.spanCtx.TraceID = parent.TraceID
+		span.spanCtx.Sampled = parent.Sampled
+		span.parentSpanID = parent.SpanID
+	} else {
+		span.spanCtx.TraceID = newTraceID()
+		span.spanCtx.Sampled = t.shouldSample(span.spanCtx.TraceID)
+	}
+	span.spanCtx.SpanID = newSpanID()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the local span in context, returns nil if not exist.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// parentSpanContext returns the span context of local span, or remote span extracted from request.
+func parentSpanContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.spanCtx, true
+	}
+	if sc, ok := ctx.Value(remoteSpanKey{}).(SpanContext); ok && sc.IsValid() {
+		return sc, true
+	}
+	return SpanContext{}, false
+}
+
+// SpanContext returns the span context of span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.spanCtx
+}
+
+// IsRecording returns if span is sampled and not ended.
+func (s *Span) IsRecording() bool {
+	if s == nil || !s.spanCtx.Sampled {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+// SetAttribute sets the key/value attribute of span.
+func (s *Span) SetAttribute(key, value string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, Attribute{Key: key, Value: value})
+}
+
+// SetError marks span as failure if err not nil.
+func (s *Span) SetError(err error) {
+	if err == nil || !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMsg = err.Error()
+}
+
+// End ends the span, then exports it if sampled, only the first call takes effect.
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.endTime = time.Now()
+	s.mu.Unlock()
+
+	if s.exporter != nil {
+		s.exporter.ExportSpan(s)
+	}
+}
+
+// newTraceID returns a random trace id.
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID returns a random span id.
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// tracer holds the exporter and sampler of tracing.
+type tracer struct {
+	exporter    Exporter
+	sampleRatio float64
+}
+
+// shouldSample returns if a new trace is sampled based on trace id, so that sampling is deterministic.
+func (t *tracer) shouldSample(traceID TraceID) bool {
+	switch {
+	case t.sampleRatio >= 1:
+		return true
+	case t.sampleRatio <= 0:
+		return false
+	default:
+		bound := uint64(t.sampleRatio * (1 << 63))
+		return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+	}
+}
+
+var (
+	tracerMu      sync.RWMutex
+	currentTracer *tracer
+)
+
+// Setup enables tracing with exporter and sample ratio(0~1) of new traces, disables tracing if exporter is nil.
+func Setup(exporter Exporter, sampleRatio float64) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+
+	if exporter == nil {
+		currentTracer = nil
+		return
+	}
+	currentTracer = &tracer{exporter: exporter, sampleRatio: sampleRatio}
+}
+
+// getTracer returns current tracer, returns nil if tracing is disabled.
+func getTracer() *tracer {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return currentTracer
+}
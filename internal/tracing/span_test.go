@@ -0,0 +1,110 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (e *mockExporter) ExportSpan(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func TestStartSpan_Disabled(t *testing.T) {
+	Setup(nil, 1)
+	ctx, span := StartSpan(context.TODO(), "test")
+	assert.Nil(t, span)
+	assert.Nil(t, SpanFromContext(ctx))
+	assert.False(t, span.IsRecording())
+	assert.False(t, span.SpanContext().IsValid())
+	// nil span is no-op
+	span.SetAttribute("key", "value")
+	span.SetError(fmt.Errorf("err"))
+	span.End()
+}
+
+func TestStartSpan(t *testing.T) {
+	exporter := &mockExporter{}
+	Setup(exporter, 1)
+	defer Setup(nil, 0)
+
+	ctx, root := StartSpan(context.TODO(), "root")
+	assert.Equal(t, root, SpanFromContext(ctx))
+	assert.True(t, root.IsRecording())
+	assert.True(t, root.SpanContext().IsValid())
+	assert.False(t, root.parentSpanID.IsValid())
+
+	_, child := StartSpan(ctx, "child")
+	assert.Equal(t, root.SpanContext().TraceID, child.SpanContext().TraceID)
+	assert.Equal(t, root.SpanContext().SpanID, child.parentSpanID)
+	assert.NotEqual(t, root.SpanContext().SpanID, child.SpanContext().SpanID)
+	child.SetAttribute("key", "value")
+	child.SetError(nil)
+	child.SetError(fmt.Errorf("err"))
+	child.End()
+	assert.False(t, child.IsRecording())
+	// ignore ended span
+	child.End()
+	child.SetAttribute("key2", "value2")
+	root.End()
+
+	assert.Len(t, exporter.spans, 2)
+	assert.Equal(t, []Attribute{{Key: "key", Value: "value"}}, child.attributes)
+	assert.Equal(t, "err", child.errMsg)
+	assert.False(t, child.endTime.IsZero())
+}
+
+func TestStartSpan_NotSampled(t *testing.T) {
+	exporter := &mockExporter{}
+	Setup(exporter, 0)
+	defer Setup(nil, 0)
+
+	ctx, root := StartSpan(context.TODO(), "root")
+	assert.False(t, root.IsRecording())
+	assert.True(t, root.SpanContext().IsValid())
+	_, child := StartSpan(ctx, "child")
+	assert.False(t, child.SpanContext().Sampled)
+	child.End()
+	root.End()
+	assert.Empty(t, exporter.spans)
+}
+
+func TestTracer_shouldSample(t *testing.T) {
+	tr := &tracer{sampleRatio: 0.5}
+	assert.True(t, tr.shouldSample(TraceID{8: 0x00}))
+	assert.False(t, tr.shouldSample(TraceID{8: 0xff}))
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if tr.shouldSample(newTraceID()) {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
@@ -18,6 +18,7 @@
 package kv
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -90,7 +91,13 @@ func (c *compactJob) mergeCompaction() (err error) {
 	}()
 
 	// do merge logic
-	if err = c.doMerge(); err != nil {
+	err = c.doMerge()
+	// reader returns nil value if it is corrupted, abort compaction for avoiding losing data,
+	// the corrupted input files will be quarantined by family after compaction.
+	if corruptedErr := c.state.checkCorruptedInputs(); corruptedErr != nil {
+		return corruptedErr
+	}
+	if err != nil {
 		return err
 	}
 	// if merge success install compaction results into manifest
@@ -105,8 +112,15 @@ func (c *compactJob) doMerge() error {
 		return err
 	}
 	merger := c.merger()
+	params := make(map[string]interface{})
+	for k, v := range c.state.mergerParams {
+		params[k] = v
+	}
 	if c.rollup != nil {
-		merger.Init(map[string]interface{}{RollupContext: c.rollup})
+		params[RollupContext] = c.rollup
+	}
+	if len(params) > 0 {
+		merger.Init(params)
 	}
 
 	var needMerge [][]byte
@@ -115,6 +129,10 @@ func (c *compactJob) doMerge() error {
 	for it.HasNext() {
 		key := it.Key()
 		value := it.Value()
+		if err := it.Err(); err != nil {
+			// stop merging, value is nil if it is corrupted
+			return err
+		}
 		switch {
 		case start || key == previousKey:
 			// if start or same keys, append to need merge slice
@@ -189,6 +207,10 @@ func (c *compactJob) add(key uint32, value []byte) error {
 			return err
 		}
 	}
+	// throttle written bytes before adding key/value
+	if err := c.throttle(len(value)); err != nil {
+		return err
+	}
 	// add key/value into store builder
 	if err := c.state.builder.Add(key, value); err != nil {
 		return err
@@ -213,6 +235,7 @@ func (c *compactJob) makeInputIterator() (table.Iterator, error) {
 				if err != nil {
 					return nil, err
 				}
+				c.state.inputReaders[fileMeta.GetFileNumber()] = reader
 				its = append(its, reader.Iterator())
 			}
 		}
@@ -220,6 +243,27 @@ func (c *compactJob) makeInputIterator() (table.Iterator, error) {
 	return table.NewMergedIterator(its), nil
 }
 
+// throttle waits until n bytes can be written if compaction job has limiter,
+// splits n by the burst of limiter, because limiter cannot wait n bytes which > burst.
+func (c *compactJob) throttle(n int) error {
+	limiter := c.state.limiter
+	if limiter == nil || limiter.Burst() <= 0 {
+		return nil
+	}
+	burst := limiter.Burst()
+	for n > 0 {
+		size := n
+		if size > burst {
+			size = burst
+		}
+		if err := limiter.WaitN(context.TODO(), size); err != nil {
+			return err
+		}
+		n -= size
+	}
+	return nil
+}
+
 // openCompactionOutputFile opens a new compaction store build, and adds the file number into pending output
 func (c *compactJob) openCompactionOutputFile() error {
 	//TODO add lock
@@ -18,12 +18,14 @@
 package kv
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 
 	"github.com/lindb/lindb/kv/table"
 	"github.com/lindb/lindb/kv/version"
@@ -102,6 +104,7 @@ func TestCompactJob_merge_compact_merge_fail(t *testing.T) {
 
 	snapshot := version.NewMockSnapshot(ctrl)
 	reader := table.NewMockReader(ctrl)
+	reader.EXPECT().Corrupted().Return(nil).AnyTimes()
 	gomock.InOrder(
 		reader.EXPECT().Iterator().Return(generateIterator(ctrl, map[uint32][]byte{
 			1: []byte("value1"),
@@ -142,7 +145,9 @@ func TestCompactJob_merge_doMerge_fail(t *testing.T) {
 
 	snapshot := version.NewMockSnapshot(ctrl)
 	reader1 := table.NewMockReader(ctrl)
+	reader1.EXPECT().Corrupted().Return(nil).AnyTimes()
 	reader2 := table.NewMockReader(ctrl)
+	reader2.EXPECT().Corrupted().Return(nil).AnyTimes()
 	merge := NewMockMerger(ctrl)
 
 	// test new store build fail
@@ -281,13 +286,54 @@ func TestCompactJob_merge_doMerge_fail(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestCompactJob_merge_corrupted_input(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	snapshot := version.NewMockSnapshot(ctrl)
+	reader1 := table.NewMockReader(ctrl)
+	reader1.EXPECT().Corrupted().Return(table.ErrCorrupted).AnyTimes()
+	reader2 := table.NewMockReader(ctrl)
+	reader2.EXPECT().Corrupted().Return(nil).AnyTimes()
+	// value of reader1 is corrupted, stop merging
+	it1 := table.NewMockIterator(ctrl)
+	gomock.InOrder(
+		it1.EXPECT().HasNext().Return(true),
+		it1.EXPECT().Key().Return(uint32(1)),
+		it1.EXPECT().Value().Return(nil),
+		it1.EXPECT().HasNext().Return(false),
+	)
+	it1.EXPECT().Err().Return(table.ErrCorrupted).AnyTimes()
+	reader1.EXPECT().Iterator().Return(it1)
+	reader2.EXPECT().Iterator().Return(generateIterator(ctrl, map[uint32][]byte{}))
+	snapshot.EXPECT().GetReader(table.FileNumber(1)).Return(reader1, nil)
+	snapshot.EXPECT().GetReader(table.FileNumber(4)).Return(reader2, nil)
+	family := generateMockFamily(ctrl, func() Merger { return NewMockMerger(ctrl) })
+	family.EXPECT().familyInfo().Return("family").AnyTimes()
+	f1 := version.NewFileMeta(1, 1, 10, 100)
+	f4 := version.NewFileMeta(4, 30, 100, 100)
+	compaction := version.NewCompaction(1, 0, []*version.FileMeta{f1}, []*version.FileMeta{f4})
+	state := newCompactionState(1000, snapshot, compaction)
+	compactJob := newCompactJob(family, state, nil)
+	err := compactJob.Run()
+	assert.True(t, errors.Is(err, table.ErrCorrupted))
+	assert.Len(t, state.corrupted, 1)
+
+	// quarantine corrupted input files
+	family.EXPECT().quarantineFile(table.FileNumber(1), table.ErrCorrupted)
+	quarantineCorruptedInputs(family, state)
+	quarantineCorruptedInputs(family, nil)
+}
+
 func TestCompactJob_output_fail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	snapshot := version.NewMockSnapshot(ctrl)
 	reader1 := table.NewMockReader(ctrl)
+	reader1.EXPECT().Corrupted().Return(nil).AnyTimes()
 	reader2 := table.NewMockReader(ctrl)
+	reader2.EXPECT().Corrupted().Return(nil).AnyTimes()
 	merge := NewMockMerger(ctrl)
 
 	// test store build is empty
@@ -387,9 +433,13 @@ func TestCompactJob_merge_compact(t *testing.T) {
 
 	snapshot := version.NewMockSnapshot(ctrl)
 	reader1 := table.NewMockReader(ctrl)
+	reader1.EXPECT().Corrupted().Return(nil).AnyTimes()
 	reader2 := table.NewMockReader(ctrl)
+	reader2.EXPECT().Corrupted().Return(nil).AnyTimes()
 	reader3 := table.NewMockReader(ctrl)
+	reader3.EXPECT().Corrupted().Return(nil).AnyTimes()
 	reader4 := table.NewMockReader(ctrl)
+	reader4.EXPECT().Corrupted().Return(nil).AnyTimes()
 	reader1.EXPECT().Iterator().Return(generateIterator(ctrl, map[uint32][]byte{
 		1:  []byte("value1"),
 		3:  []byte("value3"),
@@ -462,6 +512,55 @@ func TestCompactJob_merge_compact(t *testing.T) {
 	assert.Equal(t, version.CreateNewFile(1, newFile), logs[4])
 }
 
+func TestCompactJob_merger_params(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	snapshot := version.NewMockSnapshot(ctrl)
+	reader1 := table.NewMockReader(ctrl)
+	reader1.EXPECT().Corrupted().Return(nil).AnyTimes()
+	reader2 := table.NewMockReader(ctrl)
+	reader2.EXPECT().Corrupted().Return(nil).AnyTimes()
+	reader1.EXPECT().Iterator().Return(generateIterator(ctrl, map[uint32][]byte{1: []byte("value1")}))
+	reader2.EXPECT().Iterator().Return(generateIterator(ctrl, map[uint32][]byte{1: []byte("value1")}))
+	snapshot.EXPECT().GetReader(table.FileNumber(1)).Return(reader1, nil)
+	snapshot.EXPECT().GetReader(table.FileNumber(2)).Return(reader2, nil)
+	merger := NewMockMerger(ctrl)
+	family := generateMockFamily(ctrl, func() Merger { return merger })
+	family.EXPECT().familyInfo().Return("family").AnyTimes()
+	rollup := NewMockRollup(ctrl)
+	compaction := version.NewCompaction(1, 0,
+		[]*version.FileMeta{version.NewFileMeta(1, 1, 10, 100)},
+		[]*version.FileMeta{version.NewFileMeta(2, 1, 10, 100)})
+	state := newCompactionState(10000000, snapshot, compaction)
+	state.mergerParams = map[string]interface{}{"key": "value"}
+	compactJob := newCompactJob(family, state, rollup)
+	merger.EXPECT().Init(map[string]interface{}{"key": "value", RollupContext: rollup})
+	// drop key if merged value is empty
+	merger.EXPECT().Merge(uint32(1), gomock.Any()).Return(nil, nil)
+	err := compactJob.Run()
+	assert.NoError(t, err)
+	assert.Empty(t, state.outputs)
+	logs := state.compaction.GetEditLog().GetLogs()
+	assert.Equal(t, []version.Log{version.NewDeleteFile(0, 1), version.NewDeleteFile(1, 2)}, logs)
+}
+
+func TestCompactJob_throttle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := newCompactionState(1000, nil, nil)
+	job := newCompactJob(generateMockFamily(ctrl, newMockMerger), state, nil).(*compactJob)
+	// case 1: no limiter
+	assert.NoError(t, job.throttle(100))
+	// case 2: bytes > burst, split by burst
+	state.limiter = rate.NewLimiter(rate.Inf, 10)
+	assert.NoError(t, job.throttle(100))
+	// case 3: burst is zero, no limit
+	state.limiter = rate.NewLimiter(rate.Limit(10), 0)
+	assert.NoError(t, job.throttle(100))
+}
+
 func generateMockFamily(ctrl *gomock.Controller, merger NewMerger) *MockFamily {
 	family := NewMockFamily(ctrl)
 	family.EXPECT().getNewMerger().Return(merger).AnyTimes()
@@ -489,5 +588,6 @@ func generateIterator(ctrl *gomock.Controller, values map[uint32][]byte) table.I
 	calls = append(calls, it1.EXPECT().HasNext().Return(false))
 
 	gomock.InOrder(calls...)
+	it1.EXPECT().Err().Return(nil).AnyTimes()
 	return it1
 }
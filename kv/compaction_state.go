@@ -18,6 +18,8 @@
 package kv
 
 import (
+	"golang.org/x/time/rate"
+
 	"github.com/lindb/lindb/kv/table"
 	"github.com/lindb/lindb/kv/version"
 )
@@ -30,15 +32,36 @@ type compactionState struct {
 	snapshot          version.Snapshot
 	currentFileNumber table.FileNumber
 	maxFileSize       int32
+	limiter           *rate.Limiter          // throttles written bytes of compaction job, nil means no limit
+	mergerParams      map[string]interface{} // extra params for initializing merger
+
+	inputReaders map[table.FileNumber]table.Reader // readers of input files
+	corrupted    map[table.FileNumber]error        // corrupted input files found when merging
 }
 
 // newCompactionState creates a compaction state
 func newCompactionState(maxFileSize int32, snapshot version.Snapshot, compaction *version.Compaction) *compactionState {
 	return &compactionState{
-		maxFileSize: maxFileSize,
-		snapshot:    snapshot,
-		compaction:  compaction,
+		maxFileSize:  maxFileSize,
+		snapshot:     snapshot,
+		compaction:   compaction,
+		inputReaders: make(map[table.FileNumber]table.Reader),
+		corrupted:    make(map[table.FileNumber]error),
+	}
+}
+
+// checkCorruptedInputs records the corrupted input files which found corruption when reading value,
+// returns the first corruption error, nil if all input files are fine.
+func (c *compactionState) checkCorruptedInputs() (err error) {
+	for fileNumber, reader := range c.inputReaders {
+		if corruptedErr := reader.Corrupted(); corruptedErr != nil {
+			c.corrupted[fileNumber] = corruptedErr
+			if err == nil {
+				err = corruptedErr
+			}
+		}
 	}
+	return err
 }
 
 // addOutputFile adds a new output file
@@ -24,6 +24,13 @@ const RollupContext = "RollupContext"
 const defaultMaxFileSize = int32(256 * 1024 * 1024)
 const defaultCompactThreshold = 4
 const defaultRollupThreshold = 3
+const defaultTableCacheSize = int64(512 * 1024 * 1024)
+
+// quarantineDir is the dir under family path for saving corrupted files
+const quarantineDir = "quarantine"
+
+// verifyBytesPerSecond is the rate limit of reading files when verifying files in background
+const verifyBytesPerSecond = 32 * 1024 * 1024
 
 var defaultCompactCheckInterval = 60
 var kvLogger = logger.GetLogger("kv", "Store")
@@ -18,11 +18,14 @@
 package kv
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sync"
 
 	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
 
 	"github.com/lindb/lindb/kv/table"
 	"github.com/lindb/lindb/kv/version"
@@ -38,6 +41,9 @@ var (
 	removeDirFunc     = fileutil.RemoveDir
 )
 
+// ErrCompacting represents the compaction job of family is running
+var ErrCompacting = errors.New("family is compacting")
+
 // Family implements column family for data isolation each family.
 type Family interface {
 	// ID return family's id
@@ -48,6 +54,9 @@ type Family interface {
 	NewFlusher() Flusher
 	// GetSnapshot returns current version's snapshot
 	GetSnapshot() version.Snapshot
+	// DropFiles removes the files from current version, then deletes them as obsolete files,
+	// e.g. the files which are flushed but not confirmed by upper level before crash.
+	DropFiles(fileNumbers []table.FileNumber) error
 	// familyInfo return family info
 	familyInfo() string
 
@@ -57,9 +66,16 @@ type Family interface {
 	commitEditLog(editLog version.EditLog) bool
 	// newTableBuilder creates table builder instance for storing kv data.
 	newTableBuilder() (table.Builder, error)
-	// needCompat returns level0 files if need do compact job
-	needCompat() bool
-	// compact does compaction job
+	// NeedCompact checks if family need do compaction job(number of level0 files >= threshold)
+	NeedCompact() bool
+	// Compact does compaction job in current goroutine if hasn't compaction job running,
+	// the written bytes of compaction job are throttled by limiter(nil means no limit).
+	Compact(limiter *rate.Limiter) error
+	// FullCompact compacts all files of level0/level1 into level1 in current goroutine,
+	// the merger is initialized with params, so that merger can remove obsolete data.
+	// returns ErrCompacting if other compaction job is running.
+	FullCompact(params map[string]interface{}) error
+	// compact does compaction job in background goroutine
 	compact()
 	// getNewMerger returns new merger function, merger need implement Merger interface
 	getNewMerger() NewMerger
@@ -72,6 +88,11 @@ type Family interface {
 
 	// deleteObsoleteFiles deletes obsolete files
 	deleteObsoleteFiles()
+	// quarantineFile removes the corrupted file from family version, then moves it into quarantine dir
+	quarantineFile(fileNumber table.FileNumber, reason error)
+	// verifyFiles verifies the checksum of all live files, then quarantines the corrupted files,
+	// the read bytes are throttled by limiter(nil means no limit)
+	verifyFiles(ctx context.Context, limiter *rate.Limiter)
 }
 
 // family implements Family interface
@@ -85,6 +106,7 @@ type family struct {
 	maxFileSize   int32
 
 	pendingOutputs    sync.Map
+	quarantined       sync.Map // file numbers of quarantined files
 	newCompactJobFunc func(family Family, state *compactionState, rollup Rollup) CompactJob
 
 	rolluping  atomic.Bool
@@ -146,6 +168,50 @@ func (f *family) GetSnapshot() version.Snapshot {
 	return f.familyVersion.GetSnapshot()
 }
 
+// DropFiles removes the files from current version, then deletes them as obsolete files.
+func (f *family) DropFiles(fileNumbers []table.FileNumber) error {
+	if len(fileNumbers) == 0 {
+		return nil
+	}
+	if !f.removeFiles(fileNumbers) {
+		return fmt.Errorf("remove files from family[%s] failure", f.familyInfo())
+	}
+	kvLogger.Info("drop sst files successfully",
+		logger.String("family", f.familyInfo()), logger.Any("fileNumbers", fileNumbers))
+	f.deleteObsoleteFiles()
+	return nil
+}
+
+// removeFiles removes the files from each level and rollup files of current version,
+// returns false if commit edit log failure.
+func (f *family) removeFiles(fileNumbers []table.FileNumber) bool {
+	removed := make(map[table.FileNumber]struct{})
+	for _, fileNumber := range fileNumbers {
+		removed[fileNumber] = struct{}{}
+	}
+	snapshot := f.GetSnapshot()
+	current := snapshot.GetCurrent()
+	editLog := version.NewEditLog(f.ID())
+	for level := range current.Levels() {
+		for _, file := range current.GetFiles(level) {
+			if _, ok := removed[file.GetFileNumber()]; ok {
+				editLog.Add(version.NewDeleteFile(int32(level), file.GetFileNumber()))
+			}
+		}
+	}
+	for fileNumber := range current.GetRollupFiles() {
+		if _, ok := removed[fileNumber]; ok {
+			editLog.Add(version.CreateDeleteRollupFile(fileNumber))
+		}
+	}
+	snapshot.Close()
+
+	if editLog.IsEmpty() {
+		return true
+	}
+	return f.commitEditLog(editLog)
+}
+
 // familyInfo return family info
 func (f *family) familyInfo() string {
 	return f.familyPath
@@ -155,7 +221,7 @@ func (f *family) familyInfo() string {
 func (f *family) newTableBuilder() (table.Builder, error) {
 	fileNumber := f.store.nextFileNumber()
 	fileName := filepath.Join(f.familyPath, version.Table(fileNumber))
-	return table.NewStoreBuilder(fileNumber, fileName)
+	return table.NewStoreBuilder(fileNumber, fileName, f.store.getSyncPolicy())
 }
 
 // commitEditLog persists edit logs into manifest file.
@@ -172,8 +238,8 @@ func (f *family) commitEditLog(editLog version.EditLog) bool {
 	return true
 }
 
-// needCompat returns level0 files if need do compact job
-func (f *family) needCompat() bool {
+// NeedCompact checks if family need do compaction job(number of level0 files >= threshold)
+func (f *family) NeedCompact() bool {
 	// has compaction job doing
 	if f.compacting.Load() {
 		return false
@@ -195,13 +261,54 @@ func (f *family) needCompat() bool {
 	return false
 }
 
+// Compact does compaction job in current goroutine if hasn't compaction job running
+func (f *family) Compact(limiter *rate.Limiter) error {
+	if f.compacting.CAS(false, true) {
+		defer f.compacting.Store(false)
+
+		return f.backgroundCompactionJob(limiter)
+	}
+	return nil
+}
+
+// FullCompact compacts all files of level0/level1 into level1 in current goroutine,
+// the merger is initialized with params, so that merger can remove obsolete data.
+// returns ErrCompacting if other compaction job is running.
+func (f *family) FullCompact(params map[string]interface{}) error {
+	if !f.compacting.CAS(false, true) {
+		return ErrCompacting
+	}
+	defer f.compacting.Store(false)
+
+	snapshot := f.GetSnapshot()
+	var compactionState *compactionState
+	defer func() {
+		snapshot.Close()
+		// quarantine corrupted input files after releasing snapshot
+		quarantineCorruptedInputs(f, compactionState)
+		// clean up unused files, maybe some file not used
+		f.deleteObsoleteFiles()
+	}()
+
+	compaction := snapshot.GetCurrent().PickFullCompaction()
+	if compaction == nil {
+		// no data
+		return nil
+	}
+	kvLogger.Info("starting full compaction job", logger.String("family", f.familyInfo()))
+	compactionState = newCompactionState(f.maxFileSize, snapshot, compaction)
+	compactionState.mergerParams = f.mergerParams(params)
+	compactJob := f.newCompactJobFunc(f, compactionState, nil)
+	return compactJob.Run()
+}
+
 // compact does compact job if hasn't compact job running
 func (f *family) compact() {
 	if f.compacting.CAS(false, true) {
 		go func() {
 			defer f.compacting.Store(false)
 
-			if err := f.backgroundCompactionJob(); err != nil {
+			if err := f.backgroundCompactionJob(nil); err != nil {
 				kvLogger.Error("do compact job error",
 					logger.String("family", f.familyInfo()), logger.Error(err), logger.Stack())
 			}
@@ -209,11 +316,14 @@ func (f *family) compact() {
 	}
 }
 
-// backgroundCompactionJob runs compact job in background goroutine
-func (f *family) backgroundCompactionJob() error {
+// backgroundCompactionJob runs compact job, throttles written bytes if limiter not nil
+func (f *family) backgroundCompactionJob(limiter *rate.Limiter) error {
 	snapshot := f.GetSnapshot()
+	var compactionState *compactionState
 	defer func() {
 		snapshot.Close()
+		// quarantine corrupted input files after releasing snapshot
+		quarantineCorruptedInputs(f, compactionState)
 		// clean up unused files, maybe some file not used
 		f.deleteObsoleteFiles()
 	}()
@@ -223,7 +333,9 @@ func (f *family) backgroundCompactionJob() error {
 		// no compaction job need to do
 		return nil
 	}
-	compactionState := newCompactionState(f.maxFileSize, snapshot, compaction)
+	compactionState = newCompactionState(f.maxFileSize, snapshot, compaction)
+	compactionState.limiter = limiter
+	compactionState.mergerParams = f.mergerParams(nil)
 	compactJob := f.newCompactJobFunc(f, compactionState, nil)
 	if err := compactJob.Run(); err != nil {
 		return err
@@ -254,6 +366,21 @@ func (f *family) getFamilyVersion() version.FamilyVersion {
 	return f.familyVersion
 }
 
+// mergerParams returns the params for initializing merger, includes the merger params of family option.
+func (f *family) mergerParams(params map[string]interface{}) map[string]interface{} {
+	if len(f.option.MergerParams) == 0 {
+		return params
+	}
+	result := make(map[string]interface{})
+	for k, v := range f.option.MergerParams {
+		result[k] = v
+	}
+	for k, v := range params {
+		result[k] = v
+	}
+	return result
+}
+
 // getNewMerger returns new merger function, merger need implement Merger interface
 func (f *family) getNewMerger() NewMerger {
 	return f.merger
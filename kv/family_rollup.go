@@ -147,12 +147,16 @@ func (f *family) doRollupWork(sourceFamily Family, rollup Rollup, sourceFiles []
 	}
 
 	snapshot := sourceFamily.GetSnapshot()
+	var compactionState *compactionState
 	defer func() {
 		snapshot.Close()
+		// quarantine corrupted source files after releasing snapshot
+		quarantineCorruptedInputs(sourceFamily, compactionState)
 	}()
 	compaction := version.NewCompaction(f.ID(), -1, nil, nil)
 
-	compactionState := newCompactionState(f.maxFileSize, snapshot, compaction)
+	compactionState = newCompactionState(f.maxFileSize, snapshot, compaction)
+	compactionState.mergerParams = f.mergerParams(nil)
 	compactJob := newCompactJobFunc(f, compactionState, rollup)
 	if err := compactJob.Run(); err != nil {
 		return err
@@ -19,11 +19,13 @@ package kv
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 
 	"github.com/lindb/lindb/kv/table"
 	"github.com/lindb/lindb/kv/version"
@@ -148,15 +150,15 @@ func TestFamily_needCompact(t *testing.T) {
 	assert.NoError(t, err)
 	// case 1: empty family
 	v.EXPECT().NumberOfFilesInLevel(gomock.Any()).Return(0)
-	assert.False(t, f.needCompat())
+	assert.False(t, f.NeedCompact())
 	// case 2: compacting
 	f2 := f.(*family)
 	f2.compacting.Store(true)
-	assert.False(t, f.needCompat())
+	assert.False(t, f.NeedCompact())
 	f2.compacting.Store(false)
 	// case 3: need compact
 	v.EXPECT().NumberOfFilesInLevel(gomock.Any()).Return(10)
-	assert.True(t, f.needCompat())
+	assert.True(t, f.NeedCompact())
 }
 
 func TestFamily_compact(t *testing.T) {
@@ -225,12 +227,80 @@ func TestFamily_compact_background(t *testing.T) {
 		return compactJob
 	}
 	compactJob.EXPECT().Run().Return(fmt.Errorf("err"))
-	err = f2.backgroundCompactionJob()
+	err = f2.backgroundCompactionJob(nil)
 	assert.Error(t, err)
 	// case 3: compact job run success
 	compactJob.EXPECT().Run().Return(nil)
-	err = f2.backgroundCompactionJob()
+	err = f2.backgroundCompactionJob(nil)
 	assert.NoError(t, err)
+	// case 4: compact in current goroutine with limiter
+	limiter := rate.NewLimiter(rate.Limit(100), 100)
+	f2.newCompactJobFunc = func(family Family, state *compactionState, rollup Rollup) CompactJob {
+		assert.Equal(t, limiter, state.limiter)
+		return compactJob
+	}
+	compactJob.EXPECT().Run().Return(fmt.Errorf("err"))
+	err = f.Compact(limiter)
+	assert.Error(t, err)
+	// case 5: family is compacting
+	f2.compacting.Store(true)
+	err = f.Compact(limiter)
+	assert.NoError(t, err)
+}
+
+func TestFamily_FullCompact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testKVPath)
+		ctrl.Finish()
+	}()
+	store := NewMockStore(ctrl)
+	store.EXPECT().Option().Return(DefaultStoreOption(testKVPath)).AnyTimes()
+	fv := version.NewMockFamilyVersion(ctrl)
+	snapshot := version.NewMockSnapshot(ctrl)
+	v := version.NewMockVersion(ctrl)
+	snapshot.EXPECT().Close().AnyTimes()
+	snapshot.EXPECT().GetCurrent().Return(v).AnyTimes()
+	fv.EXPECT().GetSnapshot().Return(snapshot).AnyTimes()
+	store.EXPECT().createFamilyVersion(gomock.Any(), gomock.Any()).Return(fv)
+	f, err := newFamily(store, FamilyOption{Merger: "mockMerger"})
+	assert.NoError(t, err)
+	fv.EXPECT().GetAllActiveFiles().Return(nil).AnyTimes()
+	fv.EXPECT().GetLiveRollupFiles().Return(nil).AnyTimes()
+	params := map[string]interface{}{"key": "value"}
+	f1 := f.(*family)
+	compactJob := NewMockCompactJob(ctrl)
+	f1.newCompactJobFunc = func(family Family, state *compactionState, rollup Rollup) CompactJob {
+		assert.Equal(t, params, state.mergerParams)
+		assert.Nil(t, rollup)
+		return compactJob
+	}
+	// case 1: no files
+	v.EXPECT().PickFullCompaction().Return(nil)
+	assert.NoError(t, f.FullCompact(params))
+	// case 2: compact job run err
+	v.EXPECT().PickFullCompaction().Return(version.NewCompaction(1, 0, nil, nil)).AnyTimes()
+	compactJob.EXPECT().Run().Return(fmt.Errorf("err"))
+	assert.Error(t, f.FullCompact(params))
+	// case 3: compact job run success
+	compactJob.EXPECT().Run().Return(nil)
+	assert.NoError(t, f.FullCompact(params))
+	assert.False(t, f1.compacting.Load())
+	// case 4: family is compacting
+	f1.compacting.Store(true)
+	assert.Equal(t, ErrCompacting, f.FullCompact(params))
+}
+
+func TestFamily_mergerParams(t *testing.T) {
+	f := &family{option: FamilyOption{}}
+	params := map[string]interface{}{"key": "value"}
+	assert.Equal(t, params, f.mergerParams(params))
+	assert.Nil(t, f.mergerParams(nil))
+
+	f.option.MergerParams = map[string]string{"codec": "zstd", "key": "option"}
+	assert.Equal(t, map[string]interface{}{"codec": "lz4", "key": "option"}, f.mergerParams(map[string]interface{}{"codec": "lz4"}))
+	assert.Equal(t, map[string]interface{}{"codec": "zstd", "key": "value"}, f.mergerParams(params))
+	assert.Equal(t, map[string]interface{}{"codec": "zstd", "key": "option"}, f.mergerParams(nil))
 }
 
 func TestFamily_deleteObsoleteFiles(t *testing.T) {
@@ -276,3 +346,60 @@ func TestFamily_deleteObsoleteFiles(t *testing.T) {
 	}
 	f1.deleteObsoleteFiles()
 }
+
+func TestFamily_DropFiles(t *testing.T) {
+	option := DefaultStoreOption(testKVPath)
+	option.DisableAutoCompact = true
+	defer func() {
+		_ = fileutil.RemoveDir(testKVPath)
+	}()
+	kv, err := NewStore("test_kv", option)
+	assert.NoError(t, err)
+	defer func() {
+		_ = kv.Close()
+	}()
+	f, err := kv.CreateFamily("f", FamilyOption{Merger: "mockMerger"})
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		flusher := f.NewFlusher()
+		_ = flusher.Add(1, []byte("test"))
+		assert.NoError(t, flusher.Commit())
+	}
+	snapshot := f.GetSnapshot()
+	files := snapshot.GetCurrent().GetFiles(0)
+	snapshot.Close()
+	assert.Len(t, files, 2)
+	// case 1: drop nothing
+	assert.NoError(t, f.DropFiles(nil))
+	// case 2: drop file not exist
+	assert.NoError(t, f.DropFiles([]table.FileNumber{1000}))
+	// case 3: drop file
+	assert.NoError(t, f.DropFiles([]table.FileNumber{files[0].GetFileNumber()}))
+	snapshot = f.GetSnapshot()
+	assert.Len(t, snapshot.GetCurrent().GetFiles(0), 1)
+	snapshot.Close()
+	assert.False(t, fileutil.Exist(filepath.Join(testKVPath, "f", version.Table(files[0].GetFileNumber()))))
+}
+
+func TestFamily_DropFiles_Err(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testKVPath)
+		ctrl.Finish()
+	}()
+	store := NewMockStore(ctrl)
+	store.EXPECT().Option().Return(DefaultStoreOption(testKVPath)).AnyTimes()
+	fv := version.NewMockFamilyVersion(ctrl)
+	store.EXPECT().createFamilyVersion(gomock.Any(), gomock.Any()).Return(fv)
+	f, err := newFamily(store, FamilyOption{Merger: "mockMerger"})
+	assert.NoError(t, err)
+	snapshot := version.NewMockSnapshot(ctrl)
+	v := version.NewMockVersion(ctrl)
+	fv.EXPECT().GetSnapshot().Return(snapshot)
+	snapshot.EXPECT().GetCurrent().Return(v)
+	snapshot.EXPECT().Close()
+	v.EXPECT().Levels().Return(nil)
+	v.EXPECT().GetRollupFiles().Return(map[table.FileNumber]timeutil.Interval{10: timeutil.Interval(10)})
+	store.EXPECT().commitFamilyEditLog(gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, f.DropFiles([]table.FileNumber{10}))
+}
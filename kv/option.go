@@ -17,6 +17,8 @@
 
 package kv
 
+import "github.com/lindb/lindb/pkg/bufioutil"
+
 // FamilyOption defines config items for family level
 type FamilyOption struct {
 	ID               int    `toml:"id"`
@@ -25,6 +27,8 @@ type FamilyOption struct {
 	RollupThreshold  int    `toml:"rollupThreshold"`  // level 0 rollup threshold
 	Merger           string `toml:"merger"`           // merger which need implement Merger interface
 	MaxFileSize      int32  `toml:"maxFileSize"`      // max file size
+	// extra params for initializing merger when compacting/rolling up
+	MergerParams map[string]string `toml:"mergerParams"`
 }
 
 // StoreOption defines config item for store level
@@ -33,13 +37,19 @@ type StoreOption struct {
 	Levels               int    `toml:"levels"`               // num. of levels
 	CompactCheckInterval int    `toml:"compactCheckInterval"` // compact job check interval(number of seconds)
 	RollupCheckInterval  int    `toml:"rollupCheckInterval"`  // rollup job check interval(number of seconds)
+	TableCacheSize       int64  `toml:"tableCacheSize"`       // max size of mapped table files(number of bytes)
+	// disable compaction job scheduled by store, compaction job need be triggered by Family.Compact
+	DisableAutoCompact bool `toml:"-"`
+	// fsync policy of table files written by flush/compaction, can be changed by Store.SetSyncPolicy
+	SyncPolicy bufioutil.SyncPolicy `toml:"-"`
 }
 
 // DefaultStoreOption builds default store option
 func DefaultStoreOption(path string) StoreOption {
 	return StoreOption{
-		Path:   path,
-		Levels: 2,
+		Path:           path,
+		Levels:         2,
+		TableCacheSize: defaultTableCacheSize,
 	}
 }
 
@@ -0,0 +1,189 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/time/rate"
+
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/kv/table"
+	"github.com/lindb/lindb/kv/version"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+// for testing
+var (
+	renameFunc = os.Rename
+)
+
+var (
+	quarantineScope           = linmetric.NewScope("lindb.kv.quarantine")
+	quarantinedFilesCounter   = quarantineScope.NewDeltaCounter("quarantined_files")
+	quarantineFailuresCounter = quarantineScope.NewDeltaCounter("quarantine_failures")
+)
+
+// quarantineFamilyFile quarantines the corrupted file of family, invoked by table cache.
+func (s *store) quarantineFamilyFile(familyName string, fileName string, reason error) {
+	fileDesc := version.ParseFileName(fileName)
+	if fileDesc == nil || fileDesc.FileType != version.TypeTable {
+		return
+	}
+	family := s.GetFamily(familyName)
+	if family == nil {
+		kvLogger.Warn("cannot find family when quarantine corrupted file",
+			logger.String("store", s.option.Path), logger.String("family", familyName),
+			logger.String("file", fileName))
+		return
+	}
+	family.quarantineFile(fileDesc.FileNumber, reason)
+}
+
+// verifyFamilyFilesInBackground verifies the all live files of all families in background goroutine
+// when open kv store, the read bytes are throttled for avoiding impacting write/query.
+func (s *store) verifyFamilyFilesInBackground() {
+	limiter := rate.NewLimiter(rate.Limit(verifyBytesPerSecond), verifyBytesPerSecond)
+	s.verifyWG.Add(1)
+	go func() {
+		defer s.verifyWG.Done()
+		s.verifyFamilyFiles(s.ctx, limiter)
+	}()
+}
+
+// verifyFamilyFiles verifies the all live files of all families, the corrupted files will be quarantined.
+func (s *store) verifyFamilyFiles(ctx context.Context, limiter *rate.Limiter) {
+	s.rwMutex.RLock()
+	families := make([]Family, 0, len(s.families))
+	for _, family := range s.families {
+		families = append(families, family)
+	}
+	s.rwMutex.RUnlock()
+
+	for _, family := range families {
+		if ctx.Err() != nil {
+			// store closed
+			return
+		}
+		family.verifyFiles(ctx, limiter)
+	}
+}
+
+// quarantineCorruptedInputs quarantines the corrupted input files found by compaction job,
+// must be invoked after closing the snapshot of compaction.
+func quarantineCorruptedInputs(family Family, state *compactionState) {
+	if state == nil {
+		return
+	}
+	for fileNumber, err := range state.corrupted {
+		family.quarantineFile(fileNumber, err)
+	}
+}
+
+// quarantineFile removes the corrupted file from family version, then moves the file into quarantine dir,
+// so that query/compaction don't read the broken data, the data need be repaired from other replica.
+func (f *family) quarantineFile(fileNumber table.FileNumber, reason error) {
+	if _, loaded := f.quarantined.LoadOrStore(fileNumber, dummy); loaded {
+		// file is quarantining or quarantined
+		return
+	}
+	kvLogger.Error("quarantine corrupted sst file, need repair data from replica",
+		logger.String("family", f.familyInfo()), logger.Any("fileNumber", fileNumber), logger.Error(reason))
+
+	if !f.removeFiles([]table.FileNumber{fileNumber}) {
+		quarantineFailuresCounter.Incr()
+		// quarantine again when found corruption next time
+		f.quarantined.Delete(fileNumber)
+		return
+	}
+	f.store.evictFamilyFile(f.name, fileNumber)
+
+	if err := f.moveToQuarantine(fileNumber); err != nil {
+		quarantineFailuresCounter.Incr()
+		kvLogger.Error("move corrupted sst file to quarantine dir failure",
+			logger.String("family", f.familyInfo()), logger.Any("fileNumber", fileNumber), logger.Error(err))
+		return
+	}
+	quarantinedFilesCounter.Incr()
+}
+
+// moveToQuarantine moves the file into quarantine dir of family.
+func (f *family) moveToQuarantine(fileNumber table.FileNumber) error {
+	quarantinePath := filepath.Join(f.familyPath, quarantineDir)
+	if err := mkDirFunc(quarantinePath); err != nil {
+		return err
+	}
+	fileName := version.Table(fileNumber)
+	return renameFunc(filepath.Join(f.familyPath, fileName), filepath.Join(quarantinePath, fileName))
+}
+
+// verifyFiles verifies the checksum of all live files, then quarantines the corrupted files,
+// the read bytes are throttled by limiter(nil means no limit).
+func (f *family) verifyFiles(ctx context.Context, limiter *rate.Limiter) {
+	snapshot := f.GetSnapshot()
+	var corrupted = make(map[table.FileNumber]error)
+	for _, file := range snapshot.GetCurrent().GetAllFiles() {
+		if err := waitBytes(ctx, limiter, int(file.GetFileSize())); err != nil {
+			// store closed, stop verifying
+			break
+		}
+		fileNumber := file.GetFileNumber()
+		reader, err := snapshot.GetReader(fileNumber)
+		if err != nil {
+			if errors.Is(err, table.ErrCorrupted) {
+				// corrupted file is quarantined by corruption handler of table cache
+				continue
+			}
+			kvLogger.Warn("open sst file failure when verify family files",
+				logger.String("family", f.familyInfo()), logger.Any("fileNumber", fileNumber), logger.Error(err))
+			continue
+		}
+		if err := reader.Verify(); err != nil {
+			corrupted[fileNumber] = err
+		}
+	}
+	// close snapshot before quarantine, release the readers of corrupted files
+	snapshot.Close()
+
+	for fileNumber, err := range corrupted {
+		f.quarantineFile(fileNumber, err)
+	}
+}
+
+// waitBytes waits until n bytes can be read/written if limiter not nil,
+// splits n by the burst of limiter, because limiter cannot wait n bytes which > burst.
+func waitBytes(ctx context.Context, limiter *rate.Limiter, n int) error {
+	if limiter == nil || limiter.Burst() <= 0 {
+		return ctx.Err()
+	}
+	burst := limiter.Burst()
+	for n > 0 {
+		size := n
+		if size > burst {
+			size = burst
+		}
+		if err := limiter.WaitN(ctx, size); err != nil {
+			return err
+		}
+		n -= size
+	}
+	return nil
+}
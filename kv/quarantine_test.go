@@ -0,0 +1,144 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/kv/table"
+	"github.com/lindb/lindb/kv/version"
+	"github.com/lindb/lindb/pkg/fileutil"
+)
+
+func TestStore_quarantine_corrupted_file_when_open(t *testing.T) {
+	defer func() {
+		_ = fileutil.RemoveDir(testKVPath)
+	}()
+	option := DefaultStoreOption(testKVPath)
+	option.DisableAutoCompact = true
+	files := prepareQuarantineStore(t, option)
+	// value of first file corrupted, index block of second file corrupted
+	corruptFile(t, files[0], 1)
+	corruptFile(t, files[1], -20)
+
+	kv, err := NewStore("test_kv", option)
+	assert.NoError(t, err)
+	defer func() {
+		_ = kv.Close()
+	}()
+	// wait background verify job completed
+	kv.(*store).verifyWG.Wait()
+	f := kv.GetFamily("f")
+	snapshot := f.GetSnapshot()
+	liveFiles := snapshot.GetCurrent().GetAllFiles()
+	snapshot.Close()
+	assert.Len(t, liveFiles, 1)
+	for _, file := range files[:2] {
+		assert.False(t, fileutil.Exist(file))
+		assert.True(t, fileutil.Exist(filepath.Join(testKVPath, "f", quarantineDir, filepath.Base(file))))
+	}
+	assert.True(t, fileutil.Exist(files[2]))
+	// obsolete files deletion ignores quarantine dir
+	f.deleteObsoleteFiles()
+	assert.True(t, fileutil.Exist(filepath.Join(testKVPath, "f", quarantineDir, filepath.Base(files[0]))))
+}
+
+func TestFamily_quarantineFile(t *testing.T) {
+	defer func() {
+		renameFunc = os.Rename
+		_ = fileutil.RemoveDir(testKVPath)
+	}()
+	option := DefaultStoreOption(testKVPath)
+	option.DisableAutoCompact = true
+	files := prepareQuarantineStore(t, option)
+	kv, err := NewStore("test_kv", option)
+	assert.NoError(t, err)
+	defer func() {
+		_ = kv.Close()
+	}()
+	f := kv.GetFamily("f")
+	fileNumber := func(path string) table.FileNumber {
+		return version.ParseFileName(filepath.Base(path)).FileNumber
+	}
+	// case 1: move file err
+	renameFunc = func(oldpath, newpath string) error {
+		return fmt.Errorf("err")
+	}
+	f.quarantineFile(fileNumber(files[0]), table.ErrCorrupted)
+	assert.True(t, fileutil.Exist(files[0]))
+	renameFunc = os.Rename
+	// case 2: quarantine file success
+	f.quarantineFile(fileNumber(files[1]), table.ErrCorrupted)
+	assert.False(t, fileutil.Exist(files[1]))
+	// case 3: quarantine same file again, ignore it
+	f.quarantineFile(fileNumber(files[1]), table.ErrCorrupted)
+	snapshot := f.GetSnapshot()
+	assert.Len(t, snapshot.GetCurrent().GetAllFiles(), 1)
+	snapshot.Close()
+	// case 4: corruption found by cache, family not exist/file name invalid
+	kvStore := kv.(*store)
+	kvStore.quarantineFamilyFile("not_exist", version.Table(fileNumber(files[2])), table.ErrCorrupted)
+	kvStore.quarantineFamilyFile("f", "OPTIONS", table.ErrCorrupted)
+	assert.True(t, fileutil.Exist(files[2]))
+	// case 5: corruption found by cache
+	kvStore.quarantineFamilyFile("f", version.Table(fileNumber(files[2])), table.ErrCorrupted)
+	assert.False(t, fileutil.Exist(files[2]))
+	snapshot = f.GetSnapshot()
+	assert.Empty(t, snapshot.GetCurrent().GetAllFiles())
+	snapshot.Close()
+}
+
+// prepareQuarantineStore creates store with 3 files in family, returns the path of files.
+func prepareQuarantineStore(t *testing.T, option StoreOption) []string {
+	kv, err := NewStore("test_kv", option)
+	assert.NoError(t, err)
+	f, err := kv.CreateFamily("f", FamilyOption{Merger: "mockMerger"})
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		flusher := f.NewFlusher()
+		_ = flusher.Add(1, []byte("test"))
+		_ = flusher.Add(10, []byte("test10"))
+		assert.NoError(t, flusher.Commit())
+	}
+	snapshot := f.GetSnapshot()
+	var files []string
+	for _, file := range snapshot.GetCurrent().GetAllFiles() {
+		files = append(files, filepath.Join(testKVPath, "f", version.Table(file.GetFileNumber())))
+	}
+	snapshot.Close()
+	assert.NoError(t, kv.Close())
+	assert.Len(t, files, 3)
+	return files
+}
+
+// corruptFile flips the byte of file at pos, negative pos means position from the end of file.
+func corruptFile(t *testing.T, path string, pos int) {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	if pos < 0 {
+		pos += len(data)
+	}
+	data[pos] ^= 0xff
+	assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+}
@@ -24,10 +24,12 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lindb/lindb/kv/table"
 	"github.com/lindb/lindb/kv/version"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/lockers"
 	"github.com/lindb/lindb/pkg/logger"
@@ -62,6 +64,8 @@ type Store interface {
 	Option() StoreOption
 	// RegisterRollup registers the rollup source/target relation
 	RegisterRollup(interval timeutil.Interval, rollup Rollup)
+	// SetSyncPolicy sets the fsync policy of table files written afterwards
+	SetSyncPolicy(policy bufioutil.SyncPolicy)
 	// Close closes store, then release some resource
 	Close() error
 
@@ -76,6 +80,8 @@ type Store interface {
 	evictFamilyFile(name string, fileNumber table.FileNumber)
 	// getRollup returns the rollup relation by interval
 	getRollup(interval timeutil.Interval) (Rollup, bool)
+	// getSyncPolicy returns the fsync policy of table files
+	getSyncPolicy() bufioutil.SyncPolicy
 }
 
 // store implements Store interface
@@ -95,9 +101,11 @@ type store struct {
 	cache     table.Cache
 
 	rollupRelations map[timeutil.Interval]Rollup // save target kv store for rollup job
+	syncPolicy      atomic.Value                 // fsync policy of table files
 
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx      context.Context
+	cancel   context.CancelFunc
+	verifyWG sync.WaitGroup // waits background verify job completed when closing store
 }
 
 // NewStore new store instance, need recover data if store existent
@@ -135,6 +143,7 @@ func NewStore(name string, option StoreOption) (s Store, err error) {
 		ctx:       ctx,
 		cancel:    cancel,
 	}
+	store1.syncPolicy.Store(option.SyncPolicy)
 
 	defer func() {
 		if err != nil {
@@ -151,7 +160,8 @@ func NewStore(name string, option StoreOption) (s Store, err error) {
 	}()
 
 	// build store reader cache
-	store1.cache = table.NewCache(store1.option.Path)
+	store1.cache = table.NewCache(store1.option.Path, store1.option.TableCacheSize)
+	store1.cache.SetCorruptionHandler(store1.quarantineFamilyFile)
 	// init version set
 	store1.versions = newVersionSetFunc(store1.option.Path, store1.cache, store1.option.Levels)
 
@@ -178,9 +188,13 @@ func NewStore(name string, option StoreOption) (s Store, err error) {
 	if err = store1.versions.Recover(); err != nil {
 		return nil, fmt.Errorf("recover store version set error:%s", err)
 	}
+	// verify live files in background, quarantine the corrupted files(e.g. torn write after power loss)
+	store1.verifyFamilyFilesInBackground()
 
-	// schedule compact job
-	store1.scheduleCompactJob()
+	if !option.DisableAutoCompact {
+		// schedule compact job
+		store1.scheduleCompactJob()
+	}
 	return store1, nil
 }
 
@@ -242,7 +256,19 @@ func (s *store) ListFamilyNames() []string {
 
 // Option returns the store configuration options
 func (s *store) Option() StoreOption {
-	return s.option
+	option := s.option
+	option.SyncPolicy = s.getSyncPolicy()
+	return option
+}
+
+// SetSyncPolicy sets the fsync policy of table files written afterwards.
+func (s *store) SetSyncPolicy(policy bufioutil.SyncPolicy) {
+	s.syncPolicy.Store(policy)
+}
+
+// getSyncPolicy returns the fsync policy of table files.
+func (s *store) getSyncPolicy() bufioutil.SyncPolicy {
+	return s.syncPolicy.Load().(bufioutil.SyncPolicy)
 }
 
 // RegisterRollup registers the rollup source/target relation
@@ -266,6 +292,9 @@ func (s *store) RegisterRollup(interval timeutil.Interval, rollup Rollup) {
 // Close closes store, then release some resource
 func (s *store) Close() error {
 	//FIXME stone1100 need if has background job doing(family compact/flush etc.)
+	// stop background verify job before closing cache, because it reads the files
+	s.cancel()
+	s.verifyWG.Wait()
 	if err := s.cache.Close(); err != nil {
 		kvLogger.Error("close store cache error", logger.String("store", s.option.Path), logger.Error(err))
 	}
@@ -273,7 +302,6 @@ func (s *store) Close() error {
 		kvLogger.Error("destroy store version set error",
 			logger.String("store", s.option.Path), logger.Error(err))
 	}
-	s.cancel()
 	return s.lock.Unlock()
 }
 
@@ -345,7 +373,7 @@ func (s *store) compact() {
 	}
 	s.rwMutex.RUnlock()
 	for _, family := range families {
-		if family.needCompat() {
+		if family.NeedCompact() {
 			family.compact()
 		}
 	}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/lindb/lindb/kv/table"
 	"github.com/lindb/lindb/kv/version"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/lockers"
 	"github.com/lindb/lindb/pkg/ltoml"
@@ -296,3 +297,25 @@ func TestStore_RegisterRollup(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, rollup, rollup2)
 }
+
+func TestStore_SetSyncPolicy(t *testing.T) {
+	option := DefaultStoreOption(testKVPath)
+	option.SyncPolicy = bufioutil.SyncPolicy{Mode: bufioutil.SyncNever}
+	defer func() {
+		_ = fileutil.RemoveDir(testKVPath)
+	}()
+
+	kv, err := NewStore("test_kv", option)
+	assert.NoError(t, err)
+	assert.Equal(t, bufioutil.SyncNever, kv.getSyncPolicy().Mode)
+	kv.SetSyncPolicy(bufioutil.SyncPolicy{Mode: bufioutil.SyncBytes, Bytes: 1024})
+	assert.Equal(t, bufioutil.SyncPolicy{Mode: bufioutil.SyncBytes, Bytes: 1024}, kv.getSyncPolicy())
+	// new table builder uses sync policy of store
+	f, err := kv.CreateFamily("f", FamilyOption{Merger: mergerStr})
+	assert.NoError(t, err)
+	builder, err := f.newTableBuilder()
+	assert.NoError(t, err)
+	assert.NoError(t, builder.Add(1, []byte("test")))
+	assert.NoError(t, builder.Close())
+	assert.NoError(t, kv.Close())
+}
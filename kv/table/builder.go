@@ -20,6 +20,7 @@ package table
 import (
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 
 	"github.com/lindb/roaring"
 
@@ -32,7 +33,7 @@ import (
 
 // for testing
 var (
-	newBufioWriterFunc = bufioutil.NewBufioWriter
+	newBufioWriterFunc = bufioutil.NewSyncBufioWriter
 )
 
 // FileNumber represents sst file number
@@ -70,6 +71,7 @@ type storeBuilder struct {
 	fileName   string
 	writer     bufioutil.BufioWriter
 	offset     *encoding.FixedOffsetEncoder
+	checksums  []byte // crc32 checksum of each value
 
 	// see paper of roaring bitmap: https://arxiv.org/pdf/1603.06549.pdf
 	keys   *roaring.Bitmap
@@ -79,9 +81,10 @@ type storeBuilder struct {
 	first bool
 }
 
-// NewStoreBuilder creates store builder instance for building store file
-func NewStoreBuilder(fileNumber FileNumber, fileName string) (Builder, error) {
-	writer, err := newBufioWriterFunc(fileName)
+// NewStoreBuilder creates store builder instance for building store file,
+// written data is synced to disk based on sync policy.
+func NewStoreBuilder(fileNumber FileNumber, fileName string, syncPolicy bufioutil.SyncPolicy) (Builder, error) {
+	writer, err := newBufioWriterFunc(fileName, bufioutil.NewSyncer(syncKindTable, syncPolicy))
 	if err != nil {
 		return nil, fmt.Errorf("create file write for store builder error:%s", err)
 	}
@@ -116,6 +119,10 @@ func (b *storeBuilder) Add(key uint32, value []byte) error {
 	}
 	// add offset into offset buffer
 	b.offset.Add(int(offset))
+	// add checksum of value into checksum block
+	var checksum [checksumSize]byte
+	binary.LittleEndian.PutUint32(checksum[:], crc32.Checksum(value, crcTable))
+	b.checksums = append(b.checksums, checksum[:]...)
 	// add key into index block
 	b.keys.Add(key)
 
@@ -175,12 +182,22 @@ func (b *storeBuilder) Close() error {
 		return err
 	}
 
-	// for file footer for offsets/keys index, length=1+4+4+8
-	var buf [17]byte
+	posOfChecksums := b.writer.Size()
+	if _, err = b.writer.Write(b.checksums); err != nil {
+		return err
+	}
+
+	// checksum of index block(offsets/keys/checksums), verified when open file
+	indexChecksum := crc32.Update(crc32.Checksum(offset, crcTable), crcTable, keys)
+	indexChecksum = crc32.Update(indexChecksum, crcTable, b.checksums)
+	// for file footer for offsets/keys/checksums index, length=4+4+4+4+1+8
+	var buf [sstFileFooterSizeV1 - 1]byte
 	binary.LittleEndian.PutUint32(buf[:4], uint32(posOfOffset))
 	binary.LittleEndian.PutUint32(buf[4:8], uint32(posOfKeys))
-	buf[8] = version0
-	binary.LittleEndian.PutUint64(buf[9:], magicNumberOffsetFile)
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(posOfChecksums))
+	binary.LittleEndian.PutUint32(buf[12:16], indexChecksum)
+	buf[16] = version1
+	binary.LittleEndian.PutUint64(buf[17:], magicNumberOffsetFile)
 	if _, err = b.writer.Write(buf[:]); err != nil {
 		return err
 	}
@@ -50,7 +50,7 @@ func TestStoreBuilder_magicNumber(t *testing.T) {
 
 func TestStoreBuilder_BuildStore(t *testing.T) {
 	_ = fileutil.MkDirIfNotExist(testKVPath)
-	var builder, err = NewStoreBuilder(10, testKVPath+"/000010.sst")
+	var builder, err = NewStoreBuilder(10, testKVPath+"/000010.sst", bufioutil.DefaultSyncPolicy)
 	defer func() {
 		_ = os.RemoveAll(testKVPath)
 		_ = builder.Close()
@@ -79,16 +79,16 @@ func TestStoreBuilder_Build_Err(t *testing.T) {
 	_ = fileutil.MkDirIfNotExist(testKVPath)
 	ctrl := gomock.NewController(t)
 	defer func() {
-		newBufioWriterFunc = bufioutil.NewBufioWriter
+		newBufioWriterFunc = bufioutil.NewSyncBufioWriter
 		encoding.BitmapMarshal = bitmapMarshal
 		_ = os.Remove(testKVPath)
 		ctrl.Finish()
 	}()
 	writer := bufioutil.NewMockBufioWriter(ctrl)
-	newBufioWriterFunc = func(fileName string) (bufioutil.BufioWriter, error) {
+	newBufioWriterFunc = func(fileName string, syncer *bufioutil.Syncer) (bufioutil.BufioWriter, error) {
 		return writer, nil
 	}
-	builder, err := NewStoreBuilder(10, testKVPath+"/000200.sst")
+	builder, err := NewStoreBuilder(10, testKVPath+"/000200.sst", bufioutil.DefaultSyncPolicy)
 	assert.NoError(t, err)
 	writer.EXPECT().Size().Return(int64(10)).AnyTimes()
 
@@ -125,10 +125,10 @@ func TestStoreBuilder_Build_Err(t *testing.T) {
 	err = builder.Close()
 	assert.Error(t, err)
 	// case 6: new builder err
-	newBufioWriterFunc = func(fileName string) (bufioutil.BufioWriter, error) {
+	newBufioWriterFunc = func(fileName string, syncer *bufioutil.Syncer) (bufioutil.BufioWriter, error) {
 		return nil, fmt.Errorf("err")
 	}
-	builder, err = NewStoreBuilder(10, testKVPath+"/000200.sst")
+	builder, err = NewStoreBuilder(10, testKVPath+"/000200.sst", bufioutil.DefaultSyncPolicy)
 	assert.Error(t, err)
 	assert.Nil(t, builder)
 }
@@ -138,7 +138,7 @@ func TestStoreBuilder_Abandon(t *testing.T) {
 	defer func() {
 		_ = os.RemoveAll(testKVPath)
 	}()
-	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst")
+	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst", bufioutil.DefaultSyncPolicy)
 	assert.NoError(t, err)
 	_ = builder.Add(1, []byte("test"))
 	err = builder.Abandon()
@@ -18,14 +18,15 @@
 package table
 
 import (
+	"container/list"
+	"errors"
 	"path/filepath"
 	"sync"
 
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/pkg/logger"
 )
 
-//FIXME store100 using lru cache?????
-
 //go:generate mockgen -source ./cache.go -destination=./cache_mock.go -package table
 
 // for test
@@ -33,77 +34,270 @@ var (
 	newMMapStoreReaderFunc = newMMapStoreReader
 )
 
+var (
+	cacheScope          = linmetric.NewScope("lindb.kv.table.cache")
+	cacheReadersGauge   = cacheScope.NewGauge("readers")
+	cacheMappedGauge    = cacheScope.NewGauge("mapped_bytes")
+	cacheInUseGauge     = cacheScope.NewGauge("in_use_readers")
+	cacheHitCounter     = cacheScope.NewDeltaCounter("hits")
+	cacheMissCounter    = cacheScope.NewDeltaCounter("misses")
+	cacheEvictCounter   = cacheScope.NewDeltaCounter("evicts")
+	cacheFailureCounter = cacheScope.NewDeltaCounter("open_failures")
+	corruptedCounter    = cacheScope.NewDeltaCounter("corruptions")
+)
+
+// CorruptionHandler handles the corrupted file found by cache, e.g. quarantines the file.
+type CorruptionHandler func(family string, fileName string, err error)
+
 // Cache caches table readers
 type Cache interface {
 	// GetReader returns store reader from cache, create new reader if not exist.
+	// NOTICE: reader is retained when get it, so must call Release after using it.
 	GetReader(family string, fileName string) (Reader, error)
-	// Evict evicts file reader from cache
+	// Release releases the reader retained by GetReader,
+	// unused reader maybe unmapped if cache is full or the file is evicted.
+	Release(family string, fileName string)
+	// Evict evicts file reader from cache, reader is closed when no one use it.
 	Evict(family string, fileName string)
+	// SetCorruptionHandler sets the handler which is invoked when found corrupted file,
+	// when open file or release reader which found corrupted value.
+	SetCorruptionHandler(handler CorruptionHandler)
 	// Close cleans cache data after closing reader resource firstly
 	Close() error
 }
 
-// Cache caches table readers based on map
-type mapCache struct {
+// cacheEntry represents the cached reader with ref count
+type cacheEntry struct {
+	key     string
+	reader  Reader
+	size    int64
+	ref     int
+	evicted bool
+	elem    *list.Element
+
+	corruptionReported bool
+}
+
+// lruCache caches table readers based on lru list, limited by total mapped size.
+// Reader which is used by someone cannot be unmapped, so the limit is soft,
+// cache will shrink after these readers released.
+type lruCache struct {
 	storePath string
-	readers   map[string]Reader
+	maxSize   int64 // max mapped size, zero means no limit
+	size      int64 // current mapped size
+	readers   map[string]*cacheEntry
+	evicted   map[string]*cacheEntry // evicted readers which are still in use
+	lru       *list.List             // front is the most recent used
 	mutex     sync.Mutex
+
+	corruptionHandler CorruptionHandler
 }
 
-// NewCache creates cache for store readers
-func NewCache(storePath string) Cache {
-	return &mapCache{
+// NewCache creates cache for store readers, maxSize is the max size of mapped files(zero means no limit).
+func NewCache(storePath string, maxSize int64) Cache {
+	return &lruCache{
 		storePath: storePath,
-		readers:   make(map[string]Reader),
+		maxSize:   maxSize,
+		readers:   make(map[string]*cacheEntry),
+		evicted:   make(map[string]*cacheEntry),
+		lru:       list.New(),
 	}
 }
 
-// Evict evicts file reader from cache
-func (c *mapCache) Evict(family string, fileName string) {
+// Evict evicts file reader from cache, if reader is in use, close it after released.
+func (c *lruCache) Evict(family string, fileName string) {
 	filePath := filepath.Join(family, fileName)
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	reader, ok := c.readers[filePath]
+
+	entry, ok := c.readers[filePath]
 	if ok {
-		if err := reader.Close(); err != nil {
-			tableLogger.Error("close store reader error",
-				logger.String("path", c.storePath),
-				logger.String("file", filePath), logger.Error(err))
-		}
-		delete(c.readers, filePath)
+		c.remove(entry)
 	}
 }
 
+// SetCorruptionHandler sets the handler which is invoked when found corrupted file.
+func (c *lruCache) SetCorruptionHandler(handler CorruptionHandler) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.corruptionHandler = handler
+}
+
 // GetReader returns store reader from cache, create new reader if not exist
-func (c *mapCache) GetReader(family string, fileName string) (Reader, error) {
+func (c *lruCache) GetReader(family string, fileName string) (Reader, error) {
+	reader, err := c.getReader(family, fileName)
+	if errors.Is(err, ErrCorrupted) {
+		corruptedCounter.Incr()
+		c.handleCorruption(family, fileName, err)
+	}
+	return reader, err
+}
+
+// getReader returns store reader from cache, create new reader if not exist
+func (c *lruCache) getReader(family string, fileName string) (Reader, error) {
 	filePath := filepath.Join(family, fileName)
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	// find from cache
-	reader, ok := c.readers[filePath]
+	entry, ok := c.readers[filePath]
 	if ok {
-		return reader, nil
+		cacheHitCounter.Incr()
+		c.retain(entry)
+		c.lru.MoveToFront(entry.elem)
+		return entry.reader, nil
 	}
+	cacheMissCounter.Incr()
 
 	// create new reader
 	path := filepath.Join(c.storePath, filePath)
 	newReader, err := newMMapStoreReaderFunc(path)
 	if err != nil {
+		cacheFailureCounter.Incr()
 		return nil, err
 	}
-	c.readers[filePath] = newReader
+	entry = &cacheEntry{
+		key:    filePath,
+		reader: newReader,
+		size:   int64(newReader.Size()),
+	}
+	entry.elem = c.lru.PushFront(entry)
+	c.readers[filePath] = entry
+	c.size += entry.size
+	cacheReadersGauge.Incr()
+	cacheMappedGauge.Add(float64(entry.size))
+	c.retain(entry)
+	// try unmap the least recent used readers
+	c.shrink()
 	return newReader, nil
 }
 
+// Release releases the reader retained by GetReader,
+// invokes corruption handler if reader found corrupted value.
+func (c *lruCache) Release(family string, fileName string) {
+	if err := c.releaseReader(family, fileName); err != nil {
+		c.handleCorruption(family, fileName, err)
+	}
+}
+
+// releaseReader releases the reader, returns the corruption error which isn't reported.
+func (c *lruCache) releaseReader(family string, fileName string) error {
+	filePath := filepath.Join(family, fileName)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.readers[filePath]
+	if !ok {
+		entry, ok = c.evicted[filePath]
+	}
+	if !ok || entry.ref <= 0 {
+		return nil
+	}
+	var err error
+	if !entry.corruptionReported {
+		if err = entry.reader.Corrupted(); err != nil {
+			entry.corruptionReported = true
+		}
+	}
+	c.release(entry)
+	c.shrink()
+	return err
+}
+
+// handleCorruption invokes corruption handler without holding lock,
+// because handler maybe evicts the corrupted file from cache.
+func (c *lruCache) handleCorruption(family string, fileName string, err error) {
+	c.mutex.Lock()
+	handler := c.corruptionHandler
+	c.mutex.Unlock()
+
+	tableLogger.Error("found corrupted sst file",
+		logger.String("family", family), logger.String("file", fileName), logger.Error(err))
+	if handler != nil {
+		handler(family, fileName, err)
+	}
+}
+
 // Close closes reader resource and cleans cache data.
-func (c *mapCache) Close() error {
-	for k, v := range c.readers {
-		if err := v.Close(); err != nil {
-			tableLogger.Error("close store reader error",
-				logger.String("path", c.storePath),
-				logger.String("file", k), logger.Error(err))
+func (c *lruCache) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, entries := range []map[string]*cacheEntry{c.readers, c.evicted} {
+		for _, entry := range entries {
+			c.closeReader(entry)
+			if entry.ref > 0 {
+				cacheInUseGauge.Decr()
+			}
 		}
 	}
+	c.readers = make(map[string]*cacheEntry)
+	c.evicted = make(map[string]*cacheEntry)
+	c.lru.Init()
+	c.size = 0
 	return nil
 }
+
+// retain increases the ref count of reader.
+func (c *lruCache) retain(entry *cacheEntry) {
+	if entry.ref == 0 {
+		cacheInUseGauge.Incr()
+	}
+	entry.ref++
+}
+
+// release decreases the ref count of reader, closes evicted reader if no one use it.
+func (c *lruCache) release(entry *cacheEntry) {
+	entry.ref--
+	if entry.ref > 0 {
+		return
+	}
+	cacheInUseGauge.Decr()
+	if entry.evicted {
+		delete(c.evicted, entry.key)
+		c.closeReader(entry)
+	}
+}
+
+// remove removes the reader from cache, closes it if no one use it.
+func (c *lruCache) remove(entry *cacheEntry) {
+	delete(c.readers, entry.key)
+	c.lru.Remove(entry.elem)
+	c.size -= entry.size
+	cacheEvictCounter.Incr()
+	if entry.ref > 0 {
+		// reader is in use, close it after released
+		entry.evicted = true
+		c.evicted[entry.key] = entry
+		return
+	}
+	c.closeReader(entry)
+}
+
+// shrink unmaps the least recent used readers which are not in use until total size under limit.
+func (c *lruCache) shrink() {
+	if c.maxSize <= 0 {
+		return
+	}
+	elem := c.lru.Back()
+	for elem != nil && c.size > c.maxSize {
+		prev := elem.Prev()
+		entry := elem.Value.(*cacheEntry)
+		if entry.ref == 0 {
+			c.remove(entry)
+		}
+		elem = prev
+	}
+}
+
+// closeReader closes the reader, then releases the mapped memory.
+func (c *lruCache) closeReader(entry *cacheEntry) {
+	if err := entry.reader.Close(); err != nil {
+		tableLogger.Error("close store reader error",
+			logger.String("path", c.storePath),
+			logger.String("file", entry.key), logger.Error(err))
+	}
+	cacheReadersGauge.Decr()
+	cacheMappedGauge.Sub(float64(entry.size))
+}
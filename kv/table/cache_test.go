@@ -18,7 +18,9 @@
 package table
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -27,7 +29,7 @@ import (
 	"github.com/lindb/lindb/pkg/fileutil"
 )
 
-func TestMapCache_GetReader(t *testing.T) {
+func TestLRUCache_GetReader(t *testing.T) {
 	_ = fileutil.MkDirIfNotExist(testKVPath)
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -35,7 +37,7 @@ func TestMapCache_GetReader(t *testing.T) {
 		_ = fileutil.RemoveDir(testKVPath)
 		ctrl.Finish()
 	}()
-	cache := NewCache(testKVPath)
+	cache := NewCache(testKVPath, 0)
 	// case 1: get reader err
 	newMMapStoreReaderFunc = func(path string) (r Reader, err error) {
 		return nil, fmt.Errorf("err")
@@ -45,6 +47,8 @@ func TestMapCache_GetReader(t *testing.T) {
 	assert.Nil(t, r)
 	// case 2: get reader success
 	mockReader := NewMockReader(ctrl)
+	mockReader.EXPECT().Size().Return(100).AnyTimes()
+	mockReader.EXPECT().Corrupted().Return(nil).AnyTimes()
 	newMMapStoreReaderFunc = func(path string) (reader Reader, err error) {
 		return mockReader, nil
 	}
@@ -55,9 +59,13 @@ func TestMapCache_GetReader(t *testing.T) {
 	r, err = cache.GetReader("f", "100000.sst")
 	assert.NoError(t, err)
 	assert.Equal(t, mockReader, r)
-	// case 4: evict not exist
+	cache.Release("f", "100000.sst")
+	cache.Release("f", "100000.sst")
+	// case 4: evict/release not exist
 	cache.Evict("f", "200000.sst")
 	cache.Evict("f1", "100000.sst")
+	cache.Release("f", "200000.sst")
+	cache.Release("f", "100000.sst")
 	// case 5: evict reader err
 	mockReader.EXPECT().Close().Return(fmt.Errorf("err"))
 	cache.Evict("f", "100000.sst")
@@ -68,3 +76,124 @@ func TestMapCache_GetReader(t *testing.T) {
 	err = cache.Close()
 	assert.NoError(t, err)
 }
+
+func TestLRUCache_Evict_InUse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newMMapStoreReaderFunc = newMMapStoreReader
+		ctrl.Finish()
+	}()
+	mockReader := NewMockReader(ctrl)
+	mockReader.EXPECT().Size().Return(100).AnyTimes()
+	mockReader.EXPECT().Corrupted().Return(nil).AnyTimes()
+	newMMapStoreReaderFunc = func(path string) (reader Reader, err error) {
+		return mockReader, nil
+	}
+	cache := NewCache(testKVPath, 0)
+	_, _ = cache.GetReader("f", "100000.sst")
+	_, _ = cache.GetReader("f", "100000.sst")
+	// reader in use, cannot close it
+	cache.Evict("f", "100000.sst")
+	cache.Release("f", "100000.sst")
+	// close reader after all released
+	mockReader.EXPECT().Close().Return(nil)
+	cache.Release("f", "100000.sst")
+
+	// close cache with evicted reader which is in use
+	_, _ = cache.GetReader("f", "200000.sst")
+	cache.Evict("f", "200000.sst")
+	mockReader.EXPECT().Close().Return(nil)
+	err := cache.Close()
+	assert.NoError(t, err)
+}
+
+func TestLRUCache_Shrink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newMMapStoreReaderFunc = newMMapStoreReader
+		ctrl.Finish()
+	}()
+	readers := make(map[string]*MockReader)
+	newMMapStoreReaderFunc = func(path string) (reader Reader, err error) {
+		r := NewMockReader(ctrl)
+		r.EXPECT().Size().Return(100).AnyTimes()
+		r.EXPECT().Corrupted().Return(nil).AnyTimes()
+		readers[path] = r
+		return r, nil
+	}
+	cache := NewCache(testKVPath, 200)
+	c := cache.(*lruCache)
+	_, _ = cache.GetReader("f", "1.sst")
+	_, _ = cache.GetReader("f", "2.sst")
+	_, _ = cache.GetReader("f", "3.sst")
+	// all readers in use, cannot shrink
+	assert.Len(t, c.readers, 3)
+	assert.Equal(t, int64(300), c.size)
+	// release least recent used reader, unmap it
+	readers[filepath.Join(testKVPath, "f", "1.sst")].EXPECT().Close().Return(nil)
+	cache.Release("f", "1.sst")
+	assert.Len(t, c.readers, 2)
+	assert.Equal(t, int64(200), c.size)
+	// under limit, keep released reader
+	cache.Release("f", "2.sst")
+	assert.Len(t, c.readers, 2)
+	// reuse reader, move to front
+	_, _ = cache.GetReader("f", "2.sst")
+	cache.Release("f", "3.sst")
+	// new reader, unmap least recent used reader which is not in use
+	readers[filepath.Join(testKVPath, "f", "3.sst")].EXPECT().Close().Return(nil)
+	_, _ = cache.GetReader("f", "4.sst")
+	assert.Len(t, c.readers, 2)
+	_, ok := c.readers[filepath.Join("f", "3.sst")]
+	assert.False(t, ok)
+
+	for _, r := range c.readers {
+		r.reader.(*MockReader).EXPECT().Close().Return(nil)
+	}
+	err := cache.Close()
+	assert.NoError(t, err)
+}
+
+func TestLRUCache_CorruptionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newMMapStoreReaderFunc = newMMapStoreReader
+		ctrl.Finish()
+	}()
+	var corrupted []string
+	cache := NewCache(testKVPath, 0)
+	// case 1: no handler
+	newMMapStoreReaderFunc = func(path string) (reader Reader, err error) {
+		return nil, ErrCorrupted
+	}
+	_, err := cache.GetReader("f", "100000.sst")
+	assert.True(t, errors.Is(err, ErrCorrupted))
+	cache.SetCorruptionHandler(func(family string, fileName string, err error) {
+		corrupted = append(corrupted, fileName)
+	})
+	// case 2: open corrupted file
+	_, err = cache.GetReader("f", "100000.sst")
+	assert.True(t, errors.Is(err, ErrCorrupted))
+	assert.Equal(t, []string{"100000.sst"}, corrupted)
+	// case 3: open file failure, not corrupted
+	newMMapStoreReaderFunc = func(path string) (reader Reader, err error) {
+		return nil, fmt.Errorf("err")
+	}
+	_, err = cache.GetReader("f", "100000.sst")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"100000.sst"}, corrupted)
+	// case 4: found corrupted value when reading, report once
+	mockReader := NewMockReader(ctrl)
+	mockReader.EXPECT().Size().Return(100).AnyTimes()
+	mockReader.EXPECT().Corrupted().Return(ErrCorrupted).AnyTimes()
+	newMMapStoreReaderFunc = func(path string) (reader Reader, err error) {
+		return mockReader, nil
+	}
+	_, _ = cache.GetReader("f", "200000.sst")
+	_, _ = cache.GetReader("f", "200000.sst")
+	cache.Release("f", "200000.sst")
+	cache.Release("f", "200000.sst")
+	assert.Equal(t, []string{"100000.sst", "200000.sst"}, corrupted)
+	mockReader.EXPECT().Close().Return(nil)
+	assert.NoError(t, cache.Close())
+}
@@ -19,27 +19,46 @@ package table
 
 import (
 	"errors"
+	"hash/crc32"
 
 	"github.com/lindb/lindb/pkg/logger"
 )
 
 var (
 	ErrEmptyKeys = errors.New("empty keys under store builder")
+	// ErrCorrupted represents the content of sst file is broken(torn write, bit rot etc.)
+	ErrCorrupted = errors.New("sst file is corrupted")
 )
 
 const (
 	// magic-number in the footer of sst file
 	magicNumberOffsetFile uint64 = 0x69632d656d656c65
-	// current file layout version
+	// file layout version without checksum
 	version0 = 0
+	// file layout version with crc32 checksum of each value and index block
+	version1 = 1
 
 	sstFileFooterSize = 1 + // entry length wrote by bufioutil
 		4 + // posOfOffset(4)
 		4 + // posOfKeys(4)
 		1 + // version(1)
 		8 // magicNumber(8)
+	sstFileFooterSizeV1 = 1 + // entry length wrote by bufioutil
+		4 + // posOfOffset(4)
+		4 + // posOfKeys(4)
+		4 + // posOfChecksums(4)
+		4 + // checksum of index block(4)
+		1 + // version(1)
+		8 // magicNumber(8)
 	// footer-size, offset(1), keys(1)
 	sstFileMinLength = sstFileFooterSize + 2
+	// size of value checksum
+	checksumSize = 4
+	// kind of sync metrics for table file
+	syncKindTable = "table"
 )
 
+// crc32 table for checksum of values and index block
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
 var tableLogger = logger.GetLogger("kv", "Table")
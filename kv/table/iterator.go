@@ -32,6 +32,9 @@ type Iterator interface {
 	Key() uint32
 	// Value returns the value of the current key/value pair
 	Value() []byte
+	// Err returns the first error found when iterating(e.g. corrupted value), nil if no error.
+	// NOTICE: value is nil if it is corrupted, so caller must check Err after reading value.
+	Err() error
 }
 
 /////////////
@@ -110,6 +113,16 @@ func (m *mergedIterator) Value() []byte {
 	return m.curValue
 }
 
+// Err returns the first error of underlying iterators, nil if no error
+func (m *mergedIterator) Err() error {
+	for _, it := range m.its {
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // item represents an item under priority queue, using key as priority.
 type item struct {
 	it Iterator
@@ -57,6 +57,7 @@ func TestMergedIterator(t *testing.T) {
 		i++
 	}
 	assert.Equal(t, len(keys), i)
+	assert.NoError(t, mergedIt.Err())
 
 	it1 = generateIterator(ctrl, map[uint32][]byte{
 		10:   []byte("value10"),
@@ -172,5 +173,6 @@ func generateIterator(ctrl *gomock.Controller, values map[uint32][]byte) *MockIt
 	calls = append(calls, it1.EXPECT().HasNext().Return(false))
 
 	gomock.InOrder(calls...)
+	it1.EXPECT().Err().Return(nil).AnyTimes()
 	return it1
 }
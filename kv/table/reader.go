@@ -21,8 +21,10 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 
 	"github.com/lindb/roaring"
+	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/fileutil"
@@ -48,6 +50,12 @@ type Reader interface {
 	Get(key uint32) ([]byte, bool)
 	// Iterator iterates over a store's key/value pairs in key order.
 	Iterator() Iterator
+	// Size returns the length of file data mapped into memory
+	Size() int
+	// Verify verifies the checksum of all values, returns ErrCorrupted if any value is broken
+	Verify() error
+	// Corrupted returns the corruption error found when reading value, nil if not found
+	Corrupted() error
 	// Close closes reader, release related resources
 	Close() error
 }
@@ -59,6 +67,11 @@ type storeMMapReader struct {
 	len     int                          // length of the file
 	keys    *roaring.Bitmap              // bitmap of keys
 	offsets *encoding.FixedOffsetDecoder // offset of values
+	version byte                         // file layout version
+	// crc32 checksum of each value(version1), nil for version0
+	checksums []byte
+
+	corrupted atomic.Error // first corruption found when reading value
 }
 
 // newMMapStoreReader creates mmap store file reader
@@ -87,7 +100,7 @@ func newMMapStoreReader(path string) (r Reader, err error) {
 		keys: roaring.New(),
 	}
 
-	if err := reader.initialize(); err != nil {
+	if err = reader.initialize(); err != nil {
 		return nil, err
 	}
 
@@ -95,25 +108,53 @@ func newMMapStoreReader(path string) (r Reader, err error) {
 }
 
 // initialize initializes store reader, reads index block(keys,offset etc.), then caches it
-func (r *storeMMapReader) initialize() error {
-	buf := r.readBytes(r.len - sstFileFooterSize)
-	if (len(buf)) != sstFileFooterSize-1 {
-		return fmt.Errorf("read sstfile:%s footer error", r.path)
-	}
+func (r *storeMMapReader) initialize() (err error) {
+	defer func() {
+		// torn write maybe produces the broken index block which makes decoding panic
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%w, initialize sstfile:%s panic:%v", ErrCorrupted, r.path, rec)
+		}
+	}()
 	// validate magic-number
-	if uint64Func(buf[9:]) != magicNumberOffsetFile {
-		return fmt.Errorf("verify magic-number of sstfile:%s failure", r.path)
+	if uint64Func(r.data[r.len-8:]) != magicNumberOffsetFile {
+		return fmt.Errorf("%w, verify magic-number of sstfile:%s failure", ErrCorrupted, r.path)
+	}
+	r.version = r.data[r.len-9]
+	var offset, keys []byte
+	switch r.version {
+	case version0:
+		buf := r.readBytes(r.len - sstFileFooterSize)
+		if len(buf) != sstFileFooterSize-1 {
+			return fmt.Errorf("%w, read sstfile:%s footer error", ErrCorrupted, r.path)
+		}
+		offset = r.readBytes(int(binary.LittleEndian.Uint32(buf[:4])))
+		keys = r.readBytes(int(binary.LittleEndian.Uint32(buf[4:8])))
+	case version1:
+		buf := r.readBytes(r.len - sstFileFooterSizeV1)
+		if len(buf) != sstFileFooterSizeV1-1 {
+			return fmt.Errorf("%w, read sstfile:%s footer error", ErrCorrupted, r.path)
+		}
+		offset = r.readBytes(int(binary.LittleEndian.Uint32(buf[:4])))
+		keys = r.readBytes(int(binary.LittleEndian.Uint32(buf[4:8])))
+		r.checksums = r.readBytes(int(binary.LittleEndian.Uint32(buf[8:12])))
+		checksum := crc32.Update(crc32.Checksum(offset, crcTable), crcTable, keys)
+		checksum = crc32.Update(checksum, crcTable, r.checksums)
+		if checksum != binary.LittleEndian.Uint32(buf[12:16]) {
+			return fmt.Errorf("%w, verify index checksum of sstfile:%s failure", ErrCorrupted, r.path)
+		}
+	default:
+		return fmt.Errorf("%w, unknown version:%d of sstfile:%s", ErrCorrupted, r.version, r.path)
 	}
-	posOfOffset := int(binary.LittleEndian.Uint32(buf[:4]))
-	posOfKeys := int(binary.LittleEndian.Uint32(buf[4:8]))
-	if err := encoding.BitmapUnmarshal(r.keys, r.readBytes(posOfKeys)); err != nil {
-		return fmt.Errorf("unmarshal keys data from file[%s] error:%s", r.path, err)
+	if err := encoding.BitmapUnmarshal(r.keys, keys); err != nil {
+		return fmt.Errorf("%w, unmarshal keys data from file[%s] error:%s", ErrCorrupted, r.path, err)
 	}
-	offset := r.readBytes(posOfOffset)
 	r.offsets = encoding.NewFixedOffsetDecoder(offset)
 
 	if r.offsets.Size() != int(r.keys.GetCardinality()) {
-		return fmt.Errorf("num. of keys != num. of offsets in file[%s]", r.path)
+		return fmt.Errorf("%w, num. of keys != num. of offsets in file[%s]", ErrCorrupted, r.path)
+	}
+	if r.version == version1 && len(r.checksums) != r.offsets.Size()*checksumSize {
+		return fmt.Errorf("%w, num. of keys != num. of checksums in file[%s]", ErrCorrupted, r.path)
 	}
 	return nil
 }
@@ -130,8 +171,65 @@ func (r *storeMMapReader) Get(key uint32) ([]byte, bool) {
 	}
 	// bitmap data's index from 1, so idx= get index - 1
 	idx := r.keys.Rank(key)
-	offset, _ := r.offsets.Get(int(idx) - 1)
-	return r.readBytes(offset), true
+	value, err := r.readValue(int(idx) - 1)
+	return value, err == nil
+}
+
+// readValue reads the value by index, then verifies the checksum of value,
+// returns ErrCorrupted if value is corrupted.
+func (r *storeMMapReader) readValue(idx int) ([]byte, error) {
+	offset, ok := r.offsets.Get(idx)
+	if !ok {
+		return nil, r.markCorrupted(fmt.Errorf("%w, offset of value:%d not found in file[%s]", ErrCorrupted, idx, r.path))
+	}
+	value := r.readBytes(offset)
+	if value == nil {
+		return nil, r.markCorrupted(fmt.Errorf("%w, read value:%d out of range in file[%s]", ErrCorrupted, idx, r.path))
+	}
+	if !r.verifyValue(idx, value) {
+		return nil, r.markCorrupted(fmt.Errorf("%w, verify checksum of value:%d failure in file[%s]", ErrCorrupted, idx, r.path))
+	}
+	return value, nil
+}
+
+// verifyValue verifies the checksum of value, version0 file hasn't checksum, always returns true.
+func (r *storeMMapReader) verifyValue(idx int, value []byte) bool {
+	if r.version == version0 {
+		return true
+	}
+	pos := idx * checksumSize
+	return binary.LittleEndian.Uint32(r.checksums[pos:pos+checksumSize]) == crc32.Checksum(value, crcTable)
+}
+
+// markCorrupted records the first corruption found when reading value, returns the given error.
+func (r *storeMMapReader) markCorrupted(err error) error {
+	if r.corrupted.Load() == nil {
+		r.corrupted.Store(err)
+		corruptedCounter.Incr()
+		tableLogger.Error("found corrupted value in sst file",
+			logger.String("path", r.path), logger.Error(err))
+	}
+	return err
+}
+
+// Verify verifies the checksum of all values, returns ErrCorrupted if any value is broken
+func (r *storeMMapReader) Verify() error {
+	for idx := 0; idx < r.offsets.Size(); idx++ {
+		if _, err := r.readValue(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Corrupted returns the corruption error found when reading value, nil if not found
+func (r *storeMMapReader) Corrupted() error {
+	return r.corrupted.Load()
+}
+
+// Size returns the length of file data mapped into memory
+func (r *storeMMapReader) Size() int {
+	return r.len
 }
 
 // Iterator iterates over a store's key/value pairs in key order.
@@ -144,8 +242,11 @@ func (r *storeMMapReader) Close() error {
 	return fileutil.Unmap(r.data)
 }
 
-// readBytes reads bytes from buffer, read length+data format
+// readBytes reads bytes from buffer, read length+data format, returns nil if out of range
 func (r *storeMMapReader) readBytes(offset int) []byte {
+	if offset < 0 || offset >= len(r.data) {
+		return nil
+	}
 	length, err := uvarintFunc(bytes.NewReader(r.data[offset:]))
 	if err != nil {
 		return nil
@@ -153,7 +254,7 @@ func (r *storeMMapReader) readBytes(offset int) []byte {
 	bytesCount := stream.UvariantSize(length)
 	start := offset + bytesCount
 	end := start + int(length)
-	if end > len(r.data) {
+	if length > uint64(len(r.data)) || end > len(r.data) {
 		return nil
 	}
 	return r.data[start:end]
@@ -165,6 +266,7 @@ type storeMMapIterator struct {
 	keyIt  roaring.IntIterable
 
 	idx int
+	err error // first corruption found when reading value
 }
 
 // newMMapIterator creates store iterator using mmap store reader
@@ -187,9 +289,17 @@ func (it *storeMMapIterator) Key() uint32 {
 	return key
 }
 
-// Value returns the value of the current key/value pair
+// Value returns the value of the current key/value pair, returns nil if value is corrupted
 func (it *storeMMapIterator) Value() []byte {
-	offset, _ := it.reader.offsets.Get(it.idx)
+	value, err := it.reader.readValue(it.idx)
+	if err != nil && it.err == nil {
+		it.err = err
+	}
 	it.idx++
-	return it.reader.readBytes(offset)
+	return value
+}
+
+// Err returns the first corruption error found when reading value, nil if not found
+func (it *storeMMapIterator) Err() error {
+	return it.err
 }
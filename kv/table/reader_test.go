@@ -19,6 +19,7 @@ package table
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -27,6 +28,7 @@ import (
 	"github.com/lindb/roaring"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/fileutil"
 )
@@ -72,7 +74,7 @@ func TestStoreMMapReader_readBytes_Err(t *testing.T) {
 		encoding.BitmapUnmarshal = bitmapUnmarshal
 		_ = os.RemoveAll(testKVPath)
 	}()
-	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst")
+	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst", bufioutil.DefaultSyncPolicy)
 	assert.NoError(t, err)
 
 	_ = builder.Add(1, []byte("test"))
@@ -126,7 +128,7 @@ func TestReader(t *testing.T) {
 		_ = os.RemoveAll(testKVPath)
 	}()
 
-	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst")
+	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst", bufioutil.DefaultSyncPolicy)
 	assert.NoError(t, err)
 
 	_ = builder.Add(1, []byte("test"))
@@ -135,7 +137,7 @@ func TestReader(t *testing.T) {
 	err = builder.Close()
 	assert.Nil(t, err)
 
-	cache := NewCache(testKVPath)
+	cache := NewCache(testKVPath, 0)
 
 	reader, err := cache.GetReader("", "000010.sst")
 	assert.NoError(t, err)
@@ -169,7 +171,7 @@ func TestStoreIterator(t *testing.T) {
 	defer func() {
 		_ = os.RemoveAll(testKVPath)
 	}()
-	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst")
+	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst", bufioutil.DefaultSyncPolicy)
 	assert.NoError(t, err)
 
 	_ = builder.Add(1, []byte("test"))
@@ -178,7 +180,7 @@ func TestStoreIterator(t *testing.T) {
 	err = builder.Close()
 	assert.Nil(t, err)
 
-	cache := NewCache(testKVPath)
+	cache := NewCache(testKVPath, 0)
 	reader, err := cache.GetReader("", "000010.sst")
 	assert.NoError(t, err)
 
@@ -196,3 +198,115 @@ func TestStoreIterator(t *testing.T) {
 
 	assert.False(t, it.HasNext())
 }
+
+func TestReader_Corrupted(t *testing.T) {
+	_ = fileutil.MkDirIfNotExist(testKVPath)
+	defer func() {
+		_ = os.RemoveAll(testKVPath)
+	}()
+	builder, err := NewStoreBuilder(10, testKVPath+"/000010.sst", bufioutil.DefaultSyncPolicy)
+	assert.NoError(t, err)
+	_ = builder.Add(1, []byte("test"))
+	_ = builder.Add(10, []byte("test10"))
+	assert.NoError(t, builder.Close())
+	data, err := os.ReadFile(testKVPath + "/000010.sst")
+	assert.NoError(t, err)
+
+	// case 1: verify success
+	r, err := newMMapStoreReader(testKVPath + "/000010.sst")
+	assert.NoError(t, err)
+	assert.NoError(t, r.Verify())
+	assert.NoError(t, r.Corrupted())
+	_ = r.Close()
+	// case 2: value corrupted
+	corrupted := append([]byte{}, data...)
+	corrupted[1] = 'x'
+	assert.NoError(t, os.WriteFile(testKVPath+"/000010.sst", corrupted, 0644))
+	r, err = newMMapStoreReader(testKVPath + "/000010.sst")
+	assert.NoError(t, err)
+	value, ok := r.Get(1)
+	assert.False(t, ok)
+	assert.Nil(t, value)
+	assert.True(t, errors.Is(r.Corrupted(), ErrCorrupted))
+	value, ok = r.Get(10)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("test10"), value)
+	it := r.Iterator()
+	assert.NoError(t, it.Err())
+	assert.True(t, it.HasNext())
+	assert.Equal(t, uint32(1), it.Key())
+	assert.Nil(t, it.Value())
+	assert.True(t, errors.Is(it.Err(), ErrCorrupted))
+	assert.True(t, it.HasNext())
+	assert.Equal(t, uint32(10), it.Key())
+	assert.Equal(t, []byte("test10"), it.Value())
+	// keep the first error
+	assert.True(t, errors.Is(it.Err(), ErrCorrupted))
+	// merged iterator returns the error of underlying iterator
+	it = NewMergedIterator([]Iterator{r.Iterator()})
+	assert.True(t, it.HasNext())
+	assert.Equal(t, uint32(1), it.Key())
+	assert.Nil(t, it.Value())
+	assert.True(t, errors.Is(it.Err(), ErrCorrupted))
+	assert.True(t, errors.Is(r.Verify(), ErrCorrupted))
+	_ = r.Close()
+	// case 3: index block corrupted
+	corrupted = append([]byte{}, data...)
+	corrupted[len(corrupted)-sstFileFooterSizeV1+13]++
+	assert.NoError(t, os.WriteFile(testKVPath+"/000010.sst", corrupted, 0644))
+	r, err = newMMapStoreReader(testKVPath + "/000010.sst")
+	assert.True(t, errors.Is(err, ErrCorrupted))
+	assert.Nil(t, r)
+	// case 4: torn write, tail of file lost
+	assert.NoError(t, os.WriteFile(testKVPath+"/000010.sst", data[:len(data)-5], 0644))
+	r, err = newMMapStoreReader(testKVPath + "/000010.sst")
+	assert.True(t, errors.Is(err, ErrCorrupted))
+	assert.Nil(t, r)
+	// case 5: unknown version
+	corrupted = append([]byte{}, data...)
+	corrupted[len(corrupted)-9] = 10
+	assert.NoError(t, os.WriteFile(testKVPath+"/000010.sst", corrupted, 0644))
+	r, err = newMMapStoreReader(testKVPath + "/000010.sst")
+	assert.True(t, errors.Is(err, ErrCorrupted))
+	assert.Nil(t, r)
+}
+
+func TestReader_Version0(t *testing.T) {
+	_ = fileutil.MkDirIfNotExist(testKVPath)
+	defer func() {
+		_ = os.RemoveAll(testKVPath)
+	}()
+	// build file with version0 layout, which hasn't checksum
+	writer, err := bufioutil.NewBufioWriter(testKVPath + "/000010.sst")
+	assert.NoError(t, err)
+	offsets := encoding.NewFixedOffsetEncoder()
+	keys := roaring.New()
+	for idx, value := range []string{"test", "test10"} {
+		keys.Add([]uint32{1, 10}[idx])
+		offsets.Add(int(writer.Size()))
+		_, _ = writer.Write([]byte(value))
+	}
+	posOfOffset := writer.Size()
+	_, _ = writer.Write(offsets.MarshalBinary())
+	posOfKeys := writer.Size()
+	keysData, _ := encoding.BitmapMarshal(keys)
+	_, _ = writer.Write(keysData)
+	var buf [sstFileFooterSize - 1]byte
+	binary.LittleEndian.PutUint32(buf[:4], uint32(posOfOffset))
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(posOfKeys))
+	buf[8] = version0
+	binary.LittleEndian.PutUint64(buf[9:], magicNumberOffsetFile)
+	_, _ = writer.Write(buf[:])
+	assert.NoError(t, writer.Close())
+
+	r, err := newMMapStoreReader(testKVPath + "/000010.sst")
+	assert.NoError(t, err)
+	assert.NoError(t, r.Verify())
+	value, ok := r.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("test"), value)
+	value, ok = r.Get(10)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("test10"), value)
+	_ = r.Close()
+}
@@ -84,6 +84,7 @@ func TestFamilyVersion(t *testing.T) {
 
 	reader := table.NewMockReader(ctrl)
 	cache.EXPECT().GetReader(gomock.Any(), gomock.Any()).Return(reader, nil).MaxTimes(3)
+	cache.EXPECT().Release(gomock.Any(), gomock.Any()).AnyTimes()
 	// add duplicate file
 	version2.AddFile(1, file3)
 	assert.Equal(t, 2, len(familyVersion1.GetAllActiveFiles()), "file list != 2")
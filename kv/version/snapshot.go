@@ -18,6 +18,8 @@
 package version
 
 import (
+	"sync"
+
 	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/kv/table"
@@ -26,7 +28,7 @@ import (
 //go:generate mockgen -source ./snapshot.go -destination=./snapshot_mock.go -package version
 
 // Snapshot represents a current family version for reading data.
-// NOTICE: current version will retain like ref count, so snapshot must close.
+// NOTICE: current version and file readers will retain like ref count, so snapshot must close.
 type Snapshot interface {
 	// GetCurrent returns current mutable version
 	GetCurrent() Version
@@ -44,6 +46,8 @@ type snapshot struct {
 	cache      table.Cache
 
 	version Version
+	readers []string // file names of retained readers
+	mutex   sync.Mutex
 	closed  atomic.Bool
 }
 
@@ -70,7 +74,7 @@ func (s *snapshot) FindReaders(key uint32) ([]table.Reader, error) {
 	var readers []table.Reader
 	for _, fileMeta := range files {
 		// get store reader from cache
-		reader, err := s.cache.GetReader(s.familyName, Table(fileMeta.GetFileNumber()))
+		reader, err := s.getReader(Table(fileMeta.GetFileNumber()))
 		if err != nil {
 			return nil, err
 		}
@@ -83,13 +87,32 @@ func (s *snapshot) FindReaders(key uint32) ([]table.Reader, error) {
 
 // GetReader returns the file reader
 func (s *snapshot) GetReader(fileNumber table.FileNumber) (table.Reader, error) {
-	return s.cache.GetReader(s.familyName, Table(fileNumber))
+	return s.getReader(Table(fileNumber))
+}
+
+// getReader returns the file reader from cache, then retains it until snapshot closed.
+func (s *snapshot) getReader(fileName string) (table.Reader, error) {
+	reader, err := s.cache.GetReader(s.familyName, fileName)
+	if err != nil || reader == nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	s.readers = append(s.readers, fileName)
+	s.mutex.Unlock()
+	return reader, nil
 }
 
 // Close releases related resources
 func (s *snapshot) Close() {
 	// atomic set closed status, make sure only release once
 	if s.closed.CAS(false, true) {
+		s.mutex.Lock()
+		for _, fileName := range s.readers {
+			s.cache.Release(s.familyName, fileName)
+		}
+		s.readers = nil
+		s.mutex.Unlock()
+
 		s.version.Release()
 	}
 }
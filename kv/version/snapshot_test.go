@@ -67,7 +67,9 @@ func TestSnapshot_FindReaders(t *testing.T) {
 	readers, err = snapshot.FindReaders(uint32(80))
 	assert.Error(t, err)
 	assert.Nil(t, readers)
-	// case 7: close snapshot
+	// case 7: close snapshot, release retained readers
+	cache.EXPECT().Release("test", Table(table.FileNumber(11)))
+	cache.EXPECT().Release("test", Table(table.FileNumber(10)))
 	v.EXPECT().Release()
 	snapshot.Close()
 	snapshot.Close() // test version release only once
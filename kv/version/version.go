@@ -60,6 +60,9 @@ type Version interface {
 	// PickL0Compaction picks level0 compaction context,
 	// if hasn't congruent compaction return nil.
 	PickL0Compaction(compactThreshold int) *Compaction
+	// PickFullCompaction picks all files of level0/level1 to do compaction,
+	// if hasn't any file return nil.
+	PickFullCompaction() *Compaction
 
 	// AddRollupFile adds need rollup file and target interval
 	AddRollupFile(fileNumber table.FileNumber, interval timeutil.Interval)
@@ -188,6 +191,20 @@ func (v *version) PickL0Compaction(compactThreshold int) *Compaction {
 	return NewCompaction(v.fv.GetID(), 0, levelInputs, levelUpInputs)
 }
 
+// PickFullCompaction picks all files of level0/level1 to do compaction,
+// all values of same key will be merged into level1, so that obsolete data can be removed by merger.
+// if hasn't any file return nil.
+func (v *version) PickFullCompaction() *Compaction {
+	levelInputs := v.GetFiles(0)
+	levelUpInputs := v.GetFiles(1)
+	if len(levelInputs) == 0 && len(levelUpInputs) == 0 {
+		return nil
+	}
+	return NewCompaction(v.fv.GetID(), 0,
+		append([]*FileMeta{}, levelInputs...),
+		append([]*FileMeta{}, levelUpInputs...))
+}
+
 // FindFiles finds all files include key from each level
 func (v *version) FindFiles(key uint32) []*FileMeta {
 	var files []*FileMeta
@@ -179,6 +179,34 @@ func TestVersion_PickL0Compaction(t *testing.T) {
 	assert.Equal(t, 3, len(compaction.levelUpInputs))
 }
 
+func TestVersion_PickFullCompaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fv := NewMockFamilyVersion(ctrl)
+	vs := NewMockStoreVersionSet(ctrl)
+	fv.EXPECT().GetVersionSet().Return(vs).AnyTimes()
+	fv.EXPECT().GetID().Return(FamilyID(1)).AnyTimes()
+	vs.EXPECT().numberOfLevels().Return(2).AnyTimes()
+	v := newVersion(1, fv)
+	assert.Nil(t, v.PickFullCompaction())
+
+	f1 := FileMeta{fileNumber: 1, minKey: 10, maxKey: 100}
+	f3 := FileMeta{fileNumber: 3, minKey: 1, maxKey: 5}
+	f4 := FileMeta{fileNumber: 4, minKey: 400, maxKey: 500}
+	v.AddFiles(1, []*FileMeta{&f3, &f4})
+	compaction := v.PickFullCompaction()
+	assert.NotNil(t, compaction)
+	assert.False(t, compaction.IsTrivialMove())
+	assert.Empty(t, compaction.levelInputs)
+	assert.Len(t, compaction.levelUpInputs, 2)
+
+	v.AddFiles(0, []*FileMeta{&f1})
+	compaction = v.PickFullCompaction()
+	assert.Len(t, compaction.levelInputs, 1)
+	assert.Len(t, compaction.levelUpInputs, 2)
+}
+
 func TestVersion_RollupJob(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,107 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertStatus represents the status of alert notification.
+type AlertStatus string
+
+// Defines all statuses of alert notification.
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// alertOperators represents the operators supported by alert rule for comparing value with threshold.
+var alertOperators = map[string]func(value, threshold float64) bool{
+	">":  func(value, threshold float64) bool { return value > threshold },
+	">=": func(value, threshold float64) bool { return value >= threshold },
+	"<":  func(value, threshold float64) bool { return value < threshold },
+	"<=": func(value, threshold float64) bool { return value <= threshold },
+	"==": func(value, threshold float64) bool { return value == threshold },
+	"!=": func(value, threshold float64) bool { return value != threshold },
+}
+
+// AlertRule represents the threshold alert rule, stored in state repo, evaluated by master broker periodically.
+// The latest value of each series' field queried by sql is compared with threshold,
+// alert is fired if the condition keeps true for the duration.
+type AlertRule struct {
+	Name      string  `json:"name" binding:"required"`
+	Database  string  `json:"database" binding:"required"`
+	SQL       string  `json:"sql" binding:"required"`
+	Operator  string  `json:"operator" binding:"required"` // one of >,>=,<,<=,==,!=
+	Threshold float64 `json:"threshold"`
+	// duration of condition keeps true before firing, like 5m, empty means firing immediately
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Validate checks if the alert rule is valid.
+func (r AlertRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("alert rule name cannot be empty")
+	}
+	if r.Database == "" {
+		return fmt.Errorf("database of alert rule cannot be empty")
+	}
+	if r.SQL == "" {
+		return fmt.Errorf("sql of alert rule cannot be empty")
+	}
+	if _, ok := alertOperators[r.Operator]; !ok {
+		return fmt.Errorf("not support operator: %s of alert rule", r.Operator)
+	}
+	if r.For != "" {
+		if duration, err := time.ParseDuration(r.For); err != nil || duration < 0 {
+			return fmt.Errorf("bad for duration: %s of alert rule", r.For)
+		}
+	}
+	return nil
+}
+
+// Match returns if the value matches the condition of alert rule.
+func (r AlertRule) Match(value float64) bool {
+	op, ok := alertOperators[r.Operator]
+	if !ok {
+		return false
+	}
+	return op(value, r.Threshold)
+}
+
+// GetFor returns the duration of condition keeps true before firing, returns 0 if not set.
+func (r AlertRule) GetFor() time.Duration {
+	duration, err := time.ParseDuration(r.For)
+	if err != nil || duration < 0 {
+		return 0
+	}
+	return duration
+}
+
+// Alert represents the notification of alert which is fired/resolved by alert rule.
+type Alert struct {
+	Status      AlertStatus       `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Value       float64           `json:"value"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt,omitempty"`
+}
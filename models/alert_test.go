@@ -0,0 +1,71 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertRule_Validate(t *testing.T) {
+	rule := AlertRule{Name: "cpu_high", Database: "db", SQL: "select f from cpu", Operator: ">", Threshold: 90}
+	assert.NoError(t, rule.Validate())
+	rule.For = "5m"
+	assert.NoError(t, rule.Validate())
+
+	for _, r := range []AlertRule{
+		{Database: "db", SQL: "select f from cpu", Operator: ">"},
+		{Name: "a", SQL: "select f from cpu", Operator: ">"},
+		{Name: "a", Database: "db", Operator: ">"},
+		{Name: "a", Database: "db", SQL: "select f from cpu", Operator: "=>"},
+		{Name: "a", Database: "db", SQL: "select f from cpu", Operator: ">", For: "abc"},
+		{Name: "a", Database: "db", SQL: "select f from cpu", Operator: ">", For: "-1m"},
+	} {
+		assert.Error(t, r.Validate())
+	}
+}
+
+func TestAlertRule_Match(t *testing.T) {
+	cases := []struct {
+		op     string
+		value  float64
+		expect bool
+	}{
+		{op: ">", value: 11, expect: true},
+		{op: ">", value: 10, expect: false},
+		{op: ">=", value: 10, expect: true},
+		{op: "<", value: 9, expect: true},
+		{op: "<", value: 10, expect: false},
+		{op: "<=", value: 10, expect: true},
+		{op: "==", value: 10, expect: true},
+		{op: "!=", value: 10, expect: false},
+		{op: "unknown", value: 10, expect: false},
+	}
+	for _, c := range cases {
+		rule := AlertRule{Operator: c.op, Threshold: 10}
+		assert.Equal(t, c.expect, rule.Match(c.value), c.op)
+	}
+}
+
+func TestAlertRule_GetFor(t *testing.T) {
+	assert.Equal(t, time.Duration(0), AlertRule{}.GetFor())
+	assert.Equal(t, time.Duration(0), AlertRule{For: "abc"}.GetFor())
+	assert.Equal(t, 5*time.Minute, AlertRule{For: "5m"}.GetFor())
+}
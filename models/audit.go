@@ -0,0 +1,39 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+// AuditEntry represents an admin operation recorded in audit log, such as create/drop database,
+// storage cluster config change and login, entries are stored in state repo ordered by id.
+type AuditEntry struct {
+	ID        int64  `json:"id"`
+	Timestamp int64  `json:"timestamp"` // millisecond
+	Actor     string `json:"actor"`     // authenticated user, or client ip if request is anonymous
+	ClientIP  string `json:"clientIP"`
+	Operation string `json:"operation"` // like "POST /api/v1/database" or "login"
+	// Payload is the request body of operation, sensitive fields(like password) are redacted.
+	Payload string `json:"payload,omitempty"`
+	Status  int    `json:"status"`          // http status of operation, failed login is recorded as 401
+	Error   string `json:"error,omitempty"` // failure reason of operation
+}
+
+// AuditLogPage represents a page of audit log entries, newest entry first.
+type AuditLogPage struct {
+	Entries []*AuditEntry `json:"entries"`
+	// Next is the id which is used as "before" param to fetch next page, 0 if no more entries.
+	Next int64 `json:"next"`
+}
@@ -0,0 +1,178 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"sort"
+
+	"github.com/lindb/lindb/pkg/hll"
+)
+
+// SeriesCardinality represents the series cardinality of metric,
+// includes the number of series and the number of distinct tag values of each tag key.
+type SeriesCardinality struct {
+	MetricName string              `json:"metricName"`
+	Series     uint64              `json:"series"`
+	TagKeys    []TagKeyCardinality `json:"tagKeys,omitempty"`
+}
+
+// TagKeyCardinality represents the number of distinct tag values of tag key.
+type TagKeyCardinality struct {
+	TagKey string `json:"tagKey"`
+	Values uint64 `json:"values"`
+	// Estimated is true if values is estimated by HyperLogLog sketch.
+	Estimated bool `json:"estimated,omitempty"`
+
+	// TagValues/Sketch are the exact tag values or HyperLogLog sketch of tag values in one storage node,
+	// only used for merging the results of storage nodes.
+	TagValues []string `json:"tagValues,omitempty"`
+	Sketch    []byte   `json:"sketch,omitempty"`
+}
+
+// MergeSeriesCardinality merges the series cardinality results of storage nodes,
+// series of different shards are disjoint, so series count is exact sum.
+// Tag values are union of exact tag values if all nodes return them,
+// else estimated by merged HyperLogLog sketch. Tag keys are sorted by values desc.
+func MergeSeriesCardinality(results []*SeriesCardinality) (*SeriesCardinality, error) {
+	merged := &SeriesCardinality{}
+	if len(results) == 0 {
+		return merged, nil
+	}
+	merged.MetricName = results[0].MetricName
+	tagKeys := make(map[string][]TagKeyCardinality)
+	var tagKeyNames []string
+	for _, result := range results {
+		merged.Series += result.Series
+		for _, tagKey := range result.TagKeys {
+			if _, ok := tagKeys[tagKey.TagKey]; !ok {
+				tagKeyNames = append(tagKeyNames, tagKey.TagKey)
+			}
+			tagKeys[tagKey.TagKey] = append(tagKeys[tagKey.TagKey], tagKey)
+		}
+	}
+	for _, name := range tagKeyNames {
+		tagKey, err := mergeTagKeyCardinality(name, tagKeys[name])
+		if err != nil {
+			return nil, err
+		}
+		merged.TagKeys = append(merged.TagKeys, tagKey)
+	}
+	sort.SliceStable(merged.TagKeys, func(i, j int) bool {
+		return merged.TagKeys[i].Values > merged.TagKeys[j].Values
+	})
+	return merged, nil
+}
+
+// mergeTagKeyCardinality merges the tag value cardinality of tag key from storage nodes.
+func mergeTagKeyCardinality(name string, partials []TagKeyCardinality) (TagKeyCardinality, error) {
+	merged := TagKeyCardinality{TagKey: name}
+	if len(partials) == 1 {
+		merged.Values = partials[0].Values
+		return merged, nil
+	}
+	exact := true
+	for idx := range partials {
+		if partials[idx].Sketch != nil {
+			exact = false
+			break
+		}
+	}
+	if exact {
+		values := make(map[string]struct{})
+		for idx := range partials {
+			for _, value := range partials[idx].TagValues {
+				values[value] = struct{}{}
+			}
+		}
+		merged.Values = uint64(len(values))
+		return merged, nil
+	}
+	sketch := hll.New()
+	for idx := range partials {
+		partial := partials[idx]
+		if partial.Sketch == nil {
+			for _, value := range partial.TagValues {
+				sketch.InsertString(value)
+			}
+			continue
+		}
+		other, err := hll.FromBytes(partial.Sketch)
+		if err != nil {
+			return merged, err
+		}
+		sketch.Merge(other)
+	}
+	merged.Values = sketch.Estimate()
+	merged.Estimated = true
+	return merged, nil
+}
+
+// TagValueCardinality represents the heavy hitter tag values of tag key,
+// ranked by created series or written points in the window.
+type TagValueCardinality struct {
+	MetricName string          `json:"metricName"`
+	TagKey     string          `json:"tagKey"`
+	By         string          `json:"by"`
+	Window     int64           `json:"window"` // window(millisecond)
+	Values     []TagValueCount `json:"values,omitempty"`
+}
+
+// TagValueCount represents the estimated count of tag value.
+type TagValueCount struct {
+	TagValue string `json:"tagValue"`
+	Count    uint64 `json:"count"`
+}
+
+// MergeTagValueCardinality merges the heavy hitter tag values of storage nodes, counts of same tag value
+// are summed because storage nodes have different shards, then keeps top-k tag values if k > 0.
+func MergeTagValueCardinality(results []*TagValueCardinality, k int) *TagValueCardinality {
+	merged := &TagValueCardinality{}
+	if len(results) == 0 {
+		return merged
+	}
+	merged.MetricName = results[0].MetricName
+	merged.TagKey = results[0].TagKey
+	merged.By = results[0].By
+	merged.Window = results[0].Window
+	counts := make(map[string]uint64)
+	for _, result := range results {
+		for _, value := range result.Values {
+			counts[value.TagValue] += value.Count
+		}
+	}
+	merged.Values = SortTagValueCounts(counts, k)
+	return merged
+}
+
+// SortTagValueCounts returns the tag value counts sorted by count desc, keeps top-k tag values if k > 0.
+func SortTagValueCounts(counts map[string]uint64, k int) []TagValueCount {
+	values := make([]TagValueCount, 0, len(counts))
+	for tagValue, count := range counts {
+		values = append(values, TagValueCount{TagValue: tagValue, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count == values[j].Count {
+			return values[i].TagValue < values[j].TagValue
+		}
+		return values[i].Count > values[j].Count
+	})
+	if k > 0 && len(values) > k {
+		values = values[:k]
+	}
+	return values
+}
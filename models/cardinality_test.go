@@ -0,0 +1,96 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/hll"
+)
+
+func TestMergeSeriesCardinality(t *testing.T) {
+	merged, err := MergeSeriesCardinality(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &SeriesCardinality{}, merged)
+
+	// single node, exact count
+	merged, err = MergeSeriesCardinality([]*SeriesCardinality{{
+		MetricName: "cpu",
+		Series:     10,
+		TagKeys: []TagKeyCardinality{
+			{TagKey: "host", Values: 10, TagValues: []string{"a"}},
+			{TagKey: "ip", Values: 5000, Sketch: []byte{1}},
+		},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, &SeriesCardinality{
+		MetricName: "cpu",
+		Series:     10,
+		TagKeys:    []TagKeyCardinality{{TagKey: "ip", Values: 5000}, {TagKey: "host", Values: 10}},
+	}, merged)
+
+	// multi nodes, exact tag values
+	merged, err = MergeSeriesCardinality([]*SeriesCardinality{
+		{MetricName: "cpu", Series: 2, TagKeys: []TagKeyCardinality{{TagKey: "host", Values: 2, TagValues: []string{"a", "b"}}}},
+		{MetricName: "cpu", Series: 3, TagKeys: []TagKeyCardinality{{TagKey: "host", Values: 2, TagValues: []string{"b", "c"}}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), merged.Series)
+	assert.Equal(t, []TagKeyCardinality{{TagKey: "host", Values: 3}}, merged.TagKeys)
+
+	// multi nodes, estimated by sketch
+	sketch := hll.New()
+	for i := 0; i < 2000; i++ {
+		sketch.InsertString(strconv.Itoa(i))
+	}
+	merged, err = MergeSeriesCardinality([]*SeriesCardinality{
+		{MetricName: "cpu", TagKeys: []TagKeyCardinality{{TagKey: "ip", Values: 2000, Sketch: sketch.Bytes()}}},
+		{MetricName: "cpu", TagKeys: []TagKeyCardinality{{TagKey: "ip", Values: 2, TagValues: []string{"a", "b"}}}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, merged.TagKeys[0].Estimated)
+	assert.InDelta(t, 2002, float64(merged.TagKeys[0].Values), 2002*0.03)
+
+	// bad sketch
+	_, err = MergeSeriesCardinality([]*SeriesCardinality{
+		{TagKeys: []TagKeyCardinality{{TagKey: "ip", Sketch: []byte{1}}}},
+		{TagKeys: []TagKeyCardinality{{TagKey: "ip", Sketch: []byte{1}}}},
+	})
+	assert.Error(t, err)
+}
+
+func TestMergeTagValueCardinality(t *testing.T) {
+	assert.Equal(t, &TagValueCardinality{}, MergeTagValueCardinality(nil, 10))
+
+	merged := MergeTagValueCardinality([]*TagValueCardinality{
+		{MetricName: "cpu", TagKey: "host", By: "points", Window: 100,
+			Values: []TagValueCount{{TagValue: "a", Count: 10}, {TagValue: "b", Count: 5}}},
+		{MetricName: "cpu", TagKey: "host", By: "points", Window: 100,
+			Values: []TagValueCount{{TagValue: "c", Count: 12}, {TagValue: "b", Count: 6}, {TagValue: "d", Count: 1}}},
+	}, 3)
+	assert.Equal(t, &TagValueCardinality{
+		MetricName: "cpu", TagKey: "host", By: "points", Window: 100,
+		Values: []TagValueCount{{TagValue: "c", Count: 12}, {TagValue: "b", Count: 11}, {TagValue: "a", Count: 10}},
+	}, merged)
+
+	assert.Equal(t, []TagValueCount{{TagValue: "a", Count: 1}, {TagValue: "b", Count: 1}},
+		SortTagValueCounts(map[string]uint64{"b": 1, "a": 1}, 0))
+}
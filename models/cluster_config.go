@@ -0,0 +1,73 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClusterConfig represents the cluster-wide operational config, stored in state repo,
+// so that all brokers/storages change behavior by a single api call without restart.
+type ClusterConfig struct {
+	// timeout of query, like 30s, empty means using query timeout of broker config
+	QueryTimeout string `json:"queryTimeout,omitempty"`
+	// threshold of logging slow query, like 5s, 0s means disabled, empty means using broker config
+	SlowQueryThreshold string `json:"slowQueryThreshold,omitempty"`
+	// max num. of series of each metric in shard, new series are rejected if exceeded, 0 means no limit
+	MaxSeriesPerMetric uint32 `json:"maxSeriesPerMetric,omitempty"`
+}
+
+// Validate checks if the cluster config is valid.
+func (c ClusterConfig) Validate() error {
+	if c.QueryTimeout != "" {
+		if timeout, err := time.ParseDuration(c.QueryTimeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("bad query timeout: %s", c.QueryTimeout)
+		}
+	}
+	if c.SlowQueryThreshold != "" {
+		if threshold, err := time.ParseDuration(c.SlowQueryThreshold); err != nil || threshold < 0 {
+			return fmt.Errorf("bad slow query threshold: %s", c.SlowQueryThreshold)
+		}
+	}
+	return nil
+}
+
+// GetQueryTimeout returns the timeout of query, returns defaultTimeout if not set.
+func (c ClusterConfig) GetQueryTimeout(defaultTimeout time.Duration) time.Duration {
+	if c.QueryTimeout == "" {
+		return defaultTimeout
+	}
+	timeout, err := time.ParseDuration(c.QueryTimeout)
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+// GetSlowQueryThreshold returns the threshold of logging slow query, returns defaultThreshold if not set.
+func (c ClusterConfig) GetSlowQueryThreshold(defaultThreshold time.Duration) time.Duration {
+	if c.SlowQueryThreshold == "" {
+		return defaultThreshold
+	}
+	threshold, err := time.ParseDuration(c.SlowQueryThreshold)
+	if err != nil || threshold < 0 {
+		return defaultThreshold
+	}
+	return threshold
+}
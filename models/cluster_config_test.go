@@ -0,0 +1,49 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterConfig_Validate(t *testing.T) {
+	assert.NoError(t, ClusterConfig{}.Validate())
+	assert.NoError(t, ClusterConfig{QueryTimeout: "10s", SlowQueryThreshold: "0s", MaxSeriesPerMetric: 100}.Validate())
+	assert.Error(t, ClusterConfig{QueryTimeout: "abc"}.Validate())
+	assert.Error(t, ClusterConfig{QueryTimeout: "0s"}.Validate())
+	assert.Error(t, ClusterConfig{SlowQueryThreshold: "abc"}.Validate())
+	assert.Error(t, ClusterConfig{SlowQueryThreshold: "-1s"}.Validate())
+}
+
+func TestClusterConfig_GetQueryTimeout(t *testing.T) {
+	assert.Equal(t, time.Minute, ClusterConfig{}.GetQueryTimeout(time.Minute))
+	assert.Equal(t, time.Minute, ClusterConfig{QueryTimeout: "abc"}.GetQueryTimeout(time.Minute))
+	assert.Equal(t, time.Minute, ClusterConfig{QueryTimeout: "-1s"}.GetQueryTimeout(time.Minute))
+	assert.Equal(t, 10*time.Second, ClusterConfig{QueryTimeout: "10s"}.GetQueryTimeout(time.Minute))
+}
+
+func TestClusterConfig_GetSlowQueryThreshold(t *testing.T) {
+	assert.Equal(t, time.Second, ClusterConfig{}.GetSlowQueryThreshold(time.Second))
+	assert.Equal(t, time.Second, ClusterConfig{SlowQueryThreshold: "abc"}.GetSlowQueryThreshold(time.Second))
+	assert.Equal(t, time.Second, ClusterConfig{SlowQueryThreshold: "-1s"}.GetSlowQueryThreshold(time.Second))
+	assert.Equal(t, time.Duration(0), ClusterConfig{SlowQueryThreshold: "0s"}.GetSlowQueryThreshold(time.Second))
+	assert.Equal(t, 5*time.Second, ClusterConfig{SlowQueryThreshold: "5s"}.GetSlowQueryThreshold(time.Second))
+}
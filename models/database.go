@@ -34,6 +34,9 @@ type Database struct {
 	ReplicaFactor int                   `json:"replicaFactor"`           // replica refactor
 	Option        option.DatabaseOption `json:"option"`                  // time series database option
 	Desc          string                `json:"desc,omitempty"`
+	// Revision is the modify revision of database config in state repo, used for optimistic
+	// concurrency control when saving config, 0 means creating a new database.
+	Revision int64 `json:"revision"`
 }
 
 // String returns the database's description
@@ -44,16 +47,53 @@ func (db Database) String() string {
 	return result
 }
 
+// ValidateUpdate checks if the database config can be modified from old config,
+// storage cluster and write interval/family window cannot be changed,
+// num. of shard and replica factor cannot be reduced.
+func (db Database) ValidateUpdate(old Database) error {
+	if db.Cluster != old.Cluster {
+		return fmt.Errorf("storage cluster of database cannot be changed")
+	}
+	if db.NumOfShard < old.NumOfShard {
+		return fmt.Errorf("num. of shard cannot be reduced")
+	}
+	if db.ReplicaFactor < old.ReplicaFactor {
+		return fmt.Errorf("replica factor cannot be reduced")
+	}
+	if db.Option.Interval != old.Option.Interval {
+		return fmt.Errorf("write interval of database cannot be changed")
+	}
+	if db.Option.FamilyWindow != old.Option.FamilyWindow {
+		return fmt.Errorf("family window of database cannot be changed")
+	}
+	if db.Option.IsHashRingRouting() != old.Option.IsHashRingRouting() {
+		return fmt.Errorf("shard routing of database cannot be changed")
+	}
+	return nil
+}
+
 // Replica defines replica list for spec shard of database
 type Replica struct {
 	Replicas []int `json:"replicas"`
 }
 
+// Contains checks if replica list includes the replica id
+func (r *Replica) Contains(replicaID int) bool {
+	for _, id := range r.Replicas {
+		if id == replicaID {
+			return true
+		}
+	}
+	return false
+}
+
 // ShardAssignment defines shard assignment for database
 type ShardAssignment struct {
 	Name   string           `json:"name"` // database's name
 	Nodes  map[int]*Node    `json:"nodes"`
 	Shards map[int]*Replica `json:"shards"`
+	// routing which maps series to shard on broker, empty means modulo
+	ShardRouting string `json:"shardRouting,omitempty"`
 }
 
 // NewShardAssignment returns empty shard assignment instance
@@ -74,3 +114,14 @@ func (s *ShardAssignment) AddReplica(shardID int, replicaID int) {
 	}
 	replica.Replicas = append(replica.Replicas, replicaID)
 }
+
+// MinReplicaFactor returns the min num. of replicas in all shards, returns 0 if no shard.
+func (s *ShardAssignment) MinReplicaFactor() int {
+	minReplicas := 0
+	for _, replica := range s.Shards {
+		if minReplicas == 0 || len(replica.Replicas) < minReplicas {
+			minReplicas = len(replica.Replicas)
+		}
+	}
+	return minReplicas
+}
@@ -33,6 +33,45 @@ func TestNewShardAssignment(t *testing.T) {
 	shardAssign.AddReplica(2, 5)
 	assert.Equal(t, []int{1, 2}, shardAssign.Shards[1].Replicas)
 	assert.Equal(t, []int{3, 5}, shardAssign.Shards[2].Replicas)
+	assert.True(t, shardAssign.Shards[1].Contains(2))
+	assert.False(t, shardAssign.Shards[1].Contains(3))
+	assert.Equal(t, 2, shardAssign.MinReplicaFactor())
+	shardAssign.AddReplica(3, 1)
+	assert.Equal(t, 1, shardAssign.MinReplicaFactor())
+	assert.Equal(t, 0, NewShardAssignment("test").MinReplicaFactor())
+}
+
+func TestDatabase_ValidateUpdate(t *testing.T) {
+	old := Database{
+		Name:          "test",
+		Cluster:       "cluster",
+		NumOfShard:    3,
+		ReplicaFactor: 2,
+		Option:        option.DatabaseOption{Interval: "10s", Ahead: "1h"},
+	}
+	newDB := old
+	newDB.Option.Ahead = "2h"
+	newDB.Option.Rollup = []string{"5m"}
+	newDB.NumOfShard = 5
+	newDB.ReplicaFactor = 3
+	assert.NoError(t, newDB.ValidateUpdate(old))
+	// empty shard routing is modulo
+	newDB.Option.ShardRouting = option.ShardRoutingModulo
+	assert.NoError(t, newDB.ValidateUpdate(old))
+
+	cases := []func(db *Database){
+		func(db *Database) { db.Cluster = "other" },
+		func(db *Database) { db.NumOfShard = 2 },
+		func(db *Database) { db.ReplicaFactor = 1 },
+		func(db *Database) { db.Option.Interval = "1m" },
+		func(db *Database) { db.Option.FamilyWindow = "10m" },
+		func(db *Database) { db.Option.ShardRouting = option.ShardRoutingHashRing },
+	}
+	for _, modify := range cases {
+		db := old
+		modify(&db)
+		assert.Error(t, db.ValidateUpdate(old))
+	}
 }
 
 func TestDatabase_String(t *testing.T) {
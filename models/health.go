@@ -0,0 +1,34 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+// ComponentHealth represents the health status of a server component.
+type ComponentHealth struct {
+	Name      string `json:"name"`
+	Healthy   bool   `json:"healthy"`
+	Error     string `json:"error,omitempty"`     // last start error
+	Failures  int    `json:"failures"`            // number of start failures
+	StartTime int64  `json:"startTime,omitempty"` // start successfully time
+}
+
+// NodeHealth represents the health status of current node,
+// node is degraded if any non-critical component is unhealthy.
+type NodeHealth struct {
+	Degraded   bool              `json:"degraded"`
+	Components []ComponentHealth `json:"components,omitempty"`
+}
@@ -21,6 +21,8 @@ package models
 type Metadata struct {
 	Type   string      `json:"type"`
 	Values interface{} `json:"values"`
+	// Total is the hint of matched values count before pagination.
+	Total int `json:"total,omitempty"`
 }
 
 // Field represents field metadata
@@ -0,0 +1,104 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"errors"
+	"sort"
+)
+
+// MetricDescriptor represents the metadata of metric, like unit/description/expected field types,
+// which is used by UI for axis labels and help text.
+type MetricDescriptor struct {
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"` // gauge/counter/histogram/summary
+	Unit        string `json:"unit,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Fields are the expected fields of metric with field type
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// Validate checks if the descriptor is valid.
+func (d *MetricDescriptor) Validate() error {
+	if d.Name == "" {
+		return errors.New("metric name cannot be empty")
+	}
+	names := make(map[string]struct{}, len(d.Fields))
+	for _, f := range d.Fields {
+		if f.Name == "" {
+			return errors.New("field name cannot be empty")
+		}
+		if _, ok := names[f.Name]; ok {
+			return errors.New("duplicate field name: " + f.Name)
+		}
+		names[f.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Fill fills the absent unit/description/type/fields with the inferred descriptor,
+// the attributes set explicitly are kept, returns true if descriptor changed.
+func (d *MetricDescriptor) Fill(inferred *MetricDescriptor) (changed bool) {
+	if d.Type == "" && inferred.Type != "" {
+		d.Type = inferred.Type
+		changed = true
+	}
+	if d.Unit == "" && inferred.Unit != "" {
+		d.Unit = inferred.Unit
+		changed = true
+	}
+	if d.Description == "" && inferred.Description != "" {
+		d.Description = inferred.Description
+		changed = true
+	}
+	names := make(map[string]struct{}, len(d.Fields))
+	for _, f := range d.Fields {
+		names[f.Name] = struct{}{}
+	}
+	for _, f := range inferred.Fields {
+		if _, ok := names[f.Name]; !ok {
+			d.Fields = append(d.Fields, f)
+			names[f.Name] = struct{}{}
+			changed = true
+		}
+	}
+	if changed {
+		sort.Slice(d.Fields, func(i, j int) bool {
+			return d.Fields[i].Name < d.Fields[j].Name
+		})
+	}
+	return changed
+}
+
+// MergeMetricDescriptors merges the descriptors of same metric returned by storage nodes,
+// the first descriptor takes precedence, returns nil if no descriptor.
+func MergeMetricDescriptors(descriptors []*MetricDescriptor) *MetricDescriptor {
+	var merged *MetricDescriptor
+	for _, descriptor := range descriptors {
+		if descriptor == nil {
+			continue
+		}
+		if merged == nil {
+			merged = descriptor
+			continue
+		}
+		merged.Fill(descriptor)
+	}
+	return merged
+}
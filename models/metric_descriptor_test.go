@@ -0,0 +1,61 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricDescriptor_Validate(t *testing.T) {
+	assert.Error(t, (&MetricDescriptor{}).Validate())
+	assert.Error(t, (&MetricDescriptor{Name: "cpu", Fields: []Field{{Type: "sum"}}}).Validate())
+	assert.Error(t, (&MetricDescriptor{Name: "cpu", Fields: []Field{{Name: "f"}, {Name: "f"}}}).Validate())
+	assert.NoError(t, (&MetricDescriptor{Name: "cpu", Fields: []Field{{Name: "f", Type: "sum"}}}).Validate())
+}
+
+func TestMetricDescriptor_Fill(t *testing.T) {
+	descriptor := &MetricDescriptor{Name: "cpu", Unit: "percent", Fields: []Field{{Name: "usage", Type: "gauge"}}}
+	assert.True(t, descriptor.Fill(&MetricDescriptor{
+		Name:        "cpu",
+		Type:        "gauge",
+		Unit:        "seconds",
+		Description: "cpu usage",
+		Fields:      []Field{{Name: "usage", Type: "sum"}, {Name: "idle", Type: "gauge"}},
+	}))
+	assert.Equal(t, &MetricDescriptor{
+		Name:        "cpu",
+		Type:        "gauge",
+		Unit:        "percent",
+		Description: "cpu usage",
+		Fields:      []Field{{Name: "idle", Type: "gauge"}, {Name: "usage", Type: "gauge"}},
+	}, descriptor)
+	assert.False(t, descriptor.Fill(&MetricDescriptor{Name: "cpu", Unit: "bytes"}))
+}
+
+func TestMergeMetricDescriptors(t *testing.T) {
+	assert.Nil(t, MergeMetricDescriptors(nil))
+	assert.Nil(t, MergeMetricDescriptors([]*MetricDescriptor{nil}))
+	assert.Equal(t, &MetricDescriptor{Name: "cpu", Unit: "percent", Description: "cpu usage"},
+		MergeMetricDescriptors([]*MetricDescriptor{
+			nil,
+			{Name: "cpu", Unit: "percent"},
+			{Name: "cpu", Unit: "bytes", Description: "cpu usage"},
+		}))
+}
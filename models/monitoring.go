@@ -28,6 +28,22 @@ type NodeStat struct {
 	System   SystemStat `json:"system,omitempty"`
 	Replicas int        `json:"replicas"` // the number of replica under the node
 	IsDead   bool       `json:"isDead"`
+	// Capacity is reported by storage node, used by broker for disk-aware shard placement.
+	Capacity NodeCapacity `json:"capacity"`
+}
+
+// NodeCapacity represents the disk capacity/usage and write throughput self-described by storage node.
+type NodeCapacity struct {
+	DiskTotal uint64 `json:"diskTotal"` // bytes of data dir's disk
+	DiskUsed  uint64 `json:"diskUsed"`
+	DiskFree  uint64 `json:"diskFree"`
+	// WriteThroughput is the num. of metrics written per second during last collect interval.
+	WriteThroughput float64 `json:"writeThroughput"`
+}
+
+// Reported returns if the capacity is reported by node.
+func (c NodeCapacity) Reported() bool {
+	return c.DiskTotal > 0
 }
 
 // StorageClusterStat represents the storage cluster's stat
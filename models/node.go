@@ -34,6 +34,11 @@ type Node struct {
 	Port     uint16 `json:"port"`
 	HTTPPort uint16 `json:"httpPort"`
 	HostName string `json:"hostName"`
+	// Zone/Rack are location labels of node, used by zone aware replica placement.
+	Zone string `json:"zone,omitempty"`
+	Rack string `json:"rack,omitempty"`
+	// ReadOnly represents the storage node receives no new shard assignments and writes, but still serves queries.
+	ReadOnly bool `json:"readOnly,omitempty"`
 }
 
 // Indicator returns return node indicator's string
@@ -23,6 +23,11 @@ type PhysicalPlan struct {
 	Root          Root           `json:"root"`          // root node
 	Intermediates []Intermediate `json:"intermediates"` // intermediate node if need
 	Leafs         []Leaf         `json:"leafs"`         // leaf nodes(storage nodes of query database)
+	// hedged leaf tasks sent to other replicas after hedge delay(ms), if leaf doesn't respond
+	HedgeDelay int64       `json:"hedgeDelay,omitempty"`
+	Hedges     []HedgeLeaf `json:"hedges,omitempty"`
+	// other replica nodes which hold all shards of leaf, for retrying leaf task on transient errors
+	Failovers []FailoverLeaf `json:"failovers,omitempty"`
 }
 
 // NewPhysicalPlan creates the physical plan with root node
@@ -40,6 +45,26 @@ func (t *PhysicalPlan) AddLeaf(leaf Leaf) {
 	t.Leafs = append(t.Leafs, leaf)
 }
 
+// AddHedge adds a hedged leaf node into the hedge list
+func (t *PhysicalPlan) AddHedge(hedge HedgeLeaf) {
+	t.Hedges = append(t.Hedges, hedge)
+}
+
+// AddFailover adds the failover replicas of leaf node into the failover list
+func (t *PhysicalPlan) AddFailover(failover FailoverLeaf) {
+	t.Failovers = append(t.Failovers, failover)
+}
+
+// GetFailover returns the failover replicas of leaf node
+func (t *PhysicalPlan) GetFailover(primary string) []string {
+	for _, failover := range t.Failovers {
+		if failover.Primary == primary {
+			return failover.Replicas
+		}
+	}
+	return nil
+}
+
 // Root represents the root node info
 type Root struct {
 	Indicator string `json:"indicator"`
@@ -65,3 +90,18 @@ type Leaf struct {
 	Receivers []Node  `json:"receivers"`
 	ShardIDs  []int32 `json:"shardIDs"`
 }
+
+// HedgeLeaf represents the hedged leaf node, which searches the same shards of primary leaf in other replicas,
+// the root takes whichever of primary/hedged leaf responds first.
+type HedgeLeaf struct {
+	Leaf
+
+	Primary string `json:"primary"` // primary leaf node's indicator
+}
+
+// FailoverLeaf represents the other replica nodes which hold all shards of primary leaf,
+// the leaf task is retried in these replicas(the fastest first) if primary leaf returns transient errors.
+type FailoverLeaf struct {
+	Primary  string   `json:"primary"`  // primary leaf node's indicator
+	Replicas []string `json:"replicas"` // replica nodes' indicator
+}
@@ -61,3 +61,11 @@ func TestPhysicalPlan(t *testing.T) {
 		}},
 	}, *physicalPlan)
 }
+
+func TestPhysicalPlan_Failover(t *testing.T) {
+	physicalPlan := NewPhysicalPlan(Root{Indicator: "1.1.1.3:8000", NumOfTask: 1})
+	assert.Nil(t, physicalPlan.GetFailover("1.1.1.1:9000"))
+	physicalPlan.AddFailover(FailoverLeaf{Primary: "1.1.1.1:9000", Replicas: []string{"1.1.1.2:9000"}})
+	assert.Equal(t, []string{"1.1.1.2:9000"}, physicalPlan.GetFailover("1.1.1.1:9000"))
+	assert.Nil(t, physicalPlan.GetFailover("1.1.1.2:9000"))
+}
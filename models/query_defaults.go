@@ -0,0 +1,103 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+// QueryDefaults represents the cluster-wide query defaults, stored in state repo,
+// so that all brokers execute query with the same defaults.
+type QueryDefaults struct {
+	TimeRange string `json:"timeRange"` // default time range if query without start time, like 1h
+	// max points of per series, downsampling interval is widened to fit it(like max points hint of query),
+	// query is rejected only if interval cannot be widened(outer query of sub query), 0 means no limit
+	MaxPoints      int    `json:"maxPoints"`
+	Timezone       string `json:"timezone,omitempty"` // timezone for parsing time literal, empty means local zone
+	PartialResults bool   `json:"partialResults"`     // allow returning partial results if some shards unavailable
+	// delay before sending hedged leaf request to other replica if leaf doesn't respond, like 100ms,
+	// empty means hedging is disabled
+	HedgeDelay string `json:"hedgeDelay,omitempty"`
+}
+
+// NewDefaultQueryDefaults returns the query defaults used if no cluster-wide config.
+func NewDefaultQueryDefaults() QueryDefaults {
+	return QueryDefaults{
+		TimeRange:      "1h",
+		PartialResults: true,
+	}
+}
+
+// Validate checks if the query defaults are valid.
+func (q QueryDefaults) Validate() error {
+	var timeRange timeutil.Interval
+	if err := timeRange.ValueOf(q.TimeRange); err != nil {
+		return fmt.Errorf("bad time range: %s", q.TimeRange)
+	}
+	if timeRange <= 0 {
+		return fmt.Errorf("time range must be > 0")
+	}
+	if q.MaxPoints < 0 {
+		return fmt.Errorf("max points cannot be negative")
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("bad timezone: %s", q.Timezone)
+	}
+	if q.HedgeDelay != "" {
+		if delay, err := time.ParseDuration(q.HedgeDelay); err != nil || delay < 0 {
+			return fmt.Errorf("bad hedge delay: %s", q.HedgeDelay)
+		}
+	}
+	return nil
+}
+
+// GetTimeRange returns the default time range(millisecond), if not set returns one hour.
+func (q QueryDefaults) GetTimeRange() int64 {
+	var timeRange timeutil.Interval
+	if err := timeRange.ValueOf(q.TimeRange); err != nil || timeRange <= 0 {
+		return timeutil.OneHour
+	}
+	return timeRange.Int64()
+}
+
+// GetLocation returns the location of timezone, if not set or invalid returns local zone.
+func (q QueryDefaults) GetLocation() *time.Location {
+	if q.Timezone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// GetHedgeDelay returns the delay of hedged leaf request, returns 0 if hedging is disabled.
+func (q QueryDefaults) GetHedgeDelay() time.Duration {
+	if q.HedgeDelay == "" {
+		return 0
+	}
+	delay, err := time.ParseDuration(q.HedgeDelay)
+	if err != nil || delay < 0 {
+		return 0
+	}
+	return delay
+}
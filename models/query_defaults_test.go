@@ -0,0 +1,60 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+func TestQueryDefaults_Validate(t *testing.T) {
+	defaults := NewDefaultQueryDefaults()
+	assert.NoError(t, defaults.Validate())
+
+	assert.Error(t, QueryDefaults{TimeRange: "abc"}.Validate())
+	assert.Error(t, QueryDefaults{TimeRange: "-1h"}.Validate())
+	assert.Error(t, QueryDefaults{TimeRange: "1h", MaxPoints: -1}.Validate())
+	assert.Error(t, QueryDefaults{TimeRange: "1h", Timezone: "bad/zone"}.Validate())
+	assert.Error(t, QueryDefaults{TimeRange: "1h", HedgeDelay: "abc"}.Validate())
+	assert.Error(t, QueryDefaults{TimeRange: "1h", HedgeDelay: "-1s"}.Validate())
+	assert.NoError(t, QueryDefaults{TimeRange: "1d", MaxPoints: 100, Timezone: "UTC"}.Validate())
+	assert.NoError(t, QueryDefaults{TimeRange: "1d", HedgeDelay: "100ms"}.Validate())
+}
+
+func TestQueryDefaults_GetTimeRange(t *testing.T) {
+	assert.Equal(t, timeutil.OneHour, QueryDefaults{}.GetTimeRange())
+	assert.Equal(t, timeutil.OneHour, QueryDefaults{TimeRange: "-1d"}.GetTimeRange())
+	assert.Equal(t, timeutil.OneDay, QueryDefaults{TimeRange: "1d"}.GetTimeRange())
+}
+
+func TestQueryDefaults_GetLocation(t *testing.T) {
+	assert.Equal(t, time.Local, QueryDefaults{}.GetLocation())
+	assert.Equal(t, time.Local, QueryDefaults{Timezone: "bad/zone"}.GetLocation())
+	assert.Equal(t, time.UTC, QueryDefaults{Timezone: "UTC"}.GetLocation())
+}
+
+func TestQueryDefaults_GetHedgeDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), QueryDefaults{}.GetHedgeDelay())
+	assert.Equal(t, time.Duration(0), QueryDefaults{HedgeDelay: "abc"}.GetHedgeDelay())
+	assert.Equal(t, time.Duration(0), QueryDefaults{HedgeDelay: "-1s"}.GetHedgeDelay())
+	assert.Equal(t, 100*time.Millisecond, QueryDefaults{HedgeDelay: "100ms"}.GetHedgeDelay())
+}
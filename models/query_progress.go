@@ -0,0 +1,42 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+// QueryProgress represents the execution progress of a running metric query,
+// used by ui for showing the progress of long-running query.
+type QueryProgress struct {
+	QueryID        string         `json:"queryId"`
+	TotalTasks     int32          `json:"totalTasks"`     // number of responses expected from leaf/intermediate nodes
+	CompletedTasks int32          `json:"completedTasks"` // number of responses received
+	MergedGroups   int64          `json:"mergedGroups"`   // number of time series merged into result
+	ReceivedBytes  ltoml.Size     `json:"receivedBytes"`  // size of responses received
+	Elapsed        ltoml.Duration `json:"elapsed"`
+	Done           bool           `json:"done"`
+}
+
+// Percent returns the percent of completed tasks.
+func (p *QueryProgress) Percent() float64 {
+	if p.TotalTasks <= 0 {
+		return 100
+	}
+	return float64(p.CompletedTasks) * 100 / float64(p.TotalTasks)
+}
@@ -32,6 +32,10 @@ type QueryStats struct {
 	WaitCost     ltoml.Duration           `json:"waitCost,omitempty"` // wait intermediate or leaf response duration
 	ExpressCost  ltoml.Duration           `json:"expressCost,omitempty"`
 	TotalCost    ltoml.Duration           `json:"totalCost,omitempty"` // total query cost
+	// totals of all leaf nodes
+	SeriesScanned uint64     `json:"seriesScanned,omitempty"`
+	BlocksDecoded uint64     `json:"blocksDecoded,omitempty"`
+	BytesRead     ltoml.Size `json:"bytesRead,omitempty"`
 }
 
 // NewQueryStats creates the query stats
@@ -45,11 +49,17 @@ func NewQueryStats() *QueryStats {
 // MergeBrokerTaskStats merges intermediate task execution stats
 func (s *QueryStats) MergeBrokerTaskStats(nodeID string, stats *QueryStats) {
 	s.BrokerNodes[nodeID] = stats
+	s.SeriesScanned += stats.SeriesScanned
+	s.BlocksDecoded += stats.BlocksDecoded
+	s.BytesRead += stats.BytesRead
 }
 
 // MergeStorageTaskStats merges storage task execution stats
 func (s *QueryStats) MergeStorageTaskStats(nodeID string, stats *StorageStats) {
 	s.StorageNodes[nodeID] = stats
+	s.SeriesScanned += stats.SeriesScanned
+	s.BlocksDecoded += stats.BlocksDecoded
+	s.BytesRead += stats.BytesRead
 }
 
 // StorageStats represents query stats in storage side
@@ -60,6 +70,10 @@ type StorageStats struct {
 	TagFilterCost         ltoml.Duration            `json:"tagFilterCost"`
 	Shards                map[int32]*ShardStats     `json:"shards,omitempty"`
 	CollectTagValuesStats map[string]ltoml.Duration `json:"collectTagValuesStats,omitempty"`
+	// totals of all shards, summed when query completed
+	SeriesScanned uint64     `json:"seriesScanned"`
+	BlocksDecoded uint64     `json:"blocksDecoded"`
+	BytesRead     ltoml.Size `json:"bytesRead"`
 
 	start time.Time  // track search start time in storage side
 	mutex sync.Mutex // need add lock for goroutine update stats data
@@ -79,6 +93,12 @@ func (s *StorageStats) Complete() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.TotalCost = ltoml.Duration(time.Since(s.start))
+	s.SeriesScanned, s.BlocksDecoded, s.BytesRead = 0, 0, 0
+	for _, shard := range s.Shards {
+		s.SeriesScanned += shard.SeriesScanned
+		s.BlocksDecoded += shard.BlocksDecoded
+		s.BytesRead += shard.BytesRead
+	}
 }
 
 // SetPlanCost sets plan cost
@@ -100,10 +120,29 @@ func (s *StorageStats) SetShardSeriesIDsSearchStats(shardID int32, numOfSeries u
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	stats := newShardStats()
+	stats := s.getOrCreateShardStats(shardID)
 	stats.NumOfSeries = numOfSeries
 	stats.SeriesFilterCost = ltoml.Duration(seriesFilterCost)
-	s.Shards[shardID] = stats
+}
+
+// SetShardExecuteCost sets the cost of waiting for parallelism budget and executing filtering in shard level
+func (s *StorageStats) SetShardExecuteCost(shardID int32, waitCost, executeCost time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.getOrCreateShardStats(shardID)
+	stats.WaitCost = ltoml.Duration(waitCost)
+	stats.ExecuteCost = ltoml.Duration(executeCost)
+}
+
+// getOrCreateShardStats returns the shard level stats, creates it if not exist
+func (s *StorageStats) getOrCreateShardStats(shardID int32) *ShardStats {
+	stats, ok := s.Shards[shardID]
+	if !ok {
+		stats = newShardStats()
+		s.Shards[shardID] = stats
+	}
+	return stats
 }
 
 // SetShardMemoryDataFilterCost sets shard memory data filter cost
@@ -146,6 +185,20 @@ func (s *StorageStats) SetShardScanStats(shardID int32, identifier string, cost
 	}
 }
 
+// SetShardLoadStats accumulates the data load stats in shard level,
+// includes num. of series scanned, field data blocks decoded, bytes read and cost of load/decode.
+func (s *StorageStats) SetShardLoadStats(shardID int32, seriesScanned, blocksDecoded, bytesRead uint64, cost time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stats, ok := s.Shards[shardID]
+	if ok {
+		stats.SeriesScanned += seriesScanned
+		stats.BlocksDecoded += blocksDecoded
+		stats.BytesRead += ltoml.Size(bytesRead)
+		stats.LoadCost += ltoml.Duration(cost)
+	}
+}
+
 // SetShardGroupBuildStats sets grouping build stats in shard level
 func (s *StorageStats) SetShardGroupBuildStats(shardID int32, cost time.Duration) {
 	s.mutex.Lock()
@@ -165,11 +218,17 @@ func (s *StorageStats) SetCollectTagValuesStats(tagKey string, cost time.Duratio
 
 // ShardStats represents the shard level stats
 type ShardStats struct {
+	WaitCost         ltoml.Duration    `json:"waitCost"`    // waiting for shard parallelism budget
+	ExecuteCost      ltoml.Duration    `json:"executeCost"` // series search and data filtering
 	SeriesFilterCost ltoml.Duration    `json:"seriesFilterCost"`
 	NumOfSeries      uint64            `json:"numOfSeries"`
 	MemFilterCost    ltoml.Duration    `json:"memFilterCost"`
 	KVFilterCost     ltoml.Duration    `json:"kvFilterCost"`
 	GroupingCost     ltoml.Duration    `json:"groupingCost"`
+	SeriesScanned    uint64            `json:"seriesScanned"`
+	BlocksDecoded    uint64            `json:"blocksDecoded"`
+	BytesRead        ltoml.Size        `json:"bytesRead"`
+	LoadCost         ltoml.Duration    `json:"loadCost"` // load and decode data of all series
 	ScanStats        map[string]*Stats `json:"scanStats,omitempty"`
 	GroupBuildStats  *Stats            `json:"groupBuildStats,omitempty"`
 }
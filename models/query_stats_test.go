@@ -33,6 +33,35 @@ func TestNewQueryStats(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestQueryStats_Totals(t *testing.T) {
+	storageStats := NewStorageStats()
+	storageStats.SetShardLoadStats(1, 10, 20, 1024, 10)
+	assert.Empty(t, storageStats.Shards)
+	storageStats.SetShardSeriesIDsSearchStats(1, 10, 10)
+	storageStats.SetShardSeriesIDsSearchStats(2, 10, 10)
+	storageStats.SetShardLoadStats(1, 10, 20, 1024, 10)
+	storageStats.SetShardLoadStats(1, 5, 10, 512, 10)
+	storageStats.SetShardLoadStats(2, 1, 2, 100, 10)
+	shard := storageStats.Shards[1]
+	assert.Equal(t, uint64(15), shard.SeriesScanned)
+	assert.Equal(t, uint64(30), shard.BlocksDecoded)
+	assert.Equal(t, ltoml.Size(1536), shard.BytesRead)
+	assert.Equal(t, ltoml.Duration(20), shard.LoadCost)
+	storageStats.Complete()
+	assert.Equal(t, uint64(16), storageStats.SeriesScanned)
+	assert.Equal(t, uint64(32), storageStats.BlocksDecoded)
+	assert.Equal(t, ltoml.Size(1636), storageStats.BytesRead)
+
+	brokerStats := NewQueryStats()
+	brokerStats.MergeStorageTaskStats("1.1.1.1:9000", storageStats)
+	stats := NewQueryStats()
+	stats.MergeBrokerTaskStats("1.1.1.2:9000", brokerStats)
+	stats.MergeStorageTaskStats("1.1.1.3:9000", storageStats)
+	assert.Equal(t, uint64(32), stats.SeriesScanned)
+	assert.Equal(t, uint64(64), stats.BlocksDecoded)
+	assert.Equal(t, ltoml.Size(3272), stats.BytesRead)
+}
+
 func TestStorageStats(t *testing.T) {
 	stats := NewStorageStats()
 	stats.SetPlanCost(10)
@@ -77,6 +106,18 @@ func TestStorageStats(t *testing.T) {
 	assert.Equal(t, 2, scan.Count)
 }
 
+func TestStorageStats_ShardExecuteCost(t *testing.T) {
+	stats := NewStorageStats()
+	stats.SetShardExecuteCost(10, 5, 20)
+	stats.SetShardSeriesIDsSearchStats(10, 100, 10)
+	shard, ok := stats.Shards[10]
+	assert.True(t, ok)
+	assert.Equal(t, ltoml.Duration(5), shard.WaitCost)
+	assert.Equal(t, ltoml.Duration(20), shard.ExecuteCost)
+	assert.Equal(t, ltoml.Duration(10), shard.SeriesFilterCost)
+	assert.Equal(t, uint64(100), shard.NumOfSeries)
+}
+
 func TestShardStats(t *testing.T) {
 	stats := newShardStats()
 	stats.SetGroupBuildStats(10)
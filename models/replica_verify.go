@@ -0,0 +1,108 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+// FamilyChecksum represents the checksum of data points in one family of shard replica,
+// the checksum doesn't depend on the order of series, so that replicas with same data have same checksum.
+type FamilyChecksum struct {
+	FamilyTime int64  `json:"familyTime"`
+	EndTime    int64  `json:"endTime"`
+	Series     int    `json:"series"`
+	Points     int    `json:"points"`
+	Checksum   uint64 `json:"checksum"`
+}
+
+// Equal checks if the data of two families is same.
+func (f *FamilyChecksum) Equal(o *FamilyChecksum) bool {
+	if f == nil || o == nil {
+		return f == o
+	}
+	return f.Series == o.Series && f.Points == o.Points && f.Checksum == o.Checksum
+}
+
+// ShardChecksum represents the family checksums of one shard replica, families are sorted by family time.
+type ShardChecksum struct {
+	ShardID    int32            `json:"shardId"`
+	Namespaces []string         `json:"namespaces,omitempty"`
+	Families   []FamilyChecksum `json:"families,omitempty"`
+}
+
+// ReplicaVerifyResult represents the result of replica verify task reported by storage node.
+type ReplicaVerifyResult struct {
+	Node   string          `json:"node"`
+	Shards []ShardChecksum `json:"shards,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// FamilyReplica represents the checksum of family on one replica, checksum is nil if replica has no data in family.
+type FamilyReplica struct {
+	Node     string          `json:"node"`
+	Checksum *FamilyChecksum `json:"checksum,omitempty"`
+}
+
+// FamilyDivergence represents the family whose data differs between replicas of shard.
+type FamilyDivergence struct {
+	ShardID    int32           `json:"shardId"`
+	FamilyTime int64           `json:"familyTime"`
+	EndTime    int64           `json:"endTime"`
+	Replicas   []FamilyReplica `json:"replicas"`
+	// Repairable represents the family is only missing on some replicas, and others have same data,
+	// so that the data can be copied from the replica which has the data.
+	Repairable bool `json:"repairable"`
+}
+
+// RepairSource returns the source replica which has the data, and the target replicas which miss the family.
+func (d *FamilyDivergence) RepairSource() (source string, targets []string, ok bool) {
+	var sourceChecksum *FamilyChecksum
+	for _, replica := range d.Replicas {
+		switch {
+		case replica.Checksum == nil:
+			targets = append(targets, replica.Node)
+		case sourceChecksum == nil:
+			source = replica.Node
+			sourceChecksum = replica.Checksum
+		case !sourceChecksum.Equal(replica.Checksum):
+			return "", nil, false
+		}
+	}
+	if source == "" || len(targets) == 0 {
+		return "", nil, false
+	}
+	return source, targets, true
+}
+
+// ReplicaVerifyFailure represents the replica verify task failure of storage node.
+type ReplicaVerifyFailure struct {
+	Node  string `json:"node"`
+	Error string `json:"error"`
+}
+
+// ReplicaVerifyReport represents the divergence report of replica verify task.
+type ReplicaVerifyReport struct {
+	Name      string                 `json:"name"`
+	Database  string                 `json:"database"`
+	Completed bool                   `json:"completed"`
+	Pending   []string               `json:"pending,omitempty"`
+	Failures  []ReplicaVerifyFailure `json:"failures,omitempty"`
+	// Shards/Families are the number of shards/families which are compared.
+	Shards      int                `json:"shards"`
+	Families    int                `json:"families"`
+	Divergences []FamilyDivergence `json:"divergences,omitempty"`
+	// RepairTasks is the number of submitted repair tasks.
+	RepairTasks int `json:"repairTasks,omitempty"`
+}
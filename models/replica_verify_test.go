@@ -0,0 +1,51 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFamilyChecksum_Equal(t *testing.T) {
+	f := &FamilyChecksum{FamilyTime: 10, Series: 2, Points: 10, Checksum: 100}
+	assert.True(t, f.Equal(&FamilyChecksum{FamilyTime: 10, EndTime: 20, Series: 2, Points: 10, Checksum: 100}))
+	assert.False(t, f.Equal(&FamilyChecksum{Series: 2, Points: 10, Checksum: 101}))
+	assert.False(t, f.Equal(nil))
+	var empty *FamilyChecksum
+	assert.True(t, empty.Equal(nil))
+}
+
+func TestFamilyDivergence_RepairSource(t *testing.T) {
+	checksum := &FamilyChecksum{Series: 2, Points: 10, Checksum: 100}
+	// missing on one replica
+	d := &FamilyDivergence{Replicas: []FamilyReplica{{Node: "a"}, {Node: "b", Checksum: checksum}, {Node: "c", Checksum: checksum}}}
+	source, targets, ok := d.RepairSource()
+	assert.True(t, ok)
+	assert.Equal(t, "b", source)
+	assert.Equal(t, []string{"a"}, targets)
+	// replicas have different data
+	d = &FamilyDivergence{Replicas: []FamilyReplica{{Node: "a"}, {Node: "b", Checksum: checksum},
+		{Node: "c", Checksum: &FamilyChecksum{Series: 1}}}}
+	_, _, ok = d.RepairSource()
+	assert.False(t, ok)
+	d = &FamilyDivergence{Replicas: []FamilyReplica{{Node: "b", Checksum: checksum}, {Node: "c", Checksum: &FamilyChecksum{Series: 1}}}}
+	_, _, ok = d.RepairSource()
+	assert.False(t, ok)
+}
@@ -39,3 +39,37 @@ type ReplicaState struct {
 func (r ReplicaState) ShardIndicator() string {
 	return fmt.Sprintf("%s/%d", r.Database, r.ShardID)
 }
+
+// DatabaseChannelTopology represents the replication channel topology of database under broker,
+// database -> shard -> target storage node.
+type DatabaseChannelTopology struct {
+	Database   string                 `json:"database"`   // database name
+	NumOfShard int32                  `json:"numOfShard"` // num. of shard
+	Shards     []ShardChannelTopology `json:"shards"`     // shard level channel list
+}
+
+// ShardChannelTopology represents the shard level replication channel topology
+type ShardChannelTopology struct {
+	ShardID         int32          `json:"shardID"`         // shard id
+	BufferedMetrics int            `json:"bufferedMetrics"` // the num. of metrics buffered in chunk, not appended into wal
+	AppendSeq       int64          `json:"appendSeq"`       // last appended sequence of wal, -1 if nothing appended
+	QueueDepth      int64          `json:"queueDepth"`      // the num. of messages in wal not acked by all replicas
+	QueueDiskSize   int64          `json:"queueDiskSize"`   // the size of wal on disk
+	ReplaySeq       int64          `json:"replaySeq"`       // message after replay sequence is retained for replay
+	Replicas        []ReplicaState `json:"replicas"`        // replicator state of each target storage node
+}
+
+// HashRing represents the routing of database under broker, which maps series to shard.
+type HashRing struct {
+	Database     string          `json:"database"`     // database name
+	Routing      string          `json:"routing"`      // shard routing(modulo/hashRing)
+	NumOfShard   int32           `json:"numOfShard"`   // num. of shard
+	VirtualNodes int             `json:"virtualNodes"` // num. of virtual nodes of each shard
+	Shards       []HashRingShard `json:"shards"`       // hash space owned by each shard
+}
+
+// HashRingShard represents the hash space owned by shard on hash ring.
+type HashRingShard struct {
+	ShardID   int32   `json:"shardID"`   // shard id
+	Ownership float64 `json:"ownership"` // ratio of hash space owned by shard
+}
@@ -17,9 +17,48 @@
 
 package models
 
+import (
+	"sort"
+	"strings"
+)
+
+// PointFlag represents the quality flags of data point, multiple flags are combined as bitmap.
+type PointFlag uint8
+
+const (
+	// PointFill marks the point synthesized by fill/interpolation, not observed.
+	PointFill PointFlag = 1 << iota
+	// PointPartial marks the point calculated without data of some unavailable shards.
+	PointPartial
+	// PointDownSampled marks the point rolled up from data points with smaller interval.
+	PointDownSampled
+)
+
+// Has returns if the flag bitmap contains the given flag.
+func (f PointFlag) Has(flag PointFlag) bool {
+	return f&flag != 0
+}
+
+// String returns the flag names joined by '|'.
+func (f PointFlag) String() string {
+	var names []string
+	if f.Has(PointFill) {
+		names = append(names, "fill")
+	}
+	if f.Has(PointPartial) {
+		names = append(names, "partial")
+	}
+	if f.Has(PointDownSampled) {
+		names = append(names, "downsampled")
+	}
+	return strings.Join(names, "|")
+}
+
 // SuggestResult represents the suggest result set
 type SuggestResult struct {
 	Values []string `json:"values"`
+	// Total is the hint of matched values count before pagination.
+	Total int `json:"total,omitempty"`
 }
 
 // ResultSet represents the query result set
@@ -30,6 +69,20 @@ type ResultSet struct {
 	Interval   int64       `json:"interval,omitempty"`
 	Series     []*Series   `json:"series,omitempty"`
 	Stats      *QueryStats `json:"stats,omitempty"`
+	Warnings   []string    `json:"warnings,omitempty"`
+	// Failures are the failed/timed out nodes if partial results are returned.
+	Failures []NodeFailure `json:"failures,omitempty"`
+	// Flags are the quality flags applied to all points of result set.
+	Flags PointFlag `json:"flags,omitempty"`
+	// Fields are the field names of series in order of select list, alias is used if set.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// NodeFailure represents the failed/timed out node of query, the data of its shards is missing in result set.
+type NodeFailure struct {
+	Node     string  `json:"node"`
+	ShardIDs []int32 `json:"shardIDs,omitempty"`
+	Error    string  `json:"error"`
 }
 
 // NewResultSet creates a new result set
@@ -42,10 +95,53 @@ func (rs *ResultSet) AddSeries(series *Series) {
 	rs.Series = append(rs.Series, series)
 }
 
+// PointFlags returns the quality flags of the point of series' field,
+// combines flags of result set and flags of the point.
+func (rs *ResultSet) PointFlags(series *Series, fieldName string, timestamp int64) PointFlag {
+	return rs.Flags | series.Flags[fieldName][timestamp]
+}
+
+// SortFieldNames sorts the field names of series by select list of result set, others are sorted by name.
+func (rs *ResultSet) SortFieldNames(names []string) []string {
+	position := make(map[string]int, len(rs.Fields))
+	for idx, name := range rs.Fields {
+		position[name] = idx
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, iok := position[names[i]]
+		pj, jok := position[names[j]]
+		switch {
+		case iok && jok:
+			return pi < pj
+		case iok != jok:
+			return iok
+		default:
+			return names[i] < names[j]
+		}
+	})
+	return names
+}
+
 // Series represents one time series for metric
 type Series struct {
 	Tags   map[string]string            `json:"tags,omitempty"`
 	Fields map[string]map[int64]float64 `json:"fields,omitempty"`
+	// Strings are the values of string fields, like deploy markers, versions and annotations.
+	Strings map[string]map[int64]string `json:"strings,omitempty"`
+	// Flags are the quality flags of points, only contains flagged points.
+	Flags map[string]map[int64]PointFlag `json:"flags,omitempty"`
+	// Exemplars are the exemplars of histogram series in group, only returned if requested.
+	Exemplars []Exemplar `json:"exemplars,omitempty"`
+}
+
+// Exemplar represents the trace observation linked to histogram bucket, for drilling down from metrics to traces.
+type Exemplar struct {
+	TraceID   string  `json:"traceId"`
+	SpanID    string  `json:"spanId,omitempty"`
+	Value     float64 `json:"value"`
+	Timestamp int64   `json:"timestamp"`
+	// Bucket is the upper bound of histogram bucket which value falls into, like 0.5 or +Inf.
+	Bucket string `json:"bucket"`
 }
 
 // NewSeries creates a new series
@@ -55,6 +151,7 @@ func NewSeries(tags map[string]string) *Series {
 
 // AddField adds a field
 func (s *Series) AddField(fieldName string, points *Points) {
+	s.addFlags(fieldName, points.Flags)
 	dataPoints, ok := s.Fields[fieldName]
 	if !ok {
 		s.Fields[fieldName] = points.Points
@@ -65,9 +162,44 @@ func (s *Series) AddField(fieldName string, points *Points) {
 	}
 }
 
+// AddStringField adds the values of string field
+func (s *Series) AddStringField(fieldName string, values map[int64]string) {
+	if s.Strings == nil {
+		s.Strings = make(map[string]map[int64]string)
+	}
+	stringValues, ok := s.Strings[fieldName]
+	if !ok {
+		s.Strings[fieldName] = values
+		return
+	}
+	for t, v := range values {
+		stringValues[t] = v
+	}
+}
+
+// addFlags adds the quality flags of field's points.
+func (s *Series) addFlags(fieldName string, flags map[int64]PointFlag) {
+	if len(flags) == 0 {
+		return
+	}
+	if s.Flags == nil {
+		s.Flags = make(map[string]map[int64]PointFlag)
+	}
+	pointFlags, ok := s.Flags[fieldName]
+	if !ok {
+		s.Flags[fieldName] = flags
+		return
+	}
+	for t, f := range flags {
+		pointFlags[t] |= f
+	}
+}
+
 // Points represents the data points of the field
 type Points struct {
 	Points map[int64]float64 `json:"points,omitempty"`
+	// Flags are the quality flags of points, only contains flagged points.
+	Flags map[int64]PointFlag `json:"flags,omitempty"`
 }
 
 // NewPoints creates the data point
@@ -79,3 +211,15 @@ func NewPoints() *Points {
 func (p *Points) AddPoint(timestamp int64, value float64) {
 	p.Points[timestamp] = value
 }
+
+// AddPointWithFlags adds point with quality flags.
+func (p *Points) AddPointWithFlags(timestamp int64, value float64, flags PointFlag) {
+	p.Points[timestamp] = value
+	if flags == 0 {
+		return
+	}
+	if p.Flags == nil {
+		p.Flags = make(map[int64]PointFlag)
+	}
+	p.Flags[timestamp] |= flags
+}
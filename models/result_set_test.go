@@ -42,3 +42,47 @@ func TestResultSet(t *testing.T) {
 		int64(20): 10.0},
 		s.Fields["f1"])
 }
+
+func TestSeries_AddStringField(t *testing.T) {
+	series := NewSeries(nil)
+	series.AddStringField("version", map[int64]string{10: "v1.0.0"})
+	series.AddStringField("version", map[int64]string{20: "v2.0.0"})
+	assert.Equal(t, map[int64]string{10: "v1.0.0", 20: "v2.0.0"}, series.Strings["version"])
+}
+
+func TestResultSet_PointFlags(t *testing.T) {
+	rs := NewResultSet()
+	rs.Flags = PointPartial
+	series := NewSeries(nil)
+	rs.AddSeries(series)
+	points := NewPoints()
+	points.AddPoint(int64(10), 10.0)
+	points.AddPointWithFlags(int64(20), 0, PointFill)
+	points.AddPointWithFlags(int64(30), 1.0, 0)
+	series.AddField("f1", points)
+	points = NewPoints()
+	points.AddPointWithFlags(int64(20), 0, PointDownSampled)
+	points.AddPointWithFlags(int64(40), 0, PointFill)
+	series.AddField("f1", points)
+	series.AddField("f2", NewPoints())
+
+	assert.Equal(t, map[int64]PointFlag{
+		int64(20): PointFill | PointDownSampled,
+		int64(40): PointFill},
+		series.Flags["f1"])
+	assert.Equal(t, PointPartial, rs.PointFlags(series, "f1", 10))
+	assert.Equal(t, PointPartial|PointFill|PointDownSampled, rs.PointFlags(series, "f1", 20))
+	assert.Equal(t, PointPartial, rs.PointFlags(series, "f2", 20))
+
+	f := rs.PointFlags(series, "f1", 20)
+	assert.True(t, f.Has(PointFill))
+	assert.Equal(t, "fill|partial|downsampled", f.String())
+	assert.Equal(t, "", PointFlag(0).String())
+}
+
+func TestResultSet_SortFieldNames(t *testing.T) {
+	rs := NewResultSet()
+	assert.Equal(t, []string{"a", "b", "c"}, rs.SortFieldNames([]string{"c", "a", "b"}))
+	rs.Fields = []string{"c", "b"}
+	assert.Equal(t, []string{"c", "b", "a", "d"}, rs.SortFieldNames([]string{"d", "a", "b", "c"}))
+}
@@ -0,0 +1,45 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+// ShardResult represents the raw result of metric query on one shard of storage node, which isn't merged by broker,
+// used for isolating whether the data problem is storage side or merge side.
+type ShardResult struct {
+	ShardID int32        `json:"shardId"`
+	Series  []*RawSeries `json:"series,omitempty"`
+	// StringValues are the string dictionary entries of string fields, value id => string value.
+	StringValues map[uint64]string `json:"stringValues,omitempty"`
+	Stats        *StorageStats     `json:"stats,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// RawSeries represents the grouped series of shard, values of fields are not merged and calculated.
+type RawSeries struct {
+	Tags   map[string]string `json:"tags,omitempty"`
+	Fields []RawField        `json:"fields,omitempty"`
+}
+
+// RawField represents the values of one aggregation of field in one family, slots are relative to family time.
+type RawField struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	AggType    string    `json:"aggType"`
+	FamilyTime int64     `json:"familyTime"`
+	Slots      []int     `json:"slots"`
+	Values     []float64 `json:"values"`
+}
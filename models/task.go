@@ -20,6 +20,7 @@ package models
 import (
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
 )
 
 // CreateShardTask represents the create shard task's param
@@ -34,6 +35,17 @@ func (t CreateShardTask) Bytes() []byte {
 	return encoding.JSONMarshal(t)
 }
 
+// UpdateDatabaseOptionTask represents the update database option task's param
+type UpdateDatabaseOptionTask struct {
+	DatabaseName   string                `json:"databaseName"`   // database's name
+	DatabaseOption option.DatabaseOption `json:"databaseOption"` // time series database
+}
+
+// Bytes returns the update database option task's binary data using json
+func (t UpdateDatabaseOptionTask) Bytes() []byte {
+	return encoding.JSONMarshal(t)
+}
+
 // DatabaseFlushTask represents the database flush task's param
 type DatabaseFlushTask struct {
 	DatabaseName string `json:"databaseName"` // database's name
@@ -43,3 +55,35 @@ type DatabaseFlushTask struct {
 func (t DatabaseFlushTask) Bytes() []byte {
 	return encoding.JSONMarshal(t)
 }
+
+// ReplicaVerifyTask represents the replica verify task's param,
+// storage node computes the family checksums of shards within time range.
+type ReplicaVerifyTask struct {
+	DatabaseName string             `json:"databaseName"` // database's name
+	ShardIDs     []int32            `json:"shardIDs"`     // shard ids
+	TimeRange    timeutil.TimeRange `json:"timeRange"`    // time range of verified data
+}
+
+// Bytes returns the replica verify task's binary data using json
+func (t ReplicaVerifyTask) Bytes() []byte {
+	return encoding.JSONMarshal(t)
+}
+
+// ShardRepair represents the families of shard which need to be copied from source replica.
+type ShardRepair struct {
+	ShardID    int32                `json:"shardId"`
+	Source     string               `json:"source"`     // indicator of source storage node
+	Namespaces []string             `json:"namespaces"` // namespaces of source replica
+	TimeRanges []timeutil.TimeRange `json:"timeRanges"` // time range of missing families
+}
+
+// ReplicaRepairTask represents the replica repair task's param
+type ReplicaRepairTask struct {
+	DatabaseName string        `json:"databaseName"` // database's name
+	Shards       []ShardRepair `json:"shards"`       // repaired shards
+}
+
+// Bytes returns the replica repair task's binary data using json
+func (t ReplicaRepairTask) Bytes() []byte {
+	return encoding.JSONMarshal(t)
+}
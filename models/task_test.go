@@ -24,6 +24,7 @@ import (
 
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
 )
 
 func TestCreateShardTask_Bytes(t *testing.T) {
@@ -47,3 +48,31 @@ func TestDatabaseFlushTask_Bytes(t *testing.T) {
 	_ = encoding.JSONUnmarshal(data, &task1)
 	assert.Equal(t, task, task1)
 }
+
+func TestReplicaVerifyTask_Bytes(t *testing.T) {
+	task := ReplicaVerifyTask{
+		DatabaseName: "test",
+		ShardIDs:     []int32{1, 2},
+		TimeRange:    timeutil.TimeRange{Start: 10, End: 20},
+	}
+	data := task.Bytes()
+	task1 := ReplicaVerifyTask{}
+	_ = encoding.JSONUnmarshal(data, &task1)
+	assert.Equal(t, task, task1)
+}
+
+func TestReplicaRepairTask_Bytes(t *testing.T) {
+	task := ReplicaRepairTask{
+		DatabaseName: "test",
+		Shards: []ShardRepair{{
+			ShardID:    1,
+			Source:     "1.1.1.1:2891",
+			Namespaces: []string{"default-ns"},
+			TimeRanges: []timeutil.TimeRange{{Start: 10, End: 20}},
+		}},
+	}
+	data := task.Bytes()
+	task1 := ReplicaRepairTask{}
+	_ = encoding.JSONUnmarshal(data, &task1)
+	assert.Equal(t, task, task1)
+}
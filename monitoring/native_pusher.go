@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/klauspost/compress/gzip"
+	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/pkg/logger"
@@ -53,6 +54,8 @@ const (
 type NativePusher interface {
 	// Start starts push metrics data in period
 	Start()
+	// SetInterval changes the push interval at runtime, interval <= 0 is ignored.
+	SetInterval(interval time.Duration)
 	// Stop stops push metrics data
 	Stop()
 }
@@ -61,8 +64,9 @@ type NativePusher interface {
 type nativeProtoPusher struct {
 	ctx             context.Context
 	cancel          context.CancelFunc
-	interval        time.Duration
-	endpoint        string // HTTP endpoint
+	interval        atomic.Duration
+	intervalChanged chan struct{} // notifies ticker to reset with new interval
+	endpoint        string        // HTTP endpoint
 	globalKeyValues tag.KeyValues
 	gather          linmetric.Gather
 	client          *http.Client
@@ -77,11 +81,11 @@ func NewNativeProtoPusher(
 	globalKeyValues tag.KeyValues,
 ) NativePusher {
 	c, cancel := context.WithCancel(ctx)
-	return &nativeProtoPusher{
+	np := &nativeProtoPusher{
 		ctx:             c,
 		cancel:          cancel,
+		intervalChanged: make(chan struct{}, 1),
 		endpoint:        endpoint,
-		interval:        interval,
 		globalKeyValues: globalKeyValues,
 		gather: linmetric.NewGather(
 			linmetric.WithReadRuntimeOption(),
@@ -89,11 +93,13 @@ func NewNativeProtoPusher(
 		),
 		client: &http.Client{Timeout: pushTimeout},
 	}
+	np.interval.Store(interval)
+	return np
 }
 
 func (np *nativeProtoPusher) Start() {
 	nativePushLogger.Info("native proto pusher starting...")
-	ticker := time.NewTicker(np.interval)
+	ticker := time.NewTicker(np.interval.Load())
 	defer ticker.Stop()
 
 	for {
@@ -101,6 +107,8 @@ func (np *nativeProtoPusher) Start() {
 		case <-ticker.C:
 			buf := np.gatherAndMarshal()
 			np.push(buf)
+		case <-np.intervalChanged:
+			ticker.Reset(np.interval.Load())
 		case <-np.ctx.Done():
 			nativePushLogger.Info("native proto pusher stopped")
 			return
@@ -108,6 +116,18 @@ func (np *nativeProtoPusher) Start() {
 	}
 }
 
+func (np *nativeProtoPusher) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	np.interval.Store(interval)
+	select {
+	case np.intervalChanged <- struct{}{}:
+	default:
+		// ticker will be reset with latest interval
+	}
+}
+
 func (np *nativeProtoPusher) Stop() {
 	np.cancel()
 }
@@ -21,6 +21,8 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_NativeProtoPusher(t *testing.T) {
@@ -32,7 +34,13 @@ func Test_NativeProtoPusher(t *testing.T) {
 		nil,
 	)
 	go pusher.Start()
-	time.Sleep(time.Second)
+	time.Sleep(time.Millisecond * 500)
+	pusher.SetInterval(0)
+	assert.Equal(t, time.Millisecond*100, pusher.(*nativeProtoPusher).interval.Load())
+	pusher.SetInterval(time.Millisecond * 50)
+	pusher.SetInterval(time.Millisecond * 200)
+	assert.Equal(t, time.Millisecond*200, pusher.(*nativeProtoPusher).interval.Load())
+	time.Sleep(time.Millisecond * 500)
 	pusher.Stop()
 
 	pusher.(*nativeProtoPusher).push(nil)
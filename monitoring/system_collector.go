@@ -49,6 +49,11 @@ type SystemCollector struct {
 	CPUStatGetter       CPUStatGetter
 	DiskUsageStatGetter DiskUsageStatGetter
 	NetStatGetter       NetStatGetter
+	// WrittenMetricsGetter returns total num. of written metrics for calculating write throughput,
+	// only set by storage runtime.
+	WrittenMetricsGetter func() int64
+	lastWrittenMetrics   int64
+	lastCollectTime      time.Time
 
 	//  role symbols this collector is owned by storage or broker runtime
 	role string
@@ -205,6 +210,7 @@ func (r *SystemCollector) collect() {
 	}
 
 	r.nodeStat.System = *r.systemStat
+	r.collectCapacity()
 
 	r.logMemStat()
 	r.logDiskUsageStat()
@@ -216,6 +222,29 @@ func (r *SystemCollector) collect() {
 	}
 }
 
+// collectCapacity collects the disk capacity/usage and write throughput of node,
+// which is used by broker for disk-aware shard placement.
+func (r *SystemCollector) collectCapacity() {
+	capacity := models.NodeCapacity{}
+	if diskStat := r.systemStat.DiskUsageStat; diskStat != nil {
+		capacity.DiskTotal = diskStat.Total
+		capacity.DiskUsed = diskStat.Used
+		capacity.DiskFree = diskStat.Free
+	}
+	if r.WrittenMetricsGetter != nil {
+		now := time.Now()
+		written := r.WrittenMetricsGetter()
+		if !r.lastCollectTime.IsZero() {
+			if elapsed := now.Sub(r.lastCollectTime).Seconds(); elapsed > 0 {
+				capacity.WriteThroughput = float64(written-r.lastWrittenMetrics) / elapsed
+			}
+		}
+		r.lastWrittenMetrics = written
+		r.lastCollectTime = now
+	}
+	r.nodeStat.Capacity = capacity
+}
+
 func (r *SystemCollector) logMemStat() {
 	if r.systemStat.MemoryStat != nil {
 		memStat := r.systemStat.MemoryStat
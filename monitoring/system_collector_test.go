@@ -30,6 +30,7 @@ import (
 	"github.com/shirou/gopsutil/disk"
 	"github.com/shirou/gopsutil/mem"
 	"github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_NewSystemCollector(t *testing.T) {
@@ -104,3 +105,37 @@ func Test_SystemCollector_Collect(t *testing.T) {
 	collector.collect()
 	collector.collect()
 }
+
+func Test_SystemCollector_collectCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	collector := NewSystemCollector(
+		context.TODO(),
+		"/tmp",
+		state.NewMockRepository(ctrl),
+		"",
+		models.ActiveNode{},
+		"storage",
+	)
+	// capacity not reported if disk usage stat not collected
+	collector.collectCapacity()
+	assert.False(t, collector.nodeStat.Capacity.Reported())
+
+	collector.systemStat.DiskUsageStat = &disk.UsageStat{Total: 100, Used: 40, Free: 60}
+	written := int64(100)
+	collector.WrittenMetricsGetter = func() int64 {
+		return written
+	}
+	collector.collectCapacity()
+	assert.True(t, collector.nodeStat.Capacity.Reported())
+	assert.Equal(t, uint64(60), collector.nodeStat.Capacity.DiskFree)
+	assert.Equal(t, uint64(40), collector.nodeStat.Capacity.DiskUsed)
+	// no throughput for first collect
+	assert.Zero(t, collector.nodeStat.Capacity.WriteThroughput)
+
+	collector.lastCollectTime = time.Now().Add(-10 * time.Second)
+	written = 1100
+	collector.collectCapacity()
+	assert.InDelta(t, 100, collector.nodeStat.Capacity.WriteThroughput, 1)
+}
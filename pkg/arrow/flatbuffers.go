@@ -0,0 +1,169 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package arrow
+
+import (
+	"encoding/binary"
+)
+
+// fbField represents the field of flatbuffers table, scalar value or offset to referenced object.
+type fbField struct {
+	id    int    // field id(slot in vtable)
+	size  int    // inline size of field(1/2/4/8)
+	value uint64 // scalar value
+	// ref writes the referenced object(table/vector/string), returns the position of it, nil if scalar field.
+	ref func(b *fbBuilder) int
+}
+
+// scalar returns the scalar field of table.
+func scalar(id, size int, value uint64) fbField {
+	return fbField{id: id, size: size, value: value}
+}
+
+// reference returns the field which references other object of table.
+func reference(id int, ref func(b *fbBuilder) int) fbField {
+	return fbField{id: id, size: 4, ref: ref}
+}
+
+// fbBuilder builds flatbuffers forward, the objects referenced by table/vector are written after it,
+// so that all unsigned offsets are positive, vtable of table is written before it.
+type fbBuilder struct {
+	buf []byte
+}
+
+// finish builds the buffer with the root table.
+func (b *fbBuilder) finish(root func(b *fbBuilder) int) []byte {
+	b.buf = make([]byte, 4)
+	pos := root(b)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	return b.buf
+}
+
+// pad appends zero bytes until the length of buffer is multiple of alignment after adding given extra bytes.
+func (b *fbBuilder) pad(alignment, extra int) {
+	for (len(b.buf)+extra)%alignment != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// table writes the table with vtable, returns the position of table.
+func (b *fbBuilder) table(fields ...fbField) int {
+	numOfSlots := 0
+	for _, f := range fields {
+		if f.id+1 > numOfSlots {
+			numOfSlots = f.id + 1
+		}
+	}
+	b.pad(2, 0)
+	vtablePos := len(b.buf)
+	vtableSize := 4 + 2*numOfSlots
+	tablePos := alignTo(vtablePos+vtableSize, 4)
+	// layout of table, fields are aligned by size based on position of buffer
+	cursor := tablePos + 4
+	offsets := make([]int, len(fields))
+	for idx, f := range fields {
+		cursor = alignTo(cursor, f.size)
+		offsets[idx] = cursor - tablePos
+		cursor += f.size
+	}
+	b.buf = append(b.buf, make([]byte, cursor-vtablePos)...)
+	binary.LittleEndian.PutUint16(b.buf[vtablePos:], uint16(vtableSize))
+	binary.LittleEndian.PutUint16(b.buf[vtablePos+2:], uint16(cursor-tablePos))
+	for idx, f := range fields {
+		binary.LittleEndian.PutUint16(b.buf[vtablePos+4+2*f.id:], uint16(offsets[idx]))
+	}
+	binary.LittleEndian.PutUint32(b.buf[tablePos:], uint32(tablePos-vtablePos))
+	for idx, f := range fields {
+		pos := tablePos + offsets[idx]
+		switch f.size {
+		case 1:
+			b.buf[pos] = byte(f.value)
+		case 2:
+			binary.LittleEndian.PutUint16(b.buf[pos:], uint16(f.value))
+		case 4:
+			binary.LittleEndian.PutUint32(b.buf[pos:], uint32(f.value))
+		case 8:
+			binary.LittleEndian.PutUint64(b.buf[pos:], f.value)
+		}
+	}
+	for idx, f := range fields {
+		if f.ref != nil {
+			// writes referenced object first, buffer may grow
+			ref := f.ref(b)
+			pos := tablePos + offsets[idx]
+			binary.LittleEndian.PutUint32(b.buf[pos:], uint32(ref-pos))
+		}
+	}
+	return tablePos
+}
+
+// string writes the string with null terminator, returns the position of it.
+func (b *fbBuilder) string(s string) int {
+	b.pad(4, 0)
+	pos := len(b.buf)
+	b.appendUint32(uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+// tableVector writes the vector of tables, returns the position of it.
+func (b *fbBuilder) tableVector(n int, elem func(b *fbBuilder, idx int) int) int {
+	b.pad(4, 0)
+	pos := len(b.buf)
+	b.appendUint32(uint32(n))
+	b.buf = append(b.buf, make([]byte, 4*n)...)
+	for idx := 0; idx < n; idx++ {
+		ref := elem(b, idx)
+		slot := pos + 4 + 4*idx
+		binary.LittleEndian.PutUint32(b.buf[slot:], uint32(ref-slot))
+	}
+	return pos
+}
+
+// longPairVector writes the vector of structs which have two long fields(like FieldNode/Buffer),
+// elements are aligned by 8 bytes, returns the position of it.
+func (b *fbBuilder) longPairVector(pairs [][2]int64) int {
+	b.pad(8, 4)
+	pos := len(b.buf)
+	b.appendUint32(uint32(len(pairs)))
+	for _, pair := range pairs {
+		b.appendUint64(uint64(pair[0]))
+		b.appendUint64(uint64(pair[1]))
+	}
+	return pos
+}
+
+// appendUint32 appends the uint32 value in little endian.
+func (b *fbBuilder) appendUint32(v uint32) {
+	var scratch [4]byte
+	binary.LittleEndian.PutUint32(scratch[:], v)
+	b.buf = append(b.buf, scratch[:]...)
+}
+
+// appendUint64 appends the uint64 value in little endian.
+func (b *fbBuilder) appendUint64(v uint64) {
+	var scratch [8]byte
+	binary.LittleEndian.PutUint64(scratch[:], v)
+	b.buf = append(b.buf, scratch[:]...)
+}
+
+// alignTo returns the position aligned by alignment.
+func alignTo(pos, alignment int) int {
+	return (pos + alignment - 1) / alignment * alignment
+}
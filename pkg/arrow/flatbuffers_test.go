@@ -0,0 +1,218 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package arrow
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// knownStream is the arrow ipc stream of one int64 column "a" with value 1, the layout of flatbuffers
+// is verified with Schema.fbs/Message.fbs, offsets in comments are relative to the start of metadata.
+var knownStream = []byte{
+	// schema message: continuation marker, metadata size(136)
+	0xff, 0xff, 0xff, 0xff, 0x88, 0x00, 0x00, 0x00,
+	// 0: root table offset(16); 4: vtable of Message, vtable size(12), table size(24)
+	0x10, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x18, 0x00,
+	// version@4, header_type@6, header@8, bodyLength@16
+	0x04, 0x00, 0x06, 0x00, 0x08, 0x00, 0x10, 0x00,
+	// 16: Message, vtable at -12; version(V5), header_type(Schema)
+	0x0c, 0x00, 0x00, 0x00, 0x04, 0x00, 0x01, 0x00,
+	// header => 48, padding
+	0x18, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// 32: bodyLength(0)
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// 40: vtable of Schema, vtable size(8), table size(8), endianness(default little), fields@4
+	0x08, 0x00, 0x08, 0x00, 0x00, 0x00, 0x04, 0x00,
+	// 48: Schema, vtable at -8; fields => 56
+	0x08, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00,
+	// 56: fields vector, length(1), [0] => 80
+	0x01, 0x00, 0x00, 0x00, 0x14, 0x00, 0x00, 0x00,
+	// 64: vtable of Field, vtable size(16), table size(20), name@4, nullable@8
+	0x10, 0x00, 0x14, 0x00, 0x04, 0x00, 0x08, 0x00,
+	// type_type@9, type@12, dictionary(none), children@16
+	0x09, 0x00, 0x0c, 0x00, 0x00, 0x00, 0x10, 0x00,
+	// 80: Field, vtable at -16; name => 100
+	0x10, 0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00,
+	// nullable(false), type_type(Int), padding; type => 116
+	0x00, 0x02, 0x00, 0x00, 0x18, 0x00, 0x00, 0x00,
+	// children => 128; 100: name, length(1)
+	0x20, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+	// "a", null terminator; 106: vtable of Int, vtable size(8), table size(9), bitWidth@4
+	0x61, 0x00, 0x08, 0x00, 0x09, 0x00, 0x04, 0x00,
+	// is_signed@8, padding; 116: Int, vtable at -10
+	0x08, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00,
+	// bitWidth(64); is_signed(true), padding
+	0x40, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+	// 128: children vector, length(0); padding of metadata
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+
+	// record batch message: continuation marker, metadata size(136)
+	0xff, 0xff, 0xff, 0xff, 0x88, 0x00, 0x00, 0x00,
+	// 0: root table offset(16); 4: vtable of Message, vtable size(12), table size(24)
+	0x10, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x18, 0x00,
+	// version@4, header_type@6, header@8, bodyLength@16
+	0x04, 0x00, 0x06, 0x00, 0x08, 0x00, 0x10, 0x00,
+	// 16: Message, vtable at -12; version(V5), header_type(RecordBatch)
+	0x0c, 0x00, 0x00, 0x00, 0x04, 0x00, 0x03, 0x00,
+	// header => 52, padding
+	0x1c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// 32: bodyLength(8)
+	0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// 40: vtable of RecordBatch, vtable size(10), table size(20), length@4, nodes@12
+	0x0a, 0x00, 0x14, 0x00, 0x04, 0x00, 0x0c, 0x00,
+	// buffers@16, padding; 52: RecordBatch, vtable at -12
+	0x10, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x00, 0x00,
+	// 56: length(1)
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// nodes => 76; buffers => 100
+	0x0c, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00,
+	// padding; 76: nodes vector, length(1)
+	0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+	// 80: FieldNode{length(1), null_count(0)}
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// padding; 100: buffers vector, length(2)
+	0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00,
+	// 104: Buffer{offset(0), length(0)}, validity bitmap is omitted without null
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// 120: Buffer{offset(0), length(8)}, values
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	// body: int64 values
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+
+	// end-of-stream
+	0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestWriteStream_KnownPayload(t *testing.T) {
+	// encode
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteStream(buf, []Column{{Name: "a", Type: Int64, Int64s: []int64{1}}}))
+	assert.Equal(t, knownStream, buf.Bytes())
+
+	// decode
+	r := bytes.NewReader(knownStream)
+	message, body := readMessage(t, r)
+	assert.Empty(t, body)
+	assert.Equal(t, uint64(messageHeaderSchema), message.scalar(1, 1))
+	schema := message.table(2)
+	assert.Zero(t, schema.scalar(0, 2))
+	fields := schema.tables(1)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "a", fields[0].string(0))
+	assert.Zero(t, fields[0].scalar(1, 1))
+	assert.Equal(t, uint64(typeInt), fields[0].scalar(2, 1))
+	assert.Equal(t, uint64(64), fields[0].table(3).scalar(0, 4))
+	assert.Equal(t, uint64(1), fields[0].table(3).scalar(1, 1))
+	assert.Zero(t, fields[0].fieldPos(4))
+	assert.Empty(t, fields[0].tables(5))
+
+	message, body = readMessage(t, r)
+	assert.Equal(t, uint64(messageHeaderRecordBatch), message.scalar(1, 1))
+	batch := message.table(2)
+	assert.Equal(t, uint64(1), batch.scalar(0, 8))
+	assert.Equal(t, [][2]int64{{1, 0}}, batch.longPairs(1))
+	assert.Equal(t, [][2]int64{{0, 0}, {0, 8}}, batch.longPairs(2))
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0}, body)
+
+	message, _ = readMessage(t, r)
+	assert.Nil(t, message.buf)
+	assert.Zero(t, r.Len())
+}
+
+func TestFBBuilder_RoundTrip(t *testing.T) {
+	b := &fbBuilder{}
+	buf := b.finish(func(b *fbBuilder) int {
+		return b.table(
+			scalar(0, 1, 0xab),
+			// slot 1 is absent
+			scalar(2, 8, math.MaxUint64),
+			scalar(3, 2, 0xbeef),
+			reference(4, func(b *fbBuilder) int { return b.string("ns/中文") }),
+			scalar(5, 4, 0xdeadbeef),
+			reference(6, func(b *fbBuilder) int {
+				return b.tableVector(3, func(b *fbBuilder, idx int) int {
+					return b.table(
+						scalar(0, 8, uint64(idx)),
+						reference(1, func(b *fbBuilder) int { return b.string(string(rune('a' + idx))) }),
+					)
+				})
+			}),
+			reference(7, func(b *fbBuilder) int {
+				return b.longPairVector([][2]int64{{1, -1}, {math.MaxInt64, math.MinInt64}})
+			}),
+			reference(8, func(b *fbBuilder) int { return b.table() }),
+			reference(9, func(b *fbBuilder) int { return b.string("") }),
+		)
+	})
+	root := rootTable(buf)
+	assert.Equal(t, uint64(0xab), root.scalar(0, 1))
+	assert.Zero(t, root.fieldPos(1))
+	assert.Zero(t, root.scalar(1, 4))
+	assert.Equal(t, uint64(math.MaxUint64), root.scalar(2, 8))
+	assert.Equal(t, uint64(0xbeef), root.scalar(3, 2))
+	assert.Equal(t, "ns/中文", root.string(4))
+	assert.Equal(t, uint64(0xdeadbeef), root.scalar(5, 4))
+	elems := root.tables(6)
+	assert.Len(t, elems, 3)
+	for idx, elem := range elems {
+		assert.Equal(t, uint64(idx), elem.scalar(0, 8))
+		assert.Equal(t, string(rune('a'+idx)), elem.string(1))
+	}
+	assert.Equal(t, [][2]int64{{1, -1}, {math.MaxInt64, math.MinInt64}}, root.longPairs(7))
+	empty := root.table(8)
+	assert.Zero(t, empty.fieldPos(0))
+	assert.Equal(t, "", root.string(9))
+	// slot out of vtable
+	assert.Zero(t, root.fieldPos(10))
+}
+
+func TestFBBuilder_EmptyVector(t *testing.T) {
+	b := &fbBuilder{}
+	buf := b.finish(func(b *fbBuilder) int {
+		return b.table(
+			reference(0, func(b *fbBuilder) int { return b.tableVector(0, nil) }),
+			reference(1, func(b *fbBuilder) int { return b.longPairVector(nil) }),
+		)
+	})
+	root := rootTable(buf)
+	assert.Empty(t, root.tables(0))
+	assert.Empty(t, root.longPairs(1))
+}
+
+func TestAlignTo(t *testing.T) {
+	cases := []struct {
+		pos, alignment, expect int
+	}{
+		{pos: 0, alignment: 8, expect: 0},
+		{pos: 1, alignment: 8, expect: 8},
+		{pos: 8, alignment: 8, expect: 8},
+		{pos: 9, alignment: 4, expect: 12},
+		{pos: 3, alignment: 2, expect: 4},
+		{pos: 3, alignment: 1, expect: 3},
+	}
+	for _, tt := range cases {
+		assert.Equal(t, tt.expect, alignTo(tt.pos, tt.alignment))
+	}
+}
@@ -0,0 +1,255 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ContentType is the media type of arrow ipc stream format.
+const ContentType = "application/vnd.apache.arrow.stream"
+
+// DataType represents the data type of column.
+type DataType int
+
+// Defines all supported data types of column.
+const (
+	Utf8 DataType = iota
+	Int64
+	Float64
+	// TimestampMillis is the timestamp(millisecond) without time zone.
+	TimestampMillis
+)
+
+// Defines the constants of arrow flatbuffers schema(Schema.fbs/Message.fbs).
+const (
+	metadataVersionV5        = 4
+	messageHeaderSchema      = 1
+	messageHeaderRecordBatch = 3
+	typeInt                  = 2
+	typeFloatingPoint        = 3
+	typeUtf8                 = 5
+	typeTimestamp            = 10
+	precisionDouble          = 2
+	timeUnitMillisecond      = 1
+	continuationMarker       = 0xFFFFFFFF
+	bodyAlignment            = 8
+)
+
+// Column represents the column of record batch, values are kept in slice of data type.
+type Column struct {
+	Name    string
+	Type    DataType
+	Strings []string  // values of utf8 column
+	Int64s  []int64   // values of int64/timestamp column
+	Floats  []float64 // values of float64 column
+	// Valid marks if value is not null, all values are valid if nil.
+	Valid []bool
+}
+
+// Len returns the number of values of column.
+func (c *Column) Len() int {
+	switch c.Type {
+	case Utf8:
+		return len(c.Strings)
+	case Float64:
+		return len(c.Floats)
+	default:
+		return len(c.Int64s)
+	}
+}
+
+// nullCount returns the number of null values of column.
+func (c *Column) nullCount() int {
+	count := 0
+	for _, valid := range c.Valid {
+		if !valid {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteStream writes the columns as arrow ipc stream format, includes schema message,
+// one record batch message and end-of-stream marker, all columns must have same length.
+func WriteStream(w io.Writer, columns []Column) error {
+	length := 0
+	for idx := range columns {
+		if idx == 0 {
+			length = columns[idx].Len()
+		}
+		if columns[idx].Len() != length || (columns[idx].Valid != nil && len(columns[idx].Valid) != length) {
+			return fmt.Errorf("length of column %s not match", columns[idx].Name)
+		}
+	}
+	if err := writeMessage(w, schemaMessage(columns), nil); err != nil {
+		return err
+	}
+	metadata, body := recordBatchMessage(columns, length)
+	if err := writeMessage(w, metadata, body); err != nil {
+		return err
+	}
+	// end-of-stream
+	var eos [8]byte
+	binary.LittleEndian.PutUint32(eos[:], continuationMarker)
+	_, err := w.Write(eos[:])
+	return err
+}
+
+// writeMessage writes the encapsulated message, metadata is padded to 8 bytes alignment.
+func writeMessage(w io.Writer, metadata, body []byte) error {
+	metadataSize := alignTo(8+len(metadata), 8) - 8
+	prefix := make([]byte, 8, 8+metadataSize)
+	binary.LittleEndian.PutUint32(prefix, continuationMarker)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(metadataSize))
+	prefix = append(prefix, metadata...)
+	prefix = append(prefix, make([]byte, 8+metadataSize-len(prefix))...)
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// schemaMessage builds the message of schema.
+func schemaMessage(columns []Column) []byte {
+	b := &fbBuilder{}
+	return b.finish(func(b *fbBuilder) int {
+		return b.table(
+			scalar(0, 2, metadataVersionV5),
+			scalar(1, 1, messageHeaderSchema),
+			reference(2, func(b *fbBuilder) int {
+				return b.table(
+					// endianness is little by default
+					reference(1, func(b *fbBuilder) int {
+						return b.tableVector(len(columns), func(b *fbBuilder, idx int) int {
+							return fieldTable(b, &columns[idx])
+						})
+					}),
+				)
+			}),
+			scalar(3, 8, 0),
+		)
+	})
+}
+
+// fieldTable writes the field of schema.
+func fieldTable(b *fbBuilder, column *Column) int {
+	var typeType uint64
+	var typeTable func(b *fbBuilder) int
+	switch column.Type {
+	case Utf8:
+		typeType = typeUtf8
+		typeTable = func(b *fbBuilder) int { return b.table() }
+	case Float64:
+		typeType = typeFloatingPoint
+		typeTable = func(b *fbBuilder) int { return b.table(scalar(0, 2, precisionDouble)) }
+	case TimestampMillis:
+		typeType = typeTimestamp
+		typeTable = func(b *fbBuilder) int { return b.table(scalar(0, 2, timeUnitMillisecond)) }
+	default:
+		typeType = typeInt
+		typeTable = func(b *fbBuilder) int { return b.table(scalar(0, 4, 64), scalar(1, 1, 1)) }
+	}
+	nullable := uint64(0)
+	if column.Valid != nil {
+		nullable = 1
+	}
+	return b.table(
+		reference(0, func(b *fbBuilder) int { return b.string(column.Name) }),
+		scalar(1, 1, nullable),
+		scalar(2, 1, typeType),
+		reference(3, typeTable),
+		// children is required even if empty
+		reference(5, func(b *fbBuilder) int {
+			return b.tableVector(0, nil)
+		}),
+	)
+}
+
+// recordBatchMessage builds the message of record batch, returns the metadata and body.
+func recordBatchMessage(columns []Column, length int) (metadata, body []byte) {
+	var nodes, buffers [][2]int64
+	addBuffer := func(data []byte) {
+		buffers = append(buffers, [2]int64{int64(len(body)), int64(len(data))})
+		body = append(body, data...)
+		body = append(body, make([]byte, alignTo(len(body), bodyAlignment)-len(body))...)
+	}
+	for idx := range columns {
+		column := &columns[idx]
+		nullCount := column.nullCount()
+		nodes = append(nodes, [2]int64{int64(length), int64(nullCount)})
+		// validity bitmap
+		if nullCount > 0 {
+			bitmap := make([]byte, (length+7)/8)
+			for i, valid := range column.Valid {
+				if valid {
+					bitmap[i/8] |= 1 << (i % 8)
+				}
+			}
+			addBuffer(bitmap)
+		} else {
+			addBuffer(nil)
+		}
+		switch column.Type {
+		case Utf8:
+			offsets := make([]byte, 4*(length+1))
+			var data []byte
+			for i, s := range column.Strings {
+				data = append(data, s...)
+				binary.LittleEndian.PutUint32(offsets[4*(i+1):], uint32(len(data)))
+			}
+			addBuffer(offsets)
+			addBuffer(data)
+		case Float64:
+			data := make([]byte, 8*length)
+			for i, v := range column.Floats {
+				binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+			}
+			addBuffer(data)
+		default:
+			data := make([]byte, 8*length)
+			for i, v := range column.Int64s {
+				binary.LittleEndian.PutUint64(data[8*i:], uint64(v))
+			}
+			addBuffer(data)
+		}
+	}
+	b := &fbBuilder{}
+	metadata = b.finish(func(b *fbBuilder) int {
+		return b.table(
+			scalar(0, 2, metadataVersionV5),
+			scalar(1, 1, messageHeaderRecordBatch),
+			reference(2, func(b *fbBuilder) int {
+				return b.table(
+					scalar(0, 8, uint64(length)),
+					reference(1, func(b *fbBuilder) int { return b.longPairVector(nodes) }),
+					reference(2, func(b *fbBuilder) int { return b.longPairVector(buffers) }),
+				)
+			}),
+			scalar(3, 8, uint64(len(body))),
+		)
+	})
+	return metadata, body
+}
@@ -0,0 +1,230 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fbTable reads the table of flatbuffers for verifying the written messages.
+type fbTable struct {
+	buf []byte
+	pos int
+}
+
+func rootTable(buf []byte) fbTable {
+	return fbTable{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+func (t fbTable) fieldPos(id int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if vtable%2 != 0 {
+		panic("vtable not aligned")
+	}
+	vtableSize := int(binary.LittleEndian.Uint16(t.buf[vtable:]))
+	if 4+2*id >= vtableSize {
+		return 0
+	}
+	offset := int(binary.LittleEndian.Uint16(t.buf[vtable+4+2*id:]))
+	if offset == 0 {
+		return 0
+	}
+	return t.pos + offset
+}
+
+func (t fbTable) scalar(id, size int) uint64 {
+	pos := t.fieldPos(id)
+	if pos == 0 {
+		return 0
+	}
+	if pos%size != 0 {
+		panic(fmt.Sprintf("field %d not aligned", id))
+	}
+	switch size {
+	case 1:
+		return uint64(t.buf[pos])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(t.buf[pos:]))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(t.buf[pos:]))
+	default:
+		return binary.LittleEndian.Uint64(t.buf[pos:])
+	}
+}
+
+func (t fbTable) deref(id int) int {
+	pos := t.fieldPos(id)
+	return pos + int(binary.LittleEndian.Uint32(t.buf[pos:]))
+}
+
+func (t fbTable) table(id int) fbTable {
+	return fbTable{buf: t.buf, pos: t.deref(id)}
+}
+
+func (t fbTable) string(id int) string {
+	pos := t.deref(id)
+	length := int(binary.LittleEndian.Uint32(t.buf[pos:]))
+	if t.buf[pos+4+length] != 0 {
+		panic("string without null terminator")
+	}
+	return string(t.buf[pos+4 : pos+4+length])
+}
+
+func (t fbTable) tables(id int) []fbTable {
+	pos := t.deref(id)
+	var result []fbTable
+	for i := 0; i < int(binary.LittleEndian.Uint32(t.buf[pos:])); i++ {
+		slot := pos + 4 + 4*i
+		result = append(result, fbTable{buf: t.buf, pos: slot + int(binary.LittleEndian.Uint32(t.buf[slot:]))})
+	}
+	return result
+}
+
+func (t fbTable) longPairs(id int) [][2]int64 {
+	pos := t.deref(id)
+	if (pos+4)%8 != 0 {
+		panic("struct vector not aligned")
+	}
+	var result [][2]int64
+	for i := 0; i < int(binary.LittleEndian.Uint32(t.buf[pos:])); i++ {
+		elem := pos + 4 + 16*i
+		result = append(result, [2]int64{
+			int64(binary.LittleEndian.Uint64(t.buf[elem:])),
+			int64(binary.LittleEndian.Uint64(t.buf[elem+8:])),
+		})
+	}
+	return result
+}
+
+// readMessage reads the encapsulated message, returns nil if end-of-stream.
+func readMessage(t *testing.T, r *bytes.Reader) (message fbTable, body []byte) {
+	prefix := make([]byte, 8)
+	_, err := r.Read(prefix)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(continuationMarker), binary.LittleEndian.Uint32(prefix))
+	size := int(binary.LittleEndian.Uint32(prefix[4:]))
+	if size == 0 {
+		return fbTable{}, nil
+	}
+	assert.Zero(t, (8+size)%8)
+	metadata := make([]byte, size)
+	_, err = r.Read(metadata)
+	assert.NoError(t, err)
+	message = rootTable(metadata)
+	assert.Equal(t, uint64(metadataVersionV5), message.scalar(0, 2))
+	body = make([]byte, message.scalar(3, 8))
+	if len(body) > 0 {
+		_, err = r.Read(body)
+		assert.NoError(t, err)
+	}
+	return message, body
+}
+
+func TestWriteStream(t *testing.T) {
+	columns := []Column{
+		{Name: "host", Type: Utf8, Strings: []string{"a", "", "ccc"}, Valid: []bool{true, false, true}},
+		{Name: "timestamp", Type: TimestampMillis, Int64s: []int64{1, 2, 3}},
+		{Name: "value", Type: Float64, Floats: []float64{1.5, math.NaN(), -3}},
+		{Name: "count", Type: Int64, Int64s: []int64{-1, 0, 1}},
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteStream(buf, columns))
+	r := bytes.NewReader(buf.Bytes())
+
+	// schema
+	message, body := readMessage(t, r)
+	assert.Empty(t, body)
+	assert.Equal(t, uint64(messageHeaderSchema), message.scalar(1, 1))
+	fields := message.table(2).tables(1)
+	assert.Len(t, fields, len(columns))
+	types := []uint64{typeUtf8, typeTimestamp, typeFloatingPoint, typeInt}
+	for idx, f := range fields {
+		assert.Equal(t, columns[idx].Name, f.string(0))
+		assert.Equal(t, columns[idx].Valid != nil, f.scalar(1, 1) == 1)
+		assert.Equal(t, types[idx], f.scalar(2, 1))
+		assert.Empty(t, f.tables(5))
+	}
+	assert.Equal(t, uint64(timeUnitMillisecond), fields[1].table(3).scalar(0, 2))
+	assert.Equal(t, uint64(precisionDouble), fields[2].table(3).scalar(0, 2))
+	assert.Equal(t, uint64(64), fields[3].table(3).scalar(0, 4))
+	assert.Equal(t, uint64(1), fields[3].table(3).scalar(1, 1))
+
+	// record batch
+	message, body = readMessage(t, r)
+	assert.Equal(t, uint64(messageHeaderRecordBatch), message.scalar(1, 1))
+	batch := message.table(2)
+	assert.Equal(t, uint64(3), batch.scalar(0, 8))
+	assert.Equal(t, [][2]int64{{3, 1}, {3, 0}, {3, 0}, {3, 0}}, batch.longPairs(1))
+	buffers := batch.longPairs(2)
+	assert.Len(t, buffers, 9)
+	data := func(idx int) []byte {
+		assert.Zero(t, buffers[idx][0]%bodyAlignment)
+		return body[buffers[idx][0] : buffers[idx][0]+buffers[idx][1]]
+	}
+	assert.Equal(t, []byte{0x05}, data(0))
+	assert.Equal(t, []byte{0, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 4, 0, 0, 0}, data(1))
+	assert.Equal(t, "accc", string(data(2)))
+	assert.Empty(t, data(3))
+	assert.Equal(t, uint64(2), binary.LittleEndian.Uint64(data(4)[8:]))
+	assert.Equal(t, 1.5, math.Float64frombits(binary.LittleEndian.Uint64(data(6))))
+	assert.True(t, math.IsNaN(math.Float64frombits(binary.LittleEndian.Uint64(data(6)[8:]))))
+	assert.Equal(t, int64(-1), int64(binary.LittleEndian.Uint64(data(8))))
+
+	// end-of-stream
+	message, _ = readMessage(t, r)
+	assert.Nil(t, message.buf)
+	assert.Zero(t, r.Len())
+}
+
+func TestWriteStream_Empty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteStream(buf, []Column{{Name: "value", Type: Float64}}))
+	r := bytes.NewReader(buf.Bytes())
+	_, _ = readMessage(t, r)
+	message, body := readMessage(t, r)
+	assert.Equal(t, uint64(0), message.table(2).scalar(0, 8))
+	assert.Empty(t, body)
+}
+
+func TestWriteStream_LengthNotMatch(t *testing.T) {
+	err := WriteStream(&bytes.Buffer{}, []Column{
+		{Name: "a", Type: Float64, Floats: []float64{1}},
+		{Name: "b", Type: Int64},
+	})
+	assert.Error(t, err)
+	err = WriteStream(&bytes.Buffer{}, []Column{
+		{Name: "a", Type: Float64, Floats: []float64{1}, Valid: []bool{true, false}},
+	})
+	assert.Error(t, err)
+}
+
+type errWriter struct{}
+
+func (w *errWriter) Write(_ []byte) (int, error) {
+	return 0, fmt.Errorf("err")
+}
+
+func TestWriteStream_WriteErr(t *testing.T) {
+	assert.Error(t, WriteStream(&errWriter{}, []Column{{Name: "a", Type: Float64, Floats: []float64{1}}}))
+}
@@ -53,6 +53,7 @@ type bufioWriter struct {
 	w        *bufio.Writer
 	f        *os.File
 	size     int64
+	syncer   *Syncer // syncs data based on sync policy, nil means only syncs by calling Sync
 }
 
 // NewBufioWriter returns a new BufioWriter from fileName.
@@ -68,6 +69,16 @@ func NewBufioWriter(fileName string) (BufioWriter, error) {
 	}, nil
 }
 
+// NewSyncBufioWriter returns a new BufioWriter from fileName, which syncs written data by syncer.
+func NewSyncBufioWriter(fileName string, syncer *Syncer) (BufioWriter, error) {
+	writer, err := NewBufioWriter(fileName)
+	if err != nil {
+		return nil, err
+	}
+	writer.(*bufioWriter).syncer = syncer
+	return writer, nil
+}
+
 // Reset switches the buffered writer to write to a new file.
 func (bw *bufioWriter) Reset(fileName string) error {
 	newF, err := os.Create(fileName)
@@ -100,6 +111,11 @@ func (bw *bufioWriter) Write(content []byte) (int, error) {
 		return 0, err
 	}
 	bw.size += int64(n2)
+	if bw.syncer != nil {
+		if err := bw.syncer.Written(n1+n2, bw.Sync); err != nil {
+			return 0, err
+		}
+	}
 	return n1 + n2, nil
 }
 
@@ -124,8 +140,14 @@ func (bw *bufioWriter) Size() int64 {
 	return bw.size
 }
 
-// Close closes the writer after flushing the buffered data.
+// Close closes the writer after flushing the buffered data,
+// syncs data before closing if sync policy is SyncBatch.
 func (bw *bufioWriter) Close() error {
+	if bw.syncer != nil {
+		if err := bw.syncer.Batch(bw.Sync); err != nil {
+			return err
+		}
+	}
 	if err := bw.w.Flush(); err != nil {
 		return err
 	}
@@ -0,0 +1,124 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bufioutil
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/lindb/lindb/internal/linmetric"
+)
+
+var (
+	syncScope      = linmetric.NewScope("lindb.storage.sync")
+	syncTimerVec   = syncScope.Scope("sync_duration").NewDeltaHistogramVec("kind")
+	syncFailureVec = syncScope.NewDeltaCounterVec("sync_failures", "kind")
+)
+
+// SyncMode represents when the written data is synced(fsync) to disk.
+type SyncMode int
+
+// Defines all modes of sync policy.
+const (
+	// SyncBatch syncs data when a batch of writes completes(like table file closed or wal flushed).
+	SyncBatch SyncMode = iota
+	// SyncBytes syncs data every time the number of unsynced bytes reaches the threshold.
+	SyncBytes
+	// SyncNever never syncs data explicitly, relies on the os to write back dirty pages.
+	SyncNever
+)
+
+// SyncPolicy represents the fsync policy of writer, which trades durability against write throughput.
+type SyncPolicy struct {
+	Mode  SyncMode
+	Bytes int64 // threshold of unsynced bytes, only works for SyncBytes
+}
+
+// DefaultSyncPolicy represents the default sync policy which syncs data for each batch.
+var DefaultSyncPolicy = SyncPolicy{Mode: SyncBatch}
+
+// Syncer decides when to sync written data based on sync policy, records the latency of sync.
+// Policy can be changed concurrently, others are not thread-safe.
+type Syncer struct {
+	policy   atomic.Value
+	unsynced int64
+
+	syncTimer    *linmetric.BoundDeltaHistogram
+	syncFailures *linmetric.BoundDeltaCounter
+}
+
+// NewSyncer creates a syncer with sync policy, kind is used for tagging sync metrics(like table/wal).
+func NewSyncer(kind string, policy SyncPolicy) *Syncer {
+	s := &Syncer{
+		syncTimer:    syncTimerVec.WithTagValues(kind),
+		syncFailures: syncFailureVec.WithTagValues(kind),
+	}
+	s.policy.Store(policy)
+	return s
+}
+
+// SetPolicy changes the sync policy.
+func (s *Syncer) SetPolicy(policy SyncPolicy) {
+	s.policy.Store(policy)
+}
+
+// Policy returns the current sync policy.
+func (s *Syncer) Policy() SyncPolicy {
+	return s.policy.Load().(SyncPolicy)
+}
+
+// Written records the number of written bytes, syncs data if unsynced bytes reach the threshold of SyncBytes.
+func (s *Syncer) Written(n int, sync func() error) error {
+	policy := s.Policy()
+	if policy.Mode == SyncNever {
+		return nil
+	}
+	s.unsynced += int64(n)
+	if policy.Mode != SyncBytes || s.unsynced < policy.Bytes {
+		return nil
+	}
+	return s.doSync(sync)
+}
+
+// Batch is invoked after a batch of writes completes, syncs data if policy is SyncBatch.
+func (s *Syncer) Batch(sync func() error) error {
+	if s.Policy().Mode != SyncBatch || s.unsynced == 0 {
+		return nil
+	}
+	return s.doSync(sync)
+}
+
+// Sync syncs all unsynced data unless policy is SyncNever(like before switching to a new file).
+func (s *Syncer) Sync(sync func() error) error {
+	if s.Policy().Mode == SyncNever || s.unsynced == 0 {
+		return nil
+	}
+	return s.doSync(sync)
+}
+
+// doSync syncs data, then resets unsynced bytes.
+func (s *Syncer) doSync(sync func() error) error {
+	start := time.Now()
+	if err := sync(); err != nil {
+		s.syncFailures.Incr()
+		return err
+	}
+	s.syncTimer.UpdateSince(start)
+	s.unsynced = 0
+	return nil
+}
@@ -0,0 +1,110 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bufioutil
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncer_Batch(t *testing.T) {
+	syncs := 0
+	sync := func() error {
+		syncs++
+		return nil
+	}
+	s := NewSyncer("test", DefaultSyncPolicy)
+	assert.NoError(t, s.Written(100, sync))
+	assert.Equal(t, 0, syncs)
+	assert.NoError(t, s.Batch(sync))
+	assert.Equal(t, 1, syncs)
+	// nothing written after sync
+	assert.NoError(t, s.Batch(sync))
+	assert.Equal(t, 1, syncs)
+	// sync failure
+	assert.NoError(t, s.Written(10, sync))
+	assert.Error(t, s.Batch(func() error {
+		return fmt.Errorf("err")
+	}))
+	assert.NoError(t, s.Batch(sync))
+	assert.Equal(t, 2, syncs)
+}
+
+func TestSyncer_Bytes(t *testing.T) {
+	syncs := 0
+	sync := func() error {
+		syncs++
+		return nil
+	}
+	s := NewSyncer("test", SyncPolicy{Mode: SyncBytes, Bytes: 100})
+	assert.NoError(t, s.Written(60, sync))
+	assert.Equal(t, 0, syncs)
+	assert.NoError(t, s.Batch(sync))
+	assert.Equal(t, 0, syncs)
+	assert.NoError(t, s.Written(60, sync))
+	assert.Equal(t, 1, syncs)
+	assert.NoError(t, s.Written(60, sync))
+	assert.NoError(t, s.Sync(sync))
+	assert.Equal(t, 2, syncs)
+}
+
+func TestSyncer_Never(t *testing.T) {
+	syncs := 0
+	sync := func() error {
+		syncs++
+		return nil
+	}
+	s := NewSyncer("test", SyncPolicy{Mode: SyncNever})
+	assert.NoError(t, s.Written(1000, sync))
+	assert.NoError(t, s.Batch(sync))
+	assert.NoError(t, s.Sync(sync))
+	assert.Equal(t, 0, syncs)
+	// change policy
+	s.SetPolicy(DefaultSyncPolicy)
+	assert.Equal(t, DefaultSyncPolicy, s.Policy())
+	assert.NoError(t, s.Written(10, sync))
+	assert.NoError(t, s.Sync(sync))
+	assert.Equal(t, 1, syncs)
+}
+
+func TestNewSyncBufioWriter(t *testing.T) {
+	defer os.Remove(_testFile)
+	bw, err := NewSyncBufioWriter(_testFile, NewSyncer("test", SyncPolicy{Mode: SyncBytes, Bytes: 10}))
+	assert.NoError(t, err)
+	_, err = bw.Write(_testContent)
+	assert.NoError(t, err)
+	assert.NoError(t, bw.Close())
+	// write after file closed, sync failure
+	bw, err = NewSyncBufioWriter(_testFile, NewSyncer("test", DefaultSyncPolicy))
+	assert.NoError(t, err)
+	_, err = bw.Write(_testContent)
+	assert.NoError(t, err)
+	assert.NoError(t, bw.(*bufioWriter).f.Close())
+	assert.Error(t, bw.Close())
+	bw, err = NewSyncBufioWriter(_testFile, NewSyncer("test", SyncPolicy{Mode: SyncBytes, Bytes: 1}))
+	assert.NoError(t, err)
+	assert.NoError(t, bw.(*bufioWriter).f.Close())
+	_, err = bw.Write(_testContent)
+	assert.Error(t, err)
+
+	_, err = NewSyncBufioWriter("/not_exist/file", NewSyncer("test", DefaultSyncPolicy))
+	assert.Error(t, err)
+}
@@ -22,6 +22,16 @@ import (
 	"sync"
 )
 
+// maxPooledBytesSize is the max capacity of byte slice put back to pool,
+// too large slice is dropped for avoiding retaining memory after query burst.
+const maxPooledBytesSize = 16 * 1024 * 1024
+
+// bytesPool is a set of temporary byte slices for marshaling payloads.
+var bytesPool = &sync.Pool{New: func() interface{} {
+	buf := make([]byte, 0, 4096)
+	return &buf
+}}
+
 // bufferPool is a set of temporary buffers for storing bytes.Buffer.
 var bufferPool = &sync.Pool{New: func() interface{} {
 	return &bytes.Buffer{}
@@ -37,3 +47,22 @@ func PutBuffer(buf *bytes.Buffer) {
 	buf.Reset()
 	bufferPool.Put(buf)
 }
+
+// GetBytes picks a byte slice from the pool, then resizes it to length n.
+func GetBytes(n int) *[]byte {
+	buf := bytesPool.Get().(*[]byte)
+	if cap(*buf) < n {
+		*buf = make([]byte, n)
+	}
+	*buf = (*buf)[:n]
+	return buf
+}
+
+// PutBytes returns a byte slice to the pool
+func PutBytes(buf *[]byte) {
+	if buf == nil || cap(*buf) > maxPooledBytesSize {
+		return
+	}
+	*buf = (*buf)[:0]
+	bytesPool.Put(buf)
+}
@@ -36,3 +36,19 @@ func Test_BufferPool(t *testing.T) {
 	buf2 := GetBuffer()
 	assert.Equal(t, 0, buf2.Len())
 }
+
+func Test_BytesPool(t *testing.T) {
+	buf1 := GetBytes(10)
+	assert.Len(t, *buf1, 10)
+	PutBytes(buf1)
+
+	buf2 := GetBytes(8192)
+	assert.Len(t, *buf2, 8192)
+	PutBytes(buf2)
+
+	// too large, drop it
+	buf3 := GetBytes(maxPooledBytesSize + 1)
+	assert.Len(t, *buf3, maxPooledBytesSize+1)
+	PutBytes(buf3)
+	PutBytes(nil)
+}
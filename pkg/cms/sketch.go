@@ -0,0 +1,81 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cms
+
+import (
+	"github.com/cespare/xxhash"
+)
+
+// Sketch represents a count-min sketch which estimates the frequency of values with fixed memory,
+// the estimated count is never less than the real count.
+type Sketch struct {
+	width    uint64
+	counters [][]uint32
+}
+
+// New creates a count-min sketch with depth rows and width counters of each row.
+func New(depth, width int) *Sketch {
+	counters := make([][]uint32, depth)
+	for idx := range counters {
+		counters[idx] = make([]uint32, width)
+	}
+	return &Sketch{width: uint64(width), counters: counters}
+}
+
+// Add adds delta to the count of value, returns the estimated count after adding.
+func (s *Sketch) Add(value []byte, delta uint32) uint32 {
+	h1, h2 := hash(value)
+	estimate := ^uint32(0)
+	for row := range s.counters {
+		idx := (h1 + uint64(row)*h2) % s.width
+		s.counters[row][idx] += delta
+		if s.counters[row][idx] < estimate {
+			estimate = s.counters[row][idx]
+		}
+	}
+	return estimate
+}
+
+// Count returns the estimated count of value.
+func (s *Sketch) Count(value []byte) uint32 {
+	h1, h2 := hash(value)
+	estimate := ^uint32(0)
+	for row := range s.counters {
+		idx := (h1 + uint64(row)*h2) % s.width
+		if s.counters[row][idx] < estimate {
+			estimate = s.counters[row][idx]
+		}
+	}
+	return estimate
+}
+
+// Reset resets all counters.
+func (s *Sketch) Reset() {
+	for row := range s.counters {
+		counters := s.counters[row]
+		for idx := range counters {
+			counters[idx] = 0
+		}
+	}
+}
+
+// hash returns two hashes of value, the hash of each row is derived by double hashing.
+func hash(value []byte) (h1, h2 uint64) {
+	h := xxhash.Sum64(value)
+	return h, h>>32 | h<<32 | 1
+}
@@ -0,0 +1,43 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cms
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketch(t *testing.T) {
+	s := New(4, 1024)
+	assert.Zero(t, s.Count([]byte("a")))
+	assert.Equal(t, uint32(1), s.Add([]byte("a"), 1))
+	assert.Equal(t, uint32(11), s.Add([]byte("a"), 10))
+	for i := 0; i < 1000; i++ {
+		s.Add([]byte(strconv.Itoa(i)), 1)
+	}
+	// never underestimate
+	assert.GreaterOrEqual(t, s.Count([]byte("a")), uint32(11))
+	assert.LessOrEqual(t, s.Count([]byte("a")), uint32(20))
+	for i := 0; i < 1000; i++ {
+		assert.GreaterOrEqual(t, s.Count([]byte(strconv.Itoa(i))), uint32(1))
+	}
+	s.Reset()
+	assert.Zero(t, s.Count([]byte("a")))
+}
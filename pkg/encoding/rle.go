@@ -0,0 +1,275 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/lindb/lindb/pkg/stream"
+)
+
+// reference:
+// run length encoding for integer/boolean values, which avoids forcing them through float64 xor.
+// int:  <num of values><list of run: <zigzag delta><run length>>
+// bool: <num of values><first value><list of run length>, value of each run flips from previous run.
+// monotonic counters with fixed step or constant values only need one run.
+
+var errBadRun = errors.New("bad run length")
+
+// IntRLEEncoder represents a run length encoding over zigzag encoded deltas for int64 values
+type IntRLEEncoder struct {
+	previous int64
+	delta    int64
+	run      uint64
+	count    int
+	buf      bytes.Buffer
+	runs     *stream.BufferWriter
+}
+
+// NewIntRLEEncoder creates a int run length encoder
+func NewIntRLEEncoder() *IntRLEEncoder {
+	e := &IntRLEEncoder{}
+	e.runs = stream.NewBufferWriter(&e.buf)
+	return e
+}
+
+// Reset clears the underlying data structure to prepare for next use
+func (e *IntRLEEncoder) Reset() {
+	e.runs.Reset()
+	e.previous = 0
+	e.delta = 0
+	e.run = 0
+	e.count = 0
+}
+
+// Add adds a new value
+func (e *IntRLEEncoder) Add(v int64) {
+	delta := v - e.previous
+	e.previous = v
+	e.count++
+	if e.run > 0 && delta == e.delta {
+		e.run++
+		return
+	}
+	if e.run > 0 {
+		e.runs.PutUvarint64(ZigZagEncode(e.delta))
+		e.runs.PutUvarint64(e.run)
+	}
+	e.delta = delta
+	e.run = 1
+}
+
+// Bytes returns binary data
+func (e *IntRLEEncoder) Bytes() ([]byte, error) {
+	if err := e.runs.Error(); err != nil {
+		return nil, err
+	}
+	writer := stream.NewBufferWriter(nil)
+	writer.PutUvarint64(uint64(e.count)) // num of values
+	if e.count > 0 {
+		writer.PutBytes(e.buf.Bytes())
+		// pending run
+		writer.PutUvarint64(ZigZagEncode(e.delta))
+		writer.PutUvarint64(e.run)
+	}
+	return writer.Bytes()
+}
+
+// IntRLEDecoder represents a run length decoding for int64 values
+type IntRLEDecoder struct {
+	sr       *stream.Reader
+	count    int
+	pos      int
+	previous int64
+	delta    int64
+	run      uint64
+	err      error
+}
+
+// NewIntRLEDecoder creates a int run length decoder
+func NewIntRLEDecoder(buf []byte) *IntRLEDecoder {
+	d := &IntRLEDecoder{
+		sr: stream.NewReader(nil),
+	}
+	d.Reset(buf)
+	return d
+}
+
+// Reset resets the decoder with new binary data
+func (d *IntRLEDecoder) Reset(buf []byte) {
+	d.sr.Reset(buf)
+	d.count = int(d.sr.ReadUvarint64()) // num of values
+	d.pos = 0
+	d.previous = 0
+	d.delta = 0
+	d.run = 0
+	d.err = d.sr.Error()
+}
+
+// HasNext tests if has more value
+func (d *IntRLEDecoder) HasNext() bool {
+	return d.err == nil && d.pos < d.count
+}
+
+// Next returns next value if exist
+func (d *IntRLEDecoder) Next() int64 {
+	if d.run == 0 {
+		d.delta = ZigZagDecode(d.sr.ReadUvarint64())
+		d.run = d.sr.ReadUvarint64()
+		if d.err = d.sr.Error(); d.err == nil && d.run == 0 {
+			d.err = errBadRun
+		}
+		if d.err != nil {
+			return 0
+		}
+	}
+	d.run--
+	d.pos++
+	d.previous += d.delta
+	return d.previous
+}
+
+// Error returns the error when decoding corrupted data
+func (d *IntRLEDecoder) Error() error {
+	return d.err
+}
+
+// BoolRLEEncoder represents a run length encoding for boolean values
+type BoolRLEEncoder struct {
+	first   bool
+	current bool
+	run     uint64
+	count   int
+	buf     bytes.Buffer
+	runs    *stream.BufferWriter
+}
+
+// NewBoolRLEEncoder creates a bool run length encoder
+func NewBoolRLEEncoder() *BoolRLEEncoder {
+	e := &BoolRLEEncoder{}
+	e.runs = stream.NewBufferWriter(&e.buf)
+	return e
+}
+
+// Reset clears the underlying data structure to prepare for next use
+func (e *BoolRLEEncoder) Reset() {
+	e.runs.Reset()
+	e.first = false
+	e.current = false
+	e.run = 0
+	e.count = 0
+}
+
+// Add adds a new value
+func (e *BoolRLEEncoder) Add(v bool) {
+	e.count++
+	if e.count == 1 {
+		e.first = v
+		e.current = v
+		e.run = 1
+		return
+	}
+	if v == e.current {
+		e.run++
+		return
+	}
+	e.runs.PutUvarint64(e.run)
+	e.current = v
+	e.run = 1
+}
+
+// Bytes returns binary data
+func (e *BoolRLEEncoder) Bytes() ([]byte, error) {
+	if err := e.runs.Error(); err != nil {
+		return nil, err
+	}
+	writer := stream.NewBufferWriter(nil)
+	writer.PutUvarint64(uint64(e.count)) // num of values
+	if e.count > 0 {
+		if e.first {
+			writer.PutByte(1)
+		} else {
+			writer.PutByte(0)
+		}
+		writer.PutBytes(e.buf.Bytes())
+		// pending run
+		writer.PutUvarint64(e.run)
+	}
+	return writer.Bytes()
+}
+
+// BoolRLEDecoder represents a run length decoding for boolean values
+type BoolRLEDecoder struct {
+	sr      *stream.Reader
+	count   int
+	pos     int
+	current bool
+	run     uint64
+	err     error
+}
+
+// NewBoolRLEDecoder creates a bool run length decoder
+func NewBoolRLEDecoder(buf []byte) *BoolRLEDecoder {
+	d := &BoolRLEDecoder{
+		sr: stream.NewReader(nil),
+	}
+	d.Reset(buf)
+	return d
+}
+
+// Reset resets the decoder with new binary data
+func (d *BoolRLEDecoder) Reset(buf []byte) {
+	d.sr.Reset(buf)
+	d.count = int(d.sr.ReadUvarint64()) // num of values
+	d.pos = 0
+	d.run = 0
+	d.current = false
+	if d.count > 0 {
+		// value flips when reading run, so keeps the opposite of first value
+		d.current = d.sr.ReadByte() == 0
+	}
+	d.err = d.sr.Error()
+}
+
+// HasNext tests if has more value
+func (d *BoolRLEDecoder) HasNext() bool {
+	return d.err == nil && d.pos < d.count
+}
+
+// Next returns next value if exist
+func (d *BoolRLEDecoder) Next() bool {
+	if d.run == 0 {
+		d.run = d.sr.ReadUvarint64()
+		if d.err = d.sr.Error(); d.err == nil && d.run == 0 {
+			d.err = errBadRun
+		}
+		if d.err != nil {
+			return false
+		}
+		d.current = !d.current
+	}
+	d.run--
+	d.pos++
+	return d.current
+}
+
+// Error returns the error when decoding corrupted data
+func (d *BoolRLEDecoder) Error() error {
+	return d.err
+}
@@ -0,0 +1,94 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntRLE_Codec(t *testing.T) {
+	encoder := NewIntRLEEncoder()
+	data, err := encoder.Bytes()
+	assert.NoError(t, err)
+	decoder := NewIntRLEDecoder(data)
+	assert.False(t, decoder.HasNext())
+
+	values := []int64{10, 20, 30, 40, 40, 40, -5, math.MaxInt64, math.MinInt64, 0}
+	for _, v := range values {
+		encoder.Add(v)
+	}
+	data, err = encoder.Bytes()
+	assert.NoError(t, err)
+	decoder.Reset(data)
+	for _, v := range values {
+		assert.True(t, decoder.HasNext())
+		assert.Equal(t, v, decoder.Next())
+	}
+	assert.False(t, decoder.HasNext())
+	assert.NoError(t, decoder.Error())
+
+	// fixed step counter only needs one run
+	encoder.Reset()
+	for i := 0; i < 1000; i++ {
+		encoder.Add(int64(i * 10))
+	}
+	data, err = encoder.Bytes()
+	assert.NoError(t, err)
+	assert.True(t, len(data) < 10)
+
+	// corrupted data
+	decoder.Reset([]byte{10, 2, 0})
+	assert.True(t, decoder.HasNext())
+	assert.Equal(t, int64(0), decoder.Next())
+	assert.Error(t, decoder.Error())
+	assert.False(t, decoder.HasNext())
+}
+
+func TestBoolRLE_Codec(t *testing.T) {
+	encoder := NewBoolRLEEncoder()
+	data, err := encoder.Bytes()
+	assert.NoError(t, err)
+	decoder := NewBoolRLEDecoder(data)
+	assert.False(t, decoder.HasNext())
+
+	for _, first := range []bool{true, false} {
+		values := []bool{first, first, !first, first, first, first, !first}
+		encoder.Reset()
+		for _, v := range values {
+			encoder.Add(v)
+		}
+		data, err = encoder.Bytes()
+		assert.NoError(t, err)
+		decoder.Reset(data)
+		for _, v := range values {
+			assert.True(t, decoder.HasNext())
+			assert.Equal(t, v, decoder.Next())
+		}
+		assert.False(t, decoder.HasNext())
+		assert.NoError(t, decoder.Error())
+	}
+
+	// corrupted data
+	decoder.Reset([]byte{10, 1, 0})
+	assert.True(t, decoder.HasNext())
+	assert.False(t, decoder.Next())
+	assert.Error(t, decoder.Error())
+}
@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"sync"
 
 	"github.com/lindb/lindb/pkg/bit"
@@ -152,6 +153,14 @@ type TSDDecoder struct {
 	reader *bit.Reader
 	values *XORDecoder
 	buf    *bufioutil.Buffer
+	block  []byte // buffer for decoding zstd block
+
+	codec      TSDCodec
+	numOfSlots int    // num of slots of int/bool block
+	marks      []byte // slot marks of int/bool block
+	pos        int    // position of next slot mark of int/bool block
+	ints       *IntRLEDecoder
+	bools      *BoolRLEDecoder
 
 	idx uint16
 
@@ -167,6 +176,40 @@ func NewTSDDecoder(data []byte) *TSDDecoder {
 	return decoder
 }
 
+// ResetTSDBlock resets tsd block with time range, the block is decoded by the codec in block header,
+// int/bool block is decoded natively, returns err and resets with empty data if block is corrupted.
+func (d *TSDDecoder) ResetTSDBlock(block []byte, start, end uint16) error {
+	if err := d.resetTSDBlock(block, start, end); err != nil {
+		d.ResetWithTimeRange(nil, start, end)
+		return err
+	}
+	return nil
+}
+
+func (d *TSDDecoder) resetTSDBlock(block []byte, start, end uint16) error {
+	if len(block) <= tsdBlockHeaderSize {
+		return fmt.Errorf("tsd block is too short, length: %d", len(block))
+	}
+	data := block[tsdBlockHeaderSize:]
+	switch codec := TSDCodec(block[0]); codec {
+	case TSDCodecXOR:
+		d.ResetWithTimeRange(data, start, end)
+	case TSDCodecZstd:
+		decoded, err := decodeZstdBlock(data, d.block)
+		if err != nil {
+			return err
+		}
+		// keep decoded buffer for reuse
+		d.block = decoded
+		d.ResetWithTimeRange(decoded, start, end)
+	case TSDCodecInt, TSDCodecBool:
+		return d.resetValueBlock(codec, data, start, end)
+	default:
+		return fmt.Errorf("unknown tsd codec: %d", codec)
+	}
+	return nil
+}
+
 // ResetWithTimeRange resets tsd data and reads the meta info from the data with time range
 func (d *TSDDecoder) ResetWithTimeRange(data []byte, start, end uint16) {
 	d.reset(data)
@@ -202,6 +245,8 @@ func (d *TSDDecoder) reset(data []byte) {
 		d.values.Reset()
 		d.buf.SetBuf(data)
 	}
+	d.codec = TSDCodecXOR
+	d.pos = 0
 	d.idx = 0
 	d.err = nil
 }
@@ -221,6 +266,11 @@ func (d *TSDDecoder) EndTime() uint16 {
 	return d.endTime
 }
 
+// Codec returns the codec of tsd block, xor if resets with tsd stream
+func (d *TSDDecoder) Codec() TSDCodec {
+	return d.codec
+}
+
 // Next returns if has next slot data
 func (d *TSDDecoder) Next() bool {
 	if d.startTime+d.idx <= d.endTime {
@@ -232,6 +282,11 @@ func (d *TSDDecoder) Next() bool {
 
 // HasValue returns slot value if exist
 func (d *TSDDecoder) HasValue() bool {
+	if d.codec == TSDCodecInt || d.codec == TSDCodecBool {
+		pos := d.pos
+		d.pos++
+		return pos < d.numOfSlots && d.marks[pos/8]&(1<<(pos%8)) != 0
+	}
 	if d.reader == nil {
 		return false
 	}
@@ -259,8 +314,11 @@ func (d *TSDDecoder) Slot() uint16 {
 	return d.startTime + d.idx - 1
 }
 
-// Value returns value of time slot
+// Value returns value of time slot, int/bool value is returned as the bits of float64
 func (d *TSDDecoder) Value() uint64 {
+	if d.codec == TSDCodecInt || d.codec == TSDCodecBool {
+		return math.Float64bits(float64(d.nextInt()))
+	}
 	if d.values == nil {
 		return 0
 	}
@@ -270,6 +328,28 @@ func (d *TSDDecoder) Value() uint64 {
 	return 0
 }
 
+// IntValue returns int64 value of time slot, bool value is returned as 0/1,
+// float value is truncated if block isn't encoded with int/bool codec.
+func (d *TSDDecoder) IntValue() int64 {
+	if d.codec == TSDCodecInt || d.codec == TSDCodecBool {
+		return d.nextInt()
+	}
+	return int64(math.Float64frombits(d.Value()))
+}
+
+func (d *TSDDecoder) nextInt() int64 {
+	if d.codec == TSDCodecBool {
+		if d.bools.HasNext() && d.bools.Next() {
+			return 1
+		}
+		return 0
+	}
+	if d.ints.HasNext() {
+		return d.ints.Next()
+	}
+	return 0
+}
+
 // DecodeTSDTime decodes start-time-slot and end-time-slot of tsd.
 // a simple method extracted from NewTSDDecoder to reduce gc pressure.
 func DecodeTSDTime(data []byte) (startTime, endTime uint16) {
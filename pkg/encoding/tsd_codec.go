@@ -0,0 +1,254 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/lindb/lindb/pkg/bit"
+	"github.com/lindb/lindb/pkg/stream"
+)
+
+// TSDCodec represents the codec of tsd block.
+type TSDCodec byte
+
+// Defines all codecs of tsd block.
+const (
+	// TSDCodecXOR is the default codec, just xor compressed tsd stream(gorilla).
+	TSDCodecXOR TSDCodec = iota
+	// TSDCodecZstd applies zstd over the xor compressed tsd stream, used for cold data.
+	TSDCodecZstd
+	// TSDCodecInt encodes the int64 values natively with zigzag delta + run length.
+	TSDCodecInt
+	// TSDCodecBool encodes the bool values natively with run length.
+	TSDCodecBool
+)
+
+// tsdBlockHeaderSize is the header size of tsd block, layout: codec id(1 byte) + data,
+// the codec of block is always recorded in header, so decoder never guesses it from data.
+const tsdBlockHeaderSize = 1
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// String returns the name of codec.
+func (c TSDCodec) String() string {
+	switch c {
+	case TSDCodecXOR:
+		return "xor"
+	case TSDCodecZstd:
+		return "zstd"
+	case TSDCodecInt:
+		return "int"
+	case TSDCodecBool:
+		return "bool"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseTSDCodec returns the codec by name, empty name means xor.
+func ParseTSDCodec(name string) (TSDCodec, error) {
+	switch name {
+	case "", TSDCodecXOR.String():
+		return TSDCodecXOR, nil
+	case TSDCodecZstd.String():
+		return TSDCodecZstd, nil
+	default:
+		return TSDCodecXOR, fmt.Errorf("unknown tsd codec: %s", name)
+	}
+}
+
+// NewTSDBlock returns the tsd block of xor compressed tsd stream, returns nil if stream is empty.
+func NewTSDBlock(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	block := make([]byte, tsdBlockHeaderSize, tsdBlockHeaderSize+len(data))
+	block[0] = byte(TSDCodecXOR)
+	return append(block, data...)
+}
+
+// EncodeTSDBlock encodes the xor compressed tsd stream of float values into tsd block with codec,
+// uses xor codec if codec isn't zstd or encoded data is not smaller.
+func EncodeTSDBlock(codec TSDCodec, data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	if codec != TSDCodecZstd {
+		return NewTSDBlock(data)
+	}
+	dst := make([]byte, tsdBlockHeaderSize, tsdBlockHeaderSize+len(data))
+	dst[0] = byte(codec)
+	dst = zstdEncoder.EncodeAll(data, dst)
+	if len(dst) >= tsdBlockHeaderSize+len(data) {
+		return NewTSDBlock(data)
+	}
+	return dst
+}
+
+// decodeZstdBlock decodes the data of zstd tsd block into xor compressed tsd stream, decoded data is written into buf(reused).
+func decodeZstdBlock(data []byte, buf []byte) ([]byte, error) {
+	decoded, err := zstdDecoder.DecodeAll(data, buf[:0])
+	if err != nil {
+		return nil, fmt.Errorf("decode %s tsd block failure: %w", TSDCodecZstd, err)
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("decode %s tsd block failure: empty data", TSDCodecZstd)
+	}
+	return decoded, nil
+}
+
+// TSDValueEncoder encodes the int64/bool values of time slots into tsd block with int/bool codec,
+// layout: codec id + num of slots + slot marks(bitmap) + run length encoded values,
+// values are written natively, so integers keep the precision above 2^53.
+// The block has no time slot range, which is kept by the metric block.
+type TSDValueEncoder struct {
+	codec      TSDCodec
+	numOfSlots int
+	marks      []byte
+	ints       *IntRLEEncoder
+	bools      *BoolRLEEncoder
+}
+
+// NewTSDValueEncoder creates tsd value encoder with int/bool codec.
+func NewTSDValueEncoder(codec TSDCodec) *TSDValueEncoder {
+	e := &TSDValueEncoder{codec: codec}
+	if codec == TSDCodecBool {
+		e.bools = NewBoolRLEEncoder()
+	} else {
+		e.codec = TSDCodecInt
+		e.ints = NewIntRLEEncoder()
+	}
+	return e
+}
+
+// AppendTime appends time slot, marks time slot if has data point
+func (e *TSDValueEncoder) AppendTime(slot bit.Bit) {
+	if e.numOfSlots%8 == 0 {
+		e.marks = append(e.marks, 0)
+	}
+	if slot == bit.One {
+		e.marks[e.numOfSlots/8] |= 1 << (e.numOfSlots % 8)
+	}
+	e.numOfSlots++
+}
+
+// AppendValue appends data point value, value is the bits of int64(0/1 for bool).
+func (e *TSDValueEncoder) AppendValue(value uint64) {
+	if e.codec == TSDCodecBool {
+		e.bools.Add(value != 0)
+		return
+	}
+	e.ints.Add(int64(value))
+}
+
+// Reset resets the encoder for reuse
+func (e *TSDValueEncoder) Reset() {
+	e.numOfSlots = 0
+	e.marks = e.marks[:0]
+	if e.codec == TSDCodecBool {
+		e.bools.Reset()
+	} else {
+		e.ints.Reset()
+	}
+}
+
+// Bytes returns the tsd block, returns nil if no data point appended.
+func (e *TSDValueEncoder) Bytes() ([]byte, error) {
+	var values []byte
+	var err error
+	if e.codec == TSDCodecBool {
+		if e.bools.count == 0 {
+			return nil, nil
+		}
+		values, err = e.bools.Bytes()
+	} else {
+		if e.ints.count == 0 {
+			return nil, nil
+		}
+		values, err = e.ints.Bytes()
+	}
+	if err != nil {
+		return nil, err
+	}
+	writer := stream.NewBufferWriter(nil)
+	writer.PutByte(byte(e.codec))
+	writer.PutUvarint64(uint64(e.numOfSlots))
+	writer.PutBytes(e.marks)
+	writer.PutBytes(values)
+	return writer.Bytes()
+}
+
+// BytesWithoutTime returns the tsd block, same as Bytes because block has no time slot range.
+func (e *TSDValueEncoder) BytesWithoutTime() ([]byte, error) {
+	return e.Bytes()
+}
+
+// resetValueBlock resets the decoder with the data of int/bool tsd block and time range,
+// returns err if slot marks don't match the values.
+func (d *TSDDecoder) resetValueBlock(codec TSDCodec, data []byte, start, end uint16) error {
+	reader := stream.NewReader(data)
+	numOfSlots := int(reader.ReadUvarint64())
+	if reader.Error() != nil || numOfSlots > math.MaxUint16+1 {
+		return fmt.Errorf("decode %s tsd block failure: bad num of slots", codec)
+	}
+	marks := reader.ReadSlice((numOfSlots + 7) / 8)
+	if reader.Error() != nil {
+		return fmt.Errorf("decode %s tsd block failure: %w", codec, reader.Error())
+	}
+	values := data[reader.Position():]
+	numOfValues := 0
+	for _, mark := range marks {
+		numOfValues += bits.OnesCount8(mark)
+	}
+	var count int
+	var err error
+	if codec == TSDCodecBool {
+		if d.bools == nil {
+			d.bools = NewBoolRLEDecoder(values)
+		} else {
+			d.bools.Reset(values)
+		}
+		count, err = d.bools.count, d.bools.Error()
+	} else {
+		if d.ints == nil {
+			d.ints = NewIntRLEDecoder(values)
+		} else {
+			d.ints.Reset(values)
+		}
+		count, err = d.ints.count, d.ints.Error()
+	}
+	if err != nil {
+		return fmt.Errorf("decode %s tsd block failure: %w", codec, err)
+	}
+	if count != numOfValues {
+		return fmt.Errorf("decode %s tsd block failure: values don't match slot marks", codec)
+	}
+	d.ResetWithTimeRange(nil, start, end)
+	d.codec = codec
+	d.numOfSlots = numOfSlots
+	d.marks = marks
+	return nil
+}
@@ -0,0 +1,185 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/bit"
+)
+
+func TestParseTSDCodec(t *testing.T) {
+	codec, err := ParseTSDCodec("")
+	assert.NoError(t, err)
+	assert.Equal(t, TSDCodecXOR, codec)
+	codec, err = ParseTSDCodec("xor")
+	assert.NoError(t, err)
+	assert.Equal(t, TSDCodecXOR, codec)
+	codec, err = ParseTSDCodec("zstd")
+	assert.NoError(t, err)
+	assert.Equal(t, TSDCodecZstd, codec)
+	_, err = ParseTSDCodec("lz4")
+	assert.Error(t, err)
+	assert.Equal(t, "unknown", TSDCodec(100).String())
+	assert.Equal(t, "int", TSDCodecInt.String())
+	assert.Equal(t, "bool", TSDCodecBool.String())
+}
+
+func TestTSDBlock_Codec(t *testing.T) {
+	encoder := NewTSDEncoder(0)
+	for i := 0; i < 360; i++ {
+		encoder.AppendTime(bit.One)
+		encoder.AppendValue(uint64(i % 10))
+	}
+	data, err := encoder.BytesWithoutTime()
+	assert.NoError(t, err)
+
+	decoder := NewTSDDecoder(nil)
+	// case 1: xor codec
+	block := EncodeTSDBlock(TSDCodecXOR, data)
+	assert.Equal(t, byte(TSDCodecXOR), block[0])
+	assert.Equal(t, data, block[1:])
+	assert.Equal(t, block, NewTSDBlock(data))
+	assert.Equal(t, block, EncodeTSDBlock(TSDCodecInt, data))
+	assert.Nil(t, EncodeTSDBlock(TSDCodecZstd, nil))
+	assert.Nil(t, NewTSDBlock(nil))
+	assert.NoError(t, decoder.ResetTSDBlock(block, 0, 359))
+	assert.Equal(t, TSDCodecXOR, decoder.Codec())
+	// case 2: zstd codec
+	block = EncodeTSDBlock(TSDCodecZstd, data)
+	assert.Equal(t, byte(TSDCodecZstd), block[0])
+	assert.True(t, len(block) < len(data))
+	decodedData, err := decodeZstdBlock(block[1:], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decodedData)
+	// case 3: decode with tsd decoder
+	assert.NoError(t, decoder.ResetTSDBlock(block, 0, 359))
+	assert.Equal(t, TSDCodecXOR, decoder.Codec())
+	for i := 0; i < 360; i++ {
+		assert.True(t, decoder.Next())
+		assert.True(t, decoder.HasValue())
+		assert.Equal(t, uint64(i%10), decoder.Value())
+	}
+	assert.False(t, decoder.Next())
+	// case 4: encoded block isn't smaller, uses xor codec
+	small := []byte{1, 2, 3}
+	assert.Equal(t, []byte{byte(TSDCodecXOR), 1, 2, 3}, EncodeTSDBlock(TSDCodecZstd, small))
+	// case 5: xor stream starts with zstd codec id is kept as is
+	block = EncodeTSDBlock(TSDCodecXOR, []byte{byte(TSDCodecZstd), 1, 2, 3})
+	assert.NoError(t, decoder.ResetTSDBlock(block, 0, 10))
+	assert.Equal(t, TSDCodecXOR, decoder.Codec())
+	// case 6: corrupted block/unknown codec, decoder has no value
+	assert.Error(t, decoder.ResetTSDBlock(nil, 0, 359))
+	assert.Error(t, decoder.ResetTSDBlock([]byte{byte(TSDCodecZstd), 1, 2, 3, 4, 5, 6, 7, 8}, 0, 359))
+	assert.Error(t, decoder.ResetTSDBlock([]byte{100, 1, 2, 3}, 0, 359))
+	assert.Error(t, decoder.ResetTSDBlock([]byte{byte(TSDCodecZstd), 1, 2, 3}, 0, 359))
+	assert.True(t, decoder.Next())
+	assert.False(t, decoder.HasValue())
+}
+
+func TestTSDBlock_ValueCodec(t *testing.T) {
+	newBlock := func(codec TSDCodec, values map[int]int64, slots int) []byte {
+		encoder := NewTSDValueEncoder(codec)
+		for i := 0; i < slots; i++ {
+			value, ok := values[i]
+			if !ok {
+				encoder.AppendTime(bit.Zero)
+				continue
+			}
+			encoder.AppendTime(bit.One)
+			encoder.AppendValue(uint64(value))
+		}
+		block, err := encoder.Bytes()
+		assert.NoError(t, err)
+		return block
+	}
+	decoder := NewTSDDecoder(nil)
+	assertDecoded := func(block []byte, values map[int]int64, slots int) {
+		assert.NoError(t, decoder.ResetTSDBlock(block, 10, uint16(10+slots-1)))
+		assert.Equal(t, TSDCodec(block[0]), decoder.Codec())
+		for i := 0; i < slots; i++ {
+			value, ok := values[i]
+			assert.Equal(t, ok, decoder.HasValueWithSlot(uint16(10+i)))
+			if ok {
+				assert.Equal(t, value, decoder.IntValue())
+			}
+		}
+		assert.False(t, decoder.HasValueWithSlot(uint16(10+slots)))
+	}
+
+	// case 1: integer values above 2^53 keep the precision
+	intValues := make(map[int]int64)
+	for i := 0; i < 360; i++ {
+		if i%7 != 3 {
+			intValues[i] = 1<<60 + int64(i)
+		}
+	}
+	block := newBlock(TSDCodecInt, intValues, 360)
+	assert.Equal(t, byte(TSDCodecInt), block[0])
+	assertDecoded(block, intValues, 360)
+	// case 2: boolean values
+	boolValues := make(map[int]int64)
+	for i := 0; i < 360; i++ {
+		boolValues[i] = int64(i / 100 % 2)
+	}
+	block = newBlock(TSDCodecBool, boolValues, 360)
+	assert.Equal(t, byte(TSDCodecBool), block[0])
+	assert.True(t, len(block) < 60)
+	assertDecoded(block, boolValues, 360)
+	// case 3: value returns the bits of float64
+	assert.NoError(t, decoder.ResetTSDBlock(newBlock(TSDCodecInt, map[int]int64{1: -5}, 3), 0, 2))
+	assert.False(t, decoder.HasValue())
+	assert.True(t, decoder.HasValue())
+	assert.Equal(t, -5.0, math.Float64frombits(decoder.Value()))
+	assert.False(t, decoder.HasValue())
+	// case 4: int value of xor block
+	xor := NewTSDEncoder(0)
+	xor.AppendTime(bit.One)
+	xor.AppendValue(math.Float64bits(10.5))
+	data, _ := xor.BytesWithoutTime()
+	assert.NoError(t, decoder.ResetTSDBlock(NewTSDBlock(data), 0, 0))
+	assert.True(t, decoder.HasValueWithSlot(0))
+	assert.Equal(t, int64(10), decoder.IntValue())
+	// case 5: reset encoder, empty data
+	encoder := NewTSDValueEncoder(TSDCodecInt)
+	encoder.AppendTime(bit.Zero)
+	block, err := encoder.BytesWithoutTime()
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+	encoder.AppendTime(bit.One)
+	encoder.AppendValue(1)
+	encoder.Reset()
+	block, err = encoder.Bytes()
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+	encoder = NewTSDValueEncoder(TSDCodecBool)
+	encoder.Reset()
+	block, err = encoder.Bytes()
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+	// case 6: corrupted block
+	assert.Error(t, decoder.ResetTSDBlock([]byte{byte(TSDCodecInt), 0xFF}, 0, 10))
+	assert.Error(t, decoder.ResetTSDBlock([]byte{byte(TSDCodecInt), 100, 1}, 0, 10))
+	assert.Error(t, decoder.ResetTSDBlock([]byte{byte(TSDCodecBool), 2, 3, 0}, 0, 10))
+	assert.Error(t, decoder.ResetTSDBlock([]byte{byte(TSDCodecBool), 2, 3, 0xFF}, 0, 10))
+	assert.Equal(t, TSDCodecXOR, decoder.Codec())
+	assert.False(t, decoder.HasValueWithSlot(0))
+}
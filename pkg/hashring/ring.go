@@ -0,0 +1,126 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hashring
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/cespare/xxhash"
+)
+
+// DefaultVirtualNodes is the default num. of virtual nodes of each shard on hash ring.
+const DefaultVirtualNodes = 128
+
+// Ring represents the consistent hash ring which maps the series hash to shard,
+// each shard has virtual nodes on ring whose positions only depend on shard id and virtual node index,
+// so that only the series owned by new shards are remapped when num. of shard increases.
+// Ring is immutable after created, so it is thread-safe.
+type Ring struct {
+	numOfShard   int32
+	virtualNodes int
+	points       []uint64 // sorted positions of virtual nodes
+	shards       []int32  // shard id of virtual node, same index of points
+}
+
+// New creates the consistent hash ring with num. of shard and virtual nodes of each shard.
+func New(numOfShard int32, virtualNodes int) *Ring {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	r := &Ring{
+		numOfShard:   numOfShard,
+		virtualNodes: virtualNodes,
+	}
+	if numOfShard <= 0 {
+		return r
+	}
+	type virtualNode struct {
+		point   uint64
+		shardID int32
+	}
+	nodes := make([]virtualNode, 0, int(numOfShard)*virtualNodes)
+	var key [8]byte
+	for shardID := int32(0); shardID < numOfShard; shardID++ {
+		for idx := 0; idx < virtualNodes; idx++ {
+			binary.BigEndian.PutUint32(key[:4], uint32(shardID))
+			binary.BigEndian.PutUint32(key[4:], uint32(idx))
+			nodes = append(nodes, virtualNode{point: xxhash.Sum64(key[:]), shardID: shardID})
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].point == nodes[j].point {
+			return nodes[i].shardID < nodes[j].shardID
+		}
+		return nodes[i].point < nodes[j].point
+	})
+	r.points = make([]uint64, len(nodes))
+	r.shards = make([]int32, len(nodes))
+	for idx, node := range nodes {
+		r.points[idx] = node.point
+		r.shards[idx] = node.shardID
+	}
+	return r
+}
+
+// NumOfShard returns the num. of shard on ring.
+func (r *Ring) NumOfShard() int32 {
+	return r.numOfShard
+}
+
+// VirtualNodes returns the num. of virtual nodes of each shard.
+func (r *Ring) VirtualNodes() int {
+	return r.virtualNodes
+}
+
+// Get returns the shard id which owns the hash, the owner is the first virtual node clockwise.
+// returns 0 if ring is empty.
+func (r *Ring) Get(hash uint64) int32 {
+	if len(r.points) == 0 {
+		return 0
+	}
+	idx := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= hash
+	})
+	if idx == len(r.points) {
+		// wrap around
+		idx = 0
+	}
+	return r.shards[idx]
+}
+
+// Ownership returns the ratio of hash space owned by each shard, index is shard id.
+func (r *Ring) Ownership() []float64 {
+	ownership := make([]float64, r.numOfShard)
+	if len(r.points) == 0 {
+		return ownership
+	}
+	last := len(r.points) - 1
+	for idx, point := range r.points {
+		var arc float64
+		if idx == 0 {
+			// wrap around range: (last point, max] + [0, first point]
+			arc = float64(math.MaxUint64-r.points[last]) + float64(point) + 1
+		} else {
+			arc = float64(point - r.points[idx-1])
+		}
+		ownership[r.shards[idx]] += arc / math.MaxUint64
+	}
+	return ownership
+}
@@ -0,0 +1,89 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hashring
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/cespare/xxhash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing_Get(t *testing.T) {
+	r := New(0, 0)
+	assert.Equal(t, int32(0), r.Get(100))
+	assert.Equal(t, DefaultVirtualNodes, r.VirtualNodes())
+	assert.Empty(t, r.Ownership())
+
+	r = New(4, 16)
+	assert.Equal(t, int32(4), r.NumOfShard())
+	assert.Equal(t, 16, r.VirtualNodes())
+	assert.Equal(t, r.shards[0], r.Get(0))
+	assert.Equal(t, r.shards[0], r.Get(math.MaxUint64))
+	assert.Equal(t, r.shards[1], r.Get(r.points[1]))
+	// same hash, same shard
+	assert.Equal(t, New(4, 16).Get(12345), r.Get(12345))
+}
+
+func TestRing_Ownership(t *testing.T) {
+	r := New(8, DefaultVirtualNodes)
+	ownership := r.Ownership()
+	assert.Len(t, ownership, 8)
+	total := 0.0
+	for _, ratio := range ownership {
+		// virtual nodes balance the hash space
+		assert.InDelta(t, 1.0/8, ratio, 0.05)
+		total += ratio
+	}
+	assert.InDelta(t, 1, total, 0.0001)
+}
+
+func TestRing_stable(t *testing.T) {
+	r1 := New(8, DefaultVirtualNodes)
+	r2 := New(10, DefaultVirtualNodes)
+	moved := 0
+	count := 10000
+	for i := 0; i < count; i++ {
+		hash := xxhash.Sum64String("series-" + strconv.Itoa(i))
+		shard1, shard2 := r1.Get(hash), r2.Get(hash)
+		if shard1 != shard2 {
+			// only remapped to new shards
+			assert.True(t, shard2 >= 8)
+			moved++
+		}
+	}
+	// about 2/10 series remapped, modulo remaps about 8/10
+	assert.InDelta(t, 0.2, float64(moved)/float64(count), 0.05)
+}
+
+func TestNewRouter(t *testing.T) {
+	r := NewRouter(true, 4)
+	_, ok := r.(*Ring)
+	assert.True(t, ok)
+
+	r = NewRouter(false, 4)
+	assert.Equal(t, int32(4), r.NumOfShard())
+	assert.Equal(t, 0, r.VirtualNodes())
+	assert.Equal(t, []float64{0.25, 0.25, 0.25, 0.25}, r.Ownership())
+	for i := uint64(0); i < 100; i++ {
+		assert.Equal(t, int32(i%4), r.Get(i))
+	}
+	assert.Equal(t, int32(0), NewModulo(0).Get(100))
+}
@@ -0,0 +1,76 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hashring
+
+// Router represents the routing which maps the series hash to shard.
+type Router interface {
+	// Get returns the shard id which owns the hash.
+	Get(hash uint64) int32
+	// NumOfShard returns the num. of shard.
+	NumOfShard() int32
+	// VirtualNodes returns the num. of virtual nodes of each shard, 0 if not based on hash ring.
+	VirtualNodes() int
+	// Ownership returns the ratio of hash space owned by each shard, index is shard id.
+	Ownership() []float64
+}
+
+// NewRouter creates the routing with num. of shard, consistent hash ring if hashRing is true, else modulo.
+func NewRouter(hashRing bool, numOfShard int32) Router {
+	if hashRing {
+		return New(numOfShard, DefaultVirtualNodes)
+	}
+	return NewModulo(numOfShard)
+}
+
+// Modulo represents the routing which maps the series hash to shard by hash % num. of shard,
+// all series are remapped when num. of shard changes.
+type Modulo struct {
+	numOfShard int32
+}
+
+// NewModulo creates the modulo routing with num. of shard.
+func NewModulo(numOfShard int32) *Modulo {
+	return &Modulo{numOfShard: numOfShard}
+}
+
+// Get returns the shard id which owns the hash, returns 0 if no shard.
+func (m *Modulo) Get(hash uint64) int32 {
+	if m.numOfShard <= 0 {
+		return 0
+	}
+	return int32(hash % uint64(m.numOfShard))
+}
+
+// NumOfShard returns the num. of shard.
+func (m *Modulo) NumOfShard() int32 {
+	return m.numOfShard
+}
+
+// VirtualNodes returns 0, because modulo routing has no virtual node.
+func (m *Modulo) VirtualNodes() int {
+	return 0
+}
+
+// Ownership returns the ratio of hash space owned by each shard, each shard owns the same ratio.
+func (m *Modulo) Ownership() []float64 {
+	ownership := make([]float64, m.numOfShard)
+	for idx := range ownership {
+		ownership[idx] = 1 / float64(m.numOfShard)
+	}
+	return ownership
+}
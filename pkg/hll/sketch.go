@@ -0,0 +1,102 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hll
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/cespare/xxhash"
+)
+
+const (
+	// precision is the number of hash bits used as register index.
+	precision = 14
+	// numOfRegisters is the number of registers(2^precision), standard error is about 1.04/sqrt(m)=0.81%.
+	numOfRegisters = 1 << precision
+)
+
+// ErrInvalidSketch represents the sketch data is corrupted.
+var ErrInvalidSketch = errors.New("invalid hyperloglog sketch")
+
+// Sketch represents a HyperLogLog sketch which estimates the number of distinct values with fixed memory,
+// sketches built on different nodes can be merged without losing accuracy.
+type Sketch struct {
+	registers []uint8
+}
+
+// New creates an empty sketch.
+func New() *Sketch {
+	return &Sketch{registers: make([]uint8, numOfRegisters)}
+}
+
+// FromBytes creates a sketch from the data returned by Bytes.
+func FromBytes(data []byte) (*Sketch, error) {
+	if len(data) != numOfRegisters {
+		return nil, ErrInvalidSketch
+	}
+	registers := make([]uint8, numOfRegisters)
+	copy(registers, data)
+	return &Sketch{registers: registers}, nil
+}
+
+// InsertString adds the value into sketch.
+func (s *Sketch) InsertString(value string) {
+	hash := xxhash.Sum64String(value)
+	idx := hash >> (64 - precision)
+	// set the lowest bit of remaining bits, make sure rank <= 64-precision+1
+	rank := uint8(bits.LeadingZeros64(hash<<precision|1<<(precision-1))) + 1
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// Merge merges other sketch into current sketch.
+func (s *Sketch) Merge(other *Sketch) {
+	for idx, rank := range other.registers {
+		if rank > s.registers[idx] {
+			s.registers[idx] = rank
+		}
+	}
+}
+
+// Estimate returns the estimated number of distinct values.
+func (s *Sketch) Estimate() uint64 {
+	m := float64(numOfRegisters)
+	sum := 0.0
+	zeros := 0
+	for _, rank := range s.registers {
+		sum += 1.0 / float64(uint64(1)<<rank)
+		if rank == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// small range correction using linear counting
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Bytes returns the registers of sketch.
+func (s *Sketch) Bytes() []byte {
+	return s.registers
+}
@@ -0,0 +1,55 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hll
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketch_Estimate(t *testing.T) {
+	s := New()
+	assert.Zero(t, s.Estimate())
+	for _, n := range []int{10, 1000, 100000} {
+		s = New()
+		for i := 0; i < n; i++ {
+			s.InsertString("value-" + strconv.Itoa(i))
+			// duplicated values are counted once
+			s.InsertString("value-" + strconv.Itoa(i))
+		}
+		assert.InDelta(t, float64(n), float64(s.Estimate()), float64(n)*0.03)
+	}
+}
+
+func TestSketch_Merge(t *testing.T) {
+	s1 := New()
+	s2 := New()
+	for i := 0; i < 20000; i++ {
+		s1.InsertString(strconv.Itoa(i))
+		s2.InsertString(strconv.Itoa(i + 10000))
+	}
+	s3, err := FromBytes(s2.Bytes())
+	assert.NoError(t, err)
+	s1.Merge(s3)
+	assert.InDelta(t, 30000, float64(s1.Estimate()), 30000*0.03)
+
+	_, err = FromBytes([]byte{1, 2})
+	assert.Equal(t, ErrInvalidSketch, err)
+}
@@ -0,0 +1,87 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/server"
+)
+
+var (
+	// DrainPath represents the path of draining server before shutdown.
+	DrainPath = "/admin/drain"
+	// ErrServerDraining is the error returned when request is rejected because server is draining.
+	ErrServerDraining = errors.New("server is draining, rejects new requests")
+)
+
+// DrainState represents if server is draining.
+type DrainState struct {
+	Draining bool `json:"draining"`
+}
+
+// DrainAPI represents the api of draining server before shutdown.
+type DrainAPI struct {
+	drainer server.Drainer
+	logger  *logger.Logger
+}
+
+// NewDrainAPI creates drain api.
+func NewDrainAPI(drainer server.Drainer) *DrainAPI {
+	return &DrainAPI{
+		drainer: drainer,
+		logger:  logger.GetLogger("http", "DrainAPI"),
+	}
+}
+
+// Register adds drain url route.
+func (d *DrainAPI) Register(route gin.IRoutes) {
+	route.GET(DrainPath, d.State)
+	route.PUT(DrainPath, d.Drain)
+}
+
+// State returns if server is draining.
+func (d *DrainAPI) State(c *gin.Context) {
+	OK(c, &DrainState{Draining: d.drainer.Draining()})
+}
+
+// Drain stops accepting new writes/queries, flushes pending data and deregisters from discovery,
+// responses after drained, then server can be stopped safely.
+func (d *DrainAPI) Drain(c *gin.Context) {
+	d.logger.Info("draining server by api")
+	if err := d.drainer.Drain(); err != nil {
+		Error(c, err)
+		return
+	}
+	OK(c, &DrainState{Draining: true})
+}
+
+// RejectWhenDraining returns a middleware which rejects request with http status code 503 when server is draining.
+func RejectWhenDraining(drainer server.Drainer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if drainer != nil && drainer.Draining() {
+			ServiceUnavailable(c, ErrServerDraining)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,81 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/pkg/server"
+)
+
+func TestDrainAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	drainer := server.NewMockDrainer(ctrl)
+	r := gin.New()
+	NewDrainAPI(drainer).Register(r)
+
+	drainer.EXPECT().Draining().Return(false)
+	resp := mock.DoRequest(t, r, http.MethodGet, DrainPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `{"draining":false}`, resp.Body.String())
+	// case 1: drain failure
+	drainer.EXPECT().Drain().Return(fmt.Errorf("err"))
+	resp = mock.DoRequest(t, r, http.MethodPut, DrainPath, "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// case 2: drain successfully
+	drainer.EXPECT().Drain().Return(nil)
+	resp = mock.DoRequest(t, r, http.MethodPut, DrainPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `{"draining":true}`, resp.Body.String())
+}
+
+func TestRejectWhenDraining(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	drainer := server.NewMockDrainer(ctrl)
+	r := gin.New()
+	r.Use(RejectWhenDraining(drainer))
+	r.GET("/query", func(c *gin.Context) {
+		OK(c, "ok")
+	})
+	drainer.EXPECT().Draining().Return(false)
+	resp := mock.DoRequest(t, r, http.MethodGet, "/query", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	drainer.EXPECT().Draining().Return(true)
+	resp = mock.DoRequest(t, r, http.MethodGet, "/query", "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+
+	// nil drainer, never draining
+	r = gin.New()
+	r.Use(RejectWhenDraining(nil))
+	r.GET("/query", func(c *gin.Context) {
+		OK(c, "ok")
+	})
+	resp = mock.DoRequest(t, r, http.MethodGet, "/query", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
@@ -0,0 +1,119 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+var (
+	// LogLevelPath represents the path of getting/changing log levels.
+	LogLevelPath = "/log/level"
+	// RecentLogPath represents the path of tailing recent log entries in memory.
+	RecentLogPath = "/log/recent"
+)
+
+// defaultRecentLogLimit is the default number of recent log entries returned.
+const defaultRecentLogLimit = 100
+
+// LogLevels represents the global log level and the log level overrides of modules.
+type LogLevels struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// LoggerAPI represents the api of changing log levels and tailing recent log entries on the fly.
+type LoggerAPI struct {
+	logger *logger.Logger
+}
+
+// NewLoggerAPI creates logger api.
+func NewLoggerAPI() *LoggerAPI {
+	return &LoggerAPI{
+		logger: logger.GetLogger("http", "LoggerAPI"),
+	}
+}
+
+// Register adds logger url route.
+func (l *LoggerAPI) Register(route gin.IRoutes) {
+	route.GET(LogLevelPath, l.GetLevels)
+	route.PUT(LogLevelPath, l.SetLevel)
+	route.GET(RecentLogPath, l.Recent)
+}
+
+// GetLevels returns the global log level and the log level overrides of modules.
+func (l *LoggerAPI) GetLevels(c *gin.Context) {
+	level, modules := logger.Levels()
+	OK(c, &LogLevels{Level: level, Modules: modules})
+}
+
+// SetLevel changes the log level of module, changes global log level if module is empty,
+// removes the override of module if level is empty.
+func (l *LoggerAPI) SetLevel(c *gin.Context) {
+	var param struct {
+		Module string `json:"module"`
+		Level  string `json:"level"`
+	}
+	if err := c.ShouldBind(&param); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+	var err error
+	if param.Module == "" {
+		err = logger.SetLevel(param.Level)
+	} else {
+		err = logger.SetModuleLevel(param.Module, param.Level)
+	}
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+	l.logger.Info("log level changed",
+		logger.String("module", param.Module), logger.String("level", param.Level))
+	level, modules := logger.Levels()
+	OK(c, &LogLevels{Level: level, Modules: modules})
+}
+
+// Recent returns the recent log entries in memory filtered by level/module.
+func (l *LoggerAPI) Recent(c *gin.Context) {
+	var param struct {
+		Level  string `form:"level"`
+		Module string `form:"module"`
+		Limit  int    `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&param); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+	minLevel := zapcore.DebugLevel
+	if param.Level != "" {
+		if err := minLevel.UnmarshalText([]byte(param.Level)); err != nil {
+			BadRequest(c, fmt.Sprintf("invalid log level: %s", param.Level))
+			return
+		}
+	}
+	if param.Limit <= 0 {
+		param.Limit = defaultRecentLogLimit
+	}
+	OK(c, logger.RecentEntries(minLevel, param.Module, param.Limit))
+}
@@ -0,0 +1,83 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/internal/mock"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+func TestLoggerAPI(t *testing.T) {
+	defer func() {
+		_ = logger.SetLevel("info")
+		_ = logger.SetModuleLevel("logger-api-test", "")
+	}()
+	r := gin.New()
+	api := NewLoggerAPI()
+	api.Register(r)
+
+	// case 1: bad request
+	resp := mock.DoRequest(t, r, http.MethodPut, LogLevelPath, `{"module":1}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	// case 2: invalid level
+	resp = mock.DoRequest(t, r, http.MethodPut, LogLevelPath, `{"level":"abc"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	// case 3: change global level
+	resp = mock.DoRequest(t, r, http.MethodPut, LogLevelPath, `{"level":"warn"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	// case 4: change module level
+	resp = mock.DoRequest(t, r, http.MethodPut, LogLevelPath, `{"module":"logger-api-test","level":"debug"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = mock.DoRequest(t, r, http.MethodGet, LogLevelPath, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	levels := &LogLevels{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), levels))
+	assert.Equal(t, "warn", levels.Level)
+	assert.Equal(t, "debug", levels.Modules["logger-api-test"])
+
+	// case 5: tail recent log entries
+	log := logger.GetLogger("logger-api-test", "test")
+	log.Debug("debug log")
+	log.Warn("warn log", logger.String("key", "value"))
+	logger.GetLogger("logger-api-test-other", "test").Debug("filtered by level")
+	resp = mock.DoRequest(t, r, http.MethodGet, RecentLogPath+"?module=logger-api-test&level=warn", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var entries []logger.Entry
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "warn log", entries[0].Message)
+	assert.Equal(t, "value", entries[0].Fields["key"])
+	resp = mock.DoRequest(t, r, http.MethodGet, RecentLogPath+"?module=logger-api-test&limit=1", "")
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "warn log", entries[0].Message)
+	resp = mock.DoRequest(t, r, http.MethodGet, RecentLogPath+"?module=logger-api-test-other", "")
+	assert.Equal(t, "null", resp.Body.String())
+	// case 6: invalid query param
+	resp = mock.DoRequest(t, r, http.MethodGet, RecentLogPath+"?level=abc", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = mock.DoRequest(t, r, http.MethodGet, RecentLogPath+"?limit=abc", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
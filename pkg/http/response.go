@@ -40,6 +40,35 @@ func NotFound(c *gin.Context) {
 	response(c, http.StatusNotFound, nil)
 }
 
+// Conflict responses error message and set the http status code 409.
+func Conflict(c *gin.Context, err error) {
+	_ = c.Error(err)
+	response(c, http.StatusConflict, err.Error())
+}
+
+// Forbidden responses error message and set the http status code 403.
+func Forbidden(c *gin.Context, err error) {
+	_ = c.Error(err)
+	response(c, http.StatusForbidden, err.Error())
+}
+
+// ServiceUnavailable responses error message and set the http status code 503.
+func ServiceUnavailable(c *gin.Context, err error) {
+	_ = c.Error(err)
+	response(c, http.StatusServiceUnavailable, err.Error())
+}
+
+// TooManyRequests responses error message and set the http status code 429.
+func TooManyRequests(c *gin.Context, err error) {
+	_ = c.Error(err)
+	response(c, http.StatusTooManyRequests, err.Error())
+}
+
+// BadRequest responses content and set the http status code 400.
+func BadRequest(c *gin.Context, content interface{}) {
+	response(c, http.StatusBadRequest, content)
+}
+
 // Error responses error message and set the http status code 500.
 func Error(c *gin.Context, err error) {
 	_ = c.Error(err)
@@ -51,6 +51,14 @@ func TestNotFound(t *testing.T) {
 	assert.Equal(t, 4, resp.Body.Len())
 }
 
+func TestBadRequest(t *testing.T) {
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	BadRequest(c, "bad")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, `"bad"`, resp.Body.String())
+}
+
 func TestError(t *testing.T) {
 	resp := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(resp)
@@ -58,3 +66,35 @@ func TestError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	assert.Equal(t, `"err"`, resp.Body.String())
 }
+
+func TestConflict(t *testing.T) {
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	Conflict(c, fmt.Errorf("err"))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, `"err"`, resp.Body.String())
+}
+
+func TestForbidden(t *testing.T) {
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	Forbidden(c, fmt.Errorf("err"))
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, `"err"`, resp.Body.String())
+}
+
+func TestServiceUnavailable(t *testing.T) {
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	ServiceUnavailable(c, fmt.Errorf("err"))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, `"err"`, resp.Body.String())
+}
+
+func TestTooManyRequests(t *testing.T) {
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	TooManyRequests(c, fmt.Errorf("err"))
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, `"err"`, resp.Body.String())
+}
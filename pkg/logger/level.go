@@ -0,0 +1,162 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/lindb/lindb/config"
+)
+
+var (
+	// moduleLevels stores the log level overrides of modules, module name => zapcore.Level,
+	// copy on write so that logging won't be blocked.
+	moduleLevels   atomic.Value
+	moduleLevelsMu sync.Mutex
+	// coreLevel is the level enabler of zap core, which enables the lowest level of all modules.
+	coreLevel zapcore.LevelEnabler = zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		if RunningAtomicLevel.Enabled(lvl) {
+			return true
+		}
+		for _, moduleLevel := range getModuleLevels() {
+			if moduleLevel.Enabled(lvl) {
+				return true
+			}
+		}
+		return false
+	})
+)
+
+func init() {
+	moduleLevels.Store(map[string]zapcore.Level{})
+}
+
+// getModuleLevels returns the log level overrides of modules.
+func getModuleLevels() map[string]zapcore.Level {
+	return moduleLevels.Load().(map[string]zapcore.Level)
+}
+
+// Levels returns the global log level and the log level overrides of modules.
+func Levels() (global string, modules map[string]string) {
+	modules = make(map[string]string)
+	for module, level := range getModuleLevels() {
+		modules[module] = level.String()
+	}
+	return RunningAtomicLevel.Level().String(), modules
+}
+
+// SetLevel changes the global log level on the fly.
+func SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	RunningAtomicLevel.SetLevel(lvl)
+	return nil
+}
+
+// SetModuleLevel changes the log level of module on the fly, removes the override if level is empty.
+func SetModuleLevel(module, level string) error {
+	if module == "" {
+		return fmt.Errorf("module cannot be empty")
+	}
+	var lvl zapcore.Level
+	if level != "" {
+		var err error
+		if lvl, err = parseLevel(level); err != nil {
+			return err
+		}
+	}
+	moduleLevelsMu.Lock()
+	defer moduleLevelsMu.Unlock()
+
+	levels := make(map[string]zapcore.Level)
+	for k, v := range getModuleLevels() {
+		levels[k] = v
+	}
+	if level == "" {
+		delete(levels, module)
+	} else {
+		levels[module] = lvl
+	}
+	moduleLevels.Store(levels)
+	return nil
+}
+
+// UpdateLevels applies the global log level and the log level overrides of modules in logging config,
+// the overrides of modules not in config are removed.
+func UpdateLevels(cfg config.Logging) error {
+	global, err := parseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	levels, err := ParseModuleLevels(cfg.ModuleLevels)
+	if err != nil {
+		return err
+	}
+	moduleLevelsMu.Lock()
+	defer moduleLevelsMu.Unlock()
+
+	RunningAtomicLevel.SetLevel(global)
+	moduleLevels.Store(levels)
+	return nil
+}
+
+// ParseModuleLevels parses the log level overrides of modules, format: module1:level1,module2:level2.
+func ParseModuleLevels(moduleLevels string) (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level)
+	for _, item := range strings.Split(moduleLevels, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid module level: %s, format is module:level", item)
+		}
+		lvl, err := parseLevel(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(parts[0])] = lvl
+	}
+	return levels, nil
+}
+
+// parseLevel parses log level, error, warn, info, and debug are available.
+func parseLevel(level string) (zapcore.Level, error) {
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return lvl, err
+	}
+	return lvl, nil
+}
+
+// enabled checks if the level is enabled for the module of logger.
+func (l *Logger) enabled(lvl zapcore.Level) bool {
+	if moduleLevel, ok := getModuleLevels()[l.module]; ok {
+		return moduleLevel.Enabled(lvl)
+	}
+	return RunningAtomicLevel.Enabled(lvl)
+}
@@ -0,0 +1,74 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/lindb/lindb/config"
+)
+
+func TestModuleLevels(t *testing.T) {
+	defer func() {
+		RunningAtomicLevel.SetLevel(zapcore.InfoLevel)
+		moduleLevels.Store(map[string]zapcore.Level{})
+	}()
+	log := GetLogger("level-test", "test")
+
+	assert.Error(t, SetLevel("abc"))
+	assert.NoError(t, SetLevel("warn"))
+	assert.False(t, log.enabled(zapcore.InfoLevel))
+	assert.False(t, coreLevel.Enabled(zapcore.DebugLevel))
+
+	assert.Error(t, SetModuleLevel("", "debug"))
+	assert.Error(t, SetModuleLevel("level-test", "abc"))
+	assert.NoError(t, SetModuleLevel("level-test", "debug"))
+	assert.True(t, log.enabled(zapcore.DebugLevel))
+	assert.False(t, GetLogger("other", "test").enabled(zapcore.DebugLevel))
+	// core enables the lowest level of all modules
+	assert.True(t, coreLevel.Enabled(zapcore.DebugLevel))
+	level, modules := Levels()
+	assert.Equal(t, "warn", level)
+	assert.Equal(t, map[string]string{"level-test": "debug"}, modules)
+	// remove override
+	assert.NoError(t, SetModuleLevel("level-test", ""))
+	assert.False(t, log.enabled(zapcore.DebugLevel))
+
+	assert.Error(t, UpdateLevels(config.Logging{Level: "abc"}))
+	assert.Error(t, UpdateLevels(config.Logging{Level: "info", ModuleLevels: "tsdb"}))
+	assert.NoError(t, UpdateLevels(config.Logging{Level: "info", ModuleLevels: " tsdb:warn, replication:debug,"}))
+	level, modules = Levels()
+	assert.Equal(t, "info", level)
+	assert.Equal(t, map[string]string{"tsdb": "warn", "replication": "debug"}, modules)
+}
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := ParseModuleLevels("")
+	assert.NoError(t, err)
+	assert.Empty(t, levels)
+	_, err = ParseModuleLevels(":debug")
+	assert.Error(t, err)
+	_, err = ParseModuleLevels("tsdb:abc")
+	assert.Error(t, err)
+	levels, err = ParseModuleLevels("tsdb:error")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]zapcore.Level{"tsdb": zapcore.ErrorLevel}, levels)
+}
@@ -114,25 +114,39 @@ func (l *Logger) getInitializedOrDefaultLogger() *zap.Logger {
 // Debug logs a message at DebugLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (l *Logger) Debug(msg string, fields ...zap.Field) {
-	l.getInitializedOrDefaultLogger().Debug(l.formatMsg(msg), fields...)
+	l.log(zapcore.DebugLevel, msg, fields)
 }
 
 // Info logs a message at InfoLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (l *Logger) Info(msg string, fields ...zap.Field) {
-	l.getInitializedOrDefaultLogger().Info(l.formatMsg(msg), fields...)
+	l.log(zapcore.InfoLevel, msg, fields)
 }
 
 // Warn logs a message at WarnLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (l *Logger) Warn(msg string, fields ...zap.Field) {
-	l.getInitializedOrDefaultLogger().Warn(l.formatMsg(msg), fields...)
+	l.log(zapcore.WarnLevel, msg, fields)
 }
 
 // Error logs a message at ErrorLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (l *Logger) Error(msg string, fields ...zap.Field) {
-	l.getInitializedOrDefaultLogger().Error(l.formatMsg(msg), fields...)
+	l.log(zapcore.ErrorLevel, msg, fields)
+}
+
+// log logs a message if the level is enabled for the module of logger,
+// keeps the log entry in memory except access log.
+func (l *Logger) log(lvl zapcore.Level, msg string, fields []zap.Field) {
+	if !l.enabled(lvl) {
+		return
+	}
+	if l.module != HTTPModule {
+		l.record(lvl, msg, fields)
+	}
+	if ce := l.getInitializedOrDefaultLogger().Check(lvl, l.formatMsg(msg)); ce != nil {
+		ce.Write(fields...)
+	}
 }
 
 // formatMsg formats msg using module name
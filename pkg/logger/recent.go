@@ -0,0 +1,123 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultRecentEntries is the max number of recent log entries kept in memory.
+const defaultRecentEntries = 2000
+
+// recentEntries keeps the recent log entries for tailing log on the fly.
+var recentEntries = newEntryRing(defaultRecentEntries)
+
+// Entry represents a log entry kept in memory.
+type Entry struct {
+	Time    int64                  `json:"time"`
+	Level   string                 `json:"level"`
+	Module  string                 `json:"module"`
+	Role    string                 `json:"role"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+
+	level zapcore.Level
+}
+
+// entryRing is a fixed size ring buffer of log entries, the oldest entry is overwritten when full.
+type entryRing struct {
+	mu      sync.RWMutex
+	entries []Entry
+	next    int // position of next entry
+	full    bool
+}
+
+func newEntryRing(size int) *entryRing {
+	return &entryRing{entries: make([]Entry, size)}
+}
+
+// add adds an entry into ring.
+func (r *entryRing) add(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = entry
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// tail returns the latest entries which match the level/module filter, sorted by time(oldest first).
+func (r *entryRing) tail(minLevel zapcore.Level, module string, limit int) []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	size := r.next
+	if r.full {
+		size = len(r.entries)
+	}
+	var result []Entry
+	// iterate from the newest entry
+	for i := 1; i <= size; i++ {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		entry := r.entries[(r.next-i+len(r.entries))%len(r.entries)]
+		if entry.level < minLevel || (module != "" && entry.Module != module) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	// reverse, oldest first
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// RecentEntries returns the latest log entries kept in memory whose level is greater than or equal to min level,
+// filters by module if module is not empty, returns all matched entries if limit <= 0.
+func RecentEntries(minLevel zapcore.Level, module string, limit int) []Entry {
+	return recentEntries.tail(minLevel, module, limit)
+}
+
+// record keeps the log entry in memory.
+func (l *Logger) record(lvl zapcore.Level, msg string, fields []zap.Field) {
+	entry := Entry{
+		Time:    time.Now().UnixNano() / int64(time.Millisecond),
+		Level:   lvl.String(),
+		Module:  l.module,
+		Role:    l.role,
+		Message: msg,
+		level:   lvl,
+	}
+	if len(fields) > 0 {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, field := range fields {
+			field.AddTo(enc)
+		}
+		entry.Fields = enc.Fields
+	}
+	recentEntries.add(entry)
+}
@@ -0,0 +1,66 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEntryRing(t *testing.T) {
+	r := newEntryRing(3)
+	assert.Empty(t, r.tail(zapcore.DebugLevel, "", 0))
+	for i := 0; i < 5; i++ {
+		level := zapcore.InfoLevel
+		if i%2 == 0 {
+			level = zapcore.ErrorLevel
+		}
+		r.add(Entry{Message: fmt.Sprintf("%d", i), Module: "m", level: level})
+	}
+	// oldest entries are overwritten
+	entries := r.tail(zapcore.DebugLevel, "", 0)
+	assert.Equal(t, []string{"2", "3", "4"}, messages(entries))
+	entries = r.tail(zapcore.DebugLevel, "m", 2)
+	assert.Equal(t, []string{"3", "4"}, messages(entries))
+	entries = r.tail(zapcore.ErrorLevel, "", 0)
+	assert.Equal(t, []string{"2", "4"}, messages(entries))
+	assert.Empty(t, r.tail(zapcore.DebugLevel, "other", 0))
+}
+
+func TestRecentEntries(t *testing.T) {
+	log := GetLogger("recent-test", "test")
+	log.Info("info log", Error(fmt.Errorf("err")))
+	GetLogger(HTTPModule, "test").Info("access log is not recorded")
+	entries := RecentEntries(zapcore.InfoLevel, "recent-test", 10)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "info", entries[0].Level)
+	assert.Equal(t, "test", entries[0].Role)
+	assert.Equal(t, "err", entries[0].Fields["error"])
+	assert.Empty(t, RecentEntries(zapcore.DebugLevel, HTTPModule, 10))
+}
+
+func messages(entries []Entry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.Message)
+	}
+	return result
+}
@@ -86,7 +86,7 @@ func newDefaultLogger() *zap.Logger {
 	core := zapcore.NewCore(
 		zapcore.NewConsoleEncoder(encoderConfig),
 		os.Stdout,
-		RunningAtomicLevel)
+		coreLevel)
 	return zap.New(core)
 }
 
@@ -113,13 +113,18 @@ func initLogger(logFilename string, cfg config.Logging) error {
 	if isTerminal {
 		w = os.Stdout
 	}
-	// parse logging level
-	if err := RunningAtomicLevel.UnmarshalText([]byte(cfg.Level)); err != nil {
+	// parse logging level and level overrides of modules
+	if err := UpdateLevels(cfg); err != nil {
 		return err
 	}
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = SimpleTimeEncoder
+	encoder := zapcore.NewConsoleEncoder
 	switch {
+	case logFilename == accessLogFileName && !isTerminal:
+		// access log is structured(json) for collecting by log agent
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewJSONEncoder
 	case logFilename == accessLogFileName:
 		encoderConfig.EncodeLevel = SimpleAccessLevelEncoder
 	default:
@@ -127,9 +132,9 @@ func initLogger(logFilename string, cfg config.Logging) error {
 	}
 	// check format
 	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoderConfig),
+		encoder(encoderConfig),
 		w,
-		RunningAtomicLevel)
+		coreLevel)
 	switch {
 	case logFilename == accessLogFileName:
 		accessLogger.Store(zap.New(core))
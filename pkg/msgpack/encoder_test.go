@@ -0,0 +1,222 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package msgpack
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonValue struct{}
+
+func (v jsonValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"json"`), nil
+}
+
+type ptrJSONValue struct{}
+
+func (v *ptrJSONValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"ptr"`), nil
+}
+
+type textValue struct{}
+
+func (v textValue) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+type errTextValue struct{}
+
+func (v errTextValue) MarshalText() ([]byte, error) {
+	return nil, fmt.Errorf("err")
+}
+
+type errJSONValue struct{}
+
+func (v errJSONValue) MarshalJSON() ([]byte, error) {
+	return nil, fmt.Errorf("err")
+}
+
+type Embedded struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+type testStruct struct {
+	*Embedded
+	B       string `json:"b"`
+	Omit    string `json:"omit,omitempty"`
+	Ignore  string `json:"-"`
+	NoTag   bool
+	Tags    map[string]string `json:"tags,omitempty"`
+	private int
+}
+
+func TestMarshal_Scalar(t *testing.T) {
+	cases := []struct {
+		in  interface{}
+		out []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{false, []byte{0xc2}},
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0xcc, 0x80}},
+		{uint16(256), []byte{0xcd, 0x01, 0x00}},
+		{uint32(65536), []byte{0xce, 0x00, 0x01, 0x00, 0x00}},
+		{uint64(math.MaxUint32 + 1), []byte{0xcf, 0, 0, 0, 1, 0, 0, 0, 0}},
+		{-1, []byte{0xff}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{0xd0, 0xdf}},
+		{int16(-129), []byte{0xd1, 0xff, 0x7f}},
+		{int32(-32769), []byte{0xd2, 0xff, 0xff, 0x7f, 0xff}},
+		{int64(math.MinInt32 - 1), []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff}},
+		{float32(1.5), []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"abc", []byte{0xa3, 'a', 'b', 'c'}},
+		{[]byte{1, 2}, []byte{0xc4, 0x02, 0x01, 0x02}},
+		{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{[2]bool{true, false}, []byte{0x92, 0xc3, 0xc2}},
+		{[]int(nil), []byte{0xc0}},
+		{map[string]int(nil), []byte{0xc0}},
+		{map[string]int{"a": 1}, []byte{0x81, 0xa1, 'a', 0x01}},
+		{(*int)(nil), []byte{0xc0}},
+		{jsonValue{}, []byte{0xa4, 'j', 's', 'o', 'n'}},
+		{&ptrJSONValue{}, []byte{0xa3, 'p', 't', 'r'}},
+		{(*ptrJSONValue)(nil), []byte{0xc0}},
+		{textValue{}, []byte{0xa4, 't', 'e', 'x', 't'}},
+		// not addressable, same as json
+		{ptrJSONValue{}, []byte{0x80}},
+		{&struct {
+			V ptrJSONValue `json:"v"`
+		}{}, []byte{0x81, 0xa1, 'v', 0xa3, 'p', 't', 'r'}},
+	}
+	for _, c := range cases {
+		data, err := Marshal(c.in)
+		assert.NoError(t, err)
+		assert.Equal(t, c.out, data, "%v", c.in)
+	}
+}
+
+func TestMarshal_Length(t *testing.T) {
+	data, err := Marshal(strings.Repeat("a", 32))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xd9, 32}, data[:2])
+	data, err = Marshal(strings.Repeat("a", 256))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xda, 0x01, 0x00}, data[:3])
+	data, err = Marshal(strings.Repeat("a", math.MaxUint16+1))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xdb, 0x00, 0x01, 0x00, 0x00}, data[:5])
+
+	data, err = Marshal(make([]byte, 256))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xc5, 0x01, 0x00}, data[:3])
+	data, err = Marshal(make([]byte, math.MaxUint16+1))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xc6, 0x00, 0x01, 0x00, 0x00}, data[:5])
+
+	data, err = Marshal(make([]int, 16))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xdc, 0x00, 0x10}, data[:3])
+	data, err = Marshal(make([]int, math.MaxUint16+1))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xdd, 0x00, 0x01, 0x00, 0x00}, data[:5])
+
+	m := make(map[int]int)
+	for i := 0; i < 16; i++ {
+		m[i] = i
+	}
+	data, err = Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xde, 0x00, 0x10}, data[:3])
+	for i := 16; i < math.MaxUint16+1; i++ {
+		m[i] = i
+	}
+	data, err = Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xdf, 0x00, 0x01, 0x00, 0x00}, data[:5])
+}
+
+func TestMarshal_Struct(t *testing.T) {
+	data, err := Marshal(&testStruct{B: "b", Ignore: "i", private: 1})
+	assert.NoError(t, err)
+	// embedded pointer is nil, field b of outer struct
+	assert.Equal(t, []byte{0x82, 0xa1, 'b', 0xa1, 'b', 0xa5, 'N', 'o', 'T', 'a', 'g', 0xc2}, data)
+
+	data, err = Marshal(testStruct{Embedded: &Embedded{A: 1, B: 2}, Omit: "o", Tags: map[string]string{"k": "v"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x85,
+		0xa1, 'b', 0xa0,
+		0xa4, 'o', 'm', 'i', 't', 0xa1, 'o',
+		0xa5, 'N', 'o', 'T', 'a', 'g', 0xc2,
+		0xa4, 't', 'a', 'g', 's', 0x81, 0xa1, 'k', 0xa1, 'v',
+		0xa1, 'a', 0x01,
+	}, data)
+}
+
+func TestMarshal_SortedMapKeys(t *testing.T) {
+	data, err := Marshal(map[string]int{"b": 2, "c": 3, "a": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02, 0xa1, 'c', 0x03}, data)
+	data, err = Marshal(map[int64]bool{10: true, -1: false, 2: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x83, 0xff, 0xc2, 0x02, 0xc3, 0x0a, 0xc3}, data)
+	data, err = Marshal(map[uint8]int{3: 3, 1: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x82, 0x01, 0x01, 0x03, 0x03}, data)
+	data, err = Marshal(map[float64]int{2.5: 1, 1.5: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0x82), data[0])
+	assert.Equal(t, math.Float64bits(1.5), binary.BigEndian.Uint64(data[2:10]))
+	data, err = Marshal(map[bool]int{true: 1, false: 0})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x82, 0xc2, 0x00, 0xc3, 0x01}, data)
+}
+
+func TestMarshal_Interface(t *testing.T) {
+	var generic interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"a":[1,"x",null]}`), &generic))
+	data, err := Marshal(generic)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x81, 0xa1, 'a', 0x93, 0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0, 0xa1, 'x', 0xc0}, data)
+}
+
+func TestMarshal_Err(t *testing.T) {
+	_, err := Marshal(make(chan int))
+	assert.Error(t, err)
+	_, err = Marshal([]interface{}{func() {}})
+	assert.Error(t, err)
+	_, err = Marshal(map[string]interface{}{"a": make(chan int)})
+	assert.Error(t, err)
+	_, err = Marshal(struct {
+		C chan int
+	}{})
+	assert.Error(t, err)
+	_, err = Marshal(errJSONValue{})
+	assert.Error(t, err)
+	_, err = Marshal(errTextValue{})
+	assert.Error(t, err)
+}
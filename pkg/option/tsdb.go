@@ -19,13 +19,46 @@ package option
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
+	"github.com/dustin/go-humanize"
+
+	"github.com/lindb/lindb/pkg/bufioutil"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/timeutil"
 )
 
+// Defines the modes of data point buffer for memory database.
+const (
+	DataPointBufferMMap = "mmap"
+	DataPointBufferHeap = "heap"
+)
+
+// Defines the fsync policies of data/index files and write ahead logs.
+const (
+	SyncPolicyBatch = "batch"
+	SyncPolicyBytes = "bytes"
+	SyncPolicyNever = "never"
+
+	defaultSyncBytes = 4 * 1024 * 1024 // 4MB
+)
+
+// Defines the routings which map series to shard on broker.
+const (
+	ShardRoutingModulo   = "modulo"
+	ShardRoutingHashRing = "hashRing"
+)
+
 // DatabaseOption represents a database option include shard ids and shard's option
 type DatabaseOption struct {
 	Interval string `toml:"interval" json:"interval,omitempty"` // write interval(the number of second)
+	// precision of written timestamp, only ms is supported now, sub-second write interval(like 100ms) is supported
+	// for high-frequency metrics.
+	// NOTICE: us/ns precision is rejected, because data is stored by slot of write interval and the minimum
+	// sub-second interval is 100ms(slots of one hour cannot exceed 65536 and interval must evenly divide one second),
+	// points faster than it would be merged into the same slot.
+	Precision string `toml:"precision" json:"precision,omitempty"`
 	// rollup intervals(like seconds->minute->hour->day)
 	Rollup []string `toml:"rollup" json:"rollup,omitempty"`
 
@@ -35,10 +68,133 @@ type DatabaseOption struct {
 	Behind string `toml:"behind" json:"behind,omitempty"` // allowed timestamp write behind
 	Ahead  string `toml:"ahead" json:"ahead,omitempty"`   // allowed timestamp write ahead
 
+	// time window of family(memory database/kv family), default is one hour for interval less than 5 minutes,
+	// which must evenly divide one hour(like 10m/15m/30m), for coarse interval(default one day/one month family),
+	// must be multi-hour and evenly divide one day(like 6h/12h/1d),
+	// NOTICE: cannot be changed after data written.
+	FamilyWindow string `toml:"familyWindow" json:"familyWindow,omitempty"`
+
+	// retention of data(like 30d/1y), queries are clipped to retention window, default keeps forever.
+	Retention string `toml:"retention" json:"retention,omitempty"`
+
+	// data point buffer of memory database, mmap(default, based on temp files) or heap(pooled in-heap byte slices,
+	// for small deployments or tmpfs-less containers).
+	DataPointBuffer string `toml:"dataPointBuffer" json:"dataPointBuffer,omitempty"`
+
+	// codec of tsd block written by compaction for cold data, xor(default, gorilla only) or zstd(zstd over xor),
+	// only works for data family created after changed.
+	BlockCodec string `toml:"blockCodec" json:"blockCodec,omitempty"`
+
+	// weight of write rate shaping on storage node, each database shares the node's write rate by weight,
+	// default is 1.
+	WriteWeight int `toml:"writeWeight" json:"writeWeight,omitempty"`
+
+	// fsync policy of files written by flush/compaction and write ahead logs, trades durability against throughput,
+	// batch(default, syncs after each file/flush), bytes(syncs every syncBytes written) or never(relies on os).
+	SyncPolicy string `toml:"syncPolicy" json:"syncPolicy,omitempty"`
+	// threshold of unsynced bytes(like 4MB) for bytes sync policy, default is 4MB.
+	SyncBytes string `toml:"syncBytes" json:"syncBytes,omitempty"`
+
+	// routing which maps series to shard on broker, modulo(default, hash % num. of shard) or hashRing(consistent hash
+	// ring, only series owned by new shards are remapped when num. of shard increases).
+	// NOTICE: cannot be changed after database created, because series would be written into other shards.
+	ShardRouting string `toml:"shardRouting" json:"shardRouting,omitempty"`
+
+	// tag keys whose values are numeric(like shard_id), tag values of them are indexed by number,
+	// so that range filters(<,<=,>,>=) can be used in query condition.
+	NumericTagKeys []string `toml:"numericTagKeys" json:"numericTagKeys,omitempty"`
+
+	// retention/rollup overrides of fields matched by name pattern(like keeps histogram buckets 7d but sums 1y),
+	// expired or excluded field data is removed by compaction of data family.
+	FieldRetentions []FieldRetention `toml:"fieldRetentions" json:"fieldRetentions,omitempty"`
+
 	Index FlusherOption `toml:"index" json:"index,omitempty"` // index flusher option
 	Data  FlusherOption `toml:"data" json:"data,omitempty"`   // data flusher data
 }
 
+// FieldRetention represents the retention/rollup override of fields which name matches the pattern.
+type FieldRetention struct {
+	// field name pattern, supports '*' suffix for prefix matching(like __bucket_*)
+	Field string `toml:"field" json:"field"`
+	// retention of field data(like 7d), cannot be longer than database retention, default keeps same as database.
+	Retention string `toml:"retention" json:"retention,omitempty"`
+	// rollup intervals which keep field data, must be subset of database rollup, default keeps all.
+	Rollup []string `toml:"rollup" json:"rollup,omitempty"`
+	// excludes field data from all rollup intervals, only keeps in write interval.
+	NoRollup bool `toml:"noRollup" json:"noRollup,omitempty"`
+}
+
+// Match returns if field name matches the pattern of field retention.
+func (f FieldRetention) Match(fieldName string) bool {
+	if strings.HasSuffix(f.Field, "*") {
+		return strings.HasPrefix(fieldName, f.Field[:len(f.Field)-1])
+	}
+	return fieldName == f.Field
+}
+
+// GetRetention returns the retention of field data, returns 0 if not set.
+func (f FieldRetention) GetRetention() timeutil.Interval {
+	var retention timeutil.Interval
+	if f.Retention == "" {
+		return retention
+	}
+	_ = retention.ValueOf(f.Retention)
+	return retention
+}
+
+// KeepRollup returns if field data is kept in the rollup interval.
+func (f FieldRetention) KeepRollup(interval timeutil.Interval) bool {
+	if f.NoRollup {
+		return false
+	}
+	if len(f.Rollup) == 0 {
+		return true
+	}
+	for _, intervalStr := range f.Rollup {
+		var rollupInterval timeutil.Interval
+		_ = rollupInterval.ValueOf(intervalStr)
+		if rollupInterval == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks field retention if valid based on database option.
+func (f FieldRetention) validate(e DatabaseOption) error {
+	if f.Field == "" || strings.Contains(strings.TrimSuffix(f.Field, "*"), "*") {
+		return fmt.Errorf("invalid field pattern of field retention: %s", f.Field)
+	}
+	if f.Retention == "" && len(f.Rollup) == 0 && !f.NoRollup {
+		return fmt.Errorf("field retention of %s must override retention or rollup", f.Field)
+	}
+	if err := validateInterval(f.Retention, false); err != nil {
+		return err
+	}
+	if dbRetention := e.GetRetention(); dbRetention > 0 && f.GetRetention() > dbRetention {
+		return fmt.Errorf("retention of field %s cannot be longer than database retention", f.Field)
+	}
+	for _, intervalStr := range f.Rollup {
+		var interval timeutil.Interval
+		if err := interval.ValueOf(intervalStr); err != nil {
+			return err
+		}
+		found := false
+		for _, dbRollup := range e.Rollup {
+			var rollupInterval timeutil.Interval
+			_ = rollupInterval.ValueOf(dbRollup)
+			if rollupInterval == interval {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("rollup interval %s of field %s not in database rollup", intervalStr, f.Field)
+		}
+	}
+	return nil
+}
+
 // FlusherOption represents a flusher configuration for index and memory db
 type FlusherOption struct {
 	TimeThreshold int64 `toml:"timeThreshold" json:"timeThreshold"` // time level flush threshold
@@ -50,6 +206,13 @@ func (e DatabaseOption) Validate() error {
 	if err := validateInterval(e.Interval, true); err != nil {
 		return err
 	}
+	precision, err := timeutil.ParsePrecision(e.Precision)
+	if err != nil {
+		return err
+	}
+	if precision != timeutil.Millisecond {
+		return fmt.Errorf("%s precision is not supported, the minimum write interval is 100ms", precision)
+	}
 	for _, interval := range e.Rollup {
 		if err := validateInterval(interval, true); err != nil {
 			return err
@@ -61,8 +224,57 @@ func (e DatabaseOption) Validate() error {
 	if err := validateInterval(e.Behind, false); err != nil {
 		return err
 	}
+	if err := validateInterval(e.Retention, false); err != nil {
+		return err
+	}
+	switch e.DataPointBuffer {
+	case "", DataPointBufferMMap, DataPointBufferHeap:
+	default:
+		return fmt.Errorf("unknown data point buffer: %s", e.DataPointBuffer)
+	}
+	if _, err := encoding.ParseTSDCodec(e.BlockCodec); err != nil {
+		return err
+	}
+	if e.WriteWeight < 0 {
+		return fmt.Errorf("write weight cannot be negative")
+	}
+	switch e.SyncPolicy {
+	case "", SyncPolicyBatch, SyncPolicyBytes, SyncPolicyNever:
+	default:
+		return fmt.Errorf("unknown sync policy: %s", e.SyncPolicy)
+	}
+	if e.SyncBytes != "" {
+		syncBytes, err := humanize.ParseBytes(e.SyncBytes)
+		if err != nil {
+			return err
+		}
+		if syncBytes == 0 {
+			return fmt.Errorf("sync bytes must be positive")
+		}
+	}
+	switch e.ShardRouting {
+	case "", ShardRoutingModulo, ShardRoutingHashRing:
+	default:
+		return fmt.Errorf("unknown shard routing: %s", e.ShardRouting)
+	}
+	for _, tagKey := range e.NumericTagKeys {
+		if tagKey == "" {
+			return fmt.Errorf("numeric tag key cannot be empty")
+		}
+	}
+	for _, fieldRetention := range e.FieldRetentions {
+		if err := fieldRetention.validate(e); err != nil {
+			return err
+		}
+	}
 	var interval timeutil.Interval
 	_ = interval.ValueOf(e.Interval)
+	if err := validateSubSecondInterval(interval); err != nil {
+		return err
+	}
+	if err := validateFamilyWindow(interval, e.FamilyWindow); err != nil {
+		return err
+	}
 	for _, intervalStr := range e.Rollup {
 		var rollupInterval timeutil.Interval
 		_ = rollupInterval.ValueOf(intervalStr)
@@ -73,6 +285,129 @@ func (e DatabaseOption) Validate() error {
 	return nil
 }
 
+// GetFamilyWindow returns the family time window, returns 0 if not set.
+func (e DatabaseOption) GetFamilyWindow() timeutil.Interval {
+	var familyWindow timeutil.Interval
+	if e.FamilyWindow == "" {
+		return familyWindow
+	}
+	_ = familyWindow.ValueOf(e.FamilyWindow)
+	return familyWindow
+}
+
+// GetPrecision returns the precision of written timestamp, returns millisecond if not set.
+func (e DatabaseOption) GetPrecision() timeutil.Precision {
+	precision, _ := timeutil.ParsePrecision(e.Precision)
+	return precision
+}
+
+// GetRetention returns the retention of data, returns 0 if not set.
+func (e DatabaseOption) GetRetention() timeutil.Interval {
+	var retention timeutil.Interval
+	if e.Retention == "" {
+		return retention
+	}
+	_ = retention.ValueOf(e.Retention)
+	return retention
+}
+
+// IsOnHeapBuffer returns if data point buffer of memory database is based on in-heap byte slices.
+func (e DatabaseOption) IsOnHeapBuffer() bool {
+	return e.DataPointBuffer == DataPointBufferHeap
+}
+
+// IsHashRingRouting returns if series are mapped to shard by consistent hash ring.
+func (e DatabaseOption) IsHashRingRouting() bool {
+	return e.ShardRouting == ShardRoutingHashRing
+}
+
+// GetBlockCodec returns the codec of tsd block written by compaction, returns xor if not set.
+func (e DatabaseOption) GetBlockCodec() encoding.TSDCodec {
+	codec, _ := encoding.ParseTSDCodec(e.BlockCodec)
+	return codec
+}
+
+// GetWriteWeight returns the weight of write rate shaping, returns 1 if not set.
+func (e DatabaseOption) GetWriteWeight() int {
+	if e.WriteWeight <= 0 {
+		return 1
+	}
+	return e.WriteWeight
+}
+
+// GetSyncPolicy returns the fsync policy of files and write ahead logs, returns batch policy if not set.
+func (e DatabaseOption) GetSyncPolicy() bufioutil.SyncPolicy {
+	switch e.SyncPolicy {
+	case SyncPolicyBytes:
+		syncBytes, err := humanize.ParseBytes(e.SyncBytes)
+		if err != nil || syncBytes == 0 {
+			syncBytes = defaultSyncBytes
+		}
+		return bufioutil.SyncPolicy{Mode: bufioutil.SyncBytes, Bytes: int64(syncBytes)}
+	case SyncPolicyNever:
+		return bufioutil.SyncPolicy{Mode: bufioutil.SyncNever}
+	default:
+		return bufioutil.DefaultSyncPolicy
+	}
+}
+
+// IsNumericTagKey returns if the values of tag key are declared as numeric.
+func (e DatabaseOption) IsNumericTagKey(tagKey string) bool {
+	for _, key := range e.NumericTagKeys {
+		if key == tagKey {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSubSecondInterval checks sub-second write interval if valid,
+// which must evenly divide one second and slots of one hour cannot exceed the max slot index(uint16),
+// so the minimum sub-second interval is 100ms.
+func validateSubSecondInterval(interval timeutil.Interval) error {
+	if interval.Int64() >= timeutil.OneSecond {
+		return nil
+	}
+	if timeutil.OneSecond%interval.Int64() != 0 {
+		return fmt.Errorf("sub-second interval must evenly divide one second")
+	}
+	if timeutil.OneHour/interval.Int64() > math.MaxUint16+1 {
+		return fmt.Errorf("sub-second interval is too small, slots of one hour exceed %d, the minimum is 100ms",
+			math.MaxUint16+1)
+	}
+	return nil
+}
+
+// validateFamilyWindow checks family window if valid for write interval
+func validateFamilyWindow(interval timeutil.Interval, familyWindowStr string) error {
+	if familyWindowStr == "" {
+		return nil
+	}
+	if err := validateInterval(familyWindowStr, true); err != nil {
+		return err
+	}
+	var familyWindow timeutil.Interval
+	_ = familyWindow.ValueOf(familyWindowStr)
+	if interval.Type() == timeutil.Day {
+		// day interval type, family window must be in one hour(default one hour family)
+		if timeutil.OneHour%familyWindow.Int64() != 0 {
+			return fmt.Errorf("family window must evenly divide one hour")
+		}
+	} else {
+		// month/year interval type, family window must be multi-hour in one day(coarse archival data)
+		if familyWindow.Int64()%timeutil.OneHour != 0 {
+			return fmt.Errorf("family window must be multiple of one hour for write interval not less than 5 minutes")
+		}
+		if timeutil.OneDay%familyWindow.Int64() != 0 {
+			return fmt.Errorf("family window must evenly divide one day")
+		}
+	}
+	if familyWindow.Int64()%interval.Int64() != 0 {
+		return fmt.Errorf("family window must be multiple of write interval")
+	}
+	return nil
+}
+
 // validateInterval checks interval string if valid
 func validateInterval(intervalStr string, require bool) error {
 	if !require && intervalStr == "" {
@@ -21,6 +21,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/bufioutil"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/timeutil"
 )
 
 func Test_DatabaseOption_Validate(t *testing.T) {
@@ -45,3 +49,195 @@ func Test_DatabaseOption_Validate(t *testing.T) {
 	databaseOption = DatabaseOption{Interval: "10s", Rollup: []string{"20s", "1m", "1h"}, Behind: "10h", Ahead: "1h"}
 	assert.Nil(t, databaseOption.Validate())
 }
+
+func Test_DatabaseOption_FamilyWindow(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, int64(0), databaseOption.GetFamilyWindow().Int64())
+	databaseOption = DatabaseOption{Interval: "10s", FamilyWindow: "30m"}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, 30*timeutil.OneMinute, databaseOption.GetFamilyWindow().Int64())
+	databaseOption = DatabaseOption{Interval: "10s", FamilyWindow: "1h"}
+	assert.Nil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "10s", FamilyWindow: "aa"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "10m", FamilyWindow: "30m"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "10s", FamilyWindow: "7m"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "10s", FamilyWindow: "2h"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "40s", FamilyWindow: "1m"}
+	assert.NotNil(t, databaseOption.Validate())
+	// month/year interval type, multi-hour family window
+	databaseOption = DatabaseOption{Interval: "10m", FamilyWindow: "6h"}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, 6*timeutil.OneHour, databaseOption.GetFamilyWindow().Int64())
+	databaseOption = DatabaseOption{Interval: "10m", FamilyWindow: "1d"}
+	assert.Nil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "1h", FamilyWindow: "12h"}
+	assert.Nil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "2h", FamilyWindow: "8h"}
+	assert.Nil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "10m", FamilyWindow: "5h"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "1h", FamilyWindow: "2d"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "3h", FamilyWindow: "2h"}
+	assert.NotNil(t, databaseOption.Validate())
+}
+
+func Test_DatabaseOption_WriteWeight(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.Equal(t, 1, databaseOption.GetWriteWeight())
+	databaseOption = DatabaseOption{Interval: "10s", WriteWeight: 3}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, 3, databaseOption.GetWriteWeight())
+	databaseOption = DatabaseOption{Interval: "10s", WriteWeight: -1}
+	assert.NotNil(t, databaseOption.Validate())
+}
+
+func Test_DatabaseOption_NumericTagKeys(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.False(t, databaseOption.IsNumericTagKey("shard_id"))
+	databaseOption = DatabaseOption{Interval: "10s", NumericTagKeys: []string{"shard_id"}}
+	assert.Nil(t, databaseOption.Validate())
+	assert.True(t, databaseOption.IsNumericTagKey("shard_id"))
+	assert.False(t, databaseOption.IsNumericTagKey("host"))
+	databaseOption = DatabaseOption{Interval: "10s", NumericTagKeys: []string{""}}
+	assert.NotNil(t, databaseOption.Validate())
+}
+
+func Test_DatabaseOption_Retention(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.Equal(t, timeutil.Interval(0), databaseOption.GetRetention())
+	databaseOption = DatabaseOption{Interval: "10s", Retention: "30d"}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, timeutil.Interval(30*timeutil.OneDay), databaseOption.GetRetention())
+	databaseOption = DatabaseOption{Interval: "10s", Retention: "30x"}
+	assert.NotNil(t, databaseOption.Validate())
+}
+
+func Test_DatabaseOption_DataPointBuffer(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.False(t, databaseOption.IsOnHeapBuffer())
+	databaseOption = DatabaseOption{Interval: "10s", DataPointBuffer: DataPointBufferHeap}
+	assert.Nil(t, databaseOption.Validate())
+	assert.True(t, databaseOption.IsOnHeapBuffer())
+	databaseOption = DatabaseOption{Interval: "10s", DataPointBuffer: DataPointBufferMMap}
+	assert.Nil(t, databaseOption.Validate())
+	assert.False(t, databaseOption.IsOnHeapBuffer())
+	databaseOption = DatabaseOption{Interval: "10s", DataPointBuffer: "disk"}
+	assert.NotNil(t, databaseOption.Validate())
+}
+
+func Test_DatabaseOption_ShardRouting(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.False(t, databaseOption.IsHashRingRouting())
+	databaseOption = DatabaseOption{Interval: "10s", ShardRouting: ShardRoutingModulo}
+	assert.Nil(t, databaseOption.Validate())
+	assert.False(t, databaseOption.IsHashRingRouting())
+	databaseOption = DatabaseOption{Interval: "10s", ShardRouting: ShardRoutingHashRing}
+	assert.Nil(t, databaseOption.Validate())
+	assert.True(t, databaseOption.IsHashRingRouting())
+	databaseOption = DatabaseOption{Interval: "10s", ShardRouting: "range"}
+	assert.NotNil(t, databaseOption.Validate())
+}
+
+func Test_DatabaseOption_SyncPolicy(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.Equal(t, bufioutil.DefaultSyncPolicy, databaseOption.GetSyncPolicy())
+	databaseOption = DatabaseOption{Interval: "10s", SyncPolicy: SyncPolicyBatch}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, bufioutil.DefaultSyncPolicy, databaseOption.GetSyncPolicy())
+	databaseOption = DatabaseOption{Interval: "10s", SyncPolicy: SyncPolicyNever}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, bufioutil.SyncPolicy{Mode: bufioutil.SyncNever}, databaseOption.GetSyncPolicy())
+	databaseOption = DatabaseOption{Interval: "10s", SyncPolicy: SyncPolicyBytes}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, bufioutil.SyncPolicy{Mode: bufioutil.SyncBytes, Bytes: defaultSyncBytes}, databaseOption.GetSyncPolicy())
+	databaseOption = DatabaseOption{Interval: "10s", SyncPolicy: SyncPolicyBytes, SyncBytes: "1MiB"}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, bufioutil.SyncPolicy{Mode: bufioutil.SyncBytes, Bytes: 1024 * 1024}, databaseOption.GetSyncPolicy())
+
+	databaseOption = DatabaseOption{Interval: "10s", SyncPolicy: "always"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "10s", SyncPolicy: SyncPolicyBytes, SyncBytes: "1xB"}
+	assert.NotNil(t, databaseOption.Validate())
+	databaseOption = DatabaseOption{Interval: "10s", SyncPolicy: SyncPolicyBytes, SyncBytes: "0"}
+	assert.NotNil(t, databaseOption.Validate())
+}
+
+func Test_DatabaseOption_BlockCodec(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.Equal(t, encoding.TSDCodecXOR, databaseOption.GetBlockCodec())
+	databaseOption = DatabaseOption{Interval: "10s", BlockCodec: "zstd"}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, encoding.TSDCodecZstd, databaseOption.GetBlockCodec())
+	databaseOption = DatabaseOption{Interval: "10s", BlockCodec: "lz4"}
+	assert.NotNil(t, databaseOption.Validate())
+	assert.Equal(t, encoding.TSDCodecXOR, databaseOption.GetBlockCodec())
+}
+
+func Test_DatabaseOption_Precision(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s"}
+	assert.Equal(t, timeutil.Millisecond, databaseOption.GetPrecision())
+	databaseOption = DatabaseOption{Interval: "100ms", Precision: "ms"}
+	assert.Nil(t, databaseOption.Validate())
+	assert.Equal(t, timeutil.Millisecond, databaseOption.GetPrecision())
+	// points faster than the minimum interval would be merged into the same slot
+	for _, precision := range []string{"us", "ns"} {
+		databaseOption = DatabaseOption{Interval: "100ms", Precision: precision}
+		assert.NotNil(t, databaseOption.Validate(), precision)
+	}
+	databaseOption = DatabaseOption{Interval: "10s", Precision: "s"}
+	assert.NotNil(t, databaseOption.Validate())
+	// sub-second interval cannot evenly divide one second
+	databaseOption = DatabaseOption{Interval: "300ms"}
+	assert.NotNil(t, databaseOption.Validate())
+	// too many slots of one hour
+	databaseOption = DatabaseOption{Interval: "50ms"}
+	assert.NotNil(t, databaseOption.Validate())
+	// the minimum sub-second interval
+	for _, interval := range []string{"1ms", "10ms", "20ms", "25ms", "40ms"} {
+		databaseOption = DatabaseOption{Interval: interval}
+		assert.NotNil(t, databaseOption.Validate(), interval)
+	}
+}
+
+func Test_DatabaseOption_FieldRetentions(t *testing.T) {
+	databaseOption := DatabaseOption{Interval: "10s", Rollup: []string{"5m", "1h"}, Retention: "30d",
+		FieldRetentions: []FieldRetention{
+			{Field: "__bucket_*", Retention: "7d", NoRollup: true},
+			{Field: "HistogramSum", Rollup: []string{"1h"}},
+		}}
+	assert.Nil(t, databaseOption.Validate())
+
+	cases := []FieldRetention{
+		{Retention: "7d"},
+		{Field: "a*b", Retention: "7d"},
+		{Field: "a"},
+		{Field: "a", Retention: "7x"},
+		{Field: "a", Retention: "60d"},
+		{Field: "a", Rollup: []string{"1d"}},
+		{Field: "a", Rollup: []string{"1x"}},
+	}
+	for _, c := range cases {
+		databaseOption.FieldRetentions = []FieldRetention{c}
+		assert.NotNil(t, databaseOption.Validate())
+	}
+
+	bucket := FieldRetention{Field: "__bucket_*", Retention: "7d", NoRollup: true}
+	assert.True(t, bucket.Match("__bucket_1"))
+	assert.False(t, bucket.Match("HistogramSum"))
+	assert.Equal(t, timeutil.Interval(7*timeutil.OneDay), bucket.GetRetention())
+	assert.False(t, bucket.KeepRollup(timeutil.Interval(timeutil.OneHour)))
+
+	sum := FieldRetention{Field: "HistogramSum", Rollup: []string{"1h"}}
+	assert.True(t, sum.Match("HistogramSum"))
+	assert.False(t, sum.Match("HistogramSum1"))
+	assert.Equal(t, timeutil.Interval(0), sum.GetRetention())
+	assert.True(t, sum.KeepRollup(timeutil.Interval(timeutil.OneHour)))
+	assert.False(t, sum.KeepRollup(timeutil.Interval(5*timeutil.OneMinute)))
+	assert.True(t, FieldRetention{Field: "a", Retention: "1d"}.KeepRollup(timeutil.Interval(timeutil.OneHour)))
+}
@@ -27,17 +27,20 @@ const (
 	indexItemLength          = 8 + 4 + 4 // data page id(8bytes) + message offset in data page(4bytes) + message length(4bytes)
 	indexItemsPerPage        = 1024 * 256
 	indexPageSize            = indexItemsPerPage * indexItemLength
-	dataPageSize             = 128 * 1024 * 1024 // 128MB
-	metaPageSize             = 8 + 8 + 8 + 8     // headSeq(int64), tailSeq(int64), data expire page, index expire page
+	defaultDataPageSize      = 128 * 1024 * 1024      // 128MB
+	legacyMetaPageSize       = 8 + 8 + 8 + 8          // headSeq(int64), tailSeq(int64), data expire page, index expire page
+	metaPageSize             = legacyMetaPageSize + 8 // legacy meta + replaySeq(int64)
 	queueHeadSeqOffset       = 0
 	queueTailSeqOffset       = queueHeadSeqOffset + 8
 	queueExpireDataOffset    = queueTailSeqOffset + 8
 	queueExpireIndexOffset   = queueExpireDataOffset + 8
+	queueReplaySeqOffset     = queueExpireIndexOffset + 8
 	queueDataPageIndexOffset = 0
 	messageOffsetOffset      = 8
 	messageLengthOffset      = 8 + 4
 
-	defaultDataSizeLimit = 4 * dataPageSize
+	// minDataPagesOfLimit is the min num. of data pages the data size limit can hold
+	minDataPagesOfLimit = 4
 
 	fanOutDirName = "fan_out"
 	// headSeq(int64), tailSeq(int64)
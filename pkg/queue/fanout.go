@@ -57,6 +57,10 @@ type FanOutQueue interface {
 	HeadSeq() int64
 	// TailSeq returns the tailSeq which is the smallest seq among all the fanOut tailSeq.
 	TailSeq() int64
+	// ReplaySeq returns the replaySeq, message with seq greater than replaySeq is retained for replay.
+	ReplaySeq() int64
+	// DiskSize returns the total size of underlying queue on disk.
+	DiskSize() int64
 	//SetAppendSeq sets append seq(head/tail seq)
 	SetAppendSeq(seq int64)
 	// Close persists Seq meta, FanOut seq meta, release resources.
@@ -82,7 +86,7 @@ type fanOutQueue struct {
 }
 
 // NewFanOutQueue returns a FanOutQueue persisted in dirPath.
-func NewFanOutQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration) (FanOutQueue, error) {
+func NewFanOutQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration, opts ...Option) (FanOutQueue, error) {
 	var err error
 
 	fq := &fanOutQueue{
@@ -99,7 +103,7 @@ func NewFanOutQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time
 	}()
 
 	// create underlying queue
-	fq.queue, err = newQueueFunc(dirPath, dataSizeLimit, removeTaskInterval)
+	fq.queue, err = newQueueFunc(dirPath, dataSizeLimit, removeTaskInterval, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +165,16 @@ func (fq *fanOutQueue) TailSeq() int64 {
 	return fq.queue.TailSeq()
 }
 
+// ReplaySeq returns the replaySeq, message with seq greater than replaySeq is retained for replay.
+func (fq *fanOutQueue) ReplaySeq() int64 {
+	return fq.queue.ReplaySeq()
+}
+
+// DiskSize returns the total size of underlying queue on disk.
+func (fq *fanOutQueue) DiskSize() int64 {
+	return fq.queue.DiskSize()
+}
+
 // SetAppendSeq sets append seq(head/tail) underlying queue
 func (fq *fanOutQueue) SetAppendSeq(seq int64) {
 	fq.lock4map.RLock()
@@ -254,7 +268,8 @@ type FanOut interface {
 	// If no new data is available, SeqNoNewMessageAvailable is returned.
 	Consume() int64
 	// SetHeadSeq sets the HeadSeq to seq, this is useful when re-consume message.
-	// error returns when seq is invalidate(less than ackSeq or greater than the read barrier).
+	// seq less than ackSeq is allowed for replay if the message is still retained by queue.
+	// error returns when seq is invalidate(less than replay seq or greater than the read barrier).
 	SetHeadSeq(seq int64) error
 	// Get retrieves the data for seq.
 	// The seq must bu a valid sequence num returned by consume.
@@ -363,6 +378,10 @@ func (f *fanOut) SetHeadSeq(seq int64) error {
 
 	hs := f.q.HeadSeq()
 	ts := f.TailSeq()
+	// replays from retained message
+	if rs := f.q.ReplaySeq(); rs < ts {
+		ts = rs
+	}
 
 	if seq > hs || seq < ts {
 		return fmt.Errorf("set headSeq failed, %d not in the range [%d,%d]", seq, ts, hs)
@@ -64,7 +64,7 @@ func TestFanOutQueue_New(t *testing.T) {
 	}()
 
 	// case 1: create underlying queue err
-	newQueueFunc = func(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration) (Queue, error) {
+	newQueueFunc = func(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration, _ ...Option) (Queue, error) {
 		return nil, fmt.Errorf("err")
 	}
 	fq, err := NewFanOutQueue(dir, 1024, time.Minute)
@@ -76,7 +76,7 @@ func TestFanOutQueue_New(t *testing.T) {
 	queue := NewMockQueue(ctrl)
 	queue.EXPECT().Close().AnyTimes()
 
-	newQueueFunc = func(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration) (Queue, error) {
+	newQueueFunc = func(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration, _ ...Option) (Queue, error) {
 		return queue, nil
 	}
 	mkDirFunc = func(path string) error {
@@ -375,6 +375,11 @@ func TestFanOutQueue_SetHeadSeq(t *testing.T) {
 
 	f1.Ack(1)
 
+	// replay acked message which is still retained by queue
+	err = f1.SetHeadSeq(0)
+	assert.NoError(t, err)
+	// queue acked without retention
+	fq.Sync()
 	err = f1.SetHeadSeq(0)
 	assert.Error(t, err)
 	fq.Close()
@@ -21,7 +21,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -38,6 +40,7 @@ import (
 var (
 	mkDirFunc          = fileutil.MkDirIfNotExist
 	newPageFactoryFunc = page.NewFactory
+	statFunc           = os.Stat
 )
 
 // ErrExceedingMessageSizeLimit returns when appending message exceeds the max size limit.
@@ -65,6 +68,11 @@ type Queue interface {
 	// TailSeq returns the tail seq which stands for the oldest read barrier.
 	// Message with req less than tailSeq would be deleted at some point.
 	TailSeq() int64
+	// ReplaySeq returns the replay seq which stands for the oldest retained barrier,
+	// message with seq greater than replaySeq can be read for replay, replaySeq <= tailSeq.
+	ReplaySeq() int64
+	// DiskSize returns the total size of data/index pages on disk.
+	DiskSize() int64
 	// SetAppendSeq sets head/tail seq.
 	SetAppendSeq(seq int64)
 	// Ack advances the tailSeq to seq.
@@ -73,6 +81,27 @@ type Queue interface {
 	Close()
 }
 
+// Option represents the option of queue.
+type Option func(q *queue)
+
+// WithSegmentSize sets the size of data page(segment), a new data page is created when current page is full.
+// Existing data pages keep their size, if the size is smaller than the pages on disk.
+func WithSegmentSize(size int) Option {
+	return func(q *queue) {
+		if size > 0 {
+			q.dataPageSize = size
+		}
+	}
+}
+
+// WithRetention sets how long acked data pages are retained for replay,
+// retained pages are removed first when the data size exceeds the limit.
+func WithRetention(retention time.Duration) Option {
+	return func(q *queue) {
+		q.retention = retention
+	}
+}
+
 // queue implements queue.
 type queue struct {
 	ctx    context.Context
@@ -81,6 +110,10 @@ type queue struct {
 	dirPath string
 	// the max size limit in bytes for data file
 	dataSizeLimit int64
+	// the size of each data page(segment)
+	dataPageSize int
+	// how long acked data pages are retained for replay
+	retention time.Duration
 
 	indexPageFct page.Factory // index page factory
 	dataPageFct  page.Factory // data page factory
@@ -90,6 +123,8 @@ type queue struct {
 	metaPage page.MappedPage // meta buffer
 	headSeq  atomic.Int64    // current written sequence
 	tailSeq  atomic.Int64    // current acked sequence
+	// message with seq > replaySeq is retained for replay
+	replaySeq atomic.Int64
 
 	indexPage      page.MappedPage // index buffer
 	indexPageIndex int64
@@ -105,11 +140,12 @@ type queue struct {
 	expireIndexPage  atomic.Int64
 	closed           atomic.Bool
 	rwMutex          sync.RWMutex
+	lock4remove      sync.Mutex
 }
 
 // NewQueue returns Queue based on dirPath, dataSizeLimit is used to limit the total data/index size,
 // removeTaskInterval specifics the interval to remove expired segments.
-func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration) (Queue, error) {
+func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration, opts ...Option) (Queue, error) {
 	var err error
 	if err = mkDirFunc(dirPath); err != nil {
 		return nil, err
@@ -120,11 +156,19 @@ func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Durat
 		cancel:        cancel,
 		dirPath:       dirPath,
 		dataSizeLimit: dataSizeLimit,
+		dataPageSize:  defaultDataPageSize,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	// existing data pages keep their size
+	if size := existingPageSize(filepath.Join(dirPath, dataPath)); size > q.dataPageSize {
+		q.dataPageSize = size
 	}
 
-	// if data size limit < default limit, need reset
-	if q.dataSizeLimit < defaultDataSizeLimit {
-		q.dataSizeLimit = defaultDataSizeLimit
+	// if data size limit < min limit, need reset
+	if minLimit := int64(minDataPagesOfLimit * q.dataPageSize); q.dataSizeLimit < minLimit {
+		q.dataSizeLimit = minLimit
 	}
 
 	defer func() {
@@ -135,7 +179,7 @@ func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Durat
 	}()
 
 	// init data page factory
-	fct, err := newPageFactoryFunc(filepath.Join(dirPath, dataPath), dataPageSize)
+	fct, err := newPageFactoryFunc(filepath.Join(dirPath, dataPath), q.dataPageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -151,8 +195,11 @@ func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Durat
 	q.indexPageFct = fct
 
 	hasMeta := false
-	if fileutil.Exist(filepath.Join(dirPath, metaPath, fmt.Sprintf("%d.bat", metaPageIndex))) {
+	legacyMeta := false
+	if stat, err0 := statFunc(filepath.Join(dirPath, metaPath, fmt.Sprintf("%d.bat", metaPageIndex))); err0 == nil {
 		hasMeta = true
+		// meta page created before replay seq supported
+		legacyMeta = stat.Size() < metaPageSize
 	}
 
 	// init meta page factory
@@ -170,10 +217,11 @@ func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Durat
 
 	if hasMeta {
 		// initialize sequence
-		q.initSequence()
+		q.initSequence(legacyMeta)
 	} else {
 		q.headSeq.Store(-1)
 		q.tailSeq.Store(-1)
+		q.replaySeq.Store(-1)
 		q.expireDataPage.Store(-1)
 		q.expireIndexPage.Store(-1)
 		// persist metadata
@@ -181,6 +229,7 @@ func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Durat
 		q.metaPage.PutUint64(uint64(q.TailSeq()), queueTailSeqOffset)
 		q.metaPage.PutUint64(uint64(q.expireDataPage.Load()), queueExpireDataOffset)
 		q.metaPage.PutUint64(uint64(q.expireIndexPage.Load()), queueExpireIndexOffset)
+		q.metaPage.PutUint64(uint64(q.ReplaySeq()), queueReplaySeqOffset)
 		if err = q.metaPage.Sync(); err != nil {
 			return nil, err
 		}
@@ -200,7 +249,7 @@ func NewQueue(dirPath string, dataSizeLimit int64, removeTaskInterval time.Durat
 // Put puts data to the end of the queue, if puts failure return err
 func (q *queue) Put(data []byte) error {
 	dataLength := len(data)
-	if dataLength > dataPageSize {
+	if dataLength > q.dataPageSize {
 		// if message size > data page size, return err
 		return ErrExceedingMessageSizeLimit
 	}
@@ -266,6 +315,17 @@ func (q *queue) TailSeq() int64 {
 	return q.tailSeq.Load()
 }
 
+// ReplaySeq returns the replay seq which stands for the oldest retained barrier,
+// message with seq greater than replaySeq can be read for replay, replaySeq <= tailSeq.
+func (q *queue) ReplaySeq() int64 {
+	return q.replaySeq.Load()
+}
+
+// DiskSize returns the total size of data/index pages on disk.
+func (q *queue) DiskSize() int64 {
+	return q.dataPageFct.Size() + q.indexPageFct.Size()
+}
+
 // SetAppendSeq sets head/tail seq.
 func (q *queue) SetAppendSeq(seq int64) {
 	q.rwMutex.RLock()
@@ -295,7 +355,9 @@ func (q *queue) SetAppendSeq(seq int64) {
 	q.metaPage.PutUint64(uint64(head), queueHeadSeqOffset)
 	tail := head - 1
 	q.tailSeq.Store(tail)
+	q.replaySeq.Store(tail)
 	q.metaPage.PutUint64(uint64(tail), queueTailSeqOffset)
+	q.metaPage.PutUint64(uint64(tail), queueReplaySeqOffset)
 	q.metaPage.PutUint64(uint64(q.HeadSeq()), queueHeadSeqOffset)
 	q.metaPage.PutUint64(uint64(q.TailSeq()), queueTailSeqOffset)
 	if err := q.metaPage.Sync(); err != nil {
@@ -312,6 +374,11 @@ func (q *queue) Ack(seq int64) {
 	if seq > q.TailSeq() && seq <= q.HeadSeq() {
 		q.tailSeq.Store(seq)
 		q.metaPage.PutUint64(uint64(seq), queueTailSeqOffset)
+		if q.retention <= 0 {
+			// no retention, acked message cannot be replayed
+			q.replaySeq.Store(seq)
+			q.metaPage.PutUint64(uint64(seq), queueReplaySeqOffset)
+		}
 
 		if err := q.metaPage.Sync(); err != nil {
 			queueLogger.Error("sync queue meta page error, when ack seq",
@@ -370,7 +437,7 @@ func (q *queue) initRemoveTask() {
 		for {
 			select {
 			case <-q.removeTaskTicker.C:
-				q.removeExpirePage()
+				q.removeExpirePage(false)
 			case <-q.ctx.Done():
 				return
 			}
@@ -378,21 +445,28 @@ func (q *queue) initRemoveTask() {
 	}()
 }
 
-func (q *queue) removeExpirePage() {
+// removeExpirePage removes the data/index pages which all messages are acked and retention expired,
+// retention is ignored if force, used when data size exceeds the limit.
+func (q *queue) removeExpirePage(force bool) {
+	q.lock4remove.Lock()
+	defer q.lock4remove.Unlock()
+
 	ackSeq := q.TailSeq() // get current acked sequence
 	if ackSeq < 0 {
 		return
 	}
-	indexPageID := ackSeq / indexItemsPerPage
-	indexPage, ok := q.indexPageFct.GetPage(indexPageID)
+	dataPageID, ok := q.dataPageIDOf(ackSeq)
 	if !ok {
 		return
 	}
-	// calculate index offset of ack sequence
-	indexOffset := int((ackSeq % indexItemsPerPage) * indexItemLength)
-	dataPageID := int64(indexPage.ReadUint64(indexOffset + queueDataPageIndexOffset))
+	retained := q.retention > 0 && !force
+	now := time.Now()
 	lastDataPageID := q.expireDataPage.Load()
 	for i := lastDataPageID + 1; i < dataPageID; i++ {
+		if retained && !q.isPageExpired(i, now) {
+			// data pages are created in order, following pages are not expired
+			break
+		}
 		if err := q.dataPageFct.ReleasePage(i); err != nil {
 			queueLogger.Error("remove expire data page error",
 				logger.String("queue", q.dirPath), logger.Any("page", i), logger.Error(err))
@@ -403,8 +477,15 @@ func (q *queue) removeExpirePage() {
 		q.expireDataPage.Store(i)
 		q.metaPage.PutUint64(uint64(q.expireDataPage.Load()), queueExpireDataOffset)
 	}
+	indexPageID := ackSeq / indexItemsPerPage
 	lastIndexPageID := q.expireIndexPage.Load()
 	for i := lastIndexPageID + 1; i < indexPageID; i++ {
+		if q.retention > 0 {
+			// keeps index page if the data of last message is retained
+			if id, ok := q.dataPageIDOf((i+1)*indexItemsPerPage - 1); ok && id > q.expireDataPage.Load() {
+				break
+			}
+		}
 		if err := q.indexPageFct.ReleasePage(i); err != nil {
 			queueLogger.Error("remove expire index page error",
 				logger.String("queue", q.dirPath), logger.Any("page", i), logger.Error(err))
@@ -415,6 +496,7 @@ func (q *queue) removeExpirePage() {
 		q.expireIndexPage.Store(i)
 		q.metaPage.PutUint64(uint64(q.expireIndexPage.Load()), queueExpireIndexOffset)
 	}
+	q.advanceReplaySeq(ackSeq)
 
 	if err := q.metaPage.Sync(); err != nil {
 		queueLogger.Error("sync meta page error when do expire page",
@@ -422,10 +504,53 @@ func (q *queue) removeExpirePage() {
 	}
 }
 
+// advanceReplaySeq advances the replay seq to the seq before the oldest retained message.
+func (q *queue) advanceReplaySeq(ackSeq int64) {
+	replaySeq := ackSeq
+	if q.retention > 0 {
+		expireDataPage := q.expireDataPage.Load()
+		low := q.ReplaySeq()
+		if minSeq := (q.expireIndexPage.Load()+1)*indexItemsPerPage - 1; low < minSeq {
+			low = minSeq
+		}
+		// data page id increases with seq, finds the first message in (low, ackSeq] which data page is retained
+		idx := sort.Search(int(ackSeq-low), func(i int) bool {
+			id, ok := q.dataPageIDOf(low + 1 + int64(i))
+			return ok && id > expireDataPage
+		})
+		replaySeq = low + int64(idx)
+	}
+	if replaySeq <= q.ReplaySeq() {
+		return
+	}
+	q.replaySeq.Store(replaySeq)
+	q.metaPage.PutUint64(uint64(replaySeq), queueReplaySeqOffset)
+}
+
+// dataPageIDOf returns the data page id which stores the message of seq.
+func (q *queue) dataPageIDOf(seq int64) (int64, bool) {
+	indexPage, ok := q.indexPageFct.GetPage(seq / indexItemsPerPage)
+	if !ok {
+		return 0, false
+	}
+	// calculate index offset of sequence
+	indexOffset := int((seq % indexItemsPerPage) * indexItemLength)
+	return int64(indexPage.ReadUint64(indexOffset + queueDataPageIndexOffset)), true
+}
+
+// isPageExpired checks if the data page is not modified within retention.
+func (q *queue) isPageExpired(pageID int64, now time.Time) bool {
+	stat, err := statFunc(filepath.Join(q.dirPath, dataPath, fmt.Sprintf("%d.bat", pageID)))
+	if err != nil {
+		return true
+	}
+	return stat.ModTime().Add(q.retention).Before(now)
+}
+
 // alloc allocates the data page and offset for message writing
 func (q *queue) alloc(dataLen int) (dataPage page.MappedPage, offset int, err error) {
 	// prepare the data pointer
-	if q.messageOffset+dataLen > dataPageSize {
+	if q.messageOffset+dataLen > q.dataPageSize {
 		// check size limit before data page acquire
 		if err = q.checkDataSize(); err != nil {
 			return nil, 0, err
@@ -485,10 +610,16 @@ func (q *queue) alloc(dataLen int) (dataPage page.MappedPage, offset int, err er
 	return q.dataPage, messageOffset, nil
 }
 
-// initSequence initializes head/tail from the meta data
-func (q *queue) initSequence() {
+// initSequence initializes head/tail from the meta data, replay seq is tail seq for legacy meta.
+func (q *queue) initSequence(legacyMeta bool) {
 	q.headSeq.Store(int64(q.metaPage.ReadUint64(queueHeadSeqOffset)))
 	q.tailSeq.Store(int64(q.metaPage.ReadUint64(queueTailSeqOffset)))
+	if legacyMeta {
+		q.replaySeq.Store(q.TailSeq())
+		q.metaPage.PutUint64(uint64(q.ReplaySeq()), queueReplaySeqOffset)
+	} else {
+		q.replaySeq.Store(int64(q.metaPage.ReadUint64(queueReplaySeqOffset)))
+	}
 	q.expireDataPage.Store(int64(q.metaPage.ReadUint64(queueExpireDataOffset)))
 	q.expireIndexPage.Store(int64(q.metaPage.ReadUint64(queueExpireIndexOffset)))
 }
@@ -538,17 +669,36 @@ func (q *queue) validateSequence(sequence int64) error {
 	q.rwMutex.RLock()
 	defer q.rwMutex.RUnlock()
 
-	if sequence <= q.TailSeq() || sequence > q.HeadSeq() {
+	if sequence <= q.ReplaySeq() || sequence > q.HeadSeq() {
 		return ErrOutOfSequenceRange
 	}
 
 	return nil
 }
 
-// checkDataSize checks the data size if exceeds the size limit
+// checkDataSize checks the data size if exceeds the size limit,
+// removes the retained pages first if exceeds.
 func (q *queue) checkDataSize() error {
-	if q.dataPageFct.Size()+q.indexPageFct.Size() > q.dataSizeLimit {
+	if q.DiskSize() > q.dataSizeLimit && q.retention > 0 {
+		q.removeExpirePage(true)
+	}
+	if q.DiskSize() > q.dataSizeLimit {
 		return ErrExceedingTotalSizeLimit
 	}
 	return nil
 }
+
+// existingPageSize returns the max size of page files under dir, returns 0 if no page file.
+func existingPageSize(dir string) int {
+	fileNames, err := listDirFunc(dir)
+	if err != nil {
+		return 0
+	}
+	size := 0
+	for _, fn := range fileNames {
+		if stat, err := statFunc(filepath.Join(dir, fn)); err == nil && int(stat.Size()) > size {
+			size = int(stat.Size())
+		}
+	}
+	return size
+}
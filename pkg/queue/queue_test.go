@@ -19,6 +19,7 @@ package queue
 
 import (
 	"fmt"
+	"os"
 	"path"
 	"strings"
 	"sync"
@@ -251,7 +252,7 @@ func TestQueue_new_err(t *testing.T) {
 	metaPage := page.NewMockMappedPage(ctrl)
 
 	fct.EXPECT().AcquirePage(gomock.Any()).Return(metaPage, nil)
-	metaPage.EXPECT().PutUint64(gomock.Any(), gomock.Any()).MaxTimes(4)
+	metaPage.EXPECT().PutUint64(gomock.Any(), gomock.Any()).MaxTimes(5)
 	metaPage.EXPECT().Sync().Return(fmt.Errorf("err"))
 	// remove old data
 	_ = fileutil.RemoveDir(testPath)
@@ -347,7 +348,7 @@ func TestQueue_Put_err(t *testing.T) {
 	q, err := NewQueue(dir, 1024, time.Minute)
 	assert.NoError(t, err)
 	// case 1: data > page size, return err
-	data := make([]byte, dataPageSize+10)
+	data := make([]byte, defaultDataPageSize+10)
 	err = q.Put(data)
 	assert.Error(t, err)
 	// case 2: alloc new data page err
@@ -364,7 +365,7 @@ func TestQueue_Put_err(t *testing.T) {
 
 	fct.EXPECT().AcquirePage(gomock.Any()).Return(nil, fmt.Errorf("err"))
 
-	data = make([]byte, dataPageSize-5)
+	data = make([]byte, defaultDataPageSize-5)
 	err = q.Put(data)
 	assert.Error(t, err)
 
@@ -448,7 +449,7 @@ func TestQueue_Ack_err(t *testing.T) {
 	q1.metaPage = mockMetaPage
 
 	// sync meta page err
-	mockMetaPage.EXPECT().PutUint64(gomock.Any(), gomock.Any())
+	mockMetaPage.EXPECT().PutUint64(gomock.Any(), gomock.Any()).Times(2)
 	mockMetaPage.EXPECT().Sync().Return(fmt.Errorf("err"))
 	q.Ack(0)
 
@@ -467,8 +468,8 @@ func TestQueue_data_limit(t *testing.T) {
 	q, err := NewQueue(dir, 128*1024*1024, time.Second)
 	assert.NoError(t, err)
 	q1 := q.(*queue)
-	q1.dataSizeLimit = dataPageSize - 10
-	data := make([]byte, dataPageSize-10)
+	q1.dataSizeLimit = defaultDataPageSize - 10
+	data := make([]byte, defaultDataPageSize-10)
 	// put data
 	err = q.Put(data)
 	assert.NoError(t, err)
@@ -476,7 +477,7 @@ func TestQueue_data_limit(t *testing.T) {
 	err = q.Put(data)
 	assert.Equal(t, ErrExceedingTotalSizeLimit, err)
 
-	q1.dataSizeLimit = 2 * dataPageSize
+	q1.dataSizeLimit = 2 * defaultDataPageSize
 	q1.headSeq.Store(indexItemsPerPage)
 	// need acquire index page, but size limit
 	err = q.Put(data)
@@ -548,7 +549,7 @@ func TestQueue_remove_expire_page(t *testing.T) {
 	indexPageFct := page.NewMockFactory(ctrl)
 	dataPageFct := page.NewMockFactory(ctrl)
 	metaPage := page.NewMockMappedPage(ctrl)
-	q, err := NewQueue(dir, dataPageSize*8, 500*time.Second)
+	q, err := NewQueue(dir, defaultDataPageSize*8, 500*time.Second)
 	assert.NoError(t, err)
 	q.Close()
 
@@ -557,11 +558,11 @@ func TestQueue_remove_expire_page(t *testing.T) {
 	q1.indexPageFct = indexPageFct
 	q1.dataPageFct = dataPageFct
 	// case 1: ack sequence < 0
-	q1.removeExpirePage()
+	q1.removeExpirePage(false)
 	q1.tailSeq.Store(indexItemsPerPage * 3)
 	// case 2: index page not exist
 	indexPageFct.EXPECT().GetPage(gomock.Any()).Return(nil, false)
-	q1.removeExpirePage()
+	q1.removeExpirePage(false)
 	// case 3: release page
 	indexPage := page.NewMockMappedPage(ctrl)
 	gomock.InOrder(
@@ -575,9 +576,10 @@ func TestQueue_remove_expire_page(t *testing.T) {
 		indexPageFct.EXPECT().ReleasePage(int64(0)).Return(nil),
 		metaPage.EXPECT().PutUint64(uint64(0), queueExpireIndexOffset),
 		indexPageFct.EXPECT().ReleasePage(int64(1)).Return(fmt.Errorf("err")),
+		metaPage.EXPECT().PutUint64(uint64(indexItemsPerPage*3), queueReplaySeqOffset),
 		metaPage.EXPECT().Sync().Return(fmt.Errorf("err")),
 	)
-	q1.removeExpirePage()
+	q1.removeExpirePage(false)
 }
 
 func TestQueue_big_loop(t *testing.T) {
@@ -587,7 +589,7 @@ func TestQueue_big_loop(t *testing.T) {
 		_ = fileutil.RemoveDir(testPath)
 	}()
 
-	q, err := NewQueue(dir, dataPageSize*8, 500*time.Millisecond)
+	q, err := NewQueue(dir, defaultDataPageSize*8, 500*time.Millisecond)
 	assert.NoError(t, err)
 	loop := 1000000
 	str := "big_loop_test"
@@ -616,3 +618,75 @@ func mockMessageData(bucket, length int) map[string][]byte {
 
 	return data
 }
+
+func TestQueue_retention(t *testing.T) {
+	dir := path.Join(testPath, "queue")
+
+	defer func() {
+		statFunc = os.Stat
+		_ = fileutil.RemoveDir(testPath)
+	}()
+
+	q, err := NewQueue(dir, indexPageSize+4*1024, time.Second,
+		WithSegmentSize(1024), WithRetention(time.Hour))
+	assert.NoError(t, err)
+	q1 := q.(*queue)
+	assert.Equal(t, 1024, q1.dataPageSize)
+	msg := make([]byte, 512)
+	// 2 messages per data page
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, q.Put(msg))
+	}
+	q.Ack(3)
+	assert.Equal(t, int64(-1), q.ReplaySeq())
+	// case 1: acked pages retained, can be replayed
+	q1.removeExpirePage(false)
+	assert.Equal(t, int64(-1), q.ReplaySeq())
+	_, err = q.Get(0)
+	assert.NoError(t, err)
+	// case 2: retention expired, page 0 removed
+	statFunc = func(name string) (os.FileInfo, error) {
+		return nil, fmt.Errorf("err")
+	}
+	q1.removeExpirePage(false)
+	statFunc = os.Stat
+	assert.Equal(t, int64(1), q.ReplaySeq())
+	_, err = q.Get(1)
+	assert.Equal(t, ErrOutOfSequenceRange, err)
+	_, err = q.Get(2)
+	assert.NoError(t, err)
+	q.Close()
+
+	// case 3: reopen, keep replay sequence and page size of existing pages
+	q, err = NewQueue(dir, indexPageSize+4*1024, time.Second,
+		WithSegmentSize(512), WithRetention(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, q.(*queue).dataPageSize)
+	assert.Equal(t, int64(1), q.ReplaySeq())
+	assert.Equal(t, int64(3), q.TailSeq())
+	q.Close()
+}
+
+func TestQueue_retention_data_limit(t *testing.T) {
+	dir := path.Join(testPath, "queue")
+
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+
+	q, err := NewQueue(dir, indexPageSize+4*1024, time.Second,
+		WithSegmentSize(1024), WithRetention(time.Hour))
+	assert.NoError(t, err)
+	msg := make([]byte, 512)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, q.Put(msg))
+	}
+	assert.True(t, q.DiskSize() > indexPageSize)
+	// no acked page can be removed
+	assert.Equal(t, ErrExceedingTotalSizeLimit, q.Put(msg))
+	// retained pages are removed before exceeding limit
+	q.Ack(5)
+	assert.NoError(t, q.Put(msg))
+	assert.Equal(t, int64(3), q.ReplaySeq())
+	q.Close()
+}
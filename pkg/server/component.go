@@ -0,0 +1,151 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+//go:generate mockgen -source=./component.go -destination=./component_mock.go -package=server
+
+// ComponentManager starts the non-critical components of server(monitoring pusher, system collector etc.),
+// failure of these components doesn't prevent server serving, the component is reported as degraded,
+// and its startup is retried in background with backoff.
+type ComponentManager interface {
+	// StartComponent starts the component, if fail retries in background until success or server stopped.
+	StartComponent(name string, start func() error)
+	// Health returns the health status of all components.
+	Health() *models.NodeHealth
+}
+
+// componentManager implements ComponentManager interface.
+type componentManager struct {
+	ctx        context.Context
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	components map[string]*models.ComponentHealth
+	mutex      sync.RWMutex
+
+	logger *logger.Logger
+}
+
+// NewComponentManager creates the component manager, backoff of retry starts from minBackoff,
+// doubles after each failure and is limited by maxBackoff.
+func NewComponentManager(ctx context.Context, minBackoff, maxBackoff time.Duration) ComponentManager {
+	return &componentManager{
+		ctx:        ctx,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		components: make(map[string]*models.ComponentHealth),
+		logger:     logger.GetLogger("server", "ComponentManager"),
+	}
+}
+
+// StartComponent starts the component, if fail retries in background until success or server stopped.
+func (m *componentManager) StartComponent(name string, start func() error) {
+	m.mutex.Lock()
+	m.components[name] = &models.ComponentHealth{Name: name}
+	m.mutex.Unlock()
+
+	if m.tryStart(name, start) {
+		return
+	}
+	go m.retry(name, start)
+}
+
+// Health returns the health status of all components.
+func (m *componentManager) Health() *models.NodeHealth {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	health := &models.NodeHealth{}
+	for _, c := range m.components {
+		if !c.Healthy {
+			health.Degraded = true
+		}
+		health.Components = append(health.Components, *c)
+	}
+	sort.Slice(health.Components, func(i, j int) bool {
+		return health.Components[i].Name < health.Components[j].Name
+	})
+	return health
+}
+
+// retry retries starting the component with backoff.
+func (m *componentManager) retry(name string, start func() error) {
+	backoff := m.minBackoff
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-timer.C:
+			if m.tryStart(name, start) {
+				return
+			}
+			backoff *= 2
+			if backoff > m.maxBackoff {
+				backoff = m.maxBackoff
+			}
+			timer.Reset(backoff)
+		}
+	}
+}
+
+// tryStart starts the component, returns if start successfully.
+func (m *componentManager) tryStart(name string, start func() error) bool {
+	err := m.doStart(start)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	c := m.components[name]
+	if err != nil {
+		m.logger.Warn("start component failure, server is degraded, retry later",
+			logger.String("component", name), logger.Error(err))
+		c.Healthy = false
+		c.Error = err.Error()
+		c.Failures++
+		return false
+	}
+	m.logger.Info("start component successfully", logger.String("component", name))
+	c.Healthy = true
+	c.Error = ""
+	c.StartTime = timeutil.Now()
+	return true
+}
+
+// doStart starts the component, converts the panic of component into error.
+func (m *componentManager) doStart(start func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic when start component: %v", r)
+		}
+	}()
+	return start()
+}
@@ -0,0 +1,75 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func TestComponentManager_StartComponent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewComponentManager(ctx, time.Millisecond, 4*time.Millisecond)
+	m.StartComponent("ok", func() error { return nil })
+	health := m.Health()
+	assert.False(t, health.Degraded)
+	assert.Len(t, health.Components, 1)
+	assert.True(t, health.Components[0].Healthy)
+
+	// start failure, retry in background until success
+	count := atomic.NewInt32(0)
+	m.StartComponent("retry", func() error {
+		if count.Inc() < 3 {
+			return fmt.Errorf("err")
+		}
+		return nil
+	})
+	health = m.Health()
+	assert.True(t, health.Degraded)
+	assert.Equal(t, "retry", health.Components[1].Name)
+	assert.Equal(t, "err", health.Components[1].Error)
+	for i := 0; i < 1000 && m.Health().Degraded; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, m.Health().Degraded)
+	health = m.Health()
+	assert.Equal(t, 2, health.Components[1].Failures)
+	assert.Empty(t, health.Components[1].Error)
+}
+
+func TestComponentManager_Panic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewComponentManager(ctx, time.Millisecond, time.Millisecond)
+	m.StartComponent("panic", func() error {
+		panic("err")
+	})
+	health := m.Health()
+	assert.True(t, health.Degraded)
+	assert.Contains(t, health.Components[0].Error, "panic")
+	// stop retry
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, m.Health().Degraded)
+}
@@ -17,6 +17,8 @@
 
 package server
 
+import "github.com/lindb/lindb/config"
+
 //go:generate mockgen -source=./service.go -destination=./service_mock.go -package=server
 
 // Service represents an operational state of server, lifecycle methods to transition between states.
@@ -27,6 +29,24 @@ type Service interface {
 	Run() error
 	// State returns current service state
 	State() State
-	// Stop shutdowns server, do some cleanup logic
+	// Stop shutdowns server, do some cleanup logic, drains server first if not drained
 	Stop()
+	Drainer
+	Reloader
+}
+
+// Drainer drains server before shutdown, so that rolling deploy doesn't lose in-flight data.
+type Drainer interface {
+	// Drain stops accepting new writes/queries, flushes pending data and deregisters from discovery,
+	// returns after drained, server can be stopped safely.
+	Drain() error
+	// Draining returns if server is draining or drained.
+	Draining() bool
+}
+
+// Reloader reloads config of server without restart.
+type Reloader interface {
+	// Reload applies the dynamic fields of new config(same type as server's config),
+	// changes of immutable fields are rejected, returns the report of applied/rejected changes.
+	Reload(newCfg interface{}) (*config.ReloadReport, error)
 }
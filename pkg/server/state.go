@@ -29,4 +29,8 @@ const (
 	Failed
 	// Terminated is stopped
 	Terminated
+	// Draining rejects new writes/queries, flushes pending data before shutdown
+	Draining
+	// Drained has flushed pending data and deregistered from discovery, can be stopped safely
+	Drained
 )
@@ -0,0 +1,85 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package state
+
+import (
+	"time"
+
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// max retry interval of exponential backoff
+	maxRetryInterval = 5 * time.Second
+)
+
+// backoff represents the exponential backoff for retrying etcd operation.
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+// newBackoff creates the exponential backoff.
+func newBackoff(initial, max time.Duration) *backoff {
+	return &backoff{
+		initial: initial,
+		max:     max,
+	}
+}
+
+// next returns the waiting time before next retry, doubles it until max.
+func (b *backoff) next() time.Duration {
+	if b.current <= 0 {
+		b.current = b.initial
+	}
+	d := b.current
+	b.current *= 2
+	if b.current > b.max {
+		b.current = b.max
+	}
+	return d
+}
+
+// reset resets the waiting time to initial value after success.
+func (b *backoff) reset() {
+	b.current = 0
+}
+
+// isTransientErr checks if the error is transient which can be retried,
+// such as leader changing or etcd server unavailable when restarting.
+func isTransientErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch err {
+	case rpctypes.ErrNoLeader,
+		rpctypes.ErrLeaderChanged,
+		rpctypes.ErrTimeout,
+		rpctypes.ErrTimeoutDueToLeaderFail,
+		rpctypes.ErrTimeoutDueToConnectionLost,
+		rpctypes.ErrTooManyRequests:
+		return true
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code() == codes.Unavailable
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(100*time.Millisecond, time.Second)
+	assert.Equal(t, 100*time.Millisecond, b.next())
+	assert.Equal(t, 200*time.Millisecond, b.next())
+	assert.Equal(t, 400*time.Millisecond, b.next())
+	assert.Equal(t, 800*time.Millisecond, b.next())
+	assert.Equal(t, time.Second, b.next())
+	assert.Equal(t, time.Second, b.next())
+	b.reset()
+	assert.Equal(t, 100*time.Millisecond, b.next())
+}
+
+func TestIsTransientErr(t *testing.T) {
+	assert.False(t, isTransientErr(nil))
+	assert.False(t, isTransientErr(fmt.Errorf("err")))
+	assert.False(t, isTransientErr(rpctypes.ErrEmptyKey))
+	assert.True(t, isTransientErr(rpctypes.ErrNoLeader))
+	assert.True(t, isTransientErr(rpctypes.ErrTimeout))
+	assert.True(t, isTransientErr(status.Error(codes.Unavailable, "unavailable")))
+	assert.False(t, isTransientErr(status.Error(codes.InvalidArgument, "invalid")))
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/pkg/logger"
 
 	etcdcliv3 "go.etcd.io/etcd/clientv3"
@@ -33,12 +34,34 @@ import (
 	"google.golang.org/grpc"
 )
 
+// etcdMetrics represents the health metrics of etcd repository.
+type etcdMetrics struct {
+	healthy           *linmetric.BoundGauge        // 1 if etcd is available, 0 if transient error occurs
+	retries           *linmetric.DeltaCounterVec   // retries of operation on transient error
+	failures          *linmetric.DeltaCounterVec   // failed operations
+	watchRestarts     *linmetric.BoundDeltaCounter // re-watch count after watch stream broken
+	keepaliveRegrants *linmetric.BoundDeltaCounter // re-grant lease count after keepalive broken
+}
+
+// newEtcdMetrics creates the health metrics of etcd repository.
+func newEtcdMetrics(owner string) *etcdMetrics {
+	scope := linmetric.NewScope("lindb.state.etcd", "owner", owner)
+	return &etcdMetrics{
+		healthy:           scope.NewGauge("healthy"),
+		retries:           scope.NewDeltaCounterVec("retries", "op"),
+		failures:          scope.NewDeltaCounterVec("failures", "op"),
+		watchRestarts:     scope.NewDeltaCounter("watch_restarts"),
+		keepaliveRegrants: scope.NewDeltaCounter("keepalive_regrants"),
+	}
+}
+
 // etcdRepository is repository based on etcd storage
 type etcdRepository struct {
 	namespace string
 	client    *etcdcliv3.Client
 	logger    *logger.Logger
 	timeout   time.Duration
+	metrics   *etcdMetrics
 }
 
 // newEtcdRepository creates a new repository based on etcd storage
@@ -61,7 +84,9 @@ func newEtcdRepository(repoState config.RepoState, owner string) (Repository, er
 		namespace: repoState.Namespace,
 		client:    cli,
 		timeout:   repoState.Timeout.Duration(),
+		metrics:   newEtcdMetrics(owner),
 		logger:    logger.GetLogger(owner, "ETCD")}
+	repo.metrics.healthy.Update(1)
 
 	repo.logger.Info("new etcd client successfully",
 		logger.Any("endpoints", repoState.Endpoints))
@@ -79,11 +104,30 @@ func (r *etcdRepository) Get(ctx context.Context, key string) ([]byte, error) {
 	return r.getValue(key, resp)
 }
 
+// GetWithRevision retrieves value and its modify revision for given key from etcd
+func (r *etcdRepository) GetWithRevision(ctx context.Context, key string) ([]byte, int64, error) {
+	thisCtx, cancelFunc := context.WithTimeout(ctx, r.timeout)
+	defer cancelFunc()
+	resp, err := r.get(thisCtx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	val, err := r.getValue(key, resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	return val, resp.Kvs[0].ModRevision, nil
+}
+
 // List retrieves list for given prefix from etcd
 func (r *etcdRepository) List(ctx context.Context, prefix string) ([]KeyValue, error) {
 	thisCtx, cancelFunc := context.WithTimeout(ctx, r.timeout)
 	defer cancelFunc()
-	resp, err := r.client.Get(thisCtx, r.keyPath(prefix), etcdcliv3.WithPrefix())
+	var resp *etcdcliv3.GetResponse
+	err := r.retry(thisCtx, "list", func() (err error) {
+		resp, err = r.client.Get(thisCtx, r.keyPath(prefix), etcdcliv3.WithPrefix())
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +147,10 @@ func (r *etcdRepository) List(ctx context.Context, prefix string) ([]KeyValue, e
 func (r *etcdRepository) Put(ctx context.Context, key string, val []byte) error {
 	thisCtx, cancelFunc := context.WithTimeout(ctx, r.timeout)
 	defer cancelFunc()
-	_, err := r.client.Put(thisCtx, r.keyPath(key), string(val))
+	err := r.retry(thisCtx, "put", func() error {
+		_, err := r.client.Put(thisCtx, r.keyPath(key), string(val))
+		return err
+	})
 	if err == nil {
 		r.logger.Debug("put ok", logger.String("path", key),
 			logger.String("namespace", r.namespace))
@@ -119,8 +166,10 @@ func (r *etcdRepository) Put(ctx context.Context, key string, val []byte) error
 func (r *etcdRepository) Delete(ctx context.Context, key string) error {
 	thisCtx, cancelFunc := context.WithTimeout(ctx, r.timeout)
 	defer cancelFunc()
-	_, err := r.client.Delete(thisCtx, r.keyPath(key))
-	return err
+	return r.retry(thisCtx, "delete", func() error {
+		_, err := r.client.Delete(thisCtx, r.keyPath(key))
+		return err
+	})
 }
 
 // Close closes etcd client
@@ -132,6 +181,7 @@ func (r *etcdRepository) Close() error {
 func (r *etcdRepository) Heartbeat(ctx context.Context, key string, value []byte, ttl int64) (<-chan Closed, error) {
 	h := newHeartbeat(r.client, r.keyPath(key), value, ttl, false)
 	h.withLogger(r.logger)
+	h.regrants = r.metrics.keepaliveRegrants
 	_, err := h.grantKeepAliveLease(ctx)
 	if err != nil {
 		return nil, err
@@ -155,6 +205,7 @@ func (r *etcdRepository) Elect(
 ) (bool, <-chan Closed, error) {
 	h := newHeartbeat(r.client, r.keyPath(key), value, ttl, true)
 	h.withLogger(r.logger)
+	h.regrants = r.metrics.keepaliveRegrants
 	success, err := h.grantKeepAliveLease(ctx)
 	if err != nil {
 		return false, nil, err
@@ -179,13 +230,43 @@ func (r *etcdRepository) Elect(
 func (r *etcdRepository) get(ctx context.Context, key string) (*etcdcliv3.GetResponse, error) {
 	thisCtx, cancelFunc := context.WithTimeout(ctx, r.timeout)
 	defer cancelFunc()
-	resp, err := r.client.Get(thisCtx, r.keyPath(key))
+	var resp *etcdcliv3.GetResponse
+	err := r.retry(thisCtx, "get", func() (err error) {
+		resp, err = r.client.Get(thisCtx, r.keyPath(key))
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("get value failure for key[%s], error:%s", key, err)
 	}
 	return resp, nil
 }
 
+// retry executes the idempotent operation, retries it with exponential backoff on transient error,
+// such as leader changing or etcd restarting, until ctx is done.
+func (r *etcdRepository) retry(ctx context.Context, op string, fn func() error) error {
+	b := newBackoff(defaultRetryInterval, maxRetryInterval)
+	for {
+		err := fn()
+		if !isTransientErr(err) {
+			r.metrics.healthy.Update(1)
+			if err != nil {
+				r.metrics.failures.WithTagValues(op).Incr()
+			}
+			return err
+		}
+		r.metrics.healthy.Update(0)
+		select {
+		case <-ctx.Done():
+			r.metrics.failures.WithTagValues(op).Incr()
+			return err
+		case <-time.After(b.next()):
+			r.metrics.retries.WithTagValues(op).Incr()
+			r.logger.Warn("retry etcd operation on transient error",
+				logger.String("op", op), logger.Error(err))
+		}
+	}
+}
+
 // getValue returns value of get's response
 func (r *etcdRepository) getValue(key string, resp *etcdcliv3.GetResponse) ([]byte, error) {
 	if len(resp.Kvs) == 0 {
@@ -228,7 +309,11 @@ func (r *etcdRepository) Batch(ctx context.Context, batch Batch) (bool, error) {
 		))
 	}
 
-	resp, err := r.client.Txn(ctx).Then(ops...).Commit()
+	var resp *etcdcliv3.TxnResponse
+	err := r.retry(ctx, "batch", func() (err error) {
+		resp, err = r.client.Txn(ctx).Then(ops...).Commit()
+		return err
+	})
 	if err != nil {
 		return false, err
 	}
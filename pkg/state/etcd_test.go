@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -71,11 +72,26 @@ func (ts *testEtcdRepoSuite) Test_Write_Read(c *check.C) {
 	home2 := &address{}
 	_ = encoding.JSONUnmarshal(d1, home2)
 	c.Assert(*home1, check.Equals, *home2)
+	d1, rev, err1 := rep.GetWithRevision(context.TODO(), "/test/key1")
+	c.Assert(err1, check.IsNil)
+	c.Assert(d1, check.DeepEquals, d)
+	c.Assert(rev > 0, check.Equals, true)
+	// revision mismatch
+	txn := rep.NewTransaction()
+	txn.ModRevisionCmp("/test/key1", "=", rev+1)
+	txn.Put("/test/key1", d)
+	c.Assert(rep.Commit(context.TODO(), txn), check.Equals, ErrTxnFailed)
+	txn = rep.NewTransaction()
+	txn.ModRevisionCmp("/test/key1", "=", rev)
+	txn.Put("/test/key1", d)
+	c.Assert(rep.Commit(context.TODO(), txn), check.IsNil)
 
 	_ = rep.Delete(context.TODO(), "/test/key1")
 
 	_, err2 := rep.Get(context.TODO(), "/test/key1")
 	c.Assert(err2, check.NotNil)
+	_, _, err2 = rep.GetWithRevision(context.TODO(), "/test/key1")
+	c.Assert(err2, check.NotNil)
 
 	_ = rep.Close()
 }
@@ -424,3 +440,37 @@ func (ts *testEtcdRepoSuite) TestNextSequence_Concurrency(c *check.C) {
 		c.Assert(ok, check.Equals, ok)
 	}
 }
+
+func (ts *testEtcdRepoSuite) TestWatch_EtcdRestart(c *check.C) {
+	b, _ := newEtcdRepository(config.RepoState{
+		Endpoints: ts.Cluster.Endpoints,
+	}, "nobody")
+	repo := b.(*etcdRepository)
+	repo.timeout = time.Second * 10
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := b.Watch(ctx, "/cluster1/restart/1", true)
+	var val atomic.Value
+	go func() {
+		for event := range ch {
+			for _, kv := range event.KeyValues {
+				val.Store(string(kv.Value))
+			}
+		}
+	}()
+	_ = b.Put(ctx, "/cluster1/restart/1", []byte("1"))
+
+	ts.RestartCluster()
+
+	// put after etcd restarted, put retries on transient error
+	err := b.Put(ctx, "/cluster1/restart/1", []byte("2"))
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 100; i++ {
+		if val.Load() == "2" {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Fatal("watch not recovered after etcd restart")
+}
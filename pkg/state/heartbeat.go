@@ -22,6 +22,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/pkg/logger"
 
 	etcd "go.etcd.io/etcd/clientv3"
@@ -30,7 +31,10 @@ import (
 const defaultTTL = 10 // default ttl => 10 seconds
 
 // define errors
-var errKeepaliveStopped = errors.New("heartbeat keepalive stopped")
+var (
+	errKeepaliveStopped = errors.New("heartbeat keepalive stopped")
+	errKeepaliveClosed  = errors.New("heartbeat keepalive channel closed")
+)
 
 // heartbeat represents a heartbeat with etcd, it will start a goroutine does keepalive in background
 type heartbeat struct {
@@ -41,8 +45,9 @@ type heartbeat struct {
 	keepaliveCh <-chan *etcd.LeaseKeepAliveResponse
 	isElect     bool
 
-	ttl    int64
-	logger *logger.Logger
+	ttl      int64
+	regrants *linmetric.BoundDeltaCounter // re-grant lease count after keepalive broken, maybe nil
+	logger   *logger.Logger
 }
 
 // newHeartbeat creates heartbeat instance
@@ -91,14 +96,22 @@ func (h *heartbeat) grantKeepAliveLease(ctx context.Context) (bool, error) {
 
 // keepAlive does keepalive and retry,if the key should be not exist,it should retry
 func (h *heartbeat) keepAlive(ctx context.Context) {
-	var (
-		err error
-		gap = 100 * time.Millisecond
-	)
+	var err error
+	b := newBackoff(defaultRetryInterval, maxRetryInterval)
 	for {
 		if err != nil {
 			h.logger.Error("do heartbeat keepalive error, retry.", logger.Error(err), logger.String("key", h.key))
-			time.Sleep(gap)
+			select {
+			case <-ctx.Done():
+				return
+			case <-h.client.Ctx().Done():
+				// etcd client closed, cannot re-grant lease any more
+				return
+			case <-time.After(b.next()):
+			}
+			if h.regrants != nil {
+				h.regrants.Incr()
+			}
 			if h.isElect {
 				// retry put if not exist. if failed closes the heartbeat
 				isSuccess, e := h.grantKeepAliveLease(ctx)
@@ -115,6 +128,9 @@ func (h *heartbeat) keepAlive(ctx context.Context) {
 			if ctx.Err() != nil {
 				return
 			}
+			if err == nil {
+				b.reset()
+			}
 		} else {
 			err = h.handleAliveResp(ctx)
 			// return if keepalive stopped
@@ -125,12 +141,16 @@ func (h *heartbeat) keepAlive(ctx context.Context) {
 	}
 }
 
-// handleAliveResp handles keepalive response, if keepalive closed or ctx canceled return keep liave stopped error
+// handleAliveResp handles keepalive response, if ctx canceled or etcd client closed return keep liave stopped error,
+// if keepalive channel closed(lease expired after etcd restart/leader change) return keepalive closed error for re-grant.
 func (h *heartbeat) handleAliveResp(ctx context.Context) error {
 	select {
 	case aliveResp := <-h.keepaliveCh:
 		if aliveResp == nil {
-			return errKeepaliveStopped
+			if ctx.Err() != nil || h.client.Ctx().Err() != nil {
+				return errKeepaliveStopped
+			}
+			return errKeepaliveClosed
 		}
 	case <-ctx.Done():
 		return errKeepaliveStopped
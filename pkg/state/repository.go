@@ -41,6 +41,9 @@ type RepositoryFactory interface {
 type Repository interface {
 	// Get retrieves value for given key from repository
 	Get(ctx context.Context, key string) ([]byte, error)
+	// GetWithRevision retrieves value and its modify revision for given key from repository,
+	// the revision can be used for compare-and-swap update by transaction.
+	GetWithRevision(ctx context.Context, key string) ([]byte, int64, error)
 	// List retrieves list for given prefix from repository
 	List(ctx context.Context, prefix string) ([]KeyValue, error)
 	// Put puts a key-value pair into repository
@@ -61,18 +61,28 @@ func (w *watcher) watch(eventCh chan<- *Event) {
 	cli := w.cli.client
 	var evtAll *Event
 	var resp *etcdcliv3.GetResponse
-	// The etcdcliv3.Watch may fail if ErrCompacted or other errors occurs.
-	for {
+	b := newBackoff(defaultRetryInterval, maxRetryInterval)
+	// The etcdcliv3.Watch may fail if ErrCompacted, leader changed, etcd restarted or other errors occurs,
+	// re-fetch all values(resync) then re-watch from the latest revision.
+	for first := true; ; first = false {
+		if !first {
+			w.cli.metrics.watchRestarts.Incr()
+		}
 		for {
 			var err error
 			if resp, err = cli.Get(w.ctx, w.key, w.opts...); err == nil {
 				evtAll = w.packAllEvents(resp.Kvs)
+				b.reset()
+				w.cli.metrics.healthy.Update(1)
 				break
 			}
+			if isTransientErr(err) {
+				w.cli.metrics.healthy.Update(0)
+			}
 			select {
 			case <-w.ctx.Done():
 				return
-			case <-time.After(defaultRetryInterval):
+			case <-time.After(b.next()):
 			}
 		}
 		select {
@@ -82,25 +92,37 @@ func (w *watcher) watch(eventCh chan<- *Event) {
 		}
 
 		opts := append(w.opts, etcdcliv3.WithRev(resp.Header.Revision+1))
-		wchc := cli.Watch(w.ctx, w.key, opts...)
+		// require leader, so that watch stream will be closed if etcd cluster loses leader.
+		watchCtx, cancel := context.WithCancel(etcdcliv3.WithRequireLeader(w.ctx))
+		wchc := cli.Watch(watchCtx, w.key, opts...)
 		if wchc == nil {
+			cancel()
 			continue
 		}
-		for watchResp := range wchc {
-			if err := watchResp.Err(); err != nil {
-				select {
-				case <-w.ctx.Done():
-					return
-				case eventCh <- &Event{Err: err}:
-				}
-				continue
+		w.watchEvents(wchc, eventCh)
+		cancel()
+		if w.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// watchEvents sends watch events to event channel until watch channel closed.
+func (w *watcher) watchEvents(wchc etcdcliv3.WatchChan, eventCh chan<- *Event) {
+	for watchResp := range wchc {
+		if err := watchResp.Err(); err != nil {
+			select {
+			case <-w.ctx.Done():
+				return
+			case eventCh <- &Event{Err: err}:
 			}
-			for _, event := range watchResp.Events {
-				select {
-				case <-w.ctx.Done():
-					return
-				case eventCh <- w.packWatchEvent(event):
-				}
+			continue
+		}
+		for _, event := range watchResp.Events {
+			select {
+			case <-w.ctx.Done():
+				return
+			case eventCh <- w.packWatchEvent(event):
 			}
 		}
 	}
@@ -69,3 +69,19 @@ func DeDupStringSlice(items []string) []string {
 	}
 	return dst
 }
+
+// Paginate returns the page of items which skips offset items and keeps at most limit items,
+// no limit if limit <= 0.
+func Paginate(items []string, offset, limit int) []string {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
@@ -46,3 +46,14 @@ func Test_DeDupString(t *testing.T) {
 	assert.Len(t, DeDupStringSlice([]string{"a", "v"}), 2)
 	assert.Len(t, DeDupStringSlice([]string{"a", "a", "b", "v"}), 3)
 }
+
+func Test_Paginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+	assert.Equal(t, items, Paginate(items, 0, 0))
+	assert.Equal(t, []string{"a", "b"}, Paginate(items, 0, 2))
+	assert.Equal(t, []string{"c", "d"}, Paginate(items, 2, 10))
+	assert.Equal(t, []string{"b"}, Paginate(items, 1, 1))
+	assert.Equal(t, []string{"a"}, Paginate(items, -1, 1))
+	assert.Nil(t, Paginate(items, 4, 1))
+	assert.Nil(t, Paginate(nil, 0, 1))
+}
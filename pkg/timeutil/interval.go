@@ -52,9 +52,16 @@ func (i *Interval) ValueOf(intervalStr string) error {
 	}
 	unixSuffix := string(intervalBytes[len(intervalBytes)-1])
 	valuePrefix := string(intervalBytes[:len(intervalBytes)-1])
+	if strings.HasSuffix(string(intervalBytes), "ms") {
+		// sub-second interval(millisecond)
+		unixSuffix = "ms"
+		valuePrefix = string(intervalBytes[:len(intervalBytes)-2])
+	}
 
 	var unit int64
 	switch unixSuffix {
+	case "ms":
+		unit = 1
 	case "s", "S":
 		unit = OneSecond
 	case "m":
@@ -103,3 +110,32 @@ func (i Interval) Calculator() IntervalCalculator {
 		return dayCalculator
 	}
 }
+
+// FamilyCalculator returns the calculator with given family time window,
+// day interval type uses sub-hour family(default one hour family),
+// month/year interval type uses multi-hour family(default one day/one month family),
+// if family window is not set, returns the default calculator.
+func (i Interval) FamilyCalculator(familyWindow Interval) IntervalCalculator {
+	if familyWindow <= 0 {
+		return i.Calculator()
+	}
+	window := familyWindow.Int64()
+	switch i.Type() {
+	case Year, Month:
+		if window%OneHour != 0 || OneDay%window != 0 {
+			return i.Calculator()
+		}
+		if i.Type() == Year {
+			return &yearWindow{window: window}
+		}
+		if window == OneDay {
+			return i.Calculator()
+		}
+		return &monthWindow{window: window}
+	default:
+		if window == OneHour {
+			return i.Calculator()
+		}
+		return &dayWindow{window: window}
+	}
+}
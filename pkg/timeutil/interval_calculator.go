@@ -114,6 +114,43 @@ func (d *day) CalcTimeWindows(start, end int64) int {
 	return int((t2-t1)/OneHour) + 1
 }
 
+// dayWindow implements Calculator interface for day interval type with custom family window,
+// family window must evenly divide one hour, slot index is still calculated based on the hour,
+// so that query can merge data of these families into hourly time window.
+type dayWindow struct {
+	day
+	window int64
+}
+
+// CalcSlot calculates field store slot index based on given timestamp and family start time
+func (d *dayWindow) CalcSlot(timestamp, baseTime, interval int64) int {
+	segmentTime := d.CalcSegmentTime(baseTime)
+	hourStartTime := segmentTime + (baseTime-segmentTime)/OneHour*OneHour
+	return int(((timestamp - hourStartTime) % OneHour) / interval)
+}
+
+// CalcFamily calculates family base time based on given timestamp and family window
+func (d *dayWindow) CalcFamily(timestamp int64, segmentTime int64) int {
+	return int((timestamp - segmentTime) / d.window)
+}
+
+// CalcFamilyStartTime calculates family start time based on segment time and family time
+func (d *dayWindow) CalcFamilyStartTime(segmentTime int64, familyTime int) int64 {
+	return segmentTime + int64(familyTime)*d.window
+}
+
+// CalcFamilyEndTime calculates family end time based on family start time
+func (d *dayWindow) CalcFamilyEndTime(familyStartTime int64) int64 {
+	return familyStartTime + d.window - 1
+}
+
+// CalcTimeWindows calculates the number of family window between start and end time
+func (d *dayWindow) CalcTimeWindows(start, end int64) int {
+	t1 := start / d.window * d.window
+	t2 := end / d.window * d.window
+	return int((t2-t1)/d.window) + 1
+}
+
 // month implements Calculator interface for month interval type
 type month struct{}
 
@@ -168,6 +205,43 @@ func (m *month) CalcTimeWindows(start, end int64) int {
 	return int(t2.Sub(t1).Hours()/24) + 1
 }
 
+// monthWindow implements Calculator interface for month interval type with custom family window,
+// family window must evenly divide one day, slot index is still based on day start time,
+// so that query side can use default month calculator.
+type monthWindow struct {
+	month
+	window int64
+}
+
+// CalcSlot calculates field store slot index based on given timestamp and family start time
+func (m *monthWindow) CalcSlot(timestamp, baseTime, interval int64) int {
+	segmentTime := m.CalcSegmentTime(baseTime)
+	dayStartTime := segmentTime + (baseTime-segmentTime)/OneDay*OneDay
+	return int(((timestamp - dayStartTime) % OneDay) / interval)
+}
+
+// CalcFamily calculates family base time based on given timestamp and family window
+func (m *monthWindow) CalcFamily(timestamp int64, segmentTime int64) int {
+	return int((timestamp - segmentTime) / m.window)
+}
+
+// CalcFamilyStartTime calculates family start time based on segment time and family time
+func (m *monthWindow) CalcFamilyStartTime(segmentTime int64, familyTime int) int64 {
+	return segmentTime + int64(familyTime)*m.window
+}
+
+// CalcFamilyEndTime calculates family end time based on family start time
+func (m *monthWindow) CalcFamilyEndTime(familyStartTime int64) int64 {
+	return familyStartTime + m.window - 1
+}
+
+// CalcTimeWindows calculates the number of family window between start and end time
+func (m *monthWindow) CalcTimeWindows(start, end int64) int {
+	t1 := start / m.window * m.window
+	t2 := end / m.window * m.window
+	return int((t2-t1)/m.window) + 1
+}
+
 // year implements Calculator interface for year interval type
 type year struct{}
 
@@ -222,6 +296,43 @@ func (y *year) CalcTimeWindows(start, end int64) int {
 	return int(t2.Sub(t1).Hours()/24/30) + 1
 }
 
+// yearWindow implements Calculator interface for year interval type with custom family window,
+// family window must evenly divide one day, slot index is still based on month start time,
+// so that query side can use default year calculator.
+type yearWindow struct {
+	year
+	window int64
+}
+
+// CalcSlot calculates field store slot index based on given timestamp and family start time
+func (y *yearWindow) CalcSlot(timestamp, baseTime, interval int64) int {
+	t := time.Unix(baseTime/1000, 0)
+	monthStartTime := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local).UnixNano() / 1000000
+	return int((timestamp - monthStartTime) / interval)
+}
+
+// CalcFamily calculates family base time based on given timestamp and family window
+func (y *yearWindow) CalcFamily(timestamp int64, segmentTime int64) int {
+	return int((timestamp - segmentTime) / y.window)
+}
+
+// CalcFamilyStartTime calculates family start time based on segment time and family time
+func (y *yearWindow) CalcFamilyStartTime(segmentTime int64, familyTime int) int64 {
+	return segmentTime + int64(familyTime)*y.window
+}
+
+// CalcFamilyEndTime calculates family end time based on family start time
+func (y *yearWindow) CalcFamilyEndTime(familyStartTime int64) int64 {
+	return familyStartTime + y.window - 1
+}
+
+// CalcTimeWindows calculates the number of family window between start and end time
+func (y *yearWindow) CalcTimeWindows(start, end int64) int {
+	t1 := start / y.window * y.window
+	t2 := end / y.window * y.window
+	return int((t2-t1)/y.window) + 1
+}
+
 // CalcTimestamp returns timestamp based on start time, slot and interval.
 func CalcTimestamp(startTime int64, slot int, interval Interval) int64 {
 	return interval.Int64()*int64(slot) + startTime
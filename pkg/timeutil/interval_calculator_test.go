@@ -150,3 +150,55 @@ func TestCalcTimestamp(t *testing.T) {
 	timestamp := CalcTimestamp(now, 10, i)
 	assert.True(t, timestamp >= n.Add(10*time.Minute).Unix()*1000)
 }
+
+func TestDayWindowCalculator(t *testing.T) {
+	calc := Interval(10 * OneSecond).FamilyCalculator(Interval(30 * OneMinute))
+	segmentTime, _ := calc.ParseSegmentTime("20190702")
+	now, _ := ParseTimestamp("20190702 12:40:30", "20060102 15:04:05")
+	assert.Equal(t, segmentTime, calc.CalcSegmentTime(now))
+	family := calc.CalcFamily(now, segmentTime)
+	assert.Equal(t, 25, family)
+	familyStartTime, _ := ParseTimestamp("20190702 12:30:00", "20060102 15:04:05")
+	assert.Equal(t, familyStartTime, calc.CalcFamilyStartTime(segmentTime, family))
+	assert.Equal(t, familyStartTime+30*OneMinute-1, calc.CalcFamilyEndTime(familyStartTime))
+	// slot is based on the hour of family
+	assert.Equal(t, 243, calc.CalcSlot(now, familyStartTime, 10*OneSecond))
+	assert.Equal(t, dayCalculator.CalcSlot(now, familyStartTime-30*OneMinute, 10*OneSecond),
+		calc.CalcSlot(now, familyStartTime, 10*OneSecond))
+	assert.Equal(t, 2, calc.CalcTimeWindows(familyStartTime, familyStartTime+30*OneMinute))
+}
+
+func TestMonthWindowCalculator(t *testing.T) {
+	calc := Interval(10 * OneMinute).FamilyCalculator(Interval(6 * OneHour))
+	segmentTime, _ := calc.ParseSegmentTime("201907")
+	now, _ := ParseTimestamp("20190702 14:40:00", "20060102 15:04:05")
+	assert.Equal(t, segmentTime, calc.CalcSegmentTime(now))
+	family := calc.CalcFamily(now, segmentTime)
+	assert.Equal(t, 6, family)
+	familyStartTime, _ := ParseTimestamp("20190702 12:00:00", "20060102 15:04:05")
+	assert.Equal(t, familyStartTime, calc.CalcFamilyStartTime(segmentTime, family))
+	assert.Equal(t, familyStartTime+6*OneHour-1, calc.CalcFamilyEndTime(familyStartTime))
+	// slot is based on the day of family
+	dayStartTime, _ := ParseTimestamp("20190702 00:00:00", "20060102 15:04:05")
+	assert.Equal(t, 88, calc.CalcSlot(now, familyStartTime, 10*OneMinute))
+	assert.Equal(t, monthCalculator.CalcSlot(now, dayStartTime, 10*OneMinute),
+		calc.CalcSlot(now, familyStartTime, 10*OneMinute))
+	assert.Equal(t, 2, calc.CalcTimeWindows(familyStartTime, familyStartTime+6*OneHour))
+}
+
+func TestYearWindowCalculator(t *testing.T) {
+	calc := Interval(OneHour).FamilyCalculator(Interval(12 * OneHour))
+	segmentTime, _ := calc.ParseSegmentTime("2019")
+	now, _ := ParseTimestamp("20190702 14:00:00", "20060102 15:04:05")
+	assert.Equal(t, segmentTime, calc.CalcSegmentTime(now))
+	family := calc.CalcFamily(now, segmentTime)
+	familyStartTime, _ := ParseTimestamp("20190702 12:00:00", "20060102 15:04:05")
+	assert.Equal(t, familyStartTime, calc.CalcFamilyStartTime(segmentTime, family))
+	assert.Equal(t, familyStartTime+12*OneHour-1, calc.CalcFamilyEndTime(familyStartTime))
+	// slot is based on the month of family
+	monthStartTime, _ := ParseTimestamp("20190701 00:00:00", "20060102 15:04:05")
+	assert.Equal(t, 38, calc.CalcSlot(now, familyStartTime, OneHour))
+	assert.Equal(t, yearCalculator.CalcSlot(now, monthStartTime, OneHour),
+		calc.CalcSlot(now, familyStartTime, OneHour))
+	assert.Equal(t, 2, calc.CalcTimeWindows(familyStartTime, familyStartTime+12*OneHour))
+}
@@ -65,6 +65,12 @@ func Test_Interval_ValueOf(t *testing.T) {
 
 	assert.Nil(t, i.ValueOf(" 10Y"))
 	assert.Equal(t, 10*OneYear, i.Int64())
+
+	assert.Nil(t, i.ValueOf("100ms"))
+	assert.Equal(t, int64(100), i.Int64())
+	assert.Equal(t, Day, i.Type())
+
+	assert.NotNil(t, i.ValueOf("ms"))
 }
 
 func Test_IntervalCalculator(t *testing.T) {
@@ -79,3 +85,19 @@ func Test_IntervalCalculator(t *testing.T) {
 	_ = i.ValueOf("10d")
 	assert.NotNil(t, i.Calculator())
 }
+
+func Test_Interval_FamilyCalculator(t *testing.T) {
+	assert.Equal(t, dayCalculator, Interval(10*OneSecond).FamilyCalculator(0))
+	assert.Equal(t, dayCalculator, Interval(10*OneSecond).FamilyCalculator(Interval(OneHour)))
+	assert.Equal(t, monthCalculator, Interval(10*OneMinute).FamilyCalculator(Interval(30*OneMinute)))
+	assert.Equal(t, &dayWindow{window: 30 * OneMinute},
+		Interval(10*OneSecond).FamilyCalculator(Interval(30*OneMinute)))
+	assert.Equal(t, monthCalculator, Interval(10*OneMinute).FamilyCalculator(Interval(OneDay)))
+	assert.Equal(t, &monthWindow{window: 6 * OneHour},
+		Interval(10*OneMinute).FamilyCalculator(Interval(6*OneHour)))
+	assert.Equal(t, yearCalculator, Interval(OneHour).FamilyCalculator(Interval(5*OneHour)))
+	assert.Equal(t, &yearWindow{window: OneDay},
+		Interval(OneHour).FamilyCalculator(Interval(OneDay)))
+	assert.Equal(t, &yearWindow{window: 12 * OneHour},
+		Interval(OneHour).FamilyCalculator(Interval(12*OneHour)))
+}
@@ -0,0 +1,96 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package timeutil
+
+import (
+	"errors"
+	"strings"
+)
+
+// Precision represents the precision of timestamp, the value is the number of precision unit in a millisecond.
+type Precision int64
+
+// Defines all precisions of timestamp.
+const (
+	Millisecond Precision = 1
+	Microsecond Precision = 1000
+	Nanosecond  Precision = 1000 * 1000
+)
+
+// ErrUnknownPrecision represents the precision of timestamp is unknown.
+var ErrUnknownPrecision = errors.New("unknown precision")
+
+// ParsePrecision parses the precision str(ms/us/ns), returns millisecond if empty.
+func ParsePrecision(precisionStr string) (Precision, error) {
+	switch strings.ToLower(precisionStr) {
+	case "", "ms":
+		return Millisecond, nil
+	case "us":
+		return Microsecond, nil
+	case "ns":
+		return Nanosecond, nil
+	default:
+		return Millisecond, ErrUnknownPrecision
+	}
+}
+
+// String returns the string value of precision.
+func (p Precision) String() string {
+	switch p {
+	case Microsecond:
+		return "us"
+	case Nanosecond:
+		return "ns"
+	default:
+		return "ms"
+	}
+}
+
+// ToMillis converts the timestamp of precision to millisecond, sub-millisecond part is truncated.
+func (p Precision) ToMillis(timestamp int64) int64 {
+	if p <= Millisecond {
+		return timestamp
+	}
+	millis := timestamp / int64(p)
+	if timestamp < 0 && timestamp%int64(p) != 0 {
+		millis--
+	}
+	return millis
+}
+
+// FromMillis converts the timestamp of millisecond to precision.
+func (p Precision) FromMillis(timestamp int64) int64 {
+	if p <= Millisecond {
+		return timestamp
+	}
+	return timestamp * int64(p)
+}
+
+// Interval returns the number of precision unit of interval.
+func (p Precision) Interval(interval Interval) int64 {
+	return p.FromMillis(interval.Int64())
+}
+
+// CalcSlot calculates field store slot index based on given timestamp of precision and base time(millisecond),
+// offset of timestamp is calculated by interval calculator, then divided by interval in precision unit.
+func (p Precision) CalcSlot(calc IntervalCalculator, timestamp, baseTime int64, interval Interval) int {
+	millis := p.ToMillis(timestamp)
+	offset := int64(calc.CalcSlot(millis, baseTime, 1))
+	offset = p.FromMillis(offset) + timestamp - p.FromMillis(millis)
+	return int(offset / p.Interval(interval))
+}
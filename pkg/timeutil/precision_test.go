@@ -0,0 +1,71 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package timeutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrecision(t *testing.T) {
+	cases := []struct {
+		in        string
+		precision Precision
+	}{
+		{"", Millisecond},
+		{"ms", Millisecond},
+		{"us", Microsecond},
+		{"NS", Nanosecond},
+	}
+	for _, c := range cases {
+		p, err := ParsePrecision(c.in)
+		assert.NoError(t, err)
+		assert.Equal(t, c.precision, p)
+	}
+	_, err := ParsePrecision("s")
+	assert.Equal(t, ErrUnknownPrecision, err)
+
+	assert.Equal(t, "ms", Millisecond.String())
+	assert.Equal(t, "us", Microsecond.String())
+	assert.Equal(t, "ns", Nanosecond.String())
+}
+
+func TestPrecision_Convert(t *testing.T) {
+	assert.Equal(t, int64(1500), Millisecond.ToMillis(1500))
+	assert.Equal(t, int64(1500), Millisecond.FromMillis(1500))
+	assert.Equal(t, int64(1500), Microsecond.ToMillis(1500999))
+	assert.Equal(t, int64(1500000), Microsecond.FromMillis(1500))
+	assert.Equal(t, int64(1500), Nanosecond.ToMillis(1500999999))
+	assert.Equal(t, int64(-2), Nanosecond.ToMillis(-1000001))
+	assert.Equal(t, 10*OneSecond*1000*1000, Nanosecond.Interval(Interval(10*OneSecond)))
+}
+
+func TestPrecision_CalcSlot(t *testing.T) {
+	now, _ := ParseTimestamp("20190702 19:10:48", "20060102 15:04:05")
+	interval := Interval(100)
+	calc := interval.Calculator()
+	familyTime := calc.CalcFamilyStartTime(calc.CalcSegmentTime(now), calc.CalcFamily(now, calc.CalcSegmentTime(now)))
+	// 10m48s in hour
+	assert.Equal(t, 6480, Millisecond.CalcSlot(calc, now, familyTime, interval))
+	assert.Equal(t, 6481, Millisecond.CalcSlot(calc, now+150, familyTime, interval))
+	assert.Equal(t, 6481, Nanosecond.CalcSlot(calc, Nanosecond.FromMillis(now+150)+999, familyTime, interval))
+	assert.Equal(t, 6482, Microsecond.CalcSlot(calc, Microsecond.FromMillis(now+200), familyTime, interval))
+	assert.Equal(t, calc.CalcSlot(now, familyTime, 10*OneSecond),
+		Nanosecond.CalcSlot(calc, Nanosecond.FromMillis(now), familyTime, Interval(10*OneSecond)))
+}
@@ -45,6 +45,7 @@ const (
 	dataTimeFormat1 = "20060102 15:04:05"
 	dataTimeFormat2 = "2006-01-02 15:04:05"
 	dataTimeFormat3 = "2006/01/02 15:04:05"
+	dataTimeFormat4 = "2006-01-02T15:04:05.999999999"
 )
 
 // FormatTimestamp returns timestamp format based on layout
@@ -55,11 +56,27 @@ func FormatTimestamp(timestamp int64, layout string) string {
 
 // ParseTimestamp parses timestamp str value based on layout using local zone
 func ParseTimestamp(timestampStr string, layout ...string) (int64, error) {
+	return ParseTimestampInLocation(timestampStr, time.Local, layout...)
+}
+
+// ParseTimestampInLocation parses timestamp str value based on layout using the given zone,
+// if location is nil, uses local zone.
+func ParseTimestampInLocation(timestampStr string, location *time.Location, layout ...string) (int64, error) {
+	if location == nil {
+		location = time.Local
+	}
 	var format string
 	if len(layout) > 0 {
 		format = layout[0]
 	} else {
 		switch {
+		case strings.Contains(timestampStr, "T"):
+			// RFC3339, zone offset is optional, if not set uses the given zone
+			format = dataTimeFormat4
+			if idx := strings.Index(timestampStr, "T"); strings.HasSuffix(timestampStr, "Z") ||
+				strings.ContainsAny(timestampStr[idx:], "+-") {
+				format = time.RFC3339Nano
+			}
 		case strings.Index(timestampStr, "-") > 0:
 			format = dataTimeFormat2
 		case strings.Index(timestampStr, "/") > 0:
@@ -68,7 +85,7 @@ func ParseTimestamp(timestampStr string, layout ...string) (int64, error) {
 			format = dataTimeFormat1
 		}
 	}
-	tm, err := parseTimeFunc(format, timestampStr, time.Local)
+	tm, err := parseTimeFunc(format, timestampStr, location)
 	if err != nil {
 		return 0, err
 	}
@@ -52,6 +52,39 @@ func Test_ParseTimestamp(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_ParseTimestampInLocation(t *testing.T) {
+	utc, err := ParseTimestampInLocation("2019-12-12 10:11:10", time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1576145470000), utc)
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	t1, err := ParseTimestampInLocation("2019-12-12 18:11:10", shanghai)
+	assert.NoError(t, err)
+	assert.Equal(t, utc, t1)
+	// nil location using local zone
+	t1, err = ParseTimestampInLocation(date, nil)
+	assert.NoError(t, err)
+	t2, err := ParseTimestamp(date)
+	assert.NoError(t, err)
+	assert.Equal(t, t2, t1)
+}
+
+func Test_ParseTimestamp_RFC3339(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	for _, timeStr := range []string{"2019-12-12T10:11:10Z", "2019-12-12T18:11:10+08:00", "2019-12-12T10:11:10.000Z"} {
+		t1, err := ParseTimestampInLocation(timeStr, shanghai)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1576145470000), t1)
+	}
+	// without zone offset uses the given zone
+	t1, err := ParseTimestampInLocation("2019-12-12T18:11:10", shanghai)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1576145470000), t1)
+	_, err = ParseTimestampInLocation("2019-12-12T18:11", shanghai)
+	assert.Error(t, err)
+}
+
 func TestCalPointCount(t *testing.T) {
 	time1, _ := ParseTimestamp(date)
 	assert.Equal(t, 1, CalPointCount(time1, time1, 10*OneSecond))
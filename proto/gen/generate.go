@@ -20,4 +20,5 @@ package gen
 //go:generate mockgen -source=./v1/broker/broker.pb.go -destination=./v1/broker/broker_pb_mock.go -package=protoBrokerV1
 //go:generate mockgen -source=./v1/common/common.pb.go -destination=./v1/common/common_pb_mock.go -package=protoCommonV1
 //go:generate mockgen -source=./v1/replica/replica.pb.go -destination=./v1/replica/replica_pb_mock.go -package=protoReplicaV1
+//go:generate mockgen -source=./v1/query/query.pb.go -destination=./v1/query/query_pb_mock.go -package=protoQueryV1
 //go:generate mockgen -source=./v1/storage/storage.pb.go -destination=./v1/storage/storage_pb_mock.go -package=protoStorageV1
@@ -22,6 +22,7 @@ package protoCommonV1
 
 import (
 	context "context"
+	encoding_binary "encoding/binary"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
 	grpc "google.golang.org/grpc"
@@ -76,16 +77,19 @@ type RequestType int32
 const (
 	RequestType_Data     RequestType = 0
 	RequestType_Metadata RequestType = 1
+	RequestType_Cancel   RequestType = 2
 )
 
 var RequestType_name = map[int32]string{
 	0: "Data",
 	1: "Metadata",
+	2: "Cancel",
 }
 
 var RequestType_value = map[string]int32{
 	"Data":     0,
 	"Metadata": 1,
+	"Cancel":   2,
 }
 
 func (x RequestType) String() string {
@@ -97,14 +101,16 @@ func (RequestType) EnumDescriptor() ([]byte, []int) {
 }
 
 type TaskRequest struct {
-	ParentTaskID         string      `protobuf:"bytes,1,opt,name=parentTaskID,proto3" json:"parentTaskID,omitempty"`
-	Type                 TaskType    `protobuf:"varint,2,opt,name=type,proto3,enum=protoCommonV1.TaskType" json:"type,omitempty"`
-	RequestType          RequestType `protobuf:"varint,3,opt,name=requestType,proto3,enum=protoCommonV1.RequestType" json:"requestType,omitempty"`
-	PhysicalPlan         []byte      `protobuf:"bytes,4,opt,name=physicalPlan,proto3" json:"physicalPlan,omitempty"`
-	Payload              []byte      `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	ParentTaskID         string            `protobuf:"bytes,1,opt,name=parentTaskID,proto3" json:"parentTaskID,omitempty"`
+	Type                 TaskType          `protobuf:"varint,2,opt,name=type,proto3,enum=protoCommonV1.TaskType" json:"type,omitempty"`
+	RequestType          RequestType       `protobuf:"varint,3,opt,name=requestType,proto3,enum=protoCommonV1.RequestType" json:"requestType,omitempty"`
+	PhysicalPlan         []byte            `protobuf:"bytes,4,opt,name=physicalPlan,proto3" json:"physicalPlan,omitempty"`
+	Payload              []byte            `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	QueryID              string            `protobuf:"bytes,6,opt,name=queryID,proto3" json:"queryID,omitempty"`
+	Metadata             map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *TaskRequest) Reset()         { *m = TaskRequest{} }
@@ -175,6 +181,20 @@ func (m *TaskRequest) GetPayload() []byte {
 	return nil
 }
 
+func (m *TaskRequest) GetQueryID() string {
+	if m != nil {
+		return m.QueryID
+	}
+	return ""
+}
+
+func (m *TaskRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 type TaskResponse struct {
 	TaskID               string   `protobuf:"bytes,1,opt,name=taskID,proto3" json:"taskID,omitempty"`
 	Type                 TaskType `protobuf:"varint,2,opt,name=type,proto3,enum=protoCommonV1.TaskType" json:"type,omitempty"`
@@ -273,6 +293,7 @@ func (m *TaskResponse) GetStats() []byte {
 type TimeSeriesList struct {
 	TimeSeriesList       []*TimeSeries     `protobuf:"bytes,1,rep,name=timeSeriesList,proto3" json:"timeSeriesList,omitempty"`
 	FieldAggSpecs        []*AggregatorSpec `protobuf:"bytes,2,rep,name=fieldAggSpecs,proto3" json:"fieldAggSpecs,omitempty"`
+	StringValues         []*StringValue    `protobuf:"bytes,3,rep,name=stringValues,proto3" json:"stringValues,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -325,9 +346,17 @@ func (m *TimeSeriesList) GetFieldAggSpecs() []*AggregatorSpec {
 	return nil
 }
 
+func (m *TimeSeriesList) GetStringValues() []*StringValue {
+	if m != nil {
+		return m.StringValues
+	}
+	return nil
+}
+
 type TimeSeries struct {
 	Tags                 string            `protobuf:"bytes,1,opt,name=tags,proto3" json:"tags,omitempty"`
 	Fields               map[string][]byte `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Exemplars            []*Exemplar       `protobuf:"bytes,3,rep,name=exemplars,proto3" json:"exemplars,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -380,6 +409,13 @@ func (m *TimeSeries) GetFields() map[string][]byte {
 	return nil
 }
 
+func (m *TimeSeries) GetExemplars() []*Exemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
 type AggregatorSpec struct {
 	FieldName            string   `protobuf:"bytes,1,opt,name=fieldName,proto3" json:"fieldName,omitempty"`
 	FieldType            uint32   `protobuf:"varint,2,opt,name=fieldType,proto3" json:"fieldType,omitempty"`
@@ -443,57 +479,205 @@ func (m *AggregatorSpec) GetFuncTypeList() []uint32 {
 	return nil
 }
 
+type Exemplar struct {
+	TraceID              string   `protobuf:"bytes,1,opt,name=traceID,proto3" json:"traceID,omitempty"`
+	SpanID               string   `protobuf:"bytes,2,opt,name=spanID,proto3" json:"spanID,omitempty"`
+	Value                float64  `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp            int64    `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Bucket               float64  `protobuf:"fixed64,5,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Exemplar) Reset()         { *m = Exemplar{} }
+func (m *Exemplar) String() string { return proto.CompactTextString(m) }
+func (*Exemplar) ProtoMessage()    {}
+func (*Exemplar) Descriptor() ([]byte, []int) {
+	return fileDescriptor_555bd8c177793206, []int{5}
+}
+func (m *Exemplar) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Exemplar) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Exemplar.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Exemplar) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Exemplar.Merge(m, src)
+}
+func (m *Exemplar) XXX_Size() int {
+	return m.Size()
+}
+func (m *Exemplar) XXX_DiscardUnknown() {
+	xxx_messageInfo_Exemplar.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Exemplar proto.InternalMessageInfo
+
+func (m *Exemplar) GetTraceID() string {
+	if m != nil {
+		return m.TraceID
+	}
+	return ""
+}
+
+func (m *Exemplar) GetSpanID() string {
+	if m != nil {
+		return m.SpanID
+	}
+	return ""
+}
+
+func (m *Exemplar) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *Exemplar) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Exemplar) GetBucket() float64 {
+	if m != nil {
+		return m.Bucket
+	}
+	return 0
+}
+
+type StringValue struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StringValue) Reset()         { *m = StringValue{} }
+func (m *StringValue) String() string { return proto.CompactTextString(m) }
+func (*StringValue) ProtoMessage()    {}
+func (*StringValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_555bd8c177793206, []int{6}
+}
+func (m *StringValue) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StringValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StringValue.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *StringValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StringValue.Merge(m, src)
+}
+func (m *StringValue) XXX_Size() int {
+	return m.Size()
+}
+func (m *StringValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_StringValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StringValue proto.InternalMessageInfo
+
+func (m *StringValue) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *StringValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterEnum("protoCommonV1.TaskType", TaskType_name, TaskType_value)
 	proto.RegisterEnum("protoCommonV1.RequestType", RequestType_name, RequestType_value)
 	proto.RegisterType((*TaskRequest)(nil), "protoCommonV1.TaskRequest")
+	proto.RegisterMapType((map[string]string)(nil), "protoCommonV1.TaskRequest.MetadataEntry")
 	proto.RegisterType((*TaskResponse)(nil), "protoCommonV1.TaskResponse")
 	proto.RegisterType((*TimeSeriesList)(nil), "protoCommonV1.TimeSeriesList")
 	proto.RegisterType((*TimeSeries)(nil), "protoCommonV1.TimeSeries")
 	proto.RegisterMapType((map[string][]byte)(nil), "protoCommonV1.TimeSeries.FieldsEntry")
 	proto.RegisterType((*AggregatorSpec)(nil), "protoCommonV1.AggregatorSpec")
+	proto.RegisterType((*Exemplar)(nil), "protoCommonV1.Exemplar")
+	proto.RegisterType((*StringValue)(nil), "protoCommonV1.StringValue")
 }
 
 func init() { proto.RegisterFile("common.proto", fileDescriptor_555bd8c177793206) }
 
 var fileDescriptor_555bd8c177793206 = []byte{
-	// 564 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xcd, 0xc6, 0x69, 0x9a, 0x8e, 0x9d, 0xc8, 0x5a, 0x21, 0x30, 0x01, 0xa2, 0xc8, 0x52, 0x25,
-	0xab, 0x48, 0x11, 0xb4, 0x17, 0x40, 0x70, 0x28, 0x2d, 0x1f, 0x15, 0x6d, 0x40, 0xdb, 0x50, 0xce,
-	0x8b, 0x3d, 0x35, 0x56, 0xfd, 0x85, 0x77, 0x5b, 0xc9, 0xff, 0xa4, 0xe2, 0x17, 0x71, 0xe4, 0xc2,
-	0x85, 0x13, 0x2a, 0x7f, 0x04, 0xed, 0xda, 0x69, 0xe2, 0xa8, 0x1c, 0x38, 0x79, 0xe7, 0xcd, 0xbc,
-	0x37, 0xf3, 0xd6, 0xb3, 0x60, 0xf9, 0x59, 0x92, 0x64, 0xe9, 0x24, 0x2f, 0x32, 0x99, 0xd1, 0xbe,
-	0xfe, 0xec, 0x69, 0xe8, 0xe4, 0xb1, 0xfb, 0x8b, 0x80, 0x39, 0xe3, 0xe2, 0x8c, 0xe1, 0xd7, 0x73,
-	0x14, 0x92, 0xba, 0x60, 0xe5, 0xbc, 0xc0, 0x54, 0x2a, 0xf0, 0x60, 0xdf, 0x21, 0x63, 0xe2, 0x6d,
-	0xb0, 0x06, 0x46, 0x1f, 0x42, 0x47, 0x96, 0x39, 0x3a, 0xed, 0x31, 0xf1, 0x06, 0xdb, 0x77, 0x26,
-	0x0d, 0xc5, 0x89, 0x2a, 0x9a, 0x95, 0x39, 0x32, 0x5d, 0x44, 0x9f, 0x83, 0x59, 0x54, 0xda, 0x0a,
-	0x74, 0x0c, 0xcd, 0x19, 0xae, 0x70, 0xd8, 0xa2, 0x82, 0x2d, 0x97, 0xeb, 0x71, 0xbe, 0x94, 0x22,
-	0xf2, 0x79, 0xfc, 0x21, 0xe6, 0xa9, 0xd3, 0x19, 0x13, 0xcf, 0x62, 0x0d, 0x8c, 0x3a, 0xb0, 0x9e,
-	0xf3, 0x32, 0xce, 0x78, 0xe0, 0xac, 0xe9, 0xf4, 0x3c, 0x74, 0x7f, 0x12, 0xb0, 0x2a, 0x73, 0x22,
-	0xcf, 0x52, 0x81, 0xf4, 0x36, 0x74, 0xe5, 0xb2, 0xaf, 0x3a, 0xfa, 0x3f, 0x47, 0xf7, 0x61, 0xc3,
-	0xcf, 0x92, 0x3c, 0x46, 0x89, 0x81, 0xf6, 0xd3, 0x63, 0x0b, 0x40, 0xb5, 0xc0, 0xa2, 0x38, 0x12,
-	0xa1, 0x9e, 0x75, 0x83, 0xd5, 0x11, 0x1d, 0x42, 0x4f, 0x60, 0x1a, 0xcc, 0xa2, 0x04, 0xf5, 0x98,
-	0x06, 0xbb, 0x8e, 0x97, 0x1d, 0x74, 0x1b, 0x0e, 0xe8, 0x2d, 0x58, 0x13, 0x92, 0x4b, 0xe1, 0xac,
-	0x6b, 0xbc, 0x0a, 0xdc, 0x4b, 0x02, 0x03, 0x45, 0x3c, 0xc6, 0x22, 0x42, 0x71, 0x18, 0x09, 0x49,
-	0x77, 0x61, 0x20, 0x1b, 0x88, 0x43, 0xc6, 0x86, 0x67, 0x6e, 0xdf, 0x5d, 0xf5, 0x72, 0x5d, 0xc4,
-	0x56, 0x08, 0x74, 0x0f, 0xfa, 0xa7, 0x11, 0xc6, 0xc1, 0x6e, 0x18, 0x1e, 0xe7, 0xe8, 0x0b, 0xa7,
-	0xad, 0x15, 0x1e, 0xac, 0x28, 0xec, 0x86, 0x61, 0x81, 0x21, 0x97, 0x59, 0xa1, 0xaa, 0x58, 0x93,
-	0xe3, 0x7e, 0x23, 0x00, 0x8b, 0x1e, 0x94, 0x42, 0x47, 0xf2, 0x50, 0xd4, 0xd7, 0xad, 0xcf, 0xf4,
-	0x05, 0x74, 0x35, 0x67, 0xde, 0x60, 0xf3, 0x9f, 0x23, 0x4e, 0x5e, 0xeb, 0xba, 0x57, 0xa9, 0x2c,
-	0x4a, 0x56, 0x93, 0x86, 0x4f, 0xc1, 0x5c, 0x82, 0xa9, 0x0d, 0xc6, 0x19, 0x96, 0x75, 0x03, 0x75,
-	0x54, 0x77, 0x76, 0xc1, 0xe3, 0xf3, 0xea, 0x6f, 0x5a, 0xac, 0x0a, 0x9e, 0xb5, 0x9f, 0x10, 0x37,
-	0x87, 0x41, 0x73, 0x7a, 0xf5, 0x2f, 0xb5, 0xec, 0x94, 0x27, 0x58, 0x6b, 0x2c, 0x80, 0xeb, 0xec,
-	0x6c, 0xbe, 0x1b, 0x7d, 0xb6, 0x00, 0xd4, 0x6e, 0x9e, 0x9e, 0xa7, 0xbe, 0x3a, 0xeb, 0x0b, 0x37,
-	0xc6, 0x86, 0xd7, 0x67, 0x0d, 0x6c, 0x6b, 0x07, 0x7a, 0xf3, 0xed, 0xa1, 0x26, 0xac, 0x7f, 0x9c,
-	0xbe, 0x9b, 0xbe, 0xff, 0x34, 0xb5, 0x5b, 0xd4, 0x06, 0xeb, 0x20, 0x95, 0x58, 0x24, 0x18, 0x44,
-	0x5c, 0xa2, 0x4d, 0x68, 0x0f, 0x3a, 0x87, 0xc8, 0x4f, 0xed, 0xf6, 0xd6, 0x26, 0x98, 0x4b, 0x0f,
-	0x42, 0x25, 0xf6, 0xb9, 0xe4, 0x76, 0x8b, 0x5a, 0xd0, 0x3b, 0x42, 0xc9, 0x03, 0x15, 0x91, 0xed,
-	0x93, 0xea, 0xe5, 0x1e, 0x63, 0x71, 0x11, 0xf9, 0x48, 0xdf, 0x40, 0xf7, 0x2d, 0x4f, 0x83, 0x18,
-	0xe9, 0xf0, 0x86, 0xfd, 0xad, 0x05, 0x87, 0xf7, 0x6e, 0xcc, 0x55, 0xcf, 0xc3, 0x6d, 0x79, 0xe4,
-	0x11, 0x79, 0x69, 0x7f, 0xbf, 0x1a, 0x91, 0x1f, 0x57, 0x23, 0xf2, 0xfb, 0x6a, 0x44, 0x2e, 0xff,
-	0x8c, 0x5a, 0x9f, 0xbb, 0x9a, 0xb3, 0xf3, 0x37, 0x00, 0x00, 0xff, 0xff, 0xdc, 0xb3, 0x4d, 0x15,
-	0x4a, 0x04, 0x00, 0x00,
+	// 739 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xdd, 0x6e, 0xeb, 0x44,
+	0x10, 0xce, 0x3a, 0xa9, 0x93, 0x4c, 0x9c, 0xc8, 0x5a, 0x1d, 0x81, 0x09, 0x10, 0x45, 0x96, 0x90,
+	0xa2, 0x83, 0x14, 0x71, 0x5a, 0x21, 0xf1, 0x2f, 0x95, 0xe6, 0x00, 0x11, 0xa7, 0x01, 0x6d, 0x42,
+	0xb9, 0xde, 0xda, 0x53, 0x63, 0xd5, 0x7f, 0xf5, 0x6e, 0x2a, 0xf2, 0x06, 0x3c, 0x02, 0x8f, 0xc4,
+	0x25, 0x5c, 0x20, 0x71, 0x89, 0xca, 0x43, 0x70, 0x8b, 0x76, 0x6d, 0xc7, 0x71, 0xd4, 0x0a, 0xe9,
+	0x5c, 0xd9, 0xf3, 0xcd, 0xcf, 0xce, 0x7c, 0xfb, 0xed, 0x80, 0xe5, 0xa5, 0x71, 0x9c, 0x26, 0xf3,
+	0x2c, 0x4f, 0x65, 0x4a, 0x87, 0xfa, 0x73, 0xa1, 0xa1, 0xab, 0x17, 0xee, 0xbf, 0x06, 0x0c, 0x36,
+	0x5c, 0xdc, 0x32, 0xbc, 0xdb, 0xa2, 0x90, 0xd4, 0x05, 0x2b, 0xe3, 0x39, 0x26, 0x52, 0x81, 0xcb,
+	0x85, 0x43, 0xa6, 0x64, 0xd6, 0x67, 0x0d, 0x8c, 0xbe, 0x0f, 0x1d, 0xb9, 0xcb, 0xd0, 0x31, 0xa6,
+	0x64, 0x36, 0x3a, 0x7d, 0x73, 0xde, 0xa8, 0x38, 0x57, 0x41, 0x9b, 0x5d, 0x86, 0x4c, 0x07, 0xd1,
+	0xcf, 0x60, 0x90, 0x17, 0xb5, 0x15, 0xe8, 0xb4, 0x75, 0xce, 0xf8, 0x28, 0x87, 0xd5, 0x11, 0xec,
+	0x30, 0x5c, 0xb7, 0xf3, 0xd3, 0x4e, 0x84, 0x1e, 0x8f, 0xbe, 0x8f, 0x78, 0xe2, 0x74, 0xa6, 0x64,
+	0x66, 0xb1, 0x06, 0x46, 0x1d, 0xe8, 0x66, 0x7c, 0x17, 0xa5, 0xdc, 0x77, 0x4e, 0xb4, 0xbb, 0x32,
+	0x95, 0xe7, 0x6e, 0x8b, 0xf9, 0x6e, 0xb9, 0x70, 0x4c, 0x3d, 0x47, 0x65, 0xd2, 0x05, 0xf4, 0x62,
+	0x94, 0xdc, 0xe7, 0x92, 0x3b, 0xdd, 0x69, 0x7b, 0x36, 0x38, 0x9d, 0x3d, 0x32, 0x46, 0xd9, 0xd6,
+	0xfc, 0xb2, 0x0c, 0x7d, 0x99, 0xc8, 0x7c, 0xc7, 0xf6, 0x99, 0xe3, 0x4f, 0x61, 0xd8, 0x70, 0x51,
+	0x1b, 0xda, 0xb7, 0xb8, 0x2b, 0x49, 0x53, 0xbf, 0xf4, 0x19, 0x9c, 0xdc, 0xf3, 0x68, 0x5b, 0x90,
+	0xd5, 0x67, 0x85, 0xf1, 0x89, 0xf1, 0x11, 0x71, 0xff, 0x24, 0x60, 0x15, 0x87, 0x88, 0x2c, 0x4d,
+	0x04, 0xd2, 0x37, 0xc0, 0x94, 0x87, 0xa4, 0x9b, 0xf2, 0x35, 0xe8, 0x7e, 0x07, 0xfa, 0x5e, 0x1a,
+	0x67, 0x11, 0x4a, 0xf4, 0x35, 0xd9, 0x3d, 0x56, 0x03, 0xea, 0x08, 0xcc, 0xf3, 0x4b, 0x11, 0x68,
+	0x22, 0xfb, 0xac, 0xb4, 0xe8, 0x18, 0x7a, 0x02, 0x13, 0x7f, 0x13, 0xc6, 0xa8, 0x39, 0x6c, 0xb3,
+	0xbd, 0x7d, 0x48, 0xaf, 0xd9, 0xa4, 0xf7, 0x19, 0x9c, 0x08, 0xc9, 0xa5, 0x70, 0xba, 0x1a, 0x2f,
+	0x0c, 0xf7, 0x2f, 0x02, 0x23, 0x95, 0xb8, 0xc6, 0x3c, 0x44, 0xf1, 0x2a, 0x14, 0x92, 0x9e, 0xc3,
+	0x48, 0x36, 0x10, 0x87, 0x68, 0xce, 0xdf, 0x3a, 0x9e, 0x65, 0x1f, 0xc4, 0x8e, 0x12, 0xe8, 0x05,
+	0x0c, 0x6f, 0x42, 0x8c, 0xfc, 0xf3, 0x20, 0x58, 0x67, 0xe8, 0x09, 0xc7, 0xd0, 0x15, 0xde, 0x3d,
+	0xaa, 0x70, 0x1e, 0x04, 0x39, 0x06, 0x5c, 0xa6, 0xb9, 0x8a, 0x62, 0xcd, 0x1c, 0xfa, 0x05, 0x58,
+	0x42, 0xe6, 0x61, 0x12, 0x5c, 0xa9, 0x5b, 0x10, 0x4e, 0x5b, 0xd7, 0x38, 0x16, 0xe3, 0xba, 0x0e,
+	0x61, 0x8d, 0x78, 0xf7, 0x0f, 0x02, 0x50, 0xf7, 0x48, 0x29, 0x74, 0x24, 0x0f, 0x44, 0x79, 0x5d,
+	0xfa, 0x9f, 0x7e, 0x0e, 0xa6, 0x3e, 0xb3, 0x6a, 0xf0, 0xbd, 0x27, 0x47, 0x9c, 0x7f, 0xa5, 0xe3,
+	0x0a, 0x4d, 0x95, 0x49, 0xf4, 0x43, 0xe8, 0xe3, 0xcf, 0x18, 0x67, 0x11, 0xcf, 0xab, 0xf6, 0x8e,
+	0x2f, 0xfc, 0x65, 0xe9, 0x67, 0x75, 0xe4, 0xf8, 0x63, 0x18, 0x1c, 0x54, 0xfb, 0x3f, 0x19, 0x5a,
+	0x87, 0x32, 0xcc, 0x60, 0xd4, 0x24, 0x4d, 0x49, 0x48, 0x77, 0xb3, 0xe2, 0x31, 0x96, 0x35, 0x6a,
+	0x60, 0xef, 0xdd, 0x54, 0x92, 0x1c, 0xb2, 0x1a, 0x50, 0xef, 0xf5, 0x66, 0x9b, 0x78, 0xea, 0x5f,
+	0xdf, 0xb3, 0x1a, 0x61, 0xc8, 0x1a, 0x98, 0xfb, 0x0b, 0x81, 0x5e, 0x35, 0x84, 0x52, 0x97, 0xcc,
+	0xb9, 0x87, 0x7b, 0xd5, 0x57, 0xa6, 0xd2, 0xaa, 0xc8, 0x78, 0xb2, 0x5c, 0x94, 0x4f, 0xa7, 0xb4,
+	0xea, 0x51, 0x94, 0xba, 0x49, 0x39, 0x8a, 0x6a, 0x4b, 0x29, 0x46, 0x48, 0x1e, 0x67, 0x5a, 0xdc,
+	0x6d, 0x56, 0x03, 0xaa, 0xd6, 0xf5, 0xd6, 0xbb, 0x45, 0xa9, 0xd5, 0x4d, 0x58, 0x69, 0xb9, 0x67,
+	0x30, 0x38, 0xb8, 0x6d, 0x3a, 0x02, 0x23, 0xf4, 0x75, 0x1f, 0x1d, 0x66, 0x84, 0xfe, 0xe3, 0x8f,
+	0xf7, 0xf9, 0x19, 0xf4, 0xaa, 0x47, 0x47, 0x07, 0xd0, 0xfd, 0x61, 0xf5, 0xed, 0xea, 0xbb, 0x1f,
+	0x57, 0x76, 0x8b, 0xda, 0x60, 0x2d, 0x13, 0x89, 0x79, 0x8c, 0x7e, 0xc8, 0x25, 0xda, 0x84, 0xf6,
+	0xa0, 0xf3, 0x0a, 0xf9, 0x8d, 0x6d, 0x3c, 0x7f, 0x01, 0x83, 0x83, 0x25, 0xa7, 0x1c, 0x0b, 0x2e,
+	0xb9, 0xdd, 0xa2, 0x16, 0xf4, 0xaa, 0x1d, 0x62, 0x13, 0x0a, 0x60, 0x5e, 0xf0, 0xc4, 0xc3, 0xc8,
+	0x36, 0x4e, 0xaf, 0x8a, 0xcd, 0xbc, 0xc6, 0xfc, 0x3e, 0xf4, 0x90, 0x7e, 0x0d, 0xe6, 0x37, 0x3c,
+	0xf1, 0x23, 0xa4, 0xe3, 0xa7, 0x57, 0xd5, 0xf8, 0xed, 0x47, 0x7d, 0xc5, 0x86, 0x71, 0x5b, 0x33,
+	0xf2, 0x01, 0xf9, 0xd2, 0xfe, 0xed, 0x61, 0x42, 0x7e, 0x7f, 0x98, 0x90, 0xbf, 0x1f, 0x26, 0xe4,
+	0xd7, 0x7f, 0x26, 0xad, 0x6b, 0x53, 0xe7, 0x9c, 0xfd, 0x37, 0x00, 0x44, 0xd1, 0xdf, 0xb7, 0x2a,
+	0x06, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -632,6 +816,32 @@ func (m *TaskRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Metadata) > 0 {
+		for k := range m.Metadata {
+			v := m.Metadata[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintCommon(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintCommon(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintCommon(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
+	if len(m.QueryID) > 0 {
+		i -= len(m.QueryID)
+		copy(dAtA[i:], m.QueryID)
+		i = encodeVarintCommon(dAtA, i, uint64(len(m.QueryID)))
+		i--
+		dAtA[i] = 0x32
+	}
 	if len(m.Payload) > 0 {
 		i -= len(m.Payload)
 		copy(dAtA[i:], m.Payload)
@@ -765,6 +975,20 @@ func (m *TimeSeriesList) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.StringValues) > 0 {
+		for iNdEx := len(m.StringValues) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.StringValues[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintCommon(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if len(m.FieldAggSpecs) > 0 {
 		for iNdEx := len(m.FieldAggSpecs) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -820,6 +1044,20 @@ func (m *TimeSeries) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Exemplars) > 0 {
+		for iNdEx := len(m.Exemplars) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Exemplars[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintCommon(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if len(m.Fields) > 0 {
 		for k := range m.Fields {
 			v := m.Fields[k]
@@ -908,6 +1146,103 @@ func (m *AggregatorSpec) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *Exemplar) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Exemplar) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Exemplar) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Bucket != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Bucket))))
+		i--
+		dAtA[i] = 0x29
+	}
+	if m.Timestamp != 0 {
+		i = encodeVarintCommon(dAtA, i, uint64(m.Timestamp))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Value != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Value))))
+		i--
+		dAtA[i] = 0x19
+	}
+	if len(m.SpanID) > 0 {
+		i -= len(m.SpanID)
+		copy(dAtA[i:], m.SpanID)
+		i = encodeVarintCommon(dAtA, i, uint64(len(m.SpanID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.TraceID) > 0 {
+		i -= len(m.TraceID)
+		copy(dAtA[i:], m.TraceID)
+		i = encodeVarintCommon(dAtA, i, uint64(len(m.TraceID)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *StringValue) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StringValue) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StringValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Value) > 0 {
+		i -= len(m.Value)
+		copy(dAtA[i:], m.Value)
+		i = encodeVarintCommon(dAtA, i, uint64(len(m.Value)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Id != 0 {
+		i = encodeVarintCommon(dAtA, i, uint64(m.Id))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintCommon(dAtA []byte, offset int, v uint64) int {
 	offset -= sovCommon(v)
 	base := offset
@@ -943,6 +1278,18 @@ func (m *TaskRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovCommon(uint64(l))
 	}
+	l = len(m.QueryID)
+	if l > 0 {
+		n += 1 + l + sovCommon(uint64(l))
+	}
+	if len(m.Metadata) > 0 {
+		for k, v := range m.Metadata {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovCommon(uint64(len(k))) + 1 + len(v) + sovCommon(uint64(len(v)))
+			n += mapEntrySize + 1 + sovCommon(uint64(mapEntrySize))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1004,6 +1351,12 @@ func (m *TimeSeriesList) Size() (n int) {
 			n += 1 + l + sovCommon(uint64(l))
 		}
 	}
+	if len(m.StringValues) > 0 {
+		for _, e := range m.StringValues {
+			l = e.Size()
+			n += 1 + l + sovCommon(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1032,6 +1385,12 @@ func (m *TimeSeries) Size() (n int) {
 			n += mapEntrySize + 1 + sovCommon(uint64(mapEntrySize))
 		}
 	}
+	if len(m.Exemplars) > 0 {
+		for _, e := range m.Exemplars {
+			l = e.Size()
+			n += 1 + l + sovCommon(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1064,6 +1423,54 @@ func (m *AggregatorSpec) Size() (n int) {
 	return n
 }
 
+func (m *Exemplar) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TraceID)
+	if l > 0 {
+		n += 1 + l + sovCommon(uint64(l))
+	}
+	l = len(m.SpanID)
+	if l > 0 {
+		n += 1 + l + sovCommon(uint64(l))
+	}
+	if m.Value != 0 {
+		n += 9
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovCommon(uint64(m.Timestamp))
+	}
+	if m.Bucket != 0 {
+		n += 9
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StringValue) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Id != 0 {
+		n += 1 + sovCommon(uint64(m.Id))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovCommon(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func sovCommon(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -1237,7 +1644,166 @@ func (m *TaskRequest) Unmarshal(dAtA []byte) error {
 				m.Payload = []byte{}
 			}
 			iNdEx = postIndex
-		default:
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueryID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCommon
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.QueryID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCommon
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metadata == nil {
+				m.Metadata = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCommon
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowCommon
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthCommon
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthCommon
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowCommon
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthCommon
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthCommon
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipCommon(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthCommon
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Metadata[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
 			iNdEx = preIndex
 			skippy, err := skipCommon(dAtA[iNdEx:])
 			if err != nil {
@@ -1597,6 +2163,40 @@ func (m *TimeSeriesList) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StringValues", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCommon
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StringValues = append(m.StringValues, &StringValue{})
+			if err := m.StringValues[len(m.StringValues)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCommon(dAtA[iNdEx:])
@@ -1808,6 +2408,40 @@ func (m *TimeSeries) Unmarshal(dAtA []byte) error {
 			}
 			m.Fields[mapkey] = mapvalue
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Exemplars", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCommon
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Exemplars = append(m.Exemplars, &Exemplar{})
+			if err := m.Exemplars[len(m.Exemplars)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCommon(dAtA[iNdEx:])
@@ -2008,6 +2642,264 @@ func (m *AggregatorSpec) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Exemplar) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCommon
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Exemplar: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Exemplar: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCommon
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TraceID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SpanID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCommon
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SpanID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Value = float64(math.Float64frombits(v))
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Bucket", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Bucket = float64(math.Float64frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCommon(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StringValue) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCommon
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StringValue: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StringValue: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			m.Id = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Id |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCommon
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCommon(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthCommon
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipCommon(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
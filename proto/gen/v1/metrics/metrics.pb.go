@@ -47,6 +47,10 @@ const (
 	SimpleFieldType_GAUGE              SimpleFieldType = 1
 	SimpleFieldType_DELTA_SUM          SimpleFieldType = 2
 	SimpleFieldType_CUMULATIVE_SUM     SimpleFieldType = 3
+	SimpleFieldType_FIRST              SimpleFieldType = 4
+	SimpleFieldType_LAST               SimpleFieldType = 5
+	SimpleFieldType_COUNT              SimpleFieldType = 6
+	SimpleFieldType_STRING             SimpleFieldType = 7
 )
 
 var SimpleFieldType_name = map[int32]string{
@@ -54,6 +58,10 @@ var SimpleFieldType_name = map[int32]string{
 	1: "GAUGE",
 	2: "DELTA_SUM",
 	3: "CUMULATIVE_SUM",
+	4: "FIRST",
+	5: "LAST",
+	6: "COUNT",
+	7: "STRING",
 }
 
 var SimpleFieldType_value = map[string]int32{
@@ -61,6 +69,10 @@ var SimpleFieldType_value = map[string]int32{
 	"GAUGE":              1,
 	"DELTA_SUM":          2,
 	"CUMULATIVE_SUM":     3,
+	"FIRST":              4,
+	"LAST":               5,
+	"COUNT":              6,
+	"STRING":             7,
 }
 
 func (x SimpleFieldType) String() string {
@@ -99,8 +111,41 @@ func (CompoundFieldType) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_6039342a2ba47b72, []int{1}
 }
 
+// SimpleFieldValueType is the type of simple field value written by user,
+// int/bool values are stored natively without float64 conversion.
+type SimpleFieldValueType int32
+
+const (
+	SimpleFieldValueType_FLOAT_VALUE SimpleFieldValueType = 0
+	SimpleFieldValueType_INT_VALUE   SimpleFieldValueType = 1
+	SimpleFieldValueType_BOOL_VALUE  SimpleFieldValueType = 2
+)
+
+var SimpleFieldValueType_name = map[int32]string{
+	0: "FLOAT_VALUE",
+	1: "INT_VALUE",
+	2: "BOOL_VALUE",
+}
+
+var SimpleFieldValueType_value = map[string]int32{
+	"FLOAT_VALUE": 0,
+	"INT_VALUE":   1,
+	"BOOL_VALUE":  2,
+}
+
+func (x SimpleFieldValueType) String() string {
+	return proto.EnumName(SimpleFieldValueType_name, int32(x))
+}
+
+func (SimpleFieldValueType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_6039342a2ba47b72, []int{2}
+}
+
 type MetricList struct {
 	Metrics              []*Metric `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	ProducerID           string    `protobuf:"bytes,3,opt,name=producerID,proto3" json:"producerID,omitempty"`
+	Sequence             int64     `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	ProducerEpoch        int64     `protobuf:"varint,5,opt,name=producerEpoch,proto3" json:"producerEpoch,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
 	XXX_unrecognized     []byte    `json:"-"`
 	XXX_sizecache        int32     `json:"-"`
@@ -146,6 +191,27 @@ func (m *MetricList) GetMetrics() []*Metric {
 	return nil
 }
 
+func (m *MetricList) GetProducerID() string {
+	if m != nil {
+		return m.ProducerID
+	}
+	return ""
+}
+
+func (m *MetricList) GetSequence() int64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *MetricList) GetProducerEpoch() int64 {
+	if m != nil {
+		return m.ProducerEpoch
+	}
+	return 0
+}
+
 // Defines a Metric which has one or more timeseries.  The following is a
 // brief summary of the Metric data model.  For more details, see:
 //
@@ -283,13 +349,18 @@ func (m *Metric) GetCompoundField() *CompoundField {
 }
 
 type SimpleField struct {
-	Name                 string          `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Type                 SimpleFieldType `protobuf:"varint,2,opt,name=type,proto3,enum=protoMetricsV1.SimpleFieldType" json:"type,omitempty"`
-	Exemplars            []*Exemplar     `protobuf:"bytes,3,rep,name=exemplars,proto3" json:"exemplars,omitempty"`
-	Value                float64         `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	Name        string          `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type        SimpleFieldType `protobuf:"varint,2,opt,name=type,proto3,enum=protoMetricsV1.SimpleFieldType" json:"type,omitempty"`
+	Exemplars   []*Exemplar     `protobuf:"bytes,3,rep,name=exemplars,proto3" json:"exemplars,omitempty"`
+	Value       float64         `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	StringValue string          `protobuf:"bytes,5,opt,name=string_value,json=stringValue,proto3" json:"string_value,omitempty"`
+	// value type of field, int/bool value is kept in int_value, value is its float64 approximation
+	ValueType SimpleFieldValueType `protobuf:"varint,6,opt,name=value_type,json=valueType,proto3,enum=protoMetricsV1.SimpleFieldValueType" json:"value_type,omitempty"`
+	// only used by int/bool field, bool is 0/1
+	IntValue             int64    `protobuf:"varint,7,opt,name=int_value,json=intValue,proto3" json:"int_value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SimpleField) Reset()         { *m = SimpleField{} }
@@ -353,6 +424,27 @@ func (m *SimpleField) GetValue() float64 {
 	return 0
 }
 
+func (m *SimpleField) GetStringValue() string {
+	if m != nil {
+		return m.StringValue
+	}
+	return ""
+}
+
+func (m *SimpleField) GetValueType() SimpleFieldValueType {
+	if m != nil {
+		return m.ValueType
+	}
+	return SimpleFieldValueType_FLOAT_VALUE
+}
+
+func (m *SimpleField) GetIntValue() int64 {
+	if m != nil {
+		return m.IntValue
+	}
+	return 0
+}
+
 // CompoundData is compound data used for histogram field.
 type CompoundField struct {
 	Type      CompoundFieldType `protobuf:"varint,1,opt,name=type,proto3,enum=protoMetricsV1.CompoundFieldType" json:"type,omitempty"`
@@ -537,6 +629,8 @@ type Exemplar struct {
 	TraceId []byte `protobuf:"bytes,2,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
 	// Duration of the exemplar span.
 	Duration             int64    `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	Value                float64  `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp            int64    `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -596,9 +690,24 @@ func (m *Exemplar) GetDuration() int64 {
 	return 0
 }
 
+func (m *Exemplar) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *Exemplar) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("protoMetricsV1.SimpleFieldType", SimpleFieldType_name, SimpleFieldType_value)
 	proto.RegisterEnum("protoMetricsV1.CompoundFieldType", CompoundFieldType_name, CompoundFieldType_value)
+	proto.RegisterEnum("protoMetricsV1.SimpleFieldValueType", SimpleFieldValueType_name, SimpleFieldValueType_value)
 	proto.RegisterType((*MetricList)(nil), "protoMetricsV1.MetricList")
 	proto.RegisterType((*Metric)(nil), "protoMetricsV1.Metric")
 	proto.RegisterType((*SimpleField)(nil), "protoMetricsV1.SimpleField")
@@ -610,47 +719,57 @@ func init() {
 func init() { proto.RegisterFile("metrics.proto", fileDescriptor_6039342a2ba47b72) }
 
 var fileDescriptor_6039342a2ba47b72 = []byte{
-	// 627 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x5d, 0x6e, 0xd3, 0x40,
-	0x10, 0xee, 0xda, 0x69, 0x7e, 0xa6, 0x4d, 0x1a, 0x86, 0xaa, 0x2c, 0x14, 0x82, 0xc9, 0x0b, 0x56,
-	0x85, 0x2a, 0x48, 0x05, 0x8f, 0x88, 0x34, 0x71, 0x5b, 0x8b, 0xa4, 0xad, 0x36, 0x49, 0x91, 0x10,
-	0x52, 0xb4, 0x75, 0x16, 0x6a, 0x11, 0x27, 0x56, 0xd6, 0x41, 0xcd, 0x19, 0xb8, 0x00, 0x07, 0xe0,
-	0x8d, 0x8b, 0xf0, 0xc8, 0x11, 0x50, 0xb9, 0x08, 0xda, 0xb5, 0x53, 0xb7, 0x01, 0x2a, 0x9e, 0x3c,
-	0xdf, 0x37, 0xdf, 0xce, 0x7e, 0x33, 0x3b, 0x86, 0x62, 0x20, 0xa2, 0x89, 0xef, 0xc9, 0xed, 0x70,
-	0x32, 0x8e, 0xc6, 0x58, 0xd2, 0x9f, 0x76, 0xcc, 0x9d, 0x3c, 0xab, 0xbe, 0x04, 0x88, 0x41, 0xcb,
-	0x97, 0x11, 0x3e, 0x85, 0x5c, 0x22, 0xa7, 0x86, 0x65, 0xda, 0x2b, 0xb5, 0x8d, 0xed, 0xeb, 0xfa,
-	0xed, 0x38, 0x62, 0x73, 0x59, 0xf5, 0x9b, 0x01, 0xd9, 0x98, 0xc3, 0xfb, 0x50, 0x18, 0xf1, 0x40,
-	0xc8, 0x90, 0x7b, 0x82, 0x12, 0x8b, 0xd8, 0x05, 0x96, 0x12, 0x88, 0x90, 0x51, 0x80, 0x1a, 0x3a,
-	0xa1, 0x63, 0x75, 0x22, 0xf2, 0x03, 0x21, 0x23, 0x1e, 0x84, 0xd4, 0xb4, 0x88, 0x6d, 0xb2, 0x94,
-	0xc0, 0x27, 0x90, 0x89, 0xf8, 0x07, 0x49, 0x33, 0xda, 0x09, 0x5d, 0x74, 0xf2, 0x5a, 0xcc, 0x4e,
-	0xf8, 0x70, 0x2a, 0x98, 0x56, 0xe1, 0x26, 0x14, 0xd4, 0xb7, 0x7f, 0xc6, 0xe5, 0x19, 0x5d, 0xb6,
-	0x88, 0x9d, 0x61, 0x79, 0x45, 0x1c, 0x70, 0x79, 0x86, 0xaf, 0xa0, 0x28, 0xfd, 0x20, 0x1c, 0x8a,
-	0xfe, 0x7b, 0x5f, 0x0c, 0x07, 0x92, 0x66, 0x75, 0xcd, 0xcd, 0xc5, 0x9a, 0x1d, 0x2d, 0xda, 0x53,
-	0x1a, 0xb6, 0x2a, 0x53, 0x20, 0xb1, 0x09, 0x25, 0x6f, 0x1c, 0x84, 0xe3, 0xe9, 0x68, 0x10, 0xd7,
-	0xa0, 0x39, 0x8b, 0xd8, 0x2b, 0xb5, 0x07, 0x8b, 0x25, 0x1a, 0x89, 0x2a, 0x2e, 0x52, 0xf4, 0xae,
-	0xc2, 0xea, 0x57, 0x02, 0x2b, 0x57, 0xee, 0xb8, 0x1c, 0x0a, 0xb9, 0x32, 0x94, 0x1d, 0xc8, 0x44,
-	0xb3, 0x30, 0x1e, 0x54, 0xa9, 0xf6, 0xf0, 0x06, 0x8b, 0xdd, 0x59, 0xa8, 0xba, 0x9f, 0x85, 0x02,
-	0x5f, 0x40, 0x41, 0x9c, 0x8b, 0x20, 0x1c, 0xf2, 0x89, 0xa4, 0xe6, 0xdf, 0x07, 0xe6, 0x24, 0x02,
-	0x96, 0x4a, 0x71, 0x1d, 0x96, 0x3f, 0xa9, 0x21, 0xd2, 0x8c, 0x45, 0x6c, 0xc2, 0x62, 0x50, 0xfd,
-	0x6c, 0x40, 0xf1, 0x5a, 0x1f, 0xf8, 0x3c, 0x31, 0x45, 0xb4, 0xa9, 0x47, 0x37, 0x36, 0xfd, 0x2f,
-	0x5b, 0xc6, 0xff, 0xdb, 0x2a, 0x83, 0x19, 0xf8, 0x23, 0xbd, 0x12, 0x84, 0xa9, 0x50, 0x33, 0xfc,
-	0x3c, 0xb1, 0xa9, 0x42, 0xc5, 0xc8, 0x69, 0xa0, 0x9f, 0x9a, 0x30, 0x15, 0xaa, 0x66, 0xbc, 0xf1,
-	0x74, 0x14, 0xd1, 0x6c, 0xdc, 0x8c, 0x06, 0xf8, 0x18, 0xd6, 0xc4, 0x79, 0x38, 0xf4, 0x3d, 0x3f,
-	0xea, 0x9f, 0x2a, 0x93, 0x92, 0xe6, 0x2c, 0xd3, 0x26, 0xac, 0x34, 0xa7, 0x77, 0x35, 0x8b, 0x1b,
-	0x90, 0xd5, 0xed, 0x4b, 0x9a, 0xd7, 0xf9, 0x04, 0x55, 0x6b, 0x90, 0x9f, 0xef, 0x9a, 0xba, 0xf4,
-	0xa3, 0x98, 0x25, 0xef, 0xa5, 0xc2, 0x74, 0x82, 0xf1, 0x62, 0x27, 0x13, 0x7c, 0x0b, 0xf9, 0x79,
-	0x5f, 0x78, 0x07, 0x72, 0x32, 0xe4, 0xa3, 0xbe, 0x3f, 0xd0, 0xe7, 0x56, 0x59, 0x56, 0x41, 0x77,
-	0x80, 0x77, 0x21, 0x1f, 0x4d, 0xb8, 0x27, 0x54, 0xc6, 0xd0, 0x99, 0x9c, 0xc6, 0xee, 0x00, 0xef,
-	0x41, 0x7e, 0x30, 0x9d, 0xf0, 0xc8, 0x1f, 0x8f, 0x92, 0x1f, 0xe3, 0x12, 0x6f, 0xbd, 0x81, 0xb5,
-	0x85, 0x25, 0xc0, 0x0d, 0xc0, 0x8e, 0xdb, 0x3e, 0x6e, 0x39, 0xfd, 0xde, 0x61, 0xe7, 0xd8, 0x69,
-	0xb8, 0x7b, 0xae, 0xd3, 0x2c, 0x2f, 0x61, 0x01, 0x96, 0xf7, 0xeb, 0xbd, 0x7d, 0xa7, 0x4c, 0xb0,
-	0x08, 0x85, 0xa6, 0xd3, 0xea, 0xd6, 0xfb, 0x9d, 0x5e, 0xbb, 0x6c, 0x20, 0x42, 0xa9, 0xd1, 0x6b,
-	0xf7, 0x5a, 0xf5, 0xae, 0x7b, 0xe2, 0x68, 0xce, 0xdc, 0x7a, 0x07, 0xb7, 0xfe, 0x78, 0x48, 0xa4,
-	0xb0, 0xde, 0x38, 0x6a, 0x1f, 0x1f, 0xf5, 0x0e, 0x9b, 0x0b, 0xc5, 0x6f, 0xc3, 0x5a, 0x5c, 0xf1,
-	0xc0, 0xed, 0x74, 0x8f, 0xf6, 0x59, 0xbd, 0x5d, 0x26, 0x5a, 0x9e, 0xd6, 0x4d, 0x33, 0xc6, 0x6e,
-	0xf9, 0xfb, 0x45, 0x85, 0xfc, 0xb8, 0xa8, 0x90, 0x9f, 0x17, 0x15, 0xf2, 0xe5, 0x57, 0x65, 0xe9,
-	0x34, 0xab, 0x37, 0x61, 0xe7, 0x77, 0x00, 0x00, 0x00, 0xff, 0xff, 0x14, 0xc5, 0x14, 0x49, 0xa3,
-	0x04, 0x00, 0x00,
+	// 795 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcf, 0x6f, 0xe3, 0x44,
+	0x14, 0xee, 0x38, 0xbf, 0x5f, 0x9a, 0xd4, 0x3c, 0xaa, 0x62, 0x58, 0x08, 0xd9, 0x68, 0x25, 0xa2,
+	0x0a, 0x55, 0xd0, 0x15, 0x9c, 0x49, 0x13, 0xa7, 0x6b, 0x91, 0x1f, 0xd5, 0xc4, 0xe9, 0x09, 0xc9,
+	0xf2, 0x3a, 0xc3, 0xd6, 0x22, 0x76, 0x4c, 0xc6, 0x59, 0x35, 0x67, 0x8e, 0x48, 0x9c, 0x39, 0x72,
+	0x45, 0xfc, 0x23, 0x1c, 0xf9, 0x13, 0x50, 0xf9, 0x47, 0xd0, 0xbc, 0x71, 0x9a, 0x26, 0x6c, 0x2b,
+	0x4e, 0x7e, 0xdf, 0xf7, 0xbe, 0x99, 0xf7, 0xe6, 0x9b, 0x37, 0x86, 0x5a, 0x24, 0xd2, 0x65, 0x18,
+	0xc8, 0xb3, 0x64, 0xb9, 0x48, 0x17, 0x58, 0xa7, 0xcf, 0x50, 0x73, 0xd7, 0x5f, 0xb6, 0x7e, 0x63,
+	0x00, 0x1a, 0x0d, 0x42, 0x99, 0xe2, 0x17, 0x50, 0xca, 0xf4, 0x96, 0xd1, 0xcc, 0xb5, 0xab, 0xe7,
+	0x27, 0x67, 0xbb, 0x0b, 0xce, 0x74, 0xc4, 0x37, 0x32, 0x6c, 0x00, 0x24, 0xcb, 0xc5, 0x6c, 0x15,
+	0x88, 0xa5, 0xd3, 0xb3, 0x72, 0x4d, 0xd6, 0xae, 0xf0, 0x07, 0x0c, 0x7e, 0x04, 0x65, 0x29, 0x7e,
+	0x5c, 0x89, 0x38, 0x10, 0x56, 0xbe, 0xc9, 0xda, 0x39, 0x7e, 0x8f, 0xf1, 0x05, 0xd4, 0x36, 0x4a,
+	0x3b, 0x59, 0x04, 0x37, 0x56, 0x81, 0x04, 0xbb, 0x64, 0xeb, 0x0f, 0x03, 0x8a, 0xba, 0x2a, 0x7e,
+	0x0c, 0x95, 0xd8, 0x8f, 0x84, 0x4c, 0xfc, 0x40, 0x58, 0x8c, 0x6a, 0x6d, 0x09, 0x44, 0xc8, 0x2b,
+	0x60, 0x19, 0x94, 0xa0, 0x58, 0xad, 0x48, 0xc3, 0x48, 0xc8, 0xd4, 0x8f, 0x12, 0xea, 0x2e, 0xc7,
+	0xb7, 0x04, 0x7e, 0x0e, 0xf9, 0xd4, 0x7f, 0x23, 0xad, 0x3c, 0x9d, 0xd5, 0xda, 0x3f, 0xeb, 0xb7,
+	0x62, 0x7d, 0xed, 0xcf, 0x57, 0x82, 0x93, 0x0a, 0x9f, 0x41, 0x45, 0x7d, 0xbd, 0x1b, 0x5f, 0xea,
+	0x56, 0xf3, 0xbc, 0xac, 0x88, 0x57, 0xbe, 0xbc, 0xc1, 0x6f, 0xa0, 0x26, 0xc3, 0x28, 0x99, 0x0b,
+	0xef, 0xfb, 0x50, 0xcc, 0x67, 0xd2, 0x2a, 0xd2, 0x9e, 0xcf, 0xf6, 0xf7, 0x9c, 0x90, 0xa8, 0xaf,
+	0x34, 0xfc, 0x50, 0x6e, 0x81, 0xc4, 0x1e, 0xd4, 0x83, 0x45, 0x94, 0x2c, 0x56, 0xf1, 0x4c, 0xef,
+	0x61, 0x95, 0x9a, 0xac, 0x5d, 0x3d, 0xff, 0x64, 0x7f, 0x8b, 0x6e, 0xa6, 0xd2, 0x9b, 0xd4, 0x82,
+	0x87, 0xb0, 0xf5, 0xbb, 0x01, 0xd5, 0x07, 0x35, 0xee, 0x4d, 0x61, 0x0f, 0x4c, 0x79, 0x09, 0xf9,
+	0x74, 0x9d, 0x68, 0xa3, 0xea, 0xe7, 0x9f, 0x3e, 0xd1, 0xa2, 0xbb, 0x4e, 0xd4, 0xe9, 0xd7, 0x89,
+	0xc0, 0xaf, 0xa1, 0x22, 0x6e, 0x45, 0x94, 0xcc, 0xfd, 0xa5, 0xb4, 0x72, 0xef, 0x36, 0xcc, 0xce,
+	0x04, 0x7c, 0x2b, 0xc5, 0x63, 0x28, 0xbc, 0x55, 0x26, 0xd2, 0xed, 0x33, 0xae, 0x01, 0x3e, 0x87,
+	0x43, 0x99, 0x2e, 0xc3, 0xf8, 0x8d, 0xa7, 0x93, 0x05, 0x6a, 0xaf, 0xaa, 0x39, 0x32, 0x1d, 0xbb,
+	0x00, 0x94, 0xf3, 0xa8, 0xd7, 0x22, 0xf5, 0xfa, 0xe2, 0x89, 0x5e, 0x69, 0x15, 0x35, 0x5c, 0x79,
+	0xbb, 0x09, 0xd5, 0x9d, 0x85, 0x71, 0x9a, 0x15, 0x29, 0xe9, 0xf9, 0x0b, 0xe3, 0x94, 0xb4, 0xad,
+	0x9f, 0x0d, 0xa8, 0xed, 0x98, 0x89, 0x5f, 0x65, 0xce, 0x30, 0xaa, 0xf6, 0xfc, 0x49, 0xe7, 0x1f,
+	0xf3, 0xc6, 0xf8, 0xff, 0xde, 0x98, 0x90, 0x8b, 0xc2, 0x98, 0xe6, 0x92, 0x71, 0x15, 0x12, 0xe3,
+	0xdf, 0x66, 0x5e, 0xa9, 0x50, 0x31, 0x72, 0x15, 0x91, 0x41, 0x8c, 0xab, 0x50, 0x39, 0x1a, 0x2c,
+	0x56, 0x71, 0x4a, 0x9e, 0x30, 0xae, 0x01, 0x7e, 0x06, 0x47, 0xe2, 0x36, 0x99, 0x87, 0x41, 0x98,
+	0x7a, 0xaf, 0x55, 0x93, 0xd2, 0x2a, 0x35, 0x73, 0x6d, 0xc6, 0xeb, 0x1b, 0xfa, 0x82, 0x58, 0x3c,
+	0x81, 0x22, 0xd9, 0x21, 0xad, 0x32, 0xe5, 0x33, 0xd4, 0x3a, 0x87, 0xf2, 0x66, 0xe0, 0x55, 0xd1,
+	0x1f, 0xc4, 0x3a, 0x1b, 0x1a, 0x15, 0x6e, 0xaf, 0x51, 0xbf, 0x2e, 0x0d, 0x5a, 0xbf, 0x30, 0x28,
+	0x6f, 0x0e, 0x86, 0x1f, 0x40, 0x49, 0x26, 0x7e, 0xec, 0x85, 0x33, 0x5a, 0x78, 0xc8, 0x8b, 0x0a,
+	0x3a, 0x33, 0xfc, 0x10, 0xca, 0xe9, 0xd2, 0x0f, 0x84, 0xca, 0x18, 0x94, 0x29, 0x11, 0x76, 0x66,
+	0xea, 0xf7, 0x30, 0x5b, 0x2d, 0xfd, 0x34, 0x5c, 0xc4, 0xd9, 0xf3, 0xbc, 0xc7, 0x8f, 0x4c, 0xce,
+	0xce, 0x8b, 0x2e, 0xec, 0xbd, 0xe8, 0xd3, 0x9f, 0x18, 0x1c, 0xed, 0xcd, 0x2f, 0x9e, 0x00, 0x4e,
+	0x9c, 0xe1, 0xd5, 0xc0, 0xf6, 0xa6, 0xa3, 0xc9, 0x95, 0xdd, 0x75, 0xfa, 0x8e, 0xdd, 0x33, 0x0f,
+	0xb0, 0x02, 0x85, 0xcb, 0xce, 0xf4, 0xd2, 0x36, 0x19, 0xd6, 0xa0, 0xd2, 0xb3, 0x07, 0x6e, 0xc7,
+	0x9b, 0x4c, 0x87, 0xa6, 0x81, 0x08, 0xf5, 0xee, 0x74, 0x38, 0x1d, 0x74, 0x5c, 0xe7, 0xda, 0x26,
+	0x2e, 0xa7, 0xd4, 0x7d, 0x87, 0x4f, 0x5c, 0x33, 0x8f, 0x65, 0xc8, 0x0f, 0x3a, 0x13, 0xd7, 0x2c,
+	0x28, 0xb2, 0x3b, 0x9e, 0x8e, 0x5c, 0xb3, 0x88, 0x00, 0xc5, 0x89, 0xcb, 0x9d, 0xd1, 0xa5, 0x59,
+	0x3a, 0xfd, 0x0e, 0xde, 0xfb, 0xcf, 0xa8, 0xa0, 0x05, 0xc7, 0xdd, 0xf1, 0xf0, 0x6a, 0x3c, 0x1d,
+	0xf5, 0xf6, 0x1a, 0x79, 0x1f, 0x8e, 0x74, 0xf5, 0x57, 0xce, 0xc4, 0x1d, 0x5f, 0xf2, 0xce, 0xd0,
+	0x64, 0x24, 0xdf, 0xf6, 0xb0, 0xcd, 0x18, 0xa7, 0x7d, 0x38, 0x7e, 0xd7, 0xd8, 0xe3, 0x11, 0x54,
+	0xfb, 0x83, 0x71, 0xc7, 0xf5, 0xae, 0x3b, 0x83, 0xa9, 0x6d, 0x1e, 0xa8, 0x53, 0x39, 0xa3, 0x0d,
+	0x64, 0x58, 0x07, 0xb8, 0x18, 0x8f, 0x07, 0x19, 0x36, 0x2e, 0xcc, 0x3f, 0xef, 0x1a, 0xec, 0xaf,
+	0xbb, 0x06, 0xfb, 0xfb, 0xae, 0xc1, 0x7e, 0xfd, 0xa7, 0x71, 0xf0, 0xba, 0x48, 0x33, 0xfb, 0xf2,
+	0xdf, 0x01, 0x00, 0x39, 0xda, 0x29, 0x60, 0x35, 0x06, 0x00, 0x00,
 }
 
 func (m *MetricList) Marshal() (dAtA []byte, err error) {
@@ -677,6 +796,23 @@ func (m *MetricList) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.ProducerEpoch != 0 {
+		i = encodeVarintMetrics(dAtA, i, uint64(m.ProducerEpoch))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Sequence != 0 {
+		i = encodeVarintMetrics(dAtA, i, uint64(m.Sequence))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.ProducerID) > 0 {
+		i -= len(m.ProducerID)
+		copy(dAtA[i:], m.ProducerID)
+		i = encodeVarintMetrics(dAtA, i, uint64(len(m.ProducerID)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.Metrics) > 0 {
 		for iNdEx := len(m.Metrics) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -809,6 +945,23 @@ func (m *SimpleField) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.IntValue != 0 {
+		i = encodeVarintMetrics(dAtA, i, uint64(m.IntValue))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.ValueType != 0 {
+		i = encodeVarintMetrics(dAtA, i, uint64(m.ValueType))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.StringValue) > 0 {
+		i -= len(m.StringValue)
+		copy(dAtA[i:], m.StringValue)
+		i = encodeVarintMetrics(dAtA, i, uint64(len(m.StringValue)))
+		i--
+		dAtA[i] = 0x2a
+	}
 	if m.Value != 0 {
 		i -= 8
 		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Value))))
@@ -999,6 +1152,17 @@ func (m *Exemplar) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Timestamp != 0 {
+		i = encodeVarintMetrics(dAtA, i, uint64(m.Timestamp))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Value != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Value))))
+		i--
+		dAtA[i] = 0x21
+	}
 	if m.Duration != 0 {
 		i = encodeVarintMetrics(dAtA, i, uint64(m.Duration))
 		i--
@@ -1044,6 +1208,16 @@ func (m *MetricList) Size() (n int) {
 			n += 1 + l + sovMetrics(uint64(l))
 		}
 	}
+	l = len(m.ProducerID)
+	if l > 0 {
+		n += 1 + l + sovMetrics(uint64(l))
+	}
+	if m.Sequence != 0 {
+		n += 1 + sovMetrics(uint64(m.Sequence))
+	}
+	if m.ProducerEpoch != 0 {
+		n += 1 + sovMetrics(uint64(m.ProducerEpoch))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1114,6 +1288,16 @@ func (m *SimpleField) Size() (n int) {
 	if m.Value != 0 {
 		n += 9
 	}
+	l = len(m.StringValue)
+	if l > 0 {
+		n += 1 + l + sovMetrics(uint64(l))
+	}
+	if m.ValueType != 0 {
+		n += 1 + sovMetrics(uint64(m.ValueType))
+	}
+	if m.IntValue != 0 {
+		n += 1 + sovMetrics(uint64(m.IntValue))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1196,6 +1380,12 @@ func (m *Exemplar) Size() (n int) {
 	if m.Duration != 0 {
 		n += 1 + sovMetrics(uint64(m.Duration))
 	}
+	if m.Value != 0 {
+		n += 9
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovMetrics(uint64(m.Timestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1271,6 +1461,76 @@ func (m *MetricList) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProducerID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProducerID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sequence", wireType)
+			}
+			m.Sequence = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Sequence |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProducerEpoch", wireType)
+			}
+			m.ProducerEpoch = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProducerEpoch |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetrics(dAtA[iNdEx:])
@@ -1675,6 +1935,76 @@ func (m *SimpleField) Unmarshal(dAtA []byte) error {
 			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
 			iNdEx += 8
 			m.Value = float64(math.Float64frombits(v))
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StringValue", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StringValue = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValueType", wireType)
+			}
+			m.ValueType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ValueType |= SimpleFieldValueType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IntValue", wireType)
+			}
+			m.IntValue = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.IntValue |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetrics(dAtA[iNdEx:])
@@ -2184,6 +2514,36 @@ func (m *Exemplar) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Value = float64(math.Float64frombits(v))
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetrics(dAtA[iNdEx:])
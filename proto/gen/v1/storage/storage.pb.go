@@ -438,6 +438,357 @@ func (m *NextSeqResponse) GetSeq() int64 {
 	return 0
 }
 
+type BulkLoadRequest struct {
+	Database             string   `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	ShardID              int32    `protobuf:"varint,2,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkLoadRequest) Reset()         { *m = BulkLoadRequest{} }
+func (m *BulkLoadRequest) String() string { return proto.CompactTextString(m) }
+func (*BulkLoadRequest) ProtoMessage()    {}
+func (*BulkLoadRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d2c4ccf1453ffdb, []int{7}
+}
+func (m *BulkLoadRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BulkLoadRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BulkLoadRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BulkLoadRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkLoadRequest.Merge(m, src)
+}
+func (m *BulkLoadRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *BulkLoadRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkLoadRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkLoadRequest proto.InternalMessageInfo
+
+func (m *BulkLoadRequest) GetDatabase() string {
+	if m != nil {
+		return m.Database
+	}
+	return ""
+}
+
+func (m *BulkLoadRequest) GetShardID() int32 {
+	if m != nil {
+		return m.ShardID
+	}
+	return 0
+}
+
+func (m *BulkLoadRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type BulkLoadResponse struct {
+	// number of metrics loaded into sealed data files
+	LoadedMetrics int64 `protobuf:"varint,1,opt,name=loadedMetrics,proto3" json:"loadedMetrics,omitempty"`
+	// number of families sealed
+	SealedFamilies       int32    `protobuf:"varint,2,opt,name=sealedFamilies,proto3" json:"sealedFamilies,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkLoadResponse) Reset()         { *m = BulkLoadResponse{} }
+func (m *BulkLoadResponse) String() string { return proto.CompactTextString(m) }
+func (*BulkLoadResponse) ProtoMessage()    {}
+func (*BulkLoadResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d2c4ccf1453ffdb, []int{8}
+}
+func (m *BulkLoadResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BulkLoadResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BulkLoadResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BulkLoadResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkLoadResponse.Merge(m, src)
+}
+func (m *BulkLoadResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *BulkLoadResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkLoadResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkLoadResponse proto.InternalMessageInfo
+
+func (m *BulkLoadResponse) GetLoadedMetrics() int64 {
+	if m != nil {
+		return m.LoadedMetrics
+	}
+	return 0
+}
+
+func (m *BulkLoadResponse) GetSealedFamilies() int32 {
+	if m != nil {
+		return m.SealedFamilies
+	}
+	return 0
+}
+
+type ExportRequest struct {
+	Database  string `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	ShardID   int32  `protobuf:"varint,2,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	Namespace string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// exports all metrics of namespace if metric names are empty
+	MetricNames []string `protobuf:"bytes,4,rep,name=metricNames,proto3" json:"metricNames,omitempty"`
+	// time range of data points, [startTime, endTime]
+	StartTime            int64    `protobuf:"varint,5,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	EndTime              int64    `protobuf:"varint,6,opt,name=endTime,proto3" json:"endTime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportRequest) Reset()         { *m = ExportRequest{} }
+func (m *ExportRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()    {}
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d2c4ccf1453ffdb, []int{9}
+}
+func (m *ExportRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExportRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportRequest.Merge(m, src)
+}
+func (m *ExportRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportRequest proto.InternalMessageInfo
+
+func (m *ExportRequest) GetDatabase() string {
+	if m != nil {
+		return m.Database
+	}
+	return ""
+}
+
+func (m *ExportRequest) GetShardID() int32 {
+	if m != nil {
+		return m.ShardID
+	}
+	return 0
+}
+
+func (m *ExportRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *ExportRequest) GetMetricNames() []string {
+	if m != nil {
+		return m.MetricNames
+	}
+	return nil
+}
+
+func (m *ExportRequest) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *ExportRequest) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+type ExportResponse struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportResponse) Reset()         { *m = ExportResponse{} }
+func (m *ExportResponse) String() string { return proto.CompactTextString(m) }
+func (*ExportResponse) ProtoMessage()    {}
+func (*ExportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d2c4ccf1453ffdb, []int{10}
+}
+func (m *ExportResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExportResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportResponse.Merge(m, src)
+}
+func (m *ExportResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportResponse proto.InternalMessageInfo
+
+func (m *ExportResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type ListShardsRequest struct {
+	Database             string   `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListShardsRequest) Reset()         { *m = ListShardsRequest{} }
+func (m *ListShardsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListShardsRequest) ProtoMessage()    {}
+func (*ListShardsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d2c4ccf1453ffdb, []int{11}
+}
+func (m *ListShardsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListShardsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListShardsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListShardsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListShardsRequest.Merge(m, src)
+}
+func (m *ListShardsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListShardsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListShardsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListShardsRequest proto.InternalMessageInfo
+
+func (m *ListShardsRequest) GetDatabase() string {
+	if m != nil {
+		return m.Database
+	}
+	return ""
+}
+
+type ListShardsResponse struct {
+	// ids of shards of database hosted by storage node, in ascending order
+	ShardIDs             []int32  `protobuf:"varint,1,rep,packed,name=shardIDs,proto3" json:"shardIDs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListShardsResponse) Reset()         { *m = ListShardsResponse{} }
+func (m *ListShardsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListShardsResponse) ProtoMessage()    {}
+func (*ListShardsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d2c4ccf1453ffdb, []int{12}
+}
+func (m *ListShardsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListShardsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListShardsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListShardsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListShardsResponse.Merge(m, src)
+}
+func (m *ListShardsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListShardsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListShardsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListShardsResponse proto.InternalMessageInfo
+
+func (m *ListShardsResponse) GetShardIDs() []int32 {
+	if m != nil {
+		return m.ShardIDs
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Replica)(nil), "protoStorageV1.Replica")
 	proto.RegisterType((*WriteRequest)(nil), "protoStorageV1.WriteRequest")
@@ -446,34 +797,55 @@ func init() {
 	proto.RegisterType((*ResetSeqResponse)(nil), "protoStorageV1.ResetSeqResponse")
 	proto.RegisterType((*NextSeqRequest)(nil), "protoStorageV1.NextSeqRequest")
 	proto.RegisterType((*NextSeqResponse)(nil), "protoStorageV1.NextSeqResponse")
+	proto.RegisterType((*BulkLoadRequest)(nil), "protoStorageV1.BulkLoadRequest")
+	proto.RegisterType((*BulkLoadResponse)(nil), "protoStorageV1.BulkLoadResponse")
+	proto.RegisterType((*ExportRequest)(nil), "protoStorageV1.ExportRequest")
+	proto.RegisterType((*ExportResponse)(nil), "protoStorageV1.ExportResponse")
+	proto.RegisterType((*ListShardsRequest)(nil), "protoStorageV1.ListShardsRequest")
+	proto.RegisterType((*ListShardsResponse)(nil), "protoStorageV1.ListShardsResponse")
 }
 
 func init() { proto.RegisterFile("storage.proto", fileDescriptor_0d2c4ccf1453ffdb) }
 
 var fileDescriptor_0d2c4ccf1453ffdb = []byte{
-	// 350 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x91, 0xcf, 0x4e, 0xc2, 0x40,
-	0x10, 0xc6, 0x59, 0x4b, 0x01, 0x47, 0xfe, 0x65, 0x0e, 0xda, 0x34, 0x5a, 0x9a, 0x7a, 0xe9, 0x09,
-	0x15, 0xde, 0x00, 0x8d, 0x01, 0x63, 0x3c, 0x6c, 0x13, 0x8d, 0xc7, 0xa5, 0x4c, 0x94, 0x60, 0x2c,
-	0x74, 0x8b, 0xf1, 0x51, 0x7c, 0x24, 0x8f, 0x3e, 0x82, 0xc1, 0x47, 0xf0, 0x05, 0x4c, 0x97, 0x6d,
-	0x15, 0x08, 0x27, 0x4f, 0xdd, 0x6f, 0x77, 0xfa, 0x9b, 0xf9, 0xbe, 0x81, 0x9a, 0x4c, 0xa2, 0x58,
-	0x3c, 0x50, 0x7b, 0x1a, 0x47, 0x49, 0x84, 0x75, 0xf5, 0x09, 0x96, 0x77, 0xb7, 0x67, 0xde, 0x09,
-	0x94, 0x39, 0x4d, 0x9f, 0xc6, 0xa1, 0xc0, 0x26, 0x18, 0x92, 0x66, 0x16, 0x73, 0x99, 0x6f, 0xf0,
-	0xf4, 0x88, 0x08, 0xc5, 0x91, 0x48, 0x84, 0xb5, 0xe3, 0x32, 0xbf, 0xca, 0xd5, 0xd9, 0x3b, 0x87,
-	0xea, 0x5d, 0x3c, 0x4e, 0x88, 0xd3, 0x6c, 0x4e, 0x32, 0xc1, 0x2e, 0x54, 0xe2, 0x25, 0x40, 0x5a,
-	0xcc, 0x35, 0xfc, 0xbd, 0xce, 0x41, 0x7b, 0xb5, 0x47, 0x5b, 0x37, 0xe0, 0x79, 0xa1, 0xd7, 0x87,
-	0x9a, 0x86, 0xc8, 0x69, 0xf4, 0x2c, 0x09, 0xf7, 0xa1, 0x14, 0xce, 0xe3, 0x20, 0x6f, 0xaf, 0x15,
-	0x5a, 0x50, 0x12, 0xe1, 0x24, 0xbd, 0x4f, 0x67, 0x30, 0xfa, 0x05, 0xae, 0x75, 0xcf, 0x04, 0x43,
-	0x84, 0x13, 0xef, 0x1e, 0x1a, 0x9c, 0x24, 0x25, 0x01, 0xcd, 0xb2, 0x89, 0x6c, 0xa8, 0xa4, 0x93,
-	0x0e, 0x85, 0x24, 0x45, 0xdb, 0xe5, 0xb9, 0x46, 0x0b, 0xca, 0xf2, 0x51, 0xc4, 0xa3, 0xc1, 0x85,
-	0x02, 0x9a, 0x3c, 0x93, 0x99, 0x7b, 0x23, 0x77, 0xef, 0x21, 0x34, 0x7f, 0xd1, 0xcb, 0x39, 0xbd,
-	0x4b, 0xa8, 0xdf, 0xd0, 0xeb, 0xbf, 0xbb, 0x79, 0xc7, 0xd0, 0xc8, 0x39, 0x3a, 0x82, 0x8d, 0xf8,
-	0x3b, 0xdf, 0x4c, 0x67, 0x1d, 0x50, 0xfc, 0x32, 0x0e, 0x09, 0xaf, 0xc0, 0x54, 0x1a, 0x0f, 0xd7,
-	0x23, 0xfe, 0xbb, 0x12, 0xfb, 0x68, 0xcb, 0xab, 0xf6, 0x50, 0xf0, 0xd9, 0x29, 0xc3, 0x6b, 0x30,
-	0x95, 0x3b, 0x6c, 0x6d, 0xae, 0x6b, 0x25, 0x4f, 0xdb, 0xdd, 0x5e, 0x90, 0x11, 0x71, 0x00, 0xc5,
-	0xd4, 0x0f, 0x3a, 0xeb, 0xb5, 0xab, 0x69, 0xd9, 0xad, 0xad, 0xef, 0x19, 0xaa, 0xd7, 0x7c, 0x5f,
-	0x38, 0xec, 0x63, 0xe1, 0xb0, 0xcf, 0x85, 0xc3, 0xde, 0xbe, 0x9c, 0xc2, 0xb0, 0xa4, 0xfe, 0xe9,
-	0xfe, 0x04, 0x00, 0x00, 0xff, 0xff, 0x68, 0xf9, 0xfb, 0xa0, 0xcb, 0x02, 0x00, 0x00,
+	// 583 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x53, 0xdd, 0x6e, 0xd3, 0x4c,
+	0x10, 0xed, 0x7e, 0x8e, 0xd3, 0x66, 0xda, 0xa4, 0xf9, 0xf6, 0x02, 0x2c, 0xab, 0xb8, 0x66, 0xa9,
+	0x50, 0xae, 0xda, 0x92, 0xbe, 0x41, 0x81, 0xaa, 0x85, 0xb4, 0x17, 0x6b, 0x04, 0x42, 0xdc, 0x74,
+	0x6b, 0x8f, 0xc0, 0xca, 0x8f, 0x13, 0xaf, 0x83, 0xfa, 0x28, 0xbc, 0x06, 0x0f, 0xc0, 0x3d, 0x97,
+	0x3c, 0x02, 0x0a, 0x8f, 0xc0, 0x0b, 0x20, 0xaf, 0x77, 0xed, 0xfc, 0x28, 0x08, 0x29, 0x57, 0xf6,
+	0x9c, 0x9d, 0x3d, 0x73, 0xce, 0xec, 0x0c, 0x34, 0x65, 0x96, 0xa4, 0xe2, 0x23, 0x1e, 0x8f, 0xd3,
+	0x24, 0x4b, 0x68, 0x4b, 0x7d, 0x82, 0x02, 0x7b, 0xfb, 0x8c, 0x9d, 0xc0, 0x36, 0xc7, 0xf1, 0x20,
+	0x0e, 0x05, 0x6d, 0x83, 0x25, 0x71, 0xe2, 0x10, 0x9f, 0x74, 0x2c, 0x9e, 0xff, 0x52, 0x0a, 0xb5,
+	0x48, 0x64, 0xc2, 0xf9, 0xcf, 0x27, 0x9d, 0x3d, 0xae, 0xfe, 0xd9, 0x73, 0xd8, 0x7b, 0x97, 0xc6,
+	0x19, 0x72, 0x9c, 0x4c, 0x51, 0x66, 0xf4, 0x0c, 0x76, 0xd2, 0x82, 0x40, 0x3a, 0xc4, 0xb7, 0x3a,
+	0xbb, 0xdd, 0x87, 0xc7, 0x8b, 0x35, 0x8e, 0x75, 0x01, 0x5e, 0x26, 0xb2, 0x4b, 0x68, 0x6a, 0x12,
+	0x39, 0x4e, 0x46, 0x12, 0xe9, 0x03, 0xa8, 0x87, 0xd3, 0x34, 0x28, 0xcb, 0xeb, 0x88, 0x3a, 0x50,
+	0x17, 0x61, 0x3f, 0xc7, 0x73, 0x0d, 0xd6, 0xe5, 0x16, 0xd7, 0xf1, 0xb9, 0x0d, 0x96, 0x08, 0xfb,
+	0xec, 0x3d, 0xec, 0x73, 0x94, 0x98, 0x05, 0x38, 0x31, 0x8a, 0x5c, 0xd8, 0xc9, 0x95, 0xde, 0x09,
+	0x89, 0x8a, 0xad, 0xc1, 0xcb, 0x98, 0x3a, 0xb0, 0x2d, 0x3f, 0x89, 0x34, 0xba, 0x7a, 0xa1, 0x08,
+	0x6d, 0x6e, 0x42, 0xe3, 0xde, 0x2a, 0xdd, 0x33, 0x0a, 0xed, 0x8a, 0xba, 0xd0, 0xc9, 0x2e, 0xa0,
+	0x75, 0x83, 0xf7, 0x1b, 0x57, 0x63, 0x4f, 0x60, 0xbf, 0xe4, 0xd1, 0x2d, 0x58, 0x69, 0x3f, 0xfb,
+	0x00, 0xfb, 0xe7, 0xd3, 0x41, 0xbf, 0x97, 0x88, 0x68, 0x33, 0x6f, 0xe6, 0x1d, 0xad, 0xb9, 0x77,
+	0xbc, 0x85, 0x76, 0x45, 0xae, 0x25, 0x1c, 0x41, 0x73, 0x90, 0x88, 0x08, 0xa3, 0x6b, 0xcc, 0xd2,
+	0x38, 0x94, 0x5a, 0xcc, 0x22, 0x48, 0x9f, 0x42, 0x4b, 0xa2, 0x18, 0x60, 0x74, 0x21, 0x86, 0xf1,
+	0x20, 0x46, 0xa9, 0xcb, 0x2d, 0xa1, 0xec, 0x1b, 0x81, 0xe6, 0xcb, 0xfb, 0x71, 0x92, 0x66, 0x9b,
+	0xa9, 0x3f, 0x80, 0xc6, 0x48, 0x0c, 0x51, 0x8e, 0x45, 0x88, 0xca, 0x42, 0x83, 0x57, 0x00, 0xf5,
+	0x61, 0x77, 0xa8, 0x84, 0xdd, 0xe4, 0x90, 0x53, 0xf3, 0xad, 0x4e, 0x83, 0xcf, 0x43, 0xf9, 0x7d,
+	0x99, 0x89, 0x34, 0x7b, 0x13, 0x0f, 0xd1, 0xb1, 0x95, 0xa3, 0x0a, 0xc8, 0xeb, 0xe2, 0x28, 0x52,
+	0x67, 0x75, 0x75, 0x66, 0x42, 0x76, 0x04, 0x2d, 0x23, 0x5f, 0xf7, 0xc7, 0xf4, 0x91, 0xcc, 0xf5,
+	0xf1, 0x04, 0xfe, 0xef, 0xc5, 0x32, 0x0b, 0x72, 0xb1, 0xf2, 0x1f, 0x8c, 0xb2, 0x53, 0xa0, 0xf3,
+	0x17, 0x34, 0xb5, 0x0b, 0x3b, 0xda, 0x6f, 0xb1, 0x46, 0x36, 0x2f, 0xe3, 0xee, 0x6f, 0xa2, 0x77,
+	0x2e, 0xc0, 0xf4, 0x73, 0x1c, 0x22, 0x7d, 0x05, 0xb6, 0x8a, 0xe9, 0xc1, 0xf2, 0xaa, 0xcd, 0xaf,
+	0xa6, 0xfb, 0x68, 0xcd, 0xa9, 0x9e, 0xe5, 0xad, 0x0e, 0x39, 0x25, 0xb4, 0x07, 0xb6, 0x9a, 0x72,
+	0x7a, 0xb8, 0xba, 0xb6, 0x0b, 0x7b, 0xe5, 0xfa, 0xeb, 0x13, 0x0c, 0x23, 0xbd, 0x82, 0x5a, 0x3e,
+	0xd7, 0xd4, 0x5b, 0xce, 0x5d, 0xdc, 0x1a, 0xf7, 0x70, 0xed, 0xb9, 0xa1, 0xea, 0xde, 0x56, 0xd3,
+	0x6f, 0x7c, 0x5f, 0x43, 0x2d, 0x0f, 0x57, 0xa5, 0x2e, 0xad, 0x89, 0xeb, 0xaf, 0x4f, 0xa8, 0xcc,
+	0x77, 0xbf, 0x96, 0x03, 0x6a, 0x0a, 0xbc, 0x86, 0x7a, 0x01, 0xd0, 0x95, 0xde, 0x2d, 0x4c, 0xb2,
+	0xeb, 0xad, 0x3b, 0x36, 0xf4, 0xa7, 0x84, 0x06, 0x00, 0xd5, 0x43, 0xd3, 0xc7, 0xcb, 0x37, 0x56,
+	0xa6, 0xc6, 0x65, 0x7f, 0x4b, 0x29, 0x88, 0xcf, 0xdb, 0xdf, 0x67, 0x1e, 0xf9, 0x31, 0xf3, 0xc8,
+	0xcf, 0x99, 0x47, 0xbe, 0xfc, 0xf2, 0xb6, 0xee, 0xea, 0xea, 0xd2, 0xd9, 0x9f, 0x01, 0x00, 0x3a,
+	0xa4, 0xe7, 0x42, 0xe9, 0x05, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -661,34 +1033,276 @@ var _WriteService_serviceDesc = grpc.ServiceDesc{
 	Metadata: "storage.proto",
 }
 
-func (m *Replica) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+// BulkLoadServiceClient is the client API for BulkLoadService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type BulkLoadServiceClient interface {
+	Load(ctx context.Context, opts ...grpc.CallOption) (BulkLoadService_LoadClient, error)
+}
+
+type bulkLoadServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBulkLoadServiceClient(cc *grpc.ClientConn) BulkLoadServiceClient {
+	return &bulkLoadServiceClient{cc}
+}
+
+func (c *bulkLoadServiceClient) Load(ctx context.Context, opts ...grpc.CallOption) (BulkLoadService_LoadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_BulkLoadService_serviceDesc.Streams[0], "/protoStorageV1.BulkLoadService/Load", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	x := &bulkLoadServiceLoadClient{stream}
+	return x, nil
 }
 
-func (m *Replica) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+type BulkLoadService_LoadClient interface {
+	Send(*BulkLoadRequest) error
+	CloseAndRecv() (*BulkLoadResponse, error)
+	grpc.ClientStream
 }
 
-func (m *Replica) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if len(m.Data) > 0 {
-		i -= len(m.Data)
-		copy(dAtA[i:], m.Data)
-		i = encodeVarintStorage(dAtA, i, uint64(len(m.Data)))
+type bulkLoadServiceLoadClient struct {
+	grpc.ClientStream
+}
+
+func (x *bulkLoadServiceLoadClient) Send(m *BulkLoadRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *bulkLoadServiceLoadClient) CloseAndRecv() (*BulkLoadResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkLoadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// BulkLoadServiceServer is the server API for BulkLoadService service.
+type BulkLoadServiceServer interface {
+	Load(BulkLoadService_LoadServer) error
+}
+
+// UnimplementedBulkLoadServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedBulkLoadServiceServer struct {
+}
+
+func (*UnimplementedBulkLoadServiceServer) Load(srv BulkLoadService_LoadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Load not implemented")
+}
+
+func RegisterBulkLoadServiceServer(s *grpc.Server, srv BulkLoadServiceServer) {
+	s.RegisterService(&_BulkLoadService_serviceDesc, srv)
+}
+
+func _BulkLoadService_Load_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BulkLoadServiceServer).Load(&bulkLoadServiceLoadServer{stream})
+}
+
+type BulkLoadService_LoadServer interface {
+	SendAndClose(*BulkLoadResponse) error
+	Recv() (*BulkLoadRequest, error)
+	grpc.ServerStream
+}
+
+type bulkLoadServiceLoadServer struct {
+	grpc.ServerStream
+}
+
+func (x *bulkLoadServiceLoadServer) SendAndClose(m *BulkLoadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *bulkLoadServiceLoadServer) Recv() (*BulkLoadRequest, error) {
+	m := new(BulkLoadRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _BulkLoadService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protoStorageV1.BulkLoadService",
+	HandlerType: (*BulkLoadServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Load",
+			Handler:       _BulkLoadService_Load_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "storage.proto",
+}
+
+// ExportServiceClient is the client API for ExportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ExportServiceClient interface {
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (ExportService_ExportClient, error)
+	ListShards(ctx context.Context, in *ListShardsRequest, opts ...grpc.CallOption) (*ListShardsResponse, error)
+}
+
+type exportServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewExportServiceClient(cc *grpc.ClientConn) ExportServiceClient {
+	return &exportServiceClient{cc}
+}
+
+func (c *exportServiceClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (ExportService_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ExportService_serviceDesc.Streams[0], "/protoStorageV1.ExportService/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &exportServiceExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ExportService_ExportClient interface {
+	Recv() (*ExportResponse, error)
+	grpc.ClientStream
+}
+
+type exportServiceExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *exportServiceExportClient) Recv() (*ExportResponse, error) {
+	m := new(ExportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *exportServiceClient) ListShards(ctx context.Context, in *ListShardsRequest, opts ...grpc.CallOption) (*ListShardsResponse, error) {
+	out := new(ListShardsResponse)
+	err := c.cc.Invoke(ctx, "/protoStorageV1.ExportService/ListShards", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportServiceServer is the server API for ExportService service.
+type ExportServiceServer interface {
+	Export(*ExportRequest, ExportService_ExportServer) error
+	ListShards(context.Context, *ListShardsRequest) (*ListShardsResponse, error)
+}
+
+// UnimplementedExportServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedExportServiceServer struct {
+}
+
+func (*UnimplementedExportServiceServer) Export(req *ExportRequest, srv ExportService_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (*UnimplementedExportServiceServer) ListShards(ctx context.Context, req *ListShardsRequest) (*ListShardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShards not implemented")
+}
+
+func RegisterExportServiceServer(s *grpc.Server, srv ExportServiceServer) {
+	s.RegisterService(&_ExportService_serviceDesc, srv)
+}
+
+func _ExportService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExportServiceServer).Export(m, &exportServiceExportServer{stream})
+}
+
+type ExportService_ExportServer interface {
+	Send(*ExportResponse) error
+	grpc.ServerStream
+}
+
+type exportServiceExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *exportServiceExportServer) Send(m *ExportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ExportService_ListShards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExportServiceServer).ListShards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protoStorageV1.ExportService/ListShards",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExportServiceServer).ListShards(ctx, req.(*ListShardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ExportService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protoStorageV1.ExportService",
+	HandlerType: (*ExportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListShards",
+			Handler:    _ExportService_ListShards_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _ExportService_Export_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "storage.proto",
+}
+
+func (m *Replica) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Replica) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Replica) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintStorage(dAtA, i, uint64(len(m.Data)))
 		i--
 		dAtA[i] = 0x12
 	}
@@ -936,68 +1550,330 @@ func (m *NextSeqResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintStorage(dAtA []byte, offset int, v uint64) int {
-	offset -= sovStorage(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *BulkLoadRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *Replica) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *BulkLoadRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BulkLoadRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if m.Seq != 0 {
-		n += 1 + sovStorage(uint64(m.Seq))
-	}
-	l = len(m.Data)
-	if l > 0 {
-		n += 1 + l + sovStorage(uint64(l))
-	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
-}
-
-func (m *WriteRequest) Size() (n int) {
-	if m == nil {
-		return 0
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintStorage(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0x1a
 	}
-	var l int
-	_ = l
-	if len(m.Replicas) > 0 {
-		for _, e := range m.Replicas {
-			l = e.Size()
-			n += 1 + l + sovStorage(uint64(l))
-		}
+	if m.ShardID != 0 {
+		i = encodeVarintStorage(dAtA, i, uint64(m.ShardID))
+		i--
+		dAtA[i] = 0x10
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(m.Database) > 0 {
+		i -= len(m.Database)
+		copy(dAtA[i:], m.Database)
+		i = encodeVarintStorage(dAtA, i, uint64(len(m.Database)))
+		i--
+		dAtA[i] = 0xa
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *WriteResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *BulkLoadResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *BulkLoadResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BulkLoadResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if m.CurSeq != 0 {
-		n += 1 + sovStorage(uint64(m.CurSeq))
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if m.Ack != nil {
-		n += m.Ack.Size()
+	if m.SealedFamilies != 0 {
+		i = encodeVarintStorage(dAtA, i, uint64(m.SealedFamilies))
+		i--
+		dAtA[i] = 0x10
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if m.LoadedMetrics != 0 {
+		i = encodeVarintStorage(dAtA, i, uint64(m.LoadedMetrics))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ExportRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExportRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExportRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.EndTime != 0 {
+		i = encodeVarintStorage(dAtA, i, uint64(m.EndTime))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.StartTime != 0 {
+		i = encodeVarintStorage(dAtA, i, uint64(m.StartTime))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.MetricNames) > 0 {
+		for iNdEx := len(m.MetricNames) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.MetricNames[iNdEx])
+			copy(dAtA[i:], m.MetricNames[iNdEx])
+			i = encodeVarintStorage(dAtA, i, uint64(len(m.MetricNames[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Namespace) > 0 {
+		i -= len(m.Namespace)
+		copy(dAtA[i:], m.Namespace)
+		i = encodeVarintStorage(dAtA, i, uint64(len(m.Namespace)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.ShardID != 0 {
+		i = encodeVarintStorage(dAtA, i, uint64(m.ShardID))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Database) > 0 {
+		i -= len(m.Database)
+		copy(dAtA[i:], m.Database)
+		i = encodeVarintStorage(dAtA, i, uint64(len(m.Database)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ExportResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExportResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExportResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintStorage(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ListShardsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListShardsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListShardsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Database) > 0 {
+		i -= len(m.Database)
+		copy(dAtA[i:], m.Database)
+		i = encodeVarintStorage(dAtA, i, uint64(len(m.Database)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ListShardsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListShardsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListShardsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.ShardIDs) > 0 {
+		dAtA2 := make([]byte, len(m.ShardIDs)*10)
+		var j1 int
+		for _, num1 := range m.ShardIDs {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		i -= j1
+		copy(dAtA[i:], dAtA2[:j1])
+		i = encodeVarintStorage(dAtA, i, uint64(j1))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintStorage(dAtA []byte, offset int, v uint64) int {
+	offset -= sovStorage(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Replica) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Seq != 0 {
+		n += 1 + sovStorage(uint64(m.Seq))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovStorage(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *WriteRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Replicas) > 0 {
+		for _, e := range m.Replicas {
+			l = e.Size()
+			n += 1 + l + sovStorage(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *WriteResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.CurSeq != 0 {
+		n += 1 + sovStorage(uint64(m.CurSeq))
+	}
+	if m.Ack != nil {
+		n += m.Ack.Size()
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
 	}
 	return n
 }
@@ -1079,31 +1955,158 @@ func (m *NextSeqResponse) Size() (n int) {
 	return n
 }
 
-func sovStorage(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
+func (m *BulkLoadRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Database)
+	if l > 0 {
+		n += 1 + l + sovStorage(uint64(l))
+	}
+	if m.ShardID != 0 {
+		n += 1 + sovStorage(uint64(m.ShardID))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovStorage(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
 }
-func sozStorage(x uint64) (n int) {
-	return sovStorage(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+
+func (m *BulkLoadResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LoadedMetrics != 0 {
+		n += 1 + sovStorage(uint64(m.LoadedMetrics))
+	}
+	if m.SealedFamilies != 0 {
+		n += 1 + sovStorage(uint64(m.SealedFamilies))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
 }
-func (m *Replica) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowStorage
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
+
+func (m *ExportRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Database)
+	if l > 0 {
+		n += 1 + l + sovStorage(uint64(l))
+	}
+	if m.ShardID != 0 {
+		n += 1 + sovStorage(uint64(m.ShardID))
+	}
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovStorage(uint64(l))
+	}
+	if len(m.MetricNames) > 0 {
+		for _, s := range m.MetricNames {
+			l = len(s)
+			n += 1 + l + sovStorage(uint64(l))
+		}
+	}
+	if m.StartTime != 0 {
+		n += 1 + sovStorage(uint64(m.StartTime))
+	}
+	if m.EndTime != 0 {
+		n += 1 + sovStorage(uint64(m.EndTime))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ExportResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovStorage(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListShardsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Database)
+	if l > 0 {
+		n += 1 + l + sovStorage(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListShardsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.ShardIDs) > 0 {
+		l = 0
+		for _, e := range m.ShardIDs {
+			l += sovStorage(uint64(e))
+		}
+		n += 1 + sovStorage(uint64(l)) + l
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovStorage(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozStorage(x uint64) (n int) {
+	return sovStorage(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Replica) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
 		}
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
@@ -1152,21 +2155,540 @@ func (m *Replica) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthStorage
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthStorage
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
-			if m.Data == nil {
-				m.Data = []byte{}
-			}
-			iNdEx = postIndex
+			if byteLen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStorage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WriteRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WriteRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WriteRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Replicas = append(m.Replicas, &Replica{})
+			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStorage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WriteResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WriteResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WriteResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CurSeq", wireType)
+			}
+			m.CurSeq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CurSeq |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AckSeq", wireType)
+			}
+			var v int64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Ack = &WriteResponse_AckSeq{v}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStorage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResetSeqRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResetSeqRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResetSeqRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Database", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Database = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStorage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResetSeqResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResetSeqResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResetSeqResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStorage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NextSeqRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NextSeqRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NextSeqRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Database", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Database = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStorage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NextSeqResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NextSeqResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NextSeqResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStorage(dAtA[iNdEx:])
@@ -1189,7 +2711,7 @@ func (m *Replica) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *WriteRequest) Unmarshal(dAtA []byte) error {
+func (m *BulkLoadRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1212,17 +2734,17 @@ func (m *WriteRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: WriteRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: BulkLoadRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: WriteRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: BulkLoadRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Database", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStorage
@@ -1232,24 +2754,75 @@ func (m *WriteRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthStorage
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthStorage
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Replicas = append(m.Replicas, &Replica{})
-			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Database = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
 			}
 			iNdEx = postIndex
 		default:
@@ -1274,7 +2847,7 @@ func (m *WriteRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *WriteResponse) Unmarshal(dAtA []byte) error {
+func (m *BulkLoadResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1297,17 +2870,17 @@ func (m *WriteResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: WriteResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: BulkLoadResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: WriteResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: BulkLoadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CurSeq", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LoadedMetrics", wireType)
 			}
-			m.CurSeq = 0
+			m.LoadedMetrics = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStorage
@@ -1317,16 +2890,16 @@ func (m *WriteResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.CurSeq |= int64(b&0x7F) << shift
+				m.LoadedMetrics |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AckSeq", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SealedFamilies", wireType)
 			}
-			var v int64
+			m.SealedFamilies = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStorage
@@ -1336,12 +2909,11 @@ func (m *WriteResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int64(b&0x7F) << shift
+				m.SealedFamilies |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Ack = &WriteResponse_AckSeq{v}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStorage(dAtA[iNdEx:])
@@ -1364,7 +2936,7 @@ func (m *WriteResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ResetSeqRequest) Unmarshal(dAtA []byte) error {
+func (m *ExportRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1387,10 +2959,10 @@ func (m *ResetSeqRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ResetSeqRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ResetSeqRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1445,10 +3017,74 @@ func (m *ResetSeqRequest) Unmarshal(dAtA []byte) error {
 				}
 			}
 		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetricNames", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MetricNames = append(m.MetricNames, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
 			}
-			m.Seq = 0
+			m.StartTime = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStorage
@@ -1458,7 +3094,26 @@ func (m *ResetSeqRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Seq |= int64(b&0x7F) << shift
+				m.StartTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EndTime", wireType)
+			}
+			m.EndTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.EndTime |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -1485,7 +3140,7 @@ func (m *ResetSeqRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ResetSeqResponse) Unmarshal(dAtA []byte) error {
+func (m *ExportResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1508,12 +3163,46 @@ func (m *ResetSeqResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ResetSeqResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ResetSeqResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStorage
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStorage(dAtA[iNdEx:])
@@ -1536,7 +3225,7 @@ func (m *ResetSeqResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *NextSeqRequest) Unmarshal(dAtA []byte) error {
+func (m *ListShardsRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1559,10 +3248,10 @@ func (m *NextSeqRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NextSeqRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ListShardsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NextSeqRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ListShardsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1597,25 +3286,6 @@ func (m *NextSeqRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Database = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
-			}
-			m.ShardID = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowStorage
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.ShardID |= int32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStorage(dAtA[iNdEx:])
@@ -1638,7 +3308,7 @@ func (m *NextSeqRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *NextSeqResponse) Unmarshal(dAtA []byte) error {
+func (m *ListShardsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1661,30 +3331,87 @@ func (m *NextSeqResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NextSeqResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ListShardsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NextSeqResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ListShardsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
-			}
-			m.Seq = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowStorage
+			if wireType == 0 {
+				var v int32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStorage
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
 				}
-				if iNdEx >= l {
+				m.ShardIDs = append(m.ShardIDs, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStorage
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthStorage
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthStorage
+				}
+				if postIndex > l {
 					return io.ErrUnexpectedEOF
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Seq |= int64(b&0x7F) << shift
-				if b < 0x80 {
-					break
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.ShardIDs) == 0 {
+					m.ShardIDs = make([]int32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStorage
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.ShardIDs = append(m.ShardIDs, v)
 				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardIDs", wireType)
 			}
 		default:
 			iNdEx = preIndex
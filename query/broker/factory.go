@@ -22,27 +22,35 @@ import (
 
 	"github.com/lindb/lindb/coordinator/broker"
 	"github.com/lindb/lindb/coordinator/discovery"
+	"github.com/lindb/lindb/internal/tenant"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/sql/stmt"
 )
 
 type queryFactory struct {
-	replicaStateMachine  broker.ReplicaStatusStateMachine
-	nodeStateMachine     discovery.ActiveNodeStateMachine
-	databaseStateMachine broker.DatabaseStateMachine
-	taskManager          TaskManager
+	replicaStateMachine       broker.ReplicaStatusStateMachine
+	nodeStateMachine          discovery.ActiveNodeStateMachine
+	databaseStateMachine      broker.DatabaseStateMachine
+	queryDefaultsStateMachine broker.QueryDefaultsStateMachine
+	taskManager               TaskManager
+	tenants                   tenant.Tenants // nil means no quota/isolation of namespace
 }
 
 func NewQueryFactory(
 	replicaStateMachine broker.ReplicaStatusStateMachine,
 	nodeStateMachine discovery.ActiveNodeStateMachine,
 	databaseStateMachine broker.DatabaseStateMachine,
+	queryDefaultsStateMachine broker.QueryDefaultsStateMachine,
 	taskManager TaskManager,
+	tenants tenant.Tenants,
 ) Factory {
 	return &queryFactory{
-		replicaStateMachine:  replicaStateMachine,
-		nodeStateMachine:     nodeStateMachine,
-		databaseStateMachine: databaseStateMachine,
-		taskManager:          taskManager,
+		replicaStateMachine:       replicaStateMachine,
+		nodeStateMachine:          nodeStateMachine,
+		databaseStateMachine:      databaseStateMachine,
+		queryDefaultsStateMachine: queryDefaultsStateMachine,
+		taskManager:               taskManager,
+		tenants:                   tenants,
 	}
 }
 
@@ -50,8 +58,9 @@ func (qh *queryFactory) NewMetricQuery(
 	ctx context.Context,
 	databaseName string,
 	sql string,
+	queryID string,
 ) MetricQuery {
-	return newMetricQuery(ctx, databaseName, sql, qh)
+	return newMetricQuery(ctx, databaseName, sql, queryID, qh)
 }
 
 func (qh *queryFactory) NewMetadataQuery(
@@ -61,3 +70,7 @@ func (qh *queryFactory) NewMetadataQuery(
 ) MetaDataQuery {
 	return newMetadataQuery(ctx, database, stmt, qh)
 }
+
+func (qh *queryFactory) QueryProgress(queryID string) (*models.QueryProgress, bool) {
+	return qh.taskManager.QueryProgress(queryID)
+}
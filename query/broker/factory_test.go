@@ -24,6 +24,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/sql/stmt"
 )
 
@@ -31,14 +32,22 @@ func TestExecutorFactory_NewExecutor(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	factory := NewQueryFactory(nil, nil, nil, nil)
+	factory := NewQueryFactory(nil, nil, nil, nil, nil, nil)
 	assert.NotNil(t, factory.NewMetricQuery(
 		context.Background(),
 		"",
+		"",
 		""))
 	assert.NotNil(t, factory.NewMetadataQuery(
 		context.Background(),
 		"",
 		&stmt.Metadata{}))
 
+	taskManager := NewMockTaskManager(ctrl)
+	factory = NewQueryFactory(nil, nil, nil, nil, taskManager, nil)
+	taskManager.EXPECT().QueryProgress("q1").Return(&models.QueryProgress{QueryID: "q1"}, true)
+	progress, ok := factory.QueryProgress("q1")
+	assert.True(t, ok)
+	assert.Equal(t, "q1", progress.QueryID)
+
 }
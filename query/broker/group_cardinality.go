@@ -0,0 +1,106 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package brokerquery
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+//go:generate mockgen -source=./group_cardinality.go -destination=./group_cardinality_mock.go -package=brokerquery
+
+const (
+	// maxGroupCardinalityEntries is the max number of recorded group by queries
+	maxGroupCardinalityEntries = 10000
+	// maxPreallocatedGroups is the max number of groups which can be preallocated,
+	// avoid allocating too much memory based on abnormal history.
+	maxPreallocatedGroups = 1 << 20
+)
+
+// GroupCardinalityStats records the number of groups for group by query of each metric,
+// which is used to preallocate the memory of grouping aggregator when merging results.
+type GroupCardinalityStats interface {
+	// Get returns the recorded group cardinality of query, returns 0 if not found.
+	Get(query *stmt.Query) int
+	// Record records the group cardinality of query after merging results.
+	Record(query *stmt.Query, cardinality int)
+}
+
+// groupCardinalityStats implements GroupCardinalityStats interface
+type groupCardinalityStats struct {
+	cardinality map[string]int // namespace/metric/group by tag keys => num. of groups
+	mutex       sync.RWMutex
+}
+
+// NewGroupCardinalityStats creates the group cardinality statistics
+func NewGroupCardinalityStats() GroupCardinalityStats {
+	return &groupCardinalityStats{
+		cardinality: make(map[string]int),
+	}
+}
+
+// Get returns the recorded group cardinality of query, returns 0 if not found.
+func (s *groupCardinalityStats) Get(query *stmt.Query) int {
+	if query == nil || !query.HasGroupBy() {
+		return 0
+	}
+	key := groupCardinalityKey(query)
+	s.mutex.RLock()
+	cardinality := s.cardinality[key]
+	s.mutex.RUnlock()
+
+	if cardinality > maxPreallocatedGroups {
+		return maxPreallocatedGroups
+	}
+	return cardinality
+}
+
+// Record records the group cardinality of query after merging results,
+// keeps the larger cardinality, decays the recorded cardinality if the groups become fewer.
+func (s *groupCardinalityStats) Record(query *stmt.Query, cardinality int) {
+	if query == nil || !query.HasGroupBy() || cardinality <= 0 {
+		return
+	}
+	key := groupCardinalityKey(query)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	old, ok := s.cardinality[key]
+	if !ok && len(s.cardinality) >= maxGroupCardinalityEntries {
+		// evict one entry randomly if too many entries
+		for k := range s.cardinality {
+			delete(s.cardinality, k)
+			break
+		}
+	}
+	if cardinality < old {
+		cardinality = (old + cardinality) / 2
+	}
+	s.cardinality[key] = cardinality
+}
+
+// groupCardinalityKey returns the key of query, which is namespace/metric/sorted group by tag keys
+func groupCardinalityKey(query *stmt.Query) string {
+	groupBy := make([]string, len(query.GroupBy))
+	copy(groupBy, query.GroupBy)
+	sort.Strings(groupBy)
+	return query.Namespace + "/" + query.MetricName + "/" + strings.Join(groupBy, ",")
+}
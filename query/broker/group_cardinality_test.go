@@ -0,0 +1,65 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package brokerquery
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestGroupCardinalityStats(t *testing.T) {
+	stats := NewGroupCardinalityStats()
+	// no group by
+	q := &stmt.Query{Namespace: "ns", MetricName: "cpu"}
+	stats.Record(q, 100)
+	assert.Equal(t, 0, stats.Get(q))
+	assert.Equal(t, 0, stats.Get(nil))
+	stats.Record(nil, 100)
+
+	q1 := &stmt.Query{Namespace: "ns", MetricName: "cpu", GroupBy: []string{"host", "app"}}
+	q2 := &stmt.Query{Namespace: "ns", MetricName: "cpu", GroupBy: []string{"app", "host"}}
+	assert.Equal(t, 0, stats.Get(q1))
+	stats.Record(q1, 0)
+	assert.Equal(t, 0, stats.Get(q1))
+	stats.Record(q1, 100)
+	// group by tag keys order not matter
+	assert.Equal(t, 100, stats.Get(q2))
+	// keep larger
+	stats.Record(q2, 200)
+	assert.Equal(t, 200, stats.Get(q1))
+	// decay
+	stats.Record(q2, 100)
+	assert.Equal(t, 150, stats.Get(q1))
+	// query group by not changed
+	assert.Equal(t, []string{"host", "app"}, q1.GroupBy)
+	// max preallocated
+	stats.Record(q1, maxPreallocatedGroups*2)
+	assert.Equal(t, maxPreallocatedGroups, stats.Get(q1))
+}
+
+func TestGroupCardinalityStats_evict(t *testing.T) {
+	stats := NewGroupCardinalityStats()
+	for i := 0; i < maxGroupCardinalityEntries+10; i++ {
+		stats.Record(&stmt.Query{Namespace: "ns", MetricName: strconv.Itoa(i), GroupBy: []string{"host"}}, 10)
+	}
+	assert.Len(t, stats.(*groupCardinalityStats).cardinality, maxGroupCardinalityEntries)
+}
@@ -77,14 +77,19 @@ type MetricQuery interface {
 
 type MetaDataQuery interface {
 	WaitResponse() ([]string, error)
+	// Total returns the total-count hint of matched values before pagination,
+	// it's valid after WaitResponse returns.
+	Total() int
 }
 
 // Factory is the handler for executing querying tasks
 type Factory interface {
+	// NewMetricQuery creates the metric query, the progress of query can be tracked by query id.
 	NewMetricQuery(
 		ctx context.Context,
 		databaseName string,
 		sql string,
+		queryID string,
 	) MetricQuery
 
 	NewMetadataQuery(
@@ -92,4 +97,7 @@ type Factory interface {
 		databaseName string,
 		stmt *stmt.Metadata,
 	) MetaDataQuery
+
+	// QueryProgress returns the execution progress of metric query by query id.
+	QueryProgress(queryID string) (*models.QueryProgress, bool)
 }
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/logger"
@@ -93,6 +94,8 @@ ErrToRoot:
 		SendTime:  timeutil.NowNano(),
 	}); streamErr != nil {
 		p.logger.Error("failed to send error message to target stream",
+			logger.String("queryID", req.QueryID),
+			logger.String("requestID", query.RequestID(req)),
 			logger.String("taskID", req.ParentTaskID),
 			logger.Error(streamErr),
 		)
@@ -101,8 +104,18 @@ ErrToRoot:
 
 // processIntermediateTask processes the task request, sends task request to leaf nodes based on physical plan,
 // and tracks the task state
-func (p *intermediateTaskProcessor) processIntermediateTask(ctx context.Context, req *protoCommonV1.TaskRequest) error {
+func (p *intermediateTaskProcessor) processIntermediateTask(
+	ctx context.Context,
+	req *protoCommonV1.TaskRequest,
+) (err error) {
 	startTime := time.Now()
+	ctx, span := tracing.StartSpan(ctx, "broker.intermediate_task")
+	span.SetAttribute("node", p.currentNodeID)
+	span.SetAttribute("taskID", req.ParentTaskID)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
 	stmtQuery := stmt.Query{}
 	if err := stmtQuery.UnmarshalJSON(req.Payload); err != nil {
 		return query.ErrUnmarshalQuery
@@ -185,8 +198,9 @@ func (p *intermediateTaskProcessor) makeTaskResponse(
 		if len(fields) > 0 {
 			// always have group by
 			timeSeriesList = append(timeSeriesList, &protoCommonV1.TimeSeries{
-				Tags:   itr.Tags(),
-				Fields: fields,
+				Tags:      itr.Tags(),
+				Fields:    fields,
+				Exemplars: event.Exemplars[itr.Tags()],
 			})
 		}
 	}
@@ -195,9 +209,14 @@ func (p *intermediateTaskProcessor) makeTaskResponse(
 	for _, spec := range event.AggregatorSpecs {
 		aggregatorSpecs = append(aggregatorSpecs, spec)
 	}
+	var stringValues []*protoCommonV1.StringValue
+	for id, value := range event.StringValues {
+		stringValues = append(stringValues, &protoCommonV1.StringValue{Id: id, Value: value})
+	}
 	seriesList := protoCommonV1.TimeSeriesList{
 		TimeSeriesList: timeSeriesList,
 		FieldAggSpecs:  aggregatorSpecs,
+		StringValues:   stringValues,
 	}
 	data, _ := seriesList.Marshal()
 	return &protoCommonV1.TaskResponse{
@@ -31,6 +31,7 @@ import (
 	"github.com/lindb/lindb/pkg/logger"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
 	"github.com/lindb/lindb/series"
+	"github.com/lindb/lindb/series/field"
 )
 
 func Test_Intermediate_decodePhysicalPlan(t *testing.T) {
@@ -143,3 +144,27 @@ func Test_Intermediate_processIntermediateTask(t *testing.T) {
 			PhysicalPlan: planData,
 		}))
 }
+
+func Test_Intermediate_makeTaskResponse_exemplars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeSeries := series.NewMockGroupedIterator(ctrl)
+	it := series.NewMockIterator(ctrl)
+	timeSeries.EXPECT().HasNext().Return(true)
+	timeSeries.EXPECT().Next().Return(it)
+	timeSeries.EXPECT().HasNext().Return(false)
+	timeSeries.EXPECT().Tags().Return("host1").AnyTimes()
+	it.EXPECT().MarshalBinary().Return([]byte{1, 2, 3}, nil)
+	it.EXPECT().FieldName().Return(field.Name("f1"))
+	taskProcessor := intermediateTaskProcessor{}
+	resp := taskProcessor.makeTaskResponse(&protoCommonV1.TaskRequest{}, &series.TimeSeriesEvent{
+		SeriesList:   series.GroupedIterators{timeSeries},
+		Exemplars:    map[string][]*protoCommonV1.Exemplar{"host1": {{TraceID: "1a", Timestamp: 10}}},
+		StringValues: map[uint64]string{1: "v1.0.0"},
+	})
+	seriesList := &protoCommonV1.TimeSeriesList{}
+	assert.NoError(t, seriesList.Unmarshal(resp.Payload))
+	assert.Equal(t, []*protoCommonV1.Exemplar{{TraceID: "1a", Timestamp: 10}}, seriesList.TimeSeriesList[0].Exemplars)
+	assert.Equal(t, []*protoCommonV1.StringValue{{Id: 1, Value: "v1.0.0"}}, seriesList.StringValues)
+}
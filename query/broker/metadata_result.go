@@ -0,0 +1,130 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package brokerquery
+
+import (
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+// BuildMetadata builds the result of metadata statement based on the values returned by storage nodes,
+// values of fields/cardinality/metric descriptor are json of each node which need to be merged.
+func BuildMetadata(request *stmt.Metadata, values []string) (*models.Metadata, error) {
+	result := &models.Metadata{Type: request.Type.String()}
+	switch request.Type {
+	case stmt.Field:
+		// build field result model
+		fieldMetas := make(map[field.Name]field.Meta)
+		fields := field.Metas{}
+		for _, value := range values {
+			if err := encoding.JSONUnmarshal([]byte(value), &fields); err != nil {
+				return nil, err
+			}
+			for _, f := range fields {
+				fieldMetas[f.Name] = f
+			}
+		}
+		// HistogramSum(sum), HistogramCount(sum), HistogramMin(min), HistogramMax(max) is visible
+		// __bucket_{id}(HistogramField) is not visible for api,
+		// underlying histogram data is only restricted access by user via quantile function
+		// furthermore, we suggest some quantile functions for user in field names, such as quantile(0.99)
+		var (
+			resultFields []models.Field
+			hasHistogram bool
+		)
+		for _, f := range fieldMetas {
+			if f.Type != field.HistogramField {
+				resultFields = append(resultFields, models.Field{
+					Name: string(f.Name),
+					Type: f.Type.String(),
+				})
+			} else {
+				hasHistogram = true
+			}
+		}
+		//
+		if hasHistogram {
+			resultFields = append(resultFields,
+				models.Field{Name: "quantile(0.99)", Type: field.HistogramField.String()},
+				models.Field{Name: "quantile(0.95)", Type: field.HistogramField.String()},
+				models.Field{Name: "quantile(0.90)", Type: field.HistogramField.String()},
+			)
+		}
+		result.Values = resultFields
+	case stmt.SeriesCardinality:
+		// merge series cardinality of storage nodes
+		var partials []*models.SeriesCardinality
+		for _, value := range values {
+			partial := &models.SeriesCardinality{}
+			if err := encoding.JSONUnmarshal([]byte(value), partial); err != nil {
+				return nil, err
+			}
+			partials = append(partials, partial)
+		}
+		cardinality, err := models.MergeSeriesCardinality(partials)
+		if err != nil {
+			return nil, err
+		}
+		if cardinality.MetricName == "" {
+			cardinality.MetricName = request.MetricName
+		}
+		// tag keys are sorted by values desc, keep the top tag keys
+		if request.Limit > 0 && len(cardinality.TagKeys) > request.Limit {
+			cardinality.TagKeys = cardinality.TagKeys[:request.Limit]
+		}
+		result.Values = cardinality
+	case stmt.TagValueCardinality:
+		// merge top-k tag values of storage nodes by summing counts
+		var partials []*models.TagValueCardinality
+		for _, value := range values {
+			partial := &models.TagValueCardinality{}
+			if err := encoding.JSONUnmarshal([]byte(value), partial); err != nil {
+				return nil, err
+			}
+			partials = append(partials, partial)
+		}
+		cardinality := models.MergeTagValueCardinality(partials, request.Limit)
+		if cardinality.MetricName == "" {
+			cardinality.MetricName = request.MetricName
+		}
+		if cardinality.TagKey == "" {
+			cardinality.TagKey = request.TagKey
+		}
+		result.Values = cardinality
+	case stmt.MetricDescriptor:
+		// merge descriptors of storage nodes, returns descriptor only with name if metric isn't described
+		var descriptors []*models.MetricDescriptor
+		for _, value := range values {
+			descriptor := &models.MetricDescriptor{}
+			if err := encoding.JSONUnmarshal([]byte(value), descriptor); err != nil {
+				return nil, err
+			}
+			descriptors = append(descriptors, descriptor)
+		}
+		descriptor := models.MergeMetricDescriptors(descriptors)
+		if descriptor == nil {
+			descriptor = &models.MetricDescriptor{Namespace: request.Namespace, Name: request.MetricName}
+		}
+		result.Values = descriptor
+	default:
+		result.Values = values
+	}
+	return result, nil
+}
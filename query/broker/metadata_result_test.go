@@ -0,0 +1,79 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package brokerquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestBuildMetadata(t *testing.T) {
+	// plain values
+	result, err := BuildMetadata(&stmt.Metadata{Type: stmt.TagKey}, []string{"host"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Metadata{Type: stmt.TagKey.String(), Values: []string{"host"}}, result)
+	// fields
+	fields := string(encoding.JSONMarshal(field.Metas{
+		{Name: "f1", Type: field.SumField},
+		{Name: "__bucket_0", Type: field.HistogramField},
+	}))
+	result, err = BuildMetadata(&stmt.Metadata{Type: stmt.Field}, []string{fields, fields})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Field{
+		{Name: "f1", Type: field.SumField.String()},
+		{Name: "quantile(0.99)", Type: field.HistogramField.String()},
+		{Name: "quantile(0.95)", Type: field.HistogramField.String()},
+		{Name: "quantile(0.90)", Type: field.HistogramField.String()},
+	}, result.Values)
+	// series cardinality
+	cardinality := string(encoding.JSONMarshal(&models.SeriesCardinality{Series: 10,
+		TagKeys: []models.TagKeyCardinality{{TagKey: "host"}, {TagKey: "ip"}}}))
+	result, err = BuildMetadata(&stmt.Metadata{Type: stmt.SeriesCardinality, MetricName: "cpu", Limit: 1},
+		[]string{cardinality})
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", result.Values.(*models.SeriesCardinality).MetricName)
+	assert.Len(t, result.Values.(*models.SeriesCardinality).TagKeys, 1)
+	// tag value cardinality
+	result, err = BuildMetadata(&stmt.Metadata{Type: stmt.TagValueCardinality, MetricName: "cpu", TagKey: "host"},
+		[]string{string(encoding.JSONMarshal(&models.TagValueCardinality{}))})
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", result.Values.(*models.TagValueCardinality).MetricName)
+	assert.Equal(t, "host", result.Values.(*models.TagValueCardinality).TagKey)
+	// metric descriptor
+	result, err = BuildMetadata(&stmt.Metadata{Type: stmt.MetricDescriptor, MetricName: "cpu"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.MetricDescriptor{Name: "cpu"}, result.Values)
+	result, err = BuildMetadata(&stmt.Metadata{Type: stmt.MetricDescriptor, MetricName: "cpu"},
+		[]string{string(encoding.JSONMarshal(&models.MetricDescriptor{Name: "cpu", Unit: "ms"}))})
+	assert.NoError(t, err)
+	assert.Equal(t, "ms", result.Values.(*models.MetricDescriptor).Unit)
+}
+
+func TestBuildMetadata_UnmarshalErr(t *testing.T) {
+	for _, metadataType := range []stmt.MetadataType{stmt.Field, stmt.SeriesCardinality,
+		stmt.TagValueCardinality, stmt.MetricDescriptor} {
+		_, err := BuildMetadata(&stmt.Metadata{Type: metadataType}, []string{"xx"})
+		assert.Error(t, err)
+	}
+}
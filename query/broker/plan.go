@@ -18,6 +18,9 @@
 package brokerquery
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/query"
@@ -25,15 +28,36 @@ import (
 	"github.com/lindb/lindb/sql/stmt"
 )
 
+// defaultStatementCacheSize is the max number of cached query statements
+const defaultStatementCacheSize = 1024
+
+// statementCache caches the parse result of query sql, dashboards issue lots of structurally identical queries.
+var statementCache = sql.NewStatementCache(defaultStatementCacheSize)
+
 // brokerPlan represents the broker execute plan
 type brokerPlan struct {
 	sql               string
 	query             *stmt.Query
+	outer             *stmt.Query // outer query if query has subquery
 	storageNodes      map[string][]int32
 	currentBrokerNode models.Node
 	brokerNodes       []models.ActiveNode
 	intermediateNodes []models.Node
 	databaseCfg       models.Database
+	queryDefaults     models.QueryDefaults
+	// shardReplicas are all queryable replicas of each shard, used for building hedged/retried leaf tasks
+	shardReplicas map[int32][]string
+	// params are the values bound to placeholders of sql, nil if query isn't parameterized
+	params map[string]string
+	// max points hint of client, downsampling interval is chosen automatically if points exceed it
+	maxPoints int
+	// explicit downsampling interval, overrides the interval of group by time
+	interval timeutil.Interval
+
+	// outOfRetention is true if whole time range of query is out of retention, no need to execute query.
+	outOfRetention bool
+	// warnings annotates the response, like time range clipped by retention
+	warnings []string
 
 	physicalPlan *models.PhysicalPlan
 }
@@ -42,6 +66,7 @@ type brokerPlan struct {
 func newBrokerPlan(
 	sql string,
 	databaseCfg models.Database,
+	queryDefaults models.QueryDefaults,
 	storageNodes map[string][]int32,
 	currentBrokerNode models.Node,
 	brokerNodes []models.ActiveNode,
@@ -49,6 +74,7 @@ func newBrokerPlan(
 	return &brokerPlan{
 		sql:               sql,
 		databaseCfg:       databaseCfg,
+		queryDefaults:     queryDefaults,
 		storageNodes:      storageNodes,
 		currentBrokerNode: currentBrokerNode,
 		brokerNodes:       brokerNodes,
@@ -68,13 +94,30 @@ func (p *brokerPlan) Plan() error {
 		return query.ErrNoAvailableStorageNode
 	}
 
-	qry, err := sql.Parse(p.sql)
+	prepared, err := statementCache.Prepare(p.sql)
+	if err != nil {
+		return err
+	}
+	qry, err := prepared.Bind(&sql.Options{
+		TimeRange: p.queryDefaults.GetTimeRange(),
+		Location:  p.queryDefaults.GetLocation(),
+		Params:    p.params,
+		Precision: p.databaseCfg.Option.GetPrecision(),
+	})
 	if err != nil {
 		return err
 	}
 	// set query statement
 	p.query = qry.(*stmt.Query)
+	if p.query.SubQuery != nil {
+		// inner query is executed by the plan, outer query aggregates the result of inner query on broker
+		p.outer = p.query
+		p.query = p.query.SubQuery
+	}
 
+	if p.interval > 0 {
+		p.query.Interval = p.interval
+	}
 	if p.query.Interval <= 0 {
 		var interval timeutil.Interval
 		if err := interval.ValueOf(p.databaseCfg.Option.Interval); err != nil {
@@ -82,9 +125,26 @@ func (p *brokerPlan) Plan() error {
 		}
 		p.query.Interval = interval
 	}
+	if maxPoints := p.targetMaxPoints(); maxPoints > 0 && p.outer == nil {
+		p.query.Interval = p.autoInterval(p.query.Interval, maxPoints)
+	}
 	intervalVal := int64(p.query.Interval)
 	p.query.TimeRange.Start = timeutil.Truncate(p.query.TimeRange.Start, intervalVal)
 	p.query.TimeRange.End = timeutil.Truncate(p.query.TimeRange.End, intervalVal)
+	if p.outer != nil {
+		if err := p.planOuterQuery(); err != nil {
+			return err
+		}
+	}
+	if p.clipTimeRange(intervalVal) {
+		// whole time range is out of retention, no need to build physical plan
+		return nil
+	}
+	// interval of outer query cannot be widened, reject if still exceeds cluster-wide max points
+	if p.queryDefaults.MaxPoints > 0 &&
+		timeutil.CalPointCount(p.query.TimeRange.Start, p.query.TimeRange.End, intervalVal) > p.queryDefaults.MaxPoints {
+		return query.ErrTooManyPoints
+	}
 
 	root := p.currentBrokerNode
 
@@ -106,10 +166,103 @@ func (p *brokerPlan) Plan() error {
 			Indicator: (&root).Indicator(),
 			NumOfTask: int32(lenOfStorageNodes)})
 		p.buildLeafs((&root).Indicator(), p.getStorageNodeIDs(), receivers)
+		p.buildFailovers()
+		p.buildHedges((&root).Indicator(), receivers)
 	}
 	return nil
 }
 
+// targetMaxPoints returns the max points for choosing downsampling interval, which is the smaller one of
+// max points hint and cluster-wide max points, returns 0 if both are not set.
+func (p *brokerPlan) targetMaxPoints() int {
+	maxPoints := p.maxPoints
+	if p.queryDefaults.MaxPoints > 0 && (maxPoints <= 0 || p.queryDefaults.MaxPoints < maxPoints) {
+		maxPoints = p.queryDefaults.MaxPoints
+	}
+	return maxPoints
+}
+
+// autoInterval chooses the downsampling interval based on max points, snaps to the interval hierarchy
+// of database(write interval and rollup intervals), uses multiple of the largest one if points still exceed.
+func (p *brokerPlan) autoInterval(interval timeutil.Interval, maxPoints int) timeutil.Interval {
+	timeRange := p.query.TimeRange
+	if timeutil.CalPointCount(timeRange.Start, timeRange.End, interval.Int64()) <= maxPoints {
+		return interval
+	}
+	largest := interval
+	for _, candidate := range p.intervalHierarchy() {
+		if candidate <= interval {
+			continue
+		}
+		if timeutil.CalPointCount(timeRange.Start, timeRange.End, candidate.Int64()) <= maxPoints {
+			return candidate
+		}
+		largest = candidate
+	}
+	pointCount := timeutil.CalPointCount(timeRange.Start, timeRange.End, largest.Int64())
+	return largest * timeutil.Interval((pointCount+maxPoints-1)/maxPoints)
+}
+
+// intervalHierarchy returns the write interval and rollup intervals of database in ascending order.
+func (p *brokerPlan) intervalHierarchy() []timeutil.Interval {
+	var intervals []timeutil.Interval
+	for _, intervalStr := range append([]string{p.databaseCfg.Option.Interval}, p.databaseCfg.Option.Rollup...) {
+		var interval timeutil.Interval
+		if err := interval.ValueOf(intervalStr); err == nil {
+			intervals = append(intervals, interval)
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i] < intervals[j]
+	})
+	return intervals
+}
+
+// planOuterQuery checks the interval of outer query which must be multiple of inner query's interval,
+// uses the interval of inner query if not set.
+func (p *brokerPlan) planOuterQuery() error {
+	if p.outer.Interval <= 0 {
+		p.outer.Interval = p.query.Interval
+		return nil
+	}
+	if p.outer.Interval < p.query.Interval || p.outer.Interval%p.query.Interval != 0 {
+		return fmt.Errorf("interval of outer query must be multiple of subquery's interval: %dms",
+			p.query.Interval.Int64())
+	}
+	return nil
+}
+
+// clipTimeRange clips the time range of query to retention window of database,
+// returns true if whole time range is out of retention.
+func (p *brokerPlan) clipTimeRange(interval int64) bool {
+	retention := p.databaseCfg.Option.GetRetention()
+	if retention <= 0 {
+		return false
+	}
+	minTime := timeutil.Now() - retention.Int64()
+	timeRange := &p.query.TimeRange
+	if timeRange.End < minTime {
+		p.outOfRetention = true
+		p.warnings = append(p.warnings,
+			fmt.Sprintf("time range is out of retention(%s), returns empty result", p.databaseCfg.Option.Retention))
+		return true
+	}
+	if timeRange.Start < minTime {
+		start := timeutil.Truncate(minTime, interval)
+		if start < minTime {
+			start += interval
+		}
+		if start > timeRange.End {
+			start = timeRange.End
+		}
+		p.warnings = append(p.warnings,
+			fmt.Sprintf("start time is clipped to %s by retention(%s)",
+				timeutil.FormatTimestamp(start, "2006-01-02 15:04:05"), p.databaseCfg.Option.Retention))
+		timeRange.Start = start
+	}
+	return false
+}
+
 // buildIntermediateNodes builds intermediate nodes if need
 func (p *brokerPlan) buildIntermediateNodes() {
 	if len(p.query.GroupBy) == 0 {
@@ -194,3 +347,88 @@ func (p *brokerPlan) buildLeafs(parentID string, nodeIDs []string, receivers []m
 		})
 	}
 }
+
+// buildHedges builds the hedged leaf tasks if hedge delay is set, hedged leaf searches the same shards of
+// primary leaf in other replica, which holds all shards of primary leaf.
+func (p *brokerPlan) buildHedges(parentID string, receivers []models.Node) {
+	hedgeDelay := p.queryDefaults.GetHedgeDelay()
+	if hedgeDelay <= 0 || len(p.shardReplicas) == 0 {
+		return
+	}
+	for _, leaf := range p.physicalPlan.Leafs {
+		nodeID, ok := p.findHedgeNode(leaf.Indicator, leaf.ShardIDs)
+		if !ok {
+			continue
+		}
+		p.physicalPlan.AddHedge(models.HedgeLeaf{
+			Leaf: models.Leaf{
+				BaseNode: models.BaseNode{
+					Parent:    parentID,
+					Indicator: nodeID,
+				},
+				ShardIDs:  leaf.ShardIDs,
+				Receivers: receivers,
+			},
+			Primary: leaf.Indicator,
+		})
+	}
+	if len(p.physicalPlan.Hedges) > 0 {
+		p.physicalPlan.HedgeDelay = hedgeDelay.Milliseconds()
+	}
+}
+
+// buildFailovers builds the replica nodes of each leaf for retrying leaf task on transient errors.
+func (p *brokerPlan) buildFailovers() {
+	if len(p.shardReplicas) == 0 {
+		return
+	}
+	for _, leaf := range p.physicalPlan.Leafs {
+		nodeIDs := p.findReplicaNodes(leaf.Indicator, leaf.ShardIDs)
+		if len(nodeIDs) == 0 {
+			continue
+		}
+		p.physicalPlan.AddFailover(models.FailoverLeaf{Primary: leaf.Indicator, Replicas: nodeIDs})
+	}
+}
+
+// findHedgeNode finds the fastest replica node(except primary node) which holds all shards.
+func (p *brokerPlan) findHedgeNode(primary string, shardIDs []int32) (string, bool) {
+	nodeIDs := p.findReplicaNodes(primary, shardIDs)
+	if len(nodeIDs) == 0 {
+		return "", false
+	}
+	return nodeIDs[0], true
+}
+
+// findReplicaNodes finds the replica nodes(except primary node) which hold all shards, the fastest first.
+func (p *brokerPlan) findReplicaNodes(primary string, shardIDs []int32) (nodeIDs []string) {
+	if len(shardIDs) == 0 {
+		return nil
+	}
+	for _, nodeID := range p.shardReplicas[shardIDs[0]] {
+		if nodeID == primary {
+			continue
+		}
+		if p.hasAllShards(nodeID, shardIDs[1:]) {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	return nodeIDs
+}
+
+// hasAllShards checks if node holds replicas of all shards.
+func (p *brokerPlan) hasAllShards(nodeID string, shardIDs []int32) bool {
+	for _, shardID := range shardIDs {
+		found := false
+		for _, replica := range p.shardReplicas[shardID] {
+			if replica == nodeID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -24,18 +24,20 @@ import (
 
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/query"
+	"github.com/lindb/lindb/sql/stmt"
 )
 
 func TestBrokerPlan_Wrong_Case(t *testing.T) {
-	plan := newBrokerPlan("sql", models.Database{}, nil, models.Node{}, nil)
+	plan := newBrokerPlan("sql", models.Database{}, models.NewDefaultQueryDefaults(), nil, models.Node{}, nil)
 	// storage nodes cannot be empty
 	err := plan.Plan()
 	assert.Equal(t, query.ErrNoAvailableStorageNode, err)
 
 	storageNodes := map[string][]int32{"1.1.1.1:8000": {1, 2, 4}}
 	// wrong sql
-	plan = newBrokerPlan("sql", models.Database{}, storageNodes, models.Node{}, nil)
+	plan = newBrokerPlan("sql", models.Database{}, models.NewDefaultQueryDefaults(), storageNodes, models.Node{}, nil)
 	err = plan.Plan()
 	assert.NotNil(t, err)
 }
@@ -46,6 +48,7 @@ func TestBrokerPlan_wrong_database_interval(t *testing.T) {
 	// no group sql
 	plan := newBrokerPlan("select f from cpu",
 		models.Database{Option: option.DatabaseOption{Interval: "s"}},
+		models.NewDefaultQueryDefaults(),
 		storageNodes, currentNode.Node, nil)
 	err := plan.Plan()
 	assert.Error(t, err)
@@ -56,17 +59,147 @@ func TestBrokerPlan_quantile(t *testing.T) {
 	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
 	plan := newBrokerPlan("select quantile(0.99) from cpu",
 		models.Database{Option: option.DatabaseOption{Interval: "s"}},
+		models.NewDefaultQueryDefaults(),
 		storageNodes, currentNode.Node, nil)
 	err := plan.Plan()
 	assert.Error(t, err)
 }
 
+func TestBrokerPlan_params(t *testing.T) {
+	storageNodes := map[string][]int32{"1.1.1.1:9000": {1, 2, 4}}
+	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
+	plan := newBrokerPlan("select f from cpu where host=$host",
+		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
+		storageNodes, currentNode.Node, nil)
+	plan.params = map[string]string{"host": "h1"}
+	err := plan.Plan()
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "host", Value: "h1"}, plan.query.Condition)
+	// parse result cached
+	assert.True(t, statementCache.Len() > 0)
+
+	// param not bound
+	plan = newBrokerPlan("select f from cpu where host=$host",
+		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
+		storageNodes, currentNode.Node, nil)
+	plan.params = map[string]string{}
+	err = plan.Plan()
+	assert.Error(t, err)
+}
+
+func TestBrokerPlan_subQuery(t *testing.T) {
+	storageNodes := map[string][]int32{"1.1.1.1:9000": {1, 2, 4}}
+	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
+	newPlan := func(sql string) *brokerPlan {
+		return newBrokerPlan(sql,
+			models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+			models.NewDefaultQueryDefaults(),
+			storageNodes, currentNode.Node, nil)
+	}
+	// interval of outer query is multiple of inner's
+	plan := newPlan("select max(f) from (select avg(f) as f from cpu group by host, time(1m)) group by time(5m)")
+	err := plan.Plan()
+	assert.NoError(t, err)
+	assert.NotNil(t, plan.outer)
+	assert.Nil(t, plan.query.SubQuery)
+	assert.Equal(t, []string{"host"}, plan.query.GroupBy)
+	assert.Equal(t, timeutil.Interval(5*timeutil.OneMinute), plan.outer.Interval)
+	// uses interval of inner query
+	plan = newPlan("select max(f) from (select avg(f) as f from cpu group by host)")
+	err = plan.Plan()
+	assert.NoError(t, err)
+	assert.Equal(t, timeutil.Interval(10*timeutil.OneSecond), plan.outer.Interval)
+	// interval of outer query isn't multiple of inner's
+	plan = newPlan("select max(f) from (select avg(f) as f from cpu group by host, time(1m)) group by time(90s)")
+	err = plan.Plan()
+	assert.Error(t, err)
+	plan = newPlan("select max(f) from (select avg(f) as f from cpu group by host, time(1m)) group by time(30s)")
+	err = plan.Plan()
+	assert.Error(t, err)
+}
+
+func TestBrokerPlan_interval(t *testing.T) {
+	storageNodes := map[string][]int32{"1.1.1.1:9000": {1, 2, 4}}
+	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
+	newPlan := func(sql string) *brokerPlan {
+		return newBrokerPlan(sql,
+			models.Database{Option: option.DatabaseOption{Interval: "10s", Rollup: []string{"1h", "5m"}}},
+			models.NewDefaultQueryDefaults(),
+			storageNodes, currentNode.Node, nil)
+	}
+	// explicit interval overrides group by time
+	plan := newPlan("select f from cpu where time>now()-1h group by time(1m)")
+	plan.interval = timeutil.Interval(5 * timeutil.OneMinute)
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(5*timeutil.OneMinute), plan.query.Interval)
+	// points not exceed max points hint
+	plan = newPlan("select f from cpu where time>now()-1h")
+	plan.maxPoints = 1000
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(10*timeutil.OneSecond), plan.query.Interval)
+	// snaps to rollup interval
+	plan = newPlan("select f from cpu where time>now()-1d")
+	plan.maxPoints = 1000
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(5*timeutil.OneMinute), plan.query.Interval)
+	plan = newPlan("select f from cpu where time>now()-7d group by time(1m)")
+	plan.maxPoints = 1000
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(timeutil.OneHour), plan.query.Interval)
+	// multiple of the largest interval
+	plan = newPlan("select f from cpu where time>now()-30d")
+	plan.maxPoints = 100
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(8*timeutil.OneHour), plan.query.Interval)
+	assert.True(t, timeutil.CalPointCount(plan.query.TimeRange.Start, plan.query.TimeRange.End,
+		plan.query.Interval.Int64()) <= 100)
+}
+
+func TestBrokerPlan_maxPoints(t *testing.T) {
+	storageNodes := map[string][]int32{"1.1.1.1:9000": {1, 2, 4}}
+	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
+	newPlan := func(sql string, clusterMaxPoints, maxPoints int) *brokerPlan {
+		queryDefaults := models.NewDefaultQueryDefaults()
+		queryDefaults.MaxPoints = clusterMaxPoints
+		plan := newBrokerPlan(sql,
+			models.Database{Option: option.DatabaseOption{Interval: "10s", Rollup: []string{"5m", "1h"}}},
+			queryDefaults,
+			storageNodes, currentNode.Node, nil)
+		plan.maxPoints = maxPoints
+		return plan
+	}
+	// cluster-wide max points is the downsampling target if no hint
+	plan := newPlan("select f from cpu where time>now()-1d", 1000, 0)
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(5*timeutil.OneMinute), plan.query.Interval)
+	// hint less than cluster-wide max points
+	plan = newPlan("select f from cpu where time>now()-1d", 1000, 100)
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(timeutil.OneHour), plan.query.Interval)
+	// hint greater than cluster-wide max points, cluster-wide max points wins
+	plan = newPlan("select f from cpu where time>now()-1d", 100, 1000)
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(timeutil.OneHour), plan.query.Interval)
+	// both not set
+	plan = newPlan("select f from cpu where time>now()-1d", 0, 0)
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, timeutil.Interval(10*timeutil.OneSecond), plan.query.Interval)
+	// interval of outer query cannot be widened, reject by cluster-wide max points
+	plan = newPlan("select max(f) from (select avg(f) as f from cpu where time>now()-1d group by host)", 100, 1000)
+	assert.Equal(t, query.ErrTooManyPoints, plan.Plan())
+	plan = newPlan("select max(f) from (select avg(f) as f from cpu where time>now()-1h group by host)", 1000, 0)
+	assert.NoError(t, plan.Plan())
+}
+
 func TestBrokerPlan_No_GroupBy(t *testing.T) {
 	storageNodes := map[string][]int32{"1.1.1.1:9000": {1, 2, 4}, "1.1.1.2:9000": {3, 5, 6}}
 	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
 	// no group sql
 	plan := newBrokerPlan("select f from cpu",
 		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
 		storageNodes, currentNode.Node, nil)
 	err := plan.Plan()
 	assert.NoError(t, err)
@@ -94,6 +227,81 @@ func TestBrokerPlan_No_GroupBy(t *testing.T) {
 	assert.Equal(t, 0, len(plan.physicalPlan.Intermediates))
 }
 
+func TestBrokerPlan_hedge(t *testing.T) {
+	storageNodes := map[string][]int32{"1.1.1.1:9000": {1, 2}, "1.1.1.2:9000": {3}}
+	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
+	queryDefaults := models.NewDefaultQueryDefaults()
+	queryDefaults.HedgeDelay = "50ms"
+	plan := newBrokerPlan("select f from cpu",
+		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		queryDefaults, storageNodes, currentNode.Node, nil)
+	plan.shardReplicas = map[int32][]string{
+		1: {"1.1.1.1:9000", "1.1.1.4:9000", "1.1.1.5:9000"},
+		2: {"1.1.1.5:9000", "1.1.1.1:9000"},
+		3: {"1.1.1.2:9000"},
+	}
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, int64(50), plan.physicalPlan.HedgeDelay)
+	// shard 3 has no other replica
+	assert.Equal(t, []models.HedgeLeaf{{
+		Leaf: models.Leaf{
+			BaseNode: models.BaseNode{
+				Parent:    "1.1.1.3:8000",
+				Indicator: "1.1.1.5:9000",
+			},
+			Receivers: []models.Node{currentNode.Node},
+			ShardIDs:  []int32{1, 2},
+		},
+		Primary: "1.1.1.1:9000",
+	}}, plan.physicalPlan.Hedges)
+	assert.Equal(t, []models.FailoverLeaf{
+		{Primary: "1.1.1.1:9000", Replicas: []string{"1.1.1.5:9000"}},
+	}, plan.physicalPlan.Failovers)
+
+	// hedging disabled
+	plan = newBrokerPlan("select f from cpu",
+		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(), storageNodes, currentNode.Node, nil)
+	plan.shardReplicas = map[int32][]string{1: {"1.1.1.4:9000"}, 2: {"1.1.1.4:9000"}}
+	assert.NoError(t, plan.Plan())
+	assert.Equal(t, int64(0), plan.physicalPlan.HedgeDelay)
+	assert.Empty(t, plan.physicalPlan.Hedges)
+	// failovers built without hedging
+	assert.Len(t, plan.physicalPlan.Failovers, 1)
+}
+
+func TestBrokerPlan_clip_by_retention(t *testing.T) {
+	storageNodes := map[string][]int32{"1.1.1.1:9000": {1, 2, 4}, "1.1.1.2:9000": {3, 5, 6}}
+	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
+	databaseCfg := models.Database{Option: option.DatabaseOption{Interval: "10s", Retention: "1h"}}
+
+	// time range in retention
+	plan := newBrokerPlan("select f from cpu where time>now()-30m",
+		databaseCfg, models.NewDefaultQueryDefaults(), storageNodes, currentNode.Node, nil)
+	assert.NoError(t, plan.Plan())
+	assert.False(t, plan.outOfRetention)
+	assert.Empty(t, plan.warnings)
+
+	// start time clipped
+	now := timeutil.Now()
+	plan = newBrokerPlan("select f from cpu where time>now()-3h",
+		databaseCfg, models.NewDefaultQueryDefaults(), storageNodes, currentNode.Node, nil)
+	assert.NoError(t, plan.Plan())
+	assert.False(t, plan.outOfRetention)
+	assert.Len(t, plan.warnings, 1)
+	assert.True(t, plan.query.TimeRange.Start >= now-timeutil.OneHour)
+	assert.True(t, plan.query.TimeRange.Start <= now-timeutil.OneHour+20*timeutil.OneSecond)
+	assert.NotNil(t, plan.physicalPlan)
+
+	// out of retention
+	plan = newBrokerPlan("select f from cpu where time>now()-5h and time<now()-2h",
+		databaseCfg, models.NewDefaultQueryDefaults(), storageNodes, currentNode.Node, nil)
+	assert.NoError(t, plan.Plan())
+	assert.True(t, plan.outOfRetention)
+	assert.Len(t, plan.warnings, 1)
+	assert.Nil(t, plan.physicalPlan)
+}
+
 func TestBrokerPlan_GroupBy_oddCount(t *testing.T) {
 	// odd number
 	oddStorageNodes := map[string][]int32{
@@ -107,6 +315,7 @@ func TestBrokerPlan_GroupBy_oddCount(t *testing.T) {
 	plan := newBrokerPlan(
 		"select f from cpu group by host",
 		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
 		oddStorageNodes,
 		currentNode.Node,
 		[]models.ActiveNode{
@@ -146,6 +355,7 @@ func TestBrokerPlan_GroupBy_evenCount(t *testing.T) {
 	plan := newBrokerPlan(
 		"select f from cpu group by host",
 		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
 		evenStorageNodes,
 		currentNode.Node,
 		[]models.ActiveNode{
@@ -182,6 +392,7 @@ func TestBrokerPlan_GroupBy_Less_StorageNodes(t *testing.T) {
 	plan := newBrokerPlan(
 		"select f from cpu group by host",
 		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
 		storageNodes,
 		currentNode.Node,
 		[]models.ActiveNode{
@@ -220,6 +431,7 @@ func TestBrokerPlan_GroupBy_Same_Broker(t *testing.T) {
 	plan := newBrokerPlan(
 		"select f from cpu group by host",
 		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
 		storageNodes,
 		currentNode.Node,
 		[]models.ActiveNode{currentNode})
@@ -249,6 +461,7 @@ func TestBrokerPlan_GroupBy_No_Broker(t *testing.T) {
 	plan := newBrokerPlan(
 		"select f from cpu group by host",
 		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
 		storageNodes,
 		currentNode.Node,
 		nil)
@@ -278,6 +491,7 @@ func TestBrokerPlan_GroupBy_One_StorageNode(t *testing.T) {
 	plan := newBrokerPlan(
 		"select f from cpu group by host",
 		models.Database{Option: option.DatabaseOption{Interval: "10s"}},
+		models.NewDefaultQueryDefaults(),
 		storageNodes,
 		currentNode.Node,
 		[]models.ActiveNode{
@@ -38,6 +38,7 @@ type metadataQuery struct {
 	metaStmtQuery *stmt.Metadata
 
 	results []string
+	total   int
 }
 
 // newMetadataQuery creates the execution which executes the job of parallel query
@@ -56,6 +57,13 @@ func newMetadataQuery(
 }
 
 func (mq *metadataQuery) WaitResponse() ([]string, error) {
+	if tenants := mq.runtime.tenants; tenants != nil {
+		namespace, err := tenants.AdmitQuery(query.NamespaceFromContext(mq.ctx), mq.metaStmtQuery.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		mq.metaStmtQuery.Namespace = namespace
+	}
 	physicalPlan, err := mq.makePlan()
 	if err != nil {
 		return nil, err
@@ -70,9 +78,14 @@ func (mq *metadataQuery) WaitResponse() ([]string, error) {
 		case result, ok := <-resultCh:
 			// received all data, break for loop
 			if !ok {
+				switch mq.metaStmtQuery.Type {
+				case stmt.SeriesCardinality, stmt.TagValueCardinality, stmt.MetricDescriptor:
+					// result is json encoded per storage node, cannot be deduped, merged by api
+					return mq.results, nil
+				}
 				deduped := strutil.DeDupStringSlice(mq.results)
 				sort.Strings(deduped)
-				return deduped, nil
+				return mq.paginate(mq.filterNamespaces(deduped)), nil
 			}
 			if result.ErrMsg != "" {
 				return nil, errors.New(result.ErrMsg)
@@ -117,11 +130,51 @@ func (mq *metadataQuery) makePlan() (*models.PhysicalPlan, error) {
 	return physicalPlan, nil
 }
 
+// filterNamespaces hides the namespaces of other tenants if isolation enabled.
+func (mq *metadataQuery) filterNamespaces(values []string) []string {
+	if !mq.isIsolated() {
+		return values
+	}
+	var namespaces []string
+	for _, value := range values {
+		if value == mq.metaStmtQuery.Namespace {
+			namespaces = append(namespaces, value)
+		}
+	}
+	return namespaces
+}
+
+// Total returns the total-count hint of matched values, it's valid after WaitResponse returns.
+func (mq *metadataQuery) Total() int {
+	return mq.total
+}
+
+// paginate applies offset/limit on the merged values of all storage nodes,
+// and builds the total-count hint as the max of storage nodes' hints and merged values count.
+func (mq *metadataQuery) paginate(values []string) []string {
+	if mq.metaStmtQuery.Type == stmt.Field {
+		// field metas are json encoded per storage node, merged by api
+		return values
+	}
+	if mq.total < len(values) || mq.isIsolated() {
+		mq.total = len(values)
+	}
+	return strutil.Paginate(values, mq.metaStmtQuery.Offset, mq.metaStmtQuery.Limit)
+}
+
+// isIsolated returns if the namespaces of other tenants are hidden.
+func (mq *metadataQuery) isIsolated() bool {
+	return mq.metaStmtQuery.Type == stmt.Namespace && mq.runtime.tenants != nil && mq.runtime.tenants.Isolation()
+}
+
 func (mq *metadataQuery) handleTaskResponse(resp *protoCommonV1.TaskResponse) error {
 	result := &models.SuggestResult{}
 	if err := encoding.JSONUnmarshal(resp.Payload, result); err != nil {
 		return err
 	}
 	mq.results = append(mq.results, result.Values...)
+	if result.Total > mq.total {
+		mq.total = result.Total
+	}
 	return nil
 }
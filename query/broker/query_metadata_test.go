@@ -19,6 +19,7 @@ package brokerquery
 
 import (
 	"context"
+	"errors"
 	"io"
 	"testing"
 	"time"
@@ -26,11 +27,15 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/coordinator/broker"
 	"github.com/lindb/lindb/coordinator/discovery"
+	"github.com/lindb/lindb/internal/tenant"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	"github.com/lindb/lindb/query"
 	"github.com/lindb/lindb/sql/stmt"
 	"github.com/lindb/lindb/tsdb/metadb"
 )
@@ -124,3 +129,123 @@ func Test_MetadataQuery(t *testing.T) {
 	_, err = metaDataQuery.WaitResponse()
 	assert.Error(t, err)
 }
+
+func Test_MetadataQuery_namespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	replicaStateMachine := broker.NewMockReplicaStatusStateMachine(ctrl)
+	nodeStateMachine := discovery.NewMockActiveNodeStateMachine(ctrl)
+	thisTaskManager := NewMockTaskManager(ctrl)
+	factory := &queryFactory{
+		replicaStateMachine: replicaStateMachine,
+		nodeStateMachine:    nodeStateMachine,
+		taskManager:         thisTaskManager,
+		tenants:             tenant.NewTenants(config.Tenant{Isolation: true}),
+	}
+	ctx := query.WithNamespace(context.Background(), "ns")
+
+	// case 1: namespace of other tenant
+	metaDataQuery := newMetadataQuery(ctx, "db", &stmt.Metadata{Type: stmt.Metric, Namespace: "other"}, factory)
+	_, err := metaDataQuery.WaitResponse()
+	assert.True(t, errors.Is(err, tenant.ErrNamespaceIsolated))
+
+	// case 2: namespaces of other tenants are hidden
+	replicaStateMachine.EXPECT().GetQueryableReplicas("db").
+		Return(map[string][]int32{"1.1.1.1:9000": {1}}).AnyTimes()
+	nodeStateMachine.EXPECT().GetCurrentNode().Return(models.Node{IP: "1.1.1.3", Port: 8000}).AnyTimes()
+	responseCh := make(chan *protoCommonV1.TaskResponse, 1)
+	responseCh <- &protoCommonV1.TaskResponse{
+		Payload: encoding.JSONMarshal(models.SuggestResult{Values: []string{"ns", "other"}}),
+	}
+	close(responseCh)
+	thisTaskManager.EXPECT().SubmitMetaDataTask(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ *models.PhysicalPlan, stmtQuery *stmt.Metadata) (<-chan *protoCommonV1.TaskResponse, error) {
+			assert.Equal(t, "ns", stmtQuery.Namespace)
+			return responseCh, nil
+		})
+	metaDataQuery = newMetadataQuery(ctx, "db",
+		&stmt.Metadata{Type: stmt.Namespace, Namespace: constants.DefaultNamespace}, factory)
+	results, err := metaDataQuery.WaitResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns"}, results)
+	assert.Equal(t, 1, metaDataQuery.Total())
+}
+
+func Test_MetadataQuery_pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	replicaStateMachine := broker.NewMockReplicaStatusStateMachine(ctrl)
+	nodeStateMachine := discovery.NewMockActiveNodeStateMachine(ctrl)
+	thisTaskManager := NewMockTaskManager(ctrl)
+	factory := &queryFactory{
+		replicaStateMachine: replicaStateMachine,
+		nodeStateMachine:    nodeStateMachine,
+		taskManager:         thisTaskManager,
+	}
+	replicaStateMachine.EXPECT().GetQueryableReplicas("db").
+		Return(map[string][]int32{"1.1.1.1:9000": {1}, "1.1.1.2:9000": {2}}).AnyTimes()
+	nodeStateMachine.EXPECT().GetCurrentNode().Return(models.Node{IP: "1.1.1.3", Port: 8000}).AnyTimes()
+
+	submit := func(responses ...models.SuggestResult) {
+		responseCh := make(chan *protoCommonV1.TaskResponse, len(responses))
+		for idx := range responses {
+			responseCh <- &protoCommonV1.TaskResponse{Payload: encoding.JSONMarshal(&responses[idx])}
+		}
+		close(responseCh)
+		thisTaskManager.EXPECT().SubmitMetaDataTask(gomock.Any(), gomock.Any()).Return(responseCh, nil)
+	}
+
+	// case 1: merge, then apply offset/limit
+	submit(models.SuggestResult{Values: []string{"a", "c"}, Total: 10},
+		models.SuggestResult{Values: []string{"b", "c"}, Total: 2})
+	metaDataQuery := newMetadataQuery(context.Background(), "db",
+		&stmt.Metadata{Type: stmt.Metric, Offset: 1, Limit: 1}, factory)
+	results, err := metaDataQuery.WaitResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, results)
+	assert.Equal(t, 10, metaDataQuery.Total())
+
+	// case 2: total hint at least merged values count
+	submit(models.SuggestResult{Values: []string{"a"}}, models.SuggestResult{Values: []string{"b"}})
+	metaDataQuery = newMetadataQuery(context.Background(), "db",
+		&stmt.Metadata{Type: stmt.TagKey, Offset: 5}, factory)
+	results, err = metaDataQuery.WaitResponse()
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Equal(t, 2, metaDataQuery.Total())
+
+	// case 3: field metas not paginated
+	submit(models.SuggestResult{Values: []string{"[]"}}, models.SuggestResult{Values: []string{"[{}]"}})
+	metaDataQuery = newMetadataQuery(context.Background(), "db",
+		&stmt.Metadata{Type: stmt.Field, Limit: 1}, factory)
+	results, err = metaDataQuery.WaitResponse()
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Zero(t, metaDataQuery.Total())
+
+	// case 4: series cardinality not deduped
+	submit(models.SuggestResult{Values: []string{"{}"}}, models.SuggestResult{Values: []string{"{}"}})
+	metaDataQuery = newMetadataQuery(context.Background(), "db",
+		&stmt.Metadata{Type: stmt.SeriesCardinality, Limit: 1}, factory)
+	results, err = metaDataQuery.WaitResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"{}", "{}"}, results)
+
+	// case 5: tag value cardinality not deduped
+	submit(models.SuggestResult{Values: []string{"{}"}}, models.SuggestResult{Values: []string{"{}"}})
+	metaDataQuery = newMetadataQuery(context.Background(), "db",
+		&stmt.Metadata{Type: stmt.TagValueCardinality, Limit: 1}, factory)
+	results, err = metaDataQuery.WaitResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"{}", "{}"}, results)
+
+	// case 6: metric descriptor not deduped
+	submit(models.SuggestResult{Values: []string{"{}"}}, models.SuggestResult{Values: []string{"{}"}})
+	metaDataQuery = newMetadataQuery(context.Background(), "db",
+		&stmt.Metadata{Type: stmt.MetricDescriptor}, factory)
+	results, err = metaDataQuery.WaitResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"{}", "{}"}, results)
+}
@@ -19,15 +19,21 @@ package brokerquery
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/lindb/lindb/aggregation"
+	"github.com/lindb/lindb/internal/dbstats"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/pkg/timeutil"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
 	"github.com/lindb/lindb/query"
 	"github.com/lindb/lindb/series"
+	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/series/tag"
 	"github.com/lindb/lindb/sql/stmt"
 )
@@ -39,6 +45,7 @@ type metricQuery struct {
 	ctx      context.Context
 	database string
 	sql      string
+	queryID  string
 
 	startTime   time.Time
 	endPlanTime time.Time
@@ -46,6 +53,8 @@ type metricQuery struct {
 	stmtQuery  *stmt.Query
 	plan       *brokerPlan
 	expression *aggregation.Expression
+	// pointFlags are the quality flags applied to all points of result set
+	pointFlags models.PointFlag
 }
 
 // newMetricQuery creates the execution which executes the job of parallel query
@@ -53,10 +62,12 @@ func newMetricQuery(
 	ctx context.Context,
 	database string,
 	sql string,
+	queryID string,
 	queryFactory *queryFactory,
 ) MetricQuery {
 	return &metricQuery{
 		sql:          sql,
+		queryID:      queryID,
 		database:     database,
 		ctx:          ctx,
 		queryFactory: queryFactory,
@@ -78,22 +89,56 @@ func (mq *metricQuery) makePlan() error {
 	if len(storageNodes) == 0 {
 		return query.ErrNoAvailableStorageNode
 	}
+	queryDefaults := mq.queryFactory.queryDefaultsStateMachine.GetQueryDefaults()
+	if !isAllShardsAvailable(databaseCfg, storageNodes) {
+		if !queryDefaults.PartialResults && !query.PartialResultsFromContext(mq.ctx) {
+			return query.ErrShardNotAvailable
+		}
+		// points are calculated without data of unavailable shards
+		mq.pointFlags |= models.PointPartial
+	}
 	brokerNodes := mq.queryFactory.nodeStateMachine.GetActiveNodes()
 
 	mq.plan = newBrokerPlan(
 		mq.sql,
 		databaseCfg,
+		queryDefaults,
 		storageNodes,
 		mq.queryFactory.nodeStateMachine.GetCurrentNode(),
 		brokerNodes,
 	)
+	mq.plan.shardReplicas = mq.queryFactory.replicaStateMachine.GetQueryableShardReplicas(mq.database)
+	mq.plan.params = query.ParamsFromContext(mq.ctx)
+	mq.plan.maxPoints = query.MaxPointsFromContext(mq.ctx)
+	mq.plan.interval = query.IntervalFromContext(mq.ctx)
 	if err := mq.plan.Plan(); err != nil {
 		return err
 	}
+	if tenants := mq.queryFactory.tenants; tenants != nil {
+		namespace, err := tenants.AdmitQuery(query.NamespaceFromContext(mq.ctx), mq.plan.query.Namespace)
+		if err != nil {
+			return err
+		}
+		mq.plan.query.Namespace = namespace
+	}
+
+	if query.StatsFromContext(mq.ctx) {
+		// collect execution stats in all nodes like explain query
+		mq.plan.query.Explain = true
+	}
+	if query.ExemplarsFromContext(mq.ctx) {
+		mq.plan.query.Exemplars = true
+	}
 
 	mq.startTime = startTime
-	mq.plan.physicalPlan.Database = mq.database
 	mq.stmtQuery = mq.plan.query
+	if isDownSampled(databaseCfg, mq.stmtQuery.Interval) {
+		mq.pointFlags |= models.PointDownSampled
+	}
+	if mq.plan.outOfRetention {
+		return nil
+	}
+	mq.plan.physicalPlan.Database = mq.database
 	mq.expression = aggregation.NewExpression(
 		mq.plan.query.TimeRange,
 		mq.plan.query.Interval.Int64(),
@@ -102,16 +147,46 @@ func (mq *metricQuery) makePlan() error {
 	return nil
 }
 
-// WaitResponse builds the plan, the dispatch the task by task-manager
-func (mq *metricQuery) WaitResponse() (*models.ResultSet, error) {
-	if err := mq.makePlan(); err != nil {
+// WaitResponse builds the plan, the dispatch the task by task-manager,
+// records the statistics of query for database.
+func (mq *metricQuery) WaitResponse() (resultSet *models.ResultSet, err error) {
+	startTime := time.Now()
+	var span *tracing.Span
+	mq.ctx, span = tracing.StartSpan(mq.ctx, "broker.metric_query")
+	span.SetAttribute("db", mq.database)
+	span.SetAttribute("queryID", mq.queryID)
+	span.SetAttribute("sql", mq.sql)
+	defer func() {
+		span.SetError(err)
+		span.End()
+		// skip unknown database, avoid recording arbitrary database name
+		if !errors.Is(err, query.ErrDatabaseNotExist) {
+			dbstats.RecordQuery(mq.database, time.Since(startTime), err)
+		}
+	}()
+	return mq.waitResponse()
+}
+
+// waitResponse builds the plan, the dispatch the task by task-manager
+func (mq *metricQuery) waitResponse() (*models.ResultSet, error) {
+	_, planSpan := tracing.StartSpan(mq.ctx, "broker.make_plan")
+	err := mq.makePlan()
+	planSpan.SetError(err)
+	planSpan.End()
+	if err != nil {
 		return nil, err
 	}
 	mq.endPlanTime = time.Now()
+	if mq.plan.outOfRetention {
+		// short-circuit, no data can be found out of retention
+		return mq.aggregateSubQuery(mq.makeEmptyResultSet()), nil
+	}
 
 	eventCh, err := mq.queryFactory.taskManager.SubmitMetricTask(
+		mq.ctx,
 		mq.plan.physicalPlan,
 		mq.plan.query,
+		mq.queryID,
 	)
 	// send error
 	if err != nil {
@@ -130,10 +205,25 @@ func (mq *metricQuery) WaitResponse() (*models.ResultSet, error) {
 			return nil, event.Err
 		}
 	case <-mq.ctx.Done():
-		return nil, ErrTimeout
+		if !query.PartialResultsFromContext(mq.ctx) {
+			return nil, ErrTimeout
+		}
+		// returns results of responded nodes, annotated with timed out nodes
+		event = mq.queryFactory.taskManager.CompletePartially(mq.queryID, ErrTimeout)
+		if event == nil {
+			return nil, ErrTimeout
+		}
 	}
 
-	return mq.makeResultSet(event), nil
+	return mq.aggregateSubQuery(mq.makeResultSet(event)), nil
+}
+
+// aggregateSubQuery aggregates the result set of inner query by outer query if query has subquery.
+func (mq *metricQuery) aggregateSubQuery(resultSet *models.ResultSet) *models.ResultSet {
+	if mq.plan.outer == nil {
+		return resultSet
+	}
+	return aggregateSubQuery(mq.plan.outer, resultSet)
 }
 
 func (mq *metricQuery) makeResultSet(event *series.TimeSeriesEvent) (resultSet *models.ResultSet) {
@@ -143,6 +233,7 @@ func (mq *metricQuery) makeResultSet(event *series.TimeSeriesEvent) (resultSet *
 	//TODO merge stats for cross idc query?
 	groupByKeys := mq.stmtQuery.GroupBy
 	groupByKeysLength := len(groupByKeys)
+	stringFields := getStringFields(event)
 	for _, ts := range event.SeriesList {
 		var tags map[string]string
 		if groupByKeysLength > 0 {
@@ -158,6 +249,9 @@ func (mq *metricQuery) makeResultSet(event *series.TimeSeriesEvent) (resultSet *
 			}
 		}
 		timeSeries := models.NewSeries(tags)
+		if len(event.Exemplars) > 0 {
+			timeSeries.Exemplars = toExemplars(event.Exemplars[ts.Tags()])
+		}
 		resultSet.AddSeries(timeSeries)
 		mq.expression.Eval(ts)
 		rs := mq.expression.ResultSet()
@@ -165,6 +259,19 @@ func (mq *metricQuery) makeResultSet(event *series.TimeSeriesEvent) (resultSet *
 			if values == nil {
 				continue
 			}
+			if _, ok := stringFields[fieldName]; ok {
+				// resolve string values of string field by value id
+				stringValues := make(map[int64]string)
+				it := values.NewIterator()
+				for it.HasNext() {
+					slot, val := it.Next()
+					if value, ok := event.StringValues[uint64(val)]; ok {
+						stringValues[timeutil.CalcTimestamp(mq.stmtQuery.TimeRange.Start, slot, mq.stmtQuery.Interval)] = value
+					}
+				}
+				timeSeries.AddStringField(fieldName, stringValues)
+				continue
+			}
 			points := models.NewPoints()
 			it := values.NewIterator()
 			for it.HasNext() {
@@ -176,10 +283,13 @@ func (mq *metricQuery) makeResultSet(event *series.TimeSeriesEvent) (resultSet *
 		mq.expression.Reset()
 	}
 
-	resultSet.MetricName = mq.stmtQuery.MetricName
-	resultSet.StartTime = mq.stmtQuery.TimeRange.Start
-	resultSet.EndTime = mq.stmtQuery.TimeRange.End
-	resultSet.Interval = mq.stmtQuery.Interval.Int64()
+	mq.fillResultSet(resultSet)
+	if len(event.Failures) > 0 {
+		resultSet.Flags |= models.PointPartial
+		resultSet.Failures = event.Failures
+		resultSet.Warnings = append(resultSet.Warnings,
+			fmt.Sprintf("partial results, %d node(s) failed or timed out", len(event.Failures)))
+	}
 
 	resultSet.Stats = event.Stats
 	if resultSet.Stats != nil {
@@ -191,3 +301,76 @@ func (mq *metricQuery) makeResultSet(event *series.TimeSeriesEvent) (resultSet *
 	}
 	return resultSet
 }
+
+// getStringFields returns the names of string fields which values need be resolved by string dictionary.
+func getStringFields(event *series.TimeSeriesEvent) map[string]struct{} {
+	if len(event.StringValues) == 0 {
+		return nil
+	}
+	stringFields := make(map[string]struct{})
+	for fieldName, spec := range event.AggregatorSpecs {
+		if field.Type(spec.FieldType) == field.StringField {
+			stringFields[fieldName] = struct{}{}
+		}
+	}
+	return stringFields
+}
+
+// toExemplars converts the exemplars of rpc response to the exemplars of result set.
+func toExemplars(exemplars []*protoCommonV1.Exemplar) []models.Exemplar {
+	if len(exemplars) == 0 {
+		return nil
+	}
+	result := make([]models.Exemplar, len(exemplars))
+	for idx, exemplar := range exemplars {
+		result[idx] = models.Exemplar{
+			TraceID:   exemplar.TraceID,
+			SpanID:    exemplar.SpanID,
+			Value:     exemplar.Value,
+			Timestamp: exemplar.Timestamp,
+			Bucket:    strconv.FormatFloat(exemplar.Bucket, 'f', -1, 64),
+		}
+	}
+	return result
+}
+
+// makeEmptyResultSet makes an empty result set with query info and warnings.
+func (mq *metricQuery) makeEmptyResultSet() *models.ResultSet {
+	resultSet := new(models.ResultSet)
+	mq.fillResultSet(resultSet)
+	return resultSet
+}
+
+// fillResultSet fills the query info and warnings of plan into result set.
+func (mq *metricQuery) fillResultSet(resultSet *models.ResultSet) {
+	resultSet.MetricName = mq.stmtQuery.MetricName
+	resultSet.StartTime = mq.stmtQuery.TimeRange.Start
+	resultSet.EndTime = mq.stmtQuery.TimeRange.End
+	resultSet.Interval = mq.stmtQuery.Interval.Int64()
+	resultSet.Fields = mq.stmtQuery.ResultFields()
+	resultSet.Flags = mq.pointFlags
+	if mq.plan != nil {
+		resultSet.Warnings = mq.plan.warnings
+	}
+}
+
+// isDownSampled checks if the query interval is larger than the storage interval of database,
+// which means points are rolled up from data points with smaller interval.
+func isDownSampled(databaseCfg models.Database, interval timeutil.Interval) bool {
+	var storageInterval timeutil.Interval
+	if err := storageInterval.ValueOf(databaseCfg.Option.Interval); err != nil {
+		return false
+	}
+	return interval > storageInterval
+}
+
+// isAllShardsAvailable checks if all shards of database have queryable replica.
+func isAllShardsAvailable(databaseCfg models.Database, storageNodes map[string][]int32) bool {
+	shards := make(map[int32]struct{})
+	for _, shardIDs := range storageNodes {
+		for _, shardID := range shardIDs {
+			shards[shardID] = struct{}{}
+		}
+	}
+	return len(shards) >= databaseCfg.NumOfShard
+}
@@ -19,7 +19,9 @@ package brokerquery
 
 import (
 	"context"
+	"errors"
 	"io"
+	"math"
 	"testing"
 	"time"
 
@@ -27,11 +29,16 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/aggregation"
+	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/coordinator/broker"
 	"github.com/lindb/lindb/coordinator/discovery"
+	"github.com/lindb/lindb/internal/dbstats"
+	"github.com/lindb/lindb/internal/tenant"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/pkg/timeutil"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	"github.com/lindb/lindb/query"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/sql"
@@ -47,13 +54,15 @@ func Test_MetricQuery(t *testing.T) {
 	dbStateMachine := broker.NewMockDatabaseStateMachine(ctrl)
 	nodeStateMachine.EXPECT().GetCurrentNode().Return(currentNode.Node).AnyTimes()
 	replicaStateMachine := broker.NewMockReplicaStatusStateMachine(ctrl)
+	queryDefaultsStateMachine := broker.NewMockQueryDefaultsStateMachine(ctrl)
 	taskManager := NewMockTaskManager(ctrl)
 
 	queryFactory := &queryFactory{
-		replicaStateMachine:  replicaStateMachine,
-		nodeStateMachine:     nodeStateMachine,
-		databaseStateMachine: dbStateMachine,
-		taskManager:          taskManager,
+		replicaStateMachine:       replicaStateMachine,
+		nodeStateMachine:          nodeStateMachine,
+		databaseStateMachine:      dbStateMachine,
+		queryDefaultsStateMachine: queryDefaultsStateMachine,
+		taskManager:               taskManager,
 	}
 	brokerNodes := []models.ActiveNode{
 		generateBrokerActiveNode("1.1.1.1", 8000),
@@ -67,22 +76,30 @@ func Test_MetricQuery(t *testing.T) {
 	qry := newMetricQuery(context.Background(),
 		"test_db",
 		"select f from cpu",
+		"",
 		queryFactory)
 	dbStateMachine.EXPECT().GetDatabaseCfg("test_db").Return(models.Database{}, false)
 	_, err := qry.WaitResponse()
 	assert.Error(t, err)
+	// query of unknown database not recorded
+	_, ok := dbstats.Get("test_db")
+	assert.False(t, ok)
 
 	// case 2: storage nodes not exist
 	dbStateMachine.EXPECT().GetDatabaseCfg("test_db").
-		Return(models.Database{Option: option.DatabaseOption{Interval: "10s"}}, true).
+		Return(models.Database{NumOfShard: 20, Option: option.DatabaseOption{Interval: "10s"}}, true).
 		AnyTimes()
 	qry = newMetricQuery(context.Background(),
 		"test_db",
 		"select f from cpu",
+		"",
 		queryFactory)
 	replicaStateMachine.EXPECT().GetQueryableReplicas("test_db").Return(nil)
 	_, err = qry.WaitResponse()
 	assert.Error(t, err)
+	stats, ok := dbstats.Get("test_db")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), stats.QueryFailures)
 
 	storageNodes := map[string][]int32{
 		"1.1.1.1:9000": {1, 2, 4},
@@ -93,35 +110,123 @@ func Test_MetricQuery(t *testing.T) {
 	}
 	replicaStateMachine.EXPECT().GetQueryableReplicas("test_db").
 		Return(storageNodes).AnyTimes()
+	replicaStateMachine.EXPECT().GetQueryableShardReplicas("test_db").Return(nil).AnyTimes()
 	nodeStateMachine.EXPECT().GetActiveNodes().
 		Return(brokerNodes).AnyTimes()
 
+	// partial results not allowed
+	queryDefaultsStateMachine.EXPECT().GetQueryDefaults().
+		Return(models.QueryDefaults{TimeRange: "1h", PartialResults: false})
+	qry = newMetricQuery(context.Background(),
+		"test_db",
+		"select f from cpu",
+		"",
+		queryFactory)
+	_, err = qry.WaitResponse()
+	assert.Equal(t, query.ErrShardNotAvailable, err)
+
+	// too many points
+	queryDefaultsStateMachine.EXPECT().GetQueryDefaults().
+		Return(models.QueryDefaults{TimeRange: "1h", MaxPoints: 10, PartialResults: true})
+	qry = newMetricQuery(context.Background(),
+		"test_db",
+		"select max(f) from (select avg(f) as f from cpu group by host)",
+		"",
+		queryFactory)
+	_, err = qry.WaitResponse()
+	assert.Equal(t, query.ErrTooManyPoints, err)
+
+	assert.True(t, isAllShardsAvailable(models.Database{NumOfShard: 15}, storageNodes))
+
+	queryDefaultsStateMachine.EXPECT().GetQueryDefaults().
+		Return(models.NewDefaultQueryDefaults()).AnyTimes()
+
 	// bad sql
 	qry = newMetricQuery(context.Background(),
 		"test_db",
 		"select f fro",
+		"",
 		queryFactory)
 	_, err = qry.WaitResponse()
 	assert.Error(t, err)
 
+	// namespace of other tenant
+	queryFactory.tenants = tenant.NewTenants(config.Tenant{Isolation: true})
+	qry = newMetricQuery(query.WithNamespace(context.Background(), "ns"),
+		"test_db", "select f on 'other' from cpu",
+		"",
+		queryFactory)
+	_, err = qry.WaitResponse()
+	assert.True(t, errors.Is(err, tenant.ErrNamespaceIsolated))
+	// statement without namespace queries the namespace of tenant
+	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *models.PhysicalPlan, stmtQuery *stmt.Query,
+			_ string) (<-chan *series.TimeSeriesEvent, error) {
+			assert.Equal(t, "ns", stmtQuery.Namespace)
+			return nil, io.ErrClosedPipe
+		})
+	qry = newMetricQuery(query.WithNamespace(context.Background(), "ns"),
+		"test_db", "select f from cpu",
+		"",
+		queryFactory)
+	_, err = qry.WaitResponse()
+	assert.Equal(t, io.ErrClosedPipe, err)
+	queryFactory.tenants = nil
+
+	// stats required, collects execution stats like explain query
+	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *models.PhysicalPlan, stmtQuery *stmt.Query,
+			_ string) (<-chan *series.TimeSeriesEvent, error) {
+			assert.True(t, stmtQuery.Explain)
+			return nil, io.ErrClosedPipe
+		})
+	qry = newMetricQuery(query.WithStats(context.Background()),
+		"test_db", "select f from cpu",
+		"",
+		queryFactory)
+	_, err = qry.WaitResponse()
+	assert.Equal(t, io.ErrClosedPipe, err)
+
 	// timeout
 	eventCh1 := make(chan *series.TimeSeriesEvent)
-	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any()).
+	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(eventCh1, nil)
 	ctx, cancel := context.WithCancel(context.Background())
 	qry = newMetricQuery(ctx,
 		"test_db", "select f from cpu",
+		"",
 		queryFactory)
 	time.AfterFunc(time.Millisecond*200, cancel)
 	_, err = qry.WaitResponse()
 	assert.Error(t, err)
 
+	// timeout, partial results allowed
+	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(eventCh1, nil).Times(2)
+	taskManager.EXPECT().CompletePartially("query-1", ErrTimeout).Return(nil)
+	ctx, cancel = context.WithCancel(query.WithPartialResults(context.Background()))
+	qry = newMetricQuery(ctx, "test_db", "select f from cpu", "query-1", queryFactory)
+	cancel()
+	_, err = qry.WaitResponse()
+	assert.Equal(t, ErrTimeout, err)
+	taskManager.EXPECT().CompletePartially("query-2", ErrTimeout).Return(&series.TimeSeriesEvent{
+		Failures: []models.NodeFailure{{Node: "1.1.1.1:9000", Error: ErrTimeout.Error()}},
+	})
+	ctx, cancel = context.WithCancel(query.WithPartialResults(context.Background()))
+	qry = newMetricQuery(ctx, "test_db", "select f from cpu", "query-2", queryFactory)
+	cancel()
+	rs, err := qry.WaitResponse()
+	assert.NoError(t, err)
+	assert.Len(t, rs.Failures, 1)
+	assert.True(t, rs.Flags.Has(models.PointPartial))
+
 	qry = newMetricQuery(context.Background(),
 		"test_db", "select f from cpu",
+		"",
 		queryFactory)
 	// has error
 	eventCh2 := make(chan *series.TimeSeriesEvent)
-	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any()).Return(eventCh2, nil)
+	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(eventCh2, nil)
 	time.AfterFunc(time.Millisecond*200, func() {
 		eventCh2 <- &series.TimeSeriesEvent{Err: io.ErrClosedPipe}
 	})
@@ -130,11 +235,48 @@ func Test_MetricQuery(t *testing.T) {
 
 	// closed channel
 	eventCh3 := make(chan *series.TimeSeriesEvent)
-	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any()).Return(eventCh3, nil)
+	taskManager.EXPECT().SubmitMetricTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(eventCh3, nil)
 	time.AfterFunc(time.Millisecond*200, func() { close(eventCh3) })
 	_, err = qry.WaitResponse()
 	assert.Error(t, err)
+}
+
+func Test_MetricQuery_out_of_retention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currentNode := generateBrokerActiveNode("1.1.1.3", 8000)
+	nodeStateMachine := discovery.NewMockActiveNodeStateMachine(ctrl)
+	dbStateMachine := broker.NewMockDatabaseStateMachine(ctrl)
+	replicaStateMachine := broker.NewMockReplicaStatusStateMachine(ctrl)
+	queryDefaultsStateMachine := broker.NewMockQueryDefaultsStateMachine(ctrl)
+	taskManager := NewMockTaskManager(ctrl)
+	queryFactory := &queryFactory{
+		replicaStateMachine:       replicaStateMachine,
+		nodeStateMachine:          nodeStateMachine,
+		databaseStateMachine:      dbStateMachine,
+		queryDefaultsStateMachine: queryDefaultsStateMachine,
+		taskManager:               taskManager,
+	}
+	nodeStateMachine.EXPECT().GetCurrentNode().Return(currentNode.Node).AnyTimes()
+	nodeStateMachine.EXPECT().GetActiveNodes().Return(nil).AnyTimes()
+	dbStateMachine.EXPECT().GetDatabaseCfg("test_db").
+		Return(models.Database{NumOfShard: 1, Option: option.DatabaseOption{Interval: "10s", Retention: "1d"}}, true)
+	replicaStateMachine.EXPECT().GetQueryableReplicas("test_db").
+		Return(map[string][]int32{"1.1.1.1:9000": {0}})
+	replicaStateMachine.EXPECT().GetQueryableShardReplicas("test_db").Return(nil)
+	queryDefaultsStateMachine.EXPECT().GetQueryDefaults().Return(models.NewDefaultQueryDefaults())
 
+	// no task submitted
+	qry := newMetricQuery(context.Background(),
+		"test_db", "select f from cpu where time>now()-5d and time<now()-3d",
+		"",
+		queryFactory)
+	rs, err := qry.WaitResponse()
+	assert.NoError(t, err)
+	assert.Empty(t, rs.Series)
+	assert.Equal(t, "cpu", rs.MetricName)
+	assert.Len(t, rs.Warnings, 1)
 }
 
 // mockSingleIterator returns mock an iterator of single field
@@ -194,15 +336,93 @@ func Test_MetricQuery_makeResultSet(t *testing.T) {
 	qry := &metricQuery{
 		expression: expression,
 		stmtQuery: &stmt.Query{
-			MetricName: "1",
-			TimeRange:  timeutil.TimeRange{End: 2, Start: 1},
+			MetricName:  "1",
+			SelectItems: query.SelectItems,
+			TimeRange:   timeutil.TimeRange{End: 2, Start: 1},
 		},
+		pointFlags: models.PointPartial,
 	}
-	_ = qry.makeResultSet(&series.TimeSeriesEvent{
+	rs := qry.makeResultSet(&series.TimeSeriesEvent{
 		SeriesList: []series.GroupedIterator{timeSeries},
 		Stats: &models.QueryStats{
 			TotalCost:   100,
 			ExpressCost: 200,
 		},
 	})
+	assert.Equal(t, models.PointPartial, rs.Flags)
+	assert.Empty(t, rs.Failures)
+	// result field named by alias
+	assert.Equal(t, []string{"f"}, rs.Fields)
+	assert.Contains(t, rs.Series[0].Fields, "f")
+
+	// failed nodes annotated
+	timeSeries.EXPECT().HasNext().Return(false)
+	qry.pointFlags = 0
+	rs = qry.makeResultSet(&series.TimeSeriesEvent{
+		SeriesList: []series.GroupedIterator{timeSeries},
+		Failures:   []models.NodeFailure{{Node: "1.1.1.1:9000", ShardIDs: []int32{1}, Error: "err"}},
+	})
+	assert.Equal(t, models.PointPartial, rs.Flags)
+	assert.Len(t, rs.Failures, 1)
+	assert.Len(t, rs.Warnings, 1)
+
+	// exemplars of series
+	timeSeries.EXPECT().HasNext().Return(false)
+	timeSeries.EXPECT().Tags().Return("")
+	rs = qry.makeResultSet(&series.TimeSeriesEvent{
+		SeriesList: []series.GroupedIterator{timeSeries},
+		Exemplars: map[string][]*protoCommonV1.Exemplar{
+			"": {{TraceID: "1a", Value: 2, Timestamp: 10, Bucket: math.Inf(1)}},
+		},
+	})
+	assert.Equal(t, []models.Exemplar{{TraceID: "1a", Value: 2, Timestamp: 10, Bucket: "+Inf"}}, rs.Series[0].Exemplars)
+}
+
+func Test_MetricQuery_makeResultSet_stringField(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var now, _ = timeutil.ParseTimestamp("20190702 19:10:00", "20060102 15:04:05")
+
+	series1 := mockTimeSeries(ctrl, now, "version", field.StringField, field.LastValue)
+	timeSeries := series.NewMockGroupedIterator(ctrl)
+	gomock.InOrder(
+		timeSeries.EXPECT().HasNext().Return(true),
+		timeSeries.EXPECT().Next().Return(series1),
+		timeSeries.EXPECT().HasNext().Return(false),
+	)
+	q, _ := sql.Parse("select version from cpu")
+	query := q.(*stmt.Query)
+	qry := &metricQuery{
+		expression: aggregation.NewExpression(timeutil.TimeRange{
+			Start: now,
+			End:   now + timeutil.OneHour*2,
+		}, timeutil.OneMinute, query.SelectItems),
+		stmtQuery: &stmt.Query{
+			MetricName:  "cpu",
+			SelectItems: query.SelectItems,
+			TimeRange:   timeutil.TimeRange{Start: now, End: now + timeutil.OneHour*2},
+			Interval:    timeutil.Interval(timeutil.OneMinute),
+		},
+	}
+	rs := qry.makeResultSet(&series.TimeSeriesEvent{
+		SeriesList: []series.GroupedIterator{timeSeries},
+		AggregatorSpecs: map[string]*protoCommonV1.AggregatorSpec{
+			"version": {FieldName: "version", FieldType: uint32(field.StringField)},
+		},
+		StringValues: map[uint64]string{4: "v1.0.0"},
+	})
+	// string field resolved by string values, value id without string value ignored
+	assert.NotContains(t, rs.Series[0].Fields, "version")
+	assert.Len(t, rs.Series[0].Strings["version"], 1)
+	for _, value := range rs.Series[0].Strings["version"] {
+		assert.Equal(t, "v1.0.0", value)
+	}
+}
+
+func Test_isDownSampled(t *testing.T) {
+	databaseCfg := models.Database{Option: option.DatabaseOption{Interval: "10s"}}
+	assert.False(t, isDownSampled(databaseCfg, timeutil.Interval(10*timeutil.OneSecond)))
+	assert.True(t, isDownSampled(databaseCfg, timeutil.Interval(timeutil.OneMinute)))
+	assert.False(t, isDownSampled(models.Database{}, timeutil.Interval(timeutil.OneMinute)))
 }
@@ -0,0 +1,153 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package brokerquery
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+// subQueryPoint represents the aggregated point of outer query in a time bucket.
+type subQueryPoint struct {
+	sum, min, max, count float64
+	flags                models.PointFlag
+}
+
+// add adds value of inner query's point into time bucket.
+func (p *subQueryPoint) add(value float64, flags models.PointFlag) {
+	if p.count == 0 || value < p.min {
+		p.min = value
+	}
+	if p.count == 0 || value > p.max {
+		p.max = value
+	}
+	p.sum += value
+	p.count++
+	p.flags |= flags
+}
+
+// value returns the aggregated value by function type.
+func (p *subQueryPoint) value(funcType function.FuncType) float64 {
+	switch funcType {
+	case function.Min:
+		return p.min
+	case function.Max:
+		return p.max
+	case function.Count:
+		return p.count
+	case function.Avg:
+		return p.sum / p.count
+	default:
+		return p.sum
+	}
+}
+
+// subQueryGroup represents the series of outer query grouped by tag keys.
+type subQueryGroup struct {
+	tags   map[string]string
+	fields map[string]map[int64]*subQueryPoint // result field name => time bucket => point
+}
+
+// aggregateSubQuery aggregates the result set of inner query by outer query on broker,
+// the series of inner query are grouped by group by tag keys of outer query,
+// the points are aggregated into time buckets by interval of outer query.
+func aggregateSubQuery(outer *stmt.Query, inner *models.ResultSet) *models.ResultSet {
+	resultSet := &models.ResultSet{
+		MetricName: inner.MetricName,
+		StartTime:  inner.StartTime,
+		EndTime:    inner.EndTime,
+		Interval:   outer.Interval.Int64(),
+		Fields:     outer.ResultFields(),
+		Stats:      inner.Stats,
+		Warnings:   inner.Warnings,
+		Failures:   inner.Failures,
+		Flags:      inner.Flags,
+	}
+	interval := outer.Interval.Int64()
+	groups := make(map[string]*subQueryGroup)
+	for _, series := range inner.Series {
+		tagValues := make([]string, len(outer.GroupBy))
+		for idx, tagKey := range outer.GroupBy {
+			tagValues[idx] = series.Tags[tagKey]
+		}
+		groupKey := strings.Join(tagValues, ",")
+		group, ok := groups[groupKey]
+		if !ok {
+			group = &subQueryGroup{fields: make(map[string]map[int64]*subQueryPoint)}
+			if len(outer.GroupBy) > 0 {
+				group.tags = make(map[string]string)
+				for idx, tagKey := range outer.GroupBy {
+					group.tags[tagKey] = tagValues[idx]
+				}
+			}
+			groups[groupKey] = group
+		}
+		for _, selectItem := range outer.SelectItems {
+			item := selectItem.(*stmt.SelectItem)
+			call := item.Expr.(*stmt.CallExpr)
+			fieldName := call.Params[0].(*stmt.FieldExpr).Name
+			resultName := item.ResultName()
+			buckets, ok := group.fields[resultName]
+			if !ok {
+				buckets = make(map[int64]*subQueryPoint)
+				group.fields[resultName] = buckets
+			}
+			for timestamp, value := range series.Fields[fieldName] {
+				if timestamp < resultSet.StartTime {
+					continue
+				}
+				bucket := resultSet.StartTime + (timestamp-resultSet.StartTime)/interval*interval
+				point, ok := buckets[bucket]
+				if !ok {
+					point = &subQueryPoint{}
+					buckets[bucket] = point
+				}
+				point.add(value, series.Flags[fieldName][timestamp])
+			}
+		}
+	}
+	groupKeys := make([]string, 0, len(groups))
+	for groupKey := range groups {
+		groupKeys = append(groupKeys, groupKey)
+	}
+	sort.Strings(groupKeys)
+	for _, groupKey := range groupKeys {
+		group := groups[groupKey]
+		series := models.NewSeries(group.tags)
+		for _, selectItem := range outer.SelectItems {
+			item := selectItem.(*stmt.SelectItem)
+			funcType := item.Expr.(*stmt.CallExpr).FuncType
+			resultName := item.ResultName()
+			buckets := group.fields[resultName]
+			if len(buckets) == 0 {
+				continue
+			}
+			points := models.NewPoints()
+			for bucket, point := range buckets {
+				points.AddPointWithFlags(bucket, point.value(funcType), point.flags)
+			}
+			series.AddField(resultName, points)
+		}
+		resultSet.AddSeries(series)
+	}
+	return resultSet
+}
@@ -0,0 +1,94 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package brokerquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func Test_aggregateSubQuery(t *testing.T) {
+	call := func(funcType function.FuncType, field string) *stmt.CallExpr {
+		return &stmt.CallExpr{FuncType: funcType, Params: []stmt.Expr{&stmt.FieldExpr{Name: field}}}
+	}
+	outer := &stmt.Query{
+		Interval: timeutil.Interval(2 * timeutil.OneMinute),
+		GroupBy:  []string{"region"},
+		SelectItems: []stmt.Expr{
+			&stmt.SelectItem{Expr: call(function.Max, "mean_f"), Alias: "max_f"},
+			&stmt.SelectItem{Expr: call(function.Min, "mean_f")},
+			&stmt.SelectItem{Expr: call(function.Sum, "mean_f")},
+			&stmt.SelectItem{Expr: call(function.Count, "mean_f")},
+			&stmt.SelectItem{Expr: call(function.Avg, "mean_f")},
+			&stmt.SelectItem{Expr: call(function.Sum, "not_found")},
+		},
+	}
+	inner := &models.ResultSet{
+		MetricName: "cpu",
+		StartTime:  0,
+		EndTime:    4 * timeutil.OneMinute,
+		Interval:   timeutil.OneMinute,
+		Flags:      models.PointPartial,
+	}
+	newSeries := func(host, region string, points map[int64]float64) *models.Series {
+		series := models.NewSeries(map[string]string{"host": host, "region": region})
+		p := models.NewPoints()
+		for timestamp, value := range points {
+			p.AddPoint(timestamp, value)
+		}
+		series.AddField("mean_f", p)
+		return series
+	}
+	inner.AddSeries(newSeries("h1", "sh", map[int64]float64{0: 1, timeutil.OneMinute: 3, 2 * timeutil.OneMinute: 5}))
+	inner.AddSeries(newSeries("h2", "sh", map[int64]float64{0: 2, -timeutil.OneMinute: 100}))
+	inner.AddSeries(newSeries("h3", "bj", map[int64]float64{timeutil.OneMinute: 4}))
+	inner.Series[0].Flags = map[string]map[int64]models.PointFlag{"mean_f": {timeutil.OneMinute: models.PointDownSampled}}
+
+	rs := aggregateSubQuery(outer, inner)
+	assert.Equal(t, "cpu", rs.MetricName)
+	assert.Equal(t, 2*timeutil.OneMinute, rs.Interval)
+	assert.Equal(t, models.PointPartial, rs.Flags)
+	assert.Equal(t, []string{"max_f", "min(mean_f)", "sum(mean_f)", "count(mean_f)", "avg(mean_f)", "sum(not_found)"}, rs.Fields)
+	assert.Len(t, rs.Series, 2)
+	// series sorted by group key
+	bj := rs.Series[0]
+	assert.Equal(t, map[string]string{"region": "bj"}, bj.Tags)
+	assert.Equal(t, map[int64]float64{0: 4}, bj.Fields["max_f"])
+	sh := rs.Series[1]
+	assert.Equal(t, map[string]string{"region": "sh"}, sh.Tags)
+	assert.Equal(t, map[int64]float64{0: 3, 2 * timeutil.OneMinute: 5}, sh.Fields["max_f"])
+	assert.Equal(t, map[int64]float64{0: 1, 2 * timeutil.OneMinute: 5}, sh.Fields["min(mean_f)"])
+	assert.Equal(t, map[int64]float64{0: 6, 2 * timeutil.OneMinute: 5}, sh.Fields["sum(mean_f)"])
+	assert.Equal(t, map[int64]float64{0: 3, 2 * timeutil.OneMinute: 1}, sh.Fields["count(mean_f)"])
+	assert.Equal(t, map[int64]float64{0: 2, 2 * timeutil.OneMinute: 5}, sh.Fields["avg(mean_f)"])
+	assert.NotContains(t, sh.Fields, "sum(not_found)")
+	assert.Equal(t, models.PointDownSampled, sh.Flags["max_f"][0])
+
+	// without group by
+	outer.GroupBy = nil
+	rs = aggregateSubQuery(outer, inner)
+	assert.Len(t, rs.Series, 1)
+	assert.Nil(t, rs.Series[0].Tags)
+	assert.Equal(t, map[int64]float64{0: 10, 2 * timeutil.OneMinute: 5}, rs.Series[0].Fields["sum(mean_f)"])
+}
@@ -19,6 +19,7 @@ package brokerquery
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -29,15 +30,70 @@ import (
 	"github.com/lindb/lindb/pkg/fasttime"
 	"github.com/lindb/lindb/pkg/ltoml"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	"github.com/lindb/lindb/query"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/sql/stmt"
 )
 
 var (
-	newGroupingAgg = aggregation.NewGroupingAggregator
+	newGroupingAgg = aggregation.NewGroupingAggregatorWithCapacity
 )
 
+// timeSeriesSetPool is a set of decoded time series sets, reused by task contexts.
+var timeSeriesSetPool = sync.Pool{New: func() interface{} {
+	return &timeSeriesSet{fields: make(map[field.Name][]byte)}
+}}
+
+// timeSeriesSet represents the decoded time series list of task response,
+// which is reused for decoding all responses of a task.
+type timeSeriesSet struct {
+	list   protoCommonV1.TimeSeriesList
+	fields map[field.Name][]byte
+}
+
+// getTimeSeriesSet picks a time series set from the pool.
+func getTimeSeriesSet() *timeSeriesSet {
+	return timeSeriesSetPool.Get().(*timeSeriesSet)
+}
+
+// putTimeSeriesSet returns the time series set to the pool.
+func putTimeSeriesSet(s *timeSeriesSet) {
+	s.reset()
+	timeSeriesSetPool.Put(s)
+}
+
+// unmarshal decodes the payload of task response, reusing the underlying series slice.
+func (s *timeSeriesSet) unmarshal(data []byte) error {
+	s.reset()
+	return s.list.Unmarshal(data)
+}
+
+// groupedFields returns the field data of time series, the returned map is reused by next call.
+func (s *timeSeriesSet) groupedFields(ts *protoCommonV1.TimeSeries) map[field.Name][]byte {
+	for k := range s.fields {
+		delete(s.fields, k)
+	}
+	for k, v := range ts.Fields {
+		s.fields[field.Name(k)] = v
+	}
+	return s.fields
+}
+
+// reset clears the decoded data, keeps the allocated slice for reusing.
+func (s *timeSeriesSet) reset() {
+	for idx := range s.list.TimeSeriesList {
+		s.list.TimeSeriesList[idx] = nil
+	}
+	s.list.TimeSeriesList = s.list.TimeSeriesList[:0]
+	// aggregator specs are retained by task context, cannot be reused
+	s.list.FieldAggSpecs = nil
+	s.list.StringValues = nil
+	for k := range s.fields {
+		delete(s.fields, k)
+	}
+}
+
 //go:generate mockgen -source=./task_context.go -destination=./task_context_mock.go -package=brokerquery
 
 // TaskContext represents the task context for distribution query and computing
@@ -56,6 +112,8 @@ type TaskContext interface {
 	WriteResponse(resp *protoCommonV1.TaskResponse, fromNode string)
 	// Done returns if the task has been done
 	Done() bool
+	// Release releases the pooled resources retained by the task after results merged
+	Release()
 }
 
 type baseTaskContext struct {
@@ -101,6 +159,9 @@ func (c *baseTaskContext) Done() bool {
 	return c.expectResults <= 0
 }
 
+// Release releases the resources retained by the task, do nothing by default
+func (c *baseTaskContext) Release() {}
+
 // metricTaskContext represents the task context for tacking task execution state
 type metricTaskContext struct {
 	baseTaskContext
@@ -109,9 +170,52 @@ type metricTaskContext struct {
 	stmtQuery *stmt.Query
 	groupAgg  aggregation.GroupingAggregator
 	stats     *models.QueryStats
+	// group cardinality stats for preallocating grouping aggregator
+	groupCardinality GroupCardinalityStats
+	// progress of task execution, updated incrementally when response arrives
+	totalTasks    int32
+	mergedGroups  int64
+	receivedBytes int64
+	doneTime      int64
 	// fieldname -> aggregator spec
 	// we will use it during intermediate tasks
 	aggregatorSpecs map[string]*protoCommonV1.AggregatorSpec
+	// pooled decoded time series set, reused by all task responses
+	seriesSet *timeSeriesSet
+	// exemplars of histogram series, tags => exemplars
+	exemplars map[string][]*protoCommonV1.Exemplar
+	// string values of string fields, value id => string value
+	stringValues map[uint64]string
+
+	// hedged/retried leaf tasks, task id => primary leaf node
+	subTasks map[string]string
+	// race state of primary/hedged leaf task, primary leaf node => hedged slot
+	hedgedSlots map[string]*hedgedSlot
+	// cancelLoser cancels the loser of primary/hedged leaf task
+	cancelLoser func(nodeID, taskID string, hedgeWon bool)
+	// retryLeaf re-sends the leaf task of primary node to other replica, returns false if not retried
+	retryLeaf func(primary string) bool
+	// retried times of leaf task, primary leaf node => retried times
+	retries map[string]int
+
+	// partialResults allows returning results of responded nodes, if some nodes fail or time out
+	partialResults bool
+	// nodes not responded yet, node => shard ids
+	pendingNodes map[string][]int32
+	// failed/timed out nodes
+	failures []models.NodeFailure
+}
+
+// hedgedSlot represents the race state of primary leaf task and its hedged leaf task,
+// the root takes whichever responds first.
+type hedgedSlot struct {
+	hedgeNode   string
+	hedgeTaskID string
+	attempts    int // num. of sent tasks(primary + hedge)
+	responses   int // num. of received responses
+	done        bool
+	// error response is held if other attempt is still pending
+	errResp *protoCommonV1.TaskResponse
 }
 
 // metricTaskContext creates the task context based on params
@@ -123,6 +227,7 @@ func newMetricTaskContext(
 	stmtQuery *stmt.Query,
 	expectResults int32,
 	eventCh chan<- *series.TimeSeriesEvent,
+	groupCardinality GroupCardinalityStats,
 ) TaskContext {
 	return &metricTaskContext{
 		baseTaskContext: baseTaskContext{
@@ -134,17 +239,202 @@ func newMetricTaskContext(
 			closed:        false,
 			createTime:    fasttime.UnixMilliseconds(),
 		},
-		aggregatorSpecs: make(map[string]*protoCommonV1.AggregatorSpec),
-		stmtQuery:       stmtQuery,
-		eventCh:         eventCh,
+		aggregatorSpecs:  make(map[string]*protoCommonV1.AggregatorSpec),
+		stmtQuery:        stmtQuery,
+		eventCh:          eventCh,
+		groupCardinality: groupCardinality,
+		totalTasks:       expectResults,
+	}
+}
+
+// Progress returns the execution progress of current task.
+func (c *metricTaskContext) Progress() *models.QueryProgress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	completed := c.totalTasks - c.expectResults
+	if completed > c.totalTasks {
+		completed = c.totalTasks
+	}
+	done := c.expectResults <= 0
+	end := fasttime.UnixMilliseconds()
+	if done {
+		end = c.doneTime
+	}
+	return &models.QueryProgress{
+		TotalTasks:     c.totalTasks,
+		CompletedTasks: completed,
+		MergedGroups:   c.mergedGroups,
+		ReceivedBytes:  ltoml.Size(c.receivedBytes),
+		Elapsed:        ltoml.Duration(time.Duration(end-c.createTime) * time.Millisecond),
+		Done:           done,
+	}
+}
+
+// addHedge adds the hedged leaf task which searches the same shards of primary leaf in other replica.
+func (c *metricTaskContext) addHedge(hedgeTaskID, primary, hedgeNode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.hedgedSlots == nil {
+		c.hedgedSlots = make(map[string]*hedgedSlot)
+	}
+	c.addSubTask(hedgeTaskID, primary)
+	c.hedgedSlots[primary] = &hedgedSlot{
+		hedgeNode:   hedgeNode,
+		hedgeTaskID: hedgeTaskID,
+		attempts:    1,
+	}
+}
+
+// addSubTask adds the hedged/retried leaf task which searches the same shards of primary leaf.
+// NOTE: must be called with lock held.
+func (c *metricTaskContext) addSubTask(taskID, primary string) {
+	if c.subTasks == nil {
+		c.subTasks = make(map[string]string)
+	}
+	c.subTasks[taskID] = primary
+}
+
+// addRetry adds the retried leaf task of primary leaf node.
+func (c *metricTaskContext) addRetry(retryTaskID, primary string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addSubTask(retryTaskID, primary)
+}
+
+// retry retries the leaf task of primary node if response is retryable error(stream broken, node restarting),
+// returns true if the leaf task is re-sent to other replica.
+func (c *metricTaskContext) retry(resp *protoCommonV1.TaskResponse, primary string) bool {
+	if resp.ErrMsg == "" || c.retryLeaf == nil || !query.IsRetryableError(resp.ErrMsg) {
+		return false
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return false
+	}
+	// retried leaf task is not hedged
+	delete(c.hedgedSlots, primary)
+	c.mu.Unlock()
+
+	return c.retryLeaf(primary)
+}
+
+// nextRetry increases the retried times of leaf task, returns the retry attempt.
+func (c *metricTaskContext) nextRetry(primary string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.retries == nil {
+		c.retries = make(map[string]int)
+	}
+	c.retries[primary]++
+	return c.retries[primary]
+}
+
+// beginHedge marks the hedged leaf task sent, returns false if primary leaf task has responded.
+func (c *metricTaskContext) beginHedge(primary string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slot, ok := c.hedgedSlots[primary]
+	if !ok || slot.done || c.closed {
+		return false
+	}
+	slot.attempts++
+	return true
+}
+
+// abortHedge rollbacks the hedged leaf task if sent failure,
+// writes the held error response of primary leaf task if exist.
+func (c *metricTaskContext) abortHedge(primary string) {
+	c.mu.Lock()
+	slot, ok := c.hedgedSlots[primary]
+	if !ok || slot.done {
+		c.mu.Unlock()
+		return
+	}
+	slot.attempts--
+	errResp := slot.errResp
+	if errResp != nil {
+		slot.done = true
+	}
+	c.mu.Unlock()
+
+	if errResp != nil {
+		c.writeResponse(errResp, primary, primary)
 	}
 }
 
+// raceHedge checks the response of hedged slot, returns the primary node of slot,
+// returns false if the response need be dropped:
+// 1) the other task of slot has responded
+// 2) error response, but the other task is still pending
+func (c *metricTaskContext) raceHedge(
+	resp *protoCommonV1.TaskResponse,
+	fromNode string,
+) (primary string, accepted bool, cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	primary, isHedge := c.subTasks[resp.TaskID]
+	if !isHedge {
+		primary = fromNode
+	}
+	slot, ok := c.hedgedSlots[primary]
+	if !ok {
+		return primary, true, nil
+	}
+	if slot.done {
+		return primary, false, nil
+	}
+	slot.responses++
+	if resp.ErrMsg != "" && slot.responses < slot.attempts {
+		slot.errResp = resp
+		return primary, false, nil
+	}
+	slot.done = true
+	if slot.responses >= slot.attempts || c.cancelLoser == nil {
+		return primary, true, nil
+	}
+	if isHedge {
+		return primary, true, func() { c.cancelLoser(primary, c.taskID, true) }
+	}
+	return primary, true, func() { c.cancelLoser(slot.hedgeNode, slot.hedgeTaskID, false) }
+}
+
+// expectNodes sets the nodes which the root task waits for, used for annotating the missing nodes
+// if partial results are allowed, node => shard ids(empty for intermediate node).
+func (c *metricTaskContext) expectNodes(nodes map[string][]int32, partialResults bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pendingNodes = nodes
+	c.partialResults = partialResults
+}
+
 func (c *metricTaskContext) WriteResponse(resp *protoCommonV1.TaskResponse, fromNode string) {
+	node, accepted, cancel := c.raceHedge(resp, fromNode)
+	if cancel != nil {
+		cancel()
+	}
+	if !accepted || c.retry(resp, node) {
+		return
+	}
+	c.writeResponse(resp, fromNode, node)
+}
+
+// writeResponse merges the task response, node is the expected node of response(primary node if hedged).
+func (c *metricTaskContext) writeResponse(resp *protoCommonV1.TaskResponse, fromNode, node string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.expectResults--
+	c.receivedBytes += int64(len(resp.Stats) + len(resp.Payload))
+	shardIDs := c.pendingNodes[node]
+	delete(c.pendingNodes, node)
 
 	// preventing close channel twice
 	if c.closed {
@@ -154,32 +444,95 @@ func (c *metricTaskContext) WriteResponse(resp *protoCommonV1.TaskResponse, from
 		if c.expectResults <= 0 {
 			close(c.eventCh)
 			c.closed = true
+			c.doneTime = fasttime.UnixMilliseconds()
 		}
 	}()
 
 	if err := c.handleTaskResponse(resp, fromNode); err != nil {
-		select {
-		case c.eventCh <- &series.TimeSeriesEvent{Err: err, Stats: c.stats}:
-		default:
-			// reader gone
+		if !c.partialResults {
+			c.emitError(err)
+			return
 		}
-		return
+		// partial results allowed, records the failed node, then waits other responses
+		c.failures = append(c.failures, models.NodeFailure{Node: node, ShardIDs: shardIDs, Error: err.Error()})
 	}
 	// not done yet
 	if c.expectResults > 0 {
 		return
 	}
+	if c.groupAgg == nil && len(c.failures) > 0 {
+		// all nodes failed, no partial results
+		c.emitError(errors.New(c.failures[0].Error))
+		return
+	}
 
 	select {
-	case c.eventCh <- &series.TimeSeriesEvent{
-		AggregatorSpecs: c.aggregatorSpecs,
-		SeriesList:      c.groupAgg.ResultSet(),
-		Stats:           c.stats}:
+	case c.eventCh <- c.makeResultEvent():
 	default:
 		// reader gone
 	}
 }
 
+// emitError sends the error event to the reader.
+func (c *metricTaskContext) emitError(err error) {
+	select {
+	case c.eventCh <- &series.TimeSeriesEvent{Err: err, Stats: c.stats}:
+	default:
+		// reader gone
+	}
+}
+
+// makeResultEvent makes the result event based on merged responses and failed nodes.
+func (c *metricTaskContext) makeResultEvent() *series.TimeSeriesEvent {
+	var seriesList series.GroupedIterators
+	if c.groupAgg != nil {
+		seriesList = c.groupAgg.ResultSet()
+	}
+	if c.groupCardinality != nil && len(c.failures) == 0 {
+		c.groupCardinality.Record(c.stmtQuery, len(seriesList))
+	}
+	for tags, exemplars := range c.exemplars {
+		c.exemplars[tags] = query.LatestExemplars(exemplars)
+	}
+	return &series.TimeSeriesEvent{
+		AggregatorSpecs: c.aggregatorSpecs,
+		SeriesList:      seriesList,
+		Stats:           c.stats,
+		Failures:        c.failures,
+		Exemplars:       c.exemplars,
+		StringValues:    c.stringValues,
+	}
+}
+
+// completePartially completes the task with merged responses before all nodes responded(like query timeout),
+// the pending nodes are annotated as missing, returns nil if partial results not allowed or no response merged.
+func (c *metricTaskContext) completePartially(reason error) *series.TimeSeriesEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || !c.partialResults {
+		return nil
+	}
+	close(c.eventCh)
+	c.closed = true
+	c.doneTime = fasttime.UnixMilliseconds()
+	c.expectResults = 0
+
+	if c.groupAgg == nil {
+		return nil
+	}
+	nodes := make([]string, 0, len(c.pendingNodes))
+	for node := range c.pendingNodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		c.failures = append(c.failures, models.NodeFailure{Node: node, ShardIDs: c.pendingNodes[node], Error: reason.Error()})
+	}
+	c.pendingNodes = nil
+	return c.makeResultEvent()
+}
+
 func (c *metricTaskContext) handleStats(resp *protoCommonV1.TaskResponse, fromNode string) {
 	if len(resp.Stats) == 0 {
 		return
@@ -210,14 +563,23 @@ func (c *metricTaskContext) handleTaskResponse(resp *protoCommonV1.TaskResponse,
 		return errors.New(resp.ErrMsg)
 	}
 
-	tsList := &protoCommonV1.TimeSeriesList{}
-	if err := tsList.Unmarshal(resp.Payload); err != nil {
+	if c.seriesSet == nil {
+		c.seriesSet = getTimeSeriesSet()
+	}
+	if err := c.seriesSet.unmarshal(resp.Payload); err != nil {
 		return err
 	}
+	tsList := &c.seriesSet.list
 
 	for _, spec := range tsList.FieldAggSpecs {
 		c.aggregatorSpecs[spec.FieldName] = spec
 	}
+	for _, stringValue := range tsList.StringValues {
+		if c.stringValues == nil {
+			c.stringValues = make(map[uint64]string)
+		}
+		c.stringValues[stringValue.Id] = stringValue.Value
+	}
 
 	if c.groupAgg == nil {
 		AggregatorSpecs := make(aggregation.AggregatorSpecs, len(tsList.FieldAggSpecs))
@@ -230,12 +592,18 @@ func (c *metricTaskContext) handleTaskResponse(resp *protoCommonV1.TaskResponse,
 				AggregatorSpecs[idx].AddFunctionType(function.FuncType(funcType))
 			}
 		}
+		// preallocate groups based on historical group cardinality of the same query.
+		capacity := 0
+		if c.groupCardinality != nil {
+			capacity = c.groupCardinality.Get(c.stmtQuery)
+		}
 		// interval ratio is 1 when do merge result.
 		c.groupAgg = newGroupingAgg(
 			c.stmtQuery.Interval,
 			1,
 			c.stmtQuery.TimeRange,
 			AggregatorSpecs,
+			capacity,
 		)
 	}
 
@@ -244,15 +612,29 @@ func (c *metricTaskContext) handleTaskResponse(resp *protoCommonV1.TaskResponse,
 		if len(ts.Fields) == 0 {
 			return nil
 		}
-		fields := make(map[field.Name][]byte)
-		for k, v := range ts.Fields {
-			fields[field.Name(k)] = v
+		c.groupAgg.Aggregate(series.NewGroupedIterator(ts.Tags, c.seriesSet.groupedFields(ts)))
+		c.mergedGroups++
+		if len(ts.Exemplars) > 0 {
+			if c.exemplars == nil {
+				c.exemplars = make(map[string][]*protoCommonV1.Exemplar)
+			}
+			c.exemplars[ts.Tags] = append(c.exemplars[ts.Tags], ts.Exemplars...)
 		}
-		c.groupAgg.Aggregate(series.NewGroupedIterator(ts.Tags, fields))
 	}
 	return nil
 }
 
+// Release returns the pooled time series set after results merged
+func (c *metricTaskContext) Release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.seriesSet != nil {
+		putTimeSeriesSet(c.seriesSet)
+		c.seriesSet = nil
+	}
+}
+
 // metaDataTaskContext represents the task context for tacking task execution state
 type metaDataTaskContext struct {
 	baseTaskContext
@@ -21,12 +21,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/aggregation"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/timeutil"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	"github.com/lindb/lindb/query"
 	"github.com/lindb/lindb/series"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/sql/stmt"
 )
 
 func Test_TaskContext_metaDataTaskContext(t *testing.T) {
@@ -67,6 +73,7 @@ func Test_TaskContext_metricTaskContext(t *testing.T) {
 		nil,
 		2,
 		ch,
+		nil,
 	)
 
 	// sent omitted
@@ -90,6 +97,133 @@ func Test_TaskContext_metricTaskContext(t *testing.T) {
 	)
 }
 
+func Test_TaskContext_hedge(t *testing.T) {
+	ch := make(chan *series.TimeSeriesEvent)
+	taskCtx := newMetricTaskContext("1", RootTask, "", "", nil, 3, ch, nil).(*metricTaskContext)
+	type cancelled struct {
+		nodeID, taskID string
+		hedgeWon       bool
+	}
+	var cancels []cancelled
+	taskCtx.cancelLoser = func(nodeID, taskID string, hedgeWon bool) {
+		cancels = append(cancels, cancelled{nodeID: nodeID, taskID: taskID, hedgeWon: hedgeWon})
+	}
+	taskCtx.addHedge("1-hedge-0", "1.1.1.1", "1.1.1.4")
+	taskCtx.addHedge("1-hedge-1", "1.1.1.2", "1.1.1.5")
+	taskCtx.addHedge("1-hedge-2", "1.1.1.3", "1.1.1.6")
+
+	// hedge wins, primary cancelled
+	assert.True(t, taskCtx.beginHedge("1.1.1.1"))
+	_, accepted, cancel := taskCtx.raceHedge(&protoCommonV1.TaskResponse{TaskID: "1-hedge-0"}, "1.1.1.4")
+	assert.True(t, accepted)
+	cancel()
+	_, accepted, cancel = taskCtx.raceHedge(&protoCommonV1.TaskResponse{TaskID: "1"}, "1.1.1.1")
+	assert.False(t, accepted)
+	assert.Nil(t, cancel)
+	assert.False(t, taskCtx.beginHedge("1.1.1.1"))
+
+	// primary wins, hedge cancelled
+	assert.True(t, taskCtx.beginHedge("1.1.1.2"))
+	_, accepted, cancel = taskCtx.raceHedge(&protoCommonV1.TaskResponse{TaskID: "1"}, "1.1.1.2")
+	assert.True(t, accepted)
+	cancel()
+	assert.Equal(t, []cancelled{
+		{nodeID: "1.1.1.1", taskID: "1", hedgeWon: true},
+		{nodeID: "1.1.1.5", taskID: "1-hedge-1", hedgeWon: false},
+	}, cancels)
+
+	// error response is held if other task is pending
+	assert.True(t, taskCtx.beginHedge("1.1.1.3"))
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{TaskID: "1", ErrMsg: "err"}, "1.1.1.3")
+	assert.Equal(t, int32(3), taskCtx.expectResults)
+	// hedge sent failure, write held error response
+	taskCtx.abortHedge("1.1.1.3")
+	assert.Equal(t, int32(2), taskCtx.expectResults)
+	taskCtx.abortHedge("1.1.1.3")
+	assert.Equal(t, int32(2), taskCtx.expectResults)
+
+	// not hedged node
+	_, accepted, cancel = taskCtx.raceHedge(&protoCommonV1.TaskResponse{TaskID: "1"}, "1.1.1.9")
+	assert.True(t, accepted)
+	assert.Nil(t, cancel)
+}
+
+func Test_TaskContext_retry(t *testing.T) {
+	ch := make(chan *series.TimeSeriesEvent, 1)
+	taskCtx := newMetricTaskContext("1", RootTask, "", "", &stmt.Query{}, 1, ch, nil).(*metricTaskContext)
+	retryErr := &protoCommonV1.TaskResponse{TaskID: "1", ErrMsg: query.ErrNoDatabase.Error()}
+	// retry not set
+	assert.False(t, taskCtx.retry(retryErr, "1.1.1.1"))
+
+	retried := true
+	taskCtx.retryLeaf = func(primary string) bool {
+		assert.Equal(t, "1.1.1.1", primary)
+		return retried
+	}
+	taskCtx.addHedge("1-hedge-0", "1.1.1.1", "1.1.1.4")
+	// not retryable error
+	assert.False(t, taskCtx.retry(&protoCommonV1.TaskResponse{TaskID: "1", ErrMsg: "err"}, "1.1.1.1"))
+	// retried, hedge dropped
+	taskCtx.WriteResponse(retryErr, "1.1.1.1")
+	assert.False(t, taskCtx.Done())
+	assert.False(t, taskCtx.beginHedge("1.1.1.1"))
+	assert.Equal(t, 1, taskCtx.nextRetry("1.1.1.1"))
+	// response of retried task
+	taskCtx.addRetry("1-retry-0-1", "1.1.1.1")
+	retried = false
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{TaskID: "1-retry-0-1", ErrMsg: query.ErrNoDatabase.Error()}, "1.1.1.2")
+	assert.True(t, taskCtx.Done())
+	assert.Error(t, (<-ch).Err)
+	// task closed
+	retried = true
+	assert.False(t, taskCtx.retry(retryErr, "1.1.1.1"))
+}
+
+func Test_TaskContext_partialResults(t *testing.T) {
+	newTaskCtx := func(partial bool) (*metricTaskContext, chan *series.TimeSeriesEvent) {
+		ch := make(chan *series.TimeSeriesEvent, 1)
+		taskCtx := newMetricTaskContext("1", RootTask, "", "", &stmt.Query{}, 2, ch, nil).(*metricTaskContext)
+		taskCtx.expectNodes(map[string][]int32{"1.1.1.1": {1, 2}, "1.1.1.2": {3}}, partial)
+		return taskCtx, ch
+	}
+	// partial results not allowed
+	taskCtx, ch := newTaskCtx(false)
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{ErrMsg: "err"}, "1.1.1.1")
+	assert.EqualError(t, (<-ch).Err, "err")
+	assert.Nil(t, taskCtx.completePartially(ErrTimeout))
+
+	// failed node annotated
+	taskCtx, ch = newTaskCtx(true)
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{ErrMsg: "err"}, "1.1.1.1")
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{}, "1.1.1.2")
+	event := <-ch
+	assert.NoError(t, event.Err)
+	assert.Equal(t, []models.NodeFailure{{Node: "1.1.1.1", ShardIDs: []int32{1, 2}, Error: "err"}}, event.Failures)
+	assert.True(t, taskCtx.Done())
+
+	// all nodes failed
+	taskCtx, ch = newTaskCtx(true)
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{ErrMsg: "err1"}, "1.1.1.1")
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{ErrMsg: "err2"}, "1.1.1.2")
+	assert.EqualError(t, (<-ch).Err, "err1")
+
+	// timed out, no node responded
+	taskCtx, _ = newTaskCtx(true)
+	assert.Nil(t, taskCtx.completePartially(ErrTimeout))
+	assert.True(t, taskCtx.Done())
+
+	// timed out, pending node annotated
+	taskCtx, ch = newTaskCtx(true)
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{}, "1.1.1.1")
+	event = taskCtx.completePartially(ErrTimeout)
+	assert.Equal(t, []models.NodeFailure{{Node: "1.1.1.2", ShardIDs: []int32{3}, Error: ErrTimeout.Error()}}, event.Failures)
+	assert.Nil(t, taskCtx.completePartially(ErrTimeout))
+	_, ok := <-ch
+	assert.False(t, ok)
+	// response after completed
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{}, "1.1.1.2")
+}
+
 func Test_TaskContext_handleStats(t *testing.T) {
 	taskCtx3 := newMetricTaskContext(
 		"1",
@@ -99,6 +233,7 @@ func Test_TaskContext_handleStats(t *testing.T) {
 		nil,
 		2,
 		nil,
+		nil,
 	).(*metricTaskContext)
 	//
 	storageNodeStat1 := models.NewStorageStats()
@@ -131,3 +266,105 @@ func Test_TaskContext_handleStats(t *testing.T) {
 		"2")
 	assert.Len(t, taskCtx3.stats.BrokerNodes, 2)
 }
+
+func Test_TaskContext_groupCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newGroupingAgg = aggregation.NewGroupingAggregatorWithCapacity
+		ctrl.Finish()
+	}()
+	groupAgg := aggregation.NewMockGroupingAggregator(ctrl)
+	var capacity int
+	newGroupingAgg = func(interval timeutil.Interval, intervalRatio int, timeRange timeutil.TimeRange,
+		aggSpecs aggregation.AggregatorSpecs, c int) aggregation.GroupingAggregator {
+		capacity = c
+		return groupAgg
+	}
+	stats := NewGroupCardinalityStats()
+	q := &stmt.Query{Namespace: "ns", MetricName: "cpu", GroupBy: []string{"host"}}
+	stats.Record(q, 10)
+
+	ch := make(chan *series.TimeSeriesEvent, 1)
+	taskCtx := newMetricTaskContext("1", RootTask, "", "", q, 1, ch, stats)
+	groupAgg.EXPECT().ResultSet().Return(make(series.GroupedIterators, 20))
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{}, "1.1.1.1")
+	assert.Equal(t, 10, capacity)
+	assert.Equal(t, 20, stats.Get(q))
+	e := <-ch
+	assert.Len(t, e.SeriesList, 20)
+}
+
+func Test_TaskContext_exemplars(t *testing.T) {
+	ch := make(chan *series.TimeSeriesEvent, 1)
+	q := &stmt.Query{Namespace: "ns", MetricName: "cpu", Exemplars: true}
+	taskCtx := newMetricTaskContext("1", RootTask, "", "", q, 2, ch, nil)
+	seriesList := &protoCommonV1.TimeSeriesList{
+		TimeSeriesList: []*protoCommonV1.TimeSeries{{
+			Fields:    map[string][]byte{"f1": {1}},
+			Exemplars: []*protoCommonV1.Exemplar{{TraceID: "1a", Timestamp: 20}},
+		}},
+		FieldAggSpecs: []*protoCommonV1.AggregatorSpec{{FieldName: "f1", FieldType: uint32(field.HistogramField)}},
+	}
+	data, _ := seriesList.Marshal()
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{Payload: data}, "1.1.1.1")
+	seriesList.TimeSeriesList[0].Exemplars = []*protoCommonV1.Exemplar{{TraceID: "2b", Timestamp: 10}}
+	data, _ = seriesList.Marshal()
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{Payload: data}, "1.1.1.2")
+	e := <-ch
+	assert.Equal(t, map[string][]*protoCommonV1.Exemplar{
+		"": {{TraceID: "2b", Timestamp: 10}, {TraceID: "1a", Timestamp: 20}},
+	}, e.Exemplars)
+}
+
+func Test_TaskContext_stringValues(t *testing.T) {
+	ch := make(chan *series.TimeSeriesEvent, 1)
+	q := &stmt.Query{Namespace: "ns", MetricName: "cpu"}
+	taskCtx := newMetricTaskContext("1", RootTask, "", "", q, 2, ch, nil)
+	seriesList := &protoCommonV1.TimeSeriesList{
+		TimeSeriesList: []*protoCommonV1.TimeSeries{{Fields: map[string][]byte{"version": {1}}}},
+		FieldAggSpecs:  []*protoCommonV1.AggregatorSpec{{FieldName: "version", FieldType: uint32(field.StringField)}},
+		StringValues:   []*protoCommonV1.StringValue{{Id: 1, Value: "v1.0.0"}},
+	}
+	data, _ := seriesList.Marshal()
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{Payload: data}, "1.1.1.1")
+	seriesList.StringValues = []*protoCommonV1.StringValue{{Id: 2, Value: "v2.0.0"}}
+	data, _ = seriesList.Marshal()
+	taskCtx.WriteResponse(&protoCommonV1.TaskResponse{Payload: data}, "1.1.1.2")
+	e := <-ch
+	assert.Equal(t, map[uint64]string{1: "v1.0.0", 2: "v2.0.0"}, e.StringValues)
+}
+
+func Test_TaskContext_timeSeriesSet(t *testing.T) {
+	set := getTimeSeriesSet()
+	seriesList := &protoCommonV1.TimeSeriesList{
+		TimeSeriesList: []*protoCommonV1.TimeSeries{
+			{Tags: "host=1", Fields: map[string][]byte{"f1": {1}}},
+			{Tags: "host=2", Fields: map[string][]byte{"f2": {2}}},
+		},
+		FieldAggSpecs: []*protoCommonV1.AggregatorSpec{{FieldName: "f1"}},
+	}
+	data, _ := seriesList.Marshal()
+	assert.NoError(t, set.unmarshal(data))
+	assert.Len(t, set.list.TimeSeriesList, 2)
+	assert.Equal(t, map[field.Name][]byte{"f1": {1}}, set.groupedFields(set.list.TimeSeriesList[0]))
+	assert.Equal(t, map[field.Name][]byte{"f2": {2}}, set.groupedFields(set.list.TimeSeriesList[1]))
+	// decode again, reuse the set
+	seriesList.TimeSeriesList = seriesList.TimeSeriesList[:1]
+	data, _ = seriesList.Marshal()
+	assert.NoError(t, set.unmarshal(data))
+	assert.Len(t, set.list.TimeSeriesList, 1)
+	assert.Equal(t, "host=1", set.list.TimeSeriesList[0].Tags)
+	assert.Error(t, set.unmarshal([]byte{1, 2, 3}))
+	putTimeSeriesSet(set)
+	assert.Empty(t, set.list.TimeSeriesList)
+	assert.Empty(t, set.fields)
+
+	// release metric task context
+	taskCtx := newMetricTaskContext("1", RootTask, "", "", nil, 1, make(chan *series.TimeSeriesEvent), nil).(*metricTaskContext)
+	taskCtx.seriesSet = getTimeSeriesSet()
+	taskCtx.Release()
+	assert.Nil(t, taskCtx.seriesSet)
+	taskCtx.Release()
+	// base task context
+	newMetaDataTaskContext("1", RootTask, "", "", 1, nil).Release()
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/lindb/lindb/internal/concurrent"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/logger"
@@ -37,6 +38,9 @@ import (
 	"github.com/lindb/lindb/sql/stmt"
 )
 
+// maxLeafRetries is the max retried times of leaf task on transient errors.
+const maxLeafRetries = 2
+
 //go:generate mockgen -source=./task_manager.go -destination=./task_manager_mock.go -package=brokerquery
 
 // TaskManager represents the task manager for current node
@@ -47,9 +51,13 @@ type TaskManager interface {
 	// 1. api -> metric-query -> SubmitMetricTask (query without intermediate nodes) -> leaf nodes
 	//                                                                            -> leaf nodes -> response
 	// 2. api -> metric-query -> SubmitMetricTask (query with intermediate nodes) <-> peer broker <->
+	// The progress of the task can be tracked by query id, if query id is empty, uses root task id.
+	// The trace context in ctx is propagated to intermediates and leafs via task request's metadata.
 	SubmitMetricTask(
+		ctx context.Context,
 		physicalPlan *models.PhysicalPlan,
 		stmtQuery *stmt.Query,
+		queryID string,
 	) (eventCh <-chan *series.TimeSeriesEvent, err error)
 
 	// SubmitIntermediateMetricTask creates a intermediate task from leaf nodes
@@ -74,6 +82,15 @@ type TaskManager interface {
 
 	// Receive receives task response from rpc handler asynchronous
 	Receive(req *protoCommonV1.TaskResponse, targetNode string) error
+
+	// QueryProgress returns the execution progress of metric query by query id,
+	// the progress of completed query is kept until task ttl expired.
+	QueryProgress(queryID string) (*models.QueryProgress, bool)
+
+	// CompletePartially completes the running metric query with results of responded nodes if partial results
+	// are allowed, the nodes not responded are annotated with reason,
+	// returns nil if partial results not allowed or no node responded.
+	CompletePartially(queryID string, reason error) *series.TimeSeriesEvent
 }
 
 // taskManager implements the task manager interface, tracks all task of the current node
@@ -86,17 +103,27 @@ type taskManager struct {
 
 	workerPool concurrent.Pool // workers for
 	tasks      sync.Map        // taskID -> taskCtx
+	queries    sync.Map        // queryID -> root metric taskCtx, for tracking query progress
+	subTasks   sync.Map        // hedged/retried taskID -> root taskID, for routing response of hedged/retried leaf task
 	logger     *logger.Logger
 	ttl        time.Duration
-
-	createdTaskCounter   *linmetric.BoundDeltaCounter
-	aliveTaskGauge       *linmetric.BoundGauge
-	emitResponseCounter  *linmetric.BoundDeltaCounter
-	omitResponseCounter  *linmetric.BoundDeltaCounter
-	sentRequestCounter   *linmetric.BoundDeltaCounter
-	sentResponsesCounter *linmetric.BoundDeltaCounter
-	sentResponseFailures *linmetric.BoundDeltaCounter
-	sentRequestFailures  *linmetric.BoundDeltaCounter
+	// group cardinality of group by query, used for preallocating grouping aggregator
+	groupCardinality GroupCardinalityStats
+
+	createdTaskCounter    *linmetric.BoundDeltaCounter
+	aliveTaskGauge        *linmetric.BoundGauge
+	emitResponseCounter   *linmetric.BoundDeltaCounter
+	omitResponseCounter   *linmetric.BoundDeltaCounter
+	sentRequestCounter    *linmetric.BoundDeltaCounter
+	sentResponsesCounter  *linmetric.BoundDeltaCounter
+	sentResponseFailures  *linmetric.BoundDeltaCounter
+	sentRequestFailures   *linmetric.BoundDeltaCounter
+	receivedBytesCounter  *linmetric.BoundDeltaCounter
+	hedgedRequestCounter  *linmetric.BoundDeltaCounter
+	hedgeWinCounter       *linmetric.BoundDeltaCounter
+	cancelRequestCounter  *linmetric.BoundDeltaCounter
+	retriedRequestCounter *linmetric.BoundDeltaCounter
+	retryExhaustedCounter *linmetric.BoundDeltaCounter
 }
 
 // NewTaskManager creates the task manager
@@ -110,22 +137,29 @@ func NewTaskManager(
 ) TaskManager {
 	taskManagerScope := linmetric.NewScope("lindb.broker.query")
 	tm := &taskManager{
-		ctx:                  ctx,
-		currentNodeID:        (&currentNode).Indicator(),
-		taskClientFactory:    taskClientFactory,
-		taskServerFactory:    taskServerFactory,
-		seq:                  atomic.NewInt64(0),
-		workerPool:           taskPool,
-		logger:               logger.GetLogger("query", "TaskManager"),
-		ttl:                  ttl,
-		createdTaskCounter:   taskManagerScope.NewDeltaCounter("created_tasks"),
-		aliveTaskGauge:       taskManagerScope.NewGauge("alive_tasks"),
-		emitResponseCounter:  taskManagerScope.NewDeltaCounter("emitted_responses"),
-		omitResponseCounter:  taskManagerScope.NewDeltaCounter("omitted_responses"),
-		sentRequestCounter:   taskManagerScope.NewDeltaCounter("sent_requests"),
-		sentResponsesCounter: taskManagerScope.NewDeltaCounter("sent_responses"),
-		sentResponseFailures: taskManagerScope.NewDeltaCounter("sent_responses_failures"),
-		sentRequestFailures:  taskManagerScope.NewDeltaCounter("sent_requests_failures"),
+		ctx:                   ctx,
+		currentNodeID:         (&currentNode).Indicator(),
+		taskClientFactory:     taskClientFactory,
+		taskServerFactory:     taskServerFactory,
+		seq:                   atomic.NewInt64(0),
+		workerPool:            taskPool,
+		logger:                logger.GetLogger("query", "TaskManager"),
+		ttl:                   ttl,
+		groupCardinality:      NewGroupCardinalityStats(),
+		createdTaskCounter:    taskManagerScope.NewDeltaCounter("created_tasks"),
+		aliveTaskGauge:        taskManagerScope.NewGauge("alive_tasks"),
+		emitResponseCounter:   taskManagerScope.NewDeltaCounter("emitted_responses"),
+		omitResponseCounter:   taskManagerScope.NewDeltaCounter("omitted_responses"),
+		sentRequestCounter:    taskManagerScope.NewDeltaCounter("sent_requests"),
+		sentResponsesCounter:  taskManagerScope.NewDeltaCounter("sent_responses"),
+		sentResponseFailures:  taskManagerScope.NewDeltaCounter("sent_responses_failures"),
+		sentRequestFailures:   taskManagerScope.NewDeltaCounter("sent_requests_failures"),
+		receivedBytesCounter:  taskManagerScope.NewDeltaCounter("received_response_bytes"),
+		hedgedRequestCounter:  taskManagerScope.NewDeltaCounter("hedged_requests"),
+		hedgeWinCounter:       taskManagerScope.NewDeltaCounter("hedge_wins"),
+		cancelRequestCounter:  taskManagerScope.NewDeltaCounter("cancelled_requests"),
+		retriedRequestCounter: taskManagerScope.NewDeltaCounter("retried_requests"),
+		retryExhaustedCounter: taskManagerScope.NewDeltaCounter("retry_exhausted"),
 	}
 	duration := ttl
 	if ttl < time.Minute {
@@ -148,6 +182,19 @@ func (t *taskManager) cleaner(duration time.Duration) {
 				if taskCtx.Expired(t.ttl) {
 					t.aliveTaskGauge.Decr()
 					t.tasks.Delete(key)
+					taskCtx.Release()
+				}
+				return true
+			})
+			t.queries.Range(func(key, value interface{}) bool {
+				if value.(TaskContext).Expired(t.ttl) {
+					t.queries.Delete(key)
+				}
+				return true
+			})
+			t.subTasks.Range(func(key, value interface{}) bool {
+				if t.Get(value.(string)) == nil {
+					t.subTasks.Delete(key)
 				}
 				return true
 			})
@@ -158,9 +205,10 @@ func (t *taskManager) cleaner(duration time.Duration) {
 }
 
 func (t *taskManager) evictTask(taskID string) {
-	_, loaded := t.tasks.LoadAndDelete(taskID)
+	taskCtx, loaded := t.tasks.LoadAndDelete(taskID)
 	if loaded {
 		t.aliveTaskGauge.Decr()
+		taskCtx.(TaskContext).Release()
 	}
 }
 
@@ -171,10 +219,23 @@ func (t *taskManager) storeTask(taskID string, taskCtx TaskContext) {
 }
 
 func (t *taskManager) SubmitMetricTask(
+	ctx context.Context,
 	physicalPlan *models.PhysicalPlan,
 	stmtQuery *stmt.Query,
+	queryID string,
 ) (eventCh <-chan *series.TimeSeriesEvent, err error) {
 	rootTaskID := t.AllocTaskID()
+	if queryID == "" {
+		queryID = rootTaskID
+	}
+	ctx, span := tracing.StartSpan(ctx, "broker.submit_metric_task")
+	span.SetAttribute("taskID", rootTaskID)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	metadata := make(map[string]string)
+	tracing.Inject(ctx, metadata)
 	marshalledPhysicalPlan := encoding.JSONMarshal(physicalPlan)
 	marshalledPayload, _ := stmtQuery.MarshalJSON()
 	responseCh := make(chan *series.TimeSeriesEvent)
@@ -187,8 +248,19 @@ func (t *taskManager) SubmitMetricTask(
 		stmtQuery,
 		physicalPlan.Root.NumOfTask,
 		responseCh,
+		t.groupCardinality,
 	)
+	if prev, loaded := t.queries.LoadOrStore(queryID, taskCtx); loaded {
+		if !prev.(TaskContext).Done() {
+			return nil, fmt.Errorf("%w, queryID: %s", query.ErrQueryIDConflict, queryID)
+		}
+		t.queries.Store(queryID, taskCtx)
+	}
+	taskCtx.(*metricTaskContext).expectNodes(expectedNodes(physicalPlan), query.PartialResultsFromContext(ctx))
 	t.storeTask(rootTaskID, taskCtx)
+	metricTaskCtx := taskCtx.(*metricTaskContext)
+	t.prepareHedges(rootTaskID, metricTaskCtx, physicalPlan)
+	t.prepareRetries(ctx, rootTaskID, metricTaskCtx, physicalPlan, marshalledPayload, queryID, metadata)
 
 	// return the channel for reader, then send the rpc request
 	// in case of too early response arriving without reader
@@ -204,16 +276,21 @@ func (t *taskManager) SubmitMetricTask(
 			RequestType:  protoCommonV1.RequestType_Data,
 			PhysicalPlan: marshalledPhysicalPlan,
 			Payload:      marshalledPayload,
+			QueryID:      queryID,
+			Metadata:     metadata,
 		}
 		wg.Add(len(physicalPlan.Intermediates))
 		for _, intermediate := range physicalPlan.Intermediates {
 			intermediate := intermediate
-			t.workerPool.Submit(func() {
+			if err := t.workerPool.Submit(func() {
 				defer wg.Done()
 				if err := t.SendRequest(intermediate.Indicator, req); err != nil {
 					sendError.Store(err)
 				}
-			})
+			}); err != nil {
+				wg.Done()
+				sendError.Store(err)
+			}
 		}
 		wg.Wait()
 	}
@@ -225,24 +302,206 @@ func (t *taskManager) SubmitMetricTask(
 			RequestType:  protoCommonV1.RequestType_Data,
 			PhysicalPlan: marshalledPhysicalPlan,
 			Payload:      marshalledPayload,
+			QueryID:      queryID,
+			Metadata:     metadata,
 		}
 		wg.Add(len(physicalPlan.Leafs))
 		for _, leaf := range physicalPlan.Leafs {
 			leaf := leaf
-			t.workerPool.Submit(func() {
+			if err := t.workerPool.Submit(func() {
 				defer wg.Done()
 				if err := t.SendRequest(leaf.Indicator, req); err != nil {
-					sendError.Store(err)
+					// stream broken, retry leaf task in other replica
+					if metricTaskCtx.retryLeaf == nil || !metricTaskCtx.retryLeaf(leaf.Indicator) {
+						sendError.Store(err)
+					}
 				}
-			})
+			}); err != nil {
+				wg.Done()
+				sendError.Store(err)
+			}
 		}
 		wg.Wait()
 	}
 
 	if sendError.Load() != nil {
 		t.evictTask(rootTaskID)
+		t.queries.Delete(queryID)
+		return responseCh, sendError.Load()
 	}
-	return responseCh, sendError.Load()
+	t.scheduleHedges(rootTaskID, metricTaskCtx, physicalPlan, marshalledPayload, queryID, metadata)
+	return responseCh, nil
+}
+
+// expectedNodes returns the nodes which the root task waits for, node => shard ids(empty for intermediate node).
+func expectedNodes(physicalPlan *models.PhysicalPlan) map[string][]int32 {
+	nodes := make(map[string][]int32)
+	if len(physicalPlan.Intermediates) > 0 {
+		for _, intermediate := range physicalPlan.Intermediates {
+			nodes[intermediate.Indicator] = nil
+		}
+		return nodes
+	}
+	for _, leaf := range physicalPlan.Leafs {
+		nodes[leaf.Indicator] = leaf.ShardIDs
+	}
+	return nodes
+}
+
+// prepareHedges registers the hedged leaf tasks into root task context.
+func (t *taskManager) prepareHedges(rootTaskID string, taskCtx *metricTaskContext, physicalPlan *models.PhysicalPlan) {
+	if physicalPlan.HedgeDelay <= 0 || len(physicalPlan.Hedges) == 0 {
+		return
+	}
+	taskCtx.cancelLoser = func(nodeID, taskID string, hedgeWon bool) {
+		if hedgeWon {
+			t.hedgeWinCounter.Incr()
+		}
+		t.cancelRequest(nodeID, taskID)
+	}
+	for idx, hedge := range physicalPlan.Hedges {
+		hedgeTaskID := hedgeTaskID(rootTaskID, idx)
+		taskCtx.addHedge(hedgeTaskID, hedge.Primary, hedge.Indicator)
+		t.subTasks.Store(hedgeTaskID, rootTaskID)
+	}
+}
+
+// scheduleHedges sends the hedged leaf tasks after hedge delay, if primary leaf task doesn't respond.
+func (t *taskManager) scheduleHedges(
+	rootTaskID string,
+	taskCtx *metricTaskContext,
+	physicalPlan *models.PhysicalPlan,
+	payload []byte,
+	queryID string,
+	metadata map[string]string,
+) {
+	if physicalPlan.HedgeDelay <= 0 {
+		return
+	}
+	delay := time.Duration(physicalPlan.HedgeDelay) * time.Millisecond
+	for idx, hedge := range physicalPlan.Hedges {
+		hedge := hedge
+		req := newLeafTaskRequest(hedgeTaskID(rootTaskID, idx), physicalPlan, hedge.Leaf, payload, queryID, metadata)
+		time.AfterFunc(delay, func() {
+			if !taskCtx.beginHedge(hedge.Primary) {
+				return
+			}
+			if err := t.SendRequest(hedge.Indicator, req); err != nil {
+				t.logger.Warn("send hedged leaf task failure",
+					logger.String("queryID", queryID), logger.String("requestID", metadata[tracing.RequestIDKey]),
+					logger.String("target", hedge.Indicator), logger.Error(err))
+				taskCtx.abortHedge(hedge.Primary)
+				return
+			}
+			t.hedgedRequestCounter.Incr()
+		})
+	}
+}
+
+// prepareRetries sets the retry function of root task context, which re-sends the leaf task to other replica
+// within the query deadline, if leaf returns retryable error(stream broken, node restarting).
+func (t *taskManager) prepareRetries(
+	ctx context.Context,
+	rootTaskID string,
+	taskCtx *metricTaskContext,
+	physicalPlan *models.PhysicalPlan,
+	payload []byte,
+	queryID string,
+	metadata map[string]string,
+) {
+	if len(physicalPlan.Failovers) == 0 || len(physicalPlan.Intermediates) > 0 {
+		return
+	}
+	leafs := make(map[string]int)
+	for idx, leaf := range physicalPlan.Leafs {
+		leafs[leaf.Indicator] = idx
+	}
+	taskCtx.retryLeaf = func(primary string) bool {
+		replicas := physicalPlan.GetFailover(primary)
+		leafIdx, ok := leafs[primary]
+		if !ok {
+			return false
+		}
+		for {
+			attempt := taskCtx.nextRetry(primary)
+			if attempt > len(replicas) || attempt > maxLeafRetries || ctx.Err() != nil {
+				// no replica to retry, or exceed query deadline
+				t.retryExhaustedCounter.Incr()
+				return false
+			}
+			leaf := physicalPlan.Leafs[leafIdx]
+			leaf.Indicator = replicas[attempt-1]
+			retryTaskID := retryTaskID(rootTaskID, leafIdx, attempt)
+			taskCtx.addRetry(retryTaskID, primary)
+			t.subTasks.Store(retryTaskID, rootTaskID)
+			req := newLeafTaskRequest(retryTaskID, physicalPlan, leaf, payload, queryID, metadata)
+			if err := t.SendRequest(leaf.Indicator, req); err != nil {
+				t.logger.Warn("send retried leaf task failure",
+					logger.String("queryID", queryID), logger.String("requestID", metadata[tracing.RequestIDKey]),
+					logger.String("target", leaf.Indicator), logger.Error(err))
+				continue
+			}
+			t.retriedRequestCounter.Incr()
+			return true
+		}
+	}
+}
+
+// newLeafTaskRequest creates the task request which only searches given leaf's shards.
+func newLeafTaskRequest(
+	taskID string,
+	physicalPlan *models.PhysicalPlan,
+	leaf models.Leaf,
+	payload []byte,
+	queryID string,
+	metadata map[string]string,
+) *protoCommonV1.TaskRequest {
+	return &protoCommonV1.TaskRequest{
+		ParentTaskID: taskID,
+		Type:         protoCommonV1.TaskType_Leaf,
+		RequestType:  protoCommonV1.RequestType_Data,
+		PhysicalPlan: encoding.JSONMarshal(&models.PhysicalPlan{
+			Database:   physicalPlan.Database,
+			Root:       models.Root{Indicator: physicalPlan.Root.Indicator, NumOfTask: 1},
+			Leafs:      []models.Leaf{leaf},
+			HedgeDelay: physicalPlan.HedgeDelay,
+		}),
+		Payload:  payload,
+		QueryID:  queryID,
+		Metadata: metadata,
+	}
+}
+
+// cancelRequest sends the cancel request for the loser of primary/hedged leaf task.
+func (t *taskManager) cancelRequest(targetNodeID, taskID string) {
+	err := t.workerPool.Submit(func() {
+		if err := t.SendRequest(targetNodeID, &protoCommonV1.TaskRequest{
+			ParentTaskID: taskID,
+			Type:         protoCommonV1.TaskType_Leaf,
+			RequestType:  protoCommonV1.RequestType_Cancel,
+		}); err != nil {
+			t.logger.Warn("send cancel request failure",
+				logger.String("taskID", taskID),
+				logger.String("target", targetNodeID), logger.Error(err))
+			return
+		}
+		t.cancelRequestCounter.Incr()
+	})
+	if err != nil {
+		t.logger.Warn("submit cancel request failure",
+			logger.String("taskID", taskID),
+			logger.String("target", targetNodeID), logger.Error(err))
+	}
+}
+
+// retryTaskID returns the task id of retried leaf task.
+func retryTaskID(rootTaskID string, leafIdx, attempt int) string {
+	return fmt.Sprintf("%s-retry-%d-%d", rootTaskID, leafIdx, attempt)
+}
+
+// hedgeTaskID returns the task id of hedged leaf task.
+func hedgeTaskID(rootTaskID string, idx int) string {
+	return fmt.Sprintf("%s-hedge-%d", rootTaskID, idx)
 }
 
 func (t *taskManager) SubmitIntermediateMetricTask(
@@ -259,6 +518,7 @@ func (t *taskManager) SubmitIntermediateMetricTask(
 		stmtQuery,
 		int32(len(physicalPlan.Leafs)),
 		responseCh,
+		t.groupCardinality,
 	)
 
 	t.storeTask(parentTaskID, taskCtx)
@@ -277,6 +537,7 @@ func (t *taskManager) SubmitMetaDataTask(
 		ParentTaskID: taskID,
 		PhysicalPlan: encoding.JSONMarshal(physicalPlan),
 		Payload:      suggestMarshalData,
+		QueryID:      taskID,
 	}
 
 	responseCh := make(chan *protoCommonV1.TaskResponse)
@@ -297,12 +558,15 @@ func (t *taskManager) SubmitMetaDataTask(
 	wg.Add(len(physicalPlan.Leafs))
 	for _, leafNode := range physicalPlan.Leafs {
 		leafNode := leafNode
-		t.workerPool.Submit(func() {
+		if err := t.workerPool.Submit(func() {
 			defer wg.Done()
 			if err := t.SendRequest(leafNode.Indicator, req); err != nil {
 				sendError.Store(err)
 			}
-		})
+		}); err != nil {
+			wg.Done()
+			sendError.Store(err)
+		}
 	}
 	wg.Wait()
 	if sendError.Load() != nil {
@@ -359,19 +623,66 @@ func (t *taskManager) SendResponse(parentNodeID string, resp *protoCommonV1.Task
 }
 
 func (t *taskManager) Receive(resp *protoCommonV1.TaskResponse, targetNode string) error {
-	taskCtx := t.Get(resp.TaskID)
+	taskID := resp.TaskID
+	if rootTaskID, ok := t.subTasks.Load(taskID); ok {
+		// response of hedged/retried leaf task, routes to root task
+		taskID = rootTaskID.(string)
+	}
+	taskCtx := t.Get(taskID)
 	if taskCtx == nil {
 		t.omitResponseCounter.Incr()
 		return fmt.Errorf("TaskID: %s may be evicted", resp.TaskID)
 	}
 	t.emitResponseCounter.Incr()
-	t.workerPool.Submit(func() {
-		// for root task and intermediate task
-		taskCtx.WriteResponse(resp, targetNode)
-
-		if taskCtx.Done() {
-			t.evictTask(resp.TaskID)
-		}
-	})
+	t.receivedBytesCounter.Add(float64(len(resp.Stats) + len(resp.Payload)))
+	if err := t.workerPool.Submit(func() {
+		t.handleResponse(taskID, taskCtx, resp, targetNode)
+	}); err != nil {
+		// response cannot be handled in pool, completes the task with error, avoids waiting until timeout
+		t.handleResponse(taskID, taskCtx, &protoCommonV1.TaskResponse{
+			TaskID:    resp.TaskID,
+			Type:      resp.Type,
+			Completed: true,
+			ErrMsg:    err.Error(),
+			SendTime:  resp.SendTime,
+		}, targetNode)
+		return err
+	}
 	return nil
 }
+
+// handleResponse writes the response into task context, evicts the task if completed.
+func (t *taskManager) handleResponse(taskID string, taskCtx TaskContext,
+	resp *protoCommonV1.TaskResponse, targetNode string) {
+	// for root task and intermediate task
+	taskCtx.WriteResponse(resp, targetNode)
+
+	if taskCtx.Done() {
+		t.evictTask(taskID)
+	}
+}
+
+// QueryProgress returns the execution progress of metric query by query id
+func (t *taskManager) QueryProgress(queryID string) (*models.QueryProgress, bool) {
+	taskCtx, ok := t.queries.Load(queryID)
+	if !ok {
+		return nil, false
+	}
+	progress := taskCtx.(*metricTaskContext).Progress()
+	progress.QueryID = queryID
+	return progress, true
+}
+
+// CompletePartially completes the running metric query with results of responded nodes
+func (t *taskManager) CompletePartially(queryID string, reason error) *series.TimeSeriesEvent {
+	taskCtx, ok := t.queries.Load(queryID)
+	if !ok {
+		return nil
+	}
+	metricTaskCtx := taskCtx.(*metricTaskContext)
+	event := metricTaskCtx.completePartially(reason)
+	if metricTaskCtx.Done() {
+		t.evictTask(metricTaskCtx.TaskID())
+	}
+	return event
+}
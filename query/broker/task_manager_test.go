@@ -19,7 +19,9 @@ package brokerquery
 
 import (
 	"context"
+	"errors"
 	"io"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,8 +30,12 @@ import (
 
 	"github.com/lindb/lindb/internal/concurrent"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/ltoml"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	"github.com/lindb/lindb/query"
 	"github.com/lindb/lindb/rpc"
 	"github.com/lindb/lindb/sql/stmt"
 )
@@ -77,7 +83,8 @@ func TestTaskManager_SubmitMetricTask(t *testing.T) {
 	taskClientFactory.EXPECT().GetTaskClient(gomock.Any()).
 		Return(nil).Times(1)
 	_, _ = taskManager1.SubmitMetricTask(
-		physicalPlan, &stmt.Query{})
+		context.TODO(),
+		physicalPlan, &stmt.Query{}, "")
 
 	// send error
 	client := protoCommonV1.NewMockTaskService_HandleClient(ctrl)
@@ -85,14 +92,22 @@ func TestTaskManager_SubmitMetricTask(t *testing.T) {
 		Return(client).Times(1)
 	client.EXPECT().Send(gomock.Any()).Return(io.ErrClosedPipe)
 	_, _ = taskManager1.SubmitMetricTask(
-		physicalPlan, &stmt.Query{})
+		context.TODO(),
+		physicalPlan, &stmt.Query{}, "")
 
-	// send ok
+	// send ok, trace context and request id are propagated by metadata
+	tracing.Setup(tracing.NewOTLPExporter(ctx, "http://127.0.0.1:4318/v1/traces", time.Second, nil), 1)
+	defer tracing.Setup(nil, 0)
 	taskClientFactory.EXPECT().GetTaskClient(gomock.Any()).
 		Return(client).Times(2)
-	client.EXPECT().Send(gomock.Any()).Return(nil).Times(2)
+	client.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *protoCommonV1.TaskRequest) error {
+		assert.Contains(t, req.Metadata, tracing.TraceParentKey)
+		assert.Equal(t, "r1", req.Metadata[tracing.RequestIDKey])
+		return nil
+	}).Times(2)
 	_, _ = taskManager1.SubmitMetricTask(
-		physicalPlan, &stmt.Query{})
+		tracing.WithRequestID(context.TODO(), "r1"),
+		physicalPlan, &stmt.Query{}, "")
 
 	tm := taskManager1.(*taskManager)
 	// task not found
@@ -103,6 +118,290 @@ func TestTaskManager_SubmitMetricTask(t *testing.T) {
 		TaskID: "1.1.1.1:8000-3"}, ""))
 }
 
+func TestTaskManager_SubmitMetricTask_hedge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currentNode := models.Node{IP: "1.1.1.3", Port: 8000}
+	taskClientFactory := rpc.NewMockTaskClientFactory(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	taskManager1 := NewTaskManager(
+		ctx,
+		currentNode,
+		taskClientFactory,
+		rpc.NewMockTaskServerFactory(ctrl),
+		concurrent.NewPool(
+			"p",
+			10,
+			time.Minute,
+			linmetric.NewScope("test"),
+		),
+		time.Second*10,
+	)
+	tm := taskManager1.(*taskManager)
+	physicalPlan := models.NewPhysicalPlan(models.Root{Indicator: "1.1.1.3:8000", NumOfTask: 1})
+	leaf := models.Leaf{
+		BaseNode:  models.BaseNode{Parent: "1.1.1.3:8000", Indicator: "1.1.1.1:9000"},
+		Receivers: []models.Node{currentNode},
+		ShardIDs:  []int32{1, 2},
+	}
+	physicalPlan.AddLeaf(leaf)
+	hedge := leaf
+	hedge.Indicator = "1.1.1.2:9000"
+	physicalPlan.AddHedge(models.HedgeLeaf{Leaf: hedge, Primary: "1.1.1.1:9000"})
+	physicalPlan.HedgeDelay = 10
+
+	client := protoCommonV1.NewMockTaskService_HandleClient(ctrl)
+	taskClientFactory.EXPECT().GetTaskClient(gomock.Any()).Return(client).AnyTimes()
+	var (
+		mu   sync.Mutex
+		reqs = make(map[string][]*protoCommonV1.TaskRequest)
+	)
+	client.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *protoCommonV1.TaskRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+		reqs[req.ParentTaskID] = append(reqs[req.ParentTaskID], req)
+		return nil
+	}).AnyTimes()
+	_, err := taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "")
+	assert.NoError(t, err)
+	// hedged leaf task sent after hedge delay
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	assert.Len(t, reqs["1.1.1.3:8000-1"], 1)
+	hedgeReqs := reqs["1.1.1.3:8000-1-hedge-0"]
+	mu.Unlock()
+	assert.Len(t, hedgeReqs, 1)
+	hedgePlan := models.PhysicalPlan{}
+	assert.NoError(t, encoding.JSONUnmarshal(hedgeReqs[0].PhysicalPlan, &hedgePlan))
+	assert.Equal(t, []models.Leaf{hedge}, hedgePlan.Leafs)
+
+	// hedge wins, response routed to root task, primary cancelled
+	assert.NoError(t, tm.Receive(&protoCommonV1.TaskResponse{
+		TaskID: "1.1.1.3:8000-1-hedge-0"}, "1.1.1.2:9000"))
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	assert.Len(t, reqs["1.1.1.3:8000-1"], 2)
+	assert.Equal(t, protoCommonV1.RequestType_Cancel, reqs["1.1.1.3:8000-1"][1].RequestType)
+	mu.Unlock()
+	assert.Nil(t, tm.Get("1.1.1.3:8000-1"))
+}
+
+func TestTaskManager_SubmitMetricTask_retry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currentNode := models.Node{IP: "1.1.1.3", Port: 8000}
+	taskClientFactory := rpc.NewMockTaskClientFactory(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	taskManager1 := NewTaskManager(
+		ctx,
+		currentNode,
+		taskClientFactory,
+		rpc.NewMockTaskServerFactory(ctrl),
+		concurrent.NewPool(
+			"p",
+			10,
+			time.Minute,
+			linmetric.NewScope("test"),
+		),
+		time.Second*10,
+	)
+	tm := taskManager1.(*taskManager)
+	physicalPlan := models.NewPhysicalPlan(models.Root{Indicator: "1.1.1.3:8000", NumOfTask: 1})
+	physicalPlan.AddLeaf(models.Leaf{
+		BaseNode:  models.BaseNode{Parent: "1.1.1.3:8000", Indicator: "1.1.1.1:9000"},
+		Receivers: []models.Node{currentNode},
+		ShardIDs:  []int32{1, 2},
+	})
+	physicalPlan.AddFailover(models.FailoverLeaf{
+		Primary:  "1.1.1.1:9000",
+		Replicas: []string{"1.1.1.2:9000", "1.1.1.4:9000", "1.1.1.5:9000"},
+	})
+
+	client := protoCommonV1.NewMockTaskService_HandleClient(ctrl)
+	taskClientFactory.EXPECT().GetTaskClient(gomock.Any()).DoAndReturn(func(target string) protoCommonV1.TaskService_HandleClient {
+		if target == "1.1.1.1:9000" {
+			// stream broken
+			return nil
+		}
+		return client
+	}).AnyTimes()
+	var (
+		mu      sync.Mutex
+		targets = make(map[string]string)
+	)
+	client.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *protoCommonV1.TaskRequest) error {
+		plan := models.PhysicalPlan{}
+		_ = encoding.JSONUnmarshal(req.PhysicalPlan, &plan)
+		mu.Lock()
+		defer mu.Unlock()
+		targets[req.ParentTaskID] = plan.Leafs[0].Indicator
+		return nil
+	}).AnyTimes()
+
+	// send failure, retry in other replica
+	eventCh, err := taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "")
+	assert.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, map[string]string{"1.1.1.3:8000-1-retry-0-1": "1.1.1.2:9000"}, targets)
+	mu.Unlock()
+
+	// retryable error response, retry again
+	assert.NoError(t, tm.Receive(&protoCommonV1.TaskResponse{
+		TaskID: "1.1.1.3:8000-1-retry-0-1", ErrMsg: query.ErrNoDatabase.Error()}, "1.1.1.2:9000"))
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, "1.1.1.4:9000", targets["1.1.1.3:8000-1-retry-0-2"])
+	mu.Unlock()
+	assert.NotNil(t, tm.Get("1.1.1.3:8000-1"))
+
+	// exceed max retries
+	go func() {
+		assert.NoError(t, tm.Receive(&protoCommonV1.TaskResponse{
+			TaskID: "1.1.1.3:8000-1-retry-0-2", ErrMsg: query.ErrNoDatabase.Error()}, "1.1.1.4:9000"))
+	}()
+	event := <-eventCh
+	assert.Error(t, event.Err)
+
+	// no failover replica
+	physicalPlan.Failovers = nil
+	_, err = taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "")
+	assert.Error(t, err)
+}
+
+func TestTaskManager_QueryProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskClientFactory := rpc.NewMockTaskClientFactory(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	taskManager1 := NewTaskManager(
+		ctx,
+		models.Node{IP: "1.1.1.1", Port: 8000},
+		taskClientFactory,
+		nil,
+		concurrent.NewPool(
+			"p",
+			10,
+			time.Minute,
+			linmetric.NewScope("test"),
+		),
+		time.Second*10,
+	)
+	physicalPlan := models.NewPhysicalPlan(models.Root{Indicator: "1.1.1.1:8000", NumOfTask: 2})
+	physicalPlan.AddLeaf(models.Leaf{BaseNode: models.BaseNode{Parent: "1.1.1.1:8000", Indicator: "1.1.1.1:9000"}})
+	physicalPlan.AddLeaf(models.Leaf{BaseNode: models.BaseNode{Parent: "1.1.1.1:8000", Indicator: "1.1.1.2:9000"}})
+	client := protoCommonV1.NewMockTaskService_HandleClient(ctrl)
+	taskClientFactory.EXPECT().GetTaskClient(gomock.Any()).Return(client).AnyTimes()
+	var queryIDs sync.Map
+	client.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *protoCommonV1.TaskRequest) error {
+		queryIDs.Store(req.QueryID, req.ParentTaskID)
+		return nil
+	}).AnyTimes()
+
+	// query not found
+	_, ok := taskManager1.QueryProgress("q1")
+	assert.False(t, ok)
+
+	_, err := taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "q1")
+	assert.NoError(t, err)
+	progress, ok := taskManager1.QueryProgress("q1")
+	assert.True(t, ok)
+	assert.Equal(t, "q1", progress.QueryID)
+	// query id is sent to leafs
+	parentTaskID, ok := queryIDs.Load("q1")
+	assert.True(t, ok)
+	assert.Equal(t, "1.1.1.1:8000-1", parentTaskID)
+	assert.Equal(t, int32(2), progress.TotalTasks)
+	assert.Equal(t, int32(0), progress.CompletedTasks)
+	assert.False(t, progress.Done)
+
+	// query id conflict with running query
+	_, err = taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "q1")
+	assert.True(t, errors.Is(err, query.ErrQueryIDConflict))
+
+	// receive error response from leafs
+	tm := taskManager1.(*taskManager)
+	tm.Get("1.1.1.1:8000-1").WriteResponse(&protoCommonV1.TaskResponse{ErrMsg: "err", Payload: []byte{1, 2}}, "1.1.1.1:9000")
+	progress, _ = taskManager1.QueryProgress("q1")
+	assert.Equal(t, int32(1), progress.CompletedTasks)
+	assert.Equal(t, ltoml.Size(2), progress.ReceivedBytes)
+	tm.Get("1.1.1.1:8000-1").WriteResponse(&protoCommonV1.TaskResponse{ErrMsg: "err"}, "1.1.1.2:9000")
+	progress, _ = taskManager1.QueryProgress("q1")
+	assert.Equal(t, int32(2), progress.CompletedTasks)
+	assert.True(t, progress.Done)
+
+	// reuse query id of completed query
+	_, err = taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "q1")
+	assert.NoError(t, err)
+	progress, _ = taskManager1.QueryProgress("q1")
+	assert.False(t, progress.Done)
+
+	// query id is empty, uses root task id
+	_, err = taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "")
+	assert.NoError(t, err)
+	_, ok = taskManager1.QueryProgress("1.1.1.1:8000-4")
+	assert.True(t, ok)
+	_, ok = queryIDs.Load("1.1.1.1:8000-4")
+	assert.True(t, ok)
+}
+
+func TestTaskManager_CompletePartially(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskClientFactory := rpc.NewMockTaskClientFactory(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	taskManager1 := NewTaskManager(
+		ctx,
+		models.Node{IP: "1.1.1.1", Port: 8000},
+		taskClientFactory,
+		nil,
+		concurrent.NewPool(
+			"p",
+			10,
+			time.Minute,
+			linmetric.NewScope("test"),
+		),
+		time.Second*10,
+	)
+	physicalPlan := models.NewPhysicalPlan(models.Root{Indicator: "1.1.1.1:8000", NumOfTask: 2})
+	physicalPlan.AddLeaf(models.Leaf{
+		BaseNode: models.BaseNode{Parent: "1.1.1.1:8000", Indicator: "1.1.1.1:9000"}, ShardIDs: []int32{1}})
+	physicalPlan.AddLeaf(models.Leaf{
+		BaseNode: models.BaseNode{Parent: "1.1.1.1:8000", Indicator: "1.1.1.2:9000"}, ShardIDs: []int32{2}})
+	client := protoCommonV1.NewMockTaskService_HandleClient(ctrl)
+	taskClientFactory.EXPECT().GetTaskClient(gomock.Any()).Return(client).AnyTimes()
+	client.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+
+	// query not found
+	assert.Nil(t, taskManager1.CompletePartially("q1", ErrTimeout))
+
+	_, err := taskManager1.SubmitMetricTask(query.WithPartialResults(context.TODO()),
+		physicalPlan, &stmt.Query{}, "q1")
+	assert.NoError(t, err)
+	tm := taskManager1.(*taskManager)
+	tm.Get("1.1.1.1:8000-1").WriteResponse(&protoCommonV1.TaskResponse{}, "1.1.1.1:9000")
+	event := taskManager1.CompletePartially("q1", ErrTimeout)
+	assert.Equal(t, []models.NodeFailure{{Node: "1.1.1.2:9000", ShardIDs: []int32{2}, Error: ErrTimeout.Error()}},
+		event.Failures)
+	assert.Nil(t, tm.Get("1.1.1.1:8000-1"))
+
+	// partial results not allowed
+	_, err = taskManager1.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "q2")
+	assert.NoError(t, err)
+	assert.Nil(t, taskManager1.CompletePartially("q2", ErrTimeout))
+}
+
 func TestTaskManager_SendResponse(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -207,8 +506,46 @@ func TestTaskManager_cleaner(t *testing.T) {
 	go tm.cleaner(time.Millisecond * 10)
 	task := NewMockTaskContext(ctrl)
 	task.EXPECT().Expired(gomock.Any()).Return(true)
+	task.EXPECT().Release()
 
 	tm.tasks.Store("1", task)
 	time.Sleep(time.Second)
 
 }
+
+func TestTaskManager_PoolRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := concurrent.NewPool("p", 10, time.Minute, linmetric.NewScope("test"))
+	pool.Stop()
+	tm := NewTaskManager(
+		context.Background(),
+		models.Node{IP: "1.1.1.1", Port: 8000},
+		nil,
+		nil,
+		pool,
+		time.Second*10,
+	).(*taskManager)
+	physicalPlan := models.NewPhysicalPlan(models.Root{Indicator: "1.1.1.3:8000", NumOfTask: 1})
+	physicalPlan.AddLeaf(models.Leaf{
+		BaseNode:  models.BaseNode{Parent: "1.1.1.3:8000", Indicator: "1.1.1.1:9000"},
+		Receivers: []models.Node{{IP: "1.1.1.1", Port: 2000}},
+		ShardIDs:  []int32{1},
+	})
+	// send request rejected, no hang
+	_, err := tm.SubmitMetricTask(context.TODO(), physicalPlan, &stmt.Query{}, "")
+	assert.Equal(t, concurrent.ErrPoolStopped, err)
+
+	// response rejected, completes task with error
+	taskCtx := NewMockTaskContext(ctrl)
+	tm.tasks.Store("1", taskCtx)
+	taskCtx.EXPECT().WriteResponse(gomock.Any(), "").Do(func(resp *protoCommonV1.TaskResponse, _ string) {
+		assert.True(t, resp.Completed)
+		assert.Equal(t, concurrent.ErrPoolStopped.Error(), resp.ErrMsg)
+	})
+	taskCtx.EXPECT().Done().Return(true)
+	taskCtx.EXPECT().Release().AnyTimes()
+	assert.Equal(t, concurrent.ErrPoolStopped, tm.Receive(&protoCommonV1.TaskResponse{TaskID: "1"}, ""))
+	assert.Nil(t, tm.Get("1"))
+}
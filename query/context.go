@@ -0,0 +1,119 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"context"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+type partialResultsKey struct{}
+
+type paramsKey struct{}
+
+type namespaceKey struct{}
+
+type statsKey struct{}
+
+type exemplarsKey struct{}
+
+type maxPointsKey struct{}
+
+type intervalKey struct{}
+
+// WithPartialResults returns the context which allows the query returning partial results,
+// if some leaf/intermediate nodes fail or time out.
+func WithPartialResults(ctx context.Context) context.Context {
+	return context.WithValue(ctx, partialResultsKey{}, true)
+}
+
+// PartialResultsFromContext returns if the query allows returning partial results.
+func PartialResultsFromContext(ctx context.Context) bool {
+	partial, _ := ctx.Value(partialResultsKey{}).(bool)
+	return partial
+}
+
+// WithParams returns the context which carries the parameters bound to placeholders of query sql.
+func WithParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, paramsKey{}, params)
+}
+
+// ParamsFromContext returns the parameters of query sql, returns nil if query isn't parameterized.
+func ParamsFromContext(ctx context.Context) map[string]string {
+	params, _ := ctx.Value(paramsKey{}).(map[string]string)
+	return params
+}
+
+// WithNamespace returns the context which carries the namespace(tenant) of query.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// WithStats returns the context which requires returning the execution stats of query with results,
+// like series scanned, blocks decoded, bytes read and durations of each stage per leaf node.
+func WithStats(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statsKey{}, true)
+}
+
+// StatsFromContext returns if the query requires returning execution stats.
+func StatsFromContext(ctx context.Context) bool {
+	stats, _ := ctx.Value(statsKey{}).(bool)
+	return stats
+}
+
+// WithExemplars returns the context which requires returning the exemplars of histogram series with results.
+func WithExemplars(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exemplarsKey{}, true)
+}
+
+// ExemplarsFromContext returns if the query requires returning exemplars.
+func ExemplarsFromContext(ctx context.Context) bool {
+	exemplars, _ := ctx.Value(exemplarsKey{}).(bool)
+	return exemplars
+}
+
+// WithMaxPoints returns the context which carries the max points hint of client(like width of chart),
+// downsampling interval is chosen automatically if points of series exceed it.
+func WithMaxPoints(ctx context.Context, maxPoints int) context.Context {
+	return context.WithValue(ctx, maxPointsKey{}, maxPoints)
+}
+
+// MaxPointsFromContext returns the max points hint of query, returns 0 if not given.
+func MaxPointsFromContext(ctx context.Context) int {
+	maxPoints, _ := ctx.Value(maxPointsKey{}).(int)
+	return maxPoints
+}
+
+// WithInterval returns the context which carries the explicit downsampling interval,
+// which overrides the interval of group by time.
+func WithInterval(ctx context.Context, interval timeutil.Interval) context.Context {
+	return context.WithValue(ctx, intervalKey{}, interval)
+}
+
+// IntervalFromContext returns the explicit downsampling interval of query, returns 0 if not given.
+func IntervalFromContext(ctx context.Context) timeutil.Interval {
+	interval, _ := ctx.Value(intervalKey{}).(timeutil.Interval)
+	return interval
+}
+
+// NamespaceFromContext returns the namespace(tenant) of query, returns empty if not given.
+func NamespaceFromContext(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceKey{}).(string)
+	return namespace
+}
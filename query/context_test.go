@@ -0,0 +1,59 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+func TestPartialResults(t *testing.T) {
+	assert.False(t, PartialResultsFromContext(context.TODO()))
+	assert.True(t, PartialResultsFromContext(WithPartialResults(context.TODO())))
+}
+
+func TestStats(t *testing.T) {
+	assert.False(t, StatsFromContext(context.TODO()))
+	assert.True(t, StatsFromContext(WithStats(context.TODO())))
+}
+
+func TestExemplars(t *testing.T) {
+	assert.False(t, ExemplarsFromContext(context.TODO()))
+	assert.True(t, ExemplarsFromContext(WithExemplars(context.TODO())))
+}
+
+func TestMaxPoints(t *testing.T) {
+	assert.Zero(t, MaxPointsFromContext(context.TODO()))
+	assert.Equal(t, 1000, MaxPointsFromContext(WithMaxPoints(context.TODO(), 1000)))
+}
+
+func TestInterval(t *testing.T) {
+	assert.Zero(t, IntervalFromContext(context.TODO()))
+	assert.Equal(t, timeutil.Interval(timeutil.OneMinute),
+		IntervalFromContext(WithInterval(context.TODO(), timeutil.Interval(timeutil.OneMinute))))
+}
+
+func TestParams(t *testing.T) {
+	assert.Nil(t, ParamsFromContext(context.TODO()))
+	params := map[string]string{"host": "1.1.1.1"}
+	assert.Equal(t, params, ParamsFromContext(WithParams(context.TODO(), params)))
+}
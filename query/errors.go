@@ -19,6 +19,7 @@ package query
 
 import (
 	"errors"
+	"strings"
 )
 
 var (
@@ -33,4 +34,21 @@ var (
 	ErrTaskSend                    = errors.New("send task request error")
 	ErrResponseSend                = errors.New("send response error")
 	ErrNoDatabase                  = errors.New("not found database")
+	ErrTooManyPoints               = errors.New("too many points of series, exceed max points limit")
+	ErrShardNotAvailable           = errors.New("some shards not available, partial results not allowed")
+	ErrQueryIDConflict             = errors.New("query id is used by another running query")
+	ErrServerDraining              = errors.New("server is draining, rejects new queries")
 )
+
+// retryableErrors are the transient errors of leaf task, which can be retried in other replica.
+var retryableErrors = []error{ErrNoSendStream, ErrTaskSend, ErrResponseSend, ErrNoDatabase, ErrServerDraining}
+
+// IsRetryableError checks if the error message of leaf task is transient(stream broken, node restarting/draining).
+func IsRetryableError(errMsg string) bool {
+	for _, err := range retryableErrors {
+		if strings.Contains(errMsg, err.Error()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"sort"
+
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+)
+
+// MaxExemplars is the max num. of exemplars returned for each series of result set.
+const MaxExemplars = 20
+
+// LatestExemplars returns the latest MaxExemplars exemplars sorted by timestamp.
+func LatestExemplars(exemplars []*protoCommonV1.Exemplar) []*protoCommonV1.Exemplar {
+	sort.SliceStable(exemplars, func(i, j int) bool {
+		return exemplars[i].Timestamp < exemplars[j].Timestamp
+	})
+	if len(exemplars) > MaxExemplars {
+		return exemplars[len(exemplars)-MaxExemplars:]
+	}
+	return exemplars
+}
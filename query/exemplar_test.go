@@ -0,0 +1,38 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+)
+
+func TestLatestExemplars(t *testing.T) {
+	assert.Empty(t, LatestExemplars(nil))
+	var exemplars []*protoCommonV1.Exemplar
+	for i := MaxExemplars + 5; i > 0; i-- {
+		exemplars = append(exemplars, &protoCommonV1.Exemplar{TraceID: "trace", Timestamp: int64(i)})
+	}
+	latest := LatestExemplars(exemplars)
+	assert.Len(t, latest, MaxExemplars)
+	assert.Equal(t, int64(6), latest[0].Timestamp)
+	assert.Equal(t, int64(MaxExemplars+5), latest[MaxExemplars-1].Timestamp)
+}
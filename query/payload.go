@@ -0,0 +1,32 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"github.com/lindb/lindb/pkg/bufpool"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+)
+
+// MarshalTimeSeriesList marshals time series list into a pooled byte slice,
+// caller must return the slice by bufpool.PutBytes after the payload sent.
+func MarshalTimeSeriesList(seriesList *protoCommonV1.TimeSeriesList) *[]byte {
+	buf := bufpool.GetBytes(seriesList.Size())
+	n, _ := seriesList.MarshalTo(*buf)
+	*buf = (*buf)[:n]
+	return buf
+}
@@ -0,0 +1,41 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/bufpool"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+)
+
+func TestMarshalTimeSeriesList(t *testing.T) {
+	seriesList := &protoCommonV1.TimeSeriesList{
+		TimeSeriesList: []*protoCommonV1.TimeSeries{{Tags: "host=1", Fields: map[string][]byte{"f": {1, 2, 3}}}},
+	}
+	buf := MarshalTimeSeriesList(seriesList)
+	defer bufpool.PutBytes(buf)
+	expect, _ := seriesList.Marshal()
+	assert.Equal(t, expect, *buf)
+
+	rs := &protoCommonV1.TimeSeriesList{}
+	assert.NoError(t, rs.Unmarshal(*buf))
+	assert.Equal(t, seriesList.TimeSeriesList[0].Tags, rs.TimeSeriesList[0].Tags)
+}
@@ -0,0 +1,44 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+// for testing
+var randRead = rand.Read
+
+var querySeq atomic.Int64
+
+// NewQueryID returns a cluster-wide unique query id assigned by broker,
+// which ties together logs of broker/intermediate/leaf nodes for one query.
+func NewQueryID() string {
+	id := make([]byte, 8)
+	if _, err := randRead(id); err != nil {
+		// fallback to timestamp with sequence if random source unavailable
+		return strconv.FormatInt(timeutil.NowNano(), 16) + "-" + strconv.FormatInt(querySeq.Inc(), 16)
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,44 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQueryID(t *testing.T) {
+	id1 := NewQueryID()
+	id2 := NewQueryID()
+	assert.Len(t, id1, 16)
+	assert.NotEqual(t, id1, id2)
+
+	randRead = func(b []byte) (n int, err error) {
+		return 0, fmt.Errorf("err")
+	}
+	defer func() {
+		randRead = rand.Read
+	}()
+	id1 = NewQueryID()
+	id2 = NewQueryID()
+	assert.NotEmpty(t, id1)
+	assert.NotEqual(t, id1, id2)
+}
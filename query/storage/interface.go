@@ -26,11 +26,14 @@ type storageMetricQuery interface {
 }
 
 type storageMetadataQuery interface {
-	Execute() (result []string, err error)
+	Execute() (result []string, total int, err error)
 }
 
 // StorageExecuteContext represents the storage execute context
 type StorageExecuteContext interface {
 	// QueryStats returns the storage query stats
 	QueryStats() *models.StorageStats
+	// Release releases the resources(e.g. file readers) retained by filtering result,
+	// must be called after all query tasks completed.
+	Release()
 }
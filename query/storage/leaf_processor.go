@@ -21,11 +21,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/fasttime"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/timeutil"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
@@ -35,6 +39,15 @@ import (
 	"github.com/lindb/lindb/tsdb"
 )
 
+// hedgedFlowTTL is the max time of tracking the running query flow which may be cancelled by hedging.
+const hedgedFlowTTL = time.Minute
+
+// hedgedFlow represents the running query flow of hedged leaf task.
+type hedgedFlow struct {
+	queryFlow  *storageQueryFlow
+	expireTime int64
+}
+
 // leafTaskProcessor represents the leaf node's task, the leaf node is always storage node
 // 1. receives the task request, and searches the data from time seres engine
 // 2. sends the result to the parent node(root or intermediate)
@@ -43,11 +56,16 @@ type leafTaskProcessor struct {
 	currentNodeID     string
 	engine            tsdb.Engine
 	taskServerFactory rpc.TaskServerFactory
+	shardParallelism  int // max num. of shards searched concurrently by one query
 	logger            *logger.Logger
 
+	// running query flows of hedged leaf tasks, parent task id => hedged flow
+	hedgedFlows sync.Map
+
 	storageMetricQueryCounter  *linmetric.BoundDeltaCounter
 	storageMetaQueryCounter    *linmetric.BoundDeltaCounter
 	storageOmitResponseCounter *linmetric.BoundDeltaCounter
+	storageCancelQueryCounter  *linmetric.BoundDeltaCounter
 }
 
 // NewLeafTaskProcessor creates the leaf task
@@ -55,6 +73,7 @@ func NewLeafTaskProcessor(
 	currentNode models.Node,
 	engine tsdb.Engine,
 	taskServerFactory rpc.TaskServerFactory,
+	shardParallelism int,
 ) query.TaskProcessor {
 	storageQueryScope := linmetric.NewScope("lindb.storage.query")
 	return &leafTaskProcessor{
@@ -62,10 +81,12 @@ func NewLeafTaskProcessor(
 		currentNodeID:              (&currentNode).Indicator(),
 		engine:                     engine,
 		taskServerFactory:          taskServerFactory,
+		shardParallelism:           shardParallelism,
 		logger:                     logger.GetLogger("query", "LeafTaskDispatcher"),
 		storageMetricQueryCounter:  storageQueryScope.NewDeltaCounter("metric_queries"),
 		storageMetaQueryCounter:    storageQueryScope.NewDeltaCounter("meta_queries"),
 		storageOmitResponseCounter: storageQueryScope.NewDeltaCounter("omitted_responses"),
+		storageCancelQueryCounter:  storageQueryScope.NewDeltaCounter("cancelled_queries"),
 	}
 }
 
@@ -87,6 +108,8 @@ func (p *leafTaskProcessor) Process(
 		SendTime:  timeutil.NowNano(),
 	}); sendError != nil {
 		p.logger.Error("failed to send error message to target stream",
+			logger.String("queryID", req.QueryID),
+			logger.String("requestID", query.RequestID(req)),
 			logger.String("taskID", req.ParentTaskID),
 			logger.Error(err),
 		)
@@ -97,7 +120,18 @@ func (p *leafTaskProcessor) Process(
 func (p *leafTaskProcessor) process(
 	ctx context.Context,
 	req *protoCommonV1.TaskRequest,
-) error {
+) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "storage.leaf_task")
+	span.SetAttribute("node", p.currentNodeID)
+	span.SetAttribute("taskID", req.ParentTaskID)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	if req.RequestType == protoCommonV1.RequestType_Cancel {
+		p.cancel(req.ParentTaskID)
+		return nil
+	}
 	physicalPlan := models.PhysicalPlan{}
 	if err := encoding.JSONUnmarshal(req.PhysicalPlan, &physicalPlan); err != nil {
 		return fmt.Errorf("%w: %s", query.ErrUnmarshalPlan, err)
@@ -130,7 +164,8 @@ func (p *leafTaskProcessor) process(
 	switch req.RequestType {
 	case protoCommonV1.RequestType_Data:
 		p.storageMetricQueryCounter.Incr()
-		if err := p.processDataSearch(ctx, db, curLeaf.ShardIDs, req, &curLeaf); err != nil {
+		hedged := physicalPlan.HedgeDelay > 0
+		if err := p.processDataSearch(ctx, db, curLeaf.ShardIDs, req, &curLeaf, hedged); err != nil {
 			return err
 		}
 	case protoCommonV1.RequestType_Metadata:
@@ -156,7 +191,7 @@ func (p *leafTaskProcessor) processMetadataSuggest(
 		return query.ErrUnmarshalSuggest
 	}
 	exec := newStorageMetadataQuery(db, shardIDs, stmtQuery)
-	result, err := exec.Execute()
+	result, total, err := exec.Execute()
 	if err != nil && !errors.Is(err, constants.ErrNotFound) {
 		return err
 	}
@@ -165,7 +200,7 @@ func (p *leafTaskProcessor) processMetadataSuggest(
 		Type:      protoCommonV1.TaskType_Leaf,
 		TaskID:    req.ParentTaskID,
 		Completed: true,
-		Payload:   encoding.JSONMarshal(&models.SuggestResult{Values: result}),
+		Payload:   encoding.JSONMarshal(&models.SuggestResult{Values: result, Total: total}),
 	}); err != nil {
 		return err
 	}
@@ -178,6 +213,7 @@ func (p *leafTaskProcessor) processDataSearch(
 	shardIDs []int32,
 	req *protoCommonV1.TaskRequest,
 	leafNode *models.Leaf,
+	hedged bool,
 ) error {
 	stmtQuery := stmt.Query{}
 	if err := stmtQuery.UnmarshalJSON(req.Payload); err != nil {
@@ -186,6 +222,10 @@ func (p *leafTaskProcessor) processDataSearch(
 
 	// execute leaf task
 	storageExecuteCtx := newStorageExecuteContext(shardIDs, &stmtQuery)
+	// shard parallelism is capped when memory database pressure is critical
+	ioCoordinator := p.engine.IOCoordinator()
+	storageExecuteCtx.shardParallelism = ioCoordinator.QueryParallelism(p.shardParallelism)
+	storageExecuteCtx.ioCoordinator = ioCoordinator
 	queryFlow := NewStorageQueryFlow(
 		ctx,
 		storageExecuteCtx,
@@ -195,7 +235,37 @@ func (p *leafTaskProcessor) processDataSearch(
 		leafNode,
 		db.ExecutorPool(),
 	)
+	if hedged {
+		// hedged leaf task may be cancelled if another replica responds first
+		p.trackHedgedFlow(req.ParentTaskID, queryFlow.(*storageQueryFlow))
+	}
 	exec := newStorageMetricQuery(queryFlow, db, storageExecuteCtx)
 	exec.Execute()
 	return nil
 }
+
+// trackHedgedFlow tracks the running query flow of hedged leaf task, purges the completed/expired flows.
+func (p *leafTaskProcessor) trackHedgedFlow(taskID string, queryFlow *storageQueryFlow) {
+	now := fasttime.UnixMilliseconds()
+	p.hedgedFlows.Range(func(key, value interface{}) bool {
+		flow := value.(*hedgedFlow)
+		if flow.queryFlow.isCompleted() || flow.expireTime < now {
+			p.hedgedFlows.Delete(key)
+		}
+		return true
+	})
+	p.hedgedFlows.Store(taskID, &hedgedFlow{
+		queryFlow:  queryFlow,
+		expireTime: now + hedgedFlowTTL.Milliseconds(),
+	})
+}
+
+// cancel cancels the running query flow of hedged leaf task, which lost the race with other replica.
+func (p *leafTaskProcessor) cancel(taskID string) {
+	value, ok := p.hedgedFlows.LoadAndDelete(taskID)
+	if !ok {
+		return
+	}
+	p.storageCancelQueryCounter.Incr()
+	value.(*hedgedFlow).queryFlow.Cancel()
+}
@@ -45,7 +45,8 @@ func TestLeafTaskProcessor_Process_sendStreamFailure(t *testing.T) {
 	leafTaskProcessor := NewLeafTaskProcessor(
 		models.Node{IP: "1.1.1.1", Port: 9000},
 		nil,
-		nil)
+		nil,
+		4)
 	leafTaskProcessor.Process(
 		context.Background(),
 		server,
@@ -58,11 +59,14 @@ func TestLeafTask_Process_Fail(t *testing.T) {
 
 	taskServerFactory := rpc.NewMockTaskServerFactory(ctrl)
 	engine := tsdb.NewMockEngine(ctrl)
+	ioCoordinator := tsdb.NewMockIOCoordinator(ctrl)
+	ioCoordinator.EXPECT().QueryParallelism(4).Return(4).AnyTimes()
+	engine.EXPECT().IOCoordinator().Return(ioCoordinator).AnyTimes()
 	serverStream := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
 	mockDatabase := tsdb.NewMockDatabase(ctrl)
 
 	currentNode := models.Node{IP: "1.1.1.3", Port: 8000}
-	processorI := NewLeafTaskProcessor(currentNode, engine, taskServerFactory)
+	processorI := NewLeafTaskProcessor(currentNode, engine, taskServerFactory, 4)
 	processor := processorI.(*leafTaskProcessor)
 	// unmarshal error
 	err := processor.process(
@@ -127,7 +131,7 @@ func TestLeafProcessor_Process(t *testing.T) {
 	engine := tsdb.NewMockEngine(ctrl)
 
 	currentNode := models.Node{IP: "1.1.1.3", Port: 8000}
-	processorI := NewLeafTaskProcessor(currentNode, engine, taskServerFactory)
+	processorI := NewLeafTaskProcessor(currentNode, engine, taskServerFactory, 4)
 	processor := processorI.(*leafTaskProcessor)
 	mockDatabase := tsdb.NewMockDatabase(ctrl)
 	plan := encoding.JSONMarshal(&models.PhysicalPlan{
@@ -139,6 +143,10 @@ func TestLeafProcessor_Process(t *testing.T) {
 
 	mockDatabase.EXPECT().ExecutorPool().Return(&tsdb.ExecutorPool{})
 	engine.EXPECT().GetDatabase(gomock.Any()).Return(mockDatabase, true)
+	// shard parallelism capped by io coordinator
+	ioCoordinator := tsdb.NewMockIOCoordinator(ctrl)
+	ioCoordinator.EXPECT().QueryParallelism(4).Return(1)
+	engine.EXPECT().IOCoordinator().Return(ioCoordinator)
 
 	serverStream := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
 	taskServerFactory.EXPECT().GetStream(gomock.Any()).Return(serverStream)
@@ -146,6 +154,55 @@ func TestLeafProcessor_Process(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestLeafProcessor_Process_cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskServerFactory := rpc.NewMockTaskServerFactory(ctrl)
+	engine := tsdb.NewMockEngine(ctrl)
+
+	currentNode := models.Node{IP: "1.1.1.3", Port: 8000}
+	processor := NewLeafTaskProcessor(currentNode, engine, taskServerFactory, 4).(*leafTaskProcessor)
+	mockDatabase := tsdb.NewMockDatabase(ctrl)
+	plan := encoding.JSONMarshal(&models.PhysicalPlan{
+		Database:   "test_db",
+		Leafs:      []models.Leaf{{BaseNode: models.BaseNode{Indicator: "1.1.1.3:8000"}}},
+		HedgeDelay: 10,
+	})
+	qry := stmt.Query{MetricName: "cpu"}
+	data := encoding.JSONMarshal(&qry)
+
+	mockDatabase.EXPECT().ExecutorPool().Return(&tsdb.ExecutorPool{})
+	engine.EXPECT().GetDatabase(gomock.Any()).Return(mockDatabase, true)
+	ioCoordinator := tsdb.NewMockIOCoordinator(ctrl)
+	ioCoordinator.EXPECT().QueryParallelism(4).Return(1)
+	engine.EXPECT().IOCoordinator().Return(ioCoordinator)
+	serverStream := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
+	taskServerFactory.EXPECT().GetStream(gomock.Any()).Return(serverStream)
+
+	// expired flow purged when tracking new flow
+	processor.hedgedFlows.Store("expired", &hedgedFlow{queryFlow: &storageQueryFlow{}})
+	err := processor.process(context.Background(), &protoCommonV1.TaskRequest{
+		ParentTaskID: "task-1", PhysicalPlan: plan, Payload: data})
+	assert.NoError(t, err)
+	_, ok := processor.hedgedFlows.Load("expired")
+	assert.False(t, ok)
+	value, ok := processor.hedgedFlows.Load("task-1")
+	assert.True(t, ok)
+
+	// cancel hedged flow
+	err = processor.process(context.Background(), &protoCommonV1.TaskRequest{
+		ParentTaskID: "task-1", RequestType: protoCommonV1.RequestType_Cancel})
+	assert.NoError(t, err)
+	assert.True(t, value.(*hedgedFlow).queryFlow.isCompleted())
+	_, ok = processor.hedgedFlows.Load("task-1")
+	assert.False(t, ok)
+	// flow not found
+	err = processor.process(context.Background(), &protoCommonV1.TaskRequest{
+		ParentTaskID: "task-1", RequestType: protoCommonV1.RequestType_Cancel})
+	assert.NoError(t, err)
+}
+
 func TestLeafTask_Suggest_Process(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -154,7 +211,7 @@ func TestLeafTask_Suggest_Process(t *testing.T) {
 	engine := tsdb.NewMockEngine(ctrl)
 
 	currentNode := models.Node{IP: "1.1.1.3", Port: 8000}
-	processorI := NewLeafTaskProcessor(currentNode, engine, taskServerFactory)
+	processorI := NewLeafTaskProcessor(currentNode, engine, taskServerFactory, 4)
 	processor := processorI.(*leafTaskProcessor)
 	mockDatabase := tsdb.NewMockDatabase(ctrl)
 	plan := encoding.JSONMarshal(&models.PhysicalPlan{
@@ -0,0 +1,195 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storagequery
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	"github.com/lindb/lindb/rpc"
+	"github.com/lindb/lindb/series"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/series/tag"
+	"github.com/lindb/lindb/sql/stmt"
+	"github.com/lindb/lindb/tsdb"
+)
+
+// localReceiver is the indicator of receiver of local query, the result is collected instead of sending to broker.
+const localReceiver = "local"
+
+var (
+	errLocalResponseSent = errors.New("response of local query has been sent")
+	errLocalRecv         = errors.New("local stream doesn't receive request")
+)
+
+// QueryShard executes the metric query against one shard of local database directly, bypassing broker,
+// returns the raw result of shard which isn't merged, query must be planned(interval/time range) by caller.
+func QueryShard(ctx context.Context, db tsdb.Database, shardID int32, query *stmt.Query) (*models.ShardResult, error) {
+	stream := &localTaskStream{ctx: ctx, responses: make(chan *protoCommonV1.TaskResponse, 1)}
+	leafNode := &models.Leaf{
+		BaseNode:  models.BaseNode{Parent: localReceiver, Indicator: localReceiver},
+		Receivers: []models.Node{{IP: localReceiver}},
+		ShardIDs:  []int32{shardID},
+	}
+	storageExecuteCtx := newStorageExecuteContext(leafNode.ShardIDs, query)
+	queryFlow := NewStorageQueryFlow(
+		ctx,
+		storageExecuteCtx,
+		query,
+		&protoCommonV1.TaskRequest{ParentTaskID: localReceiver, Type: protoCommonV1.TaskType_Leaf},
+		&localTaskServerFactory{stream: stream},
+		leafNode,
+		db.ExecutorPool(),
+	)
+	newStorageMetricQuery(queryFlow, db, storageExecuteCtx).Execute()
+
+	select {
+	case resp := <-stream.responses:
+		return newShardResult(shardID, query, resp)
+	case <-ctx.Done():
+		queryFlow.(*storageQueryFlow).Cancel()
+		return nil, ctx.Err()
+	}
+}
+
+// newShardResult decodes the raw time series list of shard from task response.
+func newShardResult(shardID int32, query *stmt.Query, resp *protoCommonV1.TaskResponse) (*models.ShardResult, error) {
+	result := &models.ShardResult{ShardID: shardID, Error: resp.ErrMsg}
+	if len(resp.Stats) > 0 {
+		result.Stats = models.NewStorageStats()
+		if err := encoding.JSONUnmarshal(resp.Stats, result.Stats); err != nil {
+			return nil, err
+		}
+	}
+	if len(resp.Payload) == 0 {
+		return result, nil
+	}
+	timeSeriesList := &protoCommonV1.TimeSeriesList{}
+	if err := timeSeriesList.Unmarshal(resp.Payload); err != nil {
+		return nil, err
+	}
+	for _, stringValue := range timeSeriesList.StringValues {
+		if result.StringValues == nil {
+			result.StringValues = make(map[uint64]string)
+		}
+		result.StringValues[stringValue.Id] = stringValue.Value
+	}
+	for _, ts := range timeSeriesList.TimeSeriesList {
+		rawSeries := &models.RawSeries{}
+		if query.HasGroupBy() {
+			tagValues := tag.SplitTagValues(ts.Tags)
+			rawSeries.Tags = make(map[string]string, len(tagValues))
+			for idx, tagKey := range query.GroupBy {
+				if idx < len(tagValues) {
+					rawSeries.Tags[tagKey] = tagValues[idx]
+				}
+			}
+		}
+		for _, spec := range timeSeriesList.FieldAggSpecs {
+			data, ok := ts.Fields[spec.FieldName]
+			if !ok {
+				continue
+			}
+			it := series.NewIterator(field.Name(spec.FieldName), data)
+			for it.HasNext() {
+				familyTime, fieldIt := it.Next()
+				if fieldIt == nil {
+					continue
+				}
+				for fieldIt.HasNext() {
+					primitiveIt := fieldIt.Next()
+					rawField := models.RawField{
+						Name:       spec.FieldName,
+						Type:       it.FieldType().String(),
+						AggType:    primitiveIt.AggType().String(),
+						FamilyTime: familyTime,
+					}
+					for primitiveIt.HasNext() {
+						slot, value := primitiveIt.Next()
+						rawField.Slots = append(rawField.Slots, slot)
+						rawField.Values = append(rawField.Values, value)
+					}
+					rawSeries.Fields = append(rawSeries.Fields, rawField)
+				}
+			}
+		}
+		result.Series = append(result.Series, rawSeries)
+	}
+	return result, nil
+}
+
+// localTaskServerFactory implements rpc.TaskServerFactory, returns the local stream for all receivers.
+type localTaskServerFactory struct {
+	stream protoCommonV1.TaskService_HandleServer
+}
+
+// GetStream returns the local stream.
+func (f *localTaskServerFactory) GetStream(_ string) protoCommonV1.TaskService_HandleServer {
+	return f.stream
+}
+
+// Register does nothing for local stream.
+func (f *localTaskServerFactory) Register(_ string, _ protoCommonV1.TaskService_HandleServer) (epoch int64) {
+	return 0
+}
+
+// Deregister does nothing for local stream.
+func (f *localTaskServerFactory) Deregister(_ int64, _ string) bool {
+	return false
+}
+
+// Nodes returns empty nodes for local stream.
+func (f *localTaskServerFactory) Nodes() []models.Node {
+	return nil
+}
+
+var _ rpc.TaskServerFactory = (*localTaskServerFactory)(nil)
+
+// localTaskStream implements protoCommonV1.TaskService_HandleServer, collects the response of query flow.
+type localTaskStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses chan *protoCommonV1.TaskResponse
+}
+
+// Context returns the context of local query.
+func (s *localTaskStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send collects the response, the payload is copied because it's pooled by query flow.
+func (s *localTaskStream) Send(resp *protoCommonV1.TaskResponse) error {
+	copied := *resp
+	copied.Payload = append([]byte(nil), resp.Payload...)
+	select {
+	case s.responses <- &copied:
+		return nil
+	default:
+		return errLocalResponseSent
+	}
+}
+
+// Recv isn't supported by local stream.
+func (s *localTaskStream) Recv() (*protoCommonV1.TaskRequest, error) {
+	return nil, errLocalRecv
+}
@@ -0,0 +1,130 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package storagequery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/aggregation"
+	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/series/tag"
+	"github.com/lindb/lindb/sql/stmt"
+	"github.com/lindb/lindb/tsdb"
+)
+
+func TestQueryShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := tsdb.NewMockDatabase(ctrl)
+	db.EXPECT().ExecutorPool().Return(&tsdb.ExecutorPool{}).AnyTimes()
+	// case 1: no shard in database
+	db.EXPECT().NumOfShards().Return(0)
+	result, err := QueryShard(context.TODO(), db, 1, &stmt.Query{MetricName: "cpu"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ShardResult{ShardID: 1, Error: errNoShardInDatabase.Error()}, result)
+	// case 2: shard not found
+	db.EXPECT().NumOfShards().Return(1)
+	db.EXPECT().GetShard(int32(1)).Return(nil, false)
+	result, err = QueryShard(context.TODO(), db, 1, &stmt.Query{MetricName: "cpu"})
+	assert.NoError(t, err)
+	assert.Equal(t, errShardNotFound.Error(), result.Error)
+}
+
+func TestNewShardResult(t *testing.T) {
+	now, _ := timeutil.ParseTimestamp("20190702 19:10:00", "20060102 15:04:05")
+	familyTime := timeutil.Truncate(now, timeutil.OneHour)
+	interval := timeutil.Interval(10 * timeutil.OneSecond)
+	spec := aggregation.NewAggregatorSpec("f1", field.SumField)
+	spec.AddFunctionType(function.Sum)
+	agg := aggregation.NewSeriesAggregator(interval, 1,
+		timeutil.TimeRange{Start: familyTime, End: familyTime + timeutil.OneHour - 1}, spec)
+	fieldAgg, ok := agg.GetAggregator(familyTime)
+	assert.True(t, ok)
+	fieldAgg.AggregateBySlot(5, 1.5)
+	fieldAgg.AggregateBySlot(10, 2)
+	data, err := agg.ResultSet().MarshalBinary()
+	assert.NoError(t, err)
+
+	payload, err := (&protoCommonV1.TimeSeriesList{
+		TimeSeriesList: []*protoCommonV1.TimeSeries{{
+			Tags:   tag.ConcatTagValues([]string{"h1", "1.1.1.1"}),
+			Fields: map[string][]byte{"f1": data},
+		}},
+		FieldAggSpecs: []*protoCommonV1.AggregatorSpec{
+			{FieldName: "f1", FieldType: uint32(field.SumField)},
+			{FieldName: "f2", FieldType: uint32(field.SumField)},
+		},
+		StringValues: []*protoCommonV1.StringValue{{Id: 1, Value: "v1"}},
+	}).Marshal()
+	assert.NoError(t, err)
+	result, err := newShardResult(1, &stmt.Query{GroupBy: []string{"host", "ip"}}, &protoCommonV1.TaskResponse{
+		Payload: payload,
+		Stats:   []byte(`{"totalCost":"1s"}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), result.ShardID)
+	assert.Equal(t, ltoml.Duration(time.Second), result.Stats.TotalCost)
+	assert.Equal(t, map[uint64]string{1: "v1"}, result.StringValues)
+	assert.Equal(t, []*models.RawSeries{{
+		Tags: map[string]string{"host": "h1", "ip": "1.1.1.1"},
+		Fields: []models.RawField{{
+			Name:       "f1",
+			Type:       field.SumField.String(),
+			AggType:    field.Sum.String(),
+			FamilyTime: familyTime,
+			Slots:      []int{5, 10},
+			Values:     []float64{1.5, 2},
+		}},
+	}}, result.Series)
+
+	// case: bad payload/stats
+	_, err = newShardResult(1, &stmt.Query{}, &protoCommonV1.TaskResponse{Payload: []byte{1, 2, 3}})
+	assert.Error(t, err)
+	_, err = newShardResult(1, &stmt.Query{}, &protoCommonV1.TaskResponse{Stats: []byte{1, 2, 3}})
+	assert.Error(t, err)
+}
+
+func TestLocalTaskStream(t *testing.T) {
+	stream := &localTaskStream{ctx: context.TODO(), responses: make(chan *protoCommonV1.TaskResponse, 1)}
+	factory := &localTaskServerFactory{stream: stream}
+	assert.Equal(t, stream, factory.GetStream("node"))
+	assert.Zero(t, factory.Register("node", stream))
+	assert.False(t, factory.Deregister(0, "node"))
+	assert.Nil(t, factory.Nodes())
+
+	assert.Equal(t, context.TODO(), stream.Context())
+	payload := []byte{1, 2, 3}
+	assert.NoError(t, stream.Send(&protoCommonV1.TaskResponse{Payload: payload}))
+	payload[0] = 4
+	assert.Equal(t, []byte{1, 2, 3}, (<-stream.responses).Payload)
+	assert.NoError(t, stream.Send(&protoCommonV1.TaskResponse{}))
+	assert.Error(t, stream.Send(&protoCommonV1.TaskResponse{}))
+	_, err := stream.Recv()
+	assert.Error(t, err)
+}
@@ -19,11 +19,14 @@ package storagequery
 
 import (
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/lindb/lindb/flow"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/sql/stmt"
+	"github.com/lindb/lindb/tsdb"
 
 	"github.com/lindb/roaring"
 )
@@ -32,17 +35,26 @@ import (
 type storageExecuteContext struct {
 	query    *stmt.Query
 	shardIDs []int32
+	// max num. of shards searched concurrently, searches all shards concurrently if <= 0
+	shardParallelism int
 
 	tagFilterResult map[string]*tagFilterResult
 
 	stats *models.StorageStats // storage query stats track for explain query
+
+	startTime     time.Time
+	ioCoordinator tsdb.IOCoordinator // report query latency if set
+
+	resultSets []*timeSpanResultSet // filtering result of each shard, need release after query
+	mutex      sync.Mutex
 }
 
 // newStorageExecuteContext creates storage execute context
 func newStorageExecuteContext(shardIDs []int32, query *stmt.Query) *storageExecuteContext {
 	ctx := &storageExecuteContext{
-		query:    query,
-		shardIDs: shardIDs,
+		query:     query,
+		shardIDs:  shardIDs,
+		startTime: time.Now(),
 	}
 	if query.Explain {
 		// if explain query, create storage query stats
@@ -59,6 +71,30 @@ func (ctx *storageExecuteContext) QueryStats() *models.StorageStats {
 	return ctx.stats
 }
 
+// Release releases the resources retained by filtering result,
+// then reports the query latency to io coordinator.
+func (ctx *storageExecuteContext) Release() {
+	ctx.mutex.Lock()
+	resultSets := ctx.resultSets
+	ctx.resultSets = nil
+	ctx.mutex.Unlock()
+
+	if ctx.ioCoordinator != nil {
+		ctx.ioCoordinator.ObserveQueryLatency(time.Since(ctx.startTime))
+	}
+
+	for _, rs := range resultSets {
+		rs.release()
+	}
+}
+
+// addResultSet adds the filtering result of shard for releasing after query.
+func (ctx *storageExecuteContext) addResultSet(rs *timeSpanResultSet) {
+	ctx.mutex.Lock()
+	ctx.resultSets = append(ctx.resultSets, rs)
+	ctx.mutex.Unlock()
+}
+
 // setTagFilterResult sets tag filter result
 func (ctx *storageExecuteContext) setTagFilterResult(tagFilterResult map[string]*tagFilterResult) {
 	ctx.tagFilterResult = tagFilterResult
@@ -73,8 +109,11 @@ func (f timeSpans) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
 
 // timeSpan represents a time span in query time range.
 type timeSpan struct {
-	identifier     string
-	familyTime     int64
+	identifier string
+	familyTime int64
+	// slotBaseTime is the base time of slot index, maybe not equal family time
+	// if family window less than default family time window.
+	slotBaseTime   int64
 	source, target timeutil.SlotRange
 	interval       timeutil.Interval
 
@@ -100,12 +139,15 @@ func (s *timeSpanResultSet) addFilterResultSet(interval timeutil.Interval, rs fl
 	familyTime := rs.FamilyTime()
 	span, ok := s.spanMap[familyTime]
 	if !ok {
+		calc := interval.Calculator()
+		segmentTime := calc.CalcSegmentTime(familyTime)
 		span = &timeSpan{
-			identifier: rs.Identifier(),
-			familyTime: familyTime,
-			source:     rs.SlotRange(),
-			target:     rs.SlotRange(),
-			interval:   interval,
+			identifier:   rs.Identifier(),
+			familyTime:   familyTime,
+			slotBaseTime: calc.CalcFamilyStartTime(segmentTime, calc.CalcFamily(familyTime, segmentTime)),
+			source:       rs.SlotRange(),
+			target:       rs.SlotRange(),
+			interval:     interval,
 		}
 		s.spanMap[familyTime] = span
 	} else {
@@ -141,6 +183,15 @@ func (s *timeSpanResultSet) getTimeSpans() timeSpans {
 	return timeSpans
 }
 
+// release releases the resources held by all filter result sets.
+func (s *timeSpanResultSet) release() {
+	for _, span := range s.spanMap {
+		for _, rs := range span.resultSets {
+			rs.Close()
+		}
+	}
+}
+
 // getSeriesIDs returns final series ids after family filtering.
 func (s *timeSpanResultSet) getSeriesIDs() *roaring.Bitmap {
 	return s.seriesIDs
@@ -21,9 +21,14 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/golang/mock/gomock"
+	"github.com/lindb/roaring"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/flow"
+	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/sql/stmt"
+	"github.com/lindb/lindb/tsdb"
 )
 
 func TestStorageExecuteContext(t *testing.T) {
@@ -36,3 +41,51 @@ func TestStorageExecuteContext(t *testing.T) {
 
 	_ = newTimeSpanResultSet().getFilterRSCount()
 }
+
+func TestStorageExecuteContext_Release(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newStorageExecuteContext(nil, &stmt.Query{})
+	rs := newTimeSpanResultSet()
+	filterRS := flow.NewMockFilterResultSet(ctrl)
+	filterRS.EXPECT().FamilyTime().Return(int64(10)).AnyTimes()
+	filterRS.EXPECT().Identifier().Return("file").AnyTimes()
+	filterRS.EXPECT().SlotRange().Return(timeutil.SlotRange{Start: 1, End: 10}).AnyTimes()
+	filterRS.EXPECT().SeriesIDs().Return(roaring.BitmapOf(1, 2)).AnyTimes()
+	rs.addFilterResultSet(timeutil.Interval(10), filterRS)
+	rs.addFilterResultSet(timeutil.Interval(10), filterRS)
+	ctx.addResultSet(rs)
+
+	// release all filter result sets
+	filterRS.EXPECT().Close().Times(2)
+	ctx.Release()
+	// release only once
+	ctx.Release()
+
+	// report query latency
+	ioCoordinator := tsdb.NewMockIOCoordinator(ctrl)
+	ctx.ioCoordinator = ioCoordinator
+	ioCoordinator.EXPECT().ObserveQueryLatency(gomock.Any())
+	ctx.Release()
+}
+
+func TestTimeSpanResultSet_SlotBaseTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now, _ := timeutil.ParseTimestamp("20190702 19:30:00", "20060102 15:04:05")
+	hour, _ := timeutil.ParseTimestamp("20190702 19:00:00", "20060102 15:04:05")
+	rs := newTimeSpanResultSet()
+	filterRS := flow.NewMockFilterResultSet(ctrl)
+	filterRS.EXPECT().FamilyTime().Return(now).AnyTimes()
+	filterRS.EXPECT().Identifier().Return("memory").AnyTimes()
+	filterRS.EXPECT().SlotRange().Return(timeutil.SlotRange{Start: 180, End: 359}).AnyTimes()
+	filterRS.EXPECT().SeriesIDs().Return(roaring.BitmapOf(1, 2)).AnyTimes()
+	rs.addFilterResultSet(timeutil.Interval(10*timeutil.OneSecond), filterRS)
+	spans := rs.getTimeSpans()
+	assert.Len(t, spans, 1)
+	// family window less than one hour, slot index based on hour
+	assert.Equal(t, now, spans[0].familyTime)
+	assert.Equal(t, hour, spans[0].slotBaseTime)
+}
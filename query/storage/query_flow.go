@@ -29,7 +29,9 @@ import (
 	"github.com/lindb/lindb/aggregation"
 	"github.com/lindb/lindb/flow"
 	"github.com/lindb/lindb/internal/concurrent"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/bufpool"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/timeutil"
@@ -61,6 +63,7 @@ type storageQueryFlow struct {
 	leafNode          *models.Leaf
 	req               *protoCommonV1.TaskRequest
 	ctx               context.Context
+	span              *tracing.Span
 	serverFactory     rpc.TaskServerFactory
 
 	aggregatorSpecs []*protoCommonV1.AggregatorSpec
@@ -70,6 +73,9 @@ type storageQueryFlow struct {
 	tagValues    []string
 	signal       sync.WaitGroup
 
+	exemplars    map[string][]*protoCommonV1.Exemplar // tag value ids => exemplars of series in group
+	stringValues map[uint64]string                    // value id => string value of string fields
+
 	mux       sync.Mutex
 	completed atomic.Bool
 }
@@ -83,8 +89,11 @@ func NewStorageQueryFlow(
 	leafNode *models.Leaf,
 	executorPool *tsdb.ExecutorPool,
 ) flow.StorageQueryFlow {
+	ctx, span := tracing.StartSpan(ctx, "storage.query_flow")
+	span.SetAttribute("metric", query.MetricName)
 	return &storageQueryFlow{
 		ctx:               ctx,
+		span:              span,
 		storageExecuteCtx: storageExecuteCtx,
 		query:             query,
 		req:               req,
@@ -126,11 +135,14 @@ func (qf *storageQueryFlow) Prepare(
 // Complete completes the query flow with error
 func (qf *storageQueryFlow) Complete(err error) {
 	if err != nil && qf.completed.CAS(false, true) {
+		qf.span.SetError(err)
+		defer qf.span.End()
 		// if complete with err, need send err msg directly and mark task completed
 		for _, receiver := range qf.leafNode.Receivers {
 			stream := qf.serverFactory.GetStream(receiver.Indicator())
 			if stream == nil {
 				storageQueryFlowLogger.Error("unable to get stream for answering error",
+					logger.String("queryID", qf.req.GetQueryID()), logger.String("requestID", query.RequestID(qf.req)),
 					logger.String("target", receiver.Indicator()))
 				continue
 			}
@@ -140,12 +152,28 @@ func (qf *storageQueryFlow) Complete(err error) {
 				Completed: true,
 				ErrMsg:    err.Error(),
 			}); err != nil {
-				storageQueryFlowLogger.Error("send storage execute result", logger.Error(err))
+				storageQueryFlowLogger.Error("send storage execute result",
+					logger.String("queryID", qf.req.GetQueryID()), logger.String("requestID", query.RequestID(qf.req)),
+					logger.Error(err))
 			}
 		}
 	}
 }
 
+// Cancel cancels the query flow without sending response, pending tasks are rejected,
+// used for cancelling the loser of hedged leaf tasks.
+func (qf *storageQueryFlow) Cancel() {
+	if qf.completed.CAS(false, true) {
+		qf.span.SetAttribute("cancelled", "true")
+		qf.span.End()
+	}
+}
+
+// isCompleted returns if the query flow is completed(or cancelled).
+func (qf *storageQueryFlow) isCompleted() bool {
+	return qf.completed.Load()
+}
+
 func (qf *storageQueryFlow) Load(task concurrent.Task) {
 	qf.execute(Scanner, task)
 }
@@ -179,6 +207,46 @@ func (qf *storageQueryFlow) ReduceTagValues(tagKeyIndex int, tagValues map[uint3
 	qf.signal.Done()
 }
 
+// ReduceExemplars reduces the exemplars of histogram series in group
+func (qf *storageQueryFlow) ReduceExemplars(tags string, exemplars []*protoCommonV1.Exemplar) {
+	if len(exemplars) == 0 {
+		return
+	}
+	qf.mux.Lock()
+	defer qf.mux.Unlock()
+	if qf.exemplars == nil {
+		qf.exemplars = make(map[string][]*protoCommonV1.Exemplar)
+	}
+	qf.exemplars[tags] = append(qf.exemplars[tags], exemplars...)
+}
+
+// ReduceStringValues reduces the string values of string fields by value id
+func (qf *storageQueryFlow) ReduceStringValues(values map[uint64]string) {
+	if len(values) == 0 {
+		return
+	}
+	qf.mux.Lock()
+	defer qf.mux.Unlock()
+	if qf.stringValues == nil {
+		qf.stringValues = make(map[uint64]string)
+	}
+	for id, value := range values {
+		qf.stringValues[id] = value
+	}
+}
+
+// makeStringValues returns the string dictionary entries of string fields in result
+func (qf *storageQueryFlow) makeStringValues() []*protoCommonV1.StringValue {
+	if len(qf.stringValues) == 0 {
+		return nil
+	}
+	stringValues := make([]*protoCommonV1.StringValue, 0, len(qf.stringValues))
+	for id, value := range qf.stringValues {
+		stringValues = append(stringValues, &protoCommonV1.StringValue{Id: id, Value: value})
+	}
+	return stringValues
+}
+
 func (qf *storageQueryFlow) getTagValues(tags string) string {
 	tagValues, ok := qf.tagsMap[tags]
 	if ok {
@@ -209,25 +277,39 @@ func (qf *storageQueryFlow) completeTask(taskID int32) {
 	completed = len(qf.pendingTasks) == 0
 	qf.mux.Unlock()
 
+	if completed {
+		// all tasks completed(maybe query completed with err), release resources retained by filtering
+		defer qf.storageExecuteCtx.Release()
+	}
 	if !completed || !qf.completed.CAS(false, true) {
 		return
 	}
 
 	hashGroupData := make([][]byte, len(qf.leafNode.Receivers))
+	// payload buffers are pooled, release them after all responses sent
+	var payloadBufs []*[]byte
+	defer func() {
+		for _, buf := range payloadBufs {
+			bufpool.PutBytes(buf)
+		}
+	}()
 	if qf.reduceAgg != nil {
 		hasGroupBy := qf.query.HasGroupBy()
 		if hasGroupBy {
 			qf.signal.Wait() // wait collect group by tag value complete
 		}
 		timeSeriesList := qf.makeTimeSeriesList()
+		stringValues := qf.makeStringValues()
 		// root -> leaf task, return the raw total series
 		if len(qf.leafNode.Receivers) == 1 {
 			leaf2RootSeries := protoCommonV1.TimeSeriesList{
 				TimeSeriesList: timeSeriesList,
 				FieldAggSpecs:  qf.aggregatorSpecs,
+				StringValues:   stringValues,
 			}
-			leaf2RootSeriesPayload, _ := leaf2RootSeries.Marshal()
-			hashGroupData[0] = leaf2RootSeriesPayload
+			leaf2RootSeriesPayload := query.MarshalTimeSeriesList(&leaf2RootSeries)
+			payloadBufs = append(payloadBufs, leaf2RootSeriesPayload)
+			hashGroupData[0] = *leaf2RootSeriesPayload
 		} else {
 			// during intermediate task, time series will be grouped by hash
 			// and send to multi intermediate receiver
@@ -242,13 +324,16 @@ func (qf *storageQueryFlow) completeTask(taskID int32) {
 				leaf2IntermediateSeries := protoCommonV1.TimeSeriesList{
 					TimeSeriesList: timeSeriesHashGroup,
 					FieldAggSpecs:  qf.aggregatorSpecs,
+					StringValues:   stringValues,
 				}
-				leaf2IntermediatePayload, _ := leaf2IntermediateSeries.Marshal()
-				hashGroupData[idx] = leaf2IntermediatePayload
+				leaf2IntermediatePayload := query.MarshalTimeSeriesList(&leaf2IntermediateSeries)
+				payloadBufs = append(payloadBufs, leaf2IntermediatePayload)
+				hashGroupData[idx] = *leaf2IntermediatePayload
 			}
 		}
 	}
 	qf.sendResponse(hashGroupData)
+	qf.span.End()
 }
 
 func (qf *storageQueryFlow) sendResponse(hashGroupData [][]byte) {
@@ -261,6 +346,7 @@ func (qf *storageQueryFlow) sendResponse(hashGroupData [][]byte) {
 		stream := qf.serverFactory.GetStream(receiver.Indicator())
 		if stream == nil {
 			storageQueryFlowLogger.Error("unable to get stream for write response",
+				logger.String("queryID", qf.req.GetQueryID()), logger.String("requestID", query.RequestID(qf.req)),
 				logger.String("target", receiver.Indicator()))
 			qf.Complete(query.ErrNoSendStream)
 			break
@@ -273,7 +359,9 @@ func (qf *storageQueryFlow) sendResponse(hashGroupData [][]byte) {
 			Payload:   hashGroupData[idx],
 			Stats:     stats,
 		}); err != nil {
-			storageQueryFlowLogger.Error("send storage query result", logger.Error(err))
+			storageQueryFlowLogger.Error("send storage query result",
+				logger.String("queryID", qf.req.GetQueryID()), logger.String("requestID", query.RequestID(qf.req)),
+				logger.Error(err))
 		}
 	}
 }
@@ -291,7 +379,9 @@ func (qf *storageQueryFlow) makeTimeSeriesList() []*protoCommonV1.TimeSeries {
 			data, err := fieldIt.MarshalBinary()
 			if err != nil || len(data) == 0 {
 				if err != nil {
-					storageQueryFlowLogger.Error("marshal iterator data", logger.Error(err))
+					storageQueryFlowLogger.Error("marshal iterator data",
+						logger.String("queryID", qf.req.GetQueryID()), logger.String("requestID", query.RequestID(qf.req)),
+						logger.Error(err))
 				}
 				continue
 			}
@@ -304,8 +394,9 @@ func (qf *storageQueryFlow) makeTimeSeriesList() []*protoCommonV1.TimeSeries {
 				tags = qf.getTagValues(ts.Tags())
 			}
 			timeSeriesList = append(timeSeriesList, &protoCommonV1.TimeSeries{
-				Tags:   tags,
-				Fields: fields,
+				Tags:      tags,
+				Fields:    fields,
+				Exemplars: query.LatestExemplars(qf.exemplars[ts.Tags()]),
 			})
 		}
 	}
@@ -334,7 +425,7 @@ func (qf *storageQueryFlow) execute(stage Stage, task concurrent.Task) {
 		qf.pendingTasks[taskID] = stage
 		qf.mux.Unlock()
 
-		executePool.Submit(func() {
+		err := executePool.Submit(func() {
 			defer func() {
 				// 3. complete task and dec task pending after task handle
 				qf.completeTask(taskID)
@@ -350,6 +441,7 @@ func (qf *storageQueryFlow) execute(stage Stage, task concurrent.Task) {
 						err = errors.New("unknown error")
 					}
 					storageQueryFlowLogger.Error("do task fail when execute storage query flow",
+						logger.String("queryID", qf.req.GetQueryID()), logger.String("requestID", query.RequestID(qf.req)),
 						logger.Error(err), logger.Stack())
 					qf.Complete(err)
 				}
@@ -358,5 +450,10 @@ func (qf *storageQueryFlow) execute(stage Stage, task concurrent.Task) {
 			// 2. handle task logic in background goroutine
 			task()
 		})
+		if err != nil {
+			// task rejected by pool, completes query flow with error, avoids waiting pending task forever
+			qf.Complete(err)
+			qf.completeTask(taskID)
+		}
 	}
 }
@@ -71,6 +71,7 @@ func TestStorageQueryFlow_Execute(t *testing.T) {
 
 	storageExecuteCtx := NewMockStorageExecuteContext(ctrl)
 	storageExecuteCtx.EXPECT().QueryStats().Return(models.NewStorageStats()).AnyTimes()
+	storageExecuteCtx.EXPECT().Release().AnyTimes()
 	taskServerFactory := rpc.NewMockTaskServerFactory(ctrl)
 	taskServerFactory.EXPECT().GetStream(gomock.Any()).Return(nil)
 
@@ -132,6 +133,7 @@ func TestStorageQueryFlow_completeTask(t *testing.T) {
 
 	storageExecuteCtx := NewMockStorageExecuteContext(ctrl)
 	storageExecuteCtx.EXPECT().QueryStats().Return(nil).AnyTimes()
+	storageExecuteCtx.EXPECT().Release().AnyTimes()
 	taskServerFactory := rpc.NewMockTaskServerFactory(ctrl)
 	server := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
 	server.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
@@ -202,12 +204,70 @@ func TestStorageQueryFlow_completeTask(t *testing.T) {
 	time.Sleep(300 * time.Millisecond)
 }
 
+func TestStorageQueryFlow_exemplars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFlow := NewStorageQueryFlow(
+		context.TODO(),
+		NewMockStorageExecuteContext(ctrl),
+		&stmt.Query{Exemplars: true},
+		&protoCommonV1.TaskRequest{},
+		rpc.NewMockTaskServerFactory(ctrl),
+		&models.Leaf{Receivers: []models.Node{{IP: "1.1.1.1", Port: 1000}}},
+		testExecPool,
+	)
+	qf := queryFlow.(*storageQueryFlow)
+	reduceAgg := aggregation.NewMockGroupingAggregator(ctrl)
+	qf.reduceAgg = reduceAgg
+	queryFlow.ReduceExemplars("", nil)
+	assert.Nil(t, qf.exemplars)
+	queryFlow.ReduceExemplars("", []*protoCommonV1.Exemplar{{TraceID: "1a", Timestamp: 20}})
+	queryFlow.ReduceExemplars("", []*protoCommonV1.Exemplar{{TraceID: "2b", Timestamp: 10}})
+
+	groupIt := series.NewMockGroupedIterator(ctrl)
+	it := series.NewMockIterator(ctrl)
+	groupIt.EXPECT().HasNext().Return(true)
+	groupIt.EXPECT().Next().Return(it)
+	groupIt.EXPECT().HasNext().Return(false)
+	groupIt.EXPECT().Tags().Return("")
+	it.EXPECT().MarshalBinary().Return([]byte{1, 2, 3}, nil)
+	it.EXPECT().FieldName().Return(field.Name("f1"))
+	reduceAgg.EXPECT().ResultSet().Return([]series.GroupedIterator{groupIt})
+	timeSeriesList := qf.makeTimeSeriesList()
+	assert.Len(t, timeSeriesList, 1)
+	assert.Equal(t, []*protoCommonV1.Exemplar{{TraceID: "2b", Timestamp: 10}, {TraceID: "1a", Timestamp: 20}},
+		timeSeriesList[0].Exemplars)
+}
+
+func TestStorageQueryFlow_stringValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queryFlow := NewStorageQueryFlow(
+		context.TODO(),
+		NewMockStorageExecuteContext(ctrl),
+		&stmt.Query{},
+		&protoCommonV1.TaskRequest{},
+		rpc.NewMockTaskServerFactory(ctrl),
+		&models.Leaf{Receivers: []models.Node{{IP: "1.1.1.1", Port: 1000}}},
+		testExecPool,
+	)
+	qf := queryFlow.(*storageQueryFlow)
+	queryFlow.ReduceStringValues(nil)
+	assert.Nil(t, qf.makeStringValues())
+	queryFlow.ReduceStringValues(map[uint64]string{1: "v1.0.0"})
+	queryFlow.ReduceStringValues(map[uint64]string{1: "v1.0.0"})
+	assert.Equal(t, []*protoCommonV1.StringValue{{Id: 1, Value: "v1.0.0"}}, qf.makeStringValues())
+}
+
 func TestStorageQueryFlow_getValues(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	storageExecuteCtx := NewMockStorageExecuteContext(ctrl)
 	storageExecuteCtx.EXPECT().QueryStats().Return(nil).AnyTimes()
+	storageExecuteCtx.EXPECT().Release().AnyTimes()
 	taskServerFactory := rpc.NewMockTaskServerFactory(ctrl)
 	server := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
 	server.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
@@ -248,6 +308,7 @@ func TestStorageQueryFlow_Task_panic(t *testing.T) {
 
 	storageExecuteCtx := NewMockStorageExecuteContext(ctrl)
 	storageExecuteCtx.EXPECT().QueryStats().Return(nil).AnyTimes()
+	storageExecuteCtx.EXPECT().Release().AnyTimes()
 	queryFlow := NewStorageQueryFlow(context.TODO(),
 		storageExecuteCtx, &stmt.Query{},
 		&protoCommonV1.TaskRequest{},
@@ -273,12 +334,41 @@ func TestStorageQueryFlow_Task_panic(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 }
 
+func TestStorageQueryFlow_Task_rejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storageExecuteCtx := NewMockStorageExecuteContext(ctrl)
+	storageExecuteCtx.EXPECT().QueryStats().Return(nil).AnyTimes()
+	storageExecuteCtx.EXPECT().Release()
+	taskServerFactory := rpc.NewMockTaskServerFactory(ctrl)
+	server := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
+	taskServerFactory.EXPECT().GetStream(gomock.Any()).Return(server)
+	server.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoCommonV1.TaskResponse) error {
+		assert.Equal(t, concurrent.ErrPoolStopped.Error(), resp.ErrMsg)
+		return nil
+	})
+	pool := concurrent.NewPool("test-stopped-pool", 1, time.Second, linmetric.NewScope("test-stopped-pool"))
+	pool.Stop()
+	queryFlow := NewStorageQueryFlow(context.TODO(),
+		storageExecuteCtx, &stmt.Query{},
+		&protoCommonV1.TaskRequest{},
+		taskServerFactory,
+		&models.Leaf{Receivers: []models.Node{{IP: "1.1.1.1", Port: 1000}}},
+		&tsdb.ExecutorPool{Filtering: pool})
+	queryFlow.Prepare(timeutil.Interval(timeutil.OneSecond), 1, timeutil.TimeRange{}, nil)
+	queryFlow.Filtering(func() {
+		panic("task must not be executed")
+	})
+}
+
 func TestStorageQueryFlow_Complete(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	storageExecuteCtx := NewMockStorageExecuteContext(ctrl)
 	storageExecuteCtx.EXPECT().QueryStats().Return(nil).AnyTimes()
+	storageExecuteCtx.EXPECT().Release().AnyTimes()
 	taskServerFactory := rpc.NewMockTaskServerFactory(ctrl)
 	server := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
 	taskServerFactory.EXPECT().GetStream(gomock.Any()).Return(server).Times(2)
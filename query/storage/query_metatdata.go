@@ -18,14 +18,29 @@
 package storagequery
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lindb/roaring"
 
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/hll"
+	"github.com/lindb/lindb/pkg/strutil"
 	"github.com/lindb/lindb/sql/stmt"
 	"github.com/lindb/lindb/tsdb"
+	"github.com/lindb/lindb/tsdb/metadb"
 )
 
+// exactTagValuesThreshold is the max number of tag values returned for exact series cardinality,
+// HyperLogLog sketch is returned instead if exceeded.
+const exactTagValuesThreshold = 1000
+
 // metadataStorageExecutor represents the executor which executes metric metadata suggest in storage side
 type metadataStorageExecutor struct {
 	database tsdb.Database
@@ -46,25 +61,194 @@ func newStorageMetadataQuery(
 	}
 }
 
-// Execute executes the metadata suggest query based on query type
-func (e *metadataStorageExecutor) Execute() (result []string, err error) {
+// Execute executes the metadata suggest query based on query type,
+// returns the values filtered by prefix/regex and the total-count hint of matched values.
+// Values are sorted and only the leading offset+limit values are returned,
+// because offset is applied by broker after merging the results of all storage nodes.
+func (e *metadataStorageExecutor) Execute() (result []string, total int, err error) {
 	req := e.request
-	limit := req.Limit
+	if req.Type == stmt.Field {
+		fields, err := e.database.Metadata().MetadataDatabase().GetAllFields(req.Namespace, req.MetricName)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, string(encoding.JSONMarshal(fields)))
+		return result, 0, nil
+	}
+	if req.Type == stmt.SeriesCardinality {
+		cardinality, err := e.seriesCardinality()
+		if err != nil {
+			return nil, 0, err
+		}
+		return []string{string(encoding.JSONMarshal(cardinality))}, 0, nil
+	}
+	if req.Type == stmt.TagValueCardinality {
+		return []string{string(encoding.JSONMarshal(e.tagValueCardinality()))}, 0, nil
+	}
+	if req.Type == stmt.MetricDescriptor {
+		descriptor, err := e.database.Metadata().MetadataDatabase().GetMetricDescriptor(req.Namespace, req.MetricName)
+		if err != nil {
+			if errors.Is(err, constants.ErrNotFound) {
+				return nil, 0, nil
+			}
+			return nil, 0, err
+		}
+		return []string{string(encoding.JSONMarshal(descriptor))}, 0, nil
+	}
+	var matcher *regexp.Regexp
+	if req.Regex != "" {
+		matcher, err = regexp.Compile(req.Regex)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	// scan bounded candidates, because regex/offset can only be applied after scanning
+	values, err := e.suggest(constants.MaxSuggestions)
+	if err != nil {
+		return nil, 0, err
+	}
+	if matcher != nil {
+		matched := values[:0]
+		for _, value := range values {
+			if matcher.MatchString(value) {
+				matched = append(matched, value)
+			}
+		}
+		values = matched
+	}
+	values = strutil.DeDupStringSlice(values)
+	sort.Strings(values)
+	total = len(values)
+	if req.Limit > 0 {
+		values = strutil.Paginate(values, 0, req.Offset+req.Limit)
+	}
+	return values, total, nil
+}
+
+// tagValueCardinality returns the top-k heavy hitter tag values of given tag key within the window,
+// counted by series created or points written, based on the sketches maintained by write path.
+func (e *metadataStorageExecutor) tagValueCardinality() *models.TagValueCardinality {
+	req := e.request
+	window := time.Duration(req.Window) * time.Millisecond
+	if window <= 0 || window > metadb.MaxTagValueStatsWindow {
+		window = metadb.MaxTagValueStatsWindow
+	}
+	by := "points"
+	if req.BySeries {
+		by = "series"
+	}
+	return &models.TagValueCardinality{
+		MetricName: req.MetricName,
+		TagKey:     req.TagKey,
+		By:         by,
+		Window:     window.Milliseconds(),
+		Values: e.database.Metadata().TagValueStats().TopK(
+			req.Namespace, req.MetricName, req.TagKey, req.BySeries, window, req.Limit),
+	}
+}
+
+// seriesCardinality counts the series of metric and distinct tag values of each tag key in query shards,
+// tag values are returned if count <= exactTagValuesThreshold, else HyperLogLog sketch of tag values is returned,
+// then broker can merge the results of storage nodes.
+func (e *metadataStorageExecutor) seriesCardinality() (*models.SeriesCardinality, error) {
+	req := e.request
+	tagKeys, err := e.database.Metadata().MetadataDatabase().GetAllTagKeys(req.Namespace, req.MetricName)
+	if err != nil {
+		return nil, err
+	}
+	var tagFilterResult map[string]*tagFilterResult
+	if req.Condition != nil {
+		if err := checkRangeFilter(req.Condition, e.database.GetOption()); err != nil {
+			return nil, err
+		}
+		tagSearch := newTagSearchFunc(req.Namespace, req.MetricName,
+			req.Condition, e.database.Metadata())
+		tagFilterResult, err = tagSearch.Filter()
+		if err != nil {
+			return nil, err
+		}
+		if len(tagFilterResult) == 0 {
+			// filter not match, return not found
+			return nil, fmt.Errorf("%w , namespace: %s, metricName: %s",
+				constants.ErrTagFilterResultNotFound, req.Namespace, req.MetricName)
+		}
+	}
+	tagKeyIDs := make([]uint32, len(tagKeys))
+	tagValueIDs := make([]*roaring.Bitmap, len(tagKeys))
+	for idx, tagKey := range tagKeys {
+		tagKeyIDs[idx] = tagKey.ID
+		tagValueIDs[idx] = roaring.New()
+	}
+	result := &models.SeriesCardinality{MetricName: req.MetricName}
+	for _, shardID := range e.shardIDs {
+		shard, ok := e.database.GetShard(shardID)
+		if !ok {
+			continue
+		}
+		var seriesIDs *roaring.Bitmap
+		if tagFilterResult == nil {
+			seriesIDs, err = shard.IndexDatabase().GetSeriesIDsForMetric(req.Namespace, req.MetricName)
+		} else {
+			seriesIDs, err = newSeriesSearchFunc(shard.IndexDatabase(), tagFilterResult, req.Condition).Search()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if seriesIDs == nil || seriesIDs.IsEmpty() {
+			continue
+		}
+		// series of different shards are disjoint
+		result.Series += seriesIDs.GetCardinality()
+		if len(tagKeyIDs) == 0 {
+			continue
+		}
+		gCtx, err := shard.IndexDatabase().GetGroupingContext(tagKeyIDs, seriesIDs)
+		if err != nil {
+			return nil, err
+		}
+		// tag value ids are unique in database, so distinct tag values of shards can be merged by ids
+		highKeys := seriesIDs.GetHighKeys()
+		for i, highKey := range highKeys {
+			ids := gCtx.ScanTagValueIDs(highKey, seriesIDs.GetContainerAtIndex(i))
+			for idx := range ids {
+				tagValueIDs[idx].Or(ids[idx])
+			}
+		}
+	}
+	for idx, tagKey := range tagKeys {
+		cardinality := models.TagKeyCardinality{TagKey: tagKey.Key, Values: tagValueIDs[idx].GetCardinality()}
+		if cardinality.Values > 0 {
+			tagValues := make(map[uint32]string)
+			if err := e.database.Metadata().TagMetadata().CollectTagValues(tagKey.ID, tagValueIDs[idx], tagValues); err != nil {
+				return nil, err
+			}
+			if cardinality.Values <= exactTagValuesThreshold {
+				for _, tagValue := range tagValues {
+					cardinality.TagValues = append(cardinality.TagValues, tagValue)
+				}
+			} else {
+				sketch := hll.New()
+				for _, tagValue := range tagValues {
+					sketch.InsertString(tagValue)
+				}
+				cardinality.Sketch = sketch.Bytes()
+			}
+		}
+		result.TagKeys = append(result.TagKeys, cardinality)
+	}
+	return result, nil
+}
 
+// suggest returns at most scanLimit candidates which match the prefix based on query type.
+func (e *metadataStorageExecutor) suggest(scanLimit int) (result []string, err error) {
+	req := e.request
 	switch req.Type {
 	case stmt.Namespace:
-		return e.database.Metadata().MetadataDatabase().SuggestNamespace(req.Prefix, limit)
+		return e.database.Metadata().MetadataDatabase().SuggestNamespace(req.Prefix, scanLimit)
 	case stmt.Metric:
-		return e.database.Metadata().MetadataDatabase().SuggestMetrics(req.Namespace, req.Prefix, limit)
+		return e.database.Metadata().MetadataDatabase().SuggestMetrics(req.Namespace, req.Prefix, scanLimit)
 	case stmt.TagKey:
-		return e.database.Metadata().MetadataDatabase().SuggestTagKeys(req.Namespace, req.MetricName, req.Prefix, limit)
-	case stmt.Field:
-		fields, err := e.database.Metadata().MetadataDatabase().GetAllFields(req.Namespace, req.MetricName)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, string(encoding.JSONMarshal(fields)))
-		return result, nil
+		return e.database.Metadata().MetadataDatabase().SuggestTagKeys(req.Namespace, req.MetricName, req.Prefix, scanLimit)
 	case stmt.TagValue:
 		tagKeyID, err := e.database.Metadata().MetadataDatabase().GetTagKeyID(req.Namespace, req.MetricName, req.TagKey)
 		if err != nil {
@@ -72,54 +256,63 @@ func (e *metadataStorageExecutor) Execute() (result []string, err error) {
 		}
 		if req.Condition == nil {
 			// if not tag filter condition, just get tag value by tag key
-			result = e.database.Metadata().TagMetadata().SuggestTagValues(tagKeyID, req.Prefix, limit)
-		} else {
-			// 1. do tag filter
-			tagSearch := newTagSearchFunc(req.Namespace, req.MetricName,
-				req.Condition, e.database.Metadata())
-			tagFilterResult, err := tagSearch.Filter()
+			result = e.database.Metadata().TagMetadata().SuggestTagValues(tagKeyID, req.Prefix, scanLimit)
+			if len(result) > scanLimit {
+				result = result[:scanLimit]
+			}
+			return result, nil
+		}
+		// 1. do tag filter
+		if err := checkRangeFilter(req.Condition, e.database.GetOption()); err != nil {
+			return nil, err
+		}
+		tagSearch := newTagSearchFunc(req.Namespace, req.MetricName,
+			req.Condition, e.database.Metadata())
+		tagFilterResult, err := tagSearch.Filter()
+		if err != nil {
+			return nil, err
+		}
+		if len(tagFilterResult) == 0 {
+			// filter not match, return not found
+			return nil, fmt.Errorf("%w , namespace: %s, metricName: %s",
+				constants.ErrTagFilterResultNotFound, req.Namespace, req.MetricName)
+		}
+		groupByTagKeyIDs := []uint32{tagKeyID}
+		// get shard by given query shard id list
+		for _, shardID := range e.shardIDs {
+			shard, ok := e.database.GetShard(shardID)
+			if !ok {
+				continue
+			}
+			// if shard exist, do series search
+			// if get tag filter result do series ids searching
+			seriesSearch := newSeriesSearchFunc(shard.IndexDatabase(), tagFilterResult, req.Condition)
+			seriesIDs, err := seriesSearch.Search()
 			if err != nil {
 				return nil, err
 			}
-			if len(tagFilterResult) == 0 {
-				// filter not match, return not found
-				return nil, fmt.Errorf("%w , namespace: %s, metricName: %s",
-					constants.ErrTagFilterResultNotFound, req.Namespace, req.MetricName)
+			// get grouping based on tag keys and series ids
+			gCtx, err := shard.IndexDatabase().GetGroupingContext(groupByTagKeyIDs, seriesIDs)
+			if err != nil {
+				return nil, err
 			}
-			groupByTagKeyIDs := []uint32{tagKeyID}
-			// get shard by given query shard id list
-			for _, shardID := range e.shardIDs {
-				shard, ok := e.database.GetShard(shardID)
-				if !ok {
-					continue
-				}
-				// if shard exist, do series search
-				// if get tag filter result do series ids searching
-				seriesSearch := newSeriesSearchFunc(shard.IndexDatabase(), tagFilterResult, req.Condition)
-				seriesIDs, err := seriesSearch.Search()
-				if err != nil {
-					return nil, err
-				}
-				// get grouping based on tag keys and series ids
-				gCtx, err := shard.IndexDatabase().GetGroupingContext(groupByTagKeyIDs, seriesIDs)
+			highKeys := seriesIDs.GetHighKeys()
+			for i, highKey := range highKeys {
+				// get tag value ids
+				tagValueIDs := gCtx.ScanTagValueIDs(highKey, seriesIDs.GetContainerAtIndex(i))
+				tagValues := make(map[uint32]string)
+				// get tag value
+				err = e.database.Metadata().TagMetadata().CollectTagValues(tagKeyID, tagValueIDs[0], tagValues)
 				if err != nil {
 					return nil, err
 				}
-				highKeys := seriesIDs.GetHighKeys()
-				for i, highKey := range highKeys {
-					// get tag value ids
-					tagValueIDs := gCtx.ScanTagValueIDs(highKey, seriesIDs.GetContainerAtIndex(i))
-					tagValues := make(map[uint32]string)
-					// get tag value
-					err = e.database.Metadata().TagMetadata().CollectTagValues(tagKeyID, tagValueIDs[0], tagValues)
-					if err != nil {
-						return nil, err
+				for _, tagValue := range tagValues {
+					if !strings.HasPrefix(tagValue, req.Prefix) {
+						continue
 					}
-					for _, tagValue := range tagValues {
-						result = append(result, tagValue)
-						if len(result) >= limit {
-							return result, nil
-						}
+					result = append(result, tagValue)
+					if len(result) >= scanLimit {
+						return result, nil
 					}
 				}
 			}
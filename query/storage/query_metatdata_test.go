@@ -18,16 +18,24 @@
 package storagequery
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/lindb/roaring"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/hll"
+	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/series/tag"
 	"github.com/lindb/lindb/sql/stmt"
 	"github.com/lindb/lindb/tsdb"
 	"github.com/lindb/lindb/tsdb/indexdb"
@@ -50,7 +58,7 @@ func TestMetadataStorageQuery_Execute(t *testing.T) {
 		Type: stmt.Namespace,
 	})
 	metadataIndex.EXPECT().SuggestNamespace(gomock.Any(), gomock.Any()).Return([]string{"a"}, nil)
-	result, err := exec.Execute()
+	result, _, err := exec.Execute()
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"a"}, result)
 
@@ -59,7 +67,7 @@ func TestMetadataStorageQuery_Execute(t *testing.T) {
 		Type: stmt.Metric,
 	})
 	metadataIndex.EXPECT().SuggestMetrics(gomock.Any(), gomock.Any(), gomock.Any()).Return([]string{"a"}, nil)
-	result, err = exec.Execute()
+	result, _, err = exec.Execute()
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"a"}, result)
 
@@ -68,7 +76,7 @@ func TestMetadataStorageQuery_Execute(t *testing.T) {
 		Type: stmt.TagKey,
 	})
 	metadataIndex.EXPECT().SuggestTagKeys(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]string{"a"}, nil)
-	result, err = exec.Execute()
+	result, _, err = exec.Execute()
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"a"}, result)
 	// case 4: get fields err
@@ -76,7 +84,7 @@ func TestMetadataStorageQuery_Execute(t *testing.T) {
 		Type: stmt.Field,
 	})
 	metadataIndex.EXPECT().GetAllFields(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
-	result, err = exec.Execute()
+	result, _, err = exec.Execute()
 	assert.Error(t, err)
 	assert.Empty(t, result)
 
@@ -85,7 +93,7 @@ func TestMetadataStorageQuery_Execute(t *testing.T) {
 		Type: stmt.Field,
 	})
 	metadataIndex.EXPECT().GetAllFields(gomock.Any(), gomock.Any()).Return([]field.Meta{{ID: 10}}, nil)
-	result, err = exec.Execute()
+	result, _, err = exec.Execute()
 	assert.NoError(t, err)
 	assert.Equal(t, string(encoding.JSONMarshal([]field.Meta{{ID: 10}})), result[0])
 
@@ -99,7 +107,7 @@ func TestMetadataStorageQuery_Execute(t *testing.T) {
 	metadata.EXPECT().TagMetadata().Return(tagMeta).AnyTimes()
 
 	tagMeta.EXPECT().SuggestTagValues(gomock.Any(), gomock.Any(), gomock.Any()).Return([]string{"a"})
-	result, err = exec.Execute()
+	result, _, err = exec.Execute()
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"a"}, result)
 
@@ -109,11 +117,65 @@ func TestMetadataStorageQuery_Execute(t *testing.T) {
 	})
 	metadataIndex.EXPECT().GetTagKeyID(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint32(0), fmt.Errorf("err"))
 
-	result, err = exec.Execute()
+	result, _, err = exec.Execute()
 	assert.Error(t, err)
 	assert.Empty(t, result)
 }
 
+func TestMetadataStorageQuery_Execute_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := tsdb.NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	metadataIndex := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataIndex).AnyTimes()
+
+	// case 1: bad regex
+	_, _, err := newStorageMetadataQuery(db, nil, &stmt.Metadata{
+		Type:  stmt.Metric,
+		Regex: "(",
+	}).Execute()
+	assert.Error(t, err)
+
+	// case 2: suggest err
+	metadataIndex.EXPECT().SuggestMetrics("ns", "cpu", constants.MaxSuggestions).Return(nil, fmt.Errorf("err"))
+	_, _, err = newStorageMetadataQuery(db, nil, &stmt.Metadata{
+		Namespace: "ns",
+		Type:      stmt.Metric,
+		Prefix:    "cpu",
+	}).Execute()
+	assert.Error(t, err)
+
+	// case 3: regex filter, sort and keep leading offset+limit values
+	metadataIndex.EXPECT().SuggestMetrics("ns", "cpu", constants.MaxSuggestions).
+		Return([]string{"cpu.user", "cpu.idle", "cpu.sys", "cpu.load", "cpu.idle"}, nil)
+	result, total, err := newStorageMetadataQuery(db, nil, &stmt.Metadata{
+		Namespace: "ns",
+		Type:      stmt.Metric,
+		Prefix:    "cpu",
+		Regex:     "^cpu\\.(user|idle|sys)$",
+		Offset:    1,
+		Limit:     1,
+	}).Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cpu.idle", "cpu.sys"}, result)
+	assert.Equal(t, 3, total)
+
+	// case 4: no limit
+	tagMeta := metadb.NewMockTagMetadata(ctrl)
+	metadata.EXPECT().TagMetadata().Return(tagMeta).AnyTimes()
+	metadataIndex.EXPECT().GetTagKeyID(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint32(2), nil)
+	tagMeta.EXPECT().SuggestTagValues(uint32(2), "", constants.MaxSuggestions).Return([]string{"b", "a"})
+	result, total, err = newStorageMetadataQuery(db, nil, &stmt.Metadata{
+		Type: stmt.TagValue,
+	}).Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, result)
+	assert.Equal(t, 2, total)
+}
+
 func TestMetadataStorageQuery_Execute_With_Tag_Condition(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -124,6 +186,7 @@ func TestMetadataStorageQuery_Execute_With_Tag_Condition(t *testing.T) {
 	}()
 
 	db := tsdb.NewMockDatabase(ctrl)
+	db.EXPECT().GetOption().Return(option.DatabaseOption{NumericTagKeys: []string{"shard_id"}}).AnyTimes()
 
 	metadata := metadb.NewMockMetadata(ctrl)
 	db.EXPECT().Metadata().Return(metadata).AnyTimes()
@@ -131,6 +194,14 @@ func TestMetadataStorageQuery_Execute_With_Tag_Condition(t *testing.T) {
 	metadata.EXPECT().MetadataDatabase().Return(metadataIndex).AnyTimes()
 	metadataIndex.EXPECT().GetTagKeyID(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint32(2), nil).AnyTimes()
 
+	// case 0: range filter on tag key not declared as numeric
+	_, _, err := newStorageMetadataQuery(db, []int32{1}, &stmt.Metadata{
+		Type:      stmt.TagValue,
+		Condition: &stmt.RangeExpr{Key: "host", Op: stmt.GreaterThan, Value: "1"},
+		Limit:     2,
+	}).Execute()
+	assert.True(t, errors.Is(err, constants.ErrTagKeyNotNumeric))
+
 	// case 1: tag search err
 	tagSearch := NewMockTagSearch(ctrl)
 	newTagSearchFunc = func(namespace, metricName string, condition stmt.Expr, metadata metadb.Metadata) TagSearch {
@@ -142,11 +213,11 @@ func TestMetadataStorageQuery_Execute_With_Tag_Condition(t *testing.T) {
 		Limit:     2,
 	})
 	tagSearch.EXPECT().Filter().Return(nil, fmt.Errorf("err"))
-	_, err := exec.Execute()
+	_, _, err = exec.Execute()
 	assert.Error(t, err)
 	// case 2: tag not found
 	tagSearch.EXPECT().Filter().Return(nil, nil)
-	_, err = exec.Execute()
+	_, _, err = exec.Execute()
 	assert.Error(t, err)
 
 	shard := tsdb.NewMockShard(ctrl)
@@ -161,13 +232,13 @@ func TestMetadataStorageQuery_Execute_With_Tag_Condition(t *testing.T) {
 		return seriesSearch
 	}
 	seriesSearch.EXPECT().Search().Return(nil, fmt.Errorf("err"))
-	_, err = exec.Execute()
+	_, _, err = exec.Execute()
 	assert.Error(t, err)
 
 	seriesSearch.EXPECT().Search().Return(roaring.BitmapOf(1, 2, 3), nil).AnyTimes()
 	// case 4: get grouping err
 	indexDB.EXPECT().GetGroupingContext(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
-	_, err = exec.Execute()
+	_, _, err = exec.Execute()
 	assert.Error(t, err)
 
 	gCtx := series.NewMockGroupingContext(ctrl)
@@ -179,7 +250,7 @@ func TestMetadataStorageQuery_Execute_With_Tag_Condition(t *testing.T) {
 
 	// case 5: collect tag value err
 	tagMeta.EXPECT().CollectTagValues(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
-	_, err = exec.Execute()
+	_, _, err = exec.Execute()
 	assert.Error(t, err)
 
 	// case 6: collect tag values
@@ -194,7 +265,211 @@ func TestMetadataStorageQuery_Execute_With_Tag_Condition(t *testing.T) {
 			tagValues[15] = "d"
 			return nil
 		})
-	result, err := exec.Execute()
+	result, total, err := exec.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, result)
+	assert.Equal(t, 4, total)
+}
+
+func TestMetadataStorageQuery_SeriesCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newTagSearchFunc = newTagSearch
+		newSeriesSearchFunc = newSeriesSearch
+
+		ctrl.Finish()
+	}()
+
+	db := tsdb.NewMockDatabase(ctrl)
+	db.EXPECT().GetOption().Return(option.DatabaseOption{}).AnyTimes()
+	metadata := metadb.NewMockMetadata(ctrl)
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	metadataIndex := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataIndex).AnyTimes()
+	tagMeta := metadb.NewMockTagMetadata(ctrl)
+	metadata.EXPECT().TagMetadata().Return(tagMeta).AnyTimes()
+	shard := tsdb.NewMockShard(ctrl)
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	shard.EXPECT().IndexDatabase().Return(indexDB).AnyTimes()
+	gCtx := series.NewMockGroupingContext(ctrl)
+
+	execute := func(condition stmt.Expr) (*models.SeriesCardinality, error) {
+		result, _, err := newStorageMetadataQuery(db, []int32{1, 2, 3}, &stmt.Metadata{
+			Namespace:  "ns",
+			MetricName: "cpu",
+			Type:       stmt.SeriesCardinality,
+			Condition:  condition,
+		}).Execute()
+		if err != nil {
+			return nil, err
+		}
+		cardinality := &models.SeriesCardinality{}
+		assert.NoError(t, encoding.JSONUnmarshal([]byte(result[0]), cardinality))
+		return cardinality, nil
+	}
+	tagKeys := []tag.Meta{{Key: "host", ID: 1}, {Key: "ip", ID: 2}}
+
+	// case 1: get tag keys err
+	metadataIndex.EXPECT().GetAllTagKeys("ns", "cpu").Return(nil, fmt.Errorf("err"))
+	_, err := execute(nil)
+	assert.Error(t, err)
+
+	// case 2: tag filter not found
+	metadataIndex.EXPECT().GetAllTagKeys("ns", "cpu").Return(tagKeys, nil).AnyTimes()
+	tagSearch := NewMockTagSearch(ctrl)
+	newTagSearchFunc = func(namespace, metricName string, condition stmt.Expr, metadata metadb.Metadata) TagSearch {
+		return tagSearch
+	}
+	tagSearch.EXPECT().Filter().Return(nil, nil)
+	_, err = execute(&stmt.EqualsExpr{Key: "host", Value: "a"})
+	assert.Error(t, err)
+
+	// case 3: series search err
+	tagSearch.EXPECT().Filter().Return(map[string]*tagFilterResult{"key": {}}, nil)
+	seriesSearch := NewMockSeriesSearch(ctrl)
+	newSeriesSearchFunc = func(filter series.Filter, filterResult map[string]*tagFilterResult, condition stmt.Expr) SeriesSearch {
+		return seriesSearch
+	}
+	db.EXPECT().GetShard(int32(1)).Return(shard, true).AnyTimes()
+	db.EXPECT().GetShard(int32(2)).Return(nil, false).AnyTimes()
+	db.EXPECT().GetShard(int32(3)).Return(shard, true).AnyTimes()
+	seriesSearch.EXPECT().Search().Return(nil, fmt.Errorf("err"))
+	_, err = execute(&stmt.EqualsExpr{Key: "host", Value: "a"})
+	assert.Error(t, err)
+
+	// case 4: grouping context err
+	indexDB.EXPECT().GetSeriesIDsForMetric("ns", "cpu").Return(roaring.BitmapOf(1, 2), nil)
+	indexDB.EXPECT().GetGroupingContext(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	_, err = execute(nil)
+	assert.Error(t, err)
+
+	// case 5: collect tag values err
+	indexDB.EXPECT().GetSeriesIDsForMetric("ns", "cpu").Return(roaring.BitmapOf(1, 2), nil).Times(4)
+	indexDB.EXPECT().GetGroupingContext([]uint32{1, 2}, gomock.Any()).Return(gCtx, nil).AnyTimes()
+	gCtx.EXPECT().ScanTagValueIDs(gomock.Any(), gomock.Any()).
+		Return([]*roaring.Bitmap{roaring.BitmapOf(1, 2), roaring.New()}).Times(4)
+	tagMeta.EXPECT().CollectTagValues(uint32(1), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	_, err = execute(nil)
+	assert.Error(t, err)
+
+	// case 6: exact tag values
+	tagMeta.EXPECT().CollectTagValues(uint32(1), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ uint32, _ *roaring.Bitmap, tagValues map[uint32]string) error {
+			tagValues[1] = "a"
+			tagValues[2] = "b"
+			return nil
+		})
+	cardinality, err := execute(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), cardinality.Series)
+	assert.Len(t, cardinality.TagKeys, 2)
+	assert.Equal(t, uint64(2), cardinality.TagKeys[0].Values)
+	assert.ElementsMatch(t, []string{"a", "b"}, cardinality.TagKeys[0].TagValues)
+	assert.Zero(t, cardinality.TagKeys[1].Values)
+
+	// case 7: sketch of tag values
+	tagValueIDs := roaring.New()
+	tagValueIDs.AddRange(0, exactTagValuesThreshold+1)
+	seriesSearch.EXPECT().Search().Return(roaring.BitmapOf(1), nil).Times(2)
+	tagSearch.EXPECT().Filter().Return(map[string]*tagFilterResult{"key": {}}, nil)
+	gCtx.EXPECT().ScanTagValueIDs(gomock.Any(), gomock.Any()).
+		Return([]*roaring.Bitmap{tagValueIDs, roaring.New()}).Times(2)
+	tagMeta.EXPECT().CollectTagValues(uint32(1), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ uint32, ids *roaring.Bitmap, tagValues map[uint32]string) error {
+			it := ids.Iterator()
+			for it.HasNext() {
+				id := it.Next()
+				tagValues[id] = strconv.Itoa(int(id))
+			}
+			return nil
+		})
+	cardinality, err = execute(&stmt.EqualsExpr{Key: "host", Value: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), cardinality.Series)
+	assert.Equal(t, uint64(exactTagValuesThreshold+1), cardinality.TagKeys[0].Values)
+	assert.Empty(t, cardinality.TagKeys[0].TagValues)
+	sketch, err := hll.FromBytes(cardinality.TagKeys[0].Sketch)
+	assert.NoError(t, err)
+	assert.InDelta(t, exactTagValuesThreshold+1, float64(sketch.Estimate()), 50)
+}
+
+func TestMetadataStorageQuery_TagValueCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := tsdb.NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	stats := metadb.NewMockTagValueStats(ctrl)
+	metadata.EXPECT().TagValueStats().Return(stats).AnyTimes()
+
+	execute := func(bySeries bool, window int64) *models.TagValueCardinality {
+		result, _, err := newStorageMetadataQuery(db, []int32{1}, &stmt.Metadata{
+			Namespace:  "ns",
+			MetricName: "cpu",
+			TagKey:     "host",
+			Type:       stmt.TagValueCardinality,
+			BySeries:   bySeries,
+			Window:     window,
+			Limit:      2,
+		}).Execute()
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		cardinality := &models.TagValueCardinality{}
+		assert.NoError(t, encoding.JSONUnmarshal([]byte(result[0]), cardinality))
+		return cardinality
+	}
+	// case 1: by series within window
+	stats.EXPECT().TopK("ns", "cpu", "host", true, 10*time.Minute, 2).
+		Return([]models.TagValueCount{{TagValue: "a", Count: 10}, {TagValue: "b", Count: 5}})
+	assert.Equal(t, &models.TagValueCardinality{
+		MetricName: "cpu",
+		TagKey:     "host",
+		By:         "series",
+		Window:     (10 * time.Minute).Milliseconds(),
+		Values:     []models.TagValueCount{{TagValue: "a", Count: 10}, {TagValue: "b", Count: 5}},
+	}, execute(true, (10*time.Minute).Milliseconds()))
+	// case 2: by points, window defaults to max window
+	stats.EXPECT().TopK("ns", "cpu", "host", false, metadb.MaxTagValueStatsWindow, 2).Return(nil).Times(2)
+	cardinality := execute(false, 0)
+	assert.Equal(t, "points", cardinality.By)
+	assert.Equal(t, metadb.MaxTagValueStatsWindow.Milliseconds(), cardinality.Window)
+	assert.Empty(t, cardinality.Values)
+	cardinality = execute(false, (48 * time.Hour).Milliseconds())
+	assert.Equal(t, metadb.MaxTagValueStatsWindow.Milliseconds(), cardinality.Window)
+}
+
+func TestMetadataStorageQuery_MetricDescriptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := tsdb.NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	metadataIndex := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataIndex).AnyTimes()
+	query := newStorageMetadataQuery(db, []int32{1}, &stmt.Metadata{
+		Namespace:  "ns",
+		MetricName: "cpu",
+		Type:       stmt.MetricDescriptor,
+	})
+
+	// case 1: get descriptor err
+	metadataIndex.EXPECT().GetMetricDescriptor("ns", "cpu").Return(nil, fmt.Errorf("err"))
+	_, _, err := query.Execute()
+	assert.Error(t, err)
+	// case 2: descriptor not found
+	metadataIndex.EXPECT().GetMetricDescriptor("ns", "cpu").Return(nil, constants.ErrMetricDescriptorNotFound)
+	result, _, err := query.Execute()
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	// case 3: descriptor found
+	metadataIndex.EXPECT().GetMetricDescriptor("ns", "cpu").
+		Return(&models.MetricDescriptor{Namespace: "ns", Name: "cpu", Unit: "percent"}, nil)
+	result, _, err = query.Execute()
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
+	assert.Len(t, result, 1)
+	descriptor := &models.MetricDescriptor{}
+	assert.NoError(t, encoding.JSONUnmarshal([]byte(result[0]), descriptor))
+	assert.Equal(t, &models.MetricDescriptor{Namespace: "ns", Name: "cpu", Unit: "percent"}, descriptor)
 }
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/lindb/roaring"
 	"go.uber.org/atomic"
@@ -30,6 +31,7 @@ import (
 	"github.com/lindb/lindb/flow"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/timeutil"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/series/tag"
@@ -77,6 +79,10 @@ type storageExecutor struct {
 	metricID           uint32
 	fields             field.Metas
 	storageExecutePlan *storageExecutePlan
+	// exemplars is true if query requires exemplars and histogram fields are queried
+	exemplars bool
+	// stringFields is true if string fields are queried, value ids need be resolved by string dictionary
+	stringFields bool
 
 	queryFlow flow.StorageQueryFlow
 
@@ -143,6 +149,10 @@ func (e *storageExecutor) Execute() {
 	}
 	condition := e.ctx.query.Condition
 	if condition != nil {
+		if err := checkRangeFilter(condition, e.database.GetOption()); err != nil {
+			e.queryFlow.Complete(err)
+			return
+		}
 		tagSearch := newTagSearchFunc(e.ctx.query.Namespace, e.ctx.query.MetricName,
 			e.ctx.query.Condition, e.database.Metadata())
 		t = newTagFilterTask(e.ctx, tagSearch)
@@ -155,6 +165,8 @@ func (e *storageExecutor) Execute() {
 	e.metricID = plan.metricID
 	e.fields = plan.getFields()
 	e.storageExecutePlan = plan
+	e.exemplars = e.ctx.query.Exemplars && hasHistogramField(e.fields)
+	e.stringFields = hasStringField(e.fields)
 	if e.ctx.query.HasGroupBy() {
 		e.groupByTagKeyIDs = e.storageExecutePlan.groupByKeyIDs()
 		e.tagValueIDs = make([]*roaring.Bitmap, len(e.groupByTagKeyIDs))
@@ -174,65 +186,93 @@ func (e *storageExecutor) Execute() {
 	e.executeQuery()
 }
 
-// executeQuery executes query flow for each shard
+// executeQuery executes query flow for all shards, the num. of shards searched concurrently is limited by
+// shard parallelism, each worker picks next pending shard after current shard completed(work-stealing),
+// so that slow shards don't block others and one query cannot occupy the whole filtering pool.
 func (e *storageExecutor) executeQuery() {
-	e.pendingForShard.Store(int32(len(e.shards)))
-	for idx := range e.shards {
-		shard := e.shards[idx]
+	numOfShards := len(e.shards)
+	e.pendingForShard.Store(int32(numOfShards))
+	parallelism := e.ctx.shardParallelism
+	if parallelism <= 0 || parallelism > numOfShards {
+		parallelism = numOfShards
+	}
+	startTime := time.Now()
+	var nextShard atomic.Int32
+	for i := 0; i < parallelism; i++ {
 		e.queryFlow.Filtering(func() {
-			defer func() {
-				// finish shard query
-				e.pendingForShard.Dec()
-				// try start collect tag values
-				e.collectGroupByTagValues()
-			}()
-			// 1. get series ids by query condition
-			seriesIDs := roaring.New()
-			t := newSeriesIDsSearchTask(e.ctx, shard, seriesIDs)
-			err := t.Run()
-			if err != nil && !errors.Is(err, constants.ErrNotFound) {
-				// maybe series ids not found in shard, so ignore not found err
-				e.queryFlow.Complete(err)
-			}
-			// if series ids not found
-			if seriesIDs.IsEmpty() {
-				return
+			for {
+				idx := int(nextShard.Inc()) - 1
+				if idx >= numOfShards {
+					return
+				}
+				e.executeShardQuery(e.shards[idx], startTime)
 			}
+		})
+	}
+}
 
-			rs := newTimeSpanResultSet()
-			// 2. filter data in memory database
-			t = newMemoryDataFilterTask(e.ctx, shard, e.metricID, e.fields, seriesIDs, rs)
-			err = t.Run()
-			if err != nil && !errors.Is(err, constants.ErrNotFound) {
-				// maybe data not exist in memory database, so ignore not found err
-				e.queryFlow.Complete(err)
-				return
-			}
-			// 3. filter data each data family in shard
-			t = newFileDataFilterTask(e.ctx, shard, e.metricID, e.fields, seriesIDs, rs)
-			err = t.Run()
-			if err != nil && !errors.Is(err, constants.ErrNotFound) {
-				// maybe data not exist in shard, so ignore not found err
-				e.queryFlow.Complete(err)
-				return
-			}
-			if rs.isEmpty() {
-				// data not found
-				return
-			}
+// executeShardQuery executes query flow for given shard, step as below:
+// 1. series ids search
+// 2. filtering data in memory/file storage
+// 3. grouping and loading
+func (e *storageExecutor) executeShardQuery(shard tsdb.Shard, startTime time.Time) {
+	pickTime := time.Now()
+	defer func() {
+		if e.ctx.stats != nil {
+			e.ctx.stats.SetShardExecuteCost(shard.ShardID(), pickTime.Sub(startTime), time.Since(pickTime))
+		}
+		// finish shard query
+		e.pendingForShard.Dec()
+		// try start collect tag values
+		e.collectGroupByTagValues()
+	}()
+	// 1. get series ids by query condition
+	seriesIDs := roaring.New()
+	t := newSeriesIDsSearchTask(e.ctx, shard, seriesIDs)
+	err := t.Run()
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		// maybe series ids not found in shard, so ignore not found err
+		e.queryFlow.Complete(err)
+	}
+	// if series ids not found
+	if seriesIDs.IsEmpty() {
+		return
+	}
 
-			// 5. execute group by
-			e.pendingForGrouping.Inc()
-			e.queryFlow.Grouping(func() {
-				defer func() {
-					e.pendingForGrouping.Dec()
-					// try start collect tag values
-					e.collectGroupByTagValues()
-				}()
-				e.executeGroupBy(shard, rs, rs.getSeriesIDs())
-			})
-		})
+	rs := newTimeSpanResultSet()
+	// release filter result after query completed
+	e.ctx.addResultSet(rs)
+	// 2. filter data in memory database
+	t = newMemoryDataFilterTask(e.ctx, shard, e.metricID, e.fields, seriesIDs, rs)
+	err = t.Run()
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		// maybe data not exist in memory database, so ignore not found err
+		e.queryFlow.Complete(err)
+		return
 	}
+	// 3. filter data each data family in shard
+	t = newFileDataFilterTask(e.ctx, shard, e.metricID, e.fields, seriesIDs, rs)
+	err = t.Run()
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		// maybe data not exist in shard, so ignore not found err
+		e.queryFlow.Complete(err)
+		return
+	}
+	if rs.isEmpty() {
+		// data not found
+		return
+	}
+
+	// 5. execute group by
+	e.pendingForGrouping.Inc()
+	e.queryFlow.Grouping(func() {
+		defer func() {
+			e.pendingForGrouping.Dec()
+			// try start collect tag values
+			e.collectGroupByTagValues()
+		}()
+		e.executeGroupBy(shard, rs, rs.getSeriesIDs())
+	})
 }
 
 // executeGroupBy executes the query flow, step as below:
@@ -291,6 +331,7 @@ func (e *storageExecutor) executeGroupBy(shard tsdb.Shard, rs *timeSpanResultSet
 			}
 
 			e.queryFlow.Load(func() {
+				loadStart := time.Now()
 				for _, span := range timeSpans {
 					// 3.load data by grouped seriesIDs
 					t := newDataLoadTaskFunc(e.ctx, shard, e.queryFlow, span,
@@ -319,9 +360,12 @@ func (e *storageExecutor) executeGroupBy(shard tsdb.Shard, rs *timeSpanResultSet
 						fmt.Println(r)
 					}
 				}()
+				// track scan stats for explain query
+				var seriesScanned, blocksDecoded, bytesRead uint64
 				for tags, seriesIDs := range grouped {
 					// scan metric data from storage(memory/file)
 					for _, seriesID := range seriesIDs {
+						seriesScanned++
 						for _, span := range timeSpans {
 							// loads the metric data by given series id from load result.
 							for resultSetIdx, loader := range span.loaders {
@@ -331,10 +375,16 @@ func (e *storageExecutor) executeGroupBy(shard tsdb.Shard, rs *timeSpanResultSet
 									fieldBytes := allFieldsBytes[fieldIndex]
 									fieldsTSDDecoders := fieldSeriesList[fieldIndex]
 									if fieldBytes != nil {
+										blocksDecoded++
+										bytesRead += uint64(len(fieldBytes))
 										if fieldsTSDDecoders[resultSetIdx] == nil {
 											fieldsTSDDecoders[resultSetIdx] = encoding.GetTSDDecoder()
 										}
-										fieldsTSDDecoders[resultSetIdx].ResetWithTimeRange(fieldBytes, slotRange2.Start, slotRange2.End)
+										err := fieldsTSDDecoders[resultSetIdx].ResetTSDBlock(fieldBytes, slotRange2.Start, slotRange2.End)
+										if err != nil {
+											e.queryFlow.Complete(err)
+											return
+										}
 									}
 								}
 							}
@@ -343,7 +393,7 @@ func (e *storageExecutor) executeGroupBy(shard tsdb.Shard, rs *timeSpanResultSet
 								merge := fieldMerge[idx]
 								var agg aggregation.FieldAggregator
 								var ok bool
-								agg, ok = fieldAggList[idx].GetAggregator(span.familyTime)
+								agg, ok = fieldAggList[idx].GetAggregator(span.slotBaseTime)
 								if !ok {
 									continue
 								}
@@ -364,15 +414,99 @@ func (e *storageExecutor) executeGroupBy(shard tsdb.Shard, rs *timeSpanResultSet
 							}
 						}
 					}
+					if e.exemplars {
+						e.queryFlow.ReduceExemplars(tags, e.getExemplars(shard, seriesIDHighKey, seriesIDs))
+					}
+					if e.stringFields {
+						stringValues, err := e.getStringValues(fieldAggList)
+						if err != nil {
+							e.queryFlow.Complete(err)
+							return
+						}
+						e.queryFlow.ReduceStringValues(stringValues)
+					}
 					e.queryFlow.Reduce(tags, fieldAggList.ResultSet(tags))
 					// reset aggregate context
 					fieldAggList.Reset()
 				}
+				if e.ctx.stats != nil {
+					e.ctx.stats.SetShardLoadStats(shard.ShardID(), seriesScanned, blocksDecoded, bytesRead, time.Since(loadStart))
+				}
 			})
 		})
 	}
 }
 
+// getExemplars returns the exemplars of histogram series in group within query time range.
+func (e *storageExecutor) getExemplars(shard tsdb.Shard, highKey uint16, lowSeriesIDs []uint16) []*protoCommonV1.Exemplar {
+	store := shard.ExemplarStore()
+	var exemplars []*protoCommonV1.Exemplar
+	for _, lowSeriesID := range lowSeriesIDs {
+		seriesID := uint32(highKey)<<16 | uint32(lowSeriesID)
+		for _, exemplar := range store.Get(e.metricID, seriesID, e.ctx.query.TimeRange) {
+			exemplars = append(exemplars, &protoCommonV1.Exemplar{
+				TraceID:   exemplar.TraceID,
+				SpanID:    exemplar.SpanID,
+				Value:     exemplar.Value,
+				Timestamp: exemplar.Timestamp,
+				Bucket:    exemplar.Bucket,
+			})
+		}
+	}
+	return exemplars
+}
+
+// hasHistogramField checks if histogram fields are queried, exemplars only link to histogram buckets.
+func hasHistogramField(fields field.Metas) bool {
+	for _, f := range fields {
+		if f.Type == field.HistogramField {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStringField checks if string fields are queried.
+func hasStringField(fields field.Metas) bool {
+	for _, f := range fields {
+		if f.Type == field.StringField {
+			return true
+		}
+	}
+	return false
+}
+
+// getStringValues returns the string values of value ids which aggregated by string fields in group.
+func (e *storageExecutor) getStringValues(fieldAggList aggregation.FieldAggregates) (map[uint64]string, error) {
+	idSet := make(map[uint64]struct{})
+	for idx, f := range e.fields {
+		if f.Type != field.StringField {
+			continue
+		}
+		for _, agg := range fieldAggList[idx].GetAggregates() {
+			if agg == nil {
+				continue
+			}
+			_, it := agg.ResultSet()
+			for it.HasNext() {
+				pIt := it.Next()
+				for pIt.HasNext() {
+					_, value := pIt.Next()
+					idSet[uint64(value)] = struct{}{}
+				}
+			}
+		}
+	}
+	if len(idSet) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint64, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	return e.database.Metadata().MetadataDatabase().GetStringValues(ids)
+}
+
 // mergeGroupByTagValueIDs merges group by tag value ids for each shard
 func (e *storageExecutor) mergeGroupByTagValueIDs(tagValueIDs []*roaring.Bitmap) {
 	if tagValueIDs == nil {
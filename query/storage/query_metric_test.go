@@ -18,25 +18,32 @@
 package storagequery
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/lindb/roaring"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/aggregation"
+	"github.com/lindb/lindb/aggregation/function"
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/flow"
 	"github.com/lindb/lindb/internal/concurrent"
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/pkg/timeutil"
+	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/series/tag"
 	"github.com/lindb/lindb/sql"
 	"github.com/lindb/lindb/sql/stmt"
 	"github.com/lindb/lindb/tsdb"
+	"github.com/lindb/lindb/tsdb/exemplar"
 	"github.com/lindb/lindb/tsdb/indexdb"
 	"github.com/lindb/lindb/tsdb/metadb"
 )
@@ -65,6 +72,12 @@ func (m *mockQueryFlow) Load(task concurrent.Task) {
 func (m *mockQueryFlow) Reduce(_ string, _ series.GroupedIterator) {
 }
 
+func (m *mockQueryFlow) ReduceExemplars(_ string, _ []*protoCommonV1.Exemplar) {
+}
+
+func (m *mockQueryFlow) ReduceStringValues(_ map[uint64]string) {
+}
+
 func (m *mockQueryFlow) Complete(_ error) {
 }
 
@@ -72,6 +85,16 @@ func newMockQueryFlow() flow.StorageQueryFlow {
 	return &mockQueryFlow{}
 }
 
+type countFilteringQueryFlow struct {
+	mockQueryFlow
+	filtering int
+}
+
+func (m *countFilteringQueryFlow) Filtering(task concurrent.Task) {
+	m.filtering++
+	task()
+}
+
 func TestStorageExecute_validation(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -159,6 +182,7 @@ func TestStorageExecutor_TagSearch(t *testing.T) {
 		return tagSearch
 	}
 	mockDatabase := newMockDatabase(ctrl)
+	mockDatabase.EXPECT().GetOption().Return(option.DatabaseOption{Interval: "10s"}).AnyTimes()
 	qFlow := flow.NewMockStorageQueryFlow(ctrl)
 	q, _ := sql.Parse("select f from cpu where ip='1.1.1.1'")
 	query := q.(*stmt.Query)
@@ -173,6 +197,13 @@ func TestStorageExecutor_TagSearch(t *testing.T) {
 	tagSearch.EXPECT().Filter().Return(nil, nil)
 	qFlow.EXPECT().Complete(constants.ErrNotFound)
 	exec.Execute()
+	// case 3: range filter on tag key not declared as numeric
+	q, _ = sql.Parse("select f from cpu where shard_id>=100")
+	exec = newStorageMetricQuery(qFlow, mockDatabase, newStorageExecuteContext([]int32{1, 2, 3}, q.(*stmt.Query)))
+	qFlow.EXPECT().Complete(gomock.Any()).Do(func(err error) {
+		assert.True(t, errors.Is(err, constants.ErrTagKeyNotNumeric))
+	})
+	exec.Execute()
 }
 
 func TestStorageExecute_Execute(t *testing.T) {
@@ -300,6 +331,68 @@ func TestStorageExecute_Execute(t *testing.T) {
 	exec.Execute()
 }
 
+func TestStorageExecute_ShardParallelism(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newSeriesSearchFunc = newSeriesSearch
+		newTagSearchFunc = newTagSearch
+		ctrl.Finish()
+	}()
+
+	tagSearch := NewMockTagSearch(ctrl)
+	newTagSearchFunc = func(namespace, metricName string, condition stmt.Expr, metadata metadb.Metadata) TagSearch {
+		return tagSearch
+	}
+	tagSearch.EXPECT().Filter().Return(map[string]*tagFilterResult{
+		"host": {tagValueIDs: roaring.BitmapOf(1, 2)},
+	}, nil).AnyTimes()
+	seriesSearch := NewMockSeriesSearch(ctrl)
+	newSeriesSearchFunc = func(filter series.Filter, filterResult map[string]*tagFilterResult, condition stmt.Expr) SeriesSearch {
+		return seriesSearch
+	}
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataIndex := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataIndex).AnyTimes()
+	metadataIndex.EXPECT().GetTagKeyID(gomock.Any(), gomock.Any(), "host").Return(uint32(10), nil).AnyTimes()
+	metadataIndex.EXPECT().GetMetricID(gomock.Any(), "cpu").Return(uint32(10), nil).AnyTimes()
+	metadataIndex.EXPECT().GetField(gomock.Any(), gomock.Any(), field.Name("f")).
+		Return(field.Meta{ID: 10, Type: field.SumField}, nil).AnyTimes()
+	mockDatabase := tsdb.NewMockDatabase(ctrl)
+	mockDatabase.EXPECT().GetOption().Return(option.DatabaseOption{Interval: "10s"}).AnyTimes()
+	mockDatabase.EXPECT().NumOfShards().Return(3).AnyTimes()
+	mockDatabase.EXPECT().Metadata().Return(metadata).AnyTimes()
+	for _, shardID := range []int32{1, 2, 3} {
+		shard := tsdb.NewMockShard(ctrl)
+		shard.EXPECT().ShardID().Return(shardID).AnyTimes()
+		shard.EXPECT().CurrentInterval().Return(timeutil.Interval(10000)).AnyTimes()
+		shard.EXPECT().IndexDatabase().Return(nil).AnyTimes()
+		mockDatabase.EXPECT().GetShard(shardID).Return(shard, true).AnyTimes()
+	}
+	q, _ := sql.Parse("explain select f from cpu where host='1.1.1.1' and time>'20190729 11:00:00' and time<'20190729 12:00:00'")
+	query := q.(*stmt.Query)
+	seriesSearch.EXPECT().Search().Return(nil, fmt.Errorf("err")).AnyTimes()
+
+	cases := []struct {
+		parallelism int
+		workers     int
+	}{
+		{parallelism: 0, workers: 3},
+		{parallelism: 2, workers: 2},
+		{parallelism: 10, workers: 3},
+	}
+	for _, c := range cases {
+		queryFlow := &countFilteringQueryFlow{}
+		ctx := newStorageExecuteContext([]int32{1, 2, 3}, query)
+		ctx.shardParallelism = c.parallelism
+		exec := newStorageMetricQuery(queryFlow, mockDatabase, ctx)
+		exec.Execute()
+		assert.Equal(t, c.workers, queryFlow.filtering)
+		// all shards searched
+		stats := ctx.QueryStats()
+		assert.Len(t, stats.Shards, 3)
+	}
+}
+
 func TestStorageExecutor_Execute_GroupBy(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -401,3 +494,66 @@ func TestStorageExecutor_merge_groupBy_tagValues(t *testing.T) {
 	// case 3: merge tag value
 	exec1.mergeGroupByTagValueIDs([]*roaring.Bitmap{roaring.BitmapOf(4, 5, 6), roaring.BitmapOf(1, 2, 3), nil})
 }
+
+func TestStorageExecutor_getExemplars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert.False(t, hasHistogramField(field.Metas{{Type: field.SumField}}))
+	assert.True(t, hasHistogramField(field.Metas{{Type: field.SumField}, {Type: field.HistogramField}}))
+
+	store := exemplar.NewStore(4, 10, linmetric.NewScope("test_query_exemplars"))
+	store.Add(10, 1<<16|2, 100, &protoMetricsV1.CompoundField{
+		ExplicitBounds: []float64{1, 2},
+		Exemplars:      []*protoMetricsV1.Exemplar{{TraceId: []byte{0x1a}, SpanId: []byte{0x2b}, Value: 1.5}},
+	})
+	shard := tsdb.NewMockShard(ctrl)
+	shard.EXPECT().ExemplarStore().Return(store).AnyTimes()
+	q, _ := sql.Parse("select f from cpu where time>'20190729 11:00:00' and time<'20190729 12:00:00'")
+	query := q.(*stmt.Query)
+	query.TimeRange = timeutil.TimeRange{Start: 0, End: 200}
+	exec := newStorageMetricQuery(newMockQueryFlow(), nil, newStorageExecuteContext([]int32{1}, query))
+	e := exec.(*storageExecutor)
+	e.metricID = 10
+	exemplars := e.getExemplars(shard, 1, []uint16{1, 2})
+	assert.Equal(t, []*protoCommonV1.Exemplar{{TraceID: "1a", SpanID: "2b", Value: 1.5, Timestamp: 100, Bucket: 2}}, exemplars)
+	assert.Empty(t, e.getExemplars(shard, 2, []uint16{2}))
+}
+
+func TestStorageExecutor_getStringValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert.False(t, hasStringField(field.Metas{{Type: field.SumField}}))
+	assert.True(t, hasStringField(field.Metas{{Type: field.SumField}, {Type: field.StringField}}))
+
+	db := tsdb.NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	q, _ := sql.Parse("select version from cpu where time>'20190729 11:00:00' and time<'20190729 12:00:00'")
+	exec := newStorageMetricQuery(newMockQueryFlow(), db, newStorageExecuteContext([]int32{1}, q.(*stmt.Query)))
+	e := exec.(*storageExecutor)
+	e.fields = field.Metas{{Name: "f", Type: field.SumField}, {Name: "version", Type: field.StringField}}
+
+	spec := aggregation.NewAggregatorSpec("version", field.StringField)
+	spec.AddFunctionType(function.LastValue)
+	agg := aggregation.NewFieldAggregator(spec, 0, 0, 9)
+	sumAgg := aggregation.NewMockSeriesAggregator(ctrl)
+	stringAgg := aggregation.NewMockSeriesAggregator(ctrl)
+	fieldAggList := aggregation.FieldAggregates{sumAgg, stringAgg}
+	// case 1: no string value
+	stringAgg.EXPECT().GetAggregates().Return([]aggregation.FieldAggregator{nil, agg})
+	values, err := e.getStringValues(fieldAggList)
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+	// case 2: resolve string values
+	agg.AggregateBySlot(1, 100)
+	agg.AggregateBySlot(2, 100)
+	stringAgg.EXPECT().GetAggregates().Return([]aggregation.FieldAggregator{nil, agg})
+	metadataDB.EXPECT().GetStringValues([]uint64{100}).Return(map[uint64]string{100: "v1.0.0"}, nil)
+	values, err = e.getStringValues(fieldAggList)
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint64]string{100: "v1.0.0"}, values)
+}
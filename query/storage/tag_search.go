@@ -22,6 +22,8 @@ import (
 
 	"github.com/lindb/roaring"
 
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/sql/stmt"
 	"github.com/lindb/lindb/tsdb/metadb"
 )
@@ -115,6 +117,26 @@ func (s *tagSearch) findTagValueIDsByExpr(expr stmt.Expr) {
 	}
 }
 
+// checkRangeFilter checks if the tag keys of range filters in condition are declared as numeric in database option
+func checkRangeFilter(expr stmt.Expr, opt option.DatabaseOption) error {
+	switch expr := expr.(type) {
+	case *stmt.RangeExpr:
+		if !opt.IsNumericTagKey(expr.Key) {
+			return fmt.Errorf("%w, tag key: %s", constants.ErrTagKeyNotNumeric, expr.Key)
+		}
+	case *stmt.ParenExpr:
+		return checkRangeFilter(expr.Expr, opt)
+	case *stmt.NotExpr:
+		return checkRangeFilter(expr.Expr, opt)
+	case *stmt.BinaryExpr:
+		if err := checkRangeFilter(expr.Left, opt); err != nil {
+			return err
+		}
+		return checkRangeFilter(expr.Right, opt)
+	}
+	return nil
+}
+
 // getTagKeyID returns the tag key id by tag key
 func (s *tagSearch) getTagKeyID(tagKey string) (uint32, error) {
 	tagKeyID, ok := s.tags[tagKey]
@@ -25,11 +25,23 @@ import (
 	"github.com/lindb/roaring"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/sql"
 	"github.com/lindb/lindb/sql/stmt"
 	"github.com/lindb/lindb/tsdb/metadb"
 )
 
+func TestCheckRangeFilter(t *testing.T) {
+	opt := option.DatabaseOption{NumericTagKeys: []string{"shard_id"}}
+	q, _ := sql.Parse("select f from cpu where ip='1.1.1.1' and (shard_id>=100 or shard_id<10)")
+	assert.NoError(t, checkRangeFilter(q.(*stmt.Query).Condition, opt))
+	q, _ = sql.Parse("select f from cpu where (ip='1.1.1.1' and host>10)")
+	assert.Error(t, checkRangeFilter(q.(*stmt.Query).Condition, opt))
+	q, _ = sql.Parse("select f from cpu where host>10 and ip='1.1.1.1'")
+	assert.Error(t, checkRangeFilter(q.(*stmt.Query).Condition, opt))
+	assert.Error(t, checkRangeFilter(&stmt.NotExpr{Expr: &stmt.RangeExpr{Key: "host"}}, opt))
+}
+
 func TestTagSearch_Filter(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
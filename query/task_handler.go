@@ -23,7 +23,9 @@ import (
 
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/internal/concurrent"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
 	"github.com/lindb/lindb/rpc"
 )
@@ -36,6 +38,8 @@ type TaskHandler struct {
 	timeout   time.Duration
 
 	taskPool concurrent.Pool
+	// rejects new task requests if server is draining, nil means never draining
+	draining func() bool
 
 	logger *logger.Logger
 }
@@ -57,6 +61,13 @@ func NewTaskHandler(
 	}
 }
 
+// WithDraining sets the function which checks if server is draining,
+// new task requests are rejected when draining, so that leaf task is retried in other replica.
+func (q *TaskHandler) WithDraining(draining func() bool) *TaskHandler {
+	q.draining = draining
+	return q
+}
+
 // Handle handles the task request based on grpc stream
 func (q *TaskHandler) Handle(stream protoCommonV1.TaskService_HandleServer) (err error) {
 	clientLogicNode, err := rpc.GetLogicNodeFromContext(stream.Context())
@@ -91,14 +102,53 @@ func (q *TaskHandler) Handle(stream protoCommonV1.TaskService_HandleServer) (err
 
 // dispatch dispatches request with timeout
 func (q *TaskHandler) process(stream protoCommonV1.TaskService_HandleServer, req *protoCommonV1.TaskRequest) {
+	if q.draining != nil && q.draining() {
+		q.sendError(stream, req, ErrServerDraining)
+		return
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
-	q.taskPool.Submit(func() {
+	// continue the trace of query from parent node
+	ctx = tracing.Extract(ctx, req.GetMetadata())
+	// query task is interactive, dispatched before background tasks,
+	// and dropped if the query is timed out before being executed.
+	err := q.taskPool.SubmitWithContext(concurrent.WithPriority(ctx, concurrent.PriorityHigh), func() {
 		defer func() {
 			if err := recover(); err != nil {
-				q.logger.Error("dispatch task request", logger.Any("err", err), logger.Stack())
+				q.logger.Error("dispatch task request",
+					logger.String("queryID", req.QueryID), logger.String("requestID", RequestID(req)),
+					logger.Any("err", err), logger.Stack())
 			}
 			cancel()
 		}()
 		q.processor.Process(ctx, stream, req)
 	})
+	if err == nil {
+		return
+	}
+	cancel()
+	q.logger.Error("submit task request",
+		logger.String("queryID", req.QueryID), logger.String("requestID", RequestID(req)),
+		logger.String("taskID", req.ParentTaskID), logger.Error(err))
+	q.sendError(stream, req, err)
+}
+
+// sendError sends the error message of task request to target stream.
+func (q *TaskHandler) sendError(stream protoCommonV1.TaskService_HandleServer, req *protoCommonV1.TaskRequest, err error) {
+	if sendErr := stream.Send(&protoCommonV1.TaskResponse{
+		TaskID:    req.ParentTaskID,
+		Completed: true,
+		ErrMsg:    err.Error(),
+		SendTime:  timeutil.NowNano(),
+	}); sendErr != nil {
+		q.logger.Error("failed to send error message to target stream",
+			logger.String("queryID", req.QueryID), logger.String("requestID", RequestID(req)),
+			logger.String("taskID", req.ParentTaskID),
+			logger.Error(sendErr),
+		)
+	}
+}
+
+// RequestID returns the request id of http request forwarded with task request, returns empty if not given.
+func RequestID(req *protoCommonV1.TaskRequest) string {
+	return req.GetMetadata()[tracing.RequestIDKey]
 }
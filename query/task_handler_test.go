@@ -30,6 +30,7 @@ import (
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/internal/concurrent"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/internal/tracing"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/ltoml"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
@@ -80,3 +81,42 @@ func TestTaskHandler_dispatch(t *testing.T) {
 	// test process panic
 	handler.process(nil, nil)
 }
+
+func TestTaskHandler_submit_rejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := concurrent.NewPool("", 10, time.Second, linmetric.NewScope("22"))
+	pool.Stop()
+	handler := NewTaskHandler(cfg, nil, &mockTaskProcessor{}, pool)
+	server := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
+	server.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoCommonV1.TaskResponse) error {
+		assert.Equal(t, "1", resp.TaskID)
+		assert.Equal(t, concurrent.ErrPoolStopped.Error(), resp.ErrMsg)
+		return fmt.Errorf("err")
+	})
+	handler.process(server, &protoCommonV1.TaskRequest{ParentTaskID: "1"})
+}
+
+func TestTaskHandler_draining(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := NewTaskHandler(cfg, nil, &mockTaskProcessor{},
+		concurrent.NewPool("", 10, time.Second, linmetric.NewScope("22"))).
+		WithDraining(func() bool { return true })
+	server := protoCommonV1.NewMockTaskService_HandleServer(ctrl)
+	server.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *protoCommonV1.TaskResponse) error {
+		assert.Equal(t, "1", resp.TaskID)
+		assert.True(t, IsRetryableError(resp.ErrMsg))
+		return nil
+	})
+	handler.process(server, &protoCommonV1.TaskRequest{ParentTaskID: "1"})
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(&protoCommonV1.TaskRequest{}))
+	assert.Equal(t, "r1", RequestID(&protoCommonV1.TaskRequest{
+		Metadata: map[string]string{tracing.RequestIDKey: "r1"},
+	}))
+}
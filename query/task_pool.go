@@ -0,0 +1,38 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/concurrent"
+	"github.com/lindb/lindb/internal/linmetric"
+)
+
+// NewTaskPool creates the worker pool for executing query tasks,
+// the pending queue is bounded, task is rejected if waiting timeout when queue is full.
+func NewTaskPool(name string, cfg config.Query, scope linmetric.Scope) concurrent.Pool {
+	opts := concurrent.PoolOptions{
+		QueueCapacity: cfg.MaxPendingTasks,
+		RejectPolicy:  concurrent.RejectBlock,
+	}
+	if cfg.PendingTimeout > 0 {
+		opts.RejectPolicy = concurrent.RejectTimeout
+		opts.RejectTimeout = cfg.PendingTimeout.Duration()
+	}
+	return concurrent.NewPoolWithOptions(name, cfg.QueryConcurrency, cfg.IdleTimeout.Duration(), opts, scope)
+}
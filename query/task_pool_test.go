@@ -0,0 +1,42 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+func TestNewTaskPool(t *testing.T) {
+	cfg := config.NewDefaultQuery()
+	p := NewTaskPool("test", *cfg, linmetric.NewScope("task-pool-test", "case", "timeout"))
+	assert.NotNil(t, p)
+	p.Stop()
+
+	cfg.PendingTimeout = 0
+	cfg.IdleTimeout = ltoml.Duration(time.Second)
+	p = NewTaskPool("test", *cfg, linmetric.NewScope("task-pool-test", "case", "block"))
+	assert.NotNil(t, p)
+	p.Stop()
+}
@@ -18,6 +18,7 @@
 package replica
 
 import (
+	"context"
 	"errors"
 
 	"github.com/lindb/lindb/constants"
@@ -29,14 +30,18 @@ import (
 type localReplicator struct {
 	replicator
 
-	shard  tsdb.Shard
-	logger *logger.Logger
+	shard        tsdb.Shard
+	writeShaper  tsdb.WriteShaper
+	deduplicator *writeDeduplicator
+	logger       *logger.Logger
 }
 
-func NewLocalReplicator(shard tsdb.Shard) Replicator {
+func NewLocalReplicator(shard tsdb.Shard, writeShaper tsdb.WriteShaper, deduplicator *writeDeduplicator) Replicator {
 	return &localReplicator{
-		shard:  shard,
-		logger: logger.GetLogger("replica", "localReplicator"),
+		shard:        shard,
+		writeShaper:  writeShaper,
+		deduplicator: deduplicator,
+		logger:       logger.GetLogger("replica", "localReplicator"),
 	}
 }
 
@@ -47,14 +52,52 @@ func (r *localReplicator) Replica(_ int64, msg []byte) {
 		r.logger.Error("unmarshal metricList", logger.Error(err))
 		return
 	}
+	// drops the batch retried by producer, which is applying or has been applied
+	var applied map[int]struct{}
+	if r.deduplicator != nil {
+		applied, err = r.deduplicator.acquire(metricList.ProducerID, metricList.ProducerEpoch, metricList.Sequence)
+		if err != nil {
+			if errors.Is(err, errStaleSequence) {
+				r.logger.Error("reject write batch",
+					logger.String("producer", metricList.ProducerID),
+					logger.Int64("epoch", metricList.ProducerEpoch),
+					logger.Int64("sequence", metricList.Sequence), logger.Error(err))
+			}
+			return
+		}
+	}
+	if applied == nil {
+		applied = make(map[int]struct{})
+	}
+	done := false
+	defer func() {
+		if r.deduplicator != nil {
+			// marks the batch done only if all metrics written, otherwise retry of producer
+			// only applies the metrics which failed, avoids double counting.
+			r.deduplicator.release(metricList.ProducerID, metricList.ProducerEpoch, metricList.Sequence, applied, done)
+		}
+	}()
 
+	// shapes write rate of database, blocks until metrics can be applied
+	if err := r.writeShaper.Wait(context.TODO(), r.shard.DatabaseName(), len(metricList.Metrics)); err != nil {
+		r.logger.Error("wait write shaper", logger.Error(err))
+	}
 	//TODO write metric, need handle panic
-	for _, metric := range metricList.Metrics {
+	failed := false
+	for idx, metric := range metricList.Metrics {
+		if _, ok := applied[idx]; ok {
+			// applied by previous attempt
+			continue
+		}
 		if err := r.shard.Write(metric); err != nil {
 			if errors.Is(err, constants.ErrMetricOutOfTimeRange) {
 				continue
 			}
+			failed = true
 			r.logger.Error("write metric", logger.Error(err))
+			continue
 		}
+		applied[idx] = struct{}{}
 	}
+	done = !failed
 }
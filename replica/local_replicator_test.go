@@ -34,7 +34,8 @@ func TestLocalReplicator_Replica(t *testing.T) {
 		ctrl.Finish()
 	}()
 	shard := tsdb.NewMockShard(ctrl)
-	replicator := NewLocalReplicator(shard)
+	writeShaper := tsdb.NewMockWriteShaper(ctrl)
+	replicator := NewLocalReplicator(shard, writeShaper, newWriteDeduplicator())
 	assert.True(t, replicator.IsReady())
 	replicator.Replica(1, []byte{1, 2, 3})
 
@@ -42,6 +43,69 @@ func TestLocalReplicator_Replica(t *testing.T) {
 		Metrics: []*protoMetricsV1.Metric{{Name: "test"}},
 	}
 	data, _ := metricList.Marshal()
+	shard.EXPECT().DatabaseName().Return("db").AnyTimes()
+	writeShaper.EXPECT().Wait(gomock.Any(), "db", 1).Return(nil)
 	shard.EXPECT().Write(gomock.Any()).Return(fmt.Errorf("errj"))
 	replicator.Replica(1, data)
+	// wait write shaper err
+	writeShaper.EXPECT().Wait(gomock.Any(), "db", 1).Return(fmt.Errorf("err"))
+	shard.EXPECT().Write(gomock.Any()).Return(nil)
+	replicator.Replica(1, data)
+
+	// duplicate batch of producer
+	metricList.ProducerID = "producer"
+	metricList.Sequence = 1
+	data, _ = metricList.Marshal()
+	writeShaper.EXPECT().Wait(gomock.Any(), "db", 1).Return(nil)
+	shard.EXPECT().Write(gomock.Any()).Return(nil)
+	replicator.Replica(1, data)
+	replicator.Replica(2, data)
+
+	// failed batch can be retried
+	metricList.Sequence = 2
+	data, _ = metricList.Marshal()
+	writeShaper.EXPECT().Wait(gomock.Any(), "db", 1).Return(nil).Times(2)
+	shard.EXPECT().Write(gomock.Any()).Return(fmt.Errorf("err"))
+	replicator.Replica(3, data)
+	shard.EXPECT().Write(gomock.Any()).Return(nil)
+	replicator.Replica(4, data)
+	replicator.Replica(5, data)
+
+	// retry of partial failed batch skips the applied metrics
+	metricList.Sequence = 3
+	metricList.Metrics = []*protoMetricsV1.Metric{{Name: "m1"}, {Name: "m2"}}
+	data, _ = metricList.Marshal()
+	writeShaper.EXPECT().Wait(gomock.Any(), "db", 2).Return(nil).Times(2)
+	gomock.InOrder(
+		shard.EXPECT().Write(gomock.Any()).Return(nil),
+		shard.EXPECT().Write(gomock.Any()).Return(fmt.Errorf("err")),
+		shard.EXPECT().Write(&metricMatcher{name: "m2"}).Return(nil),
+	)
+	replicator.Replica(6, data)
+	replicator.Replica(7, data)
+
+	// stale sequence is rejected
+	for seq := int64(4); seq <= dedupWindowSize+4; seq++ {
+		metricList.Sequence = seq
+		metricList.Metrics = nil
+		data, _ = metricList.Marshal()
+		writeShaper.EXPECT().Wait(gomock.Any(), "db", 0).Return(nil)
+		replicator.Replica(seq, data)
+	}
+	metricList.Sequence = 0
+	data, _ = metricList.Marshal()
+	replicator.Replica(8, data)
+}
+
+type metricMatcher struct {
+	name string
+}
+
+func (m *metricMatcher) Matches(x interface{}) bool {
+	metric, ok := x.(*protoMetricsV1.Metric)
+	return ok && metric.Name == m.name
+}
+
+func (m *metricMatcher) String() string {
+	return "metric name is " + m.name
 }
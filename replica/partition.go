@@ -57,14 +57,16 @@ type partition struct {
 	log           queue.FanOutQueue
 	shardID       models.ShardID
 	shard         tsdb.Shard
+	writeShaper   tsdb.WriteShaper
 	peers         map[string]ReplicatorPeer
 	cliFct        rpc.ClientStreamFactory
+	deduplicator  *writeDeduplicator // shared by local replicators of partition
 
 	mutex sync.Mutex
 }
 
 // NewPartition creates a write ahead log partition.
-func NewPartition(shardID models.ShardID, shard tsdb.Shard,
+func NewPartition(shardID models.ShardID, shard tsdb.Shard, writeShaper tsdb.WriteShaper,
 	currentNodeID models.NodeID,
 	log queue.FanOutQueue,
 	cliFct rpc.ClientStreamFactory,
@@ -73,9 +75,11 @@ func NewPartition(shardID models.ShardID, shard tsdb.Shard,
 		log:           log,
 		shardID:       shardID,
 		shard:         shard,
+		writeShaper:   writeShaper,
 		currentNodeID: currentNodeID,
 		cliFct:        cliFct,
 		peers:         make(map[string]ReplicatorPeer),
+		deduplicator:  newWriteDeduplicator(),
 	}
 }
 
@@ -156,7 +160,7 @@ func (p *partition) buildReplica(leader models.NodeID, replica models.NodeID) {
 	var replicator Replicator
 	if replica == p.currentNodeID {
 		// local replicator
-		replicator = newLocalReplicatorFn(p.shard)
+		replicator = newLocalReplicatorFn(p.shard, p.writeShaper, p.deduplicator)
 	} else {
 		// build remote replicator
 		replicator = newRemoteReplicatorFn(&ReplicatorChannel{
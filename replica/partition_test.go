@@ -39,7 +39,7 @@ func TestPartition_BuildReplicaRelation(t *testing.T) {
 	}()
 	r := NewMockReplicator(ctrl)
 	r.EXPECT().String().Return("test").AnyTimes()
-	newLocalReplicatorFn = func(_ tsdb.Shard) Replicator {
+	newLocalReplicatorFn = func(_ tsdb.Shard, _ tsdb.WriteShaper, _ *writeDeduplicator) Replicator {
 		return r
 	}
 	newRemoteReplicatorFn = func(_ *ReplicatorChannel,
@@ -47,7 +47,7 @@ func TestPartition_BuildReplicaRelation(t *testing.T) {
 		return r
 	}
 
-	p := NewPartition(1, nil, 1, nil, nil)
+	p := NewPartition(1, nil, nil, 1, nil, nil)
 	err := p.BuildReplicaForLeader(2, []models.NodeID{1, 2, 3})
 	assert.Error(t, err)
 
@@ -71,7 +71,7 @@ func TestPartition_BuildReplicaForFollower(t *testing.T) {
 	}()
 	r := NewMockReplicator(ctrl)
 	r.EXPECT().String().Return("test").AnyTimes()
-	newLocalReplicatorFn = func(_ tsdb.Shard) Replicator {
+	newLocalReplicatorFn = func(_ tsdb.Shard, _ tsdb.WriteShaper, _ *writeDeduplicator) Replicator {
 		return r
 	}
 	newRemoteReplicatorFn = func(_ *ReplicatorChannel,
@@ -79,7 +79,7 @@ func TestPartition_BuildReplicaForFollower(t *testing.T) {
 		return r
 	}
 
-	p := NewPartition(1, nil, 1, nil, nil)
+	p := NewPartition(1, nil, nil, 1, nil, nil)
 	err := p.BuildReplicaForFollower(2, 2)
 	assert.Error(t, err)
 
@@ -98,7 +98,7 @@ func TestPartition_Close(t *testing.T) {
 	r := NewMockReplicator(ctrl)
 	l := queue.NewMockFanOutQueue(ctrl)
 	r.EXPECT().String().Return("test").AnyTimes()
-	newLocalReplicatorFn = func(_ tsdb.Shard) Replicator {
+	newLocalReplicatorFn = func(_ tsdb.Shard, _ tsdb.WriteShaper, _ *writeDeduplicator) Replicator {
 		return r
 	}
 	newRemoteReplicatorFn = func(_ *ReplicatorChannel,
@@ -107,7 +107,7 @@ func TestPartition_Close(t *testing.T) {
 	}
 
 	l.EXPECT().Close().MaxTimes(2)
-	p := NewPartition(1, nil, 1, l, nil)
+	p := NewPartition(1, nil, nil, 1, l, nil)
 	err := p.Close()
 	assert.NoError(t, err)
 	r.EXPECT().IsReady().Return(false).AnyTimes()
@@ -123,7 +123,7 @@ func TestPartition_WriteLog(t *testing.T) {
 		ctrl.Finish()
 	}()
 	l := queue.NewMockFanOutQueue(ctrl)
-	p := NewPartition(1, nil, 1, l, nil)
+	p := NewPartition(1, nil, nil, 1, l, nil)
 	l.EXPECT().Put(gomock.Any()).Return(fmt.Errorf("err"))
 	err := p.WriteLog([]byte{1})
 	assert.Error(t, err)
@@ -138,7 +138,7 @@ func TestPartition_ReplicaLog(t *testing.T) {
 		ctrl.Finish()
 	}()
 	l := queue.NewMockFanOutQueue(ctrl)
-	p := NewPartition(1, nil, 1, l, nil)
+	p := NewPartition(1, nil, nil, 1, l, nil)
 	// case 1: replica idx err
 	l.EXPECT().HeadSeq().Return(int64(8))
 	idx, err := p.ReplicaLog(10, []byte{1})
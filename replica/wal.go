@@ -146,7 +146,7 @@ func (w *writeAheadLog) GetOrCreatePartition(shardID models.ShardID) (Partition,
 	if err != nil {
 		return nil, err
 	}
-	p = NewPartition(shardID, shard, w.currentNodeID, q, w.cliFct)
+	p = NewPartition(shardID, shard, w.engine.WriteShaper(), w.currentNodeID, q, w.cliFct)
 	w.shardLogs[shardID] = p
 	return p, nil
 }
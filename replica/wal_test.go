@@ -71,7 +71,7 @@ func TestWriteAheadLog_GetOrCreatePartition(t *testing.T) {
 	assert.Nil(t, p)
 	// case 2: new log err
 	newFanOutQueue = func(dirPath string, dataSizeLimit int64,
-		removeTaskInterval time.Duration) (queue.FanOutQueue, error) {
+		removeTaskInterval time.Duration, _ ...queue.Option) (queue.FanOutQueue, error) {
 		return nil, fmt.Errorf("err")
 	}
 	engine.EXPECT().GetShard(gomock.Any(), gomock.Any()).Return(nil, true)
@@ -80,10 +80,11 @@ func TestWriteAheadLog_GetOrCreatePartition(t *testing.T) {
 	assert.Nil(t, p)
 	// case 3: create log ok
 	newFanOutQueue = func(dirPath string, dataSizeLimit int64,
-		removeTaskInterval time.Duration) (queue.FanOutQueue, error) {
+		removeTaskInterval time.Duration, _ ...queue.Option) (queue.FanOutQueue, error) {
 		return nil, nil
 	}
 	engine.EXPECT().GetShard(gomock.Any(), gomock.Any()).Return(nil, true)
+	engine.EXPECT().WriteShaper().Return(nil)
 	p, err = l.GetOrCreatePartition(1)
 	assert.NoError(t, err)
 	assert.NotNil(t, p)
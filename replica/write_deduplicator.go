@@ -0,0 +1,186 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replica
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/timeutil"
+)
+
+const (
+	// dedupWindowSize is the num. of recent sequences tracked for each producer,
+	// sequence older than the window is rejected as stale.
+	dedupWindowSize = 1024
+	// producerIdleTTL is the idle time after which the dedup state of producer session is dropped.
+	producerIdleTTL = time.Hour
+	// dedupCleanupInterval is the min interval of cleaning up idle producers.
+	dedupCleanupInterval = time.Minute
+)
+
+var (
+	// errDuplicateBatch represents the batch is applying or has been applied.
+	errDuplicateBatch = errors.New("duplicate batch")
+	// errStaleSequence represents the sequence of batch is older than the dedup window,
+	// cannot know if it has been applied.
+	errStaleSequence = errors.New("sequence of batch is older than dedup window")
+)
+
+var (
+	dedupScope              = linmetric.NewScope("lindb.storage.replica")
+	duplicateBatchesCounter = dedupScope.NewDeltaCounter("duplicate_batches")
+	staleBatchesCounter     = dedupScope.NewDeltaCounter("stale_batches")
+)
+
+// producerKey identifies the session of producer, sequence restarts from 0 after producer restarted with new epoch.
+type producerKey struct {
+	producerID string
+	epoch      int64
+}
+
+// sequenceState represents the apply state of batch.
+type sequenceState struct {
+	applying bool             // batch is applying
+	done     bool             // all metrics of batch have been applied
+	applied  map[int]struct{} // indexes of metrics applied by previous attempts which failed partially
+}
+
+// producerWindow tracks the recent sequences of producer session which are applying or have been applied.
+type producerWindow struct {
+	maxSeq   int64
+	seen     map[int64]*sequenceState
+	lastSeen int64
+}
+
+// writeDeduplicator drops the metric batches which are applying or have been applied,
+// based on (producer id, producer epoch, sequence) attached by writer,
+// so that writer can safely retry against any broker.
+// The sequence is reserved before applying, marked done after all metrics applied,
+// if the batch failed partially, the indexes of applied metrics are kept,
+// so that retry of the batch only applies the metrics which failed.
+// NOTE: there are two limits of deduplication:
+// 1. dedup state is kept in memory, the batches applied before restart are not tracked after restart,
+// retry of them across storage restart is applied again;
+// 2. only the latest dedupWindowSize sequences of each producer session are tracked,
+// the batch with older sequence is rejected with errStaleSequence instead of being applied.
+type writeDeduplicator struct {
+	producers   map[producerKey]*producerWindow
+	lastCleanup int64
+	nowFunc     func() int64
+
+	mutex sync.Mutex
+}
+
+// newWriteDeduplicator creates a write deduplicator.
+func newWriteDeduplicator() *writeDeduplicator {
+	return &writeDeduplicator{
+		producers: make(map[producerKey]*producerWindow),
+		nowFunc:   timeutil.Now,
+	}
+}
+
+// acquire reserves the sequence of producer session for applying, returns the indexes of metrics
+// which have been applied by previous attempts, the caller must skip them.
+// Returns errDuplicateBatch if the batch is applying or has been applied,
+// errStaleSequence if the sequence is older than the dedup window.
+// Batch without producer id is never treated as duplicate.
+func (d *writeDeduplicator) acquire(producerID string, epoch, seq int64) (map[int]struct{}, error) {
+	if producerID == "" {
+		return nil, nil
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := d.nowFunc()
+	d.cleanup(now)
+
+	key := producerKey{producerID: producerID, epoch: epoch}
+	window, ok := d.producers[key]
+	if !ok {
+		window = &producerWindow{maxSeq: seq, seen: make(map[int64]*sequenceState)}
+		d.producers[key] = window
+	}
+	window.lastSeen = now
+	state, ok := window.seen[seq]
+	switch {
+	case ok && (state.applying || state.done):
+		duplicateBatchesCounter.Incr()
+		return nil, errDuplicateBatch
+	case !ok && seq <= window.maxSeq-dedupWindowSize:
+		staleBatchesCounter.Incr()
+		return nil, errStaleSequence
+	case !ok:
+		state = &sequenceState{applied: make(map[int]struct{})}
+		window.seen[seq] = state
+	}
+	state.applying = true
+	return state.applied, nil
+}
+
+// release releases the reserved sequence of producer session, marks it done if all metrics applied,
+// otherwise keeps the indexes of applied metrics, so that the batch retried by producer only applies the rest.
+func (d *writeDeduplicator) release(producerID string, epoch, seq int64, applied map[int]struct{}, done bool) {
+	if producerID == "" {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	window, ok := d.producers[producerKey{producerID: producerID, epoch: epoch}]
+	if !ok {
+		return
+	}
+	state, ok := window.seen[seq]
+	if !ok {
+		return
+	}
+	state.applying = false
+	if !done {
+		state.applied = applied
+		return
+	}
+	state.done = true
+	state.applied = nil
+	if seq > window.maxSeq {
+		window.maxSeq = seq
+	}
+	if len(window.seen) > 2*dedupWindowSize {
+		// drop the applied sequences out of window
+		for s, st := range window.seen {
+			if st.done && s <= window.maxSeq-dedupWindowSize {
+				delete(window.seen, s)
+			}
+		}
+	}
+}
+
+// cleanup drops the dedup state of idle producer sessions.
+func (d *writeDeduplicator) cleanup(now int64) {
+	if now-d.lastCleanup < dedupCleanupInterval.Milliseconds() {
+		return
+	}
+	d.lastCleanup = now
+	for key, window := range d.producers {
+		if now-window.lastSeen > producerIdleTTL.Milliseconds() {
+			delete(d.producers, key)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replica
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDeduplicator_acquire(t *testing.T) {
+	now := int64(0)
+	d := newWriteDeduplicator()
+	d.nowFunc = func() int64 { return now }
+	apply := func(producerID string, epoch, seq int64) bool {
+		if _, err := d.acquire(producerID, epoch, seq); err != nil {
+			return false
+		}
+		d.release(producerID, epoch, seq, nil, true)
+		return true
+	}
+
+	// without producer
+	assert.True(t, apply("", 0, 1))
+	assert.True(t, apply("", 0, 1))
+
+	assert.True(t, apply("p1", 0, 10))
+	assert.False(t, apply("p1", 0, 10))
+	// out of order
+	assert.True(t, apply("p1", 0, 8))
+	assert.False(t, apply("p1", 0, 8))
+	// other producer
+	assert.True(t, apply("p2", 0, 10))
+	// producer restarted with new epoch, sequence restarts from 0
+	assert.True(t, apply("p1", 1, 0))
+	assert.True(t, apply("p1", 1, 10))
+	assert.False(t, apply("p1", 1, 10))
+
+	// applying batch is duplicate
+	_, err := d.acquire("p1", 0, 11)
+	assert.NoError(t, err)
+	_, err = d.acquire("p1", 0, 11)
+	assert.Equal(t, errDuplicateBatch, err)
+	d.release("p1", 0, 11, nil, true)
+	// release unknown producer/sequence
+	d.release("p3", 0, 1, nil, true)
+	d.release("p1", 0, 1000, nil, true)
+	d.release("", 0, 1, nil, true)
+
+	// sequence out of window
+	for seq := int64(12); seq <= 3*dedupWindowSize; seq++ {
+		assert.True(t, apply("p1", 0, seq))
+	}
+	_, err = d.acquire("p1", 0, 100)
+	assert.Equal(t, errStaleSequence, err)
+	assert.LessOrEqual(t, len(d.producers[producerKey{producerID: "p1"}].seen), 2*dedupWindowSize)
+
+	// idle producer dropped
+	now += producerIdleTTL.Milliseconds() + time.Minute.Milliseconds()
+	assert.True(t, apply("p1", 0, 1))
+	assert.NotContains(t, d.producers, producerKey{producerID: "p2"})
+}
+
+func TestWriteDeduplicator_partial_applied(t *testing.T) {
+	d := newWriteDeduplicator()
+	applied, err := d.acquire("p1", 0, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	// metric 0 applied, metric 1 failed
+	applied[0] = struct{}{}
+	d.release("p1", 0, 1, applied, false)
+
+	// retry only applies the failed metrics
+	applied, err = d.acquire("p1", 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]struct{}{0: {}}, applied)
+	_, err = d.acquire("p1", 0, 1)
+	assert.Equal(t, errDuplicateBatch, err)
+	applied[1] = struct{}{}
+	d.release("p1", 0, 1, applied, true)
+	_, err = d.acquire("p1", 0, 1)
+	assert.Equal(t, errDuplicateBatch, err)
+}
@@ -21,12 +21,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
+
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/dbstats"
+	"github.com/lindb/lindb/internal/tenant"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/pkg/timeutil"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/rpc"
@@ -34,8 +40,12 @@ import (
 
 //go:generate mockgen -source=./channel_manager.go -destination=./channel_manager_mock.go -package=replication
 
-// ErrCanceled is the error returned when writing data ctx canceled.
-var ErrCanceled = errors.New("write data ctx done")
+var (
+	// ErrCanceled is the error returned when writing data ctx canceled.
+	ErrCanceled = errors.New("write data ctx done")
+	// ErrDraining is the error returned when writing data after broker starts draining.
+	ErrDraining = errors.New("broker is draining, rejects new writes")
+)
 
 const (
 	defaultReportInterval = 30 * time.Second
@@ -47,13 +57,34 @@ var log = logger.GetLogger("replication", "ChannelManager")
 // ChannelManager manages the construction, retrieving, closing for all channels.
 type ChannelManager interface {
 	// Write writes a MetricList, the manager handler the database, sharding things.
+	// Invalid metrics and metrics exceeding quota of namespace are rejected,
+	// returns *PartialWriteError with reasons if any metric rejected.
+	// MetricList with producer id is written as WriteBatch, which keeps the batch for deduplication.
 	Write(database string, list *protoMetricsV1.MetricList) error
+	// WriteBatch writes a MetricList, metrics of the same shard are serialized into a single chunk,
+	// which is much cheaper than writing metrics one by one.
+	// Invalid metrics and metrics exceeding quota of namespace are rejected,
+	// returns *PartialWriteError with reasons if any metric rejected.
+	WriteBatch(database string, list *protoMetricsV1.MetricList) error
 	// CreateChannel creates a new channel or returns a existed channel for storage with specific database and shardID,
 	// numOfShard should be greater or equal than the origin setting, otherwise error is returned.
-	// numOfShard is used eot calculate the shardID for a given hash.
-	CreateChannel(database string, numOfShard, shardID int32) (Channel, error)
+	// numOfShard is used eot calculate the shardID for a given hash by shard routing of database.
+	CreateChannel(database, shardRouting string, numOfShard, shardID int32) (Channel, error)
 	// SyncReplicatorState syncs replicator state
 	SyncReplicatorState()
+	// Topology returns the replication channel topology of all databases under current broker.
+	Topology() []models.DatabaseChannelTopology
+	// HashRing returns the routing which maps series to shard of given database.
+	HashRing(database string) (*models.HashRing, bool)
+	// UpdateIngestion applies the timestamp bounds of ingestion config to metric validation at runtime.
+	UpdateIngestion(ingestion config.Ingestion)
+	// UpdateDatabaseOption applies the option(like timestamp precision) of database to metric validation.
+	UpdateDatabaseOption(database string, databaseOption option.DatabaseOption)
+	// RemoveDatabaseOption removes the option of database after database deleted.
+	RemoveDatabaseOption(database string)
+	// Drain rejects new writes with ErrDraining, appends all buffered data into queue,
+	// then waits until all data replicated to storage or ctx done.
+	Drain(ctx context.Context) error
 
 	// Close closes all the channel.
 	Close()
@@ -67,6 +98,16 @@ type channelManager struct {
 	cancel context.CancelFunc
 	// config
 	cfg config.ReplicationChannel
+	// validates metrics and checks quotas of namespaces before writing
+	validator *metricValidator
+	// database name -> option.DatabaseOption, used by metric validation
+	databaseOptions sync.Map
+	// records rejected metrics, nil means not recorded
+	deadLetter DeadLetter
+	// publishes written metrics to subscribers, nil means no subscription
+	subscriptions Subscriptions
+	// forwards written metrics to remote clusters, nil means no mirroring
+	mirrors Mirrors
 	// factory to get rpc  write client
 	fct rpc.ClientStreamFactory
 	// for report replica state
@@ -76,18 +117,25 @@ type channelManager struct {
 	// lock for channelMap
 	lock4map  sync.Mutex
 	syncState chan struct{}
-	logger    *logger.Logger
+	// rejects new writes if draining
+	draining atomic.Bool
+	logger   *logger.Logger
 }
 
 // NewChannelManager returns a ChannelManager with dirPath and WriteClientFactory.
 // WriteClientFactory makes it easy to mock rpc streamClient for test.
-func NewChannelManager(cfg config.ReplicationChannel, fct rpc.ClientStreamFactory,
-	replicatorStateReport ReplicatorStateReport) ChannelManager {
+func NewChannelManager(cfg config.ReplicationChannel, ingestion config.Ingestion, deadLetter DeadLetter,
+	subscriptions Subscriptions, mirrors Mirrors, tenants tenant.Tenants,
+	fct rpc.ClientStreamFactory, replicatorStateReport ReplicatorStateReport) ChannelManager {
 	ctx, cancel := context.WithCancel(context.Background())
 	cm := &channelManager{
 		ctx:                   ctx,
 		cancel:                cancel,
 		cfg:                   cfg,
+		validator:             newMetricValidator(ingestion, tenants),
+		deadLetter:            deadLetter,
+		subscriptions:         subscriptions,
+		mirrors:               mirrors,
 		fct:                   fct,
 		replicatorStateReport: replicatorStateReport,
 		syncState:             make(chan struct{}),
@@ -99,6 +147,29 @@ func NewChannelManager(cfg config.ReplicationChannel, fct rpc.ClientStreamFactor
 
 // Write writes a MetricList, the manager handler the database, sharding things.
 func (cm *channelManager) Write(database string, metricList *protoMetricsV1.MetricList) error {
+	if cm.draining.Load() {
+		return ErrDraining
+	}
+	databaseChannel, ok := cm.getDatabaseChannel(database)
+	if !ok {
+		return fmt.Errorf("database [%s] not found", database)
+	}
+	if metricList == nil || len(metricList.Metrics) == 0 {
+		return fmt.Errorf("metrics is empty")
+	}
+	if metricList.ProducerID != "" {
+		// keeps (producer id, sequence) of batch, storage side drops duplicate batch retried by producer
+		return cm.write(database, metricList, databaseChannel.WriteBatch)
+	}
+	return cm.write(database, metricList, databaseChannel.Write)
+}
+
+// WriteBatch writes a MetricList, metrics of the same shard are serialized into a single chunk,
+// which is much cheaper than writing metrics one by one.
+func (cm *channelManager) WriteBatch(database string, metricList *protoMetricsV1.MetricList) error {
+	if cm.draining.Load() {
+		return ErrDraining
+	}
 	databaseChannel, ok := cm.getDatabaseChannel(database)
 	if !ok {
 		return fmt.Errorf("database [%s] not found", database)
@@ -106,12 +177,48 @@ func (cm *channelManager) Write(database string, metricList *protoMetricsV1.Metr
 	if metricList == nil || len(metricList.Metrics) == 0 {
 		return fmt.Errorf("metrics is empty")
 	}
-	return databaseChannel.Write(metricList)
+	return cm.write(database, metricList, databaseChannel.WriteBatch)
+}
+
+// write validates metrics, records rejected metrics into dead letter, then writes valid metrics by write function,
+// written metrics are published to subscribers and forwarded to mirrors.
+// Returns *PartialWriteError if any metric rejected and the others are written successfully.
+func (cm *channelManager) write(database string, metricList *protoMetricsV1.MetricList,
+	writeFn func(metricList *protoMetricsV1.MetricList) error) error {
+	var onReject func(metric *protoMetricsV1.Metric, reason string)
+	if cm.deadLetter != nil {
+		onReject = func(metric *protoMetricsV1.Metric, reason string) {
+			cm.deadLetter.Record(database, metric, reason)
+		}
+	}
+	metricList, rejected := cm.validator.validate(metricList, cm.getDatabaseOption(database), onReject)
+	if rejected != nil {
+		rejectedMetricsCounter.Add(float64(rejected.Rejected))
+		dbstats.RecordWrite(database, 0, 0, rejected.Rejected)
+		if rejected.Succeeded == 0 {
+			return rejected
+		}
+	}
+	if err := writeFn(metricList); err != nil {
+		dbstats.RecordWrite(database, 0, 0, len(metricList.Metrics))
+		return err
+	}
+	dbstats.RecordWrite(database, len(metricList.Metrics), metricList.Size(), 0)
+	if cm.subscriptions != nil {
+		cm.subscriptions.Publish(database, metricList)
+	}
+	if cm.mirrors != nil {
+		cm.mirrors.Mirror(database, metricList)
+	}
+	if rejected != nil {
+		return rejected
+	}
+	return nil
 }
 
 // CreateChannel creates a new channel or returns a existed channel for storage with specific database and shardID.
 // NumOfShard should be greater or equal than the origin setting, otherwise error is returned.
-func (cm *channelManager) CreateChannel(database string, numOfShard, shardID int32) (Channel, error) {
+func (cm *channelManager) CreateChannel(database, shardRouting string, numOfShard, shardID int32) (Channel, error) {
 	if numOfShard <= 0 || shardID >= numOfShard {
 		return nil, errors.New("numOfShard should be greater than 0 and shardID should less then numOfShard")
 	}
@@ -124,7 +231,7 @@ func (cm *channelManager) CreateChannel(database string, numOfShard, shardID int
 		ch, ok = cm.getDatabaseChannel(database)
 		if !ok {
 			// if not exist, create database channel
-			ch, err := newDatabaseChannel(cm.ctx, database, cm.cfg, numOfShard, cm.fct)
+			ch, err := newDatabaseChannel(cm.ctx, database, cm.cfg, shardRouting, numOfShard, cm.fct)
 			if err != nil {
 				return nil, err
 			}
@@ -142,6 +249,72 @@ func (cm *channelManager) SyncReplicatorState() {
 	cm.syncState <- struct{}{}
 }
 
+// Topology returns the replication channel topology of all databases under current broker.
+func (cm *channelManager) Topology() []models.DatabaseChannelTopology {
+	var topology []models.DatabaseChannelTopology
+	cm.databaseChannelMap.Range(func(key, value interface{}) bool {
+		channel, ok := value.(DatabaseChannel)
+		if ok {
+			topology = append(topology, channel.Topology())
+		}
+		return true
+	})
+	sort.Slice(topology, func(i, j int) bool {
+		return topology[i].Database < topology[j].Database
+	})
+	return topology
+}
+
+// HashRing returns the routing which maps series to shard of given database.
+func (cm *channelManager) HashRing(database string) (*models.HashRing, bool) {
+	channel, ok := cm.getDatabaseChannel(database)
+	if !ok {
+		return nil, false
+	}
+	ring := channel.HashRing()
+	return &ring, true
+}
+
+// UpdateIngestion applies the timestamp bounds of ingestion config to metric validation at runtime.
+func (cm *channelManager) UpdateIngestion(ingestion config.Ingestion) {
+	cm.validator.update(ingestion)
+}
+
+// UpdateDatabaseOption applies the option(like timestamp precision) of database to metric validation.
+func (cm *channelManager) UpdateDatabaseOption(database string, databaseOption option.DatabaseOption) {
+	cm.databaseOptions.Store(database, databaseOption)
+}
+
+// RemoveDatabaseOption removes the option of database after database deleted.
+func (cm *channelManager) RemoveDatabaseOption(database string) {
+	cm.databaseOptions.Delete(database)
+}
+
+// getDatabaseOption returns the option of database, returns default option if not found.
+func (cm *channelManager) getDatabaseOption(database string) option.DatabaseOption {
+	databaseOption, ok := cm.databaseOptions.Load(database)
+	if !ok {
+		return option.DatabaseOption{}
+	}
+	return databaseOption.(option.DatabaseOption)
+}
+
+// Drain rejects new writes with ErrDraining, appends all buffered data into queue,
+// then waits until all data replicated to storage or ctx done.
+func (cm *channelManager) Drain(ctx context.Context) (err error) {
+	cm.draining.Store(true)
+	cm.databaseChannelMap.Range(func(key, value interface{}) bool {
+		channel, ok := value.(DatabaseChannel)
+		if ok {
+			if err0 := channel.Drain(ctx); err0 != nil {
+				err = err0
+			}
+		}
+		return true
+	})
+	return
+}
+
 // Close closes all the channel.
 func (cm *channelManager) Close() {
 	cm.cancel()
@@ -18,6 +18,7 @@
 package replication
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -28,9 +29,11 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/dbstats"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/option"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 )
 
@@ -58,15 +61,15 @@ func TestChannelManager_GetChannel(t *testing.T) {
 	replicatorStateReport.EXPECT().Report(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
 
 	replicationConfig.Dir = dirPath
-	cm := NewChannelManager(replicationConfig, nil, replicatorStateReport)
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, replicatorStateReport)
 
-	_, err := cm.CreateChannel("database", 2, 2)
+	_, err := cm.CreateChannel("database", option.ShardRoutingModulo, 2, 2)
 	assert.Error(t, err)
 
-	ch1, err := cm.CreateChannel("database", 3, 0)
+	ch1, err := cm.CreateChannel("database", option.ShardRoutingModulo, 3, 0)
 	assert.NoError(t, err)
 
-	ch111, err := cm.CreateChannel("database", 3, 0)
+	ch111, err := cm.CreateChannel("database", option.ShardRoutingModulo, 3, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, ch111, ch1)
 
@@ -76,7 +79,7 @@ func TestChannelManager_GetChannel(t *testing.T) {
 	mkdir = func(path string) error {
 		return fmt.Errorf("err")
 	}
-	_, err = cm.CreateChannel("database-err", 3, 1)
+	_, err = cm.CreateChannel("database-err", option.ShardRoutingModulo, 3, 1)
 	assert.Error(t, err)
 
 	cm1 := cm.(*channelManager)
@@ -102,7 +105,7 @@ func TestChannelManager_Write(t *testing.T) {
 	replicatorStateReport.EXPECT().Report(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
 
 	replicationConfig.Dir = dirPath
-	cm := NewChannelManager(replicationConfig, nil, replicatorStateReport)
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, replicatorStateReport)
 	err := cm.Write("database", nil)
 	assert.Error(t, err)
 
@@ -110,10 +113,105 @@ func TestChannelManager_Write(t *testing.T) {
 	cm1 := cm.(*channelManager)
 	cm1.databaseChannelMap.Store("database", dbChannel)
 	dbChannel.EXPECT().Write(gomock.Any()).Return(nil)
+	err = cm.Write("database", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
+		newValidMetric(),
+	}})
+	assert.NoError(t, err)
+	// all metrics rejected
 	err = cm.Write("database", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
 		{Namespace: "xx"},
 	}})
+	partial, ok := err.(*PartialWriteError)
+	assert.True(t, ok)
+	assert.Equal(t, 0, partial.Succeeded)
+	assert.Equal(t, 1, partial.Rejected)
+	// partial write
+	dbChannel.EXPECT().Write(gomock.Any()).DoAndReturn(func(metricList *protoMetricsV1.MetricList) error {
+		assert.Len(t, metricList.Metrics, 1)
+		return nil
+	})
+	err = cm.Write("database", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
+		{Namespace: "xx"}, newValidMetric(),
+	}})
+	partial, ok = err.(*PartialWriteError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, partial.Succeeded)
+	assert.Equal(t, []MetricError{{Index: 0, Reason: "metric name is empty"}}, partial.Errors)
+	// write valid metrics failure
+	dbChannel.EXPECT().Write(gomock.Any()).Return(fmt.Errorf("err"))
+	err = cm.Write("database", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
+		{Namespace: "xx"}, newValidMetric(),
+	}})
+	assert.EqualError(t, err, "err")
+	// statistics of database
+	stats, ok := dbstats.Get("database")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), stats.WrittenPoints)
+	assert.Equal(t, int64(4), stats.WriteFailures)
+	// metric list with producer written as batch, producer kept after validation
+	dbChannel.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(func(metricList *protoMetricsV1.MetricList) error {
+		assert.Equal(t, "p1", metricList.ProducerID)
+		assert.Equal(t, int64(1), metricList.Sequence)
+		return nil
+	})
+	err = cm.Write("database", &protoMetricsV1.MetricList{
+		Metrics:    []*protoMetricsV1.Metric{{Namespace: "xx"}, newValidMetric()},
+		ProducerID: "p1",
+		Sequence:   1,
+	})
+	_, ok = err.(*PartialWriteError)
+	assert.True(t, ok)
+	cm.Close()
+}
+
+func TestChannelManager_WriteBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dirPath := path.Join(os.TempDir(), "test_channel_manager_batch")
+	defer func() {
+		if err := os.RemoveAll(dirPath); err != nil {
+			t.Error(err)
+		}
+		ctrl.Finish()
+	}()
+
+	replicatorStateReport := NewMockReplicatorStateReport(ctrl)
+	replicatorStateReport.EXPECT().Report(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
+
+	replicationConfig.Dir = dirPath
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, replicatorStateReport)
+	err := cm.WriteBatch("database", nil)
+	assert.Error(t, err)
+
+	dbChannel := NewMockDatabaseChannel(ctrl)
+	cm1 := cm.(*channelManager)
+	cm1.databaseChannelMap.Store("database", dbChannel)
+	err = cm.WriteBatch("database", &protoMetricsV1.MetricList{})
+	assert.Error(t, err)
+	dbChannel.EXPECT().WriteBatch(gomock.Any()).Return(nil)
+	err = cm.WriteBatch("database", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
+		newValidMetric(),
+	}})
 	assert.NoError(t, err)
+	err = cm.WriteBatch("database", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
+		{Namespace: "xx"},
+	}})
+	assert.Error(t, err)
+	// written metrics published to subscribers and mirrors
+	subscriptions := NewMockSubscriptions(ctrl)
+	mirrors := NewMockMirrors(ctrl)
+	cm1.subscriptions = subscriptions
+	cm1.mirrors = mirrors
+	dbChannel.EXPECT().WriteBatch(gomock.Any()).Return(nil)
+	subscriptions.EXPECT().Publish("database", gomock.Any()).Do(func(_ string, metricList *protoMetricsV1.MetricList) {
+		assert.Len(t, metricList.Metrics, 1)
+	})
+	mirrors.EXPECT().Mirror("database", gomock.Any()).Do(func(_ string, metricList *protoMetricsV1.MetricList) {
+		assert.Len(t, metricList.Metrics, 1)
+	})
+	err = cm.WriteBatch("database", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
+		{Namespace: "xx"}, newValidMetric(),
+	}})
+	assert.Error(t, err)
 	cm.Close()
 }
 
@@ -131,7 +229,7 @@ func TestChannelManager_ReportState(t *testing.T) {
 	replicatorStateReport.EXPECT().Report(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
 
 	replicationConfig.Dir = dirPath
-	cm := NewChannelManager(replicationConfig, nil, replicatorStateReport)
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, replicatorStateReport)
 	time.Sleep(2 * time.Second)
 	cm.Close()
 	// waiting close complete
@@ -158,7 +256,7 @@ func TestChannelManager_SyncReplicatorState(t *testing.T) {
 	replicatorStateReport.EXPECT().Report(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
 
 	replicationConfig.Dir = dirPath
-	cm := NewChannelManager(replicationConfig, nil, replicatorStateReport)
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, replicatorStateReport)
 	cm.SyncReplicatorState()
 
 	dbChannel := NewMockDatabaseChannel(ctrl)
@@ -168,3 +266,67 @@ func TestChannelManager_SyncReplicatorState(t *testing.T) {
 	cm1.reportState()
 	cm.Close()
 }
+
+func TestChannelManager_Topology(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dirPath := path.Join(os.TempDir(), "test_channel_manager")
+	defer func() {
+		if err := os.RemoveAll(dirPath); err != nil {
+			t.Error(err)
+		}
+		ctrl.Finish()
+	}()
+
+	replicationConfig.Dir = dirPath
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, NewMockReplicatorStateReport(ctrl))
+	defer cm.Close()
+	assert.Empty(t, cm.Topology())
+
+	dbChannel1 := NewMockDatabaseChannel(ctrl)
+	dbChannel2 := NewMockDatabaseChannel(ctrl)
+	cm1 := cm.(*channelManager)
+	cm1.databaseChannelMap.Store("db2", dbChannel2)
+	cm1.databaseChannelMap.Store("db1", dbChannel1)
+	dbChannel1.EXPECT().Topology().Return(models.DatabaseChannelTopology{Database: "db1"})
+	dbChannel2.EXPECT().Topology().Return(models.DatabaseChannelTopology{Database: "db2"})
+	assert.Equal(t, []models.DatabaseChannelTopology{{Database: "db1"}, {Database: "db2"}}, cm.Topology())
+}
+
+func TestChannelManager_HashRing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, NewMockReplicatorStateReport(ctrl))
+	defer cm.Close()
+	ring, ok := cm.HashRing("db")
+	assert.False(t, ok)
+	assert.Nil(t, ring)
+
+	dbChannel := NewMockDatabaseChannel(ctrl)
+	cm.(*channelManager).databaseChannelMap.Store("db", dbChannel)
+	dbChannel.EXPECT().HashRing().Return(models.HashRing{Database: "db", NumOfShard: 2})
+	ring, ok = cm.HashRing("db")
+	assert.True(t, ok)
+	assert.Equal(t, &models.HashRing{Database: "db", NumOfShard: 2}, ring)
+}
+
+func TestChannelManager_Drain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := NewChannelManager(replicationConfig, config.Ingestion{}, nil, nil, nil, nil, nil, NewMockReplicatorStateReport(ctrl))
+	defer cm.Close()
+	dbChannel := NewMockDatabaseChannel(ctrl)
+	cm.(*channelManager).databaseChannelMap.Store("db", dbChannel)
+	cm.(*channelManager).databaseChannelMap.Store("err", "err channel")
+	// case 1: drain failure
+	dbChannel.EXPECT().Drain(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, cm.Drain(context.TODO()))
+	// case 2: drain successfully
+	dbChannel.EXPECT().Drain(gomock.Any()).Return(nil)
+	assert.NoError(t, cm.Drain(context.TODO()))
+	// case 3: reject new writes after draining
+	metricList := &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "cpu"}}}
+	assert.Equal(t, ErrDraining, cm.Write("db", metricList))
+	assert.Equal(t, ErrDraining, cm.WriteBatch("db", metricList))
+}
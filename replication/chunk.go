@@ -38,6 +38,8 @@ type Chunk interface {
 	Size() int
 	// Append appends the metric into buffer
 	Append(metric *protoMetricsV1.Metric)
+	// MarshalBatch marshals the metric list into one compressed block directly, bypassing the buffer.
+	MarshalBatch(metricList *protoMetricsV1.MetricList) ([]byte, error)
 	// BinaryMarshaler marshals the data
 	encoding.BinaryMarshaler
 }
@@ -85,6 +87,28 @@ func (c *chunk) Append(metric *protoMetricsV1.Metric) {
 	c.size++
 }
 
+// MarshalBatch marshals the metric list into one compressed block directly, bypassing the buffer.
+func (c *chunk) MarshalBatch(metricList *protoMetricsV1.MetricList) ([]byte, error) {
+	if metricList == nil || len(metricList.Metrics) == 0 {
+		return nil, nil
+	}
+	buf := &bytes.Buffer{}
+	c.writer.Reset(buf)
+	defer c.writer.Reset(c.buf)
+
+	data, err := metricList.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := c.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // MarshalBinary marshals the data, then resets the context,
 func (c *chunk) MarshalBinary() ([]byte, error) {
 	// if chunk is empty, return nil,nil
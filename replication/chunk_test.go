@@ -104,6 +104,36 @@ func TestChunk_MarshalBinary(t *testing.T) {
 	assert.Nil(t, data)
 }
 
+func TestChunk_MarshalBatch(t *testing.T) {
+	c1 := newChunk(2)
+	data, err := c1.MarshalBatch(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+
+	var metrics []*protoMetricsV1.Metric
+	for i := 0; i < 5; i++ {
+		metrics = append(metrics, &protoMetricsV1.Metric{
+			Name:      "cpu",
+			Timestamp: timeutil.Now(),
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 1}},
+			Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: "1.1.1.1"}},
+		})
+	}
+	data, err = c1.MarshalBatch(&protoMetricsV1.MetricList{Metrics: metrics, ProducerID: "p1", Sequence: 10})
+	assert.NoError(t, err)
+	reader := snappy.NewReader(bytes.NewReader(data))
+	data, err = ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	var metricList protoMetricsV1.MetricList
+	err = metricList.Unmarshal(data)
+	assert.NoError(t, err)
+	assert.Equal(t, protoMetricsV1.MetricList{Metrics: metrics, ProducerID: "p1", Sequence: 10}, metricList)
+	// buffer not changed
+	assert.True(t, c1.IsEmpty())
+	testMarshal(c1, 2, t)
+}
+
 func testMarshal(chunk Chunk, size int, t *testing.T) {
 	rs := protoMetricsV1.MetricList{}
 	for i := 0; i < size; i++ {
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"path"
+	"sort"
 	"sync"
 
 	"github.com/cespare/xxhash"
@@ -29,7 +30,9 @@ import (
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/fileutil"
+	"github.com/lindb/lindb/pkg/hashring"
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/option"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/rpc"
 	"github.com/lindb/lindb/series/tag"
@@ -54,10 +57,18 @@ var (
 type DatabaseChannel interface {
 	// Write writes the metric data into channel's buffer
 	Write(metricList *protoMetricsV1.MetricList) error
+	// WriteBatch writes the metric data into channel, one chunk per shard
+	WriteBatch(metricList *protoMetricsV1.MetricList) error
 	// CreateChannel creates the shard level replication channel by given shard id
 	CreateChannel(numOfShard, shardID int32) (Channel, error)
 	// ReplicaState returns the replica state
 	ReplicaState() (replicas []models.ReplicaState)
+	// Topology returns the topology of all shard level channels
+	Topology() models.DatabaseChannelTopology
+	// HashRing returns the routing which maps series to shard
+	HashRing() models.HashRing
+	// Drain drains all shard level channels, waits until all buffered data acked by replicators
+	Drain(ctx context.Context) error
 }
 
 type databaseChannel struct {
@@ -65,14 +76,15 @@ type databaseChannel struct {
 	ctx           context.Context
 	cfg           config.ReplicationChannel
 	fct           rpc.ClientStreamFactory
-	numOfShard    atomic.Int32
+	hashRing      bool         // maps series by consistent hash ring, else by modulo
+	ring          atomic.Value // hashring.Router, rebuilt when num. of shard increases
 	shardChannels sync.Map
 	mutex         sync.Mutex
 }
 
 // newDatabaseChannel creates a new database replication channel
 func newDatabaseChannel(ctx context.Context,
-	database string, cfg config.ReplicationChannel, numOfShard int32,
+	database string, cfg config.ReplicationChannel, shardRouting string, numOfShard int32,
 	fct rpc.ClientStreamFactory,
 ) (DatabaseChannel, error) {
 	dirPath := path.Join(cfg.Dir, database)
@@ -84,21 +96,22 @@ func newDatabaseChannel(ctx context.Context,
 		ctx:      ctx,
 		cfg:      cfg,
 		fct:      fct,
+		hashRing: shardRouting == option.ShardRoutingHashRing,
 	}
-	ch.numOfShard.Store(numOfShard)
+	ch.ring.Store(hashring.NewRouter(ch.hashRing, numOfShard))
 	return ch, nil
 }
 
 // Write writes the metric data into channel's buffer
 func (dc *databaseChannel) Write(metricList *protoMetricsV1.MetricList) (err error) {
 	// sharding metrics to shards
-	numOfShard := uint64(dc.numOfShard.Load())
+	ring := dc.getRing()
 	for _, metric := range metricList.Metrics {
 		hash := xxhash.Sum64String(tag.ConcatKeyValues(metric.Tags))
 		// set tags hash code for storage side reuse
 		// !!!IMPORTANT: storage side will use this hash for write
 		metric.TagsHash = hash
-		shardID := int32(hash % numOfShard)
+		shardID := ring.Get(hash)
 		channel, ok := dc.getChannelByShardID(shardID)
 		if !ok {
 			err = errChannelNotFound
@@ -113,6 +126,44 @@ func (dc *databaseChannel) Write(metricList *protoMetricsV1.MetricList) (err err
 	return
 }
 
+// WriteBatch writes the metric data into channel, one chunk per shard,
+// the producer id and sequence of metric list are kept in each chunk for storage side deduplication.
+func (dc *databaseChannel) WriteBatch(metricList *protoMetricsV1.MetricList) (err error) {
+	// sharding metrics to shards
+	ring := dc.getRing()
+	shards := make([][]*protoMetricsV1.Metric, ring.NumOfShard())
+	for _, metric := range metricList.Metrics {
+		hash := xxhash.Sum64String(tag.ConcatKeyValues(metric.Tags))
+		// set tags hash code for storage side reuse
+		// !!!IMPORTANT: storage side will use this hash for write
+		metric.TagsHash = hash
+		shardID := ring.Get(hash)
+		shards[shardID] = append(shards[shardID], metric)
+	}
+	for idx, metrics := range shards {
+		if len(metrics) == 0 {
+			continue
+		}
+		shardID := int32(idx)
+		channel, ok := dc.getChannelByShardID(shardID)
+		if !ok {
+			err = errChannelNotFound
+			// broker error, do not return to client
+			log.Error("channel not found", logger.String("database", dc.database), logger.Int32("shardID", shardID))
+			continue
+		}
+		if err = channel.WriteBatch(&protoMetricsV1.MetricList{
+			Metrics:       metrics,
+			ProducerID:    metricList.ProducerID,
+			Sequence:      metricList.Sequence,
+			ProducerEpoch: metricList.ProducerEpoch,
+		}); err != nil {
+			log.Error("channel write data error", logger.String("database", dc.database), logger.Int32("shardID", shardID))
+		}
+	}
+	return
+}
+
 // CreateChannel creates the shard level replication channel by given shard id
 func (dc *databaseChannel) CreateChannel(numOfShard, shardID int32) (Channel, error) {
 	channel, ok := dc.getChannelByShardID(shardID)
@@ -126,7 +177,8 @@ func (dc *databaseChannel) CreateChannel(numOfShard, shardID int32) (Channel, er
 			if numOfShard <= 0 || shardID >= numOfShard {
 				return nil, errInvalidShardID
 			}
-			if numOfShard < dc.numOfShard.Load() {
+			ring := dc.getRing()
+			if numOfShard < ring.NumOfShard() {
 				return nil, errInvalidShardNum
 			}
 			ch, err := createChannel(dc.ctx, dc.cfg, dc.database, shardID, dc.fct)
@@ -137,6 +189,10 @@ func (dc *databaseChannel) CreateChannel(numOfShard, shardID int32) (Channel, er
 			ch.Startup()
 			// cache shard level channel
 			dc.shardChannels.Store(shardID, ch)
+			if numOfShard > ring.NumOfShard() {
+				// num. of shard increases, only series owned by new shards are remapped if based on hash ring
+				dc.ring.Store(hashring.NewRouter(dc.hashRing, numOfShard))
+			}
 			return ch, nil
 		}
 	}
@@ -172,6 +228,68 @@ func (dc *databaseChannel) ReplicaState() (replicas []models.ReplicaState) {
 	return
 }
 
+// Topology returns the topology of all shard level channels
+func (dc *databaseChannel) Topology() models.DatabaseChannelTopology {
+	topology := models.DatabaseChannelTopology{
+		Database:   dc.database,
+		NumOfShard: dc.getRing().NumOfShard(),
+	}
+	dc.shardChannels.Range(func(key, value interface{}) bool {
+		channel, ok := value.(Channel)
+		if ok {
+			topology.Shards = append(topology.Shards, channel.Topology())
+		}
+		return true
+	})
+	sort.Slice(topology.Shards, func(i, j int) bool {
+		return topology.Shards[i].ShardID < topology.Shards[j].ShardID
+	})
+	return topology
+}
+
+// HashRing returns the routing which maps series to shard
+func (dc *databaseChannel) HashRing() models.HashRing {
+	ring := dc.getRing()
+	routing := option.ShardRoutingModulo
+	if dc.hashRing {
+		routing = option.ShardRoutingHashRing
+	}
+	hashRing := models.HashRing{
+		Database:     dc.database,
+		Routing:      routing,
+		NumOfShard:   ring.NumOfShard(),
+		VirtualNodes: ring.VirtualNodes(),
+	}
+	for shardID, ownership := range ring.Ownership() {
+		hashRing.Shards = append(hashRing.Shards, models.HashRingShard{
+			ShardID:   int32(shardID),
+			Ownership: ownership,
+		})
+	}
+	return hashRing
+}
+
+// Drain drains all shard level channels, waits until all buffered data acked by replicators
+func (dc *databaseChannel) Drain(ctx context.Context) (err error) {
+	dc.shardChannels.Range(func(key, value interface{}) bool {
+		channel, ok := value.(Channel)
+		if ok {
+			if err0 := channel.Drain(ctx); err0 != nil {
+				log.Error("drain channel error", logger.String("database", dc.database),
+					logger.Int32("shardID", channel.ShardID()), logger.Error(err0))
+				err = err0
+			}
+		}
+		return true
+	})
+	return
+}
+
+// getRing returns the current routing
+func (dc *databaseChannel) getRing() hashring.Router {
+	return dc.ring.Load().(hashring.Router)
+}
+
 // getChannelByShardID gets the replica channel by shard id
 func (dc *databaseChannel) getChannelByShardID(shardID int32) (Channel, bool) {
 	channel, ok := dc.shardChannels.Load(shardID)
@@ -22,15 +22,19 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cespare/xxhash"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/fileutil"
+	"github.com/lindb/lindb/pkg/hashring"
+	"github.com/lindb/lindb/pkg/option"
 	"github.com/lindb/lindb/pkg/timeutil"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/rpc"
+	"github.com/lindb/lindb/series/tag"
 )
 
 func TestDatabaseChannel_new(t *testing.T) {
@@ -40,7 +44,7 @@ func TestDatabaseChannel_new(t *testing.T) {
 	mkdir = func(path string) error {
 		return fmt.Errorf("err")
 	}
-	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, 10, nil)
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingModulo, 10, nil)
 	assert.Error(t, err)
 	assert.Nil(t, ch)
 }
@@ -49,7 +53,7 @@ func TestDatabaseChannel_Write(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, 1, nil)
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingModulo, 1, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, ch)
 	err = ch.Write(&protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
@@ -80,11 +84,57 @@ func TestDatabaseChannel_Write(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestDatabaseChannel_WriteBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingModulo, 2, nil)
+	assert.NoError(t, err)
+	var metrics []*protoMetricsV1.Metric
+	for i := 0; i < 10; i++ {
+		metrics = append(metrics, &protoMetricsV1.Metric{
+			Name:      "cpu",
+			Timestamp: timeutil.Now(),
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 1}},
+			Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: fmt.Sprintf("1.1.1.%d", i)}},
+		})
+	}
+	err = ch.WriteBatch(&protoMetricsV1.MetricList{Metrics: metrics})
+	assert.Equal(t, errChannelNotFound, err)
+
+	shardCh0 := NewMockChannel(ctrl)
+	shardCh1 := NewMockChannel(ctrl)
+	ch1 := ch.(*databaseChannel)
+	ch1.shardChannels.Store(int32(0), shardCh0)
+	ch1.shardChannels.Store(int32(1), shardCh1)
+
+	// each shard receives one batch, all metrics written once
+	total := 0
+	countFn := func(batch *protoMetricsV1.MetricList) error {
+		total += len(batch.Metrics)
+		// producer of batch kept for deduplication
+		assert.Equal(t, "p1", batch.ProducerID)
+		assert.Equal(t, int64(10), batch.Sequence)
+		return nil
+	}
+	shardCh0.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(countFn)
+	shardCh1.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(countFn)
+	err = ch.WriteBatch(&protoMetricsV1.MetricList{Metrics: metrics, ProducerID: "p1", Sequence: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, len(metrics), total)
+
+	shardCh0.EXPECT().WriteBatch(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
+	shardCh1.EXPECT().WriteBatch(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
+	err = ch.WriteBatch(&protoMetricsV1.MetricList{Metrics: metrics})
+	assert.Error(t, err)
+}
+
 func TestDatabaseChannel_CreateChannel(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, 4, nil)
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingModulo, 4, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, ch)
 	shardCh := NewMockChannel(ctrl)
@@ -127,7 +177,7 @@ func TestDatabaseChannel_ReplicaState(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, 3, nil)
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingModulo, 3, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, ch)
 
@@ -151,3 +201,105 @@ func TestDatabaseChannel_ReplicaState(t *testing.T) {
 	replicaState := ch.ReplicaState()
 	assert.Len(t, replicaState, 1)
 }
+
+func TestDatabaseChannel_Topology(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingModulo, 3, nil)
+	assert.NoError(t, err)
+
+	shardCh0 := NewMockChannel(ctrl)
+	shardCh1 := NewMockChannel(ctrl)
+	ch1 := ch.(*databaseChannel)
+	ch1.shardChannels.Store(int32(1), shardCh1)
+	ch1.shardChannels.Store(int32(0), shardCh0)
+	ch1.shardChannels.Store(int32(2), "err channel")
+	shardCh0.EXPECT().Topology().Return(models.ShardChannelTopology{ShardID: 0})
+	shardCh1.EXPECT().Topology().Return(models.ShardChannelTopology{ShardID: 1, AppendSeq: 10})
+
+	topology := ch.Topology()
+	assert.Equal(t, models.DatabaseChannelTopology{
+		Database:   "test-db",
+		NumOfShard: 3,
+		Shards:     []models.ShardChannelTopology{{ShardID: 0}, {ShardID: 1, AppendSeq: 10}},
+	}, topology)
+}
+
+func TestDatabaseChannel_HashRing(t *testing.T) {
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingHashRing, 4, nil)
+	assert.NoError(t, err)
+
+	ring := ch.HashRing()
+	assert.Equal(t, "test-db", ring.Database)
+	assert.Equal(t, option.ShardRoutingHashRing, ring.Routing)
+	assert.Equal(t, int32(4), ring.NumOfShard)
+	assert.Equal(t, hashring.DefaultVirtualNodes, ring.VirtualNodes)
+	assert.Len(t, ring.Shards, 4)
+
+	// num. of shard increases, hash ring rebuilt
+	_, err = ch.CreateChannel(6, 5)
+	assert.NoError(t, err)
+	ring = ch.HashRing()
+	assert.Equal(t, int32(6), ring.NumOfShard)
+	assert.Len(t, ring.Shards, 6)
+	total := 0.0
+	for _, shard := range ring.Shards {
+		total += shard.Ownership
+	}
+	assert.InDelta(t, 1.0, total, 0.0001)
+	assert.Equal(t, int32(6), ch.Topology().NumOfShard)
+}
+
+func TestDatabaseChannel_ModuloRouting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// modulo routing by default
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, "", 2, nil)
+	assert.NoError(t, err)
+	ring := ch.HashRing()
+	assert.Equal(t, option.ShardRoutingModulo, ring.Routing)
+	assert.Equal(t, 0, ring.VirtualNodes)
+	assert.Equal(t, []models.HashRingShard{{ShardID: 0, Ownership: 0.5}, {ShardID: 1, Ownership: 0.5}}, ring.Shards)
+
+	shardCh0 := NewMockChannel(ctrl)
+	shardCh1 := NewMockChannel(ctrl)
+	ch1 := ch.(*databaseChannel)
+	ch1.shardChannels.Store(int32(0), shardCh0)
+	ch1.shardChannels.Store(int32(1), shardCh1)
+	metric := &protoMetricsV1.Metric{
+		Name: "cpu",
+		Tags: []*protoMetricsV1.KeyValue{{Key: "host", Value: "h1"}},
+	}
+	hash := xxhash.Sum64String(tag.ConcatKeyValues(metric.Tags))
+	if hash%2 == 0 {
+		shardCh0.EXPECT().Write(gomock.Any()).Return(nil)
+	} else {
+		shardCh1.EXPECT().Write(gomock.Any()).Return(nil)
+	}
+	err = ch.Write(&protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{metric}})
+	assert.NoError(t, err)
+}
+
+func TestDatabaseChannel_Drain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ch, err := newDatabaseChannel(context.TODO(), "test-db", replicationConfig, option.ShardRoutingModulo, 3, nil)
+	assert.NoError(t, err)
+
+	shardCh0 := NewMockChannel(ctrl)
+	shardCh1 := NewMockChannel(ctrl)
+	ch1 := ch.(*databaseChannel)
+	ch1.shardChannels.Store(int32(0), shardCh0)
+	ch1.shardChannels.Store(int32(1), shardCh1)
+	ch1.shardChannels.Store(int32(2), "err channel")
+	shardCh0.EXPECT().Drain(gomock.Any()).Return(nil).Times(2)
+	shardCh1.EXPECT().Drain(gomock.Any()).Return(nil)
+	assert.NoError(t, ch.Drain(context.TODO()))
+	// drains all channels even if some channel failure
+	shardCh1.EXPECT().Drain(gomock.Any()).Return(fmt.Errorf("err"))
+	shardCh1.EXPECT().ShardID().Return(int32(1))
+	assert.Error(t, ch.Drain(context.TODO()))
+}
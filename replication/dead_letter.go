@@ -0,0 +1,211 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+//go:generate mockgen -source=./dead_letter.go -destination=./dead_letter_mock.go -package=replication
+
+const (
+	deadLetterFileName       = "dead_letter.log"
+	defaultDeadLetterSamples = 100
+)
+
+var (
+	deadLetterScope           = linmetric.NewScope("lindb.broker.dead_letter")
+	deadLetterRecordsCounter  = deadLetterScope.NewDeltaCounter("records")
+	deadLetterFailuresCounter = deadLetterScope.NewDeltaCounter("write_failures")
+	deadLetterRotatesCounter  = deadLetterScope.NewDeltaCounter("rotates")
+)
+
+// RejectedMetric represents the metric rejected when writing, with rejected reason.
+type RejectedMetric struct {
+	Timestamp int64  `json:"timestamp"` // rejected time
+	Database  string `json:"database"`
+	Reason    string `json:"reason"`
+	// Metric is json of rejected metric, or json string of proto text if metric cannot be marshaled(NaN value etc.).
+	Metric json.RawMessage `json:"metric"`
+}
+
+// DeadLetter records metrics rejected by validation for producer debugging.
+type DeadLetter interface {
+	io.Closer
+	// Record records the rejected metric with reason.
+	Record(database string, metric *protoMetricsV1.Metric, reason string)
+	// Recent returns recent rejected metrics, the newest first, at most limit.
+	Recent(limit int) []RejectedMetric
+}
+
+// deadLetter implements DeadLetter, keeps recent rejected metrics in memory,
+// appends all rejected metrics into local file as json lines if dir is set.
+type deadLetter struct {
+	cfg config.DeadLetter
+
+	samples []RejectedMetric // ring buffer of recent rejected metrics
+	next    int
+	count   int
+
+	file     *os.File
+	fileSize int64
+
+	mutex  sync.Mutex
+	logger *logger.Logger
+}
+
+// NewDeadLetter creates the dead letter, the file is opened lazily when first metric rejected.
+func NewDeadLetter(cfg config.DeadLetter) DeadLetter {
+	if cfg.SampleSize <= 0 {
+		cfg.SampleSize = defaultDeadLetterSamples
+	}
+	return &deadLetter{
+		cfg:     cfg,
+		samples: make([]RejectedMetric, cfg.SampleSize),
+		logger:  logger.GetLogger("replication", "DeadLetter"),
+	}
+}
+
+// Record records the rejected metric with reason.
+func (dl *deadLetter) Record(database string, metric *protoMetricsV1.Metric, reason string) {
+	deadLetterRecordsCounter.Incr()
+	rejected := RejectedMetric{
+		Timestamp: timeutil.Now(),
+		Database:  database,
+		Reason:    reason,
+		Metric:    marshalRejectedMetric(metric),
+	}
+
+	dl.mutex.Lock()
+	defer dl.mutex.Unlock()
+
+	dl.samples[dl.next] = rejected
+	dl.next = (dl.next + 1) % len(dl.samples)
+	if dl.count < len(dl.samples) {
+		dl.count++
+	}
+	if dl.cfg.Dir == "" {
+		return
+	}
+	if err := dl.append(rejected); err != nil {
+		deadLetterFailuresCounter.Incr()
+		dl.logger.Error("write rejected metric into dead letter file failure",
+			logger.String("dir", dl.cfg.Dir), logger.Error(err))
+	}
+}
+
+// Recent returns recent rejected metrics, the newest first, at most limit.
+func (dl *deadLetter) Recent(limit int) []RejectedMetric {
+	dl.mutex.Lock()
+	defer dl.mutex.Unlock()
+
+	if limit <= 0 || limit > dl.count {
+		limit = dl.count
+	}
+	result := make([]RejectedMetric, 0, limit)
+	for i := 1; i <= limit; i++ {
+		result = append(result, dl.samples[(dl.next-i+len(dl.samples))%len(dl.samples)])
+	}
+	return result
+}
+
+// Close closes the dead letter file.
+func (dl *deadLetter) Close() error {
+	dl.mutex.Lock()
+	defer dl.mutex.Unlock()
+
+	if dl.file == nil {
+		return nil
+	}
+	err := dl.file.Close()
+	dl.file = nil
+	return err
+}
+
+// append appends rejected metric into file as json line, rotates file if exceeds max file size.
+func (dl *deadLetter) append(rejected RejectedMetric) error {
+	if dl.file == nil {
+		if err := dl.openFile(); err != nil {
+			return err
+		}
+	}
+	line := append(encoding.JSONMarshal(&rejected), '\n')
+	n, err := dl.file.Write(line)
+	dl.fileSize += int64(n)
+	if err != nil {
+		return err
+	}
+	if dl.cfg.MaxFileSize > 0 && dl.fileSize >= int64(dl.cfg.MaxFileSize) {
+		return dl.rotate()
+	}
+	return nil
+}
+
+// openFile opens the dead letter file for appending.
+func (dl *deadLetter) openFile() error {
+	if err := os.MkdirAll(dl.cfg.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dl.cfg.Dir, deadLetterFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	dl.file = f
+	dl.fileSize = stat.Size()
+	return nil
+}
+
+// marshalRejectedMetric marshals metric as json, uses proto text if metric cannot be marshaled.
+func marshalRejectedMetric(metric *protoMetricsV1.Metric) json.RawMessage {
+	data, err := json.Marshal(metric)
+	if err == nil {
+		return data
+	}
+	data, _ = json.Marshal(metric.String())
+	return data
+}
+
+// rotate renames current file as backup file which overwrites the last one, then opens a new file.
+func (dl *deadLetter) rotate() error {
+	deadLetterRotatesCounter.Incr()
+	if err := dl.file.Close(); err != nil {
+		dl.logger.Warn("close dead letter file failure", logger.Error(err))
+	}
+	dl.file = nil
+	path := filepath.Join(dl.cfg.Dir, deadLetterFileName)
+	if err := os.Rename(path, path+".1"); err != nil {
+		return err
+	}
+	return dl.openFile()
+}
@@ -0,0 +1,101 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/ltoml"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func TestDeadLetter_Recent(t *testing.T) {
+	dl := NewDeadLetter(config.DeadLetter{SampleSize: 2})
+	assert.Empty(t, dl.Recent(10))
+
+	dl.Record("db", &protoMetricsV1.Metric{Name: "a"}, "reason-a")
+	rejected := dl.Recent(10)
+	assert.Len(t, rejected, 1)
+	assert.Equal(t, "db", rejected[0].Database)
+	assert.Equal(t, "reason-a", rejected[0].Reason)
+	assert.True(t, rejected[0].Timestamp > 0)
+
+	dl.Record("db", &protoMetricsV1.Metric{Name: "b"}, "reason-b")
+	dl.Record("db", &protoMetricsV1.Metric{Name: "c"}, "reason-c")
+	rejected = dl.Recent(0)
+	assert.Len(t, rejected, 2)
+	assert.Equal(t, "reason-c", rejected[0].Reason)
+	assert.Equal(t, "reason-b", rejected[1].Reason)
+	rejected = dl.Recent(1)
+	assert.Len(t, rejected, 1)
+	assert.Equal(t, "reason-c", rejected[0].Reason)
+
+	// metric with NaN value cannot be marshaled as json
+	dl.Record("db", &protoMetricsV1.Metric{Name: "d", SimpleFields: []*protoMetricsV1.SimpleField{
+		{Name: "f", Value: math.NaN()}}}, "NaN")
+	var metric string
+	assert.NoError(t, encoding.JSONUnmarshal(dl.Recent(1)[0].Metric, &metric))
+	assert.Contains(t, metric, "value:nan")
+
+	// default sample size, no file
+	dl = NewDeadLetter(config.DeadLetter{})
+	assert.Len(t, dl.(*deadLetter).samples, defaultDeadLetterSamples)
+	assert.NoError(t, dl.Close())
+}
+
+func TestDeadLetter_File(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "test_dead_letter")
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dl := NewDeadLetter(config.DeadLetter{Dir: dir, MaxFileSize: ltoml.Size(200)})
+	dl.Record("db", &protoMetricsV1.Metric{Name: "a"}, "reason-a")
+
+	path := filepath.Join(dir, deadLetterFileName)
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	scanner := bufio.NewScanner(f)
+	assert.True(t, scanner.Scan())
+	var rejected RejectedMetric
+	assert.NoError(t, encoding.JSONUnmarshal(scanner.Bytes(), &rejected))
+	assert.Equal(t, "reason-a", rejected.Reason)
+	assert.JSONEq(t, `{"name":"a"}`, string(rejected.Metric))
+	_ = f.Close()
+
+	// rotate file if exceeds max file size
+	for i := 0; i < 3; i++ {
+		dl.Record("db", &protoMetricsV1.Metric{Name: "a"}, "reason-a")
+	}
+	_, err = os.Stat(path + ".1")
+	assert.NoError(t, err)
+	assert.NoError(t, dl.Close())
+	assert.NoError(t, dl.Close())
+
+	// open file failure
+	dl = NewDeadLetter(config.DeadLetter{Dir: filepath.Join(path, "sub")})
+	dl.Record("db", &protoMetricsV1.Metric{Name: "a"}, "reason-a")
+	assert.Len(t, dl.Recent(1), 1)
+}
@@ -0,0 +1,249 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/internal/tenant"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+// maxReportedErrors represents the max num. of metric errors reported in PartialWriteError.
+const maxReportedErrors = 100
+
+var rejectedMetricsCounter = replicationScope.NewDeltaCounter("rejected_metrics")
+
+// MetricError represents the reason why the metric is rejected.
+type MetricError struct {
+	// Index is the position of the metric in the written metric list.
+	Index  int    `json:"index"`
+	Metric string `json:"metric"`
+	Reason string `json:"reason"`
+}
+
+// PartialWriteError represents some metrics are rejected by validation, the others are written.
+type PartialWriteError struct {
+	Succeeded int           `json:"succeeded"`
+	Rejected  int           `json:"rejected"`
+	Errors    []MetricError `json:"errors"` // keeps first maxReportedErrors errors
+}
+
+// Error returns the summary of partial write.
+func (e *PartialWriteError) Error() string {
+	reason := ""
+	if len(e.Errors) > 0 {
+		reason = e.Errors[0].Reason
+	}
+	return fmt.Sprintf("partial write, succeeded: %d, rejected: %d, first reason: %s",
+		e.Succeeded, e.Rejected, reason)
+}
+
+// metricValidator validates the schema of metric before writing into replication channel.
+type metricValidator struct {
+	behind atomic.Int64 // max duration(ms) that timestamp behinds now, 0 means no limit
+	ahead  atomic.Int64 // max duration(ms) that timestamp aheads now, 0 means no limit
+	// checks quotas of namespace after metric validated, nil means no quota
+	tenants tenant.Tenants
+}
+
+// newMetricValidator creates the metric validator with timestamp bounds of ingestion config and quotas of namespaces.
+func newMetricValidator(cfg config.Ingestion, tenants tenant.Tenants) *metricValidator {
+	v := &metricValidator{tenants: tenants}
+	v.update(cfg)
+	return v
+}
+
+// update changes the timestamp bounds with ingestion config at runtime.
+func (v *metricValidator) update(cfg config.Ingestion) {
+	v.behind.Store(cfg.MaxTimestampBehind.Duration().Milliseconds())
+	v.ahead.Store(cfg.MaxTimestampAhead.Duration().Milliseconds())
+}
+
+// validate splits metric list into valid metrics and rejected errors, keeps the order of valid metrics,
+// timestamps are validated based on precision of database option, calls onReject for each rejected metric
+// if onReject not nil.
+func (v *metricValidator) validate(
+	metricList *protoMetricsV1.MetricList,
+	databaseOption option.DatabaseOption,
+	onReject func(metric *protoMetricsV1.Metric, reason string),
+) (*protoMetricsV1.MetricList, *PartialWriteError) {
+	var (
+		now       = timeutil.Now()
+		precision = databaseOption.GetPrecision()
+		valid     []*protoMetricsV1.Metric
+		rejected  *PartialWriteError
+	)
+	for idx, metric := range metricList.Metrics {
+		err := v.validateMetric(metric, now, precision)
+		if err == nil && v.tenants != nil {
+			err = v.tenants.AdmitWrite(metric)
+		}
+		if err == nil {
+			valid = append(valid, metric)
+			continue
+		}
+		if rejected == nil {
+			rejected = &PartialWriteError{}
+			// copy valid metrics before first rejected metric
+			valid = append(make([]*protoMetricsV1.Metric, 0, len(metricList.Metrics)), metricList.Metrics[:idx]...)
+		}
+		rejected.Rejected++
+		if onReject != nil {
+			onReject(metric, err.Error())
+		}
+		if len(rejected.Errors) < maxReportedErrors {
+			name := ""
+			if metric != nil {
+				name = metric.Name
+			}
+			rejected.Errors = append(rejected.Errors, MetricError{Index: idx, Metric: name, Reason: err.Error()})
+		}
+	}
+	if rejected == nil {
+		return metricList, nil
+	}
+	rejected.Succeeded = len(valid)
+	return &protoMetricsV1.MetricList{
+		Metrics:       valid,
+		ProducerID:    metricList.ProducerID,
+		Sequence:      metricList.Sequence,
+		ProducerEpoch: metricList.ProducerEpoch,
+	}, rejected
+}
+
+// validateMetric validates metric name, fields, tags and timestamp of precision.
+func (v *metricValidator) validateMetric(metric *protoMetricsV1.Metric, now int64, precision timeutil.Precision) error {
+	if metric == nil {
+		return errors.New("metric is nil")
+	}
+	if err := validateName("metric name", metric.Name); err != nil {
+		return err
+	}
+	if metric.Namespace != "" && !utf8.ValidString(metric.Namespace) {
+		return fmt.Errorf("namespace is not valid utf-8")
+	}
+	if err := v.validateTimestamp(metric.Timestamp, now, precision); err != nil {
+		return err
+	}
+	if err := validateFields(metric); err != nil {
+		return err
+	}
+	return validateTags(metric.Tags)
+}
+
+// validateTimestamp validates timestamp bounds, timestamp of precision is normalized to millisecond before comparing.
+func (v *metricValidator) validateTimestamp(timestamp, now int64, precision timeutil.Precision) error {
+	if timestamp <= 0 {
+		return fmt.Errorf("timestamp %d is invalid", timestamp)
+	}
+	millis := precision.ToMillis(timestamp)
+	if behind := v.behind.Load(); behind > 0 && millis < now-behind {
+		return fmt.Errorf("timestamp %d is too far behind now", timestamp)
+	}
+	if ahead := v.ahead.Load(); ahead > 0 && millis > now+ahead {
+		return fmt.Errorf("timestamp %d is too far ahead of now", timestamp)
+	}
+	return nil
+}
+
+// validateFields validates field names, values and num. of fields.
+func validateFields(metric *protoMetricsV1.Metric) error {
+	if len(metric.SimpleFields) == 0 && metric.CompoundField == nil {
+		return errors.New("metric has no fields")
+	}
+	if len(metric.SimpleFields) > constants.DefaultMaxFieldsCount {
+		return fmt.Errorf("too many fields: %d", len(metric.SimpleFields))
+	}
+	names := make(map[string]struct{}, len(metric.SimpleFields))
+	for _, f := range metric.SimpleFields {
+		if f == nil {
+			return errors.New("field is nil")
+		}
+		if err := validateName("field name", f.Name); err != nil {
+			return err
+		}
+		if _, ok := names[f.Name]; ok {
+			return fmt.Errorf("duplicate field name %q", f.Name)
+		}
+		names[f.Name] = struct{}{}
+		if f.Type == protoMetricsV1.SimpleFieldType_SIMPLE_UNSPECIFIED {
+			return fmt.Errorf("field %q type is unspecified", f.Name)
+		}
+		if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+			return fmt.Errorf("field %q value is NaN or Inf", f.Name)
+		}
+	}
+	if f := metric.CompoundField; f != nil {
+		if f.Type == protoMetricsV1.CompoundFieldType_COMPOUND_UNSPECIFIED {
+			return errors.New("compound field type is unspecified")
+		}
+		if len(f.ExplicitBounds) != len(f.Values) {
+			return fmt.Errorf("compound field has %d bounds but %d values", len(f.ExplicitBounds), len(f.Values))
+		}
+		for i := 1; i < len(f.ExplicitBounds); i++ {
+			if f.ExplicitBounds[i] <= f.ExplicitBounds[i-1] {
+				return errors.New("compound field bounds are not increasing")
+			}
+		}
+	}
+	return nil
+}
+
+// validateTags validates tag keys, values and num. of tags.
+func validateTags(tags []*protoMetricsV1.KeyValue) error {
+	if len(tags) >= constants.DefaultMaxTagKeysCount {
+		return fmt.Errorf("too many tags: %d", len(tags))
+	}
+	for _, tag := range tags {
+		if tag == nil {
+			return errors.New("tag is nil")
+		}
+		if err := validateName("tag key", tag.Key); err != nil {
+			return err
+		}
+		if tag.Value == "" {
+			return fmt.Errorf("tag %q value is empty", tag.Key)
+		}
+		if !utf8.ValidString(tag.Value) {
+			return fmt.Errorf("tag %q value is not valid utf-8", tag.Key)
+		}
+	}
+	return nil
+}
+
+// validateName validates the name is not blank and valid utf-8.
+func validateName(kind, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%s is empty", kind)
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("%s is not valid utf-8", kind)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/tenant"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func newValidMetric() *protoMetricsV1.Metric {
+	return &protoMetricsV1.Metric{
+		Namespace: "ns",
+		Name:      "cpu",
+		Timestamp: timeutil.Now(),
+		Tags:      []*protoMetricsV1.KeyValue{{Key: "host", Value: "a"}},
+		SimpleFields: []*protoMetricsV1.SimpleField{
+			{Name: "load", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 1}},
+		CompoundField: &protoMetricsV1.CompoundField{
+			Type:           protoMetricsV1.CompoundFieldType_DELTA_HISTOGRAM,
+			ExplicitBounds: []float64{1, 10, math.Inf(1)},
+			Values:         []float64{1, 2, 3},
+		},
+	}
+}
+
+func TestMetricValidator_validateMetric(t *testing.T) {
+	v := newMetricValidator(config.Ingestion{
+		MaxTimestampBehind: ltoml.Duration(time.Hour),
+		MaxTimestampAhead:  ltoml.Duration(time.Hour),
+	}, nil)
+	now := timeutil.Now()
+	assert.NoError(t, v.validateMetric(newValidMetric(), now, timeutil.Millisecond))
+
+	var tooManyTags []*protoMetricsV1.KeyValue
+	for i := 0; i < 40; i++ {
+		tooManyTags = append(tooManyTags, &protoMetricsV1.KeyValue{Key: "t" + strconv.Itoa(i), Value: "v"})
+	}
+	invalidUTF8 := string([]byte{0xff, 0xfe})
+	cases := []struct {
+		name   string
+		modify func(m *protoMetricsV1.Metric)
+		reason string
+	}{
+		{"empty name", func(m *protoMetricsV1.Metric) { m.Name = " " }, "metric name is empty"},
+		{"bad name", func(m *protoMetricsV1.Metric) { m.Name = invalidUTF8 }, "metric name is not valid utf-8"},
+		{"bad ns", func(m *protoMetricsV1.Metric) { m.Namespace = invalidUTF8 }, "namespace is not valid utf-8"},
+		{"no timestamp", func(m *protoMetricsV1.Metric) { m.Timestamp = 0 }, "timestamp 0 is invalid"},
+		{"too old", func(m *protoMetricsV1.Metric) { m.Timestamp = now - 2*timeutil.OneHour }, "too far behind"},
+		{"too new", func(m *protoMetricsV1.Metric) { m.Timestamp = now + 2*timeutil.OneHour }, "too far ahead"},
+		{"no fields", func(m *protoMetricsV1.Metric) {
+			m.SimpleFields = nil
+			m.CompoundField = nil
+		}, "metric has no fields"},
+		{"nil field", func(m *protoMetricsV1.Metric) { m.SimpleFields[0] = nil }, "field is nil"},
+		{"empty field name", func(m *protoMetricsV1.Metric) { m.SimpleFields[0].Name = "" }, "field name is empty"},
+		{"duplicate field", func(m *protoMetricsV1.Metric) {
+			m.SimpleFields = append(m.SimpleFields, m.SimpleFields[0])
+		}, "duplicate field name"},
+		{"unspecified field", func(m *protoMetricsV1.Metric) {
+			m.SimpleFields[0].Type = protoMetricsV1.SimpleFieldType_SIMPLE_UNSPECIFIED
+		}, "type is unspecified"},
+		{"NaN value", func(m *protoMetricsV1.Metric) { m.SimpleFields[0].Value = math.NaN() }, "NaN or Inf"},
+		{"too many fields", func(m *protoMetricsV1.Metric) {
+			m.SimpleFields = make([]*protoMetricsV1.SimpleField, 256)
+		}, "too many fields"},
+		{"unspecified compound", func(m *protoMetricsV1.Metric) {
+			m.CompoundField.Type = protoMetricsV1.CompoundFieldType_COMPOUND_UNSPECIFIED
+		}, "compound field type is unspecified"},
+		{"bounds mismatch", func(m *protoMetricsV1.Metric) { m.CompoundField.Values = nil }, "3 bounds but 0 values"},
+		{"bounds not increasing", func(m *protoMetricsV1.Metric) {
+			m.CompoundField.ExplicitBounds[1] = 1
+		}, "bounds are not increasing"},
+		{"too many tags", func(m *protoMetricsV1.Metric) { m.Tags = tooManyTags }, "too many tags"},
+		{"nil tag", func(m *protoMetricsV1.Metric) { m.Tags[0] = nil }, "tag is nil"},
+		{"empty tag key", func(m *protoMetricsV1.Metric) { m.Tags[0].Key = "" }, "tag key is empty"},
+		{"empty tag value", func(m *protoMetricsV1.Metric) { m.Tags[0].Value = "" }, "value is empty"},
+		{"bad tag value", func(m *protoMetricsV1.Metric) { m.Tags[0].Value = invalidUTF8 }, "not valid utf-8"},
+	}
+	for _, c := range cases {
+		m := newValidMetric()
+		c.modify(m)
+		err := v.validateMetric(m, now, timeutil.Millisecond)
+		if assert.Error(t, err, c.name) {
+			assert.Contains(t, err.Error(), c.reason, c.name)
+		}
+	}
+	assert.Error(t, v.validateMetric(nil, now, timeutil.Millisecond))
+
+	// timestamp of us/ns precision is normalized to millisecond
+	m := newValidMetric()
+	m.Timestamp = timeutil.Nanosecond.FromMillis(now)
+	assert.NoError(t, v.validateMetric(m, now, timeutil.Nanosecond))
+	assert.Error(t, v.validateMetric(m, now, timeutil.Millisecond))
+	m.Timestamp = timeutil.Microsecond.FromMillis(now - 2*timeutil.OneHour)
+	assert.Error(t, v.validateMetric(m, now, timeutil.Microsecond))
+
+	metricList := &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{newValidMetric()}}
+	metricList.Metrics[0].Timestamp = timeutil.Microsecond.FromMillis(now)
+	_, rejected := v.validate(metricList, option.DatabaseOption{Precision: "us"}, nil)
+	assert.Nil(t, rejected)
+}
+
+func TestMetricValidator_validate(t *testing.T) {
+	v := newMetricValidator(config.Ingestion{}, nil)
+	metricList := &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{newValidMetric(), newValidMetric()}}
+	valid, rejected := v.validate(metricList, option.DatabaseOption{}, nil)
+	assert.Nil(t, rejected)
+	assert.Equal(t, metricList, valid)
+
+	// no limit of timestamp bounds
+	old := newValidMetric()
+	old.Timestamp = 1
+	metricList.Metrics = append(metricList.Metrics, old, nil, &protoMetricsV1.Metric{Name: "a"}, newValidMetric())
+	var reasons []string
+	valid, rejected = v.validate(metricList, option.DatabaseOption{}, func(_ *protoMetricsV1.Metric, reason string) {
+		reasons = append(reasons, reason)
+	})
+	assert.Equal(t, []string{"metric is nil", "timestamp 0 is invalid"}, reasons)
+	assert.Len(t, valid.Metrics, 4)
+	assert.Equal(t, &PartialWriteError{Succeeded: 4, Rejected: 2, Errors: []MetricError{
+		{Index: 3, Reason: "metric is nil"},
+		{Index: 4, Metric: "a", Reason: "timestamp 0 is invalid"},
+	}}, rejected)
+	assert.Equal(t, "partial write, succeeded: 4, rejected: 2, first reason: metric is nil", rejected.Error())
+
+	// max reported errors
+	metricList.Metrics = make([]*protoMetricsV1.Metric, maxReportedErrors+10)
+	_, rejected = v.validate(metricList, option.DatabaseOption{}, nil)
+	assert.Equal(t, maxReportedErrors+10, rejected.Rejected)
+	assert.Len(t, rejected.Errors, maxReportedErrors)
+}
+
+func TestMetricValidator_quota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tenants := tenant.NewMockTenants(ctrl)
+	v := newMetricValidator(config.Ingestion{}, tenants)
+	metricList := &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{newValidMetric(), newValidMetric()}}
+	gomock.InOrder(
+		tenants.EXPECT().AdmitWrite(gomock.Any()).Return(nil),
+		tenants.EXPECT().AdmitWrite(gomock.Any()).Return(tenant.ErrWriteRateExceeded),
+	)
+	valid, rejected := v.validate(metricList, option.DatabaseOption{}, nil)
+	assert.Len(t, valid.Metrics, 1)
+	assert.Equal(t, &PartialWriteError{Succeeded: 1, Rejected: 1, Errors: []MetricError{
+		{Index: 1, Metric: "cpu", Reason: tenant.ErrWriteRateExceeded.Error()},
+	}}, rejected)
+}
+
+func TestMetricValidator_update(t *testing.T) {
+	v := newMetricValidator(config.Ingestion{}, nil)
+	now := timeutil.Now()
+	m := newValidMetric()
+	m.Timestamp = now - 2*timeutil.OneHour
+	assert.NoError(t, v.validateMetric(m, now, timeutil.Millisecond))
+
+	v.update(config.Ingestion{MaxTimestampBehind: ltoml.Duration(time.Hour)})
+	assert.Error(t, v.validateMetric(m, now, timeutil.Millisecond))
+	v.update(config.Ingestion{})
+	assert.NoError(t, v.validateMetric(m, now, timeutil.Millisecond))
+}
@@ -0,0 +1,290 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/queue"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/rpc"
+)
+
+//go:generate mockgen -source=./mirror.go -destination=./mirror_mock.go -package=replication
+
+const (
+	// mirrorBatchSize is the max num. of queued messages forwarded in one request
+	mirrorBatchSize = 10
+	// mirrorBatchBytes is the max size of request forwarded, less than grpc max message size
+	mirrorBatchBytes = 1024 * 1024
+	// interval for syncing queue meta and removing forwarded data
+	mirrorSyncInterval = time.Second
+)
+
+var (
+	mirrorScope         = linmetric.NewScope("lindb.broker.mirror")
+	mirrorAppendsVec    = mirrorScope.NewDeltaCounterVec("appends", "db")
+	mirrorDropsVec      = mirrorScope.NewDeltaCounterVec("drops", "db")
+	mirrorForwardsVec   = mirrorScope.NewDeltaCounterVec("forwards", "db")
+	mirrorFailuresVec   = mirrorScope.NewDeltaCounterVec("forward_failures", "db")
+	mirrorQueueDepthVec = mirrorScope.NewGaugeVec("queue_depth", "db")
+)
+
+// Mirrors forwards written metrics of configured databases to remote LinDB clusters asynchronously,
+// each database has its own queue for buffering, so that local writes are never blocked by remote cluster.
+type Mirrors interface {
+	// Mirror appends written metrics into queue of database if the database is mirrored.
+	Mirror(database string, metricList *protoMetricsV1.MetricList)
+	// Close stops forwarding, data not forwarded is kept in queue and forwarded after restarting.
+	Close()
+}
+
+// mirrors implements Mirrors.
+type mirrors struct {
+	mirrors map[string]*mirror // database => mirror, read only after created
+}
+
+// NewMirrors creates the mirrors of databases in config, starts forwarding queued data to remote clusters,
+// returns error if database isn't in format "database=ip:grpc-port" or duplicated.
+func NewMirrors(cfg config.Mirror, fct rpc.ClientStreamFactory) (Mirrors, error) {
+	ms := &mirrors{mirrors: make(map[string]*mirror)}
+	for _, database := range cfg.Databases {
+		parts := strings.SplitN(database, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			ms.Close()
+			return nil, fmt.Errorf("mirror database(%s) is not in the format database=ip:grpc-port", database)
+		}
+		if _, ok := ms.mirrors[parts[0]]; ok {
+			ms.Close()
+			return nil, fmt.Errorf("duplicate mirror database: %s", parts[0])
+		}
+		target, err := models.ParseNode(parts[1])
+		if err != nil {
+			ms.Close()
+			return nil, err
+		}
+		m, err := newMirror(cfg, parts[0], *target, fct)
+		if err != nil {
+			ms.Close()
+			return nil, err
+		}
+		ms.mirrors[parts[0]] = m
+	}
+	return ms, nil
+}
+
+// Mirror appends written metrics into queue of database if the database is mirrored.
+func (ms *mirrors) Mirror(database string, metricList *protoMetricsV1.MetricList) {
+	m, ok := ms.mirrors[database]
+	if !ok {
+		return
+	}
+	m.append(metricList)
+}
+
+// Close stops forwarding, data not forwarded is kept in queue.
+func (ms *mirrors) Close() {
+	for _, m := range ms.mirrors {
+		m.close()
+	}
+}
+
+// mirror forwards the written metrics of a database to broker of remote cluster.
+type mirror struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	database      string
+	target        models.Node
+	retryInterval time.Duration
+
+	q  queue.FanOutQueue
+	fo queue.FanOut
+
+	fct    rpc.ClientStreamFactory
+	stream protoBrokerV1.BrokerService_WriteClient
+	closed chan struct{}
+
+	logger *logger.Logger
+}
+
+// newMirror creates the mirror with its own queue under dir of config, starts forwarding loop.
+func newMirror(cfg config.Mirror, database string, target models.Node, fct rpc.ClientStreamFactory) (*mirror, error) {
+	q, err := newFanOutQueue(path.Join(cfg.Dir, database), int64(cfg.DataSizeLimit), time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	fo, err := q.GetOrCreateFanOut(target.Indicator())
+	if err != nil {
+		q.Close()
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &mirror{
+		ctx:           ctx,
+		cancel:        cancel,
+		database:      database,
+		target:        target,
+		retryInterval: cfg.RetryInterval.Duration(),
+		q:             q,
+		fo:            fo,
+		fct:           fct,
+		closed:        make(chan struct{}),
+		logger:        logger.GetLogger("replication", "Mirror"),
+	}
+	go m.forwardLoop()
+	return m, nil
+}
+
+// append appends the metrics into queue, metrics are dropped if queue is full.
+func (m *mirror) append(metricList *protoMetricsV1.MetricList) {
+	// producer id/sequence is meaningless for remote cluster
+	data, err := (&protoMetricsV1.MetricList{Metrics: metricList.Metrics}).Marshal()
+	if err == nil {
+		err = m.q.Put(data)
+	}
+	if err != nil {
+		mirrorDropsVec.WithTagValues(m.database).Incr()
+		m.logger.Warn("drop metrics for mirroring",
+			logger.String("database", m.database), logger.Int32("metrics", int32(len(metricList.Metrics))), logger.Error(err))
+		return
+	}
+	mirrorAppendsVec.WithTagValues(m.database).Incr()
+}
+
+// forwardLoop consumes the queue and forwards data to remote cluster until closed,
+// data is re-forwarded after retry interval if forwarding fails.
+func (m *mirror) forwardLoop() {
+	defer close(m.closed)
+
+	ticker := time.NewTicker(mirrorSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.q.Sync()
+			mirrorQueueDepthVec.WithTagValues(m.database).Update(float64(m.fo.Pending()))
+		default:
+		}
+		first, last, data := m.consumeBatch()
+		if len(data) == 0 {
+			m.sleep(10 * time.Millisecond)
+			continue
+		}
+		if err := m.forward(data); err != nil {
+			mirrorFailuresVec.WithTagValues(m.database).Incr()
+			m.logger.Error("forward metrics to remote cluster error, retry later",
+				logger.String("database", m.database), logger.String("target", m.target.Indicator()), logger.Error(err))
+			m.closeStream()
+			// re-consume from the first message of failure batch
+			if err := m.fo.SetHeadSeq(first - 1); err != nil {
+				m.logger.Error("reset mirror head seq error", logger.String("database", m.database), logger.Error(err))
+			}
+			m.sleep(m.retryInterval)
+			continue
+		}
+		m.fo.Ack(last)
+		mirrorForwardsVec.WithTagValues(m.database).Incr()
+	}
+}
+
+// consumeBatch consumes a batch of messages, returns the first/last seq and concatenated data,
+// concatenated MetricList messages are decoded as one MetricList.
+func (m *mirror) consumeBatch() (first, last int64, data []byte) {
+	for i := 0; i < mirrorBatchSize && len(data) < mirrorBatchBytes; i++ {
+		seq := m.fo.Consume()
+		if seq == queue.SeqNoNewMessageAvailable {
+			break
+		}
+		msg, err := m.fo.Get(seq)
+		if err != nil {
+			m.logger.Error("get message from mirror queue error",
+				logger.String("database", m.database), logger.Int64("seq", seq), logger.Error(err))
+			break
+		}
+		if i == 0 {
+			first = seq
+		}
+		last = seq
+		data = append(data, msg...)
+	}
+	return first, last, data
+}
+
+// forward writes data to broker of remote cluster, waits the response.
+func (m *mirror) forward(data []byte) error {
+	if m.stream == nil {
+		stream, err := m.fct.CreateBrokerWriteClient(m.target)
+		if err != nil {
+			return err
+		}
+		m.stream = stream
+	}
+	if err := m.stream.Send(&protoBrokerV1.WriteRequest{Database: m.database, Data: data}); err != nil {
+		return err
+	}
+	resp, err := m.stream.Recv()
+	if err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("remote cluster write failure, code: %d, message: %s", resp.Code, resp.Message)
+	}
+	return nil
+}
+
+// closeStream closes the stream after failure, new stream is created when forwarding next time.
+func (m *mirror) closeStream() {
+	if m.stream == nil {
+		return
+	}
+	if err := m.stream.CloseSend(); err != nil {
+		m.logger.Warn("close mirror stream error", logger.String("database", m.database), logger.Error(err))
+	}
+	m.stream = nil
+}
+
+// sleep waits duration or mirror closed.
+func (m *mirror) sleep(duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-m.ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// close stops forwarding loop, then closes the queue.
+func (m *mirror) close() {
+	m.cancel()
+	<-m.closed
+	m.closeStream()
+	m.q.Sync()
+	m.q.Close()
+}
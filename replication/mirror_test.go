@@ -0,0 +1,140 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/queue"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/rpc"
+)
+
+func TestNewMirrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newFanOutQueue = queue.NewFanOutQueue
+		ctrl.Finish()
+	}()
+
+	cfg := config.Mirror{Dir: t.TempDir(), DataSizeLimit: ltoml.Size(1024 * 1024), RetryInterval: ltoml.Duration(time.Second)}
+	badDatabases := [][]string{
+		{"db"},                                 // bad format
+		{"db=1.1.1.1:9001", "db=2.2.2.2:9001"}, // duplicate database
+		{"db=host:9001"},                       // bad address
+	}
+	for _, databases := range badDatabases {
+		cfg.Databases = databases
+		_, err := NewMirrors(cfg, nil)
+		assert.Error(t, err)
+	}
+	// create queue failure
+	newFanOutQueue = func(_ string, _ int64, _ time.Duration, _ ...queue.Option) (queue.FanOutQueue, error) {
+		return nil, fmt.Errorf("err")
+	}
+	cfg.Databases = []string{"db=1.1.1.1:9001"}
+	_, err := NewMirrors(cfg, nil)
+	assert.Error(t, err)
+	// create fan out failure
+	q := queue.NewMockFanOutQueue(ctrl)
+	newFanOutQueue = func(_ string, _ int64, _ time.Duration, _ ...queue.Option) (queue.FanOutQueue, error) {
+		return q, nil
+	}
+	q.EXPECT().GetOrCreateFanOut(gomock.Any()).Return(nil, fmt.Errorf("err"))
+	q.EXPECT().Close()
+	_, err = NewMirrors(cfg, nil)
+	assert.Error(t, err)
+}
+
+func TestMirror_append(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	q := queue.NewMockFanOutQueue(ctrl)
+	m := &mirror{database: "db", q: q, logger: logger.GetLogger("replication", "Test")}
+	q.EXPECT().Put(gomock.Any()).Return(queue.ErrExceedingTotalSizeLimit)
+	m.append(&protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "cpu"}}})
+}
+
+func TestMirrors_Mirror(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fct := rpc.NewMockClientStreamFactory(ctrl)
+	stream := protoBrokerV1.NewMockBrokerService_WriteClient(ctrl)
+	target := models.Node{IP: "1.1.1.1", Port: 9001}
+	cfg := config.Mirror{
+		Dir:           t.TempDir(),
+		Databases:     []string{"db=1.1.1.1:9001"},
+		DataSizeLimit: ltoml.Size(1024 * 1024),
+		RetryInterval: ltoml.Duration(10 * time.Millisecond),
+	}
+	received := make(chan *protoMetricsV1.MetricList, 1)
+	gomock.InOrder(
+		// case 1: create stream failure
+		fct.EXPECT().CreateBrokerWriteClient(target).Return(nil, fmt.Errorf("err")),
+		// case 2: remote write failure
+		fct.EXPECT().CreateBrokerWriteClient(target).Return(stream, nil),
+		stream.EXPECT().Send(gomock.Any()).Return(nil),
+		stream.EXPECT().Recv().Return(&protoBrokerV1.WriteResponse{Code: 1, Message: "database not found"}, nil),
+		stream.EXPECT().CloseSend().Return(fmt.Errorf("err")),
+		// case 3: forward successfully
+		fct.EXPECT().CreateBrokerWriteClient(target).Return(stream, nil),
+		stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *protoBrokerV1.WriteRequest) error {
+			assert.Equal(t, "db", req.Database)
+			metricList := &protoMetricsV1.MetricList{}
+			assert.NoError(t, metricList.Unmarshal(req.Data))
+			received <- metricList
+			return nil
+		}),
+		stream.EXPECT().Recv().Return(&protoBrokerV1.WriteResponse{}, nil),
+	)
+	stream.EXPECT().CloseSend().Return(nil).AnyTimes()
+
+	ms, err := NewMirrors(cfg, fct)
+	assert.NoError(t, err)
+	// database not mirrored
+	ms.Mirror("other", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "cpu"}}})
+	ms.Mirror("db", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "cpu"}}, ProducerID: "p1"})
+	ms.Mirror("db", &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{{Name: "memory"}}})
+
+	select {
+	case metricList := <-received:
+		// concatenated messages are decoded as one metric list
+		assert.Len(t, metricList.Metrics, 2)
+		assert.Equal(t, "cpu", metricList.Metrics[0].Name)
+		assert.Equal(t, "memory", metricList.Metrics[1].Name)
+		assert.Empty(t, metricList.ProducerID)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "metrics not forwarded")
+	}
+	time.Sleep(50 * time.Millisecond)
+	m := ms.(*mirrors).mirrors["db"]
+	assert.Equal(t, int64(0), m.fo.Pending())
+	ms.Close()
+}
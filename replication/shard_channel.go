@@ -19,12 +19,15 @@ package replication
 
 import (
 	"context"
+	"fmt"
 	"path"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/queue"
@@ -39,6 +42,12 @@ var (
 	newFanOutQueue = queue.NewFanOutQueue
 )
 
+var (
+	replicationScope = linmetric.NewScope("lindb.broker.replication")
+	queueDepthVec    = replicationScope.NewGaugeVec("queue_depth", "db", "shard")
+	queueDiskSizeVec = replicationScope.NewGaugeVec("queue_disk_size", "db", "shard")
+)
+
 // Channel represents a place to buffer the data for a specific cluster, database, shardID.
 type Channel interface {
 	// Database returns the database attribution.
@@ -51,11 +60,24 @@ type Channel interface {
 	// data is wrote successfully.
 	// Concurrent safe.
 	Write(metric *protoMetricsV1.Metric) error
+	// WriteBatch writes the metric list into the channel as one chunk, ErrCanceled is returned when the channel
+	// is canceled before data is wrote successfully.
+	// Concurrent safe.
+	WriteBatch(metricList *protoMetricsV1.MetricList) error
 	// GetOrCreateReplicator get a existed or creates a new replicator for target.
 	// Concurrent safe.
 	GetOrCreateReplicator(target models.Node) (Replicator, error)
+	// RemoveReplicator stops and removes the replicator for target if exist, such as target becomes read-only,
+	// replication progress is kept in queue, so re-created replicator continues from it.
+	// Concurrent safe.
+	RemoveReplicator(target models.Node)
 	// Nodes returns all the target nodes for replication.
 	Targets() []models.Node
+	// Topology returns the channel topology, includes buffer backlog, wal sequence and replicator state.
+	Topology() models.ShardChannelTopology
+	// Drain appends the buffered data into queue, then waits until all data in queue is acked by replicators,
+	// returns error if ctx is done before all data acked.
+	Drain(ctx context.Context) error
 }
 
 // channel implements Channel.
@@ -71,6 +93,8 @@ type channel struct {
 	q queue.FanOutQueue
 	// chanel to convert multiple goroutine write to single goroutine write to FanOutQueue
 	ch chan []byte
+	// channel to request appending all buffered data into FanOutQueue, closes the done chan after appended
+	flushRequest chan chan struct{}
 
 	chunk Chunk // buffer current write metric for compress
 
@@ -103,7 +127,8 @@ func newChannel(
 	dirPath := path.Join(cfg.Dir, database, strconv.Itoa(int(shardID)))
 	interval := cfg.RemoveTaskInterval.Duration()
 
-	q, err := newFanOutQueue(dirPath, cfg.GetDataSizeLimit(), interval)
+	q, err := newFanOutQueue(dirPath, cfg.GetDataSizeLimit(), interval,
+		queue.WithSegmentSize(int(cfg.SegmentSize)), queue.WithRetention(cfg.Retention.Duration()))
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +145,7 @@ func newChannel(
 		shardID:            shardID,
 		q:                  q,
 		ch:                 make(chan []byte, 2),
+		flushRequest:       make(chan chan struct{}),
 		chunk:              newChunk(bufferSize),
 		lastFlushTime:      time.Now(),
 		checkFlushInterval: cfg.CheckFlushInterval.Duration(),
@@ -171,6 +197,22 @@ func (c *channel) GetOrCreateReplicator(target models.Node) (Replicator, error)
 	return rep, nil
 }
 
+// RemoveReplicator stops and removes the replicator for target if exist.
+// Concurrent safe.
+func (c *channel) RemoveReplicator(target models.Node) {
+	c.lock4map.Lock()
+	defer c.lock4map.Unlock()
+
+	val, ok := c.replicatorMap.Load(target)
+	if !ok {
+		return
+	}
+	val.(Replicator).Stop()
+	c.replicatorMap.Delete(target)
+	c.logger.Info("remove replicator", logger.String("database", c.database),
+		logger.Int32("shardID", c.shardID), logger.String("target", target.Indicator()))
+}
+
 // Nodes returns all the nodes for replication.
 func (c *channel) Targets() []models.Node {
 	nodes := make([]models.Node, 0)
@@ -182,6 +224,38 @@ func (c *channel) Targets() []models.Node {
 	return nodes
 }
 
+// Topology returns the channel topology, includes buffer backlog, wal sequence and replicator state.
+func (c *channel) Topology() models.ShardChannelTopology {
+	c.lock4write.Lock()
+	buffered := c.chunk.Size()
+	c.lock4write.Unlock()
+
+	topology := models.ShardChannelTopology{
+		ShardID:         c.shardID,
+		BufferedMetrics: buffered,
+		AppendSeq:       c.q.HeadSeq() - 1,
+		QueueDepth:      c.queueDepth(),
+		QueueDiskSize:   c.q.DiskSize(),
+		ReplaySeq:       c.q.ReplaySeq(),
+	}
+	c.replicatorMap.Range(func(key, value interface{}) bool {
+		rep, _ := value.(Replicator)
+		topology.Replicas = append(topology.Replicas, models.ReplicaState{
+			Database:     c.database,
+			ShardID:      c.shardID,
+			Target:       rep.Target(),
+			Pending:      rep.Pending(),
+			ReplicaIndex: rep.ReplicaIndex(),
+			AckIndex:     rep.AckIndex(),
+		})
+		return true
+	})
+	sort.Slice(topology.Replicas, func(i, j int) bool {
+		return (&topology.Replicas[i].Target).Indicator() < (&topology.Replicas[j].Target).Indicator()
+	})
+	return topology
+}
+
 // Write writes the data into the channel, ErrCanceled is returned when the ctx is canceled before
 // data is wrote successfully.
 // Concurrent safe.
@@ -209,6 +283,28 @@ func (c *channel) Write(metric *protoMetricsV1.Metric) error {
 	return nil
 }
 
+// WriteBatch writes the metric list into the channel as one chunk, ErrCanceled is returned when the ctx
+// is canceled before data is wrote successfully.
+// Concurrent safe.
+func (c *channel) WriteBatch(metricList *protoMetricsV1.MetricList) error {
+	c.lock4write.Lock()
+	defer c.lock4write.Unlock()
+
+	data, err := c.chunk.MarshalBatch(metricList)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	select {
+	case c.ch <- data:
+		return nil
+	case <-c.ctx.Done():
+		return ErrCanceled
+	}
+}
+
 // initAppendTask starts a goroutine to consume data from ch and batch append to q.
 func (c *channel) initAppendTask() {
 	go func() {
@@ -284,13 +380,85 @@ func (c *channel) writeWAL() {
 			if err != nil {
 				c.logger.Error("append to queue err", logger.Error(err))
 			}
+		case done := <-c.flushRequest:
+			c.flushPending()
+			close(done)
 		case <-ticker.C:
 			// check
 			c.checkFlush()
+			c.syncQueue()
+		}
+	}
+}
+
+// flushPending appends the data pending in ch and chunk into queue.
+func (c *channel) flushPending() {
+	for {
+		select {
+		case data := <-c.ch:
+			if err := c.q.Put(data); err != nil {
+				c.logger.Error("append to queue err", logger.Error(err))
+			}
+		default:
+			c.lock4write.Lock()
+			if !c.chunk.IsEmpty() {
+				c.flushChunk()
+			}
+			c.lock4write.Unlock()
+			return
 		}
 	}
 }
 
+// Drain appends the buffered data into queue, then waits until all data in queue is acked by replicators,
+// returns error if ctx is done before all data acked.
+func (c *channel) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case c.flushRequest <- done:
+	case <-c.ctx.Done():
+		return ErrCanceled
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+	case <-c.ctx.Done():
+		return ErrCanceled
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(c.checkFlushInterval)
+	defer ticker.Stop()
+	// queue tail seq is advanced by write wal routine after replicators acked
+	for c.queueDepth() > 0 {
+		select {
+		case <-ticker.C:
+		case <-c.ctx.Done():
+			return ErrCanceled
+		case <-ctx.Done():
+			return fmt.Errorf("drain channel of database: %s, shard: %d, pending: %d, error: %w",
+				c.database, c.shardID, c.queueDepth(), ctx.Err())
+		}
+	}
+	return nil
+}
+
+// syncQueue advances the ack seq of queue by all replicators, so that acked segments can be removed,
+// then records the queue depth.
+func (c *channel) syncQueue() {
+	c.q.Sync()
+	shardID := strconv.Itoa(int(c.shardID))
+	queueDepthVec.WithTagValues(c.database, shardID).Update(float64(c.queueDepth()))
+	queueDiskSizeVec.WithTagValues(c.database, shardID).Update(float64(c.q.DiskSize()))
+}
+
+// queueDepth returns the num. of messages in queue which are not acked by all replicators.
+func (c *channel) queueDepth() int64 {
+	return c.q.HeadSeq() - 1 - c.q.TailSeq()
+}
+
 // watchClose waits on the context done then close the ch.
 func (c *channel) watchClose() {
 	go func() {
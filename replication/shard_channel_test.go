@@ -19,12 +19,14 @@ package replication
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/queue"
@@ -41,7 +43,7 @@ func TestChannel_New(t *testing.T) {
 	defer func() {
 		newFanOutQueue = queue.NewFanOutQueue
 	}()
-	newFanOutQueue = func(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration) (queue.FanOutQueue, error) {
+	newFanOutQueue = func(dirPath string, dataSizeLimit int64, removeTaskInterval time.Duration, _ ...queue.Option) (queue.FanOutQueue, error) {
 		return nil, fmt.Errorf("err")
 	}
 	ch, err = newChannel(context.TODO(), replicationConfig, "database", 1, nil)
@@ -69,6 +71,14 @@ func TestChannel_GetOrCreateReplicator(t *testing.T) {
 	assert.Len(t, ch.Targets(), 1)
 	assert.Equal(t, target, ch.Targets()[0])
 
+	// remove replicator, then re-create it
+	ch.RemoveReplicator(target)
+	assert.Empty(t, ch.Targets())
+	ch.RemoveReplicator(target)
+	r2, err = ch.GetOrCreateReplicator(target)
+	assert.NoError(t, err)
+	assert.True(t, r != r2)
+
 	ch1 := ch.(*channel)
 	fanout := queue.NewMockFanOutQueue(ctrl)
 	fanout.EXPECT().GetOrCreateFanOut(gomock.Any()).Return(nil, fmt.Errorf("err"))
@@ -80,6 +90,51 @@ func TestChannel_GetOrCreateReplicator(t *testing.T) {
 	time.Sleep(300 * time.Millisecond)
 }
 
+func TestChannel_Topology(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ch, err := newChannel(context.TODO(), replicationConfig, "database", 1, nil)
+	assert.NoError(t, err)
+	ch1 := ch.(*channel)
+	q := queue.NewMockFanOutQueue(ctrl)
+	ch1.q = q
+	ch1.chunk.Append(&protoMetricsV1.Metric{Name: "cpu"})
+	r1 := NewMockReplicator(ctrl)
+	r2 := NewMockReplicator(ctrl)
+	target1 := models.Node{IP: "1.1.1.1", Port: 12345}
+	target2 := models.Node{IP: "2.2.2.2", Port: 12345}
+	ch1.replicatorMap.Store(target2, r2)
+	ch1.replicatorMap.Store(target1, r1)
+
+	q.EXPECT().HeadSeq().Return(int64(11)).Times(2)
+	q.EXPECT().TailSeq().Return(int64(5))
+	q.EXPECT().DiskSize().Return(int64(1024))
+	q.EXPECT().ReplaySeq().Return(int64(2))
+	r1.EXPECT().Target().Return(target1)
+	r1.EXPECT().Pending().Return(int64(1))
+	r1.EXPECT().ReplicaIndex().Return(int64(10))
+	r1.EXPECT().AckIndex().Return(int64(9))
+	r2.EXPECT().Target().Return(target2)
+	r2.EXPECT().Pending().Return(int64(0))
+	r2.EXPECT().ReplicaIndex().Return(int64(11))
+	r2.EXPECT().AckIndex().Return(int64(10))
+
+	topology := ch.Topology()
+	assert.Equal(t, models.ShardChannelTopology{
+		ShardID:         1,
+		BufferedMetrics: 1,
+		AppendSeq:       10,
+		QueueDepth:      5,
+		QueueDiskSize:   1024,
+		ReplaySeq:       2,
+		Replicas: []models.ReplicaState{
+			{Database: "database", ShardID: 1, Target: target1, Pending: 1, ReplicaIndex: 10, AckIndex: 9},
+			{Database: "database", ShardID: 1, Target: target2, Pending: 0, ReplicaIndex: 11, AckIndex: 10},
+		},
+	}, topology)
+}
+
 func TestChannel_Write(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -125,6 +180,46 @@ func TestChannel_Write(t *testing.T) {
 	time.Sleep(time.Millisecond * 500)
 }
 
+func TestChannel_WriteBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ch, err := newChannel(ctx, replicationConfig, "database", 1, nil)
+	assert.NoError(t, err)
+	ch1 := ch.(*channel)
+
+	metric := &protoMetricsV1.Metric{
+		Name:      "cpu",
+		Timestamp: timeutil.Now(),
+		SimpleFields: []*protoMetricsV1.SimpleField{
+			{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 1}},
+	}
+	// empty batch
+	err = ch.WriteBatch(nil)
+	assert.NoError(t, err)
+	assert.Len(t, ch1.ch, 0)
+	// one chunk per batch
+	err = ch.WriteBatch(&protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{metric, metric, metric}})
+	assert.NoError(t, err)
+	assert.Len(t, ch1.ch, 1)
+	assert.True(t, ch1.chunk.IsEmpty())
+
+	chunk := NewMockChunk(ctrl)
+	ch1.chunk = chunk
+	chunk.EXPECT().MarshalBatch(gomock.Any()).Return(nil, fmt.Errorf("err"))
+	err = ch.WriteBatch(&protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{metric}})
+	assert.Error(t, err)
+
+	// make sure chan is full, then canceled
+	ch1.ch <- []byte{1, 2}
+	chunk.EXPECT().MarshalBatch(gomock.Any()).Return([]byte{1, 2, 3}, nil)
+	cancel()
+	err = ch.WriteBatch(&protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{metric}})
+	assert.Equal(t, ErrCanceled, err)
+}
+
 func TestChannel_checkFlush(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -137,6 +232,10 @@ func TestChannel_checkFlush(t *testing.T) {
 	ch1 := ch.(*channel)
 	fanout := queue.NewMockFanOutQueue(ctrl)
 	fanout.EXPECT().Put(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
+	fanout.EXPECT().Sync().AnyTimes()
+	fanout.EXPECT().HeadSeq().Return(int64(0)).AnyTimes()
+	fanout.EXPECT().TailSeq().Return(int64(-1)).AnyTimes()
+	fanout.EXPECT().DiskSize().Return(int64(0)).AnyTimes()
 	ch1.q = fanout
 
 	metric := &protoMetricsV1.Metric{
@@ -172,6 +271,10 @@ func TestChannel_write_pending_before_close(t *testing.T) {
 	ch1.ch <- []byte{1, 2, 3}
 	fanOut := queue.NewMockFanOutQueue(ctrl)
 	fanOut.EXPECT().Put(gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
+	fanOut.EXPECT().Sync().AnyTimes()
+	fanOut.EXPECT().HeadSeq().Return(int64(0)).AnyTimes()
+	fanOut.EXPECT().TailSeq().Return(int64(-1)).AnyTimes()
+	fanOut.EXPECT().DiskSize().Return(int64(0)).AnyTimes()
 	ch1.q = fanOut
 	ch1.writePendingBeforeClose()
 }
@@ -209,3 +312,45 @@ func TestChannel_chunk_marshal_err(t *testing.T) {
 	chunk.EXPECT().MarshalBinary().Return(nil, nil)
 	ch1.flushChunk()
 }
+
+func TestChannel_Drain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	ch, err := newChannel(ctx, replicationConfig, "database", 1, nil)
+	assert.NoError(t, err)
+	ch1 := ch.(*channel)
+	fanout := queue.NewMockFanOutQueue(ctrl)
+	tailSeq := atomic.NewInt64(-1)
+	fanout.EXPECT().Put(gomock.Any()).Return(nil).AnyTimes()
+	fanout.EXPECT().Sync().AnyTimes()
+	fanout.EXPECT().HeadSeq().Return(int64(1)).AnyTimes()
+	fanout.EXPECT().TailSeq().DoAndReturn(tailSeq.Load).AnyTimes()
+	fanout.EXPECT().DiskSize().Return(int64(0)).AnyTimes()
+	ch1.q = fanout
+	ch.Startup()
+
+	metric := &protoMetricsV1.Metric{
+		Name:      "cpu",
+		Timestamp: timeutil.Now(),
+		SimpleFields: []*protoMetricsV1.SimpleField{
+			{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 1}},
+	}
+	assert.NoError(t, ch.Write(metric))
+	// case 1: pending data not acked
+	drainCtx, drainCancel := context.WithTimeout(context.TODO(), 300*time.Millisecond)
+	err = ch.Drain(drainCtx)
+	drainCancel()
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, ch1.chunk.IsEmpty())
+	// case 2: all data acked
+	time.AfterFunc(200*time.Millisecond, func() {
+		tailSeq.Store(0)
+	})
+	assert.NoError(t, ch.Drain(context.TODO()))
+	// case 3: channel closed
+	cancel()
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, ErrCanceled, ch.Drain(context.TODO()))
+}
@@ -0,0 +1,240 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/logger"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+//go:generate mockgen -source=./subscription.go -destination=./subscription_mock.go -package=replication
+
+var (
+	// ErrSubscriptionDisabled is the error returned when subscribing if max subscribers is 0.
+	ErrSubscriptionDisabled = errors.New("subscription is disabled")
+	// ErrTooManySubscribers is the error returned when num. of subscribers exceeds the limit.
+	ErrTooManySubscribers = errors.New("too many subscribers")
+)
+
+var (
+	subscriptionScope       = linmetric.NewScope("lindb.broker.subscription")
+	activeSubscribersGauge  = subscriptionScope.NewGauge("active_subscribers")
+	publishedMetricsCounter = subscriptionScope.NewDeltaCounter("published_metrics")
+	droppedMetricsCounter   = subscriptionScope.NewDeltaCounter("dropped_metrics")
+	publishFailuresCounter  = subscriptionScope.NewDeltaCounter("publish_failures")
+)
+
+// Subscriber represents an external consumer which receives the written metrics matching its filter.
+type Subscriber struct {
+	Database     string
+	MetricPrefix string // empty means all metrics of database
+
+	ch      chan []byte // marshaled MetricList
+	dropped atomic.Int64
+}
+
+// C returns the channel of marshaled MetricList matching the filter,
+// channel is closed after unsubscribed.
+func (s *Subscriber) C() <-chan []byte {
+	return s.ch
+}
+
+// Dropped returns num. of metrics dropped since last call because subscriber is too slow.
+func (s *Subscriber) Dropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// filter returns the metrics whose name starts with metric prefix, returns nil if no metric matched.
+func (s *Subscriber) filter(metricList *protoMetricsV1.MetricList) *protoMetricsV1.MetricList {
+	if s.MetricPrefix == "" {
+		return metricList
+	}
+	var metrics []*protoMetricsV1.Metric
+	for _, metric := range metricList.Metrics {
+		if strings.HasPrefix(metric.Name, s.MetricPrefix) {
+			metrics = append(metrics, metric)
+		}
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+	return &protoMetricsV1.MetricList{Metrics: metrics}
+}
+
+// subscriptionBatch represents the marshaled metrics pushed to subscribers.
+type subscriptionBatch struct {
+	data         []byte
+	numOfMetrics int
+}
+
+// Subscriptions manages the subscribers, publishes written metrics to subscribers in near real time.
+type Subscriptions interface {
+	// Subscribe registers a subscriber which receives written metrics of database with metric prefix.
+	Subscribe(database, metricPrefix string) (*Subscriber, error)
+	// Unsubscribe removes the subscriber and closes its channel.
+	Unsubscribe(subscriber *Subscriber)
+	// Publish pushes written metrics to matched subscribers without blocking write path,
+	// metrics are dropped if buffer of subscriber is full.
+	Publish(database string, metricList *protoMetricsV1.MetricList)
+	// Close removes all subscribers.
+	Close()
+}
+
+// subscriptions implements Subscriptions.
+type subscriptions struct {
+	cfg config.Subscription
+
+	subscribers map[string][]*Subscriber // database => subscribers
+	count       atomic.Int32             // for publishing without lock if no subscriber
+
+	mutex  sync.RWMutex
+	logger *logger.Logger
+}
+
+// NewSubscriptions creates the subscriptions.
+func NewSubscriptions(cfg config.Subscription) Subscriptions {
+	return &subscriptions{
+		cfg:         cfg,
+		subscribers: make(map[string][]*Subscriber),
+		logger:      logger.GetLogger("replication", "Subscriptions"),
+	}
+}
+
+// Subscribe registers a subscriber which receives written metrics of database with metric prefix.
+func (s *subscriptions) Subscribe(database, metricPrefix string) (*Subscriber, error) {
+	if s.cfg.MaxSubscribers <= 0 {
+		return nil, ErrSubscriptionDisabled
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if int(s.count.Load()) >= s.cfg.MaxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+	bufferSize := s.cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	subscriber := &Subscriber{
+		Database:     database,
+		MetricPrefix: metricPrefix,
+		ch:           make(chan []byte, bufferSize),
+	}
+	s.subscribers[database] = append(s.subscribers[database], subscriber)
+	s.count.Inc()
+	activeSubscribersGauge.Incr()
+	s.logger.Info("add subscriber",
+		logger.String("database", database), logger.String("metricPrefix", metricPrefix))
+	return subscriber, nil
+}
+
+// Unsubscribe removes the subscriber and closes its channel.
+func (s *subscriptions) Unsubscribe(subscriber *Subscriber) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.unsubscribe(subscriber)
+}
+
+// unsubscribe removes the subscriber, must be called with lock.
+func (s *subscriptions) unsubscribe(subscriber *Subscriber) {
+	subscribers := s.subscribers[subscriber.Database]
+	for idx := range subscribers {
+		if subscribers[idx] != subscriber {
+			continue
+		}
+		subscribers = append(subscribers[:idx:idx], subscribers[idx+1:]...)
+		if len(subscribers) == 0 {
+			delete(s.subscribers, subscriber.Database)
+		} else {
+			s.subscribers[subscriber.Database] = subscribers
+		}
+		// publish sends data with read lock, so it's safe to close channel with write lock
+		close(subscriber.ch)
+		s.count.Dec()
+		activeSubscribersGauge.Decr()
+		s.logger.Info("remove subscriber",
+			logger.String("database", subscriber.Database), logger.String("metricPrefix", subscriber.MetricPrefix))
+		return
+	}
+}
+
+// Publish pushes written metrics to matched subscribers without blocking write path,
+// metrics are dropped if buffer of subscriber is full.
+func (s *subscriptions) Publish(database string, metricList *protoMetricsV1.MetricList) {
+	if s.count.Load() == 0 || metricList == nil {
+		return
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	// metrics maybe reused after written, so marshal them when publishing,
+	// subscribers with the same metric prefix share the marshaled data.
+	batches := make(map[string]subscriptionBatch)
+	for _, subscriber := range s.subscribers[database] {
+		batch, ok := batches[subscriber.MetricPrefix]
+		if !ok {
+			batch = s.marshal(subscriber.filter(metricList))
+			batches[subscriber.MetricPrefix] = batch
+		}
+		if batch.numOfMetrics == 0 {
+			continue
+		}
+		select {
+		case subscriber.ch <- batch.data:
+			publishedMetricsCounter.Add(float64(batch.numOfMetrics))
+		default:
+			subscriber.dropped.Add(int64(batch.numOfMetrics))
+			droppedMetricsCounter.Add(float64(batch.numOfMetrics))
+		}
+	}
+}
+
+// marshal marshals the matched metrics, returns empty batch if no metric matched or marshal failure.
+func (s *subscriptions) marshal(metricList *protoMetricsV1.MetricList) subscriptionBatch {
+	if metricList == nil || len(metricList.Metrics) == 0 {
+		return subscriptionBatch{}
+	}
+	data, err := metricList.Marshal()
+	if err != nil {
+		publishFailuresCounter.Incr()
+		s.logger.Warn("marshal metrics for subscriber failure", logger.Error(err))
+		return subscriptionBatch{}
+	}
+	return subscriptionBatch{data: data, numOfMetrics: len(metricList.Metrics)}
+}
+
+// Close removes all subscribers.
+func (s *subscriptions) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, subscribers := range s.subscribers {
+		for _, subscriber := range subscribers {
+			s.unsubscribe(subscriber)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func TestSubscriptions_Subscribe(t *testing.T) {
+	s := NewSubscriptions(config.Subscription{})
+	_, err := s.Subscribe("db", "")
+	assert.Equal(t, ErrSubscriptionDisabled, err)
+
+	s = NewSubscriptions(config.Subscription{MaxSubscribers: 2})
+	s1, err := s.Subscribe("db", "")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultBufferSize, cap(s1.ch))
+	s2, err := s.Subscribe("db", "cpu")
+	assert.NoError(t, err)
+	_, err = s.Subscribe("db2", "")
+	assert.Equal(t, ErrTooManySubscribers, err)
+
+	s.Unsubscribe(s1)
+	_, ok := <-s1.C()
+	assert.False(t, ok)
+	// unsubscribe twice
+	s.Unsubscribe(s1)
+	_, err = s.Subscribe("db2", "")
+	assert.NoError(t, err)
+
+	s.Close()
+	_, ok = <-s2.C()
+	assert.False(t, ok)
+	assert.Empty(t, s.(*subscriptions).subscribers)
+	assert.Equal(t, int32(0), s.(*subscriptions).count.Load())
+}
+
+func TestSubscriptions_Publish(t *testing.T) {
+	s := NewSubscriptions(config.Subscription{MaxSubscribers: 10, BufferSize: 1})
+	defer s.Close()
+	newMetricList := func() *protoMetricsV1.MetricList {
+		return &protoMetricsV1.MetricList{Metrics: []*protoMetricsV1.Metric{
+			{Name: "cpu.load", Timestamp: 1}, {Name: "memory.used", Timestamp: 2}, {Name: "cpu.usage", Timestamp: 3},
+		}}
+	}
+	// no subscriber
+	s.Publish("db", newMetricList())
+
+	all, err := s.Subscribe("db", "")
+	assert.NoError(t, err)
+	cpu1, err := s.Subscribe("db", "cpu")
+	assert.NoError(t, err)
+	cpu2, err := s.Subscribe("db", "cpu")
+	assert.NoError(t, err)
+	disk, err := s.Subscribe("db", "disk")
+	assert.NoError(t, err)
+	other, err := s.Subscribe("other-db", "")
+	assert.NoError(t, err)
+
+	s.Publish("db", nil)
+	s.Publish("db", newMetricList())
+
+	received := func(subscriber *Subscriber) *protoMetricsV1.MetricList {
+		metricList := &protoMetricsV1.MetricList{}
+		assert.NoError(t, metricList.Unmarshal(<-subscriber.C()))
+		return metricList
+	}
+	assert.Len(t, received(all).Metrics, 3)
+	metricList := received(cpu1)
+	assert.Len(t, metricList.Metrics, 2)
+	assert.Equal(t, "cpu.load", metricList.Metrics[0].Name)
+	assert.Equal(t, "cpu.usage", metricList.Metrics[1].Name)
+	assert.Len(t, received(cpu2).Metrics, 2)
+	assert.Len(t, disk.C(), 0)
+	assert.Len(t, other.C(), 0)
+
+	// buffer is full, metrics dropped
+	s.Publish("db", newMetricList())
+	s.Publish("db", newMetricList())
+	assert.Equal(t, int64(3), all.Dropped())
+	assert.Equal(t, int64(0), all.Dropped())
+	assert.Equal(t, int64(2), cpu1.Dropped())
+	assert.Len(t, received(all).Metrics, 3)
+}
@@ -0,0 +1,287 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/lindb/lindb/internal/linmetric"
+)
+
+// defines the compression names for grpc stream,
+// the name is passed to server side by grpc-encoding metadata,
+// server side will compress the responses with the same compressor.
+const (
+	CompressionNone   = "none"
+	CompressionGzip   = "gzip"
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+func init() {
+	encoding.RegisterCompressor(newCompressor(CompressionGzip, &gzipCodec{}))
+	encoding.RegisterCompressor(newCompressor(CompressionSnappy, &snappyCodec{}))
+	encoding.RegisterCompressor(newCompressor(CompressionZstd, &zstdCodec{}))
+}
+
+// ValidateCompression checks if the compression is supported.
+func ValidateCompression(compression string) error {
+	switch compression {
+	case "", CompressionNone, CompressionGzip, CompressionSnappy, CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("unsupported rpc compression: %s", compression)
+	}
+}
+
+// compressionCallOptions returns the call options for creating grpc stream with given compression.
+func compressionCallOptions(compression string) []grpc.CallOption {
+	if compression == "" || compression == CompressionNone {
+		return nil
+	}
+	return []grpc.CallOption{grpc.UseCompressor(compression)}
+}
+
+// codec represents the underlying compress algorithm.
+type codec interface {
+	// compress returns a writer which compresses the data into w.
+	compress(w io.Writer) (io.WriteCloser, error)
+	// decompress returns a reader which decompresses the data from r.
+	decompress(r io.Reader) (io.Reader, error)
+}
+
+// compressor implements encoding.Compressor, records the bytes before/after compressing.
+type compressor struct {
+	name  string
+	codec codec
+
+	rawBytes        atomic.Int64
+	compressedBytes atomic.Int64
+
+	compressRawBytes   *linmetric.BoundDeltaCounter
+	compressBytes      *linmetric.BoundDeltaCounter
+	decompressRawBytes *linmetric.BoundDeltaCounter
+	decompressBytes    *linmetric.BoundDeltaCounter
+	compressRatio      *linmetric.BoundGauge
+}
+
+// newCompressor creates a grpc compressor with metric.
+func newCompressor(name string, codec codec) *compressor {
+	scope := linmetric.NewScope("lindb.rpc.compression", "compressor", name)
+	return &compressor{
+		name:               name,
+		codec:              codec,
+		compressRawBytes:   scope.NewDeltaCounter("compress_raw_bytes"),
+		compressBytes:      scope.NewDeltaCounter("compress_bytes"),
+		decompressRawBytes: scope.NewDeltaCounter("decompress_raw_bytes"),
+		decompressBytes:    scope.NewDeltaCounter("decompress_bytes"),
+		compressRatio:      scope.NewGauge("compress_ratio"),
+	}
+}
+
+// Name returns the compression name.
+func (c *compressor) Name() string {
+	return c.name
+}
+
+// Compress returns a writer which compresses the data into w.
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	out := &countingWriter{w: w}
+	cw, err := c.codec.compress(out)
+	if err != nil {
+		return nil, err
+	}
+	return &compressWriter{c: c, w: cw, out: out}, nil
+}
+
+// Decompress returns a reader which decompresses the data from r.
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	in := &countingReader{r: r}
+	dr, err := c.codec.decompress(in)
+	if err != nil {
+		return nil, err
+	}
+	return &decompressReader{c: c, r: dr, in: in}, nil
+}
+
+// recordCompress records the bytes of compressing one message.
+func (c *compressor) recordCompress(raw, compressed int64) {
+	c.compressRawBytes.Add(float64(raw))
+	c.compressBytes.Add(float64(compressed))
+	totalRaw := c.rawBytes.Add(raw)
+	totalCompressed := c.compressedBytes.Add(compressed)
+	if totalCompressed > 0 {
+		c.compressRatio.Update(float64(totalRaw) / float64(totalCompressed))
+	}
+}
+
+// compressWriter counts the raw bytes written, records metric when closing.
+type compressWriter struct {
+	c   *compressor
+	w   io.WriteCloser
+	out *countingWriter
+	raw int64
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.raw += int64(n)
+	return n, err
+}
+
+func (w *compressWriter) Close() error {
+	if err := w.w.Close(); err != nil {
+		return err
+	}
+	w.c.recordCompress(w.raw, w.out.n)
+	return nil
+}
+
+// decompressReader counts the raw bytes read, records metric when reaching EOF.
+type decompressReader struct {
+	c   *compressor
+	r   io.Reader
+	in  *countingReader
+	raw int64
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.raw += int64(n)
+	if err == io.EOF {
+		r.c.decompressRawBytes.Add(float64(r.raw))
+		r.c.decompressBytes.Add(float64(r.in.n))
+	}
+	return n, err
+}
+
+// countingWriter counts the bytes written into underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// countingReader counts the bytes read from underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// gzipCodec implements codec with gzip, reuses writers by pool.
+type gzipCodec struct {
+	writers sync.Pool
+}
+
+func (c *gzipCodec) compress(w io.Writer) (io.WriteCloser, error) {
+	gw, ok := c.writers.Get().(*gzip.Writer)
+	if !ok {
+		gw = gzip.NewWriter(w)
+	} else {
+		gw.Reset(w)
+	}
+	return &pooledWriter{WriteCloser: gw, release: func() { c.writers.Put(gw) }}, nil
+}
+
+func (c *gzipCodec) decompress(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// snappyCodec implements codec with snappy framing format.
+type snappyCodec struct{}
+
+func (c *snappyCodec) compress(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+func (c *snappyCodec) decompress(r io.Reader) (io.Reader, error) {
+	return snappy.NewReader(r), nil
+}
+
+// zstdCodec implements codec with zstd, reuses encoders/decoders by pool.
+type zstdCodec struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func (c *zstdCodec) compress(w io.Writer) (io.WriteCloser, error) {
+	enc, ok := c.encoders.Get().(*zstd.Encoder)
+	if !ok {
+		var err error
+		enc, err = zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		enc.Reset(w)
+	}
+	return &pooledWriter{WriteCloser: enc, release: func() { c.encoders.Put(enc) }}, nil
+}
+
+func (c *zstdCodec) decompress(r io.Reader) (io.Reader, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec, ok := c.decoders.Get().(*zstd.Decoder)
+	if !ok {
+		dec, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer c.decoders.Put(dec)
+	data, err = dec.DecodeAll(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// pooledWriter puts the writer back to pool after closing.
+type pooledWriter struct {
+	io.WriteCloser
+	release func()
+}
+
+func (w *pooledWriter) Close() error {
+	defer w.release()
+	return w.WriteCloser.Close()
+}
@@ -0,0 +1,97 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestValidateCompression(t *testing.T) {
+	for _, name := range []string{"", CompressionNone, CompressionGzip, CompressionSnappy, CompressionZstd} {
+		assert.NoError(t, ValidateCompression(name))
+	}
+	assert.Error(t, ValidateCompression("lz4"))
+}
+
+func TestCompressionCallOptions(t *testing.T) {
+	assert.Empty(t, compressionCallOptions(""))
+	assert.Empty(t, compressionCallOptions(CompressionNone))
+	assert.Len(t, compressionCallOptions(CompressionZstd), 1)
+}
+
+func TestCompressor(t *testing.T) {
+	data := bytes.Repeat([]byte("cpu,host=1.1.1.1 load=1"), 1000)
+	for _, name := range []string{CompressionGzip, CompressionSnappy, CompressionZstd} {
+		c := encoding.GetCompressor(name)
+		assert.NotNil(t, c)
+		assert.Equal(t, name, c.Name())
+		// compress twice for reusing pooled writer
+		for i := 0; i < 2; i++ {
+			buf := &bytes.Buffer{}
+			w, err := c.Compress(buf)
+			assert.NoError(t, err)
+			_, err = w.Write(data)
+			assert.NoError(t, err)
+			assert.NoError(t, w.Close())
+			assert.True(t, buf.Len() < len(data))
+
+			r, err := c.Decompress(bytes.NewReader(buf.Bytes()))
+			assert.NoError(t, err)
+			result, err := ioutil.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, data, result)
+		}
+		cc := c.(*compressor)
+		assert.True(t, cc.compressRatio.Get() > 1)
+	}
+}
+
+func TestCompressor_err(t *testing.T) {
+	c := encoding.GetCompressor(CompressionZstd)
+	_, err := c.Decompress(bytes.NewReader([]byte("bad data")))
+	assert.Error(t, err)
+	_, err = c.Decompress(&errReader{})
+	assert.Error(t, err)
+
+	c = encoding.GetCompressor(CompressionGzip)
+	_, err = c.Decompress(bytes.NewReader([]byte("bad data")))
+	assert.Error(t, err)
+	w, err := c.Compress(&errWriter{})
+	assert.NoError(t, err)
+	_, _ = w.Write([]byte("data"))
+	assert.Error(t, w.Close())
+}
+
+type errReader struct{}
+
+func (r *errReader) Read(_ []byte) (int, error) {
+	return 0, fmt.Errorf("err")
+}
+
+type errWriter struct{}
+
+func (w *errWriter) Write(_ []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
@@ -22,12 +22,18 @@ import (
 	"errors"
 	"strconv"
 	"sync"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/encoding"
+	protoBrokerV1 "github.com/lindb/lindb/proto/gen/v1/broker"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
 	protoReplicaV1 "github.com/lindb/lindb/proto/gen/v1/replica"
 	protoStorageV1 "github.com/lindb/lindb/proto/gen/v1/storage"
@@ -44,30 +50,67 @@ const (
 	metaKeyReplica   = "metaKeyReplica"
 )
 
+const (
+	// defaultConnPoolSize is the num. of connections for each target node
+	defaultConnPoolSize = 2
+	// keepalive pings are sent in interval if no activity on connection,
+	// connection is closed if ping ack not received within timeout.
+	keepaliveTime    = 10 * time.Second
+	keepaliveTimeout = 3 * time.Second
+)
+
 var (
 	clientConnFct ClientConnFactory
 )
 
+var (
+	clientConnScope       = linmetric.NewScope("lindb.rpc.client.conn")
+	createdConnsCounter   = clientConnScope.NewDeltaCounter("created_conns")
+	dialFailuresCounter   = clientConnScope.NewDeltaCounter("dial_failures")
+	unhealthyConnsCounter = clientConnScope.NewDeltaCounter("unhealthy_conns")
+	evictedConnsCounter   = clientConnScope.NewDeltaCounter("evicted_conns")
+	activeConnsGauge      = clientConnScope.NewGauge("active_conns")
+)
+
 func init() {
-	clientConnFct = &clientConnFactory{
-		connMap: make(map[models.Node]*grpc.ClientConn),
-	}
+	clientConnFct = newClientConnFactory(defaultConnPoolSize)
 }
 
 // ClientConnFactory is the factory for grpc ClientConn.
 type ClientConnFactory interface {
-	// GetClientConn returns the grpc ClientConn for target node.
-	// One connection for a target node.
+	// GetClientConn returns a healthy grpc ClientConn for target node,
+	// connections of a target node are pooled and picked in round-robin.
 	// Concurrent safe.
 	GetClientConn(target models.Node) (*grpc.ClientConn, error)
+	// EvictClientConn closes all pooled connections for target node,
+	// new connections will be created when getting connection next time.
+	EvictClientConn(target models.Node)
+}
+
+// connPool holds the connections for a target node.
+type connPool struct {
+	conns []*grpc.ClientConn
+	next  int // index of connection picked next time
 }
 
 // clientConnFactory implements ClientConnFactory.
 type clientConnFactory struct {
-	// target -> connection
-	connMap map[models.Node]*grpc.ClientConn
+	poolSize int
+	// target -> connection pool
+	connMap map[models.Node]*connPool
 	// lock to protect connMap
 	lock4map sync.Mutex
+
+	dialFunc func(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
+}
+
+// newClientConnFactory creates a ClientConnFactory with pool size of each target node.
+func newClientConnFactory(poolSize int) *clientConnFactory {
+	return &clientConnFactory{
+		poolSize: poolSize,
+		connMap:  make(map[models.Node]*connPool),
+		dialFunc: grpc.Dial,
+	}
 }
 
 // GetClientConnFactory returns a singleton ClientConnFactory.
@@ -75,24 +118,91 @@ func GetClientConnFactory() ClientConnFactory {
 	return clientConnFct
 }
 
-// GetClientConn returns the grpc ClientConn for a target node.
+// GetClientConn returns a healthy grpc ClientConn for a target node,
+// shutdown connection is re-created, connection in transient failure is skipped
+// if there is other healthy connection in pool.
 // Concurrent safe.
 func (fct *clientConnFactory) GetClientConn(target models.Node) (*grpc.ClientConn, error) {
 	fct.lock4map.Lock()
 	defer fct.lock4map.Unlock()
 
-	coon, ok := fct.connMap[target]
-	if ok {
-		return coon, nil
+	pool, ok := fct.connMap[target]
+	if !ok {
+		pool = &connPool{conns: make([]*grpc.ClientConn, fct.poolSize)}
+		fct.connMap[target] = pool
+	}
+	var candidate = -1
+	for i := 0; i < fct.poolSize; i++ {
+		idx := (pool.next + i) % fct.poolSize
+		conn := pool.conns[idx]
+		if conn != nil && conn.GetState() == connectivity.Shutdown {
+			activeConnsGauge.Decr()
+			conn = nil
+		}
+		if conn == nil {
+			newConn, err := fct.dial(target)
+			if err != nil {
+				return nil, err
+			}
+			pool.conns[idx] = newConn
+			conn = newConn
+		}
+		if conn.GetState() == connectivity.TransientFailure {
+			// grpc reconnects in background, try other connection
+			unhealthyConnsCounter.Incr()
+			if candidate < 0 {
+				candidate = idx
+			}
+			continue
+		}
+		candidate = idx
+		break
 	}
-	conn, err := grpc.Dial(target.Indicator(), grpc.WithInsecure())
+	pool.next = (candidate + 1) % fct.poolSize
+	return pool.conns[candidate], nil
+}
+
+// dial creates a connection with keepalive and reconnect backoff for target node.
+func (fct *clientConnFactory) dial(target models.Node) (*grpc.ClientConn, error) {
+	conn, err := fct.dialFunc(target.Indicator(),
+		grpc.WithInsecure(),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime,
+			Timeout:             keepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: 3 * time.Second,
+		}),
+	)
 	if err != nil {
+		dialFailuresCounter.Incr()
 		return nil, err
 	}
+	createdConnsCounter.Incr()
+	activeConnsGauge.Incr()
+	return conn, nil
+}
 
-	fct.connMap[target] = conn
+// EvictClientConn closes all pooled connections for target node.
+func (fct *clientConnFactory) EvictClientConn(target models.Node) {
+	fct.lock4map.Lock()
+	pool, ok := fct.connMap[target]
+	delete(fct.connMap, target)
+	fct.lock4map.Unlock()
 
-	return conn, nil
+	if !ok {
+		return
+	}
+	for _, conn := range pool.conns {
+		if conn == nil {
+			continue
+		}
+		evictedConnsCounter.Incr()
+		activeConnsGauge.Decr()
+		_ = conn.Close()
+	}
 }
 
 // ClientStreamFactory is the factory to get ClientStream.
@@ -107,19 +217,26 @@ type ClientStreamFactory interface {
 	CreateWriteServiceClient(target models.Node) (protoStorageV1.WriteServiceClient, error)
 	// CreateReplicaServiceClient creates a protoReplicaV1.ReplicaServiceClient.
 	CreateReplicaServiceClient(target models.Node) (protoReplicaV1.ReplicaServiceClient, error)
+	// CreateQueryServiceClient creates a protoBrokerV1.QueryServiceClient for querying remote cluster.
+	CreateQueryServiceClient(target models.Node) (protoBrokerV1.QueryServiceClient, error)
+	// CreateBrokerWriteClient creates a stream write client for writing metrics to broker of remote cluster.
+	CreateBrokerWriteClient(target models.Node) (protoBrokerV1.BrokerService_WriteClient, error)
 }
 
 // clientStreamFactory implements ClientStreamFactory.
 type clientStreamFactory struct {
-	logicNode models.Node
-	connFct   ClientConnFactory
+	logicNode   models.Node
+	connFct     ClientConnFactory
+	compression string
 }
 
-// NewClientStreamFactory returns a factory to get clientStream.
-func NewClientStreamFactory(logicNode models.Node) ClientStreamFactory {
+// NewClientStreamFactory returns a factory to get clientStream,
+// compression is used for compressing the stream data(none/gzip/snappy/zstd).
+func NewClientStreamFactory(logicNode models.Node, compression string) ClientStreamFactory {
 	return &clientStreamFactory{
-		logicNode: logicNode,
-		connFct:   GetClientConnFactory(),
+		logicNode:   logicNode,
+		connFct:     GetClientConnFactory(),
+		compression: compression,
 	}
 }
 
@@ -138,7 +255,7 @@ func (w *clientStreamFactory) CreateTaskClient(target models.Node) (protoCommonV
 	node := w.LogicNode()
 	//TODO handle context?????
 	ctx := createOutgoingContextWithPairs(context.TODO(), metaKeyLogicNode, (&node).Indicator())
-	cli, err := protoCommonV1.NewTaskServiceClient(conn).Handle(ctx)
+	cli, err := protoCommonV1.NewTaskServiceClient(conn).Handle(ctx, compressionCallOptions(w.compression)...)
 	return cli, err
 }
 
@@ -152,7 +269,7 @@ func (w *clientStreamFactory) CreateWriteClient(db string, shardID int32,
 
 	// pass logicNode.ID as meta to rpc serve
 	ctx := createOutgoingContext(context.TODO(), db, shardID, w.LogicNode())
-	cli, err := protoStorageV1.NewWriteServiceClient(conn).Write(ctx)
+	cli, err := protoStorageV1.NewWriteServiceClient(conn).Write(ctx, compressionCallOptions(w.compression)...)
 
 	return cli, err
 }
@@ -175,6 +292,26 @@ func (w *clientStreamFactory) CreateReplicaServiceClient(target models.Node) (pr
 	return protoReplicaV1.NewReplicaServiceClient(conn), nil
 }
 
+// CreateQueryServiceClient creates a protoBrokerV1.QueryServiceClient.
+func (w *clientStreamFactory) CreateQueryServiceClient(target models.Node) (protoBrokerV1.QueryServiceClient, error) {
+	conn, err := w.connFct.GetClientConn(target)
+	if err != nil {
+		return nil, err
+	}
+	return protoBrokerV1.NewQueryServiceClient(conn), nil
+}
+
+// CreateBrokerWriteClient creates a protoBrokerV1.BrokerService_WriteClient.
+func (w *clientStreamFactory) CreateBrokerWriteClient(target models.Node) (protoBrokerV1.BrokerService_WriteClient, error) {
+	conn, err := w.connFct.GetClientConn(target)
+	if err != nil {
+		return nil, err
+	}
+	node := w.LogicNode()
+	ctx := createOutgoingContextWithPairs(context.TODO(), metaKeyLogicNode, (&node).Indicator())
+	return protoBrokerV1.NewBrokerServiceClient(conn).Write(ctx, compressionCallOptions(w.compression)...)
+}
+
 // createOutgoingContextWithPairs creates outGoing context with key, value pairs.
 func createOutgoingContextWithPairs(ctx context.Context, pairs ...string) context.Context {
 	return metadata.NewOutgoingContext(ctx, metadata.Pairs(pairs...))
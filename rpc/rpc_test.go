@@ -19,11 +19,14 @@ package rpc
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 
 	"github.com/lindb/lindb/models"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
@@ -49,13 +52,18 @@ func TestClientConnFactory(t *testing.T) {
 		Port: 456,
 	}
 
-	fct := GetClientConnFactory()
+	fct := newClientConnFactory(2)
 
 	conn1, err := fct.GetClientConn(node1)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	conn12, err := fct.GetClientConn(node1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	conn11, err := fct.GetClientConn(node1)
 	if err != nil {
 		t.Fatal(err)
@@ -66,8 +74,32 @@ func TestClientConnFactory(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// connections are picked in round-robin
 	assert.True(t, conn1 == conn11)
+	assert.False(t, conn1 == conn12)
 	assert.False(t, conn1 == conn2)
+
+	// evict connections, then re-create
+	fct.EvictClientConn(node1)
+	fct.EvictClientConn(node1)
+	assert.Equal(t, connectivity.Shutdown, conn1.GetState())
+	conn13, err := fct.GetClientConn(node1)
+	assert.NoError(t, err)
+	assert.False(t, conn1 == conn13)
+
+	// shutdown connection is re-created
+	_ = conn2.Close()
+	conn21, err := fct.GetClientConn(node2)
+	assert.NoError(t, err)
+	assert.False(t, conn2 == conn21)
+
+	// dial failure
+	fct.dialFunc = func(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		return nil, fmt.Errorf("err")
+	}
+	_, err = fct.GetClientConn(models.Node{IP: "1.1.1.1", Port: 789})
+	assert.Error(t, err)
+	assert.NotNil(t, GetClientConnFactory())
 }
 
 func TestContext(t *testing.T) {
@@ -102,9 +134,14 @@ func TestClientStreamFactory(t *testing.T) {
 		IP:   "127.0.0.1",
 		Port: 1234,
 	}
-	fct := NewClientStreamFactory(node)
+	fct := NewClientStreamFactory(node, CompressionNone)
 	_, err := fct.CreateWriteServiceClient(target)
 	assert.Nil(t, err)
+	_, err = fct.CreateQueryServiceClient(target)
+	assert.Nil(t, err)
+	// no server listening on target
+	_, err = fct.CreateBrokerWriteClient(target)
+	assert.Error(t, err)
 
 	assert.Equal(t, fct.LogicNode(), node)
 
@@ -113,11 +150,12 @@ func TestClientStreamFactory(t *testing.T) {
 
 func TestClientStreamFactory_CreateTaskClient(t *testing.T) {
 	ctrl := gomock.NewController(t)
-	go ctrl.Finish()
+	defer ctrl.Finish()
 
 	handler := protoCommonV1.NewMockTaskServiceServer(ctrl)
+	handler.EXPECT().Handle(gomock.Any()).Return(nil).AnyTimes()
 
-	factory := NewClientStreamFactory(models.Node{IP: "127.0.0.2", Port: 9000})
+	factory := NewClientStreamFactory(models.Node{IP: "127.0.0.2", Port: 9000}, CompressionSnappy)
 	target := models.Node{IP: "127.0.0.1", Port: 9000}
 
 	client, err := factory.CreateTaskClient(target)
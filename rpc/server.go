@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/lindb/lindb/pkg/logger"
 )
@@ -50,6 +51,11 @@ func NewGRPCServer(bindAddress string) GRPCServer {
 		gs: grpc.NewServer(
 			grpc.ConnectionTimeout(time.Second*3),
 			grpc.MaxConcurrentStreams(30),
+			// allow keepalive pings sent by client connections, see ClientConnFactory
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             keepaliveTime / 2,
+				PermitWithoutStream: true,
+			}),
 		),
 	}
 }
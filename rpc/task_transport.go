@@ -25,6 +25,7 @@ import (
 	"go.uber.org/atomic"
 	"google.golang.org/grpc"
 
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/logger"
 	protoCommonV1 "github.com/lindb/lindb/proto/gen/v1/common"
@@ -34,11 +35,28 @@ import (
 
 var log = logger.GetLogger("rpc", "TaskClient")
 
+const (
+	// reconnect backoff of task client stream, doubled after each failure
+	minReconnectBackoff = 100 * time.Millisecond
+	maxReconnectBackoff = 10 * time.Second
+	// connections of target node are evicted after num. of consecutive reconnect failures
+	maxReconnectFailures = 5
+)
+
+var (
+	taskClientScope          = linmetric.NewScope("lindb.rpc.client.task_stream")
+	readyStreamsGauge        = taskClientScope.NewGauge("ready_streams")
+	reconnectsCounter        = taskClientScope.NewDeltaCounter("reconnects")
+	reconnectFailuresCounter = taskClientScope.NewDeltaCounter("reconnect_failures")
+	brokenStreamsCounter     = taskClientScope.NewDeltaCounter("broken_streams")
+)
+
 // TaskClientFactory represents the task stream manage
 type TaskClientFactory interface {
 	// CreateTaskClient creates a task client stream if not exist
 	CreateTaskClient(target models.Node) error
-	// GetTaskClient returns the task client stream by target node
+	// GetTaskClient returns the ready task client stream by target node,
+	// returns nil if stream is broken and reconnecting.
 	GetTaskClient(target string) protoCommonV1.TaskService_HandleClient
 	// CloseTaskClient closes the task client stream for target node
 	CloseTaskClient(targetNodeID string) (closed bool, err error)
@@ -64,15 +82,22 @@ type taskClientFactory struct {
 
 	newTaskServiceClientFunc func(cc *grpc.ClientConn) protoCommonV1.TaskServiceClient
 	connFct                  ClientConnFactory
+	compression              string
+	minBackoff               time.Duration
+	maxBackoff               time.Duration
 }
 
-// NewTaskClientFactory creates a task client factory
-func NewTaskClientFactory(currentNode models.Node) TaskClientFactory {
+// NewTaskClientFactory creates a task client factory,
+// compression is used for compressing the task stream(none/gzip/snappy/zstd).
+func NewTaskClientFactory(currentNode models.Node, compression string) TaskClientFactory {
 	return &taskClientFactory{
 		currentNode:              currentNode,
+		compression:              compression,
 		connFct:                  GetClientConnFactory(),
 		taskStreams:              make(map[string]*taskClient),
 		newTaskServiceClientFunc: protoCommonV1.NewTaskServiceClient,
+		minBackoff:               minReconnectBackoff,
+		maxBackoff:               maxReconnectBackoff,
 	}
 }
 
@@ -87,7 +112,7 @@ func (f *taskClientFactory) GetTaskClient(target string) protoCommonV1.TaskServi
 	defer f.mutex.RUnlock()
 
 	stream, ok := f.taskStreams[target]
-	if ok && stream != nil {
+	if ok && stream != nil && stream.ready.Load() {
 		return stream.cli
 	}
 	return nil
@@ -150,7 +175,7 @@ func (f *taskClientFactory) initTaskClient(client *taskClient) error {
 
 	//TODO handle context?????
 	ctx := createOutgoingContextWithPairs(context.TODO(), metaKeyLogicNode, (&f.currentNode).Indicator())
-	cli, err := f.newTaskServiceClientFunc(conn).Handle(ctx)
+	cli, err := f.newTaskServiceClientFunc(conn).Handle(ctx, compressionCallOptions(f.compression)...)
 	if err != nil {
 		return err
 	}
@@ -158,9 +183,15 @@ func (f *taskClientFactory) initTaskClient(client *taskClient) error {
 	return nil
 }
 
-// handleTaskResponse handles task response loop, if stream closed exist loop
+// handleTaskResponse handles task response loop, if stream closed exist loop.
+// Broken stream is re-created with exponential backoff, connections of target node are evicted
+// after consecutive reconnect failures, so that a new connection is dialed next time.
 func (f *taskClientFactory) handleTaskResponse(client *taskClient) {
-	var sequence int32 = 0
+	var (
+		sequence int32 = 0
+		failures       = 0
+		backoff        = f.minBackoff
+	)
 	for client.running.Load() {
 		if !client.ready.Load() {
 			sequence++
@@ -169,24 +200,47 @@ func (f *taskClientFactory) handleTaskResponse(client *taskClient) {
 				logger.Int32("sequence", sequence),
 			)
 			if err := f.initTaskClient(client); err != nil {
+				reconnectFailuresCounter.Incr()
+				failures++
 				log.Error("failed to initialize task client",
 					logger.Error(err),
 					logger.String("target", client.targetID),
 					logger.Int32("sequence", sequence),
+					logger.String("backoff", backoff.String()),
 				)
-				time.Sleep(time.Second)
+				if failures >= maxReconnectFailures {
+					log.Warn("evict connections of target node after reconnect failures",
+						logger.String("target", client.targetID))
+					f.connFct.EvictClientConn(client.target)
+					failures = 0
+				}
+				time.Sleep(backoff)
+				backoff *= 2
+				if backoff > f.maxBackoff {
+					backoff = f.maxBackoff
+				}
 				continue
 			} else {
 				log.Info("initialized task client successfully",
 					logger.String("target", client.targetID),
 					logger.Int32("sequence", sequence))
+				if sequence > 1 {
+					reconnectsCounter.Incr()
+				}
+				failures = 0
+				backoff = f.minBackoff
 				client.ready.Store(true)
+				readyStreamsGauge.Incr()
 			}
 		}
 		resp, err := client.cli.Recv()
 		if err != nil {
-			client.ready.Store(false)
-			log.Error("receive task error from stream", logger.Error(err))
+			if client.ready.CAS(true, false) {
+				readyStreamsGauge.Decr()
+			}
+			brokenStreamsCounter.Incr()
+			log.Error("receive task error from stream",
+				logger.String("target", client.targetID), logger.Error(err))
 			continue
 		}
 
@@ -198,6 +252,9 @@ func (f *taskClientFactory) handleTaskResponse(client *taskClient) {
 				logger.Error(err))
 		}
 	}
+	if client.ready.CAS(true, false) {
+		readyStreamsGauge.Decr()
+	}
 }
 
 // TaskServerFactory represents a factory to get server stream.
@@ -20,6 +20,7 @@ package rpc
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -72,7 +73,7 @@ func TestTaskClientFactory(t *testing.T) {
 	mockTaskClient.EXPECT().CloseSend().Return(fmt.Errorf("err")).AnyTimes()
 	taskService := protoCommonV1.NewMockTaskServiceClient(ctl)
 
-	fct := NewTaskClientFactory(models.Node{IP: "127.0.0.1", Port: 123})
+	fct := NewTaskClientFactory(models.Node{IP: "127.0.0.1", Port: 123}, CompressionNone)
 	receiver := NewMockTaskReceiver(ctl)
 	receiver.EXPECT().Receive(gomock.Any(), gomock.Any()).Return(fmt.Errorf("err")).AnyTimes()
 	fct.SetTaskReceiver(receiver)
@@ -90,6 +91,8 @@ func TestTaskClientFactory(t *testing.T) {
 	assert.NoError(t, err)
 	tc := fct1.taskStreams[(&target).Indicator()]
 	tc.running.Store(false)
+	// wait response handle loop exit
+	time.Sleep(10 * time.Millisecond)
 	fct1.mutex.Lock()
 	tc.cli = mockTaskClient
 	fct1.mutex.Unlock()
@@ -99,7 +102,12 @@ func TestTaskClientFactory(t *testing.T) {
 	err = fct.CreateTaskClient(target)
 	assert.NoError(t, err)
 
+	// stream not ready
+	tc.ready.Store(false)
 	cli := fct.GetTaskClient((&target).Indicator())
+	assert.Nil(t, cli)
+	tc.ready.Store(true)
+	cli = fct.GetTaskClient((&target).Indicator())
 	assert.NotNil(t, cli)
 
 	cli = fct.GetTaskClient((&models.Node{IP: "", Port: testGRPCPort}).Indicator())
@@ -121,7 +129,7 @@ func TestTaskClientFactory_handler(t *testing.T) {
 	}()
 
 	receiver := NewMockTaskReceiver(ctrl)
-	fct := NewTaskClientFactory(models.Node{IP: "127.0.0.1", Port: 123})
+	fct := NewTaskClientFactory(models.Node{IP: "127.0.0.1", Port: 123}, CompressionNone)
 	fct.SetTaskReceiver(receiver)
 
 	target := models.Node{IP: "127.0.0.1", Port: 321}
@@ -161,3 +169,51 @@ func TestTaskClientFactory_handler(t *testing.T) {
 	)
 	factory.handleTaskResponse(taskClient)
 }
+
+func TestTaskClientFactory_reconnect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver := NewMockTaskReceiver(ctrl)
+	fct := NewTaskClientFactory(models.Node{IP: "127.0.0.1", Port: 123}, CompressionNone)
+	fct.SetTaskReceiver(receiver)
+
+	target := models.Node{IP: "127.0.0.1", Port: 321}
+	conn, _ := grpc.Dial(target.Indicator(), grpc.WithInsecure())
+	mockClientConnFct := NewMockClientConnFactory(ctrl)
+	mockTaskClient := protoCommonV1.NewMockTaskService_HandleClient(ctrl)
+	taskService := protoCommonV1.NewMockTaskServiceClient(ctrl)
+
+	factory := fct.(*taskClientFactory)
+	factory.newTaskServiceClientFunc = func(cc *grpc.ClientConn) protoCommonV1.TaskServiceClient {
+		return taskService
+	}
+	factory.connFct = mockClientConnFct
+	factory.minBackoff = time.Millisecond
+	factory.maxBackoff = 2 * time.Millisecond
+	taskClient := &taskClient{
+		targetID: "test",
+		target:   target,
+	}
+	taskClient.running.Store(true)
+	gomock.InOrder(
+		// evict connections after consecutive failures
+		mockClientConnFct.EXPECT().GetClientConn(target).Return(nil, fmt.Errorf("err")).Times(maxReconnectFailures),
+		mockClientConnFct.EXPECT().EvictClientConn(target),
+		mockClientConnFct.EXPECT().GetClientConn(target).Return(conn, nil),
+		taskService.EXPECT().Handle(gomock.Any(), gomock.Any()).Return(mockTaskClient, nil),
+		mockTaskClient.EXPECT().Recv().DoAndReturn(func() (*protoCommonV1.TaskResponse, error) {
+			assert.NotNil(t, fct.GetTaskClient("test"))
+			return &protoCommonV1.TaskResponse{}, nil
+		}),
+		receiver.EXPECT().Receive(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(req *protoCommonV1.TaskResponse, targetID string) error {
+				taskClient.running.Store(false)
+				return nil
+			}),
+	)
+	factory.taskStreams["test"] = taskClient
+	factory.handleTaskResponse(taskClient)
+	assert.False(t, taskClient.ready.Load())
+	assert.Nil(t, fct.GetTaskClient("test"))
+}
@@ -27,6 +27,10 @@ var ErrTooManyTags = errors.New("too many tags")
 // writes exceed the max limit of tag keys.
 var ErrTooManyTagKeys = errors.New("too many tag keys")
 
+// ErrTooManySeries is the error returned by tsdb when
+// writes exceed the max limit of series under metric.
+var ErrTooManySeries = errors.New("too many series")
+
 // ErrTooManyFields is the error returned by tsdb when
 // writes exceed the max limit of fields.
 var ErrTooManyFields = errors.New("too many fields")
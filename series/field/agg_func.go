@@ -17,14 +17,18 @@
 
 package field
 
-import "math"
+import (
+	"math"
+	"math/bits"
+)
 
 var (
-	sumAggregator       = sumAgg{aggType: Sum}
-	countAggregator     = sumAgg{aggType: Count}
-	minAggregator       = minAgg{aggType: Min}
-	maxAggregator       = maxAgg{aggType: Max}
-	lastValueAggregator = lastValueAgg{aggType: LastValue}
+	sumAggregator        = sumAgg{aggType: Sum}
+	countAggregator      = sumAgg{aggType: Count}
+	minAggregator        = minAgg{aggType: Min}
+	maxAggregator        = maxAgg{aggType: Max}
+	lastValueAggregator  = lastValueAgg{aggType: LastValue}
+	firstValueAggregator = firstValueAgg{aggType: FirstValue}
 )
 
 // AggFunc returns aggregator function by given func type
@@ -40,6 +44,8 @@ func (t AggType) AggFunc() AggFunc {
 		return maxAggregator
 	case LastValue:
 		return lastValueAggregator
+	case FirstValue:
+		return firstValueAggregator
 	default:
 		return nil
 	}
@@ -49,6 +55,14 @@ func (t AggType) AggFunc() AggFunc {
 type AggFunc interface {
 	// Aggregate aggregates two float64 values into one
 	Aggregate(a, b float64) float64
+	// AggregateInt aggregates two int64 values into one, keeps the precision of integers above 2^53
+	AggregateInt(a, b int64) int64
+	// Identity returns the value used for filling empty slot of page,
+	// Aggregate(v, Identity()) always returns v except last value aggregator.
+	Identity() float64
+	// AggregatePage aggregates src page into dst page slot by slot, dst[i] = Aggregate(dst[i], src[i]),
+	// empty slots of src must be filled with Identity(), marks is the bitmap of slots which src has value.
+	AggregatePage(dst, src []float64, marks []uint64)
 	// AggType return aggregator type
 	AggType() AggType
 }
@@ -60,6 +74,15 @@ type sumAgg struct {
 
 func (s sumAgg) AggType() AggType               { return s.aggType }
 func (s sumAgg) Aggregate(a, b float64) float64 { return a + b }
+func (s sumAgg) AggregateInt(a, b int64) int64  { return a + b }
+func (s sumAgg) Identity() float64              { return 0 }
+
+func (s sumAgg) AggregatePage(dst, src []float64, _ []uint64) {
+	dst = dst[:len(src)]
+	for i, v := range src {
+		dst[i] += v
+	}
+}
 
 // minAgg represents min aggregator
 type minAgg struct {
@@ -68,6 +91,23 @@ type minAgg struct {
 
 func (m minAgg) AggType() AggType               { return m.aggType }
 func (m minAgg) Aggregate(a, b float64) float64 { return math.Min(a, b) }
+func (m minAgg) Identity() float64              { return math.Inf(1) }
+
+func (m minAgg) AggregateInt(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (m minAgg) AggregatePage(dst, src []float64, _ []uint64) {
+	dst = dst[:len(src)]
+	for i, v := range src {
+		if v < dst[i] {
+			dst[i] = v
+		}
+	}
+}
 
 // maxAgg represents max aggregator
 type maxAgg struct {
@@ -76,6 +116,23 @@ type maxAgg struct {
 
 func (m maxAgg) AggType() AggType               { return m.aggType }
 func (m maxAgg) Aggregate(a, b float64) float64 { return math.Max(a, b) }
+func (m maxAgg) Identity() float64              { return math.Inf(-1) }
+
+func (m maxAgg) AggregateInt(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (m maxAgg) AggregatePage(dst, src []float64, _ []uint64) {
+	dst = dst[:len(src)]
+	for i, v := range src {
+		if v > dst[i] {
+			dst[i] = v
+		}
+	}
+}
 
 // lastValueAgg represents last value aggregator
 type lastValueAgg struct {
@@ -84,3 +141,41 @@ type lastValueAgg struct {
 
 func (m lastValueAgg) AggType() AggType               { return m.aggType }
 func (m lastValueAgg) Aggregate(_, b float64) float64 { return b }
+func (m lastValueAgg) AggregateInt(_, b int64) int64  { return b }
+func (m lastValueAgg) Identity() float64              { return 0 }
+
+// AggregatePage copies the slots which src has value, empty slots cannot be skipped by identity.
+func (m lastValueAgg) AggregatePage(dst, src []float64, marks []uint64) {
+	dst = dst[:len(src)]
+	for w, mark := range marks {
+		for mark != 0 {
+			i := w<<6 + bits.TrailingZeros64(mark)
+			dst[i] = src[i]
+			mark &= mark - 1
+		}
+	}
+}
+
+// firstValueAgg represents first value aggregator, uses NaN as identity for marking empty slot.
+type firstValueAgg struct {
+	aggType AggType
+}
+
+func (m firstValueAgg) AggType() AggType               { return m.aggType }
+func (m firstValueAgg) Aggregate(a, _ float64) float64 { return a }
+func (m firstValueAgg) AggregateInt(a, _ int64) int64  { return a }
+func (m firstValueAgg) Identity() float64              { return math.NaN() }
+
+// AggregatePage copies the slots which src has value and dst is still empty(identity).
+func (m firstValueAgg) AggregatePage(dst, src []float64, marks []uint64) {
+	dst = dst[:len(src)]
+	for w, mark := range marks {
+		for mark != 0 {
+			i := w<<6 + bits.TrailingZeros64(mark)
+			if math.IsNaN(dst[i]) {
+				dst[i] = src[i]
+			}
+			mark &= mark - 1
+		}
+	}
+}
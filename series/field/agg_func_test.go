@@ -18,6 +18,7 @@
 package field
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,6 +30,7 @@ func TestGetAggFunc(t *testing.T) {
 	assert.NotNil(t, Max.AggFunc())
 	assert.NotNil(t, Count.AggFunc())
 	assert.NotNil(t, LastValue.AggFunc())
+	assert.NotNil(t, FirstValue.AggFunc())
 	assert.Nil(t, AggType(99).AggFunc())
 }
 
@@ -57,3 +59,43 @@ func TestReplaceAgg(t *testing.T) {
 	assert.Equal(t, LastValue, agg.AggType())
 	assert.Equal(t, 99.0, agg.Aggregate(1, 99.0))
 }
+
+func TestFirstValueAgg(t *testing.T) {
+	agg := FirstValue.AggFunc()
+	assert.Equal(t, FirstValue, agg.AggType())
+	assert.Equal(t, 1.0, agg.Aggregate(1, 99.0))
+	assert.True(t, math.IsNaN(agg.Identity()))
+
+	dst := []float64{agg.Identity(), 2, agg.Identity()}
+	agg.AggregatePage(dst, []float64{1, 3, agg.Identity()}, []uint64{0x3})
+	assert.Equal(t, []float64{1, 2}, dst[:2])
+	assert.True(t, math.IsNaN(dst[2]))
+}
+
+func TestAggFunc_AggregatePage(t *testing.T) {
+	// slot 1 of src is empty
+	marks := []uint64{0x5}
+	cases := []struct {
+		aggType AggType
+		expect  []float64
+	}{
+		{aggType: Sum, expect: []float64{3, 2, 7}},
+		{aggType: Count, expect: []float64{3, 2, 7}},
+		{aggType: Min, expect: []float64{1, 2, 3}},
+		{aggType: Max, expect: []float64{2, 2, 4}},
+		{aggType: LastValue, expect: []float64{1, 2, 4}},
+		{aggType: FirstValue, expect: []float64{2, 2, 3}},
+	}
+	for _, c := range cases {
+		agg := c.aggType.AggFunc()
+		dst := []float64{2, 2, 3}
+		src := []float64{1, agg.Identity(), 4}
+		agg.AggregatePage(dst, src, marks)
+		assert.Equal(t, c.expect, dst, c.aggType)
+		for _, v := range dst {
+			if agg.AggType() != LastValue {
+				assert.Equal(t, v, agg.Aggregate(v, agg.Identity()))
+			}
+		}
+	}
+}
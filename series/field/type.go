@@ -41,8 +41,71 @@ const (
 	Min
 	Max
 	LastValue
+	FirstValue
 )
 
+// String returns the name of aggregator type.
+func (t AggType) String() string {
+	switch t {
+	case Sum:
+		return "sum"
+	case Count:
+		return "count"
+	case Min:
+		return "min"
+	case Max:
+		return "max"
+	case LastValue:
+		return "last"
+	case FirstValue:
+		return "first"
+	default:
+		return "unknown"
+	}
+}
+
+// ValueType represents the type of field value written by user.
+type ValueType uint8
+
+// Defines all value types of field, int/bool values are stored natively without float64 conversion.
+const (
+	FloatValue ValueType = iota
+	IntValue
+	BoolValue
+)
+
+// String returns the name of value type.
+func (t ValueType) String() string {
+	switch t {
+	case FloatValue:
+		return "float"
+	case IntValue:
+		return "int"
+	case BoolValue:
+		return "bool"
+	default:
+		return "unknown"
+	}
+}
+
+// Merge returns the value type which can hold the values of both types,
+// float wins over int, int wins over bool(value types are ordered by it).
+func (t ValueType) Merge(other ValueType) ValueType {
+	if other < t {
+		return other
+	}
+	return t
+}
+
+// Aggregated returns the value type after values are aggregated by given aggregator,
+// booleans summed/counted become integers.
+func (t ValueType) Aggregated(aggType AggType) ValueType {
+	if t == BoolValue && (aggType == Sum || aggType == Count) {
+		return IntValue
+	}
+	return t
+}
+
 // Type represents field type for LinDB support
 type Type uint8
 
@@ -54,6 +117,10 @@ const (
 	MaxField
 	GaugeField
 	HistogramField // alias for sumField, only visible for tsdb
+	FirstField     // keeps the first value of time slot
+	LastField      // keeps the last value of time slot
+	CountField     // sums the count of time slot
+	StringField    // keeps the last string value id of time slot, value resolved by string dictionary
 )
 
 // String returns the field type's string value
@@ -69,6 +136,14 @@ func (t Type) String() string {
 		return "gauge"
 	case HistogramField:
 		return "histogram"
+	case FirstField:
+		return "first"
+	case LastField:
+		return "last"
+	case CountField:
+		return "count"
+	case StringField:
+		return "string"
 	default:
 		return "unknown"
 	}
@@ -77,12 +152,16 @@ func (t Type) String() string {
 // GetAggFunc returns the aggregate function
 func (t Type) GetAggFunc() AggFunc {
 	switch t {
-	case SumField, HistogramField:
+	case SumField, HistogramField, CountField:
 		return sumAggregator
 	case MinField:
 		return minAggregator
 	case MaxField:
 		return maxAggregator
+	case FirstField:
+		return firstValueAggregator
+	case LastField, StringField:
+		return lastValueAggregator
 	default:
 		//FIXME(stone1100)
 		return maxAggregator
@@ -99,8 +178,12 @@ func (t Type) DownSamplingFunc() function.FuncType {
 		return function.Max
 	case GaugeField:
 		return function.LastValue
-	case HistogramField:
+	case HistogramField, CountField:
 		return function.Sum
+	case FirstField:
+		return function.FirstValue
+	case LastField, StringField:
+		return function.LastValue
 	default:
 		return function.Unknown
 	}
@@ -143,6 +226,30 @@ func (t Type) IsFuncSupported(funcType function.FuncType) bool {
 		default:
 			return false
 		}
+	case FirstField:
+		switch funcType {
+		case function.FirstValue, function.Min, function.Max:
+			return true
+		default:
+			return false
+		}
+	case LastField:
+		switch funcType {
+		case function.LastValue, function.Min, function.Max:
+			return true
+		default:
+			return false
+		}
+	case CountField:
+		switch funcType {
+		case function.Sum, function.Count, function.Min, function.Max:
+			return true
+		default:
+			return false
+		}
+	case StringField:
+		// string value ids are not comparable, only last value makes sense
+		return funcType == function.LastValue
 	default:
 		return false
 	}
@@ -155,11 +262,21 @@ func (t Type) GetFuncFieldParams(funcType function.FuncType) []AggType {
 		return getFieldParamsForSumField(funcType)
 	case MinField:
 		return getFieldParamsForMinField(funcType)
+	case MaxField:
+		return []AggType{Max}
 	case GaugeField:
 		return getFieldParamsForGaugeField(funcType)
 	case HistogramField:
 		// Histogram field only supports sum
 		return []AggType{Sum}
+	case FirstField:
+		return getFieldParamsForValueField(funcType, FirstValue)
+	case LastField:
+		return getFieldParamsForValueField(funcType, LastValue)
+	case CountField:
+		return getFieldParamsForCountField(funcType)
+	case StringField:
+		return []AggType{LastValue}
 	}
 	return nil
 }
@@ -171,10 +288,16 @@ func (t Type) GetDefaultFuncFieldParams() []AggType {
 		return []AggType{Sum}
 	case MinField:
 		return []AggType{Min}
+	case MaxField:
+		return []AggType{Max}
 	case GaugeField:
 		return []AggType{LastValue}
-	case HistogramField:
+	case HistogramField, CountField:
 		return []AggType{Sum}
+	case FirstField:
+		return []AggType{FirstValue}
+	case LastField, StringField:
+		return []AggType{LastValue}
 	}
 	return nil
 }
@@ -205,3 +328,26 @@ func getFieldParamsForGaugeField(funcType function.FuncType) []AggType {
 		return []AggType{LastValue}
 	}
 }
+
+// getFieldParamsForValueField returns agg type for first/last field, which keeps the value of time slot.
+func getFieldParamsForValueField(funcType function.FuncType, valueAggType AggType) []AggType {
+	switch funcType {
+	case function.Min:
+		return []AggType{Min}
+	case function.Max:
+		return []AggType{Max}
+	default:
+		return []AggType{valueAggType}
+	}
+}
+
+func getFieldParamsForCountField(funcType function.FuncType) []AggType {
+	switch funcType {
+	case function.Min:
+		return []AggType{Min}
+	case function.Max:
+		return []AggType{Max}
+	default:
+		return []AggType{Sum}
+	}
+}
@@ -30,6 +30,10 @@ func TestDownSamplingFunc(t *testing.T) {
 	assert.Equal(t, function.Min, MinField.DownSamplingFunc())
 	assert.Equal(t, function.Max, MaxField.DownSamplingFunc())
 	assert.Equal(t, function.LastValue, GaugeField.DownSamplingFunc())
+	assert.Equal(t, function.FirstValue, FirstField.DownSamplingFunc())
+	assert.Equal(t, function.LastValue, LastField.DownSamplingFunc())
+	assert.Equal(t, function.Sum, CountField.DownSamplingFunc())
+	assert.Equal(t, function.LastValue, StringField.DownSamplingFunc())
 	assert.Equal(t, function.Unknown, Unknown.DownSamplingFunc())
 }
 
@@ -38,9 +42,24 @@ func TestType_String(t *testing.T) {
 	assert.Equal(t, "max", MaxField.String())
 	assert.Equal(t, "min", MinField.String())
 	assert.Equal(t, "gauge", GaugeField.String())
+	assert.Equal(t, "histogram", HistogramField.String())
+	assert.Equal(t, "first", FirstField.String())
+	assert.Equal(t, "last", LastField.String())
+	assert.Equal(t, "count", CountField.String())
+	assert.Equal(t, "string", StringField.String())
 	assert.Equal(t, "unknown", Unknown.String())
 }
 
+func TestAggType_String(t *testing.T) {
+	assert.Equal(t, "sum", Sum.String())
+	assert.Equal(t, "count", Count.String())
+	assert.Equal(t, "min", Min.String())
+	assert.Equal(t, "max", Max.String())
+	assert.Equal(t, "last", LastValue.String())
+	assert.Equal(t, "first", FirstValue.String())
+	assert.Equal(t, "unknown", AggType(0).String())
+}
+
 func TestIsSupportFunc(t *testing.T) {
 	assert.True(t, SumField.IsFuncSupported(function.Sum))
 	assert.True(t, SumField.IsFuncSupported(function.Min))
@@ -56,6 +75,20 @@ func TestIsSupportFunc(t *testing.T) {
 	assert.True(t, MinField.IsFuncSupported(function.Min))
 	assert.False(t, MinField.IsFuncSupported(function.Quantile))
 
+	assert.True(t, FirstField.IsFuncSupported(function.FirstValue))
+	assert.True(t, FirstField.IsFuncSupported(function.Max))
+	assert.False(t, FirstField.IsFuncSupported(function.Sum))
+
+	assert.True(t, LastField.IsFuncSupported(function.LastValue))
+	assert.True(t, LastField.IsFuncSupported(function.Min))
+	assert.False(t, LastField.IsFuncSupported(function.Sum))
+
+	assert.True(t, CountField.IsFuncSupported(function.Count))
+	assert.True(t, CountField.IsFuncSupported(function.Sum))
+	assert.False(t, CountField.IsFuncSupported(function.LastValue))
+	assert.True(t, StringField.IsFuncSupported(function.LastValue))
+	assert.False(t, StringField.IsFuncSupported(function.Max))
+
 	assert.False(t, Unknown.IsFuncSupported(function.Quantile))
 }
 
@@ -64,4 +97,28 @@ func TestType_GetAggFunc(t *testing.T) {
 	assert.Equal(t, sumAggregator, SumField.GetAggFunc())
 	assert.Equal(t, minAggregator, MinField.GetAggFunc())
 	assert.Equal(t, maxAggregator, Unknown.GetAggFunc())
+	assert.Equal(t, firstValueAggregator, FirstField.GetAggFunc())
+	assert.Equal(t, lastValueAggregator, LastField.GetAggFunc())
+	assert.Equal(t, sumAggregator, CountField.GetAggFunc())
+	assert.Equal(t, lastValueAggregator, StringField.GetAggFunc())
+}
+
+func TestType_GetFuncFieldParams(t *testing.T) {
+	assert.Equal(t, []AggType{Max}, MaxField.GetFuncFieldParams(function.Max))
+	assert.Equal(t, []AggType{Max}, MaxField.GetDefaultFuncFieldParams())
+
+	assert.Equal(t, []AggType{FirstValue}, FirstField.GetFuncFieldParams(function.FirstValue))
+	assert.Equal(t, []AggType{Min}, FirstField.GetFuncFieldParams(function.Min))
+	assert.Equal(t, []AggType{FirstValue}, FirstField.GetDefaultFuncFieldParams())
+
+	assert.Equal(t, []AggType{LastValue}, LastField.GetFuncFieldParams(function.LastValue))
+	assert.Equal(t, []AggType{Max}, LastField.GetFuncFieldParams(function.Max))
+	assert.Equal(t, []AggType{LastValue}, LastField.GetDefaultFuncFieldParams())
+
+	assert.Equal(t, []AggType{Sum}, CountField.GetFuncFieldParams(function.Count))
+	assert.Equal(t, []AggType{Min}, CountField.GetFuncFieldParams(function.Min))
+	assert.Equal(t, []AggType{Max}, CountField.GetFuncFieldParams(function.Max))
+	assert.Equal(t, []AggType{Sum}, CountField.GetDefaultFuncFieldParams())
+	assert.Equal(t, []AggType{LastValue}, StringField.GetFuncFieldParams(function.Max))
+	assert.Equal(t, []AggType{LastValue}, StringField.GetDefaultFuncFieldParams())
 }
@@ -32,7 +32,13 @@ type TimeSeriesEvent struct {
 	SeriesList      GroupedIterators
 	AggregatorSpecs map[string]*protoCommonV1.AggregatorSpec
 	Stats           *models.QueryStats
-	Err             error
+	// Failures are the failed/timed out nodes if partial results are allowed
+	Failures []models.NodeFailure
+	// Exemplars are the exemplars of histogram series, tags => exemplars, only returned if requested
+	Exemplars map[string][]*protoCommonV1.Exemplar
+	// StringValues are the string values of string fields, value id => string value
+	StringValues map[uint64]string
+	Err          error
 }
 
 type GroupedIterators []GroupedIterator
@@ -18,6 +18,7 @@
 package sql
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/lindb/lindb/pkg/collections"
@@ -36,6 +37,11 @@ type baseStmtParser struct {
 
 	limit int
 
+	// rangeOps keeps the range operators of tag filter rewritten to regexp, key is operator's start offset
+	rangeOps map[int]stmt.RangeOP
+	// params are the values bound to placeholders of tag value
+	params map[string]string
+
 	err error
 }
 
@@ -86,7 +92,11 @@ func (b *baseStmtParser) visitTagValue(ctx *grammar.TagValueContext) {
 		return
 	}
 	tagFilterExpr := b.exprStack.Peek()
-	tagValue := strutil.GetStringValue(ctx.Ident().GetText())
+	tagValue, err := bindParam(b.params, ctx.Ident().GetText())
+	if err != nil {
+		b.err = err
+		return
+	}
 	switch expr := tagFilterExpr.(type) {
 	case *stmt.NotExpr:
 		b.setTagFilterExprValue(expr.Expr, tagValue)
@@ -104,6 +114,12 @@ func (b *baseStmtParser) setTagFilterExprValue(expr stmt.Expr, tagValue string)
 		e.Value = tagValue
 	case *stmt.RegexExpr:
 		e.Regexp = tagValue
+	case *stmt.RangeExpr:
+		if _, err := strconv.ParseFloat(tagValue, 64); err != nil {
+			b.err = fmt.Errorf("range filter value of tag key: %s must be numeric, value: %s", e.Key, tagValue)
+			return
+		}
+		e.Value = tagValue
 	case *stmt.InExpr:
 		e.Values = append(e.Values, tagValue)
 	}
@@ -126,7 +142,11 @@ func (b *baseStmtParser) createTagFilterExpr(tagKey grammar.ITagKeyContext,
 				expr = &stmt.LikeExpr{Key: tagKeyStr}
 			}
 		case ctx.T_REGEXP() != nil:
-			expr = &stmt.RegexExpr{Key: tagKeyStr}
+			if op, ok := b.rangeOps[ctx.T_REGEXP().GetSymbol().GetStart()]; ok {
+				expr = &stmt.RangeExpr{Key: tagKeyStr, Op: op}
+			} else {
+				expr = &stmt.RegexExpr{Key: tagKeyStr}
+			}
 		case ctx.T_NEQREGEXP() != nil:
 			expr = &stmt.NotExpr{Expr: &stmt.RegexExpr{Key: tagKeyStr}}
 		case ctx.T_NOTEQUAL() != nil || ctx.T_NOTEQUAL2() != nil:
@@ -0,0 +1,174 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+
+	"github.com/lindb/lindb/sql/grammar"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+// seriesCardinalityTagKey is the placeholder tag key of rewritten series cardinality statement.
+const seriesCardinalityTagKey = "__series_cardinality"
+
+// rewriteSeriesCardinality rewrites the series cardinality statement to show tag values statement
+// with placeholder tag key, so that on/from/where/limit clauses can be parsed by grammar,
+// returns false if sql isn't a series cardinality statement.
+//
+// show series cardinality [on <namespace>] from <metric> [where ...] [limit n]
+// =>
+// show tag values [on <namespace>] from <metric> with key=__series_cardinality [where ...] [limit n]
+func rewriteSeriesCardinality(sql string, tokens *antlr.CommonTokenStream) (string, bool) {
+	tokens.Fill()
+	var defaultTokens []antlr.Token
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		defaultTokens = append(defaultTokens, token)
+	}
+	if len(defaultTokens) < 3 || defaultTokens[0].GetTokenType() != grammar.SQLLexerT_SHOW ||
+		!isWordToken(defaultTokens[1], "series") || !isWordToken(defaultTokens[2], "cardinality") {
+		return sql, false
+	}
+	// token's offset is the index of rune
+	input := []rune(sql)
+	// with key clause is before where/limit clause
+	insertPos := len(input)
+	for _, token := range defaultTokens[3:] {
+		if token.GetTokenType() == grammar.SQLLexerT_WHERE || token.GetTokenType() == grammar.SQLLexerT_LIMIT {
+			insertPos = token.GetStart()
+			break
+		}
+	}
+	var b strings.Builder
+	b.WriteString("show tag values")
+	b.WriteString(string(input[defaultTokens[2].GetStop()+1 : insertPos]))
+	b.WriteString(" with key=" + seriesCardinalityTagKey + " ")
+	b.WriteString(string(input[insertPos:]))
+	return b.String(), true
+}
+
+// toSeriesCardinality converts the show tag values statement rewritten by rewriteSeriesCardinality
+// back to series cardinality statement.
+func toSeriesCardinality(statement stmt.Statement) {
+	if metadata, ok := statement.(*stmt.Metadata); ok {
+		metadata.Type = stmt.SeriesCardinality
+		metadata.TagKey = ""
+	}
+}
+
+// isWordToken checks if the token is the identifier with the text(case-insensitive).
+func isWordToken(token antlr.Token, text string) bool {
+	return token.GetTokenType() == grammar.SQLLexerL_ID && strings.EqualFold(token.GetText(), text)
+}
+
+// tagValueCardinality represents the options of tag value cardinality statement.
+type tagValueCardinality struct {
+	bySeries bool
+	window   int64
+}
+
+// apply converts the show tag values statement rewritten by rewriteTagValueCardinality
+// back to tag value cardinality statement.
+func (c *tagValueCardinality) apply(statement stmt.Statement) {
+	if metadata, ok := statement.(*stmt.Metadata); ok {
+		metadata.Type = stmt.TagValueCardinality
+		metadata.BySeries = c.bySeries
+		metadata.Window = c.window
+	}
+}
+
+// rewriteTagValueCardinality rewrites the tag value cardinality statement to show tag values statement
+// by removing the cardinality keyword and by/window clauses which grammar doesn't support,
+// returns false if sql isn't a tag value cardinality statement.
+//
+// show tag values cardinality [on <namespace>] from <metric> with key=<tag key> [by series|points] [window 30m] [limit n]
+// =>
+// show tag values [on <namespace>] from <metric> with key=<tag key> [limit n]
+func rewriteTagValueCardinality(sql string, tokens *antlr.CommonTokenStream) (string, *tagValueCardinality, bool, error) {
+	tokens.Fill()
+	var defaultTokens []antlr.Token
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		defaultTokens = append(defaultTokens, token)
+	}
+	if len(defaultTokens) < 4 || defaultTokens[0].GetTokenType() != grammar.SQLLexerT_SHOW ||
+		defaultTokens[1].GetTokenType() != grammar.SQLLexerT_TAG ||
+		defaultTokens[2].GetTokenType() != grammar.SQLLexerT_VALUES ||
+		!isWordToken(defaultTokens[3], "cardinality") {
+		return sql, nil, false, nil
+	}
+	option := &tagValueCardinality{}
+	// removed token ranges(index of defaultTokens)
+	removed := [][2]int{{3, 3}}
+	for idx := 4; idx < len(defaultTokens); idx++ {
+		token := defaultTokens[idx]
+		switch {
+		case token.GetTokenType() == grammar.SQLLexerT_WHERE:
+			return "", nil, true, errors.New("where clause isn't supported by tag value cardinality")
+		case token.GetTokenType() == grammar.SQLLexerT_BY:
+			if idx+1 >= len(defaultTokens) {
+				return "", nil, true, errors.New("expect series or points after by")
+			}
+			switch next := defaultTokens[idx+1]; {
+			case isWordToken(next, "series"):
+				option.bySeries = true
+			case isWordToken(next, "points"):
+				option.bySeries = false
+			default:
+				return "", nil, true, fmt.Errorf("expect series or points after by, but got '%s'", next.GetText())
+			}
+			removed = append(removed, [2]int{idx, idx + 1})
+			idx++
+		case isWordToken(token, "window"):
+			if idx+2 >= len(defaultTokens) || defaultTokens[idx+1].GetTokenType() != grammar.SQLLexerL_INT {
+				return "", nil, true, errors.New("expect duration after window, like window 30m")
+			}
+			unit, ok := durationUnits[defaultTokens[idx+2].GetTokenType()]
+			if !ok {
+				return "", nil, true, fmt.Errorf("invalid duration unit of window: %s", defaultTokens[idx+2].GetText())
+			}
+			value, err := strconv.ParseInt(defaultTokens[idx+1].GetText(), 10, 64)
+			if err != nil {
+				return "", nil, true, err
+			}
+			option.window = value * unit
+			removed = append(removed, [2]int{idx, idx + 2})
+			idx += 2
+		}
+	}
+	// token's offset is the index of rune
+	input := []rune(sql)
+	output := make([]rune, 0, len(input))
+	pos := 0
+	for _, r := range removed {
+		output = append(output, input[pos:defaultTokens[r[0]].GetStart()]...)
+		pos = defaultTokens[r[1]].GetStop() + 1
+	}
+	output = append(output, input[pos:]...)
+	return string(output), option, true, nil
+}
@@ -0,0 +1,130 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestSeriesCardinality_SQL_Parse(t *testing.T) {
+	query, err := Parse("show series cardinality from cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Metadata{
+		Namespace:  constants.DefaultNamespace,
+		MetricName: "cpu",
+		Type:       stmt.SeriesCardinality,
+		Limit:      100,
+	}, query)
+
+	query, err = Parse("SHOW SERIES Cardinality on 'ns' from 'cpu' where host='1.1.1.1' and cpu_id>=2 limit 10")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Metadata{
+		Namespace:  "ns",
+		MetricName: "cpu",
+		Type:       stmt.SeriesCardinality,
+		Condition: &stmt.BinaryExpr{
+			Left:     &stmt.EqualsExpr{Key: "host", Value: "1.1.1.1"},
+			Operator: stmt.AND,
+			Right:    &stmt.RangeExpr{Key: "cpu_id", Op: stmt.GreaterEqual, Value: "2"},
+		},
+		Limit: 10,
+	}, query)
+
+	query, err = Parse("show series cardinality from cpu limit 5")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, query.(*stmt.Metadata).Limit)
+	assert.Equal(t, stmt.SeriesCardinality, query.(*stmt.Metadata).Type)
+
+	// prepared statement can be bound repeatedly
+	prepared, err := Prepare("show series cardinality from cpu where host=$host")
+	assert.NoError(t, err)
+	query, err = prepared.Bind(&Options{Params: map[string]string{"host": "1.1.1.2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, stmt.SeriesCardinality, query.(*stmt.Metadata).Type)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "host", Value: "1.1.1.2"}, query.(*stmt.Metadata).Condition)
+}
+
+func TestSeriesCardinality_SQL_Parse_Error(t *testing.T) {
+	cases := []string{
+		"show series",
+		"show series cardinality",
+		"show series cardinality on ns",
+		"show series cardinality from cpu where",
+	}
+	for _, sql := range cases {
+		_, err := Parse(sql)
+		assert.Error(t, err, sql)
+	}
+}
+
+func TestTagValueCardinality_SQL_Parse(t *testing.T) {
+	query, err := Parse("show tag values cardinality from cpu with key=host")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Metadata{
+		Namespace:  constants.DefaultNamespace,
+		MetricName: "cpu",
+		Type:       stmt.TagValueCardinality,
+		TagKey:     "host",
+		Limit:      100,
+	}, query)
+
+	query, err = Parse("SHOW TAG VALUES Cardinality on 'ns' from 'cpu' with key='host' by series window 30m limit 10")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Metadata{
+		Namespace:  "ns",
+		MetricName: "cpu",
+		Type:       stmt.TagValueCardinality,
+		TagKey:     "host",
+		BySeries:   true,
+		Window:     30 * 60 * 1000,
+		Limit:      10,
+	}, query)
+
+	query, err = Parse("show tag values cardinality from cpu with key=host window 1h by points")
+	assert.NoError(t, err)
+	assert.False(t, query.(*stmt.Metadata).BySeries)
+	assert.Equal(t, int64(60*60*1000), query.(*stmt.Metadata).Window)
+
+	// plain show tag values isn't changed
+	query, err = Parse("show tag values from cpu with key=host")
+	assert.NoError(t, err)
+	assert.Equal(t, stmt.TagValue, query.(*stmt.Metadata).Type)
+}
+
+func TestTagValueCardinality_SQL_Parse_Error(t *testing.T) {
+	cases := []string{
+		"show tag values cardinality",
+		"show tag values cardinality from cpu",
+		"show tag values cardinality from cpu with key=host by",
+		"show tag values cardinality from cpu with key=host by host",
+		"show tag values cardinality from cpu with key=host window",
+		"show tag values cardinality from cpu with key=host window 10",
+		"show tag values cardinality from cpu with key=host window 10 limit",
+		"show tag values cardinality from cpu with key=host window 99999999999999999999m",
+		"show tag values cardinality from cpu with key=host where host='1.1.1.1'",
+	}
+	for _, sql := range cases {
+		_, err := Parse(sql)
+		assert.Error(t, err, sql)
+	}
+}
@@ -0,0 +1,69 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/sql/grammar"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+// parseDescribeStmt parses the describe metric statement by tokens directly,
+// returns false if sql isn't a describe statement.
+//
+// describe metric <metric> [on <namespace>]
+func parseDescribeStmt(tokens *antlr.CommonTokenStream) (stmt.Statement, bool, error) {
+	tokens.Fill()
+	p := &schemaStmtParser{}
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		p.tokens = append(p.tokens, token)
+	}
+	if len(p.tokens) == 0 || !isWordToken(p.tokens[0], "describe") {
+		return nil, false, nil
+	}
+	p.pos = 1
+	if err := p.expect(grammar.SQLLexerT_METRIC, "metric"); err != nil {
+		return nil, true, err
+	}
+	metricName, err := p.word("metric name")
+	if err != nil {
+		return nil, true, err
+	}
+	namespace := constants.DefaultNamespace
+	if p.pos < len(p.tokens) {
+		if err := p.expect(grammar.SQLLexerT_ON, "on"); err != nil {
+			return nil, true, err
+		}
+		if namespace, err = p.word("namespace"); err != nil {
+			return nil, true, err
+		}
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, true, err
+	}
+	return &stmt.Metadata{
+		Type:       stmt.MetricDescriptor,
+		Namespace:  namespace,
+		MetricName: metricName,
+	}, true, nil
+}
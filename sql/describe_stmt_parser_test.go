@@ -0,0 +1,58 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestDescribeStmt_Parse(t *testing.T) {
+	query, err := Parse("describe metric cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Metadata{
+		Type:       stmt.MetricDescriptor,
+		Namespace:  constants.DefaultNamespace,
+		MetricName: "cpu",
+	}, query)
+
+	query, err = Parse("DESCRIBE METRIC 'system.cpu' on 'ns'")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Metadata{
+		Type:       stmt.MetricDescriptor,
+		Namespace:  "ns",
+		MetricName: "system.cpu",
+	}, query)
+
+	cases := []string{
+		"describe",
+		"describe cpu",
+		"describe metric",
+		"describe metric cpu on",
+		"describe metric cpu from ns",
+		"describe metric cpu on ns limit",
+	}
+	for _, sql := range cases {
+		_, err = Parse(sql)
+		assert.Error(t, err, sql)
+	}
+}
@@ -0,0 +1,109 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package influxql
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lindb/lindb/models"
+)
+
+// Response represents the query response of InfluxDB http api.
+type Response struct {
+	Results []*Result `json:"results,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Result represents the result of one statement.
+type Result struct {
+	StatementID int    `json:"statement_id"`
+	Series      []*Row `json:"series,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Row represents one series of result.
+type Row struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values,omitempty"`
+}
+
+// BuildResult builds the InfluxDB result of statement based on LinQL result set,
+// epoch is the precision of timestamp(ns/u/µ/ms/s/m/h), if empty formats timestamp using RFC3339.
+func (q *Query) BuildResult(statementID int, rs *models.ResultSet, epoch string) *Result {
+	result := &Result{StatementID: statementID}
+	if rs == nil {
+		return result
+	}
+	columns := append([]string{"time"}, q.Columns...)
+	for _, series := range rs.Series {
+		row := &Row{
+			Name:    q.Measurement,
+			Tags:    series.Tags,
+			Columns: columns,
+		}
+		// collect timestamps of all fields
+		timestampSet := make(map[int64]struct{})
+		for _, points := range series.Fields {
+			for timestamp := range points {
+				timestampSet[timestamp] = struct{}{}
+			}
+		}
+		timestamps := make([]int64, 0, len(timestampSet))
+		for timestamp := range timestampSet {
+			timestamps = append(timestamps, timestamp)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		for _, timestamp := range timestamps {
+			values := make([]interface{}, len(columns))
+			values[0] = formatTimestamp(timestamp, epoch)
+			for idx, column := range q.Columns {
+				if value, ok := series.Fields[column][timestamp]; ok && !math.IsNaN(value) && !math.IsInf(value, 0) {
+					values[idx+1] = value
+				}
+			}
+			row.Values = append(row.Values, values)
+		}
+		result.Series = append(result.Series, row)
+	}
+	return result
+}
+
+// formatTimestamp formats the timestamp(millisecond) based on epoch precision.
+func formatTimestamp(timestamp int64, epoch string) interface{} {
+	switch epoch {
+	case "ns":
+		return timestamp * int64(time.Millisecond)
+	case "u", "µ":
+		return timestamp * int64(time.Millisecond/time.Microsecond)
+	case "ms":
+		return timestamp
+	case "s":
+		return timestamp / int64(time.Second/time.Millisecond)
+	case "m":
+		return timestamp / int64(time.Minute/time.Millisecond)
+	case "h":
+		return timestamp / int64(time.Hour/time.Millisecond)
+	default:
+		return time.Unix(0, timestamp*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano)
+	}
+}
@@ -0,0 +1,64 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package influxql
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+)
+
+func TestQuery_BuildResult(t *testing.T) {
+	q := &Query{Measurement: "cpu", Columns: []string{"mean", "max"}}
+	result := q.BuildResult(1, nil, "")
+	assert.Equal(t, 1, result.StatementID)
+	assert.Empty(t, result.Series)
+
+	rs := models.NewResultSet()
+	series := models.NewSeries(map[string]string{"host": "a"})
+	series.Fields["mean"] = map[int64]float64{2000: 2, 1000: 1}
+	series.Fields["max"] = map[int64]float64{1000: 10, 3000: math.NaN()}
+	rs.AddSeries(series)
+
+	result = q.BuildResult(0, rs, "ms")
+	assert.Equal(t, []*Row{{
+		Name:    "cpu",
+		Tags:    map[string]string{"host": "a"},
+		Columns: []string{"time", "mean", "max"},
+		Values: [][]interface{}{
+			{int64(1000), 1.0, 10.0},
+			{int64(2000), 2.0, nil},
+			{int64(3000), nil, nil},
+		},
+	}}, result.Series)
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	timestamp := int64(7200000)
+	assert.Equal(t, timestamp*1000000, formatTimestamp(timestamp, "ns"))
+	assert.Equal(t, timestamp*1000, formatTimestamp(timestamp, "u"))
+	assert.Equal(t, timestamp*1000, formatTimestamp(timestamp, "µ"))
+	assert.Equal(t, timestamp, formatTimestamp(timestamp, "ms"))
+	assert.Equal(t, int64(7200), formatTimestamp(timestamp, "s"))
+	assert.Equal(t, int64(120), formatTimestamp(timestamp, "m"))
+	assert.Equal(t, int64(2), formatTimestamp(timestamp, "h"))
+	assert.Equal(t, "1970-01-01T02:00:00Z", formatTimestamp(timestamp, ""))
+}
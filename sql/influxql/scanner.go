@@ -0,0 +1,147 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package influxql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenType represents the type of InfluxQL token.
+type tokenType int
+
+const (
+	tokenIdent       tokenType = iota + 1 // bare identifier or keyword
+	tokenQuotedIdent                      // double quoted identifier
+	tokenString                           // single quoted string
+	tokenRegex                            // regular expression, like /^cpu/
+	tokenNumber                           // number with optional unit suffix, like 10, 1.5, 1h, 1620000000000ms
+	tokenOperator                         // = != <> < <= > >= =~ !~ + - * /
+	tokenPunct                            // ( ) , . ;
+)
+
+// token represents a lexical token of InfluxQL.
+type token struct {
+	typ tokenType
+	val string
+}
+
+// isKeyword checks if token is the given keyword(case-insensitive).
+func (t token) isKeyword(keyword string) bool {
+	return t.typ == tokenIdent && strings.EqualFold(t.val, keyword)
+}
+
+// is checks if token is the given operator/punctuation.
+func (t token) is(val string) bool {
+	return (t.typ == tokenOperator || t.typ == tokenPunct) && t.val == val
+}
+
+// scan splits the InfluxQL into tokens.
+func scan(q string) ([]token, error) {
+	var tokens []token
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '"' || ch == '\'':
+			val, next, err := scanQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			typ := tokenString
+			if ch == '"' {
+				typ = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{typ: typ, val: val})
+			i = next
+		case ch == '/' && expectRegex(tokens):
+			val, next, err := scanQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokenRegex, val: val})
+			i = next
+		case unicode.IsDigit(ch):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// duration or precision unit
+			for i < len(runes) && (unicode.IsLetter(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, val: string(runes[start:i])})
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenIdent, val: string(runes[start:i])})
+		case strings.ContainsRune("(),.;", ch):
+			tokens = append(tokens, token{typ: tokenPunct, val: string(ch)})
+			i++
+		case strings.ContainsRune("=!<>+-*/", ch):
+			op := string(ch)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "!=", "<>", "<=", ">=", "=~", "!~":
+					op = two
+				}
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("influxql: unexpected character '!'")
+			}
+			tokens = append(tokens, token{typ: tokenOperator, val: op})
+			i += len(op)
+		default:
+			return nil, fmt.Errorf("influxql: unexpected character '%c'", ch)
+		}
+	}
+	return tokens, nil
+}
+
+// expectRegex checks if next '/' starts a regular expression based on previous token.
+func expectRegex(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	prev := tokens[len(tokens)-1]
+	return prev.is("=~") || prev.is("!~") || prev.isKeyword("from")
+}
+
+// scanQuoted scans the quoted value which starts at runes[start], returns the unquoted value and next position.
+func scanQuoted(runes []rune, start int) (val string, next int, err error) {
+	quote := runes[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == '\\' && i+1 < len(runes) && runes[i+1] == quote:
+			sb.WriteRune(quote)
+			i++
+		case ch == quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	return "", 0, fmt.Errorf("influxql: unterminated quoted value, missing %c", quote)
+}
@@ -0,0 +1,156 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package influxql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	examples := []struct {
+		q      string
+		tokens []token
+	}{
+		// empty
+		{
+			q:      "  ",
+			tokens: nil,
+		},
+		// identifiers and punctuation
+		{
+			q: "SELECT f_1, _g FROM db.rp.cpu;",
+			tokens: []token{
+				{typ: tokenIdent, val: "SELECT"}, {typ: tokenIdent, val: "f_1"}, {typ: tokenPunct, val: ","},
+				{typ: tokenIdent, val: "_g"}, {typ: tokenIdent, val: "FROM"}, {typ: tokenIdent, val: "db"},
+				{typ: tokenPunct, val: "."}, {typ: tokenIdent, val: "rp"}, {typ: tokenPunct, val: "."},
+				{typ: tokenIdent, val: "cpu"}, {typ: tokenPunct, val: ";"},
+			},
+		},
+		// quoted identifier
+		{
+			q: `"cpu load" "a\"b" "a.b"`,
+			tokens: []token{
+				{typ: tokenQuotedIdent, val: "cpu load"}, {typ: tokenQuotedIdent, val: `a"b`},
+				{typ: tokenQuotedIdent, val: "a.b"},
+			},
+		},
+		// string
+		{
+			q: `host='a b' region='it\'s' empty=''`,
+			tokens: []token{
+				{typ: tokenIdent, val: "host"}, {typ: tokenOperator, val: "="}, {typ: tokenString, val: "a b"},
+				{typ: tokenIdent, val: "region"}, {typ: tokenOperator, val: "="}, {typ: tokenString, val: "it's"},
+				{typ: tokenIdent, val: "empty"}, {typ: tokenOperator, val: "="}, {typ: tokenString, val: ""},
+			},
+		},
+		// numbers and durations
+		{
+			q: "10 1.5 1h 30m 1620000000000ms 2w",
+			tokens: []token{
+				{typ: tokenNumber, val: "10"}, {typ: tokenNumber, val: "1.5"}, {typ: tokenNumber, val: "1h"},
+				{typ: tokenNumber, val: "30m"}, {typ: tokenNumber, val: "1620000000000ms"}, {typ: tokenNumber, val: "2w"},
+			},
+		},
+		// time expression
+		{
+			q: "time > now()-1h",
+			tokens: []token{
+				{typ: tokenIdent, val: "time"}, {typ: tokenOperator, val: ">"}, {typ: tokenIdent, val: "now"},
+				{typ: tokenPunct, val: "("}, {typ: tokenPunct, val: ")"}, {typ: tokenOperator, val: "-"},
+				{typ: tokenNumber, val: "1h"},
+			},
+		},
+		// operators
+		{
+			q: "= != <> < <= > >= + - * /",
+			tokens: []token{
+				{typ: tokenOperator, val: "="}, {typ: tokenOperator, val: "!="}, {typ: tokenOperator, val: "<>"},
+				{typ: tokenOperator, val: "<"}, {typ: tokenOperator, val: "<="}, {typ: tokenOperator, val: ">"},
+				{typ: tokenOperator, val: ">="}, {typ: tokenOperator, val: "+"}, {typ: tokenOperator, val: "-"},
+				{typ: tokenOperator, val: "*"}, {typ: tokenOperator, val: "/"},
+			},
+		},
+		// regex after match operator
+		{
+			q: `host=~/^web\/[0-9]+$/ AND region!~/sh/`,
+			tokens: []token{
+				{typ: tokenIdent, val: "host"}, {typ: tokenOperator, val: "=~"}, {typ: tokenRegex, val: "^web/[0-9]+$"},
+				{typ: tokenIdent, val: "AND"}, {typ: tokenIdent, val: "region"}, {typ: tokenOperator, val: "!~"},
+				{typ: tokenRegex, val: "sh"},
+			},
+		},
+		// regex after from
+		{
+			q: "select f from /cpu.*/",
+			tokens: []token{
+				{typ: tokenIdent, val: "select"}, {typ: tokenIdent, val: "f"}, {typ: tokenIdent, val: "from"},
+				{typ: tokenRegex, val: "cpu.*"},
+			},
+		},
+		// division is not regex
+		{
+			q: "a/b",
+			tokens: []token{
+				{typ: tokenIdent, val: "a"}, {typ: tokenOperator, val: "/"}, {typ: tokenIdent, val: "b"},
+			},
+		},
+	}
+	for _, example := range examples {
+		tokens, err := scan(example.q)
+		assert.NoError(t, err)
+		assert.Equal(t, example.tokens, tokens)
+	}
+}
+
+func TestScan_Error(t *testing.T) {
+	examples := []string{
+		// unterminated quoted identifier
+		`select "f from cpu`,
+		// unterminated string
+		`select f from cpu where host='a`,
+		// escaped quote at end
+		`select f from cpu where host='a\'`,
+		// unterminated regex
+		`select f from cpu where host=~/^a`,
+		// single exclamation
+		`select f from cpu where host ! 'a'`,
+		// exclamation at end
+		`select f from cpu where host!`,
+		// unexpected character
+		`select f from cpu where host='a' & region='b'`,
+		// unexpected unicode character
+		`select f from cpu where host→'a'`,
+	}
+	for _, q := range examples {
+		tokens, err := scan(q)
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+	}
+}
+
+func TestToken(t *testing.T) {
+	assert.True(t, token{typ: tokenIdent, val: "SELECT"}.isKeyword("select"))
+	assert.False(t, token{typ: tokenQuotedIdent, val: "select"}.isKeyword("select"))
+	assert.False(t, token{typ: tokenString, val: "select"}.isKeyword("select"))
+	assert.True(t, token{typ: tokenOperator, val: "="}.is("="))
+	assert.True(t, token{typ: tokenPunct, val: "("}.is("("))
+	assert.False(t, token{typ: tokenString, val: "="}.is("="))
+	assert.False(t, token{typ: tokenRegex, val: "("}.is("("))
+}
@@ -0,0 +1,612 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package influxql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrEmptyQuery represents no statement in InfluxQL.
+var ErrEmptyQuery = errors.New("influxql: empty query")
+
+// functions maps the aggregate function of InfluxQL to LinQL.
+var functions = map[string]string{
+	"mean":   "avg",
+	"sum":    "sum",
+	"min":    "min",
+	"max":    "max",
+	"count":  "count",
+	"stddev": "stddev",
+}
+
+// clauseKeywords represents the keywords which start a new clause after where/group by.
+var clauseKeywords = []string{"where", "group", "order", "limit", "offset", "slimit", "soffset", "tz"}
+
+// Query represents the LinQL query translated from one InfluxQL select statement.
+type Query struct {
+	SQL         string   // LinQL
+	Measurement string   // measurement(metric name) of InfluxQL
+	Columns     []string // column names of select fields, exclude time column
+}
+
+// Translate translates the InfluxQL into LinQL, only supports a practical subset of InfluxQL select statement:
+// 1. select fields: field, aggregate function(mean/sum/min/max/count/stddev) of field and arithmetic of them;
+// 2. from one measurement, database/retention policy prefix will be ignored;
+// 3. where: tag conditions(=,!=,<>,=~,!~) and time conditions(now() with duration, epoch, time string);
+// 4. group by: time(interval), tags and fill(null/none/previous/number);
+// 5. slimit as limit of LinQL, order by/limit/offset/soffset/tz are ignored.
+// Absolute time literals are formatted in given location which used to parse LinQL.
+func Translate(influxQL string, location *time.Location) ([]*Query, error) {
+	tokens, err := scan(influxQL)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		location = time.Local
+	}
+	var queries []*Query
+	for _, stmtTokens := range splitStatements(tokens) {
+		t := &translator{tokens: stmtTokens, location: location}
+		q, err := t.translate()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	if len(queries) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	return queries, nil
+}
+
+// splitStatements splits tokens into statements by ';'.
+func splitStatements(tokens []token) (statements [][]token) {
+	start := 0
+	for idx, tk := range tokens {
+		if tk.is(";") {
+			if idx > start {
+				statements = append(statements, tokens[start:idx])
+			}
+			start = idx + 1
+		}
+	}
+	if start < len(tokens) {
+		statements = append(statements, tokens[start:])
+	}
+	return
+}
+
+// translator translates the tokens of one select statement into LinQL.
+type translator struct {
+	tokens   []token
+	pos      int
+	location *time.Location
+}
+
+// translate translates the select statement.
+func (t *translator) translate() (*Query, error) {
+	if !t.next().isKeyword("select") {
+		return nil, fmt.Errorf("influxql: only supports select statement")
+	}
+	q := &Query{}
+	var sb strings.Builder
+	sb.WriteString("select ")
+
+	fields, err := t.translateFields(q)
+	if err != nil {
+		return nil, err
+	}
+	sb.WriteString(fields)
+
+	if !t.next().isKeyword("from") {
+		return nil, fmt.Errorf("influxql: missing from clause")
+	}
+	if err := t.translateFrom(q); err != nil {
+		return nil, err
+	}
+	sb.WriteString(" from ")
+	sb.WriteString(quoteIdent(q.Measurement))
+
+	for !t.eof() {
+		tk := t.next()
+		switch {
+		case tk.isKeyword("where"):
+			where, err := t.translateWhere()
+			if err != nil {
+				return nil, err
+			}
+			if where != "" {
+				sb.WriteString(" where ")
+				sb.WriteString(where)
+			}
+		case tk.isKeyword("group"):
+			if !t.next().isKeyword("by") {
+				return nil, fmt.Errorf("influxql: missing by after group")
+			}
+			groupBy, err := t.translateGroupBy()
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(" group by ")
+			sb.WriteString(groupBy)
+		case tk.isKeyword("slimit"):
+			limit := t.next()
+			if limit.typ != tokenNumber {
+				return nil, fmt.Errorf("influxql: invalid slimit")
+			}
+			sb.WriteString(" limit ")
+			sb.WriteString(limit.val)
+		case tk.isKeyword("order"), tk.isKeyword("limit"), tk.isKeyword("offset"),
+			tk.isKeyword("soffset"), tk.isKeyword("tz"):
+			// no equivalent clause in LinQL, ignore it
+			t.skipClause()
+		default:
+			return nil, fmt.Errorf("influxql: unexpected token '%s'", tk.val)
+		}
+	}
+	q.SQL = sb.String()
+	return q, nil
+}
+
+// translateFields translates the select fields, fills the column names of query.
+func (t *translator) translateFields(q *Query) (string, error) {
+	var fields []string
+	columns := make(map[string]int)
+	for {
+		expr, column, err := t.translateField()
+		if err != nil {
+			return "", err
+		}
+		// make column name unique like InfluxDB, e.g. mean, mean_1
+		if n, ok := columns[column]; ok {
+			columns[column] = n + 1
+			column = fmt.Sprintf("%s_%d", column, n+1)
+		} else {
+			columns[column] = 0
+		}
+		q.Columns = append(q.Columns, column)
+		fields = append(fields, expr+" as "+quoteIdent(column))
+		if !t.peek().is(",") {
+			break
+		}
+		t.next()
+	}
+	return strings.Join(fields, ","), nil
+}
+
+// translateField translates one select field, returns the LinQL expression and column name.
+func (t *translator) translateField() (expr, column string, err error) {
+	var (
+		sb        strings.Builder
+		depth     int
+		funcName  string
+		fieldName string
+	)
+	for !t.eof() {
+		tk := t.peek()
+		if depth == 0 && (tk.is(",") || tk.isKeyword("from") || tk.isKeyword("as")) {
+			break
+		}
+		t.next()
+		switch {
+		case tk.is("("):
+			depth++
+			sb.WriteString(tk.val)
+		case tk.is(")"):
+			depth--
+			sb.WriteString(tk.val)
+		case tk.typ == tokenIdent && t.peek().is("("):
+			name := strings.ToLower(tk.val)
+			fn, ok := functions[name]
+			if !ok {
+				return "", "", fmt.Errorf("influxql: unsupported function %s", tk.val)
+			}
+			if funcName == "" {
+				funcName = name
+			}
+			sb.WriteString(fn)
+		case tk.typ == tokenIdent || tk.typ == tokenQuotedIdent:
+			if fieldName == "" {
+				fieldName = tk.val
+			}
+			sb.WriteString(quoteIdent(tk.val))
+		case tk.is("*") && (sb.Len() == 0 || strings.HasSuffix(sb.String(), "(")):
+			return "", "", fmt.Errorf("influxql: wildcard is not supported")
+		case tk.typ == tokenNumber || tk.typ == tokenOperator:
+			sb.WriteString(tk.val)
+		default:
+			return "", "", fmt.Errorf("influxql: unexpected token '%s' in select field", tk.val)
+		}
+	}
+	if sb.Len() == 0 {
+		return "", "", fmt.Errorf("influxql: missing select field")
+	}
+	column = funcName
+	if column == "" {
+		column = fieldName
+	}
+	if t.peek().isKeyword("as") {
+		t.next()
+		alias := t.next()
+		if alias.typ != tokenIdent && alias.typ != tokenQuotedIdent {
+			return "", "", fmt.Errorf("influxql: invalid alias")
+		}
+		column = alias.val
+	}
+	return sb.String(), column, nil
+}
+
+// translateFrom translates the measurement, database/retention policy prefix will be ignored.
+func (t *translator) translateFrom(q *Query) error {
+	expectSegment := true
+	for !t.eof() {
+		tk := t.peek()
+		switch {
+		case tk.typ == tokenIdent && t.isClauseKeyword(tk):
+			return t.checkMeasurement(q)
+		case expectSegment && (tk.typ == tokenIdent || tk.typ == tokenQuotedIdent):
+			q.Measurement = tk.val
+			expectSegment = false
+		case tk.is("."):
+			expectSegment = true
+		case tk.typ == tokenRegex:
+			return fmt.Errorf("influxql: regex measurement is not supported")
+		default:
+			return fmt.Errorf("influxql: unexpected token '%s' in from clause", tk.val)
+		}
+		t.next()
+	}
+	return t.checkMeasurement(q)
+}
+
+// checkMeasurement checks if measurement is set.
+func (t *translator) checkMeasurement(q *Query) error {
+	if q.Measurement == "" {
+		return fmt.Errorf("influxql: missing measurement")
+	}
+	return nil
+}
+
+// translateWhere translates the where condition, tag conditions first then time conditions,
+// because of LinQL requires time condition after tag condition.
+func (t *translator) translateWhere() (string, error) {
+	var tagConditions, timeConditions []string
+	for _, conjunct := range t.splitConjuncts() {
+		if len(conjunct) == 0 {
+			return "", fmt.Errorf("influxql: invalid where condition")
+		}
+		if isTimeKey(conjunct[0]) {
+			cond, err := t.translateTimeCondition(conjunct)
+			if err != nil {
+				return "", err
+			}
+			timeConditions = append(timeConditions, cond)
+			continue
+		}
+		cond, err := translateTagCondition(conjunct)
+		if err != nil {
+			return "", err
+		}
+		tagConditions = append(tagConditions, cond)
+	}
+	return strings.Join(append(tagConditions, timeConditions...), " and "), nil
+}
+
+// splitConjuncts splits the tokens of where condition by top level 'and'.
+func (t *translator) splitConjuncts() (conjuncts [][]token) {
+	var (
+		current []token
+		depth   int
+	)
+	for !t.eof() {
+		tk := t.peek()
+		if depth == 0 && tk.typ == tokenIdent && t.isClauseKeyword(tk) {
+			break
+		}
+		t.next()
+		switch {
+		case tk.is("("):
+			depth++
+		case tk.is(")"):
+			depth--
+		case depth == 0 && tk.isKeyword("and"):
+			conjuncts = append(conjuncts, current)
+			current = nil
+			continue
+		}
+		current = append(current, tk)
+	}
+	return append(conjuncts, current)
+}
+
+// translateTimeCondition translates the time condition, like time > now() - 1h, time >= 1620000000000ms.
+func (t *translator) translateTimeCondition(tokens []token) (string, error) {
+	if len(tokens) < 3 || tokens[1].typ != tokenOperator {
+		return "", fmt.Errorf("influxql: invalid time condition")
+	}
+	op := tokens[1].val
+	switch op {
+	case ">", ">=", "<", "<=":
+	default:
+		return "", fmt.Errorf("influxql: unsupported time operator %s", op)
+	}
+	value := tokens[2:]
+	switch {
+	case value[0].isKeyword("now"):
+		if len(value) < 3 || !value[1].is("(") || !value[2].is(")") {
+			return "", fmt.Errorf("influxql: invalid now() in time condition")
+		}
+		switch {
+		case len(value) == 3:
+			return "time" + op + "now()", nil
+		case len(value) == 5 && (value[3].is("-") || value[3].is("+")) && value[4].typ == tokenNumber:
+			d, err := parseDuration(value[4].val)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("time%snow()%s%ds", op, value[3].val, int64(d/time.Second)), nil
+		default:
+			return "", fmt.Errorf("influxql: invalid now() in time condition")
+		}
+	case len(value) == 1 && value[0].typ == tokenNumber:
+		timestamp, err := parseEpoch(value[0].val)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("time%s'%s'", op, t.formatTime(timestamp)), nil
+	case len(value) == 1 && value[0].typ == tokenString:
+		timestamp, err := parseTimeString(value[0].val)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("time%s'%s'", op, t.formatTime(timestamp)), nil
+	default:
+		return "", fmt.Errorf("influxql: unsupported time condition")
+	}
+}
+
+// formatTime formats the timestamp(millisecond) in location for LinQL.
+func (t *translator) formatTime(timestamp int64) string {
+	return time.Unix(0, timestamp*int64(time.Millisecond)).In(t.location).Format("2006-01-02 15:04:05")
+}
+
+// translateTagCondition translates the tag condition, like "host" = 'a', host =~ /^a/.
+func translateTagCondition(tokens []token) (string, error) {
+	var sb strings.Builder
+	for idx, tk := range tokens {
+		switch {
+		case tk.isKeyword("and"), tk.isKeyword("or"):
+			sb.WriteString(" " + strings.ToLower(tk.val) + " ")
+		case isTimeKey(tk):
+			return "", fmt.Errorf("influxql: time condition must be top level with and")
+		case tk.typ == tokenIdent || tk.typ == tokenQuotedIdent:
+			// tag value may be a bare identifier after operator
+			if idx > 0 && tokens[idx-1].typ == tokenOperator {
+				sb.WriteString(quoteString(tk.val))
+			} else {
+				sb.WriteString(quoteIdent(tk.val))
+			}
+		case tk.typ == tokenString, tk.typ == tokenRegex, tk.typ == tokenNumber:
+			sb.WriteString(quoteString(tk.val))
+		case tk.is("="), tk.is("!="), tk.is("<>"), tk.is("=~"), tk.is("!~"):
+			sb.WriteString(tk.val)
+		case tk.is("("), tk.is(")"):
+			sb.WriteString(tk.val)
+		default:
+			return "", fmt.Errorf("influxql: unsupported token '%s' in tag condition", tk.val)
+		}
+	}
+	return sb.String(), nil
+}
+
+// translateGroupBy translates group by keys and fill option.
+func (t *translator) translateGroupBy() (string, error) {
+	var (
+		keys []string
+		fill string
+	)
+	for !t.eof() {
+		tk := t.peek()
+		if tk.typ == tokenIdent && t.isClauseKeyword(tk) {
+			break
+		}
+		t.next()
+		switch {
+		case tk.is(","):
+		case tk.isKeyword("time") && t.peek().is("("):
+			args := t.parenArgs()
+			if len(args) == 0 || args[0].typ != tokenNumber {
+				return "", fmt.Errorf("influxql: invalid group by time interval")
+			}
+			d, err := parseDuration(args[0].val)
+			if err != nil {
+				return "", err
+			}
+			seconds := int64(math.Ceil(d.Seconds()))
+			if seconds <= 0 {
+				seconds = 1
+			}
+			keys = append(keys, fmt.Sprintf("time(%ds)", seconds))
+		case tk.isKeyword("fill") && t.peek().is("("):
+			args := t.parenArgs()
+			if len(args) != 1 {
+				return "", fmt.Errorf("influxql: invalid fill option")
+			}
+			switch {
+			case args[0].isKeyword("none"):
+			case args[0].isKeyword("null"), args[0].isKeyword("previous"), args[0].typ == tokenNumber:
+				fill = " fill(" + strings.ToLower(args[0].val) + ")"
+			default:
+				return "", fmt.Errorf("influxql: unsupported fill option %s", args[0].val)
+			}
+		case tk.is("*"):
+			return "", fmt.Errorf("influxql: group by wildcard is not supported")
+		case tk.typ == tokenIdent || tk.typ == tokenQuotedIdent:
+			keys = append(keys, quoteIdent(tk.val))
+		default:
+			return "", fmt.Errorf("influxql: unexpected token '%s' in group by clause", tk.val)
+		}
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("influxql: missing group by keys")
+	}
+	return strings.Join(keys, ",") + fill, nil
+}
+
+// parenArgs returns the tokens of arguments in parentheses, excludes ','.
+func (t *translator) parenArgs() (args []token) {
+	t.next() // skip '('
+	for !t.eof() {
+		tk := t.next()
+		if tk.is(")") {
+			break
+		}
+		if !tk.is(",") {
+			args = append(args, tk)
+		}
+	}
+	return
+}
+
+// skipClause skips the tokens of current clause.
+func (t *translator) skipClause() {
+	for !t.eof() {
+		tk := t.peek()
+		if tk.typ == tokenIdent && t.isClauseKeyword(tk) {
+			return
+		}
+		t.next()
+	}
+}
+
+// isClauseKeyword checks if token starts a new clause.
+func (t *translator) isClauseKeyword(tk token) bool {
+	for _, keyword := range clauseKeywords {
+		if tk.isKeyword(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// peek returns the current token without moving.
+func (t *translator) peek() token {
+	if t.eof() {
+		return token{}
+	}
+	return t.tokens[t.pos]
+}
+
+// next returns the current token, then moves to next.
+func (t *translator) next() token {
+	tk := t.peek()
+	t.pos++
+	return tk
+}
+
+// eof checks if all tokens are consumed.
+func (t *translator) eof() bool {
+	return t.pos >= len(t.tokens)
+}
+
+// isTimeKey checks if token is time key.
+func isTimeKey(tk token) bool {
+	return tk.isKeyword("time") || (tk.typ == tokenQuotedIdent && tk.val == "time")
+}
+
+// quoteIdent quotes the identifier with double quotes for LinQL.
+func quoteIdent(ident string) string {
+	return `"` + ident + `"`
+}
+
+// quoteString quotes the string with single quotes for LinQL.
+func quoteString(str string) string {
+	return "'" + str + "'"
+}
+
+// parseDuration parses the duration literal of InfluxQL, like 10s, 1h, 100ms, 2w.
+func parseDuration(str string) (time.Duration, error) {
+	value, unit := splitUnit(str)
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("influxql: invalid duration %s", str)
+	}
+	switch unit {
+	case "ns":
+		return time.Duration(n), nil
+	case "u", "µ":
+		return time.Duration(n) * time.Microsecond, nil
+	case "ms":
+		return time.Duration(n) * time.Millisecond, nil
+	case "s":
+		return time.Duration(n) * time.Second, nil
+	case "m":
+		return time.Duration(n) * time.Minute, nil
+	case "h":
+		return time.Duration(n) * time.Hour, nil
+	case "d":
+		return time.Duration(n) * 24 * time.Hour, nil
+	case "w":
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("influxql: invalid duration %s", str)
+	}
+}
+
+// parseEpoch parses the epoch time literal(default nanosecond) into millisecond, like 1620000000000ms.
+func parseEpoch(str string) (int64, error) {
+	value, unit := splitUnit(str)
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("influxql: invalid epoch time %s", str)
+	}
+	if unit == "" {
+		unit = "ns"
+	}
+	d, err := parseDuration(strconv.FormatInt(n, 10) + unit)
+	if err != nil {
+		return 0, fmt.Errorf("influxql: invalid epoch time %s", str)
+	}
+	return d.Milliseconds(), nil
+}
+
+// parseTimeString parses the time string literal into millisecond, time string without zone is in UTC.
+func parseTimeString(str string) (int64, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+		if tm, err := time.Parse(layout, str); err == nil {
+			return tm.UnixNano() / int64(time.Millisecond), nil
+		}
+	}
+	return 0, fmt.Errorf("influxql: invalid time string %s", str)
+}
+
+// splitUnit splits the number literal into value and unit suffix.
+func splitUnit(str string) (value, unit string) {
+	idx := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if idx < 0 {
+		return str, ""
+	}
+	return str[:idx], str[idx:]
+}
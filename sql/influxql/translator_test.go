@@ -0,0 +1,162 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package influxql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/sql"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestTranslate(t *testing.T) {
+	cases := []struct {
+		influxQL string
+		linQL    string
+		columns  []string
+	}{
+		{
+			influxQL: `SELECT mean(f) FROM m WHERE time > now()-1h GROUP BY time(1m), host`,
+			linQL:    `select avg("f") as "mean" from "m" where time>now()-3600s group by time(60s),"host"`,
+			columns:  []string{"mean"},
+		},
+		{
+			influxQL: `SELECT mean("value"), max("value") AS "peak", mean("idle") FROM "telegraf".."cpu" ` +
+				`WHERE ("host" = 'a' OR host =~ /^b.*/) AND time >= 1620000000000ms and time <= 1620003600000ms ` +
+				`GROUP BY time(200ms), "host" fill(null) ORDER BY time DESC LIMIT 10 SLIMIT 5 tz('Asia/Shanghai')`,
+			linQL: `select avg("value") as "mean",max("value") as "peak",avg("idle") as "mean_1" from "cpu" ` +
+				`where ("host"='a' or "host"=~'^b.*') and time>='2021-05-03 00:00:00' and time<='2021-05-03 01:00:00' ` +
+				`group by time(1s),"host" fill(null) limit 5`,
+			columns: []string{"mean", "peak", "mean_1"},
+		},
+		{
+			influxQL: `select f*100 from "cpu.load" where host != 'a' and time < '2021-05-03T00:00:00Z' group by host fill(none)`,
+			linQL:    `select "f"*100 as "f" from "cpu.load" where "host"!='a' and time<'2021-05-03 00:00:00' group by "host"`,
+			columns:  []string{"f"},
+		},
+		{
+			influxQL: `select count(f) from m where time > 1620000000000000000 and time < now(); select f from m`,
+			linQL:    `select count("f") as "count" from "m" where time>'2021-05-03 00:00:00' and time<now()`,
+			columns:  []string{"count"},
+		},
+	}
+	for _, c := range cases {
+		queries, err := Translate(c.influxQL, time.UTC)
+		assert.NoError(t, err, c.influxQL)
+		assert.Equal(t, c.linQL, queries[0].SQL)
+		assert.Equal(t, c.columns, queries[0].Columns)
+		// translated sql must be valid LinQL
+		_, err = sql.ParseWithOptions(queries[0].SQL, &sql.Options{Location: time.UTC})
+		assert.NoError(t, err, queries[0].SQL)
+	}
+}
+
+func TestTranslate_LinQL(t *testing.T) {
+	queries, err := Translate(`SELECT mean("value") FROM "cpu" WHERE "host" = 'a' AND time >= 1620000000000ms `+
+		`GROUP BY time(1m), "host"`, nil)
+	assert.NoError(t, err)
+	assert.Len(t, queries, 1)
+	assert.Equal(t, "cpu", queries[0].Measurement)
+	q, err := sql.Parse(queries[0].SQL)
+	assert.NoError(t, err)
+	query := q.(*stmt.Query)
+	assert.Equal(t, "cpu", query.MetricName)
+	assert.Equal(t, []string{"host"}, query.GroupBy)
+	assert.Equal(t, timeutil.Interval(timeutil.OneMinute), query.Interval)
+	assert.Equal(t, int64(1620000000000), query.TimeRange.Start)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "host", Value: "a"}, query.Condition)
+	selectItem := query.SelectItems[0].(*stmt.SelectItem)
+	assert.Equal(t, "mean", selectItem.Alias)
+}
+
+func TestTranslate_Error(t *testing.T) {
+	for _, influxQL := range []string{
+		``,
+		`;`,
+		`show databases`,
+		`select median(f) from m`,
+		`select * from m`,
+		`select count(*) from m`,
+		`select from m`,
+		`select f`,
+		`select f m`,
+		`select f as 'a' from m`,
+		`select f from /cpu.*/`,
+		`select f from`,
+		`select f from m,`,
+		`select f from m where`,
+		`select f from m where time`,
+		`select f from m where time = now()`,
+		`select f from m where time > now(`,
+		`select f from m where time > now() - 1x`,
+		`select f from m where time > now() * 1h`,
+		`select f from m where time > 1x`,
+		`select f from m where time > 'abc'`,
+		`select f from m where time > host`,
+		`select f from m where (time > now() or host = 'a')`,
+		`select f from m where host > 'a'`,
+		`select f from m group host`,
+		`select f from m group by`,
+		`select f from m group by *`,
+		`select f from m group by time()`,
+		`select f from m group by time(1x)`,
+		`select f from m group by host fill(linear)`,
+		`select f from m group by host fill()`,
+		`select f from m group by host,'a'`,
+		`select f from m slimit a`,
+		`select f from m where host = 'a`,
+		`select f from m where host = 'a' & 1`,
+		`select f from m where host ! 'a'`,
+		`select f from m where "host = 'a'`,
+		`select f from m where host =~ /a`,
+		`select f,'a' from m`,
+		`select f from m m2`,
+	} {
+		_, err := Translate(influxQL, time.UTC)
+		assert.Error(t, err, influxQL)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"10ns": 10,
+		"10u":  10 * time.Microsecond,
+		"10µ":  10 * time.Microsecond,
+		"10ms": 10 * time.Millisecond,
+		"10s":  10 * time.Second,
+		"10m":  10 * time.Minute,
+		"10h":  10 * time.Hour,
+		"1d":   24 * time.Hour,
+		"1w":   7 * 24 * time.Hour,
+	}
+	for str, d := range cases {
+		d1, err := parseDuration(str)
+		assert.NoError(t, err)
+		assert.Equal(t, d, d1)
+	}
+	_, err := parseDuration("1.5h")
+	assert.Error(t, err)
+	_, err = parseEpoch("1.5h")
+	assert.Error(t, err)
+	_, err = parseEpoch("15x")
+	assert.Error(t, err)
+}
@@ -24,14 +24,19 @@ import (
 
 type listener struct {
 	*grammar.BaseSQLListener
+	opts *Options
 	stmt *queryStmtParse
 
+	rangeOps  map[int]stmt.RangeOP
+	timeExprs map[int]*timeExpr
+	params    map[string]string
+
 	metaStmt *metaStmtParser
 }
 
 // EnterQueryStmt is called when production queryStmt is entered.
 func (l *listener) EnterQueryStmt(ctx *grammar.QueryStmtContext) {
-	l.stmt = newQueryStmtParse(ctx.T_EXPLAIN() != nil)
+	l.stmt = newQueryStmtParse(ctx.T_EXPLAIN() != nil, l.opts)
 }
 
 // EnterShowDatabaseStmt is called when production showDatabaseStmt is entered.
@@ -168,8 +173,12 @@ func (l *listener) EnterLimitClause(ctx *grammar.LimitClauseContext) {
 func (l *listener) EnterTagFilterExpr(ctx *grammar.TagFilterExprContext) {
 	switch {
 	case l.stmt != nil:
+		l.stmt.rangeOps = l.rangeOps
+		l.stmt.params = l.params
 		l.stmt.visitTagFilterExpr(ctx)
 	case l.metaStmt != nil:
+		l.metaStmt.rangeOps = l.rangeOps
+		l.metaStmt.params = l.params
 		l.metaStmt.visitTagFilterExpr(ctx)
 	}
 }
@@ -197,6 +206,7 @@ func (l *listener) EnterTagValue(ctx *grammar.TagValueContext) {
 // EnterTimeRangeExpr is called when production timeRangeExpr is entered.
 func (l *listener) EnterTimeRangeExpr(ctx *grammar.TimeRangeExprContext) {
 	if l.stmt != nil {
+		l.stmt.timeExprs = l.timeExprs
 		l.stmt.visitTimeRangeExpr(ctx)
 	}
 }
@@ -113,4 +113,10 @@ func TestMetaStmt_ShowTagValues(t *testing.T) {
 			Operator: stmt.AND,
 			Right:    &stmt.EqualsExpr{Key: "key2", Value: "value2"},
 		}, *expr)
+
+	sql = "show tag values from 'cpu' with key = 'key1' where key2 > 10"
+	q, err = Parse(sql)
+	assert.NoError(t, err)
+	query = q.(*stmt.Metadata)
+	assert.Equal(t, &stmt.RangeExpr{Key: "key2", Op: stmt.GreaterThan, Value: "10"}, query.Condition)
 }
@@ -0,0 +1,46 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/lindb/lindb/pkg/strutil"
+)
+
+// placeholderRegexp matches the placeholder of sql, like $host
+var placeholderRegexp = regexp.MustCompile(`^\$[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// isPlaceholder checks if the ident text is a placeholder.
+func isPlaceholder(text string) bool {
+	return placeholderRegexp.MatchString(text)
+}
+
+// bindParam returns the parameter value if ident text is a placeholder and params is not nil,
+// otherwise returns the string value of ident. Quoted text like '$host' is not a placeholder.
+func bindParam(params map[string]string, text string) (string, error) {
+	if params == nil || !isPlaceholder(text) {
+		return strutil.GetStringValue(text), nil
+	}
+	value, ok := params[text[1:]]
+	if !ok {
+		return "", fmt.Errorf("parameter %s is not bound", text)
+	}
+	return value, nil
+}
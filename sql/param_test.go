@@ -0,0 +1,105 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestBindParam(t *testing.T) {
+	params := map[string]string{"host": "1.1.1.1"}
+	value, err := bindParam(params, "$host")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", value)
+	// not bound
+	_, err = bindParam(params, "$ip")
+	assert.Error(t, err)
+	// not placeholder
+	value, err = bindParam(params, "'$host'")
+	assert.NoError(t, err)
+	assert.Equal(t, "$host", value)
+	value, err = bindParam(params, "host")
+	assert.NoError(t, err)
+	assert.Equal(t, "host", value)
+	// placeholder as literal without params
+	value, err = bindParam(nil, "$host")
+	assert.NoError(t, err)
+	assert.Equal(t, "$host", value)
+}
+
+func TestPreparedStatement_Bind(t *testing.T) {
+	prepared, err := Prepare("select f from cpu where host=$host and ip in ($ip1, '$ip2') and time>$start and time<$end")
+	assert.NoError(t, err)
+	q, err := prepared.Bind(&Options{Params: map[string]string{
+		"host": "h1", "ip1": "1.1.1.1", "start": "1554854400000", "end": "20190410 10:00:00"}})
+	assert.NoError(t, err)
+	query := q.(*stmt.Query)
+	assert.Equal(t, &stmt.BinaryExpr{
+		Left:     &stmt.EqualsExpr{Key: "host", Value: "h1"},
+		Operator: stmt.AND,
+		Right:    &stmt.InExpr{Key: "ip", Values: []string{"1.1.1.1", "$ip2"}},
+	}, query.Condition)
+	endTime, _ := timeutil.ParseTimestamp("20190410 10:00:00")
+	assert.Equal(t, timeutil.TimeRange{Start: 1554854400000, End: endTime}, query.TimeRange)
+
+	// bind again with other params
+	q, err = prepared.Bind(&Options{Params: map[string]string{
+		"host": "h2", "ip1": "2.2.2.2", "start": "1554854400000", "end": "1554858000000"}})
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	assert.Equal(t, "h2", query.Condition.(*stmt.BinaryExpr).Left.(*stmt.EqualsExpr).Value)
+	assert.Equal(t, timeutil.TimeRange{Start: 1554854400000, End: 1554858000000}, query.TimeRange)
+
+	// epoch time literal of database precision
+	q, err = prepared.Bind(&Options{Precision: timeutil.Nanosecond, Params: map[string]string{
+		"host": "h2", "ip1": "2.2.2.2", "start": "1554854400000000000", "end": "2019-04-10T01:00:00Z"}})
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	assert.Equal(t, timeutil.TimeRange{Start: 1554854400000, End: 1554858000000}, query.TimeRange)
+
+	// param not bound
+	_, err = prepared.Bind(&Options{Params: map[string]string{"host": "h1"}})
+	assert.Error(t, err)
+	_, err = prepared.Bind(&Options{Params: map[string]string{"host": "h1", "ip1": "1.1.1.1"}})
+	assert.Error(t, err)
+	// placeholder as time literal without params
+	_, err = prepared.Bind(nil)
+	assert.Error(t, err)
+
+	// metadata statement
+	prepared, err = Prepare("show tag values from cpu with key=host where region=$region")
+	assert.NoError(t, err)
+	q, err = prepared.Bind(&Options{Params: map[string]string{"region": "sh"}})
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "region", Value: "sh"}, q.(*stmt.Metadata).Condition)
+
+	// state statement
+	prepared, err = Prepare("show replication")
+	assert.NoError(t, err)
+	q, err = prepared.Bind(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.State{Type: stmt.ReplicationState}, q)
+	// schema statement error
+	_, err = Prepare("drop database")
+	assert.Error(t, err)
+}
@@ -20,10 +20,12 @@ package sql
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 
 	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/sql/grammar"
 	"github.com/lindb/lindb/sql/stmt"
 )
@@ -37,20 +39,54 @@ var log = logger.GetLogger("sql", "Parser")
 var errorHandle = &errorListener{}
 var walker = antlr.ParseTreeWalkerDefault
 
+// Options represents the defaults used when parsing query statement.
+type Options struct {
+	TimeRange int64          // default time range(millisecond) if query without start time
+	Location  *time.Location // location for parsing time literal, if nil uses local zone
+	// Params are the values bound to placeholders(like $host) of tag value/time literal, key is placeholder name
+	// without '$', if nil, placeholder is parsed as literal.
+	Params map[string]string
+	// Precision is the precision of epoch time literal(like time>1618560000000000000), converted to millisecond
+	// when parsing, if not set, epoch time literal is millisecond.
+	Precision timeutil.Precision
+}
+
+// PreparedStatement represents the parse result of sql which maybe includes placeholders(like $host),
+// it can be bound with different parameters repeatedly without parsing sql again.
+// Syntax tree is read only after prepared, so it's safe for concurrent binding.
+type PreparedStatement struct {
+	sql       string
+	stmt      stmt.Statement // statement parsed by tokens directly(state/schema), no need to bind
+	tree      grammar.IStatementContext
+	rangeOps  map[int]stmt.RangeOP
+	timeExprs map[int]*timeExpr
+	subQuery  *PreparedStatement // inner query if from clause is a subquery
+	// seriesCardinality is true if sql is series cardinality statement rewritten to show tag values statement
+	seriesCardinality bool
+	// tagValueCardinality is the options of tag value cardinality statement rewritten to show tag values statement
+	tagValueCardinality *tagValueCardinality
+}
+
 // Parse parses sql using the grammar of LinDB query language
-func Parse(sql string) (stmt stmt.Statement, err error) {
+func Parse(sql string) (stmt.Statement, error) {
+	return ParseWithOptions(sql, nil)
+}
+
+// ParseWithOptions parses sql using the grammar of LinDB query language with query defaults
+func ParseWithOptions(sql string, opts *Options) (stmt.Statement, error) {
+	prepared, err := Prepare(sql)
+	if err != nil {
+		return nil, err
+	}
+	return prepared.Bind(opts)
+}
+
+// Prepare parses sql into syntax tree using the grammar of LinDB query language.
+func Prepare(sql string) (prepared *PreparedStatement, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			switch x := r.(type) {
-			case string:
-				err = errors.New(x)
-			case error:
-				err = x
-			default:
-				err = errors.New("unknown panic when sql parse")
-			}
-			log.Error("parse sql", logger.String("sql", sql), logger.Error(err), logger.Stack())
-			stmt = nil
+			err = recoverParse(sql, r)
+			prepared = nil
 		}
 	}()
 
@@ -60,19 +96,236 @@ func Parse(sql string) (stmt stmt.Statement, err error) {
 	defer putSQLLexer(lexer)
 
 	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	if stateStmt, ok := parseStateStmt(tokens); ok {
+		return &PreparedStatement{sql: sql, stmt: stateStmt}, nil
+	}
+	if schemaStmt, ok, err := parseSchemaStmt(tokens); ok {
+		if err != nil {
+			return nil, err
+		}
+		return &PreparedStatement{sql: sql, stmt: schemaStmt}, nil
+	}
+	if describeStmt, ok, err := parseDescribeStmt(tokens); ok {
+		if err != nil {
+			return nil, err
+		}
+		return &PreparedStatement{sql: sql, stmt: describeStmt}, nil
+	}
+	if cardinalitySQL, ok := rewriteSeriesCardinality(sql, tokens); ok {
+		prepared, err = Prepare(cardinalitySQL)
+		if err != nil {
+			return nil, err
+		}
+		prepared.sql = sql
+		prepared.seriesCardinality = true
+		return prepared, nil
+	}
+	cardinalitySQL, cardinality, ok, err := rewriteTagValueCardinality(sql, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		prepared, err = Prepare(cardinalitySQL)
+		if err != nil {
+			return nil, err
+		}
+		prepared.sql = sql
+		prepared.tagValueCardinality = cardinality
+		return prepared, nil
+	}
+	outerSQL, innerSQL, err := splitSubQuery(sql, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(innerSQL) > 0 {
+		return prepareSubQuery(sql, outerSQL, innerSQL)
+	}
+	rewrittenSQL, rangeOps, timeExprs, err := rewriteWhereClause(sql, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if rewrittenSQL != sql {
+		lexer.SetInputStream(antlr.NewInputStream(rewrittenSQL))
+		tokens = antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	}
 
 	parser := getSQLParserFunc(tokens)
 	defer putSQLParser(parser)
 
-	ctx := parser.Statement()
+	return &PreparedStatement{sql: sql, tree: parser.Statement(), rangeOps: rangeOps, timeExprs: timeExprs}, nil
+}
+
+// Bind builds the statement by walking syntax tree with query defaults and parameters,
+// time literal like now() is evaluated when binding.
+func (p *PreparedStatement) Bind(opts *Options) (stmt stmt.Statement, err error) {
+	if p.stmt != nil {
+		return p.stmt, nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoverParse(p.sql, r)
+			stmt = nil
+		}
+	}()
 
 	// create sql listener
-	listener := listener{}
+	listener := listener{opts: opts, rangeOps: p.rangeOps, timeExprs: p.timeExprs}
+	if opts != nil {
+		listener.params = opts.Params
+	}
+
+	walker.Walk(&listener, p.tree)
+
+	statement, err := listener.statement()
+	if p.seriesCardinality {
+		toSeriesCardinality(statement)
+	}
+	if p.tagValueCardinality != nil {
+		p.tagValueCardinality.apply(statement)
+	}
+	if err != nil || p.subQuery == nil {
+		return statement, err
+	}
+	return p.bindSubQuery(statement, opts)
+}
+
+// recoverParse converts the panic when parsing sql to error.
+func recoverParse(sql string, r interface{}) error {
+	var err error
+	switch x := r.(type) {
+	case string:
+		err = errors.New(x)
+	case error:
+		err = x
+	default:
+		err = errors.New("unknown panic when sql parse")
+	}
+	log.Error("parse sql", logger.String("sql", sql), logger.Error(err), logger.Stack())
+	return err
+}
+
+// parseStateStmt parses the state statement(show replication) by tokens directly,
+// because it is a fixed keyword sequence which doesn't need parse tree.
+func parseStateStmt(tokens *antlr.CommonTokenStream) (stmt.Statement, bool) {
+	tokens.Fill()
+	var tokenTypes []int
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		tokenTypes = append(tokenTypes, token.GetTokenType())
+	}
+	if len(tokenTypes) == 2 &&
+		tokenTypes[0] == grammar.SQLLexerT_SHOW && tokenTypes[1] == grammar.SQLLexerT_REPLICATION {
+		return &stmt.State{Type: stmt.ReplicationState}, true
+	}
+	return nil, false
+}
+
+// tagRangeOps represents the range operators which can be used in tag filter
+var tagRangeOps = map[int]stmt.RangeOP{
+	grammar.SQLLexerT_LESS:         stmt.LessThan,
+	grammar.SQLLexerT_LESSEQUAL:    stmt.LessEqual,
+	grammar.SQLLexerT_GREATER:      stmt.GreaterThan,
+	grammar.SQLLexerT_GREATEREQUAL: stmt.GreaterEqual,
+}
 
-	walker.Walk(&listener, ctx)
+// sqlReplacement represents a token range of sql which need be replaced.
+type sqlReplacement struct {
+	start, stop int
+	text        string
+	op          stmt.RangeOP
+	timeExpr    *timeExpr
+}
 
-	stmt, err = listener.statement()
-	return stmt, err
+// rewriteWhereClause rewrites the where clause which grammar doesn't support:
+//  1. range filters(<,<=,>,>=) on tag key are rewritten to regexp filters with quoted value, because grammar
+//     only supports ident as tag value. The range operators are kept by the start offset of rewritten operators,
+//     then tag filter parser can build range expr by them.
+//  2. time expressions(like now()-1h-30m, '2019-04-10T08:00:00Z'+1h, 1554854400000) are rewritten to now(),
+//     the time expressions are kept by the start offset of rewritten now(), then evaluated when binding statement.
+func rewriteWhereClause(sql string, tokens *antlr.CommonTokenStream) (string, map[int]stmt.RangeOP, map[int]*timeExpr, error) {
+	tokens.Fill()
+	var defaultTokens []antlr.Token
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		defaultTokens = append(defaultTokens, token)
+	}
+	var replacements []sqlReplacement
+	inWhere := false
+	for idx, token := range defaultTokens {
+		switch token.GetTokenType() {
+		case grammar.SQLLexerT_WHERE:
+			inWhere = true
+			continue
+		case grammar.SQLLexerT_GROUP, grammar.SQLLexerT_ORDER, grammar.SQLLexerT_LIMIT, grammar.SQLLexerT_HAVING:
+			inWhere = false
+			continue
+		case grammar.SQLLexerT_TIME:
+			// time binaryOperator timeExpr
+			if !inWhere || idx+2 >= len(defaultTokens) {
+				continue
+			}
+			expr, last, err := parseTimeExpr(defaultTokens, idx+2)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			replacements = append(replacements, sqlReplacement{
+				start:    defaultTokens[idx+2].GetStart(),
+				stop:     defaultTokens[last].GetStop(),
+				text:     "now()",
+				timeExpr: expr,
+			})
+			continue
+		}
+		op, ok := tagRangeOps[token.GetTokenType()]
+		if !inWhere || !ok || idx == 0 || defaultTokens[idx-1].GetTokenType() == grammar.SQLLexerT_TIME {
+			continue
+		}
+		replacements = append(replacements, sqlReplacement{start: token.GetStart(), stop: token.GetStop(), text: "=~", op: op})
+		if idx+1 >= len(defaultTokens) {
+			continue
+		}
+		value := defaultTokens[idx+1]
+		valueText := value.GetText()
+		stop := value.GetStop()
+		if value.GetTokenType() == grammar.SQLLexerT_SUB && idx+2 < len(defaultTokens) {
+			value = defaultTokens[idx+2]
+			valueText += value.GetText()
+			stop = value.GetStop()
+		}
+		if value.GetTokenType() == grammar.SQLLexerL_INT || value.GetTokenType() == grammar.SQLLexerL_DEC {
+			replacements = append(replacements, sqlReplacement{
+				start: defaultTokens[idx+1].GetStart(),
+				stop:  stop,
+				text:  "'" + valueText + "'",
+			})
+		}
+	}
+	if len(replacements) == 0 {
+		return sql, nil, nil, nil
+	}
+	// token's offset is the index of rune
+	input := []rune(sql)
+	output := make([]rune, 0, len(input)+2*len(replacements))
+	rangeOps := make(map[int]stmt.RangeOP)
+	timeExprs := make(map[int]*timeExpr)
+	pos := 0
+	for _, r := range replacements {
+		output = append(output, input[pos:r.start]...)
+		if r.op > 0 {
+			rangeOps[len(output)] = r.op
+		}
+		if r.timeExpr != nil {
+			timeExprs[len(output)] = r.timeExpr
+		}
+		output = append(output, []rune(r.text)...)
+		pos = r.stop + 1
+	}
+	output = append(output, input[pos:]...)
+	return string(output), rangeOps, timeExprs, nil
 }
 
 var (
@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/sql/grammar"
 	"github.com/lindb/lindb/sql/stmt"
 )
@@ -65,6 +66,19 @@ func Test_Meta_SQL_Parse(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func Test_State_SQL_Parse(t *testing.T) {
+	query, err := Parse("show replication")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.State{Type: stmt.ReplicationState}, query)
+	query, err = Parse("  SHOW   Replication ")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.State{Type: stmt.ReplicationState}, query)
+	_, err = Parse("show replication on db")
+	assert.Error(t, err)
+	_, err = Parse("show replicatio")
+	assert.Error(t, err)
+}
+
 func TestParse_panic(t *testing.T) {
 	defer func() {
 		getSQLParserFunc = getSQLParser
@@ -82,6 +96,69 @@ func TestParse_panic(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// timeExprTokens returns the tokens of default channel without EOF.
+func timeExprTokens(expr string) []antlr.Token {
+	lexer := grammar.NewSQLLexer(antlr.NewInputStream(expr))
+	lexer.RemoveErrorListeners()
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	tokens.Fill()
+	var result []antlr.Token
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		result = append(result, token)
+	}
+	return result
+}
+
+func TestParseTimeExpr(t *testing.T) {
+	examples := []struct {
+		expr string
+		want *timeExpr
+		last int
+	}{
+		{"now()", &timeExpr{now: true}, 2},
+		{"now()-1h", &timeExpr{now: true, duration: -timeutil.OneHour}, 5},
+		{"now() - 1h - 30m", &timeExpr{now: true, duration: -timeutil.OneHour - 30*timeutil.OneMinute}, 8},
+		{"now()+1d-2h", &timeExpr{now: true, duration: timeutil.OneDay - 2*timeutil.OneHour}, 8},
+		{"now()-10s", &timeExpr{now: true, duration: -10 * timeutil.OneSecond}, 5},
+		{"now()-1w", &timeExpr{now: true, duration: -timeutil.OneWeek}, 5},
+		{"now()-1M", &timeExpr{now: true, duration: -timeutil.OneMonth}, 5},
+		{"now()-1y", &timeExpr{now: true, duration: -timeutil.OneYear}, 5},
+		{"'2019-04-10T08:00:00Z'+1h", &timeExpr{literal: "'2019-04-10T08:00:00Z'", duration: timeutil.OneHour}, 3},
+		{"1554854400000-1d", &timeExpr{literal: "1554854400000", duration: -timeutil.OneDay}, 3},
+		{"$start", &timeExpr{literal: "$start"}, 0},
+		// expression stops at the token which is not duration arithmetic
+		{"now()-1h and host='a'", &timeExpr{now: true, duration: -timeutil.OneHour}, 5},
+		{"now()-1h)", &timeExpr{now: true, duration: -timeutil.OneHour}, 5},
+	}
+	for _, example := range examples {
+		expr, last, err := parseTimeExpr(timeExprTokens(example.expr), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, example.want, expr)
+		assert.Equal(t, example.last, last)
+	}
+
+	// bad time expressions
+	for _, str := range []string{
+		"now(",
+		"now)(",
+		"(now())",
+		"now()-",
+		"now()-1",
+		"now()-h",
+		"now()-1x",
+		"now()-1h+",
+		"now()-1.5h",
+		"now()-99999999999999999999h",
+	} {
+		expr, _, err := parseTimeExpr(timeExprTokens(str), 0)
+		assert.Error(t, err)
+		assert.Nil(t, expr)
+	}
+}
+
 func BenchmarkSQLParse(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = Parse("select f from cpu " +
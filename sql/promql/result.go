@@ -0,0 +1,179 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package promql
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lindb/lindb/models"
+)
+
+// valueColumn represents the column name of selected field in LinQL.
+const valueColumn = "value"
+
+// define the result type of Prometheus http api
+const (
+	ResultTypeMatrix = "matrix"
+	ResultTypeVector = "vector"
+	ResultTypeScalar = "scalar"
+)
+
+// define the error type of Prometheus http api
+const (
+	ErrorTypeBadData   = "bad_data"
+	ErrorTypeExecution = "execution"
+)
+
+// Response represents the response of Prometheus http api.
+type Response struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// QueryData represents the data of query response.
+type QueryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+// MatrixSeries represents one series of range query result.
+type MatrixSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][]interface{}   `json:"values"`
+}
+
+// VectorSample represents one sample of instant query result.
+type VectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+// Success returns the success response with data.
+func Success(data interface{}) *Response {
+	return &Response{Status: "success", Data: data}
+}
+
+// Fail returns the error response.
+func Fail(errorType string, err error) *Response {
+	return &Response{Status: "error", ErrorType: errorType, Error: err.Error()}
+}
+
+// BuildMatrix builds the range query result based on LinQL result set.
+func (q *Query) BuildMatrix(rs *models.ResultSet) []*MatrixSeries {
+	result := []*MatrixSeries{}
+	if rs == nil {
+		return result
+	}
+	for _, series := range rs.Series {
+		points := q.points(rs, series)
+		if len(points) == 0 {
+			continue
+		}
+		item := &MatrixSeries{Metric: q.labels(series)}
+		for _, p := range points {
+			item.Values = append(item.Values, samplePair(p.timestamp, p.value))
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// BuildVector builds the instant query result based on LinQL result set, uses the latest point of each series.
+func (q *Query) BuildVector(rs *models.ResultSet, evalTime time.Time) []*VectorSample {
+	result := []*VectorSample{}
+	if rs == nil {
+		return result
+	}
+	for _, series := range rs.Series {
+		points := q.points(rs, series)
+		if len(points) == 0 {
+			continue
+		}
+		latest := points[len(points)-1]
+		result = append(result, &VectorSample{
+			Metric: q.labels(series),
+			Value:  samplePair(evalTime.UnixNano()/int64(time.Millisecond), latest.value),
+		})
+	}
+	return result
+}
+
+// BuildScalarMatrix builds the range query result of number literal, one point per step.
+func (q *Query) BuildScalarMatrix(start, end time.Time, step time.Duration) []*MatrixSeries {
+	series := &MatrixSeries{Metric: map[string]string{}}
+	if step <= 0 {
+		step = time.Second
+	}
+	for tm := start; !tm.After(end); tm = tm.Add(step) {
+		series.Values = append(series.Values, samplePair(tm.UnixNano()/int64(time.Millisecond), q.Value))
+	}
+	return []*MatrixSeries{series}
+}
+
+// BuildScalar builds the instant query result of number literal.
+func (q *Query) BuildScalar(evalTime time.Time) []interface{} {
+	return samplePair(evalTime.UnixNano()/int64(time.Millisecond), q.Value)
+}
+
+// point represents a point of series.
+type point struct {
+	timestamp int64
+	value     float64
+}
+
+// points returns the valid points of series sorted by timestamp, converts value to per-second rate for rate function.
+func (q *Query) points(rs *models.ResultSet, series *models.Series) []point {
+	values := series.Fields[valueColumn]
+	points := make([]point, 0, len(values))
+	for timestamp, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		if q.rate && rs.Interval > 0 {
+			value /= float64(rs.Interval) / float64(time.Second/time.Millisecond)
+		}
+		points = append(points, point{timestamp: timestamp, value: value})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].timestamp < points[j].timestamp })
+	return points
+}
+
+// labels returns the labels of series, includes metric name if no aggregation like Prometheus.
+func (q *Query) labels(series *models.Series) map[string]string {
+	labels := make(map[string]string, len(series.Tags)+1)
+	for k, v := range series.Tags {
+		labels[k] = v
+	}
+	if q.GroupByAll && q.function == "" {
+		labels[MetricNameLabel] = q.Metric
+	}
+	return labels
+}
+
+// samplePair returns the sample pair of Prometheus, [unix seconds, "value"].
+func samplePair(timestamp int64, value float64) []interface{} {
+	return []interface{}{
+		float64(timestamp) / float64(time.Second/time.Millisecond),
+		strconv.FormatFloat(value, 'f', -1, 64),
+	}
+}
@@ -0,0 +1,90 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package promql
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+)
+
+func TestQuery_BuildMatrix(t *testing.T) {
+	q, err := Translate(`cpu{__field__="usage"}`)
+	assert.NoError(t, err)
+	assert.Empty(t, q.BuildMatrix(nil))
+
+	rs := models.NewResultSet()
+	rs.Interval = 10000
+	series := models.NewSeries(map[string]string{"host": "a"})
+	series.Fields[valueColumn] = map[int64]float64{20000: 2, 10000: 1, 30000: math.NaN()}
+	rs.AddSeries(series)
+	// empty series
+	rs.AddSeries(models.NewSeries(map[string]string{"host": "b"}))
+
+	assert.Equal(t, []*MatrixSeries{{
+		Metric: map[string]string{"host": "a", MetricNameLabel: "cpu"},
+		Values: [][]interface{}{{10.0, "1"}, {20.0, "2"}},
+	}}, q.BuildMatrix(rs))
+
+	// rate, value per second
+	q, err = Translate(`sum by (host) (rate(cpu[1m]))`)
+	assert.NoError(t, err)
+	assert.Equal(t, []*MatrixSeries{{
+		Metric: map[string]string{"host": "a"},
+		Values: [][]interface{}{{10.0, "0.1"}, {20.0, "0.2"}},
+	}}, q.BuildMatrix(rs))
+}
+
+func TestQuery_BuildVector(t *testing.T) {
+	q, err := Translate(`sum by (host) (cpu)`)
+	assert.NoError(t, err)
+	evalTime := time.Unix(100, 0)
+	assert.Empty(t, q.BuildVector(nil, evalTime))
+
+	rs := models.NewResultSet()
+	series := models.NewSeries(map[string]string{"host": "a"})
+	series.Fields[valueColumn] = map[int64]float64{20000: 2, 10000: 1}
+	rs.AddSeries(series)
+	rs.AddSeries(models.NewSeries(map[string]string{"host": "b"}))
+	assert.Equal(t, []*VectorSample{{
+		Metric: map[string]string{"host": "a"},
+		Value:  []interface{}{100.0, "2"},
+	}}, q.BuildVector(rs, evalTime))
+}
+
+func TestQuery_BuildScalar(t *testing.T) {
+	q, err := Translate(`2`)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{100.0, "2"}, q.BuildScalar(time.Unix(100, 0)))
+	assert.Equal(t, []*MatrixSeries{{
+		Metric: map[string]string{},
+		Values: [][]interface{}{{100.0, "2"}, {160.0, "2"}},
+	}}, q.BuildScalarMatrix(time.Unix(100, 0), time.Unix(200, 0), time.Minute))
+	assert.Len(t, q.BuildScalarMatrix(time.Unix(100, 0), time.Unix(101, 0), 0)[0].Values, 2)
+}
+
+func TestResponse(t *testing.T) {
+	assert.Equal(t, &Response{Status: "success", Data: 1}, Success(1))
+	assert.Equal(t, &Response{Status: "error", ErrorType: ErrorTypeBadData, Error: "err"},
+		Fail(ErrorTypeBadData, fmt.Errorf("err")))
+}
@@ -0,0 +1,141 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package promql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	examples := []struct {
+		expr   string
+		tokens []token
+	}{
+		// empty
+		{
+			expr:   " \t\n",
+			tokens: nil,
+		},
+		// metric name with label matchers
+		{
+			expr: `http_requests_total{job="api", code!="200", path=~"/api/.*", method!~'GET|POST'}`,
+			tokens: []token{
+				{typ: tokenIdent, val: "http_requests_total"}, {typ: tokenPunct, val: "{"},
+				{typ: tokenIdent, val: "job"}, {typ: tokenOperator, val: "="}, {typ: tokenString, val: "api"},
+				{typ: tokenPunct, val: ","},
+				{typ: tokenIdent, val: "code"}, {typ: tokenOperator, val: "!="}, {typ: tokenString, val: "200"},
+				{typ: tokenPunct, val: ","},
+				{typ: tokenIdent, val: "path"}, {typ: tokenOperator, val: "=~"}, {typ: tokenString, val: "/api/.*"},
+				{typ: tokenPunct, val: ","},
+				{typ: tokenIdent, val: "method"}, {typ: tokenOperator, val: "!~"}, {typ: tokenString, val: "GET|POST"},
+				{typ: tokenPunct, val: "}"},
+			},
+		},
+		// recording rule name and reserved label
+		{
+			expr: `job:http_requests:rate5m{__name__="a"}`,
+			tokens: []token{
+				{typ: tokenIdent, val: "job:http_requests:rate5m"}, {typ: tokenPunct, val: "{"},
+				{typ: tokenIdent, val: "__name__"}, {typ: tokenOperator, val: "="}, {typ: tokenString, val: "a"},
+				{typ: tokenPunct, val: "}"},
+			},
+		},
+		// quoted strings with escapes
+		{
+			expr: `"a\"b" 'a\'b' "a\\b" "a\.b" ` + "`a\\.b` `a\"b`",
+			tokens: []token{
+				{typ: tokenString, val: `a"b`}, {typ: tokenString, val: `a'b`}, {typ: tokenString, val: `a\b`},
+				{typ: tokenString, val: `a\.b`}, {typ: tokenString, val: `a\.b`}, {typ: tokenString, val: `a"b`},
+			},
+		},
+		// range vector with durations
+		{
+			expr: "rate(cpu[5m]), rate(mem[1h30m])",
+			tokens: []token{
+				{typ: tokenIdent, val: "rate"}, {typ: tokenPunct, val: "("}, {typ: tokenIdent, val: "cpu"},
+				{typ: tokenPunct, val: "["}, {typ: tokenNumber, val: "5m"}, {typ: tokenPunct, val: "]"},
+				{typ: tokenPunct, val: ")"}, {typ: tokenPunct, val: ","},
+				{typ: tokenIdent, val: "rate"}, {typ: tokenPunct, val: "("}, {typ: tokenIdent, val: "mem"},
+				{typ: tokenPunct, val: "["}, {typ: tokenNumber, val: "1h30m"}, {typ: tokenPunct, val: "]"},
+				{typ: tokenPunct, val: ")"},
+			},
+		},
+		// numbers
+		{
+			expr: "1 0.5 .5 1e3 1.5e3",
+			tokens: []token{
+				{typ: tokenNumber, val: "1"}, {typ: tokenNumber, val: "0.5"}, {typ: tokenNumber, val: ".5"},
+				{typ: tokenNumber, val: "1e3"}, {typ: tokenNumber, val: "1.5e3"},
+			},
+		},
+		// aggregation with grouping
+		{
+			expr: "sum by (job) (cpu[1d])",
+			tokens: []token{
+				{typ: tokenIdent, val: "sum"}, {typ: tokenIdent, val: "by"}, {typ: tokenPunct, val: "("},
+				{typ: tokenIdent, val: "job"}, {typ: tokenPunct, val: ")"}, {typ: tokenPunct, val: "("},
+				{typ: tokenIdent, val: "cpu"}, {typ: tokenPunct, val: "["}, {typ: tokenNumber, val: "1d"},
+				{typ: tokenPunct, val: "]"}, {typ: tokenPunct, val: ")"},
+			},
+		},
+	}
+	for _, example := range examples {
+		tokens, err := scan(example.expr)
+		assert.NoError(t, err)
+		assert.Equal(t, example.tokens, tokens)
+	}
+}
+
+func TestScan_Error(t *testing.T) {
+	examples := []string{
+		// unterminated double quoted string
+		`cpu{host="a}`,
+		// unterminated single quoted string
+		`cpu{host='a}`,
+		// unterminated raw string
+		"cpu{host=`a}",
+		// escaped quote at end
+		`cpu{host="a\"`,
+		// single exclamation
+		`cpu{host!"a"}`,
+		// exclamation at end
+		`cpu{host!`,
+		// binary operator
+		`cpu + mem`,
+		// offset modifier
+		`cpu @ 1620000000`,
+		// unexpected unicode character
+		`cpu{host→"a"}`,
+	}
+	for _, expr := range examples {
+		tokens, err := scan(expr)
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+	}
+}
+
+func TestToken(t *testing.T) {
+	assert.True(t, token{typ: tokenIdent, val: "BY"}.isKeyword("by"))
+	assert.False(t, token{typ: tokenString, val: "by"}.isKeyword("by"))
+	assert.True(t, token{typ: tokenOperator, val: "=~"}.is("=~"))
+	assert.True(t, token{typ: tokenPunct, val: "{"}.is("{"))
+	assert.False(t, token{typ: tokenString, val: "{"}.is("{"))
+	assert.False(t, token{typ: tokenIdent, val: "="}.is("="))
+}
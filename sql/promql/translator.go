@@ -0,0 +1,419 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package promql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// define the special label names
+const (
+	// MetricNameLabel is the label name of metric name.
+	MetricNameLabel = "__name__"
+	// FieldNameLabel is the label name for selecting the field of metric, LinDB metric may have multi-fields.
+	FieldNameLabel = "__field__"
+)
+
+// ErrEmptyQuery represents no expression in PromQL.
+var ErrEmptyQuery = errors.New("promql: empty query")
+
+// aggregations maps the aggregation operator of PromQL to LinQL function.
+var aggregations = map[string]string{
+	"sum": "sum",
+	"avg": "avg",
+	"min": "min",
+	"max": "max",
+}
+
+// rangeFunctions maps the range vector function of PromQL to LinQL function.
+var rangeFunctions = map[string]string{
+	"rate":            "sum",
+	"increase":        "sum",
+	"sum_over_time":   "sum",
+	"avg_over_time":   "avg",
+	"min_over_time":   "min",
+	"max_over_time":   "max",
+	"count_over_time": "count",
+}
+
+// Query represents the LinQL query translated from PromQL expression.
+type Query struct {
+	Metric     string   // metric name
+	Field      string   // field name selected by __field__ label, empty if not specified
+	Condition  string   // LinQL tag condition of label matchers
+	GroupBy    []string // group by tag keys
+	GroupByAll bool     // no aggregation, keeps all series of metric, group by all tag keys
+	Scalar     bool     // number literal
+	Value      float64  // value of number literal
+
+	function string // LinQL aggregate function, empty means default function of field
+	rate     bool   // per-second rate, value of each interval divided by interval seconds
+}
+
+// Translate translates the PromQL expression into LinQL query, only supports a practical subset of PromQL:
+// 1. number literal;
+// 2. vector selector: metric{label="a",label!="b",label=~"c.*",label!~"d"}, field of metric selected by __field__ label;
+// 3. range function of vector selector: rate/increase/sum_over_time/avg_over_time/min_over_time/max_over_time/
+// count_over_time, range of selector is ignored, which always equals the step of range query;
+// 4. aggregation: sum/avg/min/max by(labels) of vector selector, sum of rate/increase, and the same aggregation
+// of *_over_time function.
+// Binary operators, offset modifier, subquery and without clause are not supported.
+func Translate(expr string) (*Query, error) {
+	tokens, err := scan(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	if len(tokens) == 1 && tokens[0].typ == tokenNumber {
+		value, err := strconv.ParseFloat(tokens[0].val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("promql: invalid number %s", tokens[0].val)
+		}
+		return &Query{Scalar: true, Value: value}, nil
+	}
+	t := &translator{tokens: tokens}
+	q, err := t.translateExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !t.eof() {
+		return nil, fmt.Errorf("promql: unexpected token '%s'", t.peek().val)
+	}
+	return q, nil
+}
+
+// SQL returns the LinQL of query in time range [start, end] with step interval, time literals are formatted in location.
+func (q *Query) SQL(start, end time.Time, step time.Duration, location *time.Location) string {
+	if location == nil {
+		location = time.Local
+	}
+	var sb strings.Builder
+	sb.WriteString("select ")
+	if q.function == "" {
+		sb.WriteString(quoteIdent(q.Field))
+	} else {
+		sb.WriteString(q.function + "(" + quoteIdent(q.Field) + ")")
+	}
+	sb.WriteString(" as " + quoteIdent(valueColumn))
+	sb.WriteString(" from " + quoteIdent(q.Metric))
+	sb.WriteString(" where ")
+	if q.Condition != "" {
+		sb.WriteString(q.Condition + " and ")
+	}
+	sb.WriteString(fmt.Sprintf("time>='%s' and time<='%s'", formatTime(start, location), formatTime(end, location)))
+	seconds := int64(math.Ceil(step.Seconds()))
+	if seconds <= 0 {
+		seconds = 1
+	}
+	sb.WriteString(fmt.Sprintf(" group by time(%ds)", seconds))
+	for _, key := range q.GroupBy {
+		sb.WriteString("," + quoteIdent(key))
+	}
+	return sb.String()
+}
+
+// ShowFieldsSQL returns the LinQL of showing fields of metric.
+func (q *Query) ShowFieldsSQL() string {
+	return "show fields from " + quoteString(q.Metric)
+}
+
+// ShowTagKeysSQL returns the LinQL of showing tag keys of metric.
+func (q *Query) ShowTagKeysSQL() string {
+	return "show tag keys from " + quoteString(q.Metric)
+}
+
+// ShowTagValuesSQL returns the LinQL of showing tag values of tag key under metric, filtered by label matchers.
+func (q *Query) ShowTagValuesSQL(tagKey string, limit int) string {
+	var sb strings.Builder
+	sb.WriteString("show tag values from " + quoteString(q.Metric) + " with key=" + quoteString(tagKey))
+	if q.Condition != "" {
+		sb.WriteString(" where " + q.Condition)
+	}
+	if limit > 0 {
+		sb.WriteString(" limit " + strconv.Itoa(limit))
+	}
+	return sb.String()
+}
+
+// translator translates the tokens of PromQL expression.
+type translator struct {
+	tokens []token
+	pos    int
+}
+
+// translateExpr translates the aggregation, range function or vector selector.
+func (t *translator) translateExpr() (*Query, error) {
+	tk := t.peek()
+	if tk.typ == tokenIdent && t.peekNext().typ != tokenOperator && !t.peekNext().is("{") {
+		if _, ok := aggregations[tk.val]; ok {
+			return t.translateAggregation()
+		}
+		if _, ok := rangeFunctions[tk.val]; ok {
+			return t.translateRangeFunction()
+		}
+	}
+	return t.translateSelector()
+}
+
+// translateAggregation translates the aggregation, like sum by (host) (metric), sum(rate(metric[1m])) by (host).
+func (t *translator) translateAggregation() (*Query, error) {
+	op := t.next().val
+	groupBy, err := t.translateGrouping()
+	if err != nil {
+		return nil, err
+	}
+	if !t.next().is("(") {
+		return nil, fmt.Errorf("promql: missing ( after %s", op)
+	}
+	q, err := t.translateExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !t.next().is(")") {
+		return nil, fmt.Errorf("promql: missing ) of %s", op)
+	}
+	if groupBy == nil {
+		if groupBy, err = t.translateGrouping(); err != nil {
+			return nil, err
+		}
+	}
+	if !q.GroupByAll {
+		return nil, fmt.Errorf("promql: nested aggregation is not supported")
+	}
+	function := aggregations[op]
+	switch {
+	case q.function == "":
+		q.function = function
+	case q.function != function:
+		return nil, fmt.Errorf("promql: %s of %s function is not supported", op, q.function)
+	}
+	q.GroupByAll = false
+	q.GroupBy = groupBy
+	return q, nil
+}
+
+// translateGrouping translates the optional by clause, returns nil if no by clause.
+func (t *translator) translateGrouping() ([]string, error) {
+	tk := t.peek()
+	switch {
+	case tk.isKeyword("without"):
+		return nil, fmt.Errorf("promql: without clause is not supported")
+	case !tk.isKeyword("by"):
+		return nil, nil
+	}
+	t.next()
+	if !t.next().is("(") {
+		return nil, fmt.Errorf("promql: missing ( after by")
+	}
+	labels := []string{}
+	for {
+		tk := t.next()
+		switch {
+		case tk.is(")"):
+			return labels, nil
+		case tk.is(","):
+		case tk.typ == tokenIdent:
+			labels = append(labels, tk.val)
+		default:
+			return nil, fmt.Errorf("promql: unexpected token '%s' in by clause", tk.val)
+		}
+	}
+}
+
+// translateRangeFunction translates the range vector function, like rate(metric[1m]).
+func (t *translator) translateRangeFunction() (*Query, error) {
+	fn := t.next().val
+	if !t.next().is("(") {
+		return nil, fmt.Errorf("promql: missing ( after %s", fn)
+	}
+	q, err := t.translateSelector()
+	if err != nil {
+		return nil, err
+	}
+	if !t.next().is("[") {
+		return nil, fmt.Errorf("promql: %s requires range vector", fn)
+	}
+	rangeToken := t.next()
+	if rangeToken.typ != tokenNumber {
+		return nil, fmt.Errorf("promql: invalid range of %s", fn)
+	}
+	if _, err := ParseDuration(rangeToken.val); err != nil {
+		return nil, err
+	}
+	if !t.next().is("]") || !t.next().is(")") {
+		return nil, fmt.Errorf("promql: invalid range vector of %s", fn)
+	}
+	q.function = rangeFunctions[fn]
+	q.rate = fn == "rate"
+	return q, nil
+}
+
+// translateSelector translates the vector selector, like metric{host="a"}, {__name__="metric"}.
+func (t *translator) translateSelector() (*Query, error) {
+	q := &Query{GroupByAll: true}
+	if t.peek().typ == tokenIdent {
+		q.Metric = t.next().val
+	}
+	if t.peek().is("{") {
+		t.next()
+		if err := t.translateMatchers(q); err != nil {
+			return nil, err
+		}
+	}
+	if q.Metric == "" {
+		return nil, fmt.Errorf("promql: missing metric name of vector selector")
+	}
+	return q, nil
+}
+
+// translateMatchers translates the label matchers of vector selector.
+func (t *translator) translateMatchers(q *Query) error {
+	var conditions []string
+	for {
+		tk := t.next()
+		switch {
+		case tk.is("}"):
+			q.Condition = strings.Join(conditions, " and ")
+			return nil
+		case tk.is(","):
+		case tk.typ == tokenIdent:
+			op, value := t.next(), t.next()
+			if op.typ != tokenOperator || value.typ != tokenString {
+				return fmt.Errorf("promql: invalid matcher of label %s", tk.val)
+			}
+			switch tk.val {
+			case MetricNameLabel, FieldNameLabel:
+				if !op.is("=") {
+					return fmt.Errorf("promql: only supports = matcher of %s", tk.val)
+				}
+				if tk.val == MetricNameLabel {
+					q.Metric = value.val
+				} else {
+					q.Field = value.val
+				}
+				continue
+			}
+			val := value.val
+			if strings.ContainsRune(val, '\'') {
+				return fmt.Errorf("promql: single quote in value of label %s is not supported", tk.val)
+			}
+			if op.is("=~") || op.is("!~") {
+				// regex of PromQL is fully anchored
+				val = "^(?:" + val + ")$"
+			}
+			conditions = append(conditions, quoteIdent(tk.val)+op.val+quoteString(val))
+		default:
+			return fmt.Errorf("promql: unexpected token '%s' in label matchers", tk.val)
+		}
+	}
+}
+
+// peek returns the current token without moving.
+func (t *translator) peek() token {
+	if t.eof() {
+		return token{}
+	}
+	return t.tokens[t.pos]
+}
+
+// peekNext returns the token after current token without moving.
+func (t *translator) peekNext() token {
+	if t.pos+1 >= len(t.tokens) {
+		return token{}
+	}
+	return t.tokens[t.pos+1]
+}
+
+// next returns the current token, then moves to next.
+func (t *translator) next() token {
+	tk := t.peek()
+	t.pos++
+	return tk
+}
+
+// eof checks if all tokens are consumed.
+func (t *translator) eof() bool {
+	return t.pos >= len(t.tokens)
+}
+
+// quoteIdent quotes the identifier with double quotes for LinQL.
+func quoteIdent(ident string) string {
+	return `"` + ident + `"`
+}
+
+// quoteString quotes the string with single quotes for LinQL.
+func quoteString(str string) string {
+	return "'" + str + "'"
+}
+
+// formatTime formats the time in location for LinQL.
+func formatTime(tm time.Time, location *time.Location) string {
+	return tm.In(location).Format("2006-01-02 15:04:05")
+}
+
+// ParseDuration parses the duration of PromQL/Prometheus http api, like 1h30m, 5m, 15, 0.5(seconds).
+func ParseDuration(str string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(str, 64); err == nil {
+		if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return 0, fmt.Errorf("promql: invalid duration %s", str)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	var d time.Duration
+	rest := str
+	for rest != "" {
+		idx := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if idx <= 0 {
+			return 0, fmt.Errorf("promql: invalid duration %s", str)
+		}
+		n, _ := strconv.ParseInt(rest[:idx], 10, 64)
+		rest = rest[idx:]
+		end := strings.IndexFunc(rest, func(r rune) bool { return r >= '0' && r <= '9' })
+		if end < 0 {
+			end = len(rest)
+		}
+		unit := rest[:end]
+		rest = rest[end:]
+		switch unit {
+		case "ms":
+			d += time.Duration(n) * time.Millisecond
+		case "s":
+			d += time.Duration(n) * time.Second
+		case "m":
+			d += time.Duration(n) * time.Minute
+		case "h":
+			d += time.Duration(n) * time.Hour
+		case "d":
+			d += time.Duration(n) * 24 * time.Hour
+		case "w":
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case "y":
+			d += time.Duration(n) * 365 * 24 * time.Hour
+		default:
+			return 0, fmt.Errorf("promql: invalid duration %s", str)
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,176 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package promql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/sql"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestTranslate(t *testing.T) {
+	start := time.Date(2021, 5, 3, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	timeRange := `time>='2021-05-03 00:00:00' and time<='2021-05-03 01:00:00'`
+	cases := []struct {
+		promQL     string
+		linQL      string
+		groupByAll bool
+	}{
+		{
+			promQL:     `cpu{__field__="usage"}`,
+			linQL:      `select "usage" as "value" from "cpu" where ` + timeRange + ` group by time(60s)`,
+			groupByAll: true,
+		},
+		{
+			promQL: `sum by (host, region) ({__name__="cpu", host=~"a|b", region!="sh", __field__='usage'})`,
+			linQL: `select sum("usage") as "value" from "cpu" where "host"=~'^(?:a|b)$' and "region"!='sh' and ` +
+				timeRange + ` group by time(60s),"host","region"`,
+		},
+		{
+			promQL: `sum(rate(http_requests_total{__field__="counter",code!~"5.."}[5m])) by (code)`,
+			linQL: `select sum("counter") as "value" from "http_requests_total" where "code"!~'^(?:5..)$' and ` +
+				timeRange + ` group by time(60s),"code"`,
+		},
+		{
+			promQL:     `max_over_time(jvm:heap{__field__="gauge"}[1h30m])`,
+			linQL:      `select max("gauge") as "value" from "jvm:heap" where ` + timeRange + ` group by time(60s)`,
+			groupByAll: true,
+		},
+		{
+			promQL: `avg(cpu{__field__="usage"})`,
+			linQL:  `select avg("usage") as "value" from "cpu" where ` + timeRange + ` group by time(60s)`,
+		},
+	}
+	for _, c := range cases {
+		q, err := Translate(c.promQL)
+		assert.NoError(t, err, c.promQL)
+		assert.Equal(t, c.groupByAll, q.GroupByAll, c.promQL)
+		linQL := q.SQL(start, end, time.Minute, time.UTC)
+		assert.Equal(t, c.linQL, linQL)
+		// translated sql must be valid LinQL
+		_, err = sql.ParseWithOptions(linQL, &sql.Options{Location: time.UTC})
+		assert.NoError(t, err, linQL)
+	}
+}
+
+func TestTranslate_LinQL(t *testing.T) {
+	q, err := Translate(`sum by (host) (rate(cpu{host="a"}[1m]))`)
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", q.Metric)
+	assert.Empty(t, q.Field)
+	assert.True(t, q.rate)
+	q.Field = "counter"
+	start := time.Unix(1620000000, 0)
+	statement, err := sql.Parse(q.SQL(start, start.Add(time.Hour), 500*time.Millisecond, nil))
+	assert.NoError(t, err)
+	query := statement.(*stmt.Query)
+	assert.Equal(t, "cpu", query.MetricName)
+	assert.Equal(t, []string{"host"}, query.GroupBy)
+	assert.Equal(t, int64(1620000000000), query.TimeRange.Start)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "host", Value: "a"}, query.Condition)
+}
+
+func TestTranslate_Scalar(t *testing.T) {
+	q, err := Translate(" 1.5 ")
+	assert.NoError(t, err)
+	assert.True(t, q.Scalar)
+	assert.Equal(t, 1.5, q.Value)
+	_, err = Translate("1x")
+	assert.Error(t, err)
+}
+
+func TestTranslate_Metadata(t *testing.T) {
+	q, err := Translate(`cpu{host="a"}`)
+	assert.NoError(t, err)
+	for _, ql := range []string{
+		q.ShowFieldsSQL(),
+		q.ShowTagKeysSQL(),
+		q.ShowTagValuesSQL("region", 100),
+		q.ShowTagValuesSQL("region", 0),
+	} {
+		statement, err := sql.Parse(ql)
+		assert.NoError(t, err, ql)
+		metadata := statement.(*stmt.Metadata)
+		assert.Equal(t, "cpu", metadata.MetricName)
+	}
+	assert.Equal(t, `show tag values from 'cpu' with key='region' where "host"='a' limit 100`,
+		q.ShowTagValuesSQL("region", 100))
+}
+
+func TestTranslate_Error(t *testing.T) {
+	for _, promQL := range []string{
+		``,
+		`cpu + 1`,
+		`cpu > 1`,
+		`!cpu`,
+		`cpu{host="a}`,
+		`cpu{host=a}`,
+		`cpu{host}`,
+		`cpu{host="a" or}`,
+		`cpu{host="it's"}`,
+		`cpu{__name__=~"c.*"}`,
+		`{host="a"}`,
+		`cpu offset 5m`,
+		`sum`,
+		`sum cpu`,
+		`sum(cpu`,
+		`sum(cpu) by host`,
+		`sum without (host) (cpu)`,
+		`sum by (host, "a") (cpu)`,
+		`sum(sum(cpu))`,
+		`max(rate(cpu[1m]))`,
+		`rate cpu`,
+		`rate(cpu)`,
+		`rate(cpu[])`,
+		`rate(cpu[1x])`,
+		`rate(cpu[1m)`,
+		`rate(sum(cpu)[1m])`,
+		`cpu[5m]`,
+	} {
+		_, err := Translate(promQL)
+		assert.Error(t, err, promQL)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"10":    10 * time.Second,
+		"0.5":   500 * time.Millisecond,
+		"10ms":  10 * time.Millisecond,
+		"10s":   10 * time.Second,
+		"10m":   10 * time.Minute,
+		"1h30m": 90 * time.Minute,
+		"1d":    24 * time.Hour,
+		"1w":    7 * 24 * time.Hour,
+		"1y":    365 * 24 * time.Hour,
+	}
+	for str, d := range cases {
+		d1, err := ParseDuration(str)
+		assert.NoError(t, err, str)
+		assert.Equal(t, d, d1, str)
+	}
+	for _, str := range []string{"-1", "1x", "m", "1.5h", "NaN"} {
+		_, err := ParseDuration(str)
+		assert.Error(t, err, str)
+	}
+}
@@ -35,12 +35,15 @@ import (
 type queryStmtParse struct {
 	baseStmtParser
 	explain bool
+	opts    *Options
 
 	selectItems []stmt.Expr
 	fieldNames  map[string]struct{}
 
 	startTime int64
 	endTime   int64
+	// timeExprs keeps the time expressions rewritten to now(), key is now()'s start offset
+	timeExprs map[int]*timeExpr
 
 	//orderByExpr stmt.Expr
 	//desc        bool
@@ -50,9 +53,13 @@ type queryStmtParse struct {
 }
 
 // newQueryStmtParse create a query statement parser
-func newQueryStmtParse(explain bool) *queryStmtParse {
+func newQueryStmtParse(explain bool, opts *Options) *queryStmtParse {
+	if opts == nil {
+		opts = &Options{}
+	}
 	return &queryStmtParse{
 		explain:    explain,
+		opts:       opts,
 		fieldNames: make(map[string]struct{}),
 		fieldID:    1,
 		baseStmtParser: baseStmtParser{
@@ -73,6 +80,9 @@ func (q *queryStmtParse) build() (stmt.Statement, error) {
 	query.Explain = q.explain
 	query.Namespace = q.namespace
 	query.MetricName = q.metricName
+	for idx, selectItem := range q.selectItems {
+		q.selectItems[idx] = rebalanceExpr(selectItem)
+	}
 	query.SelectItems = q.selectItems
 	query.Condition = q.condition
 
@@ -89,12 +99,16 @@ func (q *queryStmtParse) build() (stmt.Statement, error) {
 
 	now := timeutil.Now()
 	query.TimeRange = timeutil.TimeRange{Start: q.startTime, End: q.endTime}
-	if query.TimeRange.Start <= 0 {
-		query.TimeRange.Start = now - timeutil.OneHour
+	timeRange := q.opts.TimeRange
+	if timeRange <= 0 {
+		timeRange = timeutil.OneHour
 	}
 	if query.TimeRange.End <= 0 {
 		query.TimeRange.End = now
 	}
+	if query.TimeRange.Start <= 0 {
+		query.TimeRange.Start = query.TimeRange.End - timeRange
+	}
 	if query.TimeRange.End < query.TimeRange.Start {
 		return nil, fmt.Errorf("start time cannot be larger than end time")
 	}
@@ -116,6 +130,18 @@ func (q *queryStmtParse) validation() error {
 	if len(q.selectItems) == 0 {
 		return fmt.Errorf("select fields cannbe be empty")
 	}
+	resultNames := make(map[string]struct{})
+	for _, selectItem := range q.selectItems {
+		item, ok := selectItem.(*stmt.SelectItem)
+		if !ok {
+			continue
+		}
+		resultName := item.ResultName()
+		if _, ok := resultNames[resultName]; ok {
+			return fmt.Errorf("duplicate field name: %s in select list, please use alias", resultName)
+		}
+		resultNames[resultName] = struct{}{}
+	}
 	return nil
 }
 
@@ -140,21 +166,15 @@ func (q *queryStmtParse) visitTimeRangeExpr(ctx *grammar.TimeRangeExprContext) {
 	timeExprCtxList := ctx.AllTimeExpr()
 	for _, timeExpr := range timeExprCtxList {
 		timeExprCtx, ok := timeExpr.(*grammar.TimeExprContext)
-		if !ok {
+		if !ok || timeExprCtx.NowExpr() == nil {
 			continue
 		}
-		var timestamp int64
-		var err error
-		switch {
-		case timeExprCtx.Ident() != nil:
-			timestamp, err = timeutil.ParseTimestamp(strutil.GetStringValue(timeExprCtx.Ident().GetText()))
-		case timeExprCtx.NowExpr() != nil:
-			timestamp = timeutil.Now()
-			durationExpr, ok := timeExprCtx.NowExpr().(*grammar.NowExprContext)
-			if ok {
-				timestamp += q.parseDuration(durationExpr.DurationLit())
-			}
+		// all time expressions are rewritten to now() before parsing
+		expr, ok := q.timeExprs[timeExprCtx.NowExpr().GetStart().GetStart()]
+		if !ok {
+			continue
 		}
+		timestamp, err := q.evalTimeExpr(expr)
 		if err != nil {
 			q.err = err
 			continue
@@ -176,6 +196,31 @@ func (q *queryStmtParse) visitTimeRangeExpr(ctx *grammar.TimeRangeExprContext) {
 	}
 }
 
+// evalTimeExpr evaluates the timestamp of time expression.
+func (q *queryStmtParse) evalTimeExpr(expr *timeExpr) (int64, error) {
+	if expr.now {
+		return timeutil.Now() + expr.duration, nil
+	}
+	timestamp, err := q.parseTimestamp(expr.literal)
+	if err != nil {
+		return 0, err
+	}
+	return timestamp + expr.duration, nil
+}
+
+// parseTimestamp parses timestamp from time literal(like '2019-04-10 08:00:00'/RFC3339)
+// or epoch of database precision, placeholder is replaced by bound parameter.
+func (q *queryStmtParse) parseTimestamp(text string) (int64, error) {
+	timeStr, err := bindParam(q.opts.Params, text)
+	if err != nil {
+		return 0, err
+	}
+	if timestamp, err := strconv.ParseInt(timeStr, 10, 64); err == nil {
+		return q.opts.Precision.ToMillis(timestamp), nil
+	}
+	return timeutil.ParseTimestampInLocation(timeStr, q.opts.Location)
+}
+
 // parseDuration parses time duration from duration string
 func (q *queryStmtParse) parseDuration(ctx grammar.IDurationLitContext) int64 {
 	if ctx == nil {
@@ -242,7 +287,8 @@ func (q *queryStmtParse) visitAlias(ctx *grammar.AliasContext) {
 	if len(q.selectItems) == 0 {
 		return
 	}
-	selectItem, ok := (q.selectItems[0]).(*stmt.SelectItem)
+	// alias belongs to the last completed select item
+	selectItem, ok := (q.selectItems[len(q.selectItems)-1]).(*stmt.SelectItem)
 	if ok {
 		selectItem.Alias = strutil.GetStringValue(ctx.Ident().GetText())
 	}
@@ -343,3 +389,72 @@ func (q *queryStmtParse) completeFieldExpr(ctx *grammar.FieldExprContext) {
 		}
 	}
 }
+
+// rebalanceExpr rebuilds the arithmetic expressions of select item with correct operator precedence.
+// Grammar gives each of +,-,*,/ its own precedence level(like a/b*c => a/(b*c), a-b+c => a-(b+c)),
+// so operands/operators of binary expression chain(not crossing parentheses) are collected in source
+// order, then rebuilt: * and / bind tighter than + and -, operators of same precedence are left associative.
+func rebalanceExpr(expr stmt.Expr) stmt.Expr {
+	switch e := expr.(type) {
+	case *stmt.SelectItem:
+		e.Expr = rebalanceExpr(e.Expr)
+	case *stmt.ParenExpr:
+		e.Expr = rebalanceExpr(e.Expr)
+	case *stmt.CallExpr:
+		for idx, param := range e.Params {
+			e.Params[idx] = rebalanceExpr(param)
+		}
+	case *stmt.BinaryExpr:
+		if !isArithmeticOP(e.Operator) {
+			return e
+		}
+		var (
+			operands  []stmt.Expr
+			operators []stmt.BinaryOP
+		)
+		flattenBinaryExpr(e, &operands, &operators)
+		for idx, operand := range operands {
+			operands[idx] = rebalanceExpr(operand)
+		}
+		return buildBinaryExpr(operands, operators)
+	}
+	return expr
+}
+
+// flattenBinaryExpr collects operands/operators of arithmetic binary expression chain in source order.
+func flattenBinaryExpr(expr stmt.Expr, operands *[]stmt.Expr, operators *[]stmt.BinaryOP) {
+	binaryExpr, ok := expr.(*stmt.BinaryExpr)
+	if !ok || !isArithmeticOP(binaryExpr.Operator) {
+		*operands = append(*operands, expr)
+		return
+	}
+	flattenBinaryExpr(binaryExpr.Left, operands, operators)
+	*operators = append(*operators, binaryExpr.Operator)
+	flattenBinaryExpr(binaryExpr.Right, operands, operators)
+}
+
+// buildBinaryExpr builds binary expression from operands/operators in source order,
+// folds * and / first, then + and -, both are left associative.
+func buildBinaryExpr(operands []stmt.Expr, operators []stmt.BinaryOP) stmt.Expr {
+	terms := []stmt.Expr{operands[0]}
+	var termOperators []stmt.BinaryOP
+	for idx, op := range operators {
+		if op == stmt.MUL || op == stmt.DIV {
+			last := len(terms) - 1
+			terms[last] = &stmt.BinaryExpr{Left: terms[last], Operator: op, Right: operands[idx+1]}
+			continue
+		}
+		terms = append(terms, operands[idx+1])
+		termOperators = append(termOperators, op)
+	}
+	result := terms[0]
+	for idx, op := range termOperators {
+		result = &stmt.BinaryExpr{Left: result, Operator: op, Right: terms[idx+1]}
+	}
+	return result
+}
+
+// isArithmeticOP checks if the binary operator is arithmetic operator(+,-,*,/).
+func isArithmeticOP(op stmt.BinaryOP) bool {
+	return op == stmt.ADD || op == stmt.SUB || op == stmt.MUL || op == stmt.DIV
+}
@@ -19,7 +19,9 @@ package sql
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -30,7 +32,7 @@ import (
 )
 
 func TestQueryStmt_validation(t *testing.T) {
-	queryStmt := newQueryStmtParse(false)
+	queryStmt := newQueryStmtParse(false, nil)
 	// case 1: stmt err
 	queryStmt.err = fmt.Errorf("err")
 	s, err := queryStmt.build()
@@ -112,6 +114,27 @@ func TestSingleSelectItem(t *testing.T) {
 	assert.Equal(t, []string{"a", "d", "f"}, query.FieldNames)
 }
 
+func TestSelectItem_Alias(t *testing.T) {
+	q, err := Parse("select f as usage, sum(g) as total, max(g) from cpu")
+	assert.NoError(t, err)
+	query := q.(*stmt.Query)
+	assert.Equal(t, []stmt.Expr{
+		&stmt.SelectItem{Expr: &stmt.FieldExpr{Name: "f"}, Alias: "usage"},
+		&stmt.SelectItem{Expr: &stmt.CallExpr{FuncType: function.Sum, Params: []stmt.Expr{&stmt.FieldExpr{Name: "g"}}},
+			Alias: "total"},
+		&stmt.SelectItem{Expr: &stmt.CallExpr{FuncType: function.Max, Params: []stmt.Expr{&stmt.FieldExpr{Name: "g"}}}},
+	}, query.SelectItems)
+	assert.Equal(t, []string{"usage", "total", "max(g)"}, query.ResultFields())
+
+	// duplicate result name
+	_, err = Parse("select f as usage, sum(g) as usage from cpu")
+	assert.Error(t, err)
+	_, err = Parse("select sum(g), max(f) as 'sum(g)' from cpu")
+	assert.Error(t, err)
+	_, err = Parse("select f, f from cpu")
+	assert.Error(t, err)
+}
+
 func TestSelectFuncItem(t *testing.T) {
 	sql := "select count(f) from memory"
 	q, err := Parse(sql)
@@ -291,6 +314,41 @@ func TestMathExpress(t *testing.T) {
 		query.SelectItems)
 }
 
+func TestMathExpress_Precedence(t *testing.T) {
+	field := func(name string) stmt.Expr {
+		return &stmt.FieldExpr{Name: name}
+	}
+	binary := func(left stmt.Expr, op stmt.BinaryOP, right stmt.Expr) stmt.Expr {
+		return &stmt.BinaryExpr{Left: left, Operator: op, Right: right}
+	}
+	cases := []struct {
+		sql  string
+		expr stmt.Expr
+	}{
+		{sql: "a/b*c", expr: binary(binary(field("a"), stmt.DIV, field("b")), stmt.MUL, field("c"))},
+		{sql: "a-b+c", expr: binary(binary(field("a"), stmt.SUB, field("b")), stmt.ADD, field("c"))},
+		{sql: "a-b-c", expr: binary(binary(field("a"), stmt.SUB, field("b")), stmt.SUB, field("c"))},
+		{sql: "a-b*c+d", expr: binary(binary(field("a"), stmt.SUB, binary(field("b"), stmt.MUL, field("c"))),
+			stmt.ADD, field("d"))},
+		{sql: "a-(b+c)", expr: binary(field("a"), stmt.SUB, &stmt.ParenExpr{Expr: binary(field("b"), stmt.ADD, field("c"))})},
+		{sql: "sum(a)/max(b)*100", expr: binary(binary(
+			&stmt.CallExpr{FuncType: function.Sum, Params: []stmt.Expr{field("a")}},
+			stmt.DIV,
+			&stmt.CallExpr{FuncType: function.Max, Params: []stmt.Expr{field("b")}}),
+			stmt.MUL, &stmt.NumberLiteral{Val: 100})},
+		{sql: "sum(a-b+c)", expr: &stmt.CallExpr{FuncType: function.Sum, Params: []stmt.Expr{
+			binary(binary(field("a"), stmt.SUB, field("b")), stmt.ADD, field("c"))}}},
+	}
+	for _, c := range cases {
+		q, err := Parse("select " + c.sql + " from cpu")
+		assert.NoError(t, err, c.sql)
+		selectItem := q.(*stmt.Query).SelectItems[0].(*stmt.SelectItem)
+		assert.Equal(t, c.expr, selectItem.Expr, c.sql)
+		// result name keeps source order
+		assert.Equal(t, c.sql, strings.ReplaceAll(selectItem.Rewrite(), "100.00", "100"))
+	}
+}
+
 func TestLimit(t *testing.T) {
 	sql := "select f from cpu limit 10"
 	q, err := Parse(sql)
@@ -327,6 +385,79 @@ func TestTimeRange(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestTimeRange_Options(t *testing.T) {
+	// default time range
+	sql := "select f from cpu where time<'20190410 10:00:00'"
+	q, err := ParseWithOptions(sql, &Options{TimeRange: timeutil.OneDay})
+	assert.NoError(t, err)
+	query := q.(*stmt.Query)
+	endTime, _ := timeutil.ParseTimestamp("20190410 10:00:00")
+	assert.Equal(t, endTime, query.TimeRange.End)
+	assert.Equal(t, endTime-timeutil.OneDay, query.TimeRange.Start)
+
+	// time literal in location
+	sql = "select f from cpu where time>'2019-04-10 08:00:00' and time<'2019-04-10 10:00:00'"
+	q, err = ParseWithOptions(sql, &Options{Location: time.UTC})
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	startTime, _ := timeutil.ParseTimestampInLocation("2019-04-10 08:00:00", time.UTC)
+	assert.Equal(t, startTime, query.TimeRange.Start)
+	assert.Equal(t, startTime+2*timeutil.OneHour, query.TimeRange.End)
+}
+
+func TestTimeRange_Expr(t *testing.T) {
+	// now with duration arithmetic
+	now := timeutil.Now()
+	q, err := Parse("select f from cpu where time > now() - 1h - 30m and time < now() + 1d - 2h")
+	assert.NoError(t, err)
+	query := q.(*stmt.Query)
+	assert.InDelta(t, now-timeutil.OneHour-30*timeutil.OneMinute, query.TimeRange.Start, float64(timeutil.OneMinute))
+	assert.InDelta(t, now+timeutil.OneDay-2*timeutil.OneHour, query.TimeRange.End, float64(timeutil.OneMinute))
+
+	// RFC3339 and epoch milliseconds
+	q, err = Parse("select f from cpu where time>='2019-04-10T08:00:00Z'-1h and time<=1554886800000 and host='a'")
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	assert.Equal(t, timeutil.TimeRange{Start: 1554879600000, End: 1554886800000}, query.TimeRange)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "host", Value: "a"}, query.Condition)
+
+	// time literal in location with duration
+	q, err = ParseWithOptions("select f from cpu where time>'2019-04-10 08:00:00'+1w", &Options{Location: time.UTC})
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	assert.Equal(t, int64(1554883200000)+timeutil.OneWeek, query.TimeRange.Start)
+
+	// placeholder with duration
+	q, err = ParseWithOptions("select f from cpu where time>$start-1d and time<$end",
+		&Options{Params: map[string]string{"start": "1554883200000", "end": "2019-04-10T10:00:00Z"}})
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	assert.Equal(t, timeutil.TimeRange{Start: 1554883200000 - timeutil.OneDay, End: 1554890400000}, query.TimeRange)
+
+	// invalid time expression
+	for _, sql := range []string{
+		"select f from cpu where time>now(",
+		"select f from cpu where time>now()-",
+		"select f from cpu where time>now()-1",
+		"select f from cpu where time>now()-h",
+		"select f from cpu where time>now()-1x",
+		"select f from cpu where time>now()-1h+",
+		"select f from cpu where time>(now())",
+		"select f from cpu where time>'2019-04-10T08:00'",
+		"select f from cpu where time>now()-1h-1q",
+		"select f from cpu where time>'2019-04-10T08:00:00Z'-1.5h",
+	} {
+		_, err = Parse(sql)
+		assert.Error(t, err, sql)
+	}
+	// placeholder not bound or bad bound value
+	_, err = Parse("select f from cpu where time>$start-1d")
+	assert.Error(t, err)
+	_, err = ParseWithOptions("select f from cpu where time>$start-1d",
+		&Options{Params: map[string]string{"start": "yesterday"}})
+	assert.Error(t, err)
+}
+
 func TestInterval(t *testing.T) {
 	sql := "select f from cpu where region='sh'"
 	q, err := Parse(sql)
@@ -442,6 +573,54 @@ func TestRegexExpr(t *testing.T) {
 	assert.Equal(t, stmt.NotExpr{Expr: &stmt.RegexExpr{Key: "ip", Regexp: "/1.1.*.1/"}}, *notExpr)
 }
 
+func TestRangeExpr(t *testing.T) {
+	sql := "select f from cpu where shard_id >= 100 and shard_id < 200 and time>now()-1h"
+	q, err := Parse(sql)
+	assert.NoError(t, err)
+	query := q.(*stmt.Query)
+	expr := query.Condition.(*stmt.BinaryExpr)
+	assert.Equal(t,
+		stmt.BinaryExpr{
+			Left:     &stmt.RangeExpr{Key: "shard_id", Op: stmt.GreaterEqual, Value: "100"},
+			Operator: stmt.AND,
+			Right:    &stmt.RangeExpr{Key: "shard_id", Op: stmt.LessThan, Value: "200"},
+		}, *expr)
+	assert.True(t, query.TimeRange.Start > 0)
+
+	sql = "select f from cpu where ip='1.1.1.1' and (load > -1.5 or load<='3') group by ip limit 10"
+	q, err = Parse(sql)
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	expr = query.Condition.(*stmt.BinaryExpr)
+	assert.Equal(t,
+		stmt.BinaryExpr{
+			Left:     &stmt.EqualsExpr{Key: "ip", Value: "1.1.1.1"},
+			Operator: stmt.AND,
+			Right: &stmt.ParenExpr{Expr: &stmt.BinaryExpr{
+				Left:     &stmt.RangeExpr{Key: "load", Op: stmt.GreaterThan, Value: "-1.5"},
+				Operator: stmt.OR,
+				Right:    &stmt.RangeExpr{Key: "load", Op: stmt.LessEqual, Value: "3"},
+			}},
+		}, *expr)
+	assert.Equal(t, []string{"ip"}, query.GroupBy)
+	assert.Equal(t, 10, query.Limit)
+
+	// regexp filter not changed
+	sql = "select f from cpu where ip=~'1.1.*' and shard_id>1"
+	q, err = Parse(sql)
+	assert.NoError(t, err)
+	query = q.(*stmt.Query)
+	expr = query.Condition.(*stmt.BinaryExpr)
+	assert.Equal(t, &stmt.RegexExpr{Key: "ip", Regexp: "1.1.*"}, expr.Left)
+	assert.Equal(t, &stmt.RangeExpr{Key: "shard_id", Op: stmt.GreaterThan, Value: "1"}, expr.Right)
+
+	// value not numeric
+	sql = "select f from cpu where shard_id >= 'abc'"
+	q, err = Parse(sql)
+	assert.Error(t, err)
+	assert.Nil(t, q)
+}
+
 func TestInExpr(t *testing.T) {
 	sql := "select f from cpu where ip in ('1.1.1.1','2.2.2.2')"
 	q, _ := Parse(sql)
@@ -0,0 +1,175 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+
+	"github.com/lindb/lindb/pkg/strutil"
+	"github.com/lindb/lindb/sql/grammar"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+// schemaStmtParser parses the schema statement(create database/drop database/show schemas) by tokens directly,
+// like state statement, grammar only supports query/metadata statement.
+//
+// create database <name> [with (key=value, ...)]
+// drop database <name>
+// show schemas
+type schemaStmtParser struct {
+	tokens []antlr.Token
+	pos    int
+}
+
+// parseSchemaStmt parses the schema statement, returns false if sql isn't a schema statement.
+func parseSchemaStmt(tokens *antlr.CommonTokenStream) (stmt.Statement, bool, error) {
+	tokens.Fill()
+	p := &schemaStmtParser{}
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		p.tokens = append(p.tokens, token)
+	}
+	if len(p.tokens) < 2 {
+		return nil, false, nil
+	}
+	first, second := p.tokens[0].GetTokenType(), p.tokens[1]
+	switch {
+	case first == grammar.SQLLexerT_SHOW && second.GetTokenType() == grammar.SQLLexerL_ID &&
+		strings.EqualFold(second.GetText(), "schemas"):
+		p.pos = 2
+		if err := p.expectEnd(); err != nil {
+			return nil, true, err
+		}
+		return &stmt.Schema{Type: stmt.DatabaseSchemasSchemaType}, true, nil
+	case first == grammar.SQLLexerT_DROP && second.GetTokenType() == grammar.SQLLexerT_DATASBAE:
+		p.pos = 2
+		name, err := p.word("database name")
+		if err != nil {
+			return nil, true, err
+		}
+		if err := p.expectEnd(); err != nil {
+			return nil, true, err
+		}
+		return &stmt.Schema{Type: stmt.DropDatabaseSchemaType, Database: name}, true, nil
+	case first == grammar.SQLLexerT_CREATE && second.GetTokenType() == grammar.SQLLexerT_DATASBAE:
+		p.pos = 2
+		schema, err := p.createDatabase()
+		if err != nil {
+			return nil, true, err
+		}
+		return schema, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// createDatabase parses the database name and options of create database statement.
+func (p *schemaStmtParser) createDatabase() (*stmt.Schema, error) {
+	name, err := p.word("database name")
+	if err != nil {
+		return nil, err
+	}
+	schema := &stmt.Schema{Type: stmt.CreateDatabaseSchemaType, Database: name}
+	if p.pos == len(p.tokens) {
+		return schema, nil
+	}
+	if err := p.expect(grammar.SQLLexerT_WITH, "with"); err != nil {
+		return nil, err
+	}
+	if err := p.expect(grammar.SQLLexerT_OPEN_P, "("); err != nil {
+		return nil, err
+	}
+	for {
+		key, err := p.word("option name")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(grammar.SQLLexerT_EQUAL, "="); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		schema.Options = append(schema.Options, stmt.SchemaOption{Key: key, Value: value})
+		if p.pos < len(p.tokens) && p.tokens[p.pos].GetTokenType() == grammar.SQLLexerT_COMMA {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if err := p.expect(grammar.SQLLexerT_CLOSE_P, ")"); err != nil {
+		return nil, err
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// word returns the text of identifier or keyword, keyword can be used as name, like interval.
+func (p *schemaStmtParser) word(expected string) (string, error) {
+	if p.pos == len(p.tokens) {
+		return "", fmt.Errorf("expect %s, but sql is end", expected)
+	}
+	token := p.tokens[p.pos]
+	text := token.GetText()
+	if token.GetTokenType() != grammar.SQLLexerL_ID && !unicode.IsLetter([]rune(text)[0]) {
+		return "", fmt.Errorf("expect %s, but got '%s'", expected, text)
+	}
+	p.pos++
+	return strutil.GetStringValue(text), nil
+}
+
+// value returns the text of option value, which is identifier/keyword/number.
+func (p *schemaStmtParser) value() (string, error) {
+	if p.pos < len(p.tokens) {
+		switch p.tokens[p.pos].GetTokenType() {
+		case grammar.SQLLexerL_INT, grammar.SQLLexerL_DEC:
+			p.pos++
+			return p.tokens[p.pos-1].GetText(), nil
+		}
+	}
+	return p.word("option value")
+}
+
+// expect consumes the token with the token type.
+func (p *schemaStmtParser) expect(tokenType int, text string) error {
+	if p.pos == len(p.tokens) {
+		return fmt.Errorf("expect '%s', but sql is end", text)
+	}
+	if p.tokens[p.pos].GetTokenType() != tokenType {
+		return fmt.Errorf("expect '%s', but got '%s'", text, p.tokens[p.pos].GetText())
+	}
+	p.pos++
+	return nil
+}
+
+// expectEnd checks if all tokens are consumed.
+func (p *schemaStmtParser) expectEnd() error {
+	if p.pos != len(p.tokens) {
+		return fmt.Errorf("unexpected '%s' at end of schema statement", p.tokens[p.pos].GetText())
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestSchema_SQL_Parse(t *testing.T) {
+	query, err := Parse("show schemas")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Schema{Type: stmt.DatabaseSchemasSchemaType}, query)
+	query, err = Parse("  SHOW Schemas ")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Schema{Type: stmt.DatabaseSchemasSchemaType}, query)
+
+	query, err = Parse("drop database test")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Schema{Type: stmt.DropDatabaseSchemaType, Database: "test"}, query)
+	query, err = Parse("DROP DATABASE 'test.db'")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Schema{Type: stmt.DropDatabaseSchemaType, Database: "test.db"}, query)
+
+	query, err = Parse("create database test")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Schema{Type: stmt.CreateDatabaseSchemaType, Database: "test"}, query)
+	query, err = Parse("create database test with (cluster=dev, numOfShard=8, replicaFactor=2, interval='10s', " +
+		"rollup=\"1m,1h\", autoCreateNS=true, writeWeight=1.5)")
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.Schema{
+		Type:     stmt.CreateDatabaseSchemaType,
+		Database: "test",
+		Options: []stmt.SchemaOption{
+			{Key: "cluster", Value: "dev"},
+			{Key: "numOfShard", Value: "8"},
+			{Key: "replicaFactor", Value: "2"},
+			{Key: "interval", Value: "10s"},
+			{Key: "rollup", Value: "1m,1h"},
+			{Key: "autoCreateNS", Value: "true"},
+			{Key: "writeWeight", Value: "1.5"},
+		},
+	}, query)
+}
+
+func TestSchema_SQL_Parse_Error(t *testing.T) {
+	cases := []string{
+		"show schemas on db",
+		"drop database",
+		"drop database (",
+		"drop database test test",
+		"create database",
+		"create database test numOfShard=8",
+		"create database test with numOfShard=8",
+		"create database test with (numOfShard 8)",
+		"create database test with (numOfShard=)",
+		"create database test with (numOfShard=8",
+		"create database test with (numOfShard=8,)",
+		"create database test with (=8)",
+		"create database test with (numOfShard=8) test",
+	}
+	for _, sql := range cases {
+		query, err := Parse(sql)
+		assert.Error(t, err, sql)
+		assert.Nil(t, query, sql)
+	}
+}
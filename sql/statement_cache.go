@@ -0,0 +1,84 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"container/list"
+	"sync"
+)
+
+// StatementCache caches the prepared statements keyed by sql text based on lru list,
+// structurally identical queries(like dashboard queries with placeholders) need not be parsed again.
+type StatementCache struct {
+	capacity   int
+	statements map[string]*list.Element
+	lru        *list.List // front is the most recent used
+	mutex      sync.Mutex
+}
+
+// NewStatementCache creates the statement cache, capacity is the max number of cached statements.
+func NewStatementCache(capacity int) *StatementCache {
+	return &StatementCache{
+		capacity:   capacity,
+		statements: make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Prepare returns the prepared statement from cache, parses sql if not cached,
+// only statement prepared successfully is cached.
+func (c *StatementCache) Prepare(sql string) (*PreparedStatement, error) {
+	c.mutex.Lock()
+	elem, ok := c.statements[sql]
+	if ok {
+		c.lru.MoveToFront(elem)
+		c.mutex.Unlock()
+		return elem.Value.(*PreparedStatement), nil
+	}
+	c.mutex.Unlock()
+
+	// parse sql without lock, statement maybe prepared by others concurrently
+	prepared, err := Prepare(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.statements[sql]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*PreparedStatement), nil
+	}
+	c.statements[sql] = c.lru.PushFront(prepared)
+	// evict the least recent used statements
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.statements, oldest.Value.(*PreparedStatement).sql)
+	}
+	return prepared, nil
+}
+
+// Len returns the number of cached statements.
+func (c *StatementCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lru.Len()
+}
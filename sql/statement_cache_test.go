@@ -0,0 +1,55 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementCache_Prepare(t *testing.T) {
+	cache := NewStatementCache(2)
+	// parse error not cached
+	_, err := cache.Prepare("select f from")
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.Len())
+
+	p1, err := cache.Prepare("select f from cpu where host=$host")
+	assert.NoError(t, err)
+	p2, err := cache.Prepare("select f from cpu where host=$host")
+	assert.NoError(t, err)
+	assert.Same(t, p1, p2)
+	assert.Equal(t, 1, cache.Len())
+
+	_, err = cache.Prepare("select f from memory")
+	assert.NoError(t, err)
+	// p1 is most recent used
+	p2, err = cache.Prepare("select f from cpu where host=$host")
+	assert.NoError(t, err)
+	assert.Same(t, p1, p2)
+	// evict memory
+	_, err = cache.Prepare("show replication")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cache.Len())
+	p2, err = cache.Prepare("select f from cpu where host=$host")
+	assert.NoError(t, err)
+	assert.Same(t, p1, p2)
+	_, ok := cache.statements["select f from memory"]
+	assert.False(t, ok)
+}
@@ -125,11 +125,26 @@ type RegexExpr struct {
 	Regexp string `json:"regexp"`
 }
 
+// RangeExpr represents a range comparison of numeric tag value, like shard_id >= 100
+type RangeExpr struct {
+	Key   string  `json:"key"`
+	Op    RangeOP `json:"op"`
+	Value string  `json:"value"`
+}
+
 // NotExpr represents a not expression
 type NotExpr struct {
 	Expr Expr
 }
 
+// ResultName returns the field name of select item in result set, alias if set.
+func (e *SelectItem) ResultName() string {
+	if len(e.Alias) > 0 {
+		return e.Alias
+	}
+	return e.Expr.Rewrite()
+}
+
 // Rewrite rewrites the select item expr after parse
 func (e *SelectItem) Rewrite() string {
 	if len(e.Alias) == 0 {
@@ -192,6 +207,11 @@ func (e *RegexExpr) Rewrite() string {
 	return fmt.Sprintf("%s=~%s", e.Key, e.Regexp)
 }
 
+// Rewrite rewrites the range expr after parse
+func (e *RangeExpr) Rewrite() string {
+	return fmt.Sprintf("%s%s%s", e.Key, RangeOPString(e.Op), e.Value)
+}
+
 // Marshal returns json of expr using custom json marshal
 func Marshal(expr Expr) []byte {
 	switch e := expr.(type) {
@@ -199,6 +219,8 @@ func Marshal(expr Expr) []byte {
 		return encoding.JSONMarshal(&exprData{Type: "regex", Expr: encoding.JSONMarshal(expr)})
 	case *LikeExpr:
 		return encoding.JSONMarshal(&exprData{Type: "like", Expr: encoding.JSONMarshal(expr)})
+	case *RangeExpr:
+		return encoding.JSONMarshal(&exprData{Type: "range", Expr: encoding.JSONMarshal(expr)})
 	case *InExpr:
 		return encoding.JSONMarshal(&exprData{Type: "in", Expr: encoding.JSONMarshal(expr)})
 	case *EqualsExpr:
@@ -254,6 +276,8 @@ func Unmarshal(value []byte) (Expr, error) {
 		return unmarshal(&exprData, &RegexExpr{})
 	case "like":
 		return unmarshal(&exprData, &LikeExpr{})
+	case "range":
+		return unmarshal(&exprData, &RangeExpr{})
 	case "in":
 		return unmarshal(&exprData, &InExpr{})
 	case "equals":
@@ -359,3 +383,6 @@ func (e *LikeExpr) TagKey() string { return e.Key }
 
 // TagKey returns the regex filter's tag key
 func (e *RegexExpr) TagKey() string { return e.Key }
+
+// TagKey returns the range filter's tag key
+func (e *RangeExpr) TagKey() string { return e.Key }
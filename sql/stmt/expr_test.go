@@ -29,6 +29,8 @@ func TestExpr_Rewrite(t *testing.T) {
 	assert.Equal(t, "f", (&SelectItem{Expr: &FieldExpr{Name: "f"}}).Rewrite())
 	assert.Equal(t, "1.90", (&SelectItem{Expr: &NumberLiteral{Val: 1.9}}).Rewrite())
 	assert.Equal(t, "f as f1", (&SelectItem{Expr: &FieldExpr{Name: "f"}, Alias: "f1"}).Rewrite())
+	assert.Equal(t, "f", (&SelectItem{Expr: &FieldExpr{Name: "f"}}).ResultName())
+	assert.Equal(t, "f1", (&SelectItem{Expr: &FieldExpr{Name: "f"}, Alias: "f1"}).ResultName())
 
 	assert.Equal(t, "f", (&FieldExpr{Name: "f"}).Rewrite())
 
@@ -62,6 +64,8 @@ func TestExpr_Rewrite(t *testing.T) {
 	assert.Equal(t, "tagKey in ()", (&InExpr{Key: "tagKey"}).Rewrite())
 
 	assert.Equal(t, "tagKey=~Regexp", (&RegexExpr{Key: "tagKey", Regexp: "Regexp"}).Rewrite())
+
+	assert.Equal(t, "tagKey>=100", (&RangeExpr{Key: "tagKey", Op: GreaterEqual, Value: "100"}).Rewrite())
 }
 
 func TestTagFilter(t *testing.T) {
@@ -69,6 +73,7 @@ func TestTagFilter(t *testing.T) {
 	assert.Equal(t, "tagKey", (&LikeExpr{Key: "tagKey", Value: "tagValue"}).TagKey())
 	assert.Equal(t, "tagKey", (&InExpr{Key: "tagKey", Values: []string{"a", "b", "c"}}).TagKey())
 	assert.Equal(t, "tagKey", (&RegexExpr{Key: "tagKey", Regexp: "Regexp"}).TagKey())
+	assert.Equal(t, "tagKey", (&RangeExpr{Key: "tagKey", Op: LessThan, Value: "1"}).TagKey())
 }
 
 func TestExpr_Marshal_Fail(t *testing.T) {
@@ -122,6 +127,14 @@ func TestLikeExpr_Marshal(t *testing.T) {
 	assert.Equal(t, *expr, *e)
 }
 
+func TestRangeExpr_Marshal(t *testing.T) {
+	expr := &RangeExpr{Key: "tagKey", Op: LessEqual, Value: "200"}
+	data := Marshal(expr)
+	exprData, _ := Unmarshal(data)
+	e := exprData.(*RangeExpr)
+	assert.Equal(t, *expr, *e)
+}
+
 func TestInExpr_Marshal(t *testing.T) {
 	expr := &InExpr{Key: "tagKey"}
 	data := Marshal(expr)
@@ -34,6 +34,9 @@ const (
 	TagKey
 	TagValue
 	Field
+	SeriesCardinality
+	TagValueCardinality
+	MetricDescriptor
 )
 
 // String returns string value of metadata type
@@ -51,6 +54,12 @@ func (m MetadataType) String() string {
 		return "tagKey"
 	case TagValue:
 		return "tagValue"
+	case SeriesCardinality:
+		return "seriesCardinality"
+	case TagValueCardinality:
+		return "tagValueCardinality"
+	case MetricDescriptor:
+		return "metricDescriptor"
 	default:
 		return unknown
 	}
@@ -63,8 +72,12 @@ type Metadata struct {
 	Type       MetadataType // metadata suggest type
 	TagKey     string
 	Prefix     string
-	Condition  Expr // tag filter condition expression
-	Limit      int  // result set limit
+	Regex      string // regular expression which suggest values must match
+	Condition  Expr   // tag filter condition expression
+	Limit      int    // result set limit
+	Offset     int    // number of matched values to skip
+	BySeries   bool   // rank tag values by created series instead of written points for tag value cardinality
+	Window     int64  // window(millisecond) of tag value cardinality
 }
 
 // innerMetadata represents a wrapper of metadata for json encoding
@@ -75,7 +88,11 @@ type innerMetadata struct {
 	TagKey     string          `json:"tagKey,omitempty"`
 	Condition  json.RawMessage `json:"condition,omitempty"`
 	Prefix     string          `json:"prefix,omitempty"`
+	Regex      string          `json:"regex,omitempty"`
 	Limit      int             `json:"limit,omitempty"`
+	Offset     int             `json:"offset,omitempty"`
+	BySeries   bool            `json:"bySeries,omitempty"`
+	Window     int64           `json:"window,omitempty"`
 }
 
 // MarshalJSON returns json data of query
@@ -87,7 +104,11 @@ func (q *Metadata) MarshalJSON() ([]byte, error) {
 		TagKey:     q.TagKey,
 		Type:       q.Type,
 		Prefix:     q.Prefix,
+		Regex:      q.Regex,
 		Limit:      q.Limit,
+		Offset:     q.Offset,
+		BySeries:   q.BySeries,
+		Window:     q.Window,
 	}
 	return encoding.JSONMarshal(&inner), nil
 }
@@ -110,6 +131,10 @@ func (q *Metadata) UnmarshalJSON(value []byte) error {
 	q.Type = inner.Type
 	q.TagKey = inner.TagKey
 	q.Prefix = inner.Prefix
+	q.Regex = inner.Regex
 	q.Limit = inner.Limit
+	q.Offset = inner.Offset
+	q.BySeries = inner.BySeries
+	q.Window = inner.Window
 	return nil
 }
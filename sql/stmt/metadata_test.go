@@ -32,6 +32,9 @@ func TestMetadataType_String(t *testing.T) {
 	assert.Equal(t, "field", Field.String())
 	assert.Equal(t, "tagKey", TagKey.String())
 	assert.Equal(t, "tagValue", TagValue.String())
+	assert.Equal(t, "seriesCardinality", SeriesCardinality.String())
+	assert.Equal(t, "tagValueCardinality", TagValueCardinality.String())
+	assert.Equal(t, "metricDescriptor", MetricDescriptor.String())
 	assert.Equal(t, "unknown", MetadataType(0).String())
 }
 
@@ -55,7 +58,10 @@ func TestMetadata_MarshalJSON(t *testing.T) {
 		},
 		TagKey: "tagKey",
 		Prefix: "prefix",
+		Regex:  "^pre.*x$",
 		Limit:  100,
+		Offset: 10,
+		Window: 60000,
 	}
 
 	data := encoding.JSONMarshal(&query)
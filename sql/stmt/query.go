@@ -38,6 +38,26 @@ type Query struct {
 
 	GroupBy []string // group by tag keys
 	Limit   int      // num. of time series list for result
+
+	// Exemplars is true if query requires returning the exemplars of histogram series with results.
+	Exemplars bool
+
+	// SubQuery is the inner query of from clause, which is executed through the normal query pipeline,
+	// then the query aggregates the result of inner query on broker, it isn't sent to storage.
+	SubQuery *Query
+}
+
+// ResultFields returns the field names of result set in order of select list, alias is used if set.
+func (q *Query) ResultFields() []string {
+	var fields []string
+	for _, selectItem := range q.SelectItems {
+		if item, ok := selectItem.(*SelectItem); ok {
+			fields = append(fields, item.ResultName())
+		} else {
+			fields = append(fields, selectItem.Rewrite())
+		}
+	}
+	return fields
 }
 
 // HasGroupBy returns whether query has group by tag keys
@@ -59,6 +79,8 @@ type innerQuery struct {
 
 	GroupBy []string `json:"groupBy,omitempty"`
 	Limit   int      `json:"limit,omitempty"`
+
+	Exemplars bool `json:"exemplars,omitempty"`
 }
 
 // MarshalJSON returns json data of query
@@ -73,6 +95,7 @@ func (q *Query) MarshalJSON() ([]byte, error) {
 		Interval:   q.Interval,
 		GroupBy:    q.GroupBy,
 		Limit:      q.Limit,
+		Exemplars:  q.Exemplars,
 	}
 	for _, item := range q.SelectItems {
 		inner.SelectItems = append(inner.SelectItems, Marshal(item))
@@ -110,5 +133,6 @@ func (q *Query) UnmarshalJSON(value []byte) error {
 	q.Interval = inner.Interval
 	q.GroupBy = inner.GroupBy
 	q.Limit = inner.Limit
+	q.Exemplars = inner.Exemplars
 	return nil
 }
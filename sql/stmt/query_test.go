@@ -67,6 +67,7 @@ func TestQuery_Marshal(t *testing.T) {
 		Interval:  1000,
 		GroupBy:   []string{"a", "b", "c"},
 		Limit:     100,
+		Exemplars: true,
 	}
 
 	data := encoding.JSONMarshal(&query)
@@ -0,0 +1,44 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stmt
+
+// RangeOP represents range comparison operation type of numeric tag value
+type RangeOP int
+
+const (
+	LessThan RangeOP = iota + 1
+	LessEqual
+	GreaterThan
+	GreaterEqual
+)
+
+// RangeOPString returns the range operator's string value
+func RangeOPString(op RangeOP) string {
+	switch op {
+	case LessThan:
+		return "<"
+	case LessEqual:
+		return "<="
+	case GreaterThan:
+		return ">"
+	case GreaterEqual:
+		return ">="
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,55 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stmt
+
+// SchemaType represents the type of schema statement
+type SchemaType uint8
+
+// Defines all types of schema statement
+const (
+	CreateDatabaseSchemaType SchemaType = iota + 1
+	DropDatabaseSchemaType
+	DatabaseSchemasSchemaType
+)
+
+// String returns string value of schema type
+func (s SchemaType) String() string {
+	switch s {
+	case CreateDatabaseSchemaType:
+		return "createDatabase"
+	case DropDatabaseSchemaType:
+		return "dropDatabase"
+	case DatabaseSchemasSchemaType:
+		return "schemas"
+	default:
+		return unknown
+	}
+}
+
+// SchemaOption represents the option of create database statement, like numOfShard=8
+type SchemaOption struct {
+	Key   string
+	Value string
+}
+
+// Schema represents database lifecycle statement, like create database/drop database/show schemas
+type Schema struct {
+	Type     SchemaType     // schema type
+	Database string         // database name
+	Options  []SchemaOption // options of create database, keep the order in sql
+}
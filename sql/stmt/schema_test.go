@@ -0,0 +1,31 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaType_String(t *testing.T) {
+	assert.Equal(t, "createDatabase", CreateDatabaseSchemaType.String())
+	assert.Equal(t, "dropDatabase", DropDatabaseSchemaType.String())
+	assert.Equal(t, "schemas", DatabaseSchemasSchemaType.String())
+	assert.Equal(t, "unknown", SchemaType(0).String())
+}
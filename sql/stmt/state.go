@@ -0,0 +1,41 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stmt
+
+// StateType represents the type of state statement
+type StateType uint8
+
+// Defines all types of state statement
+const (
+	ReplicationState StateType = iota + 1
+)
+
+// String returns string value of state type
+func (s StateType) String() string {
+	switch s {
+	case ReplicationState:
+		return "replication"
+	default:
+		return unknown
+	}
+}
+
+// State represents show internal state statement, like show replication
+type State struct {
+	Type StateType // state type
+}
@@ -0,0 +1,173 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+
+	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/sql/grammar"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+// subQueryMetricName is the metric name which replaces the subquery of outer query before parsing
+const subQueryMetricName = "__subquery"
+
+// subQueryFuncs represents the aggregation functions which can be used in outer query
+var subQueryFuncs = map[function.FuncType]struct{}{
+	function.Sum:   {},
+	function.Min:   {},
+	function.Max:   {},
+	function.Count: {},
+	function.Avg:   {},
+}
+
+// splitSubQuery splits the sql into outer query and inner query if from clause is a subquery, like
+// select max(mean_f) from (select avg(f) as mean_f from cpu group by host, time(1m)) group by time(5m),
+// the subquery of outer query is replaced by metric name placeholder, returns empty inner sql if no subquery.
+func splitSubQuery(sql string, tokens *antlr.CommonTokenStream) (outer, inner string, err error) {
+	tokens.Fill()
+	var defaultTokens []antlr.Token
+	for _, token := range tokens.GetAllTokens() {
+		if token.GetChannel() != antlr.TokenDefaultChannel || token.GetTokenType() == antlr.TokenEOF {
+			continue
+		}
+		defaultTokens = append(defaultTokens, token)
+	}
+	for idx := 0; idx+2 < len(defaultTokens); idx++ {
+		if defaultTokens[idx].GetTokenType() != grammar.SQLLexerT_FROM ||
+			defaultTokens[idx+1].GetTokenType() != grammar.SQLLexerT_OPEN_P ||
+			defaultTokens[idx+2].GetTokenType() != grammar.SQLLexerT_SELECT {
+			continue
+		}
+		open := defaultTokens[idx+1]
+		depth := 0
+		for closeIdx := idx + 1; closeIdx < len(defaultTokens); closeIdx++ {
+			switch defaultTokens[closeIdx].GetTokenType() {
+			case grammar.SQLLexerT_OPEN_P:
+				depth++
+			case grammar.SQLLexerT_CLOSE_P:
+				depth--
+			}
+			if depth > 0 {
+				continue
+			}
+			if closeIdx+1 < len(defaultTokens) && defaultTokens[closeIdx+1].GetTokenType() == grammar.SQLLexerT_WHERE {
+				return "", "", fmt.Errorf("where clause of outer query is not supported, filter in subquery")
+			}
+			closeToken := defaultTokens[closeIdx]
+			// token's offset is the index of rune
+			input := []rune(sql)
+			inner = string(input[defaultTokens[idx+2].GetStart():closeToken.GetStart()])
+			outer = string(input[:open.GetStart()]) + subQueryMetricName + string(input[closeToken.GetStop()+1:])
+			return outer, strings.TrimSpace(inner), nil
+		}
+		return "", "", fmt.Errorf("subquery is not closed")
+	}
+	return sql, "", nil
+}
+
+// prepareSubQuery prepares the outer query and inner query, only supports one level subquery.
+func prepareSubQuery(sql, outerSQL, innerSQL string) (*PreparedStatement, error) {
+	inner, err := Prepare(innerSQL)
+	if err != nil {
+		return nil, err
+	}
+	if inner.subQuery != nil {
+		return nil, fmt.Errorf("only one level of subquery is supported")
+	}
+	outer, err := Prepare(outerSQL)
+	if err != nil {
+		return nil, err
+	}
+	outer.sql = sql
+	outer.subQuery = inner
+	return outer, nil
+}
+
+// bindSubQuery binds the inner query, then checks if outer query can aggregate the result of inner query:
+// 1. select items of outer query must be aggregation(sum/min/max/count/avg) of inner query's result field.
+// 2. group by tag keys of outer query must be included in group by tag keys of inner query.
+// The time range of outer query is same as inner query.
+func (p *PreparedStatement) bindSubQuery(statement stmt.Statement, opts *Options) (stmt.Statement, error) {
+	outer, ok := statement.(*stmt.Query)
+	if !ok {
+		return nil, fmt.Errorf("subquery is only supported in select statement")
+	}
+	innerStatement, err := p.subQuery.Bind(opts)
+	if err != nil {
+		return nil, err
+	}
+	inner, ok := innerStatement.(*stmt.Query)
+	if !ok {
+		return nil, fmt.Errorf("subquery must be select statement")
+	}
+	innerFields := make(map[string]struct{})
+	for _, selectItem := range inner.SelectItems {
+		if item, ok := selectItem.(*stmt.SelectItem); ok {
+			innerFields[item.ResultName()] = struct{}{}
+		} else {
+			innerFields[selectItem.Rewrite()] = struct{}{}
+		}
+	}
+	for _, selectItem := range outer.SelectItems {
+		if err := validateSubQuerySelectItem(selectItem, innerFields); err != nil {
+			return nil, err
+		}
+	}
+	innerGroupBy := make(map[string]struct{})
+	for _, tagKey := range inner.GroupBy {
+		innerGroupBy[tagKey] = struct{}{}
+	}
+	for _, tagKey := range outer.GroupBy {
+		if _, ok := innerGroupBy[tagKey]; !ok {
+			return nil, fmt.Errorf("group by tag key: %s of outer query not found in subquery", tagKey)
+		}
+	}
+	outer.Namespace = inner.Namespace
+	outer.MetricName = inner.MetricName
+	outer.TimeRange = inner.TimeRange
+	outer.SubQuery = inner
+	return outer, nil
+}
+
+// validateSubQuerySelectItem checks if the select item of outer query is aggregation of inner query's field.
+func validateSubQuerySelectItem(selectItem stmt.Expr, innerFields map[string]struct{}) error {
+	item, ok := selectItem.(*stmt.SelectItem)
+	if !ok {
+		return fmt.Errorf("not support select item: %s of outer query", selectItem.Rewrite())
+	}
+	call, ok := item.Expr.(*stmt.CallExpr)
+	if !ok || len(call.Params) != 1 {
+		return fmt.Errorf("select item: %s of outer query must be aggregation of subquery field", item.Rewrite())
+	}
+	if _, ok := subQueryFuncs[call.FuncType]; !ok {
+		return fmt.Errorf("not support function: %s in outer query", call.FuncType)
+	}
+	field, ok := call.Params[0].(*stmt.FieldExpr)
+	if !ok {
+		return fmt.Errorf("select item: %s of outer query must be aggregation of subquery field", item.Rewrite())
+	}
+	if _, ok := innerFields[field.Name]; !ok {
+		return fmt.Errorf("field: %s of outer query not found in subquery", field.Name)
+	}
+	return nil
+}
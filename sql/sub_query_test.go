@@ -0,0 +1,97 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/aggregation/function"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestSubQuery_Parse(t *testing.T) {
+	q, err := Parse("select max(mean_f) as max_f, min(mean_f) from " +
+		"(select avg(f) as mean_f from cpu where region='sh' and time>now()-1h group by host, region, time(1m)) " +
+		"group by region, time(5m)")
+	assert.NoError(t, err)
+	query := q.(*stmt.Query)
+	assert.Equal(t, "cpu", query.MetricName)
+	assert.Equal(t, []string{"region"}, query.GroupBy)
+	assert.Equal(t, timeutil.Interval(5*timeutil.OneMinute), query.Interval)
+	assert.Equal(t, []stmt.Expr{
+		&stmt.SelectItem{Expr: &stmt.CallExpr{FuncType: function.Max, Params: []stmt.Expr{&stmt.FieldExpr{Name: "mean_f"}}},
+			Alias: "max_f"},
+		&stmt.SelectItem{Expr: &stmt.CallExpr{FuncType: function.Min, Params: []stmt.Expr{&stmt.FieldExpr{Name: "mean_f"}}}},
+	}, query.SelectItems)
+	inner := query.SubQuery
+	assert.NotNil(t, inner)
+	assert.Nil(t, inner.SubQuery)
+	assert.Equal(t, "cpu", inner.MetricName)
+	assert.Equal(t, []string{"host", "region"}, inner.GroupBy)
+	assert.Equal(t, timeutil.Interval(timeutil.OneMinute), inner.Interval)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "region", Value: "sh"}, inner.Condition)
+	assert.Equal(t, inner.TimeRange, query.TimeRange)
+
+	// nested function of outer query
+	_, err = Parse("select sum(avg(f)) from (select avg(f) from cpu group by host)")
+	assert.Error(t, err)
+	// without alias and outer group by
+	q, err = Parse("select count(f) from (select f from cpu group by host)")
+	assert.NoError(t, err)
+	assert.NotNil(t, q.(*stmt.Query).SubQuery)
+
+	// prepared subquery with params
+	prepared, err := Prepare("select max(f) from (select avg(f) as f from cpu where host=$host group by host)")
+	assert.NoError(t, err)
+	q, err = prepared.Bind(&Options{Params: map[string]string{"host": "h1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, &stmt.EqualsExpr{Key: "host", Value: "h1"}, q.(*stmt.Query).SubQuery.Condition)
+}
+
+func TestSubQuery_Parse_Error(t *testing.T) {
+	for _, sql := range []string{
+		// not closed
+		"select max(f) from (select f from cpu",
+		// nested subquery
+		"select max(f) from (select max(f) as f from (select f from cpu))",
+		// inner error
+		"select max(f) from (select from cpu)",
+		// outer error
+		"select max(f) from (select f from cpu) group",
+		// where of outer
+		"select max(f) from (select f from cpu) where host='a'",
+		// field not found
+		"select max(f1) from (select f from cpu)",
+		// not aggregation
+		"select f from (select f from cpu)",
+		"select max(f)+1 from (select f from cpu)",
+		"select max(f+1) from (select f from cpu)",
+		// function not support
+		"select stddev(f) from (select f from cpu)",
+		// group by tag not found
+		"select max(f) from (select f from cpu group by host) group by region",
+		// limit of outer query error
+		"select max(f) from (select f from cpu) limit",
+	} {
+		_, err := Parse(sql)
+		assert.Error(t, err, sql)
+	}
+}
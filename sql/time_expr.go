@@ -0,0 +1,97 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/sql/grammar"
+)
+
+// durationUnits represents the unit(millisecond) of duration literal, like 1h/30m
+var durationUnits = map[int]int64{
+	grammar.SQLLexerT_SECOND: timeutil.OneSecond,
+	grammar.SQLLexerT_MINUTE: timeutil.OneMinute,
+	grammar.SQLLexerT_HOUR:   timeutil.OneHour,
+	grammar.SQLLexerT_DAY:    timeutil.OneDay,
+	grammar.SQLLexerT_WEEK:   timeutil.OneWeek,
+	grammar.SQLLexerT_MONTH:  timeutil.OneMonth,
+	grammar.SQLLexerT_YEAR:   timeutil.OneYear,
+}
+
+// timeExpr represents the time expression of time range in where clause, which is base time with
+// duration arithmetic, like now()-1h-30m/'2019-04-10T08:00:00Z'+1h/$start-1d/1554854400000.
+// It is evaluated when binding statement, because now() and placeholder are changed for each query.
+type timeExpr struct {
+	now      bool   // base time is now()
+	literal  string // raw text of base time(time literal/placeholder/epoch milliseconds) if not now()
+	duration int64  // sum of durations(millisecond) added to base time
+}
+
+// parseTimeExpr parses the time expression from tokens[pos:], returns the index of last token of expression.
+func parseTimeExpr(tokens []antlr.Token, pos int) (*timeExpr, int, error) {
+	expr := &timeExpr{}
+	token := tokens[pos]
+	switch token.GetTokenType() {
+	case grammar.SQLLexerT_NOW:
+		if pos+2 >= len(tokens) || tokens[pos+1].GetTokenType() != grammar.SQLLexerT_OPEN_P ||
+			tokens[pos+2].GetTokenType() != grammar.SQLLexerT_CLOSE_P {
+			return nil, 0, fmt.Errorf("time expression now() is incomplete")
+		}
+		expr.now = true
+		pos += 2
+	case grammar.SQLLexerL_ID, grammar.SQLLexerL_INT:
+		expr.literal = token.GetText()
+	default:
+		return nil, 0, fmt.Errorf("invalid time expression: %s", token.GetText())
+	}
+	// duration arithmetic: (+|-) L_INT unit
+	for pos+3 < len(tokens) {
+		var sign int64
+		switch tokens[pos+1].GetTokenType() {
+		case grammar.SQLLexerT_ADD:
+			sign = 1
+		case grammar.SQLLexerT_SUB:
+			sign = -1
+		default:
+			return expr, pos, nil
+		}
+		if tokens[pos+2].GetTokenType() != grammar.SQLLexerL_INT {
+			return nil, 0, fmt.Errorf("invalid duration of time expression: %s", tokens[pos+2].GetText())
+		}
+		unit, ok := durationUnits[tokens[pos+3].GetTokenType()]
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid duration unit of time expression: %s", tokens[pos+3].GetText())
+		}
+		value, err := strconv.ParseInt(tokens[pos+2].GetText(), 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		expr.duration += sign * value * unit
+		pos += 3
+	}
+	if pos+1 < len(tokens) && (tokens[pos+1].GetTokenType() == grammar.SQLLexerT_ADD ||
+		tokens[pos+1].GetTokenType() == grammar.SQLLexerT_SUB) {
+		return nil, 0, fmt.Errorf("time expression is incomplete")
+	}
+	return expr, pos, nil
+}
@@ -0,0 +1,248 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/stream"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/tsdb/memdb"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
+)
+
+//go:generate mockgen -source=./bulk_loader.go -destination=./bulk_loader_mock.go -package=tsdb
+
+// maxBulkLoadFamilySize is the max memory size of pending family, the family will be sealed if exceeded.
+const maxBulkLoadFamilySize = 64 * 1024 * 1024
+
+var (
+	// errBulkLoadCumulativeMetric represents cumulative metric cannot be transformed to delta without continuous data.
+	errBulkLoadCumulativeMetric = fmt.Errorf("%w, cumulative metric not support bulk load", constants.ErrBadMetricPBFormat)
+	// errBulkLoadClosed represents bulk loader is closed.
+	errBulkLoadClosed = errors.New("bulk loader is closed")
+)
+
+// BulkLoader loads pre-sorted historical data into sealed data files of shard directly,
+// bypasses the memory database of shard, so that migrating huge historical data doesn't hit the write path.
+// The data of one family is buffered in a pending memory database,
+// sealed into a data file when data of another family comes or commit.
+type BulkLoader interface {
+	// Load loads the metric into pending family.
+	Load(metric *protoMetricsV1.Metric) error
+	// Commit seals the pending family into data file.
+	Commit() error
+	// LoadedMetrics returns the number of loaded metrics.
+	LoadedMetrics() int64
+	// SealedFamilies returns the number of sealed families.
+	SealedFamilies() int32
+	// Close releases the pending family without sealing.
+	Close() error
+}
+
+// bulkLoader implements BulkLoader interface.
+type bulkLoader struct {
+	shard *shard
+
+	familyTime int64
+	memDB      memdb.MemoryDatabase // pending family
+
+	loadedMetrics  int64
+	sealedFamilies int32
+	closed         bool
+}
+
+// NewBulkLoader creates a loader which writes historical data into sealed data files directly.
+func (s *shard) NewBulkLoader() BulkLoader {
+	return &bulkLoader{shard: s}
+}
+
+// Load loads the metric into pending family.
+func (l *bulkLoader) Load(metric *protoMetricsV1.Metric) (err error) {
+	if l.closed {
+		return errBulkLoadClosed
+	}
+	s := l.shard
+	defer func() {
+		if err != nil {
+			s.metrics.bulkLoadFailures.Incr()
+		}
+	}()
+	isCumulative, err := s.checkMetric(metric, false)
+	if err != nil {
+		return err
+	}
+	if isCumulative {
+		return errBulkLoadCumulativeMetric
+	}
+	point, err := s.lookupMetricMeta(metric)
+	if err != nil {
+		return err
+	}
+	intervalCalc := s.intervalCalc
+	timestamp := s.precision.ToMillis(metric.Timestamp)
+	segmentTime := intervalCalc.CalcSegmentTime(timestamp)
+	familyTime := intervalCalc.CalcFamilyStartTime(segmentTime, intervalCalc.CalcFamily(timestamp, segmentTime))
+	if l.memDB != nil && (l.familyTime != familyTime || l.memDB.MemSize() >= maxBulkLoadFamilySize) {
+		if err := l.seal(); err != nil {
+			return err
+		}
+	}
+	if l.memDB == nil {
+		memDB, err := newMemoryDBFunc(memdb.MemoryDatabaseCfg{
+			FamilyTime: familyTime,
+			Name:       s.databaseName,
+			OnHeap:     true,
+		})
+		if err != nil {
+			return err
+		}
+		l.memDB = memDB
+		l.familyTime = familyTime
+	}
+	point.SlotIndex = uint16(s.precision.CalcSlot(intervalCalc, metric.Timestamp, familyTime, s.interval))
+	if err := l.memDB.Write(point); err != nil {
+		return err
+	}
+	l.loadedMetrics++
+	s.metrics.bulkLoadMetrics.Incr()
+	return nil
+}
+
+// Commit seals the pending family into data file.
+func (l *bulkLoader) Commit() error {
+	if l.closed {
+		return errBulkLoadClosed
+	}
+	if err := l.seal(); err != nil {
+		l.shard.metrics.bulkLoadFailures.Incr()
+		return err
+	}
+	return nil
+}
+
+// LoadedMetrics returns the number of loaded metrics.
+func (l *bulkLoader) LoadedMetrics() int64 {
+	return l.loadedMetrics
+}
+
+// SealedFamilies returns the number of sealed families.
+func (l *bulkLoader) SealedFamilies() int32 {
+	return l.sealedFamilies
+}
+
+// Close releases the pending family without sealing.
+func (l *bulkLoader) Close() error {
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.memDB == nil {
+		return nil
+	}
+	memDB := l.memDB
+	l.memDB = nil
+	return memDB.Close()
+}
+
+// seal flushes the pending family into a data file, then registers it with the data family.
+func (l *bulkLoader) seal() error {
+	if l.memDB == nil {
+		return nil
+	}
+	s := l.shard
+	memDB := l.memDB
+	familyTime := l.familyTime
+	l.memDB = nil
+	defer func() {
+		if err := memDB.Close(); err != nil {
+			engineLogger.Warn("close bulk load memory database error",
+				logger.String("shard", s.path), logger.Error(err))
+		}
+	}()
+
+	segment, err := s.segment.GetOrCreateSegment(s.interval.Calculator().GetSegment(familyTime))
+	if err != nil {
+		return err
+	}
+	dataFamily, err := segment.GetDataFamily(familyTime)
+	if err != nil {
+		return err
+	}
+	flusher := newVerifiedFlusher(dataFamily.Family().NewFlusher())
+	if err := memDB.FlushFamilyTo(metricsdata.NewFlusher(flusher)); err != nil {
+		return err
+	}
+	// confirm the sealed file, otherwise it will be dropped as partially flushed file when shard restart
+	if err := s.confirmFlushedFiles([]DataFamily{dataFamily}); err != nil {
+		return err
+	}
+	l.sealedFamilies++
+	s.metrics.bulkLoadSealedFamilies.Incr()
+	engineLogger.Info("seal bulk load family successfully",
+		logger.String("shard", s.path), logger.Int64("family", familyTime))
+	return nil
+}
+
+// verifiedFlusher buffers the metric blocks, verifies all of them before writing into data file,
+// so that the data file is only registered with data family after all metric blocks are verified.
+type verifiedFlusher struct {
+	kvFlusher kv.Flusher
+	keys      []uint32
+	values    [][]byte
+}
+
+// newVerifiedFlusher creates a kv flusher which verifies metric blocks before flushing.
+func newVerifiedFlusher(kvFlusher kv.Flusher) kv.Flusher {
+	return &verifiedFlusher{kvFlusher: kvFlusher}
+}
+
+// Add buffers the metric block.
+func (f *verifiedFlusher) Add(key uint32, value []byte) error {
+	f.keys = append(f.keys, key)
+	f.values = append(f.values, append([]byte(nil), value...))
+	return nil
+}
+
+// Commit verifies checksum and layout of all metric blocks, then flushes them into data file.
+func (f *verifiedFlusher) Commit() error {
+	for idx, value := range f.values {
+		if len(value) <= 4 {
+			return fmt.Errorf("%w, metric block of metric: %d is too short", constants.ErrDataFileCorruption, f.keys[idx])
+		}
+		checksumPos := len(value) - 4
+		if crc32.ChecksumIEEE(value[:checksumPos]) != stream.ReadUint32(value, checksumPos) {
+			return fmt.Errorf("%w, checksum of metric: %d not match", constants.ErrDataFileCorruption, f.keys[idx])
+		}
+		if _, err := newReaderFunc("", value); err != nil {
+			return fmt.Errorf("%w, metric block of metric: %d is bad, err: %s",
+				constants.ErrDataFileCorruption, f.keys[idx], err)
+		}
+	}
+	for idx, key := range f.keys {
+		if err := f.kvFlusher.Add(key, f.values[idx]); err != nil {
+			return err
+		}
+	}
+	return f.kvFlusher.Commit()
+}
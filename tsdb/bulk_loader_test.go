@@ -0,0 +1,152 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/fileutil"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/tsdb/memdb"
+	"github.com/lindb/lindb/tsdb/metadb"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
+)
+
+func TestBulkLoader_Load(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newMemoryDBFunc = memdb.NewMemoryDatabase
+		ctrl.Finish()
+	}()
+
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	metadata.EXPECT().TagValueStats().Return(metadb.NewTagValueStats()).AnyTimes()
+	metadataDB.EXPECT().GenMetricID(constants.DefaultNamespace, "test").Return(uint32(10), nil).AnyTimes()
+	metadataDB.EXPECT().GenFieldID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(field.ID(1), nil).AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+
+	shardINTF, err := newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "10s", Behind: "1h"})
+	assert.NoError(t, err)
+	s := shardINTF.(*shard)
+	newMetric := func(timestamp int64, fieldType protoMetricsV1.SimpleFieldType) *protoMetricsV1.Metric {
+		return &protoMetricsV1.Metric{
+			Name:         "test",
+			Timestamp:    timestamp,
+			SimpleFields: []*protoMetricsV1.SimpleField{{Name: "f1", Type: fieldType, Value: 1.0}},
+		}
+	}
+	// family older than behind
+	now := timeutil.Now() - 2*timeutil.OneDay
+	segmentTime := s.intervalCalc.CalcSegmentTime(now)
+	familyTime := s.intervalCalc.CalcFamilyStartTime(segmentTime, s.intervalCalc.CalcFamily(now, segmentTime))
+
+	loader := shardINTF.NewBulkLoader()
+	// case 1: bad metric
+	assert.Error(t, loader.Load(nil))
+	// case 2: cumulative metric
+	assert.Equal(t, errBulkLoadCumulativeMetric,
+		loader.Load(newMetric(familyTime, protoMetricsV1.SimpleFieldType_CUMULATIVE_SUM)))
+	// case 3: create memory database err
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		return nil, fmt.Errorf("err")
+	}
+	assert.Error(t, loader.Load(newMetric(familyTime, protoMetricsV1.SimpleFieldType_DELTA_SUM)))
+	newMemoryDBFunc = memdb.NewMemoryDatabase
+	// case 4: load metrics of two families
+	assert.NoError(t, loader.Load(newMetric(familyTime, protoMetricsV1.SimpleFieldType_DELTA_SUM)))
+	assert.NoError(t, loader.Load(newMetric(familyTime+10*timeutil.OneSecond, protoMetricsV1.SimpleFieldType_DELTA_SUM)))
+	assert.NoError(t, loader.Load(newMetric(familyTime+timeutil.OneHour, protoMetricsV1.SimpleFieldType_DELTA_SUM)))
+	assert.NoError(t, loader.Commit())
+	assert.Equal(t, int64(3), loader.LoadedMetrics())
+	assert.Equal(t, int32(2), loader.SealedFamilies())
+	// memory database of shard not used
+	assert.Empty(t, s.memDBEntries())
+	// sealed data files are registered with data family
+	for _, ft := range []int64{familyTime, familyTime + timeutil.OneHour} {
+		segment, err := s.segment.GetOrCreateSegment(s.interval.Calculator().GetSegment(ft))
+		assert.NoError(t, err)
+		dataFamily, err := segment.GetDataFamily(ft)
+		assert.NoError(t, err)
+		snapshot := dataFamily.Family().GetSnapshot()
+		readers, err := snapshot.FindReaders(10)
+		assert.NoError(t, err)
+		assert.Len(t, readers, 1)
+		snapshot.Close()
+	}
+	// case 5: close pending family without sealing
+	assert.NoError(t, loader.Load(newMetric(familyTime, protoMetricsV1.SimpleFieldType_DELTA_SUM)))
+	assert.NoError(t, loader.Close())
+	assert.NoError(t, loader.Close())
+	assert.Equal(t, int32(2), loader.SealedFamilies())
+	// case 6: loader closed
+	assert.Equal(t, errBulkLoadClosed, loader.Load(newMetric(familyTime, protoMetricsV1.SimpleFieldType_DELTA_SUM)))
+	assert.Equal(t, errBulkLoadClosed, loader.Commit())
+}
+
+func TestVerifiedFlusher_Commit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// build a valid metric block
+	nopFlusher := kv.NewNopFlusher()
+	flusher := metricsdata.NewFlusher(nopFlusher)
+	flusher.FlushFieldMetas(field.Metas{{ID: 1, Type: field.SumField}})
+	flusher.FlushField([]byte{1, 2, 3})
+	flusher.FlushSeries(10)
+	assert.NoError(t, flusher.FlushMetric(10, 5, 5))
+	block := append([]byte(nil), nopFlusher.Bytes()...)
+
+	// case 1: block too short
+	kvFlusher := kv.NewMockFlusher(ctrl)
+	f := newVerifiedFlusher(kvFlusher)
+	assert.NoError(t, f.Add(10, []byte{1, 2}))
+	assert.Error(t, f.Commit())
+	// case 2: checksum not match
+	f = newVerifiedFlusher(kvFlusher)
+	corrupted := append([]byte(nil), block...)
+	corrupted[0]++
+	assert.NoError(t, f.Add(10, corrupted))
+	assert.Error(t, f.Commit())
+	// case 3: add err
+	f = newVerifiedFlusher(kvFlusher)
+	assert.NoError(t, f.Add(10, block))
+	kvFlusher.EXPECT().Add(uint32(10), block).Return(fmt.Errorf("err"))
+	assert.Error(t, f.Commit())
+	// case 4: commit successfully
+	f = newVerifiedFlusher(kvFlusher)
+	assert.NoError(t, f.Add(10, block))
+	kvFlusher.EXPECT().Add(uint32(10), block).Return(nil)
+	kvFlusher.EXPECT().Commit().Return(nil)
+	assert.NoError(t, f.Commit())
+}
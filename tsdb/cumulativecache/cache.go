@@ -46,14 +46,20 @@ type cacheMetrics struct {
 	Misses *linmetric.BoundDeltaCounter
 	// Evicts is a number of successfully deleted keys
 	Evicts *linmetric.BoundDeltaCounter
+	// Resets is a number of counter resets detected
+	Resets *linmetric.BoundDeltaCounter
+	// OutOfOrders is a number of points older than the cached point of series
+	OutOfOrders *linmetric.BoundDeltaCounter
 }
 
 func newCacheMetrics(scope linmetric.Scope) *cacheMetrics {
 	return &cacheMetrics{
-		Nums:   scope.NewGauge("key_nums"),
-		Hits:   scope.NewDeltaCounter("key_hits"),
-		Misses: scope.NewDeltaCounter("key_misses"),
-		Evicts: scope.NewDeltaCounter("key_evicts"),
+		Nums:        scope.NewGauge("key_nums"),
+		Hits:        scope.NewDeltaCounter("key_hits"),
+		Misses:      scope.NewDeltaCounter("key_misses"),
+		Evicts:      scope.NewDeltaCounter("key_evicts"),
+		Resets:      scope.NewDeltaCounter("counter_resets"),
+		OutOfOrders: scope.NewDeltaCounter("out_of_order_points"),
 	}
 }
 
@@ -98,12 +104,6 @@ func (c *Cache) getShard(hashedKey uint64) (shard *cacheShard) {
 	return c.shards[hashedKey&c.shardMask]
 }
 
-// loadAndStore picks the old data and stored with new
-func (c *Cache) loadAndStore(key uint64, newValue []byte) (oldValue []byte, found bool) {
-	shard := c.getShard(key)
-	return shard.getAndSet(key, newValue)
-}
-
 func (c *Cache) clean() {
 	for _, shard := range c.shards {
 		shard.cleanUp()
@@ -120,12 +120,33 @@ func (c *Cache) Close() {
 	close(c.closeCh)
 }
 
+// CumulativePointToDelta transforms the cumulative fields of metric point into the increments since
+// previous point of series, handles counter reset(process restart) and out-of-order point.
+// Returns false if series has no previous point(first point or evicted by ttl), the values are set to zero,
+// so that the first cumulative point doesn't produce a huge spike.
 func (c *Cache) CumulativePointToDelta(mp *memdb.MetricPoint) (updated bool) {
-	key := uint64(mp.MetricID)<<32 + uint64(mp.SeriesID)
-	newData := encodeCumulativeFields(mp)
-	oldData, ok := c.loadAndStore(key, newData)
-	if !ok {
+	fields := collectCumulativeFields(mp)
+	if len(fields.ids) == 0 {
 		return false
 	}
-	return decodeCumulativeFieldsInto(mp, oldData)
+	key := uint64(mp.MetricID)<<32 + uint64(mp.SeriesID)
+	c.getShard(key).compute(key, func(prevState []byte, exist bool) []byte {
+		newState := fields.encode()
+		if !exist {
+			fields.zero()
+			return newState
+		}
+		inOrder, reset := fields.toDelta(prevState)
+		if !inOrder {
+			// keeps the state of latest point
+			c.metrics.OutOfOrders.Incr()
+			return nil
+		}
+		if reset {
+			c.metrics.Resets.Incr()
+		}
+		updated = true
+		return newState
+	})
+	return updated
 }
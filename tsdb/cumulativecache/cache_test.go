@@ -82,7 +82,7 @@ func Test_CumulativePointToDelta(t *testing.T) {
 func Test_CumulativePointToDelta_SimpleFields(t *testing.T) {
 	mp := newTestPoint()
 	mp.Proto.CompoundField = nil
-	assert.Equal(t, countCumulativeFields(mp), 4)
+	assert.Len(t, collectCumulativeFields(mp).ids, 4)
 	cache := NewCache(32, time.Second, time.Second, linmetric.NewScope("11"))
 	assert.Equal(t, cache.Capacity(), 0)
 	assert.False(t, cache.CumulativePointToDelta(mp))
@@ -93,7 +93,7 @@ func Test_CumulativePointToDelta_SimpleFields(t *testing.T) {
 func Test_CumulativePointToDelta_SimpleFields2(t *testing.T) {
 	mp := newTestPoint()
 	mp.Proto.CompoundField.Type = protoMetricsV1.CompoundFieldType_DELTA_HISTOGRAM
-	assert.Equal(t, countCumulativeFields(mp), 4)
+	assert.Len(t, collectCumulativeFields(mp).ids, 4)
 	cache := NewCache(32, time.Second, time.Second, linmetric.NewScope("12"))
 	assert.Equal(t, cache.Capacity(), 0)
 	assert.False(t, cache.CumulativePointToDelta(mp))
@@ -105,40 +105,120 @@ func Test_CumulativePointToDelta_CompoundFields(t *testing.T) {
 	mp := newTestPoint()
 	mp.Proto.SimpleFields = nil
 
-	assert.Equal(t, countCumulativeFields(mp), 7)
+	assert.Len(t, collectCumulativeFields(mp).ids, 7)
 	cache := NewCache(32, time.Second, time.Second, linmetric.NewScope("13"))
 	assert.Equal(t, 0, cache.Capacity())
 	assert.False(t, cache.CumulativePointToDelta(mp))
 	assert.Equal(t, 1, cache.Capacity())
 	assert.True(t, cache.CumulativePointToDelta(mp))
+	// min/max of histogram are skipped
+	mp = newTestPoint()
+	mp.Proto.CompoundField.Min = 1
+	mp.Proto.CompoundField.Max = 2
+	fields := collectCumulativeFields(mp)
+	assert.Len(t, fields.ids, 9)
+	assert.Equal(t, field.ID(8), fields.ids[4])
+	// no cumulative fields
+	mp = newTestPoint()
+	mp.Proto.SimpleFields = nil
+	mp.Proto.CompoundField = nil
+	assert.False(t, cache.CumulativePointToDelta(mp))
 }
 
-func Test_decodeCumulativeFieldsInto(t *testing.T) {
+func Test_CumulativePointToDelta_values(t *testing.T) {
+	cache := NewCache(32, time.Minute, 0, linmetric.NewScope("14"))
+	defer cache.Close()
+	write := func(timestamp int64, values ...float64) ([]float64, bool) {
+		mp := newTestPoint()
+		mp.Proto.Timestamp = timestamp
+		mp.Proto.CompoundField = nil
+		for idx, value := range values {
+			mp.Proto.SimpleFields[idx+1].Value = value
+		}
+		updated := cache.CumulativePointToDelta(mp)
+		var result []float64
+		for _, f := range mp.Proto.SimpleFields[1:] {
+			result = append(result, f.Value)
+		}
+		return result, updated
+	}
+	// case 1: first point has no increment
+	values, updated := write(10, 10.5, 20, 30, 40)
+	assert.False(t, updated)
+	assert.Equal(t, []float64{0, 0, 0, 0}, values)
+	// case 2: increments of float values
+	values, updated = write(20, 11, 25, 30, 41.5)
+	assert.True(t, updated)
+	assert.Equal(t, []float64{0.5, 5, 0, 1.5}, values)
+	// case 3: counter reset, uses values since reset
+	values, updated = write(30, 12, 2, 31, 42)
+	assert.True(t, updated)
+	assert.Equal(t, []float64{12, 2, 31, 42}, values)
+	// case 4: out-of-order point, state not changed
+	values, updated = write(25, 100, 100, 100, 100)
+	assert.False(t, updated)
+	assert.Equal(t, []float64{0, 0, 0, 0}, values)
+	values, _ = write(40, 13, 3, 32, 43)
+	assert.Equal(t, []float64{1, 1, 1, 1}, values)
+	// case 5: new field without previous value
 	mp := newTestPoint()
+	mp.Proto.Timestamp = 50
+	mp.Proto.CompoundField = nil
+	mp.FieldIDs[1] = 222
+	mp.Proto.SimpleFields[1].Value = 5
+	mp.Proto.SimpleFields[2].Value = 4
+	mp.Proto.SimpleFields[3].Value = 33
+	mp.Proto.SimpleFields[4].Value = 44
+	assert.True(t, cache.CumulativePointToDelta(mp))
+	assert.Equal(t, 0.0, mp.Proto.SimpleFields[1].Value)
+	assert.Equal(t, 1.0, mp.Proto.SimpleFields[2].Value)
+}
 
-	assert.False(t, decodeCumulativeFieldsInto(mp, nil))
-	cache := NewCache(32, time.Second, time.Second, linmetric.NewScope("14"))
-	assert.False(t, cache.CumulativePointToDelta(mp))
-	// fieldid not match
-	mp2 := newTestPoint()
-	mp2.FieldIDs[1] = field.ID(222)
-	assert.False(t, cache.CumulativePointToDelta(mp2))
-	mp3 := newTestPoint()
-	mp3.Proto.SimpleFields[1].Value = 0
-	assert.False(t, cache.CumulativePointToDelta(mp3))
-
-	// sum
-	mp4 := newTestPoint()
-	mp4.Proto.CompoundField.Sum = 0
-	assert.False(t, cache.CumulativePointToDelta(mp4))
-
-	// count
-	mp5 := newTestPoint()
-	mp5.Proto.CompoundField.Count = 0
-	assert.False(t, cache.CumulativePointToDelta(mp5))
+func Test_CumulativePointToDelta_intValues(t *testing.T) {
+	cache := NewCache(32, time.Minute, 0, linmetric.NewScope("15"))
+	defer cache.Close()
+	write := func(timestamp int64, valueType protoMetricsV1.SimpleFieldValueType, value int64) *protoMetricsV1.SimpleField {
+		mp := newTestPoint()
+		mp.Proto.Timestamp = timestamp
+		mp.Proto.CompoundField = nil
+		f := mp.Proto.SimpleFields[1]
+		f.ValueType = valueType
+		f.IntValue = value
+		f.Value = float64(value)
+		cache.CumulativePointToDelta(mp)
+		return f
+	}
+	// case 1: first point has no increment
+	f := write(10, protoMetricsV1.SimpleFieldValueType_INT_VALUE, 1<<60)
+	assert.Equal(t, int64(0), f.IntValue)
+	assert.Equal(t, 0.0, f.Value)
+	// case 2: increment above 2^53 keeps precision
+	f = write(20, protoMetricsV1.SimpleFieldValueType_INT_VALUE, 1<<61+1)
+	assert.Equal(t, int64(1<<60+1), f.IntValue)
+	assert.Equal(t, float64(1<<60+1), f.Value)
+	// case 3: counter reset
+	f = write(30, protoMetricsV1.SimpleFieldValueType_INT_VALUE, 3)
+	assert.Equal(t, int64(3), f.IntValue)
+	// case 4: value type changed, previous value is unknown
+	f = write(40, protoMetricsV1.SimpleFieldValueType_FLOAT_VALUE, 5)
+	assert.Equal(t, 0.0, f.Value)
+	f = write(50, protoMetricsV1.SimpleFieldValueType_INT_VALUE, 6)
+	assert.Equal(t, int64(0), f.IntValue)
+}
 
-	// value
-	mp6 := newTestPoint()
-	mp6.Proto.CompoundField.Values[0] = 0
-	assert.False(t, cache.CumulativePointToDelta(mp6))
+func Test_stateIterator(t *testing.T) {
+	itr := newStateIterator(nil)
+	_, _, ok := itr.find(0, 1)
+	assert.False(t, ok)
+	mp := newTestPoint()
+	fields := collectCumulativeFields(mp)
+	itr = newStateIterator(fields.encode()[timestampSizeInBytes:])
+	assert.Equal(t, mp.Proto.Timestamp, itr.timestamp)
+	// not in same position
+	value, isInt, ok := itr.find(0, fields.ids[1])
+	assert.True(t, ok)
+	assert.False(t, isInt)
+	assert.Equal(t, 1.0, math.Float64frombits(value))
+	_, _, ok = itr.find(0, 222)
+	assert.False(t, ok)
 }
@@ -19,6 +19,7 @@ package cumulativecache
 
 import (
 	"encoding/binary"
+	"math"
 
 	"github.com/lindb/lindb/pkg/fasttime"
 	"github.com/lindb/lindb/pkg/stream"
@@ -27,153 +28,197 @@ import (
 	"github.com/lindb/lindb/tsdb/memdb"
 )
 
-// fieldID+fieldValue, uint16, float64
+// entry: access time(uint32, seconds)+point timestamp(int64)+[fieldID(uint16)+int flag(uint8)+fieldValue(float64/int64 bits)]
 const (
-	fieldIDBytes    = 2
-	fieldValueBytes = 8
+	pointTimestampBytes = 8
+	fieldIDBytes        = 2
+	fieldFlagBytes      = 1
+	fieldValueBytes     = 8
+	fieldEntryBytes     = fieldIDBytes + fieldFlagBytes + fieldValueBytes
 )
 
-func decodeCumulativeFieldsInto(mp *memdb.MetricPoint, data []byte) (success bool) {
-	itr := &encodedDataIterator{data: data}
-	var (
-		fieldIDIdx = 0
-	)
-	ensureFieldValueCumulative := func(fieldID field.ID, value float64) (delta float64, ok bool) {
-		if !itr.HasNext() {
-			return 0, false
-		}
-		fID, fValue := itr.Next()
-		if fValue > value {
-			return 0, false
-		}
-		if fID != fieldID {
-			return 0, false
+// cumulativeFields represents the cumulative fields of metric point, values point to the field values of proto.
+type cumulativeFields struct {
+	timestamp int64
+	ids       []field.ID
+	values    []*float64
+	ints      []*int64 // int values of fields written natively, nil if field value is float
+}
+
+// collectCumulativeFields collects the cumulative sum fields and the fields of cumulative histogram.
+func collectCumulativeFields(mp *memdb.MetricPoint) *cumulativeFields {
+	fields := &cumulativeFields{timestamp: mp.Proto.Timestamp}
+	fieldIDIdx := 0
+	add := func(value *float64, intValue *int64) {
+		if fieldIDIdx < len(mp.FieldIDs) {
+			fields.ids = append(fields.ids, mp.FieldIDs[fieldIDIdx])
+			fields.values = append(fields.values, value)
+			fields.ints = append(fields.ints, intValue)
 		}
-		return value - fValue, true
+		fieldIDIdx++
 	}
-
 	simpleFields := mp.Proto.SimpleFields
 	for sfIdx := range simpleFields {
 		if simpleFields[sfIdx].Type == protoMetricsV1.SimpleFieldType_CUMULATIVE_SUM {
-			delta, ok := ensureFieldValueCumulative(mp.FieldIDs[fieldIDIdx], simpleFields[sfIdx].Value)
-			if !ok {
-				return false
+			if simpleFields[sfIdx].ValueType != protoMetricsV1.SimpleFieldValueType_FLOAT_VALUE {
+				// int/bool counter, computes increments on int64 for keeping precision
+				add(&simpleFields[sfIdx].Value, &simpleFields[sfIdx].IntValue)
+			} else {
+				add(&simpleFields[sfIdx].Value, nil)
 			}
-			// replace field value
-			simpleFields[sfIdx].Value = delta
+		} else {
+			fieldIDIdx++
 		}
-		fieldIDIdx++
-	}
-	// already updated
-	if mp.Proto.CompoundField == nil || mp.Proto.CompoundField.Type !=
-		protoMetricsV1.CompoundFieldType_CUMULATIVE_HISTOGRAM {
-		return true
 	}
 	compoundField := mp.Proto.CompoundField
-	// replace sum field
-	delta, ok := ensureFieldValueCumulative(mp.FieldIDs[fieldIDIdx], compoundField.Sum)
-	if !ok {
-		return false
-	}
-	compoundField.Sum = delta
-	fieldIDIdx++
-	// replace count field
-	delta, ok = ensureFieldValueCumulative(mp.FieldIDs[fieldIDIdx], compoundField.Count)
-	if !ok {
-		return false
-	}
-	compoundField.Count = delta
-	fieldIDIdx++
-	// replace values
-	for cfIdx := range compoundField.Values {
-		delta, ok = ensureFieldValueCumulative(mp.FieldIDs[fieldIDIdx], compoundField.Values[cfIdx])
-		if !ok {
-			return false
-		}
+	if compoundField == nil || compoundField.Type != protoMetricsV1.CompoundFieldType_CUMULATIVE_HISTOGRAM {
+		return fields
+	}
+	// min/max are gauges of histogram, keep them as they are
+	if compoundField.Min > 0 {
 		fieldIDIdx++
-		compoundField.Values[cfIdx] = delta
 	}
-	return true
+	if compoundField.Max > 0 {
+		fieldIDIdx++
+	}
+	add(&compoundField.Sum, nil)
+	add(&compoundField.Count, nil)
+	for idx := range compoundField.Values {
+		add(&compoundField.Values[idx], nil)
+	}
+	return fields
 }
 
-type encodedDataIterator struct {
-	data   []byte
-	offset int
-	id     field.ID
-	value  float64
+// encode encodes the current cumulative values of metric point as the state of series.
+func (f *cumulativeFields) encode() []byte {
+	data := make([]byte, timestampSizeInBytes+pointTimestampBytes+len(f.ids)*fieldEntryBytes)
+	binary.LittleEndian.PutUint32(data, uint32(fasttime.UnixTimestamp()))
+	offset := timestampSizeInBytes
+	stream.PutUint64(data, offset, uint64(f.timestamp))
+	offset += pointTimestampBytes
+	for idx, id := range f.ids {
+		stream.PutUint16(data, offset, uint16(id))
+		offset += fieldIDBytes
+		value := math.Float64bits(*f.values[idx])
+		if f.ints[idx] != nil {
+			data[offset] = 1
+			value = uint64(*f.ints[idx])
+		}
+		offset += fieldFlagBytes
+		stream.PutUint64(data, offset, value)
+		offset += fieldValueBytes
+	}
+	return data
 }
 
-func (itr *encodedDataIterator) HasNext() bool {
-	if itr.offset+fieldIDBytes+fieldValueBytes > len(itr.data) {
-		return false
+// toDelta replaces the cumulative values with the increments since the previous state(without access time),
+// a counter reset(process restart) is detected if any value decreased, then the values since reset are used.
+// The field without previous value has no increment, because the start of counter is unknown.
+// Returns false if point is older than previous state, the values are set to zero.
+func (f *cumulativeFields) toDelta(prevState []byte) (inOrder, reset bool) {
+	itr := newStateIterator(prevState)
+	if f.timestamp < itr.timestamp {
+		f.zero()
+		return false, false
+	}
+	prevValues := make([]uint64, len(f.ids))
+	hasPrev := make([]bool, len(f.ids))
+	for idx, id := range f.ids {
+		prev, isInt, ok := itr.find(idx, id)
+		if !ok || isInt != (f.ints[idx] != nil) {
+			// value type changed, previous value is unknown
+			continue
+		}
+		prevValues[idx], hasPrev[idx] = prev, true
+		if f.less(idx, prev) {
+			reset = true
+		}
 	}
-	itr.id = field.ID(stream.ReadUint16(itr.data, itr.offset))
-	itr.offset += fieldIDBytes
-	itr.value = float64(stream.ReadUint64(itr.data, itr.offset))
-	itr.offset += fieldValueBytes
-	return true
+	for idx := range f.values {
+		switch {
+		case reset:
+			// counter restarts from zero
+		case hasPrev[idx]:
+			f.sub(idx, prevValues[idx])
+		default:
+			f.zeroAt(idx)
+		}
+	}
+	return true, reset
 }
 
-func (itr *encodedDataIterator) Next() (fID field.ID, v float64) {
-	return itr.id, itr.value
+// less checks if the value of field is less than the previous value(bits of float64/int64).
+func (f *cumulativeFields) less(idx int, prev uint64) bool {
+	if f.ints[idx] != nil {
+		return *f.ints[idx] < int64(prev)
+	}
+	return *f.values[idx] < math.Float64frombits(prev)
 }
 
-func encodeCumulativeFields(mp *memdb.MetricPoint) []byte {
-	var (
-		fieldIDIdx       = 0
-		cumulativeFields = make([]byte, timestampSizeInBytes+countCumulativeFields(mp)*(fieldIDBytes+fieldValueBytes))
-		writeOffset      = 4
-	)
-	binary.LittleEndian.PutUint32(cumulativeFields, uint32(fasttime.UnixTimestamp()))
-
-	putField := func(value float64) {
-		stream.PutUint16(cumulativeFields, writeOffset, uint16(mp.FieldIDs[fieldIDIdx]))
-		writeOffset += fieldIDBytes
-		stream.PutUint64(cumulativeFields, writeOffset, uint64(value))
-		writeOffset += fieldValueBytes
+// sub subtracts the previous value(bits of float64/int64) from the value of field,
+// float value of int field is kept as the approximation of int value.
+func (f *cumulativeFields) sub(idx int, prev uint64) {
+	if f.ints[idx] != nil {
+		*f.ints[idx] -= int64(prev)
+		*f.values[idx] = float64(*f.ints[idx])
+		return
 	}
+	*f.values[idx] -= math.Float64frombits(prev)
+}
 
-	simpleFields := mp.Proto.SimpleFields
-	for sfIdx := range simpleFields {
-		if simpleFields[sfIdx].Type == protoMetricsV1.SimpleFieldType_CUMULATIVE_SUM {
-			putField(simpleFields[sfIdx].Value)
-		}
-		fieldIDIdx++
-	}
-	compoundField := mp.Proto.CompoundField
-	if compoundField == nil {
-		return cumulativeFields
+// zero sets all cumulative values to zero, used for the first point of series.
+func (f *cumulativeFields) zero() {
+	for idx := range f.values {
+		f.zeroAt(idx)
 	}
-	if compoundField.Type != protoMetricsV1.CompoundFieldType_CUMULATIVE_HISTOGRAM {
-		return cumulativeFields
-	}
-	putField(compoundField.Sum)
-	fieldIDIdx++
-	putField(compoundField.Count)
-	fieldIDIdx++
+}
 
-	for idx := range compoundField.Values {
-		putField(compoundField.Values[idx])
-		fieldIDIdx++
+func (f *cumulativeFields) zeroAt(idx int) {
+	*f.values[idx] = 0
+	if f.ints[idx] != nil {
+		*f.ints[idx] = 0
 	}
-	return cumulativeFields
 }
 
-func countCumulativeFields(mp *memdb.MetricPoint) int {
-	var fieldCount = 0
-	simpleFields := mp.Proto.SimpleFields
-	for sfIdx := range simpleFields {
-		if simpleFields[sfIdx].Type == protoMetricsV1.SimpleFieldType_CUMULATIVE_SUM {
-			fieldCount++
-		}
+// stateIterator reads the field values of previous state.
+type stateIterator struct {
+	data      []byte
+	timestamp int64
+	count     int
+}
+
+func newStateIterator(data []byte) *stateIterator {
+	itr := &stateIterator{}
+	if len(data) < pointTimestampBytes {
+		return itr
 	}
-	compoundField := mp.Proto.CompoundField
-	if compoundField == nil {
-		return fieldCount
+	itr.timestamp = int64(stream.ReadUint64(data, 0))
+	itr.data = data[pointTimestampBytes:]
+	itr.count = len(itr.data) / fieldEntryBytes
+	return itr
+}
+
+// find returns the bits of previous value of field and if it's int value,
+// checks the same position first because fields are in write order.
+func (itr *stateIterator) find(pos int, id field.ID) (value uint64, isInt, ok bool) {
+	if pos < itr.count && itr.idAt(pos) == id {
+		value, isInt = itr.valueAt(pos)
+		return value, isInt, true
 	}
-	if compoundField.Type != protoMetricsV1.CompoundFieldType_CUMULATIVE_HISTOGRAM {
-		return fieldCount
+	for i := 0; i < itr.count; i++ {
+		if itr.idAt(i) == id {
+			value, isInt = itr.valueAt(i)
+			return value, isInt, true
+		}
 	}
-	// cumulative compound field like prometheus's histogram does not contains max/min field
-	return fieldCount + len(compoundField.Values) + 2
+	return 0, false, false
+}
+
+func (itr *stateIterator) idAt(pos int) field.ID {
+	return field.ID(stream.ReadUint16(itr.data, pos*fieldEntryBytes))
+}
+
+func (itr *stateIterator) valueAt(pos int) (value uint64, isInt bool) {
+	offset := pos*fieldEntryBytes + fieldIDBytes
+	return stream.ReadUint64(itr.data, offset+fieldFlagBytes), itr.data[offset] == 1
 }
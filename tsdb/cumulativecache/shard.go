@@ -39,23 +39,28 @@ func newCacheShard(ttl uint32, metrics *cacheMetrics) *cacheShard {
 	}
 }
 
-func (s *cacheShard) getAndSet(hashKey uint64, newValue []byte) (entry []byte, exist bool) {
+// compute calls fn with the entry(without access time) of key under lock,
+// then stores the returned entry if not nil, the entry passed to fn cannot be retained.
+func (s *cacheShard) compute(hashKey uint64, fn func(entry []byte, exist bool) (newEntry []byte)) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	wrappedEntry, ok := s.hashmap[hashKey]
-	if !ok {
+	var entry []byte
+	if ok {
+		s.metrics.Hits.Incr()
+		entry = wrappedEntry[timestampSizeInBytes:]
+	} else {
 		s.metrics.Misses.Incr()
-		s.hashmap[hashKey] = newValue
+	}
+	newEntry := fn(entry, ok)
+	if newEntry == nil {
+		return
+	}
+	if !ok {
 		s.metrics.Nums.Incr()
-		return nil, false
 	}
-
-	s.metrics.Hits.Incr()
-	// read entry
-	entry = readEntry(wrappedEntry)
-	s.hashmap[hashKey] = newValue
-	return entry, true
+	s.hashmap[hashKey] = newEntry
 }
 
 func (s *cacheShard) cleanUp() {
@@ -79,12 +84,3 @@ const (
 func readTimestampFromEntry(data []byte) uint32 {
 	return binary.LittleEndian.Uint32(data)
 }
-
-func readEntry(data []byte) []byte {
-	length := len(data) - timestampSizeInBytes
-
-	// copy on read
-	dst := make([]byte, length)
-	copy(dst, data[timestampSizeInBytes:])
-	return dst
-}
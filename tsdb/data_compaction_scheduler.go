@@ -0,0 +1,295 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
+)
+
+//go:generate mockgen -source=./data_compaction_scheduler.go -destination=./data_compaction_scheduler_mock.go -package=tsdb
+
+var (
+	compactionScope          = linmetric.NewScope("lindb.tsdb.compaction")
+	compactionJobCounter     = compactionScope.NewDeltaCounter("compact_jobs")
+	compactionFailureCounter = compactionScope.NewDeltaCounter("compact_failures")
+	compactionInFlightGauge  = compactionScope.NewGauge("compact_in_flight")
+	compactionTimerVec       = compactionScope.Scope("compact_duration").NewDeltaHistogramVec("db", "shard")
+	compactedBytesVec        = compactionScope.NewDeltaCounterVec("compacted_bytes", "db", "shard")
+	dataFamiliesVec          = compactionScope.NewGaugeVec("data_families", "db", "shard")
+	fieldPurgeJobCounter     = compactionScope.NewDeltaCounter("field_purge_jobs")
+	fieldPurgeFailureCounter = compactionScope.NewDeltaCounter("field_purge_failures")
+)
+
+// compactionThroughputVec records bytes per second of level0 files compacted by each job,
+// buckets are from 1KB/s to 1GB/s.
+var compactionThroughputVec = compactionScope.Scope("compact_throughput").NewDeltaHistogramVec("db", "shard").
+	WithExponentValueBuckets(1024, 1024*1024*1024, 20)
+
+const (
+	defaultCompactionConcurrency = 1
+	defaultCompactCheckInterval  = time.Minute
+)
+
+// DataCompactionScheduler represents the compaction scheduler of data families.
+// Each flush of memory database generates a small file(level0) in data family,
+// scheduler checks all data families of shards periodically, then merges the small files
+// of family which number of files >= compact threshold into larger files.
+//
+// a). Each data family is restricted to compact by one worker at the same time;
+// b). The number of compaction workers is limited by max-compaction-concurrency;
+// c). The written bytes of all compaction jobs are throttled by compaction-throughput.
+//
+// Scheduler also purges the fields of family which are expired by field retention or excluded from rollup,
+// the family is fully compacted once for the same matched field retentions.
+type DataCompactionScheduler interface {
+	// Start starts the checker goroutine and compaction workers in background
+	Start()
+	// Stop stops the background goroutines
+	Stop()
+
+	// requestCompactJob requests a compaction job for the spec data family
+	requestCompactJob(shard Shard, family DataFamily)
+}
+
+// compactRequest represents the data family compaction job request
+type compactRequest struct {
+	shard   Shard
+	family  DataFamily
+	dropper *fieldDropper // not nil means purge the dropped fields of family
+}
+
+// dataCompactionScheduler implements DataCompactionScheduler interface
+type dataCompactionScheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	concurrency   int
+	checkInterval time.Duration
+	limiter       *rate.Limiter // nil means no limit
+	ioCoordinator IOCoordinator
+
+	familyInCompacting sync.Map
+	purgedFamilies     sync.Map // data family => signature of purged field retentions
+	compactRequestCh   chan *compactRequest
+	logger             *logger.Logger
+}
+
+// newDataCompactionScheduler creates the data compaction scheduler
+func newDataCompactionScheduler(ctx context.Context, cfg config.TSDB, ioCoordinator IOCoordinator) DataCompactionScheduler {
+	c, cancel := context.WithCancel(ctx)
+	s := &dataCompactionScheduler{
+		ctx:              c,
+		cancel:           cancel,
+		concurrency:      cfg.MaxCompactionConcurrency,
+		checkInterval:    cfg.CompactCheckInterval.Duration(),
+		compactRequestCh: make(chan *compactRequest),
+		ioCoordinator:    ioCoordinator,
+		logger:           engineLogger,
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = defaultCompactionConcurrency
+	}
+	if s.checkInterval <= 0 {
+		s.checkInterval = defaultCompactCheckInterval
+	}
+	if cfg.CompactionThroughput > 0 {
+		throughput := int(cfg.CompactionThroughput)
+		s.limiter = rate.NewLimiter(rate.Limit(throughput), throughput)
+	}
+	return s
+}
+
+// Start starts the checker goroutine and compaction workers in background
+func (s *dataCompactionScheduler) Start() {
+	for i := 0; i < s.concurrency; i++ {
+		go s.compactWorker()
+	}
+	go s.startCheckDataCompaction()
+	s.logger.Info("DataCompaction Scheduler is running", logger.Int32("workers", int32(s.concurrency)))
+}
+
+// Stop stops the background goroutines
+func (s *dataCompactionScheduler) Stop() {
+	s.cancel()
+}
+
+// startCheckDataCompaction checks each data family of all shards if need do compaction job
+func (s *dataCompactionScheduler) startCheckDataCompaction() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			// defer compaction when query io has priority
+			if !s.ioCoordinator.AllowBackgroundIO() {
+				deferredCompacts.Incr()
+				continue
+			}
+			now := timeutil.Now()
+			GetShardManager().WalkEntry(func(shard Shard) {
+				families := shard.getAllDataFamilies()
+				dataFamiliesVec.WithTagValues(shard.DatabaseName(), shardIDStr(shard)).Update(float64(len(families)))
+				for _, family := range families {
+					if family.Family().NeedCompact() {
+						s.requestCompactJob(shard, family)
+						continue
+					}
+					s.checkFieldRetention(shard, family, now)
+				}
+			})
+		}
+	}
+}
+
+// checkFieldRetention requests a purge job if the family has dropped fields which haven't been purged
+func (s *dataCompactionScheduler) checkFieldRetention(shard Shard, family DataFamily, now int64) {
+	dropper := shard.fieldDropper(family, now)
+	if dropper == nil {
+		return
+	}
+	if signature, ok := s.purgedFamilies.Load(family); ok && signature == dropper.signature {
+		return
+	}
+	s.submit(&compactRequest{shard: shard, family: family, dropper: dropper})
+}
+
+// requestCompactJob requests a compaction job for the spec data family
+func (s *dataCompactionScheduler) requestCompactJob(shard Shard, family DataFamily) {
+	s.submit(&compactRequest{shard: shard, family: family})
+}
+
+// submit puts the request into compaction queue if family isn't in queue
+func (s *dataCompactionScheduler) submit(request *compactRequest) {
+	family := request.family
+	if _, ok := s.familyInCompacting.LoadOrStore(family, request.shard); ok {
+		// if family is in compaction queue, returns it
+		return
+	}
+	select {
+	case <-s.ctx.Done():
+		s.familyInCompacting.Delete(family)
+	case s.compactRequestCh <- request:
+	}
+}
+
+// compactWorker consumes the compaction request from chan
+func (s *dataCompactionScheduler) compactWorker() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case request := <-s.compactRequestCh:
+			// do compaction job with low priority
+			s.ioCoordinator.RunBackgroundJob(func() {
+				if request.dropper != nil {
+					s.doPurge(request)
+				} else {
+					s.doCompact(request)
+				}
+			})
+		}
+	}
+}
+
+// doCompact does the compaction job for the spec data family
+func (s *dataCompactionScheduler) doCompact(request *compactRequest) {
+	family := request.family
+	compactionInFlightGauge.Incr()
+	defer func() {
+		compactionInFlightGauge.Decr()
+		// delete family from compaction queue
+		s.familyInCompacting.Delete(family)
+	}()
+
+	compactionJobCounter.Incr()
+	db, shardID := request.shard.DatabaseName(), shardIDStr(request.shard)
+	level0Size := level0FilesSize(family.Family())
+	startTime := time.Now()
+	if err := family.Family().Compact(s.limiter); err != nil {
+		compactionFailureCounter.Incr()
+		s.logger.Error("compact data family error",
+			logger.String("shard", request.shard.ShardInfo()),
+			logger.String("family", family.Family().Name()), logger.Error(err))
+		return
+	}
+	elapsed := time.Since(startTime)
+	compactionTimerVec.WithTagValues(db, shardID).UpdateDuration(elapsed)
+	compactedBytesVec.WithTagValues(db, shardID).Add(float64(level0Size))
+	if elapsed > 0 && level0Size > 0 {
+		compactionThroughputVec.WithTagValues(db, shardID).UpdateValue(float64(level0Size) / elapsed.Seconds())
+	}
+}
+
+// doPurge removes the dropped fields of data family by full compaction
+func (s *dataCompactionScheduler) doPurge(request *compactRequest) {
+	family := request.family
+	defer s.familyInCompacting.Delete(family)
+
+	dropper := request.dropper
+	contains, err := dropper.containsDroppedFields(family.Family())
+	if err == nil && contains {
+		fieldPurgeJobCounter.Incr()
+		err = family.Family().FullCompact(map[string]interface{}{
+			metricsdata.DropFieldsParam: dropper.dropper(),
+		})
+	}
+	if errors.Is(err, kv.ErrCompacting) {
+		// retry in next check after other compaction job completed
+		return
+	}
+	if err != nil {
+		fieldPurgeFailureCounter.Incr()
+		s.logger.Error("purge fields of data family error",
+			logger.String("shard", request.shard.ShardInfo()),
+			logger.String("family", family.Family().Name()), logger.Error(err))
+		return
+	}
+	s.purgedFamilies.Store(family, dropper.signature)
+}
+
+// level0FilesSize returns the total size of level0 files in family, which are the input of compaction job.
+func level0FilesSize(family kv.Family) (size int64) {
+	snapshot := family.GetSnapshot()
+	defer snapshot.Close()
+
+	for _, file := range snapshot.GetCurrent().GetFiles(0) {
+		size += int64(file.GetFileSize())
+	}
+	return size
+}
+
+// shardIDStr returns the shard id as metric tag value.
+func shardIDStr(shard Shard) string {
+	return strconv.Itoa(int(shard.ShardID()))
+}
@@ -0,0 +1,248 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/kv/table"
+	"github.com/lindb/lindb/kv/version"
+	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
+)
+
+func TestDataCompactionScheduler_New(t *testing.T) {
+	s := newDataCompactionScheduler(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	scheduler := s.(*dataCompactionScheduler)
+	assert.Equal(t, defaultCompactionConcurrency, scheduler.concurrency)
+	assert.Equal(t, defaultCompactCheckInterval, scheduler.checkInterval)
+	assert.Nil(t, scheduler.limiter)
+
+	s = newDataCompactionScheduler(context.TODO(), config.TSDB{
+		MaxCompactionConcurrency: 3,
+		CompactionThroughput:     ltoml.Size(1024),
+		CompactCheckInterval:     ltoml.Duration(time.Second),
+	}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	scheduler = s.(*dataCompactionScheduler)
+	assert.Equal(t, 3, scheduler.concurrency)
+	assert.Equal(t, time.Second, scheduler.checkInterval)
+	assert.Equal(t, 1024, scheduler.limiter.Burst())
+}
+
+func TestDataCompactionScheduler_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kvFamily := kv.NewMockFamily(ctrl)
+	kvFamily.EXPECT().Name().Return("10").AnyTimes()
+	family := NewMockDataFamily(ctrl)
+	family.EXPECT().Family().Return(kvFamily).AnyTimes()
+	shard := NewMockShard(ctrl)
+	shard.EXPECT().ShardInfo().Return("shardInfo").AnyTimes()
+	shard.EXPECT().DatabaseName().Return("db").AnyTimes()
+	shard.EXPECT().ShardID().Return(int32(1)).AnyTimes()
+	snapshot := version.NewMockSnapshot(ctrl)
+	v := version.NewMockVersion(ctrl)
+	kvFamily.EXPECT().GetSnapshot().Return(snapshot).AnyTimes()
+	snapshot.EXPECT().GetCurrent().Return(v).AnyTimes()
+	snapshot.EXPECT().Close().AnyTimes()
+	v.EXPECT().GetFiles(0).Return([]*version.FileMeta{version.NewFileMeta(1, 1, 10, 1024)}).AnyTimes()
+	shard.EXPECT().getAllDataFamilies().Return([]DataFamily{family}).AnyTimes()
+	GetShardManager().AddShard(shard)
+	defer GetShardManager().RemoveShard(shard)
+
+	kvFamily.EXPECT().NeedCompact().Return(true).AnyTimes()
+	compacted := make(chan struct{}, 10)
+	kvFamily.EXPECT().Compact(gomock.Any()).DoAndReturn(func(_ interface{}) error {
+		compacted <- struct{}{}
+		return fmt.Errorf("err")
+	}).AnyTimes()
+
+	ioCoordinator := NewMockIOCoordinator(ctrl)
+	s := newDataCompactionScheduler(context.TODO(), config.TSDB{
+		CompactCheckInterval: ltoml.Duration(10 * time.Millisecond),
+	}, ioCoordinator)
+	// compaction deferred by query priority
+	deferred := make(chan struct{}, 10)
+	ioCoordinator.EXPECT().AllowBackgroundIO().DoAndReturn(func() bool {
+		deferred <- struct{}{}
+		return false
+	})
+	ioCoordinator.EXPECT().AllowBackgroundIO().Return(true).AnyTimes()
+	ioCoordinator.EXPECT().RunBackgroundJob(gomock.Any()).DoAndReturn(func(job func()) { job() }).AnyTimes()
+	s.Start()
+	select {
+	case <-deferred:
+	case <-time.After(time.Second):
+		t.Fatal("compaction job not deferred")
+	}
+	select {
+	case <-compacted:
+	case <-time.After(time.Second):
+		t.Fatal("compaction job not scheduled")
+	}
+	s.Stop()
+}
+
+func TestDataCompactionScheduler_requestCompactJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	family := NewMockDataFamily(ctrl)
+	shard := NewMockShard(ctrl)
+	s := newDataCompactionScheduler(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	scheduler := s.(*dataCompactionScheduler)
+	// case 1: family in compaction queue
+	scheduler.familyInCompacting.Store(family, shard)
+	s.requestCompactJob(shard, family)
+	scheduler.familyInCompacting.Delete(family)
+	// case 2: scheduler stopped
+	s.Stop()
+	s.requestCompactJob(shard, family)
+	_, ok := scheduler.familyInCompacting.Load(family)
+	assert.False(t, ok)
+}
+
+func TestDataCompactionScheduler_doCompact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kvFamily := kv.NewMockFamily(ctrl)
+	family := NewMockDataFamily(ctrl)
+	family.EXPECT().Family().Return(kvFamily).AnyTimes()
+	shard := NewMockShard(ctrl)
+	shard.EXPECT().DatabaseName().Return("compact_db").AnyTimes()
+	shard.EXPECT().ShardID().Return(int32(2)).AnyTimes()
+	snapshot := version.NewMockSnapshot(ctrl)
+	v := version.NewMockVersion(ctrl)
+	kvFamily.EXPECT().GetSnapshot().Return(snapshot)
+	snapshot.EXPECT().GetCurrent().Return(v)
+	snapshot.EXPECT().Close()
+	v.EXPECT().GetFiles(0).Return([]*version.FileMeta{
+		version.NewFileMeta(1, 1, 10, 1024),
+		version.NewFileMeta(2, 1, 10, 2048),
+	})
+	kvFamily.EXPECT().Compact(gomock.Any()).DoAndReturn(func(_ interface{}) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	s := newDataCompactionScheduler(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	s.(*dataCompactionScheduler).doCompact(&compactRequest{shard: shard, family: family})
+	assert.Equal(t, float64(3072), compactedBytesVec.WithTagValues("compact_db", "2").Get())
+}
+
+func TestDataCompactionScheduler_checkFieldRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	family := NewMockDataFamily(ctrl)
+	shard := NewMockShard(ctrl)
+	s := newDataCompactionScheduler(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	scheduler := s.(*dataCompactionScheduler)
+	scheduler.compactRequestCh = make(chan *compactRequest, 1)
+	// case 1: no field need to be dropped
+	shard.EXPECT().fieldDropper(family, int64(10)).Return(nil)
+	scheduler.checkFieldRetention(shard, family, 10)
+	assert.Len(t, scheduler.compactRequestCh, 0)
+	// case 2: family purged
+	shard.EXPECT().fieldDropper(family, int64(10)).Return(&fieldDropper{signature: "a"}).Times(2)
+	scheduler.purgedFamilies.Store(family, "a")
+	scheduler.checkFieldRetention(shard, family, 10)
+	assert.Len(t, scheduler.compactRequestCh, 0)
+	// case 3: request purge job
+	scheduler.purgedFamilies.Delete(family)
+	scheduler.checkFieldRetention(shard, family, 10)
+	request := <-scheduler.compactRequestCh
+	assert.Equal(t, "a", request.dropper.signature)
+}
+
+func TestDataCompactionScheduler_doPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kvFamily := kv.NewMockFamily(ctrl)
+	kvFamily.EXPECT().Name().Return("10").AnyTimes()
+	family := NewMockDataFamily(ctrl)
+	family.EXPECT().Family().Return(kvFamily).AnyTimes()
+	shard := NewMockShard(ctrl)
+	shard.EXPECT().ShardInfo().Return("shardInfo").AnyTimes()
+	snapshot := version.NewMockSnapshot(ctrl)
+	v := version.NewMockVersion(ctrl)
+	kvFamily.EXPECT().GetSnapshot().Return(snapshot).AnyTimes()
+	snapshot.EXPECT().GetCurrent().Return(v).AnyTimes()
+	snapshot.EXPECT().Close().AnyTimes()
+	s := newDataCompactionScheduler(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	scheduler := s.(*dataCompactionScheduler)
+	dropper := &fieldDropper{signature: "a", dropped: map[uint32]map[field.ID]struct{}{1: {2: {}}}}
+	request := &compactRequest{shard: shard, family: family, dropper: dropper}
+	isPurged := func() bool {
+		_, ok := scheduler.purgedFamilies.Load(family)
+		return ok
+	}
+	// case 1: check dropped fields failure
+	v.EXPECT().GetAllFiles().Return([]*version.FileMeta{version.NewFileMeta(1, 1, 2, 1024)}).Times(4)
+	snapshot.EXPECT().GetReader(gomock.Any()).Return(nil, fmt.Errorf("err"))
+	scheduler.doPurge(request)
+	assert.False(t, isPurged())
+	// case 2: full compact failure
+	reader := table.NewMockReader(ctrl)
+	reader.EXPECT().Path().Return("1.sst").AnyTimes()
+	snapshot.EXPECT().GetReader(gomock.Any()).Return(reader, nil).AnyTimes()
+	mockIterator := func() {
+		it := table.NewMockIterator(ctrl)
+		it.EXPECT().HasNext().Return(true)
+		it.EXPECT().Key().Return(uint32(1))
+		it.EXPECT().Value().Return(mockMetricBlock(2))
+		reader.EXPECT().Iterator().Return(it)
+	}
+	mockIterator()
+	kvFamily.EXPECT().FullCompact(gomock.Any()).Return(fmt.Errorf("err"))
+	scheduler.doPurge(request)
+	assert.False(t, isPurged())
+	// case 3: other compaction job running
+	mockIterator()
+	kvFamily.EXPECT().FullCompact(gomock.Any()).Return(kv.ErrCompacting)
+	scheduler.doPurge(request)
+	assert.False(t, isPurged())
+	// case 4: purge success
+	mockIterator()
+	kvFamily.EXPECT().FullCompact(gomock.Any()).DoAndReturn(func(params map[string]interface{}) error {
+		drop := params[metricsdata.DropFieldsParam].(metricsdata.FieldDropper)
+		assert.True(t, drop(1, 2))
+		assert.False(t, drop(1, 3))
+		return nil
+	})
+	scheduler.doPurge(request)
+	assert.True(t, isPurged())
+	_, ok := scheduler.familyInCompacting.Load(family)
+	assert.False(t, ok)
+	// case 5: family without dropped fields
+	scheduler.purgedFamilies.Delete(family)
+	v.EXPECT().GetAllFiles().Return(nil)
+	scheduler.doPurge(request)
+	assert.True(t, isPurged())
+}
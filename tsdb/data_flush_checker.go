@@ -19,14 +19,20 @@ package tsdb
 
 import (
 	"context"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/process"
 	"go.uber.org/atomic"
 
+	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/monitoring"
+	"github.com/lindb/lindb/pkg/fasttime"
 	"github.com/lindb/lindb/pkg/logger"
 )
 
@@ -37,20 +43,50 @@ var (
 	memoryUsageCheckInterval = *atomic.NewDuration(time.Second)
 )
 
+var (
+	flushCheckerScope              = linmetric.NewScope("lindb.tsdb.flush_checker")
+	memDBTotalSizeGauge            = flushCheckerScope.NewGauge("memdb_total_size")
+	processRSSGauge                = flushCheckerScope.NewGauge("process_rss")
+	flushFailuresCounter           = flushCheckerScope.NewDeltaCounter("flush_failures")
+	watermarkFlushCounter          = flushCheckerScope.NewDeltaCounter("watermark_flushes")
+	watermarkFlushFailuresCounter  = flushCheckerScope.NewDeltaCounter("watermark_flush_failures")
+	idleFamilyFlushCounter         = flushCheckerScope.NewDeltaCounter("idle_family_flushes")
+	idleFamilyFlushFailuresCounter = flushCheckerScope.NewDeltaCounter("idle_family_flush_failures")
+)
+
+// processRSSGetter returns the resident set size of current process.
+type processRSSGetter func() (uint64, error)
+
+// getProcessRSS returns the resident set size of current process.
+func getProcessRSS() (uint64, error) {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, err
+	}
+	memInfo, err := p.MemoryInfo()
+	if err != nil {
+		return 0, err
+	}
+	return memInfo.RSS, nil
+}
+
 // DataFlushChecker represents the memory database flush checker.
-// There are 4 flush policies of the Engine as below:
+// There are 5 flush policies of the Engine as below:
 // 1. FullFlush
 //    highest priority, triggered by external API from the users.
 //    this action will blocks any other flush checkers.
 // 2. GlobalMemoryUsageChecker
-//    This checker will check the global memory usage of the host periodically,
-//    when the metric is above MemoryHighWaterMark, a `watermarkFlusher` will be spawned
-//    whose responsibility is to flush the biggest shard until memory is lower than  MemoryLowWaterMark.
+//    This checker will check the total size of all memory databases and the rss of process periodically,
+//    when one of them is above MemoryHighWaterMark, the biggest families of all shards will be flushed
+//    until the memory usage is lower than MemoryLowWaterMark.
 // 3. ShardMemoryUsageChecker
 //    This checker will check each shard's memory usage periodically,
 //    If this shard is above ShardMemoryUsedThreshold. it will be flushed to disk.
 // 4. DatabaseMetaFlusher
 //    It is a simple checker which flush the meta of database to disk periodically.
+// 5. HistoricalFamilyIdleChecker
+//    This checker will check the families whose time window has passed(written by late/backfill data),
+//    if no data written within idle ttl, the family will be flushed, and reopened when late data comes again.
 //
 // a). Each shard or database is restricted to flush by one goroutine at the same time via CAS operation;
 // b). The flush workers runs concurrently;
@@ -67,8 +103,10 @@ type DataFlushChecker interface {
 
 // flushRequest represents the shard flush job request
 type flushRequest struct {
-	shard  Shard
-	global bool // above high memory watermark
+	shard      Shard
+	global     bool  // above high memory watermark
+	idle       bool  // historical family is idle
+	familyTime int64 // family need to flush when above high memory watermark or historical family is idle
 }
 
 // dataFlushChecker implements DataFlushCheck interface
@@ -79,21 +117,40 @@ type dataFlushChecker struct {
 	shardInFlushing      sync.Map
 	flushRequestCh       chan *flushRequest          // shard to flush
 	flushInFlight        atomic.Int32                // current pending in flushing
-	isWatermarkFlushing  atomic.Bool                 // this flag symbols if has goroutine in high water-mark flushing
+	isWatermarkFlushing  atomic.Int32                // number of families in high water-mark flushing
 	memoryStatGetterFunc monitoring.MemoryStatGetter // used for mocking
+	processRSSGetterFunc processRSSGetter            // used for mocking
+	ioCoordinator        IOCoordinator
+	maxMemDBTotalSize    int64
+	familyIdleTTL        int64 // idle ttl(ms) of historical family
+	highWaterMark        float64
+	lowWaterMark         float64
 	logger               *logger.Logger
 }
 
 // newDataFlushChecker creates the data flush checker
-func newDataFlushChecker(ctx context.Context) DataFlushChecker {
+func newDataFlushChecker(ctx context.Context, cfg config.TSDB, ioCoordinator IOCoordinator) DataFlushChecker {
 	c, cancel := context.WithCancel(ctx)
-	return &dataFlushChecker{
+	fc := &dataFlushChecker{
 		ctx:                  c,
 		cancel:               cancel,
 		flushRequestCh:       make(chan *flushRequest),
 		memoryStatGetterFunc: mem.VirtualMemory,
+		processRSSGetterFunc: getProcessRSS,
+		ioCoordinator:        ioCoordinator,
+		maxMemDBTotalSize:    int64(cfg.MaxMemDBTotalSize),
+		familyIdleTTL:        cfg.HistoricalFamilyIdleTTL.Duration().Milliseconds(),
+		highWaterMark:        cfg.MemoryHighWaterMark,
+		lowWaterMark:         cfg.MemoryLowWaterMark,
 		logger:               engineLogger,
 	}
+	if fc.highWaterMark <= 0 || fc.highWaterMark > 100 {
+		fc.highWaterMark = constants.MemoryHighWaterMark
+	}
+	if fc.lowWaterMark <= 0 || fc.lowWaterMark >= fc.highWaterMark {
+		fc.lowWaterMark = fc.highWaterMark * constants.MemoryLowWaterMark / constants.MemoryHighWaterMark
+	}
+	return fc
 }
 
 // Start starts the checker goroutine in background
@@ -123,21 +180,25 @@ func (fc *dataFlushChecker) startCheckDataFlush() {
 		case <-fc.ctx.Done():
 			return
 		case <-timer.C:
-			// check each shard if need do flush job
+			// check each shard if need do flush job, deferred when query io has priority,
+			// but watermark flush cannot be deferred for keeping memory safe.
+			allowFlush := fc.ioCoordinator.AllowBackgroundIO()
 			GetShardManager().WalkEntry(func(shard Shard) {
-				if shard.NeedFlush() {
-					fc.requestFlushJob(shard, false)
+				if !shard.NeedFlush() {
+					return
 				}
-			})
-			if fc.flushInFlight.Load() == 0 {
-				// check Global memory is above than the high watermark
-				stat, _ := fc.memoryStatGetterFunc()
-				if stat.UsedPercent > constants.MemoryHighWaterMark &&
-					!fc.isWatermarkFlushing.Load() {
-					// memory is higher than the high-watermark
-					// restrict watermarkFlusher concurrency thread-safe
-					fc.flushBiggestMemoryUsageShard()
+				if !allowFlush {
+					deferredFlushes.Incr()
+					return
 				}
+				fc.requestFlushJob(shard, false)
+			})
+			if allowFlush {
+				fc.flushIdleHistoricalFamilies()
+			}
+			// restrict watermark flush concurrency, check memory usage after previous flushes complete
+			if fc.isWatermarkFlushing.Load() == 0 {
+				fc.checkMemoryUsage()
 			}
 			// reset check interval
 			timer.Reset(memoryUsageCheckInterval.Load())
@@ -147,18 +208,30 @@ func (fc *dataFlushChecker) startCheckDataFlush() {
 
 // requestFlushJob requests a flush job for the spec shard
 func (fc *dataFlushChecker) requestFlushJob(shard Shard, global bool) {
-	_, ok := fc.shardInFlushing.Load(shard.ShardInfo())
-	if ok {
+	fc.submitFlushRequest(&flushRequest{shard: shard, global: global})
+}
+
+// submitFlushRequest submits the flush request to flush workers, returns false if shard is in flushing queue.
+func (fc *dataFlushChecker) submitFlushRequest(request *flushRequest) bool {
+	shardInfo := request.shard.ShardInfo()
+	if _, ok := fc.shardInFlushing.LoadOrStore(shardInfo, request.shard); ok {
 		// if shard is in flushing queue, returns it
-		return
+		return false
+	}
+	if request.global {
+		fc.isWatermarkFlushing.Inc()
 	}
-	fc.shardInFlushing.Store(shard.ShardInfo(), shard)
 	select {
 	case <-fc.ctx.Done():
-		return
-	case fc.flushRequestCh <- &flushRequest{shard: shard, global: global}:
+		if request.global {
+			fc.isWatermarkFlushing.Dec()
+		}
+		fc.shardInFlushing.Delete(shardInfo)
+		return false
+	case fc.flushRequestCh <- request:
 		// add count of flush in flight
 		fc.flushInFlight.Inc()
+		return true
 	}
 }
 
@@ -169,8 +242,10 @@ func (fc *dataFlushChecker) flushWorker() {
 		case <-fc.ctx.Done():
 			return
 		case request := <-fc.flushRequestCh:
-			// do flush job
-			fc.doFlush(request)
+			// do flush job with low priority
+			fc.ioCoordinator.RunBackgroundJob(func() {
+				fc.doFlush(request)
+			})
 		}
 	}
 }
@@ -182,47 +257,139 @@ func (fc *dataFlushChecker) doFlush(request *flushRequest) {
 	shardInfo := shard.ShardInfo()
 	defer func() {
 		if global {
-			fc.isWatermarkFlushing.Store(false)
+			fc.isWatermarkFlushing.Dec()
 		}
 		fc.flushInFlight.Dec()
 		// delete shard from flushing queue
 		fc.shardInFlushing.Delete(shardInfo)
 	}()
 
+	if request.idle {
+		idleFamilyFlushCounter.Incr()
+		if err := shard.flushFamily(request.familyTime); err != nil {
+			idleFamilyFlushFailuresCounter.Incr()
+			engineLogger.Error("flush idle historical family memory database error",
+				logger.String("shard", shardInfo), logger.Int64("family", request.familyTime), logger.Error(err))
+		}
+		return
+	}
 	if global {
-		fc.isWatermarkFlushing.Store(true)
+		watermarkFlushCounter.Incr()
+		if err := shard.flushFamily(request.familyTime); err != nil {
+			watermarkFlushFailuresCounter.Incr()
+			engineLogger.Error("flush family memory database error when above high watermark",
+				logger.String("shard", shardInfo), logger.Int64("family", request.familyTime), logger.Error(err))
+		}
+		return
 	}
 	if err := shard.Flush(); err != nil {
-		//TODO add metric
+		flushFailuresCounter.Incr()
 		engineLogger.Error("flush shard memory database error",
 			logger.String("shard", shardInfo), logger.Error(err))
 	}
 }
 
-// flushBiggestMemoryUsageShard picks the biggest memory usage shard to flush
-func (fc *dataFlushChecker) flushBiggestMemoryUsageShard() {
-	var (
-		biggestShard   Shard
-		biggestMemSize int32
-	)
+// flushIdleHistoricalFamilies flushes the historical families which have no data written within idle ttl,
+// each shard flushes one family per check.
+func (fc *dataFlushChecker) flushIdleHistoricalFamilies() {
+	if fc.familyIdleTTL <= 0 {
+		return
+	}
+	now := fasttime.UnixMilliseconds()
 	GetShardManager().WalkEntry(func(shard Shard) {
-		// skip shard in flushing
 		if shard.IsFlushing() {
 			return
 		}
+		families := shard.idleHistoricalFamilies(now, fc.familyIdleTTL)
+		if len(families) == 0 {
+			return
+		}
+		fc.submitFlushRequest(&flushRequest{shard: shard, idle: true, familyTime: families[0]})
+	})
+}
+
+// familyMemSize represents the memory size of family's memory database under shard
+type familyMemSize struct {
+	shard      Shard
+	familyTime int64
+	memSize    int64
+}
 
-		//FIXME(stone1100)
-		theShardSize := int32(1024)
-		//shard.MemoryDatabase().MemSize()
-		if theShardSize > biggestMemSize {
-			// pick a shard that has biggest memory size
-			biggestMemSize = theShardSize
-			biggestShard = shard
+// checkMemoryUsage checks the total size of memory databases and the rss of process,
+// if above high watermark, picks the biggest families to flush until below low watermark.
+func (fc *dataFlushChecker) checkMemoryUsage() {
+	var (
+		families   []familyMemSize
+		memDBTotal int64
+	)
+	GetShardManager().WalkEntry(func(shard Shard) {
+		for _, entry := range shard.memDBEntries() {
+			size := int64(entry.memDB.MemSize())
+			memDBTotal += size
+			families = append(families, familyMemSize{shard: shard, familyTime: entry.familyTime, memSize: size})
 		}
 	})
-	if biggestMemSize == 0 {
+	memDBTotalSizeGauge.Update(float64(memDBTotal))
+
+	needFree := fc.memDBBytesToFree(memDBTotal)
+	if rssNeedFree := fc.rssBytesToFree(); rssNeedFree > needFree {
+		needFree = rssNeedFree
+	}
+	if needFree <= 0 {
 		return
 	}
-	// request flush job
-	fc.requestFlushJob(biggestShard, true)
+	fc.flushBiggestFamilies(families, needFree)
+}
+
+// memDBBytesToFree returns the bytes need to free if total size of memory databases above high watermark.
+func (fc *dataFlushChecker) memDBBytesToFree(memDBTotal int64) int64 {
+	if fc.maxMemDBTotalSize <= 0 {
+		return 0
+	}
+	if float64(memDBTotal) <= float64(fc.maxMemDBTotalSize)*fc.highWaterMark/100 {
+		return 0
+	}
+	return memDBTotal - int64(float64(fc.maxMemDBTotalSize)*fc.lowWaterMark/100)
+}
+
+// rssBytesToFree returns the bytes need to free if the rss of process above high watermark of total memory.
+func (fc *dataFlushChecker) rssBytesToFree() int64 {
+	rss, err := fc.processRSSGetterFunc()
+	if err != nil {
+		return 0
+	}
+	processRSSGauge.Update(float64(rss))
+	stat, err := fc.memoryStatGetterFunc()
+	if err != nil || stat == nil || stat.Total == 0 {
+		return 0
+	}
+	if float64(rss)*100/float64(stat.Total) <= fc.highWaterMark {
+		return 0
+	}
+	return int64(rss) - int64(float64(stat.Total)*fc.lowWaterMark/100)
+}
+
+// flushBiggestFamilies flushes the biggest families first until the freed bytes is enough,
+// each shard can only flush one family at the same time.
+func (fc *dataFlushChecker) flushBiggestFamilies(families []familyMemSize, needFree int64) {
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].memSize > families[j].memSize
+	})
+	var freed int64
+	for _, family := range families {
+		if freed >= needFree || family.memSize <= 0 {
+			return
+		}
+		// skip shard in flushing
+		if family.shard.IsFlushing() {
+			continue
+		}
+		if fc.submitFlushRequest(&flushRequest{
+			shard:      family.shard,
+			global:     true,
+			familyTime: family.familyTime,
+		}) {
+			freed += family.memSize
+		}
+	}
 }
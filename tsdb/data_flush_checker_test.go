@@ -25,8 +25,11 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/shirou/gopsutil/mem"
+	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/tsdb/memdb"
 )
 
@@ -40,9 +43,10 @@ func TestDataFlushChecker_Start(t *testing.T) {
 	shard.EXPECT().NeedFlush().Return(true).AnyTimes()
 	shard.EXPECT().ShardInfo().Return("shardInfo").AnyTimes()
 	shard.EXPECT().Flush().Return(fmt.Errorf("err")).AnyTimes()
+	shard.EXPECT().memDBEntries().Return(nil).AnyTimes()
 	GetShardManager().AddShard(shard)
 	memoryUsageCheckInterval.Store(10 * time.Millisecond)
-	checker := newDataFlushChecker(context.TODO())
+	checker := newDataFlushChecker(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
 	checker.Start()
 
 	time.Sleep(100 * time.Millisecond)
@@ -61,51 +65,106 @@ func TestDataFlushChecker_check_high_memory_waterMark(t *testing.T) {
 	shard.EXPECT().NeedFlush().Return(false).AnyTimes()
 	shard.EXPECT().ShardInfo().Return("shardInfo").AnyTimes()
 	shard.EXPECT().IsFlushing().Return(true).AnyTimes()
+	mDB := memdb.NewMockMemoryDatabase(ctrl)
+	mDB.EXPECT().MemSize().Return(int32(1000)).AnyTimes()
+	shard.EXPECT().memDBEntries().Return(memDBEntries{{familyTime: 1, memDB: mDB}}).AnyTimes()
 	GetShardManager().AddShard(shard)
 	memoryUsageCheckInterval.Store(10 * time.Millisecond)
-	checker := newDataFlushChecker(context.TODO())
-	check := checker.(*dataFlushChecker)
-	check.memoryStatGetterFunc = func() (stat *mem.VirtualMemoryStat, err error) {
-		return &mem.VirtualMemoryStat{UsedPercent: constants.MemoryHighWaterMark + 0.1}, nil
-	}
+	checker := newDataFlushChecker(context.TODO(), config.TSDB{MaxMemDBTotalSize: 1000}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
 	checker.Start()
 
 	time.Sleep(100 * time.Millisecond)
 	checker.Stop()
 	GetShardManager().RemoveShard(shard)
 
-	// case 2: pick biggest shard data
+	// case 2: pick biggest family data
 	shard1 := NewMockShard(ctrl)
 	shard1.EXPECT().NeedFlush().Return(false).AnyTimes()
-	shard1.EXPECT().ShardInfo().Return("shardInfo").AnyTimes()
+	shard1.EXPECT().ShardInfo().Return("shardInfo1").AnyTimes()
 	shard1.EXPECT().IsFlushing().Return(false).AnyTimes()
 	mDB1 := memdb.NewMockMemoryDatabase(ctrl)
 	mDB1.EXPECT().MemSize().Return(int32(100)).AnyTimes()
+	shard1.EXPECT().memDBEntries().Return(memDBEntries{{familyTime: 1, memDB: mDB1}}).AnyTimes()
 	GetShardManager().AddShard(shard1)
 
 	shard2 := NewMockShard(ctrl)
 	shard2.EXPECT().NeedFlush().Return(false).AnyTimes()
-	shard2.EXPECT().ShardInfo().Return("shardInfo").AnyTimes()
+	shard2.EXPECT().ShardInfo().Return("shardInfo2").AnyTimes()
 	shard2.EXPECT().IsFlushing().Return(false).AnyTimes()
-	shard2.EXPECT().Flush().Return(nil).AnyTimes()
 	mDB2 := memdb.NewMockMemoryDatabase(ctrl)
 	mDB2.EXPECT().MemSize().Return(int32(1000)).AnyTimes()
+	shard2.EXPECT().memDBEntries().Return(memDBEntries{{familyTime: 2, memDB: mDB2}}).AnyTimes()
+	flushed := make(chan int64, 10)
+	shard2.EXPECT().flushFamily(int64(2)).DoAndReturn(func(familyTime int64) error {
+		flushed <- familyTime
+		return fmt.Errorf("err")
+	}).AnyTimes()
 	GetShardManager().AddShard(shard2)
 
-	memoryUsageCheckInterval.Store(10 * time.Millisecond)
-	checker = newDataFlushChecker(context.TODO())
+	// total memdb size(1100) > 80% of 1200, free 1100-720 bytes, only flush biggest family
+	checker = newDataFlushChecker(context.TODO(), config.TSDB{MaxMemDBTotalSize: 1200}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	check := checker.(*dataFlushChecker)
+	check.processRSSGetterFunc = func() (uint64, error) {
+		return 0, fmt.Errorf("err")
+	}
+	checker.Start()
+	assert.Equal(t, int64(2), <-flushed)
+	checker.Stop()
+
+	// case 3: process rss above high watermark
+	checker = newDataFlushChecker(context.TODO(), config.TSDB{MemoryHighWaterMark: 50, MemoryLowWaterMark: 20}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
 	check = checker.(*dataFlushChecker)
+	check.processRSSGetterFunc = func() (uint64, error) {
+		return 60, nil
+	}
 	check.memoryStatGetterFunc = func() (stat *mem.VirtualMemoryStat, err error) {
-		return &mem.VirtualMemoryStat{UsedPercent: constants.MemoryHighWaterMark + 0.1}, nil
+		return &mem.VirtualMemoryStat{Total: 100}, nil
 	}
 	checker.Start()
-
-	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(2), <-flushed)
 	checker.Stop()
 	GetShardManager().RemoveShard(shard1)
 	GetShardManager().RemoveShard(shard2)
 }
 
+func TestDataFlushChecker_bytesToFree(t *testing.T) {
+	checker := newDataFlushChecker(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil)).(*dataFlushChecker)
+	assert.Equal(t, float64(constants.MemoryHighWaterMark), checker.highWaterMark)
+	assert.Equal(t, float64(constants.MemoryLowWaterMark), checker.lowWaterMark)
+	// no limit
+	assert.Equal(t, int64(0), checker.memDBBytesToFree(1000))
+
+	checker = newDataFlushChecker(context.TODO(), config.TSDB{
+		MaxMemDBTotalSize:   1000,
+		MemoryHighWaterMark: 90,
+		MemoryLowWaterMark:  95,
+	}, newIOCoordinator(context.TODO(), config.TSDB{}, nil)).(*dataFlushChecker)
+	// low watermark is invalid, calc by default ratio
+	assert.Equal(t, 67.5, checker.lowWaterMark)
+	assert.Equal(t, int64(0), checker.memDBBytesToFree(900))
+	assert.Equal(t, int64(1000-675), checker.memDBBytesToFree(1000))
+
+	checker.processRSSGetterFunc = func() (uint64, error) {
+		return 50, nil
+	}
+	checker.memoryStatGetterFunc = func() (stat *mem.VirtualMemoryStat, err error) {
+		return nil, fmt.Errorf("err")
+	}
+	assert.Equal(t, int64(0), checker.rssBytesToFree())
+	checker.memoryStatGetterFunc = func() (stat *mem.VirtualMemoryStat, err error) {
+		return &mem.VirtualMemoryStat{Total: 100}, nil
+	}
+	assert.Equal(t, int64(0), checker.rssBytesToFree())
+	checker.processRSSGetterFunc = func() (uint64, error) {
+		return 95, nil
+	}
+	assert.Equal(t, int64(95-67), checker.rssBytesToFree())
+
+	rss, err := getProcessRSS()
+	assert.NoError(t, err)
+	assert.True(t, rss > 0)
+}
+
 func TestDataFlushChecker_requestFlush(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -121,15 +180,12 @@ func TestDataFlushChecker_requestFlush(t *testing.T) {
 			time.Sleep(200 * time.Millisecond)
 			return fmt.Errorf("err")
 		}).AnyTimes()
+		shard.EXPECT().memDBEntries().Return(nil).AnyTimes()
 		GetShardManager().AddShard(shard)
 		shards = append(shards, shard)
 	}
 	memoryUsageCheckInterval.Store(10 * time.Millisecond)
-	checker := newDataFlushChecker(context.TODO())
-	check := checker.(*dataFlushChecker)
-	check.memoryStatGetterFunc = func() (stat *mem.VirtualMemoryStat, err error) {
-		return &mem.VirtualMemoryStat{UsedPercent: constants.MemoryHighWaterMark + 0.1}, nil
-	}
+	checker := newDataFlushChecker(context.TODO(), config.TSDB{}, newIOCoordinator(context.TODO(), config.TSDB{}, nil))
 	checker.Start()
 
 	time.Sleep(100 * time.Millisecond)
@@ -142,6 +198,53 @@ func TestDataFlushChecker_requestFlush(t *testing.T) {
 	checker.requestFlushJob(shard, true)  // reject, because has pending flush job
 
 	for _, shard := range shards {
-		GetShardManager().AddShard(shard)
+		GetShardManager().RemoveShard(shard)
 	}
 }
+
+func TestDataFlushChecker_flushIdleHistoricalFamilies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		memoryUsageCheckInterval.Store(time.Second)
+		ctrl.Finish()
+	}()
+	// case 1: shard is flushing
+	shard1 := NewMockShard(ctrl)
+	shard1.EXPECT().NeedFlush().Return(false).AnyTimes()
+	shard1.EXPECT().ShardInfo().Return("shardInfo1").AnyTimes()
+	shard1.EXPECT().IsFlushing().Return(true).AnyTimes()
+	shard1.EXPECT().memDBEntries().Return(nil).AnyTimes()
+	GetShardManager().AddShard(shard1)
+	// case 2: no idle family
+	shard2 := NewMockShard(ctrl)
+	shard2.EXPECT().NeedFlush().Return(false).AnyTimes()
+	shard2.EXPECT().ShardInfo().Return("shardInfo2").AnyTimes()
+	shard2.EXPECT().IsFlushing().Return(false).AnyTimes()
+	shard2.EXPECT().memDBEntries().Return(nil).AnyTimes()
+	shard2.EXPECT().idleHistoricalFamilies(gomock.Any(), int64(1000)).Return(nil).AnyTimes()
+	GetShardManager().AddShard(shard2)
+	// case 3: flush first idle family
+	shard3 := NewMockShard(ctrl)
+	shard3.EXPECT().NeedFlush().Return(false).AnyTimes()
+	shard3.EXPECT().ShardInfo().Return("shardInfo3").AnyTimes()
+	shard3.EXPECT().IsFlushing().Return(false).AnyTimes()
+	shard3.EXPECT().memDBEntries().Return(nil).AnyTimes()
+	shard3.EXPECT().idleHistoricalFamilies(gomock.Any(), int64(1000)).Return([]int64{10, 20}).AnyTimes()
+	flushed := make(chan int64, 10)
+	shard3.EXPECT().flushFamily(gomock.Any()).DoAndReturn(func(familyTime int64) error {
+		flushed <- familyTime
+		return fmt.Errorf("err")
+	}).AnyTimes()
+	GetShardManager().AddShard(shard3)
+
+	memoryUsageCheckInterval.Store(10 * time.Millisecond)
+	checker := newDataFlushChecker(context.TODO(),
+		config.TSDB{HistoricalFamilyIdleTTL: ltoml.Duration(time.Second)},
+		newIOCoordinator(context.TODO(), config.TSDB{}, nil))
+	checker.Start()
+	assert.Equal(t, int64(10), <-flushed)
+	checker.Stop()
+	GetShardManager().RemoveShard(shard1)
+	GetShardManager().RemoveShard(shard2)
+	GetShardManager().RemoveShard(shard3)
+}
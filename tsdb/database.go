@@ -62,10 +62,15 @@ type Database interface {
 	Name() string
 	// NumOfShards returns number of shards in time series database
 	NumOfShards() int
+	// ShardIDs returns the ids of shards in time series database in ascending order
+	ShardIDs() []int32
 	// GetOption returns the data base options
 	GetOption() option.DatabaseOption
 	// CreateShards creates shards for data partition
 	CreateShards(option option.DatabaseOption, shardIDs []int32) error
+	// UpdateOption modifies the database option, then applies it to all shards,
+	// returns err if write interval or family window is changed.
+	UpdateOption(option option.DatabaseOption) error
 	// GetShard returns shard by given shard id
 	GetShard(shardID int32) (Shard, bool)
 	// ExecutorPool returns the pool for querying tasks
@@ -78,6 +83,10 @@ type Database interface {
 	FlushMeta() error
 	// Flush flushes memory data of all shards to disk
 	Flush() error
+	// FlushAll flushes meta and all memory data of all shards to disk synchronously
+	FlushAll() error
+	// SetMaxSeriesPerMetric sets the max number of series under metric for all shards, 0 means no limit
+	SetMaxSeriesPerMetric(limit uint32)
 }
 
 // databaseConfig represents a database configuration about config and shards
@@ -99,6 +108,8 @@ type database struct {
 	metaStore    kv.Store        // underlying meta kv store
 	isFlushing   atomic.Bool     // restrict flusher concurrency
 
+	maxSeriesPerMetric atomic.Uint32 // 0 means no limit
+
 	flushChecker DataFlushChecker
 }
 
@@ -187,6 +198,16 @@ func (db *database) NumOfShards() int {
 	return db.shardSet.GetShardNum()
 }
 
+// ShardIDs returns the ids of shards in time series database in ascending order
+func (db *database) ShardIDs() []int32 {
+	entries := db.shardSet.Entries()
+	shardIDs := make([]int32, len(entries))
+	for idx, entry := range entries {
+		shardIDs[idx] = entry.shardID
+	}
+	return shardIDs
+}
+
 func (db *database) GetOption() option.DatabaseOption {
 	return db.config.Option
 }
@@ -211,6 +232,34 @@ func (db *database) CreateShards(
 	return nil
 }
 
+// UpdateOption modifies the database option, then applies it to all shards,
+// returns err if write interval or family window is changed.
+func (db *database) UpdateOption(newOption option.DatabaseOption) error {
+	if err := newOption.Validate(); err != nil {
+		return err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	oldOption := db.config.Option
+	if oldOption.Interval != "" && oldOption.Interval != newOption.Interval {
+		return fmt.Errorf("write interval of database[%s] cannot be changed", db.name)
+	}
+	if oldOption.FamilyWindow != newOption.FamilyWindow {
+		return fmt.Errorf("family window of database[%s] cannot be changed", db.name)
+	}
+	if err := db.dumpDatabaseConfig(&databaseConfig{Option: newOption, ShardIDs: db.config.ShardIDs}); err != nil {
+		return err
+	}
+	syncPolicy := newOption.GetSyncPolicy()
+	db.metaStore.SetSyncPolicy(syncPolicy)
+	db.metadata.MetadataDatabase().SetSyncPolicy(syncPolicy)
+	for _, shardEntry := range db.shardSet.Entries() {
+		shardEntry.shard.UpdateOption(newOption)
+	}
+	return nil
+}
+
 // createShard creates a new shard based on option
 func (db *database) createShard(shardID int32, option option.DatabaseOption) error {
 	// be careful need do mutex unlock
@@ -238,6 +287,9 @@ func (db *database) createShard(shardID int32, option option.DatabaseOption) err
 	if err := db.dumpDatabaseConfig(newCfg); err != nil {
 		return err
 	}
+	if indexDB := createdShard.IndexDatabase(); indexDB != nil {
+		indexDB.SetMaxSeriesPerMetric(db.maxSeriesPerMetric.Load())
+	}
 	db.shardSet.InsertShard(shardID, createdShard)
 	return nil
 }
@@ -284,6 +336,7 @@ func (db *database) dumpDatabaseConfig(newConfig *databaseConfig) error {
 // initMetadata initializes metadata backend storage
 func (db *database) initMetadata() error {
 	metaStoreOption := kv.DefaultStoreOption(filepath.Join(db.path, metaDir, tagMetaDir))
+	metaStoreOption.SyncPolicy = db.config.Option.GetSyncPolicy()
 	//FIXME close kv store if err??
 	metaStore, err := newKVStoreFunc(metaStoreOption.Path, metaStoreOption)
 	if err != nil {
@@ -302,6 +355,7 @@ func (db *database) initMetadata() error {
 	if err != nil {
 		return err
 	}
+	metadata.MetadataDatabase().SetSyncPolicy(metaStoreOption.SyncPolicy)
 	db.metadata = metadata
 	return nil
 }
@@ -325,6 +379,32 @@ func (db *database) Flush() error {
 	return nil
 }
 
+// SetMaxSeriesPerMetric sets the max number of series under metric for all shards, 0 means no limit
+func (db *database) SetMaxSeriesPerMetric(limit uint32) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	db.maxSeriesPerMetric.Store(limit)
+	for _, shardEntry := range db.shardSet.Entries() {
+		if indexDB := shardEntry.shard.IndexDatabase(); indexDB != nil {
+			indexDB.SetMaxSeriesPerMetric(limit)
+		}
+	}
+}
+
+// FlushAll flushes meta and all memory data of all shards to disk synchronously
+func (db *database) FlushAll() error {
+	if err := db.metadata.Flush(); err != nil {
+		return err
+	}
+	for _, shardEntry := range db.shardSet.Entries() {
+		if err := shardEntry.shard.FlushAll(); err != nil {
+			return fmt.Errorf("flush shard[%d] of database[%s] error: %w", shardEntry.shardID, db.name, err)
+		}
+	}
+	return nil
+}
+
 // optionsPath returns options file path
 func optionsPath(path string) string {
 	return filepath.Join(path, options)
@@ -28,9 +28,11 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/tsdb/indexdb"
 	"github.com/lindb/lindb/tsdb/metadb"
 )
 
@@ -108,11 +110,15 @@ func TestDatabase_New(t *testing.T) {
 	assert.NotNil(t, db.ExecutorPool())
 	assert.Equal(t, option.DatabaseOption{Interval: "10s"}, db.GetOption())
 	assert.Equal(t, 3, db.NumOfShards())
+	assert.Equal(t, []int32{1, 2, 3}, db.ShardIDs())
 	kvStore.EXPECT().Close().Return(nil).AnyTimes() // include shard close
 	err = db.Close()
 	assert.NoError(t, err)
 	// case 7: close metadata err when create db
 	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB)
+	metadataDB.EXPECT().SetSyncPolicy(bufioutil.DefaultSyncPolicy)
 	newMetadataFunc = func(ctx context.Context, databaseName, parent string, tagFamily kv.Family) (metadb.Metadata, error) {
 		return metadata, nil
 	}
@@ -156,9 +162,14 @@ func TestDatabase_CreateShards(t *testing.T) {
 	// case 3: create exist shard
 	err = db.CreateShards(option.DatabaseOption{}, []int32{1, 2, 3})
 	assert.NoError(t, err)
-	// case 4: create shard success
+	// case 4: create shard success, apply max series limit of metric
+	db.SetMaxSeriesPerMetric(100)
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	indexDB.EXPECT().SetMaxSeriesPerMetric(uint32(100)).Times(3)
 	newShardFunc = func(db Database, shardID int32, shardPath string, option option.DatabaseOption) (s Shard, err error) {
-		return nil, nil
+		shard := NewMockShard(ctrl)
+		shard.EXPECT().IndexDatabase().Return(indexDB).AnyTimes()
+		return shard, nil
 	}
 	err = db.CreateShards(option.DatabaseOption{}, []int32{4, 5, 6})
 	assert.NoError(t, err)
@@ -177,6 +188,55 @@ func TestDatabase_CreateShards(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDatabase_UpdateOption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	_ = fileutil.MkDirIfNotExist(testPath)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		encodeToml = ltoml.EncodeToml
+		ctrl.Finish()
+	}()
+	db := &database{
+		name:     "db",
+		path:     testPath,
+		shardSet: *newShardSet(),
+		config: &databaseConfig{
+			ShardIDs: []int32{1},
+			Option:   option.DatabaseOption{Interval: "10s"},
+		},
+	}
+	shard1 := NewMockShard(ctrl)
+	db.shardSet.InsertShard(1, shard1)
+	metaStore := kv.NewMockStore(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	db.metaStore = metaStore
+	db.metadata = metadata
+
+	// case 1: invalid option
+	assert.Error(t, db.UpdateOption(option.DatabaseOption{}))
+	// case 2: interval cannot be changed
+	assert.Error(t, db.UpdateOption(option.DatabaseOption{Interval: "1m"}))
+	// case 3: family window cannot be changed
+	assert.Error(t, db.UpdateOption(option.DatabaseOption{Interval: "10s", FamilyWindow: "10m"}))
+	// case 4: dump option err
+	encodeToml = func(fileName string, v interface{}) error {
+		return fmt.Errorf("err")
+	}
+	assert.Error(t, db.UpdateOption(option.DatabaseOption{Interval: "10s", Ahead: "1h"}))
+	encodeToml = ltoml.EncodeToml
+	// case 5: update option successfully
+	newOption := option.DatabaseOption{Interval: "10s", Ahead: "1h", Behind: "1h", SyncPolicy: option.SyncPolicyNever}
+	syncPolicy := bufioutil.SyncPolicy{Mode: bufioutil.SyncNever}
+	metaStore.EXPECT().SetSyncPolicy(syncPolicy)
+	metadataDB.EXPECT().SetSyncPolicy(syncPolicy)
+	shard1.EXPECT().UpdateOption(newOption)
+	assert.NoError(t, db.UpdateOption(newOption))
+	assert.Equal(t, newOption, db.GetOption())
+	assert.Equal(t, []int32{1}, db.config.ShardIDs)
+}
+
 func TestDatabase_Close(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -253,6 +313,50 @@ func TestDatabase_Flush(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDatabase_SetMaxSeriesPerMetric(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := &database{
+		shardSet: *newShardSet(),
+	}
+	shard1 := NewMockShard(ctrl)
+	shard2 := NewMockShard(ctrl)
+	db.shardSet.InsertShard(1, shard1)
+	db.shardSet.InsertShard(2, shard2)
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	shard1.EXPECT().IndexDatabase().Return(indexDB)
+	shard2.EXPECT().IndexDatabase().Return(nil)
+	indexDB.EXPECT().SetMaxSeriesPerMetric(uint32(10))
+	db.SetMaxSeriesPerMetric(10)
+	assert.Equal(t, uint32(10), db.maxSeriesPerMetric.Load())
+}
+
+func TestDatabase_FlushAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := metadb.NewMockMetadata(ctrl)
+	db := &database{
+		name:     "db",
+		metadata: metadata,
+		shardSet: *newShardSet(),
+	}
+	shard1 := NewMockShard(ctrl)
+	db.shardSet.InsertShard(1, shard1)
+	// case 1: flush meta err
+	metadata.EXPECT().Flush().Return(fmt.Errorf("err"))
+	assert.Error(t, db.FlushAll())
+	// case 2: flush shard err
+	metadata.EXPECT().Flush().Return(nil)
+	shard1.EXPECT().FlushAll().Return(fmt.Errorf("err"))
+	assert.Error(t, db.FlushAll())
+	// case 3: flush successfully
+	metadata.EXPECT().Flush().Return(nil)
+	shard1.EXPECT().FlushAll().Return(nil)
+	assert.NoError(t, db.FlushAll())
+}
+
 func Test_ShardSet_multi(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -24,11 +24,14 @@ import (
 	"sync"
 
 	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/concurrent"
 	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/ltoml"
 	"github.com/lindb/lindb/pkg/option"
+
+	"go.uber.org/atomic"
 )
 
 //go:generate mockgen -source=./engine.go -destination=./engine_mock.go -package=tsdb
@@ -57,12 +60,24 @@ type Engine interface {
 		databaseOption option.DatabaseOption,
 		shardIDs ...int32,
 	) error
+	// UpdateDatabaseOption modifies the option of database if exist,
+	// 1) dump engine option into local disk
+	// 2) apply new option to all shards of database
+	UpdateDatabaseOption(databaseName string, databaseOption option.DatabaseOption) error
 	// GetShard returns shard by given db and shard id
 	GetShard(databaseName string, shardID int32) (Shard, bool)
 	// GetDatabase returns the time series database by given name
 	GetDatabase(databaseName string) (Database, bool)
 	// FlushDatabase produces a signal to workers for flushing memory database by name
 	FlushDatabase(ctx context.Context, databaseName string) bool
+	// FlushAll flushes all memory data of all databases to disk synchronously
+	FlushAll() error
+	// WriteShaper returns the write rate shaper of databases
+	WriteShaper() WriteShaper
+	// IOCoordinator returns the io coordinator between background flush/compaction and query
+	IOCoordinator() IOCoordinator
+	// SetMaxSeriesPerMetric sets the max number of series under metric for all databases, 0 means no limit
+	SetMaxSeriesPerMetric(limit uint32)
 	// Close closes the cached time series databases
 	Close()
 
@@ -79,11 +94,18 @@ type engine struct {
 	ctx              context.Context    // context
 	cancel           context.CancelFunc // cancel function of flusher
 	dataFlushChecker DataFlushChecker
+	compactScheduler DataCompactionScheduler
+	writeShaper      WriteShaper
+	ioCoordinator    IOCoordinator
+
+	maxSeriesPerMetric atomic.Uint32 // 0 means no limit
 }
 
-// NewEngine creates an engine for manipulating the databases
-func NewEngine(cfg config.TSDB) (Engine, error) {
-	engine, err := newEngine(cfg)
+// NewEngine creates an engine for manipulating the databases,
+// background flush/compaction jobs are executed in backgroundPool(shared with query tasks) with low priority,
+// nil means run them directly.
+func NewEngine(cfg config.TSDB, backgroundPool concurrent.Pool) (Engine, error) {
+	engine, err := newEngine(cfg, backgroundPool)
 	if err != nil {
 		return nil, err
 	}
@@ -91,18 +113,23 @@ func NewEngine(cfg config.TSDB) (Engine, error) {
 }
 
 // newEngine creates an engine
-func newEngine(cfg config.TSDB) (*engine, error) {
+func newEngine(cfg config.TSDB, backgroundPool concurrent.Pool) (*engine, error) {
 	// create time series storage path
 	if err := mkDirIfNotExist(cfg.Dir); err != nil {
 		return nil, fmt.Errorf("create time sereis storage path[%s] erorr: %s", cfg.Dir, err)
 	}
 	e := &engine{
-		cfg:   cfg,
-		dbSet: *newDatabaseSet(),
+		cfg:         cfg,
+		dbSet:       *newDatabaseSet(),
+		writeShaper: newWriteShaper(cfg.MaxWriteRate),
 	}
 	e.ctx, e.cancel = context.WithCancel(context.Background())
-	e.dataFlushChecker = newDataFlushChecker(e.ctx)
+	e.ioCoordinator = newIOCoordinator(e.ctx, cfg, backgroundPool)
+	e.ioCoordinator.Start()
+	e.dataFlushChecker = newDataFlushChecker(e.ctx, cfg, e.ioCoordinator)
 	e.dataFlushChecker.Start()
+	e.compactScheduler = newDataCompactionScheduler(e.ctx, cfg, e.ioCoordinator)
+	e.compactScheduler.Start()
 
 	if err := e.load(); err != nil {
 		engineLogger.Error("load engine data error when create a new engine", logger.Error(err))
@@ -132,7 +159,9 @@ func (e *engine) createDatabase(databaseName string) (Database, error) {
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxSeriesPerMetric(e.maxSeriesPerMetric.Load())
 	e.dbSet.PutDatabase(databaseName, db)
+	e.writeShaper.SetWeight(databaseName, cfg.Option.GetWriteWeight())
 	return db, nil
 }
 
@@ -169,9 +198,35 @@ func (e *engine) CreateShards(
 		return err
 	}
 	engineLogger.Info("create shard successfully", logger.String("shardIDs", string(shardIDData)))
+	// update write weight, maybe changed by master
+	e.writeShaper.SetWeight(databaseName, databaseOption.GetWriteWeight())
 	return nil
 }
 
+// WriteShaper returns the write rate shaper of databases
+// UpdateDatabaseOption modifies the option of database if exist, ignores if database not exist,
+// because new created shard uses the latest option.
+func (e *engine) UpdateDatabaseOption(databaseName string, databaseOption option.DatabaseOption) error {
+	db, ok := e.GetDatabase(databaseName)
+	if !ok {
+		return nil
+	}
+	if err := db.UpdateOption(databaseOption); err != nil {
+		return err
+	}
+	e.writeShaper.SetWeight(databaseName, databaseOption.GetWriteWeight())
+	return nil
+}
+
+func (e *engine) WriteShaper() WriteShaper {
+	return e.writeShaper
+}
+
+// IOCoordinator returns the io coordinator between background flush/compaction and query
+func (e *engine) IOCoordinator() IOCoordinator {
+	return e.ioCoordinator
+}
+
 // GetDatabase returns the time series database by given name
 func (e *engine) GetDatabase(databaseName string) (Database, bool) {
 	return e.dbSet.GetDatabase(databaseName)
@@ -191,6 +246,12 @@ func (e *engine) Close() {
 	if e.dataFlushChecker != nil {
 		e.dataFlushChecker.Stop()
 	}
+	if e.compactScheduler != nil {
+		e.compactScheduler.Stop()
+	}
+	if e.ioCoordinator != nil {
+		e.ioCoordinator.Stop()
+	}
 	for dbName, db := range e.dbSet.Entries() {
 		if err := db.Close(); err != nil {
 			engineLogger.Error("close database",
@@ -213,6 +274,30 @@ func (e *engine) FlushDatabase(ctx context.Context, name string) bool {
 	return true
 }
 
+// SetMaxSeriesPerMetric sets the max number of series under metric for all databases, 0 means no limit
+func (e *engine) SetMaxSeriesPerMetric(limit uint32) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.maxSeriesPerMetric.Store(limit)
+	for _, db := range e.dbSet.Entries() {
+		db.SetMaxSeriesPerMetric(limit)
+	}
+}
+
+// FlushAll flushes all memory data of all databases to disk synchronously
+func (e *engine) FlushAll() (err error) {
+	for dbName, db := range e.dbSet.Entries() {
+		if err0 := db.FlushAll(); err0 != nil {
+			engineLogger.Error("flush database",
+				logger.String("name", dbName),
+				logger.Error(err0))
+			err = err0
+		}
+	}
+	return
+}
+
 // load loads the time series engines if exist
 func (e *engine) load() error {
 	databaseNames, err := listDir(e.cfg.Dir)
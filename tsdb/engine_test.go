@@ -31,6 +31,7 @@ import (
 	"github.com/lindb/lindb/config"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/ltoml"
+	"github.com/lindb/lindb/pkg/option"
 )
 
 var testPath = "test_data"
@@ -47,7 +48,7 @@ func TestNew(t *testing.T) {
 	mkDirIfNotExist = func(path string) error {
 		return fmt.Errorf("err")
 	}
-	e, err := NewEngine(engineCfg)
+	e, err := NewEngine(engineCfg, nil)
 	assert.Error(t, err)
 	assert.Nil(t, e)
 	mkDirIfNotExist = fileutil.MkDirIfNotExist
@@ -56,12 +57,12 @@ func TestNew(t *testing.T) {
 	listDir = func(path string) (strings []string, e error) {
 		return nil, fmt.Errorf("err")
 	}
-	e, err = NewEngine(engineCfg)
+	e, err = NewEngine(engineCfg, nil)
 	assert.Error(t, err)
 	assert.Nil(t, e)
 	listDir = fileutil.ListDir
 
-	e, err = NewEngine(engineCfg)
+	e, err = NewEngine(engineCfg, nil)
 	assert.NoError(t, err)
 
 	db, err := e.createDatabase("test_db")
@@ -78,7 +79,7 @@ func TestNew(t *testing.T) {
 		}
 		return fileutil.MkDirIfNotExist(path)
 	}
-	e, err = NewEngine(engineCfg)
+	e, err = NewEngine(engineCfg, nil)
 	assert.Error(t, err)
 	assert.Nil(t, e)
 }
@@ -91,7 +92,7 @@ func TestEngine_CreateDatabase(t *testing.T) {
 		newDatabaseFunc = newDatabase
 	}()
 
-	e, err := NewEngine(engineCfg)
+	e, err := NewEngine(engineCfg, nil)
 	assert.NoError(t, err)
 
 	db, err := e.createDatabase("test_db")
@@ -102,6 +103,8 @@ func TestEngine_CreateDatabase(t *testing.T) {
 	_, ok := e.GetDatabase("inexist")
 	assert.False(t, ok)
 	assert.NotNil(t, db.ExecutorPool())
+	// default write weight
+	assert.Equal(t, 1, e.WriteShaper().(*writeShaper).limiters["test_db"].weight)
 
 	e.Close()
 
@@ -109,13 +112,13 @@ func TestEngine_CreateDatabase(t *testing.T) {
 	decodeToml = func(fileName string, v interface{}) error {
 		return fmt.Errorf("err")
 	}
-	e, err = NewEngine(engineCfg)
+	e, err = NewEngine(engineCfg, nil)
 	assert.Error(t, err)
 	assert.Nil(t, e)
 	decodeToml = ltoml.DecodeToml
 
 	// re-open engine
-	e, err = NewEngine(engineCfg)
+	e, err = NewEngine(engineCfg, nil)
 	assert.NoError(t, err)
 	db, ok = e.GetDatabase("test_db")
 	assert.True(t, ok)
@@ -149,7 +152,7 @@ func Test_Engine_Close(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	e, _ := NewEngine(engineCfg)
+	e, _ := NewEngine(engineCfg, nil)
 	engineImpl := e.(*engine)
 	defer engineImpl.cancel()
 
@@ -168,7 +171,7 @@ func Test_Engine_Flush_Database(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
 	}()
-	e, _ := NewEngine(engineCfg)
+	e, _ := NewEngine(engineCfg, nil)
 	engineImpl := e.(*engine)
 	defer engineImpl.cancel()
 	ok := e.FlushDatabase(context.TODO(), "test_db_3")
@@ -186,6 +189,77 @@ func Test_Engine_Flush_Database(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func Test_Engine_FlushAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+	e, _ := NewEngine(engineCfg, nil)
+	engineImpl := e.(*engine)
+	defer engineImpl.cancel()
+	assert.NoError(t, e.FlushAll())
+
+	mockDatabase := NewMockDatabase(ctrl)
+	engineImpl.dbSet.PutDatabase("test_db_1", mockDatabase)
+	// case 1: flush success
+	mockDatabase.EXPECT().FlushAll().Return(nil)
+	assert.NoError(t, e.FlushAll())
+	// case 2: flush err
+	mockDatabase.EXPECT().FlushAll().Return(fmt.Errorf("err"))
+	assert.Error(t, e.FlushAll())
+}
+
+func Test_Engine_UpdateDatabaseOption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+	e, _ := NewEngine(engineCfg, nil)
+	engineImpl := e.(*engine)
+	defer engineImpl.cancel()
+
+	newOption := option.DatabaseOption{Interval: "10s", WriteWeight: 3}
+	// case 1: database not exist
+	assert.NoError(t, e.UpdateDatabaseOption("test_db_1", newOption))
+	mockDatabase := NewMockDatabase(ctrl)
+	engineImpl.dbSet.PutDatabase("test_db_1", mockDatabase)
+	// case 2: update err
+	mockDatabase.EXPECT().UpdateOption(newOption).Return(fmt.Errorf("err"))
+	assert.Error(t, e.UpdateDatabaseOption("test_db_1", newOption))
+	// case 3: update successfully
+	mockDatabase.EXPECT().UpdateOption(newOption).Return(nil)
+	assert.NoError(t, e.UpdateDatabaseOption("test_db_1", newOption))
+	assert.Equal(t, 3, e.WriteShaper().(*writeShaper).limiters["test_db_1"].weight)
+}
+
+func Test_Engine_SetMaxSeriesPerMetric(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newDatabaseFunc = newDatabase
+		ctrl.Finish()
+	}()
+	e, _ := NewEngine(engineCfg, nil)
+	engineImpl := e.(*engine)
+	defer engineImpl.cancel()
+
+	mockDatabase := NewMockDatabase(ctrl)
+	engineImpl.dbSet.PutDatabase("test_db_1", mockDatabase)
+	mockDatabase.EXPECT().SetMaxSeriesPerMetric(uint32(100))
+	e.SetMaxSeriesPerMetric(100)
+	// new database uses the max series limit
+	newDatabaseFunc = func(databaseName string, databasePath string, cfg *databaseConfig,
+		checker DataFlushChecker) (d Database, err error) {
+		return mockDatabase, nil
+	}
+	mockDatabase.EXPECT().SetMaxSeriesPerMetric(uint32(100))
+	db, err := e.createDatabase("test_db_2")
+	assert.NoError(t, err)
+	assert.NotNil(t, db)
+}
+
 var testDatabaseNames = []string{
 	"_internal", "system", "docker", "network", "java",
 	"runtime", "go", "php", "k8s", "infra", "prometheus",
@@ -0,0 +1,210 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package exemplar
+
+import (
+	"encoding/hex"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+// evictSamples is the num. of series sampled when evicting series from full store,
+// the series with the oldest exemplar among samples is evicted(approximated LRU).
+const evictSamples = 8
+
+// Exemplar represents the trace observation linked to the histogram bucket of series.
+type Exemplar struct {
+	TraceID   string
+	SpanID    string
+	Value     float64
+	Timestamp int64
+	Bucket    float64 // upper bound of histogram bucket which value falls into
+}
+
+// Store keeps a bounded set of the latest exemplars of each histogram series in memory,
+// exemplars are side data for drilling down from metrics to traces, so they are not persisted.
+type Store struct {
+	capacity  int // max num. of exemplars for each series
+	maxSeries int // max num. of series
+	series    map[uint64]*seriesExemplars
+	mutex     sync.RWMutex
+	metrics   storeMetrics
+}
+
+type storeMetrics struct {
+	// Series is the num. of series which has exemplars
+	Series *linmetric.BoundGauge
+	// Adds is the num. of exemplars added
+	Adds *linmetric.BoundDeltaCounter
+	// Evicts is the num. of series evicted when store is full
+	Evicts *linmetric.BoundDeltaCounter
+}
+
+func newStoreMetrics(scope linmetric.Scope) *storeMetrics {
+	return &storeMetrics{
+		Series: scope.NewGauge("series"),
+		Adds:   scope.NewDeltaCounter("adds"),
+		Evicts: scope.NewDeltaCounter("series_evicts"),
+	}
+}
+
+// seriesExemplars is the ring buffer of exemplars of series.
+type seriesExemplars struct {
+	exemplars []Exemplar
+	next      int
+	latest    int64 // timestamp of the latest exemplar
+}
+
+// NewStore creates the exemplar store which keeps at most capacity exemplars for each series,
+// and at most maxSeries series.
+func NewStore(capacity, maxSeries int, scope linmetric.Scope) *Store {
+	return &Store{
+		capacity:  capacity,
+		maxSeries: maxSeries,
+		series:    make(map[uint64]*seriesExemplars),
+		metrics:   *newStoreMetrics(scope),
+	}
+}
+
+// Add adds the exemplars of histogram into the series, timestamp of metric is used if exemplar's timestamp not set,
+// the oldest exemplar of series is replaced if series is full.
+func (s *Store) Add(metricID, seriesID uint32, timestamp int64, histogram *protoMetricsV1.CompoundField) {
+	if histogram == nil || len(histogram.Exemplars) == 0 {
+		return
+	}
+	key := uint64(metricID)<<32 | uint64(seriesID)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.series[key]
+	if !ok {
+		if len(s.series) >= s.maxSeries {
+			s.evict()
+		}
+		entry = &seriesExemplars{exemplars: make([]Exemplar, 0, s.capacity)}
+		s.series[key] = entry
+		s.metrics.Series.Update(float64(len(s.series)))
+	}
+	for _, e := range histogram.Exemplars {
+		if len(e.TraceId) == 0 || math.IsNaN(e.Value) {
+			continue
+		}
+		exemplar := Exemplar{
+			TraceID:   hex.EncodeToString(e.TraceId),
+			SpanID:    hex.EncodeToString(e.SpanId),
+			Value:     e.Value,
+			Timestamp: e.Timestamp,
+			Bucket:    bucketOf(histogram.ExplicitBounds, e.Value),
+		}
+		if exemplar.Timestamp <= 0 {
+			exemplar.Timestamp = timestamp
+		}
+		if entry.add(exemplar, s.capacity) {
+			s.metrics.Adds.Incr()
+		}
+	}
+}
+
+// Get returns the exemplars of series within time range, sorted by timestamp.
+func (s *Store) Get(metricID, seriesID uint32, timeRange timeutil.TimeRange) []Exemplar {
+	key := uint64(metricID)<<32 | uint64(seriesID)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, ok := s.series[key]
+	if !ok {
+		return nil
+	}
+	var result []Exemplar
+	for _, e := range entry.exemplars {
+		if timeRange.Contains(e.Timestamp) {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp < result[j].Timestamp
+	})
+	return result
+}
+
+// Size returns the num. of series which has exemplars.
+func (s *Store) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.series)
+}
+
+// evict evicts the series with the oldest exemplar among the sampled series.
+func (s *Store) evict() {
+	var (
+		evictKey uint64
+		oldest   int64 = math.MaxInt64
+		sampled  int
+	)
+	for key, entry := range s.series {
+		if entry.latest < oldest {
+			oldest = entry.latest
+			evictKey = key
+		}
+		sampled++
+		if sampled >= evictSamples {
+			break
+		}
+	}
+	if sampled > 0 {
+		delete(s.series, evictKey)
+		s.metrics.Evicts.Incr()
+	}
+}
+
+// add adds exemplar into ring buffer, returns false if the same trace/span exists,
+// because the same exemplar is reported by each scrape until a new one observed.
+func (e *seriesExemplars) add(exemplar Exemplar, capacity int) bool {
+	for idx := range e.exemplars {
+		if e.exemplars[idx].TraceID == exemplar.TraceID && e.exemplars[idx].SpanID == exemplar.SpanID {
+			return false
+		}
+	}
+	if len(e.exemplars) < capacity {
+		e.exemplars = append(e.exemplars, exemplar)
+	} else {
+		e.exemplars[e.next] = exemplar
+		e.next = (e.next + 1) % capacity
+	}
+	if exemplar.Timestamp > e.latest {
+		e.latest = exemplar.Timestamp
+	}
+	return true
+}
+
+// bucketOf returns the upper bound of histogram bucket which value falls into,
+// buckets are inclusive of their upper boundary.
+func bucketOf(explicitBounds []float64, value float64) float64 {
+	idx := sort.SearchFloat64s(explicitBounds, value)
+	if idx < len(explicitBounds) {
+		return explicitBounds[idx]
+	}
+	return math.Inf(1)
+}
@@ -0,0 +1,88 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package exemplar
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+func newHistogram(exemplars ...*protoMetricsV1.Exemplar) *protoMetricsV1.CompoundField {
+	return &protoMetricsV1.CompoundField{
+		Type:           protoMetricsV1.CompoundFieldType_CUMULATIVE_HISTOGRAM,
+		ExplicitBounds: []float64{0.1, 0.5, 1, math.Inf(1)},
+		Values:         []float64{1, 2, 3, 4},
+		Exemplars:      exemplars,
+	}
+}
+
+func TestStore_Add(t *testing.T) {
+	store := NewStore(2, 10, linmetric.NewScope("test_exemplar_add"))
+	store.Add(1, 1, 100, nil)
+	store.Add(1, 1, 100, newHistogram())
+	assert.Equal(t, 0, store.Size())
+
+	store.Add(1, 1, 100, newHistogram(
+		&protoMetricsV1.Exemplar{TraceId: []byte{0xab}, SpanId: []byte{0x01}, Value: 0.3},
+		&protoMetricsV1.Exemplar{Value: 0.3}, // no trace id
+		&protoMetricsV1.Exemplar{TraceId: []byte{0xcd}, Value: math.NaN()},
+	))
+	assert.Equal(t, 1, store.Size())
+	timeRange := timeutil.TimeRange{Start: 0, End: 1000}
+	assert.Equal(t, []Exemplar{{TraceID: "ab", SpanID: "01", Value: 0.3, Timestamp: 100, Bucket: 0.5}},
+		store.Get(1, 1, timeRange))
+	// same exemplar reported again
+	store.Add(1, 1, 200, newHistogram(&protoMetricsV1.Exemplar{TraceId: []byte{0xab}, SpanId: []byte{0x01}, Value: 0.3}))
+	assert.Len(t, store.Get(1, 1, timeRange), 1)
+	// replace the oldest exemplar if series is full
+	store.Add(1, 1, 200, newHistogram(&protoMetricsV1.Exemplar{TraceId: []byte{0x01}, Value: 2, Timestamp: 300}))
+	store.Add(1, 1, 300, newHistogram(&protoMetricsV1.Exemplar{TraceId: []byte{0x02}, Value: 0.01, Timestamp: 250}))
+	assert.Equal(t, []Exemplar{
+		{TraceID: "02", SpanID: "", Value: 0.01, Timestamp: 250, Bucket: 0.1},
+		{TraceID: "01", SpanID: "", Value: 2, Timestamp: 300, Bucket: math.Inf(1)},
+	}, store.Get(1, 1, timeRange))
+	// filter by time range
+	assert.Len(t, store.Get(1, 1, timeutil.TimeRange{Start: 260, End: 1000}), 1)
+	assert.Empty(t, store.Get(1, 2, timeRange))
+}
+
+func TestStore_evict(t *testing.T) {
+	store := NewStore(2, 2, linmetric.NewScope("test_exemplar_evict"))
+	store.Add(1, 1, 100, newHistogram(&protoMetricsV1.Exemplar{TraceId: []byte{1}}))
+	store.Add(1, 2, 200, newHistogram(&protoMetricsV1.Exemplar{TraceId: []byte{2}}))
+	store.Add(1, 3, 300, newHistogram(&protoMetricsV1.Exemplar{TraceId: []byte{3}}))
+	assert.Equal(t, 2, store.Size())
+	timeRange := timeutil.TimeRange{Start: 0, End: 1000}
+	// series with the oldest exemplar evicted
+	assert.Empty(t, store.Get(1, 1, timeRange))
+	assert.Len(t, store.Get(1, 2, timeRange), 1)
+	assert.Len(t, store.Get(1, 3, timeRange), 1)
+}
+
+func TestBucketOf(t *testing.T) {
+	assert.Equal(t, 1.0, bucketOf([]float64{1, 2}, 1))
+	assert.Equal(t, 2.0, bucketOf([]float64{1, 2}, 1.5))
+	assert.True(t, math.IsInf(bucketOf([]float64{1, 2}, 3), 1))
+	assert.True(t, math.IsInf(bucketOf(nil, 3), 1))
+}
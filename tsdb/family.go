@@ -105,8 +105,12 @@ func (f *dataFamily) Filter(metricID uint32,
 	var metricReaders []metricsdata.MetricReader
 	for _, reader := range readers {
 		value, ok := reader.Get(metricID)
-		// metric data not found
 		if !ok {
+			// value maybe corrupted, fail query for avoiding partial results
+			if err := reader.Corrupted(); err != nil {
+				return nil, err
+			}
+			// metric data not found
 			continue
 		}
 		r, err := newReaderFunc(reader.Path(), value)
@@ -28,6 +28,8 @@ import (
 type memDBEntry struct {
 	familyTime int64
 	memDB      memdb.MemoryDatabase
+	// replica heads when memory database created, the data written into it has larger sequence
+	heads map[string]int64
 }
 
 type memDBEntries []memDBEntry
@@ -49,12 +51,12 @@ func newFamilyMemDBSet() *familyMemDBSet {
 	return set
 }
 
-// InsertFamily inserts a new family into the set
-func (ss *familyMemDBSet) InsertFamily(familyTime int64, memDB memdb.MemoryDatabase) {
+// InsertFamily inserts a new family into the set, heads are the replica heads when memory database created.
+func (ss *familyMemDBSet) InsertFamily(familyTime int64, memDB memdb.MemoryDatabase, heads map[string]int64) {
 	oldEntries := ss.value.Load().(memDBEntries)
 	var (
 		newEntries memDBEntries
-		newEntry   = memDBEntry{familyTime: familyTime, memDB: memDB}
+		newEntry   = memDBEntry{familyTime: familyTime, memDB: memDB, heads: heads}
 	)
 
 	newEntries = make([]memDBEntry, oldEntries.Len()+1)
@@ -64,6 +66,18 @@ func (ss *familyMemDBSet) InsertFamily(familyTime int64, memDB memdb.MemoryDatab
 	ss.value.Store(newEntries)
 }
 
+// RemoveFamily removes the family from the set
+func (ss *familyMemDBSet) RemoveFamily(familyTime int64) {
+	oldEntries := ss.value.Load().(memDBEntries)
+	newEntries := make([]memDBEntry, 0, oldEntries.Len())
+	for idx := range oldEntries {
+		if oldEntries[idx].familyTime != familyTime {
+			newEntries = append(newEntries, oldEntries[idx])
+		}
+	}
+	ss.value.Store(memDBEntries(newEntries))
+}
+
 // GetFamily searches the memDB by familyTime from the familyMemDBSet
 func (ss *familyMemDBSet) GetFamily(familyTime int64) (memdb.MemoryDatabase, bool) {
 	entries := ss.value.Load().(memDBEntries)
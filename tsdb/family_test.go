@@ -18,7 +18,10 @@
 package tsdb
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -27,6 +30,8 @@ import (
 	"github.com/lindb/lindb/kv"
 	"github.com/lindb/lindb/kv/table"
 	"github.com/lindb/lindb/kv/version"
+	"github.com/lindb/lindb/pkg/bufioutil"
+	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
 )
@@ -81,11 +86,20 @@ func TestDataFamily_Filter(t *testing.T) {
 	reader.EXPECT().Path().Return("test_path").AnyTimes()
 	snapshot.EXPECT().FindReaders(gomock.Any()).Return([]table.Reader{reader}, nil)
 	reader.EXPECT().Get(gomock.Any()).Return(nil, false)
+	reader.EXPECT().Corrupted().Return(nil)
 	rs, err = dataFamily.Filter(uint32(10), nil, timeutil.TimeRange{}, nil)
 	assert.NoError(t, err)
 	assert.Nil(t, rs)
 
-	// case 3: new metric reader err
+	// case 3: value of reader is corrupted
+	snapshot.EXPECT().FindReaders(gomock.Any()).Return([]table.Reader{reader}, nil)
+	reader.EXPECT().Get(gomock.Any()).Return(nil, false)
+	reader.EXPECT().Corrupted().Return(table.ErrCorrupted)
+	rs, err = dataFamily.Filter(uint32(10), nil, timeutil.TimeRange{}, nil)
+	assert.True(t, errors.Is(err, table.ErrCorrupted))
+	assert.Nil(t, rs)
+
+	// case 4: new metric reader err
 	newReaderFunc = func(file string, buf []byte) (reader metricsdata.MetricReader, err error) {
 		return nil, fmt.Errorf("err")
 	}
@@ -95,7 +109,7 @@ func TestDataFamily_Filter(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, rs)
 
-	// case 4: normal case
+	// case 5: normal case
 	newReaderFunc = func(file string, buf []byte) (reader metricsdata.MetricReader, err error) {
 		return nil, nil
 	}
@@ -109,3 +123,40 @@ func TestDataFamily_Filter(t *testing.T) {
 	_, err = dataFamily.Filter(uint32(10), nil, timeutil.TimeRange{}, nil)
 	assert.NoError(t, err)
 }
+
+func TestDataFamily_Filter_corrupted_file(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+	_ = fileutil.MkDirIfNotExist(testPath)
+	fileName := filepath.Join(testPath, "000001.sst")
+	builder, err := table.NewStoreBuilder(1, fileName, bufioutil.DefaultSyncPolicy)
+	assert.NoError(t, err)
+	assert.NoError(t, builder.Add(10, []byte("metric-data")))
+	assert.NoError(t, builder.Close())
+	// corrupt the value of metric
+	data, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	data[1] = 'x'
+	assert.NoError(t, os.WriteFile(fileName, data, 0644))
+
+	cache := table.NewCache(testPath, 0)
+	defer func() {
+		_ = cache.Close()
+	}()
+	reader, err := cache.GetReader("", "000001.sst")
+	assert.NoError(t, err)
+
+	family := kv.NewMockFamily(ctrl)
+	snapshot := version.NewMockSnapshot(ctrl)
+	snapshot.EXPECT().Close()
+	family.EXPECT().GetSnapshot().Return(snapshot)
+	snapshot.EXPECT().FindReaders(gomock.Any()).Return([]table.Reader{reader}, nil)
+	dataFamily := newDataFamily(timeutil.Interval(timeutil.OneSecond*10), timeutil.TimeRange{Start: 10, End: 50}, family)
+	// query fails instead of returning partial results
+	rs, err := dataFamily.Filter(uint32(10), nil, timeutil.TimeRange{}, nil)
+	assert.True(t, errors.Is(err, table.ErrCorrupted))
+	assert.Nil(t, rs)
+}
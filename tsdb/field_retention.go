@@ -0,0 +1,137 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/tsdb/metadb"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
+)
+
+// fieldDropper drops the fields of data family based on the field retentions of database option,
+// field is dropped if its retention has passed, or it's excluded from the rollup interval of data family.
+type fieldDropper struct {
+	rules     []option.FieldRetention // matched rules of data family
+	signature string                  // identifies the matched rules, family needn't purge again for same rules
+	metadata  metadb.MetadataDatabase
+
+	dropped map[uint32]map[field.ID]struct{} // metric id => dropped field ids
+	mutex   sync.Mutex
+}
+
+// newFieldDropper creates the field dropper of data family, returns nil if no field need to be dropped.
+func newFieldDropper(
+	opt option.DatabaseOption,
+	writeInterval timeutil.Interval,
+	family DataFamily,
+	metadata metadb.MetadataDatabase,
+	now int64,
+) *fieldDropper {
+	var rules []option.FieldRetention
+	var patterns []string
+	isRollup := family.Interval() != writeInterval
+	for _, rule := range opt.FieldRetentions {
+		retention := rule.GetRetention().Int64()
+		expired := retention > 0 && family.TimeRange().End+retention < now
+		excluded := isRollup && !rule.KeepRollup(family.Interval())
+		if expired || excluded {
+			rules = append(rules, rule)
+			patterns = append(patterns, rule.Field)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &fieldDropper{
+		rules:     rules,
+		signature: strings.Join(patterns, ","),
+		metadata:  metadata,
+		dropped:   make(map[uint32]map[field.ID]struct{}),
+	}
+}
+
+// dropper returns the merger param for removing the dropped fields.
+func (d *fieldDropper) dropper() metricsdata.FieldDropper {
+	return func(metricID uint32, fieldID field.ID) bool {
+		_, ok := d.droppedFields(metricID)[fieldID]
+		return ok
+	}
+}
+
+// containsDroppedFields checks if the files of family contain any dropped field.
+func (d *fieldDropper) containsDroppedFields(family kv.Family) (bool, error) {
+	snapshot := family.GetSnapshot()
+	defer snapshot.Close()
+
+	for _, file := range snapshot.GetCurrent().GetAllFiles() {
+		reader, err := snapshot.GetReader(file.GetFileNumber())
+		if err != nil {
+			return false, err
+		}
+		it := reader.Iterator()
+		for it.HasNext() {
+			// NOTICE: must read key and value together, because both move the cursor of iterator.
+			metricID := it.Key()
+			value := it.Value()
+			dropped := d.droppedFields(metricID)
+			if len(dropped) == 0 {
+				continue
+			}
+			metricReader, err := metricsdata.NewReader(reader.Path(), value)
+			if err != nil {
+				return false, err
+			}
+			for _, f := range metricReader.GetFields() {
+				if _, ok := dropped[f.ID]; ok {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// droppedFields returns the dropped field ids of metric, keeps all fields if cannot get fields of metric.
+func (d *fieldDropper) droppedFields(metricID uint32) map[field.ID]struct{} {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if fields, ok := d.dropped[metricID]; ok {
+		return fields
+	}
+	fields := make(map[field.ID]struct{})
+	metas, err := d.metadata.GetAllFieldsByMetricID(metricID)
+	if err == nil {
+		for _, meta := range metas {
+			for _, rule := range d.rules {
+				if rule.Match(string(meta.Name)) {
+					fields[meta.ID] = struct{}{}
+					break
+				}
+			}
+		}
+	}
+	d.dropped[metricID] = fields
+	return fields
+}
@@ -0,0 +1,161 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/kv/table"
+	"github.com/lindb/lindb/kv/version"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/tsdb/metadb"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
+)
+
+func TestFieldDropper_new(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	writeInterval := timeutil.Interval(10 * timeutil.OneSecond)
+	rollupInterval := timeutil.Interval(timeutil.OneHour)
+	now := timeutil.Now()
+	family := NewMockDataFamily(ctrl)
+	family.EXPECT().TimeRange().Return(timeutil.TimeRange{
+		Start: now - 10*timeutil.OneDay, End: now - 9*timeutil.OneDay,
+	}).AnyTimes()
+	opt := option.DatabaseOption{
+		Interval: "10s",
+		Rollup:   []string{"1h"},
+		FieldRetentions: []option.FieldRetention{
+			{Field: "__bucket_*", Retention: "7d"},
+			{Field: "HistogramSum", Retention: "30d", NoRollup: true},
+		},
+	}
+	// case 1: bucket fields expired
+	family.EXPECT().Interval().Return(writeInterval)
+	dropper := newFieldDropper(opt, writeInterval, family, nil, now)
+	assert.NotNil(t, dropper)
+	assert.Equal(t, "__bucket_*", dropper.signature)
+	// case 2: rollup family, sum field excluded from rollup
+	family.EXPECT().Interval().Return(rollupInterval).AnyTimes()
+	dropper = newFieldDropper(opt, writeInterval, family, nil, now)
+	assert.Equal(t, "__bucket_*,HistogramSum", dropper.signature)
+	// case 3: no field need to be dropped
+	assert.Nil(t, newFieldDropper(option.DatabaseOption{Interval: "10s"}, writeInterval, family, nil, now))
+	family = NewMockDataFamily(ctrl)
+	family.EXPECT().TimeRange().Return(timeutil.TimeRange{
+		Start: now - 10*timeutil.OneDay, End: now - 9*timeutil.OneDay,
+	}).AnyTimes()
+	family.EXPECT().Interval().Return(writeInterval)
+	assert.Nil(t, newFieldDropper(opt, writeInterval, family, nil, now-5*timeutil.OneDay))
+}
+
+func TestFieldDropper_dropper(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := metadb.NewMockMetadataDatabase(ctrl)
+	dropper := &fieldDropper{
+		rules:    []option.FieldRetention{{Field: "__bucket_*", Retention: "7d"}},
+		metadata: metadata,
+		dropped:  make(map[uint32]map[field.ID]struct{}),
+	}
+	metadata.EXPECT().GetAllFieldsByMetricID(uint32(1)).Return(field.Metas{
+		{ID: 1, Name: "HistogramSum"},
+		{ID: 2, Name: "__bucket_0"},
+	}, nil)
+	metadata.EXPECT().GetAllFieldsByMetricID(uint32(2)).Return(nil, fmt.Errorf("err"))
+	drop := dropper.dropper()
+	assert.False(t, drop(1, 1))
+	assert.True(t, drop(1, 2))
+	// cached
+	assert.True(t, drop(1, 2))
+	// keeps all fields if get fields failure
+	assert.False(t, drop(2, 2))
+}
+
+func TestFieldDropper_containsDroppedFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().GetAllFieldsByMetricID(uint32(1)).Return(field.Metas{{ID: 1, Name: "HistogramSum"}}, nil).AnyTimes()
+	metadata.EXPECT().GetAllFieldsByMetricID(uint32(2)).Return(field.Metas{{ID: 5, Name: "__bucket_0"}}, nil).AnyTimes()
+	newDropper := func() *fieldDropper {
+		return &fieldDropper{
+			rules:    []option.FieldRetention{{Field: "__bucket_*", Retention: "7d"}},
+			metadata: metadata,
+			dropped:  make(map[uint32]map[field.ID]struct{}),
+		}
+	}
+	family := kv.NewMockFamily(ctrl)
+	snapshot := version.NewMockSnapshot(ctrl)
+	v := version.NewMockVersion(ctrl)
+	family.EXPECT().GetSnapshot().Return(snapshot).AnyTimes()
+	snapshot.EXPECT().GetCurrent().Return(v).AnyTimes()
+	snapshot.EXPECT().Close().AnyTimes()
+	v.EXPECT().GetAllFiles().Return([]*version.FileMeta{version.NewFileMeta(1, 1, 2, 1024)}).AnyTimes()
+	reader := table.NewMockReader(ctrl)
+	reader.EXPECT().Path().Return("1.sst").AnyTimes()
+	mockIterator := func(keys []uint32, values [][]byte) {
+		it := table.NewMockIterator(ctrl)
+		for idx := range keys {
+			it.EXPECT().HasNext().Return(true)
+			it.EXPECT().Key().Return(keys[idx])
+			it.EXPECT().Value().Return(values[idx])
+		}
+		it.EXPECT().HasNext().Return(false).MaxTimes(1)
+		reader.EXPECT().Iterator().Return(it)
+	}
+	// case 1: get reader failure
+	snapshot.EXPECT().GetReader(gomock.Any()).Return(nil, fmt.Errorf("err"))
+	_, err := newDropper().containsDroppedFields(family)
+	assert.Error(t, err)
+	snapshot.EXPECT().GetReader(gomock.Any()).Return(reader, nil).AnyTimes()
+	// case 2: bad metric data
+	mockIterator([]uint32{2}, [][]byte{{1, 2, 3}})
+	_, err = newDropper().containsDroppedFields(family)
+	assert.Error(t, err)
+	// case 3: not contains dropped fields
+	mockIterator([]uint32{1, 2}, [][]byte{mockMetricBlock(1), mockMetricBlock(1)})
+	contains, err := newDropper().containsDroppedFields(family)
+	assert.NoError(t, err)
+	assert.False(t, contains)
+	// case 4: contains dropped fields
+	mockIterator([]uint32{1, 2}, [][]byte{mockMetricBlock(1), mockMetricBlock(5)})
+	contains, err = newDropper().containsDroppedFields(family)
+	assert.NoError(t, err)
+	assert.True(t, contains)
+}
+
+func mockMetricBlock(fieldID field.ID) []byte {
+	nopKVFlusher := kv.NewNopFlusher()
+	flusher := metricsdata.NewFlusher(nopKVFlusher)
+	flusher.FlushFieldMetas(field.Metas{{ID: fieldID, Type: field.SumField}})
+	flusher.FlushField([]byte{1, 2, 3})
+	flusher.FlushSeries(1)
+	_ = flusher.FlushMetric(1, 10, 10)
+	return nopKVFlusher.Bytes()
+}
@@ -45,7 +45,8 @@ var (
 )
 
 var (
-	seriesBucketName = []byte("s")
+	seriesBucketName    = []byte("s")
+	tombstoneBucketName = []byte("t")
 )
 
 // IDMappingBackend represents the id mapping backend storage,
@@ -58,6 +59,10 @@ type IDMappingBackend interface {
 	getSeriesID(metricID uint32, tagsHash uint64) (seriesID uint32, err error)
 	// saveMapping saves the id mapping event
 	saveMapping(event *mappingEvent) (err error)
+	// saveTombstone saves the series tombstone data of metric, removes it if data is empty
+	saveTombstone(metricID uint32, data []byte) (err error)
+	// loadTombstones loads all series tombstone data
+	loadTombstones(fn func(metricID uint32, data []byte) error) (err error)
 }
 
 // idMappingBackend implements IDMappingBackend interface
@@ -81,6 +86,11 @@ func newIDMappingBackend(parent string) (IDMappingBackend, error) {
 		if err != nil {
 			return err
 		}
+		// create tombstone bucket for save deleted series of metric
+		_, err = tx.CreateBucketIfNotExists(tombstoneBucketName)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 	if err != nil {
@@ -175,6 +185,30 @@ func (imb *idMappingBackend) saveMapping(event *mappingEvent) (err error) {
 	return err
 }
 
+// saveTombstone saves the series tombstone data of metric, removes it if data is empty
+func (imb *idMappingBackend) saveTombstone(metricID uint32, data []byte) (err error) {
+	var scratch [4]byte
+	binary.LittleEndian.PutUint32(scratch[:], metricID)
+	err = imb.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(tombstoneBucketName)
+		if len(data) == 0 {
+			return bucket.Delete(scratch[:])
+		}
+		return putFunc(bucket, scratch[:], data)
+	})
+	return err
+}
+
+// loadTombstones loads all series tombstone data
+func (imb *idMappingBackend) loadTombstones(fn func(metricID uint32, data []byte) error) (err error) {
+	err = imb.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tombstoneBucketName).ForEach(func(k, v []byte) error {
+			return fn(binary.LittleEndian.Uint32(k), v)
+		})
+	})
+	return err
+}
+
 // Close closes the bbolt.DB
 func (imb *idMappingBackend) Close() error {
 	return imb.db.Close()
@@ -123,6 +123,46 @@ func TestIdMappingBackend_mapping(t *testing.T) {
 	assert.Equal(t, uint32(300), mapping1.idSequence.Load())
 }
 
+func TestIdMappingBackend_tombstone(t *testing.T) {
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		putFunc = put
+	}()
+	backend, err := newIDMappingBackend(testPath)
+	assert.NoError(t, err)
+	// case 1: save tombstone
+	assert.NoError(t, backend.saveTombstone(1, []byte{1, 2, 3}))
+	assert.NoError(t, backend.saveTombstone(2, []byte{4, 5}))
+	// case 2: remove tombstone
+	assert.NoError(t, backend.saveTombstone(2, nil))
+	// case 3: save tombstone err
+	putFunc = func(bucket *bbolt.Bucket, key, value []byte) error {
+		return fmt.Errorf("err")
+	}
+	assert.Error(t, backend.saveTombstone(3, []byte{1}))
+	putFunc = put
+	err = backend.Close()
+	assert.NoError(t, err)
+
+	// reopen
+	backend, err = newIDMappingBackend(testPath)
+	assert.NoError(t, err)
+	tombstones := make(map[uint32][]byte)
+	err = backend.loadTombstones(func(metricID uint32, data []byte) error {
+		tombstones[metricID] = append([]byte{}, data...)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint32][]byte{1: {1, 2, 3}}, tombstones)
+	// case 4: load tombstone err
+	err = backend.loadTombstones(func(metricID uint32, data []byte) error {
+		return fmt.Errorf("err")
+	})
+	assert.Error(t, err)
+	err = backend.Close()
+	assert.NoError(t, err)
+}
+
 func TestIdMappingBackend_save_err(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
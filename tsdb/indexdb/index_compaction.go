@@ -0,0 +1,135 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package indexdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lindb/roaring"
+
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/logger"
+	"github.com/lindb/lindb/tsdb/tblstore/invertedindex"
+)
+
+// checkCompact checks if need compact index, if need, does compaction job in background goroutine.
+func (db *indexDatabase) checkCompact() {
+	if !db.needCompact() {
+		return
+	}
+	if db.compacting.CAS(false, true) {
+		go func() {
+			defer db.compacting.Store(false)
+
+			if err := db.compact(); err != nil {
+				compactIndexFailCounterVec.WithTagValues(db.metadata.DatabaseName()).Incr()
+				indexLogger.Error("compact index error",
+					logger.String("db", db.path), logger.Error(err))
+			}
+		}()
+	}
+}
+
+// needCompact checks if need compact index based on deleted series,
+// 1) number of deleted series >= threshold;
+// 2) ratio of deleted series under metric >= threshold.
+func (db *indexDatabase) needCompact() bool {
+	deletedSeries := db.tombstone.numOfDeletedSeries()
+	total := uint64(0)
+	for _, deleted := range deletedSeries {
+		total += deleted
+	}
+	if total >= compactTombstoneThreshold {
+		return true
+	}
+	for metricID, deleted := range deletedSeries {
+		sequence := db.getSeriesSequence(metricID)
+		if sequence > 0 && float64(deleted)/float64(sequence) >= compactTombstoneRatio {
+			return true
+		}
+	}
+	return false
+}
+
+// getSeriesSequence returns the series id sequence of metric, returns 0 if not exist.
+func (db *indexDatabase) getSeriesSequence(metricID uint32) uint32 {
+	db.rwMutex.RLock()
+	metricIDMapping, ok := db.metricID2Mapping[metricID]
+	db.rwMutex.RUnlock()
+	if ok {
+		return metricIDMapping.SeriesSequence()
+	}
+	metricIDMapping, err := db.backend.loadMetricIDMapping(metricID)
+	if err != nil {
+		return 0
+	}
+	return metricIDMapping.SeriesSequence()
+}
+
+// compact compacts all index files for removing deleted series and obsolete tag values,
+// then marks the deleted series which have been removed from index files as purged.
+func (db *indexDatabase) compact() error {
+	startTime := time.Now()
+	defer compactIndexTimerVec.WithTagValues(db.metadata.DatabaseName()).UpdateSince(startTime)
+
+	// flush memory index first, make sure deleted series in memory can be removed by compaction
+	if err := db.index.Flush(); err != nil {
+		return err
+	}
+	deletedSeries := db.tombstone.snapshot()
+	params := map[string]interface{}{invertedindex.TombstoneContext: db.tombstone}
+	for _, family := range []kv.Family{db.forwardFamily, db.invertedFamily} {
+		if err := family.FullCompact(params); err != nil {
+			if errors.Is(err, kv.ErrCompacting) {
+				// retry next time
+				return nil
+			}
+			return err
+		}
+	}
+	if err := db.gcTombstone(deletedSeries); err != nil {
+		return err
+	}
+	compactIndexCounterVec.WithTagValues(db.metadata.DatabaseName()).Incr()
+	indexLogger.Info("compact index successfully",
+		logger.String("db", db.path), logger.String("cost", time.Since(startTime).String()))
+	return nil
+}
+
+// gcTombstone marks the deleted series which have been removed from index files as purged,
+// purged series needn't be filtered when query.
+func (db *indexDatabase) gcTombstone(deletedSeries map[uint32]*roaring.Bitmap) error {
+	db.tombstoneMutex.Lock()
+	defer db.tombstoneMutex.Unlock()
+
+	for metricID, seriesIDs := range deletedSeries {
+		mt := db.tombstone.get(metricID)
+		if mt == nil {
+			continue
+		}
+		// exclude series revived after taking snapshot
+		seriesIDs.And(mt.seriesIDs)
+		mt.seriesIDs.AndNot(seriesIDs)
+		mt.purgedSeriesIDs.Or(seriesIDs)
+		if err := db.saveTombstone(metricID, mt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
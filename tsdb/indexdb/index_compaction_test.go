@@ -0,0 +1,217 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package indexdb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lindb/roaring"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/fileutil"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/series/tag"
+	"github.com/lindb/lindb/tsdb/metadb"
+	"github.com/lindb/lindb/tsdb/tblstore/invertedindex"
+)
+
+func TestIndexDatabase_needCompact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		compactTombstoneThreshold = 10000
+		ctrl.Finish()
+	}()
+	meta := metadb.NewMockMetadata(ctrl)
+	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db, err := NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
+	assert.NoError(t, err)
+	db1 := db.(*indexDatabase)
+	for i := 0; i < 10; i++ {
+		_, _, _ = db.GetOrCreateSeriesID(1, uint64(i))
+	}
+	// case 1: no deleted series
+	assert.False(t, db1.needCompact())
+	// case 2: ratio < threshold
+	db1.tombstone.set(1, mockMetricTombstone(roaring.BitmapOf(1), roaring.BitmapOf(1)))
+	assert.False(t, db1.needCompact())
+	// case 3: ratio >= threshold
+	db1.tombstone.set(1, mockMetricTombstone(roaring.BitmapOf(1), roaring.BitmapOf(1, 2)))
+	assert.True(t, db1.needCompact())
+	// case 4: metric id mapping not exist
+	db1.tombstone.set(1, nil)
+	db1.tombstone.set(2, mockMetricTombstone(roaring.BitmapOf(2), roaring.BitmapOf(1)))
+	assert.False(t, db1.needCompact())
+	// case 5: number of deleted series >= threshold
+	compactTombstoneThreshold = 1
+	assert.True(t, db1.needCompact())
+	err = db.Close()
+	assert.NoError(t, err)
+
+	// case 6: load metric id mapping from backend
+	compactTombstoneThreshold = 10000
+	db, err = NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
+	assert.NoError(t, err)
+	db1 = db.(*indexDatabase)
+	db1.tombstone.set(1, mockMetricTombstone(roaring.BitmapOf(1), roaring.BitmapOf(1, 2, 3)))
+	assert.Equal(t, uint32(10), db1.getSeriesSequence(1))
+	assert.True(t, db1.needCompact())
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+func TestIndexDatabase_compact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+	forward := kv.NewMockFamily(ctrl)
+	inverted := kv.NewMockFamily(ctrl)
+	index := NewMockInvertedIndex(ctrl)
+	metaDB := metadb.NewMockMetadataDatabase(ctrl)
+	meta := metadb.NewMockMetadata(ctrl)
+	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	meta.EXPECT().MetadataDatabase().Return(metaDB).AnyTimes()
+	metaDB.EXPECT().GetMetricID("ns", "cpu").Return(uint32(1), nil).AnyTimes()
+	metaDB.EXPECT().GetAllTagKeys("ns", "cpu").Return([]tag.Meta{{ID: 10}}, nil).AnyTimes()
+	db, err := NewIndexDatabase(context.TODO(), testPath, meta, forward, inverted)
+	assert.NoError(t, err)
+	db1 := db.(*indexDatabase)
+	db1.index = index
+	for i := 1; i <= 3; i++ {
+		_, _, _ = db.GetOrCreateSeriesID(1, uint64(i))
+	}
+	assert.NoError(t, db.DeleteSeries("ns", "cpu", roaring.BitmapOf(1, 2)))
+
+	// case 1: flush index err
+	index.EXPECT().Flush().Return(fmt.Errorf("err"))
+	assert.Error(t, db1.compact())
+	// case 2: compact family err
+	index.EXPECT().Flush().Return(nil).AnyTimes()
+	forward.EXPECT().FullCompact(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, db1.compact())
+	// case 3: family is compacting, retry next time
+	forward.EXPECT().FullCompact(gomock.Any()).Return(kv.ErrCompacting)
+	assert.NoError(t, db1.compact())
+	assert.Len(t, db1.tombstone.snapshot(), 1)
+	// case 4: compact success, mark deleted series as purged
+	forward.EXPECT().FullCompact(gomock.Any()).DoAndReturn(func(params map[string]interface{}) error {
+		tombstone := params[invertedindex.TombstoneContext].(invertedindex.Tombstone)
+		assert.Equal(t, roaring.BitmapOf(1, 2), tombstone.DeletedSeriesIDs(10))
+		return nil
+	})
+	inverted.EXPECT().FullCompact(gomock.Any()).Return(nil)
+	assert.NoError(t, db1.compact())
+	assert.Empty(t, db1.tombstone.snapshot())
+	assert.Nil(t, db1.tombstone.DeletedSeriesIDs(10))
+	assert.False(t, db1.needCompact())
+	// purged series need rebuild inverted index if written again
+	seriesID, isCreated, err := db.GetOrCreateSeriesID(1, 1)
+	assert.NoError(t, err)
+	assert.True(t, isCreated)
+	assert.Equal(t, uint32(1), seriesID)
+	seriesID, isCreated, err = db.GetOrCreateSeriesID(1, 3)
+	assert.NoError(t, err)
+	assert.False(t, isCreated)
+	assert.Equal(t, uint32(3), seriesID)
+	assert.True(t, db1.tombstone.contains(1, 2))
+	assert.False(t, db1.tombstone.contains(1, 1))
+
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+func TestIndexDatabase_gcTombstone_err(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		createBackend = newIDMappingBackend
+		ctrl.Finish()
+	}()
+	backend := NewMockIDMappingBackend(ctrl)
+	createBackend = func(parent string) (IDMappingBackend, error) {
+		return backend, nil
+	}
+	meta := metadb.NewMockMetadata(ctrl)
+	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(nil)
+	db, err := NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
+	assert.NoError(t, err)
+	db1 := db.(*indexDatabase)
+	db1.tombstone.set(1, mockMetricTombstone(roaring.BitmapOf(1), roaring.BitmapOf(1, 2)))
+	// case 1: metric tombstone not exist
+	assert.NoError(t, db1.gcTombstone(map[uint32]*roaring.Bitmap{2: roaring.BitmapOf(1)}))
+	// case 2: save tombstone err
+	backend.EXPECT().saveTombstone(uint32(1), gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, db1.gcTombstone(map[uint32]*roaring.Bitmap{1: roaring.BitmapOf(1)}))
+	assert.Equal(t, roaring.BitmapOf(1, 2), db1.tombstone.DeletedSeriesIDs(1))
+
+	backend.EXPECT().Close().Return(nil)
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+func TestIndexDatabase_checkCompact(t *testing.T) {
+	compactCheckInterval = 100
+	ctrl := gomock.NewController(t)
+	defer func() {
+		compactCheckInterval = timeutil.OneMinute
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+	forward := kv.NewMockFamily(ctrl)
+	inverted := kv.NewMockFamily(ctrl)
+	index := NewMockInvertedIndex(ctrl)
+	meta := metadb.NewMockMetadata(ctrl)
+	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db, err := NewIndexDatabase(context.TODO(), testPath, meta, forward, inverted)
+	assert.NoError(t, err)
+	db1 := db.(*indexDatabase)
+	db1.index = index
+	// case 1: no deleted series
+	db1.checkCompact()
+	// case 2: compact fail
+	db1.tombstone.set(1, mockMetricTombstone(roaring.BitmapOf(1), roaring.BitmapOf(1)))
+	_, _, _ = db.GetOrCreateSeriesID(1, 1)
+	index.EXPECT().Flush().DoAndReturn(func() error {
+		db1.tombstone.set(1, nil)
+		return fmt.Errorf("err")
+	})
+	time.Sleep(500 * time.Millisecond)
+	// case 3: compacting
+	db1.compacting.Store(true)
+	db1.checkCompact()
+	db1.compacting.Store(false)
+
+	index.EXPECT().Flush().Return(nil).AnyTimes()
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+func mockMetricTombstone(tagKeyIDs, seriesIDs *roaring.Bitmap) *metricTombstone {
+	mt := newMetricTombstone()
+	mt.tagKeyIDs = tagKeyIDs
+	mt.seriesIDs = seriesIDs
+	return mt
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/series"
@@ -35,6 +36,7 @@ import (
 	"github.com/lindb/lindb/tsdb/wal"
 
 	"github.com/lindb/roaring"
+	"go.uber.org/atomic"
 )
 
 // for testing
@@ -47,6 +49,10 @@ var (
 	indexDBScope                 = linmetric.NewScope("lindb.tsdb.indexdb")
 	buildInvertedIndexCounterVec = indexDBScope.NewDeltaCounterVec("build_inverted_index_counter", "db")
 	recoverySeriesWALTimerVec    = indexDBScope.Scope("recovery_series_wal_duration").NewDeltaHistogramVec("db")
+	deleteSeriesCounterVec       = indexDBScope.NewDeltaCounterVec("delete_series", "db")
+	compactIndexCounterVec       = indexDBScope.NewDeltaCounterVec("compact_index", "db")
+	compactIndexFailCounterVec   = indexDBScope.NewDeltaCounterVec("compact_index_failures", "db")
+	compactIndexTimerVec         = indexDBScope.Scope("compact_index_duration").NewDeltaHistogramVec("db")
 )
 
 const (
@@ -59,6 +65,16 @@ var (
 	ErrNeedRecoveryWAL = errors.New("need recovery series wal")
 )
 
+// for index compaction
+var (
+	// compactCheckInterval is the interval of checking if index need compaction
+	compactCheckInterval = timeutil.OneMinute
+	// compactTombstoneThreshold is the number of deleted series which triggers index compaction
+	compactTombstoneThreshold uint64 = 10000
+	// compactTombstoneRatio is the ratio of deleted series under metric which triggers index compaction
+	compactTombstoneRatio = 0.2
+)
+
 // indexDatabase implements IndexDatabase interface
 type indexDatabase struct {
 	path             string
@@ -68,12 +84,21 @@ type indexDatabase struct {
 	metricID2Mapping map[uint32]MetricIDMapping // key: metric id, value: metric id mapping
 	metadata         metadb.Metadata            // the metadata for generating ID of metric, field
 	index            InvertedIndex
+	forwardFamily    kv.Family
+	invertedFamily   kv.Family
 
-	seriesWAL wal.SeriesWAL
+	seriesWAL     wal.SeriesWAL
+	tombstone     *seriesTombstone // deleted series
+	seriesBatcher *seriesBatcher   // collects new series when series burst
 
-	syncInterval int64
+	syncInterval         int64
+	compactCheckInterval int64
 
-	rwMutex sync.RWMutex // lock of create metric index
+	rwMutex        sync.RWMutex // lock of create metric index
+	tombstoneMutex sync.Mutex   // lock of modify series tombstone
+	compacting     atomic.Bool
+
+	maxSeriesPerMetric atomic.Uint32 // 0 means no limit
 }
 
 // NewIndexDatabase creates a new index database
@@ -100,15 +125,24 @@ func NewIndexDatabase(ctx context.Context, parent string, metadata metadb.Metada
 	}
 	c, cancel := context.WithCancel(ctx)
 	db := &indexDatabase{
-		path:             parent,
-		ctx:              c,
-		cancel:           cancel,
-		backend:          backend,
-		metadata:         metadata,
-		metricID2Mapping: make(map[uint32]MetricIDMapping),
-		index:            newInvertedIndex(metadata, forwardFamily, invertedFamily),
-		seriesWAL:        seriesWAL,
-		syncInterval:     syncInterval,
+		path:                 parent,
+		ctx:                  c,
+		cancel:               cancel,
+		backend:              backend,
+		metadata:             metadata,
+		metricID2Mapping:     make(map[uint32]MetricIDMapping),
+		index:                newInvertedIndex(metadata, forwardFamily, invertedFamily),
+		forwardFamily:        forwardFamily,
+		invertedFamily:       invertedFamily,
+		seriesWAL:            seriesWAL,
+		tombstone:            newSeriesTombstone(),
+		seriesBatcher:        newSeriesBatcher(metadata.DatabaseName()),
+		syncInterval:         syncInterval,
+		compactCheckInterval: compactCheckInterval,
+	}
+	// load deleted series
+	if err = db.loadTombstones(); err != nil {
+		return nil, err
 	}
 
 	// series recovery
@@ -132,9 +166,20 @@ func (db *indexDatabase) SuggestTagValues(tagKeyID uint32, tagValuePrefix string
 
 // GetGroupingContext returns the context of group by
 func (db *indexDatabase) GetGroupingContext(tagKeyIDs []uint32, seriesIDs *roaring.Bitmap) (series.GroupingContext, error) {
+	db.buildPendingInvertIndex()
 	return db.index.GetGroupingContext(tagKeyIDs, seriesIDs)
 }
 
+// SetMaxSeriesPerMetric sets the max number of series under metric, 0 means no limit.
+func (db *indexDatabase) SetMaxSeriesPerMetric(limit uint32) {
+	db.maxSeriesPerMetric.Store(limit)
+}
+
+// SetSyncPolicy sets the fsync policy of series write ahead log.
+func (db *indexDatabase) SetSyncPolicy(policy bufioutil.SyncPolicy) {
+	db.seriesWAL.SetSyncPolicy(policy)
+}
+
 // GetOrCreateSeriesID gets series by tags hash, if not exist generate new series id in memory,
 // if generate a new series id returns isCreate is true
 // if generate fail return err
@@ -148,7 +193,7 @@ func (db *indexDatabase) GetOrCreateSeriesID(metricID uint32, tagsHash uint64,
 		// get series id from memory cache
 		seriesID, ok = metricIDMapping.GetSeriesID(tagsHash)
 		if ok {
-			return seriesID, false, nil
+			return db.reviveSeries(metricID, seriesID)
 		}
 	} else {
 		// metric mapping not exist, need load from backend storage
@@ -170,7 +215,7 @@ func (db *indexDatabase) GetOrCreateSeriesID(metricID uint32, tagsHash uint64,
 			if err == nil {
 				// cache load series id
 				metricIDMapping.AddSeriesID(tagsHash, seriesID)
-				return seriesID, false, nil
+				return db.reviveSeries(metricID, seriesID)
 			}
 		}
 	}
@@ -178,6 +223,10 @@ func (db *indexDatabase) GetOrCreateSeriesID(metricID uint32, tagsHash uint64,
 	if err != nil && !errors.Is(err, constants.ErrNotFound) {
 		return 0, false, err
 	}
+	// reject new series if exceed the max limit of series under metric
+	if limit := db.maxSeriesPerMetric.Load(); limit > 0 && metricIDMapping.SeriesSequence() >= limit {
+		return 0, false, series.ErrTooManySeries
+	}
 	// generate new series id
 	seriesID = metricIDMapping.GenSeriesID(tagsHash)
 
@@ -190,14 +239,55 @@ func (db *indexDatabase) GetOrCreateSeriesID(metricID uint32, tagsHash uint64,
 	return seriesID, true, nil
 }
 
+// reviveSeries revives the series if it is deleted, the inverted index of revived series need be rebuilt.
+func (db *indexDatabase) reviveSeries(metricID, seriesID uint32) (uint32, bool, error) {
+	if !db.tombstone.contains(metricID, seriesID) {
+		return seriesID, false, nil
+	}
+	db.tombstoneMutex.Lock()
+	defer db.tombstoneMutex.Unlock()
+
+	mt := db.tombstone.get(metricID)
+	if mt == nil {
+		return seriesID, false, nil
+	}
+	mt.seriesIDs.Remove(seriesID)
+	mt.purgedSeriesIDs.Remove(seriesID)
+	if err := db.saveTombstone(metricID, mt); err != nil {
+		return 0, false, err
+	}
+	return seriesID, true, nil
+}
+
 // GetSeriesIDsByTagValueIDs gets series ids by tag value ids for spec metric's tag key
 func (db *indexDatabase) GetSeriesIDsByTagValueIDs(tagKeyID uint32, tagValueIDs *roaring.Bitmap) (*roaring.Bitmap, error) {
-	return db.index.GetSeriesIDsByTagValueIDs(tagKeyID, tagValueIDs)
+	db.buildPendingInvertIndex()
+	seriesIDs, err := db.index.GetSeriesIDsByTagValueIDs(tagKeyID, tagValueIDs)
+	if err != nil {
+		return nil, err
+	}
+	return db.removeDeletedSeries(tagKeyID, seriesIDs), nil
 }
 
 // GetSeriesIDsForTag gets series ids for spec metric's tag key
 func (db *indexDatabase) GetSeriesIDsForTag(tagKeyID uint32) (*roaring.Bitmap, error) {
-	return db.index.GetSeriesIDsForTag(tagKeyID)
+	db.buildPendingInvertIndex()
+	seriesIDs, err := db.index.GetSeriesIDsForTag(tagKeyID)
+	if err != nil {
+		return nil, err
+	}
+	return db.removeDeletedSeries(tagKeyID, seriesIDs), nil
+}
+
+// removeDeletedSeries removes the deleted series ids under tag key
+func (db *indexDatabase) removeDeletedSeries(tagKeyID uint32, seriesIDs *roaring.Bitmap) *roaring.Bitmap {
+	if seriesIDs == nil {
+		return nil
+	}
+	if deleted := db.tombstone.DeletedSeriesIDs(tagKeyID); deleted != nil {
+		seriesIDs.AndNot(deleted)
+	}
+	return seriesIDs
 }
 
 // GetSeriesIDsForMetric gets series ids for spec metric name
@@ -217,19 +307,114 @@ func (db *indexDatabase) GetSeriesIDsForMetric(namespace, metricName string) (*r
 		tagKeyIDs[idx] = tag.ID
 	}
 	// get series ids under all tag key ids
-	return db.index.GetSeriesIDsForTags(tagKeyIDs)
+	db.buildPendingInvertIndex()
+	seriesIDs, err := db.index.GetSeriesIDsForTags(tagKeyIDs)
+	if err != nil {
+		return nil, err
+	}
+	// all tag keys belong to same metric, so use first tag key for removing deleted series
+	return db.removeDeletedSeries(tagKeyIDs[0], seriesIDs), nil
+}
+
+// DeleteSeries marks the series of metric as deleted, deleted series are filtered when query,
+// and removed from index files by index compaction.
+func (db *indexDatabase) DeleteSeries(namespace, metricName string, seriesIDs *roaring.Bitmap) error {
+	if seriesIDs == nil || seriesIDs.IsEmpty() {
+		return nil
+	}
+	metricID, err := db.metadata.MetadataDatabase().GetMetricID(namespace, metricName)
+	if err != nil {
+		return err
+	}
+	tags, err := db.metadata.MetadataDatabase().GetAllTagKeys(namespace, metricName)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		// metric without tags only has default series id(0), no inverted index
+		return nil
+	}
+
+	db.tombstoneMutex.Lock()
+	defer db.tombstoneMutex.Unlock()
+
+	mt := db.tombstone.get(metricID)
+	if mt == nil {
+		mt = newMetricTombstone()
+	}
+	for _, tagKey := range tags {
+		mt.tagKeyIDs.Add(tagKey.ID)
+	}
+	mt.seriesIDs.Or(seriesIDs)
+	if err := db.saveTombstone(metricID, mt); err != nil {
+		return err
+	}
+	deleteSeriesCounterVec.WithTagValues(db.metadata.DatabaseName()).Add(float64(seriesIDs.GetCardinality()))
+	return nil
+}
+
+// saveTombstone persists the metric tombstone, then updates it in memory,
+// NOTICE: must hold tombstone lock.
+func (db *indexDatabase) saveTombstone(metricID uint32, mt *metricTombstone) error {
+	var data []byte
+	if !mt.isEmpty() {
+		var err error
+		data, err = mt.marshal()
+		if err != nil {
+			return err
+		}
+	}
+	if err := db.backend.saveTombstone(metricID, data); err != nil {
+		return err
+	}
+	db.tombstone.set(metricID, mt)
+	return nil
+}
+
+// loadTombstones loads all deleted series from backend storage
+func (db *indexDatabase) loadTombstones() error {
+	return db.backend.loadTombstones(func(metricID uint32, data []byte) error {
+		mt, err := unmarshalMetricTombstone(data)
+		if err != nil {
+			return err
+		}
+		db.tombstone.set(metricID, mt)
+		return nil
+	})
 }
 
 // BuildInvertIndex builds the inverted index for tag value => series ids,
 // the tags is considered as a empty key-value pair while tags is nil.
+// when new series burst, the inverted index is built in batch.
 func (db *indexDatabase) BuildInvertIndex(namespace, metricName string, tags tag.KeyValues, seriesID uint32) {
-	db.index.buildInvertIndex(namespace, metricName, tags, seriesID)
+	batch, batched := db.seriesBatcher.add(namespace, metricName, tags, seriesID)
+	if !batched {
+		db.index.buildInvertIndex(namespace, metricName, tags, seriesID)
+	} else if len(batch) > 0 {
+		db.buildInvertIndexBatch(batch)
+	}
 
 	buildInvertedIndexCounterVec.WithTagValues(db.metadata.DatabaseName()).Incr()
 }
 
+// buildPendingInvertIndex builds the inverted index for all pending new series.
+func (db *indexDatabase) buildPendingInvertIndex() {
+	if batch := db.seriesBatcher.drain(); len(batch) > 0 {
+		db.buildInvertIndexBatch(batch)
+	}
+}
+
+// buildInvertIndexBatch builds the inverted index for a batch of new series.
+func (db *indexDatabase) buildInvertIndexBatch(batch []pendingSeries) {
+	startTime := time.Now()
+	db.index.buildInvertIndexBatch(batch)
+	db.seriesBatcher.batchTimer.UpdateSince(startTime)
+}
+
 // Flush flushes index data to disk
 func (db *indexDatabase) Flush() error {
+	// build index for pending new series before flushing
+	db.buildPendingInvertIndex()
 	if err := db.seriesWAL.Sync(); err != nil {
 		indexLogger.Error("sync series wal err when invoke flush",
 			logger.String("db", db.path), logger.Error(err))
@@ -251,20 +436,30 @@ func (db *indexDatabase) Close() error {
 	if err := db.backend.Close(); err != nil {
 		return err
 	}
+	db.buildPendingInvertIndex()
 	return db.index.Flush()
 }
 
-// checkSync checks if need sync pending series event in period
+// checkSync checks if need sync pending series event in period,
+// also checks if need compact index for removing deleted series.
 func (db *indexDatabase) checkSync() {
 	ticker := time.NewTicker(time.Duration(db.syncInterval * 1000000))
+	compactTicker := time.NewTicker(time.Duration(db.compactCheckInterval * 1000000))
+	batchTicker := time.NewTicker(seriesBatchInterval)
 	for {
 		select {
+		case <-batchTicker.C:
+			db.buildPendingInvertIndex()
 		case <-ticker.C:
 			if db.seriesWAL.NeedRecovery() {
 				db.seriesRecovery()
 			}
+		case <-compactTicker.C:
+			db.checkCompact()
 		case <-db.ctx.Done():
 			ticker.Stop()
+			compactTicker.Stop()
+			batchTicker.Stop()
 			indexLogger.Info("check series event update goroutine exit...", logger.String("db", db.path))
 			return
 		}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/tag"
 	"github.com/lindb/lindb/tsdb/metadb"
 	"github.com/lindb/lindb/tsdb/wal"
@@ -88,11 +89,26 @@ func TestNewIndexDatabase_err(t *testing.T) {
 		return mockSeriesWAl, nil
 	}
 	backend.EXPECT().Close().Return(fmt.Errorf("err"))
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(nil)
 	mockSeriesWAl.EXPECT().Recovery(gomock.Any(), gomock.Any())
 	mockSeriesWAl.EXPECT().NeedRecovery().Return(true)
 	db, err = NewIndexDatabase(context.TODO(), testPath, mockMetadata, nil, nil)
 	assert.Error(t, err)
 	assert.Nil(t, db)
+	// case 3: load tombstone err
+	backend.EXPECT().Close().Return(fmt.Errorf("err"))
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(fmt.Errorf("err"))
+	db, err = NewIndexDatabase(context.TODO(), testPath, mockMetadata, nil, nil)
+	assert.Error(t, err)
+	assert.Nil(t, db)
+	// case 4: unmarshal tombstone err
+	backend.EXPECT().Close().Return(fmt.Errorf("err"))
+	backend.EXPECT().loadTombstones(gomock.Any()).DoAndReturn(func(fn func(metricID uint32, data []byte) error) error {
+		return fn(1, []byte{1, 2})
+	})
+	db, err = NewIndexDatabase(context.TODO(), testPath, mockMetadata, nil, nil)
+	assert.Error(t, err)
+	assert.Nil(t, db)
 }
 
 func TestIndexDatabase_SuggestTagValues(t *testing.T) {
@@ -138,6 +154,46 @@ func TestIndexDatabase_BuildInvertIndex(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestIndexDatabase_BuildInvertIndex_Burst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		seriesBurstThreshold = 1000
+		seriesBatchSize = 256
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+	seriesBurstThreshold = 1
+	seriesBatchSize = 2
+	meta := metadb.NewMockMetadata(ctrl)
+	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db, err := NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
+	assert.NoError(t, err)
+	db1 := db.(*indexDatabase)
+	index := NewMockInvertedIndex(ctrl)
+	db1.index = index
+	tags := tag.KeyValuesFromMap(map[string]string{"ip": "1.1.1.1"})
+	// batch full, build index in batch
+	db.BuildInvertIndex("ns", "cpu", tags, 10)
+	index.EXPECT().buildInvertIndexBatch(gomock.Len(2))
+	db.BuildInvertIndex("ns", "cpu", tags, 11)
+	// pending series is built before query
+	index.EXPECT().buildInvertIndexBatch(gomock.Len(1))
+	db.BuildInvertIndex("ns", "cpu", tags, 12)
+	index.EXPECT().GetSeriesIDsForTag(uint32(1)).Return(roaring.BitmapOf(10, 11, 12), nil)
+	seriesIDs, err := db.GetSeriesIDsForTag(1)
+	assert.NoError(t, err)
+	assert.Equal(t, roaring.BitmapOf(10, 11, 12), seriesIDs)
+	// pending series is built before flush
+	index.EXPECT().buildInvertIndexBatch(gomock.Len(1))
+	db.BuildInvertIndex("ns", "cpu", tags, 13)
+	index.EXPECT().Flush().Return(nil)
+	assert.NoError(t, db.Flush())
+
+	index.EXPECT().Flush().Return(nil)
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
 func TestIndexDatabase_series_Recovery_err(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -165,6 +221,7 @@ func TestIndexDatabase_series_Recovery_err(t *testing.T) {
 	createBackend = func(parent string) (IDMappingBackend, error) {
 		return backend, nil
 	}
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(nil)
 	backend.EXPECT().saveMapping(gomock.Any()).Return(fmt.Errorf("err"))
 	db, err = NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
 	assert.Error(t, err)
@@ -187,6 +244,7 @@ func TestIndexDatabase_series_Recovery_err(t *testing.T) {
 	createBackend = func(parent string) (IDMappingBackend, error) {
 		return backend, nil
 	}
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(nil)
 	backend.EXPECT().saveMapping(gomock.Any()).Return(fmt.Errorf("err"))
 	db, err = NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
 	assert.Error(t, err)
@@ -253,6 +311,22 @@ func TestIndexDatabase_GetOrCreateSeriesID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, isCreated)
 	assert.Equal(t, uint32(4), seriesID)
+	// case 7: exceed max series limit of metric
+	db.SetMaxSeriesPerMetric(4)
+	seriesID, isCreated, err = db.GetOrCreateSeriesID(1, 60)
+	assert.Equal(t, series.ErrTooManySeries, err)
+	assert.False(t, isCreated)
+	assert.Equal(t, uint32(0), seriesID)
+	// existing series is still available
+	seriesID, _, err = db.GetOrCreateSeriesID(1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4), seriesID)
+	// remove limit
+	db.SetMaxSeriesPerMetric(0)
+	seriesID, isCreated, err = db.GetOrCreateSeriesID(1, 60)
+	assert.NoError(t, err)
+	assert.True(t, isCreated)
+	assert.Equal(t, uint32(5), seriesID)
 
 	// close db
 	err = db.Close()
@@ -277,6 +351,7 @@ func TestIndexDatabase_GetOrCreateSeriesID_err(t *testing.T) {
 	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
 	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
 	metadataDB.EXPECT().GenTagKeyID(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint32(1), nil).AnyTimes()
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(nil)
 	db, err := NewIndexDatabase(context.TODO(), testPath, metadata, nil, nil)
 	assert.NoError(t, err)
 	// case 1: load metric mapping err
@@ -391,6 +466,7 @@ func TestIndexDatabase_Close(t *testing.T) {
 
 	meta := metadb.NewMockMetadata(ctrl)
 	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(nil)
 	db, err := NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
 	db1 := db.(*indexDatabase)
 	db1.seriesWAL = mockSeriesWAL
@@ -462,3 +538,124 @@ func TestIndexDatabase_checkSync(t *testing.T) {
 	err = db.Close()
 	assert.NoError(t, err)
 }
+
+func TestIndexDatabase_DeleteSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+
+	index := NewMockInvertedIndex(ctrl)
+	metaDB := metadb.NewMockMetadataDatabase(ctrl)
+	meta := metadb.NewMockMetadata(ctrl)
+	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	meta.EXPECT().MetadataDatabase().Return(metaDB).AnyTimes()
+	db, err := NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
+	assert.NoError(t, err)
+	db2 := db.(*indexDatabase)
+	db2.index = index
+
+	seriesID1, _, _ := db.GetOrCreateSeriesID(1, 10)
+	seriesID2, _, _ := db.GetOrCreateSeriesID(1, 20)
+	// case 1: empty series ids
+	assert.NoError(t, db.DeleteSeries("ns", "cpu", nil))
+	// case 2: get metric id err
+	metaDB.EXPECT().GetMetricID("ns", "cpu").Return(uint32(0), fmt.Errorf("err"))
+	assert.Error(t, db.DeleteSeries("ns", "cpu", roaring.BitmapOf(seriesID1)))
+	// case 3: get tag keys err
+	metaDB.EXPECT().GetMetricID("ns", "cpu").Return(uint32(1), nil).AnyTimes()
+	metaDB.EXPECT().GetAllTagKeys("ns", "cpu").Return(nil, fmt.Errorf("err"))
+	assert.Error(t, db.DeleteSeries("ns", "cpu", roaring.BitmapOf(seriesID1)))
+	// case 4: metric without tags
+	metaDB.EXPECT().GetAllTagKeys("ns", "cpu").Return(nil, nil)
+	assert.NoError(t, db.DeleteSeries("ns", "cpu", roaring.BitmapOf(seriesID1)))
+	assert.Nil(t, db2.tombstone.get(1))
+	// case 5: delete series
+	metaDB.EXPECT().GetAllTagKeys("ns", "cpu").Return([]tag.Meta{{ID: 10}, {ID: 11}}, nil).AnyTimes()
+	assert.NoError(t, db.DeleteSeries("ns", "cpu", roaring.BitmapOf(seriesID1, seriesID2)))
+	// case 6: deleted series are filtered
+	index.EXPECT().GetSeriesIDsForTag(uint32(10)).Return(roaring.BitmapOf(seriesID1, seriesID2, 3), nil)
+	seriesIDs, err := db.GetSeriesIDsForTag(10)
+	assert.NoError(t, err)
+	assert.Equal(t, roaring.BitmapOf(3), seriesIDs)
+	index.EXPECT().GetSeriesIDsByTagValueIDs(uint32(11), gomock.Any()).Return(roaring.BitmapOf(seriesID2, 3), nil)
+	seriesIDs, err = db.GetSeriesIDsByTagValueIDs(11, roaring.BitmapOf(1))
+	assert.NoError(t, err)
+	assert.Equal(t, roaring.BitmapOf(3), seriesIDs)
+	index.EXPECT().GetSeriesIDsForTags([]uint32{10, 11}).Return(roaring.BitmapOf(seriesID1, 3), nil)
+	seriesIDs, err = db.GetSeriesIDsForMetric("ns", "cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, roaring.BitmapOf(3), seriesIDs)
+	// case 7: write deleted series again, need rebuild inverted index
+	seriesID, isCreated, err := db.GetOrCreateSeriesID(1, 10)
+	assert.NoError(t, err)
+	assert.True(t, isCreated)
+	assert.Equal(t, seriesID1, seriesID)
+	seriesID, isCreated, err = db.GetOrCreateSeriesID(1, 10)
+	assert.NoError(t, err)
+	assert.False(t, isCreated)
+	assert.Equal(t, seriesID1, seriesID)
+	assert.Equal(t, roaring.BitmapOf(seriesID2), db2.tombstone.DeletedSeriesIDs(10))
+
+	index.EXPECT().Flush().Return(nil)
+	err = db.Close()
+	assert.NoError(t, err)
+
+	// case 8: reopen, load tombstone from backend
+	db, err = NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
+	assert.NoError(t, err)
+	db2 = db.(*indexDatabase)
+	assert.Equal(t, roaring.BitmapOf(seriesID2), db2.tombstone.DeletedSeriesIDs(11))
+	// case 9: get series id from backend, revive it
+	seriesID, isCreated, err = db.GetOrCreateSeriesID(1, 20)
+	assert.NoError(t, err)
+	assert.True(t, isCreated)
+	assert.Equal(t, seriesID2, seriesID)
+	assert.Nil(t, db2.tombstone.get(1))
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+func TestIndexDatabase_DeleteSeries_err(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		createBackend = newIDMappingBackend
+		ctrl.Finish()
+	}()
+
+	backend := NewMockIDMappingBackend(ctrl)
+	createBackend = func(parent string) (IDMappingBackend, error) {
+		return backend, nil
+	}
+	metaDB := metadb.NewMockMetadataDatabase(ctrl)
+	meta := metadb.NewMockMetadata(ctrl)
+	meta.EXPECT().DatabaseName().Return("test").AnyTimes()
+	meta.EXPECT().MetadataDatabase().Return(metaDB).AnyTimes()
+	backend.EXPECT().loadTombstones(gomock.Any()).Return(nil)
+	db, err := NewIndexDatabase(context.TODO(), testPath, meta, nil, nil)
+	assert.NoError(t, err)
+	db2 := db.(*indexDatabase)
+	metaDB.EXPECT().GetMetricID("ns", "cpu").Return(uint32(1), nil).AnyTimes()
+	metaDB.EXPECT().GetAllTagKeys("ns", "cpu").Return([]tag.Meta{{ID: 10}}, nil).AnyTimes()
+	// case 1: save tombstone err
+	backend.EXPECT().saveTombstone(uint32(1), gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, db.DeleteSeries("ns", "cpu", roaring.BitmapOf(1)))
+	assert.Nil(t, db2.tombstone.get(1))
+	// case 2: revive series err
+	backend.EXPECT().saveTombstone(uint32(1), gomock.Any()).Return(nil)
+	assert.NoError(t, db.DeleteSeries("ns", "cpu", roaring.BitmapOf(1)))
+	backend.EXPECT().loadMetricIDMapping(uint32(1)).Return(newMetricIDMapping(1, 1), nil)
+	backend.EXPECT().getSeriesID(uint32(1), uint64(10)).Return(uint32(1), nil)
+	backend.EXPECT().saveTombstone(uint32(1), gomock.Any()).Return(fmt.Errorf("err"))
+	seriesID, isCreated, err := db.GetOrCreateSeriesID(1, 10)
+	assert.Error(t, err)
+	assert.False(t, isCreated)
+	assert.Equal(t, uint32(0), seriesID)
+	assert.True(t, db2.tombstone.contains(1, 1))
+
+	backend.EXPECT().Close().Return(nil)
+	err = db.Close()
+	assert.NoError(t, err)
+}
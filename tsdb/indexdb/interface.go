@@ -20,6 +20,9 @@ package indexdb
 import (
 	"io"
 
+	"github.com/lindb/roaring"
+
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/tag"
@@ -51,6 +54,14 @@ type IndexDatabase interface {
 	// BuildInvertIndex builds the inverted index for tag value => series ids,
 	// the tags is considered as a empty key-value pair while tags is nil.
 	BuildInvertIndex(namespace, metricName string, tags tag.KeyValues, seriesID uint32)
+	// DeleteSeries marks the series of metric as deleted, deleted series are filtered when query,
+	// and removed from index files by index compaction.
+	DeleteSeries(namespace, metricName string, seriesIDs *roaring.Bitmap) error
+	// SetMaxSeriesPerMetric sets the max number of series under metric,
+	// creating new series returns series.ErrTooManySeries when exceeded, 0 means no limit.
+	SetMaxSeriesPerMetric(limit uint32)
+	// SetSyncPolicy sets the fsync policy of series write ahead log
+	SetSyncPolicy(policy bufioutil.SyncPolicy)
 	// Flush flushes index data to disk
 	Flush() error
 }
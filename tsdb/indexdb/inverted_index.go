@@ -61,6 +61,9 @@ type InvertedIndex interface {
 	// buildInvertIndex builds the inverted index for tag value => series ids,
 	// the tags is considered as a empty key-value pair while tags is nil.
 	buildInvertIndex(namespace, metricName string, tags tag.KeyValues, seriesID uint32)
+	// buildInvertIndexBatch builds the inverted index for a batch of new series,
+	// tag values are grouped by tag key for allocating tag value ids in batch.
+	buildInvertIndexBatch(batch []pendingSeries)
 	// Flush flushes the inverted-index of tag value id=>series ids under tag key
 	Flush() error
 }
@@ -260,6 +263,63 @@ func (index *invertedIndex) buildInvertIndex(namespace, metricName string, tags
 	}
 }
 
+// buildInvertIndexBatch builds the inverted index for a batch of new series,
+// tag values are grouped by tag key for allocating tag value ids in batch.
+func (index *invertedIndex) buildInvertIndexBatch(batch []pendingSeries) {
+	type tagKeyValues struct {
+		tagValues []string
+		seriesIDs []uint32
+	}
+	metadataDB := index.metadata.MetadataDatabase()
+	tagMetadata := index.metadata.TagMetadata()
+	// 1. group tag values by tag key id
+	groups := make(map[uint32]*tagKeyValues)
+	for idx := range batch {
+		newSeries := &batch[idx]
+		for _, kv := range newSeries.tags {
+			tagKeyID, err := metadataDB.GenTagKeyID(newSeries.namespace, newSeries.metricName, kv.Key)
+			if err != nil {
+				index.genTagKeyFailCounter.Incr()
+
+				indexLogger.Error("gen tag key id fail, ignore index build for this tag key",
+					logger.String("namespace", newSeries.namespace), logger.String("metric", newSeries.metricName),
+					logger.String("key", kv.Key), logger.Error(err))
+				continue
+			}
+			group, ok := groups[tagKeyID]
+			if !ok {
+				group = &tagKeyValues{}
+				groups[tagKeyID] = group
+			}
+			group.tagValues = append(group.tagValues, kv.Value)
+			group.seriesIDs = append(group.seriesIDs, newSeries.seriesID)
+		}
+	}
+
+	index.rwMutex.Lock()
+	defer index.rwMutex.Unlock()
+
+	// 2. allocate tag value ids in batch, then build inverted index under tag key
+	for tagKeyID, group := range groups {
+		tagValueIDs, err := tagMetadata.GenTagValueIDs(tagKeyID, group.tagValues)
+		if err != nil {
+			index.genTagValueFailCounter.Add(float64(len(group.tagValues)))
+
+			indexLogger.Error("gen tag value ids fail, ignore index build for this tag key",
+				logger.Uint32("tagKeyID", tagKeyID), logger.Error(err))
+			continue
+		}
+		tagIndex, ok := index.mutable.Get(tagKeyID)
+		if !ok {
+			tagIndex = newTagIndex()
+			index.mutable.Put(tagKeyID, tagIndex)
+		}
+		for idx, tagValueID := range tagValueIDs {
+			tagIndex.buildInvertedIndex(tagValueID, group.seriesIDs[idx])
+		}
+	}
+}
+
 // Flush flushes the inverted-index of tag value id=>series ids under tag key
 func (index *invertedIndex) Flush() error {
 	if !index.checkFlush() {
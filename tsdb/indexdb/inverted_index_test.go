@@ -291,6 +291,43 @@ func TestInvertedIndex_FlushInvertedIndexTo(t *testing.T) {
 	assert.Nil(t, idx.immutable)
 }
 
+func TestInvertedIndex_buildInvertIndexBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	tagMetadata := metadb.NewMockTagMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	metadata.EXPECT().TagMetadata().Return(tagMetadata).AnyTimes()
+	metadataDB.EXPECT().GenTagKeyID(gomock.Any(), gomock.Any(), "host").Return(uint32(1), nil).AnyTimes()
+	metadataDB.EXPECT().GenTagKeyID(gomock.Any(), gomock.Any(), "zone").Return(uint32(2), nil).AnyTimes()
+	metadataDB.EXPECT().GenTagKeyID(gomock.Any(), gomock.Any(), "zone_err").Return(uint32(0), fmt.Errorf("err")).AnyTimes()
+	// tag values are grouped by tag key
+	tagMetadata.EXPECT().GenTagValueIDs(uint32(1), []string{"1.1.1.1", "1.1.1.2"}).Return([]uint32{1, 2}, nil)
+	tagMetadata.EXPECT().GenTagValueIDs(uint32(2), []string{"sh", "sh"}).Return(nil, fmt.Errorf("err"))
+	index := newInvertedIndex(metadata, nil, nil)
+	index.buildInvertIndexBatch([]pendingSeries{
+		{namespace: "ns", metricName: "name", seriesID: 1, tags: tag.KeyValuesFromMap(map[string]string{
+			"host": "1.1.1.1",
+			"zone": "sh",
+		})},
+		{namespace: "ns", metricName: "name", seriesID: 2, tags: tag.KeyValuesFromMap(map[string]string{
+			"host":     "1.1.1.2",
+			"zone":     "sh",
+			"zone_err": "bj",
+		})},
+	})
+	idx := index.(*invertedIndex)
+	tagIndex, ok := idx.mutable.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, roaring.BitmapOf(1), tagIndex.getSeriesIDsByTagValueIDs(roaring.BitmapOf(1)))
+	assert.Equal(t, roaring.BitmapOf(2), tagIndex.getSeriesIDsByTagValueIDs(roaring.BitmapOf(2)))
+	_, ok = idx.mutable.Get(2)
+	assert.False(t, ok)
+}
+
 func prepareInvertedIndex(ctrl *gomock.Controller) InvertedIndex {
 	metadata := metadb.NewMockMetadata(ctrl)
 	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
@@ -40,6 +40,8 @@ type MetricIDMapping interface {
 	SetMaxSeriesIDsLimit(limit uint32)
 	// GetMaxSeriesIDsLimit returns the max series ids limit
 	GetMaxSeriesIDsLimit() uint32
+	// SeriesSequence returns the current series id sequence(number of generated series ids)
+	SeriesSequence() uint32
 }
 
 // metricIDMapping implements MetricIDMapping interface
@@ -108,6 +110,11 @@ func (mim *metricIDMapping) SetMaxSeriesIDsLimit(limit uint32) {
 	mim.maxSeriesIDsLimit.Store(limit)
 }
 
+// SeriesSequence returns the current series id sequence(number of generated series ids)
+func (mim *metricIDMapping) SeriesSequence() uint32 {
+	return mim.idSequence.Load()
+}
+
 // GetMaxSeriesIDsLimit return the max series ids limit without race condition.
 func (mim *metricIDMapping) GetMaxSeriesIDsLimit() uint32 {
 	return mim.maxSeriesIDsLimit.Load()
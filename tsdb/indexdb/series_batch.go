@@ -0,0 +1,160 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package indexdb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/tag"
+)
+
+// for testing
+var (
+	// seriesBurstWindow is the window(ms) of counting new series for detecting burst
+	seriesBurstWindow = timeutil.OneSecond
+	// seriesBurstThreshold is the number of new series in one window which triggers batch index building
+	seriesBurstThreshold = 1000
+	// seriesBatchSize is the max number of pending series of one batch
+	seriesBatchSize = 256
+	// seriesBatchInterval is the max waiting time of pending series before building index
+	seriesBatchInterval = 100 * time.Millisecond
+)
+
+var (
+	newSeriesCounterVec      = indexDBScope.NewDeltaCounterVec("new_series", "db", "mode")
+	seriesBatchCounterVec    = indexDBScope.NewDeltaCounterVec("series_batches", "db")
+	seriesBatchTimerVec      = indexDBScope.Scope("series_batch_duration").NewDeltaHistogramVec("db")
+	seriesBurstGaugeVec      = indexDBScope.NewGaugeVec("series_burst", "db")
+	seriesBatchPendingGauges = indexDBScope.NewGaugeVec("series_batch_pending", "db")
+)
+
+// pendingSeries represents the new series which inverted index is not built yet.
+type pendingSeries struct {
+	namespace  string
+	metricName string
+	tags       tag.KeyValues
+	seriesID   uint32
+}
+
+// burstDetector detects the burst of new series by counting new series in fixed window.
+type burstDetector struct {
+	windowStart int64
+	count       int
+	bursting    bool
+}
+
+// observe records a new series, returns if new series is bursting.
+func (d *burstDetector) observe(now int64) bool {
+	elapsed := now - d.windowStart
+	if elapsed >= seriesBurstWindow {
+		// burst state of new window is decided by the previous window,
+		// if no series created in previous window, burst is over.
+		d.bursting = elapsed < 2*seriesBurstWindow && d.count >= seriesBurstThreshold
+		d.windowStart = now
+		d.count = 0
+	}
+	d.count++
+	if d.count >= seriesBurstThreshold {
+		d.bursting = true
+	}
+	return d.bursting
+}
+
+// seriesBatcher collects new series when series burst,
+// then tag value ids are allocated in batch and inverted index is built by group.
+type seriesBatcher struct {
+	detector burstDetector
+	pending  []pendingSeries
+
+	mutex sync.Mutex
+
+	newSeriesDirect  *linmetric.BoundDeltaCounter
+	newSeriesBatched *linmetric.BoundDeltaCounter
+	batches          *linmetric.BoundDeltaCounter
+	batchTimer       *linmetric.BoundDeltaHistogram
+	bursting         *linmetric.BoundGauge
+	pendingSize      *linmetric.BoundGauge
+}
+
+// newSeriesBatcher creates the new series batcher.
+func newSeriesBatcher(db string) *seriesBatcher {
+	return &seriesBatcher{
+		newSeriesDirect:  newSeriesCounterVec.WithTagValues(db, "direct"),
+		newSeriesBatched: newSeriesCounterVec.WithTagValues(db, "batched"),
+		batches:          seriesBatchCounterVec.WithTagValues(db),
+		batchTimer:       seriesBatchTimerVec.WithTagValues(db),
+		bursting:         seriesBurstGaugeVec.WithTagValues(db),
+		pendingSize:      seriesBatchPendingGauges.WithTagValues(db),
+	}
+}
+
+// add adds the new series, returns false if series is not bursting, the index need be built directly.
+// if series is bursting, returns the pending batch when batch is full.
+func (b *seriesBatcher) add(namespace, metricName string, tags tag.KeyValues, seriesID uint32,
+) (batch []pendingSeries, batched bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.detector.observe(timeutil.Now()) {
+		b.bursting.Update(0)
+		b.newSeriesDirect.Incr()
+		return nil, false
+	}
+	b.bursting.Update(1)
+	b.newSeriesBatched.Incr()
+	// copy tags, because write request maybe reused after written
+	copiedTags := make(tag.KeyValues, len(tags))
+	for idx := range tags {
+		copiedTags[idx] = &protoMetricsV1.KeyValue{Key: tags[idx].Key, Value: tags[idx].Value}
+	}
+	b.pending = append(b.pending, pendingSeries{
+		namespace:  namespace,
+		metricName: metricName,
+		tags:       copiedTags,
+		seriesID:   seriesID,
+	})
+	if len(b.pending) < seriesBatchSize {
+		b.pendingSize.Update(float64(len(b.pending)))
+		return nil, true
+	}
+	return b.drainWithoutLock(), true
+}
+
+// drain returns all pending series.
+func (b *seriesBatcher) drain() []pendingSeries {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.drainWithoutLock()
+}
+
+// drainWithoutLock returns all pending series, must be called with lock.
+func (b *seriesBatcher) drainWithoutLock() []pendingSeries {
+	if len(b.pending) == 0 {
+		return nil
+	}
+	batch := b.pending
+	b.pending = nil
+	b.batches.Incr()
+	b.pendingSize.Update(0)
+	return batch
+}
@@ -0,0 +1,87 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package indexdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/series/tag"
+)
+
+func TestBurstDetector_observe(t *testing.T) {
+	defer func() {
+		seriesBurstThreshold = 1000
+	}()
+	seriesBurstThreshold = 3
+	d := &burstDetector{}
+	now := int64(10 * seriesBurstWindow)
+	assert.False(t, d.observe(now))
+	assert.False(t, d.observe(now))
+	// reach threshold in window
+	assert.True(t, d.observe(now))
+	// next window keeps bursting
+	now += seriesBurstWindow
+	assert.True(t, d.observe(now))
+	// burst is over, previous window is under threshold
+	now += seriesBurstWindow
+	assert.False(t, d.observe(now))
+	// reach threshold, then idle for a long time
+	assert.False(t, d.observe(now))
+	assert.True(t, d.observe(now))
+	now += 3 * seriesBurstWindow
+	assert.False(t, d.observe(now))
+}
+
+func TestSeriesBatcher(t *testing.T) {
+	defer func() {
+		seriesBurstThreshold = 1000
+		seriesBatchSize = 256
+	}()
+	seriesBurstThreshold = 2
+	seriesBatchSize = 3
+	b := newSeriesBatcher("test")
+	tags := tag.KeyValuesFromMap(map[string]string{"host": "1.1.1.1"})
+
+	// not bursting
+	batch, batched := b.add("ns", "cpu", tags, 1)
+	assert.False(t, batched)
+	assert.Nil(t, batch)
+	// bursting
+	batch, batched = b.add("ns", "cpu", tags, 2)
+	assert.True(t, batched)
+	assert.Nil(t, batch)
+	// tags copied
+	tags[0].Value = "1.1.1.2"
+	assert.Equal(t, "1.1.1.1", b.pending[0].tags[0].Value)
+	batch, batched = b.add("ns", "cpu", tags, 3)
+	assert.True(t, batched)
+	assert.Nil(t, batch)
+	// batch full
+	batch, batched = b.add("ns", "cpu", tags, 4)
+	assert.True(t, batched)
+	assert.Len(t, batch, 3)
+	assert.Equal(t, uint32(4), batch[2].seriesID)
+	assert.Nil(t, b.drain())
+
+	_, _ = b.add("ns", "cpu", tags, 5)
+	batch = b.drain()
+	assert.Len(t, batch, 1)
+	assert.Nil(t, b.drain())
+}
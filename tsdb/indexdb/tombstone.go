@@ -0,0 +1,208 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package indexdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/lindb/roaring"
+
+	"github.com/lindb/lindb/pkg/encoding"
+)
+
+// metricTombstone represents the deleted series of metric
+type metricTombstone struct {
+	tagKeyIDs *roaring.Bitmap // tag keys of metric when deleting series
+	seriesIDs *roaring.Bitmap // deleted series ids which still exist in index files
+	// deleted series ids which have been removed from index files by compaction,
+	// keep them for rebuilding inverted index if same series written again,
+	// because tags hash => series id mapping is not removed.
+	purgedSeriesIDs *roaring.Bitmap
+}
+
+// newMetricTombstone creates an empty metric tombstone
+func newMetricTombstone() *metricTombstone {
+	return &metricTombstone{
+		tagKeyIDs:       roaring.New(),
+		seriesIDs:       roaring.New(),
+		purgedSeriesIDs: roaring.New(),
+	}
+}
+
+// isEmpty returns if metric tombstone hasn't any deleted series
+func (mt *metricTombstone) isEmpty() bool {
+	return mt.seriesIDs.IsEmpty() && mt.purgedSeriesIDs.IsEmpty()
+}
+
+// clone returns the copy of metric tombstone
+func (mt *metricTombstone) clone() *metricTombstone {
+	return &metricTombstone{
+		tagKeyIDs:       mt.tagKeyIDs.Clone(),
+		seriesIDs:       mt.seriesIDs.Clone(),
+		purgedSeriesIDs: mt.purgedSeriesIDs.Clone(),
+	}
+}
+
+// marshal marshals the metric tombstone,
+// format: length(4 bytes)+tag key ids+length(4 bytes)+series ids+purged series ids
+func (mt *metricTombstone) marshal() ([]byte, error) {
+	var data []byte
+	for idx, bitmap := range []*roaring.Bitmap{mt.tagKeyIDs, mt.seriesIDs, mt.purgedSeriesIDs} {
+		block, err := encoding.BitmapMarshal(bitmap)
+		if err != nil {
+			return nil, err
+		}
+		if idx < 2 {
+			var scratch [4]byte
+			binary.LittleEndian.PutUint32(scratch[:], uint32(len(block)))
+			data = append(data, scratch[:]...)
+		}
+		data = append(data, block...)
+	}
+	return data, nil
+}
+
+// unmarshalMetricTombstone unmarshals the metric tombstone from binary data
+func unmarshalMetricTombstone(data []byte) (*metricTombstone, error) {
+	mt := newMetricTombstone()
+	pos := 0
+	for idx, bitmap := range []*roaring.Bitmap{mt.tagKeyIDs, mt.seriesIDs, mt.purgedSeriesIDs} {
+		end := len(data)
+		if idx < 2 {
+			if len(data) < pos+4 {
+				return nil, fmt.Errorf("invalid metric tombstone data, length: %d", len(data))
+			}
+			end = pos + 4 + int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+			if len(data) < end {
+				return nil, fmt.Errorf("invalid metric tombstone data, length: %d", len(data))
+			}
+		}
+		if err := encoding.BitmapUnmarshal(bitmap, data[pos:end]); err != nil {
+			return nil, err
+		}
+		pos = end
+	}
+	return mt, nil
+}
+
+// seriesTombstone records the deleted series of all metrics under shard,
+// implements invertedindex.Tombstone interface.
+type seriesTombstone struct {
+	metrics map[uint32]*metricTombstone // metric id => metric tombstone
+	tagKeys map[uint32]uint32           // tag key id => metric id
+	mutex   sync.RWMutex
+}
+
+// newSeriesTombstone creates the series tombstone
+func newSeriesTombstone() *seriesTombstone {
+	return &seriesTombstone{
+		metrics: make(map[uint32]*metricTombstone),
+		tagKeys: make(map[uint32]uint32),
+	}
+}
+
+// DeletedSeriesIDs returns the deleted series ids under tag key which still exist in index files,
+// returns nil if not exist.
+func (st *seriesTombstone) DeletedSeriesIDs(tagKeyID uint32) *roaring.Bitmap {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	metricID, ok := st.tagKeys[tagKeyID]
+	if !ok {
+		return nil
+	}
+	seriesIDs := st.metrics[metricID].seriesIDs
+	if seriesIDs.IsEmpty() {
+		return nil
+	}
+	return seriesIDs.Clone()
+}
+
+// numOfDeletedSeries returns the number of deleted series which still exist in index files for each metric
+func (st *seriesTombstone) numOfDeletedSeries() map[uint32]uint64 {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	result := make(map[uint32]uint64, len(st.metrics))
+	for metricID, mt := range st.metrics {
+		if !mt.seriesIDs.IsEmpty() {
+			result[metricID] = mt.seriesIDs.GetCardinality()
+		}
+	}
+	return result
+}
+
+// contains checks if the series of metric is deleted(include purged)
+func (st *seriesTombstone) contains(metricID, seriesID uint32) bool {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	mt, ok := st.metrics[metricID]
+	return ok && (mt.seriesIDs.Contains(seriesID) || mt.purgedSeriesIDs.Contains(seriesID))
+}
+
+// get returns the copy of metric tombstone, returns nil if not exist.
+func (st *seriesTombstone) get(metricID uint32) *metricTombstone {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	mt, ok := st.metrics[metricID]
+	if !ok {
+		return nil
+	}
+	return mt.clone()
+}
+
+// set sets the metric tombstone, removes it if no deleted series.
+func (st *seriesTombstone) set(metricID uint32, mt *metricTombstone) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if old, ok := st.metrics[metricID]; ok {
+		it := old.tagKeyIDs.Iterator()
+		for it.HasNext() {
+			delete(st.tagKeys, it.Next())
+		}
+		delete(st.metrics, metricID)
+	}
+	if mt == nil || mt.isEmpty() {
+		return
+	}
+	st.metrics[metricID] = mt
+	it := mt.tagKeyIDs.Iterator()
+	for it.HasNext() {
+		st.tagKeys[it.Next()] = metricID
+	}
+}
+
+// snapshot returns the copy of deleted series ids which still exist in index files for each metric
+func (st *seriesTombstone) snapshot() map[uint32]*roaring.Bitmap {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	result := make(map[uint32]*roaring.Bitmap, len(st.metrics))
+	for metricID, mt := range st.metrics {
+		if !mt.seriesIDs.IsEmpty() {
+			result[metricID] = mt.seriesIDs.Clone()
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package indexdb
+
+import (
+	"testing"
+
+	"github.com/lindb/roaring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricTombstone_marshal(t *testing.T) {
+	mt := newMetricTombstone()
+	mt.tagKeyIDs.AddMany([]uint32{1, 2})
+	mt.seriesIDs.AddMany([]uint32{10, 20, 30})
+	mt.purgedSeriesIDs.AddMany([]uint32{5, 6})
+	data, err := mt.marshal()
+	assert.NoError(t, err)
+	mt2, err := unmarshalMetricTombstone(data)
+	assert.NoError(t, err)
+	assert.Equal(t, mt.tagKeyIDs.ToArray(), mt2.tagKeyIDs.ToArray())
+	assert.Equal(t, mt.seriesIDs.ToArray(), mt2.seriesIDs.ToArray())
+	assert.Equal(t, mt.purgedSeriesIDs.ToArray(), mt2.purgedSeriesIDs.ToArray())
+
+	// case: invalid data
+	_, err = unmarshalMetricTombstone([]byte{1, 2})
+	assert.Error(t, err)
+	_, err = unmarshalMetricTombstone([]byte{100, 0, 0, 0, 1})
+	assert.Error(t, err)
+	_, err = unmarshalMetricTombstone([]byte{2, 0, 0, 0, 1, 2})
+	assert.Error(t, err)
+	_, err = unmarshalMetricTombstone(data[:len(data)-2])
+	assert.Error(t, err)
+}
+
+func TestSeriesTombstone(t *testing.T) {
+	st := newSeriesTombstone()
+	assert.Nil(t, st.DeletedSeriesIDs(1))
+	assert.Nil(t, st.get(1))
+	assert.False(t, st.contains(1, 10))
+	assert.Empty(t, st.snapshot())
+
+	st.set(1, mockMetricTombstone(roaring.BitmapOf(1, 2), roaring.BitmapOf(10, 20)))
+	st.set(2, mockMetricTombstone(roaring.BitmapOf(3), roaring.BitmapOf(10)))
+	assert.Equal(t, roaring.BitmapOf(10, 20), st.DeletedSeriesIDs(2))
+	assert.Equal(t, roaring.BitmapOf(10), st.DeletedSeriesIDs(3))
+	assert.True(t, st.contains(1, 20))
+	assert.False(t, st.contains(2, 20))
+	assert.Equal(t, map[uint32]uint64{1: 2, 2: 1}, st.numOfDeletedSeries())
+	assert.Equal(t, map[uint32]*roaring.Bitmap{1: roaring.BitmapOf(10, 20), 2: roaring.BitmapOf(10)}, st.snapshot())
+
+	// modify copy, not effect tombstone
+	mt := st.get(1)
+	mt.seriesIDs.Remove(10)
+	assert.True(t, st.contains(1, 10))
+	// replace tag keys
+	mt.tagKeyIDs = roaring.BitmapOf(2, 4)
+	st.set(1, mt)
+	assert.Nil(t, st.DeletedSeriesIDs(1))
+	assert.Equal(t, roaring.BitmapOf(20), st.DeletedSeriesIDs(4))
+	// purged series
+	mt = st.get(1)
+	mt.purgedSeriesIDs.Or(mt.seriesIDs)
+	mt.seriesIDs.Clear()
+	st.set(1, mt)
+	assert.Nil(t, st.DeletedSeriesIDs(4))
+	assert.True(t, st.contains(1, 20))
+	assert.Equal(t, map[uint32]uint64{2: 1}, st.numOfDeletedSeries())
+	assert.Equal(t, map[uint32]*roaring.Bitmap{2: roaring.BitmapOf(10)}, st.snapshot())
+	// remove metric tombstone if no deleted series
+	st.set(1, mockMetricTombstone(roaring.BitmapOf(2, 4), roaring.New()))
+	assert.Nil(t, st.get(1))
+	st.set(2, nil)
+	assert.Nil(t, st.get(2))
+	assert.Nil(t, st.DeletedSeriesIDs(3))
+}
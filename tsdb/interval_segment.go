@@ -22,6 +22,8 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/lindb/lindb/pkg/bufioutil"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/timeutil"
 )
 
@@ -33,15 +35,22 @@ type IntervalSegment interface {
 	GetOrCreateSegment(segmentName string) (Segment, error)
 	// getDataFamilies returns data family list by time range, return nil if not match
 	getDataFamilies(timeRange timeutil.TimeRange) []DataFamily
+	// getAllDataFamilies returns all data families of all segments
+	getAllDataFamilies() []DataFamily
+	// SetSyncPolicy sets the fsync policy of data files for all segments, includes segments created afterwards
+	SetSyncPolicy(policy bufioutil.SyncPolicy)
 	// Close closes interval segment, release resource
 	Close()
 }
 
 // intervalSegment implements IntervalSegment interface
 type intervalSegment struct {
-	path     string
-	interval timeutil.Interval
-	segments sync.Map
+	path         string
+	interval     timeutil.Interval
+	familyWindow timeutil.Interval
+	blockCodec   encoding.TSDCodec
+	segments     sync.Map
+	syncPolicy   bufioutil.SyncPolicy
 
 	mutex sync.Mutex
 }
@@ -49,6 +58,8 @@ type intervalSegment struct {
 // newIntervalSegment create interval segment based on interval/type/path etc.
 func newIntervalSegment(
 	interval timeutil.Interval,
+	familyWindow timeutil.Interval,
+	blockCodec encoding.TSDCodec,
 	path string,
 ) (
 	segment IntervalSegment,
@@ -58,8 +69,11 @@ func newIntervalSegment(
 		return segment, err
 	}
 	intervalSegment := &intervalSegment{
-		path:     path,
-		interval: interval,
+		path:         path,
+		interval:     interval,
+		familyWindow: familyWindow,
+		blockCodec:   blockCodec,
+		syncPolicy:   bufioutil.DefaultSyncPolicy,
 	}
 
 	defer func() {
@@ -76,7 +90,8 @@ func newIntervalSegment(
 		return segment, err
 	}
 	for _, segmentName := range segmentNames {
-		seg, err := newSegment(segmentName, intervalSegment.interval, filepath.Join(path, segmentName))
+		seg, err := newSegment(segmentName, intervalSegment.interval, intervalSegment.familyWindow,
+			intervalSegment.blockCodec, filepath.Join(path, segmentName))
 		if err != nil {
 			err = fmt.Errorf("create segmenet error: %s", err)
 			return segment, err
@@ -98,10 +113,12 @@ func (s *intervalSegment) GetOrCreateSegment(segmentName string) (Segment, error
 		defer s.mutex.Unlock()
 		segment, ok = s.getSegment(segmentName)
 		if !ok {
-			seg, err := newSegment(segmentName, s.interval, filepath.Join(s.path, segmentName))
+			seg, err := newSegment(segmentName, s.interval, s.familyWindow, s.blockCodec,
+				filepath.Join(s.path, segmentName))
 			if err != nil {
 				return nil, fmt.Errorf("create segmenet error: %s", err)
 			}
+			seg.SetSyncPolicy(s.syncPolicy)
 			s.segments.Store(segmentName, seg)
 			return seg, nil
 		}
@@ -134,6 +151,34 @@ func (s *intervalSegment) getDataFamilies(timeRange timeutil.TimeRange) []DataFa
 	return result
 }
 
+// getAllDataFamilies returns all data families of all segments
+func (s *intervalSegment) getAllDataFamilies() []DataFamily {
+	var result []DataFamily
+	s.segments.Range(func(k, v interface{}) bool {
+		segment, ok := v.(Segment)
+		if ok {
+			result = append(result, segment.getAllDataFamilies()...)
+		}
+		return true
+	})
+	return result
+}
+
+// SetSyncPolicy sets the fsync policy of data files for all segments, includes segments created afterwards
+func (s *intervalSegment) SetSyncPolicy(policy bufioutil.SyncPolicy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.syncPolicy = policy
+	s.segments.Range(func(k, v interface{}) bool {
+		seg, ok := v.(Segment)
+		if ok {
+			seg.SetSyncPolicy(policy)
+		}
+		return true
+	})
+}
+
 // Close closes interval segment, release resource
 func (s *intervalSegment) Close() {
 	s.segments.Range(func(k, v interface{}) bool {
@@ -24,6 +24,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/pkg/bufioutil"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/timeutil"
 )
@@ -38,7 +40,7 @@ func TestIntervalSegment_New(t *testing.T) {
 	mkDirIfNotExist = func(path string) error {
 		return fmt.Errorf("err")
 	}
-	s, err := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, err := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	assert.Error(t, err)
 	assert.Nil(t, s)
 	mkDirIfNotExist = fileutil.MkDirIfNotExist
@@ -47,13 +49,13 @@ func TestIntervalSegment_New(t *testing.T) {
 	listDir = func(path string) (strings []string, err error) {
 		return nil, fmt.Errorf("err")
 	}
-	s, err = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, err = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	assert.Error(t, err)
 	assert.Nil(t, s)
 	listDir = fileutil.ListDir
 
 	// case 3: create segment success
-	s, err = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, err = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	assert.NoError(t, err)
 	assert.NotNil(t, s)
 	assert.True(t, fileutil.Exist(segPath))
@@ -63,11 +65,13 @@ func TestIntervalSegment_New(t *testing.T) {
 	s1, err := newSegment(
 		"20190903",
 		timeutil.Interval(timeutil.OneSecond*10),
+		0,
+		encoding.TSDCodecXOR,
 		filepath.Join(segPath, "20190903"))
 	assert.NoError(t, err)
 	assert.NotNil(t, s1)
 	// case 5: cannot re-open kv-store
-	s, err = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, err = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	assert.Nil(t, s)
 	assert.Error(t, err)
 }
@@ -76,7 +80,7 @@ func TestIntervalSegment_GetOrCreateSegment(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
 	}()
-	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	seg, err := s.GetOrCreateSegment("20190702")
 	assert.Nil(t, err)
 	assert.NotNil(t, seg)
@@ -95,7 +99,7 @@ func TestIntervalSegment_GetOrCreateSegment(t *testing.T) {
 
 	s.Close()
 
-	s, _ = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, _ = newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 
 	s1, ok := s.(*intervalSegment)
 	if ok {
@@ -112,7 +116,7 @@ func TestIntervalSegment_getDataFamilies(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
 	}()
-	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	segment1, _ := s.GetOrCreateSegment("20190902")
 	now, _ := timeutil.ParseTimestamp("20190902 19:10:48", "20060102 15:04:05")
 	_, _ = segment1.GetDataFamily(now)
@@ -155,4 +159,25 @@ func TestIntervalSegment_getDataFamilies(t *testing.T) {
 	end, _ = timeutil.ParseTimestamp("20190902 19:40:48", "20060102 15:04:05")
 	segments = s.getDataFamilies(timeutil.TimeRange{Start: start, End: end})
 	assert.Equal(t, 1, len(segments))
+	// get all data families
+	assert.Equal(t, 5, len(s.getAllDataFamilies()))
+}
+
+func TestIntervalSegment_SetSyncPolicy(t *testing.T) {
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	s, err := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
+	assert.NoError(t, err)
+	seg1, err := s.GetOrCreateSegment("20190702")
+	assert.NoError(t, err)
+	syncPolicy := bufioutil.SyncPolicy{Mode: bufioutil.SyncBytes, Bytes: 1024}
+	s.SetSyncPolicy(syncPolicy)
+	// exist segment
+	assert.Equal(t, syncPolicy, seg1.(*segment).kvStore.Option().SyncPolicy)
+	// new segment created afterwards
+	seg2, err := s.GetOrCreateSegment("20190703")
+	assert.NoError(t, err)
+	assert.Equal(t, syncPolicy, seg2.(*segment).kvStore.Option().SyncPolicy)
+	s.Close()
 }
@@ -0,0 +1,280 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/concurrent"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+//go:generate mockgen -source=./io_coordinator.go -destination=./io_coordinator_mock.go -package=tsdb
+
+var (
+	// can be modified in runtime
+	ioCoordinateInterval = *atomic.NewDuration(time.Second)
+)
+
+var (
+	ioCoordinatorScope = linmetric.NewScope("lindb.tsdb.io_coordinator")
+	ioModeGauge        = ioCoordinatorScope.NewGauge("mode")
+	ioModeDurationVec  = ioCoordinatorScope.NewDeltaCounterVec("mode_duration", "mode")
+	ioModeSwitchesVec  = ioCoordinatorScope.NewDeltaCounterVec("mode_switches", "mode")
+	deferredFlushes    = ioCoordinatorScope.NewDeltaCounter("deferred_flushes")
+	deferredCompacts   = ioCoordinatorScope.NewDeltaCounter("deferred_compactions")
+)
+
+// IOMode represents the io coordination mode between background flush/compaction and query on storage node.
+type IOMode int32
+
+const (
+	// IONormal represents background io and query io are not restricted.
+	IONormal IOMode = iota
+	// IOQueryPriority represents query latency breaches the threshold, background flush/compaction are deferred.
+	IOQueryPriority
+	// IOWritePriority represents memory database pressure is critical, query io is capped.
+	IOWritePriority
+)
+
+// String returns the string value of io mode.
+func (m IOMode) String() string {
+	switch m {
+	case IOQueryPriority:
+		return "query_priority"
+	case IOWritePriority:
+		return "write_priority"
+	default:
+		return "normal"
+	}
+}
+
+// IOCoordinator represents the coordination policy between background flush/compaction io and query io.
+// It checks the storage query latency and the total size of memory databases periodically:
+//  1. when query latency is above high watermark, switches to query priority mode which defers the
+//     background flush/compaction, switches back when latency is below low watermark;
+//  2. when total size of memory databases is above critical watermark, switches to write priority mode
+//     which caps the query io(shard parallelism), switches back when below memory low watermark.
+//
+// Write priority has higher priority than query priority, and background io cannot be deferred longer
+// than max deferral time.
+// Background flush/compaction jobs are executed in the query pool with low priority if pool is set,
+// so that the pending query tasks are executed first.
+type IOCoordinator interface {
+	// Start starts the coordinate goroutine in background
+	Start()
+	// Stop stops the background coordinate goroutine
+	Stop()
+	// Mode returns the current io mode
+	Mode() IOMode
+	// ObserveQueryLatency records the latency of storage query
+	ObserveQueryLatency(latency time.Duration)
+	// AllowBackgroundIO returns if the background flush/compaction can be executed now
+	AllowBackgroundIO() bool
+	// QueryParallelism returns the shard parallelism of query capped by current io mode
+	QueryParallelism(parallelism int) int
+	// RunBackgroundJob runs the background flush/compaction job with low priority, waits until it completed
+	RunBackgroundJob(job func())
+}
+
+// ioCoordinator implements IOCoordinator interface
+type ioCoordinator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	queryLatencyHigh  time.Duration // 0 means disable query priority
+	queryLatencyLow   time.Duration
+	maxDeferral       time.Duration // 0 means no limit
+	maxMemDBTotalSize int64
+	memDBCritical     float64 // 0 means disable write priority
+	memDBLow          float64
+
+	mode      atomic.Int32
+	modeSince atomic.Int64 // unix nano of current mode entered
+
+	latencySum   atomic.Int64
+	latencyCount atomic.Int64
+
+	backgroundPool concurrent.Pool // pool shared with query tasks, nil means run background job directly
+
+	memDBTotalSizeGetter func() int64 // used for mocking
+	nowFunc              func() time.Time
+	logger               *logger.Logger
+}
+
+// newIOCoordinator creates the io coordinator, background jobs are executed in backgroundPool if not nil
+func newIOCoordinator(ctx context.Context, cfg config.TSDB, backgroundPool concurrent.Pool) IOCoordinator {
+	c, cancel := context.WithCancel(ctx)
+	ioc := &ioCoordinator{
+		ctx:                  c,
+		cancel:               cancel,
+		backgroundPool:       backgroundPool,
+		queryLatencyHigh:     cfg.QueryLatencyHighWaterMark.Duration(),
+		queryLatencyLow:      cfg.QueryLatencyLowWaterMark.Duration(),
+		maxDeferral:          cfg.MaxBackgroundIODeferral.Duration(),
+		maxMemDBTotalSize:    int64(cfg.MaxMemDBTotalSize),
+		memDBCritical:        cfg.MemDBCriticalWaterMark,
+		memDBLow:             cfg.MemoryLowWaterMark,
+		memDBTotalSizeGetter: getMemDBTotalSize,
+		nowFunc:              time.Now,
+		logger:               engineLogger,
+	}
+	if ioc.queryLatencyLow <= 0 || ioc.queryLatencyLow > ioc.queryLatencyHigh {
+		ioc.queryLatencyLow = ioc.queryLatencyHigh
+	}
+	if ioc.memDBLow <= 0 || ioc.memDBLow > ioc.memDBCritical {
+		ioc.memDBLow = ioc.memDBCritical
+	}
+	ioc.modeSince.Store(ioc.nowFunc().UnixNano())
+	return ioc
+}
+
+// getMemDBTotalSize returns the total size of memory databases of all shards
+func getMemDBTotalSize() int64 {
+	var total int64
+	GetShardManager().WalkEntry(func(shard Shard) {
+		for _, entry := range shard.memDBEntries() {
+			total += int64(entry.memDB.MemSize())
+		}
+	})
+	return total
+}
+
+// Start starts the coordinate goroutine in background
+func (c *ioCoordinator) Start() {
+	go c.run()
+}
+
+// Stop stops the background coordinate goroutine
+func (c *ioCoordinator) Stop() {
+	c.cancel()
+}
+
+// Mode returns the current io mode
+func (c *ioCoordinator) Mode() IOMode {
+	return IOMode(c.mode.Load())
+}
+
+// ObserveQueryLatency records the latency of storage query
+func (c *ioCoordinator) ObserveQueryLatency(latency time.Duration) {
+	c.latencySum.Add(int64(latency))
+	c.latencyCount.Inc()
+}
+
+// AllowBackgroundIO returns if the background flush/compaction can be executed now,
+// background io is deferred in query priority mode until max deferral time.
+func (c *ioCoordinator) AllowBackgroundIO() bool {
+	if c.Mode() != IOQueryPriority {
+		return true
+	}
+	if c.maxDeferral > 0 && c.nowFunc().UnixNano()-c.modeSince.Load() >= int64(c.maxDeferral) {
+		return true
+	}
+	return false
+}
+
+// QueryParallelism returns the shard parallelism of query capped by current io mode,
+// searches shards one by one in write priority mode.
+func (c *ioCoordinator) QueryParallelism(parallelism int) int {
+	if c.Mode() == IOWritePriority {
+		return 1
+	}
+	return parallelism
+}
+
+// RunBackgroundJob runs the background flush/compaction job in pool with low priority, waits until it completed.
+// Runs the job directly if pool not set, or the job is rejected by pool(stopped/queue full),
+// because the background job cannot be dropped.
+func (c *ioCoordinator) RunBackgroundJob(job func()) {
+	if c.backgroundPool == nil {
+		job()
+		return
+	}
+	done := make(chan struct{})
+	if err := c.backgroundPool.SubmitWithPriority(concurrent.PriorityLow, func() {
+		defer close(done)
+		job()
+	}); err != nil {
+		c.logger.Warn("submit background job to pool failure, run it directly", logger.Error(err))
+		job()
+		return
+	}
+	<-done
+}
+
+// run checks the query latency and memory pressure periodically
+func (c *ioCoordinator) run() {
+	timer := time.NewTimer(ioCoordinateInterval.Load())
+	defer timer.Stop()
+
+	last := c.nowFunc()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-timer.C:
+			now := c.nowFunc()
+			ioModeDurationVec.WithTagValues(c.Mode().String()).Add(now.Sub(last).Seconds())
+			last = now
+			c.coordinate()
+			timer.Reset(ioCoordinateInterval.Load())
+		}
+	}
+}
+
+// coordinate decides the io mode based on the query latency in last period and memory pressure, with hysteresis.
+func (c *ioCoordinator) coordinate() {
+	var avgLatency time.Duration
+	count := c.latencyCount.Swap(0)
+	sum := c.latencySum.Swap(0)
+	if count > 0 {
+		avgLatency = time.Duration(sum / count)
+	}
+	var memDBUsage float64
+	if c.maxMemDBTotalSize > 0 && c.memDBCritical > 0 {
+		memDBUsage = float64(c.memDBTotalSizeGetter()) * 100 / float64(c.maxMemDBTotalSize)
+	}
+
+	current := c.Mode()
+	next := IONormal
+	switch {
+	case c.memDBCritical > 0 && memDBUsage >= c.memDBCritical:
+		next = IOWritePriority
+	case current == IOWritePriority && memDBUsage > c.memDBLow:
+		next = IOWritePriority
+	case c.queryLatencyHigh > 0 && avgLatency >= c.queryLatencyHigh:
+		next = IOQueryPriority
+	case current == IOQueryPriority && avgLatency > c.queryLatencyLow:
+		next = IOQueryPriority
+	}
+	ioModeGauge.Update(float64(next))
+	if next == current {
+		return
+	}
+	c.mode.Store(int32(next))
+	c.modeSince.Store(c.nowFunc().UnixNano())
+	ioModeSwitchesVec.WithTagValues(next.String()).Incr()
+	c.logger.Info("switch io coordination mode",
+		logger.String("from", current.String()), logger.String("to", next.String()),
+		logger.String("queryLatency", avgLatency.String()), logger.Any("memDBUsage", memDBUsage))
+}
@@ -0,0 +1,140 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/config"
+	"github.com/lindb/lindb/internal/concurrent"
+	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/ltoml"
+)
+
+func TestIOMode_String(t *testing.T) {
+	assert.Equal(t, "normal", IONormal.String())
+	assert.Equal(t, "query_priority", IOQueryPriority.String())
+	assert.Equal(t, "write_priority", IOWritePriority.String())
+}
+
+func TestIOCoordinator_New(t *testing.T) {
+	c := newIOCoordinator(context.TODO(), config.TSDB{
+		QueryLatencyHighWaterMark: ltoml.Duration(time.Second),
+		MemDBCriticalWaterMark:    90,
+		MemoryLowWaterMark:        95,
+	}, nil).(*ioCoordinator)
+	// low watermark cannot above high watermark
+	assert.Equal(t, time.Second, c.queryLatencyLow)
+	assert.Equal(t, 90.0, c.memDBLow)
+	assert.Equal(t, IONormal, c.Mode())
+	assert.True(t, c.AllowBackgroundIO())
+	assert.Equal(t, 8, c.QueryParallelism(8))
+}
+
+func TestIOCoordinator_QueryPriority(t *testing.T) {
+	now := time.Now()
+	c := newIOCoordinator(context.TODO(), config.TSDB{
+		QueryLatencyHighWaterMark: ltoml.Duration(time.Second),
+		QueryLatencyLowWaterMark:  ltoml.Duration(100 * time.Millisecond),
+		MaxBackgroundIODeferral:   ltoml.Duration(time.Minute),
+	}, nil).(*ioCoordinator)
+	c.nowFunc = func() time.Time { return now }
+
+	// latency above high watermark
+	c.ObserveQueryLatency(500 * time.Millisecond)
+	c.ObserveQueryLatency(2 * time.Second)
+	c.coordinate()
+	assert.Equal(t, IOQueryPriority, c.Mode())
+	assert.False(t, c.AllowBackgroundIO())
+	assert.Equal(t, 8, c.QueryParallelism(8))
+	// hysteresis, latency between low and high watermark
+	c.ObserveQueryLatency(500 * time.Millisecond)
+	c.coordinate()
+	assert.Equal(t, IOQueryPriority, c.Mode())
+	// deferred too long
+	now = now.Add(2 * time.Minute)
+	assert.True(t, c.AllowBackgroundIO())
+	// latency below low watermark
+	c.ObserveQueryLatency(50 * time.Millisecond)
+	c.coordinate()
+	assert.Equal(t, IONormal, c.Mode())
+	assert.True(t, c.AllowBackgroundIO())
+}
+
+func TestIOCoordinator_WritePriority(t *testing.T) {
+	c := newIOCoordinator(context.TODO(), config.TSDB{
+		QueryLatencyHighWaterMark: ltoml.Duration(time.Second),
+		MaxMemDBTotalSize:         1000,
+		MemDBCriticalWaterMark:    90,
+		MemoryLowWaterMark:        60,
+	}, nil).(*ioCoordinator)
+	memDBTotalSize := int64(950)
+	c.memDBTotalSizeGetter = func() int64 { return memDBTotalSize }
+
+	// write priority has higher priority than query priority
+	c.ObserveQueryLatency(2 * time.Second)
+	c.coordinate()
+	assert.Equal(t, IOWritePriority, c.Mode())
+	assert.True(t, c.AllowBackgroundIO())
+	assert.Equal(t, 1, c.QueryParallelism(8))
+	// hysteresis, between low and critical watermark
+	memDBTotalSize = 700
+	c.coordinate()
+	assert.Equal(t, IOWritePriority, c.Mode())
+	// below low watermark
+	memDBTotalSize = 500
+	c.coordinate()
+	assert.Equal(t, IONormal, c.Mode())
+}
+
+func TestIOCoordinator_Run(t *testing.T) {
+	defer func() {
+		ioCoordinateInterval.Store(time.Second)
+	}()
+	ioCoordinateInterval.Store(10 * time.Millisecond)
+	c := newIOCoordinator(context.TODO(), config.TSDB{QueryLatencyHighWaterMark: ltoml.Duration(time.Millisecond)}, nil)
+	c.Start()
+	c.ObserveQueryLatency(time.Second)
+	time.Sleep(100 * time.Millisecond)
+	c.Stop()
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestIOCoordinator_RunBackgroundJob(t *testing.T) {
+	var count int
+	job := func() { count++ }
+	// run directly without pool
+	c := newIOCoordinator(context.TODO(), config.TSDB{}, nil)
+	c.RunBackgroundJob(job)
+	assert.Equal(t, 1, count)
+
+	// run in pool with low priority
+	pool := concurrent.NewPool("test-background", 1, time.Second, linmetric.NewScope("test-background"))
+	c = newIOCoordinator(context.TODO(), config.TSDB{}, pool)
+	c.RunBackgroundJob(job)
+	assert.Equal(t, 2, count)
+
+	// pool stopped, run directly
+	pool.Stop()
+	c.RunBackgroundJob(job)
+	assert.Equal(t, 3, count)
+}
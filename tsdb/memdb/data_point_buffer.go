@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sync"
 
 	"go.uber.org/atomic"
 
@@ -41,8 +42,18 @@ const (
 	regionSize = 128 * 1024 * 1024 // 128M
 	pageSize   = 128
 	pageCount  = regionSize / pageSize
+
+	heapRegionSize = 1024 * 1024 // 1M
+	heapPageCount  = heapRegionSize / pageSize
 )
 
+// heapRegionPool pools the in-heap regions for reusing between memory databases
+var heapRegionPool = sync.Pool{
+	New: func() interface{} {
+		return make([]byte, heapRegionSize)
+	},
+}
+
 // DataPointBuffer represents data point temp write buffer based on memory map file
 type DataPointBuffer interface {
 	io.Closer
@@ -89,6 +100,46 @@ func (d *dataPointBuffer) AllocPage() (buf []byte, err error) {
 	return d.buf[region][offset : offset+pageSize], nil
 }
 
+// heapDataPointBuffer implements DataPointBuffer interface based on pooled in-heap byte slices,
+// used for small deployments without temp file(like tmpfs-less containers).
+type heapDataPointBuffer struct {
+	buf       [][]byte
+	pageIDSeq atomic.Int32
+}
+
+// newHeapDataPointBuffer creates in-heap data point buffer for writing metric's point
+func newHeapDataPointBuffer() DataPointBuffer {
+	return &heapDataPointBuffer{
+		pageIDSeq: *atomic.NewInt32(-1),
+	}
+}
+
+// AllocPage allocates the page buffer for writing data point
+func (d *heapDataPointBuffer) AllocPage() (buf []byte, err error) {
+	pageID := d.pageIDSeq.Inc()
+	if pageID%heapPageCount == 0 {
+		d.buf = append(d.buf, heapRegionPool.Get().([]byte))
+	}
+	region := int(pageID / heapPageCount)
+	if len(d.buf) <= region {
+		return nil, fmt.Errorf("wrong region in memory buffer")
+	}
+	offset := pageSize * (int(pageID) % heapPageCount)
+	return d.buf[region][offset : offset+pageSize], nil
+}
+
+// Close closes data point buffer, puts the regions back to pool after clearing
+func (d *heapDataPointBuffer) Close() error {
+	for _, buf := range d.buf {
+		for i := range buf {
+			buf[i] = 0
+		}
+		heapRegionPool.Put(buf) //nolint:staticcheck
+	}
+	d.buf = nil
+	return nil
+}
+
 // Close closes data point buffer, unmap memory map file
 func (d *dataPointBuffer) Close() error {
 	if err := removeFunc(d.path); err != nil {
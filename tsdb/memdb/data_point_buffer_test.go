@@ -19,11 +19,14 @@ package memdb
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/pkg/fileutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/field"
 )
 
 const testPath = "test_dp_buf"
@@ -111,3 +114,75 @@ func TestDataPointBuffer_Close_err(t *testing.T) {
 	err = buf.Close()
 	assert.NoError(t, err)
 }
+
+func TestHeapDataPointBuffer_AllocPage(t *testing.T) {
+	buf := newHeapDataPointBuffer()
+	for i := 0; i < heapPageCount*2+1; i++ {
+		b, err := buf.AllocPage()
+		assert.NoError(t, err)
+		assert.Len(t, b, pageSize)
+		b[0] = 1
+	}
+	assert.Len(t, buf.(*heapDataPointBuffer).buf, 3)
+	err := buf.Close()
+	assert.NoError(t, err)
+	assert.Nil(t, buf.(*heapDataPointBuffer).buf)
+
+	// reuse region from pool, must be cleared
+	buf = newHeapDataPointBuffer()
+	b, err := buf.AllocPage()
+	assert.NoError(t, err)
+	assert.Equal(t, make([]byte, pageSize), b)
+	err = buf.Close()
+	assert.NoError(t, err)
+}
+
+func TestHeapDataPointBuffer_AllocPage_err(t *testing.T) {
+	buf := newHeapDataPointBuffer()
+	buf.(*heapDataPointBuffer).pageIDSeq.Store(0)
+	// wrong region
+	b, err := buf.AllocPage()
+	assert.Error(t, err)
+	assert.Nil(t, b)
+}
+
+func BenchmarkDataPointBuffer_write(b *testing.B) {
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	run := func(b *testing.B, onHeap bool) {
+		db, err := NewMemoryDatabase(MemoryDatabaseCfg{
+			TempPath: filepath.Join(testPath, "bench"),
+			OnHeap:   onHeap,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer func() {
+			_ = db.Close()
+		}()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = db.Write(&MetricPoint{
+				MetricID:  1,
+				SeriesID:  uint32(i),
+				SlotIndex: 10,
+				FieldIDs:  []field.ID{1},
+				Proto: &protoMetricsV1.Metric{
+					Name:      "test",
+					Namespace: "ns",
+					SimpleFields: []*protoMetricsV1.SimpleField{
+						{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 10},
+					},
+				},
+			})
+		}
+	}
+	b.Run("mmap", func(b *testing.B) {
+		run(b, false)
+	})
+	b.Run("heap", func(b *testing.B) {
+		run(b, true)
+	})
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/lindb/lindb/flow"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/fasttime"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/timeutil"
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
@@ -62,6 +63,8 @@ type MemoryDatabase interface {
 	FlushFamilyTo(flusher metricsdata.Flusher) error
 	// MemSize returns the memory-size of this metric-store
 	MemSize() int32
+	// LastWriteTime returns the time(ms) of last write, returns created time if no data written
+	LastWriteTime() int64
 	// DataFilter filters the data based on condition
 	flow.DataFilter
 	// Closer closes the memory database resource
@@ -85,6 +88,7 @@ type MemoryDatabaseCfg struct {
 	FamilyTime int64
 	Name       string
 	TempPath   string
+	OnHeap     bool // data point buffer based on in-heap byte slices instead of temp files
 }
 
 // flushContext holds the context for flushing
@@ -105,24 +109,32 @@ type memoryDatabase struct {
 	writeCondition sync.WaitGroup
 	rwMutex        sync.RWMutex // lock of create metric store
 
-	allocSize atomic.Int32 // allocated size
-	metrics   memoryDBMetrics
+	allocSize     atomic.Int32 // allocated size
+	lastWriteTime atomic.Int64 // time of last write
+	metrics       memoryDBMetrics
 }
 
 // NewMemoryDatabase returns a new MemoryDatabase.
 func NewMemoryDatabase(cfg MemoryDatabaseCfg) (MemoryDatabase, error) {
-	buf, err := newDataPointBuffer(cfg.TempPath)
-	if err != nil {
-		return nil, err
+	var buf DataPointBuffer
+	if cfg.OnHeap {
+		buf = newHeapDataPointBuffer()
+	} else {
+		var err error
+		buf, err = newDataPointBuffer(cfg.TempPath)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &memoryDatabase{
-		familyTime: cfg.FamilyTime,
-		name:       cfg.Name,
-		buf:        buf,
-		mStores:    NewMetricBucketStore(),
-		allocSize:  *atomic.NewInt32(0),
-		metrics:    *newMemoryDBMetrics(cfg.Name),
-	}, err
+		familyTime:    cfg.FamilyTime,
+		name:          cfg.Name,
+		buf:           buf,
+		mStores:       NewMetricBucketStore(),
+		allocSize:     *atomic.NewInt32(0),
+		lastWriteTime: *atomic.NewInt64(fasttime.UnixMilliseconds()),
+		metrics:       *newMemoryDBMetrics(cfg.Name),
+	}, nil
 }
 
 // getOrCreateMStore returns the mStore by metricHash.
@@ -168,6 +180,7 @@ func (md *memoryDatabase) Write(point *MetricPoint) error {
 }
 
 func (md *memoryDatabase) WriteWithoutLock(point *MetricPoint) error {
+	md.lastWriteTime.Store(fasttime.UnixMilliseconds())
 	mStore := md.getOrCreateMStore(point.MetricID)
 	tStore, size := mStore.GetOrCreateTStore(point.SeriesID)
 	written := false
@@ -188,14 +201,45 @@ func (md *memoryDatabase) WriteWithoutLock(point *MetricPoint) error {
 			fieldType = field.SumField
 		case protoMetricsV1.SimpleFieldType_GAUGE:
 			fieldType = field.GaugeField
+		case protoMetricsV1.SimpleFieldType_FIRST:
+			fieldType = field.FirstField
+		case protoMetricsV1.SimpleFieldType_LAST:
+			fieldType = field.LastField
+		case protoMetricsV1.SimpleFieldType_COUNT:
+			fieldType = field.CountField
+		case protoMetricsV1.SimpleFieldType_STRING:
+			fieldType = field.StringField
 		default:
 			continue
 		}
-		writtenLinFieldSize, err := md.writeLinField(
-			point.SlotIndex,
-			point.FieldIDs[fieldIDIdx], fieldType, simpleFields[simpleFieldIdx].Value,
-			mStore, tStore,
+		var (
+			writtenLinFieldSize int
+			err                 error
 		)
+		simpleField := simpleFields[simpleFieldIdx]
+		valueType := field.FloatValue
+		if fieldType != field.StringField {
+			// string value id is always written as float
+			switch simpleField.ValueType {
+			case protoMetricsV1.SimpleFieldValueType_INT_VALUE:
+				valueType = field.IntValue
+			case protoMetricsV1.SimpleFieldValueType_BOOL_VALUE:
+				valueType = field.BoolValue
+			}
+		}
+		if valueType == field.FloatValue {
+			writtenLinFieldSize, err = md.writeLinField(
+				point.SlotIndex,
+				point.FieldIDs[fieldIDIdx], fieldType, simpleField.Value,
+				mStore, tStore,
+			)
+		} else {
+			writtenLinFieldSize, err = md.writeIntField(
+				point.SlotIndex,
+				point.FieldIDs[fieldIDIdx], fieldType, valueType, simpleField.IntValue,
+				mStore, tStore,
+			)
+		}
 		if err != nil {
 			return err
 		}
@@ -226,7 +270,7 @@ func (md *memoryDatabase) WriteWithoutLock(point *MetricPoint) error {
 	if compoundField.Max > 0 {
 		writtenLinFieldSize, err := md.writeLinField(
 			point.SlotIndex, point.FieldIDs[fieldIDIdx],
-			field.MinField, compoundField.Max,
+			field.MaxField, compoundField.Max,
 			mStore, tStore)
 		if err != nil {
 			return err
@@ -280,23 +324,50 @@ func (md *memoryDatabase) writeLinField(
 	fieldID field.ID, fieldType field.Type, fieldValue float64,
 	mStore mStoreINTF, tStore tStoreINTF,
 ) (writtenSize int, err error) {
-	fStore, ok := tStore.GetFStore(fieldID)
-	if !ok {
-		buf, err := md.buf.AllocPage()
-		if err != nil {
-			md.metrics.allocatedPageFailures.Incr()
-			return 0, err
-		}
-		md.metrics.allocatedPages.Incr()
-		fStore = newFieldStore(buf, fieldID)
-		writtenSize += tStore.InsertFStore(fStore)
-		// if write data success, add field into metric level for cache
-		mStore.AddField(fieldID, fieldType)
+	fStore, writtenSize, err := md.getOrCreateFStore(fieldID, fieldType, mStore, tStore)
+	if err != nil {
+		return 0, err
 	}
 	writtenSize += fStore.Write(fieldType, slotIndex, fieldValue)
 	return writtenSize, nil
 }
 
+// writeIntField writes the int/bool field value natively, keeps the precision of integers above 2^53.
+func (md *memoryDatabase) writeIntField(
+	slotIndex uint16,
+	fieldID field.ID, fieldType field.Type, valueType field.ValueType, fieldValue int64,
+	mStore mStoreINTF, tStore tStoreINTF,
+) (writtenSize int, err error) {
+	fStore, writtenSize, err := md.getOrCreateFStore(fieldID, fieldType, mStore, tStore)
+	if err != nil {
+		return 0, err
+	}
+	writtenSize += fStore.WriteInt(fieldType, valueType, slotIndex, fieldValue)
+	return writtenSize, nil
+}
+
+// getOrCreateFStore returns the field store, creates it if not exist, returns the created size.
+func (md *memoryDatabase) getOrCreateFStore(
+	fieldID field.ID, fieldType field.Type,
+	mStore mStoreINTF, tStore tStoreINTF,
+) (fStore fStoreINTF, createdSize int, err error) {
+	fStore, ok := tStore.GetFStore(fieldID)
+	if ok {
+		return fStore, 0, nil
+	}
+	buf, err := md.buf.AllocPage()
+	if err != nil {
+		md.metrics.allocatedPageFailures.Incr()
+		return nil, 0, err
+	}
+	md.metrics.allocatedPages.Incr()
+	fStore = newFieldStore(buf, fieldID)
+	createdSize = tStore.InsertFStore(fStore)
+	// if write data success, add field into metric level for cache
+	mStore.AddField(fieldID, fieldType)
+	return fStore, createdSize, nil
+}
+
 // FlushFamilyTo flushes all data related to the family from metric-stores to builder.
 func (md *memoryDatabase) FlushFamilyTo(flusher metricsdata.Flusher) error {
 	// waiting current writing complete
@@ -338,7 +409,15 @@ func (md *memoryDatabase) MemSize() int32 {
 	return md.allocSize.Load()
 }
 
-// Close closes memory data point buffer
+// LastWriteTime returns the time(ms) of last write, returns created time if no data written
+func (md *memoryDatabase) LastWriteTime() int64 {
+	return md.lastWriteTime.Load()
+}
+
+// Close closes memory data point buffer after current writing complete
 func (md *memoryDatabase) Close() error {
+	// waiting current writing complete
+	md.writeCondition.Wait()
+
 	return md.buf.Close()
 }
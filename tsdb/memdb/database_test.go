@@ -57,6 +57,14 @@ func TestMemoryDatabase_New(t *testing.T) {
 	mdINTF, err = NewMemoryDatabase(cfg)
 	assert.Error(t, err)
 	assert.Nil(t, mdINTF)
+
+	// on heap buffer, no temp path
+	mdINTF, err = NewMemoryDatabase(MemoryDatabaseCfg{OnHeap: true})
+	assert.NoError(t, err)
+	_, ok := mdINTF.(*memoryDatabase).buf.(*heapDataPointBuffer)
+	assert.True(t, ok)
+	err = mdINTF.Close()
+	assert.NoError(t, err)
 }
 
 func TestMemoryDatabase_AcquireWrite(t *testing.T) {
@@ -114,6 +122,34 @@ func TestMemoryDatabase_Write(t *testing.T) {
 			},
 		}})
 	assert.NoError(t, err)
+	// case 1.1: write int/bool value natively, string value id as float
+	gomock.InOrder(
+		tStore.EXPECT().GetFStore(gomock.Any()).Return(fStore, true),
+		fStore.EXPECT().WriteInt(field.SumField, field.IntValue, uint16(1), int64(1<<60+1)).Return(10),
+		tStore.EXPECT().GetFStore(gomock.Any()).Return(fStore, true),
+		fStore.EXPECT().WriteInt(field.LastField, field.BoolValue, uint16(1), int64(1)).Return(10),
+		tStore.EXPECT().GetFStore(gomock.Any()).Return(fStore, true),
+		fStore.EXPECT().Write(field.StringField, uint16(1), 5.0).Return(10),
+		mockMStore.EXPECT().SetSlot(gomock.Any()).Times(1),
+	)
+	err = md.Write(&MetricPoint{
+		MetricID:  1,
+		SeriesID:  10,
+		SlotIndex: 1,
+		FieldIDs:  []field.ID{10, 11, 12},
+		Proto: &protoMetricsV1.Metric{
+			Name:      "test1",
+			Namespace: "ns",
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 1 << 60,
+					ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE, IntValue: 1<<60 + 1},
+				{Name: "f2", Type: protoMetricsV1.SimpleFieldType_LAST, Value: 1,
+					ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE, IntValue: 1},
+				{Name: "f3", Type: protoMetricsV1.SimpleFieldType_STRING, Value: 5,
+					ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE},
+			},
+		}})
+	assert.NoError(t, err)
 	// case 2: field type unknown
 	err = md.Write(&MetricPoint{
 		MetricID:  1,
@@ -200,6 +236,58 @@ func TestMemoryDatabase_Write(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMemoryDatabase_Write_fieldType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMStore := NewMockmStoreINTF(ctrl)
+	tStore := NewMocktStoreINTF(ctrl)
+	mockMStore.EXPECT().GetOrCreateTStore(uint32(10)).Return(tStore, 10).AnyTimes()
+	tStore.EXPECT().GetFStore(gomock.Any()).Return(nil, false).AnyTimes()
+	tStore.EXPECT().InsertFStore(gomock.Any()).AnyTimes()
+	mockMStore.EXPECT().SetSlot(gomock.Any()).AnyTimes()
+	mdINTF, err := NewMemoryDatabase(cfg)
+	assert.NoError(t, err)
+	md := mdINTF.(*memoryDatabase)
+	md.mStores.Put(uint32(1), mockMStore)
+
+	gomock.InOrder(
+		mockMStore.EXPECT().AddField(field.ID(1), field.FirstField),
+		mockMStore.EXPECT().AddField(field.ID(2), field.LastField),
+		mockMStore.EXPECT().AddField(field.ID(3), field.CountField),
+		mockMStore.EXPECT().AddField(field.ID(4), field.MinField),
+		mockMStore.EXPECT().AddField(field.ID(5), field.MaxField),
+		mockMStore.EXPECT().AddField(field.ID(6), field.SumField),
+		mockMStore.EXPECT().AddField(field.ID(7), field.SumField),
+		mockMStore.EXPECT().AddField(field.ID(8), field.HistogramField),
+	)
+	err = md.Write(&MetricPoint{
+		MetricID:  1,
+		SeriesID:  10,
+		SlotIndex: 1,
+		FieldIDs:  []field.ID{1, 2, 3, 4, 5, 6, 7, 8},
+		Proto: &protoMetricsV1.Metric{
+			Name:      "test1",
+			Namespace: "ns",
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "f1", Type: protoMetricsV1.SimpleFieldType_FIRST, Value: 10},
+				{Name: "f2", Type: protoMetricsV1.SimpleFieldType_LAST, Value: 10},
+				{Name: "f3", Type: protoMetricsV1.SimpleFieldType_COUNT, Value: 10},
+			},
+			CompoundField: &protoMetricsV1.CompoundField{
+				Min:            1,
+				Max:            10,
+				Sum:            10,
+				Count:          2,
+				ExplicitBounds: []float64{math.Inf(1)},
+				Values:         []float64{2},
+				Type:           protoMetricsV1.CompoundFieldType_DELTA_HISTOGRAM,
+			},
+		}})
+	assert.NoError(t, err)
+	assert.NoError(t, md.Close())
+}
+
 func TestMemoryDatabase_Write_err(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -295,3 +383,14 @@ func TestMemoryDatabase_Filter(t *testing.T) {
 	err = md.Close()
 	assert.NoError(t, err)
 }
+
+func TestMemoryDatabase_LastWriteTime(t *testing.T) {
+	mdINTF, err := NewMemoryDatabase(cfg)
+	assert.NoError(t, err)
+	createdTime := mdINTF.LastWriteTime()
+	assert.True(t, createdTime > 0)
+	md := mdINTF.(*memoryDatabase)
+	md.lastWriteTime.Store(createdTime - 1000)
+	assert.Equal(t, createdTime-1000, mdINTF.LastWriteTime())
+	assert.NoError(t, mdINTF.Close())
+}
@@ -48,7 +48,8 @@ const (
 	valueSize   = 8
 
 	emptyFieldStoreSize = 24 + // empty buf slice cost
-		24 // empty compress slice cost
+		24 + // empty compress slice cost
+		8 // value type cost(with padding)
 )
 
 // fStoreINTF represents field-store,
@@ -60,16 +61,20 @@ type fStoreINTF interface {
 	// if time slot out of current time window, need compress time window then resets the current buffer
 	// if has same time slot in current buffer, need do rollup operation by field type
 	Write(fieldType field.Type, slotIndex uint16, value float64) (writtenSize int)
+	// WriteInt writes the int/bool field data into current buffer natively, returns the written size.
+	// the value is written as float64 if store already has float values.
+	WriteInt(fieldType field.Type, valueType field.ValueType, slotIndex uint16, value int64) (writtenSize int)
 	// FlushFieldTo flushes field store data into kv store, need align slot range in metric level
 	FlushFieldTo(tableFlusher metricsdata.Flusher, fieldMeta field.Meta, flushCtx flushContext)
-	// Load loads field series data.
+	// Load loads field series data as tsd block.
 	Load(fieldType field.Type, slotRange timeutil.SlotRange) []byte
 }
 
 // fieldStore implements fStoreINTF interface
 type fieldStore struct {
-	buf      []byte // current write buffer, accept write data
-	compress []byte // immutable compress data
+	buf       []byte          // current write buffer, accept write data
+	compress  []byte          // immutable compress data
+	valueType field.ValueType // value type of buf/compress, int/bool value is stored as the bits of int64
 }
 
 // newFieldStore creates a new field store
@@ -89,6 +94,30 @@ func (fs *fieldStore) GetFieldID() field.ID {
 // if time slot out of current time window, need compress time window then resets the current buffer
 // if has same time slot in current buffer, need do rollup operation by field type
 func (fs *fieldStore) Write(fieldType field.Type, slotIndex uint16, value float64) (writtenSize int) {
+	if fs.valueType != field.FloatValue {
+		// int/bool values written before, converts them into float values
+		writtenSize = fs.toFloat()
+	}
+	return writtenSize + fs.write(fieldType, slotIndex, math.Float64bits(value))
+}
+
+// WriteInt writes the int/bool field data into current buffer natively, returns the written size.
+// the value is written as float64 if store already has float values.
+func (fs *fieldStore) WriteInt(fieldType field.Type, valueType field.ValueType, slotIndex uint16, value int64) (writtenSize int) {
+	if fs.hasData() && fs.valueType == field.FloatValue {
+		return fs.write(fieldType, slotIndex, math.Float64bits(float64(value)))
+	}
+	// booleans summed/counted become integers
+	valueType = valueType.Aggregated(fieldType.GetAggFunc().AggType())
+	if fs.hasData() {
+		valueType = fs.valueType.Merge(valueType)
+	}
+	fs.valueType = valueType
+	return fs.write(fieldType, slotIndex, uint64(value))
+}
+
+// write writes the bits of value into current buffer, returns the written size.
+func (fs *fieldStore) write(fieldType field.Type, slotIndex uint16, value uint64) (writtenSize int) {
 	if fs.buf[markOffset+1] == 0 {
 		// no data written before
 		return fs.writeFirstPoint(slotIndex, value)
@@ -109,9 +138,8 @@ func (fs *fieldStore) Write(fieldType field.Type, slotIndex uint16, value float6
 	pos, markIdx, flagIdx := fs.position(delta)
 	if fs.buf[markOffset+markIdx]&flagIdx != 0 {
 		// has same point of same time slot
-		aggFunc := fieldType.GetAggFunc()
-		oldValue := math.Float64frombits(binary.LittleEndian.Uint64(fs.buf[pos:]))
-		value = aggFunc.Aggregate(oldValue, value)
+		oldValue := binary.LittleEndian.Uint64(fs.buf[pos:])
+		value = fs.aggregate(fieldType.GetAggFunc(), oldValue, value)
 	} else {
 		// new data for time slot
 		fs.buf[endOffset] = byte(delta)
@@ -119,38 +147,77 @@ func (fs *fieldStore) Write(fieldType field.Type, slotIndex uint16, value float6
 		writtenSize += valueSize
 	}
 	// finally write value into the body of current write buffer
-	binary.LittleEndian.PutUint64(fs.buf[pos:], math.Float64bits(value))
+	binary.LittleEndian.PutUint64(fs.buf[pos:], value)
 	return writtenSize
 }
 
+// hasData checks if store has data written
+func (fs *fieldStore) hasData() bool {
+	return fs.buf[markOffset+1] != 0 || len(fs.compress) > 0
+}
+
+// aggregate aggregates the bits of two values by value type of store
+func (fs *fieldStore) aggregate(aggFunc field.AggFunc, a, b uint64) uint64 {
+	if fs.valueType == field.FloatValue {
+		return math.Float64bits(aggFunc.Aggregate(math.Float64frombits(a), math.Float64frombits(b)))
+	}
+	return uint64(aggFunc.AggregateInt(int64(a), int64(b)))
+}
+
+// toFloat converts the int/bool values of current/compress buffer into float values, returns the changed size.
+func (fs *fieldStore) toFloat() (size int) {
+	fs.valueType = field.FloatValue
+	if fs.buf[markOffset+1] != 0 {
+		for delta := uint16(0); delta <= fs.getEnd(); delta++ {
+			pos, markIdx, flagIdx := fs.position(delta)
+			if fs.buf[markOffset+markIdx]&flagIdx != 0 {
+				value := int64(binary.LittleEndian.Uint64(fs.buf[pos:]))
+				binary.LittleEndian.PutUint64(fs.buf[pos:], math.Float64bits(float64(value)))
+			}
+		}
+	}
+	length := len(fs.compress)
+	if length == 0 {
+		return 0
+	}
+	tsd := encoding.GetTSDDecoder()
+	defer encoding.ReleaseTSDDecoder(tsd)
+	tsd.Reset(fs.compress)
+	encode := encoding.TSDEncodeFunc(tsd.StartTime())
+	for slot := int(tsd.StartTime()); slot <= int(tsd.EndTime()); slot++ {
+		if tsd.HasValueWithSlot(uint16(slot)) {
+			encode.AppendTime(bit.One)
+			encode.AppendValue(math.Float64bits(float64(int64(tsd.Value()))))
+		} else {
+			encode.AppendTime(bit.Zero)
+		}
+	}
+	compress, err := encode.Bytes()
+	if err != nil {
+		memDBLogger.Error("convert field store data to float err", logger.Error(err))
+	}
+	fs.compress = compress
+	return len(fs.compress) - length
+}
+
 // FlushFieldTo flushes field store data into kv store, need align slot range in metric level
 func (fs *fieldStore) FlushFieldTo(tableFlusher metricsdata.Flusher, fieldMeta field.Meta, flushCtx flushContext) {
-	aggFunc := fieldMeta.Type.GetAggFunc()
-	var tsd *encoding.TSDDecoder
-	size := len(fs.compress)
-	if size > 0 {
-		// calc new start/end based on old compress values
-		tsd = encoding.GetTSDDecoder()
-		defer encoding.ReleaseTSDDecoder(tsd)
-		tsd.Reset(fs.compress)
-	}
-	data, _, err := fs.merge(aggFunc, tsd, fs.getStart(), flushCtx.SlotRange, false)
+	block, err := fs.encodeBlock(fieldMeta.Type.GetAggFunc(), flushCtx.SlotRange)
 	if err != nil {
 		memDBLogger.Error("flush field store err, data lost", logger.Error(err))
 		return
 	}
-
-	tableFlusher.FlushField(data)
+	tableFlusher.FlushField(block)
 }
 
 // writeFirstPoint writes first point in current write buffer
-func (fs *fieldStore) writeFirstPoint(slotIndex uint16, value float64) (writtenSize int) {
+func (fs *fieldStore) writeFirstPoint(slotIndex uint16, value uint64) (writtenSize int) {
 	pos, markIdx, flagIdx := fs.position(0)
 	binary.LittleEndian.PutUint16(fs.buf[startOffset:], slotIndex) // write start time
 	fs.buf[endOffset] = 0
 	fs.buf[markOffset+markIdx] |= flagIdx // mark value exist
 	fs.buf[markOffset+1] |= 1             // last mark flag marks if buf has data written
-	binary.LittleEndian.PutUint64(fs.buf[pos:], math.Float64bits(value))
+	binary.LittleEndian.PutUint64(fs.buf[pos:], value)
 	return valueSize + headLen
 }
 
@@ -179,9 +246,12 @@ func (fs *fieldStore) compact(fieldType field.Type, startTime uint16) (size int)
 		defer encoding.ReleaseTSDDecoder(tsd)
 		tsd.Reset(fs.compress)
 	}
-	data, freeSize, err := fs.merge(aggFunc, tsd, startTime, thisSlotRange, true)
+	encode := encoding.TSDEncodeFunc(thisSlotRange.Start)
+	freeSize := fs.merge(aggFunc, tsd, startTime, thisSlotRange, encode)
+	data, err := encode.Bytes()
 	if err != nil {
 		memDBLogger.Error("compact field store data err", logger.Error(err))
+		freeSize = 0
 	}
 
 	fs.compress = data
@@ -208,7 +278,7 @@ func (fs *fieldStore) getEnd() uint16 {
 	return uint16(fs.buf[endOffset])
 }
 
-// merge merges the current and compress data based on field aggregate function,
+// merge merges the current and compress data based on field aggregate function into encoder,
 // startTime => current write start time
 // start/end slot => target compact time slot
 func (fs *fieldStore) merge(
@@ -216,25 +286,24 @@ func (fs *fieldStore) merge(
 	tsd *encoding.TSDDecoder,
 	startTime uint16,
 	thisSlotRange timeutil.SlotRange,
-	withTimeRange bool,
-) (compress []byte, freeSize int, err error) {
-	encode := encoding.TSDEncodeFunc(thisSlotRange.Start)
+	encode encoding.TSDEncoder,
+) (freeSize int) {
 	for i := thisSlotRange.Start; i <= thisSlotRange.End; i++ {
 		newValue, hasNewValue := fs.getCurrentValue(startTime, i)
-		oldValue, hasOldValue := getOldFloatValue(tsd, i)
+		oldValue, hasOldValue := getOldValue(tsd, i)
 		switch {
 		case hasNewValue && !hasOldValue:
 			// just compress current block value with pos
 			encode.AppendTime(bit.One)
-			encode.AppendValue(math.Float64bits(newValue))
+			encode.AppendValue(newValue)
 		case hasNewValue && hasOldValue:
 			// merge and compress
 			encode.AppendTime(bit.One)
-			encode.AppendValue(math.Float64bits(aggFunc.Aggregate(newValue, oldValue)))
+			encode.AppendValue(fs.aggregate(aggFunc, oldValue, newValue))
 		case !hasNewValue && hasOldValue:
 			// compress old value
 			encode.AppendTime(bit.One)
-			encode.AppendValue(math.Float64bits(oldValue))
+			encode.AppendValue(oldValue)
 		default:
 			// append empty value
 			encode.AppendTime(bit.Zero)
@@ -244,38 +313,48 @@ func (fs *fieldStore) merge(
 			freeSize += valueSize
 		}
 	}
-	if withTimeRange {
-		compress, err = encode.Bytes()
-		if err != nil {
-			return nil, 0, err
-		}
-		return compress, freeSize, err
-	}
-	// get compress data without time slot range
-	compress, err = encode.BytesWithoutTime()
+	return freeSize
+}
+
+// Load loads field series data as tsd block.
+func (fs *fieldStore) Load(fieldType field.Type, slotRange timeutil.SlotRange) []byte {
+	block, err := fs.encodeBlock(fieldType.GetAggFunc(), slotRange)
 	if err != nil {
-		return nil, 0, err
+		memDBLogger.Error("load field store err", logger.Error(err))
+		return nil
 	}
-	return compress, freeSize, err
+	return block
 }
 
-// Load loads field series data.
-func (fs *fieldStore) Load(fieldType field.Type, slotRange timeutil.SlotRange) []byte {
-	aggFunc := fieldType.GetAggFunc()
+// encodeBlock merges the current and compress data into tsd block,
+// int/bool values are encoded natively with int/bool codec, float values with xor codec.
+func (fs *fieldStore) encodeBlock(aggFunc field.AggFunc, slotRange timeutil.SlotRange) ([]byte, error) {
 	var tsd *encoding.TSDDecoder
-	size := len(fs.compress)
-	if size > 0 {
+	if len(fs.compress) > 0 {
 		// calc new start/end based on old compress values
 		tsd = encoding.GetTSDDecoder()
 		defer encoding.ReleaseTSDDecoder(tsd)
 		tsd.Reset(fs.compress)
 	}
-	data, _, err := fs.merge(aggFunc, tsd, fs.getStart(), slotRange, false)
-	if err != nil {
-		memDBLogger.Error("load field store err", logger.Error(err))
-		return nil
+	switch fs.valueType {
+	case field.IntValue, field.BoolValue:
+		codec := encoding.TSDCodecInt
+		if fs.valueType == field.BoolValue {
+			codec = encoding.TSDCodecBool
+		}
+		encode := encoding.NewTSDValueEncoder(codec)
+		fs.merge(aggFunc, tsd, fs.getStart(), slotRange, encode)
+		return encode.Bytes()
+	default:
+		encode := encoding.TSDEncodeFunc(slotRange.Start)
+		fs.merge(aggFunc, tsd, fs.getStart(), slotRange, encode)
+		// get compress data without time slot range
+		data, err := encode.BytesWithoutTime()
+		if err != nil {
+			return nil, err
+		}
+		return encoding.NewTSDBlock(data), nil
 	}
-	return data
 }
 
 // slotRange returns time slot range in current/compress buffer
@@ -302,7 +381,7 @@ func getTimeSlotRange(startSlot1, endSlot1 uint16, startSlot2, endSlot2 uint16)
 }
 
 // getCurrentValue returns the value in current write buffer
-func (fs *fieldStore) getCurrentValue(startTime uint16, timeSlot uint16) (value float64, hasValue bool) {
+func (fs *fieldStore) getCurrentValue(startTime uint16, timeSlot uint16) (value uint64, hasValue bool) {
 	if timeSlot < startTime || timeSlot > startTime+fs.getEnd() {
 		return
 	}
@@ -312,12 +391,12 @@ func (fs *fieldStore) getCurrentValue(startTime uint16, timeSlot uint16) (value
 		return
 	}
 	hasValue = true
-	value = math.Float64frombits(binary.LittleEndian.Uint64(fs.buf[pos:]))
+	value = binary.LittleEndian.Uint64(fs.buf[pos:])
 	return
 }
 
-// getOldFloatValue returns the value in compress buffer
-func getOldFloatValue(tsd *encoding.TSDDecoder, timeSlot uint16) (value float64, hasValue bool) {
+// getOldValue returns the bits of value in compress buffer
+func getOldValue(tsd *encoding.TSDDecoder, timeSlot uint16) (value uint64, hasValue bool) {
 	if tsd == nil {
 		return
 	}
@@ -325,6 +404,6 @@ func getOldFloatValue(tsd *encoding.TSDDecoder, timeSlot uint16) (value float64,
 		return
 	}
 	hasValue = true
-	value = math.Float64frombits(tsd.Value())
+	value = tsd.Value()
 	return
 }
@@ -60,33 +60,33 @@ func TestFieldStore_Write(t *testing.T) {
 	// case 1: get write value
 	value, ok := s.getCurrentValue(10, 10)
 	assert.True(t, ok)
-	assert.InDelta(t, 10.1, value, 0)
+	assert.InDelta(t, 10.1, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(0), s.getEnd())
 	// case 2: get not exist value, out of time slot range
 	value, ok = s.getCurrentValue(10, 12)
 	assert.False(t, ok)
-	assert.Equal(t, 0.0, value)
+	assert.Zero(t, value)
 	value, ok = s.getCurrentValue(10, 0)
 	assert.False(t, ok)
-	assert.Equal(t, 0.0, value)
+	assert.Zero(t, value)
 	// case 3: write exist value, need rollup
 	writtenSize = store.Write(field.SumField, 10, 10.1)
 	assert.Zero(t, writtenSize)
 	value, ok = s.getCurrentValue(10, 10)
 	assert.True(t, ok)
-	assert.InDelta(t, 20.2, value, 0)
+	assert.InDelta(t, 20.2, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(0), s.getEnd())
 	// case 3: write new value
 	writtenSize = store.Write(field.SumField, 12, 12.1)
 	assert.Equal(t, valueSize, writtenSize)
 	value, ok = s.getCurrentValue(10, 12)
 	assert.True(t, ok)
-	assert.InDelta(t, 12.1, value, 0)
+	assert.InDelta(t, 12.1, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(2), s.getEnd())
 	// case 4: get value in time slot range
 	value, ok = s.getCurrentValue(10, 11)
 	assert.False(t, ok)
-	assert.Equal(t, 0.0, value)
+	assert.Zero(t, value)
 	// case 5: test slot range [10,12]
 	thisSlotRange := s.slotRange(s.getStart())
 	assert.Equal(t, uint16(10), thisSlotRange.Start)
@@ -99,7 +99,7 @@ func TestFieldStore_Write(t *testing.T) {
 	assert.Equal(t, uint16(12), thisSlotRange.End)
 	value, ok = s.getCurrentValue(5, 5)
 	assert.True(t, ok)
-	assert.InDelta(t, 5.3, value, 0)
+	assert.InDelta(t, 5.3, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(0), s.getEnd())
 	// case 7: write old value
 	writtenSize = store.Write(field.SumField, 10, 10.1)
@@ -113,7 +113,7 @@ func TestFieldStore_Write(t *testing.T) {
 	assert.Equal(t, uint16(50), thisSlotRange.End)
 	value, ok = s.getCurrentValue(50, 50)
 	assert.True(t, ok)
-	assert.InDelta(t, 50.1, value, 0.0)
+	assert.InDelta(t, 50.1, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(0), s.getEnd())
 	// case 9: write 10 slot, compact old value
 	writtenSize = store.Write(field.SumField, 10, 10.1)
@@ -121,12 +121,17 @@ func TestFieldStore_Write(t *testing.T) {
 	assert.Equal(t, uint16(0), s.getEnd())
 	value, ok = s.getCurrentValue(10, 10)
 	assert.True(t, ok)
-	assert.InDelta(t, 10.1, value, 0)
+	assert.InDelta(t, 10.1, math.Float64frombits(value), 0)
 
 	// case 10: test final data by load
 	writtenSize = store.Write(field.SumField, 15, 15.1)
 	assert.Equal(t, valueSize, writtenSize)
-	s.Load(field.SumField, thisSlotRange)
+	block := s.Load(field.SumField, thisSlotRange)
+	assert.Equal(t, byte(encoding.TSDCodecXOR), block[0])
+	decoder := encoding.NewTSDDecoder(nil)
+	assert.NoError(t, decoder.ResetTSDBlock(block, thisSlotRange.Start, thisSlotRange.End))
+	assert.True(t, decoder.HasValueWithSlot(5))
+	assert.InDelta(t, 5.3, math.Float64frombits(decoder.Value()), 0.000001)
 }
 
 func TestFieldStore_Write2(t *testing.T) {
@@ -137,17 +142,66 @@ func TestFieldStore_Write2(t *testing.T) {
 	assert.Equal(t, valueSize+headLen, writtenSize)
 	value, ok := s.getCurrentValue(10, 10)
 	assert.True(t, ok)
-	assert.InDelta(t, 178.0, value, 0)
+	assert.InDelta(t, 178.0, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(0), s.getEnd())
 	// write with old slot
 	writtenSize = store.Write(field.SumField, 10, 178)
 	assert.Equal(t, 0, writtenSize)
 	value, ok = s.getCurrentValue(10, 10)
 	assert.True(t, ok)
-	assert.InDelta(t, 178.0*2, value, 0)
+	assert.InDelta(t, 178.0*2, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(0), s.getEnd())
 }
 
+func TestFieldStore_WriteInt(t *testing.T) {
+	store := newFieldStore(make([]byte, pageSize), field.ID(1))
+	s := store.(*fieldStore)
+	// case 1: write int values above 2^53, keeps precision after rollup and compact
+	writtenSize := store.WriteInt(field.SumField, field.IntValue, 10, 1<<60+1)
+	assert.Equal(t, valueSize+headLen, writtenSize)
+	assert.Zero(t, store.WriteInt(field.SumField, field.IntValue, 10, 1))
+	value, ok := s.getCurrentValue(10, 10)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1<<60+2), int64(value))
+	assert.True(t, store.WriteInt(field.SumField, field.IntValue, 50, 3) > valueSize)
+	assert.Equal(t, field.IntValue, s.valueType)
+	block := store.Load(field.SumField, timeutil.SlotRange{Start: 10, End: 50})
+	assert.Equal(t, byte(encoding.TSDCodecInt), block[0])
+	decoder := encoding.NewTSDDecoder(nil)
+	assert.NoError(t, decoder.ResetTSDBlock(block, 10, 50))
+	assert.True(t, decoder.HasValueWithSlot(10))
+	assert.Equal(t, int64(1<<60+2), decoder.IntValue())
+	// case 2: bool values become int after merging
+	assert.Zero(t, store.WriteInt(field.SumField, field.BoolValue, 50, 1))
+	assert.Equal(t, field.IntValue, s.valueType)
+	value, _ = s.getCurrentValue(50, 50)
+	assert.Equal(t, int64(4), int64(value))
+	// case 3: write float value, converts int values into float
+	assert.Zero(t, store.Write(field.SumField, 50, 0.5))
+	assert.Equal(t, field.FloatValue, s.valueType)
+	value, _ = s.getCurrentValue(50, 50)
+	assert.Equal(t, 4.5, math.Float64frombits(value))
+	assert.Zero(t, store.WriteInt(field.SumField, field.IntValue, 50, 1))
+	value, _ = s.getCurrentValue(50, 50)
+	assert.Equal(t, 5.5, math.Float64frombits(value))
+	block = store.Load(field.SumField, timeutil.SlotRange{Start: 10, End: 50})
+	assert.Equal(t, byte(encoding.TSDCodecXOR), block[0])
+	assert.NoError(t, decoder.ResetTSDBlock(block, 10, 50))
+	assert.True(t, decoder.HasValueWithSlot(10))
+	assert.Equal(t, float64(1<<60+2), math.Float64frombits(decoder.Value()))
+	// case 4: bool values kept by last value
+	boolStore := newFieldStore(make([]byte, pageSize), field.ID(2))
+	_ = boolStore.WriteInt(field.LastField, field.BoolValue, 10, 1)
+	_ = boolStore.WriteInt(field.LastField, field.BoolValue, 11, 0)
+	assert.Equal(t, field.BoolValue, boolStore.(*fieldStore).valueType)
+	block = boolStore.Load(field.LastField, timeutil.SlotRange{Start: 10, End: 11})
+	assert.Equal(t, byte(encoding.TSDCodecBool), block[0])
+	// case 5: bool values summed become int
+	boolStore = newFieldStore(make([]byte, pageSize), field.ID(2))
+	_ = boolStore.WriteInt(field.CountField, field.BoolValue, 10, 1)
+	assert.Equal(t, field.IntValue, boolStore.(*fieldStore).valueType)
+}
+
 func TestFieldStore_Write_Compact_err(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -175,7 +229,7 @@ func TestFieldStore_Write_Compact_err(t *testing.T) {
 	assert.Equal(t, valueSize+headLen, writtenSize)
 	value, ok := s.getCurrentValue(100, 100)
 	assert.True(t, ok)
-	assert.InDelta(t, 100.1, value, 0)
+	assert.InDelta(t, 100.1, math.Float64frombits(value), 0)
 	assert.Equal(t, uint16(0), s.getEnd())
 	// compress data is nil
 	assert.Nil(t, s.compress)
@@ -199,6 +253,21 @@ func TestFieldStore_FlushFieldTo(t *testing.T) {
 	// case 1: flush success
 	flusher.EXPECT().FlushField(mockFlushData())
 	store.FlushFieldTo(flusher, field.Meta{Type: field.SumField}, flushContext{SlotRange: timeutil.SlotRange{Start: 2, End: 20}})
+	// case 2: flush integer values with int codec
+	intStore := newFieldStore(make([]byte, pageSize), field.ID(3))
+	for i := uint16(2); i <= 20; i++ {
+		_ = intStore.WriteInt(field.SumField, field.IntValue, i, 1<<60+int64(i))
+	}
+	flusher.EXPECT().FlushField(gomock.Any()).Do(func(data []byte) {
+		assert.Equal(t, byte(encoding.TSDCodecInt), data[0])
+		decoder := encoding.NewTSDDecoder(nil)
+		assert.NoError(t, decoder.ResetTSDBlock(data, 2, 20))
+		for i := uint16(2); i <= 20; i++ {
+			assert.True(t, decoder.HasValueWithSlot(i))
+			assert.Equal(t, 1<<60+int64(i), decoder.IntValue())
+		}
+	})
+	intStore.FlushFieldTo(flusher, field.Meta{Type: field.SumField}, flushContext{SlotRange: timeutil.SlotRange{Start: 2, End: 20}})
 	// case 3: flush err
 	encode := encoding.NewMockTSDEncoder(ctrl)
 	encoding.TSDEncodeFunc = func(startTime uint16) encoding.TSDEncoder {
@@ -225,5 +294,5 @@ func mockFlushData() []byte {
 		}
 	}
 	d, _ := encode.BytesWithoutTime()
-	return d
+	return encoding.NewTSDBlock(d)
 }
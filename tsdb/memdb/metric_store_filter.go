@@ -88,6 +88,9 @@ func (rs *memFilterResultSet) SeriesIDs() *roaring.Bitmap {
 	return rs.seriesIDs
 }
 
+// Close releases the resources of result set, memory storage no need release.
+func (rs *memFilterResultSet) Close() {}
+
 // Load loads the data from storage, then returns the memory storage metric scanner.
 func (rs *memFilterResultSet) Load(highKey uint16, seriesIDs roaring.Container) flow.DataLoader {
 	//FIXME need add lock?????
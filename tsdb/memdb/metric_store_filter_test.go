@@ -53,6 +53,7 @@ func TestMetricStore_Filter(t *testing.T) {
 				Type: field.SumField,
 			}}, mrs.fields)
 	assert.Equal(t, "memory", rs[0].Identifier())
+	rs[0].Close()
 }
 
 func TestMemFilterResultSet_Load(t *testing.T) {
@@ -20,6 +20,8 @@ package metadb
 import (
 	"io"
 
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/series"
 	"github.com/lindb/lindb/series/field"
@@ -40,6 +42,9 @@ type IDGenerator interface {
 	GenFieldID(namespace, metricName string, fieldName field.Name, fieldType field.Type) (field.ID, error)
 	// GenTagKeyID generates the tag key id in the memory
 	GenTagKeyID(namespace, metricName, tagKey string) (uint32, error)
+	// GenStringValueID generates the value id of string field, saves the string value into dictionary if absent,
+	// error-case: different string value has same value id
+	GenStringValueID(value string) (uint64, error)
 }
 
 // IDGetter represents the query ability for metric level, such as metric id, field meta etc.
@@ -61,6 +66,8 @@ type IDGetter interface {
 	// GetAllHistogramFields returns histogram-fields namespace/metric name,
 	// if not exist return series.ErrNotFound
 	GetAllHistogramFields(namespace, metricName string) (fields field.Metas, err error)
+	// GetStringValues returns the string values of string field by value ids, absent ids are ignored
+	GetStringValues(ids []uint64) (values map[uint64]string, err error)
 }
 
 // Metadata represents all metadata of tsdb, like metric/tag metadata
@@ -71,6 +78,8 @@ type Metadata interface {
 	MetadataDatabase() MetadataDatabase
 	// TagMetadata returns the tag metadata
 	TagMetadata() TagMetadata
+	// TagValueStats returns the stats of tag values tracked in write path
+	TagValueStats() TagValueStats
 	// Flush flushes the metadata to disk
 	Flush() error
 }
@@ -84,6 +93,17 @@ type MetadataDatabase interface {
 
 	// SuggestNamespace suggests the namespace by namespace's prefix
 	SuggestNamespace(prefix string, limit int) (namespaces []string, err error)
+	// SaveMetricDescriptor saves the metric descriptor(unit/description/expected field types),
+	// overwrites the stored descriptor if not inferred, else only fills the absent attributes.
+	SaveMetricDescriptor(descriptor *models.MetricDescriptor, inferred bool) error
+	// GetMetricDescriptor gets the metric descriptor by namespace/metric name,
+	// if not exist return constants.ErrMetricDescriptorNotFound
+	GetMetricDescriptor(namespace, metricName string) (*models.MetricDescriptor, error)
+	// GetAllFieldsByMetricID returns the all stored fields by metric id,
+	// if not exist return constants.ErrMetricBucketNotFound
+	GetAllFieldsByMetricID(metricID uint32) (fields field.Metas, err error)
 	// Sync syncs the pending metadata update event
 	Sync() error
+	// SetSyncPolicy sets the fsync policy of metadata write ahead log
+	SetSyncPolicy(policy bufioutil.SyncPolicy)
 }
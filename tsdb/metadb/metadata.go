@@ -28,6 +28,7 @@ type metadata struct {
 	databaseName     string // database name
 	metadataDatabase MetadataDatabase
 	tagMetadata      TagMetadata
+	tagValueStats    TagValueStats
 }
 
 // NewMetadata creates a metadata
@@ -40,6 +41,7 @@ func NewMetadata(ctx context.Context, databaseName, parent string, tagFamily kv.
 		metadataDatabase: db,
 		databaseName:     databaseName,
 		tagMetadata:      NewTagMetadata(databaseName, tagFamily),
+		tagValueStats:    NewTagValueStats(),
 	}, nil
 }
 
@@ -58,6 +60,11 @@ func (m *metadata) TagMetadata() TagMetadata {
 	return m.tagMetadata
 }
 
+// TagValueStats returns the stats of tag values tracked in write path
+func (m *metadata) TagValueStats() TagValueStats {
+	return m.tagValueStats
+}
+
 // Close closes the metadata backend storage
 func (m *metadata) Close() error {
 	if err := m.metadataDatabase.Close(); err != nil {
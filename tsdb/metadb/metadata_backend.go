@@ -29,6 +29,8 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/series/field"
@@ -52,6 +54,8 @@ var (
 	metricBucketName = []byte("m")
 	tagBucketName    = []byte("t")
 	fieldBucketName  = []byte("f")
+	descBucketName   = []byte("d")
+	strBucketName    = []byte("s")
 )
 
 // MetadataBackend represents the metadata backend storage
@@ -101,6 +105,19 @@ type MetadataBackend interface {
 	// saveMetadata saves the pending metadata include namespace/metric metadata
 	saveMetadata(event *metadataUpdateEvent) error
 
+	// saveMetricDescriptor saves the metric descriptor, overwrites the stored descriptor if not inferred,
+	// else only fills the absent attributes of stored descriptor.
+	saveMetricDescriptor(descriptor *models.MetricDescriptor, inferred bool) error
+	// getMetricDescriptor gets the metric descriptor by namespace and metric name,
+	// if not exist return constants.ErrMetricDescriptorNotFound
+	getMetricDescriptor(namespace, metricName string) (*models.MetricDescriptor, error)
+
+	// saveStringValue saves the string value of string field by value id,
+	// if id already exists with different value return constants.ErrStringValueConflict
+	saveStringValue(id uint64, value string) error
+	// getStringValues gets the string values of string field by value ids, absent ids are ignored
+	getStringValues(ids []uint64) (map[uint64]string, error)
+
 	// sync syncs bbolt.DB file data
 	sync() error
 }
@@ -139,7 +156,13 @@ func newMetadataBackend(parent string) (MetadataBackend, error) {
 		}
 		// load tag key id sequence
 		tagKeyIDSequence.Store(uint32(metricBucket.Sequence()))
-		return nil
+		// create descriptor bucket for save metric descriptor
+		if _, err = tx.CreateBucketIfNotExists(descBucketName); err != nil {
+			return err
+		}
+		// create string bucket for save string value dictionary of string field
+		_, err = tx.CreateBucketIfNotExists(strBucketName)
+		return err
 	})
 	if err != nil {
 		// close bbolt.DB if init metadata err
@@ -379,6 +402,87 @@ func (mb *metadataBackend) saveMetadata(event *metadataUpdateEvent) (err error)
 	return
 }
 
+// saveMetricDescriptor saves the metric descriptor under namespace bucket of descriptor bucket,
+// overwrites the stored descriptor if not inferred, else only fills the absent attributes of stored descriptor.
+func (mb *metadataBackend) saveMetricDescriptor(descriptor *models.MetricDescriptor, inferred bool) error {
+	return mb.db.Update(func(tx *bbolt.Tx) error {
+		nsBucket, err := tx.Bucket(descBucketName).CreateBucketIfNotExists([]byte(descriptor.Namespace))
+		if err != nil {
+			return err
+		}
+		if value := nsBucket.Get([]byte(descriptor.Name)); inferred && len(value) > 0 {
+			stored := &models.MetricDescriptor{}
+			if err := encoding.JSONUnmarshal(value, stored); err != nil {
+				return err
+			}
+			if !stored.Fill(descriptor) {
+				return nil
+			}
+			descriptor = stored
+		}
+		return nsBucket.Put([]byte(descriptor.Name), encoding.JSONMarshal(descriptor))
+	})
+}
+
+// getMetricDescriptor gets the metric descriptor by namespace and metric name,
+// if not exist return constants.ErrMetricDescriptorNotFound
+func (mb *metadataBackend) getMetricDescriptor(namespace, metricName string) (descriptor *models.MetricDescriptor, err error) {
+	err = mb.db.View(func(tx *bbolt.Tx) error {
+		var value []byte
+		if nsBucket := tx.Bucket(descBucketName).Bucket([]byte(namespace)); nsBucket != nil {
+			value = nsBucket.Get([]byte(metricName))
+		}
+		if len(value) == 0 {
+			return fmt.Errorf("%w, namepsace: %s, metricName: %s",
+				constants.ErrMetricDescriptorNotFound, namespace, metricName)
+		}
+		descriptor = &models.MetricDescriptor{}
+		return encoding.JSONUnmarshal(value, descriptor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return descriptor, nil
+}
+
+// saveStringValue saves the string value of string field under string bucket by value id,
+// if id already exists with different value return constants.ErrStringValueConflict
+func (mb *metadataBackend) saveStringValue(id uint64, value string) error {
+	return mb.db.Update(func(tx *bbolt.Tx) error {
+		var scratch [8]byte
+		binary.LittleEndian.PutUint64(scratch[:], id)
+		bucket := tx.Bucket(strBucketName)
+		if stored := bucket.Get(scratch[:]); stored != nil {
+			if string(stored) != value {
+				return fmt.Errorf("%w, id: %d, stored: %s, value: %s",
+					constants.ErrStringValueConflict, id, stored, value)
+			}
+			return nil
+		}
+		return bucket.Put(scratch[:], []byte(value))
+	})
+}
+
+// getStringValues gets the string values of string field by value ids, absent ids are ignored
+func (mb *metadataBackend) getStringValues(ids []uint64) (values map[uint64]string, err error) {
+	values = make(map[uint64]string)
+	err = mb.db.View(func(tx *bbolt.Tx) error {
+		var scratch [8]byte
+		bucket := tx.Bucket(strBucketName)
+		for _, id := range ids {
+			binary.LittleEndian.PutUint64(scratch[:], id)
+			if value := bucket.Get(scratch[:]); value != nil {
+				values[id] = string(value)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
 // sync syncs the bbolt.DB file data
 func (mb *metadataBackend) sync() error {
 	return mb.db.Sync()
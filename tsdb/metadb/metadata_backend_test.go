@@ -27,6 +27,7 @@ import (
 	"go.etcd.io/bbolt"
 
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/series/tag"
@@ -362,6 +363,65 @@ func TestMetadataBackend_save_db_err(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestMetadataBackend_metricDescriptor(t *testing.T) {
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	db := newMockMetadataBackend(t)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	_, err := db.getMetricDescriptor("ns", "cpu")
+	assert.True(t, errors.Is(err, constants.ErrMetricDescriptorNotFound))
+
+	// inferred descriptor saved if not exist
+	assert.NoError(t, db.saveMetricDescriptor(&models.MetricDescriptor{
+		Namespace: "ns", Name: "cpu", Type: "gauge", Description: "cpu usage",
+	}, true))
+	// inferred descriptor only fills the absent attributes
+	assert.NoError(t, db.saveMetricDescriptor(&models.MetricDescriptor{
+		Namespace: "ns", Name: "cpu", Unit: "percent", Description: "changed",
+	}, true))
+	descriptor, err := db.getMetricDescriptor("ns", "cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.MetricDescriptor{
+		Namespace: "ns", Name: "cpu", Type: "gauge", Unit: "percent", Description: "cpu usage",
+	}, descriptor)
+	// explicit descriptor overwrites the stored descriptor
+	assert.NoError(t, db.saveMetricDescriptor(&models.MetricDescriptor{
+		Namespace: "ns", Name: "cpu", Unit: "seconds",
+	}, false))
+	descriptor, err = db.getMetricDescriptor("ns", "cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.MetricDescriptor{Namespace: "ns", Name: "cpu", Unit: "seconds"}, descriptor)
+
+	_, err = db.getMetricDescriptor("ns-2", "cpu")
+	assert.Error(t, err)
+	_, err = db.getMetricDescriptor("ns", "memory")
+	assert.Error(t, err)
+}
+
+func TestMetadataBackend_stringValue(t *testing.T) {
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	db := newMockMetadataBackend(t)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	assert.NoError(t, db.saveStringValue(1, "v1.0.0"))
+	assert.NoError(t, db.saveStringValue(1, "v1.0.0"))
+	assert.NoError(t, db.saveStringValue(2, "deploy"))
+	err := db.saveStringValue(1, "v2.0.0")
+	assert.True(t, errors.Is(err, constants.ErrStringValueConflict))
+
+	values, err := db.getStringValues([]uint64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint64]string{1: "v1.0.0", 2: "deploy"}, values)
+}
+
 func TestMetadataBackend_sync(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
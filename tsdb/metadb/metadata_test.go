@@ -37,6 +37,7 @@ func TestNewMetadata(t *testing.T) {
 	metadata1, err := NewMetadata(context.TODO(), "test", testPath, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, metadata1.TagMetadata())
+	assert.NotNil(t, metadata1.TagValueStats())
 	assert.NotNil(t, metadata1.MetadataDatabase())
 	assert.Equal(t, "test", metadata1.DatabaseName())
 	metadata2, err := NewMetadata(context.TODO(), "test", testPath, nil)
@@ -26,8 +26,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cespare/xxhash"
+
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/series"
@@ -57,6 +61,10 @@ var (
 
 const (
 	walPath = "wal"
+	// stringValueIDMask keeps string value id in 52 bits, so that it can be stored as float64 exactly
+	stringValueIDMask = 1<<52 - 1
+	// maxStringValueCache is the max number of saved string value ids cached in memory
+	maxStringValueCache = 100000
 )
 
 // metadataDatabase implements the MetadataDatabase interface,
@@ -68,6 +76,7 @@ type metadataDatabase struct {
 	cancel       context.CancelFunc
 	backend      MetadataBackend
 	metrics      map[string]MetricMetadata // metadata cache(key: namespace + metric-name, value: metric metadata)
+	stringValues map[uint64]struct{}       // saved string value ids of string field
 
 	metaWAL wal.MetricMetaWAL
 
@@ -110,6 +119,7 @@ func NewMetadataDatabase(ctx context.Context, databaseName, parent string) (Meta
 		cancel:               cancel,
 		backend:              backend,
 		metrics:              make(map[string]MetricMetadata),
+		stringValues:         make(map[uint64]struct{}),
 		metaWAL:              metaWAL,
 		syncInterval:         syncInterval,
 		genMetricIDCounter:   genMetricIDCounterVec.WithTagValues(databaseName),
@@ -134,6 +144,46 @@ func (mdb *metadataDatabase) SuggestNamespace(prefix string, limit int) (namespa
 	return mdb.backend.suggestNamespace(prefix, limit)
 }
 
+// SaveMetricDescriptor saves the metric descriptor(unit/description/expected field types),
+// overwrites the stored descriptor if not inferred, else only fills the absent attributes.
+func (mdb *metadataDatabase) SaveMetricDescriptor(descriptor *models.MetricDescriptor, inferred bool) error {
+	return mdb.backend.saveMetricDescriptor(descriptor, inferred)
+}
+
+// GetMetricDescriptor gets the metric descriptor by namespace/metric name,
+// if not exist return constants.ErrMetricDescriptorNotFound
+func (mdb *metadataDatabase) GetMetricDescriptor(namespace, metricName string) (*models.MetricDescriptor, error) {
+	return mdb.backend.getMetricDescriptor(namespace, metricName)
+}
+
+// GenStringValueID generates the value id of string field by hashing the string value,
+// so that same string value has same id on all nodes, saves the string value into dictionary if absent.
+func (mdb *metadataDatabase) GenStringValueID(value string) (uint64, error) {
+	id := xxhash.Sum64String(value) & stringValueIDMask
+	mdb.rwMux.RLock()
+	_, ok := mdb.stringValues[id]
+	mdb.rwMux.RUnlock()
+	if ok {
+		return id, nil
+	}
+	if err := mdb.backend.saveStringValue(id, value); err != nil {
+		return 0, err
+	}
+	mdb.rwMux.Lock()
+	if len(mdb.stringValues) >= maxStringValueCache {
+		// reset cache, string values already saved in backend
+		mdb.stringValues = make(map[uint64]struct{})
+	}
+	mdb.stringValues[id] = struct{}{}
+	mdb.rwMux.Unlock()
+	return id, nil
+}
+
+// GetStringValues returns the string values of string field by value ids, absent ids are ignored
+func (mdb *metadataDatabase) GetStringValues(ids []uint64) (values map[uint64]string, err error) {
+	return mdb.backend.getStringValues(ids)
+}
+
 // SuggestMetricName suggests the metric name by name's prefix
 func (mdb *metadataDatabase) SuggestMetricName(namespace, prefix string, limit int) (metricNames []string, err error) {
 	return mdb.backend.suggestMetricName(namespace, prefix, limit)
@@ -256,6 +306,11 @@ func (mdb *metadataDatabase) GetAllHistogramFields(namespace, metricName string)
 	return mdb.backend.getAllHistogramFields(metricID)
 }
 
+// GetAllFieldsByMetricID returns the all stored fields by metric id, includes the histogram fields.
+func (mdb *metadataDatabase) GetAllFieldsByMetricID(metricID uint32) (fields field.Metas, err error) {
+	return mdb.backend.getAllFields(metricID)
+}
+
 // GenMetricID generates the metric id in the memory.
 // 1) get metric id from memory if exist, if not exist goto 2
 // 2) get metric metadata from backend storage, if not exist need create new metric metadata
@@ -391,6 +446,11 @@ func (mdb *metadataDatabase) Sync() error {
 	return nil
 }
 
+// SetSyncPolicy sets the fsync policy of metadata write ahead log
+func (mdb *metadataDatabase) SetSyncPolicy(policy bufioutil.SyncPolicy) {
+	mdb.metaWAL.SetSyncPolicy(policy)
+}
+
 // Close closes the resources
 func (mdb *metadataDatabase) Close() error {
 	mdb.cancel()
@@ -29,6 +29,7 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/models"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/series"
@@ -109,6 +110,68 @@ func TestMetadataDatabase_SuggestNamespace(t *testing.T) {
 	_ = db.Close()
 }
 
+func TestMetadataDatabase_MetricDescriptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		createMetadataBackend = newMetadataBackend
+		_ = fileutil.RemoveDir(testPath)
+
+		ctrl.Finish()
+	}()
+	mockBackend := NewMockMetadataBackend(ctrl)
+	createMetadataBackend = func(parent string) (backend MetadataBackend, err error) {
+		return mockBackend, nil
+	}
+	db, err := NewMetadataDatabase(context.TODO(), "test", testPath)
+	assert.NoError(t, err)
+	descriptor := &models.MetricDescriptor{Namespace: "ns", Name: "cpu", Unit: "percent"}
+	mockBackend.EXPECT().saveMetricDescriptor(descriptor, true).Return(nil)
+	assert.NoError(t, db.SaveMetricDescriptor(descriptor, true))
+	mockBackend.EXPECT().getMetricDescriptor("ns", "cpu").Return(descriptor, nil)
+	result, err := db.GetMetricDescriptor("ns", "cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, descriptor, result)
+
+	mockBackend.EXPECT().Close().Return(nil)
+	_ = db.Close()
+}
+
+func TestMetadataDatabase_StringValue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		createMetadataBackend = newMetadataBackend
+		_ = fileutil.RemoveDir(testPath)
+
+		ctrl.Finish()
+	}()
+	mockBackend := NewMockMetadataBackend(ctrl)
+	createMetadataBackend = func(parent string) (backend MetadataBackend, err error) {
+		return mockBackend, nil
+	}
+	db, err := NewMetadataDatabase(context.TODO(), "test", testPath)
+	assert.NoError(t, err)
+	// case 1: save string value fail
+	mockBackend.EXPECT().saveStringValue(gomock.Any(), "v1.0.0").Return(fmt.Errorf("err"))
+	_, err = db.GenStringValueID("v1.0.0")
+	assert.Error(t, err)
+	// case 2: save string value, then hit cache
+	mockBackend.EXPECT().saveStringValue(gomock.Any(), "v1.0.0").Return(nil)
+	id, err := db.GenStringValueID("v1.0.0")
+	assert.NoError(t, err)
+	assert.True(t, id <= stringValueIDMask)
+	id2, err := db.GenStringValueID("v1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, id, id2)
+	// case 3: get string values
+	mockBackend.EXPECT().getStringValues([]uint64{id}).Return(map[uint64]string{id: "v1.0.0"}, nil)
+	values, err := db.GetStringValues([]uint64{id})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint64]string{id: "v1.0.0"}, values)
+
+	mockBackend.EXPECT().Close().Return(nil)
+	_ = db.Close()
+}
+
 func TestMetadataDatabase_SuggestMetricName(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
@@ -334,6 +397,11 @@ func TestMetadataDatabase_GetField(t *testing.T) {
 	fields, err = db.GetAllFields("ns-1", "name2")
 	assert.NoError(t, err)
 	assert.Equal(t, []field.Meta{{ID: 19, Type: field.SumField}}, fields)
+	// case 8: get fields by metric id
+	mockBackend.EXPECT().getAllFields(uint32(10)).Return([]field.Meta{{ID: 19, Type: field.SumField}}, nil)
+	fields, err = db.GetAllFieldsByMetricID(10)
+	assert.NoError(t, err)
+	assert.Equal(t, []field.Meta{{ID: 19, Type: field.SumField}}, fields)
 
 	mockBackend.EXPECT().saveMetadata(gomock.Any()).AnyTimes()
 	mockBackend.EXPECT().Close().Return(nil)
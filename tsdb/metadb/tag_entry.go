@@ -19,12 +19,15 @@ package metadb
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/lindb/roaring"
 	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/sql/stmt"
+	"github.com/lindb/lindb/tsdb/tblstore/tagkeymeta"
 )
 
 // TagEntry represents the tag value=>id under tag key
@@ -51,6 +54,10 @@ type TagEntry interface {
 type tagEntry struct {
 	tagValueSeq atomic.Uint32
 	tagValues   map[string]uint32
+
+	// numeric index of tag values, built when first range filter query
+	numericIndex *tagkeymeta.NumericIndex
+	indexMutex   sync.Mutex
 }
 
 // newTagEntry creates tag entry with tag value id auto sequence
@@ -75,6 +82,10 @@ func (t *tagEntry) getTagValueIDSeq() uint32 {
 // addTagValue adds tag value=>id mapping
 func (t *tagEntry) addTagValue(tagValue string, tagValueID uint32) {
 	t.tagValues[tagValue] = tagValueID
+	// add tag value is under write lock of tag metadata, no concurrent query for numeric index
+	if t.numericIndex != nil {
+		t.numericIndex.Add(tagValue, tagValueID)
+	}
 }
 
 // getTagValueIDs returns all tag value ids under the tag key
@@ -108,6 +119,8 @@ func (t *tagEntry) findSeriesIDsByExpr(expr stmt.TagFilter) *roaring.Bitmap {
 		return t.findSeriesIDsByLike(expression)
 	case *stmt.RegexExpr:
 		return t.findSeriesIDsByRegex(expression)
+	case *stmt.RangeExpr:
+		return t.findSeriesIDsByRange(expression)
 	}
 	metaLogger.Warn("expr type is not tag filter when find tag value ids by expr")
 	return nil
@@ -203,6 +216,21 @@ func (t *tagEntry) findSeriesIDsByRegex(expr *stmt.RegexExpr) *roaring.Bitmap {
 	return result
 }
 
+// findSeriesIDsByRange finds tag value ids by numeric tag value - range
+func (t *tagEntry) findSeriesIDsByRange(expr *stmt.RangeExpr) *roaring.Bitmap {
+	value, err := strconv.ParseFloat(expr.Value, 64)
+	if err != nil {
+		return nil
+	}
+	t.indexMutex.Lock()
+	defer t.indexMutex.Unlock()
+
+	if t.numericIndex == nil {
+		t.numericIndex = tagkeymeta.NewNumericIndexFrom(t.tagValues)
+	}
+	return roaring.BitmapOf(t.numericIndex.FindTagValueIDs(expr.Op, value)...)
+}
+
 // collectTagValues collects the tag values by tag value ids,
 func (t *tagEntry) collectTagValues(tagValueIDs *roaring.Bitmap, tagValues map[uint32]string) {
 	for value, tagValueID := range t.tagValues {
@@ -105,6 +105,22 @@ func TestTagEntry_findSeriesIDsByRegex(t *testing.T) {
 	assert.Equal(t, roaring.New(), tagIndex.findSeriesIDsByExpr(&stmt.RegexExpr{Key: "host", Regexp: `22+`}))
 }
 
+func TestTagEntry_findSeriesIDsByRange(t *testing.T) {
+	tagIndex := prepareTagEntry()
+	tagIndex.addTagValue("100", 9)
+	tagIndex.addTagValue("20", 10)
+	// value not numeric
+	assert.Nil(t, tagIndex.findSeriesIDsByExpr(&stmt.RangeExpr{Key: "host", Op: stmt.GreaterThan, Value: "a"}))
+	assert.Equal(t, roaring.BitmapOf(9),
+		tagIndex.findSeriesIDsByExpr(&stmt.RangeExpr{Key: "host", Op: stmt.GreaterThan, Value: "20"}))
+	// new tag value added after numeric index built
+	tagIndex.addTagValue("50", 11)
+	assert.Equal(t, roaring.BitmapOf(10, 11),
+		tagIndex.findSeriesIDsByExpr(&stmt.RangeExpr{Key: "host", Op: stmt.LessEqual, Value: "50"}))
+	assert.Equal(t, roaring.New(),
+		tagIndex.findSeriesIDsByExpr(&stmt.RangeExpr{Key: "host", Op: stmt.LessThan, Value: "20"}))
+}
+
 func TestTagEntry_collectTagValues(t *testing.T) {
 	tagIndex := prepareTagEntry()
 	tagValueIDs := roaring.BitmapOf(1, 2, 3, 100)
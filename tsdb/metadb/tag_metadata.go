@@ -43,6 +43,9 @@ var (
 type TagMetadata interface {
 	// GenTagValueID generates the tag value id for spec tag key
 	GenTagValueID(tagKeyID uint32, tagValue string) (uint32, error)
+	// GenTagValueIDs generates the tag value ids for a batch of tag values under spec tag key,
+	// kv store is searched once and new ids are allocated under one lock.
+	GenTagValueIDs(tagKeyID uint32, tagValues []string) ([]uint32, error)
 	// SuggestTagValues returns suggestions from given tag key id and prefix of tag value
 	SuggestTagValues(tagKeyID uint32, tagValuePrefix string, limit int) []string
 	// FindTagValueDsByExpr finds tag value ids by tag filter expr for spec tag key,
@@ -125,21 +128,9 @@ func (m *tagMetadata) GenTagValueID(tagKeyID uint32, tagValue string) (tagValueI
 	}
 
 	// assign new tag value id
-	tag, ok := m.mutable.Get(tagKeyID)
-	if !ok {
-		if reader != nil {
-			// if tag data exist in kv store, need load tag value id auto sequence
-			seq, err := reader.GetTagValueSeq(tagKeyID)
-			if err != nil {
-				return 0, err
-			}
-			tag = newTagEntry(seq)
-		} else {
-			// for new tag, auto sequence start with 1
-			tag = newTagEntry(0)
-		}
-		// cache tag entry
-		m.mutable.Put(tagKeyID, tag)
+	tag, err := m.getOrCreateTagEntry(tagKeyID, reader)
+	if err != nil {
+		return 0, err
 	}
 
 	// assign new id
@@ -150,6 +141,101 @@ func (m *tagMetadata) GenTagValueID(tagKeyID uint32, tagValue string) (tagValueI
 	return tagValueID, nil
 }
 
+// GenTagValueIDs generates the tag value ids for a batch of tag values under spec tag key,
+// kv store is searched once and new ids are allocated under one lock.
+func (m *tagMetadata) GenTagValueIDs(tagKeyID uint32, tagValues []string) ([]uint32, error) {
+	tagValueIDs := make([]uint32, len(tagValues))
+	// index of tag values which not found
+	var missing []int
+
+	// get tag value ids from memory with read lock
+	m.rwMutex.RLock()
+	for idx, tagValue := range tagValues {
+		tagValueID, ok := m.getTagValueIDInMem(tagKeyID, tagValue)
+		if ok {
+			tagValueIDs[idx] = tagValueID
+		} else {
+			missing = append(missing, idx)
+		}
+	}
+	m.rwMutex.RUnlock()
+	if len(missing) == 0 {
+		return tagValueIDs, nil
+	}
+
+	// try load tag value ids from kv store
+	snapshot := m.family.GetSnapshot()
+	defer snapshot.Close()
+
+	readers, err := snapshot.FindReaders(tagKeyID)
+	if err != nil {
+		return nil, err
+	}
+	var reader tagkeymeta.Reader
+	if len(readers) > 0 {
+		reader = newTagReaderFunc(readers)
+		notFound := missing[:0]
+		for _, idx := range missing {
+			tagValueID, err := reader.GetTagValueID(tagKeyID, tagValues[idx])
+			switch {
+			case err == nil:
+				tagValueIDs[idx] = tagValueID
+			case errors.Is(err, constants.ErrNotFound):
+				notFound = append(notFound, idx)
+			default:
+				return nil, err
+			}
+		}
+		missing = notFound
+		if len(missing) == 0 {
+			return tagValueIDs, nil
+		}
+	}
+
+	// assign new tag value ids for not exist tag values with write lock
+	m.rwMutex.Lock()
+	defer m.rwMutex.Unlock()
+
+	tag, err := m.getOrCreateTagEntry(tagKeyID, reader)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range missing {
+		tagValue := tagValues[idx]
+		// double check, tag value maybe assigned by other writer or duplicated in batch
+		tagValueID, ok := m.getTagValueIDInMem(tagKeyID, tagValue)
+		if !ok {
+			tagValueID = tag.genTagValueID()
+			tag.addTagValue(tagValue, tagValueID)
+		}
+		tagValueIDs[idx] = tagValueID
+	}
+	return tagValueIDs, nil
+}
+
+// getOrCreateTagEntry returns the tag entry of mutable store, creates it if not exist,
+// must be called with write lock.
+func (m *tagMetadata) getOrCreateTagEntry(tagKeyID uint32, reader tagkeymeta.Reader) (TagEntry, error) {
+	tag, ok := m.mutable.Get(tagKeyID)
+	if ok {
+		return tag, nil
+	}
+	if reader != nil {
+		// if tag data exist in kv store, need load tag value id auto sequence
+		seq, err := reader.GetTagValueSeq(tagKeyID)
+		if err != nil {
+			return nil, err
+		}
+		tag = newTagEntry(seq)
+	} else {
+		// for new tag, auto sequence start with 1
+		tag = newTagEntry(0)
+	}
+	// cache tag entry
+	m.mutable.Put(tagKeyID, tag)
+	return tag, nil
+}
+
 // SuggestTagValues returns suggestions from given tag key id and prefix of tag value
 func (m *tagMetadata) SuggestTagValues(tagKeyID uint32, tagValuePrefix string, limit int) []string {
 	result := make([]string, 0)
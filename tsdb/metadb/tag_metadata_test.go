@@ -102,6 +102,65 @@ func TestTagMetadata_GenTagValueID(t *testing.T) {
 	assert.Equal(t, uint32(22), tagValueID)
 }
 
+func TestTagMetadata_GenTagValueIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newTagReaderFunc = tagkeymeta.NewReader
+		ctrl.Finish()
+	}()
+
+	meta, _, snapshot := mockTagMetadata(ctrl)
+
+	tagReader := tagkeymeta.NewMockReader(ctrl)
+	newTagReaderFunc = func(readers []table.Reader) tagkeymeta.Reader {
+		return tagReader
+	}
+
+	// case 1: gen tag value ids, duplicated tag value in batch
+	snapshot.EXPECT().FindReaders(uint32(1)).Return(nil, nil)
+	tagValueIDs, err := meta.GenTagValueIDs(1, []string{"a", "b", "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1, 2, 1}, tagValueIDs)
+	// case 2: get tag value ids from mem
+	tagValueIDs, err = meta.GenTagValueIDs(1, []string{"b", "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{2, 1}, tagValueIDs)
+	// case 3: get kv readers err
+	snapshot.EXPECT().FindReaders(uint32(1)).Return(nil, fmt.Errorf("err"))
+	tagValueIDs, err = meta.GenTagValueIDs(1, []string{"a", "c"})
+	assert.Error(t, err)
+	assert.Nil(t, tagValueIDs)
+	// case 4: get tag value ids from kv store
+	snapshot.EXPECT().FindReaders(gomock.Any()).Return([]table.Reader{table.NewMockReader(ctrl)}, nil).AnyTimes()
+	tagReader.EXPECT().GetTagValueID(uint32(1), "c").Return(uint32(10), nil)
+	tagValueIDs, err = meta.GenTagValueIDs(1, []string{"a", "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1, 10}, tagValueIDs)
+	// case 5: get tag value id from kv store err
+	tagReader.EXPECT().GetTagValueID(uint32(1), "d").Return(uint32(0), fmt.Errorf("err"))
+	tagValueIDs, err = meta.GenTagValueIDs(1, []string{"d"})
+	assert.Error(t, err)
+	assert.Nil(t, tagValueIDs)
+	// case 6: init tag entry from kv store err
+	tagReader.EXPECT().GetTagValueID(uint32(5), "e").Return(uint32(0), constants.ErrNotFound)
+	tagReader.EXPECT().GetTagValueSeq(uint32(5)).Return(uint32(0), fmt.Errorf("err"))
+	tagValueIDs, err = meta.GenTagValueIDs(5, []string{"e"})
+	assert.Error(t, err)
+	assert.Nil(t, tagValueIDs)
+	// case 7: allocate new tag value ids with kv store sequence
+	tagReader.EXPECT().GetTagValueID(uint32(5), "e").Return(uint32(0), constants.ErrNotFound)
+	tagReader.EXPECT().GetTagValueID(uint32(5), "f").Return(uint32(3), nil)
+	tagReader.EXPECT().GetTagValueID(uint32(5), "g").Return(uint32(0), constants.ErrNotFound)
+	tagReader.EXPECT().GetTagValueSeq(uint32(5)).Return(uint32(20), nil)
+	tagValueIDs, err = meta.GenTagValueIDs(5, []string{"e", "f", "g"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{21, 3, 22}, tagValueIDs)
+	// case 8: ids allocated in batch can be found by single generation
+	tagValueID, err := meta.GenTagValueID(5, "g")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(22), tagValueID)
+}
+
 func TestTagMetadata_SuggestTagValues(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer func() {
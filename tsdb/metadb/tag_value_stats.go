@@ -0,0 +1,237 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metadb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/cms"
+	"github.com/lindb/lindb/pkg/fasttime"
+	"github.com/lindb/lindb/series/tag"
+)
+
+//go:generate mockgen -source ./tag_value_stats.go -destination=./tag_value_stats_mock.go -package metadb
+
+// for testing
+var (
+	nowFunc = fasttime.UnixMilliseconds
+)
+
+const (
+	// tagValueStatsInterval is the time span(millisecond) of each bucket.
+	tagValueStatsInterval = 5 * time.Minute / time.Millisecond
+	// tagValueStatsBuckets is the number of buckets, max window is 1 hour.
+	tagValueStatsBuckets = 12
+	// tagValueStatsCandidates is the max number of heavy hitter candidates of tag key in each bucket.
+	tagValueStatsCandidates = 64
+	// count-min sketch size of each bucket, 4*16384 counters(256KB).
+	tagValueSketchDepth = 4
+	tagValueSketchWidth = 1 << 14
+	// MaxTagValueStatsWindow is the max window of tag value stats.
+	MaxTagValueStatsWindow = time.Duration(tagValueStatsInterval*tagValueStatsBuckets) * time.Millisecond
+)
+
+// TagValueStats tracks the created series/written points of tag values by count-min sketch in sliding window,
+// then the heavy hitter tag values which explode cardinality of metric can be found.
+type TagValueStats interface {
+	// Record records a written point of series, isCreated is true if the series is created by this point.
+	Record(namespace, metricName string, tags tag.KeyValues, isCreated bool)
+	// TopK returns the top-k tag values of tag key by created series or written points in the window,
+	// the counts are estimated which are never less than the real counts.
+	TopK(namespace, metricName, tagKey string, bySeries bool, window time.Duration, k int) []models.TagValueCount
+}
+
+// tagValueCandidates represents the heavy hitter candidates of tag key in bucket.
+type tagValueCandidates struct {
+	counts map[string]*uint32 // tag value => estimated count, pointer avoids allocating key when updating
+	min    uint32             // lower bound of min count when candidates are full
+}
+
+// offer offers the tag value with estimated count, keeps the tag values with the largest counts.
+func (c *tagValueCandidates) offer(tagValue []byte, count uint32) {
+	if current, ok := c.counts[string(tagValue)]; ok {
+		*current = count
+		return
+	}
+	if len(c.counts) < tagValueStatsCandidates {
+		c.counts[string(tagValue)] = newCount(count)
+		if len(c.counts) == 1 || count < c.min {
+			c.min = count
+		}
+		return
+	}
+	if count <= c.min {
+		return
+	}
+	// find the min candidate, because counts of candidates may be increased after min is set
+	minValue, minCount := "", ^uint32(0)
+	for value, valueCount := range c.counts {
+		if *valueCount < minCount {
+			minValue, minCount = value, *valueCount
+		}
+	}
+	if count > minCount {
+		delete(c.counts, minValue)
+		c.counts[string(tagValue)] = newCount(count)
+		minCount = count
+		for _, valueCount := range c.counts {
+			if *valueCount < minCount {
+				minCount = *valueCount
+			}
+		}
+	}
+	c.min = minCount
+}
+
+// newCount returns the pointer of count for new candidate.
+func newCount(count uint32) *uint32 {
+	return &count
+}
+
+// tagValueStatsBucket represents the tag value stats in a time span.
+type tagValueStatsBucket struct {
+	timestamp  int64 // start time of bucket
+	series     *cms.Sketch
+	points     *cms.Sketch
+	candidates map[string]*[2]tagValueCandidates // tag key => candidates of series/points
+}
+
+// tagValueStats implements TagValueStats interface.
+type tagValueStats struct {
+	buckets [tagValueStatsBuckets]*tagValueStatsBucket
+	key     []byte // reused buffer of sketch key
+
+	mutex sync.Mutex
+}
+
+// NewTagValueStats creates the tag value stats.
+func NewTagValueStats() TagValueStats {
+	return &tagValueStats{}
+}
+
+// Record records a written point of series, isCreated is true if the series is created by this point.
+func (s *tagValueStats) Record(namespace, metricName string, tags tag.KeyValues, isCreated bool) {
+	if len(tags) == 0 {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	bucket := s.getBucket(nowFunc())
+	for _, kv := range tags {
+		s.key = appendTagKey(s.key[:0], namespace, metricName, kv.Key)
+		tagKeyLen := len(s.key)
+		s.key = append(s.key, kv.Value...)
+
+		candidates, ok := bucket.candidates[string(s.key[:tagKeyLen])]
+		if !ok {
+			candidates = &[2]tagValueCandidates{
+				{counts: make(map[string]*uint32)},
+				{counts: make(map[string]*uint32)},
+			}
+			bucket.candidates[string(s.key[:tagKeyLen])] = candidates
+		}
+		tagValue := s.key[tagKeyLen:]
+		if isCreated {
+			candidates[0].offer(tagValue, bucket.series.Add(s.key, 1))
+		}
+		candidates[1].offer(tagValue, bucket.points.Add(s.key, 1))
+	}
+}
+
+// TopK returns the top-k tag values of tag key by created series or written points in the window.
+func (s *tagValueStats) TopK(namespace, metricName, tagKey string,
+	bySeries bool, window time.Duration, k int,
+) []models.TagValueCount {
+	numOfBuckets := int((int64(window/time.Millisecond) + int64(tagValueStatsInterval) - 1) / int64(tagValueStatsInterval))
+	if numOfBuckets <= 0 || numOfBuckets > tagValueStatsBuckets {
+		numOfBuckets = tagValueStatsBuckets
+	}
+	kind := 1
+	if bySeries {
+		kind = 0
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := nowFunc() / int64(tagValueStatsInterval) * int64(tagValueStatsInterval)
+	var buckets []*tagValueStatsBucket
+	for _, bucket := range s.buckets {
+		if bucket != nil && bucket.timestamp > current-int64(numOfBuckets)*int64(tagValueStatsInterval) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	key := appendTagKey(nil, namespace, metricName, tagKey)
+	tagKeyLen := len(key)
+	counts := make(map[string]uint64)
+	for _, bucket := range buckets {
+		candidates, ok := bucket.candidates[string(key)]
+		if !ok {
+			continue
+		}
+		for tagValue := range candidates[kind].counts {
+			counts[tagValue] = 0
+		}
+	}
+	for tagValue := range counts {
+		key = append(key[:tagKeyLen], tagValue...)
+		var count uint64
+		for _, bucket := range buckets {
+			sketch := bucket.points
+			if bySeries {
+				sketch = bucket.series
+			}
+			count += uint64(sketch.Count(key))
+		}
+		counts[tagValue] = count
+	}
+	return models.SortTagValueCounts(counts, k)
+}
+
+// getBucket returns the bucket of timestamp, resets the expired bucket.
+func (s *tagValueStats) getBucket(timestamp int64) *tagValueStatsBucket {
+	bucketTime := timestamp / int64(tagValueStatsInterval) * int64(tagValueStatsInterval)
+	idx := (bucketTime / int64(tagValueStatsInterval)) % tagValueStatsBuckets
+	bucket := s.buckets[idx]
+	if bucket == nil {
+		bucket = &tagValueStatsBucket{
+			series: cms.New(tagValueSketchDepth, tagValueSketchWidth),
+			points: cms.New(tagValueSketchDepth, tagValueSketchWidth),
+		}
+		s.buckets[idx] = bucket
+	}
+	if bucket.timestamp != bucketTime || bucket.candidates == nil {
+		bucket.timestamp = bucketTime
+		bucket.series.Reset()
+		bucket.points.Reset()
+		bucket.candidates = make(map[string]*[2]tagValueCandidates)
+	}
+	return bucket
+}
+
+// appendTagKey appends the key of tag key(namespace/metric name/tag key) into buf.
+func appendTagKey(buf []byte, namespace, metricName, tagKey string) []byte {
+	buf = append(buf, namespace...)
+	buf = append(buf, 0)
+	buf = append(buf, metricName...)
+	buf = append(buf, 0)
+	buf = append(buf, tagKey...)
+	return append(buf, 0)
+}
@@ -0,0 +1,118 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metadb
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/models"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series/tag"
+)
+
+func TestTagValueStats_TopK(t *testing.T) {
+	now := int64(1000 * tagValueStatsInterval)
+	defer func() {
+		nowFunc = func() int64 { return time.Now().UnixNano() / int64(time.Millisecond) }
+	}()
+	nowFunc = func() int64 { return now }
+
+	stats := NewTagValueStats()
+	tags := func(host, region string) tag.KeyValues {
+		return tag.KeyValues{
+			{Key: "host", Value: host},
+			{Key: "region", Value: region},
+		}
+	}
+	// no tags
+	stats.Record("ns", "cpu", nil, true)
+	assert.Empty(t, stats.TopK("ns", "cpu", "host", false, time.Hour, 10))
+
+	for i := 0; i < 1000; i++ {
+		// host-0 is the heavy hitter of written points
+		stats.Record("ns", "cpu", tags("host-0", "sh"), false)
+		// all series created in region bj
+		stats.Record("ns", "cpu", tags("host-"+strconv.Itoa(i), "bj"), true)
+	}
+	topK := stats.TopK("ns", "cpu", "host", false, time.Hour, 2)
+	assert.Len(t, topK, 2)
+	assert.Equal(t, "host-0", topK[0].TagValue)
+	assert.GreaterOrEqual(t, topK[0].Count, uint64(1001))
+	topK = stats.TopK("ns", "cpu", "region", true, 0, 10)
+	assert.Equal(t, []models.TagValueCount{{TagValue: "bj", Count: 1000}}, topK)
+	topK = stats.TopK("ns", "cpu", "region", false, time.Minute, 10)
+	assert.Equal(t, []models.TagValueCount{{TagValue: "bj", Count: 1000}, {TagValue: "sh", Count: 1000}}, topK)
+	// other metric
+	assert.Empty(t, stats.TopK("ns", "mem", "region", false, time.Hour, 10))
+
+	// next bucket
+	now += int64(tagValueStatsInterval)
+	stats.Record("ns", "cpu", tags("host-1", "sh"), false)
+	topK = stats.TopK("ns", "cpu", "region", false, time.Minute, 10)
+	assert.Equal(t, []models.TagValueCount{{TagValue: "sh", Count: 1}}, topK)
+	topK = stats.TopK("ns", "cpu", "region", false, 10*time.Minute, 10)
+	assert.Equal(t, []models.TagValueCount{{TagValue: "sh", Count: 1001}, {TagValue: "bj", Count: 1000}}, topK)
+
+	// bucket expired and reused
+	now += int64(tagValueStatsInterval) * (tagValueStatsBuckets - 1)
+	stats.Record("ns", "cpu", tags("host-2", "gz"), false)
+	topK = stats.TopK("ns", "cpu", "region", false, MaxTagValueStatsWindow, 10)
+	assert.Equal(t, []models.TagValueCount{{TagValue: "gz", Count: 1}, {TagValue: "sh", Count: 1}}, topK)
+}
+
+func TestTagValueCandidates_offer(t *testing.T) {
+	c := &tagValueCandidates{counts: make(map[string]*uint32)}
+	for i := 0; i < tagValueStatsCandidates; i++ {
+		c.offer([]byte(strconv.Itoa(i)), uint32(i+10))
+	}
+	assert.Equal(t, uint32(10), c.min)
+	// update exist candidate
+	c.offer([]byte("0"), 30)
+	assert.Equal(t, uint32(30), *c.counts["0"])
+	// less than min
+	c.offer([]byte("a"), 5)
+	assert.NotContains(t, c.counts, "a")
+	// replace min candidate
+	c.offer([]byte("b"), 100)
+	assert.Contains(t, c.counts, "b")
+	assert.NotContains(t, c.counts, "1")
+	assert.Equal(t, uint32(12), c.min)
+	// stale min, but not larger than real min
+	*c.counts["2"] = 50
+	c.offer([]byte("c"), 13)
+	assert.NotContains(t, c.counts, "c")
+	assert.Equal(t, uint32(13), c.min)
+	assert.Len(t, c.counts, tagValueStatsCandidates)
+}
+
+func BenchmarkTagValueStats_Record(b *testing.B) {
+	stats := NewTagValueStats()
+	tags := tag.KeyValues{
+		&protoMetricsV1.KeyValue{Key: "host", Value: "host-1"},
+		&protoMetricsV1.KeyValue{Key: "region", Value: "sh"},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stats.Record("ns", "cpu", tags, false)
+	}
+}
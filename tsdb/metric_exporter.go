@@ -0,0 +1,346 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/lindb/roaring"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/flow"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/series/tag"
+)
+
+// exportValue represents the value of field at one timestamp.
+type exportValue struct {
+	value     float64
+	intValue  int64 // int/bool value decoded natively
+	valueType field.ValueType
+	ok        bool
+}
+
+// aggregate aggregates other value into value, int/bool values are aggregated natively if both are int/bool.
+func (v *exportValue) aggregate(aggFunc field.AggFunc, other exportValue) {
+	if v.valueType != field.FloatValue && other.valueType != field.FloatValue {
+		v.intValue = aggFunc.AggregateInt(v.intValue, other.intValue)
+		v.value = float64(v.intValue)
+		v.valueType = v.valueType.Merge(other.valueType).Aggregated(aggFunc.AggType())
+		return
+	}
+	v.value = aggFunc.Aggregate(v.value, other.value)
+	v.valueType = field.FloatValue
+}
+
+// Export scans the raw data points of metric within time range from memory databases and data families,
+// calls fn with the data point of each series/timestamp, data points of one series are sorted by timestamp.
+// Only sum and gauge fields are exported, returns nil if metric not exist in shard.
+func (s *shard) Export(
+	namespace, metricName string,
+	timeRange timeutil.TimeRange,
+	fn func(metric *protoMetricsV1.Metric) error,
+) error {
+	metadataDB := s.metadata.MetadataDatabase()
+	metricID, err := metadataDB.GetMetricID(namespace, metricName)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	allFields, err := metadataDB.GetAllFields(namespace, metricName)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	var fields field.Metas
+	for _, f := range allFields {
+		if exportFieldType(f.Type) != protoMetricsV1.SimpleFieldType_SIMPLE_UNSPECIFIED {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	// loader returns field data in order of field id
+	sort.Slice(fields, func(i, j int) bool { return fields[i].ID < fields[j].ID })
+	tagKeys, err := metadataDB.GetAllTagKeys(namespace, metricName)
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		return err
+	}
+	sort.Slice(tagKeys, func(i, j int) bool { return tagKeys[i].Key < tagKeys[j].Key })
+	seriesIDs, err := s.indexDB.GetSeriesIDsForMetric(namespace, metricName)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	if seriesIDs == nil || seriesIDs.IsEmpty() {
+		return nil
+	}
+	resultSets, err := s.filterForExport(metricID, seriesIDs, timeRange, fields)
+	defer func() {
+		for _, rs := range resultSets {
+			rs.Close()
+		}
+	}()
+	if err != nil || len(resultSets) == 0 {
+		return err
+	}
+	groupingCtxs := make([]series.GroupingContext, len(tagKeys))
+	for idx, tagKey := range tagKeys {
+		groupingCtx, err := s.indexDB.GetGroupingContext([]uint32{tagKey.ID}, seriesIDs)
+		if err != nil && !errors.Is(err, constants.ErrNotFound) {
+			return err
+		}
+		groupingCtxs[idx] = groupingCtx
+	}
+
+	decoder := encoding.GetTSDDecoder()
+	defer encoding.ReleaseTSDDecoder(decoder)
+
+	highKeys := seriesIDs.GetHighKeys()
+	for highKeyIdx, highKey := range highKeys {
+		container := seriesIDs.GetContainerAtIndex(highKeyIdx)
+		seriesTags, err := s.collectSeriesTags(tagKeys, groupingCtxs, highKey, container)
+		if err != nil {
+			return err
+		}
+		loaders := make([]flow.DataLoader, len(resultSets))
+		for idx, rs := range resultSets {
+			loaders[idx] = rs.Load(highKey, container)
+		}
+		it := container.PeekableIterator()
+		for it.HasNext() {
+			lowSeriesID := it.Next()
+			points := make(map[int64][]exportValue)
+			for idx, loader := range loaders {
+				if loader == nil {
+					continue
+				}
+				slotRange, fieldsData := loader.Load(lowSeriesID)
+				if err := s.decodeExportPoints(decoder, resultSets[idx].FamilyTime(),
+					slotRange, fieldsData, fields, timeRange, points); err != nil {
+					return err
+				}
+			}
+			if err := emitExportPoints(namespace, metricName, seriesTags[lowSeriesID], fields, points, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// filterForExport filters the memory databases and data families which overlap the time range.
+func (s *shard) filterForExport(
+	metricID uint32,
+	seriesIDs *roaring.Bitmap,
+	timeRange timeutil.TimeRange,
+	fields field.Metas,
+) (resultSets []flow.FilterResultSet, err error) {
+	for _, family := range s.GetDataFamilies(s.interval.Type(), timeRange) {
+		rs, err := family.Filter(metricID, seriesIDs, timeRange, fields)
+		if err != nil {
+			return resultSets, err
+		}
+		resultSets = append(resultSets, rs...)
+	}
+	// memory database after data files, so that the latest gauge value wins
+	for _, entry := range s.families.Entries() {
+		if entry.familyTime > timeRange.End || s.intervalCalc.CalcFamilyEndTime(entry.familyTime) < timeRange.Start {
+			continue
+		}
+		rs, err := entry.memDB.Filter(metricID, seriesIDs, timeRange, fields)
+		if err != nil {
+			return resultSets, err
+		}
+		resultSets = append(resultSets, rs...)
+	}
+	return resultSets, nil
+}
+
+// collectSeriesTags collects the tags of series under the high key, returns low series id => tags mapping.
+func (s *shard) collectSeriesTags(
+	tagKeys []tag.Meta,
+	groupingCtxs []series.GroupingContext,
+	highKey uint16,
+	container roaring.Container,
+) (map[uint16][]*protoMetricsV1.KeyValue, error) {
+	seriesTags := make(map[uint16][]*protoMetricsV1.KeyValue)
+	for idx, tagKey := range tagKeys {
+		groupingCtx := groupingCtxs[idx]
+		if groupingCtx == nil {
+			continue
+		}
+		// group key is the little endian encoded tag value id when group by one tag key
+		groups := groupingCtx.BuildGroup(highKey, container)
+		if len(groups) == 0 {
+			continue
+		}
+		tagValueIDs := roaring.New()
+		for groupKey := range groups {
+			tagValueIDs.Add(binary.LittleEndian.Uint32([]byte(groupKey)))
+		}
+		tagValues := make(map[uint32]string)
+		if err := s.metadata.TagMetadata().CollectTagValues(tagKey.ID, tagValueIDs, tagValues); err != nil {
+			return nil, err
+		}
+		for groupKey, lowSeriesIDs := range groups {
+			tagValue, ok := tagValues[binary.LittleEndian.Uint32([]byte(groupKey))]
+			if !ok {
+				continue
+			}
+			for _, lowSeriesID := range lowSeriesIDs {
+				seriesTags[lowSeriesID] = append(seriesTags[lowSeriesID],
+					&protoMetricsV1.KeyValue{Key: tagKey.Key, Value: tagValue})
+			}
+		}
+	}
+	return seriesTags, nil
+}
+
+// decodeExportPoints decodes the field data of one result set, merges the values into points by timestamp.
+func (s *shard) decodeExportPoints(
+	decoder *encoding.TSDDecoder,
+	familyTime int64,
+	slotRange timeutil.SlotRange,
+	fieldsData [][]byte,
+	fields field.Metas,
+	timeRange timeutil.TimeRange,
+	points map[int64][]exportValue,
+) error {
+	interval := s.interval.Int64()
+	for fieldIdx, data := range fieldsData {
+		if len(data) == 0 || fieldIdx >= len(fields) {
+			continue
+		}
+		aggFunc := fields[fieldIdx].Type.GetAggFunc()
+		if fields[fieldIdx].Type == field.GaugeField {
+			aggFunc = field.LastValue.AggFunc()
+		}
+		if err := decoder.ResetTSDBlock(data, slotRange.Start, slotRange.End); err != nil {
+			return err
+		}
+		for slot := int(slotRange.Start); slot <= int(slotRange.End); slot++ {
+			if !decoder.HasValueWithSlot(uint16(slot)) {
+				continue
+			}
+			value := exportValue{ok: true}
+			switch decoder.Codec() {
+			case encoding.TSDCodecInt:
+				value.intValue, value.valueType = decoder.IntValue(), field.IntValue
+				value.value = float64(value.intValue)
+			case encoding.TSDCodecBool:
+				value.intValue, value.valueType = decoder.IntValue(), field.BoolValue
+				value.value = float64(value.intValue)
+			default:
+				value.value = math.Float64frombits(decoder.Value())
+			}
+			timestamp := familyTime + int64(slot)*interval
+			if !timeRange.Contains(timestamp) {
+				continue
+			}
+			values, ok := points[timestamp]
+			if !ok {
+				values = make([]exportValue, len(fields))
+				points[timestamp] = values
+			}
+			if values[fieldIdx].ok {
+				values[fieldIdx].aggregate(aggFunc, value)
+				continue
+			}
+			values[fieldIdx] = value
+		}
+	}
+	return nil
+}
+
+// emitExportPoints builds the metric for each timestamp of series in order, then calls fn.
+func emitExportPoints(
+	namespace, metricName string,
+	tags []*protoMetricsV1.KeyValue,
+	fields field.Metas,
+	points map[int64][]exportValue,
+	fn func(metric *protoMetricsV1.Metric) error,
+) error {
+	timestamps := make([]int64, 0, len(points))
+	for timestamp := range points {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	for _, timestamp := range timestamps {
+		metric := &protoMetricsV1.Metric{
+			Namespace: namespace,
+			Name:      metricName,
+			Timestamp: timestamp,
+			Tags:      tags,
+		}
+		for idx, value := range points[timestamp] {
+			if !value.ok {
+				continue
+			}
+			simpleField := &protoMetricsV1.SimpleField{
+				Name:  fields[idx].Name.String(),
+				Type:  exportFieldType(fields[idx].Type),
+				Value: value.value,
+			}
+			switch value.valueType {
+			case field.IntValue:
+				simpleField.ValueType = protoMetricsV1.SimpleFieldValueType_INT_VALUE
+				simpleField.IntValue = value.intValue
+			case field.BoolValue:
+				simpleField.ValueType = protoMetricsV1.SimpleFieldValueType_BOOL_VALUE
+				simpleField.IntValue = value.intValue
+			}
+			metric.SimpleFields = append(metric.SimpleFields, simpleField)
+		}
+		if err := fn(metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportFieldType returns the simple field type of exported field, returns unspecified if field cannot be exported.
+func exportFieldType(fieldType field.Type) protoMetricsV1.SimpleFieldType {
+	switch fieldType {
+	case field.SumField:
+		return protoMetricsV1.SimpleFieldType_DELTA_SUM
+	case field.GaugeField:
+		return protoMetricsV1.SimpleFieldType_GAUGE
+	case field.FirstField:
+		return protoMetricsV1.SimpleFieldType_FIRST
+	case field.LastField:
+		return protoMetricsV1.SimpleFieldType_LAST
+	case field.CountField:
+		return protoMetricsV1.SimpleFieldType_COUNT
+	default:
+		return protoMetricsV1.SimpleFieldType_SIMPLE_UNSPECIFIED
+	}
+}
+
+// ignoreNotFound returns nil if err is not found.
+func ignoreNotFound(err error) error {
+	if errors.Is(err, constants.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,218 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lindb/roaring"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/flow"
+	"github.com/lindb/lindb/pkg/bit"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/series"
+	"github.com/lindb/lindb/series/field"
+	"github.com/lindb/lindb/series/tag"
+	"github.com/lindb/lindb/tsdb/indexdb"
+	"github.com/lindb/lindb/tsdb/memdb"
+	"github.com/lindb/lindb/tsdb/metadb"
+)
+
+func TestShard_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	tagMetadata := metadb.NewMockTagMetadata(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	metadata.EXPECT().TagMetadata().Return(tagMetadata).AnyTimes()
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	segment := NewMockIntervalSegment(ctrl)
+	var interval timeutil.Interval
+	_ = interval.ValueOf("10s")
+	s := &shard{
+		metadata:     metadata,
+		indexDB:      indexDB,
+		interval:     interval,
+		intervalCalc: interval.Calculator(),
+		families:     *newFamilyMemDBSet(),
+		segments:     map[timeutil.IntervalType]IntervalSegment{interval.Type(): segment},
+	}
+	familyTime := s.intervalCalc.CalcFamilyStartTime(0, s.intervalCalc.CalcFamily(timeutil.Now(), 0))
+	timeRange := timeutil.TimeRange{Start: familyTime, End: familyTime + timeutil.OneHour}
+	var metrics []*protoMetricsV1.Metric
+	collect := func(metric *protoMetricsV1.Metric) error {
+		metrics = append(metrics, metric)
+		return nil
+	}
+	export := func() error {
+		return s.Export(constants.DefaultNamespace, "test", timeRange, collect)
+	}
+
+	// case 1: metric not found
+	metadataDB.EXPECT().GetMetricID(constants.DefaultNamespace, "test").Return(uint32(0), constants.ErrNotFound)
+	assert.NoError(t, export())
+	// case 2: get metric id err
+	metadataDB.EXPECT().GetMetricID(constants.DefaultNamespace, "test").Return(uint32(0), fmt.Errorf("err"))
+	assert.Error(t, export())
+	// case 3: no exportable fields
+	metadataDB.EXPECT().GetMetricID(constants.DefaultNamespace, "test").Return(uint32(10), nil).AnyTimes()
+	metadataDB.EXPECT().GetAllFields(constants.DefaultNamespace, "test").
+		Return(field.Metas{{ID: 3, Name: "h", Type: field.HistogramField}}, nil)
+	assert.NoError(t, export())
+	fields := field.Metas{{ID: 2, Name: "f2", Type: field.GaugeField}, {ID: 1, Name: "f1", Type: field.SumField}}
+	metadataDB.EXPECT().GetAllFields(constants.DefaultNamespace, "test").Return(fields, nil).AnyTimes()
+	metadataDB.EXPECT().GetAllTagKeys(constants.DefaultNamespace, "test").
+		Return([]tag.Meta{{Key: "host", ID: 5}}, nil).AnyTimes()
+	// case 4: series not found
+	indexDB.EXPECT().GetSeriesIDsForMetric(constants.DefaultNamespace, "test").Return(nil, constants.ErrNotFound)
+	assert.NoError(t, export())
+	indexDB.EXPECT().GetSeriesIDsForMetric(constants.DefaultNamespace, "test").
+		Return(roaring.BitmapOf(1), nil).AnyTimes()
+	// case 5: filter data family err
+	family := NewMockDataFamily(ctrl)
+	segment.EXPECT().getDataFamilies(gomock.Any()).Return([]DataFamily{family}).AnyTimes()
+	family.EXPECT().Filter(uint32(10), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	assert.Error(t, export())
+
+	// prepare data of file and memory database
+	sortedFields := field.Metas{fields[1], fields[0]}
+	fileRS := flow.NewMockFilterResultSet(ctrl)
+	fileLoader := flow.NewMockDataLoader(ctrl)
+	family.EXPECT().Filter(uint32(10), gomock.Any(), gomock.Any(), sortedFields).
+		Return([]flow.FilterResultSet{fileRS}, nil).AnyTimes()
+	memDB := memdb.NewMockMemoryDatabase(ctrl)
+	memRS := flow.NewMockFilterResultSet(ctrl)
+	memLoader := flow.NewMockDataLoader(ctrl)
+	memDB.EXPECT().Filter(uint32(10), gomock.Any(), gomock.Any(), sortedFields).
+		Return([]flow.FilterResultSet{memRS}, nil).AnyTimes()
+	s.families.InsertFamily(familyTime, memDB, nil)
+	// family out of time range
+	s.families.InsertFamily(familyTime+2*timeutil.OneHour, memdb.NewMockMemoryDatabase(ctrl), nil)
+	for _, rs := range []*flow.MockFilterResultSet{fileRS, memRS} {
+		rs.EXPECT().FamilyTime().Return(familyTime).AnyTimes()
+		rs.EXPECT().Close().AnyTimes()
+	}
+	fileRS.EXPECT().Load(uint16(0), gomock.Any()).Return(fileLoader).AnyTimes()
+	memRS.EXPECT().Load(uint16(0), gomock.Any()).Return(memLoader).AnyTimes()
+	fileLoader.EXPECT().Load(uint16(1)).
+		Return(timeutil.SlotRange{Start: 5, End: 6}, [][]byte{encodeTSD(t, 5, 1, 2), encodeTSD(t, 5, 3, 4)}).AnyTimes()
+	memLoader.EXPECT().Load(uint16(1)).
+		Return(timeutil.SlotRange{Start: 6, End: 6}, [][]byte{encodeTSD(t, 6, 10), encodeTSD(t, 6, 5)}).AnyTimes()
+	groupingCtx := series.NewMockGroupingContext(ctrl)
+	indexDB.EXPECT().GetGroupingContext([]uint32{5}, gomock.Any()).Return(groupingCtx, nil).AnyTimes()
+	groupKey := make([]byte, 4)
+	binary.LittleEndian.PutUint32(groupKey, 7)
+	groupingCtx.EXPECT().BuildGroup(uint16(0), gomock.Any()).
+		Return(map[string][]uint16{string(groupKey): {1}}).AnyTimes()
+	// case 6: collect tag values err
+	tagMetadata.EXPECT().CollectTagValues(uint32(5), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, export())
+	tagMetadata.EXPECT().CollectTagValues(uint32(5), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ uint32, _ *roaring.Bitmap, tagValues map[uint32]string) error {
+			tagValues[7] = "host1"
+			return nil
+		}).AnyTimes()
+	// case 7: fn err
+	assert.Error(t, s.Export(constants.DefaultNamespace, "test", timeRange, func(_ *protoMetricsV1.Metric) error {
+		return fmt.Errorf("err")
+	}))
+	// case 8: export successfully, values of same timestamp are merged
+	assert.NoError(t, export())
+	tags := []*protoMetricsV1.KeyValue{{Key: "host", Value: "host1"}}
+	assert.Equal(t, []*protoMetricsV1.Metric{
+		{
+			Namespace: constants.DefaultNamespace, Name: "test", Timestamp: familyTime + 50*timeutil.OneSecond, Tags: tags,
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 1},
+				{Name: "f2", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 3},
+			},
+		},
+		{
+			Namespace: constants.DefaultNamespace, Name: "test", Timestamp: familyTime + 60*timeutil.OneSecond, Tags: tags,
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: 12},
+				{Name: "f2", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: 5},
+			},
+		},
+	}, metrics)
+	// case 9: points out of time range are skipped
+	metrics = nil
+	timeRange.Start = familyTime + 55*timeutil.OneSecond
+	assert.NoError(t, export())
+	assert.Len(t, metrics, 1)
+}
+
+// encodeTSD encodes the values of continuous slots from start slot.
+func encodeTSD(t *testing.T, start uint16, values ...float64) []byte {
+	encoder := encoding.NewTSDEncoder(start)
+	for _, value := range values {
+		encoder.AppendTime(bit.One)
+		encoder.AppendValue(math.Float64bits(value))
+	}
+	data, err := encoder.BytesWithoutTime()
+	assert.NoError(t, err)
+	return encoding.NewTSDBlock(data)
+}
+
+func TestMetricExporter_exportFieldType(t *testing.T) {
+	assert.Equal(t, protoMetricsV1.SimpleFieldType_DELTA_SUM, exportFieldType(field.SumField))
+	assert.Equal(t, protoMetricsV1.SimpleFieldType_GAUGE, exportFieldType(field.GaugeField))
+	assert.Equal(t, protoMetricsV1.SimpleFieldType_FIRST, exportFieldType(field.FirstField))
+	assert.Equal(t, protoMetricsV1.SimpleFieldType_LAST, exportFieldType(field.LastField))
+	assert.Equal(t, protoMetricsV1.SimpleFieldType_COUNT, exportFieldType(field.CountField))
+	assert.Equal(t, protoMetricsV1.SimpleFieldType_SIMPLE_UNSPECIFIED, exportFieldType(field.HistogramField))
+}
+
+func TestMetricExporter_intValues(t *testing.T) {
+	sum := field.SumField.GetAggFunc()
+	value := exportValue{intValue: 1 << 60, valueType: field.IntValue, ok: true}
+	value.aggregate(sum, exportValue{intValue: 1, valueType: field.BoolValue, ok: true})
+	assert.Equal(t, int64(1<<60+1), value.intValue)
+	assert.Equal(t, field.IntValue, value.valueType)
+
+	fields := field.Metas{{Name: "f1", Type: field.SumField}, {Name: "f2", Type: field.LastField}}
+	var metrics []*protoMetricsV1.Metric
+	assert.NoError(t, emitExportPoints(constants.DefaultNamespace, "test", nil, fields,
+		map[int64][]exportValue{10: {value, {intValue: 1, value: 1, valueType: field.BoolValue, ok: true}}},
+		func(metric *protoMetricsV1.Metric) error {
+			metrics = append(metrics, metric)
+			return nil
+		}))
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, []*protoMetricsV1.SimpleField{
+		{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: float64(1<<60 + 1),
+			ValueType: protoMetricsV1.SimpleFieldValueType_INT_VALUE, IntValue: 1<<60 + 1},
+		{Name: "f2", Type: protoMetricsV1.SimpleFieldType_LAST, Value: 1,
+			ValueType: protoMetricsV1.SimpleFieldValueType_BOOL_VALUE, IntValue: 1},
+	}, metrics[0].SimpleFields)
+
+	// mixed with float value, aggregates as float
+	value.aggregate(sum, exportValue{value: 0.5, ok: true})
+	assert.Equal(t, field.FloatValue, value.valueType)
+	assert.Equal(t, float64(1<<60+1)+0.5, value.value)
+}
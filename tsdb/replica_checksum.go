@@ -0,0 +1,146 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+)
+
+const (
+	// maxChecksumNamespaces is the max number of namespaces scanned for computing checksum.
+	maxChecksumNamespaces = 100000
+	// maxChecksumMetrics is the max number of metrics of one namespace scanned for computing checksum.
+	maxChecksumMetrics = 1000000
+)
+
+// Checksum computes the checksum/series count of each family within time range based on the exported data points,
+// so that replicas of shard can be compared even if they are flushed/compacted at different times.
+func (s *shard) Checksum(timeRange timeutil.TimeRange) (*models.ShardChecksum, error) {
+	metadataDB := s.metadata.MetadataDatabase()
+	namespaces, err := metadataDB.SuggestNamespace("", maxChecksumNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(namespaces)
+	checksums := newFamilyChecksums(s.intervalCalc)
+	for _, namespace := range namespaces {
+		metricNames, err := metadataDB.SuggestMetrics(namespace, "", maxChecksumMetrics)
+		if err != nil {
+			return nil, err
+		}
+		for _, metricName := range metricNames {
+			if err := s.Export(namespace, metricName, timeRange, checksums.add); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &models.ShardChecksum{
+		ShardID:    s.id,
+		Namespaces: namespaces,
+		Families:   checksums.result(),
+	}, nil
+}
+
+// familyChecksums accumulates the checksum of data points by family.
+type familyChecksums struct {
+	intervalCalc timeutil.IntervalCalculator
+	families     map[int64]*models.FamilyChecksum
+	// series hash => the last family time of series,
+	// data points of one series are exported in order of timestamp.
+	lastFamily map[uint64]int64
+	hash       hash.Hash64
+	scratch    [8]byte
+}
+
+// newFamilyChecksums creates the family checksums accumulator.
+func newFamilyChecksums(intervalCalc timeutil.IntervalCalculator) *familyChecksums {
+	return &familyChecksums{
+		intervalCalc: intervalCalc,
+		families:     make(map[int64]*models.FamilyChecksum),
+		lastFamily:   make(map[uint64]int64),
+		hash:         fnv.New64a(),
+	}
+}
+
+// add adds the data point of series into the checksum of family, the checksum of family is the sum of
+// the hash of each field value, so that it doesn't depend on the order of series.
+func (fc *familyChecksums) add(metric *protoMetricsV1.Metric) error {
+	segmentTime := fc.intervalCalc.CalcSegmentTime(metric.Timestamp)
+	family := fc.intervalCalc.CalcFamily(metric.Timestamp, segmentTime)
+	familyTime := fc.intervalCalc.CalcFamilyStartTime(segmentTime, family)
+	checksum, ok := fc.families[familyTime]
+	if !ok {
+		checksum = &models.FamilyChecksum{
+			FamilyTime: familyTime,
+			EndTime:    fc.intervalCalc.CalcFamilyEndTime(familyTime),
+		}
+		fc.families[familyTime] = checksum
+	}
+
+	fc.hash.Reset()
+	fc.writeString(metric.Namespace)
+	fc.writeString(metric.Name)
+	for _, kv := range metric.Tags {
+		fc.writeString(kv.Key)
+		fc.writeString(kv.Value)
+	}
+	seriesHash := fc.hash.Sum64()
+	if last, ok := fc.lastFamily[seriesHash]; !ok || last != familyTime {
+		fc.lastFamily[seriesHash] = familyTime
+		checksum.Series++
+	}
+	for _, f := range metric.SimpleFields {
+		fc.hash.Reset()
+		fc.writeUint64(seriesHash)
+		fc.writeUint64(uint64(metric.Timestamp))
+		fc.writeString(f.Name)
+		fc.writeUint64(uint64(f.Type))
+		fc.writeUint64(math.Float64bits(f.Value))
+		checksum.Checksum += fc.hash.Sum64()
+		checksum.Points++
+	}
+	return nil
+}
+
+// result returns the family checksums in order of family time.
+func (fc *familyChecksums) result() []models.FamilyChecksum {
+	result := make([]models.FamilyChecksum, 0, len(fc.families))
+	for _, checksum := range fc.families {
+		result = append(result, *checksum)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].FamilyTime < result[j].FamilyTime })
+	return result
+}
+
+func (fc *familyChecksums) writeString(s string) {
+	fc.writeUint64(uint64(len(s)))
+	_, _ = fc.hash.Write([]byte(s))
+}
+
+func (fc *familyChecksums) writeUint64(v uint64) {
+	binary.LittleEndian.PutUint64(fc.scratch[:], v)
+	_, _ = fc.hash.Write(fc.scratch[:])
+}
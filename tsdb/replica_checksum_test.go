@@ -0,0 +1,124 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/pkg/timeutil"
+	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
+	"github.com/lindb/lindb/tsdb/metadb"
+)
+
+func TestShard_Checksum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	var interval timeutil.Interval
+	_ = interval.ValueOf("10s")
+	s := &shard{
+		id:           3,
+		metadata:     metadata,
+		interval:     interval,
+		intervalCalc: interval.Calculator(),
+	}
+	timeRange := timeutil.TimeRange{Start: 0, End: timeutil.OneHour}
+	// case 1: suggest namespace err
+	metadataDB.EXPECT().SuggestNamespace("", maxChecksumNamespaces).Return(nil, fmt.Errorf("err"))
+	_, err := s.Checksum(timeRange)
+	assert.Error(t, err)
+	// case 2: suggest metrics err
+	metadataDB.EXPECT().SuggestNamespace("", maxChecksumNamespaces).Return([]string{"ns"}, nil).AnyTimes()
+	metadataDB.EXPECT().SuggestMetrics("ns", "", maxChecksumMetrics).Return(nil, fmt.Errorf("err"))
+	_, err = s.Checksum(timeRange)
+	assert.Error(t, err)
+	metadataDB.EXPECT().SuggestMetrics("ns", "", maxChecksumMetrics).Return([]string{"test"}, nil).AnyTimes()
+	// case 3: export err
+	metadataDB.EXPECT().GetMetricID("ns", "test").Return(uint32(0), fmt.Errorf("err"))
+	_, err = s.Checksum(timeRange)
+	assert.Error(t, err)
+	// case 4: metric not found
+	metadataDB.EXPECT().GetMetricID("ns", "test").Return(uint32(0), constants.ErrNotFound)
+	checksum, err := s.Checksum(timeRange)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), checksum.ShardID)
+	assert.Equal(t, []string{"ns"}, checksum.Namespaces)
+	assert.Empty(t, checksum.Families)
+}
+
+func TestFamilyChecksums_add(t *testing.T) {
+	var interval timeutil.Interval
+	_ = interval.ValueOf("10s")
+	calc := interval.Calculator()
+	familyTime := calc.CalcFamilyStartTime(0, calc.CalcFamily(timeutil.Now(), 0))
+	newMetric := func(host string, timestamp int64, value float64) *protoMetricsV1.Metric {
+		return &protoMetricsV1.Metric{
+			Namespace: "ns",
+			Name:      "test",
+			Timestamp: timestamp,
+			Tags:      []*protoMetricsV1.KeyValue{{Key: "host", Value: host}},
+			SimpleFields: []*protoMetricsV1.SimpleField{
+				{Name: "f1", Type: protoMetricsV1.SimpleFieldType_DELTA_SUM, Value: value},
+				{Name: "f2", Type: protoMetricsV1.SimpleFieldType_GAUGE, Value: value},
+			},
+		}
+	}
+	nextFamily := calc.CalcFamilyEndTime(familyTime) + 1
+	metrics := []*protoMetricsV1.Metric{
+		newMetric("1.1.1.1", familyTime, 1),
+		newMetric("1.1.1.1", familyTime+10000, 2),
+		newMetric("1.1.1.1", nextFamily, 3),
+		newMetric("1.1.1.2", familyTime, 4),
+	}
+	checksums := newFamilyChecksums(calc)
+	for _, m := range metrics {
+		assert.NoError(t, checksums.add(m))
+	}
+	result := checksums.result()
+	assert.Len(t, result, 2)
+	assert.Equal(t, familyTime, result[0].FamilyTime)
+	assert.Equal(t, calc.CalcFamilyEndTime(familyTime), result[0].EndTime)
+	assert.Equal(t, 2, result[0].Series)
+	assert.Equal(t, 6, result[0].Points)
+	assert.Equal(t, 1, result[1].Series)
+	assert.Equal(t, 2, result[1].Points)
+
+	// checksum doesn't depend on order of series
+	checksums2 := newFamilyChecksums(calc)
+	for _, m := range []*protoMetricsV1.Metric{metrics[3], metrics[0], metrics[1], metrics[2]} {
+		assert.NoError(t, checksums2.add(m))
+	}
+	assert.Equal(t, result, checksums2.result())
+	// checksum changes if value changed
+	checksums3 := newFamilyChecksums(calc)
+	for _, m := range []*protoMetricsV1.Metric{metrics[0], metrics[1], metrics[2], newMetric("1.1.1.2", familyTime, 5)} {
+		assert.NoError(t, checksums3.add(m))
+	}
+	result3 := checksums3.result()
+	assert.Equal(t, result[0].Series, result3[0].Series)
+	assert.NotEqual(t, result[0].Checksum, result3[0].Checksum)
+	assert.Equal(t, result[1], result3[1])
+}
@@ -24,6 +24,8 @@ import (
 
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/bufioutil"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/timeutil"
 	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
@@ -43,10 +45,14 @@ type Segment interface {
 	BaseTime() int64
 	// GetDataFamily returns the data family based on timestamp
 	GetDataFamily(timestamp int64) (DataFamily, error)
+	// SetSyncPolicy sets the fsync policy of data files written by flush/compaction
+	SetSyncPolicy(policy bufioutil.SyncPolicy)
 	// Close closes segment, include kv store
 	Close()
 	// getDataFamilies returns data family list by time range, return nil if not match
 	getDataFamilies(timeRange timeutil.TimeRange) []DataFamily
+	// getAllDataFamilies returns all data families of segment
+	getAllDataFamilies() []DataFamily
 }
 
 // segment implements Segment interface
@@ -54,7 +60,10 @@ type segment struct {
 	baseTime int64
 	kvStore  kv.Store
 	interval timeutil.Interval
-	families sync.Map
+	calc     timeutil.IntervalCalculator // calculates family based on interval and family window
+	// codec of tsd block written by compaction
+	blockCodec encoding.TSDCodec
+	families   sync.Map
 
 	mutex sync.Mutex
 
@@ -65,27 +74,34 @@ type segment struct {
 func newSegment(
 	segmentName string,
 	interval timeutil.Interval,
+	familyWindow timeutil.Interval,
+	blockCodec encoding.TSDCodec,
 	path string,
 ) (
 	Segment,
 	error,
 ) {
 	// parse base time from segment name
-	calc := interval.Calculator()
+	calc := interval.FamilyCalculator(familyWindow)
 	baseTime, err := calc.ParseSegmentTime(segmentName)
 	if err != nil {
 		return nil, fmt.Errorf("parse segment[%s] base time error", path)
 	}
-	kvStore, err := newStore(segmentName, kv.DefaultStoreOption(path))
+	storeOption := kv.DefaultStoreOption(path)
+	// data family compaction job is scheduled by compaction scheduler of engine
+	storeOption.DisableAutoCompact = true
+	kvStore, err := newStore(segmentName, storeOption)
 	if err != nil {
 		return nil, fmt.Errorf("create kv store for segment error:%s", err)
 	}
 	familyNames := kvStore.ListFamilyNames()
 	s := &segment{
-		baseTime: baseTime,
-		kvStore:  kvStore,
-		interval: interval,
-		logger:   logger.GetLogger("tsdb", "Segment"),
+		baseTime:   baseTime,
+		kvStore:    kvStore,
+		interval:   interval,
+		calc:       calc,
+		blockCodec: blockCodec,
+		logger:     logger.GetLogger("tsdb", "Segment"),
 	}
 	for _, familyName := range familyNames {
 		familyTime, err := strconv.Atoi(familyName)
@@ -105,7 +121,7 @@ func (s *segment) BaseTime() int64 {
 // GetDataFamilies returns data family list by time range, return nil if not match
 func (s *segment) getDataFamilies(timeRange timeutil.TimeRange) []DataFamily {
 	var result []DataFamily
-	calc := s.interval.Calculator()
+	calc := s.calc
 
 	familyQueryTimeRange := timeutil.TimeRange{
 		Start: calc.CalcFamilyStartTime(s.baseTime, calc.CalcFamily(timeRange.Start, s.baseTime)),
@@ -124,9 +140,22 @@ func (s *segment) getDataFamilies(timeRange timeutil.TimeRange) []DataFamily {
 	return result
 }
 
+// getAllDataFamilies returns all data families of segment
+func (s *segment) getAllDataFamilies() []DataFamily {
+	var result []DataFamily
+	s.families.Range(func(k, v interface{}) bool {
+		family, ok := v.(DataFamily)
+		if ok {
+			result = append(result, family)
+		}
+		return true
+	})
+	return result
+}
+
 // GetDataFamily returns the data family based on timestamp
 func (s *segment) GetDataFamily(timestamp int64) (DataFamily, error) {
-	calc := s.interval.Calculator()
+	calc := s.calc
 
 	segmentTime := calc.CalcSegmentTime(timestamp)
 	if segmentTime != s.baseTime {
@@ -146,6 +175,9 @@ func (s *segment) GetDataFamily(timestamp int64) (DataFamily, error) {
 				CompactThreshold: 0,
 				Merger:           string(metricsdata.MetricDataMerger),
 			}
+			if s.blockCodec != encoding.TSDCodecXOR {
+				familyOption.MergerParams = map[string]string{metricsdata.TSDCodecParam: s.blockCodec.String()}
+			}
 			// create kv family
 			f, err := s.kvStore.CreateFamily(fmt.Sprintf("%d", familyTime), familyOption)
 			if err != nil {
@@ -163,6 +195,11 @@ func (s *segment) GetDataFamily(timestamp int64) (DataFamily, error) {
 	return f, nil
 }
 
+// SetSyncPolicy sets the fsync policy of data files written by flush/compaction
+func (s *segment) SetSyncPolicy(policy bufioutil.SyncPolicy) {
+	s.kvStore.SetSyncPolicy(policy)
+}
+
 // Close closes segment, include kv store
 func (s *segment) Close() {
 	if err := s.kvStore.Close(); err != nil {
@@ -171,7 +208,7 @@ func (s *segment) Close() {
 }
 
 func (s *segment) initDataFamily(familyTime int, family kv.Family) DataFamily {
-	calc := s.interval.Calculator()
+	calc := s.calc
 	// create data family
 	familyStartTime := calc.CalcFamilyStartTime(s.baseTime, familyTime)
 	dataFamily := newDataFamily(s.interval, timeutil.TimeRange{
@@ -28,8 +28,10 @@ import (
 
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
 )
 
 var segPath = filepath.Join(testPath, shardDir, "2", segmentDir, timeutil.Day.String())
@@ -38,7 +40,7 @@ func TestSegment_Close(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
 	}()
-	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	seg, _ := s.GetOrCreateSegment("20190702")
 	seg1 := seg.(*segment)
 
@@ -55,7 +57,7 @@ func TestSegment_GetDataFamily(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
 	}()
-	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), segPath)
+	s, _ := newIntervalSegment(timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, segPath)
 	seg, _ := s.GetOrCreateSegment("20190904")
 	now, _ := timeutil.ParseTimestamp("20190904 19:10:48", "20060102 15:04:05")
 	familyBaseTime, _ := timeutil.ParseTimestamp("20190904 19:00:00", "20060102 15:04:05")
@@ -98,13 +100,24 @@ func TestSegment_GetDataFamily(t *testing.T) {
 	dataFamily, err = seg.GetDataFamily(wrongTime)
 	assert.NotNil(t, err)
 	assert.Nil(t, dataFamily)
+
+	// create family with block codec
+	seg1.blockCodec = encoding.TSDCodecZstd
+	store.EXPECT().CreateFamily("12", kv.FamilyOption{
+		Merger:       string(metricsdata.MetricDataMerger),
+		MergerParams: map[string]string{metricsdata.TSDCodecParam: "zstd"},
+	}).Return(nil, fmt.Errorf("err"))
+	wrongTime, _ = timeutil.ParseTimestamp("20190904 12:10:48", "20060102 15:04:05")
+	dataFamily, err = seg.GetDataFamily(wrongTime)
+	assert.NotNil(t, err)
+	assert.Nil(t, dataFamily)
 }
 
 func TestSegment_New(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
 	}()
-	s, err := newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), testPath)
+	s, err := newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, testPath)
 	assert.NoError(t, err)
 	assert.NotNil(t, s)
 	now, _ := timeutil.ParseTimestamp("20190904 19:10:40", "20060102 15:04:05")
@@ -114,7 +127,7 @@ func TestSegment_New(t *testing.T) {
 	s.Close()
 
 	// reopen
-	s, err = newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), testPath)
+	s, err = newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, testPath)
 	assert.NoError(t, err)
 	assert.NotNil(t, s)
 	f, err = s.GetDataFamily(now)
@@ -122,7 +135,7 @@ func TestSegment_New(t *testing.T) {
 	assert.NotNil(t, f)
 
 	// cannot reopen
-	s2, err := newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), testPath)
+	s2, err := newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, testPath)
 	assert.Error(t, err)
 	assert.Nil(t, s2)
 
@@ -130,6 +143,32 @@ func TestSegment_New(t *testing.T) {
 	s.Close()
 }
 
+func TestSegment_FamilyWindow(t *testing.T) {
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	s, err := newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10),
+		timeutil.Interval(30*timeutil.OneMinute), encoding.TSDCodecXOR, testPath)
+	assert.NoError(t, err)
+	now, _ := timeutil.ParseTimestamp("20190904 19:40:40", "20060102 15:04:05")
+	f, err := s.GetDataFamily(now)
+	assert.NoError(t, err)
+	familyStartTime, _ := timeutil.ParseTimestamp("20190904 19:30:00", "20060102 15:04:05")
+	assert.Equal(t, timeutil.TimeRange{Start: familyStartTime, End: familyStartTime + 30*timeutil.OneMinute - 1},
+		f.TimeRange())
+	assert.Len(t, s.getDataFamilies(timeutil.TimeRange{Start: now, End: now}), 1)
+	s.Close()
+
+	// reopen, family time range based on family window
+	s, err = newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10),
+		timeutil.Interval(30*timeutil.OneMinute), encoding.TSDCodecXOR, testPath)
+	assert.NoError(t, err)
+	families := s.getAllDataFamilies()
+	assert.Len(t, families, 1)
+	assert.Equal(t, familyStartTime, families[0].TimeRange().Start)
+	s.Close()
+}
+
 func TestSegment_loadFamily_err(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -143,7 +182,7 @@ func TestSegment_loadFamily_err(t *testing.T) {
 		return kvStore, nil
 	}
 	kvStore.EXPECT().ListFamilyNames().Return([]string{"abc"})
-	s, err := newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), testPath)
+	s, err := newSegment("20190904", timeutil.Interval(timeutil.OneSecond*10), 0, encoding.TSDCodecXOR, testPath)
 	assert.Error(t, err)
 	assert.Nil(t, s)
 }
@@ -34,6 +34,8 @@ import (
 	"github.com/lindb/lindb/flow"
 	"github.com/lindb/lindb/internal/linmetric"
 	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/models"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/fasttime"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/option"
@@ -42,10 +44,12 @@ import (
 	"github.com/lindb/lindb/replication"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/tsdb/cumulativecache"
+	"github.com/lindb/lindb/tsdb/exemplar"
 	"github.com/lindb/lindb/tsdb/indexdb"
 	"github.com/lindb/lindb/tsdb/memdb"
 	"github.com/lindb/lindb/tsdb/metadb"
 	"github.com/lindb/lindb/tsdb/tblstore/invertedindex"
+	"github.com/lindb/lindb/tsdb/tblstore/metricsdata"
 )
 
 //go:generate mockgen -source=./shard.go -destination=./shard_mock.go -package=tsdb
@@ -69,9 +73,30 @@ var (
 	cumulativeTransformedVec   = shardScope.NewDeltaCounterVec("cumulative_transformed", "db", "shard")
 	cumulativeUnTransformedVec = shardScope.NewDeltaCounterVec("cumulative_untransformed", "db", "shard")
 	escapedFieldNameVec        = shardScope.NewDeltaCounterVec("escaped_fields", "db", "shard")
+	backfillMetricsVec         = shardScope.NewDeltaCounterVec("backfill_metrics", "db", "shard")
+	historicalFamiliesVec      = shardScope.NewDeltaCounterVec("historical_families_opened", "db", "shard")
+	bulkLoadMetricsVec         = shardScope.NewDeltaCounterVec("bulk_load_metrics", "db", "shard")
+	bulkLoadFailuresVec        = shardScope.NewDeltaCounterVec("bulk_load_failures", "db", "shard")
+	bulkLoadSealedFamiliesVec  = shardScope.NewDeltaCounterVec("bulk_load_sealed_families", "db", "shard")
 	memFlushTimerVec           = shardScope.Scope("memdb_flush_duration").NewDeltaHistogramVec("db", "shard")
+	memFlushedBytesVec         = shardScope.NewDeltaCounterVec("memdb_flushed_bytes", "db", "shard")
+	memFamiliesVec             = shardScope.NewGaugeVec("memdb_families", "db", "shard")
+	indexFlushTimerVec         = shardScope.Scope("index_flush_duration").NewDeltaHistogramVec("db", "shard")
 )
 
+// memFlushSizeVec records memory size of memdb when flushing, buckets are from 1KB to 4GB.
+var memFlushSizeVec = shardScope.Scope("memdb_flush_size").NewDeltaHistogramVec("db", "shard").
+	WithExponentValueBuckets(1024, 4*1024*1024*1024, 20)
+
+// writtenMetrics is the total num. of metrics written into all shards, delta counters are reset after reported,
+// so keeps the total for calculating write throughput of node.
+var writtenMetrics atomic.Int64
+
+// WrittenMetrics returns the total num. of metrics written into all shards since process started.
+func WrittenMetrics() int64 {
+	return writtenMetrics.Load()
+}
+
 const (
 	replicaDir       = "replica"
 	segmentDir       = "segment"
@@ -82,6 +107,15 @@ const (
 	tempDir          = "temp"
 )
 
+const (
+	// exemplarsPerSeries is the max num. of exemplars kept for each histogram series
+	exemplarsPerSeries = 8
+	// maxExemplarSeries is the max num. of histogram series which keep exemplars in shard
+	maxExemplarSeries = 100000
+	// maxStringValueLength is the max length of string field value
+	maxStringValueLength = 1024
+)
+
 // Shard is a horizontal partition of metrics for LinDB.
 type Shard interface {
 	// DatabaseName returns the database name
@@ -98,18 +132,43 @@ type Shard interface {
 	GetOrCreateMemoryDatabase(familyTime int64) (memdb.MemoryDatabase, error)
 	// IndexDatabase returns the index-database
 	IndexDatabase() indexdb.IndexDatabase
+	// ExemplarStore returns the store which keeps the latest exemplars of histogram series
+	ExemplarStore() *exemplar.Store
 	// Write writes the metric-point into memory-database.
 	Write(metric *protoMetricsV1.Metric) error
+	// NewBulkLoader creates a loader which writes historical data into sealed data files directly.
+	NewBulkLoader() BulkLoader
+	// Export scans the raw data points of metric within time range, calls fn with the data point of each series.
+	Export(namespace, metricName string, timeRange timeutil.TimeRange, fn func(metric *protoMetricsV1.Metric) error) error
+	// Checksum computes the checksum/series count of each family within time range for comparing replicas.
+	Checksum(timeRange timeutil.TimeRange) (*models.ShardChecksum, error)
 	// GetOrCreateSequence gets the replica sequence by given remote peer if exist, else creates a new sequence
 	GetOrCreateSequence(replicaPeer string) (replication.Sequence, error)
 	// Flush flushes index and memory data to disk
 	Flush() error
+	// FlushAll flushes index and all memory data to disk without memory threshold, waits running flush job completed
+	FlushAll() error
 	// NeedFlush checks if shard need to flush memory data
 	NeedFlush() bool
 	// IsFlushing checks if this shard is in flushing
 	IsFlushing() bool
+	// UpdateOption applies the modified database option, such as write accept time range,
+	// NOTICE: write interval and family window cannot be changed.
+	UpdateOption(option option.DatabaseOption)
 	// initIndexDatabase initializes index database
 	initIndexDatabase() error
+	// getAllDataFamilies returns all data families of all interval segments
+	getAllDataFamilies() []DataFamily
+	// memDBEntries returns the memory database of each family
+	memDBEntries() memDBEntries
+	// flushFamily flushes the memory database of given family to disk, then removes it from shard
+	flushFamily(familyTime int64) error
+	// fieldDropper returns the dropper of fields which are expired or excluded from rollup in data family,
+	// returns nil if no field need to be dropped.
+	fieldDropper(family DataFamily, now int64) *fieldDropper
+	// idleHistoricalFamilies returns the historical families(family time window has passed)
+	// which have no data written within idle ttl
+	idleHistoricalFamilies(now, idleTTL int64) []int64
 
 	// Closer releases shard's resource, such as flush data, spawned goroutines etc.
 	io.Closer
@@ -126,7 +185,16 @@ type shardMetrics struct {
 	cumulativeTransformed   *linmetric.BoundDeltaCounter
 	cumulativeUnTransformed *linmetric.BoundDeltaCounter
 	escapedFields           *linmetric.BoundDeltaCounter
+	backfillMetrics         *linmetric.BoundDeltaCounter
+	historicalFamilies      *linmetric.BoundDeltaCounter
+	bulkLoadMetrics         *linmetric.BoundDeltaCounter
+	bulkLoadFailures        *linmetric.BoundDeltaCounter
+	bulkLoadSealedFamilies  *linmetric.BoundDeltaCounter
 	memFlushTimer           *linmetric.BoundDeltaHistogram
+	memFlushSize            *linmetric.BoundDeltaHistogram // memory size of memdb when flushing
+	memFlushedBytes         *linmetric.BoundDeltaCounter
+	memFamilies             *linmetric.BoundGauge // num. of families which have memdb
+	indexFlushTimer         *linmetric.BoundDeltaHistogram
 }
 
 func newShardMetrics(dbName string, shardID int32) *shardMetrics {
@@ -140,13 +208,24 @@ func newShardMetrics(dbName string, shardID int32) *shardMetrics {
 		cumulativeTransformed:   cumulativeTransformedVec.WithTagValues(dbName, shardIDStr),
 		cumulativeUnTransformed: cumulativeUnTransformedVec.WithTagValues(dbName, shardIDStr),
 		escapedFields:           escapedFieldNameVec.WithTagValues(dbName, shardIDStr),
+		backfillMetrics:         backfillMetricsVec.WithTagValues(dbName, shardIDStr),
+		historicalFamilies:      historicalFamiliesVec.WithTagValues(dbName, shardIDStr),
+		bulkLoadMetrics:         bulkLoadMetricsVec.WithTagValues(dbName, shardIDStr),
+		bulkLoadFailures:        bulkLoadFailuresVec.WithTagValues(dbName, shardIDStr),
+		bulkLoadSealedFamilies:  bulkLoadSealedFamiliesVec.WithTagValues(dbName, shardIDStr),
 		memFlushTimer:           memFlushTimerVec.WithTagValues(dbName, shardIDStr),
+		memFlushSize:            memFlushSizeVec.WithTagValues(dbName, shardIDStr),
+		memFlushedBytes:         memFlushedBytesVec.WithTagValues(dbName, shardIDStr),
+		memFamilies:             memFamiliesVec.WithTagValues(dbName, shardIDStr),
+		indexFlushTimer:         indexFlushTimerVec.WithTagValues(dbName, shardIDStr),
 	}
 }
 
 // shard implements Shard interface
 // directory tree:
 //    xx/shard/1/ (path)
+//    xx/shard/1/CURRENT // name of current shard manifest file
+//    xx/shard/1/MANIFEST-000001 // confirmed flushed files and replica sequences
 //    xx/shard/1/replica
 //    xx/shard/1/temp/123213123131 // time of ns
 //    xx/shard/1/meta/
@@ -159,16 +238,21 @@ type shard struct {
 	path         string
 	option       option.DatabaseOption
 	sequence     ReplicaSequence
+	manifest     *shardManifest // confirmed flushed files and replica sequences
 
-	mutex    sync.Mutex     // mutex for update families
+	mutex    sync.Mutex     // mutex for update families/option
 	families familyMemDBSet // memory database for each family time
 
 	indexDB  indexdb.IndexDatabase
 	metadata metadb.Metadata
 	// write accept time range
 	interval timeutil.Interval
-	ahead    timeutil.Interval
-	behind   timeutil.Interval
+	ahead    atomic.Int64
+	behind   atomic.Int64
+	// calculates family/slot based on write interval and family window
+	intervalCalc timeutil.IntervalCalculator
+	// precision of written timestamp, family is calculated by millisecond, slot by precision
+	precision timeutil.Precision
 	// segments keeps all interval segments,
 	// includes one smallest interval segment for writing data, and rollup interval segments
 	segments       map[timeutil.IntervalType]IntervalSegment
@@ -185,6 +269,8 @@ type shard struct {
 	// cumulative field value-> delta cache
 	once4Cache      sync.Once
 	cumulativeCache *cumulativecache.Cache
+	// latest exemplars of histogram series
+	exemplars *exemplar.Store
 }
 
 // newShard creates shard instance, if shard path exist then load shard data for init.
@@ -218,22 +304,31 @@ func newShard(
 		families:     *newFamilyMemDBSet(),
 		metadata:     db.Metadata(),
 		interval:     interval,
+		intervalCalc: interval.FamilyCalculator(option.GetFamilyWindow()),
+		precision:    option.GetPrecision(),
 		segments:     make(map[timeutil.IntervalType]IntervalSegment),
 		isFlushing:   *atomic.NewBool(false),
 		metrics:      *newShardMetrics(db.Name(), shardID),
+		exemplars: exemplar.NewStore(
+			exemplarsPerSeries,
+			maxExemplarSeries,
+			shardScope.Scope("exemplars"),
+		),
 	}
 	// new segment for writing
 	createdShard.segment, err = newIntervalSegmentFunc(
 		interval,
+		option.GetFamilyWindow(),
+		option.GetBlockCodec(),
 		filepath.Join(shardPath, segmentDir, interval.Type().String()))
 
 	if err != nil {
 		return nil, err
 	}
-	_ = createdShard.ahead.ValueOf(option.Ahead)
-	_ = createdShard.behind.ValueOf(option.Behind)
+	createdShard.setWriteTimeRange(option)
 	// add writing segment into segment list
 	createdShard.segments[interval.Type()] = createdShard.segment
+	createdShard.segment.SetSyncPolicy(option.GetSyncPolicy())
 
 	defer func() {
 		if err != nil {
@@ -243,6 +338,9 @@ func newShard(
 			}
 		}
 	}()
+	if err = createdShard.recoverManifest(); err != nil {
+		return nil, fmt.Errorf("recover manifest for shard[%d] error: %s", shardID, err)
+	}
 	if err = createdShard.initIndexDatabase(); err != nil {
 		return nil, fmt.Errorf("create index database for shard[%d] error: %s", shardID, err)
 	}
@@ -255,7 +353,7 @@ func (s *shard) getCache() *cumulativecache.Cache {
 	s.once4Cache.Do(func() {
 		s.cumulativeCache = cumulativecache.NewCache(
 			32,
-			time.Minute, // max pull duration is 1 minute
+			5*time.Minute, // keeps state of series within the staleness window of prometheus(5 minutes)
 			time.Second*30,
 			shardScope.Scope("cumulative_caches"),
 		)
@@ -287,10 +385,51 @@ func (s *shard) GetOrCreateSequence(replicaPeer string) (replication.Sequence, e
 	return s.sequence.getOrCreateSequence(replicaPeer)
 }
 
+// UpdateOption applies the modified database option, such as write accept time range.
+func (s *shard) UpdateOption(option option.DatabaseOption) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.option = option
+	s.setWriteTimeRange(option)
+	s.setSyncPolicy(option.GetSyncPolicy())
+}
+
+// setSyncPolicy sets the fsync policy of data/index files and series write ahead log.
+func (s *shard) setSyncPolicy(policy bufioutil.SyncPolicy) {
+	s.segment.SetSyncPolicy(policy)
+	s.indexStore.SetSyncPolicy(policy)
+	s.indexDB.SetSyncPolicy(policy)
+}
+
+// fieldDropper returns the dropper of fields which are expired or excluded from rollup in data family,
+// returns nil if no field need to be dropped.
+func (s *shard) fieldDropper(family DataFamily, now int64) *fieldDropper {
+	s.mutex.Lock()
+	opt := s.option
+	s.mutex.Unlock()
+
+	return newFieldDropper(opt, s.interval, family, s.metadata.MetadataDatabase(), now)
+}
+
+// setWriteTimeRange sets the accept time range of writing based on database option.
+func (s *shard) setWriteTimeRange(option option.DatabaseOption) {
+	var ahead, behind timeutil.Interval
+	_ = ahead.ValueOf(option.Ahead)
+	_ = behind.ValueOf(option.Behind)
+	s.ahead.Store(ahead.Int64())
+	s.behind.Store(behind.Int64())
+}
+
 func (s *shard) IndexDatabase() indexdb.IndexDatabase {
 	return s.indexDB
 }
 
+// ExemplarStore returns the store which keeps the latest exemplars of histogram series
+func (s *shard) ExemplarStore() *exemplar.Store {
+	return s.exemplars
+}
+
 func (s *shard) GetDataFamilies(intervalType timeutil.IntervalType, timeRange timeutil.TimeRange) []DataFamily {
 	segment, ok := s.segments[intervalType]
 	if ok {
@@ -299,6 +438,15 @@ func (s *shard) GetDataFamilies(intervalType timeutil.IntervalType, timeRange ti
 	return nil
 }
 
+// getAllDataFamilies returns all data families of all interval segments
+func (s *shard) getAllDataFamilies() []DataFamily {
+	var result []DataFamily
+	for _, segment := range s.segments {
+		result = append(result, segment.getAllDataFamilies()...)
+	}
+	return result
+}
+
 // GetOrCreateMemoryDatabase returns memory database by given family time.
 func (s *shard) GetOrCreateMemoryDatabase(familyTime int64) (memdb.MemoryDatabase, error) {
 	db, exist := s.families.GetFamily(familyTime)
@@ -317,10 +465,48 @@ func (s *shard) GetOrCreateMemoryDatabase(familyTime int64) (memdb.MemoryDatabas
 	if err != nil {
 		return nil, err
 	}
-	s.families.InsertFamily(familyTime, newDB)
+	if s.intervalCalc.CalcFamilyEndTime(familyTime) < fasttime.UnixMilliseconds() {
+		// opens(or reopens after flushed) historical family for late data
+		s.metrics.historicalFamilies.Incr()
+	}
+	// the data written into new memory database has larger sequence than current replica heads
+	s.families.InsertFamily(familyTime, newDB, s.sequence.getAllHeads())
+	s.metrics.memFamilies.Update(float64(s.families.Entries().Len()))
 	return newDB, nil
 }
 
+// acquireMemoryDatabase returns the memory database of given family with write acquired,
+// if the family is flushed concurrently(idle historical family etc.), reopens a new memory database for it.
+func (s *shard) acquireMemoryDatabase(familyTime int64) (memdb.MemoryDatabase, error) {
+	for {
+		db, err := s.GetOrCreateMemoryDatabase(familyTime)
+		if err != nil {
+			return nil, err
+		}
+		db.AcquireWrite()
+		// double check family not removed, flushing memory database waits acquired writes complete
+		if current, ok := s.families.GetFamily(familyTime); ok && current == db {
+			return db, nil
+		}
+		db.CompleteWrite()
+	}
+}
+
+// idleHistoricalFamilies returns the historical families(family time window has passed)
+// which have no data written within idle ttl.
+func (s *shard) idleHistoricalFamilies(now, idleTTL int64) (families []int64) {
+	for _, entry := range s.families.Entries() {
+		if s.intervalCalc.CalcFamilyEndTime(entry.familyTime) >= now {
+			continue
+		}
+		if now-entry.memDB.LastWriteTime() < idleTTL {
+			continue
+		}
+		families = append(families, entry.familyTime)
+	}
+	return families
+}
+
 // Filter filters the data based on metric/time range/seriesIDs,
 // if finds data then returns the flow.FilterResultSet, else returns nil
 func (s *shard) Filter(
@@ -352,6 +538,11 @@ func (s *shard) FindMemoryDatabase() (rs []memdb.MemoryDatabase) {
 }
 
 func (s *shard) validateMetric(metric *protoMetricsV1.Metric) (isCumulative bool, err error) {
+	return s.checkMetric(metric, true)
+}
+
+// checkMetric checks if the metric is valid, checks the timestamp if checkTimeRange is true.
+func (s *shard) checkMetric(metric *protoMetricsV1.Metric, checkTimeRange bool) (isCumulative bool, err error) {
 	if metric == nil {
 		return isCumulative, constants.ErrMetricPBNilMetric
 	}
@@ -362,11 +553,12 @@ func (s *shard) validateMetric(metric *protoMetricsV1.Metric) (isCumulative bool
 	if len(metric.SimpleFields) == 0 && metric.CompoundField == nil {
 		return isCumulative, constants.ErrMetricPBEmptyField
 	}
-	timestamp := metric.Timestamp
+	timestamp := s.precision.ToMillis(metric.Timestamp)
 	now := fasttime.UnixMilliseconds()
 	// check metric timestamp if in acceptable time range
-	if (s.behind.Int64() > 0 && timestamp < now-s.behind.Int64()) ||
-		(s.ahead.Int64() > 0 && timestamp > now+s.ahead.Int64()) {
+	behind, ahead := s.behind.Load(), s.ahead.Load()
+	if checkTimeRange && ((behind > 0 && timestamp < now-behind) ||
+		(ahead > 0 && timestamp > now+ahead)) {
 		s.metrics.outOfRangeMetrics.Incr()
 		return isCumulative, constants.ErrMetricOutOfTimeRange
 	}
@@ -405,6 +597,10 @@ func (s *shard) validateMetric(metric *protoMetricsV1.Metric) (isCumulative bool
 			return false, constants.ErrBadMetricPBFormat
 		case protoMetricsV1.SimpleFieldType_CUMULATIVE_SUM:
 			isCumulative = true
+		case protoMetricsV1.SimpleFieldType_STRING:
+			if len(metric.SimpleFields[idx].StringValue) > maxStringValueLength {
+				return isCumulative, constants.ErrMetricStringFieldTooLong
+			}
 		}
 		v := metric.SimpleFields[idx].Value
 		if math.IsNaN(v) {
@@ -500,6 +696,8 @@ func (s *shard) lookupMetricMeta(metric *protoMetricsV1.Metric) (*memdb.MetricPo
 		// if series id is new, need build inverted index
 		s.indexDB.BuildInvertIndex(ns, metric.Name, metric.Tags, seriesID)
 	}
+	// track created series/written points of tag values for finding heavy hitter tag values
+	s.metadata.TagValueStats().Record(ns, metric.Name, metric.Tags, isCreated)
 
 	fieldsCount := s.howManyFieldsWillWrite(metric)
 	var mm = memdb.MetricPoint{
@@ -516,12 +714,28 @@ func (s *shard) lookupMetricMeta(metric *protoMetricsV1.Metric) (*memdb.MetricPo
 			fieldType = field.SumField
 		case protoMetricsV1.SimpleFieldType_GAUGE:
 			fieldType = field.GaugeField
+		case protoMetricsV1.SimpleFieldType_FIRST:
+			fieldType = field.FirstField
+		case protoMetricsV1.SimpleFieldType_LAST:
+			fieldType = field.LastField
+		case protoMetricsV1.SimpleFieldType_COUNT:
+			fieldType = field.CountField
+		case protoMetricsV1.SimpleFieldType_STRING:
+			fieldType = field.StringField
 		}
 		fieldID, err := s.metadata.MetadataDatabase().GenFieldID(
 			ns, metric.Name, field.Name(metric.SimpleFields[idx].Name), fieldType)
 		if err != nil {
 			return nil, err
 		}
+		if fieldType == field.StringField {
+			// string value stored in dictionary, data point only keeps the value id
+			valueID, err := s.metadata.MetadataDatabase().GenStringValueID(metric.SimpleFields[idx].StringValue)
+			if err != nil {
+				return nil, err
+			}
+			metric.SimpleFields[idx].Value = float64(valueID)
+		}
 		mm.FieldIDs = append(mm.FieldIDs, fieldID)
 	}
 	if metric.CompoundField == nil {
@@ -580,24 +794,29 @@ func (s *shard) Write(metric *protoMetricsV1.Metric) (err error) {
 		s.metrics.badMetrics.Incr()
 		return err
 	}
-	timestamp := metric.Timestamp
+	timestamp := s.precision.ToMillis(metric.Timestamp)
 	point, err := s.lookupMetricMeta(metric)
 	if err != nil {
 		return err
 	}
 
 	// calculate family start time and slot index
-	intervalCalc := s.interval.Calculator()
+	intervalCalc := s.intervalCalc
 	segmentTime := intervalCalc.CalcSegmentTime(timestamp)              // day
 	family := intervalCalc.CalcFamily(timestamp, segmentTime)           // hours
 	familyTime := intervalCalc.CalcFamilyStartTime(segmentTime, family) // family timestamp
-	db, err := s.GetOrCreateMemoryDatabase(familyTime)
+	if intervalCalc.CalcFamilyEndTime(familyTime) < fasttime.UnixMilliseconds() {
+		// late data of historical family
+		s.metrics.backfillMetrics.Incr()
+	}
+	db, err := s.acquireMemoryDatabase(familyTime)
 	if err != nil {
 		s.metrics.writeMetricFailures.Incr()
 		return err
 	}
 
-	point.SlotIndex = uint16(intervalCalc.CalcSlot(timestamp, familyTime, s.interval.Int64())) // slot offset of family
+	// slot offset of family
+	point.SlotIndex = uint16(s.precision.CalcSlot(intervalCalc, metric.Timestamp, familyTime, s.interval))
 	if isCumulative {
 		if updated := s.getCache().CumulativePointToDelta(point); updated {
 			s.metrics.cumulativeTransformed.Incr()
@@ -606,13 +825,16 @@ func (s *shard) Write(metric *protoMetricsV1.Metric) (err error) {
 		}
 	}
 
-	db.AcquireWrite()
 	// write metric point into memory db
 	err = db.Write(point)
 	db.CompleteWrite()
 
 	if err == nil {
+		if histogram := metric.CompoundField; histogram != nil && len(histogram.Exemplars) > 0 {
+			s.exemplars.Add(point.MetricID, point.SeriesID, timestamp, histogram)
+		}
 		s.metrics.writeMetrics.Incr()
+		writtenMetrics.Inc()
 		s.metrics.writeFields.Add(float64(len(point.FieldIDs)))
 	} else {
 		s.metrics.writeMetricFailures.Incr()
@@ -625,6 +847,8 @@ func (s *shard) Close() error {
 	s.flushCondition.Wait()
 
 	GetShardManager().RemoveShard(s)
+	// replica heads before closing, the data with smaller sequence is persisted after index/memory databases flushed
+	heads := s.sequence.getAllHeads()
 	if s.indexDB != nil {
 		if err := s.indexDB.Close(); err != nil {
 			return err
@@ -635,12 +859,15 @@ func (s *shard) Close() error {
 			return err
 		}
 	}
+	var flushedFamilies []DataFamily
 	for _, entry := range s.families.Entries() {
-		if err := s.flushMemoryDatabase(entry.memDB); err != nil {
+		family, err := s.flushMemoryDatabase(entry.familyTime, entry.memDB)
+		if err != nil {
 			return err
 		}
+		flushedFamilies = append(flushedFamilies, family)
 	}
-	s.ackReplicaSeq()
+	s.commitFlush(flushedFamilies, heads)
 	return s.sequence.Close()
 }
 
@@ -662,6 +889,17 @@ func (s *shard) NeedFlush() bool {
 
 // Flush flushes index and memory data to disk
 func (s *shard) Flush() (err error) {
+	return s.flush(false)
+}
+
+// FlushAll flushes index and all memory data to disk without memory threshold, waits running flush job completed
+func (s *shard) FlushAll() error {
+	s.flushCondition.Wait()
+	return s.flush(true)
+}
+
+// flush flushes index and memory data to disk, memory database is flushed if memory used exceeds threshold or force.
+func (s *shard) flush(force bool) (err error) {
 	// another flush process is running
 	if !s.isFlushing.CAS(false, true) {
 		return nil
@@ -676,34 +914,93 @@ func (s *shard) Flush() (err error) {
 		s.isFlushing.Store(false)
 	}()
 
-	//FIXME stone1100
+	// replica heads before flushing, the data with smaller sequence is written into index/memory databases
+	heads := s.sequence.getAllHeads()
 	// index flush
 	if s.indexDB != nil {
+		startTime := time.Now()
 		if err = s.indexDB.Flush(); err != nil {
 			return err
 		}
+		s.metrics.indexFlushTimer.UpdateSince(startTime)
 	}
 
 	// flush memory database if need flush
+	var flushedFamilies []DataFamily
 	for _, entry := range s.families.Entries() {
 		//TODO add time threshold???
-		if entry.memDB.MemSize() > constants.ShardMemoryUsedThreshold {
-			if err := s.flushMemoryDatabase(entry.memDB); err != nil {
+		if force || entry.memDB.MemSize() > constants.ShardMemoryUsedThreshold {
+			s.removeMemoryDatabase(entry.familyTime)
+			family, err := s.flushMemoryDatabase(entry.familyTime, entry.memDB)
+			if err != nil {
 				return err
 			}
+			flushedFamilies = append(flushedFamilies, family)
 		}
 
 	}
-	//FIXME(stone1100) need remove memory database if long time no data
-	// finally, commit replica sequence
-	s.ackReplicaSeq()
+	// finally, commit flushed files and replica sequence persisted by index/memory databases
+	s.commitFlush(flushedFamilies, s.persistedHeads(heads))
 	return nil
 }
 
+// memDBEntries returns the memory database of each family
+func (s *shard) memDBEntries() memDBEntries {
+	return s.families.Entries()
+}
+
+// flushFamily flushes the memory database of given family to disk, then removes it from shard,
+// the new memory database will be created when writing data of this family.
+func (s *shard) flushFamily(familyTime int64) error {
+	// another flush process is running
+	if !s.isFlushing.CAS(false, true) {
+		return nil
+	}
+	s.flushCondition.Add(1)
+	defer func() {
+		s.flushCondition.Done()
+		s.isFlushing.Store(false)
+	}()
+
+	memDB, ok := s.families.GetFamily(familyTime)
+	if !ok {
+		return nil
+	}
+	// replica heads before flushing, the data with smaller sequence is written into index/memory databases
+	heads := s.sequence.getAllHeads()
+	// flush index first, because data family references the series ids of index
+	if s.indexDB != nil {
+		startTime := time.Now()
+		if err := s.indexDB.Flush(); err != nil {
+			return err
+		}
+		s.metrics.indexFlushTimer.UpdateSince(startTime)
+	}
+	s.removeMemoryDatabase(familyTime)
+
+	family, err := s.flushMemoryDatabase(familyTime, memDB)
+	if err != nil {
+		return err
+	}
+	// other memory databases are not flushed, ack the min sequence persisted by all of them
+	s.commitFlush([]DataFamily{family}, s.persistedHeads(heads))
+	return nil
+}
+
+// removeMemoryDatabase removes the memory database of given family before flushing it,
+// the new memory database will be created when writing data of this family.
+func (s *shard) removeMemoryDatabase(familyTime int64) {
+	s.mutex.Lock()
+	s.families.RemoveFamily(familyTime)
+	s.metrics.memFamilies.Update(float64(s.families.Entries().Len()))
+	s.mutex.Unlock()
+}
+
 // initIndexDatabase initializes the index database
 func (s *shard) initIndexDatabase() error {
 	var err error
 	storeOption := kv.DefaultStoreOption(filepath.Join(s.path, indexParentDir))
+	storeOption.SyncPolicy = s.option.GetSyncPolicy()
 	s.indexStore, err = newKVStoreFunc(storeOption.Path, storeOption)
 	if err != nil {
 		return err
@@ -732,6 +1029,7 @@ func (s *shard) initIndexDatabase() error {
 	if err != nil {
 		return err
 	}
+	s.indexDB.SetSyncPolicy(storeOption.SyncPolicy)
 	return nil
 }
 
@@ -741,41 +1039,32 @@ func (s *shard) createMemoryDatabase(familyTime int64) (memdb.MemoryDatabase, er
 		FamilyTime: familyTime,
 		Name:       s.databaseName,
 		TempPath:   filepath.Join(s.path, filepath.Join(tempDir, fmt.Sprintf("%d", timeutil.Now()))),
+		OnHeap:     s.option.IsOnHeapBuffer(),
 	})
 }
 
-// flushMemoryDatabase flushes memory database to disk kv store
-func (s *shard) flushMemoryDatabase(memDB memdb.MemoryDatabase) error {
+// flushMemoryDatabase flushes memory database to data family of kv store, then closes it,
+// returns the data family which the data flushed into.
+func (s *shard) flushMemoryDatabase(familyTime int64, memDB memdb.MemoryDatabase) (DataFamily, error) {
 	startTime := time.Now()
 	defer s.metrics.memFlushTimer.UpdateSince(startTime)
-	//FIXME(stone1100)
-	//for _, familyTime := range memDB.Families() {
-	//	segmentName := s.interval.Calculator().GetSegment(familyTime)
-	//	segment, err := s.segment.GetOrCreateSegment(segmentName)
-	//	if err != nil {
-	//		return err
-	//	}
-	//	thisDataFamily, err := segment.GetDataFamily(familyTime)
-	//	if err != nil {
-	//		continue
-	//	}
-	//	// flush family data
-	//	if err := memDB.FlushFamilyTo(
-	//		metricsdata.NewFlusher(thisDataFamily.Family().NewFlusher()), familyTime); err != nil {
-	//		return err
-	//	}
-	//}
-	if err := memDB.Close(); err != nil {
-		return err
-	}
-	return nil
-}
+	memSize := float64(memDB.MemSize())
+	s.metrics.memFlushSize.UpdateValue(memSize)
 
-// ackReplicaSeq commits the replica sequence
-// NOTICE: if fail, maybe data will write duplicate if system restart
-func (s *shard) ackReplicaSeq() {
-	allHeads := s.sequence.getAllHeads()
-	if err := s.sequence.ack(allHeads); err != nil {
-		engineLogger.Error("ack replica sequence error", logger.String("shard", s.path), logger.Error(err))
+	segment, err := s.segment.GetOrCreateSegment(s.interval.Calculator().GetSegment(familyTime))
+	if err != nil {
+		return nil, err
+	}
+	family, err := segment.GetDataFamily(familyTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := memDB.FlushFamilyTo(metricsdata.NewFlusher(family.Family().NewFlusher())); err != nil {
+		return nil, err
+	}
+	if err := memDB.Close(); err != nil {
+		return nil, err
 	}
+	s.metrics.memFlushedBytes.Add(memSize)
+	return family, nil
 }
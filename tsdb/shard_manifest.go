@@ -0,0 +1,360 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/lindb/lindb/kv/table"
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/fileutil"
+	"github.com/lindb/lindb/pkg/logger"
+)
+
+// for testing
+var (
+	readManifestFunc  = ioutil.ReadFile
+	writeManifestFunc = writeFileSync
+	renameManifest    = os.Rename
+)
+
+const (
+	shardManifestCurrent = "CURRENT"
+	shardManifestPrefix  = "MANIFEST-"
+	shardManifestTmp     = "tmp"
+)
+
+// flushedFamily represents the confirmed level0 files of data family.
+type flushedFamily struct {
+	FamilyTime int64              `json:"familyTime"`
+	Files      []table.FileNumber `json:"files"`
+}
+
+// replicaAckSeq represents the ack sequence of replica peer.
+type replicaAckSeq struct {
+	Peer string `json:"peer"`
+	Seq  int64  `json:"seq"`
+}
+
+// shardManifestData represents the content of shard manifest file.
+type shardManifestData struct {
+	Version   int64           `json:"version"`
+	Families  []flushedFamily `json:"families"`
+	Sequences []replicaAckSeq `json:"sequences"`
+}
+
+// shardManifest records the level0 files of data families which are flushed by shard and the replica sequences
+// after flushing successfully, both are persisted into a new manifest file, then CURRENT file is switched to it atomically.
+// The level0 files not recorded in manifest are partially flushed before crash, they are dropped when shard restart,
+// and the data is replayed from replica log, because the replica sequence isn't acked.
+type shardManifest struct {
+	path      string
+	version   int64
+	families  map[int64]map[table.FileNumber]struct{} // family time(start time of family) => confirmed level0 files
+	sequences map[string]int64                        // replica peer => ack sequence
+
+	mutex sync.Mutex
+}
+
+// openShardManifest opens the shard manifest under shard path, returns false if manifest not exist.
+func openShardManifest(path string) (*shardManifest, bool, error) {
+	m := &shardManifest{
+		path:      path,
+		families:  make(map[int64]map[table.FileNumber]struct{}),
+		sequences: make(map[string]int64),
+	}
+	currentPath := filepath.Join(path, shardManifestCurrent)
+	if !fileutil.Exist(currentPath) {
+		return m, false, nil
+	}
+	manifestFile, err := readManifestFunc(currentPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("read current file of shard manifest error: %s", err)
+	}
+	data, err := readManifestFunc(filepath.Join(path, string(manifestFile)))
+	if err != nil {
+		return nil, false, fmt.Errorf("read shard manifest file error: %s", err)
+	}
+	manifest := &shardManifestData{}
+	if err := encoding.JSONUnmarshal(data, manifest); err != nil {
+		return nil, false, fmt.Errorf("unmarshal shard manifest file error: %s", err)
+	}
+	m.version = manifest.Version
+	for _, family := range manifest.Families {
+		files := make(map[table.FileNumber]struct{})
+		for _, file := range family.Files {
+			files[file] = struct{}{}
+		}
+		m.families[family.FamilyTime] = files
+	}
+	for _, seq := range manifest.Sequences {
+		m.sequences[seq.Peer] = seq.Seq
+	}
+	return m, true, nil
+}
+
+// isConfirmed checks if the level0 file of family is confirmed after flushing successfully.
+func (m *shardManifest) isConfirmed(familyTime int64, fileNumber table.FileNumber) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, ok := m.families[familyTime][fileNumber]
+	return ok
+}
+
+// getSequences returns the replica sequences persisted with the confirmed files.
+func (m *shardManifest) getSequences() map[string]int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := make(map[string]int64)
+	for peer, seq := range m.sequences {
+		result[peer] = seq
+	}
+	return result
+}
+
+// commit confirms the level0 files of given families and the replica sequences(nil means unchanged),
+// family without level0 file is removed from manifest.
+func (m *shardManifest) commit(families map[int64][]table.FileNumber, sequences map[string]int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	newFamilies := make(map[int64][]table.FileNumber)
+	for familyTime, files := range m.families {
+		for file := range files {
+			newFamilies[familyTime] = append(newFamilies[familyTime], file)
+		}
+	}
+	for familyTime, files := range families {
+		newFamilies[familyTime] = files
+	}
+	newSequences := make(map[string]int64)
+	for peer, seq := range m.sequences {
+		newSequences[peer] = seq
+	}
+	for peer, seq := range sequences {
+		newSequences[peer] = seq
+	}
+	return m.persist(newFamilies, newSequences)
+}
+
+// reset replaces the confirmed level0 files of all families, keeps the replica sequences.
+func (m *shardManifest) reset(families map[int64][]table.FileNumber) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.persist(families, m.sequences)
+}
+
+// persist writes the new manifest file, then switches CURRENT file to it, finally removes the previous manifest file.
+func (m *shardManifest) persist(families map[int64][]table.FileNumber, sequences map[string]int64) error {
+	manifest := &shardManifestData{Version: m.version + 1}
+	for familyTime, files := range families {
+		if len(files) == 0 {
+			continue
+		}
+		sort.Slice(files, func(i, j int) bool { return files[i] < files[j] })
+		manifest.Families = append(manifest.Families, flushedFamily{FamilyTime: familyTime, Files: files})
+	}
+	sort.Slice(manifest.Families, func(i, j int) bool {
+		return manifest.Families[i].FamilyTime < manifest.Families[j].FamilyTime
+	})
+	for peer, seq := range sequences {
+		manifest.Sequences = append(manifest.Sequences, replicaAckSeq{Peer: peer, Seq: seq})
+	}
+	sort.Slice(manifest.Sequences, func(i, j int) bool {
+		return manifest.Sequences[i].Peer < manifest.Sequences[j].Peer
+	})
+	manifestFile := shardManifestFileName(manifest.Version)
+	if err := writeManifestFunc(filepath.Join(m.path, manifestFile), encoding.JSONMarshal(manifest)); err != nil {
+		return fmt.Errorf("write shard manifest file error: %s", err)
+	}
+	// switch CURRENT file to new manifest file atomically
+	currentPath := filepath.Join(m.path, shardManifestCurrent)
+	tmpPath := fmt.Sprintf("%s.%s", currentPath, shardManifestTmp)
+	if err := writeManifestFunc(tmpPath, []byte(manifestFile)); err != nil {
+		return fmt.Errorf("write current tmp file of shard manifest error: %s", err)
+	}
+	if err := renameManifest(tmpPath, currentPath); err != nil {
+		return fmt.Errorf("rename current tmp file of shard manifest error: %s", err)
+	}
+	if m.version > 0 {
+		previous := filepath.Join(m.path, shardManifestFileName(m.version))
+		if err := os.Remove(previous); err != nil && !os.IsNotExist(err) {
+			engineLogger.Warn("remove previous shard manifest file error",
+				logger.String("file", previous), logger.Error(err))
+		}
+	}
+	m.version = manifest.Version
+	m.families = make(map[int64]map[table.FileNumber]struct{})
+	for _, family := range manifest.Families {
+		files := make(map[table.FileNumber]struct{})
+		for _, file := range family.Files {
+			files[file] = struct{}{}
+		}
+		m.families[family.FamilyTime] = files
+	}
+	m.sequences = make(map[string]int64)
+	for _, seq := range manifest.Sequences {
+		m.sequences[seq.Peer] = seq.Seq
+	}
+	return nil
+}
+
+// shardManifestFileName returns the manifest file name of version.
+func shardManifestFileName(version int64) string {
+	return fmt.Sprintf("%s%06d", shardManifestPrefix, version)
+}
+
+// writeFileSync writes data into file, then syncs it to disk.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// recoverManifest opens shard manifest, drops the level0 files which are not confirmed(partially flushed before crash),
+// restores the replica sequences persisted with confirmed files, then confirms the live level0 files.
+func (s *shard) recoverManifest() error {
+	manifest, exist, err := openShardManifest(s.path)
+	if err != nil {
+		return err
+	}
+	s.manifest = manifest
+	families := s.segment.getAllDataFamilies()
+	if exist {
+		for _, family := range families {
+			familyTime := family.TimeRange().Start
+			var unconfirmed []table.FileNumber
+			for _, fileNumber := range levelZeroFiles(family) {
+				if !manifest.isConfirmed(familyTime, fileNumber) {
+					unconfirmed = append(unconfirmed, fileNumber)
+				}
+			}
+			if len(unconfirmed) == 0 {
+				continue
+			}
+			engineLogger.Warn("drop the files not confirmed by shard manifest, data will be replayed from replica log",
+				logger.String("shard", s.path), logger.Int64("family", familyTime), logger.Any("files", unconfirmed))
+			if err := family.Family().DropFiles(unconfirmed); err != nil {
+				return err
+			}
+		}
+		// replica sequence maybe not acked before crash, after manifest committed
+		heads := make(map[string]int64)
+		for peer, seq := range manifest.getSequences() {
+			sequence, err := s.sequence.getOrCreateSequence(peer)
+			if err != nil {
+				return err
+			}
+			if seq > sequence.GetAckSeq() {
+				sequence.SetHeadSeq(seq)
+				heads[peer] = seq
+			}
+		}
+		if len(heads) > 0 {
+			if err := s.sequence.ack(heads); err != nil {
+				return err
+			}
+		}
+	}
+	// confirm the live level0 files, removes the families which are not exist
+	return manifest.reset(familiesLevelZeroFiles(families))
+}
+
+// confirmFlushedFiles records the level0 files of flushed data families into shard manifest.
+func (s *shard) confirmFlushedFiles(families []DataFamily) error {
+	if s.manifest == nil {
+		return nil
+	}
+	return s.manifest.commit(familiesLevelZeroFiles(families), nil)
+}
+
+// commitFlush records the level0 files of flushed data families and the persisted replica sequences
+// into shard manifest atomically, then acks the replica sequences.
+// NOTICE: if fail, the flushed files are dropped and data is replayed from replica log when restart.
+func (s *shard) commitFlush(families []DataFamily, heads map[string]int64) {
+	if s.manifest != nil {
+		if err := s.manifest.commit(familiesLevelZeroFiles(families), heads); err != nil {
+			engineLogger.Error("commit shard manifest error", logger.String("shard", s.path), logger.Error(err))
+			return
+		}
+	}
+	if err := s.sequence.ack(heads); err != nil {
+		engineLogger.Error("ack replica sequence error", logger.String("shard", s.path), logger.Error(err))
+	}
+}
+
+// persistedHeads returns the replica heads which data has been persisted, based on the heads taken before flushing.
+// The data in memory databases not flushed yet has larger sequence than the heads when memory database created,
+// so the persisted heads cannot exceed them.
+func (s *shard) persistedHeads(heads map[string]int64) map[string]int64 {
+	result := make(map[string]int64, len(heads))
+	for peer, head := range heads {
+		result[peer] = head
+	}
+	for _, entry := range s.families.Entries() {
+		for peer, head := range result {
+			memDBHead, ok := entry.heads[peer]
+			if !ok {
+				// replica peer is created after memory database, its data in memory database is not persisted
+				delete(result, peer)
+				continue
+			}
+			if memDBHead < head {
+				result[peer] = memDBHead
+			}
+		}
+	}
+	return result
+}
+
+// familiesLevelZeroFiles returns the level0 files of each data family, key is the family time.
+func familiesLevelZeroFiles(families []DataFamily) map[int64][]table.FileNumber {
+	result := make(map[int64][]table.FileNumber)
+	for _, family := range families {
+		result[family.TimeRange().Start] = levelZeroFiles(family)
+	}
+	return result
+}
+
+// levelZeroFiles returns the level0 files of data family, the flushed files are always in level0.
+func levelZeroFiles(family DataFamily) []table.FileNumber {
+	snapshot := family.Family().GetSnapshot()
+	defer snapshot.Close()
+
+	var files []table.FileNumber
+	for _, file := range snapshot.GetCurrent().GetFiles(0) {
+		files = append(files, file.GetFileNumber())
+	}
+	return files
+}
@@ -0,0 +1,201 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/kv/table"
+	"github.com/lindb/lindb/pkg/fileutil"
+	"github.com/lindb/lindb/pkg/option"
+	"github.com/lindb/lindb/pkg/timeutil"
+	"github.com/lindb/lindb/tsdb/metadb"
+)
+
+func TestShardManifest_commit(t *testing.T) {
+	path := filepath.Join(testPath, "manifest")
+	_ = fileutil.MkDirIfNotExist(path)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	m, exist, err := openShardManifest(path)
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	assert.False(t, m.isConfirmed(10, 1))
+	// commit files and sequences
+	assert.NoError(t, m.commit(map[int64][]table.FileNumber{10: {2, 1}, 20: {3}}, map[string]int64{"peer": 100}))
+	assert.NoError(t, m.commit(map[int64][]table.FileNumber{20: nil}, nil))
+	assert.False(t, fileutil.Exist(filepath.Join(path, shardManifestFileName(1))))
+	// reopen manifest
+	m, exist, err = openShardManifest(path)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, int64(2), m.version)
+	assert.True(t, m.isConfirmed(10, 1))
+	assert.True(t, m.isConfirmed(10, 2))
+	assert.False(t, m.isConfirmed(20, 3))
+	assert.Equal(t, map[string]int64{"peer": 100}, m.getSequences())
+	// reset files, keep sequences
+	assert.NoError(t, m.reset(map[int64][]table.FileNumber{30: {4}}))
+	assert.False(t, m.isConfirmed(10, 1))
+	assert.True(t, m.isConfirmed(30, 4))
+	assert.Equal(t, map[string]int64{"peer": 100}, m.getSequences())
+}
+
+func TestShardManifest_Err(t *testing.T) {
+	path := filepath.Join(testPath, "manifest")
+	_ = fileutil.MkDirIfNotExist(path)
+	defer func() {
+		readManifestFunc = ioutil.ReadFile
+		writeManifestFunc = writeFileSync
+		renameManifest = os.Rename
+		_ = fileutil.RemoveDir(testPath)
+	}()
+	m, _, err := openShardManifest(path)
+	assert.NoError(t, err)
+	// case 1: write manifest err
+	writeManifestFunc = func(path string, data []byte) error {
+		return fmt.Errorf("err")
+	}
+	assert.Error(t, m.commit(map[int64][]table.FileNumber{10: {1}}, nil))
+	// case 2: write current err
+	writeManifestFunc = func(path string, data []byte) error {
+		if filepath.Base(path) != shardManifestFileName(1) {
+			return fmt.Errorf("err")
+		}
+		return writeFileSync(path, data)
+	}
+	assert.Error(t, m.commit(map[int64][]table.FileNumber{10: {1}}, nil))
+	// case 3: rename current err
+	writeManifestFunc = writeFileSync
+	renameManifest = func(oldpath, newpath string) error {
+		return fmt.Errorf("err")
+	}
+	assert.Error(t, m.commit(map[int64][]table.FileNumber{10: {1}}, nil))
+	assert.False(t, m.isConfirmed(10, 1))
+	renameManifest = os.Rename
+	assert.NoError(t, m.commit(map[int64][]table.FileNumber{10: {1}}, nil))
+	// case 4: read current err
+	readManifestFunc = func(filename string) ([]byte, error) {
+		return nil, fmt.Errorf("err")
+	}
+	_, _, err = openShardManifest(path)
+	assert.Error(t, err)
+	// case 5: read manifest err
+	readManifestFunc = func(filename string) ([]byte, error) {
+		if filepath.Base(filename) == shardManifestCurrent {
+			return ioutil.ReadFile(filename)
+		}
+		return nil, fmt.Errorf("err")
+	}
+	_, _, err = openShardManifest(path)
+	assert.Error(t, err)
+	// case 6: unmarshal manifest err
+	readManifestFunc = func(filename string) ([]byte, error) {
+		if filepath.Base(filename) == shardManifestCurrent {
+			return ioutil.ReadFile(filename)
+		}
+		return []byte("err"), nil
+	}
+	_, _, err = openShardManifest(path)
+	assert.Error(t, err)
+}
+
+func TestShard_recoverManifest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		ctrl.Finish()
+	}()
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	opt := option.DatabaseOption{Interval: "10s"}
+	familyTime := timeutil.Now()
+
+	flushFile := func(s *shard) DataFamily {
+		segment, err := s.segment.GetOrCreateSegment(s.interval.Calculator().GetSegment(familyTime))
+		assert.NoError(t, err)
+		family, err := segment.GetDataFamily(familyTime)
+		assert.NoError(t, err)
+		flusher := family.Family().NewFlusher()
+		assert.NoError(t, flusher.Add(1, []byte("data")))
+		assert.NoError(t, flusher.Commit())
+		return family
+	}
+	closeShard := func(s *shard) {
+		assert.NoError(t, s.Close())
+		s.segment.Close()
+	}
+
+	// case 1: new shard, confirm flushed file, another file flushed but not confirmed before crash
+	shardINTF, err := newShard(db, 1, _testShard1Path, opt)
+	assert.NoError(t, err)
+	s := shardINTF.(*shard)
+	family := flushFile(s)
+	assert.NoError(t, s.confirmFlushedFiles([]DataFamily{family}))
+	confirmed := levelZeroFiles(family)
+	assert.Len(t, confirmed, 1)
+	closeShard(s)
+	// sequence persisted in manifest, but not acked before crash
+	m, _, err := openShardManifest(_testShard1Path)
+	assert.NoError(t, err)
+	assert.NoError(t, m.commit(nil, map[string]int64{"peer": 100}))
+	shardINTF, err = newShard(db, 1, _testShard1Path, opt)
+	assert.NoError(t, err)
+	s = shardINTF.(*shard)
+	_ = flushFile(s)
+	closeShard(s)
+
+	// case 2: reopen shard, drop the file not confirmed, restore replica sequence
+	shardINTF, err = newShard(db, 1, _testShard1Path, opt)
+	assert.NoError(t, err)
+	s = shardINTF.(*shard)
+	families := s.segment.getAllDataFamilies()
+	assert.Len(t, families, 1)
+	assert.Equal(t, confirmed, levelZeroFiles(families[0]))
+	seq, err := s.GetOrCreateSequence("peer")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), seq.GetAckSeq())
+	assert.Equal(t, int64(100), seq.GetHeadSeq())
+	closeShard(s)
+}
+
+func TestShard_persistedHeads(t *testing.T) {
+	s := &shard{families: *newFamilyMemDBSet()}
+	heads := map[string]int64{"peer1": 100, "peer2": 50}
+	// no memory database
+	assert.Equal(t, heads, s.persistedHeads(heads))
+	// data of memory database not flushed cannot be acked
+	s.families.InsertFamily(1, nil, map[string]int64{"peer1": 80, "peer2": 60})
+	assert.Equal(t, map[string]int64{"peer1": 80, "peer2": 50}, s.persistedHeads(heads))
+	// replica peer created after memory database
+	s.families.InsertFamily(2, nil, map[string]int64{"peer1": 90})
+	assert.Equal(t, map[string]int64{"peer1": 80}, s.persistedHeads(heads))
+	// heads not changed
+	assert.Equal(t, map[string]int64{"peer1": 100, "peer2": 50}, heads)
+}
@@ -19,16 +19,21 @@ package tsdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/bufioutil"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/pkg/fasttime"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/option"
@@ -36,6 +41,7 @@ import (
 	protoMetricsV1 "github.com/lindb/lindb/proto/gen/v1/metrics"
 	"github.com/lindb/lindb/series/field"
 	"github.com/lindb/lindb/series/tag"
+	"github.com/lindb/lindb/tsdb/exemplar"
 	"github.com/lindb/lindb/tsdb/indexdb"
 	"github.com/lindb/lindb/tsdb/memdb"
 	"github.com/lindb/lindb/tsdb/metadb"
@@ -70,6 +76,10 @@ func TestShard_New(t *testing.T) {
 	thisShard, err = newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "as"})
 	assert.Error(t, err)
 	assert.Nil(t, thisShard)
+	// us/ns precision not supported
+	thisShard, err = newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "100ms", Precision: "ns"})
+	assert.Error(t, err)
+	assert.Nil(t, thisShard)
 	// case 3: create path err
 	mkDirIfNotExist = func(path string) error {
 		return fmt.Errorf("err")
@@ -87,7 +97,7 @@ func TestShard_New(t *testing.T) {
 	assert.Nil(t, thisShard)
 	// case 5: new interval segment err
 	newReplicaSequenceFunc = newReplicaSequence
-	newIntervalSegmentFunc = func(interval timeutil.Interval, path string) (segment IntervalSegment, err error) {
+	newIntervalSegmentFunc = func(interval, familyWindow timeutil.Interval, blockCodec encoding.TSDCodec, path string) (segment IntervalSegment, err error) {
 		return nil, fmt.Errorf("err")
 	}
 	thisShard, err = newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "10s"})
@@ -161,10 +171,11 @@ func TestShard_GetDataFamilies(t *testing.T) {
 	assert.Nil(t, s.GetDataFamilies(timeutil.Month, timeutil.TimeRange{}))
 	assert.Nil(t, s.GetDataFamilies(timeutil.Day, timeutil.TimeRange{}))
 	assert.Equal(t, 0, len(s.GetDataFamilies(timeutil.Day, timeutil.TimeRange{})))
+	assert.Empty(t, s.(*shard).getAllDataFamilies())
 }
 
 func Test_Shard_validateMetric(t *testing.T) {
-	s := &shard{behind: 100000, ahead: 100000, metrics: *newShardMetrics("1", 1)}
+	s := &shard{behind: *atomic.NewInt64(100000), ahead: *atomic.NewInt64(100000), metrics: *newShardMetrics("1", 1)}
 	// nil pb
 	_, err := s.validateMetric(nil)
 	assert.Error(t, err)
@@ -260,6 +271,16 @@ func Test_Shard_validateMetric(t *testing.T) {
 		Timestamp: fasttime.UnixMilliseconds(),
 	})
 	assert.Error(t, err)
+
+	// string field too long
+	_, err = s.validateMetric(&protoMetricsV1.Metric{
+		Name: "1",
+		SimpleFields: []*protoMetricsV1.SimpleField{
+			{StringValue: strings.Repeat("a", maxStringValueLength+1), Name: "222", Type: protoMetricsV1.SimpleFieldType_STRING},
+		},
+		Timestamp: fasttime.UnixMilliseconds(),
+	})
+	assert.True(t, errors.Is(err, constants.ErrMetricStringFieldTooLong))
 	//
 	// validate compound field
 	//
@@ -356,6 +377,37 @@ func Test_Shard_validateMetric(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestShard_UpdateOption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	segment := NewMockIntervalSegment(ctrl)
+	indexStore := kv.NewMockStore(ctrl)
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	s := &shard{
+		option:     option.DatabaseOption{Interval: "10s"},
+		segment:    segment,
+		indexStore: indexStore,
+		indexDB:    indexDB,
+	}
+	syncPolicy := bufioutil.SyncPolicy{Mode: bufioutil.SyncNever}
+	segment.EXPECT().SetSyncPolicy(syncPolicy)
+	indexStore.EXPECT().SetSyncPolicy(syncPolicy)
+	indexDB.EXPECT().SetSyncPolicy(syncPolicy)
+	s.UpdateOption(option.DatabaseOption{Interval: "10s", Ahead: "1h", Behind: "2h",
+		DataPointBuffer: option.DataPointBufferHeap, SyncPolicy: option.SyncPolicyNever})
+	assert.Equal(t, timeutil.OneHour, s.ahead.Load())
+	assert.Equal(t, 2*timeutil.OneHour, s.behind.Load())
+	assert.True(t, s.option.IsOnHeapBuffer())
+	// reset write time range
+	segment.EXPECT().SetSyncPolicy(bufioutil.DefaultSyncPolicy)
+	indexStore.EXPECT().SetSyncPolicy(bufioutil.DefaultSyncPolicy)
+	indexDB.EXPECT().SetSyncPolicy(bufioutil.DefaultSyncPolicy)
+	s.UpdateOption(option.DatabaseOption{Interval: "10s"})
+	assert.Equal(t, int64(0), s.ahead.Load())
+	assert.Equal(t, int64(0), s.behind.Load())
+}
+
 func TestShard_Write(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testPath)
@@ -370,6 +422,7 @@ func TestShard_Write(t *testing.T) {
 	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
 	indexDB := indexdb.NewMockIndexDatabase(ctrl)
 	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	metadata.EXPECT().TagValueStats().Return(metadb.NewTagValueStats()).AnyTimes()
 	db.EXPECT().Name().Return("test-db").AnyTimes()
 	db.EXPECT().Metadata().Return(metadata).AnyTimes()
 
@@ -388,7 +441,7 @@ func TestShard_Write(t *testing.T) {
 	familyTime := intervalCalc.CalcFamilyStartTime(segmentTime, family) // family timestamp
 	shardIns := shardINTF.(*shard)
 	shardIns.indexDB = indexDB
-	shardIns.families.InsertFamily(familyTime, mockMemDB)
+	shardIns.families.InsertFamily(familyTime, mockMemDB, nil)
 
 	// case 1: metric nil
 	assert.Error(t, shardINTF.Write(nil))
@@ -446,6 +499,7 @@ func TestShard_Write(t *testing.T) {
 			Type:  protoMetricsV1.SimpleFieldType_DELTA_SUM,
 		}},
 	}))
+	written := WrittenMetrics()
 	// case 8: get old series id
 	metadataDB.EXPECT().GenMetricID(constants.DefaultNamespace, "test").Return(uint32(10), nil).AnyTimes()
 	metadataDB.EXPECT().GenFieldID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(field.ID(1), nil)
@@ -488,6 +542,154 @@ func TestShard_Write(t *testing.T) {
 			Type:  protoMetricsV1.SimpleFieldType_DELTA_SUM,
 		}},
 	}))
+	// case 11: write histogram with exemplars
+	metadataDB.EXPECT().GenFieldID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(field.ID(1), nil).AnyTimes()
+	assert.NoError(t, shardINTF.Write(&protoMetricsV1.Metric{
+		Name:      "test",
+		Timestamp: timestamp,
+		TagsHash:  10,
+		CompoundField: &protoMetricsV1.CompoundField{
+			Type:           protoMetricsV1.CompoundFieldType_DELTA_HISTOGRAM,
+			Sum:            10,
+			Count:          3,
+			ExplicitBounds: []float64{1, 2, math.Inf(1) + 1},
+			Values:         []float64{1, 1, 1},
+			Exemplars:      []*protoMetricsV1.Exemplar{{TraceId: []byte{0x1a}, Value: 1.5}},
+		},
+	}))
+	assert.Equal(t, written+4, WrittenMetrics())
+	exemplars := shardINTF.ExemplarStore().Get(10, 0, timeutil.TimeRange{Start: timestamp, End: timestamp})
+	assert.Equal(t, []exemplar.Exemplar{{TraceID: "1a", Value: 1.5, Timestamp: timestamp, Bucket: 2}}, exemplars)
+	// case 12: write string field, value replaced by string value id
+	stringField := &protoMetricsV1.SimpleField{
+		Name:        "version",
+		StringValue: "v1.0.0",
+		Type:        protoMetricsV1.SimpleFieldType_STRING,
+	}
+	metadataDB.EXPECT().GenStringValueID("v1.0.0").Return(uint64(100), fmt.Errorf("err"))
+	assert.Error(t, shardINTF.Write(&protoMetricsV1.Metric{
+		Name: "test", Timestamp: timestamp, TagsHash: 10, SimpleFields: []*protoMetricsV1.SimpleField{stringField},
+	}))
+	metadataDB.EXPECT().GenStringValueID("v1.0.0").Return(uint64(100), nil)
+	assert.NoError(t, shardINTF.Write(&protoMetricsV1.Metric{
+		Name: "test", Timestamp: timestamp, TagsHash: 10, SimpleFields: []*protoMetricsV1.SimpleField{stringField},
+	}))
+	assert.Equal(t, float64(100), stringField.Value)
+}
+
+func TestShard_Write_FamilyWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newMemoryDBFunc = memdb.NewMemoryDatabase
+		ctrl.Finish()
+	}()
+
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	metadata.EXPECT().TagValueStats().Return(metadb.NewTagValueStats()).AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+
+	var familyTime int64
+	var point *memdb.MetricPoint
+	mockMemDB := memdb.NewMockMemoryDatabase(ctrl)
+	mockMemDB.EXPECT().AcquireWrite().AnyTimes()
+	mockMemDB.EXPECT().CompleteWrite().AnyTimes()
+	mockMemDB.EXPECT().Write(gomock.Any()).DoAndReturn(func(p *memdb.MetricPoint) error {
+		point = p
+		return nil
+	})
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		familyTime = cfg.FamilyTime
+		return mockMemDB, nil
+	}
+	shardINTF, err := newShard(db, 1, _testShard1Path,
+		option.DatabaseOption{Interval: "10s", FamilyWindow: "15m"})
+	assert.NoError(t, err)
+	shardINTF.(*shard).indexDB = indexDB
+
+	metadataDB.EXPECT().GenMetricID(constants.DefaultNamespace, "test").Return(uint32(10), nil)
+	metadataDB.EXPECT().GenFieldID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(field.ID(1), nil)
+	timestamp := timeutil.Now()
+	assert.NoError(t, shardINTF.Write(&protoMetricsV1.Metric{
+		Name:      "test",
+		Timestamp: timestamp,
+		SimpleFields: []*protoMetricsV1.SimpleField{{
+			Name:  "f1",
+			Value: 1.0,
+			Type:  protoMetricsV1.SimpleFieldType_DELTA_SUM,
+		}},
+	}))
+	// family start time based on family window, slot based on hour
+	segmentTime := timeutil.Interval(10 * timeutil.OneSecond).Calculator().CalcSegmentTime(timestamp)
+	assert.Equal(t, segmentTime+(timestamp-segmentTime)/(15*timeutil.OneMinute)*(15*timeutil.OneMinute), familyTime)
+	assert.Equal(t, uint16((timestamp-segmentTime)%timeutil.OneHour/(10*timeutil.OneSecond)), point.SlotIndex)
+}
+
+func TestShard_Write_SubSecondInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newMemoryDBFunc = memdb.NewMemoryDatabase
+		ctrl.Finish()
+	}()
+
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	metadataDB := metadb.NewMockMetadataDatabase(ctrl)
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	metadata.EXPECT().MetadataDatabase().Return(metadataDB).AnyTimes()
+	metadata.EXPECT().TagValueStats().Return(metadb.NewTagValueStats()).AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+
+	var familyTime int64
+	var points []*memdb.MetricPoint
+	mockMemDB := memdb.NewMockMemoryDatabase(ctrl)
+	mockMemDB.EXPECT().AcquireWrite().AnyTimes()
+	mockMemDB.EXPECT().CompleteWrite().AnyTimes()
+	mockMemDB.EXPECT().Write(gomock.Any()).DoAndReturn(func(p *memdb.MetricPoint) error {
+		points = append(points, p)
+		return nil
+	}).Times(2)
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		familyTime = cfg.FamilyTime
+		return mockMemDB, nil
+	}
+	shardINTF, err := newShard(db, 1, _testShard1Path,
+		option.DatabaseOption{Interval: "100ms"})
+	assert.NoError(t, err)
+	shardINTF.(*shard).indexDB = indexDB
+
+	metadataDB.EXPECT().GenMetricID(constants.DefaultNamespace, "test").Return(uint32(10), nil).Times(2)
+	metadataDB.EXPECT().GenFieldID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(field.ID(1), nil).Times(2)
+	timestamp := timeutil.Now()/timeutil.OneHour*timeutil.OneHour + 30*timeutil.OneMinute
+	// points in same second are written into different slots
+	for _, offset := range []int64{0, 100} {
+		assert.NoError(t, shardINTF.Write(&protoMetricsV1.Metric{
+			Name:      "test",
+			Timestamp: timestamp + offset,
+			SimpleFields: []*protoMetricsV1.SimpleField{{
+				Name:  "f1",
+				Value: 1.0,
+				Type:  protoMetricsV1.SimpleFieldType_DELTA_SUM,
+			}},
+		}))
+	}
+	calc := timeutil.Interval(100).Calculator()
+	segmentTime := calc.CalcSegmentTime(timestamp)
+	assert.Equal(t, calc.CalcFamilyStartTime(segmentTime, calc.CalcFamily(timestamp, segmentTime)), familyTime)
+	slot := uint16((timestamp - segmentTime) % timeutil.OneHour / 100)
+	assert.Len(t, points, 2)
+	assert.Equal(t, slot, points[0].SlotIndex)
+	assert.Equal(t, slot+1, points[1].SlotIndex)
 }
 
 func Test_Shard_howManyFieldsWillWrite(t *testing.T) {
@@ -534,7 +736,7 @@ func Benchmark_validate_metric(b *testing.B) {
 func Test_familyMemDBSet(t *testing.T) {
 	set := newFamilyMemDBSet()
 	for i := 1000; i >= 0; i -= 10 {
-		set.InsertFamily(int64(i), nil)
+		set.InsertFamily(int64(i), nil, nil)
 	}
 
 	for i := 0; i < 1000; i += 10 {
@@ -545,6 +747,194 @@ func Test_familyMemDBSet(t *testing.T) {
 		_, exist = set.GetFamily(int64(i - 1))
 		assert.False(t, exist)
 	}
+	set.RemoveFamily(int64(10))
+	_, exist := set.GetFamily(int64(10))
+	assert.False(t, exist)
+	assert.Len(t, set.Entries(), 100)
+}
+
+func TestShard_flushFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newMemoryDBFunc = memdb.NewMemoryDatabase
+		ctrl.Finish()
+	}()
+
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	mockMemDB := memdb.NewMockMemoryDatabase(ctrl)
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		return mockMemDB, nil
+	}
+	shardINTF, err := newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "10s"})
+	assert.NoError(t, err)
+	s := shardINTF.(*shard)
+	_, err = s.GetOrCreateMemoryDatabase(10)
+	assert.NoError(t, err)
+	assert.Len(t, s.memDBEntries(), 1)
+	assert.Equal(t, float64(1), s.metrics.memFamilies.Get())
+	mockMemDB.EXPECT().MemSize().Return(int32(1024)).AnyTimes()
+
+	// case 1: flush is doing
+	s.isFlushing.Store(true)
+	assert.NoError(t, s.flushFamily(10))
+	assert.Len(t, s.memDBEntries(), 1)
+	s.isFlushing.Store(false)
+	// case 2: family not exist
+	assert.NoError(t, s.flushFamily(20))
+	// case 3: flush index err
+	indexDB := indexdb.NewMockIndexDatabase(ctrl)
+	realIndexDB := s.indexDB
+	s.indexDB = indexDB
+	indexDB.EXPECT().Flush().Return(fmt.Errorf("err"))
+	assert.Error(t, s.flushFamily(10))
+	assert.Len(t, s.memDBEntries(), 1)
+	s.indexDB = realIndexDB
+	// case 4: flush err
+	mockMemDB.EXPECT().FlushFamilyTo(gomock.Any()).Return(fmt.Errorf("err"))
+	assert.Error(t, s.flushFamily(10))
+	assert.Empty(t, s.memDBEntries())
+	// case 5: close err
+	_, err = s.GetOrCreateMemoryDatabase(10)
+	assert.NoError(t, err)
+	mockMemDB.EXPECT().FlushFamilyTo(gomock.Any()).Return(nil).AnyTimes()
+	mockMemDB.EXPECT().Close().Return(fmt.Errorf("err"))
+	assert.Error(t, s.flushFamily(10))
+	assert.Empty(t, s.memDBEntries())
+	// case 6: flush successfully
+	_, err = s.GetOrCreateMemoryDatabase(10)
+	assert.NoError(t, err)
+	mockMemDB.EXPECT().Close().Return(nil)
+	assert.NoError(t, s.flushFamily(10))
+	assert.Empty(t, s.memDBEntries())
+	assert.False(t, s.IsFlushing())
+	assert.Equal(t, float64(0), s.metrics.memFamilies.Get())
+	assert.Equal(t, float64(1024), s.metrics.memFlushedBytes.Get())
+}
+
+func TestShard_FlushAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newMemoryDBFunc = memdb.NewMemoryDatabase
+		ctrl.Finish()
+	}()
+
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	mockMemDB := memdb.NewMockMemoryDatabase(ctrl)
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		return mockMemDB, nil
+	}
+	shardINTF, err := newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "10s"})
+	assert.NoError(t, err)
+	s := shardINTF.(*shard)
+	_, err = s.GetOrCreateMemoryDatabase(10)
+	assert.NoError(t, err)
+	mockMemDB.EXPECT().MemSize().Return(int32(1024)).AnyTimes()
+
+	// case 1: flush err
+	mockMemDB.EXPECT().FlushFamilyTo(gomock.Any()).Return(nil).AnyTimes()
+	mockMemDB.EXPECT().Close().Return(fmt.Errorf("err"))
+	assert.Error(t, s.FlushAll())
+	assert.Empty(t, s.memDBEntries())
+	// case 2: flush memory database without memory threshold
+	_, err = s.GetOrCreateMemoryDatabase(10)
+	assert.NoError(t, err)
+	mockMemDB.EXPECT().Close().Return(nil)
+	assert.NoError(t, s.FlushAll())
+	assert.False(t, s.IsFlushing())
+	assert.Empty(t, s.memDBEntries())
+}
+
+func TestShard_idleHistoricalFamilies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newMemoryDBFunc = memdb.NewMemoryDatabase
+		ctrl.Finish()
+	}()
+
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	now := timeutil.Now()
+	lastWriteTimes := map[int64]int64{}
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		mockMemDB := memdb.NewMockMemoryDatabase(ctrl)
+		mockMemDB.EXPECT().LastWriteTime().Return(lastWriteTimes[cfg.FamilyTime]).AnyTimes()
+		return mockMemDB, nil
+	}
+	shardINTF, err := newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "10s"})
+	assert.NoError(t, err)
+	s := shardINTF.(*shard)
+	currentFamily := s.intervalCalc.CalcFamilyStartTime(s.intervalCalc.CalcSegmentTime(now),
+		s.intervalCalc.CalcFamily(now, s.intervalCalc.CalcSegmentTime(now)))
+	idleFamily := currentFamily - 2*timeutil.OneHour
+	activeFamily := currentFamily - timeutil.OneHour
+	lastWriteTimes[currentFamily] = now - timeutil.OneHour
+	lastWriteTimes[idleFamily] = now - timeutil.OneHour
+	lastWriteTimes[activeFamily] = now
+	for _, familyTime := range []int64{currentFamily, idleFamily, activeFamily} {
+		_, err = s.GetOrCreateMemoryDatabase(familyTime)
+		assert.NoError(t, err)
+	}
+	// current family is not historical family, active family has data written recently
+	assert.Equal(t, []int64{idleFamily}, s.idleHistoricalFamilies(now, 10*timeutil.OneMinute))
+	assert.Empty(t, s.idleHistoricalFamilies(now, 2*timeutil.OneHour))
+}
+
+func TestShard_acquireMemoryDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		_ = fileutil.RemoveDir(testPath)
+		newMemoryDBFunc = memdb.NewMemoryDatabase
+		ctrl.Finish()
+	}()
+
+	db := NewMockDatabase(ctrl)
+	metadata := metadb.NewMockMetadata(ctrl)
+	metadata.EXPECT().DatabaseName().Return("test").AnyTimes()
+	db.EXPECT().Name().Return("test-db").AnyTimes()
+	db.EXPECT().Metadata().Return(metadata).AnyTimes()
+	shardINTF, err := newShard(db, 1, _testShard1Path, option.DatabaseOption{Interval: "10s"})
+	assert.NoError(t, err)
+	s := shardINTF.(*shard)
+
+	// case 1: create memory database err
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		return nil, fmt.Errorf("err")
+	}
+	memDB, err := s.acquireMemoryDatabase(10)
+	assert.Error(t, err)
+	assert.Nil(t, memDB)
+	// case 2: family flushed after got memory database, reopen it
+	flushedMemDB := memdb.NewMockMemoryDatabase(ctrl)
+	flushedMemDB.EXPECT().AcquireWrite().Do(func() {
+		s.families.RemoveFamily(10)
+	})
+	flushedMemDB.EXPECT().CompleteWrite()
+	reopenedMemDB := memdb.NewMockMemoryDatabase(ctrl)
+	reopenedMemDB.EXPECT().AcquireWrite()
+	memDBs := []memdb.MemoryDatabase{flushedMemDB, reopenedMemDB}
+	newMemoryDBFunc = func(cfg memdb.MemoryDatabaseCfg) (memdb.MemoryDatabase, error) {
+		memDB := memDBs[0]
+		memDBs = memDBs[1:]
+		return memDB, nil
+	}
+	memDB, err = s.acquireMemoryDatabase(10)
+	assert.NoError(t, err)
+	assert.Equal(t, reopenedMemDB, memDB)
+	assert.Len(t, s.memDBEntries(), 1)
 }
 
 func TestShard_Close(t *testing.T) {
@@ -34,10 +34,12 @@ func init() {
 type forwardMerger struct {
 	forwardFlusher ForwardFlusher
 	flusher        *kv.NopFlusher
+	tombstone      Tombstone
 }
 
+// Init initializes the series tombstone if exist
 func (m *forwardMerger) Init(params map[string]interface{}) {
-	// do nothing
+	m.tombstone = getTombstone(params)
 }
 
 // NewForwardMerger creates a forward merger
@@ -49,8 +51,10 @@ func NewForwardMerger() kv.Merger {
 	}
 }
 
-// Merge merges the multi forward index data into a forward index for same tag key id
+// Merge merges the multi forward index data into a forward index for same tag key id,
+// removes deleted series ids.
 func (m *forwardMerger) Merge(key uint32, values [][]byte) ([]byte, error) {
+	deleted := deletedSeriesIDs(m.tombstone, key)
 	var scanners []*tagForwardScanner
 	seriesIDs := roaring.New() // target merged series ids
 	// 1. prepare tag forward scanner
@@ -72,13 +76,29 @@ func (m *forwardMerger) Merge(key uint32, values [][]byte) ([]byte, error) {
 		for it.HasNext() {
 			lowSeriesID := it.Next()
 			// scan index data then merge tag value ids, sort by series id
+			pos := len(tagValueIDs)
 			for _, scanner := range scanners {
 				tagValueIDs = scanner.scan(highKey, lowSeriesID, tagValueIDs)
 			}
+			if deleted != nil && deleted.Contains(uint32(highKey)<<16|uint32(lowSeriesID)) {
+				// series is deleted, drop the tag value id of it
+				tagValueIDs = tagValueIDs[:pos]
+			}
+		}
+		if len(tagValueIDs) == 0 {
+			// all series under container are deleted
+			continue
 		}
 		// flush tag value ids by one container
 		m.forwardFlusher.FlushForwardIndex(tagValueIDs)
 	}
+	if deleted != nil {
+		seriesIDs.AndNot(deleted)
+		if seriesIDs.IsEmpty() {
+			// all series under tag key are deleted, drop tag key
+			return nil, nil
+		}
+	}
 	// flush all series ids under this tag key
 	if err := m.forwardFlusher.FlushTagKeyID(key, seriesIDs); err != nil {
 		return nil, err
@@ -77,3 +77,33 @@ func mockMergeForwardBlock() (block [][]byte) {
 	block = append(block, nopKVFlusher.Bytes())
 	return
 }
+
+func TestForwardMerger_Merge_tombstone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tombstone := NewMockTombstone(ctrl)
+	merge := NewForwardMerger()
+	merge.Init(map[string]interface{}{TombstoneContext: tombstone})
+	// case 1: remove deleted series
+	tombstone.EXPECT().DeletedSeriesIDs(uint32(1)).Return(roaring.BitmapOf(2, 3, 65535+10, 65535+20, 65535+30, 65535+40))
+	data, err := merge.Merge(1, mockMergeForwardBlock())
+	assert.NoError(t, err)
+	reader, err := NewTagForwardReader(data)
+	assert.NoError(t, err)
+	assert.EqualValues(t, roaring.BitmapOf(1, 4).ToArray(), reader.getSeriesIDs().ToArray())
+	_, tagValueIDs := reader.GetSeriesAndTagValue(0)
+	assert.Equal(t, []uint32{1, 4}, tagValueIDs)
+	// case 2: all series deleted
+	tombstone.EXPECT().DeletedSeriesIDs(uint32(1)).Return(roaring.BitmapOf(1, 2, 3, 4, 65535+10, 65535+20, 65535+30, 65535+40))
+	data, err = merge.Merge(1, mockMergeForwardBlock())
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+	// case 3: no deleted series under tag key
+	tombstone.EXPECT().DeletedSeriesIDs(uint32(1)).Return(roaring.New())
+	data, err = merge.Merge(1, mockMergeForwardBlock())
+	assert.NoError(t, err)
+	reader, err = NewTagForwardReader(data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), reader.getSeriesIDs().GetCardinality())
+}
@@ -35,6 +35,7 @@ func init() {
 type invertedMerger struct {
 	invertedFlusher InvertedFlusher
 	flusher         *kv.NopFlusher
+	tombstone       Tombstone
 }
 
 // NewInvertedMerger creates a inverted merger
@@ -46,12 +47,16 @@ func NewInvertedMerger() kv.Merger {
 	}
 }
 
+// Init initializes the series tombstone if exist
 func (m *invertedMerger) Init(params map[string]interface{}) {
-	// do nothing
+	m.tombstone = getTombstone(params)
 }
 
-// Merge merges the multi inverted index data into a inverted index for same tag key id
+// Merge merges the multi inverted index data into a inverted index for same tag key id,
+// removes deleted series ids and the tag values which haven't any series ids.
 func (m *invertedMerger) Merge(key uint32, values [][]byte) ([]byte, error) {
+	deleted := deletedSeriesIDs(m.tombstone, key)
+	tagValues := 0
 	var scanners []*tagInvertedScanner
 	targetTagValueIDs := roaring.New() // target merged tag value ids
 	// 1. prepare tag inverted scanner
@@ -78,6 +83,13 @@ func (m *invertedMerger) Merge(key uint32, values [][]byte) ([]byte, error) {
 					return nil, err
 				}
 			}
+			if deleted != nil {
+				seriesIDs.AndNot(deleted)
+			}
+			if seriesIDs.IsEmpty() {
+				// obsolete tag value, no series under it
+				continue
+			}
 
 			hk := uint32(highKey) << 16
 			// flush tag value id=>series ids mapping
@@ -86,8 +98,13 @@ func (m *invertedMerger) Merge(key uint32, values [][]byte) ([]byte, error) {
 				return nil, err
 			}
 			seriesIDs.Clear() // clear target series ids
+			tagValues++
 		}
 	}
+	if tagValues == 0 {
+		// all series under tag key are deleted, drop tag key
+		return nil, nil
+	}
 	if err := m.invertedFlusher.FlushTagKeyID(key); err != nil {
 		return nil, err
 	}
@@ -169,3 +169,36 @@ func mockInvertedData(tagKeyID uint32, tagValueIDs []uint32, tagValues map[uint3
 	_ = seriesFlusher.FlushTagKeyID(tagKeyID)
 	return nopKVFlusher.Bytes()
 }
+
+func TestInvertedMerger_Merge_tombstone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tombstone := NewMockTombstone(ctrl)
+	merge := NewInvertedMerger()
+	merge.Init(map[string]interface{}{TombstoneContext: tombstone})
+	// case 1: remove deleted series and obsolete tag values
+	tombstone.EXPECT().DeletedSeriesIDs(uint32(1)).Return(roaring.BitmapOf(2, 10, 8000000))
+	data, err := merge.Merge(1, mockInvertedMergeData())
+	assert.NoError(t, err)
+	reader, err := newTagInvertedReader(data)
+	assert.NoError(t, err)
+	assert.EqualValues(t, roaring.BitmapOf(1, 3, 4, 5, 6, 7, 9000000).ToArray(), reader.keys.ToArray())
+	seriesIDs, _ := reader.getSeriesIDsByTagValueIDs(roaring.BitmapOf(1))
+	assert.EqualValues(t, roaring.BitmapOf(1).ToArray(), seriesIDs.ToArray())
+	seriesIDs, _ = reader.getSeriesIDsByTagValueIDs(roaring.BitmapOf(3))
+	assert.EqualValues(t, roaring.BitmapOf(3, 30).ToArray(), seriesIDs.ToArray())
+	// case 2: all series deleted
+	tombstone.EXPECT().DeletedSeriesIDs(uint32(1)).
+		Return(roaring.BitmapOf(1, 2, 3, 4, 5, 6, 7, 10, 30, 8000000, 9000000))
+	data, err = merge.Merge(1, mockInvertedMergeData())
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+	// case 3: merge again after drop tag key
+	tombstone.EXPECT().DeletedSeriesIDs(uint32(1)).Return(nil)
+	data, err = merge.Merge(1, mockInvertedMergeData())
+	assert.NoError(t, err)
+	reader, err = newTagInvertedReader(data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), reader.keys.GetCardinality())
+}
@@ -0,0 +1,53 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package invertedindex
+
+import (
+	"github.com/lindb/roaring"
+)
+
+//go:generate mockgen -source ./tombstone.go -destination=./tombstone_mock.go -package invertedindex
+
+// TombstoneContext is the key of merger params for passing the series tombstone
+const TombstoneContext = "TombstoneContext"
+
+// Tombstone represents the deleted series ids, which need be removed from index when do compaction.
+type Tombstone interface {
+	// DeletedSeriesIDs returns the deleted series ids under tag key, returns nil if not exist.
+	DeletedSeriesIDs(tagKeyID uint32) *roaring.Bitmap
+}
+
+// getTombstone returns the series tombstone from merger params, returns nil if not exist.
+func getTombstone(params map[string]interface{}) Tombstone {
+	if tombstone, ok := params[TombstoneContext].(Tombstone); ok {
+		return tombstone
+	}
+	return nil
+}
+
+// deletedSeriesIDs returns the deleted series ids under tag key, returns nil if no tombstone.
+func deletedSeriesIDs(tombstone Tombstone, tagKeyID uint32) *roaring.Bitmap {
+	if tombstone == nil {
+		return nil
+	}
+	deleted := tombstone.DeletedSeriesIDs(tagKeyID)
+	if deleted == nil || deleted.IsEmpty() {
+		return nil
+	}
+	return deleted
+}
@@ -0,0 +1,169 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package invertedindex
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lindb/roaring"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/kv"
+)
+
+// mapTombstone represents the deleted series ids of tag keys.
+type mapTombstone map[uint32]*roaring.Bitmap
+
+func (t mapTombstone) DeletedSeriesIDs(tagKeyID uint32) *roaring.Bitmap {
+	return t[tagKeyID]
+}
+
+func TestGetTombstone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tombstone := NewMockTombstone(ctrl)
+	// case 1: nil params
+	assert.Nil(t, getTombstone(nil))
+	// case 2: tombstone not exist
+	assert.Nil(t, getTombstone(map[string]interface{}{"other": tombstone}))
+	// case 3: unexpected type
+	assert.Nil(t, getTombstone(map[string]interface{}{TombstoneContext: "tombstone"}))
+	// case 4: nil tombstone
+	assert.Nil(t, getTombstone(map[string]interface{}{TombstoneContext: nil}))
+	// case 5: tombstone exist
+	assert.Equal(t, tombstone, getTombstone(map[string]interface{}{TombstoneContext: tombstone}))
+}
+
+func TestDeletedSeriesIDs(t *testing.T) {
+	tombstone := mapTombstone{
+		1: roaring.BitmapOf(1, 2, 65535+10),
+		2: roaring.New(),
+	}
+	// case 1: no tombstone
+	assert.Nil(t, deletedSeriesIDs(nil, 1))
+	// case 2: deleted series
+	assert.EqualValues(t, []uint32{1, 2, 65535 + 10}, deletedSeriesIDs(tombstone, 1).ToArray())
+	// case 3: empty deleted series
+	assert.Nil(t, deletedSeriesIDs(tombstone, 2))
+	// case 4: tag key not exist
+	assert.Nil(t, deletedSeriesIDs(tombstone, 3))
+}
+
+// mockTombstoneBlocks flushes forward/inverted index of tag key into two blocks,
+// tag value 1: series 1,2; tag value 2: series 3,65535+1; tag value 3: series 65535+2,65535+3.
+func mockTombstoneBlocks(tagKeyID uint32) (forwardBlocks, invertedBlocks [][]byte) {
+	flushForward := func(seriesIDs *roaring.Bitmap, tagValueIDs ...[]uint32) []byte {
+		nopKVFlusher := kv.NewNopFlusher()
+		flusher := NewForwardFlusher(nopKVFlusher)
+		for _, ids := range tagValueIDs {
+			flusher.FlushForwardIndex(ids)
+		}
+		_ = flusher.FlushTagKeyID(tagKeyID, seriesIDs)
+		return nopKVFlusher.Bytes()
+	}
+	forwardBlocks = append(forwardBlocks,
+		flushForward(roaring.BitmapOf(1, 2, 3), []uint32{1, 1, 2}),
+		flushForward(roaring.BitmapOf(65535+1, 65535+2, 65535+3), []uint32{2, 3, 3}))
+	invertedBlocks = append(invertedBlocks,
+		mockInvertedData(tagKeyID, []uint32{1, 2}, map[uint32]*roaring.Bitmap{
+			1: roaring.BitmapOf(1, 2),
+			2: roaring.BitmapOf(3),
+		}),
+		mockInvertedData(tagKeyID, []uint32{2, 3}, map[uint32]*roaring.Bitmap{
+			2: roaring.BitmapOf(65535 + 1),
+			3: roaring.BitmapOf(65535+2, 65535+3),
+		}))
+	return forwardBlocks, invertedBlocks
+}
+
+func TestTombstone_FlushReadMerge(t *testing.T) {
+	// case 1: no tombstone
+	assertTombstoneMerge(t, nil,
+		[]uint32{1, 2, 3, 65535 + 1, 65535 + 2, 65535 + 3},
+		map[uint16][]uint32{0: {1, 1, 2}, 1: {2, 3, 3}},
+		map[uint32][]uint32{1: {1, 2}, 2: {3, 65535 + 1}, 3: {65535 + 2, 65535 + 3}})
+	// case 2: no deleted series under tag key
+	assertTombstoneMerge(t, mapTombstone{2: roaring.BitmapOf(1, 2, 3)},
+		[]uint32{1, 2, 3, 65535 + 1, 65535 + 2, 65535 + 3},
+		map[uint16][]uint32{0: {1, 1, 2}, 1: {2, 3, 3}},
+		map[uint32][]uint32{1: {1, 2}, 2: {3, 65535 + 1}, 3: {65535 + 2, 65535 + 3}})
+	// case 3: remove deleted series and obsolete tag value
+	assertTombstoneMerge(t, mapTombstone{1: roaring.BitmapOf(2, 65535+2, 65535+3, 65535+100)},
+		[]uint32{1, 3, 65535 + 1},
+		map[uint16][]uint32{0: {1, 2}, 1: {2}},
+		map[uint32][]uint32{1: {1}, 2: {3, 65535 + 1}})
+	// case 4: remove all series of container
+	assertTombstoneMerge(t, mapTombstone{1: roaring.BitmapOf(1, 2, 3)},
+		[]uint32{65535 + 1, 65535 + 2, 65535 + 3},
+		map[uint16][]uint32{1: {2, 3, 3}},
+		map[uint32][]uint32{2: {65535 + 1}, 3: {65535 + 2, 65535 + 3}})
+	// case 5: remove all series
+	assertTombstoneMerge(t, mapTombstone{1: roaring.BitmapOf(1, 2, 3, 65535+1, 65535+2, 65535+3)}, nil, nil, nil)
+}
+
+// assertTombstoneMerge merges the blocks of mockTombstoneBlocks with tombstone,
+// then checks the series ids, tag value ids(series high key => tag value ids) and tag values(tag value id => series ids).
+func assertTombstoneMerge(t *testing.T, tombstone Tombstone,
+	expectSeriesIDs []uint32, expectTagValueIDs map[uint16][]uint32, expectTagValues map[uint32][]uint32,
+) {
+	params := map[string]interface{}{}
+	if tombstone != nil {
+		params[TombstoneContext] = tombstone
+	}
+	forwardBlocks, invertedBlocks := mockTombstoneBlocks(1)
+	forwardMerger := NewForwardMerger()
+	forwardMerger.Init(params)
+	forwardData, err := forwardMerger.Merge(1, forwardBlocks)
+	assert.NoError(t, err)
+	invertedMerger := NewInvertedMerger()
+	invertedMerger.Init(params)
+	invertedData, err := invertedMerger.Merge(1, invertedBlocks)
+	assert.NoError(t, err)
+	if len(expectSeriesIDs) == 0 {
+		// tag key is removed if all series deleted
+		assert.Empty(t, forwardData)
+		assert.Empty(t, invertedData)
+		return
+	}
+
+	// read forward index
+	forwardReader, err := NewTagForwardReader(forwardData)
+	assert.NoError(t, err)
+	seriesIDs := forwardReader.getSeriesIDs()
+	assert.EqualValues(t, expectSeriesIDs, seriesIDs.ToArray())
+	assert.Len(t, seriesIDs.GetHighKeys(), len(expectTagValueIDs))
+	for highKey, expect := range expectTagValueIDs {
+		_, tagValueIDs := forwardReader.GetSeriesAndTagValue(highKey)
+		assert.Equal(t, expect, tagValueIDs)
+	}
+	// read inverted index
+	invertedReader, err := newTagInvertedReader(invertedData)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(expectTagValues)), invertedReader.keys.GetCardinality())
+	allSeriesIDs := roaring.New()
+	for tagValueID, expect := range expectTagValues {
+		seriesIDs, err := invertedReader.getSeriesIDsByTagValueIDs(roaring.BitmapOf(tagValueID))
+		assert.NoError(t, err)
+		assert.EqualValues(t, expect, seriesIDs.ToArray())
+		allSeriesIDs.Or(seriesIDs)
+	}
+	// forward and inverted index are consistent after merging
+	assert.EqualValues(t, expectSeriesIDs, allSeriesIDs.ToArray())
+}
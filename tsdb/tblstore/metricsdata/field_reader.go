@@ -29,11 +29,11 @@ import (
 type FieldReader interface {
 	// slotRange returns the time slot range of metric level
 	slotRange() (start, end uint16)
-	// getFieldData returns the field data by field id,
+	// getFieldData returns the tsd block of field data by field id,
 	// if metricReader is completed, return nil, if found data returns field data else returns nil
 	getFieldData(fieldID field.ID) []byte
-	// reset resets the field data for reading
-	reset(buf []byte, position int, start, end uint16)
+	// reset resets the series data(buf[position:dataEnd]) for reading
+	reset(buf []byte, position, dataEnd int, start, end uint16)
 	// close closes the metricReader
 	close()
 }
@@ -45,33 +45,36 @@ type fieldReader struct {
 	fieldOffsets *encoding.FixedOffsetDecoder
 	fieldIndexes map[field.ID]int
 	fieldCount   int
+	version      byte
 
 	completed bool // !!!!NOTICE: need reset completed
 }
 
 // newFieldReader creates the field metricReader
-func newFieldReader(fieldIndexes map[field.ID]int, buf []byte, position int, start, end uint16) FieldReader {
+func newFieldReader(version byte, fieldIndexes map[field.ID]int,
+	buf []byte, position, dataEnd int, start, end uint16,
+) FieldReader {
 	r := &fieldReader{
 		fieldIndexes: fieldIndexes,
 		fieldCount:   len(fieldIndexes),
+		version:      version,
 	}
-	r.reset(buf, position, start, end)
+	r.reset(buf, position, dataEnd, start, end)
 	return r
 }
 
-// reset resets the field data for reading
-func (r *fieldReader) reset(buf []byte, position int, start, end uint16) {
+// reset resets the series data(buf[position:dataEnd]) for reading
+func (r *fieldReader) reset(buf []byte, position, dataEnd int, start, end uint16) {
 	r.completed = false
 	r.start = start
 	r.end = end
+	data := buf[position:dataEnd]
 	if r.fieldCount == 1 {
-		r.seriesData = buf
+		r.seriesData = data
 		return
 	}
-	data := buf[position:]
 	r.fieldOffsets = encoding.NewFixedOffsetDecoder(data)
 	r.seriesData = data[r.fieldOffsets.Header()+r.fieldCount*r.fieldOffsets.ValueWidth():]
-	r.fieldOffsets = encoding.NewFixedOffsetDecoder(buf[position:])
 }
 
 // slotRange returns the time slot range of metric level
@@ -90,13 +93,13 @@ func (r *fieldReader) getFieldData(fieldID field.ID) []byte {
 		return nil
 	}
 	if r.fieldCount == 1 {
-		return r.seriesData
+		return fieldBlock(r.version, r.seriesData)
 	}
 	offset, ok := r.fieldOffsets.Get(idx)
 	if !ok {
 		return nil
 	}
-	return r.seriesData[offset:]
+	return fieldBlock(r.version, fieldData(r.version, r.fieldOffsets, r.seriesData, idx, offset, r.fieldCount))
 }
 
 // close marks the metricReader completed
@@ -32,8 +32,8 @@ func TestField_read(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	scanner := newDataScanner(r)
-	seriesPos := scanner.scan(0, 1)
-	fReader := newFieldReader(scanner.fieldIndexes(), block, seriesPos, 5, 5)
+	seriesPos, seriesEnd := scanner.scan(0, 1)
+	fReader := newFieldReader(scanner.version(), scanner.fieldIndexes(), block, seriesPos, seriesEnd, 5, 5)
 	start, end := fReader.slotRange()
 	assert.Equal(t, uint16(5), start)
 	assert.Equal(t, uint16(5), end)
@@ -54,7 +54,7 @@ func TestField_read(t *testing.T) {
 	data = fReader.getFieldData(10)
 	assert.Nil(t, data)
 	// case 6: no fields
-	fReader = newFieldReader(scanner.fieldIndexes(), []byte{0, 0, 0}, 0, 5, 5)
+	fReader = newFieldReader(scanner.version(), scanner.fieldIndexes(), []byte{0, 0, 0}, 0, 3, 5, 5)
 	data = fReader.getFieldData(10)
 	assert.Nil(t, data)
 }
@@ -65,8 +65,8 @@ func TestFieldReader_close(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	scanner := newDataScanner(r)
-	seriesPos := scanner.scan(0, 1)
-	fReader := newFieldReader(scanner.fieldIndexes(), block, seriesPos, 5, 5)
+	seriesPos, seriesEnd := scanner.scan(0, 1)
+	fReader := newFieldReader(scanner.version(), scanner.fieldIndexes(), block, seriesPos, seriesEnd, 5, 5)
 	fReader.close()
 	data := fReader.getFieldData(2)
 	assert.Nil(t, data)
@@ -78,8 +78,8 @@ func TestFieldReader_reset(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	scanner := newDataScanner(r)
-	seriesPos := scanner.scan(0, 1)
-	fReader := newFieldReader(scanner.fieldIndexes(), block, seriesPos, 5, 5)
+	seriesPos, seriesEnd := scanner.scan(0, 1)
+	fReader := newFieldReader(scanner.version(), scanner.fieldIndexes(), block, seriesPos, seriesEnd, 5, 5)
 	start, end := fReader.slotRange()
 	assert.Equal(t, uint16(5), start)
 	assert.Equal(t, uint16(5), end)
@@ -92,20 +92,28 @@ func TestFieldReader_reset(t *testing.T) {
 	nopKVFlusher := kv.NewNopFlusher()
 	flusher := NewFlusher(nopKVFlusher)
 	flusher.FlushFieldMetas(field.Metas{
+		{ID: 2, Type: field.SumField},
 		{ID: 10, Type: field.MinField},
 	})
+	flusher.FlushField(nil)
 	flusher.FlushField([]byte{1, 2, 3})
 	flusher.FlushSeries(10)
 	_ = flusher.FlushMetric(uint32(10), start, end)
 	block = nopKVFlusher.Bytes()
 
+	r, err = NewReader("1.sst", block)
+	assert.NoError(t, err)
+	seriesPos, seriesEnd = newDataScanner(r).scan(0, 10)
+
 	// reset value
-	fReader.reset(block, seriesPos, 15, 15)
+	fReader.reset(block, seriesPos, seriesEnd, 15, 15)
 	start, end = fReader.slotRange()
 	assert.Equal(t, uint16(15), start)
 	assert.Equal(t, uint16(15), end)
+	data = fReader.getFieldData(2)
+	assert.Nil(t, data)
 	data = fReader.getFieldData(10)
-	assert.True(t, len(data) > 0)
+	assert.Equal(t, []byte{1, 2, 3}, data)
 }
 func TestFieldReader_read_one_field(t *testing.T) {
 	block := mockMetricMergeBlockOneField([]uint32{1}, 5, 5)
@@ -113,8 +121,8 @@ func TestFieldReader_read_one_field(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	scanner := newDataScanner(r)
-	seriesPos := scanner.scan(0, 1)
-	fReader := newFieldReader(scanner.fieldIndexes(), block, seriesPos, 5, 5)
+	seriesPos, seriesEnd := scanner.scan(0, 1)
+	fReader := newFieldReader(scanner.version(), scanner.fieldIndexes(), block, seriesPos, seriesEnd, 5, 5)
 	start, end := fReader.slotRange()
 	assert.Equal(t, uint16(5), start)
 	assert.Equal(t, uint16(5), end)
@@ -38,7 +38,7 @@ type Filter interface {
 // metricsDataFilter represents the sst file data filter
 type metricsDataFilter struct {
 	familyTime int64
-	snapshot   version.Snapshot //FIXME stone1100, need close version snapshot
+	snapshot   version.Snapshot
 	readers    []MetricReader
 }
 
@@ -69,7 +69,7 @@ func (f *metricsDataFilter) Filter(
 			// series ids not found
 			continue
 		}
-		rs = append(rs, newFileFilterResultSet(f.familyTime, fields, matchSeriesIDs, reader))
+		rs = append(rs, newFileFilterResultSet(f.familyTime, fields, matchSeriesIDs, reader, f.snapshot))
 	}
 	// not founds
 	if len(rs) == 0 {
@@ -84,17 +84,19 @@ type fileFilterResultSet struct {
 	familyTime int64
 	fields     field.Metas
 	seriesIDs  *roaring.Bitmap
+	snapshot   version.Snapshot // shared by result sets of same family, retains the mapped files
 }
 
 // newFileFilterResultSet creates the file filter result set
 func newFileFilterResultSet(familyTime int64, fields field.Metas,
-	seriesIDs *roaring.Bitmap, reader MetricReader,
+	seriesIDs *roaring.Bitmap, reader MetricReader, snapshot version.Snapshot,
 ) flow.FilterResultSet {
 	return &fileFilterResultSet{
 		familyTime: familyTime,
 		reader:     reader,
 		fields:     fields,
 		seriesIDs:  seriesIDs,
+		snapshot:   snapshot,
 	}
 }
 
@@ -118,6 +120,13 @@ func (f *fileFilterResultSet) SlotRange() timeutil.SlotRange {
 	return f.reader.GetTimeRange()
 }
 
+// Close releases the version snapshot, then the mapped files can be unmapped.
+func (f *fileFilterResultSet) Close() {
+	if f.snapshot != nil {
+		f.snapshot.Close()
+	}
+}
+
 // Load reads data from sst files, then returns the data file scanner.
 func (f *fileFilterResultSet) Load(highKey uint16, seriesID roaring.Container) flow.DataLoader {
 	return f.reader.Load(highKey, seriesID, f.fields)
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/constants"
+	"github.com/lindb/lindb/kv/version"
 	"github.com/lindb/lindb/series/field"
 )
 
@@ -35,9 +36,21 @@ func TestFileFilterResultSet_Load(t *testing.T) {
 
 	reader := NewMockMetricReader(ctrl)
 
-	rs := newFileFilterResultSet(1, field.Metas{}, nil, reader)
+	rs := newFileFilterResultSet(1, field.Metas{}, nil, reader, nil)
 	reader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any())
 	rs.Load(0, nil)
+	// close without snapshot
+	rs.Close()
+}
+
+func TestFileFilterResultSet_Close(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	snapshot := version.NewMockSnapshot(ctrl)
+	rs := newFileFilterResultSet(1, field.Metas{}, nil, NewMockMetricReader(ctrl), snapshot)
+	snapshot.EXPECT().Close()
+	rs.Close()
 }
 
 func TestMetricsDataFilter_Filter(t *testing.T) {
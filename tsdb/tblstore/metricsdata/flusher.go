@@ -44,7 +44,7 @@ import (
 type Flusher interface {
 	// FlushFieldMetas writes the meta info a field
 	FlushFieldMetas(fieldMetas field.Metas)
-	// FlushField writes a compressed field data to writer.
+	// FlushField writes a tsd block(codec + compressed data) of field to writer.
 	FlushField(data []byte)
 	// FlushSeries writes a full series, this will be called after writing all fields of this entry.
 	FlushSeries(seriesID uint32)
@@ -97,10 +97,13 @@ func (w *flusher) FlushFieldMetas(fieldMetas field.Metas) {
 	w.fieldMetas = fieldMetas
 }
 
-// FlushField writes a compressed field data to writer.
+// FlushField writes a tsd block(codec + compressed data) of field to writer.
 func (w *flusher) FlushField(data []byte) {
 	hasData := len(data) > 0
-	w.seriesHasData = true
+	if hasData {
+		// if all fields of series haven't data(e.g. expired data dropped by compaction), needn't flush series
+		w.seriesHasData = true
+	}
 	if w.fieldMetas.Len() == 1 {
 		if hasData {
 			// if metric only has one field, just writes field data
@@ -212,6 +215,8 @@ func (w *flusher) FlushMetric(metricID uint32, start, end uint16) error {
 		// write field-type
 		w.writer.PutByte(byte(fm.Type))
 	}
+	// write version of metric block
+	w.writer.PutByte(metricBlockVersion1)
 	// write series ids bitmap
 	seriesIDsBlock, err := encoding.BitmapMarshal(w.seriesIDs)
 	if err != nil {
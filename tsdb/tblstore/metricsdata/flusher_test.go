@@ -71,6 +71,25 @@ func TestFlusher_flush_metric(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestFlusher_drop_empty_series(t *testing.T) {
+	nopKVFlusher := kv.NewNopFlusher()
+	flusher := NewFlusher(nopKVFlusher)
+	flusher.FlushFieldMetas([]field.Meta{{ID: 1, Type: field.SumField}, {ID: 2, Type: field.SumField}})
+	flusher.FlushField([]byte{1, 2, 3})
+	flusher.FlushField(nil)
+	flusher.FlushSeries(10)
+	// all fields of series haven't data
+	flusher.FlushField(nil)
+	flusher.FlushField(nil)
+	flusher.FlushSeries(20)
+	err := flusher.FlushMetric(39, 10, 13)
+	assert.NoError(t, err)
+
+	r, err := NewReader("test", nopKVFlusher.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{10}, r.GetSeriesIDs().ToArray())
+}
+
 func TestFlusher_flush_big_series_id(t *testing.T) {
 	nopKVFlusher := kv.NewNopFlusher()
 	flusher := NewFlusher(nopKVFlusher)
@@ -30,6 +30,15 @@ import (
 
 var MetricDataMerger kv.MergerType = "MetricDataMerger"
 
+// TSDCodecParam is the merger param of the codec name for encoding tsd block when merging
+const TSDCodecParam = "tsdCodec"
+
+// DropFieldsParam is the merger param of FieldDropper, the dropped fields are removed when merging
+const DropFieldsParam = "dropFields"
+
+// FieldDropper returns if the field data of metric need to be dropped when merging
+type FieldDropper func(metricID uint32, fieldID field.ID) bool
+
 // init registers metric data merger create function
 func init() {
 	kv.RegisterMerger(MetricDataMerger, NewMerger)
@@ -42,6 +51,8 @@ type mergerContext struct {
 
 	targetRange, sourceRange timeutil.SlotRange
 	ratio                    uint16
+
+	codec encoding.TSDCodec // codec for encoding target tsd block
 }
 
 // merger implements kv.Merger for merging series data for each metric
@@ -50,6 +61,8 @@ type merger struct {
 	flusher      *kv.NopFlusher
 	seriesMerger SeriesMerger
 	rollup       kv.Rollup
+	codec        encoding.TSDCodec
+	dropFields   FieldDropper
 }
 
 // NewMerger creates a metric data merger
@@ -69,6 +82,13 @@ func (m *merger) Init(params map[string]interface{}) {
 	if ok {
 		m.rollup = rollupCtx.(kv.Rollup)
 	}
+	if codecName, ok := params[TSDCodecParam].(string); ok {
+		// ignore unknown codec, uses default xor codec
+		m.codec, _ = encoding.ParseTSDCodec(codecName)
+	}
+	if dropFields, ok := params[DropFieldsParam].(FieldDropper); ok {
+		m.dropFields = dropFields
+	}
 }
 
 // Merge merges the multi metric data into one target metric data for same metric id
@@ -79,6 +99,13 @@ func (m *merger) Merge(key uint32, values [][]byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if m.dropFields != nil {
+		mergeCtx.targetFields = m.filterFields(key, mergeCtx.targetFields)
+		if len(mergeCtx.targetFields) == 0 {
+			// all fields dropped, removes the metric data
+			return nil, nil
+		}
+	}
 	// 2. flush fields
 	m.dataFlusher.FlushFieldMetas(mergeCtx.targetFields)
 	// 3. merge series data by roaring container
@@ -98,14 +125,14 @@ func (m *merger) Merge(key uint32, values [][]byte) ([]byte, error) {
 			lowSeriesID := it.Next()
 			// maybe series id not exist in some value block
 			for blockIdx, scanner := range mergeCtx.scanners {
-				seriesPos := scanner.scan(highKey, lowSeriesID)
+				seriesPos, dataEnd := scanner.scan(highKey, lowSeriesID)
 				if seriesPos >= 0 {
 					timeRange := scanner.slotRange()
 					if fieldReaders[blockIdx] == nil {
-						fieldReaders[blockIdx] = newFieldReader(scanner.fieldIndexes(),
-							values[blockIdx], seriesPos, timeRange.Start, timeRange.End)
+						fieldReaders[blockIdx] = newFieldReader(scanner.version(), scanner.fieldIndexes(),
+							values[blockIdx], seriesPos, dataEnd, timeRange.Start, timeRange.End)
 					} else {
-						fieldReaders[blockIdx].reset(values[blockIdx], seriesPos, timeRange.Start, timeRange.End)
+						fieldReaders[blockIdx].reset(values[blockIdx], seriesPos, dataEnd, timeRange.Start, timeRange.End)
 					}
 				}
 			}
@@ -123,11 +150,23 @@ func (m *merger) Merge(key uint32, values [][]byte) ([]byte, error) {
 	return m.flusher.Bytes(), nil
 }
 
+// filterFields removes the dropped fields from target fields
+func (m *merger) filterFields(metricID uint32, fields field.Metas) field.Metas {
+	result := fields[:0]
+	for _, f := range fields {
+		if !m.dropFields(metricID, f.ID) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
 func (m *merger) prepare(values [][]byte) (*mergerContext, error) {
 	ctx := &mergerContext{
 		scanners:     make([]*dataScanner, len(values)),
 		seriesIDs:    roaring.New(),
 		targetFields: field.Metas{},
+		codec:        m.codec,
 	}
 
 	for idx, value := range values {
@@ -19,12 +19,15 @@ package metricsdata
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/lindb/roaring"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/pkg/encoding"
 	"github.com/lindb/lindb/series/field"
 )
 
@@ -88,6 +91,80 @@ func TestMerger_Compact_Merge(t *testing.T) {
 	assert.Nil(t, data)
 }
 
+func TestMerger_Init_codec(t *testing.T) {
+	merge := NewMerger()
+	m := merge.(*merger)
+	merge.Init(nil)
+	assert.Equal(t, encoding.TSDCodecXOR, m.codec)
+	merge.Init(map[string]interface{}{TSDCodecParam: "zstd"})
+	assert.Equal(t, encoding.TSDCodecZstd, m.codec)
+	ctx, err := m.prepare([][]byte{mockMetricMergeBlock([]uint32{1}, 10, 10)})
+	assert.NoError(t, err)
+	assert.Equal(t, encoding.TSDCodecZstd, ctx.codec)
+	merge.Init(map[string]interface{}{TSDCodecParam: "unknown"})
+	assert.Equal(t, encoding.TSDCodecXOR, m.codec)
+}
+
+func TestMerger_Merge_dropFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	flusher := NewMockFlusher(ctrl)
+	seriesMerger := NewMockSeriesMerger(ctrl)
+	merge := NewMerger()
+	m := merge.(*merger)
+	m.dataFlusher = flusher
+	m.seriesMerger = seriesMerger
+	// case 1: drop part of fields
+	merge.Init(map[string]interface{}{DropFieldsParam: FieldDropper(func(metricID uint32, fieldID field.ID) bool {
+		return metricID == 1 && fieldID == 10
+	})})
+	flusher.EXPECT().FlushFieldMetas(field.Metas{{ID: 2, Type: field.SumField}})
+	seriesMerger.EXPECT().merge(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	flusher.EXPECT().FlushSeries(uint32(1))
+	flusher.EXPECT().FlushMetric(uint32(1), uint16(10), uint16(10)).Return(nil)
+	_, err := merge.Merge(1, [][]byte{mockMetricMergeBlock([]uint32{1}, 10, 10)})
+	assert.NoError(t, err)
+	// case 2: drop all fields
+	merge.Init(map[string]interface{}{DropFieldsParam: FieldDropper(func(metricID uint32, fieldID field.ID) bool {
+		return true
+	})})
+	data, err := merge.Merge(1, [][]byte{mockMetricMergeBlock([]uint32{1}, 10, 10)})
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestMerger_Merge_legacyVersion(t *testing.T) {
+	nopKVFlusher := kv.NewNopFlusher()
+	flusher := NewFlusher(nopKVFlusher)
+	flusher.FlushFieldMetas(field.Metas{
+		{ID: 2, Type: field.SumField},
+		{ID: 10, Type: field.MinField},
+	})
+	flusher.FlushField(mockFieldValue(5, 10))
+	flusher.FlushField(mockFieldValue(5, 1.5))
+	flusher.FlushSeries(1)
+	_ = flusher.FlushMetric(uint32(10), 5, 5)
+
+	data, err := NewMerger().Merge(10, [][]byte{mockLegacyMetricBlock(), nopKVFlusher.Bytes()})
+	assert.NoError(t, err)
+	r, err := NewReader("1.sst", data)
+	assert.NoError(t, err)
+	loader := r.Load(0, roaring.BitmapOf(1, 2).GetContainer(0), field.Metas{{ID: 2}, {ID: 10}})
+	decoder := encoding.NewTSDDecoder(nil)
+	assertValue := func(data []byte, value float64) {
+		assert.NoError(t, decoder.ResetTSDBlock(data, 5, 5))
+		assert.True(t, decoder.HasValueWithSlot(5))
+		assert.Equal(t, value, math.Float64frombits(decoder.Value()))
+	}
+	_, fieldsData := loader.Load(1)
+	assertValue(fieldsData[0], 11)
+	assertValue(fieldsData[1], 1.5)
+	_, fieldsData = loader.Load(2)
+	assert.NoError(t, decoder.ResetTSDBlock(fieldsData[0], 5, 5))
+	assert.False(t, decoder.HasValueWithSlot(5))
+	assertValue(fieldsData[1], 4)
+}
+
 func TestMerger_Rollup_Merge(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
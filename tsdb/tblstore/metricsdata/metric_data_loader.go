@@ -30,17 +30,20 @@ type metricLoader struct {
 	reader        MetricReader
 	lowContainer  roaring.Container
 	seriesOffsets *encoding.FixedOffsetDecoder
+	lowOffsetsPos int
 }
 
 // newMetricLoader creates a file storage metric loader.
 func newMetricLoader(reader MetricReader,
 	lowContainer roaring.Container,
 	seriesOffsets *encoding.FixedOffsetDecoder,
+	lowOffsetsPos int,
 ) flow.DataLoader {
 	return &metricLoader{
 		reader:        reader,
 		lowContainer:  lowContainer,
 		seriesOffsets: seriesOffsets,
+		lowOffsetsPos: lowOffsetsPos,
 	}
 }
 
@@ -54,6 +57,7 @@ func (s *metricLoader) Load(lowSeriesID uint16) (timeutil.SlotRange, [][]byte) {
 	idx := s.lowContainer.Rank(lowSeriesID)
 	// scan the data and aggregate the values
 	seriesPos, _ := s.seriesOffsets.Get(idx - 1)
+	end := seriesEnd(s.seriesOffsets, s.lowContainer, idx, s.lowOffsetsPos)
 	// read series data of fields
-	return s.reader.GetTimeRange(), s.reader.readSeriesData(seriesPos)
+	return s.reader.GetTimeRange(), s.reader.readSeriesData(seriesPos, end)
 }
@@ -35,14 +35,14 @@ func TestMetricLoader_Load(t *testing.T) {
 	r.EXPECT().GetTimeRange().Return(timeutil.SlotRange{}).MaxTimes(2)
 
 	// case 1: series id not exist
-	s := newMetricLoader(r, roaring.BitmapOf(10).GetContainer(0), nil)
+	s := newMetricLoader(r, roaring.BitmapOf(10).GetContainer(0), nil, 0)
 	s.Load(1)
 	// case 2: read series data
-	r.EXPECT().readSeriesData(gomock.Any())
+	r.EXPECT().readSeriesData(100, 200)
 	encoder := encoding.NewFixedOffsetEncoder()
 	encoder.Add(100)
 	data := encoder.MarshalBinary()
 	seriesOffsets := encoding.NewFixedOffsetDecoder(data)
-	s = newMetricLoader(r, roaring.BitmapOf(10).GetContainer(0), seriesOffsets)
+	s = newMetricLoader(r, roaring.BitmapOf(10).GetContainer(0), seriesOffsets, 200)
 	s.Load(10)
 }
@@ -36,6 +36,14 @@ var (
 	getOffsetFunc = getOffset
 )
 
+// Defines all versions of metric block, the version is written after field metas.
+const (
+	// metricBlockVersion0 is the legacy metric block without version, field data is xor compressed tsd stream.
+	metricBlockVersion0 byte = iota
+	// metricBlockVersion1 is the metric block whose field data is tsd block with codec header.
+	metricBlockVersion1
+)
+
 const (
 	dataFooterSize = 2 + // start time slot
 		2 + // end time slot
@@ -57,8 +65,8 @@ type MetricReader interface {
 	GetTimeRange() timeutil.SlotRange
 	// Load loads the data from sst file, then returns the file metric scanner.
 	Load(highKey uint16, seriesID roaring.Container, fields field.Metas) flow.DataLoader
-	// readSeriesData reads series data from file by given position.
-	readSeriesData(position int) [][]byte
+	// readSeriesData reads series data from file by given position[start, end).
+	readSeriesData(start, end int) [][]byte
 }
 
 // metricReader implements MetricReader interface that reads metric block
@@ -70,6 +78,7 @@ type metricReader struct {
 	fields        field.Metas
 	crc32CheckSum uint32
 	timeRange     timeutil.SlotRange
+	version       byte
 
 	readFieldIndexes []int // read field indexes be used when query metric data
 }
@@ -147,18 +156,19 @@ func (r *metricReader) Load(highKey uint16, seriesID roaring.Container, fields f
 		return nil
 	}
 	// must use lowContainer from store, because get series index based on container
-	return newMetricLoader(r, lowContainer, seriesOffsets)
+	// low offsets block is written after series data of container
+	return newMetricLoader(r, lowContainer, seriesOffsets, offset)
 }
 
-// readSeriesData reads series data from file by given position.
-func (r *metricReader) readSeriesData(position int) [][]byte {
+// readSeriesData reads series data from file by given position[start, end).
+func (r *metricReader) readSeriesData(start, end int) [][]byte {
 	fieldCount := r.fields.Len()
 	if fieldCount == 1 {
 		// metric has one field, just read the data
-		return [][]byte{r.buf[position:]}
+		return [][]byte{fieldBlock(r.version, r.buf[start:end])}
 	}
 	// read data for multi-fields
-	seriesData := r.buf[position:]
+	seriesData := r.buf[start:end]
 	fieldOffsets := encoding.NewFixedOffsetDecoder(seriesData)
 	fieldsData := seriesData[fieldOffsets.Header()+fieldCount*fieldOffsets.ValueWidth():]
 	rs := make([][]byte, len(r.readFieldIndexes))
@@ -169,7 +179,7 @@ func (r *metricReader) readSeriesData(position int) [][]byte {
 		offset, ok := fieldOffsets.Get(idx)
 		if ok {
 			// read field data
-			rs[i] = fieldsData[offset:]
+			rs[i] = fieldBlock(r.version, fieldData(r.version, fieldOffsets, fieldsData, idx, offset, fieldCount))
 		}
 	}
 	return rs
@@ -206,6 +216,13 @@ func (r *metricReader) initReader() error {
 		}
 		offset += 2
 	}
+	// read version of metric block, legacy metric block hasn't version
+	if offset < seriesIDsStartPos {
+		r.version = r.buf[offset]
+		if r.version != metricBlockVersion1 {
+			return fmt.Errorf("unknown metric block version: %d", r.version)
+		}
+	}
 
 	// read series ids
 	seriesIDs := roaring.New()
@@ -233,9 +250,10 @@ type dataScanner struct {
 	container     roaring.Container
 	seriesOffsets *encoding.FixedOffsetDecoder
 
-	highKeys  []uint16
-	highKey   uint16
-	seriesPos int
+	highKeys      []uint16
+	highKey       uint16
+	seriesPos     int
+	lowOffsetsPos int // low offsets block is written after series data of container
 }
 
 // newDataScanner creates a data scanner for data merge
@@ -259,6 +277,7 @@ func (s *dataScanner) nextContainer() {
 	s.highKey = s.highKeys[s.seriesPos]
 	s.container = s.reader.seriesIDs.GetContainerAtIndex(s.seriesPos)
 	offset, _ := s.reader.highOffsets.Get(s.seriesPos)
+	s.lowOffsetsPos = offset
 	s.seriesOffsets = encoding.NewFixedOffsetDecoder(s.reader.buf[offset:])
 	s.seriesPos++
 }
@@ -268,18 +287,23 @@ func (s *dataScanner) slotRange() timeutil.SlotRange {
 	return s.reader.GetTimeRange()
 }
 
-// scan scans the data and returns series position if series id exist, else returns -1
-func (s *dataScanner) scan(highKey, lowSeriesID uint16) int {
+// version returns the version of metric block
+func (s *dataScanner) version() byte {
+	return s.reader.version
+}
+
+// scan scans the data and returns series position[start, end) if series id exist, else returns -1
+func (s *dataScanner) scan(highKey, lowSeriesID uint16) (start, end int) {
 	if s.highKey < highKey {
 		if s.seriesPos >= len(s.highKeys) {
 			// current tag inverted no data can read
-			return -1
+			return -1, -1
 		}
 		s.nextContainer()
 	}
 	if highKey != s.highKey {
 		// high key not match, return it
-		return -1
+		return -1, -1
 	}
 	// find data by low series id
 	if s.container.Contains(lowSeriesID) {
@@ -288,11 +312,47 @@ func (s *dataScanner) scan(highKey, lowSeriesID uint16) int {
 		// get series data data position
 		offset, ok := getOffsetFunc(s.seriesOffsets, idx-1)
 		if !ok {
-			return -1
+			return -1, -1
 		}
-		return offset
+		return offset, seriesEnd(s.seriesOffsets, s.container, idx, s.lowOffsetsPos)
+	}
+	return -1, -1
+}
+
+// seriesEnd returns the end position of series data by the rank of series id,
+// which is the position of next series or the low offsets block of container.
+func seriesEnd(seriesOffsets *encoding.FixedOffsetDecoder, container roaring.Container, rank, lowOffsetsPos int) int {
+	if rank < container.GetCardinality() {
+		if offset, ok := seriesOffsets.Get(rank); ok {
+			return offset
+		}
+	}
+	return lowOffsetsPos
+}
+
+// fieldData returns the field data of series by field index,
+// field data of legacy metric block hasn't length, so it ends with next field data or series data.
+func fieldData(version byte, fieldOffsets *encoding.FixedOffsetDecoder,
+	fieldsData []byte, idx, offset, fieldCount int,
+) []byte {
+	if version != metricBlockVersion0 {
+		return fieldsData[offset:]
+	}
+	for next := idx + 1; next < fieldCount; next++ {
+		if end, ok := fieldOffsets.Get(next); ok {
+			return fieldsData[offset:end]
+		}
+	}
+	return fieldsData[offset:]
+}
+
+// fieldBlock returns the tsd block of field data,
+// field data of legacy metric block is xor compressed tsd stream, which is copied into tsd block with xor codec.
+func fieldBlock(version byte, data []byte) []byte {
+	if version == metricBlockVersion0 {
+		return encoding.NewTSDBlock(data)
 	}
-	return -1
+	return data
 }
 
 // getOffset returns the offset by idx
@@ -18,6 +18,7 @@
 package metricsdata
 
 import (
+	"encoding/binary"
 	"fmt"
 	"math"
 	"testing"
@@ -119,6 +120,32 @@ func TestReader_Load(t *testing.T) {
 	assert.Nil(t, scanner)
 }
 
+func TestReader_version(t *testing.T) {
+	// case 1: legacy metric block, field data is xor compressed tsd stream
+	r, err := NewReader("1.sst", mockLegacyMetricBlock())
+	assert.NoError(t, err)
+	loader := r.Load(0, roaring.BitmapOf(1, 2).GetContainer(0), field.Metas{{ID: 2}, {ID: 10}})
+	decoder := encoding.NewTSDDecoder(nil)
+	_, fieldsData := loader.Load(1)
+	assert.Len(t, fieldsData, 2)
+	for idx, value := range []float64{1, 2} {
+		assert.Equal(t, mockFieldValue(5, value), fieldsData[idx])
+		assert.NoError(t, decoder.ResetTSDBlock(fieldsData[idx], 5, 5))
+		assert.True(t, decoder.HasValueWithSlot(5))
+		assert.Equal(t, value, math.Float64frombits(decoder.Value()))
+	}
+	_, fieldsData = loader.Load(2)
+	assert.Nil(t, fieldsData[0])
+	assert.Equal(t, mockFieldValue(5, 4), fieldsData[1])
+	// case 2: unknown version
+	block := mockMetricBlock()
+	footerPos := len(block) - dataFooterSize
+	block[binary.LittleEndian.Uint32(block[footerPos+8:])-1] = 100
+	r, err = NewReader("1.sst", block)
+	assert.Error(t, err)
+	assert.Nil(t, r)
+}
+
 func TestReader_scan(t *testing.T) {
 	defer func() {
 		getOffsetFunc = getOffset
@@ -132,27 +159,27 @@ func TestReader_scan(t *testing.T) {
 	assert.Equal(t, uint16(5), timeRange.Start)
 	assert.Equal(t, uint16(5), timeRange.End)
 	// case 1: not match
-	seriesPos := scanner.scan(10, 10)
+	seriesPos, _ := scanner.scan(10, 10)
 	assert.True(t, seriesPos < 0)
 	// case 2: merge data
 	scanner = newDataScanner(r)
-	seriesPos = scanner.scan(0, 0)
+	seriesPos, _ = scanner.scan(0, 0)
 	assert.True(t, seriesPos >= 0)
-	seriesPos = scanner.scan(1, 10)
+	seriesPos, _ = scanner.scan(1, 10)
 	assert.True(t, seriesPos >= 0)
 	// case 3: scan completed
-	seriesPos = scanner.scan(3, 10)
+	seriesPos, _ = scanner.scan(3, 10)
 	assert.True(t, seriesPos < 0)
 	// case 4: not match
 	scanner = newDataScanner(r)
-	seriesPos = scanner.scan(0, 10)
+	seriesPos, _ = scanner.scan(0, 10)
 	assert.True(t, seriesPos < 0)
 	// case 6: get wrong offset
 	scanner = newDataScanner(r)
 	getOffsetFunc = func(seriesOffsets *encoding.FixedOffsetDecoder, idx int) (int, bool) {
 		return 0, false
 	}
-	seriesPos = scanner.scan(0, 0)
+	seriesPos, _ = scanner.scan(0, 0)
 	assert.True(t, seriesPos < 0)
 	fields := scanner.fieldIndexes()
 	assert.Len(t, fields, 4)
@@ -174,6 +201,7 @@ func mockMetricBlock() []byte {
 			encoder.AppendValue(math.Float64bits(float64(10.0 * i)))
 		}
 		data, _ := encoder.BytesWithoutTime()
+		data = encoding.NewTSDBlock(data)
 		flusher.FlushField(data)
 		flusher.FlushField(data)
 		flusher.FlushField(data)
@@ -185,7 +213,7 @@ func mockMetricBlock() []byte {
 	encoder.AppendTime(bit.One)
 	encoder.AppendValue(math.Float64bits(10.0))
 	data, _ := encoder.BytesWithoutTime()
-	flusher.FlushField(data)
+	flusher.FlushField(encoding.NewTSDBlock(data))
 	flusher.FlushSeries(uint32(65536 + 10))
 	_ = flusher.FlushMetric(uint32(10), 5, 5)
 
@@ -205,7 +233,7 @@ func mockMetricBlockForOneField() []byte {
 			encoder.AppendValue(math.Float64bits(float64(10.0 * i)))
 		}
 		data, _ := encoder.BytesWithoutTime()
-		flusher.FlushField(data)
+		flusher.FlushField(encoding.NewTSDBlock(data))
 		flusher.FlushSeries(uint32(j * 4096))
 	}
 	// mock just has one field
@@ -213,8 +241,35 @@ func mockMetricBlockForOneField() []byte {
 	encoder.AppendTime(bit.One)
 	encoder.AppendValue(math.Float64bits(10.0))
 	data, _ := encoder.BytesWithoutTime()
-	flusher.FlushField(data)
+	flusher.FlushField(encoding.NewTSDBlock(data))
 	flusher.FlushSeries(uint32(65536 + 10))
 	_ = flusher.FlushMetric(uint32(10), 5, 5)
 	return nopKVFlusher.Bytes()
 }
+
+// mockLegacyMetricBlock mocks the legacy metric block without version, field data is xor compressed tsd stream.
+func mockLegacyMetricBlock() []byte {
+	nopKVFlusher := kv.NewNopFlusher()
+	flusher := NewFlusher(nopKVFlusher)
+	flusher.FlushFieldMetas(field.Metas{
+		{ID: 2, Type: field.SumField},
+		{ID: 10, Type: field.MinField},
+	})
+	flusher.FlushField(mockFieldValue(5, 1)[1:])
+	flusher.FlushField(mockFieldValue(5, 2)[1:])
+	flusher.FlushSeries(1)
+	flusher.FlushField(nil)
+	flusher.FlushField(mockFieldValue(5, 4)[1:])
+	flusher.FlushSeries(2)
+	_ = flusher.FlushMetric(uint32(10), 5, 5)
+	block := nopKVFlusher.Bytes()
+	// remove version after field metas
+	footerPos := len(block) - dataFooterSize
+	seriesIDsPos := binary.LittleEndian.Uint32(block[footerPos+8:])
+	offsetPos := binary.LittleEndian.Uint32(block[footerPos+12:])
+	block = append(block[:seriesIDsPos-1], block[seriesIDsPos:]...)
+	footerPos--
+	binary.LittleEndian.PutUint32(block[footerPos+8:], seriesIDsPos-1)
+	binary.LittleEndian.PutUint32(block[footerPos+12:], offsetPos-1)
+	return block
+}
@@ -19,7 +19,9 @@ package metricsdata
 
 import (
 	"github.com/lindb/lindb/aggregation"
+	"github.com/lindb/lindb/pkg/bit"
 	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/series/field"
 )
 
 //go:generate mockgen -source ./series_merger.go -destination=./series_merger_mock.go -package metricsdata
@@ -36,6 +38,8 @@ type SeriesMerger interface {
 // seriesMerger implements SeriesMerger interface
 type seriesMerger struct {
 	flusher Flusher
+
+	intEncoder, boolEncoder *encoding.TSDValueEncoder // encoders for int/bool field data, lazy init
 }
 
 // newSeriesMerger creates a series merger
@@ -54,6 +58,10 @@ func (sm *seriesMerger) merge(mergeCtx *mergerContext,
 	downSampling := aggregation.NewDownSamplingAggregator(mergeCtx.sourceRange, mergeCtx.targetRange, mergeCtx.ratio, rs)
 	for _, f := range mergeCtx.targetFields {
 		fieldID := f.ID
+		aggFunc := f.Type.GetAggFunc()
+		hasData := false
+		// bool is the identity of value type merging
+		valueType := field.BoolValue
 
 		for idx, reader := range fieldReaders {
 			if reader == nil {
@@ -67,21 +75,37 @@ func (sm *seriesMerger) merge(mergeCtx *mergerContext,
 					streams[idx] = encoding.GetTSDDecoder()
 				}
 				oldStart, oldEnd := reader.slotRange()
-				// reset tsd data
-				streams[idx].ResetWithTimeRange(fieldData, oldStart, oldEnd)
+				// reset tsd block
+				if err := streams[idx].ResetTSDBlock(fieldData, oldStart, oldEnd); err != nil {
+					return err
+				}
+				hasData = true
+				valueType = valueType.Merge(valueTypeOf(streams[idx].Codec()))
 			}
 		}
+		if hasData && valueType != field.FloatValue {
+			// all field data are int/bool values, merges them natively
+			encoder := sm.valueEncoder(valueType.Aggregated(aggFunc.AggType()))
+			mergeIntValues(mergeCtx, aggFunc, streams, encoder)
+			data, err := encoder.Bytes()
+			if err != nil {
+				return err
+			}
+			sm.flusher.FlushField(data)
+			encoder.Reset()
+			continue
+		}
 		// merges field data from source time range => target time range,
 		// compact merge: source range = target range and ratio = 1
 		// rollup merge: source range[5,182]=>target range[0,6], ratio:30, source interval:10s, target interval:5min
-		downSampling.DownSampling(f.Type.GetAggFunc(), streams)
+		downSampling.DownSampling(aggFunc, streams)
 		data, err := encodeStream.BytesWithoutTime()
 		if err != nil {
 			return err
 		}
 
 		// flush field data
-		sm.flusher.FlushField(data)
+		sm.flusher.FlushField(encoding.EncodeTSDBlock(mergeCtx.codec, data))
 		encodeStream.Reset() // reset tsd compress stream for next loop
 	}
 
@@ -94,3 +118,65 @@ func (sm *seriesMerger) merge(mergeCtx *mergerContext,
 	}
 	return nil
 }
+
+// valueEncoder returns the encoder for int/bool field data
+func (sm *seriesMerger) valueEncoder(valueType field.ValueType) *encoding.TSDValueEncoder {
+	if valueType == field.BoolValue {
+		if sm.boolEncoder == nil {
+			sm.boolEncoder = encoding.NewTSDValueEncoder(encoding.TSDCodecBool)
+		}
+		return sm.boolEncoder
+	}
+	if sm.intEncoder == nil {
+		sm.intEncoder = encoding.NewTSDValueEncoder(encoding.TSDCodecInt)
+	}
+	return sm.intEncoder
+}
+
+// valueTypeOf returns the value type of field data by the codec of tsd block
+func valueTypeOf(codec encoding.TSDCodec) field.ValueType {
+	switch codec {
+	case encoding.TSDCodecInt:
+		return field.IntValue
+	case encoding.TSDCodecBool:
+		return field.BoolValue
+	default:
+		return field.FloatValue
+	}
+}
+
+// mergeIntValues merges int/bool field data from source time range => target time range by int64 aggregation,
+// so that integers keep the precision, values are merged slot by slot same as down sampling.
+func mergeIntValues(mergeCtx *mergerContext, aggFunc field.AggFunc, streams []*encoding.TSDDecoder, encoder encoding.TSDEncoder) {
+	pos := int(mergeCtx.sourceRange.Start)
+	end := int(mergeCtx.sourceRange.End)
+	ratio := int(mergeCtx.ratio)
+	for j := int(mergeCtx.targetRange.Start); j <= int(mergeCtx.targetRange.End); j++ {
+		intervalEnd := ratio * (j + 1)
+		hasValue := false
+		var result int64
+		for ; pos <= end && pos < intervalEnd; pos++ {
+			for _, stream := range streams {
+				if stream == nil || !stream.HasValueWithSlot(uint16(pos)) {
+					// if series id not exist, stream maybe nil
+					continue
+				}
+				value := stream.IntValue()
+				if !hasValue {
+					// if target value not exist, set it
+					result = value
+					hasValue = true
+				} else {
+					// if target value exist, do aggregate
+					result = aggFunc.AggregateInt(result, value)
+				}
+			}
+		}
+		if hasValue {
+			encoder.AppendTime(bit.One)
+			encoder.AppendValue(uint64(result))
+		} else {
+			encoder.AppendTime(bit.Zero)
+		}
+	}
+}
@@ -63,7 +63,7 @@ func TestSeriesMerger_compact_merge(t *testing.T) {
 		}, decodeStreams, encodeStream, readers)
 	assert.NoError(t, err)
 	tsd := encoding.GetTSDDecoder()
-	tsd.ResetWithTimeRange(result, 5, 15)
+	assert.NoError(t, tsd.ResetTSDBlock(result, 5, 15))
 	slot := uint16(0)
 	for i := uint16(5); i <= 15; i++ {
 		if tsd.HasValueWithSlot(i) {
@@ -88,7 +88,7 @@ func TestSeriesMerger_compact_merge(t *testing.T) {
 			ratio:        1,
 		}, decodeStreams, encodeStream, readers)
 	assert.NoError(t, err)
-	tsd.ResetWithTimeRange(result, 5, 15)
+	assert.NoError(t, tsd.ResetTSDBlock(result, 5, 15))
 	c := 0
 	for i := uint16(5); i <= 15; i++ {
 		if tsd.HasValueWithSlot(i) && (i == 10 || i == 12) {
@@ -114,6 +114,17 @@ func TestSeriesMerger_compact_merge(t *testing.T) {
 			ratio:        1,
 		}, decodeStreams, encodeStream2, readers)
 	assert.Error(t, err)
+	// case 4: corrupted tsd block
+	reader1.EXPECT().getFieldData(gomock.Any()).Return([]byte{byte(encoding.TSDCodecZstd), 1, 2, 3})
+	reader1.EXPECT().slotRange().Return(uint16(10), uint16(10))
+	err = merger.merge(
+		&mergerContext{
+			targetFields: field.Metas{{ID: 1, Type: field.SumField}},
+			sourceRange:  timeutil.SlotRange{Start: 5, End: 15},
+			targetRange:  timeutil.SlotRange{Start: 5, End: 15},
+			ratio:        1,
+		}, decodeStreams, encodeStream, readers)
+	assert.Error(t, err)
 }
 
 func TestSeriesMerger_rollup_merge(t *testing.T) {
@@ -149,7 +160,7 @@ func TestSeriesMerger_rollup_merge(t *testing.T) {
 		}, decodeStreams, encodeStream, readers)
 	assert.NoError(t, err)
 	tsd := encoding.GetTSDDecoder()
-	tsd.ResetWithTimeRange(result, 0, 0)
+	assert.NoError(t, tsd.ResetTSDBlock(result, 0, 0))
 	slot := uint16(0)
 	for i := uint16(0); i <= 0; i++ {
 		if tsd.HasValueWithSlot(i) {
@@ -176,7 +187,7 @@ func TestSeriesMerger_rollup_merge(t *testing.T) {
 		}, decodeStreams, encodeStream, readers)
 	assert.NoError(t, err)
 	tsd = encoding.GetTSDDecoder()
-	tsd.ResetWithTimeRange(result, 0, 6)
+	assert.NoError(t, tsd.ResetTSDBlock(result, 0, 6))
 	c := 0
 	for i := uint16(0); i <= 6; i++ {
 		if tsd.HasValueWithSlot(i) && (i == 0 || i == 6) {
@@ -187,10 +198,123 @@ func TestSeriesMerger_rollup_merge(t *testing.T) {
 	assert.Equal(t, 2, c)
 }
 
+func TestSeriesMerger_int_merge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flusher := NewMockFlusher(ctrl)
+	merger := newSeriesMerger(flusher)
+	decodeStreams := make([]*encoding.TSDDecoder, 2)
+	reader1 := NewMockFieldReader(ctrl)
+	reader2 := NewMockFieldReader(ctrl)
+	reader1.EXPECT().close().AnyTimes()
+	reader2.EXPECT().close().AnyTimes()
+	readers := []FieldReader{reader1, reader2}
+	encodeStream := encoding.NewTSDEncoder(5)
+	var result []byte
+	flusher.EXPECT().FlushField(gomock.Any()).DoAndReturn(func(data []byte) {
+		result = data
+	}).AnyTimes()
+	tsd := encoding.GetTSDDecoder()
+	defer encoding.ReleaseTSDDecoder(tsd)
+
+	// case 1: compact int values above 2^53, keeps precision
+	reader1.EXPECT().getFieldData(gomock.Any()).Return(mockIntField(encoding.TSDCodecInt, 1<<60+1))
+	reader1.EXPECT().slotRange().Return(uint16(10), uint16(10))
+	reader2.EXPECT().getFieldData(gomock.Any()).Return(mockIntField(encoding.TSDCodecInt, 1<<60+1))
+	reader2.EXPECT().slotRange().Return(uint16(10), uint16(10))
+	err := merger.merge(
+		&mergerContext{
+			targetFields: field.Metas{{ID: 1, Type: field.SumField}},
+			sourceRange:  timeutil.SlotRange{Start: 5, End: 15},
+			targetRange:  timeutil.SlotRange{Start: 5, End: 15},
+			ratio:        1,
+			codec:        encoding.TSDCodecZstd,
+		}, decodeStreams, encodeStream, readers)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(encoding.TSDCodecInt), result[0])
+	assert.NoError(t, tsd.ResetTSDBlock(result, 5, 15))
+	for i := uint16(5); i <= 15; i++ {
+		assert.Equal(t, i == 10, tsd.HasValueWithSlot(i))
+		if i == 10 {
+			assert.Equal(t, int64(1<<61+2), tsd.IntValue())
+		}
+	}
+	// case 2: rollup bool values by last value
+	reader1.EXPECT().getFieldData(gomock.Any()).Return(mockIntField(encoding.TSDCodecBool, 1))
+	reader1.EXPECT().slotRange().Return(uint16(10), uint16(10))
+	reader2.EXPECT().getFieldData(gomock.Any()).Return(mockIntField(encoding.TSDCodecBool, 0))
+	reader2.EXPECT().slotRange().Return(uint16(12), uint16(12))
+	err = merger.merge(
+		&mergerContext{
+			targetFields: field.Metas{{ID: 1, Type: field.LastField}},
+			sourceRange:  timeutil.SlotRange{Start: 5, End: 182},
+			targetRange:  timeutil.SlotRange{Start: 0, End: 6},
+			ratio:        30,
+		}, decodeStreams, encodeStream, readers)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(encoding.TSDCodecBool), result[0])
+	assert.NoError(t, tsd.ResetTSDBlock(result, 0, 6))
+	assert.True(t, tsd.HasValueWithSlot(0))
+	assert.Equal(t, int64(0), tsd.IntValue())
+	// case 3: rollup bool/int values by sum, becomes int
+	reader1.EXPECT().getFieldData(gomock.Any()).Return(mockIntField(encoding.TSDCodecBool, 1))
+	reader1.EXPECT().slotRange().Return(uint16(10), uint16(10))
+	reader2.EXPECT().getFieldData(gomock.Any()).Return(mockIntField(encoding.TSDCodecInt, 5))
+	reader2.EXPECT().slotRange().Return(uint16(40), uint16(40))
+	err = merger.merge(
+		&mergerContext{
+			targetFields: field.Metas{{ID: 1, Type: field.SumField}},
+			sourceRange:  timeutil.SlotRange{Start: 5, End: 182},
+			targetRange:  timeutil.SlotRange{Start: 0, End: 6},
+			ratio:        30,
+		}, decodeStreams, encodeStream, readers)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(encoding.TSDCodecInt), result[0])
+	assert.NoError(t, tsd.ResetTSDBlock(result, 0, 6))
+	assert.True(t, tsd.HasValueWithSlot(0))
+	assert.Equal(t, int64(1), tsd.IntValue())
+	assert.True(t, tsd.HasValueWithSlot(1))
+	assert.Equal(t, int64(5), tsd.IntValue())
+	// case 4: merge int and float values, becomes float
+	reader1.EXPECT().getFieldData(gomock.Any()).Return(mockIntField(encoding.TSDCodecInt, 5))
+	reader1.EXPECT().slotRange().Return(uint16(10), uint16(10))
+	reader2.EXPECT().getFieldData(gomock.Any()).Return(mockFieldValue(10, 1.5))
+	reader2.EXPECT().slotRange().Return(uint16(10), uint16(10))
+	err = merger.merge(
+		&mergerContext{
+			targetFields: field.Metas{{ID: 1, Type: field.SumField}},
+			sourceRange:  timeutil.SlotRange{Start: 5, End: 15},
+			targetRange:  timeutil.SlotRange{Start: 5, End: 15},
+			ratio:        1,
+		}, decodeStreams, encodeStream, readers)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(encoding.TSDCodecXOR), result[0])
+	assert.NoError(t, tsd.ResetTSDBlock(result, 5, 15))
+	for i := uint16(5); i <= 15; i++ {
+		assert.Equal(t, i == 10, tsd.HasValueWithSlot(i))
+		if i == 10 {
+			assert.Equal(t, 6.5, math.Float64frombits(tsd.Value()))
+		}
+	}
+}
+
+func mockIntField(codec encoding.TSDCodec, value int64) []byte {
+	encoder := encoding.NewTSDValueEncoder(codec)
+	encoder.AppendTime(bit.One)
+	encoder.AppendValue(uint64(value))
+	data, _ := encoder.Bytes()
+	return data
+}
+
 func mockField(start uint16) []byte {
+	return mockFieldValue(start, 10.0)
+}
+
+func mockFieldValue(start uint16, value float64) []byte {
 	encoder := encoding.NewTSDEncoder(start)
 	encoder.AppendTime(bit.One)
-	encoder.AppendValue(math.Float64bits(10.0))
+	encoder.AppendValue(math.Float64bits(value))
 	data, _ := encoder.BytesWithoutTime()
-	return data
+	return encoding.NewTSDBlock(data)
 }
@@ -0,0 +1,190 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tagkeymeta
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/lindb/lindb/pkg/encoding"
+	"github.com/lindb/lindb/pkg/strutil"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+// maxCachedNumericIndexes is the max number of numeric index cached for tag key in kv table file
+const maxCachedNumericIndexes = 4096
+
+// numericIndexes caches the numeric indexes of tag key in kv table file
+var numericIndexes = newNumericIndexCache(maxCachedNumericIndexes)
+
+// NumericIndex represents the numeric tag values sorted by number with tag value ids,
+// which is used for range filter of numeric tag key, tag values which are not numeric will be ignored.
+type NumericIndex struct {
+	values      []float64
+	tagValueIDs []uint32
+}
+
+// NewNumericIndex creates an empty numeric index
+func NewNumericIndex() *NumericIndex {
+	return &NumericIndex{}
+}
+
+// NewNumericIndexFrom creates a numeric index with tag value=>id mappings
+func NewNumericIndexFrom(tagValues map[string]uint32) *NumericIndex {
+	idx := NewNumericIndex()
+	for tagValue, tagValueID := range tagValues {
+		idx.append(strutil.String2ByteSlice(tagValue), tagValueID)
+	}
+	idx.sort()
+	return idx
+}
+
+// Add adds tag value=>id mapping into index, keeps tag values in order.
+func (idx *NumericIndex) Add(tagValue string, tagValueID uint32) {
+	value, err := strconv.ParseFloat(tagValue, 64)
+	if err != nil {
+		return
+	}
+	pos := sort.SearchFloat64s(idx.values, value)
+	idx.values = append(idx.values, 0)
+	idx.tagValueIDs = append(idx.tagValueIDs, 0)
+	copy(idx.values[pos+1:], idx.values[pos:])
+	copy(idx.tagValueIDs[pos+1:], idx.tagValueIDs[pos:])
+	idx.values[pos] = value
+	idx.tagValueIDs[pos] = tagValueID
+}
+
+// Len returns the number of tag values in index
+func (idx *NumericIndex) Len() int {
+	return len(idx.values)
+}
+
+// FindTagValueIDs finds the tag value ids which tag value matches the range filter.
+func (idx *NumericIndex) FindTagValueIDs(op stmt.RangeOP, value float64) []uint32 {
+	// first position which tag value >= value
+	geIdx := sort.SearchFloat64s(idx.values, value)
+	// first position which tag value > value
+	gtIdx := geIdx
+	for gtIdx < len(idx.values) && idx.values[gtIdx] == value {
+		gtIdx++
+	}
+	switch op {
+	case stmt.LessThan:
+		return idx.tagValueIDs[:geIdx]
+	case stmt.LessEqual:
+		return idx.tagValueIDs[:gtIdx]
+	case stmt.GreaterThan:
+		return idx.tagValueIDs[gtIdx:]
+	case stmt.GreaterEqual:
+		return idx.tagValueIDs[geIdx:]
+	default:
+		return nil
+	}
+}
+
+// append appends tag value=>id mapping without order, need invoke sort after all tag values appended.
+func (idx *NumericIndex) append(tagValue []byte, tagValueID uint32) {
+	value, err := strconv.ParseFloat(string(tagValue), 64)
+	if err != nil {
+		return
+	}
+	idx.values = append(idx.values, value)
+	idx.tagValueIDs = append(idx.tagValueIDs, tagValueID)
+}
+
+// sort sorts tag values by number
+func (idx *NumericIndex) sort() {
+	sort.Sort(numericIndexSorter{idx})
+}
+
+// numericIndexSorter implements sort.Interface for sorting numeric index by tag value
+type numericIndexSorter struct {
+	*NumericIndex
+}
+
+func (s numericIndexSorter) Less(i, j int) bool { return s.values[i] < s.values[j] }
+func (s numericIndexSorter) Swap(i, j int) {
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+	s.tagValueIDs[i], s.tagValueIDs[j] = s.tagValueIDs[j], s.tagValueIDs[i]
+}
+
+// numericIndexKey represents the key of numeric index cache
+type numericIndexKey struct {
+	path     string
+	tagKeyID uint32
+}
+
+// numericIndexCache caches the numeric index built from tag key meta block,
+// because kv table file is immutable, the index can be reused until evicted.
+type numericIndexCache struct {
+	capacity int
+	indexes  map[numericIndexKey]*NumericIndex
+	mutex    sync.Mutex
+}
+
+// newNumericIndexCache creates numeric index cache with max capacity
+func newNumericIndexCache(capacity int) *numericIndexCache {
+	return &numericIndexCache{
+		capacity: capacity,
+		indexes:  make(map[numericIndexKey]*NumericIndex),
+	}
+}
+
+// getOrLoad returns the numeric index of tag key in kv table file, builds it by tag key meta block if not cached.
+func (c *numericIndexCache) getOrLoad(path string, tagKeyID uint32, tagKeyMetaBlock []byte) (*NumericIndex, error) {
+	key := numericIndexKey{path: path, tagKeyID: tagKeyID}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if idx, ok := c.indexes[key]; ok {
+		return idx, nil
+	}
+	idx, err := buildNumericIndex(tagKeyMetaBlock)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.indexes) >= c.capacity {
+		// evict any one
+		for k := range c.indexes {
+			delete(c.indexes, k)
+			break
+		}
+	}
+	c.indexes[key] = idx
+	return idx, nil
+}
+
+// buildNumericIndex builds numeric index by walking all tag values of tag key meta block.
+func buildNumericIndex(tagKeyMetaBlock []byte) (*NumericIndex, error) {
+	tagKeyMeta, err := newTagKeyMeta(tagKeyMetaBlock)
+	if err != nil {
+		return nil, err
+	}
+	itr, err := tagKeyMeta.PrefixIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	idx := NewNumericIndex()
+	for itr.Valid() {
+		idx.append(itr.Key(), encoding.ByteSlice2Uint32(itr.Value()))
+		itr.Next()
+	}
+	idx.sort()
+	return idx, nil
+}
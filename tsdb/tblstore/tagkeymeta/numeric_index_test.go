@@ -0,0 +1,78 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tagkeymeta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/lindb/kv"
+	"github.com/lindb/lindb/sql/stmt"
+)
+
+func TestNumericIndex_FindTagValueIDs(t *testing.T) {
+	idx := NewNumericIndex()
+	idx.Add("100", 1)
+	idx.Add("5", 2)
+	idx.Add("abc", 3)
+	idx.Add("-1.5", 4)
+	idx.Add("5", 5)
+	idx.Add("200", 6)
+	assert.Equal(t, 5, idx.Len())
+
+	assert.Equal(t, []uint32{4}, idx.FindTagValueIDs(stmt.LessThan, 5))
+	assert.Equal(t, []uint32{4, 5, 2}, idx.FindTagValueIDs(stmt.LessEqual, 5))
+	assert.Equal(t, []uint32{1, 6}, idx.FindTagValueIDs(stmt.GreaterThan, 5))
+	assert.Equal(t, []uint32{5, 2, 1, 6}, idx.FindTagValueIDs(stmt.GreaterEqual, 5))
+	assert.Empty(t, idx.FindTagValueIDs(stmt.GreaterThan, 200))
+	assert.Empty(t, idx.FindTagValueIDs(stmt.LessThan, -10))
+	assert.Nil(t, idx.FindTagValueIDs(stmt.RangeOP(0), 5))
+}
+
+func TestNewNumericIndexFrom(t *testing.T) {
+	idx := NewNumericIndexFrom(map[string]uint32{"10": 1, "2": 2, "a": 3, "30": 4})
+	assert.Equal(t, 3, idx.Len())
+	assert.Equal(t, []uint32{2, 1, 4}, idx.FindTagValueIDs(stmt.GreaterEqual, 0))
+	assert.Equal(t, []uint32{1, 4}, idx.FindTagValueIDs(stmt.GreaterThan, 2))
+}
+
+func TestNumericIndexCache_getOrLoad(t *testing.T) {
+	nopKVFlusher := kv.NewNopFlusher()
+	flusher := NewFlusher(nopKVFlusher)
+	flusher.FlushTagValue([]byte("10"), 1)
+	flusher.FlushTagValue([]byte("2"), 2)
+	_ = flusher.FlushTagKeyID(1, 2)
+	block := append([]byte{}, nopKVFlusher.Bytes()...)
+
+	cache := newNumericIndexCache(1)
+	idx, err := cache.getOrLoad("1.sst", 1, block)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{2, 1}, idx.FindTagValueIDs(stmt.GreaterThan, 1))
+	// hit cache
+	idx2, err := cache.getOrLoad("1.sst", 1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, idx, idx2)
+	// evict when full
+	_, err = cache.getOrLoad("2.sst", 1, block)
+	assert.NoError(t, err)
+	assert.Len(t, cache.indexes, 1)
+	// bad block
+	_, err = cache.getOrLoad("3.sst", 1, []byte{1, 2, 3})
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ package tagkeymeta
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/lindb/lindb/constants"
 	"github.com/lindb/lindb/kv/table"
@@ -117,6 +118,9 @@ func (r *tagReader) GetTagValueID(tagID uint32, tagValue string) (tagValueID uin
 
 // FindValueIDsByExprForTagKeyID finds tag values ids by tag filter expr and tag key id
 func (r *tagReader) FindValueIDsByExprForTagKeyID(tagID uint32, expr stmt.TagFilter) (*roaring.Bitmap, error) {
+	if rangeExpr, ok := expr.(*stmt.RangeExpr); ok {
+		return r.findValueIDsByRange(tagID, rangeExpr)
+	}
 	tagKeyMetas := r.filterTagKeyMetas(tagID)
 	if len(tagKeyMetas) == 0 {
 		return nil, fmt.Errorf("%w, tagID: %d", constants.ErrTagKeyMetaNotFound, tagID)
@@ -143,6 +147,35 @@ func (r *tagReader) FindValueIDsByExprForTagKeyID(tagID uint32, expr stmt.TagFil
 	return tagValueIDs, nil
 }
 
+// findValueIDsByRange finds tag values ids by range filter expr via numeric index of tag key
+func (r *tagReader) findValueIDsByRange(tagID uint32, expr *stmt.RangeExpr) (*roaring.Bitmap, error) {
+	value, err := strconv.ParseFloat(expr.Value, 64)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	tagValueIDs := roaring.New()
+	for _, reader := range r.readers {
+		tagKeyMetaBlock, ok := reader.Get(tagID)
+		if !ok {
+			continue
+		}
+		idx, err := numericIndexes.getOrLoad(reader.Path(), tagID, tagKeyMetaBlock)
+		if err != nil {
+			continue
+		}
+		found = true
+		tagValueIDs.AddMany(idx.FindTagValueIDs(expr.Op, value))
+	}
+	if !found {
+		return nil, fmt.Errorf("%w, tagID: %d", constants.ErrTagKeyMetaNotFound, tagID)
+	}
+	if tagValueIDs.IsEmpty() {
+		return nil, fmt.Errorf("%w, tagID: %d", constants.ErrTagValueIDNotFound, tagID)
+	}
+	return tagValueIDs, nil
+}
+
 // GetTagValueIDsForTagKeyID get tag value ids for spec metric's tag key id
 func (r *tagReader) GetTagValueIDsForTagKeyID(tagID uint32) (*roaring.Bitmap, error) {
 	tagKeyMetas := r.filterTagKeyMetas(tagID)
@@ -264,6 +264,45 @@ func TestReader_FindSeriesIDsByExprForTagID_RegexExpr(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestReader_FindValueIDsByExprForTagKeyID_RangeExpr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nopKVFlusher := kv.NewNopFlusher()
+	flusher := NewFlusher(nopKVFlusher)
+	for id, value := range map[uint32]string{1: "1", 2: "5", 3: "10", 4: "100", 5: "abc"} {
+		flusher.FlushTagValue([]byte(value), id)
+	}
+	_ = flusher.FlushTagKeyID(30, 5)
+	shardBlock := append([]byte{}, nopKVFlusher.Bytes()...)
+
+	mockReader := table.NewMockReader(ctrl)
+	mockReader.EXPECT().Path().Return("range_expr_000001.sst").AnyTimes()
+	mockReader.EXPECT().Get(uint32(19)).Return(nil, false).AnyTimes()
+	mockReader.EXPECT().Get(uint32(23)).Return([]byte{1, 2, 3}, true).AnyTimes()
+	mockReader.EXPECT().Get(uint32(30)).Return(shardBlock, true).AnyTimes()
+	reader := NewReader([]table.Reader{mockReader})
+
+	idSet, err := reader.FindValueIDsByExprForTagKeyID(30, &stmt.RangeExpr{Key: "shard", Op: stmt.GreaterEqual, Value: "5"})
+	assert.NoError(t, err)
+	assert.Equal(t, roaring.BitmapOf(2, 3, 4), idSet)
+	idSet, err = reader.FindValueIDsByExprForTagKeyID(30, &stmt.RangeExpr{Key: "shard", Op: stmt.LessThan, Value: "100"})
+	assert.NoError(t, err)
+	assert.Equal(t, roaring.BitmapOf(1, 2, 3), idSet)
+	// value not match
+	_, err = reader.FindValueIDsByExprForTagKeyID(30, &stmt.RangeExpr{Key: "shard", Op: stmt.GreaterThan, Value: "100"})
+	assert.True(t, errors.Is(err, constants.ErrTagValueIDNotFound))
+	// value not numeric
+	_, err = reader.FindValueIDsByExprForTagKeyID(30, &stmt.RangeExpr{Key: "shard", Op: stmt.GreaterThan, Value: "abc"})
+	assert.Error(t, err)
+	// tag key not exist
+	_, err = reader.FindValueIDsByExprForTagKeyID(19, &stmt.RangeExpr{Key: "shard", Op: stmt.GreaterThan, Value: "1"})
+	assert.True(t, errors.Is(err, constants.ErrTagKeyMetaNotFound))
+	// bad block
+	_, err = reader.FindValueIDsByExprForTagKeyID(23, &stmt.RangeExpr{Key: "shard", Op: stmt.GreaterThan, Value: "1"})
+	assert.True(t, errors.Is(err, constants.ErrTagKeyMetaNotFound))
+}
+
 func TestReader_SuggestTagValues(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
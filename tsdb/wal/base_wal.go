@@ -21,6 +21,7 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/lindb/lindb/internal/linmetric"
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/queue/page"
 	"github.com/lindb/lindb/series/field"
@@ -34,6 +35,9 @@ var (
 	releaseWALPageFailCounter = walScope.NewDeltaCounter("wal_release_page_fail")
 )
 
+// syncKindWAL represents the kind of sync metrics for wal
+const syncKindWAL = "wal"
+
 // SeriesRecoveryFunc represents the series recovery function
 type SeriesRecoveryFunc = func(metricID uint32, tagsHash uint64, seriesID uint32) error
 
@@ -58,6 +62,7 @@ type baseWAL struct {
 	currentPage page.MappedPage
 
 	offset int
+	syncer *bufioutil.Syncer
 
 	pageIndex       atomic.Int64
 	commitPageIndex atomic.Int64
@@ -77,7 +82,12 @@ func newBaseWAL(path string, pageSize int) (*baseWAL, error) {
 	}
 
 	pageIDs := fct.GetPageIDs()
-	wal := &baseWAL{path: path, walFactory: fct, pageSize: pageSize}
+	wal := &baseWAL{
+		path:       path,
+		walFactory: fct,
+		pageSize:   pageSize,
+		syncer:     bufioutil.NewSyncer(syncKindWAL, bufioutil.DefaultSyncPolicy),
+	}
 
 	defer func() {
 		if err != nil {
@@ -106,7 +116,7 @@ func (wal *baseWAL) checkPage(length int) error {
 	// prepare the data pointer
 	if wal.offset+length > wal.pageSize {
 		// sync previous data page
-		if err := wal.currentPage.Sync(); err != nil {
+		if err := wal.syncer.Sync(wal.currentPage.Sync); err != nil {
 			walLogger.Error("sync data page err when alloc",
 				logger.String("wal", wal.path), logger.Error(err))
 		}
@@ -123,6 +133,14 @@ func (wal *baseWAL) checkPage(length int) error {
 	return nil
 }
 
+// appended records the length of appended entry, syncs current page based on sync policy.
+func (wal *baseWAL) appended(length int) {
+	if err := wal.syncer.Written(length, wal.currentPage.Sync); err != nil {
+		walLogger.Error("sync data page err when append",
+			logger.String("wal", wal.path), logger.Error(err))
+	}
+}
+
 func (wal *baseWAL) putUint8(value uint8) {
 	wal.currentPage.PutUint8(value, wal.offset)
 	wal.offset++
@@ -145,9 +163,14 @@ func (wal *baseWAL) putString(value string) {
 	wal.offset += length
 }
 
-// sync flushes data into disk
+// sync flushes data into disk based on sync policy
 func (wal *baseWAL) sync() error {
-	return wal.currentPage.Sync()
+	return wal.syncer.Batch(wal.currentPage.Sync)
+}
+
+// setSyncPolicy sets the sync policy of wal
+func (wal *baseWAL) setSyncPolicy(policy bufioutil.SyncPolicy) {
+	wal.syncer.SetPolicy(policy)
 }
 
 // close closes the wal log
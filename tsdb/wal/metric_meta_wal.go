@@ -18,6 +18,7 @@
 package wal
 
 import (
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/series/field"
 )
@@ -65,8 +66,10 @@ type MetricMetaWAL interface {
 		fieldRecovery FieldRecoveryFunc,
 		tagKeyRecovery TagKeyRecoveryFunc,
 		commit CommitFunc)
-	// Sync flushes data into disk
+	// Sync flushes data into disk based on sync policy
 	Sync() error
+	// SetSyncPolicy sets the sync policy of wal log
+	SetSyncPolicy(policy bufioutil.SyncPolicy)
 	// Close closes the wal log
 	Close() error
 }
@@ -94,6 +97,7 @@ func (m *metricMetaWAL) AppendMetric(namespace, metricName string, metricID uint
 	m.base.putString(namespace)
 	m.base.putString(metricName)
 	m.base.putUint32(metricID)
+	m.base.appended(len(namespace) + len(metricName) + metricBaseLength)
 	return nil
 }
 
@@ -107,6 +111,7 @@ func (m *metricMetaWAL) AppendField(metricID uint32, fID field.ID, fieldName fie
 	m.base.putUint8(uint8(fID))
 	m.base.putString(string(fieldName))
 	m.base.putUint8(uint8(fType))
+	m.base.appended(len(fieldName) + fieldBaseLength)
 	return nil
 }
 
@@ -119,6 +124,7 @@ func (m *metricMetaWAL) AppendTagKey(metricID uint32, tagKeyID uint32, tagKey st
 	m.base.putUint32(metricID)
 	m.base.putUint32(tagKeyID)
 	m.base.putString(tagKey)
+	m.base.appended(len(tagKey) + tagKeyBaseLength)
 	return nil
 }
 
@@ -218,6 +224,11 @@ func (m *metricMetaWAL) Sync() error {
 	return m.base.sync()
 }
 
+// SetSyncPolicy sets the sync policy of wal log
+func (m *metricMetaWAL) SetSyncPolicy(policy bufioutil.SyncPolicy) {
+	m.base.setSyncPolicy(policy)
+}
+
 // Close closes the wal log
 func (m *metricMetaWAL) Close() error {
 	return m.base.close()
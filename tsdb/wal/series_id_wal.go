@@ -18,6 +18,7 @@
 package wal
 
 import (
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/logger"
 	"github.com/lindb/lindb/pkg/queue/page"
@@ -51,8 +52,10 @@ type SeriesWAL interface {
 	NeedRecovery() bool
 	// Recovery recoveries wal log, then writes data via recovery function
 	Recovery(recovery SeriesRecoveryFunc, commit CommitFunc)
-	// Sync flushes data into disk
+	// Sync flushes data into disk based on sync policy
 	Sync() error
+	// SetSyncPolicy sets the sync policy of wal log
+	SetSyncPolicy(policy bufioutil.SyncPolicy)
 	// Close closes the wal log
 	Close() error
 }
@@ -79,6 +82,7 @@ func (wal *seriesWAL) Append(metricID uint32, tagsHash uint64, seriesID uint32)
 	wal.base.putUint32(metricID)
 	wal.base.putUint64(tagsHash)
 	wal.base.putUint32(seriesID)
+	wal.base.appended(seriesEntryLength)
 
 	return nil
 }
@@ -135,11 +139,16 @@ func (wal *seriesWAL) Recovery(recovery SeriesRecoveryFunc, commit CommitFunc) {
 	}
 }
 
-// Sync flushes data into disk
+// Sync flushes data into disk based on sync policy
 func (wal *seriesWAL) Sync() error {
 	return wal.base.sync()
 }
 
+// SetSyncPolicy sets the sync policy of wal log
+func (wal *seriesWAL) SetSyncPolicy(policy bufioutil.SyncPolicy) {
+	wal.base.setSyncPolicy(policy)
+}
+
 // Close closes the wal log
 func (wal *seriesWAL) Close() error {
 	return wal.base.close()
@@ -24,6 +24,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/lindb/pkg/bufioutil"
 	"github.com/lindb/lindb/pkg/fileutil"
 	"github.com/lindb/lindb/pkg/queue/page"
 )
@@ -248,6 +249,43 @@ func TestSeriesWAL_Recovery_err(t *testing.T) {
 	assert.False(t, wal.NeedRecovery())
 }
 
+func TestSeriesWAL_SyncPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		newPageFactoryFunc = page.NewFactory
+		_ = fileutil.RemoveDir(testSeriesWALPath)
+
+		ctrl.Finish()
+	}()
+	fct := page.NewMockFactory(ctrl)
+	newPageFactoryFunc = func(path string, pageSize int) (page.Factory, error) {
+		return fct, nil
+	}
+	mockPage := page.NewMockMappedPage(ctrl)
+	fct.EXPECT().GetPageIDs().Return(nil)
+	fct.EXPECT().AcquirePage(int64(1)).Return(mockPage, nil)
+	wal, err := NewSeriesWAL(testSeriesWALPath)
+	assert.NoError(t, err)
+	mockPage.EXPECT().PutUint32(gomock.Any(), gomock.Any()).AnyTimes()
+	mockPage.EXPECT().PutUint64(gomock.Any(), gomock.Any()).AnyTimes()
+	// case 1: sync every batch
+	assert.NoError(t, wal.Append(10, 20, 100))
+	mockPage.EXPECT().Sync().Return(nil)
+	assert.NoError(t, wal.Sync())
+	// case 2: never sync
+	wal.SetSyncPolicy(bufioutil.SyncPolicy{Mode: bufioutil.SyncNever})
+	assert.NoError(t, wal.Append(10, 20, 100))
+	assert.NoError(t, wal.Sync())
+	// case 3: sync every 2 entries, sync err
+	wal.SetSyncPolicy(bufioutil.SyncPolicy{Mode: bufioutil.SyncBytes, Bytes: seriesEntryLength * 2})
+	assert.NoError(t, wal.Append(10, 20, 100))
+	assert.NoError(t, wal.Sync())
+	mockPage.EXPECT().Sync().Return(fmt.Errorf("err"))
+	assert.NoError(t, wal.Append(10, 20, 100))
+	mockPage.EXPECT().Sync().Return(nil)
+	assert.NoError(t, wal.Append(10, 20, 100))
+}
+
 func TestSeriesWAL_Close(t *testing.T) {
 	defer func() {
 		_ = fileutil.RemoveDir(testSeriesWALPath)
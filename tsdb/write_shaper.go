@@ -0,0 +1,202 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/lindb/lindb/internal/linmetric"
+)
+
+//go:generate mockgen -source=./write_shaper.go -destination=./write_shaper_mock.go -package=tsdb
+
+var (
+	writeShaperScope        = linmetric.NewScope("lindb.tsdb.write_shaper")
+	appliedMetricsVec       = writeShaperScope.NewDeltaCounterVec("applied_metrics", "db")
+	throttledMetricsVec     = writeShaperScope.NewDeltaCounterVec("throttled_metrics", "db")
+	allocatedRateVec        = writeShaperScope.NewGaugeVec("allocated_rate", "db")
+	writeUtilizationVec     = writeShaperScope.NewGaugeVec("utilization", "db")
+	throttledDurationVec    = writeShaperScope.Scope("throttled_duration").NewDeltaHistogramVec("db")
+	utilizationStatInterval = time.Second
+)
+
+// WriteShaper represents the write-apply rate shaping of databases on storage node.
+// The max write rate of node is shared by all databases based on the write weight of database,
+// so that one database's ingest surge cannot monopolize memory database allocation and flush bandwidth.
+type WriteShaper interface {
+	// SetWeight sets the write weight of database, then re-balances the write rate of all databases.
+	SetWeight(databaseName string, weight int)
+	// Wait blocks until n metrics of database can be applied, or ctx is done.
+	Wait(ctx context.Context, databaseName string, n int) error
+}
+
+// databaseWriteLimiter represents the write rate limiter of database.
+type databaseWriteLimiter struct {
+	weight  int
+	limiter *rate.Limiter // nil means no limit
+
+	mutex         sync.Mutex
+	windowStart   time.Time
+	windowApplied int
+
+	appliedMetrics    *linmetric.BoundDeltaCounter
+	throttledMetrics  *linmetric.BoundDeltaCounter
+	allocatedRate     *linmetric.BoundGauge
+	utilization       *linmetric.BoundGauge
+	throttledDuration *linmetric.BoundDeltaHistogram
+}
+
+// writeShaper implements WriteShaper interface.
+type writeShaper struct {
+	maxWriteRate int // 0 means no limit
+
+	limiters map[string]*databaseWriteLimiter
+	mutex    sync.RWMutex
+}
+
+// newWriteShaper creates the write shaper with max write rate(number of metrics per second).
+func newWriteShaper(maxWriteRate int) WriteShaper {
+	return &writeShaper{
+		maxWriteRate: maxWriteRate,
+		limiters:     make(map[string]*databaseWriteLimiter),
+	}
+}
+
+// SetWeight sets the write weight of database, then re-balances the write rate of all databases.
+func (s *writeShaper) SetWeight(databaseName string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	l, ok := s.limiters[databaseName]
+	if ok && l.weight == weight {
+		return
+	}
+	if !ok {
+		l = newDatabaseWriteLimiter(databaseName)
+		s.limiters[databaseName] = l
+	}
+	l.weight = weight
+	s.rebalance()
+}
+
+// Wait blocks until n metrics of database can be applied, or ctx is done.
+func (s *writeShaper) Wait(ctx context.Context, databaseName string, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	l := s.getOrCreateLimiter(databaseName)
+	l.appliedMetrics.Add(float64(n))
+	l.stat(n)
+
+	s.mutex.RLock()
+	limiter := l.limiter
+	s.mutex.RUnlock()
+	if limiter == nil {
+		return nil
+	}
+	now := time.Now()
+	if limiter.AllowN(now, n) {
+		return nil
+	}
+	l.throttledMetrics.Add(float64(n))
+	defer l.throttledDuration.UpdateSince(now)
+	// wait n cannot exceed the burst of limiter
+	burst := limiter.Burst()
+	for n > 0 {
+		batch := n
+		if batch > burst {
+			batch = burst
+		}
+		if err := limiter.WaitN(ctx, batch); err != nil {
+			return err
+		}
+		n -= batch
+	}
+	return nil
+}
+
+// getOrCreateLimiter returns the limiter of database, creates it with default weight if not exist.
+func (s *writeShaper) getOrCreateLimiter(databaseName string) *databaseWriteLimiter {
+	s.mutex.RLock()
+	l, ok := s.limiters[databaseName]
+	s.mutex.RUnlock()
+	if ok {
+		return l
+	}
+	s.SetWeight(databaseName, 1)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.limiters[databaseName]
+}
+
+// rebalance re-allocates the write rate of all databases by weight, must be called with lock.
+func (s *writeShaper) rebalance() {
+	if s.maxWriteRate <= 0 {
+		return
+	}
+	totalWeight := 0
+	for _, l := range s.limiters {
+		totalWeight += l.weight
+	}
+	for _, l := range s.limiters {
+		allocated := s.maxWriteRate * l.weight / totalWeight
+		if allocated <= 0 {
+			allocated = 1
+		}
+		// the old limiter is still used by the waiting writes
+		l.limiter = rate.NewLimiter(rate.Limit(allocated), allocated)
+		l.allocatedRate.Update(float64(allocated))
+	}
+}
+
+// newDatabaseWriteLimiter creates the write limiter of database.
+func newDatabaseWriteLimiter(databaseName string) *databaseWriteLimiter {
+	return &databaseWriteLimiter{
+		windowStart:       time.Now(),
+		appliedMetrics:    appliedMetricsVec.WithTagValues(databaseName),
+		throttledMetrics:  throttledMetricsVec.WithTagValues(databaseName),
+		allocatedRate:     allocatedRateVec.WithTagValues(databaseName),
+		utilization:       writeUtilizationVec.WithTagValues(databaseName),
+		throttledDuration: throttledDurationVec.WithTagValues(databaseName),
+	}
+}
+
+// stat records the applied metrics, updates the utilization of allocated write rate per stat interval.
+func (l *databaseWriteLimiter) stat(n int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.windowApplied += n
+	elapsed := time.Since(l.windowStart)
+	if elapsed < utilizationStatInterval {
+		return
+	}
+	if allocated := l.allocatedRate.Get(); allocated > 0 {
+		l.utilization.Update(float64(l.windowApplied) / elapsed.Seconds() / allocated)
+	}
+	l.windowStart = time.Now()
+	l.windowApplied = 0
+}
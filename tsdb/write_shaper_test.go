@@ -0,0 +1,74 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteShaper_NoLimit(t *testing.T) {
+	s := newWriteShaper(0)
+	assert.NoError(t, s.Wait(context.TODO(), "db", 0))
+	assert.NoError(t, s.Wait(context.TODO(), "db", 100000))
+	s1 := s.(*writeShaper)
+	assert.Nil(t, s1.limiters["db"].limiter)
+	assert.Equal(t, 1, s1.limiters["db"].weight)
+}
+
+func TestWriteShaper_SetWeight(t *testing.T) {
+	s := newWriteShaper(100)
+	s1 := s.(*writeShaper)
+	s.SetWeight("db1", 0)
+	assert.Equal(t, 100.0, s1.limiters["db1"].allocatedRate.Get())
+	s.SetWeight("db2", 3)
+	assert.Equal(t, 25.0, s1.limiters["db1"].allocatedRate.Get())
+	assert.Equal(t, 75.0, s1.limiters["db2"].allocatedRate.Get())
+	// weight not changed
+	limiter := s1.limiters["db2"].limiter
+	s.SetWeight("db2", 3)
+	assert.Equal(t, limiter, s1.limiters["db2"].limiter)
+	// rate less than 1
+	s = newWriteShaper(1)
+	s.SetWeight("db1", 1)
+	s.SetWeight("db2", 1)
+	assert.Equal(t, 1.0, s.(*writeShaper).limiters["db2"].allocatedRate.Get())
+}
+
+func TestWriteShaper_Wait(t *testing.T) {
+	defer func() {
+		utilizationStatInterval = time.Second
+	}()
+	utilizationStatInterval = 0
+	s := newWriteShaper(100)
+	// burst
+	assert.NoError(t, s.Wait(context.TODO(), "db", 100))
+	utilization := s.(*writeShaper).limiters["db"].utilization.Get()
+	assert.True(t, utilization > 0)
+	// throttled, wait n > burst
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, s.Wait(ctx, "db", 150))
+	// wait successfully
+	start := time.Now()
+	assert.NoError(t, s.Wait(context.TODO(), "db", 2))
+	assert.True(t, time.Since(start) > 0)
+}
@@ -6,12 +6,15 @@ export const SPACING = 10
 
 /* API URL */
 // export const API_URL = 'http://localhost:9000/api/v1'
-export const API_URL = '/api/v1'
+// console is served under {base-path}/console, api is under {base-path}/api/v1
+const BASE_PATH = window.location.pathname.split('/console')[0].replace(/\/+$/, '')
+export const API_URL = BASE_PATH + '/api/v1'
 
 export const PATH = {
   login: '/login',
   master: '/cluster/master',
   brokerClusterState: '/broker/cluster/state',
+  brokerReplicationTopology: '/broker/replication/topology',
   listStorageClusterState: '/storage/cluster/state/list',
   getStorageClusterState: '/storage/cluster/state',
   storageStateList: '/storage/state/list',
@@ -42,6 +42,11 @@ export const MENUS = [
         icon: 'query',
         path: '/query',
       },
+      {
+        title: 'Replication',
+        icon: 'share-alt',
+        path: '/replication',
+      },
     ],
   }, {
     title: 'Metadata',
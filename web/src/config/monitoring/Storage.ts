@@ -55,6 +55,48 @@ export const WriteDashboard = [
         8,
         UnitEnum.Milliseconds,
     ),
+    metric(
+        'Shard Flush Memory Size',
+        'select quantile(0.99) from lindb.tsdb.shard.memdb_flush_size group by db, shard',
+        8,
+        UnitEnum.Bytes,
+    ),
+    metric(
+        'Shard Flushed Bytes',
+        'select memdb_flushed_bytes from lindb.tsdb.shard group by db, shard',
+        8,
+        UnitEnum.Bytes,
+    ),
+    metric(
+        'Shard Memory Families',
+        'select memdb_families from lindb.tsdb.shard group by db, shard',
+        8,
+        UnitEnum.None,
+    ),
+    metric(
+        'Shard Index Flush Duration',
+        'select quantile(0.99) from lindb.tsdb.shard.index_flush_duration group by db, shard',
+        8,
+        UnitEnum.Milliseconds,
+    ),
+    metric(
+        'Shard Data Families',
+        'select data_families from lindb.tsdb.compaction group by db, shard',
+        8,
+        UnitEnum.None,
+    ),
+    metric(
+        'Shard Compaction Duration',
+        'select quantile(0.99) from lindb.tsdb.compaction.compact_duration group by db, shard',
+        8,
+        UnitEnum.Milliseconds,
+    ),
+    metric(
+        'Shard Compacted Bytes',
+        'select compacted_bytes from lindb.tsdb.compaction group by db, shard',
+        8,
+        UnitEnum.Bytes,
+    ),
     metric(
       'Generate Metric ID',
       'select gen_metric_ids from lindb.tsdb.metadb group by db',
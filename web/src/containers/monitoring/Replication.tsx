@@ -0,0 +1,111 @@
+import { Badge, Card, Table } from 'antd'
+import { DatabaseChannelTopology, ReplicaState, ShardChannelTopology } from 'model/Monitoring'
+import * as React from 'react'
+import { getReplicationTopology } from 'service/Monitor'
+
+interface ReplicationProps {
+}
+
+interface ReplicationStatus {
+    topology?: Array<DatabaseChannelTopology>
+}
+
+interface ReplicaRow extends ReplicaState {
+    bufferedMetrics: number,
+    appendSeq: number,
+}
+
+export default class Replication extends React.Component<ReplicationProps, ReplicationStatus> {
+
+    constructor(props: ReplicationProps) {
+        super(props)
+        this.state = {}
+    }
+
+    componentDidMount(): void {
+        this.getReplicationTopology()
+    }
+
+    async getReplicationTopology() {
+        const topology: any = await getReplicationTopology()
+        if (topology) {
+            this.setState({ topology })
+        }
+    }
+
+    /**
+     * flat shard channel topology to replica rows, one row per target storage node
+     * @param shards shard channel topology list
+     */
+    buildRows(shards: Array<ShardChannelTopology>): Array<ReplicaRow> {
+        const rows: Array<ReplicaRow> = []
+        shards.forEach((shard: ShardChannelTopology) => {
+            (shard.replicas || []).forEach((replica: ReplicaState) => {
+                rows.push({ ...replica, bufferedMetrics: shard.bufferedMetrics, appendSeq: shard.appendSeq })
+            })
+        })
+        return rows
+    }
+
+    render() {
+        const { topology } = this.state
+        if (!topology || topology.length === 0) {
+            return (<Card size="small" title="Replication">empty</Card>)
+        }
+        const columns = [
+            {
+                title: 'Shard',
+                dataIndex: 'shardID',
+            },
+            {
+                title: 'Target',
+                render: (text: any, record: ReplicaRow, index: any) => {
+                    return `${record.target.hostName || record.target.ip}:${record.target.port}`
+                },
+            },
+            {
+                title: 'Buffered Metrics',
+                dataIndex: 'bufferedMetrics',
+            },
+            {
+                title: 'Append Seq',
+                dataIndex: 'appendSeq',
+            },
+            {
+                title: 'Replica Index',
+                dataIndex: 'replicaIndex',
+            },
+            {
+                title: 'Ack Index',
+                dataIndex: 'ackIndex',
+            },
+            {
+                title: 'Lag',
+                dataIndex: 'pending',
+                render: (text: any, record: ReplicaRow, index: any) => {
+                    return (
+                        <div>
+                            <Badge status={record.pending > 0 ? 'warning' : 'success'} />
+                            {record.pending}
+                        </div>
+                    )
+                },
+            },
+        ]
+        return (
+            <div>
+                {topology.map((db: DatabaseChannelTopology) => (
+                    <Card size="small" key={db.database} title={`${db.database} (shards: ${db.numOfShard})`}>
+                        <Table
+                            dataSource={this.buildRows(db.shards || [])}
+                            bordered={true}
+                            rowKey={(record: ReplicaRow) => `${record.shardID}-${record.target.ip}:${record.target.port}`}
+                            size="small"
+                            columns={columns}
+                            pagination={false} />
+                    </Card>
+                ))}
+            </div>
+        )
+    }
+}
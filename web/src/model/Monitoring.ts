@@ -30,4 +30,26 @@ export interface ReplicaStatus {
   total: number,
   underReplicated: number,
   unavailable: number,
+}
+
+export interface ReplicaState {
+  database: string,
+  shardID: number,
+  target: { ip: string, port: number, hostName: string },
+  pending: number,
+  replicaIndex: number,
+  ackIndex: number,
+}
+
+export interface ShardChannelTopology {
+  shardID: number,
+  bufferedMetrics: number,
+  appendSeq: number,
+  replicas: Array<ReplicaState>,
+}
+
+export interface DatabaseChannelTopology {
+  database: string,
+  numOfShard: number,
+  shards: Array<ShardChannelTopology>,
 }
\ No newline at end of file
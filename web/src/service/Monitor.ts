@@ -1,5 +1,5 @@
 import { PATH } from 'config/config'
-import { NodeList, StorageCluster } from 'model/Monitoring'
+import { DatabaseChannelTopology, NodeList, StorageCluster } from 'model/Monitoring'
 import { GET } from 'service/APIUtils'
 
 export function getMaster() {
@@ -12,6 +12,11 @@ export function getBrokerCluster() {
   return GET<NodeList>(url)
 }
 
+export function getReplicationTopology() {
+  const url = PATH.brokerReplicationTopology
+  return GET<Array<DatabaseChannelTopology>>(url)
+}
+
 export function listStorageCluster() {
   const url = PATH.listStorageClusterState
   return GET<Array<StorageCluster>>(url)